@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"go.uber.org/fx"
 
+	channelmodule "github.com/memohai/memoh/cmd/internal/channel"
 	coremodule "github.com/memohai/memoh/cmd/internal/core"
 	"github.com/memohai/memoh/internal/accounts"
 	"github.com/memohai/memoh/internal/agent/application"
@@ -17,33 +19,57 @@ import (
 	toolapproval "github.com/memohai/memoh/internal/agent/decision/approval"
 	userinput "github.com/memohai/memoh/internal/agent/decision/input"
 	acpagent "github.com/memohai/memoh/internal/agent/runtime/acp"
+	"github.com/memohai/memoh/internal/agent/turn"
 	audiopkg "github.com/memohai/memoh/internal/audio"
+	"github.com/memohai/memoh/internal/audit"
+	"github.com/memohai/memoh/internal/auth/refresh"
 	"github.com/memohai/memoh/internal/boot"
 	"github.com/memohai/memoh/internal/bots"
 	"github.com/memohai/memoh/internal/channel"
 	"github.com/memohai/memoh/internal/channel/adapters/local"
+	"github.com/memohai/memoh/internal/channel/identities"
 	"github.com/memohai/memoh/internal/channel/route"
+	msgarchive "github.com/memohai/memoh/internal/chat/archive"
 	"github.com/memohai/memoh/internal/chat/event"
+	"github.com/memohai/memoh/internal/chat/historysearch"
 	"github.com/memohai/memoh/internal/chat/message"
+	"github.com/memohai/memoh/internal/chat/tagging"
 	sessionpkg "github.com/memohai/memoh/internal/chat/thread"
+	"github.com/memohai/memoh/internal/chatimport"
 	"github.com/memohai/memoh/internal/command"
 	"github.com/memohai/memoh/internal/config"
+	"github.com/memohai/memoh/internal/dataexport"
+	pgvectordb "github.com/memohai/memoh/internal/db/pgvector"
 	dbstore "github.com/memohai/memoh/internal/db/store"
 	emailpkg "github.com/memohai/memoh/internal/email"
+	"github.com/memohai/memoh/internal/encryption"
+	"github.com/memohai/memoh/internal/erasure"
+	"github.com/memohai/memoh/internal/featureflags"
+	"github.com/memohai/memoh/internal/feeds"
+	githubpkg "github.com/memohai/memoh/internal/github"
 	"github.com/memohai/memoh/internal/handlers"
 	"github.com/memohai/memoh/internal/healthcheck"
 	channelchecker "github.com/memohai/memoh/internal/healthcheck/checkers/channel"
 	mcpchecker "github.com/memohai/memoh/internal/healthcheck/checkers/mcp"
 	modelchecker "github.com/memohai/memoh/internal/healthcheck/checkers/model"
+	"github.com/memohai/memoh/internal/knowledge"
 	"github.com/memohai/memoh/internal/mcp"
 	"github.com/memohai/memoh/internal/media"
 	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	memcompaction "github.com/memohai/memoh/internal/memory/compaction"
+	memoryexpiry "github.com/memohai/memoh/internal/memory/expiry"
+	memflags "github.com/memohai/memoh/internal/memory/flags"
+	memoryreview "github.com/memohai/memoh/internal/memory/review"
 	"github.com/memohai/memoh/internal/models"
 	"github.com/memohai/memoh/internal/oauthclients"
 	"github.com/memohai/memoh/internal/providers"
+	"github.com/memohai/memoh/internal/schedule"
 	"github.com/memohai/memoh/internal/server"
 	"github.com/memohai/memoh/internal/settings"
+	"github.com/memohai/memoh/internal/sharedstate"
+	"github.com/memohai/memoh/internal/usagereport"
 	"github.com/memohai/memoh/internal/version"
+	"github.com/memohai/memoh/internal/voice"
 	"github.com/memohai/memoh/internal/workspace"
 )
 
@@ -55,15 +81,259 @@ func provideServerHandler(fn any) any {
 	)
 }
 
-func provideMemoryHandler(log *slog.Logger, botService *bots.Service, accountService *accounts.Service, _ config.Config, memoryRegistry *memprovider.Registry, settingsService *settings.Service, _ *handlers.ContainerdHandler) *handlers.MemoryHandler {
+func provideMemoryHandler(log *slog.Logger, botService *bots.Service, accountService *accounts.Service, _ config.Config, memoryRegistry *memprovider.Registry, settingsService *settings.Service, flagService *memflags.Service, compactionService *memcompaction.Service, scheduleService *schedule.Service, featureFlags *featureflags.Service, auditService *audit.Service, _ *handlers.ContainerdHandler) *handlers.MemoryHandler {
 	h := handlers.NewMemoryHandler(log, botService, accountService)
 	h.SetMemoryRegistry(memoryRegistry)
 	h.SetSettingsService(settingsService)
+	h.SetFlagService(flagService)
+	h.SetCompactionService(compactionService)
+	h.SetScheduleService(scheduleService)
+	h.SetFeatureFlags(featureFlags)
+	h.SetAuditLog(auditService)
 	return h
 }
 
-func provideAuthHandler(log *slog.Logger, accountService *accounts.Service, rc *boot.RuntimeConfig) *handlers.AuthHandler {
-	return handlers.NewAuthHandler(log, accountService, rc.JwtSecret, rc.JwtExpiresIn)
+// provideUsageReportHandler gives reports a memory handler of their own, as
+// the one registered for routes is not injectable by type.
+func provideUsageReportHandler(log *slog.Logger, queries dbstore.Queries, botService *bots.Service, accountService *accounts.Service, memoryRegistry *memprovider.Registry, settingsService *settings.Service) *handlers.UsageReportHandler {
+	memory := handlers.NewMemoryHandler(log, botService, accountService)
+	memory.SetMemoryRegistry(memoryRegistry)
+	memory.SetSettingsService(settingsService)
+	h := handlers.NewUsageReportHandler(log, usagereport.NewService(log, queries), botService, accountService)
+	h.SetMemoryHandler(memory)
+	return h
+}
+
+func provideIdentityDataHandler(log *slog.Logger, queries dbstore.Queries, identityService *identities.Service, accountService *accounts.Service, memoryRegistry *memprovider.Registry, settingsService *settings.Service) *handlers.IdentityDataHandler {
+	service := erasure.NewService(log, queries, identityService)
+	service.SetMemoryRegistry(memoryRegistry)
+	service.SetSettingsService(settingsService)
+	return handlers.NewIdentityDataHandler(log, service, accountService)
+}
+
+func provideDataExportService(log *slog.Logger, cfg config.Config, queries dbstore.Queries, accountService *accounts.Service, identityService *identities.Service, mediaService *media.Service, keyring *encryption.Keyring, memoryRegistry *memprovider.Registry, settingsService *settings.Service, archiveService *msgarchive.Service) *dataexport.Service {
+	service := dataexport.NewService(log, queries, accountService, identityService, filepath.Join(cfg.Container.DataRootPath(), "exports"))
+	service.SetMediaService(mediaService)
+	if keyring != nil {
+		service.SetContentCipher(keyring)
+	}
+	service.SetMemoryRegistry(memoryRegistry)
+	service.SetSettingsService(settingsService)
+	service.SetMessageArchive(archiveService)
+	return service
+}
+
+func provideChatImportService(log *slog.Logger, routeService *route.DBService, sessionService *sessionpkg.Service, msgService *message.DBService, identityService *identities.Service, memoryRegistry *memprovider.Registry, settingsService *settings.Service) *chatimport.Service {
+	service := chatimport.NewService(log, routeService, route.NewThreadCoordinator(log, routeService, sessionService), msgService, identityService)
+	service.SetMemoryRegistry(memoryRegistry)
+	service.SetSettingsService(settingsService)
+	return service
+}
+
+func startDataExportWorker(lc fx.Lifecycle, service *dataexport.Service) {
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(done)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+}
+
+func provideDataExportHandler(log *slog.Logger, service *dataexport.Service, accountService *accounts.Service, rc *boot.RuntimeConfig) *handlers.DataExportHandler {
+	return handlers.NewDataExportHandler(log, service, accountService, rc.JwtSecret)
+}
+
+func provideFeedsService(log *slog.Logger, cfg config.Config, queries dbstore.Queries, knowledgeService *knowledge.Service, memoryRegistry *memprovider.Registry, settingsService *settings.Service, modelsService *models.Service, providersService *providers.Service, channelRuntime channel.Runtime, registry *channel.Registry) *feeds.Service {
+	service := feeds.NewService(log, queries)
+	if cfg.Offline.Enabled {
+		service.DisablePolling()
+	}
+	service.SetKnowledgeService(knowledgeService)
+	service.SetMemoryRegistry(memoryRegistry)
+	service.SetSettingsService(settingsService)
+	service.SetModelServices(modelsService, providersService)
+	service.SetChannelRuntime(channelRuntime, registry)
+	return service
+}
+
+func startFeedsWorker(lc fx.Lifecycle, service *feeds.Service) {
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(done)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+}
+
+func provideHistorySearchService(log *slog.Logger, queries dbstore.Queries, vectors *pgvectordb.Store, keyring *encryption.Keyring) *historysearch.Service {
+	service := historysearch.NewService(log, queries, vectors)
+	if keyring != nil {
+		service.SetContentCipher(keyring)
+	}
+	return service
+}
+
+func startHistorySearchWorker(lc fx.Lifecycle, service *historysearch.Service) {
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(done)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+}
+
+func provideMessageTaggingService(log *slog.Logger, queries dbstore.Queries, modelsService *models.Service, providersService *providers.Service, accountService *accounts.Service, keyring *encryption.Keyring) *tagging.Service {
+	service := tagging.NewService(log, queries)
+	service.SetModelServices(modelsService, providersService)
+	service.SetAccountService(accountService)
+	if keyring != nil {
+		service.SetContentCipher(keyring)
+	}
+	return service
+}
+
+func startMessageTaggingWorker(lc fx.Lifecycle, service *tagging.Service) {
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(done)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+}
+
+func provideMemoryExpiryService(log *slog.Logger, queries dbstore.Queries, memoryRegistry *memprovider.Registry, settingsService *settings.Service) *memoryexpiry.Service {
+	service := memoryexpiry.NewService(log, queries)
+	service.SetMemoryRegistry(memoryRegistry)
+	service.SetSettingsService(settingsService)
+	return service
+}
+
+func provideMemoryReviewService(log *slog.Logger, queries dbstore.Queries, memoryRegistry *memprovider.Registry, settingsService *settings.Service, channelRuntime channel.Runtime, registry *channel.Registry) *memoryreview.Service {
+	service := memoryreview.NewService(log, queries)
+	service.SetMemoryRegistry(memoryRegistry)
+	service.SetSettingsService(settingsService)
+	service.SetChannelRuntime(channelRuntime, registry)
+	return service
+}
+
+func startMemoryReviewWorker(lc fx.Lifecycle, service *memoryreview.Service) {
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(done)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+}
+
+func provideMemoryCompactionService(log *slog.Logger, queries dbstore.Queries, memoryRegistry *memprovider.Registry, settingsService *settings.Service) *memcompaction.Service {
+	service := memcompaction.NewService(log, queries)
+	service.SetMemoryRegistry(memoryRegistry)
+	service.SetSettingsService(settingsService)
+	return service
+}
+
+func configureMemoryCompactionSchedule(scheduleService *schedule.Service, compactionService *memcompaction.Service) {
+	scheduleService.SetMemoryCompactor(compactionService)
+}
+
+func startMemoryExpiryWorker(lc fx.Lifecycle, service *memoryexpiry.Service) {
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(done)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+}
+
+func provideVoiceService(log *slog.Logger, audioService *audiopkg.Service, settingsService *settings.Service, turnService turn.Service, queries dbstore.Queries, cfg config.Config) *voice.Service {
+	service := voice.NewService(log, audioService, settingsService)
+	service.SetReplier(channelmodule.NewTurnGateway(turnService, queries, cfg.Auth.JWTSecret, log, voice.CurrentChannel))
+	return service
+}
+
+func stopVoiceCalls(lc fx.Lifecycle, service *voice.Service) {
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			service.Close()
+			return nil
+		},
+	})
+}
+
+func configureGitHubChatTrigger(service *githubpkg.Service, turnService turn.Service, queries dbstore.Queries, cfg config.Config, log *slog.Logger) {
+	service.SetChatTriggerer(channelmodule.NewTurnGateway(turnService, queries, cfg.Auth.JWTSecret, log, "github"))
+}
+
+// configureBotMemoryCleanup clears a deleted bot's memories, and with them
+// its vector embeddings, which live outside the main database.
+func configureBotMemoryCleanup(botService *bots.Service, memoryRegistry *memprovider.Registry, settingsService *settings.Service) {
+	botService.AddDataCleaner(&botMemoryCleaner{registry: memoryRegistry, settings: settingsService})
+}
+
+type botMemoryCleaner struct {
+	registry *memprovider.Registry
+	settings *settings.Service
+}
+
+func (c *botMemoryCleaner) CleanupBotData(ctx context.Context, botID string) error {
+	if c.registry == nil {
+		return nil
+	}
+	providerID := memprovider.DefaultBuiltinProviderID
+	if c.settings != nil {
+		if botSettings, err := c.settings.GetBot(ctx, botID); err == nil && strings.TrimSpace(botSettings.MemoryProviderID) != "" {
+			providerID = strings.TrimSpace(botSettings.MemoryProviderID)
+		}
+	}
+	provider, err := c.registry.Get(ctx, providerID)
+	if err != nil {
+		return fmt.Errorf("resolve memory provider: %w", err)
+	}
+	_, err = provider.DeleteAll(ctx, memprovider.DeleteAllRequest{
+		BotID:   botID,
+		Filters: map[string]any{"namespace": "bot", "scopeId": botID},
+	})
+	return err
+}
+
+func provideRefreshTokenService(log *slog.Logger, queries dbstore.Queries, rc *boot.RuntimeConfig) *refresh.Service {
+	return refresh.NewService(log, queries, rc.RefreshExpiresIn)
+}
+
+func provideAuthHandler(log *slog.Logger, accountService *accounts.Service, refreshTokens *refresh.Service, rc *boot.RuntimeConfig) *handlers.AuthHandler {
+	h := handlers.NewAuthHandler(log, accountService, rc.JwtSecret, rc.JwtExpiresIn)
+	h.SetRefreshTokenService(refreshTokens)
+	return h
 }
 
 func provideMessageHandler(log *slog.Logger, msgService *message.DBService, sessionService *sessionpkg.Service, mediaService *media.Service, botService *bots.Service, accountService *accounts.Service, hub *event.Hub, toolApproval *toolapproval.Service, userInput *userinput.Service, bgManager *background.Manager) *handlers.MessageHandler {
@@ -81,9 +351,16 @@ func provideSessionHandler(log *slog.Logger, sessionService *sessionpkg.Service,
 	return handler
 }
 
-func provideUsersHandler(log *slog.Logger, accountService *accounts.Service, botService *bots.Service, routeService *route.DBService, channelStore *channel.Store, channelRuntime channel.Runtime, registry *channel.Registry, workspaceManager *workspace.Manager, acpPool *acpagent.SessionPool) *handlers.UsersHandler {
+func provideUsersHandler(log *slog.Logger, accountService *accounts.Service, botService *bots.Service, routeService *route.DBService, channelStore *channel.Store, channelRuntime channel.Runtime, registry *channel.Registry, workspaceManager *workspace.Manager, acpPool *acpagent.SessionPool, auditService *audit.Service) *handlers.UsersHandler {
 	handler := handlers.NewUsersHandler(log, accountService, botService, routeService, channelStore, channelRuntime, registry, workspaceManager)
 	handler.SetACPRuntimeCloser(acpPool)
+	handler.SetAuditLog(auditService)
+	return handler
+}
+
+func provideProvidersHandler(log *slog.Logger, service *providers.Service, modelsService *models.Service, auditService *audit.Service) *handlers.ProvidersHandler {
+	handler := handlers.NewProvidersHandler(log, service, modelsService)
+	handler.SetAuditLog(auditService)
 	return handler
 }
 
@@ -134,19 +411,27 @@ type serverParams struct {
 	AccountService    *accounts.Service
 	ServerHandlers    []server.Handler `group:"server_handlers"`
 	ContainerdHandler *handlers.ContainerdHandler
+	SharedState       sharedstate.Store
 }
 
-func provideServer(params serverParams) *server.Server {
+func provideServer(params serverParams) (*server.Server, error) {
 	allHandlers := make([]server.Handler, 0, len(params.ServerHandlers)+1)
 	allHandlers = append(allHandlers, params.ServerHandlers...)
 	allHandlers = append(allHandlers, params.ContainerdHandler)
-	return server.NewServerWithSessionValidator(
+	srv := server.NewServerWithSessionValidator(
 		params.Logger,
 		params.RuntimeConfig.ServerAddr,
 		params.Config.Auth.JWTSecret,
 		params.AccountService.ValidateSession,
 		allHandlers...,
 	)
+	srv.SetRateLimit(params.Config.Server.RateLimitPerMinute, params.Config.Server.RateLimitBurst, params.SharedState)
+	maxBodySize, bodyLimits, err := params.Config.Server.BodyLimitBytes()
+	if err != nil {
+		return nil, err
+	}
+	srv.SetBodyLimits(maxBodySize, bodyLimits)
+	return srv, nil
 }
 
 func startServer(lc fx.Lifecycle, logger *slog.Logger, srv *server.Server, shutdowner fx.Shutdowner, cfg config.Config, queries dbstore.Queries, accountStore dbstore.AccountStore, emailService *emailpkg.Service, botService *bots.Service, _ *handlers.ContainerdHandler, manager *workspace.Manager, mcpConnService *mcp.ConnectionService, toolGateway *mcp.ToolGatewayService, channelRuntime channel.Runtime, modelsService *models.Service) {
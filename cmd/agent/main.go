@@ -18,12 +18,16 @@ func main() {
 		runMigrate(os.Args[2:])
 	case "account":
 		runAccount(os.Args[2:])
+	case "messages":
+		runMessages(os.Args[2:])
+	case "maintenance":
+		runMaintenance(os.Args[2:])
 	case "version":
 		if err := runVersion(); err != nil {
 			os.Exit(1)
 		}
 	default:
-		fmt.Fprintf(os.Stderr, "Usage: memoh-server <command>\n\nCommands:\n  serve     Start the server (default)\n  migrate   Run database migrations (up|down|version|force)\n  account   Local account recovery operations\n  version   Print version information\n")
+		fmt.Fprintf(os.Stderr, "Usage: memoh-server <command>\n\nCommands:\n  serve        Start the server (default)\n  migrate      Run database migrations (up|down|version|force|preflight)\n  account      Local account recovery operations\n  messages     History maintenance (repair [--bot ID] [--dry-run])\n  maintenance  Maintenance mode (on [--message TEXT]|off|status)\n  version      Print version information\n")
 		os.Exit(1)
 	}
 }
@@ -38,9 +42,23 @@ func runAccount(args []string) {
 	}
 }
 
+func runMessages(args []string) {
+	if err := runMessagesCommand(args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "messages: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runMaintenance(args []string) {
+	if err := runMaintenanceCommand(args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "maintenance: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 func runMigrate(args []string) {
 	if len(args) == 0 {
-		fmt.Fprintf(os.Stderr, "Usage: memoh-server migrate <up|down|version|force N>\n")
+		fmt.Fprintf(os.Stderr, "Usage: memoh-server migrate <up|down|version|force N|preflight>\n")
 		os.Exit(1)
 	}
 	if err := runMigrateCommand(args); err != nil {
@@ -78,8 +78,12 @@ func commonOptions() fx.Option {
 		fx.Provide(
 			provideServerHandler(handlers.NewPingHandler),
 			provideServerHandler(handlers.NewWebhookTunnelHandler),
+			provideRefreshTokenService,
 			provideServerHandler(provideAuthHandler),
 			provideServerHandler(provideMemoryHandler),
+			provideServerHandler(provideIdentityDataHandler),
+			provideDataExportService,
+			provideServerHandler(provideDataExportHandler),
 			provideServerHandler(provideMessageHandler),
 			provideServerHandler(provideSessionHandler),
 			provideServerHandler(handlers.NewUserRuntimeHandler),
@@ -88,13 +92,32 @@ func commonOptions() fx.Option {
 			provideServerHandler(handlers.NewACPHandler),
 			provideServerHandler(handlers.NewACPRuntimeHandler),
 			provideServerHandler(handlers.NewSwaggerHandler),
-			provideServerHandler(handlers.NewProvidersHandler),
+			provideServerHandler(provideProvidersHandler),
 			provideServerHandler(handlers.NewProviderTemplatesHandler),
 			provideServerHandler(provideProviderOAuthHandler),
 			provideServerHandler(provideACPCodexOAuthServerHandler),
 			provideServerHandler(provideACPClaudeCodeOAuthServerHandler),
 			provideServerHandler(handlers.NewFetchProvidersHandler),
 			provideServerHandler(handlers.NewSearchProvidersHandler),
+			provideServerHandler(handlers.NewKnowledgeHandler),
+			provideFeedsService,
+			provideServerHandler(handlers.NewFeedsHandler),
+			provideHistorySearchService,
+			provideServerHandler(handlers.NewHistorySearchHandler),
+			provideMessageTaggingService,
+			provideServerHandler(handlers.NewMessageTaggingHandler),
+			provideServerHandler(handlers.NewOutputProcessorsHandler),
+			provideServerHandler(handlers.NewWorkingHoursHandler),
+			provideServerHandler(handlers.NewConversationFlowsHandler),
+			provideServerHandler(handlers.NewHTTPToolsHandler),
+			provideChatImportService,
+			provideServerHandler(handlers.NewChatImportHandler),
+			provideMemoryExpiryService,
+			provideMemoryReviewService,
+			provideServerHandler(handlers.NewMemoryReviewHandler),
+			provideMemoryCompactionService,
+			provideServerHandler(handlers.NewStickersHandler),
+			provideServerHandler(handlers.NewGitHubHandler),
 			provideServerHandler(handlers.NewModelsHandler),
 			provideServerHandler(handlers.NewSettingsHandler),
 			provideServerHandler(handlers.NewToolApprovalHandler),
@@ -106,12 +129,21 @@ func commonOptions() fx.Option {
 			provideServerHandler(handlers.NewHeartbeatHandler),
 			provideServerHandler(handlers.NewCompactionHandler),
 			provideServerHandler(handlers.NewChannelHandler),
+			provideServerHandler(handlers.NewInboundFailuresHandler),
+			provideServerHandler(handlers.NewChannelOutboxHandler),
+			provideServerHandler(handlers.NewAuditHandler),
+			provideServerHandler(handlers.NewFeatureFlagsHandler),
+			provideServerHandler(handlers.NewMaintenanceHandler),
+			provideServerHandler(handlers.NewChannelRoutesHandler),
 			provideServerHandler(provideUsersHandler),
 			provideServerHandler(handlers.NewMemoryProvidersHandler),
 			provideServerHandler(handlers.NewNetworkHandler),
 			provideServerHandler(handlers.NewAudioHandler),
 			provideServerHandler(handlers.NewVideoHandler),
 			provideServerHandler(handlers.NewBotAudioHandler),
+			provideVoiceService,
+			provideServerHandler(handlers.NewVoiceHandler),
+			provideServerHandler(handlers.NewPushDeviceHandler),
 			provideServerHandler(handlers.NewEmailProvidersHandler),
 			provideServerHandler(handlers.NewEmailBindingsHandler),
 			provideServerHandler(handlers.NewEmailOutboxHandler),
@@ -121,12 +153,23 @@ func commonOptions() fx.Option {
 			provideServerHandler(handlers.NewPluginsHandler),
 			provideServerHandler(handlers.NewBotBackupHandler),
 			provideServerHandler(handlers.NewTokenUsageHandler),
+			provideServerHandler(provideUsageReportHandler),
 			provideServerHandler(handlers.NewSessionInfoHandler),
 			provideServerHandler(handlers.NewSupermarketHandler),
 			provideServerHandler(provideWebHandler),
 			provideServer,
 		),
 		fx.Invoke(
+			startDataExportWorker,
+			startFeedsWorker,
+			startHistorySearchWorker,
+			startMessageTaggingWorker,
+			startMemoryExpiryWorker,
+			startMemoryReviewWorker,
+			configureMemoryCompactionSchedule,
+			configureGitHubChatTrigger,
+			configureBotMemoryCleanup,
+			stopVoiceCalls,
 			startServer,
 		),
 		fx.WithLogger(func(logger *slog.Logger) fxevent.Logger {
@@ -97,6 +97,12 @@ func (r *localFirstChannelRuntime) React(ctx context.Context, botID string, typ
 	return r.remote.React(ctx, botID, typ, req)
 }
 
+// Broadcast runs in the channel process, which owns the platform
+// connections. Web and CLI sessions are not broadcast targets.
+func (r *localFirstChannelRuntime) Broadcast(ctx context.Context, botID string, req channel.BroadcastRequest) (channel.BroadcastResult, error) {
+	return r.remote.Broadcast(ctx, botID, req)
+}
+
 func (r *localFirstChannelRuntime) UpsertBotChannelConfig(ctx context.Context, botID string, typ channel.ChannelType, req channel.UpsertConfigRequest) (channel.ChannelConfig, error) {
 	return r.remote.UpsertBotChannelConfig(ctx, botID, typ, req)
 }
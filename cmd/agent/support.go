@@ -3,21 +3,27 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"io/fs"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/crypto/bcrypt"
 
 	dbembed "github.com/memohai/memoh/db"
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
 	"github.com/memohai/memoh/internal/config"
 	"github.com/memohai/memoh/internal/db"
 	dbsqlc "github.com/memohai/memoh/internal/db/postgres/sqlc"
+	postgresstore "github.com/memohai/memoh/internal/db/postgres/store"
+	"github.com/memohai/memoh/internal/encryption"
 	"github.com/memohai/memoh/internal/logger"
+	"github.com/memohai/memoh/internal/maintenance"
 	"github.com/memohai/memoh/internal/version"
 )
 
@@ -53,6 +59,17 @@ func runMigrateCommand(args []string) error {
 		migrateArgs = args[1:]
 	}
 
+	switch migrateCmd {
+	case "preflight":
+		return runMigratePreflight(cfg, os.Stdout)
+	case "up":
+		// Report every missing privilege up front instead of failing partway
+		// through a migration on a restricted managed-database role.
+		if err := checkMigratePreflight(log, cfg); err != nil {
+			return err
+		}
+	}
+
 	if err := db.RunMigrateConfig(log, cfg, migrationsFS(cfg), migrateCmd, migrateArgs); err != nil {
 		log.Error("migration failed", slog.Any("error", err))
 		return err
@@ -60,6 +77,50 @@ func runMigrateCommand(args []string) error {
 	return nil
 }
 
+func checkMigratePreflight(log *slog.Logger, cfg config.Config) error {
+	if db.DriverFromConfig(cfg) != db.DriverPostgres {
+		return nil
+	}
+	report, err := db.PreflightPostgres(context.Background(), cfg.Postgres, db.PostgresExtensions...)
+	if err != nil {
+		return fmt.Errorf("preflight: %w", err)
+	}
+	for _, warning := range report.Warnings {
+		log.Warn("database preflight", slog.String("warning", warning))
+	}
+	return report.Err()
+}
+
+// runMigratePreflight prints whether the configured role can run the
+// migrations and, if not, the statements that grant what is missing.
+func runMigratePreflight(cfg config.Config, out io.Writer) error {
+	if db.DriverFromConfig(cfg) != db.DriverPostgres {
+		return fmt.Errorf("preflight: unsupported database driver %q", db.DriverFromConfig(cfg))
+	}
+	report, err := db.PreflightPostgres(context.Background(), cfg.Postgres, db.PostgresExtensions...)
+	if err != nil {
+		return fmt.Errorf("preflight: %w", err)
+	}
+	if _, err := fmt.Fprintf(out, "role %s on database %s (PostgreSQL %s)\n", report.Role, report.Database, report.ServerVersion); err != nil {
+		return err
+	}
+	for _, warning := range report.Warnings {
+		if _, err := fmt.Fprintf(out, "warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+	for _, m := range report.Missing {
+		if _, err := fmt.Fprintf(out, "missing: %s\n", m); err != nil {
+			return err
+		}
+	}
+	if report.OK() {
+		_, err := fmt.Fprintln(out, "ok: the role can run the migrations")
+		return err
+	}
+	return report.Err()
+}
+
 func runAccountCommand(args []string, passwordInput io.Reader) error {
 	if len(args) != 2 || args[0] != "recover-admin" {
 		return errors.New("usage: memoh-server account recover-admin <username-or-email> < new-password-file")
@@ -124,6 +185,133 @@ func runAccountCommand(args []string, passwordInput io.Reader) error {
 	return nil
 }
 
+// runMessagesCommand runs history maintenance. "repair" normalizes stored
+// message content and quarantines rows that fail validation.
+func runMessagesCommand(args []string, out io.Writer) error {
+	const usage = "usage: memoh-server messages repair [--bot <bot-id>] [--dry-run]"
+	if len(args) == 0 || args[0] != "repair" {
+		return errors.New(usage)
+	}
+	flags := flag.NewFlagSet("messages repair", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	botID := flags.String("bot", "", "only repair this bot's history")
+	dryRun := flags.Bool("dry-run", false, "report what would change without writing")
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() > 0 {
+		return errors.New(usage)
+	}
+
+	cfg, err := provideConfig()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	logger.Init(cfg.Log.Level, cfg.Log.Format)
+	ctx := context.Background()
+	pool, err := db.Open(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer pool.Close()
+	store, err := postgresstore.New(pool)
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+
+	service := messagepkg.NewService(logger.L, postgresstore.NewQueriesWithPool(store.Pool(), store.SQLC()))
+	if cfg.Encryption.Enabled() {
+		masterKey, err := encryption.ParseMasterKey(cfg.Encryption.MasterKey)
+		if err != nil {
+			return fmt.Errorf("encryption: %w", err)
+		}
+		keyring, err := encryption.NewKeyring(masterKey, store.SQLC())
+		if err != nil {
+			return fmt.Errorf("encryption: %w", err)
+		}
+		service.SetContentCipher(keyring)
+	}
+
+	result, err := service.RepairContent(ctx, messagepkg.RepairOptions{BotID: strings.TrimSpace(*botID), DryRun: *dryRun})
+	mode := "repaired"
+	if *dryRun {
+		mode = "dry run"
+	}
+	if _, werr := fmt.Fprintf(out, "%s: scanned=%d normalized=%d quarantined=%d unreadable=%d\n",
+		mode, result.Scanned, result.Normalized, result.Quarantined, result.Unreadable); werr != nil && err == nil {
+		err = werr
+	}
+	return err
+}
+
+// runMaintenanceCommand switches deployment-wide maintenance mode. Running
+// servers pick the change up within the maintenance cache window.
+func runMaintenanceCommand(args []string, out io.Writer) error {
+	const usage = "usage: memoh-server maintenance <on [--message <text>]|off|status>"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	flags := flag.NewFlagSet("maintenance "+args[0], flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	message := flags.String("message", "", "notice bots send while maintenance is on")
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() > 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "on", "off", "status":
+	default:
+		return errors.New(usage)
+	}
+	if args[0] != "on" && *message != "" {
+		return errors.New(usage)
+	}
+
+	cfg, err := provideConfig()
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	logger.Init(cfg.Log.Level, cfg.Log.Format)
+	ctx := context.Background()
+	pool, err := db.Open(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer pool.Close()
+	store, err := postgresstore.New(pool)
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+
+	service := maintenance.NewService(logger.L, postgresstore.NewQueriesWithPool(store.Pool(), store.SQLC()))
+	var state maintenance.State
+	switch args[0] {
+	case "on":
+		state, err = service.Enable(ctx, *message, "")
+	case "off":
+		state, err = service.Disable(ctx, "")
+	default:
+		state, err = service.Status(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return printMaintenanceState(out, state)
+}
+
+func printMaintenanceState(out io.Writer, state maintenance.State) error {
+	if !state.Enabled {
+		_, err := fmt.Fprintln(out, "maintenance mode: off")
+		return err
+	}
+	status := "maintenance mode: on"
+	if state.StartedAt != nil {
+		status += " since " + state.StartedAt.Format(time.RFC3339)
+	}
+	notice := state.Message
+	if notice == "" {
+		notice = "(default notice)"
+	}
+	_, err := fmt.Fprintf(out, "%s\nmessage: %s\n", status, notice)
+	return err
+}
+
 func runVersion() error {
 	fmt.Printf("memoh-server %s\n", version.GetInfo())
 	return nil
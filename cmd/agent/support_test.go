@@ -26,3 +26,42 @@ func TestRunAccountCommandValidatesRecoveryInputBeforeOpeningDatabase(t *testing
 		})
 	}
 }
+
+func TestRunMessagesCommandValidatesUsageBeforeOpeningDatabase(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/path/that/does/not/exist")
+
+	for name, args := range map[string][]string{
+		"empty":    nil,
+		"command":  {"unknown"},
+		"flag":     {"repair", "--force"},
+		"trailing": {"repair", "extra"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var out strings.Builder
+			err := runMessagesCommand(args, &out)
+			if err == nil || !strings.Contains(err.Error(), "usage:") {
+				t.Fatalf("runMessagesCommand(%v) error = %v, want usage", args, err)
+			}
+		})
+	}
+}
+
+func TestRunMaintenanceCommandValidatesUsageBeforeOpeningDatabase(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "/path/that/does/not/exist")
+
+	for name, args := range map[string][]string{
+		"empty":       nil,
+		"command":     {"pause"},
+		"flag":        {"on", "--force"},
+		"trailing":    {"off", "now"},
+		"off message": {"off", "--message", "bye"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var out strings.Builder
+			err := runMaintenanceCommand(args, &out)
+			if err == nil || !strings.Contains(err.Error(), "usage:") {
+				t.Fatalf("runMaintenanceCommand(%v) error = %v, want usage", args, err)
+			}
+		})
+	}
+}
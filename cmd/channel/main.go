@@ -25,17 +25,24 @@ import (
 	"github.com/memohai/memoh/internal/version"
 )
 
-type healthHandler struct{}
+// healthHandler serves liveness and the inbound queue depth, the first
+// thing to look at when replies lag behind.
+type healthHandler struct {
+	manager *channel.Manager
+}
 
-func newHealthHandler() *healthHandler { return &healthHandler{} }
+func newHealthHandler(manager *channel.Manager) *healthHandler {
+	return &healthHandler{manager: manager}
+}
 
-func (*healthHandler) Register(e *echo.Echo) {
+func (h *healthHandler) Register(e *echo.Echo) {
 	e.GET("/ping", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{
-			"status":      "ok",
-			"service":     "channel",
-			"version":     version.Version,
-			"commit_hash": version.ShortCommitHash(),
+		return c.JSON(http.StatusOK, map[string]any{
+			"status":        "ok",
+			"service":       "channel",
+			"version":       version.Version,
+			"commit_hash":   version.ShortCommitHash(),
+			"inbound_queue": h.manager.InboundQueueStats(),
 		})
 	})
 	e.HEAD("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
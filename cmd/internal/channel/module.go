@@ -7,6 +7,7 @@ import (
 	"github.com/memohai/memoh/internal/channel/adapters/local"
 	"github.com/memohai/memoh/internal/channel/identities"
 	"github.com/memohai/memoh/internal/channel/inbound"
+	"github.com/memohai/memoh/internal/channel/workhours"
 	emailpkg "github.com/memohai/memoh/internal/email"
 	"github.com/memohai/memoh/internal/rpc/serverruntime"
 	"github.com/memohai/memoh/internal/webhooktunnel"
@@ -20,11 +21,16 @@ func FoundationModule() fx.Option {
 	return fx.Options(
 		fx.Provide(
 			identities.NewService,
+			provideDeadletterService,
+			provideOutboxService,
+			workhours.NewService,
 			emailpkg.NewDBOAuthTokenStore,
 			provideEmailRegistry,
 			emailpkg.NewService,
 			emailpkg.NewOutboxService,
 			provideRouteService,
+			provideConversationFlows,
+			provideDirectorySync,
 			providePipeline,
 			provideEventStore,
 			provideDiscussDriver,
@@ -74,6 +80,8 @@ func RuntimeModule() fx.Option {
 		),
 		fx.Invoke(
 			startChannelManager,
+			startDeferredReplies,
+			startDirectorySync,
 			startEmailManager,
 			startWebhookTunnelListener,
 			startWebhookTunnel,
@@ -107,7 +115,10 @@ func EmbeddedModule() fx.Option {
 			webhooktunnel.NewManager,
 		),
 		fx.Invoke(
+			configureEmailTriggerMemory,
 			startChannelManager,
+			startDeferredReplies,
+			startDirectorySync,
 			startEmailManager,
 			startWebhookTunnelListener,
 			startWebhookTunnel,
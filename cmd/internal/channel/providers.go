@@ -4,6 +4,7 @@ package channel
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -23,29 +24,47 @@ import (
 	acpprofileadapter "github.com/memohai/memoh/internal/agent/adapter/acpprofile"
 	"github.com/memohai/memoh/internal/agent/context/compaction"
 	userinput "github.com/memohai/memoh/internal/agent/decision/input"
+	agentevent "github.com/memohai/memoh/internal/agent/event"
 	"github.com/memohai/memoh/internal/agent/turn"
 	audiopkg "github.com/memohai/memoh/internal/audio"
 	"github.com/memohai/memoh/internal/auth"
 	"github.com/memohai/memoh/internal/bots"
 	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/adapters/botlink"
 	"github.com/memohai/memoh/internal/channel/adapters/dingtalk"
 	"github.com/memohai/memoh/internal/channel/adapters/discord"
 	"github.com/memohai/memoh/internal/channel/adapters/feishu"
+	"github.com/memohai/memoh/internal/channel/adapters/gotify"
 	"github.com/memohai/memoh/internal/channel/adapters/line"
 	"github.com/memohai/memoh/internal/channel/adapters/local"
 	"github.com/memohai/memoh/internal/channel/adapters/matrix"
 	"github.com/memohai/memoh/internal/channel/adapters/misskey"
+	"github.com/memohai/memoh/internal/channel/adapters/mqtt"
+	"github.com/memohai/memoh/internal/channel/adapters/ntfy"
+	pushadapter "github.com/memohai/memoh/internal/channel/adapters/push"
 	"github.com/memohai/memoh/internal/channel/adapters/qq"
 	slackadapter "github.com/memohai/memoh/internal/channel/adapters/slack"
 	"github.com/memohai/memoh/internal/channel/adapters/telegram"
+	"github.com/memohai/memoh/internal/channel/adapters/webhook"
 	"github.com/memohai/memoh/internal/channel/adapters/wechatoa"
 	"github.com/memohai/memoh/internal/channel/adapters/wecom"
 	"github.com/memohai/memoh/internal/channel/adapters/weixin"
+	"github.com/memohai/memoh/internal/channel/adapters/whatsapp"
+	"github.com/memohai/memoh/internal/channel/deadletter"
+	"github.com/memohai/memoh/internal/channel/directorysync"
 	"github.com/memohai/memoh/internal/channel/discuss"
+	"github.com/memohai/memoh/internal/channel/flows"
+	"github.com/memohai/memoh/internal/channel/groupclaim"
 	"github.com/memohai/memoh/internal/channel/identities"
 	"github.com/memohai/memoh/internal/channel/inbound"
+	"github.com/memohai/memoh/internal/channel/mentions"
+	"github.com/memohai/memoh/internal/channel/onboarding"
+	"github.com/memohai/memoh/internal/channel/outbox"
+	"github.com/memohai/memoh/internal/channel/postprocess"
 	"github.com/memohai/memoh/internal/channel/publicmedia"
 	"github.com/memohai/memoh/internal/channel/route"
+	"github.com/memohai/memoh/internal/channel/unfurl"
+	"github.com/memohai/memoh/internal/channel/workhours"
 	"github.com/memohai/memoh/internal/channelaccess"
 	"github.com/memohai/memoh/internal/chat/message"
 	sessionpkg "github.com/memohai/memoh/internal/chat/thread"
@@ -58,8 +77,12 @@ import (
 	emailgeneric "github.com/memohai/memoh/internal/email/adapters/generic"
 	emailgmail "github.com/memohai/memoh/internal/email/adapters/gmail"
 	emailmailgun "github.com/memohai/memoh/internal/email/adapters/mailgun"
+	"github.com/memohai/memoh/internal/encryption"
+	"github.com/memohai/memoh/internal/extension"
+	"github.com/memohai/memoh/internal/featureflags"
 	"github.com/memohai/memoh/internal/handlers"
 	"github.com/memohai/memoh/internal/heartbeat"
+	"github.com/memohai/memoh/internal/maintenance"
 	"github.com/memohai/memoh/internal/mcp"
 	"github.com/memohai/memoh/internal/media"
 	memprovider "github.com/memohai/memoh/internal/memory/adapters"
@@ -67,9 +90,12 @@ import (
 	"github.com/memohai/memoh/internal/oauthclients"
 	"github.com/memohai/memoh/internal/policy"
 	"github.com/memohai/memoh/internal/providers"
+	pushpkg "github.com/memohai/memoh/internal/push"
+	runtimeRpc "github.com/memohai/memoh/internal/rpc/runtime"
 	"github.com/memohai/memoh/internal/schedule"
 	"github.com/memohai/memoh/internal/searchproviders"
 	"github.com/memohai/memoh/internal/settings"
+	"github.com/memohai/memoh/internal/sharedstate"
 	"github.com/memohai/memoh/internal/storage/providers/localfs"
 	"github.com/memohai/memoh/internal/team"
 	"github.com/memohai/memoh/internal/webhooktunnel"
@@ -100,8 +126,34 @@ func provideDiscussDriver(log *slog.Logger, eventStore *timeline.EventStore, msg
 	})
 }
 
-func provideRouteService(log *slog.Logger, queries dbstore.Queries) *route.DBService {
-	return route.NewService(log, queries)
+func provideRouteService(log *slog.Logger, cfg config.Config, queries dbstore.Queries) *route.DBService {
+	service := route.NewService(log, queries)
+	service.SetInactiveRetention(time.Duration(cfg.Channel.InactiveRouteRetentionDays) * 24 * time.Hour)
+	return service
+}
+
+func provideDeadletterService(log *slog.Logger, queries dbstore.Queries, keyring *encryption.Keyring) *deadletter.Service {
+	service := deadletter.NewService(log, queries)
+	if keyring != nil {
+		service.SetContentCipher(keyring)
+	}
+	return service
+}
+
+func provideOutboxService(log *slog.Logger, queries dbstore.Queries, keyring *encryption.Keyring) *outbox.Service {
+	service := outbox.NewService(log, queries)
+	if keyring != nil {
+		service.SetContentCipher(keyring)
+	}
+	return service
+}
+
+func provideDirectorySync(log *slog.Logger, registry *channel.Registry, store *channel.Store, routeService *route.DBService, identityService *identities.Service) *directorysync.Service {
+	return directorysync.NewService(log, registry, store, routeService, identityService)
+}
+
+func provideConversationFlows(log *slog.Logger, queries dbstore.Queries, routeService *route.DBService) *flows.Service {
+	return flows.NewService(log, queries, routeService)
 }
 
 type channelRegistryParams struct {
@@ -111,6 +163,7 @@ type channelRegistryParams struct {
 	Config        config.Config
 	Hub           *local.RouteHub
 	MediaService  *media.Service
+	PushService   *pushpkg.Service
 	TunnelManager *webhooktunnel.Manager `optional:"true"`
 	UserInput     *userinput.Service
 }
@@ -120,6 +173,7 @@ func provideChannelRegistry(params channelRegistryParams) *channel.Registry {
 	cfg := params.Config
 	hub := params.Hub
 	mediaService := params.MediaService
+	pushService := params.PushService
 	tunnelManager := params.TunnelManager
 	userInput := params.UserInput
 	registry := channel.NewRegistry()
@@ -158,12 +212,19 @@ func provideChannelRegistry(params channelRegistryParams) *channel.Registry {
 	lineAdapter := line.NewAdapter(log)
 	lineAdapter.SetPublicBaseURLProvider(newPublicMediaBaseProvider(cfg, tunnelManager))
 	registry.MustRegister(lineAdapter)
+	registry.MustRegister(whatsapp.NewAdapter(log))
+	registry.MustRegister(webhook.NewAdapter(log))
 
 	weixinAdapter := weixin.NewWeixinAdapter(log)
 	weixinAdapter.SetAssetOpener(mediaService)
 	registry.MustRegister(weixinAdapter)
 	registry.MustRegister(local.NewWebAdapter(hub))
 	registry.MustRegister(misskey.NewMisskeyAdapter(log))
+	registry.MustRegister(botlink.NewAdapter(log))
+	registry.MustRegister(pushadapter.NewAdapter(pushService))
+	registry.MustRegister(ntfy.NewAdapter(log))
+	registry.MustRegister(gotify.NewAdapter(log))
+	registry.MustRegister(mqtt.NewAdapter(log))
 
 	return registry
 }
@@ -219,6 +280,10 @@ func provideChannelRouter(
 	cfg config.Config,
 	cmdHandler inbound.CommandHandler,
 	skillResolver inbound.RequestedSkillResolver,
+	workingHours *workhours.Service,
+	conversationFlows *flows.Service,
+	maintenanceService *maintenance.Service,
+	queries dbstore.Queries,
 ) *inbound.ChannelInboundProcessor {
 	adapter, ok := registry.Get(qq.Type)
 	if !ok {
@@ -234,6 +299,7 @@ func provideChannelRouter(
 	threadCoordinator := route.NewThreadCoordinator(log, routeService, sessionService)
 	processor := inbound.NewChannelInboundProcessor(log, registry, routeService, msgService, turnService, identityService, policyService, cfg.Auth.JWTSecret, 5*time.Minute)
 	processor.SetSessionEnsurer(&sessionEnsurerAdapter{coordinator: threadCoordinator})
+	processor.SetRouteLifecycle(routeService)
 	processor.SetPipeline(pipeline, eventStore, discussDriver)
 	discussDriver.SetTurnService(turnService)
 	discussDriver.SetBroadcaster(hub)
@@ -241,6 +307,7 @@ func provideChannelRouter(
 	processor.SetMediaService(mediaService)
 	processor.SetStreamObserver(local.NewRouteHubBroadcaster(hub))
 	processor.SetDispatcher(inbound.NewRouteDispatcher(log))
+	processor.SetTurnScheduler(inbound.NewTurnScheduler(cfg.Agent.MaxConcurrentTurnsPerBot))
 	processor.SetSpeechService(audioService, &settingsSpeechModelResolver{settings: settingsService})
 	processor.SetTranscriptionService(audioService, &settingsTranscriptionModelResolver{settings: settingsService})
 	processor.SetIMDisplayOptions(&settingsIMDisplayOptions{settings: settingsService})
@@ -250,6 +317,18 @@ func provideChannelRouter(
 	processor.SetBotPermissionChecker(&botPermissionCheckerAdapter{bots: botService, accounts: accountService})
 	processor.SetCommandHandler(cmdHandler)
 	processor.SetRequestedSkillResolver(skillResolver)
+	processor.SetGroupReplyClaimer(groupclaim.NewService(log, queries))
+	processor.SetOnboarder(onboarding.NewService(log, queries))
+	processor.SetOutputPipelines(postprocess.NewService(log, queries))
+	processor.SetWorkingHours(workingHours)
+	processor.SetMaintenance(maintenanceService)
+	processor.SetInboundRateLimits(&settingsInboundRateLimits{settings: settingsService})
+	processor.SetWakeWords(&settingsWakeWords{settings: settingsService})
+	processor.SetConversationFlows(conversationFlows)
+	if !cfg.Offline.Enabled {
+		processor.SetLinkPreviewer(unfurl.NewService(log))
+	}
+	processor.SetMentionResolver(mentions.NewResolver(log, registry, identityService))
 	return processor
 }
 
@@ -263,6 +342,7 @@ func provideCommandHandler(
 	modelsService *models.Service,
 	providersService *providers.Service,
 	memProvService *memprovider.Service,
+	memoryRegistry *memprovider.Registry,
 	searchProvService *searchproviders.Service,
 	emailService *emailpkg.Service,
 	emailOutboxService *emailpkg.OutboxService,
@@ -293,22 +373,46 @@ func provideCommandHandler(
 	)
 	cmdHandler.SetCompactionService(compactionService, queries)
 	cmdHandler.SetLinkConsumer(channelAccessService)
+	cmdHandler.SetMemoryRegistry(memoryRegistry)
 	return cmdHandler
 }
 
-func provideChannelManager(log *slog.Logger, registry *channel.Registry, channelStore *channel.Store, channelRouter *inbound.ChannelInboundProcessor, mediaService *media.Service) *channel.Manager {
+func provideChannelManager(log *slog.Logger, cfg config.Config, registry *channel.Registry, channelStore *channel.Store, channelRouter *inbound.ChannelInboundProcessor, mediaService *media.Service, inboundFailures *deadletter.Service, outboxService *outbox.Service, featureFlags *featureflags.Service, extensions *extension.Chain, routeService *route.DBService, sharedState sharedstate.Store) (*channel.Manager, error) {
 	if adapter, ok := registry.Get(matrix.Type); ok {
 		if matrixAdapter, ok := adapter.(*matrix.MatrixAdapter); ok {
 			matrixAdapter.SetSyncStateSaver(channelStore.SaveMatrixSyncSinceToken)
 		}
 	}
-	mgr := channel.NewManager(log, registry, channelStore, channelRouter)
+	mgr := channel.NewManager(log, registry, channelStore, channelRouter,
+		channel.WithInboundWorkers(cfg.Channel.InboundWorkers),
+		channel.WithInboundQueueSize(cfg.Channel.InboundQueueSize, cfg.Channel.InboundConversationQueueSize),
+	)
 	mgr.SetAttachmentStore(mediaService)
+	mgr.SetInboundFailureRecorder(inboundFailures)
+	mgr.SetOutbox(outboxService)
+	mgr.SetFeatureFlags(featureFlags)
+	mgr.SetExtensions(extensions)
+	mgr.SetBroadcastTargets(&broadcastRouteAdapter{routes: routeService, registry: registry})
+	mgr.SetSharedState(sharedState)
+	channelRouter.SetSharedState(sharedState)
+	if maxEvents := cfg.Channel.InboundBufferMaxEvents; maxEvents > 0 {
+		buffer, err := channel.NewInboundBuffer(log, channel.InboundBufferOptions{
+			Dir:       cfg.Channel.InboundBufferPath(),
+			MaxEvents: maxEvents,
+			IsOutage: func(err error) bool {
+				return channel.IsDownstreamUnavailable(err) || errors.Is(err, runtimeRpc.ErrUnavailable)
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		mgr.SetInboundBuffer(buffer)
+	}
 	if mw := channelRouter.IdentityMiddleware(); mw != nil {
 		mgr.Use(mw)
 	}
 	channelRouter.SetReactor(mgr)
-	return mgr
+	return mgr, nil
 }
 
 func provideChannelLifecycleService(channelStore *channel.Store, channelManager *channel.Manager) *channel.Lifecycle {
@@ -373,6 +477,38 @@ func startWebhookTunnelListener(lc fx.Lifecycle, log *slog.Logger, cfg config.Co
 	})
 }
 
+// broadcastRouteAdapter offers a bot's active routes as broadcast targets.
+// Web and CLI routes are sessions of the local surfaces rather than
+// platform conversations, so they are left out.
+type broadcastRouteAdapter struct {
+	routes   *route.DBService
+	registry *channel.Registry
+}
+
+func (a *broadcastRouteAdapter) ListBroadcastTargets(ctx context.Context, botID string) ([]channel.BroadcastTarget, error) {
+	routes, err := a.routes.List(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]channel.BroadcastTarget, 0, len(routes))
+	for _, r := range routes {
+		if r.Status == route.StatusInactive {
+			continue
+		}
+		channelType, err := a.registry.ParseChannelType(r.Platform)
+		if err != nil || channelType == local.WebType || channelType == local.CLIType {
+			continue
+		}
+		targets = append(targets, channel.BroadcastTarget{
+			RouteID:          r.ID,
+			ChannelType:      channelType,
+			Target:           r.ReplyTarget,
+			ConversationType: r.ConversationType,
+		})
+	}
+	return targets, nil
+}
+
 type sessionEnsurerAdapter struct {
 	coordinator *route.ThreadCoordinator
 }
@@ -469,6 +605,34 @@ func (r *settingsIMDisplayOptions) ShowToolCallsInIM(ctx context.Context, botID
 	return s.ShowToolCallsInIM, nil
 }
 
+type settingsInboundRateLimits struct {
+	settings channelSettings
+}
+
+func (r *settingsInboundRateLimits) InboundRateLimit(ctx context.Context, botID string) (int, int, error) {
+	s, err := r.settings.GetBot(ctx, botID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return s.InboundRateLimit, s.InboundRateBurst, nil
+}
+
+type settingsWakeWords struct {
+	settings channelSettings
+}
+
+func (r *settingsWakeWords) WakeWords(ctx context.Context, botID string) (inbound.WakeWordSettings, error) {
+	s, err := r.settings.GetBot(ctx, botID)
+	if err != nil {
+		return inbound.WakeWordSettings{}, err
+	}
+	return inbound.WakeWordSettings{
+		Enabled:  s.WakeWordsEnabled,
+		Words:    s.WakeWords,
+		Cooldown: time.Duration(s.WakeWordCooldownSeconds) * time.Second,
+	}, nil
+}
+
 type settingsDefaultChatRuntime struct {
 	settings channelSettings
 }
@@ -510,11 +674,11 @@ func (a *botPermissionCheckerAdapter) HasBotPermission(ctx context.Context, botI
 	if a == nil || a.bots == nil || a.accounts == nil {
 		return false, errors.New("bot permission services not configured")
 	}
-	isAdmin, err := a.accounts.IsAdmin(ctx, accountID)
+	manageAll, err := policy.Allowed(ctx, a.accounts, accountID, policy.PermissionBotsManageAll)
 	if err != nil {
 		return false, err
 	}
-	perms, err := a.bots.ResolveUserPermissions(ctx, botID, accountID, isAdmin)
+	perms, err := a.bots.ResolveUserPermissions(ctx, botID, accountID, manageAll)
 	if err != nil {
 		return false, err
 	}
@@ -581,17 +745,27 @@ func provideEmailRegistry(log *slog.Logger, tokenStore *emailpkg.DBOAuthTokenSto
 }
 
 func provideEmailChatGateway(turnService turn.Service, queries dbstore.Queries, cfg config.Config, log *slog.Logger) emailpkg.ChatTriggerer {
-	return &emailTurnGateway{turnService: turnService, queries: queries, jwtSecret: cfg.Auth.JWTSecret, logger: log}
+	return NewTurnGateway(turnService, queries, cfg.Auth.JWTSecret, log, "email")
+}
+
+// TurnGateway starts a chat turn on behalf of a bot's owner for inbound
+// events that arrive outside a channel conversation, such as new email or
+// GitHub webhooks, and for callers of channels the server answers itself,
+// such as voice calls. CurrentChannel tells the agent where the turn came
+// from.
+type TurnGateway struct {
+	turnService    turn.Service
+	queries        dbstore.Queries
+	jwtSecret      string
+	currentChannel string
+	logger         *slog.Logger
 }
 
-type emailTurnGateway struct {
-	turnService turn.Service
-	queries     dbstore.Queries
-	jwtSecret   string
-	logger      *slog.Logger
+func NewTurnGateway(turnService turn.Service, queries dbstore.Queries, jwtSecret string, log *slog.Logger, currentChannel string) *TurnGateway {
+	return &TurnGateway{turnService: turnService, queries: queries, jwtSecret: jwtSecret, currentChannel: currentChannel, logger: log}
 }
 
-func (g *emailTurnGateway) TriggerBotChat(ctx context.Context, botID, content string) error {
+func (g *TurnGateway) TriggerBotChat(ctx context.Context, botID, content string) error {
 	pgBotID, err := db.ParseUUID(botID)
 	if err != nil {
 		return err
@@ -600,10 +774,29 @@ func (g *emailTurnGateway) TriggerBotChat(ctx context.Context, botID, content st
 	if err != nil {
 		return fmt.Errorf("get bot: %w", err)
 	}
-	ownerID := bot.OwnerUserID.String()
-	token, _, err := auth.GenerateToken(ownerID, g.jwtSecret, 10*time.Minute)
+	return g.run(ctx, botID, bot.OwnerUserID.String(), content, nil)
+}
+
+// StreamReply runs a turn on behalf of userID and streams the reply text to
+// onText, for channels that answer the user directly such as voice calls.
+func (g *TurnGateway) StreamReply(ctx context.Context, botID, userID, query string, onText func(delta string)) error {
+	return g.run(ctx, botID, userID, query, func(ev turn.Event) {
+		if ev.Kind != string(agentevent.TextDelta) {
+			return
+		}
+		var chunk struct {
+			Delta string `json:"delta"`
+		}
+		if err := json.Unmarshal(ev.Payload, &chunk); err == nil && chunk.Delta != "" {
+			onText(chunk.Delta)
+		}
+	})
+}
+
+func (g *TurnGateway) run(ctx context.Context, botID, userID, content string, onEvent func(turn.Event)) error {
+	token, _, err := auth.GenerateToken(userID, g.jwtSecret, 10*time.Minute)
 	if err != nil {
-		return fmt.Errorf("generate email turn token: %w", err)
+		return fmt.Errorf("generate %s turn token: %w", g.currentChannel, err)
 	}
 	handle, err := g.turnService.StartTurn(ctx, turn.StartTurnCommand{
 		SchemaVersion:  1,
@@ -611,21 +804,25 @@ func (g *emailTurnGateway) TriggerBotChat(ctx context.Context, botID, content st
 		Mode:           turn.ModeChat,
 		BotID:          botID,
 		ChatID:         botID,
-		UserID:         ownerID,
+		UserID:         userID,
 		Token:          "Bearer " + token,
 		Query:          content,
-		CurrentChannel: "email",
+		CurrentChannel: g.currentChannel,
 	})
 	if err != nil {
-		return fmt.Errorf("start email turn: %w", err)
+		return fmt.Errorf("start %s turn: %w", g.currentChannel, err)
 	}
 	defer handle.Cancel()
 	events, errs := handle.Events(), handle.Errs()
 	for events != nil || errs != nil {
 		select {
-		case _, ok := <-events:
+		case ev, ok := <-events:
 			if !ok {
 				events = nil
+				continue
+			}
+			if onEvent != nil {
+				onEvent(ev)
 			}
 		case runErr, ok := <-errs:
 			if ok && runErr != nil {
@@ -645,6 +842,14 @@ func provideEmailTrigger(log *slog.Logger, service *emailpkg.Service, chatTrigge
 	return emailpkg.NewTrigger(log, service, chatTriggerer)
 }
 
+// configureEmailTriggerMemory lets bindings save inbound mail to memory. Only
+// the embedded runtime has the memory providers; the standalone channel
+// process leaves them unset.
+func configureEmailTriggerMemory(trigger *emailpkg.Trigger, memoryRegistry *memprovider.Registry, settingsService *settings.Service) {
+	trigger.SetMemoryRegistry(memoryRegistry)
+	trigger.SetSettingsService(settingsService)
+}
+
 func startEmailManager(lc fx.Lifecycle, emailManager *emailpkg.Manager) {
 	ctx, cancel := context.WithCancel(context.Background())
 	lc.Append(fx.Hook{
@@ -678,6 +883,38 @@ func startChannelManager(lc fx.Lifecycle, channelManager *channel.Manager) {
 	})
 }
 
+// startDeferredReplies replays messages held outside working hours once the
+// bot opens again.
+func startDeferredReplies(lc fx.Lifecycle, service *workhours.Service, channelManager *channel.Manager) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(ctx, channelManager)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// startDirectorySync refreshes contact profiles from platform directories
+// on the configured interval.
+func startDirectorySync(lc fx.Lifecycle, cfg config.Config, service *directorysync.Service) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(ctx, time.Duration(cfg.Channel.DirectorySyncIntervalHours)*time.Hour)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
 type commandSkillLoaderAdapter struct {
 	handler *handlers.ContainerdHandler
 }
@@ -4,15 +4,20 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/memohai/memoh/internal/acl"
-	"github.com/memohai/memoh/internal/agent/context/compaction"
 	userinput "github.com/memohai/memoh/internal/agent/decision/input"
 	audiopkg "github.com/memohai/memoh/internal/audio"
+	"github.com/memohai/memoh/internal/audit"
 	"github.com/memohai/memoh/internal/boot"
 	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel/postprocess"
+	"github.com/memohai/memoh/internal/channel/stickers"
 	"github.com/memohai/memoh/internal/channelaccess"
 	"github.com/memohai/memoh/internal/chat/event"
+	"github.com/memohai/memoh/internal/featureflags"
 	"github.com/memohai/memoh/internal/fetchproviders"
 	"github.com/memohai/memoh/internal/heartbeat"
+	"github.com/memohai/memoh/internal/httptools"
+	"github.com/memohai/memoh/internal/maintenance"
 	"github.com/memohai/memoh/internal/mcp"
 	memprovider "github.com/memohai/memoh/internal/memory/adapters"
 	"github.com/memohai/memoh/internal/models"
@@ -37,6 +42,7 @@ func FoundationModule() fx.Option {
 			provideLogger,
 			provideDBConn,
 			providePostgresStore,
+			provideKeyring,
 			provideDBQueries,
 			provideAccountStore,
 			bots.NewService,
@@ -45,11 +51,17 @@ func FoundationModule() fx.Option {
 			channelaccess.NewService,
 			userinput.NewService,
 			policy.NewService,
+			featureflags.NewService,
+			maintenance.NewService,
+			provideExtensions,
 			oauthclients.NewRegistry,
 			event.NewHub,
 			provideSessionService,
 			provideMessageService,
+			providePushService,
+			provideSharedState,
 		),
+		fx.Invoke(startTelemetry),
 	)
 }
 
@@ -65,6 +77,7 @@ func ServerModule() fx.Option {
 			provideNetworkService,
 			provideNetworkController,
 			settings.NewService,
+			audit.NewService,
 			provideToolApprovalService,
 			providePGVectorStore,
 			provideUserRuntimeStore,
@@ -79,7 +92,12 @@ func ServerModule() fx.Option {
 			providePluginBridgeProvider,
 			provideMemoryLLM,
 			memprovider.NewService,
+			provideKnowledgeService,
+			provideGitHubService,
 			provideMemoryProviderRegistry,
+			provideMemoryFlagService,
+			postprocess.NewService,
+			stickers.NewService,
 			models.NewService,
 			provideACPRunner,
 			provideACPSessionPool,
@@ -91,6 +109,7 @@ func ServerModule() fx.Option {
 			fetchproviders.NewService,
 			searchproviders.NewService,
 			mcp.NewConnectionService,
+			httptools.NewService,
 			pluginspkg.NewService,
 			mcp.NewToolSessionContextStore,
 			provideAudioRegistry,
@@ -108,7 +127,8 @@ func ServerModule() fx.Option {
 			schedule.NewService,
 			provideHeartbeatTriggerer,
 			heartbeat.NewService,
-			compaction.NewService,
+			provideCompactionService,
+			provideMessageArchiveService,
 			provideContainerdHandler,
 			provideBotBackupService,
 			provideFederationGateway,
@@ -122,11 +142,16 @@ func ServerModule() fx.Option {
 			injectToolProviders,
 			injectACPToolProviders,
 			configureMemoryProviderRegistry,
+			configureScheduleMaintenance,
 			startProviderTemplateSync,
+			validateOfflineEndpoints,
 			startScheduleService,
 			startHeartbeatService,
 			startContainerReconciliation,
 			startBackgroundTaskCleanup,
+			startKnowledgeReprocessWorker,
+			startGitHubSyncWorker,
+			startMessageArchive,
 			startAudioTempStoreCleanup,
 		),
 	)
@@ -26,6 +26,7 @@ import (
 	channelcontactadapter "github.com/memohai/memoh/internal/agent/adapter/channelcontact"
 	channelidentityadapter "github.com/memohai/memoh/internal/agent/adapter/channelidentity"
 	channelmessagingadapter "github.com/memohai/memoh/internal/agent/adapter/channelmessaging"
+	channelstickeradapter "github.com/memohai/memoh/internal/agent/adapter/channelsticker"
 	channelthreadadapter "github.com/memohai/memoh/internal/agent/adapter/channelthread"
 	"github.com/memohai/memoh/internal/agent/application"
 	"github.com/memohai/memoh/internal/agent/background"
@@ -43,7 +44,10 @@ import (
 	"github.com/memohai/memoh/internal/botbackup"
 	"github.com/memohai/memoh/internal/bots"
 	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/onboarding"
 	"github.com/memohai/memoh/internal/channel/route"
+	"github.com/memohai/memoh/internal/channel/stickers"
+	msgarchive "github.com/memohai/memoh/internal/chat/archive"
 	"github.com/memohai/memoh/internal/chat/event"
 	"github.com/memohai/memoh/internal/chat/message"
 	sessionpkg "github.com/memohai/memoh/internal/chat/thread"
@@ -56,11 +60,21 @@ import (
 	postgresstore "github.com/memohai/memoh/internal/db/postgres/store"
 	dbstore "github.com/memohai/memoh/internal/db/store"
 	emailpkg "github.com/memohai/memoh/internal/email"
+	"github.com/memohai/memoh/internal/encryption"
+	"github.com/memohai/memoh/internal/extension"
+	"github.com/memohai/memoh/internal/featureflags"
 	"github.com/memohai/memoh/internal/fetchproviders"
+	githubpkg "github.com/memohai/memoh/internal/github"
 	"github.com/memohai/memoh/internal/handlers"
 	"github.com/memohai/memoh/internal/heartbeat"
 	hookspkg "github.com/memohai/memoh/internal/hooks"
+	"github.com/memohai/memoh/internal/httptools"
+	"github.com/memohai/memoh/internal/knowledge"
+	knowledgeconfluence "github.com/memohai/memoh/internal/knowledge/connectors/confluence"
+	knowledgegdrive "github.com/memohai/memoh/internal/knowledge/connectors/gdrive"
+	knowledgenotion "github.com/memohai/memoh/internal/knowledge/connectors/notion"
 	"github.com/memohai/memoh/internal/logger"
+	"github.com/memohai/memoh/internal/maintenance"
 	"github.com/memohai/memoh/internal/mcp"
 	mcpfederation "github.com/memohai/memoh/internal/mcp/sources/federation"
 	"github.com/memohai/memoh/internal/media"
@@ -68,6 +82,7 @@ import (
 	membuiltin "github.com/memohai/memoh/internal/memory/adapters/builtin"
 	memmem0 "github.com/memohai/memoh/internal/memory/adapters/mem0"
 	memopenviking "github.com/memohai/memoh/internal/memory/adapters/openviking"
+	memflags "github.com/memohai/memoh/internal/memory/flags"
 	"github.com/memohai/memoh/internal/memory/memllm"
 	storefs "github.com/memohai/memoh/internal/memory/storefs"
 	"github.com/memohai/memoh/internal/memory/wikistore"
@@ -75,18 +90,22 @@ import (
 	"github.com/memohai/memoh/internal/models"
 	netctl "github.com/memohai/memoh/internal/network"
 	netoverlay "github.com/memohai/memoh/internal/network/overlay"
+	"github.com/memohai/memoh/internal/oauthclients"
 	pluginspkg "github.com/memohai/memoh/internal/plugins"
 	"github.com/memohai/memoh/internal/policy"
 	"github.com/memohai/memoh/internal/providers"
 	"github.com/memohai/memoh/internal/providertemplates"
+	pushpkg "github.com/memohai/memoh/internal/push"
 	"github.com/memohai/memoh/internal/registry"
 	"github.com/memohai/memoh/internal/schedule"
 	"github.com/memohai/memoh/internal/searchproviders"
 	"github.com/memohai/memoh/internal/settings"
+	"github.com/memohai/memoh/internal/sharedstate"
 	"github.com/memohai/memoh/internal/storage/providers/containerfs"
 	"github.com/memohai/memoh/internal/storage/providers/fallback"
 	"github.com/memohai/memoh/internal/storage/providers/localfs"
 	"github.com/memohai/memoh/internal/team"
+	"github.com/memohai/memoh/internal/telemetry"
 	"github.com/memohai/memoh/internal/userruntime"
 	videopkg "github.com/memohai/memoh/internal/video"
 	"github.com/memohai/memoh/internal/workspace"
@@ -98,11 +117,27 @@ func provideLogger(cfg config.Config) *slog.Logger {
 	return logger.L
 }
 
+func startTelemetry(lc fx.Lifecycle, log *slog.Logger, cfg config.Config) error {
+	shutdown, err := telemetry.Setup(context.Background(), log, cfg.Telemetry)
+	if err != nil {
+		return fmt.Errorf("setup telemetry: %w", err)
+	}
+	lc.Append(fx.Hook{OnStop: shutdown})
+	return nil
+}
+
 func provideContainerService(lc fx.Lifecycle, log *slog.Logger, cfg config.Config, rc *boot.RuntimeConfig) (ctr.Service, error) {
 	svc, cleanup, err := containerprovider.ProvideService(context.Background(), log, cfg, rc.ContainerBackend)
 	if err != nil {
 		return nil, err
 	}
+	if checker, ok := svc.(ctr.HealthChecker); ok {
+		// The runtime is optional at boot: bots without a workspace keep
+		// working, and reconciliation waits for it in the background.
+		if err := boot.WaitFor(context.Background(), log, rc.ContainerBackend, cfg.Startup.DependencyWaitDuration(), checker.Ping); err != nil {
+			log.Warn("container runtime unavailable; starting without it", slog.Any("error", err))
+		}
+	}
 	lc.Append(fx.Hook{
 		OnStop: func(_ context.Context) error {
 			cleanup()
@@ -112,6 +147,44 @@ func provideContainerService(lc fx.Lifecycle, log *slog.Logger, cfg config.Confi
 	return svc, nil
 }
 
+// provideExtensions chains the compiled-in extensions with the configured
+// plugins. Plugin connections are lazy, so a plugin that is down does not
+// block startup.
+func provideExtensions(lc fx.Lifecycle, log *slog.Logger, cfg config.Config) (*extension.Chain, error) {
+	exts := extension.Registered()
+	for _, pluginCfg := range cfg.Extensions.Plugins {
+		timeout, err := time.ParseDuration(pluginCfg.TimeoutOrDefault())
+		if err != nil {
+			return nil, fmt.Errorf("extension plugin %s: %w", pluginCfg.Name, err)
+		}
+		plugin, err := extension.DialPlugin(extension.PluginOptions{
+			Name:    pluginCfg.Name,
+			Target:  pluginCfg.Target,
+			Timeout: timeout,
+			Hooks:   pluginCfg.Hooks,
+		})
+		if err != nil {
+			_ = extension.NewChain(log, exts...).Close()
+			return nil, err
+		}
+		exts = append(exts, plugin)
+	}
+	chain := extension.NewChain(log, exts...)
+	if len(exts) > 0 {
+		names := make([]string, 0, len(exts))
+		for _, ext := range exts {
+			names = append(names, ext.Name())
+		}
+		log.Info("extensions enabled", slog.Any("extensions", names))
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return chain.Close()
+		},
+	})
+	return chain, nil
+}
+
 func provideNetworkController(service ctr.Service, rc *boot.RuntimeConfig, networkService *netctl.Service, registry *netctl.Registry) netctl.Controller {
 	runtime := netctl.NewContainerRuntimeFromBackend(rc.ContainerBackend, service)
 	ctrl := netctl.NewController(runtime, networkService, registry)
@@ -119,7 +192,7 @@ func provideNetworkController(service ctr.Service, rc *boot.RuntimeConfig, netwo
 	return ctrl
 }
 
-func provideDBConn(lc fx.Lifecycle, cfg config.Config) (*pgxpool.Pool, error) {
+func provideDBConn(lc fx.Lifecycle, log *slog.Logger, cfg config.Config) (*pgxpool.Pool, error) {
 	conn, err := db.Open(context.Background(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("db connect: %w", err)
@@ -127,6 +200,10 @@ func provideDBConn(lc fx.Lifecycle, cfg config.Config) (*pgxpool.Pool, error) {
 	if conn == nil {
 		return nil, nil
 	}
+	if err := boot.WaitFor(context.Background(), log, "postgres", cfg.Startup.DependencyWaitDuration(), conn.Ping); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("db connect: %w", err)
+	}
 	lc.Append(fx.Hook{
 		OnStop: func(_ context.Context) error {
 			conn.Close()
@@ -136,17 +213,57 @@ func provideDBConn(lc fx.Lifecycle, cfg config.Config) (*pgxpool.Pool, error) {
 	return conn, nil
 }
 
+func provideSharedState(lc fx.Lifecycle, log *slog.Logger, cfg config.Config) (sharedstate.Store, error) {
+	store, err := sharedstate.New(log, cfg.Redis)
+	if err != nil {
+		return nil, fmt.Errorf("shared state: %w", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return store.Close()
+		},
+	})
+	return store, nil
+}
+
+// dependencyRetryInterval paces background reconnects to optional
+// dependencies that were still down when startup gave up waiting.
+const dependencyRetryInterval = 30 * time.Second
+
 func providePGVectorStore(lc fx.Lifecycle, log *slog.Logger, cfg config.Config) (*pgvectordb.Store, error) {
 	if !cfg.PGVector.Enabled {
 		return nil, nil
 	}
-	store, err := pgvectordb.Open(context.Background(), log, cfg.PGVector)
-	if err != nil {
-		log.Warn("pgvector store unavailable; semantic memory index disabled", slog.Any("error", err))
-		return nil, nil
+	store := pgvectordb.NewPending()
+	connect := func(ctx context.Context) error {
+		return store.Connect(ctx, log, cfg.PGVector)
+	}
+	err := boot.WaitFor(context.Background(), log, "pgvector", cfg.Startup.DependencyWaitDuration(), connect)
+	if err == nil {
+		lc.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				store.Close()
+				return nil
+			},
+		})
+		return store, nil
 	}
+	// Memory, knowledge and history search fall back to text search until
+	// the store connects in the background.
+	log.Warn("pgvector store unavailable; semantic search degraded until it connects", slog.Any("error", err))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		retryDependency(ctx, log, "pgvector", connect)
+	}()
 	lc.Append(fx.Hook{
 		OnStop: func(context.Context) error {
+			cancel()
+			<-done
 			store.Close()
 			return nil
 		},
@@ -154,6 +271,27 @@ func providePGVectorStore(lc fx.Lifecycle, log *slog.Logger, cfg config.Config)
 	return store, nil
 }
 
+// retryDependency calls probe every dependencyRetryInterval until it
+// succeeds or ctx ends, for dependencies that missed the startup wait.
+// It reports whether probe succeeded.
+func retryDependency(ctx context.Context, log *slog.Logger, name string, probe func(context.Context) error) bool {
+	ticker := time.NewTicker(dependencyRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+		if err := probe(ctx); err != nil {
+			log.Debug("dependency still unavailable", slog.String("dependency", name), slog.Any("error", err))
+			continue
+		}
+		log.Info("dependency connected after startup", slog.String("dependency", name))
+		return true
+	}
+}
+
 func providePostgresStore(conn *pgxpool.Pool) (*postgresstore.Store, error) {
 	if conn == nil {
 		return nil, nil
@@ -178,6 +316,22 @@ func provideNetworkService(log *slog.Logger, queries dbstore.Queries, registry *
 	return netctl.NewService(log, queries, registry, service, rc.ContainerBackend, cfg.Workspace.CNIBinaryDir, cfg.Workspace.CNIConfigDir, cfg.Workspace.DataRoot)
 }
 
+// provideKeyring returns the at-rest content keyring, or nil when no master
+// key is configured and content is stored as plaintext.
+func provideKeyring(cfg config.Config, postgresStore *postgresstore.Store) (*encryption.Keyring, error) {
+	if !cfg.Encryption.Enabled() {
+		return nil, nil
+	}
+	if postgresStore == nil {
+		return nil, errors.New("postgres store not configured")
+	}
+	masterKey, err := encryption.ParseMasterKey(cfg.Encryption.MasterKey)
+	if err != nil {
+		return nil, err
+	}
+	return encryption.NewKeyring(masterKey, postgresStore.SQLC())
+}
+
 func provideDBQueries(postgresStore *postgresstore.Store) (dbstore.Queries, error) {
 	if postgresStore == nil {
 		return nil, errors.New("postgres store not configured")
@@ -222,11 +376,15 @@ func provideAccountService(log *slog.Logger, accountStore dbstore.AccountStore)
 
 // provideWikiStore wires the PostgreSQL memory wiki store. Returns a pointer
 // so FX can inject nil-safe into providers that may run without a wiki store.
-func provideWikiStore(postgresStore *postgresstore.Store) (*wikistore.Store, error) {
+func provideWikiStore(postgresStore *postgresstore.Store, keyring *encryption.Keyring) (*wikistore.Store, error) {
 	if postgresStore == nil {
 		return nil, errors.New("postgres wiki store not configured")
 	}
-	ws := wikistore.Store(wikistore.NewPostgres(postgresStore.SQLC()))
+	pg := wikistore.NewPostgres(postgresStore.SQLC())
+	if keyring != nil {
+		pg.SetTextCipher(keyring)
+	}
+	ws := wikistore.Store(pg)
 	return &ws, nil
 }
 
@@ -384,8 +542,58 @@ func provideSessionService(log *slog.Logger, queries dbstore.Queries, hub *event
 	return service
 }
 
-func provideMessageService(log *slog.Logger, queries dbstore.Queries, hub *event.Hub) *message.DBService {
-	return message.NewService(log, queries, hub)
+func provideMessageService(log *slog.Logger, queries dbstore.Queries, hub *event.Hub, keyring *encryption.Keyring) *message.DBService {
+	service := message.NewService(log, queries, hub)
+	if keyring != nil {
+		service.SetContentCipher(keyring)
+	}
+	return service
+}
+
+// providePushService is shared by the channel registry, which sends
+// notifications, and the server, which registers devices.
+func providePushService(log *slog.Logger, queries dbstore.Queries, cfg config.Config) (*pushpkg.Service, error) {
+	return pushpkg.NewService(log, queries, cfg.Push)
+}
+
+func provideMemoryFlagService(log *slog.Logger, queries dbstore.Queries, keyring *encryption.Keyring) *memflags.Service {
+	service := memflags.NewService(log, queries)
+	if keyring != nil {
+		service.SetContentCipher(keyring)
+	}
+	return service
+}
+
+func provideCompactionService(log *slog.Logger, queries dbstore.Queries, keyring *encryption.Keyring) *compaction.Service {
+	service := compaction.NewService(log, queries)
+	if keyring != nil {
+		service.SetContentCipher(keyring)
+	}
+	return service
+}
+
+func provideMessageArchiveService(log *slog.Logger, queries dbstore.Queries, keyring *encryption.Keyring, cfg config.Config) *msgarchive.Service {
+	service := msgarchive.NewService(log, queries, localfs.New(cfg.History.ArchivePath()))
+	if keyring != nil {
+		service.SetContentCipher(keyring)
+	}
+	return service
+}
+
+// startMessageArchive moves old message content to cold storage when
+// history.archive_after_months is set.
+func startMessageArchive(lc fx.Lifecycle, cfg config.Config, service *msgarchive.Service) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(ctx, cfg.History.ArchiveAfterMonths)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
 }
 
 func provideScheduleTriggerer(service *application.Service) schedule.Triggerer {
@@ -419,15 +627,25 @@ func provideScheduleSessionCreator(sessionService *sessionpkg.Service) schedule.
 	return &sessionCreatorAdapter{svc: sessionService}
 }
 
-func provideAgent(log *slog.Logger, provider bridge.Provider, hookService *hookspkg.Service, cfg config.Config) *native.Agent {
+func provideAgent(log *slog.Logger, provider bridge.Provider, hookService *hookspkg.Service, extensions *extension.Chain, cfg config.Config) *native.Agent {
 	return native.New(native.Deps{
 		BridgeProvider: provider,
 		HookService:    hookService,
+		Extensions:     extensions,
 		Logger:         log,
 		Limits:         agentLimitsFromConfig(cfg.Agent),
 	})
 }
 
+func scheduleToolProvider(log *slog.Logger, scheduleService *schedule.Service, cfg config.AgentConfig) *agenttools.ScheduleProvider {
+	provider := agenttools.NewScheduleProvider(log, scheduleService)
+	provider.SetLimits(schedule.Limits{
+		MaxPerBot:   cfg.ScheduleMaxPerBot,
+		MinInterval: time.Duration(cfg.ScheduleMinIntervalSeconds) * time.Second,
+	})
+	return provider
+}
+
 func agentLimitsFromConfig(cfg config.AgentConfig) native.Limits {
 	return native.LimitsFromValues(
 		cfg.ToolOutputMaxBytes,
@@ -475,9 +693,14 @@ func provideACPSessionPool(lc fx.Lifecycle, log *slog.Logger, runner *acpclient.
 	return pool
 }
 
-func provideAgentService(log *slog.Logger, a *native.Agent, modelsService *models.Service, queries dbstore.Queries, msgService *message.DBService, settingsService *settings.Service, accountService *accounts.Service, botService *bots.Service, mediaService *media.Service, containerdHandler *handlers.ContainerdHandler, workspaceManager *workspace.Manager, memoryRegistry *memprovider.Registry, channelStore *channel.Store, _ *route.DBService, sessionService *sessionpkg.Service, eventHub *event.Hub, compactionService *compaction.Service, pipeline *timeline.Pipeline, rc *boot.RuntimeConfig, bgManager *background.Manager, toolApproval *toolapproval.Service, userInput *userinput.Service, acpPool *acpagent.SessionPool, hookService *hookspkg.Service) *application.Service {
+func provideAgentService(log *slog.Logger, cfg config.Config, a *native.Agent, modelsService *models.Service, queries dbstore.Queries, msgService *message.DBService, settingsService *settings.Service, accountService *accounts.Service, botService *bots.Service, mediaService *media.Service, containerdHandler *handlers.ContainerdHandler, workspaceManager *workspace.Manager, memoryRegistry *memprovider.Registry, channelStore *channel.Store, _ *route.DBService, sessionService *sessionpkg.Service, eventHub *event.Hub, compactionService *compaction.Service, pipeline *timeline.Pipeline, rc *boot.RuntimeConfig, bgManager *background.Manager, toolApproval *toolapproval.Service, userInput *userinput.Service, acpPool *acpagent.SessionPool, hookService *hookspkg.Service, knowledgeService *knowledge.Service, featureFlags *featureflags.Service) *application.Service {
 	service := application.NewService(log, modelsService, queries, msgService, settingsService, accountService, a, rc.TimezoneLocation, 120*time.Second)
 	service.SetBotPermissionChecker(&applicationBotPermissionChecker{bots: botService, accounts: accountService})
+	service.SetToolHistoryBudget(application.ToolHistoryBudget{
+		KeepTurns:      cfg.Agent.HistoryToolKeepTurns,
+		ResultMaxBytes: cfg.Agent.HistoryToolResultMaxBytes,
+	})
+	service.SetCompactionContextPercent(cfg.Agent.CompactionContextPercent)
 	service.SetWorkspaceTargetResolver(workspaceManager)
 	service.SetHookService(hookService)
 	if sessionService != nil {
@@ -490,9 +713,12 @@ func provideAgentService(log *slog.Logger, a *native.Agent, modelsService *model
 		workspaceManager.SetHookService(hookService)
 	}
 	service.SetMemoryRegistry(memoryRegistry)
+	service.SetKnowledgeService(knowledgeService)
+	service.SetFeatureFlags(featureFlags)
 	service.SetSkillLoader(&skillLoaderAdapter{handler: containerdHandler})
 	service.SetGatewayAssetLoader(&gatewayAssetLoaderAdapter{media: mediaService})
 	service.SetPlatformIdentitySource(channelidentityadapter.NewSource(channelStore))
+	service.SetContactTimezoneSource(onboarding.NewService(log, queries))
 	service.SetSessionService(sessionService)
 	service.SetEventPublisher(eventHub)
 	service.SetCompactionService(compactionService)
@@ -591,11 +817,11 @@ func injectACPToolProviders(source *agenttools.NativeToolSource, toolProviders [
 	}
 }
 
-func provideToolGatewayService(log *slog.Logger, fedGateway *handlers.MCPFederationGateway, oauthService *mcp.OAuthService, mcpConnService *mcp.ConnectionService, containerdHandler *handlers.ContainerdHandler, nativeSource *agenttools.NativeToolSource, toolContexts *mcp.ToolSessionContextStore, cfg config.Config) *mcp.ToolGatewayService {
+func provideToolGatewayService(log *slog.Logger, fedGateway *handlers.MCPFederationGateway, oauthService *mcp.OAuthService, mcpConnService *mcp.ConnectionService, httpToolService *httptools.Service, containerdHandler *handlers.ContainerdHandler, nativeSource *agenttools.NativeToolSource, toolContexts *mcp.ToolSessionContextStore, cfg config.Config) *mcp.ToolGatewayService {
 	fedGateway.SetOAuthService(oauthService)
 	fedSource := mcpfederation.NewSource(log, fedGateway, mcpConnService, mcpfederation.WithReservedToolName(agenttools.IsBuiltInToolName))
 	limits := agentLimitsFromConfig(cfg.Agent)
-	svc := mcp.NewToolGatewayService(log, []mcp.ToolSource{nativeSource, fedSource}, mcp.WithToolOutputLimit(limits.ToolOutputLimit()))
+	svc := mcp.NewToolGatewayService(log, []mcp.ToolSource{nativeSource, fedSource, httpToolService}, mcp.WithToolOutputLimit(limits.ToolOutputLimit()))
 	containerdHandler.SetToolGatewayService(svc)
 	containerdHandler.SetToolSessionContextStore(toolContexts)
 	return svc
@@ -619,34 +845,39 @@ func provideBackgroundManager(log *slog.Logger) *background.Manager {
 	return background.New(log)
 }
 
-func provideToolProviders(log *slog.Logger, channelRuntime channel.Runtime, registry *channel.Registry, routeService *route.DBService, scheduleService *schedule.Service, settingsService *settings.Service, searchProviderService *searchproviders.Service, fetchProviderService *fetchproviders.Service, manager *workspace.Manager, mediaService *media.Service, memoryRegistry *memprovider.Registry, emailService *emailpkg.Service, emailRuntime emailpkg.Runtime, fedGateway *handlers.MCPFederationGateway, mcpConnService *mcp.ConnectionService, modelsService *models.Service, queries dbstore.Queries, audioService *audiopkg.Service, videoService *videopkg.Service, sessionService *sessionpkg.Service, messageService *message.DBService, bgManager *background.Manager, hookService *hookspkg.Service) []agenttools.ToolProvider {
+func provideToolProviders(log *slog.Logger, channelRuntime channel.Runtime, registry *channel.Registry, routeService *route.DBService, scheduleService *schedule.Service, settingsService *settings.Service, searchProviderService *searchproviders.Service, fetchProviderService *fetchproviders.Service, manager *workspace.Manager, mediaService *media.Service, memoryRegistry *memprovider.Registry, memoryFlagService *memflags.Service, emailService *emailpkg.Service, emailRuntime emailpkg.Runtime, githubService *githubpkg.Service, fedGateway *handlers.MCPFederationGateway, mcpConnService *mcp.ConnectionService, httpToolService *httptools.Service, modelsService *models.Service, queries dbstore.Queries, audioService *audiopkg.Service, videoService *videopkg.Service, sessionService *sessionpkg.Service, messageService *message.DBService, bgManager *background.Manager, hookService *hookspkg.Service, stickerService *stickers.Service, archiveService *msgarchive.Service, cfg config.Config) []agenttools.ToolProvider {
 	var assetResolver messaging.AssetResolver
 	if mediaService != nil {
 		assetResolver = &mediaAssetResolverAdapter{media: mediaService}
 	}
 	channelMessaging := channelmessagingadapter.New(channelRuntime, registry, assetResolver)
 	fedSource := mcpfederation.NewSource(log, fedGateway, mcpConnService, mcpfederation.WithReservedToolName(agenttools.IsBuiltInToolName))
-	return []agenttools.ToolProvider{
+	historyProvider := agenttools.NewHistoryProvider(log, channelthreadadapter.NewLister(sessionService, routeService), messageService, queries)
+	historyProvider.SetArchiveReader(archiveService)
+	return offlineToolProviders(cfg, []agenttools.ToolProvider{
 		agenttools.NewAskUserProvider(log),
 		agenttools.NewMessageProvider(log, channelMessaging, channelMessaging, channelMessaging, assetResolver),
 		agenttools.NewContactsProvider(log, channelcontactadapter.NewSource(routeService)),
-		agenttools.NewScheduleProvider(log, scheduleService),
-		agenttools.NewMemoryProvider(log, memoryRegistry, settingsService),
+		scheduleToolProvider(log, scheduleService, cfg.Agent),
+		agenttools.NewMemoryProvider(log, memoryRegistry, settingsService, memoryFlagService),
 		agenttools.NewWebProvider(log, settingsService, searchProviderService),
 		agenttools.NewContainerProvider(log, manager, bgManager, config.DefaultDataMount, hookService),
 		agenttools.NewBackgroundProvider(log, bgManager),
 		agenttools.NewBrowserProvider(log, settingsService, nativeWorkspaceBridgeProvider{manager: manager}, manager, config.DefaultDataMount),
 		agenttools.NewEmailProvider(log, emailService, emailRuntime),
+		agenttools.NewGitHubProvider(log, githubService),
 		agenttools.NewWebFetchProvider(log, settingsService, fetchProviderService),
 		agenttools.NewSpawnProvider(log, settingsService, modelsService, queries, sessionService, bgManager),
 		agenttools.NewSkillProvider(log),
 		agenttools.NewTTSProvider(log, settingsService, audioService, channelMessaging, channelMessaging),
+		agenttools.NewStickerProvider(log, channelstickeradapter.NewSource(stickerService), channelMessaging, channelMessaging),
 		agenttools.NewTranscriptionProvider(log, settingsService, audioService, mediaService),
 		agenttools.NewImageGenProvider(log, settingsService, modelsService, queries, manager, config.DefaultDataMount),
 		agenttools.NewVideoGenProvider(log, settingsService, videoService, bgManager, manager, config.DefaultDataMount),
 		agenttools.NewFederationProvider(log, fedSource),
-		agenttools.NewHistoryProvider(log, channelthreadadapter.NewLister(sessionService, routeService), messageService, queries),
-	}
+		agenttools.NewFederationProvider(log, httpToolService),
+		historyProvider,
+	})
 }
 
 func provideMediaService(log *slog.Logger, provider bridge.Provider, cfg config.Config) *media.Service {
@@ -708,10 +939,60 @@ func startBackgroundTaskCleanup(lc fx.Lifecycle, mgr *background.Manager) {
 	})
 }
 
+func provideKnowledgeService(log *slog.Logger, queries dbstore.Queries, vectors *pgvectordb.Store, oauthClients *oauthclients.Registry) *knowledge.Service {
+	service := knowledge.NewService(log, queries, vectors)
+	service.SetOAuthClients(oauthClients)
+	service.RegisterConnector(knowledgenotion.New())
+	service.RegisterConnector(knowledgeconfluence.New())
+	service.RegisterConnector(knowledgegdrive.New())
+	return service
+}
+
+func provideGitHubService(log *slog.Logger, queries dbstore.Queries, knowledgeService *knowledge.Service, cfg config.Config) (*githubpkg.Service, error) {
+	service, err := githubpkg.NewService(log, queries, cfg.GitHub)
+	if err != nil {
+		return nil, err
+	}
+	service.SetKnowledgeService(knowledgeService)
+	return service, nil
+}
+
+func startGitHubSyncWorker(lc fx.Lifecycle, service *githubpkg.Service) {
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(done)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+}
+
+func startKnowledgeReprocessWorker(lc fx.Lifecycle, service *knowledge.Service) {
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go service.Run(done)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+}
+
 // inboundTranscriptionResult moved to the shared Channel module.
 
 func provideProvidersService(log *slog.Logger, queries dbstore.Queries, cfg config.Config) *providers.Service {
-	return providers.NewService(log, queries, defaultProviderOAuthCallbackURL(), cfg.Registry.ProvidersPath())
+	svc := providers.NewService(log, queries, defaultProviderOAuthCallbackURL(), cfg.Registry.ProvidersPath())
+	if cfg.Offline.Enabled {
+		svc.RestrictEndpoints(cfg.Offline.CheckEndpoint)
+	}
+	return svc
 }
 
 func defaultProviderOAuthCallbackURL() string {
@@ -744,10 +1025,68 @@ func startProviderTemplateSync(
 	})
 }
 
+// validateOfflineEndpoints refuses to start an offline deployment while any
+// stored model or memory provider still targets a public endpoint, and stops
+// knowledge crawls before the workers start. The config-file endpoints were
+// already checked by config.Load.
+func validateOfflineEndpoints(lc fx.Lifecycle, cfg config.Config, providersService *providers.Service, mpService *memprovider.Service, knowledgeService *knowledge.Service) {
+	if !cfg.Offline.Enabled {
+		return
+	}
+	knowledgeService.DisableExternalSources()
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := providersService.ValidateEndpoints(ctx, cfg.Offline.CheckEndpoint); err != nil {
+				return fmt.Errorf("offline mode: %w", err)
+			}
+			memoryProviders, err := mpService.List(ctx)
+			if err != nil {
+				return fmt.Errorf("offline mode: list memory providers: %w", err)
+			}
+			for _, mp := range memoryProviders {
+				baseURL, _ := mp.Config["base_url"].(string)
+				if strings.TrimSpace(baseURL) == "" {
+					continue
+				}
+				if err := cfg.Offline.CheckEndpoint(baseURL); err != nil {
+					return fmt.Errorf("offline mode: memory provider %q: %w", mp.Name, err)
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// offlineToolProviders drops the built-in tools whose only purpose is to
+// reach the public internet, and the imported HTTP tools, which call
+// arbitrary APIs.
+func offlineToolProviders(cfg config.Config, providers []agenttools.ToolProvider) []agenttools.ToolProvider {
+	if !cfg.Offline.Enabled {
+		return providers
+	}
+	filtered := make([]agenttools.ToolProvider, 0, len(providers))
+	for _, provider := range providers {
+		switch p := provider.(type) {
+		case *agenttools.WebProvider, *agenttools.WebFetchProvider:
+			continue
+		case *agenttools.FederationProvider:
+			if _, ok := p.Source().(*httptools.Service); ok {
+				continue
+			}
+		}
+		filtered = append(filtered, provider)
+	}
+	return filtered
+}
+
 func configureMemoryProviderRegistry(mpService *memprovider.Service, registry *memprovider.Registry) {
 	mpService.SetRegistry(registry)
 }
 
+func configureScheduleMaintenance(scheduleService *schedule.Service, maintenanceService *maintenance.Service) {
+	scheduleService.SetMaintenance(maintenanceService)
+}
+
 func startScheduleService(lc fx.Lifecycle, scheduleService *schedule.Service) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -764,10 +1103,27 @@ func startHeartbeatService(lc fx.Lifecycle, heartbeatService *heartbeat.Service)
 	})
 }
 
-func startContainerReconciliation(lc fx.Lifecycle, manager *workspace.Manager, _ *handlers.ContainerdHandler, _ *mcp.ToolGatewayService) {
+func startContainerReconciliation(lc fx.Lifecycle, log *slog.Logger, service ctr.Service, manager *workspace.Manager, _ *handlers.ContainerdHandler, _ *mcp.ToolGatewayService) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
 	lc.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
-			go manager.ReconcileContainers(ctx)
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				// A runtime that missed the startup wait is reconciled once it
+				// answers instead of leaving bot containers stopped.
+				if checker, ok := service.(ctr.HealthChecker); ok && checker.Ping(ctx) != nil {
+					if !retryDependency(ctx, log, "container runtime", checker.Ping) {
+						return
+					}
+				}
+				manager.ReconcileContainers(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
 			return nil
 		},
 	})
@@ -864,6 +1220,20 @@ func (c *lazyLLMClient) Compact(ctx context.Context, req memprovider.CompactRequ
 	return client.Compact(ctx, req)
 }
 
+// ExpandQuery expands a memory search query when the resolved memory model
+// client supports it; otherwise the query is left alone.
+func (c *lazyLLMClient) ExpandQuery(ctx context.Context, req memprovider.ExpandQueryRequest) (memprovider.ExpandQueryResponse, error) {
+	client, err := c.resolve(ctx, req.BotID)
+	if err != nil {
+		return memprovider.ExpandQueryResponse{}, err
+	}
+	expander, ok := client.(memprovider.QueryExpander)
+	if !ok {
+		return memprovider.ExpandQueryResponse{}, nil
+	}
+	return expander.ExpandQuery(ctx, req)
+}
+
 func (c *lazyLLMClient) resolve(ctx context.Context, botID string) (memprovider.LLM, error) {
 	if c.modelsService == nil || c.queries == nil {
 		return nil, errors.New("models service not configured")
@@ -1017,11 +1387,11 @@ func (a *applicationBotPermissionChecker) HasBotPermission(ctx context.Context,
 	if a == nil || a.bots == nil || a.accounts == nil {
 		return false, errors.New("bot permission services not configured")
 	}
-	isAdmin, err := a.accounts.IsAdmin(ctx, accountID)
+	manageAll, err := policy.Allowed(ctx, a.accounts, accountID, policy.PermissionBotsManageAll)
 	if err != nil {
 		return false, err
 	}
-	perms, err := a.bots.ResolveUserPermissions(ctx, botID, accountID, isAdmin)
+	perms, err := a.bots.ResolveUserPermissions(ctx, botID, accountID, manageAll)
 	if err != nil {
 		return false, err
 	}
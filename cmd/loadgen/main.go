@@ -0,0 +1,523 @@
+// Command loadgen drives synthetic chat traffic against a running server so
+// operators can size a deployment before going live. Each virtual user opens
+// its own web channel WebSocket (/bots/:bot_id/web/ws), starts a session and
+// sends messages drawn from a weighted mix; loadgen then reports
+// first-response and completion latency percentiles per message kind.
+//
+// Message kinds are text, attachment (an inline image, or -attachment FILE)
+// and mention. Web conversations are private, so mention messages exercise
+// @-mention text rather than group routing.
+//
+// Usage:
+//
+//	go run ./cmd/loadgen -server http://localhost:8080 -bot BOT_ID -token TOKEN \
+//	    [-tokens-file FILE] [-users 10] [-messages 5] \
+//	    [-mix text=8,attachment=1,mention=1] [-think 1s] [-timeout 2m]
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type kind string
+
+const (
+	kindText       kind = "text"
+	kindAttachment kind = "attachment"
+	kindMention    kind = "mention"
+)
+
+// prompts are short questions that keep model cost per message low, so the
+// run measures the platform rather than long generations.
+var prompts = []string{
+	"Hello! How are you today?",
+	"Summarize the benefits of unit testing in two sentences.",
+	"Suggest a name for a houseplant.",
+	"Give me one tip for writing clear commit messages.",
+	"What is the capital of Australia?",
+}
+
+// defaultImage is a 1x1 PNG sent when no -attachment file is given.
+const defaultImage = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="
+
+type options struct {
+	wsURL      string
+	tokens     []string
+	users      int
+	messages   int
+	mix        mix
+	attachment map[string]any
+	mention    string
+	think      time.Duration
+	timeout    time.Duration
+	seed       uint64
+}
+
+func main() {
+	server := flag.String("server", "http://localhost:8080", "server base URL")
+	bot := flag.String("bot", "", "bot id to chat with")
+	token := flag.String("token", "", "bearer token shared by all virtual users")
+	tokensFile := flag.String("tokens-file", "", "file with one bearer token per line, assigned to users round-robin")
+	users := flag.Int("users", 10, "concurrent virtual users")
+	messages := flag.Int("messages", 5, "messages each user sends")
+	mixSpec := flag.String("mix", "text=8,attachment=1,mention=1", "weighted message kinds")
+	attachmentPath := flag.String("attachment", "", "file sent by attachment messages (default: a 1x1 PNG)")
+	mention := flag.String("mention", "memoh", "handle used by mention messages")
+	think := flag.Duration("think", time.Second, "pause between a user's messages")
+	timeout := flag.Duration("timeout", 2*time.Minute, "per-message deadline")
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "random seed for the message mix")
+	flag.Parse()
+
+	if strings.TrimSpace(*bot) == "" {
+		fail("-bot is required")
+	}
+	if *users < 1 || *messages < 1 {
+		fail("-users and -messages must be positive")
+	}
+	wsURL, err := webSocketURL(*server, *bot)
+	if err != nil {
+		fail("server: %v", err)
+	}
+	tokens, err := loadTokens(*token, *tokensFile)
+	if err != nil {
+		fail("tokens: %v", err)
+	}
+	m, err := parseMix(*mixSpec)
+	if err != nil {
+		fail("mix: %v", err)
+	}
+	attachment, err := loadAttachment(*attachmentPath)
+	if err != nil {
+		fail("attachment: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "%d users x %d messages against %s\n", *users, *messages, wsURL)
+	started := time.Now()
+	samples := run(ctx, options{
+		wsURL:      wsURL,
+		tokens:     tokens,
+		users:      *users,
+		messages:   *messages,
+		mix:        m,
+		attachment: attachment,
+		mention:    strings.TrimPrefix(strings.TrimSpace(*mention), "@"),
+		think:      *think,
+		timeout:    *timeout,
+		seed:       *seed,
+	})
+	report(os.Stdout, samples, time.Since(started))
+
+	if succeeded(samples) == 0 {
+		os.Exit(1)
+	}
+}
+
+// mix is a weighted set of message kinds.
+type mix []mixEntry
+
+type mixEntry struct {
+	kind   kind
+	weight int
+}
+
+// parseMix parses "text=8,attachment=1,mention=1". A kind without a weight
+// counts once.
+func parseMix(spec string) (mix, error) {
+	var m mix
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightText, hasWeight := strings.Cut(part, "=")
+		k := kind(strings.TrimSpace(name))
+		switch k {
+		case kindText, kindAttachment, kindMention:
+		default:
+			return nil, fmt.Errorf("unknown message kind %q", name)
+		}
+		weight := 1
+		if hasWeight {
+			w, err := strconv.Atoi(strings.TrimSpace(weightText))
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("invalid weight for %s: %q", k, weightText)
+			}
+			weight = w
+		}
+		if weight > 0 {
+			m = append(m, mixEntry{kind: k, weight: weight})
+		}
+	}
+	if len(m) == 0 {
+		return nil, errors.New("no message kinds with a positive weight")
+	}
+	return m, nil
+}
+
+func (m mix) pick(r *rand.Rand) kind {
+	total := 0
+	for _, e := range m {
+		total += e.weight
+	}
+	n := r.IntN(total)
+	for _, e := range m {
+		if n < e.weight {
+			return e.kind
+		}
+		n -= e.weight
+	}
+	return m[len(m)-1].kind
+}
+
+// sample is the outcome of one message.
+type sample struct {
+	kind kind
+	// first is the time until the first reply message, total until the
+	// stream ended.
+	first time.Duration
+	total time.Duration
+	err   error
+}
+
+// run starts the virtual users and collects one sample per message sent.
+func run(ctx context.Context, opts options) []sample {
+	var (
+		mu      sync.Mutex
+		samples []sample
+		wg      sync.WaitGroup
+	)
+	for i := range opts.users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got := runUser(ctx, opts, i)
+			mu.Lock()
+			samples = append(samples, got...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return samples
+}
+
+// runUser sends one user's messages over a single connection and session.
+// A broken connection ends the user's run.
+func runUser(ctx context.Context, opts options, user int) []sample {
+	rng := rand.New(rand.NewPCG(opts.seed, uint64(user))) //nolint:gosec // traffic mix, not security
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+opts.tokens[user%len(opts.tokens)])
+	dialCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+	conn, resp, err := websocket.DefaultDialer.DialContext(dialCtx, opts.wsURL, header)
+	cancel()
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		return []sample{{kind: opts.mix.pick(rng), err: fmt.Errorf("connect: %w", err)}}
+	}
+	defer func() { _ = conn.Close() }()
+	stopOnCancel := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stopOnCancel()
+
+	samples := make([]sample, 0, opts.messages)
+	sessionID := ""
+	for n := range opts.messages {
+		if n > 0 && !sleep(ctx, opts.think) {
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		k := opts.mix.pick(rng)
+		s, sid, err := sendMessage(conn, opts, k, fmt.Sprintf("loadgen-%d-%d", user, n), sessionID, rng)
+		if sid != "" {
+			sessionID = sid
+		}
+		samples = append(samples, s)
+		if err != nil {
+			break
+		}
+	}
+	return samples
+}
+
+type clientMessage struct {
+	Type        string           `json:"type"`
+	StreamID    string           `json:"stream_id"`
+	SessionID   string           `json:"session_id,omitempty"`
+	Text        string           `json:"text"`
+	Attachments []map[string]any `json:"attachments,omitempty"`
+}
+
+type serverEvent struct {
+	Type      string `json:"type"`
+	StreamID  string `json:"stream_id"`
+	SessionID string `json:"session_id"`
+	Message   string `json:"message"`
+}
+
+// sendMessage sends one message and reads events until its stream ends. The
+// returned error is set only when the connection is no longer usable; a
+// failed turn is reported in the sample.
+func sendMessage(conn *websocket.Conn, opts options, k kind, streamID, sessionID string, rng *rand.Rand) (sample, string, error) {
+	msg := clientMessage{
+		Type:      "message",
+		StreamID:  streamID,
+		SessionID: sessionID,
+		Text:      prompts[rng.IntN(len(prompts))],
+	}
+	switch k {
+	case kindAttachment:
+		msg.Text = "What is in this attachment?"
+		msg.Attachments = []map[string]any{opts.attachment}
+	case kindMention:
+		msg.Text = "@" + opts.mention + " " + msg.Text
+	}
+
+	s := sample{kind: k}
+	started := time.Now()
+	_ = conn.SetWriteDeadline(started.Add(opts.timeout))
+	if err := conn.WriteJSON(msg); err != nil {
+		s.err = fmt.Errorf("send: %w", err)
+		return s, "", s.err
+	}
+	_ = conn.SetReadDeadline(started.Add(opts.timeout))
+	for {
+		var event serverEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			s.err = fmt.Errorf("read: %w", err)
+			return s, sessionID, s.err
+		}
+		if event.StreamID != streamID {
+			continue
+		}
+		if event.SessionID != "" {
+			sessionID = event.SessionID
+		}
+		switch event.Type {
+		case "message":
+			if s.first == 0 {
+				s.first = time.Since(started)
+			}
+		case "end":
+			s.total = time.Since(started)
+			if s.first == 0 {
+				s.first = s.total
+			}
+			return s, sessionID, nil
+		case "error", "command_error":
+			s.total = time.Since(started)
+			reason := strings.TrimSpace(event.Message)
+			if reason == "" {
+				reason = event.Type
+			}
+			s.err = errors.New(reason)
+			return s, sessionID, nil
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// report prints latency percentiles per message kind and for all messages.
+func report(w io.Writer, samples []sample, elapsed time.Duration) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "kind\tsent\terrors\tfirst p50\tfirst p90\tfirst p99\ttotal p50\ttotal p90\ttotal p99\ttotal max\t")
+	groups := []kind{kindText, kindAttachment, kindMention, "all"}
+	for _, k := range groups {
+		var first, total []time.Duration
+		sent, errs := 0, 0
+		for _, s := range samples {
+			if k != "all" && s.kind != k {
+				continue
+			}
+			sent++
+			if s.err != nil {
+				errs++
+				continue
+			}
+			first = append(first, s.first)
+			total = append(total, s.total)
+		}
+		if sent == 0 {
+			continue
+		}
+		slices.Sort(first)
+		slices.Sort(total)
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", k, sent, errs,
+			fmtDuration(percentile(first, 50)), fmtDuration(percentile(first, 90)), fmtDuration(percentile(first, 99)),
+			fmtDuration(percentile(total, 50)), fmtDuration(percentile(total, 90)), fmtDuration(percentile(total, 99)),
+			fmtDuration(percentile(total, 100)))
+	}
+	_ = tw.Flush()
+
+	ok := succeeded(samples)
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(ok) / elapsed.Seconds()
+	}
+	fmt.Fprintf(w, "\n%d/%d messages completed in %s (%.2f msg/s)\n", ok, len(samples), elapsed.Round(time.Millisecond), rate)
+	for _, reason := range topErrors(samples, 5) {
+		fmt.Fprintf(w, "  error: %s\n", reason)
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted durations,
+// or -1 when there are none.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return -1
+	}
+	rank := int(float64(len(sorted))*p/100+0.999999) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+func fmtDuration(d time.Duration) string {
+	if d < 0 {
+		return "-"
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+func succeeded(samples []sample) int {
+	n := 0
+	for _, s := range samples {
+		if s.err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// topErrors returns the most frequent error messages with their counts.
+func topErrors(samples []sample, limit int) []string {
+	counts := map[string]int{}
+	for _, s := range samples {
+		if s.err != nil {
+			counts[s.err.Error()]++
+		}
+	}
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	slices.SortFunc(reasons, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+	if len(reasons) > limit {
+		reasons = reasons[:limit]
+	}
+	for i, reason := range reasons {
+		reasons[i] = fmt.Sprintf("%dx %s", counts[reason], reason)
+	}
+	return reasons
+}
+
+func webSocketURL(server, bot string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(server))
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	return u.JoinPath("bots", strings.TrimSpace(bot), "web", "ws").String(), nil
+}
+
+func loadTokens(token, path string) ([]string, error) {
+	var tokens []string
+	if t := strings.TrimSpace(token); t != "" {
+		tokens = append(tokens, t)
+	}
+	if path != "" {
+		data, err := os.ReadFile(path) //nolint:gosec // operator-supplied path
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if t := strings.TrimSpace(line); t != "" && !strings.HasPrefix(t, "#") {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("set -token or -tokens-file")
+	}
+	return tokens, nil
+}
+
+// loadAttachment returns the inline attachment bundle sent by attachment
+// messages.
+func loadAttachment(path string) (map[string]any, error) {
+	if path == "" {
+		return map[string]any{"type": "image", "base64": defaultImage, "mime": "image/png", "name": "loadgen.png"}, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // operator-supplied path
+	if err != nil {
+		return nil, err
+	}
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	attType := "file"
+	if strings.HasPrefix(mimeType, "image/") {
+		attType = "image"
+	}
+	return map[string]any{
+		"type":   attType,
+		"base64": base64.StdEncoding.EncodeToString(data),
+		"mime":   mimeType,
+		"name":   filepath.Base(path),
+		"size":   len(data),
+	}, nil
+}
+
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "loadgen: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestParseMix(t *testing.T) {
+	t.Parallel()
+
+	m, err := parseMix("text=3, attachment, mention=0")
+	if err != nil {
+		t.Fatalf("parseMix: %v", err)
+	}
+	if len(m) != 2 || m[0] != (mixEntry{kindText, 3}) || m[1] != (mixEntry{kindAttachment, 1}) {
+		t.Fatalf("parseMix = %+v", m)
+	}
+	for _, spec := range []string{"", "mention=0", "voice=1", "text=-1", "text=x"} {
+		if _, err := parseMix(spec); err == nil {
+			t.Fatalf("parseMix(%q) accepted", spec)
+		}
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // test
+	only, _ := parseMix("mention")
+	for range 10 {
+		if got := only.pick(rng); got != kindMention {
+			t.Fatalf("pick = %s", got)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	sorted := make([]time.Duration, 0, 100)
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	for p, want := range map[float64]time.Duration{50: 50 * time.Millisecond, 99: 99 * time.Millisecond, 100: 100 * time.Millisecond} {
+		if got := percentile(sorted, p); got != want {
+			t.Fatalf("percentile(%v) = %s, want %s", p, got, want)
+		}
+	}
+	if got := percentile(nil, 50); got != -1 {
+		t.Fatalf("percentile(nil) = %s", got)
+	}
+}
+
+func TestWebSocketURL(t *testing.T) {
+	t.Parallel()
+
+	got, err := webSocketURL("https://memoh.example.com/api/", "bot 1")
+	if err != nil {
+		t.Fatalf("webSocketURL: %v", err)
+	}
+	if want := "wss://memoh.example.com/api/bots/bot%201/web/ws"; got != want {
+		t.Fatalf("webSocketURL = %q, want %q", got, want)
+	}
+}
+
+// fakeChatServer answers each message like the web channel: session_created
+// on the first turn, then start, message and end, or an error for text
+// containing "fail".
+type fakeChatServer struct {
+	mu       sync.Mutex
+	sessions map[string]int
+	kinds    map[kind]int
+}
+
+func (f *fakeChatServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer tok" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	for {
+		var msg clientMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		f.mu.Lock()
+		switch {
+		case len(msg.Attachments) > 0:
+			f.kinds[kindAttachment]++
+		case strings.HasPrefix(msg.Text, "@"):
+			f.kinds[kindMention]++
+		default:
+			f.kinds[kindText]++
+		}
+		sessionID := msg.SessionID
+		if sessionID == "" {
+			sessionID = "sess-" + msg.StreamID
+			_ = conn.WriteJSON(serverEvent{Type: "session_created", StreamID: msg.StreamID, SessionID: sessionID})
+		}
+		f.sessions[sessionID]++
+		f.mu.Unlock()
+
+		// Another stream's event must not end this one.
+		_ = conn.WriteJSON(serverEvent{Type: "end", StreamID: "other"})
+		if strings.Contains(msg.Text, "fail") {
+			_ = conn.WriteJSON(serverEvent{Type: "error", StreamID: msg.StreamID, SessionID: sessionID, Message: "model unavailable"})
+			continue
+		}
+		_ = conn.WriteJSON(serverEvent{Type: "start", StreamID: msg.StreamID, SessionID: sessionID})
+		time.Sleep(time.Millisecond)
+		_ = conn.WriteJSON(serverEvent{Type: "message", StreamID: msg.StreamID, SessionID: sessionID})
+		_ = conn.WriteJSON(serverEvent{Type: "end", StreamID: msg.StreamID, SessionID: sessionID})
+	}
+}
+
+func TestRunReportsPerKind(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeChatServer{sessions: map[string]int{}, kinds: map[kind]int{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	wsURL, err := webSocketURL(srv.URL, "bot-1")
+	if err != nil {
+		t.Fatalf("webSocketURL: %v", err)
+	}
+	m, _ := parseMix("text=2,attachment=1,mention=1")
+	attachment, _ := loadAttachment("")
+
+	samples := run(context.Background(), options{
+		wsURL:      wsURL,
+		tokens:     []string{"tok"},
+		users:      3,
+		messages:   4,
+		mix:        m,
+		attachment: attachment,
+		mention:    "memoh",
+		timeout:    5 * time.Second,
+		seed:       7,
+	})
+	if len(samples) != 12 {
+		t.Fatalf("samples = %d, want 12", len(samples))
+	}
+	for _, s := range samples {
+		if s.err != nil {
+			t.Fatalf("sample error: %v", s.err)
+		}
+		if s.first <= 0 || s.total < s.first {
+			t.Fatalf("latencies first=%s total=%s", s.first, s.total)
+		}
+	}
+	fake.mu.Lock()
+	sessions, kinds := len(fake.sessions), fake.kinds
+	fake.mu.Unlock()
+	if sessions != 3 {
+		t.Fatalf("sessions = %d, want one per user", sessions)
+	}
+	for _, s := range samples {
+		kinds[s.kind]--
+	}
+	for k, n := range kinds {
+		if n != 0 {
+			t.Fatalf("server saw %d more %s messages than were sampled", n, k)
+		}
+	}
+
+	var out bytes.Buffer
+	report(&out, samples, time.Second)
+	if !strings.Contains(out.String(), "12/12 messages completed") {
+		t.Fatalf("report:\n%s", out.String())
+	}
+}
+
+func TestRunRecordsFailures(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeChatServer{sessions: map[string]int{}, kinds: map[kind]int{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	wsURL, _ := webSocketURL(srv.URL, "bot-1")
+	m, _ := parseMix("mention")
+
+	samples := run(context.Background(), options{
+		wsURL:    wsURL,
+		tokens:   []string{"tok"},
+		users:    1,
+		messages: 2,
+		mix:      m,
+		mention:  "fail",
+		timeout:  5 * time.Second,
+	})
+	if len(samples) != 2 || samples[0].err == nil || samples[0].err.Error() != "model unavailable" {
+		t.Fatalf("samples = %+v", samples)
+	}
+
+	samples = run(context.Background(), options{
+		wsURL:    wsURL,
+		tokens:   []string{"wrong"},
+		users:    2,
+		messages: 3,
+		mix:      m,
+		timeout:  5 * time.Second,
+	})
+	if len(samples) != 2 || succeeded(samples) != 0 || !errors.Is(samples[0].err, websocket.ErrBadHandshake) {
+		t.Fatalf("samples = %+v", samples)
+	}
+	var out bytes.Buffer
+	report(&out, samples, time.Second)
+	if !strings.Contains(out.String(), "2x connect: websocket: bad handshake") {
+		t.Fatalf("report:\n%s", out.String())
+	}
+}
@@ -0,0 +1,4 @@
+-- 0003_knowledge_chunks
+-- Remove knowledge collection chunk embeddings.
+
+DROP TABLE IF EXISTS public.knowledge_chunk_embeddings;
@@ -0,0 +1,51 @@
+-- 0003_knowledge_chunks
+-- Store knowledge collection chunk embeddings, one namespace per collection.
+
+CREATE TABLE IF NOT EXISTS public.knowledge_chunk_embeddings (
+    team_id       UUID        NOT NULL DEFAULT public.memoh_pgvector_current_team_id(),
+    collection_id UUID        NOT NULL,
+    document_id   UUID        NOT NULL,
+    chunk_index   INTEGER     NOT NULL,
+    model_id      UUID        NOT NULL,
+    dimensions    INTEGER     NOT NULL,
+    content       TEXT        NOT NULL,
+    embedding     vector      NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, collection_id, document_id, chunk_index, model_id),
+    CONSTRAINT knowledge_chunk_embeddings_dimensions_check CHECK (dimensions > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_chunk_embeddings_team_collection_model
+    ON public.knowledge_chunk_embeddings (team_id, collection_id, model_id);
+
+ALTER TABLE public.knowledge_chunk_embeddings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_chunk_embeddings FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_chunk_embeddings_team_select
+    ON public.knowledge_chunk_embeddings;
+CREATE POLICY knowledge_chunk_embeddings_team_select
+    ON public.knowledge_chunk_embeddings
+    FOR SELECT
+    USING (team_id = public.memoh_pgvector_current_team_id());
+
+DROP POLICY IF EXISTS knowledge_chunk_embeddings_team_insert
+    ON public.knowledge_chunk_embeddings;
+CREATE POLICY knowledge_chunk_embeddings_team_insert
+    ON public.knowledge_chunk_embeddings
+    FOR INSERT
+    WITH CHECK (team_id = public.memoh_pgvector_current_team_id());
+
+DROP POLICY IF EXISTS knowledge_chunk_embeddings_team_update
+    ON public.knowledge_chunk_embeddings;
+CREATE POLICY knowledge_chunk_embeddings_team_update
+    ON public.knowledge_chunk_embeddings
+    FOR UPDATE
+    USING (team_id = public.memoh_pgvector_current_team_id())
+    WITH CHECK (team_id = public.memoh_pgvector_current_team_id());
+
+DROP POLICY IF EXISTS knowledge_chunk_embeddings_team_delete
+    ON public.knowledge_chunk_embeddings;
+CREATE POLICY knowledge_chunk_embeddings_team_delete
+    ON public.knowledge_chunk_embeddings
+    FOR DELETE
+    USING (team_id = public.memoh_pgvector_current_team_id());
@@ -0,0 +1,4 @@
+-- 0004_history_messages
+-- Remove history message embeddings.
+
+DROP TABLE IF EXISTS public.history_message_embeddings;
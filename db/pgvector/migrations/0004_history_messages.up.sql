@@ -0,0 +1,52 @@
+-- 0004_history_messages
+-- Store history message embeddings for semantic history search, one
+-- namespace per bot. Only vectors are kept; message text stays in the main
+-- database where it may be encrypted at rest.
+
+CREATE TABLE IF NOT EXISTS public.history_message_embeddings (
+    team_id     UUID        NOT NULL DEFAULT public.memoh_pgvector_current_team_id(),
+    bot_id      UUID        NOT NULL,
+    message_id  UUID        NOT NULL,
+    model_id    UUID        NOT NULL,
+    session_id  UUID,
+    dimensions  INTEGER     NOT NULL,
+    embedding   vector      NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, bot_id, message_id, model_id),
+    CONSTRAINT history_message_embeddings_dimensions_check CHECK (dimensions > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_history_message_embeddings_team_bot_model
+    ON public.history_message_embeddings (team_id, bot_id, model_id);
+
+ALTER TABLE public.history_message_embeddings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.history_message_embeddings FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS history_message_embeddings_team_select
+    ON public.history_message_embeddings;
+CREATE POLICY history_message_embeddings_team_select
+    ON public.history_message_embeddings
+    FOR SELECT
+    USING (team_id = public.memoh_pgvector_current_team_id());
+
+DROP POLICY IF EXISTS history_message_embeddings_team_insert
+    ON public.history_message_embeddings;
+CREATE POLICY history_message_embeddings_team_insert
+    ON public.history_message_embeddings
+    FOR INSERT
+    WITH CHECK (team_id = public.memoh_pgvector_current_team_id());
+
+DROP POLICY IF EXISTS history_message_embeddings_team_update
+    ON public.history_message_embeddings;
+CREATE POLICY history_message_embeddings_team_update
+    ON public.history_message_embeddings
+    FOR UPDATE
+    USING (team_id = public.memoh_pgvector_current_team_id())
+    WITH CHECK (team_id = public.memoh_pgvector_current_team_id());
+
+DROP POLICY IF EXISTS history_message_embeddings_team_delete
+    ON public.history_message_embeddings;
+CREATE POLICY history_message_embeddings_team_delete
+    ON public.history_message_embeddings
+    FOR DELETE
+    USING (team_id = public.memoh_pgvector_current_team_id());
@@ -25,6 +25,7 @@ FROM public.memory_node_embeddings
 WHERE team_id = sqlc.arg(team_id)
   AND bot_id = sqlc.arg(bot_id)
   AND model_id = sqlc.arg(model_id)
+  AND dimensions = sqlc.arg(dimensions)
 ORDER BY embedding <=> sqlc.arg(embedding)::vector
 LIMIT sqlc.arg(row_limit);
 
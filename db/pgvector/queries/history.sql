@@ -0,0 +1,35 @@
+-- name: UpsertHistoryMessageEmbedding :exec
+INSERT INTO public.history_message_embeddings (
+  team_id, bot_id, message_id, model_id, session_id, dimensions, embedding, created_at
+)
+VALUES (
+  sqlc.arg(team_id),
+  sqlc.arg(bot_id),
+  sqlc.arg(message_id),
+  sqlc.arg(model_id),
+  sqlc.narg(session_id),
+  sqlc.arg(dimensions),
+  sqlc.arg(embedding),
+  sqlc.arg(created_at)
+)
+ON CONFLICT (team_id, bot_id, message_id, model_id) DO UPDATE SET
+  session_id = EXCLUDED.session_id,
+  dimensions = EXCLUDED.dimensions,
+  embedding = EXCLUDED.embedding;
+
+-- name: SearchHistoryMessageEmbeddings :many
+SELECT
+  message_id,
+  CAST(1.0 - (embedding <=> sqlc.arg(embedding)::vector) AS double precision) AS score
+FROM public.history_message_embeddings
+WHERE team_id = sqlc.arg(team_id)
+  AND bot_id = sqlc.arg(bot_id)
+  AND model_id = sqlc.arg(model_id)
+  AND (sqlc.narg(session_id)::uuid IS NULL OR session_id = sqlc.narg(session_id)::uuid)
+ORDER BY embedding <=> sqlc.arg(embedding)::vector
+LIMIT sqlc.arg(row_limit);
+
+-- name: DeleteBotHistoryMessageEmbeddings :exec
+DELETE FROM public.history_message_embeddings
+WHERE team_id = sqlc.arg(team_id)
+  AND bot_id = sqlc.arg(bot_id);
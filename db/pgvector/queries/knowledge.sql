@@ -0,0 +1,42 @@
+-- name: InsertKnowledgeChunkEmbedding :exec
+INSERT INTO public.knowledge_chunk_embeddings (
+  team_id, collection_id, document_id, chunk_index, model_id, dimensions, content, embedding
+)
+VALUES (
+  sqlc.arg(team_id),
+  sqlc.arg(collection_id),
+  sqlc.arg(document_id),
+  sqlc.arg(chunk_index),
+  sqlc.arg(model_id),
+  sqlc.arg(dimensions),
+  sqlc.arg(content),
+  sqlc.arg(embedding)
+)
+ON CONFLICT (team_id, collection_id, document_id, chunk_index, model_id) DO UPDATE SET
+  dimensions = EXCLUDED.dimensions,
+  content = EXCLUDED.content,
+  embedding = EXCLUDED.embedding;
+
+-- name: SearchKnowledgeChunkEmbeddings :many
+SELECT
+  document_id,
+  chunk_index,
+  content,
+  CAST(1.0 - (embedding <=> sqlc.arg(embedding)::vector) AS double precision) AS score
+FROM public.knowledge_chunk_embeddings
+WHERE team_id = sqlc.arg(team_id)
+  AND collection_id = sqlc.arg(collection_id)
+  AND model_id = sqlc.arg(model_id)
+ORDER BY embedding <=> sqlc.arg(embedding)::vector
+LIMIT sqlc.arg(row_limit);
+
+-- name: DeleteKnowledgeDocumentEmbeddings :exec
+DELETE FROM public.knowledge_chunk_embeddings
+WHERE team_id = sqlc.arg(team_id)
+  AND collection_id = sqlc.arg(collection_id)
+  AND document_id = sqlc.arg(document_id);
+
+-- name: DeleteKnowledgeCollectionEmbeddings :exec
+DELETE FROM public.knowledge_chunk_embeddings
+WHERE team_id = sqlc.arg(team_id)
+  AND collection_id = sqlc.arg(collection_id);
@@ -44,7 +44,7 @@ CREATE TABLE IF NOT EXISTS template.provider_template_models (
   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   CONSTRAINT provider_template_models_identity_unique UNIQUE (provider_template_id, type, model_id),
   CONSTRAINT provider_template_models_type_check CHECK (
-    type IN ('chat', 'embedding', 'speech', 'transcription', 'video')
+    type IN ('chat', 'embedding', 'speech', 'transcription', 'video', 'rerank')
   )
 );
 
@@ -192,7 +192,7 @@ CREATE TABLE IF NOT EXISTS models (
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   CONSTRAINT models_provider_id_model_id_unique UNIQUE (provider_id, model_id),
-  CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding', 'speech', 'transcription', 'video'))
+  CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding', 'speech', 'transcription', 'video', 'rerank'))
 );
 
 CREATE TABLE IF NOT EXISTS model_variants (
@@ -543,8 +543,12 @@ CREATE TABLE IF NOT EXISTS bot_channel_routes (
   default_reply_target TEXT,
   active_session_id UUID,
   metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+  status TEXT NOT NULL DEFAULT 'active',
+  inactive_reason TEXT NOT NULL DEFAULT '',
+  inactive_at TIMESTAMPTZ,
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT bot_channel_routes_status_check CHECK (status IN ('active', 'inactive'))
 );
 
 CREATE UNIQUE INDEX IF NOT EXISTS idx_bot_channel_routes_unique
@@ -889,7 +893,8 @@ CREATE TABLE IF NOT EXISTS schedule (
   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   enabled BOOLEAN NOT NULL DEFAULT true,
   command TEXT NOT NULL,
-  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE
+  bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+  timezone TEXT NOT NULL DEFAULT ''
 );
 
 CREATE INDEX IF NOT EXISTS idx_schedule_bot_id ON schedule(bot_id);
@@ -1157,6 +1162,7 @@ CREATE INDEX IF NOT EXISTS idx_memory_nodes_bot_layer  ON memory_nodes (bot_id,
 CREATE INDEX IF NOT EXISTS idx_memory_nodes_bot_topic  ON memory_nodes (bot_id, topic);
 CREATE INDEX IF NOT EXISTS idx_memory_nodes_bot_prof   ON memory_nodes (bot_id, profile_ref);
 CREATE INDEX IF NOT EXISTS idx_memory_nodes_updated    ON memory_nodes (bot_id, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_memory_nodes_expires    ON memory_nodes (expires_at) WHERE expires_at IS NOT NULL;
 
 CREATE TABLE IF NOT EXISTS memory_edges (
     id          BIGSERIAL    PRIMARY KEY,
@@ -1657,6 +1663,9 @@ DROP INDEX IF EXISTS idx_bot_channel_routes_unique;
 CREATE UNIQUE INDEX idx_bot_channel_routes_unique
     ON public.bot_channel_routes
        (team_id, bot_id, channel_type, external_conversation_id, COALESCE(external_thread_id, ''::text));
+CREATE INDEX IF NOT EXISTS idx_bot_channel_routes_inactive
+    ON public.bot_channel_routes (team_id, inactive_at)
+    WHERE status = 'inactive';
 
 DROP INDEX IF EXISTS idx_bot_history_messages_turn_seq_unique;
 CREATE UNIQUE INDEX idx_bot_history_messages_turn_seq_unique
@@ -2217,3 +2226,1552 @@ CREATE POLICY subagent_configs_team_update ON public.subagent_configs
     WITH CHECK (team_id = public.memoh_current_team_id());
 CREATE POLICY subagent_configs_team_delete ON public.subagent_configs
     FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+-- Round persistence claims one idempotency key per session so a retried
+-- round write returns the originally persisted messages instead of
+-- duplicating them.
+CREATE TABLE IF NOT EXISTS public.bot_history_round_keys (
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    session_id  UUID        NOT NULL,
+    round_key   TEXT        NOT NULL,
+    message_ids UUID[]      NOT NULL DEFAULT '{}'::uuid[],
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, session_id, round_key),
+    CONSTRAINT bot_history_round_keys_session_id_fkey
+        FOREIGN KEY (team_id, session_id)
+        REFERENCES public.bot_sessions(team_id, id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.bot_history_round_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_history_round_keys FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY bot_history_round_keys_team_select ON public.bot_history_round_keys
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_round_keys_team_insert ON public.bot_history_round_keys
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_round_keys_team_update ON public.bot_history_round_keys
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_round_keys_team_delete ON public.bot_history_round_keys
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+-- Per-bot data keys encrypt message content and memory text at rest. The key
+-- material is stored wrapped by the deployment master key, never in plaintext.
+CREATE TABLE IF NOT EXISTS public.bot_data_keys (
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id      UUID        NOT NULL,
+    wrapped_key BYTEA       NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, bot_id),
+    CONSTRAINT bot_data_keys_bot_id_fkey
+        FOREIGN KEY (team_id, bot_id)
+        REFERENCES public.bots(team_id, id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.bot_data_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_data_keys FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY bot_data_keys_team_select ON public.bot_data_keys
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_data_keys_team_insert ON public.bot_data_keys
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_data_keys_team_update ON public.bot_data_keys
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_data_keys_team_delete ON public.bot_data_keys
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+-- The audit log records privileged administrative actions. actor_user_id is
+-- deliberately not a foreign key so entries outlive the accounts they name.
+CREATE TABLE IF NOT EXISTS public.audit_log (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id       UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                              REFERENCES public.teams(id) ON DELETE RESTRICT,
+    actor_user_id UUID,
+    action        TEXT        NOT NULL,
+    target_type   TEXT        NOT NULL,
+    target_id     TEXT        NOT NULL,
+    details       JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_team_created
+    ON public.audit_log (team_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target
+    ON public.audit_log (team_id, target_type, target_id);
+
+ALTER TABLE public.audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.audit_log FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY audit_log_team_select ON public.audit_log
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY audit_log_team_insert ON public.audit_log
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY audit_log_team_update ON public.audit_log
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY audit_log_team_delete ON public.audit_log
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+-- Data export jobs track asynchronous data-subject export bundles. The bundle
+-- itself lives on disk at file_path and is removed once expires_at passes.
+CREATE TABLE IF NOT EXISTS public.data_export_jobs (
+    id                   UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id              UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                     REFERENCES public.teams(id) ON DELETE RESTRICT,
+    requested_by_user_id UUID,
+    subject_type         TEXT        NOT NULL,
+    subject_id           UUID        NOT NULL,
+    status               TEXT        NOT NULL DEFAULT 'pending',
+    error                TEXT        NOT NULL DEFAULT '',
+    file_path            TEXT        NOT NULL DEFAULT '',
+    size_bytes           BIGINT      NOT NULL DEFAULT 0,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at         TIMESTAMPTZ,
+    expires_at           TIMESTAMPTZ,
+    CONSTRAINT data_export_jobs_subject_type_check CHECK (subject_type IN ('user', 'channel_identity')),
+    CONSTRAINT data_export_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_export_jobs_status
+    ON public.data_export_jobs (team_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_data_export_jobs_expires
+    ON public.data_export_jobs (team_id, expires_at)
+    WHERE expires_at IS NOT NULL;
+
+ALTER TABLE public.data_export_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.data_export_jobs FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY data_export_jobs_team_select ON public.data_export_jobs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY data_export_jobs_team_insert ON public.data_export_jobs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY data_export_jobs_team_update ON public.data_export_jobs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY data_export_jobs_team_delete ON public.data_export_jobs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+-- Group reply claims coordinate bots sharing a group chat: the first bot to
+-- claim a triggering message answers it, the others stay silent.
+CREATE TABLE IF NOT EXISTS public.group_reply_claims (
+    team_id         UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                REFERENCES public.teams(id) ON DELETE RESTRICT,
+    channel_type    TEXT        NOT NULL,
+    conversation_id TEXT        NOT NULL,
+    message_id      TEXT        NOT NULL,
+    bot_id          UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    claimed_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, channel_type, conversation_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_reply_claims_claimed_at
+    ON public.group_reply_claims (team_id, claimed_at);
+
+ALTER TABLE public.group_reply_claims ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.group_reply_claims FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS group_reply_claims_team_select ON public.group_reply_claims;
+DROP POLICY IF EXISTS group_reply_claims_team_insert ON public.group_reply_claims;
+DROP POLICY IF EXISTS group_reply_claims_team_update ON public.group_reply_claims;
+DROP POLICY IF EXISTS group_reply_claims_team_delete ON public.group_reply_claims;
+
+CREATE POLICY group_reply_claims_team_select ON public.group_reply_claims
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY group_reply_claims_team_insert ON public.group_reply_claims
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY group_reply_claims_team_update ON public.group_reply_claims
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY group_reply_claims_team_delete ON public.group_reply_claims
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+-- Knowledge collections are named document sets managed apart from chat
+-- memory. Chunk embeddings live in the pgvector database, namespaced by
+-- collection_id; bots opt in to collections through bot_knowledge_collections.
+CREATE TABLE IF NOT EXISTS public.knowledge_collections (
+    id                  UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id             UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                    REFERENCES public.teams(id) ON DELETE RESTRICT,
+    name                TEXT        NOT NULL,
+    description         TEXT        NOT NULL DEFAULT '',
+    embedding_model_id  UUID        REFERENCES public.models(id) ON DELETE SET NULL,
+    chunk_size          INTEGER     NOT NULL DEFAULT 1200,
+    chunk_overlap       INTEGER     NOT NULL DEFAULT 0,
+    metadata_extraction JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT knowledge_collections_team_name_unique UNIQUE (team_id, name),
+    CONSTRAINT knowledge_collections_chunk_size_check CHECK (chunk_size BETWEEN 200 AND 8000),
+    CONSTRAINT knowledge_collections_chunk_overlap_check CHECK (chunk_overlap >= 0 AND chunk_overlap * 2 <= chunk_size)
+);
+
+CREATE TABLE IF NOT EXISTS public.knowledge_documents (
+    id               UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id          UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                 REFERENCES public.teams(id) ON DELETE RESTRICT,
+    collection_id    UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    title            TEXT        NOT NULL DEFAULT '',
+    content          TEXT        NOT NULL,
+    content_hash     TEXT        NOT NULL DEFAULT '',
+    chunk_count      INTEGER     NOT NULL DEFAULT 0,
+    metadata         JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    source_url       TEXT        NOT NULL DEFAULT '',
+    crawl_source_id  UUID,
+    connector_id     UUID,
+    external_id      TEXT        NOT NULL DEFAULT '',
+    external_version TEXT        NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_collection
+    ON public.knowledge_documents (team_id, collection_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_fts
+    ON public.knowledge_documents
+    USING GIN (to_tsvector('simple', title || ' ' || content));
+
+CREATE TABLE IF NOT EXISTS public.bot_knowledge_collections (
+    team_id       UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                              REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id        UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    collection_id UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (bot_id, collection_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_knowledge_collections_collection
+    ON public.bot_knowledge_collections (collection_id);
+
+ALTER TABLE public.knowledge_collections ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_collections FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_collections_team_select ON public.knowledge_collections;
+DROP POLICY IF EXISTS knowledge_collections_team_insert ON public.knowledge_collections;
+DROP POLICY IF EXISTS knowledge_collections_team_update ON public.knowledge_collections;
+DROP POLICY IF EXISTS knowledge_collections_team_delete ON public.knowledge_collections;
+
+CREATE POLICY knowledge_collections_team_select ON public.knowledge_collections
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_collections_team_insert ON public.knowledge_collections
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_collections_team_update ON public.knowledge_collections
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_collections_team_delete ON public.knowledge_collections
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.knowledge_documents ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_documents FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_documents_team_select ON public.knowledge_documents;
+DROP POLICY IF EXISTS knowledge_documents_team_insert ON public.knowledge_documents;
+DROP POLICY IF EXISTS knowledge_documents_team_update ON public.knowledge_documents;
+DROP POLICY IF EXISTS knowledge_documents_team_delete ON public.knowledge_documents;
+
+CREATE POLICY knowledge_documents_team_select ON public.knowledge_documents
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_documents_team_insert ON public.knowledge_documents
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_documents_team_update ON public.knowledge_documents
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_documents_team_delete ON public.knowledge_documents
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.bot_knowledge_collections ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_knowledge_collections FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_knowledge_collections_team_select ON public.bot_knowledge_collections;
+DROP POLICY IF EXISTS bot_knowledge_collections_team_insert ON public.bot_knowledge_collections;
+DROP POLICY IF EXISTS bot_knowledge_collections_team_update ON public.bot_knowledge_collections;
+DROP POLICY IF EXISTS bot_knowledge_collections_team_delete ON public.bot_knowledge_collections;
+
+CREATE POLICY bot_knowledge_collections_team_select ON public.bot_knowledge_collections
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_knowledge_collections_team_insert ON public.bot_knowledge_collections
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_knowledge_collections_team_update ON public.bot_knowledge_collections
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_knowledge_collections_team_delete ON public.bot_knowledge_collections
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.knowledge_reprocess_jobs (
+    id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id         UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                REFERENCES public.teams(id) ON DELETE RESTRICT,
+    collection_id   UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    status          TEXT        NOT NULL DEFAULT 'pending',
+    error           TEXT        NOT NULL DEFAULT '',
+    documents_total INTEGER     NOT NULL DEFAULT 0,
+    documents_done  INTEGER     NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at    TIMESTAMPTZ,
+    CONSTRAINT knowledge_reprocess_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+-- At most one pending job per collection: further settings changes before
+-- the job starts fold into it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_reprocess_jobs_pending
+    ON public.knowledge_reprocess_jobs (collection_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_knowledge_reprocess_jobs_collection
+    ON public.knowledge_reprocess_jobs (team_id, collection_id, created_at);
+
+ALTER TABLE public.knowledge_reprocess_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_reprocess_jobs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_reprocess_jobs_team_select ON public.knowledge_reprocess_jobs;
+DROP POLICY IF EXISTS knowledge_reprocess_jobs_team_insert ON public.knowledge_reprocess_jobs;
+DROP POLICY IF EXISTS knowledge_reprocess_jobs_team_update ON public.knowledge_reprocess_jobs;
+DROP POLICY IF EXISTS knowledge_reprocess_jobs_team_delete ON public.knowledge_reprocess_jobs;
+
+CREATE POLICY knowledge_reprocess_jobs_team_select ON public.knowledge_reprocess_jobs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_reprocess_jobs_team_insert ON public.knowledge_reprocess_jobs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_reprocess_jobs_team_update ON public.knowledge_reprocess_jobs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_reprocess_jobs_team_delete ON public.knowledge_reprocess_jobs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.knowledge_crawl_sources (
+    id               UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id          UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                 REFERENCES public.teams(id) ON DELETE RESTRICT,
+    collection_id    UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    name             TEXT        NOT NULL,
+    seed_urls        JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    max_depth        INTEGER     NOT NULL DEFAULT 1,
+    max_pages        INTEGER     NOT NULL DEFAULT 50,
+    include_patterns JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    exclude_patterns JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    delay_ms         INTEGER     NOT NULL DEFAULT 1000,
+    refresh_pattern  TEXT        NOT NULL DEFAULT '',
+    enabled          BOOLEAN     NOT NULL DEFAULT true,
+    next_run_at      TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT knowledge_crawl_sources_depth_check CHECK (max_depth BETWEEN 0 AND 5),
+    CONSTRAINT knowledge_crawl_sources_pages_check CHECK (max_pages BETWEEN 1 AND 1000),
+    CONSTRAINT knowledge_crawl_sources_delay_check CHECK (delay_ms BETWEEN 0 AND 60000)
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_crawl_sources_collection
+    ON public.knowledge_crawl_sources (team_id, collection_id);
+CREATE INDEX IF NOT EXISTS idx_knowledge_crawl_sources_due
+    ON public.knowledge_crawl_sources (team_id, next_run_at)
+    WHERE enabled AND next_run_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS public.knowledge_crawl_runs (
+    id             UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id        UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                               REFERENCES public.teams(id) ON DELETE RESTRICT,
+    source_id      UUID        NOT NULL REFERENCES public.knowledge_crawl_sources(id) ON DELETE CASCADE,
+    status         TEXT        NOT NULL DEFAULT 'pending',
+    error          TEXT        NOT NULL DEFAULT '',
+    pages_fetched  INTEGER     NOT NULL DEFAULT 0,
+    pages_ingested INTEGER     NOT NULL DEFAULT 0,
+    pages_skipped  INTEGER     NOT NULL DEFAULT 0,
+    pages_removed  INTEGER     NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at   TIMESTAMPTZ,
+    CONSTRAINT knowledge_crawl_runs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_crawl_runs_pending
+    ON public.knowledge_crawl_runs (source_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_knowledge_crawl_runs_source
+    ON public.knowledge_crawl_runs (team_id, source_id, created_at);
+
+ALTER TABLE public.knowledge_documents
+    DROP CONSTRAINT IF EXISTS knowledge_documents_crawl_source_id_fkey,
+    ADD CONSTRAINT knowledge_documents_crawl_source_id_fkey
+        FOREIGN KEY (crawl_source_id)
+        REFERENCES public.knowledge_crawl_sources(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_crawl_source
+    ON public.knowledge_documents (crawl_source_id)
+    WHERE crawl_source_id IS NOT NULL;
+
+ALTER TABLE public.knowledge_crawl_sources ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_crawl_sources FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_crawl_sources_team_select ON public.knowledge_crawl_sources;
+DROP POLICY IF EXISTS knowledge_crawl_sources_team_insert ON public.knowledge_crawl_sources;
+DROP POLICY IF EXISTS knowledge_crawl_sources_team_update ON public.knowledge_crawl_sources;
+DROP POLICY IF EXISTS knowledge_crawl_sources_team_delete ON public.knowledge_crawl_sources;
+
+CREATE POLICY knowledge_crawl_sources_team_select ON public.knowledge_crawl_sources
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_sources_team_insert ON public.knowledge_crawl_sources
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_sources_team_update ON public.knowledge_crawl_sources
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_sources_team_delete ON public.knowledge_crawl_sources
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.knowledge_crawl_runs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_crawl_runs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_crawl_runs_team_select ON public.knowledge_crawl_runs;
+DROP POLICY IF EXISTS knowledge_crawl_runs_team_insert ON public.knowledge_crawl_runs;
+DROP POLICY IF EXISTS knowledge_crawl_runs_team_update ON public.knowledge_crawl_runs;
+DROP POLICY IF EXISTS knowledge_crawl_runs_team_delete ON public.knowledge_crawl_runs;
+
+CREATE POLICY knowledge_crawl_runs_team_select ON public.knowledge_crawl_runs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_runs_team_insert ON public.knowledge_crawl_runs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_runs_team_update ON public.knowledge_crawl_runs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_runs_team_delete ON public.knowledge_crawl_runs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_feeds (
+    id                    UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id               UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                      REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id                UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    url                   TEXT        NOT NULL,
+    title                 TEXT        NOT NULL DEFAULT '',
+    poll_interval_minutes INTEGER     NOT NULL DEFAULT 60,
+    ingest_memory         BOOLEAN     NOT NULL DEFAULT false,
+    collection_id         UUID        REFERENCES public.knowledge_collections(id) ON DELETE SET NULL,
+    digest_channel        TEXT        NOT NULL DEFAULT '',
+    digest_target         TEXT        NOT NULL DEFAULT '',
+    digest_pattern        TEXT        NOT NULL DEFAULT '',
+    enabled               BOOLEAN     NOT NULL DEFAULT true,
+    etag                  TEXT        NOT NULL DEFAULT '',
+    last_modified         TEXT        NOT NULL DEFAULT '',
+    last_error            TEXT        NOT NULL DEFAULT '',
+    last_polled_at        TIMESTAMPTZ,
+    next_poll_at          TIMESTAMPTZ,
+    next_digest_at        TIMESTAMPTZ,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT bot_feeds_bot_url_unique UNIQUE (bot_id, url),
+    CONSTRAINT bot_feeds_poll_interval_check CHECK (poll_interval_minutes BETWEEN 5 AND 10080)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_feeds_bot
+    ON public.bot_feeds (team_id, bot_id);
+CREATE INDEX IF NOT EXISTS idx_bot_feeds_due
+    ON public.bot_feeds (team_id, next_poll_at)
+    WHERE enabled AND next_poll_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS public.bot_feed_items (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id      UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                             REFERENCES public.teams(id) ON DELETE RESTRICT,
+    feed_id      UUID        NOT NULL REFERENCES public.bot_feeds(id) ON DELETE CASCADE,
+    guid         TEXT        NOT NULL,
+    title        TEXT        NOT NULL DEFAULT '',
+    link         TEXT        NOT NULL DEFAULT '',
+    summary      TEXT        NOT NULL DEFAULT '',
+    published_at TIMESTAMPTZ,
+    digested_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT bot_feed_items_feed_guid_unique UNIQUE (feed_id, guid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_feed_items_feed
+    ON public.bot_feed_items (team_id, feed_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_bot_feed_items_undigested
+    ON public.bot_feed_items (feed_id, created_at)
+    WHERE digested_at IS NULL;
+
+ALTER TABLE public.bot_feeds ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_feeds FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_feeds_team_select ON public.bot_feeds;
+DROP POLICY IF EXISTS bot_feeds_team_insert ON public.bot_feeds;
+DROP POLICY IF EXISTS bot_feeds_team_update ON public.bot_feeds;
+DROP POLICY IF EXISTS bot_feeds_team_delete ON public.bot_feeds;
+
+CREATE POLICY bot_feeds_team_select ON public.bot_feeds
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feeds_team_insert ON public.bot_feeds
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feeds_team_update ON public.bot_feeds
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feeds_team_delete ON public.bot_feeds
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.bot_feed_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_feed_items FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_feed_items_team_select ON public.bot_feed_items;
+DROP POLICY IF EXISTS bot_feed_items_team_insert ON public.bot_feed_items;
+DROP POLICY IF EXISTS bot_feed_items_team_update ON public.bot_feed_items;
+DROP POLICY IF EXISTS bot_feed_items_team_delete ON public.bot_feed_items;
+
+CREATE POLICY bot_feed_items_team_select ON public.bot_feed_items
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feed_items_team_insert ON public.bot_feed_items
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feed_items_team_update ON public.bot_feed_items
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feed_items_team_delete ON public.bot_feed_items
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.knowledge_connectors (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id       UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                              REFERENCES public.teams(id) ON DELETE RESTRICT,
+    collection_id UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    type          TEXT        NOT NULL,
+    name          TEXT        NOT NULL,
+    config        JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    sync_pattern  TEXT        NOT NULL DEFAULT '',
+    enabled       BOOLEAN     NOT NULL DEFAULT true,
+    cursor        TEXT        NOT NULL DEFAULT '',
+    next_sync_at  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_connectors_collection
+    ON public.knowledge_connectors (team_id, collection_id);
+CREATE INDEX IF NOT EXISTS idx_knowledge_connectors_due
+    ON public.knowledge_connectors (team_id, next_sync_at)
+    WHERE enabled AND next_sync_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS public.knowledge_connector_tokens (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id       UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                              REFERENCES public.teams(id) ON DELETE RESTRICT,
+    connector_id  UUID        NOT NULL UNIQUE REFERENCES public.knowledge_connectors(id) ON DELETE CASCADE,
+    account       TEXT        NOT NULL DEFAULT '',
+    access_token  TEXT        NOT NULL DEFAULT '',
+    refresh_token TEXT        NOT NULL DEFAULT '',
+    token_type    TEXT        NOT NULL DEFAULT '',
+    expires_at    TIMESTAMPTZ,
+    scope         TEXT        NOT NULL DEFAULT '',
+    state         TEXT        NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_connector_tokens_state
+    ON public.knowledge_connector_tokens (state)
+    WHERE state != '';
+
+CREATE TABLE IF NOT EXISTS public.knowledge_connector_syncs (
+    id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id            UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                   REFERENCES public.teams(id) ON DELETE RESTRICT,
+    connector_id       UUID        NOT NULL REFERENCES public.knowledge_connectors(id) ON DELETE CASCADE,
+    status             TEXT        NOT NULL DEFAULT 'pending',
+    error              TEXT        NOT NULL DEFAULT '',
+    full_sync          BOOLEAN     NOT NULL DEFAULT false,
+    items_seen         INTEGER     NOT NULL DEFAULT 0,
+    documents_ingested INTEGER     NOT NULL DEFAULT 0,
+    documents_skipped  INTEGER     NOT NULL DEFAULT 0,
+    documents_removed  INTEGER     NOT NULL DEFAULT 0,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at       TIMESTAMPTZ,
+    CONSTRAINT knowledge_connector_syncs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_connector_syncs_pending
+    ON public.knowledge_connector_syncs (connector_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_knowledge_connector_syncs_connector
+    ON public.knowledge_connector_syncs (team_id, connector_id, created_at);
+
+ALTER TABLE public.knowledge_documents
+    DROP CONSTRAINT IF EXISTS knowledge_documents_connector_id_fkey,
+    ADD CONSTRAINT knowledge_documents_connector_id_fkey
+        FOREIGN KEY (connector_id)
+        REFERENCES public.knowledge_connectors(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_documents_connector_item
+    ON public.knowledge_documents (connector_id, external_id)
+    WHERE connector_id IS NOT NULL;
+
+ALTER TABLE public.knowledge_connectors ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_connectors FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_connectors_team_select ON public.knowledge_connectors;
+DROP POLICY IF EXISTS knowledge_connectors_team_insert ON public.knowledge_connectors;
+DROP POLICY IF EXISTS knowledge_connectors_team_update ON public.knowledge_connectors;
+DROP POLICY IF EXISTS knowledge_connectors_team_delete ON public.knowledge_connectors;
+
+CREATE POLICY knowledge_connectors_team_select ON public.knowledge_connectors
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connectors_team_insert ON public.knowledge_connectors
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connectors_team_update ON public.knowledge_connectors
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connectors_team_delete ON public.knowledge_connectors
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.knowledge_connector_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_connector_tokens FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_connector_tokens_team_select ON public.knowledge_connector_tokens;
+DROP POLICY IF EXISTS knowledge_connector_tokens_team_insert ON public.knowledge_connector_tokens;
+DROP POLICY IF EXISTS knowledge_connector_tokens_team_update ON public.knowledge_connector_tokens;
+DROP POLICY IF EXISTS knowledge_connector_tokens_team_delete ON public.knowledge_connector_tokens;
+
+CREATE POLICY knowledge_connector_tokens_team_select ON public.knowledge_connector_tokens
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_tokens_team_insert ON public.knowledge_connector_tokens
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_tokens_team_update ON public.knowledge_connector_tokens
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_tokens_team_delete ON public.knowledge_connector_tokens
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.knowledge_connector_syncs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_connector_syncs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_connector_syncs_team_select ON public.knowledge_connector_syncs;
+DROP POLICY IF EXISTS knowledge_connector_syncs_team_insert ON public.knowledge_connector_syncs;
+DROP POLICY IF EXISTS knowledge_connector_syncs_team_update ON public.knowledge_connector_syncs;
+DROP POLICY IF EXISTS knowledge_connector_syncs_team_delete ON public.knowledge_connector_syncs;
+
+CREATE POLICY knowledge_connector_syncs_team_select ON public.knowledge_connector_syncs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_syncs_team_insert ON public.knowledge_connector_syncs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_syncs_team_update ON public.knowledge_connector_syncs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_syncs_team_delete ON public.knowledge_connector_syncs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.github_repos (
+    id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id         UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                REFERENCES public.teams(id) ON DELETE RESTRICT,
+    owner           TEXT        NOT NULL,
+    name            TEXT        NOT NULL,
+    installation_id BIGINT      NOT NULL,
+    collection_id   UUID        REFERENCES public.knowledge_collections(id) ON DELETE SET NULL,
+    docs_paths      TEXT[]      NOT NULL DEFAULT '{}',
+    docs_tree_sha   TEXT        NOT NULL DEFAULT '',
+    sync_status     TEXT        NOT NULL DEFAULT 'idle',
+    last_error      TEXT        NOT NULL DEFAULT '',
+    synced_at       TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT github_repos_team_repo_unique UNIQUE (team_id, owner, name),
+    CONSTRAINT github_repos_sync_status_check CHECK (sync_status IN ('idle', 'pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_repos_pending
+    ON public.github_repos (team_id, updated_at)
+    WHERE sync_status = 'pending';
+
+CREATE TABLE IF NOT EXISTS public.github_repo_documents (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    repo_id     UUID        NOT NULL REFERENCES public.github_repos(id) ON DELETE CASCADE,
+    path        TEXT        NOT NULL,
+    blob_sha    TEXT        NOT NULL,
+    document_id UUID        NOT NULL REFERENCES public.knowledge_documents(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT github_repo_documents_repo_path_unique UNIQUE (repo_id, path)
+);
+
+CREATE TABLE IF NOT EXISTS public.github_repo_subscriptions (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    repo_id     UUID        NOT NULL REFERENCES public.github_repos(id) ON DELETE CASCADE,
+    bot_id      UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    events      TEXT[]      NOT NULL DEFAULT '{issues,pull_request}',
+    can_comment BOOLEAN     NOT NULL DEFAULT false,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT github_repo_subscriptions_repo_bot_unique UNIQUE (repo_id, bot_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_repo_subscriptions_bot
+    ON public.github_repo_subscriptions (team_id, bot_id);
+
+ALTER TABLE public.github_repos ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.github_repos FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS github_repos_team_select ON public.github_repos;
+DROP POLICY IF EXISTS github_repos_team_insert ON public.github_repos;
+DROP POLICY IF EXISTS github_repos_team_update ON public.github_repos;
+DROP POLICY IF EXISTS github_repos_team_delete ON public.github_repos;
+
+CREATE POLICY github_repos_team_select ON public.github_repos
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repos_team_insert ON public.github_repos
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repos_team_update ON public.github_repos
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repos_team_delete ON public.github_repos
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.github_repo_documents ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.github_repo_documents FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS github_repo_documents_team_select ON public.github_repo_documents;
+DROP POLICY IF EXISTS github_repo_documents_team_insert ON public.github_repo_documents;
+DROP POLICY IF EXISTS github_repo_documents_team_update ON public.github_repo_documents;
+DROP POLICY IF EXISTS github_repo_documents_team_delete ON public.github_repo_documents;
+
+CREATE POLICY github_repo_documents_team_select ON public.github_repo_documents
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_documents_team_insert ON public.github_repo_documents
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_documents_team_update ON public.github_repo_documents
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_documents_team_delete ON public.github_repo_documents
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.github_repo_subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.github_repo_subscriptions FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS github_repo_subscriptions_team_select ON public.github_repo_subscriptions;
+DROP POLICY IF EXISTS github_repo_subscriptions_team_insert ON public.github_repo_subscriptions;
+DROP POLICY IF EXISTS github_repo_subscriptions_team_update ON public.github_repo_subscriptions;
+DROP POLICY IF EXISTS github_repo_subscriptions_team_delete ON public.github_repo_subscriptions;
+
+CREATE POLICY github_repo_subscriptions_team_select ON public.github_repo_subscriptions
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_subscriptions_team_insert ON public.github_repo_subscriptions
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_subscriptions_team_update ON public.github_repo_subscriptions
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_subscriptions_team_delete ON public.github_repo_subscriptions
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.schedule_feed_tokens (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id      UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                             REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id       UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    name         TEXT        NOT NULL DEFAULT '',
+    token_hash   TEXT        NOT NULL,
+    created_by   UUID        REFERENCES public.users(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT schedule_feed_tokens_token_hash_unique UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_feed_tokens_bot
+    ON public.schedule_feed_tokens (team_id, bot_id);
+
+ALTER TABLE public.schedule_feed_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.schedule_feed_tokens FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS schedule_feed_tokens_team_select ON public.schedule_feed_tokens;
+DROP POLICY IF EXISTS schedule_feed_tokens_team_insert ON public.schedule_feed_tokens;
+DROP POLICY IF EXISTS schedule_feed_tokens_team_update ON public.schedule_feed_tokens;
+DROP POLICY IF EXISTS schedule_feed_tokens_team_delete ON public.schedule_feed_tokens;
+
+CREATE POLICY schedule_feed_tokens_team_select ON public.schedule_feed_tokens
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY schedule_feed_tokens_team_insert ON public.schedule_feed_tokens
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY schedule_feed_tokens_team_update ON public.schedule_feed_tokens
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY schedule_feed_tokens_team_delete ON public.schedule_feed_tokens
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.push_devices (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id      UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                             REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id       UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    platform     TEXT        NOT NULL,
+    token        TEXT        NOT NULL,
+    name         TEXT        NOT NULL DEFAULT '',
+    muted        BOOLEAN     NOT NULL DEFAULT false,
+    muted_until  TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT push_devices_platform_check CHECK (platform IN ('apns', 'fcm')),
+    CONSTRAINT push_devices_bot_token_unique UNIQUE (bot_id, platform, token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_devices_bot_user
+    ON public.push_devices (team_id, bot_id, user_id);
+
+ALTER TABLE public.push_devices ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.push_devices FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS push_devices_team_select ON public.push_devices;
+DROP POLICY IF EXISTS push_devices_team_insert ON public.push_devices;
+DROP POLICY IF EXISTS push_devices_team_update ON public.push_devices;
+DROP POLICY IF EXISTS push_devices_team_delete ON public.push_devices;
+
+CREATE POLICY push_devices_team_select ON public.push_devices
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY push_devices_team_insert ON public.push_devices
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY push_devices_team_update ON public.push_devices
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY push_devices_team_delete ON public.push_devices
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.memory_flags (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id      UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    memory_id   TEXT        NOT NULL,
+    memory      TEXT        NOT NULL DEFAULT '',
+    reason      TEXT        NOT NULL DEFAULT '',
+    session_id  TEXT        NOT NULL DEFAULT '',
+    status      TEXT        NOT NULL DEFAULT 'pending',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at TIMESTAMPTZ,
+    CONSTRAINT memory_flags_status_check CHECK (status IN ('pending', 'confirmed', 'dismissed'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_memory_flags_pending
+    ON public.memory_flags (bot_id, memory_id)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_memory_flags_bot_status
+    ON public.memory_flags (team_id, bot_id, status, created_at DESC);
+
+ALTER TABLE public.memory_flags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.memory_flags FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS memory_flags_team_select ON public.memory_flags;
+DROP POLICY IF EXISTS memory_flags_team_insert ON public.memory_flags;
+DROP POLICY IF EXISTS memory_flags_team_update ON public.memory_flags;
+DROP POLICY IF EXISTS memory_flags_team_delete ON public.memory_flags;
+
+CREATE POLICY memory_flags_team_select ON public.memory_flags
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_flags_team_insert ON public.memory_flags
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_flags_team_update ON public.memory_flags
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_flags_team_delete ON public.memory_flags
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_output_processors (
+    bot_id     UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    processors JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.bot_output_processors ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_output_processors FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_output_processors_team_select ON public.bot_output_processors;
+DROP POLICY IF EXISTS bot_output_processors_team_insert ON public.bot_output_processors;
+DROP POLICY IF EXISTS bot_output_processors_team_update ON public.bot_output_processors;
+DROP POLICY IF EXISTS bot_output_processors_team_delete ON public.bot_output_processors;
+
+CREATE POLICY bot_output_processors_team_select ON public.bot_output_processors
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_output_processors_team_insert ON public.bot_output_processors
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_output_processors_team_update ON public.bot_output_processors
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_output_processors_team_delete ON public.bot_output_processors
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_sticker_catalogs (
+    bot_id     UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    stickers   JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.bot_sticker_catalogs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_sticker_catalogs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_sticker_catalogs_team_select ON public.bot_sticker_catalogs;
+DROP POLICY IF EXISTS bot_sticker_catalogs_team_insert ON public.bot_sticker_catalogs;
+DROP POLICY IF EXISTS bot_sticker_catalogs_team_update ON public.bot_sticker_catalogs;
+DROP POLICY IF EXISTS bot_sticker_catalogs_team_delete ON public.bot_sticker_catalogs;
+
+CREATE POLICY bot_sticker_catalogs_team_select ON public.bot_sticker_catalogs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_sticker_catalogs_team_insert ON public.bot_sticker_catalogs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_sticker_catalogs_team_update ON public.bot_sticker_catalogs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_sticker_catalogs_team_delete ON public.bot_sticker_catalogs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.inbound_failures (
+    id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id           UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                  REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id            UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    channel_config_id TEXT        NOT NULL DEFAULT '',
+    channel_type      TEXT        NOT NULL,
+    message           JSONB       NOT NULL,
+    error             TEXT        NOT NULL DEFAULT '',
+    attempts          INTEGER     NOT NULL DEFAULT 0,
+    status            TEXT        NOT NULL DEFAULT 'pending'
+                                  CHECK (status IN ('pending', 'replayed')),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    replayed_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS inbound_failures_status_created_idx
+    ON public.inbound_failures (team_id, status, created_at DESC);
+
+ALTER TABLE public.inbound_failures ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.inbound_failures FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS inbound_failures_team_select ON public.inbound_failures;
+DROP POLICY IF EXISTS inbound_failures_team_insert ON public.inbound_failures;
+DROP POLICY IF EXISTS inbound_failures_team_update ON public.inbound_failures;
+DROP POLICY IF EXISTS inbound_failures_team_delete ON public.inbound_failures;
+
+CREATE POLICY inbound_failures_team_select ON public.inbound_failures
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY inbound_failures_team_insert ON public.inbound_failures
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY inbound_failures_team_update ON public.inbound_failures
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY inbound_failures_team_delete ON public.inbound_failures
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_contact_profiles (
+    bot_id              UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    channel_identity_id UUID        NOT NULL REFERENCES public.channel_identities(id) ON DELETE CASCADE,
+    team_id             UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                    REFERENCES public.teams(id) ON DELETE RESTRICT,
+    display_name        TEXT        NOT NULL DEFAULT '',
+    timezone            TEXT        NOT NULL DEFAULT '',
+    language            TEXT        NOT NULL DEFAULT '',
+    timezone_source     TEXT        NOT NULL DEFAULT ''
+                                    CHECK (timezone_source IN ('', 'user', 'platform')),
+    onboarding_status   TEXT        NOT NULL DEFAULT 'completed'
+                                    CHECK (onboarding_status IN ('pending', 'collecting', 'completed')),
+    pending_fields      TEXT[]      NOT NULL DEFAULT '{}',
+    linked_user_id      UUID,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at        TIMESTAMPTZ,
+    PRIMARY KEY (bot_id, channel_identity_id)
+);
+
+CREATE INDEX IF NOT EXISTS bot_contact_profiles_team_bot_idx
+    ON public.bot_contact_profiles (team_id, bot_id);
+
+ALTER TABLE public.bot_contact_profiles ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_contact_profiles FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_contact_profiles_team_select ON public.bot_contact_profiles;
+DROP POLICY IF EXISTS bot_contact_profiles_team_insert ON public.bot_contact_profiles;
+DROP POLICY IF EXISTS bot_contact_profiles_team_update ON public.bot_contact_profiles;
+DROP POLICY IF EXISTS bot_contact_profiles_team_delete ON public.bot_contact_profiles;
+
+CREATE POLICY bot_contact_profiles_team_select ON public.bot_contact_profiles
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_contact_profiles_team_insert ON public.bot_contact_profiles
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_contact_profiles_team_update ON public.bot_contact_profiles
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_contact_profiles_team_delete ON public.bot_contact_profiles
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_working_hours (
+    bot_id     UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    config     JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.bot_working_hours ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_working_hours FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_working_hours_team_select ON public.bot_working_hours;
+DROP POLICY IF EXISTS bot_working_hours_team_insert ON public.bot_working_hours;
+DROP POLICY IF EXISTS bot_working_hours_team_update ON public.bot_working_hours;
+DROP POLICY IF EXISTS bot_working_hours_team_delete ON public.bot_working_hours;
+
+CREATE POLICY bot_working_hours_team_select ON public.bot_working_hours
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_working_hours_team_insert ON public.bot_working_hours
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_working_hours_team_update ON public.bot_working_hours
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_working_hours_team_delete ON public.bot_working_hours
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.deferred_inbound_messages (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id      UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                             REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id       UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    channel_type TEXT        NOT NULL,
+    message      JSONB       NOT NULL,
+    deliver_at   TIMESTAMPTZ NOT NULL,
+    status       TEXT        NOT NULL DEFAULT 'pending'
+                             CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    error        TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS deferred_inbound_messages_due_idx
+    ON public.deferred_inbound_messages (team_id, deliver_at)
+    WHERE status = 'pending';
+
+ALTER TABLE public.deferred_inbound_messages ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.deferred_inbound_messages FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS deferred_inbound_messages_team_select ON public.deferred_inbound_messages;
+DROP POLICY IF EXISTS deferred_inbound_messages_team_insert ON public.deferred_inbound_messages;
+DROP POLICY IF EXISTS deferred_inbound_messages_team_update ON public.deferred_inbound_messages;
+DROP POLICY IF EXISTS deferred_inbound_messages_team_delete ON public.deferred_inbound_messages;
+
+CREATE POLICY deferred_inbound_messages_team_select ON public.deferred_inbound_messages
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY deferred_inbound_messages_team_insert ON public.deferred_inbound_messages
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY deferred_inbound_messages_team_update ON public.deferred_inbound_messages
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY deferred_inbound_messages_team_delete ON public.deferred_inbound_messages
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_conversation_flows (
+    bot_id     UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    config     JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.bot_conversation_flows ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_conversation_flows FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_conversation_flows_team_select ON public.bot_conversation_flows;
+DROP POLICY IF EXISTS bot_conversation_flows_team_insert ON public.bot_conversation_flows;
+DROP POLICY IF EXISTS bot_conversation_flows_team_update ON public.bot_conversation_flows;
+DROP POLICY IF EXISTS bot_conversation_flows_team_delete ON public.bot_conversation_flows;
+
+CREATE POLICY bot_conversation_flows_team_select ON public.bot_conversation_flows
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_conversation_flows_team_insert ON public.bot_conversation_flows
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_conversation_flows_team_update ON public.bot_conversation_flows
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_conversation_flows_team_delete ON public.bot_conversation_flows
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_http_tools (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    bot_id     UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    name       TEXT        NOT NULL,
+    base_url   TEXT        NOT NULL,
+    operations JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    auth       JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    enabled    BOOLEAN     NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT bot_http_tools_unique UNIQUE (bot_id, name)
+);
+
+ALTER TABLE public.bot_http_tools ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_http_tools FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_http_tools_team_select ON public.bot_http_tools;
+DROP POLICY IF EXISTS bot_http_tools_team_insert ON public.bot_http_tools;
+DROP POLICY IF EXISTS bot_http_tools_team_update ON public.bot_http_tools;
+DROP POLICY IF EXISTS bot_http_tools_team_delete ON public.bot_http_tools;
+
+CREATE POLICY bot_http_tools_team_select ON public.bot_http_tools
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_http_tools_team_insert ON public.bot_http_tools
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_http_tools_team_update ON public.bot_http_tools
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_http_tools_team_delete ON public.bot_http_tools
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_history_message_quarantine (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id     UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    message_id UUID        REFERENCES public.bot_history_messages(id) ON DELETE SET NULL,
+    role       TEXT        NOT NULL,
+    payload    TEXT        NOT NULL,
+    reason     TEXT        NOT NULL DEFAULT '',
+    source     TEXT        NOT NULL CHECK (source IN ('persist', 'repair')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS bot_history_message_quarantine_bot_created_idx
+    ON public.bot_history_message_quarantine (team_id, bot_id, created_at DESC);
+
+ALTER TABLE public.bot_history_message_quarantine ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_history_message_quarantine FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_history_message_quarantine_team_select ON public.bot_history_message_quarantine;
+DROP POLICY IF EXISTS bot_history_message_quarantine_team_insert ON public.bot_history_message_quarantine;
+DROP POLICY IF EXISTS bot_history_message_quarantine_team_update ON public.bot_history_message_quarantine;
+DROP POLICY IF EXISTS bot_history_message_quarantine_team_delete ON public.bot_history_message_quarantine;
+
+CREATE POLICY bot_history_message_quarantine_team_select ON public.bot_history_message_quarantine
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_quarantine_team_insert ON public.bot_history_message_quarantine
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_quarantine_team_update ON public.bot_history_message_quarantine
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_quarantine_team_delete ON public.bot_history_message_quarantine
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_memory_compact_schedules (
+    bot_id     UUID             PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID             NOT NULL DEFAULT public.memoh_current_team_id()
+                                REFERENCES public.teams(id) ON DELETE RESTRICT,
+    pattern    TEXT             NOT NULL,
+    timezone   TEXT             NOT NULL DEFAULT '',
+    ratio      DOUBLE PRECISION NOT NULL CHECK (ratio > 0 AND ratio <= 1),
+    decay_days INTEGER          NOT NULL DEFAULT 0 CHECK (decay_days >= 0),
+    enabled    BOOLEAN          NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS public.memory_compact_runs (
+    id            UUID             PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id       UUID             NOT NULL DEFAULT public.memoh_current_team_id()
+                                   REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id        UUID             NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    source        TEXT             NOT NULL CHECK (source IN ('manual', 'schedule')),
+    status        TEXT             NOT NULL DEFAULT 'running'
+                                   CHECK (status IN ('running', 'ok', 'error')),
+    ratio         DOUBLE PRECISION NOT NULL,
+    decay_days    INTEGER          NOT NULL DEFAULT 0,
+    before_count  INTEGER          NOT NULL DEFAULT 0,
+    after_count   INTEGER          NOT NULL DEFAULT 0,
+    error_message TEXT             NOT NULL DEFAULT '',
+    started_at    TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    completed_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS memory_compact_runs_bot_started_idx
+    ON public.memory_compact_runs (team_id, bot_id, started_at DESC);
+
+ALTER TABLE public.bot_memory_compact_schedules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_memory_compact_schedules FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.memory_compact_runs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.memory_compact_runs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_memory_compact_schedules_team_select ON public.bot_memory_compact_schedules;
+DROP POLICY IF EXISTS bot_memory_compact_schedules_team_insert ON public.bot_memory_compact_schedules;
+DROP POLICY IF EXISTS bot_memory_compact_schedules_team_update ON public.bot_memory_compact_schedules;
+DROP POLICY IF EXISTS bot_memory_compact_schedules_team_delete ON public.bot_memory_compact_schedules;
+DROP POLICY IF EXISTS memory_compact_runs_team_select ON public.memory_compact_runs;
+DROP POLICY IF EXISTS memory_compact_runs_team_insert ON public.memory_compact_runs;
+DROP POLICY IF EXISTS memory_compact_runs_team_update ON public.memory_compact_runs;
+DROP POLICY IF EXISTS memory_compact_runs_team_delete ON public.memory_compact_runs;
+
+CREATE POLICY bot_memory_compact_schedules_team_select ON public.bot_memory_compact_schedules
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_compact_schedules_team_insert ON public.bot_memory_compact_schedules
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_compact_schedules_team_update ON public.bot_memory_compact_schedules
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_compact_schedules_team_delete ON public.bot_memory_compact_schedules
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE POLICY memory_compact_runs_team_select ON public.memory_compact_runs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_compact_runs_team_insert ON public.memory_compact_runs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_compact_runs_team_update ON public.memory_compact_runs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_compact_runs_team_delete ON public.memory_compact_runs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_history_message_archives (
+    message_id  UUID        PRIMARY KEY,
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id      UUID        NOT NULL,
+    object_key  TEXT        NOT NULL,
+    size_bytes  BIGINT      NOT NULL DEFAULT 0,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS bot_history_message_archives_bot_idx
+    ON public.bot_history_message_archives (team_id, bot_id);
+
+ALTER TABLE public.bot_history_message_archives ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_history_message_archives FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_history_message_archives_team_select ON public.bot_history_message_archives;
+DROP POLICY IF EXISTS bot_history_message_archives_team_insert ON public.bot_history_message_archives;
+DROP POLICY IF EXISTS bot_history_message_archives_team_update ON public.bot_history_message_archives;
+DROP POLICY IF EXISTS bot_history_message_archives_team_delete ON public.bot_history_message_archives;
+
+CREATE POLICY bot_history_message_archives_team_select ON public.bot_history_message_archives
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_archives_team_insert ON public.bot_history_message_archives
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_archives_team_update ON public.bot_history_message_archives
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_archives_team_delete ON public.bot_history_message_archives
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.feature_flags (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    flag_key   TEXT        NOT NULL,
+    bot_id     UUID        REFERENCES public.bots(id) ON DELETE CASCADE,
+    enabled    BOOLEAN     NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT feature_flags_scope_unique UNIQUE NULLS NOT DISTINCT (team_id, flag_key, bot_id)
+);
+
+ALTER TABLE public.feature_flags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.feature_flags FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS feature_flags_team_select ON public.feature_flags;
+DROP POLICY IF EXISTS feature_flags_team_insert ON public.feature_flags;
+DROP POLICY IF EXISTS feature_flags_team_update ON public.feature_flags;
+DROP POLICY IF EXISTS feature_flags_team_delete ON public.feature_flags;
+
+CREATE POLICY feature_flags_team_select ON public.feature_flags
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY feature_flags_team_insert ON public.feature_flags
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY feature_flags_team_update ON public.feature_flags
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY feature_flags_team_delete ON public.feature_flags
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.auth_refresh_tokens (
+    id             UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id        UUID        NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    family_id      UUID        NOT NULL,
+    token_hash     TEXT        NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at     TIMESTAMPTZ,
+    revoked_reason TEXT,
+    CONSTRAINT auth_refresh_tokens_hash_unique UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_refresh_tokens_user
+    ON public.auth_refresh_tokens (user_id)
+    WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_auth_refresh_tokens_family
+    ON public.auth_refresh_tokens (family_id);
+
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'operator';
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'bot_manager';
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'viewer';
+
+CREATE TABLE IF NOT EXISTS public.bot_history_search_indexes (
+    bot_id             UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id            UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                   REFERENCES public.teams(id) ON DELETE RESTRICT,
+    embedding_model_id UUID        NOT NULL REFERENCES public.models(id) ON DELETE CASCADE,
+    cursor_created_at  TIMESTAMPTZ,
+    cursor_message_id  UUID,
+    indexed_count      BIGINT      NOT NULL DEFAULT 0,
+    last_error         TEXT        NOT NULL DEFAULT '',
+    last_indexed_at    TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_history_search_indexes_team
+    ON public.bot_history_search_indexes (team_id);
+
+ALTER TABLE public.bot_history_search_indexes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_history_search_indexes FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_history_search_indexes_team_select ON public.bot_history_search_indexes;
+DROP POLICY IF EXISTS bot_history_search_indexes_team_insert ON public.bot_history_search_indexes;
+DROP POLICY IF EXISTS bot_history_search_indexes_team_update ON public.bot_history_search_indexes;
+DROP POLICY IF EXISTS bot_history_search_indexes_team_delete ON public.bot_history_search_indexes;
+
+CREATE POLICY bot_history_search_indexes_team_select ON public.bot_history_search_indexes
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_search_indexes_team_insert ON public.bot_history_search_indexes
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_search_indexes_team_update ON public.bot_history_search_indexes
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_search_indexes_team_delete ON public.bot_history_search_indexes
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.maintenance_mode (
+    team_id    UUID        PRIMARY KEY DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    enabled    BOOLEAN     NOT NULL DEFAULT false,
+    message    TEXT        NOT NULL DEFAULT '',
+    updated_by UUID        REFERENCES public.users(id) ON DELETE SET NULL,
+    started_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.maintenance_mode ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.maintenance_mode FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS maintenance_mode_team_select ON public.maintenance_mode;
+DROP POLICY IF EXISTS maintenance_mode_team_insert ON public.maintenance_mode;
+DROP POLICY IF EXISTS maintenance_mode_team_update ON public.maintenance_mode;
+DROP POLICY IF EXISTS maintenance_mode_team_delete ON public.maintenance_mode;
+
+CREATE POLICY maintenance_mode_team_select ON public.maintenance_mode
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY maintenance_mode_team_insert ON public.maintenance_mode
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY maintenance_mode_team_update ON public.maintenance_mode
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY maintenance_mode_team_delete ON public.maintenance_mode
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE bots
+  ADD COLUMN IF NOT EXISTS inbound_rate_limit INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS inbound_rate_burst INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE bots
+  ADD COLUMN IF NOT EXISTS wake_words_enabled BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS wake_words TEXT[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS wake_word_cooldown_seconds INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE public.audit_log
+  ADD COLUMN IF NOT EXISTS actor_ip TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS diff JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_action
+    ON public.audit_log (team_id, action, created_at DESC);
+
+ALTER TABLE public.bot_history_round_keys
+  ADD COLUMN IF NOT EXISTS memory_pending BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE bots
+  ADD COLUMN IF NOT EXISTS persona JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE TABLE IF NOT EXISTS public.bot_memory_reviews (
+    bot_id           UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id          UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                 REFERENCES public.teams(id) ON DELETE RESTRICT,
+    enabled          BOOLEAN     NOT NULL DEFAULT false,
+    channel_type     TEXT        NOT NULL DEFAULT '',
+    target           TEXT        NOT NULL DEFAULT '',
+    next_review_at   TIMESTAMPTZ,
+    last_reviewed_at TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_memory_reviews_due
+    ON public.bot_memory_reviews (team_id, next_review_at)
+    WHERE enabled AND next_review_at IS NOT NULL;
+
+ALTER TABLE public.bot_memory_reviews ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_memory_reviews FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_memory_reviews_team_select ON public.bot_memory_reviews;
+DROP POLICY IF EXISTS bot_memory_reviews_team_insert ON public.bot_memory_reviews;
+DROP POLICY IF EXISTS bot_memory_reviews_team_update ON public.bot_memory_reviews;
+DROP POLICY IF EXISTS bot_memory_reviews_team_delete ON public.bot_memory_reviews;
+
+CREATE POLICY bot_memory_reviews_team_select ON public.bot_memory_reviews
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_reviews_team_insert ON public.bot_memory_reviews
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_reviews_team_update ON public.bot_memory_reviews
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_reviews_team_delete ON public.bot_memory_reviews
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.channel_outbox (
+    id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id           UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                  REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id            UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    channel_config_id TEXT        NOT NULL DEFAULT '',
+    channel_type      TEXT        NOT NULL,
+    target            TEXT        NOT NULL,
+    message           JSONB       NOT NULL,
+    error             TEXT        NOT NULL DEFAULT '',
+    attempts          INTEGER     NOT NULL DEFAULT 0,
+    status            TEXT        NOT NULL DEFAULT 'pending'
+                                  CHECK (status IN ('pending', 'sent', 'dead')),
+    next_attempt_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at           TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS channel_outbox_due_idx
+    ON public.channel_outbox (team_id, next_attempt_at)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS channel_outbox_status_created_idx
+    ON public.channel_outbox (team_id, status, created_at DESC);
+
+ALTER TABLE public.channel_outbox ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.channel_outbox FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS channel_outbox_team_select ON public.channel_outbox;
+DROP POLICY IF EXISTS channel_outbox_team_insert ON public.channel_outbox;
+DROP POLICY IF EXISTS channel_outbox_team_update ON public.channel_outbox;
+DROP POLICY IF EXISTS channel_outbox_team_delete ON public.channel_outbox;
+
+CREATE POLICY channel_outbox_team_select ON public.channel_outbox
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY channel_outbox_team_insert ON public.channel_outbox
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY channel_outbox_team_update ON public.channel_outbox
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY channel_outbox_team_delete ON public.channel_outbox
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.bot_message_tagging (
+    bot_id         UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id        UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                               REFERENCES public.teams(id) ON DELETE RESTRICT,
+    enabled        BOOLEAN     NOT NULL DEFAULT false,
+    model_id       UUID        REFERENCES public.models(id) ON DELETE SET NULL,
+    topics         TEXT[]      NOT NULL DEFAULT '{}',
+    enabled_at     TIMESTAMPTZ,
+    last_error     TEXT        NOT NULL DEFAULT '',
+    last_tagged_at TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_message_tagging_enabled
+    ON public.bot_message_tagging (team_id)
+    WHERE enabled;
+
+ALTER TABLE public.bot_message_tagging ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_message_tagging FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_message_tagging_team_select ON public.bot_message_tagging;
+DROP POLICY IF EXISTS bot_message_tagging_team_insert ON public.bot_message_tagging;
+DROP POLICY IF EXISTS bot_message_tagging_team_update ON public.bot_message_tagging;
+DROP POLICY IF EXISTS bot_message_tagging_team_delete ON public.bot_message_tagging;
+
+CREATE POLICY bot_message_tagging_team_select ON public.bot_message_tagging
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_message_tagging_team_insert ON public.bot_message_tagging
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_message_tagging_team_update ON public.bot_message_tagging
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_message_tagging_team_delete ON public.bot_message_tagging
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.inbound_failures
+  ADD COLUMN IF NOT EXISTS channel_identity_id UUID
+    REFERENCES public.channel_identities(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS inbound_failures_channel_identity_idx
+    ON public.inbound_failures (channel_identity_id)
+    WHERE channel_identity_id IS NOT NULL;
+
+ALTER TABLE public.channel_outbox
+  ADD COLUMN IF NOT EXISTS channel_identity_id UUID
+    REFERENCES public.channel_identities(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS channel_outbox_channel_identity_idx
+    ON public.channel_outbox (channel_identity_id)
+    WHERE channel_identity_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS channel_outbox_sent_idx
+    ON public.channel_outbox (team_id, sent_at)
+    WHERE status = 'sent';
+
+ALTER TABLE public.bot_history_round_keys
+  ADD COLUMN IF NOT EXISTS memory_claimed_at TIMESTAMPTZ;
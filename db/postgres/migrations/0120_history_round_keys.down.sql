@@ -0,0 +1,4 @@
+-- 0120_history_round_keys
+-- Remove per-round history idempotency keys.
+
+DROP TABLE IF EXISTS public.bot_history_round_keys;
@@ -0,0 +1,34 @@
+-- 0120_history_round_keys
+-- Record per-round idempotency keys so retried round persistence does not duplicate history messages.
+
+CREATE TABLE IF NOT EXISTS public.bot_history_round_keys (
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    session_id  UUID        NOT NULL,
+    round_key   TEXT        NOT NULL,
+    message_ids UUID[]      NOT NULL DEFAULT '{}'::uuid[],
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, session_id, round_key),
+    CONSTRAINT bot_history_round_keys_session_id_fkey
+        FOREIGN KEY (team_id, session_id)
+        REFERENCES public.bot_sessions(team_id, id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.bot_history_round_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_history_round_keys FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_history_round_keys_team_select ON public.bot_history_round_keys;
+DROP POLICY IF EXISTS bot_history_round_keys_team_insert ON public.bot_history_round_keys;
+DROP POLICY IF EXISTS bot_history_round_keys_team_update ON public.bot_history_round_keys;
+DROP POLICY IF EXISTS bot_history_round_keys_team_delete ON public.bot_history_round_keys;
+
+CREATE POLICY bot_history_round_keys_team_select ON public.bot_history_round_keys
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_round_keys_team_insert ON public.bot_history_round_keys
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_round_keys_team_update ON public.bot_history_round_keys
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_round_keys_team_delete ON public.bot_history_round_keys
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
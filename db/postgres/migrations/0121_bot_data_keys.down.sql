@@ -0,0 +1,4 @@
+-- 0121_bot_data_keys
+-- Remove per-bot content encryption keys.
+
+DROP TABLE IF EXISTS public.bot_data_keys;
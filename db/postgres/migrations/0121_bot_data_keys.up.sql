@@ -0,0 +1,33 @@
+-- 0121_bot_data_keys
+-- Store per-bot data keys, wrapped by the deployment master key, used to encrypt conversation content at rest.
+
+CREATE TABLE IF NOT EXISTS public.bot_data_keys (
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id      UUID        NOT NULL,
+    wrapped_key BYTEA       NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, bot_id),
+    CONSTRAINT bot_data_keys_bot_id_fkey
+        FOREIGN KEY (team_id, bot_id)
+        REFERENCES public.bots(team_id, id) ON DELETE CASCADE
+);
+
+ALTER TABLE public.bot_data_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_data_keys FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_data_keys_team_select ON public.bot_data_keys;
+DROP POLICY IF EXISTS bot_data_keys_team_insert ON public.bot_data_keys;
+DROP POLICY IF EXISTS bot_data_keys_team_update ON public.bot_data_keys;
+DROP POLICY IF EXISTS bot_data_keys_team_delete ON public.bot_data_keys;
+
+CREATE POLICY bot_data_keys_team_select ON public.bot_data_keys
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_data_keys_team_insert ON public.bot_data_keys
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_data_keys_team_update ON public.bot_data_keys
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_data_keys_team_delete ON public.bot_data_keys
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0122_audit_log
+-- Remove the administrative audit log.
+
+DROP TABLE IF EXISTS public.audit_log;
@@ -0,0 +1,38 @@
+-- 0122_audit_log
+-- Record privileged administrative actions, such as erasing a channel identity's data.
+
+CREATE TABLE IF NOT EXISTS public.audit_log (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id       UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                              REFERENCES public.teams(id) ON DELETE RESTRICT,
+    actor_user_id UUID,
+    action        TEXT        NOT NULL,
+    target_type   TEXT        NOT NULL,
+    target_id     TEXT        NOT NULL,
+    details       JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_team_created
+    ON public.audit_log (team_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target
+    ON public.audit_log (team_id, target_type, target_id);
+
+ALTER TABLE public.audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.audit_log FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS audit_log_team_select ON public.audit_log;
+DROP POLICY IF EXISTS audit_log_team_insert ON public.audit_log;
+DROP POLICY IF EXISTS audit_log_team_update ON public.audit_log;
+DROP POLICY IF EXISTS audit_log_team_delete ON public.audit_log;
+
+CREATE POLICY audit_log_team_select ON public.audit_log
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY audit_log_team_insert ON public.audit_log
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY audit_log_team_update ON public.audit_log
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY audit_log_team_delete ON public.audit_log
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0123_data_export_jobs
+-- Remove data-subject export job tracking.
+
+DROP TABLE IF EXISTS public.data_export_jobs;
@@ -0,0 +1,46 @@
+-- 0123_data_export_jobs
+-- Track asynchronous data-subject export bundles for users and channel identities.
+
+CREATE TABLE IF NOT EXISTS public.data_export_jobs (
+    id                   UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id              UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                     REFERENCES public.teams(id) ON DELETE RESTRICT,
+    requested_by_user_id UUID,
+    subject_type         TEXT        NOT NULL,
+    subject_id           UUID        NOT NULL,
+    status               TEXT        NOT NULL DEFAULT 'pending',
+    error                TEXT        NOT NULL DEFAULT '',
+    file_path            TEXT        NOT NULL DEFAULT '',
+    size_bytes           BIGINT      NOT NULL DEFAULT 0,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at         TIMESTAMPTZ,
+    expires_at           TIMESTAMPTZ,
+    CONSTRAINT data_export_jobs_subject_type_check CHECK (subject_type IN ('user', 'channel_identity')),
+    CONSTRAINT data_export_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_export_jobs_status
+    ON public.data_export_jobs (team_id, status, created_at);
+CREATE INDEX IF NOT EXISTS idx_data_export_jobs_expires
+    ON public.data_export_jobs (team_id, expires_at)
+    WHERE expires_at IS NOT NULL;
+
+ALTER TABLE public.data_export_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.data_export_jobs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS data_export_jobs_team_select ON public.data_export_jobs;
+DROP POLICY IF EXISTS data_export_jobs_team_insert ON public.data_export_jobs;
+DROP POLICY IF EXISTS data_export_jobs_team_update ON public.data_export_jobs;
+DROP POLICY IF EXISTS data_export_jobs_team_delete ON public.data_export_jobs;
+
+CREATE POLICY data_export_jobs_team_select ON public.data_export_jobs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY data_export_jobs_team_insert ON public.data_export_jobs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY data_export_jobs_team_update ON public.data_export_jobs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY data_export_jobs_team_delete ON public.data_export_jobs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0124_group_reply_claims
+-- Remove multi-bot group reply claims.
+
+DROP TABLE IF EXISTS public.group_reply_claims;
@@ -0,0 +1,35 @@
+-- 0124_group_reply_claims
+-- Record which bot claimed the reply to a group message so only one coordinated bot answers.
+
+CREATE TABLE IF NOT EXISTS public.group_reply_claims (
+    team_id         UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                REFERENCES public.teams(id) ON DELETE RESTRICT,
+    channel_type    TEXT        NOT NULL,
+    conversation_id TEXT        NOT NULL,
+    message_id      TEXT        NOT NULL,
+    bot_id          UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    claimed_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, channel_type, conversation_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_reply_claims_claimed_at
+    ON public.group_reply_claims (team_id, claimed_at);
+
+ALTER TABLE public.group_reply_claims ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.group_reply_claims FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS group_reply_claims_team_select ON public.group_reply_claims;
+DROP POLICY IF EXISTS group_reply_claims_team_insert ON public.group_reply_claims;
+DROP POLICY IF EXISTS group_reply_claims_team_update ON public.group_reply_claims;
+DROP POLICY IF EXISTS group_reply_claims_team_delete ON public.group_reply_claims;
+
+CREATE POLICY group_reply_claims_team_select ON public.group_reply_claims
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY group_reply_claims_team_insert ON public.group_reply_claims
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY group_reply_claims_team_update ON public.group_reply_claims
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY group_reply_claims_team_delete ON public.group_reply_claims
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,6 @@
+-- 0125_knowledge_collections
+-- Remove knowledge base collections.
+
+DROP TABLE IF EXISTS public.bot_knowledge_collections;
+DROP TABLE IF EXISTS public.knowledge_documents;
+DROP TABLE IF EXISTS public.knowledge_collections;
@@ -0,0 +1,103 @@
+-- 0125_knowledge_collections
+-- Add knowledge base collections: named document sets attached to bots and
+-- retrieved alongside personal memory.
+
+CREATE TABLE IF NOT EXISTS public.knowledge_collections (
+    id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id            UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                   REFERENCES public.teams(id) ON DELETE RESTRICT,
+    name               TEXT        NOT NULL,
+    description        TEXT        NOT NULL DEFAULT '',
+    embedding_model_id UUID        REFERENCES public.models(id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT knowledge_collections_team_name_unique UNIQUE (team_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS public.knowledge_documents (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id       UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                              REFERENCES public.teams(id) ON DELETE RESTRICT,
+    collection_id UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    title         TEXT        NOT NULL DEFAULT '',
+    content       TEXT        NOT NULL,
+    content_hash  TEXT        NOT NULL DEFAULT '',
+    chunk_count   INTEGER     NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_collection
+    ON public.knowledge_documents (team_id, collection_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_fts
+    ON public.knowledge_documents
+    USING GIN (to_tsvector('simple', title || ' ' || content));
+
+CREATE TABLE IF NOT EXISTS public.bot_knowledge_collections (
+    team_id       UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                              REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id        UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    collection_id UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (bot_id, collection_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_knowledge_collections_collection
+    ON public.bot_knowledge_collections (collection_id);
+
+ALTER TABLE public.knowledge_collections ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_collections FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_collections_team_select ON public.knowledge_collections;
+DROP POLICY IF EXISTS knowledge_collections_team_insert ON public.knowledge_collections;
+DROP POLICY IF EXISTS knowledge_collections_team_update ON public.knowledge_collections;
+DROP POLICY IF EXISTS knowledge_collections_team_delete ON public.knowledge_collections;
+
+CREATE POLICY knowledge_collections_team_select ON public.knowledge_collections
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_collections_team_insert ON public.knowledge_collections
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_collections_team_update ON public.knowledge_collections
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_collections_team_delete ON public.knowledge_collections
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.knowledge_documents ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_documents FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_documents_team_select ON public.knowledge_documents;
+DROP POLICY IF EXISTS knowledge_documents_team_insert ON public.knowledge_documents;
+DROP POLICY IF EXISTS knowledge_documents_team_update ON public.knowledge_documents;
+DROP POLICY IF EXISTS knowledge_documents_team_delete ON public.knowledge_documents;
+
+CREATE POLICY knowledge_documents_team_select ON public.knowledge_documents
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_documents_team_insert ON public.knowledge_documents
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_documents_team_update ON public.knowledge_documents
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_documents_team_delete ON public.knowledge_documents
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.bot_knowledge_collections ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_knowledge_collections FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_knowledge_collections_team_select ON public.bot_knowledge_collections;
+DROP POLICY IF EXISTS bot_knowledge_collections_team_insert ON public.bot_knowledge_collections;
+DROP POLICY IF EXISTS bot_knowledge_collections_team_update ON public.bot_knowledge_collections;
+DROP POLICY IF EXISTS bot_knowledge_collections_team_delete ON public.bot_knowledge_collections;
+
+CREATE POLICY bot_knowledge_collections_team_select ON public.bot_knowledge_collections
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_knowledge_collections_team_insert ON public.bot_knowledge_collections
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_knowledge_collections_team_update ON public.bot_knowledge_collections
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_knowledge_collections_team_delete ON public.bot_knowledge_collections
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,14 @@
+-- 0126_knowledge_collection_settings
+-- Remove per-collection processing settings and re-processing jobs.
+
+DROP TABLE IF EXISTS public.knowledge_reprocess_jobs;
+
+ALTER TABLE public.knowledge_documents
+    DROP COLUMN IF EXISTS metadata;
+
+ALTER TABLE public.knowledge_collections
+    DROP CONSTRAINT IF EXISTS knowledge_collections_chunk_overlap_check,
+    DROP CONSTRAINT IF EXISTS knowledge_collections_chunk_size_check,
+    DROP COLUMN IF EXISTS metadata_extraction,
+    DROP COLUMN IF EXISTS chunk_overlap,
+    DROP COLUMN IF EXISTS chunk_size;
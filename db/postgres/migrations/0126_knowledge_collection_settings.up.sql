@@ -0,0 +1,62 @@
+-- 0126_knowledge_collection_settings
+-- Add per-collection chunking and metadata extraction settings, extracted
+-- document metadata, and jobs that re-process a collection after its settings
+-- change.
+
+ALTER TABLE public.knowledge_collections
+    ADD COLUMN IF NOT EXISTS chunk_size INTEGER NOT NULL DEFAULT 1200,
+    ADD COLUMN IF NOT EXISTS chunk_overlap INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS metadata_extraction JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+ALTER TABLE public.knowledge_collections
+    DROP CONSTRAINT IF EXISTS knowledge_collections_chunk_size_check,
+    ADD CONSTRAINT knowledge_collections_chunk_size_check
+        CHECK (chunk_size BETWEEN 200 AND 8000),
+    DROP CONSTRAINT IF EXISTS knowledge_collections_chunk_overlap_check,
+    ADD CONSTRAINT knowledge_collections_chunk_overlap_check
+        CHECK (chunk_overlap >= 0 AND chunk_overlap * 2 <= chunk_size);
+
+ALTER TABLE public.knowledge_documents
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE TABLE IF NOT EXISTS public.knowledge_reprocess_jobs (
+    id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id         UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                REFERENCES public.teams(id) ON DELETE RESTRICT,
+    collection_id   UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    status          TEXT        NOT NULL DEFAULT 'pending',
+    error           TEXT        NOT NULL DEFAULT '',
+    documents_total INTEGER     NOT NULL DEFAULT 0,
+    documents_done  INTEGER     NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at    TIMESTAMPTZ,
+    CONSTRAINT knowledge_reprocess_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+-- At most one pending job per collection: further settings changes before
+-- the job starts fold into it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_reprocess_jobs_pending
+    ON public.knowledge_reprocess_jobs (collection_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_knowledge_reprocess_jobs_collection
+    ON public.knowledge_reprocess_jobs (team_id, collection_id, created_at);
+
+ALTER TABLE public.knowledge_reprocess_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_reprocess_jobs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_reprocess_jobs_team_select ON public.knowledge_reprocess_jobs;
+DROP POLICY IF EXISTS knowledge_reprocess_jobs_team_insert ON public.knowledge_reprocess_jobs;
+DROP POLICY IF EXISTS knowledge_reprocess_jobs_team_update ON public.knowledge_reprocess_jobs;
+DROP POLICY IF EXISTS knowledge_reprocess_jobs_team_delete ON public.knowledge_reprocess_jobs;
+
+CREATE POLICY knowledge_reprocess_jobs_team_select ON public.knowledge_reprocess_jobs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_reprocess_jobs_team_insert ON public.knowledge_reprocess_jobs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_reprocess_jobs_team_update ON public.knowledge_reprocess_jobs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_reprocess_jobs_team_delete ON public.knowledge_reprocess_jobs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
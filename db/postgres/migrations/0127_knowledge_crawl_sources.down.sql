@@ -0,0 +1,9 @@
+-- 0127_knowledge_crawl_sources
+-- Remove website crawl sources and runs.
+
+ALTER TABLE public.knowledge_documents
+    DROP COLUMN IF EXISTS crawl_source_id,
+    DROP COLUMN IF EXISTS source_url;
+
+DROP TABLE IF EXISTS public.knowledge_crawl_runs;
+DROP TABLE IF EXISTS public.knowledge_crawl_sources;
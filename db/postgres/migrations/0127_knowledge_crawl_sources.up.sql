@@ -0,0 +1,100 @@
+-- 0127_knowledge_crawl_sources
+-- Add website crawl sources that ingest pages into knowledge collections, the
+-- crawl runs that execute them, and page provenance on documents.
+
+CREATE TABLE IF NOT EXISTS public.knowledge_crawl_sources (
+    id               UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id          UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                 REFERENCES public.teams(id) ON DELETE RESTRICT,
+    collection_id    UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    name             TEXT        NOT NULL,
+    seed_urls        JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    max_depth        INTEGER     NOT NULL DEFAULT 1,
+    max_pages        INTEGER     NOT NULL DEFAULT 50,
+    include_patterns JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    exclude_patterns JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    delay_ms         INTEGER     NOT NULL DEFAULT 1000,
+    refresh_pattern  TEXT        NOT NULL DEFAULT '',
+    enabled          BOOLEAN     NOT NULL DEFAULT true,
+    next_run_at      TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT knowledge_crawl_sources_depth_check CHECK (max_depth BETWEEN 0 AND 5),
+    CONSTRAINT knowledge_crawl_sources_pages_check CHECK (max_pages BETWEEN 1 AND 1000),
+    CONSTRAINT knowledge_crawl_sources_delay_check CHECK (delay_ms BETWEEN 0 AND 60000)
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_crawl_sources_collection
+    ON public.knowledge_crawl_sources (team_id, collection_id);
+CREATE INDEX IF NOT EXISTS idx_knowledge_crawl_sources_due
+    ON public.knowledge_crawl_sources (team_id, next_run_at)
+    WHERE enabled AND next_run_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS public.knowledge_crawl_runs (
+    id             UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id        UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                               REFERENCES public.teams(id) ON DELETE RESTRICT,
+    source_id      UUID        NOT NULL REFERENCES public.knowledge_crawl_sources(id) ON DELETE CASCADE,
+    status         TEXT        NOT NULL DEFAULT 'pending',
+    error          TEXT        NOT NULL DEFAULT '',
+    pages_fetched  INTEGER     NOT NULL DEFAULT 0,
+    pages_ingested INTEGER     NOT NULL DEFAULT 0,
+    pages_skipped  INTEGER     NOT NULL DEFAULT 0,
+    pages_removed  INTEGER     NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at   TIMESTAMPTZ,
+    CONSTRAINT knowledge_crawl_runs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_crawl_runs_pending
+    ON public.knowledge_crawl_runs (source_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_knowledge_crawl_runs_source
+    ON public.knowledge_crawl_runs (team_id, source_id, created_at);
+
+ALTER TABLE public.knowledge_documents
+    ADD COLUMN IF NOT EXISTS source_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS crawl_source_id UUID REFERENCES public.knowledge_crawl_sources(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_documents_crawl_source
+    ON public.knowledge_documents (crawl_source_id)
+    WHERE crawl_source_id IS NOT NULL;
+
+ALTER TABLE public.knowledge_crawl_sources ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_crawl_sources FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_crawl_sources_team_select ON public.knowledge_crawl_sources;
+DROP POLICY IF EXISTS knowledge_crawl_sources_team_insert ON public.knowledge_crawl_sources;
+DROP POLICY IF EXISTS knowledge_crawl_sources_team_update ON public.knowledge_crawl_sources;
+DROP POLICY IF EXISTS knowledge_crawl_sources_team_delete ON public.knowledge_crawl_sources;
+
+CREATE POLICY knowledge_crawl_sources_team_select ON public.knowledge_crawl_sources
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_sources_team_insert ON public.knowledge_crawl_sources
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_sources_team_update ON public.knowledge_crawl_sources
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_sources_team_delete ON public.knowledge_crawl_sources
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.knowledge_crawl_runs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_crawl_runs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_crawl_runs_team_select ON public.knowledge_crawl_runs;
+DROP POLICY IF EXISTS knowledge_crawl_runs_team_insert ON public.knowledge_crawl_runs;
+DROP POLICY IF EXISTS knowledge_crawl_runs_team_update ON public.knowledge_crawl_runs;
+DROP POLICY IF EXISTS knowledge_crawl_runs_team_delete ON public.knowledge_crawl_runs;
+
+CREATE POLICY knowledge_crawl_runs_team_select ON public.knowledge_crawl_runs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_runs_team_insert ON public.knowledge_crawl_runs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_runs_team_update ON public.knowledge_crawl_runs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_crawl_runs_team_delete ON public.knowledge_crawl_runs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
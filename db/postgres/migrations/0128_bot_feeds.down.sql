@@ -0,0 +1,5 @@
+-- 0128_bot_feeds
+-- Remove bot feed subscriptions and their items.
+
+DROP TABLE IF EXISTS public.bot_feed_items;
+DROP TABLE IF EXISTS public.bot_feeds;
@@ -0,0 +1,93 @@
+-- 0128_bot_feeds
+-- Add per-bot RSS/Atom feed subscriptions and the items fetched from them.
+
+CREATE TABLE IF NOT EXISTS public.bot_feeds (
+    id                    UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id               UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                      REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id                UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    url                   TEXT        NOT NULL,
+    title                 TEXT        NOT NULL DEFAULT '',
+    poll_interval_minutes INTEGER     NOT NULL DEFAULT 60,
+    ingest_memory         BOOLEAN     NOT NULL DEFAULT false,
+    collection_id         UUID        REFERENCES public.knowledge_collections(id) ON DELETE SET NULL,
+    digest_channel        TEXT        NOT NULL DEFAULT '',
+    digest_target         TEXT        NOT NULL DEFAULT '',
+    digest_pattern        TEXT        NOT NULL DEFAULT '',
+    enabled               BOOLEAN     NOT NULL DEFAULT true,
+    etag                  TEXT        NOT NULL DEFAULT '',
+    last_modified         TEXT        NOT NULL DEFAULT '',
+    last_error            TEXT        NOT NULL DEFAULT '',
+    last_polled_at        TIMESTAMPTZ,
+    next_poll_at          TIMESTAMPTZ,
+    next_digest_at        TIMESTAMPTZ,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT bot_feeds_bot_url_unique UNIQUE (bot_id, url),
+    CONSTRAINT bot_feeds_poll_interval_check CHECK (poll_interval_minutes BETWEEN 5 AND 10080)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_feeds_bot
+    ON public.bot_feeds (team_id, bot_id);
+CREATE INDEX IF NOT EXISTS idx_bot_feeds_due
+    ON public.bot_feeds (team_id, next_poll_at)
+    WHERE enabled AND next_poll_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS public.bot_feed_items (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id      UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                             REFERENCES public.teams(id) ON DELETE RESTRICT,
+    feed_id      UUID        NOT NULL REFERENCES public.bot_feeds(id) ON DELETE CASCADE,
+    guid         TEXT        NOT NULL,
+    title        TEXT        NOT NULL DEFAULT '',
+    link         TEXT        NOT NULL DEFAULT '',
+    summary      TEXT        NOT NULL DEFAULT '',
+    published_at TIMESTAMPTZ,
+    digested_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT bot_feed_items_feed_guid_unique UNIQUE (feed_id, guid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_feed_items_feed
+    ON public.bot_feed_items (team_id, feed_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_bot_feed_items_undigested
+    ON public.bot_feed_items (feed_id, created_at)
+    WHERE digested_at IS NULL;
+
+ALTER TABLE public.bot_feeds ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_feeds FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_feeds_team_select ON public.bot_feeds;
+DROP POLICY IF EXISTS bot_feeds_team_insert ON public.bot_feeds;
+DROP POLICY IF EXISTS bot_feeds_team_update ON public.bot_feeds;
+DROP POLICY IF EXISTS bot_feeds_team_delete ON public.bot_feeds;
+
+CREATE POLICY bot_feeds_team_select ON public.bot_feeds
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feeds_team_insert ON public.bot_feeds
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feeds_team_update ON public.bot_feeds
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feeds_team_delete ON public.bot_feeds
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.bot_feed_items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_feed_items FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_feed_items_team_select ON public.bot_feed_items;
+DROP POLICY IF EXISTS bot_feed_items_team_insert ON public.bot_feed_items;
+DROP POLICY IF EXISTS bot_feed_items_team_update ON public.bot_feed_items;
+DROP POLICY IF EXISTS bot_feed_items_team_delete ON public.bot_feed_items;
+
+CREATE POLICY bot_feed_items_team_select ON public.bot_feed_items
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feed_items_team_insert ON public.bot_feed_items
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feed_items_team_update ON public.bot_feed_items
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_feed_items_team_delete ON public.bot_feed_items
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,13 @@
+-- 0129_knowledge_connectors
+-- Remove document connectors, their tokens and sync runs.
+
+DROP INDEX IF EXISTS public.idx_knowledge_documents_connector_item;
+
+ALTER TABLE public.knowledge_documents
+    DROP COLUMN IF EXISTS external_version,
+    DROP COLUMN IF EXISTS external_id,
+    DROP COLUMN IF EXISTS connector_id;
+
+DROP TABLE IF EXISTS public.knowledge_connector_syncs;
+DROP TABLE IF EXISTS public.knowledge_connector_tokens;
+DROP TABLE IF EXISTS public.knowledge_connectors;
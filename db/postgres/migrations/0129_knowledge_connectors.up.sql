@@ -0,0 +1,136 @@
+-- 0129_knowledge_connectors
+-- Add document connectors that sync pages from external knowledge tools into
+-- knowledge collections, their OAuth tokens, sync runs, and item provenance on
+-- documents.
+
+CREATE TABLE IF NOT EXISTS public.knowledge_connectors (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id       UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                              REFERENCES public.teams(id) ON DELETE RESTRICT,
+    collection_id UUID        NOT NULL REFERENCES public.knowledge_collections(id) ON DELETE CASCADE,
+    type          TEXT        NOT NULL,
+    name          TEXT        NOT NULL,
+    config        JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    sync_pattern  TEXT        NOT NULL DEFAULT '',
+    enabled       BOOLEAN     NOT NULL DEFAULT true,
+    cursor        TEXT        NOT NULL DEFAULT '',
+    next_sync_at  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_connectors_collection
+    ON public.knowledge_connectors (team_id, collection_id);
+CREATE INDEX IF NOT EXISTS idx_knowledge_connectors_due
+    ON public.knowledge_connectors (team_id, next_sync_at)
+    WHERE enabled AND next_sync_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS public.knowledge_connector_tokens (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id       UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                              REFERENCES public.teams(id) ON DELETE RESTRICT,
+    connector_id  UUID        NOT NULL UNIQUE REFERENCES public.knowledge_connectors(id) ON DELETE CASCADE,
+    account       TEXT        NOT NULL DEFAULT '',
+    access_token  TEXT        NOT NULL DEFAULT '',
+    refresh_token TEXT        NOT NULL DEFAULT '',
+    token_type    TEXT        NOT NULL DEFAULT '',
+    expires_at    TIMESTAMPTZ,
+    scope         TEXT        NOT NULL DEFAULT '',
+    state         TEXT        NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_connector_tokens_state
+    ON public.knowledge_connector_tokens (state)
+    WHERE state != '';
+
+CREATE TABLE IF NOT EXISTS public.knowledge_connector_syncs (
+    id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id            UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                   REFERENCES public.teams(id) ON DELETE RESTRICT,
+    connector_id       UUID        NOT NULL REFERENCES public.knowledge_connectors(id) ON DELETE CASCADE,
+    status             TEXT        NOT NULL DEFAULT 'pending',
+    error              TEXT        NOT NULL DEFAULT '',
+    full_sync          BOOLEAN     NOT NULL DEFAULT false,
+    items_seen         INTEGER     NOT NULL DEFAULT 0,
+    documents_ingested INTEGER     NOT NULL DEFAULT 0,
+    documents_skipped  INTEGER     NOT NULL DEFAULT 0,
+    documents_removed  INTEGER     NOT NULL DEFAULT 0,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at       TIMESTAMPTZ,
+    CONSTRAINT knowledge_connector_syncs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_connector_syncs_pending
+    ON public.knowledge_connector_syncs (connector_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_knowledge_connector_syncs_connector
+    ON public.knowledge_connector_syncs (team_id, connector_id, created_at);
+
+ALTER TABLE public.knowledge_documents
+    ADD COLUMN IF NOT EXISTS connector_id UUID REFERENCES public.knowledge_connectors(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS external_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS external_version TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_documents_connector_item
+    ON public.knowledge_documents (connector_id, external_id)
+    WHERE connector_id IS NOT NULL;
+
+ALTER TABLE public.knowledge_connectors ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_connectors FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_connectors_team_select ON public.knowledge_connectors;
+DROP POLICY IF EXISTS knowledge_connectors_team_insert ON public.knowledge_connectors;
+DROP POLICY IF EXISTS knowledge_connectors_team_update ON public.knowledge_connectors;
+DROP POLICY IF EXISTS knowledge_connectors_team_delete ON public.knowledge_connectors;
+
+CREATE POLICY knowledge_connectors_team_select ON public.knowledge_connectors
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connectors_team_insert ON public.knowledge_connectors
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connectors_team_update ON public.knowledge_connectors
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connectors_team_delete ON public.knowledge_connectors
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.knowledge_connector_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_connector_tokens FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_connector_tokens_team_select ON public.knowledge_connector_tokens;
+DROP POLICY IF EXISTS knowledge_connector_tokens_team_insert ON public.knowledge_connector_tokens;
+DROP POLICY IF EXISTS knowledge_connector_tokens_team_update ON public.knowledge_connector_tokens;
+DROP POLICY IF EXISTS knowledge_connector_tokens_team_delete ON public.knowledge_connector_tokens;
+
+CREATE POLICY knowledge_connector_tokens_team_select ON public.knowledge_connector_tokens
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_tokens_team_insert ON public.knowledge_connector_tokens
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_tokens_team_update ON public.knowledge_connector_tokens
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_tokens_team_delete ON public.knowledge_connector_tokens
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.knowledge_connector_syncs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.knowledge_connector_syncs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS knowledge_connector_syncs_team_select ON public.knowledge_connector_syncs;
+DROP POLICY IF EXISTS knowledge_connector_syncs_team_insert ON public.knowledge_connector_syncs;
+DROP POLICY IF EXISTS knowledge_connector_syncs_team_update ON public.knowledge_connector_syncs;
+DROP POLICY IF EXISTS knowledge_connector_syncs_team_delete ON public.knowledge_connector_syncs;
+
+CREATE POLICY knowledge_connector_syncs_team_select ON public.knowledge_connector_syncs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_syncs_team_insert ON public.knowledge_connector_syncs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_syncs_team_update ON public.knowledge_connector_syncs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY knowledge_connector_syncs_team_delete ON public.knowledge_connector_syncs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,6 @@
+-- 0130_github_integration
+-- Remove GitHub repositories, their synced documents and bot subscriptions.
+
+DROP TABLE IF EXISTS public.github_repo_subscriptions;
+DROP TABLE IF EXISTS public.github_repo_documents;
+DROP TABLE IF EXISTS public.github_repos;
@@ -0,0 +1,112 @@
+-- 0130_github_integration
+-- Add GitHub App repositories, the documents synced from them, and bot
+-- subscriptions to their issue and pull request events.
+
+CREATE TABLE IF NOT EXISTS public.github_repos (
+    id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id         UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                REFERENCES public.teams(id) ON DELETE RESTRICT,
+    owner           TEXT        NOT NULL,
+    name            TEXT        NOT NULL,
+    installation_id BIGINT      NOT NULL,
+    collection_id   UUID        REFERENCES public.knowledge_collections(id) ON DELETE SET NULL,
+    docs_paths      TEXT[]      NOT NULL DEFAULT '{}',
+    docs_tree_sha   TEXT        NOT NULL DEFAULT '',
+    sync_status     TEXT        NOT NULL DEFAULT 'idle',
+    last_error      TEXT        NOT NULL DEFAULT '',
+    synced_at       TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT github_repos_team_repo_unique UNIQUE (team_id, owner, name),
+    CONSTRAINT github_repos_sync_status_check CHECK (sync_status IN ('idle', 'pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_repos_pending
+    ON public.github_repos (team_id, updated_at)
+    WHERE sync_status = 'pending';
+
+CREATE TABLE IF NOT EXISTS public.github_repo_documents (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    repo_id     UUID        NOT NULL REFERENCES public.github_repos(id) ON DELETE CASCADE,
+    path        TEXT        NOT NULL,
+    blob_sha    TEXT        NOT NULL,
+    document_id UUID        NOT NULL REFERENCES public.knowledge_documents(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT github_repo_documents_repo_path_unique UNIQUE (repo_id, path)
+);
+
+CREATE TABLE IF NOT EXISTS public.github_repo_subscriptions (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    repo_id     UUID        NOT NULL REFERENCES public.github_repos(id) ON DELETE CASCADE,
+    bot_id      UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    events      TEXT[]      NOT NULL DEFAULT '{issues,pull_request}',
+    can_comment BOOLEAN     NOT NULL DEFAULT false,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT github_repo_subscriptions_repo_bot_unique UNIQUE (repo_id, bot_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_github_repo_subscriptions_bot
+    ON public.github_repo_subscriptions (team_id, bot_id);
+
+ALTER TABLE public.github_repos ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.github_repos FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS github_repos_team_select ON public.github_repos;
+DROP POLICY IF EXISTS github_repos_team_insert ON public.github_repos;
+DROP POLICY IF EXISTS github_repos_team_update ON public.github_repos;
+DROP POLICY IF EXISTS github_repos_team_delete ON public.github_repos;
+
+CREATE POLICY github_repos_team_select ON public.github_repos
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repos_team_insert ON public.github_repos
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repos_team_update ON public.github_repos
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repos_team_delete ON public.github_repos
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.github_repo_documents ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.github_repo_documents FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS github_repo_documents_team_select ON public.github_repo_documents;
+DROP POLICY IF EXISTS github_repo_documents_team_insert ON public.github_repo_documents;
+DROP POLICY IF EXISTS github_repo_documents_team_update ON public.github_repo_documents;
+DROP POLICY IF EXISTS github_repo_documents_team_delete ON public.github_repo_documents;
+
+CREATE POLICY github_repo_documents_team_select ON public.github_repo_documents
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_documents_team_insert ON public.github_repo_documents
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_documents_team_update ON public.github_repo_documents
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_documents_team_delete ON public.github_repo_documents
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+ALTER TABLE public.github_repo_subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.github_repo_subscriptions FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS github_repo_subscriptions_team_select ON public.github_repo_subscriptions;
+DROP POLICY IF EXISTS github_repo_subscriptions_team_insert ON public.github_repo_subscriptions;
+DROP POLICY IF EXISTS github_repo_subscriptions_team_update ON public.github_repo_subscriptions;
+DROP POLICY IF EXISTS github_repo_subscriptions_team_delete ON public.github_repo_subscriptions;
+
+CREATE POLICY github_repo_subscriptions_team_select ON public.github_repo_subscriptions
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_subscriptions_team_insert ON public.github_repo_subscriptions
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_subscriptions_team_update ON public.github_repo_subscriptions
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY github_repo_subscriptions_team_delete ON public.github_repo_subscriptions
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0131_schedule_feed_tokens
+-- Remove schedule calendar feed tokens.
+
+DROP TABLE IF EXISTS public.schedule_feed_tokens;
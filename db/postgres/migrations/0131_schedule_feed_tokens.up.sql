@@ -0,0 +1,37 @@
+-- 0131_schedule_feed_tokens
+-- Add tokens that let calendar clients read a bot's schedules as an ICS feed.
+
+CREATE TABLE IF NOT EXISTS public.schedule_feed_tokens (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id      UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                             REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id       UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    name         TEXT        NOT NULL DEFAULT '',
+    token_hash   TEXT        NOT NULL,
+    created_by   UUID        REFERENCES public.users(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT schedule_feed_tokens_token_hash_unique UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_feed_tokens_bot
+    ON public.schedule_feed_tokens (team_id, bot_id);
+
+ALTER TABLE public.schedule_feed_tokens ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.schedule_feed_tokens FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS schedule_feed_tokens_team_select ON public.schedule_feed_tokens;
+DROP POLICY IF EXISTS schedule_feed_tokens_team_insert ON public.schedule_feed_tokens;
+DROP POLICY IF EXISTS schedule_feed_tokens_team_update ON public.schedule_feed_tokens;
+DROP POLICY IF EXISTS schedule_feed_tokens_team_delete ON public.schedule_feed_tokens;
+
+CREATE POLICY schedule_feed_tokens_team_select ON public.schedule_feed_tokens
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY schedule_feed_tokens_team_insert ON public.schedule_feed_tokens
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY schedule_feed_tokens_team_update ON public.schedule_feed_tokens
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY schedule_feed_tokens_team_delete ON public.schedule_feed_tokens
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0132_push_devices
+-- Remove push notification devices.
+
+DROP TABLE IF EXISTS public.push_devices;
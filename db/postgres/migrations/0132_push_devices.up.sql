@@ -0,0 +1,42 @@
+-- 0132_push_devices
+-- Register companion app devices that receive a bot's messages as push notifications.
+
+CREATE TABLE IF NOT EXISTS public.push_devices (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id      UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                             REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id       UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    platform     TEXT        NOT NULL,
+    token        TEXT        NOT NULL,
+    name         TEXT        NOT NULL DEFAULT '',
+    muted        BOOLEAN     NOT NULL DEFAULT false,
+    muted_until  TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT push_devices_platform_check CHECK (platform IN ('apns', 'fcm')),
+    CONSTRAINT push_devices_bot_token_unique UNIQUE (bot_id, platform, token)
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_devices_bot_user
+    ON public.push_devices (team_id, bot_id, user_id);
+
+ALTER TABLE public.push_devices ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.push_devices FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS push_devices_team_select ON public.push_devices;
+DROP POLICY IF EXISTS push_devices_team_insert ON public.push_devices;
+DROP POLICY IF EXISTS push_devices_team_update ON public.push_devices;
+DROP POLICY IF EXISTS push_devices_team_delete ON public.push_devices;
+
+CREATE POLICY push_devices_team_select ON public.push_devices
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY push_devices_team_insert ON public.push_devices
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY push_devices_team_update ON public.push_devices
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY push_devices_team_delete ON public.push_devices
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0133_memory_flags
+-- Remove the memory flag queue.
+
+DROP TABLE IF EXISTS public.memory_flags;
@@ -0,0 +1,43 @@
+-- 0133_memory_flags
+-- Queue memories the agent flags as outdated until the bot owner confirms or dismisses them.
+
+CREATE TABLE IF NOT EXISTS public.memory_flags (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id      UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    memory_id   TEXT        NOT NULL,
+    memory      TEXT        NOT NULL DEFAULT '',
+    reason      TEXT        NOT NULL DEFAULT '',
+    session_id  TEXT        NOT NULL DEFAULT '',
+    status      TEXT        NOT NULL DEFAULT 'pending',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at TIMESTAMPTZ,
+    CONSTRAINT memory_flags_status_check CHECK (status IN ('pending', 'confirmed', 'dismissed'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_memory_flags_pending
+    ON public.memory_flags (bot_id, memory_id)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_memory_flags_bot_status
+    ON public.memory_flags (team_id, bot_id, status, created_at DESC);
+
+ALTER TABLE public.memory_flags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.memory_flags FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS memory_flags_team_select ON public.memory_flags;
+DROP POLICY IF EXISTS memory_flags_team_insert ON public.memory_flags;
+DROP POLICY IF EXISTS memory_flags_team_update ON public.memory_flags;
+DROP POLICY IF EXISTS memory_flags_team_delete ON public.memory_flags;
+
+CREATE POLICY memory_flags_team_select ON public.memory_flags
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_flags_team_insert ON public.memory_flags
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_flags_team_update ON public.memory_flags
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_flags_team_delete ON public.memory_flags
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0134_bot_output_processors
+-- Remove bot output post-processors.
+
+DROP TABLE IF EXISTS public.bot_output_processors;
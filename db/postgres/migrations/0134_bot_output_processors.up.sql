@@ -0,0 +1,29 @@
+-- 0134_bot_output_processors
+-- Store the ordered pipeline of post-processors applied to a bot's replies before channel delivery.
+
+CREATE TABLE IF NOT EXISTS public.bot_output_processors (
+    bot_id     UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    processors JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.bot_output_processors ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_output_processors FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_output_processors_team_select ON public.bot_output_processors;
+DROP POLICY IF EXISTS bot_output_processors_team_insert ON public.bot_output_processors;
+DROP POLICY IF EXISTS bot_output_processors_team_update ON public.bot_output_processors;
+DROP POLICY IF EXISTS bot_output_processors_team_delete ON public.bot_output_processors;
+
+CREATE POLICY bot_output_processors_team_select ON public.bot_output_processors
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_output_processors_team_insert ON public.bot_output_processors
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_output_processors_team_update ON public.bot_output_processors
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_output_processors_team_delete ON public.bot_output_processors
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
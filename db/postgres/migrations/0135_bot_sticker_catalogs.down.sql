@@ -0,0 +1,4 @@
+-- 0135_bot_sticker_catalogs
+-- Remove bot sticker catalogs.
+
+DROP TABLE IF EXISTS public.bot_sticker_catalogs;
@@ -0,0 +1,29 @@
+-- 0135_bot_sticker_catalogs
+-- Store the catalog of platform-native stickers each bot may send.
+
+CREATE TABLE IF NOT EXISTS public.bot_sticker_catalogs (
+    bot_id     UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    stickers   JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.bot_sticker_catalogs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_sticker_catalogs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_sticker_catalogs_team_select ON public.bot_sticker_catalogs;
+DROP POLICY IF EXISTS bot_sticker_catalogs_team_insert ON public.bot_sticker_catalogs;
+DROP POLICY IF EXISTS bot_sticker_catalogs_team_update ON public.bot_sticker_catalogs;
+DROP POLICY IF EXISTS bot_sticker_catalogs_team_delete ON public.bot_sticker_catalogs;
+
+CREATE POLICY bot_sticker_catalogs_team_select ON public.bot_sticker_catalogs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_sticker_catalogs_team_insert ON public.bot_sticker_catalogs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_sticker_catalogs_team_update ON public.bot_sticker_catalogs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_sticker_catalogs_team_delete ON public.bot_sticker_catalogs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
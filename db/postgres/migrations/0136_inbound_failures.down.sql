@@ -0,0 +1,4 @@
+-- 0136_inbound_failures
+-- Remove the inbound failure dead-letter table.
+
+DROP TABLE IF EXISTS public.inbound_failures;
@@ -0,0 +1,42 @@
+-- 0136_inbound_failures
+-- Keep inbound channel events whose processing failed after the platform
+-- acknowledged them, so operators can inspect and replay them.
+
+CREATE TABLE IF NOT EXISTS public.inbound_failures (
+    id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id           UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                  REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id            UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    channel_config_id TEXT        NOT NULL DEFAULT '',
+    channel_type      TEXT        NOT NULL,
+    message           JSONB       NOT NULL,
+    error             TEXT        NOT NULL DEFAULT '',
+    attempts          INTEGER     NOT NULL DEFAULT 0,
+    status            TEXT        NOT NULL DEFAULT 'pending'
+                                  CHECK (status IN ('pending', 'replayed')),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    replayed_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS inbound_failures_status_created_idx
+    ON public.inbound_failures (team_id, status, created_at DESC);
+
+ALTER TABLE public.inbound_failures ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.inbound_failures FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS inbound_failures_team_select ON public.inbound_failures;
+DROP POLICY IF EXISTS inbound_failures_team_insert ON public.inbound_failures;
+DROP POLICY IF EXISTS inbound_failures_team_update ON public.inbound_failures;
+DROP POLICY IF EXISTS inbound_failures_team_delete ON public.inbound_failures;
+
+CREATE POLICY inbound_failures_team_select ON public.inbound_failures
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY inbound_failures_team_insert ON public.inbound_failures
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY inbound_failures_team_update ON public.inbound_failures
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY inbound_failures_team_delete ON public.inbound_failures
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
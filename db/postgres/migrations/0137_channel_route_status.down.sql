@@ -0,0 +1,12 @@
+-- 0137_channel_route_status
+-- Remove route membership status tracking.
+
+DROP INDEX IF EXISTS public.idx_bot_channel_routes_inactive;
+
+ALTER TABLE public.bot_channel_routes
+    DROP CONSTRAINT IF EXISTS bot_channel_routes_status_check;
+
+ALTER TABLE public.bot_channel_routes
+    DROP COLUMN IF EXISTS inactive_at,
+    DROP COLUMN IF EXISTS inactive_reason,
+    DROP COLUMN IF EXISTS status;
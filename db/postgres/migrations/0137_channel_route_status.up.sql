@@ -0,0 +1,18 @@
+-- 0137_channel_route_status
+-- Track whether the bot is still a member of a routed conversation. Routes
+-- for conversations the bot was removed from are marked inactive and pruned
+-- after a retention period.
+
+ALTER TABLE public.bot_channel_routes
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS inactive_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS inactive_at TIMESTAMPTZ;
+
+ALTER TABLE public.bot_channel_routes
+    DROP CONSTRAINT IF EXISTS bot_channel_routes_status_check;
+ALTER TABLE public.bot_channel_routes
+    ADD CONSTRAINT bot_channel_routes_status_check CHECK (status IN ('active', 'inactive'));
+
+CREATE INDEX IF NOT EXISTS idx_bot_channel_routes_inactive
+    ON public.bot_channel_routes (team_id, inactive_at)
+    WHERE status = 'inactive';
@@ -0,0 +1,4 @@
+-- 0138_bot_contact_profiles
+-- Remove channel onboarding contact profiles.
+
+DROP TABLE IF EXISTS public.bot_contact_profiles;
@@ -0,0 +1,43 @@
+-- 0138_bot_contact_profiles
+-- Per-bot contact profiles filled in by the channel onboarding flow: the
+-- details a contact shared and where their onboarding stands.
+
+CREATE TABLE IF NOT EXISTS public.bot_contact_profiles (
+    bot_id              UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    channel_identity_id UUID        NOT NULL REFERENCES public.channel_identities(id) ON DELETE CASCADE,
+    team_id             UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                    REFERENCES public.teams(id) ON DELETE RESTRICT,
+    display_name        TEXT        NOT NULL DEFAULT '',
+    timezone            TEXT        NOT NULL DEFAULT '',
+    language            TEXT        NOT NULL DEFAULT '',
+    onboarding_status   TEXT        NOT NULL DEFAULT 'completed'
+                                    CHECK (onboarding_status IN ('collecting', 'completed')),
+    pending_fields      TEXT[]      NOT NULL DEFAULT '{}',
+    linked_user_id      UUID,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at        TIMESTAMPTZ,
+    PRIMARY KEY (bot_id, channel_identity_id)
+);
+
+CREATE INDEX IF NOT EXISTS bot_contact_profiles_team_bot_idx
+    ON public.bot_contact_profiles (team_id, bot_id);
+
+ALTER TABLE public.bot_contact_profiles ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_contact_profiles FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_contact_profiles_team_select ON public.bot_contact_profiles;
+DROP POLICY IF EXISTS bot_contact_profiles_team_insert ON public.bot_contact_profiles;
+DROP POLICY IF EXISTS bot_contact_profiles_team_update ON public.bot_contact_profiles;
+DROP POLICY IF EXISTS bot_contact_profiles_team_delete ON public.bot_contact_profiles;
+
+CREATE POLICY bot_contact_profiles_team_select ON public.bot_contact_profiles
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_contact_profiles_team_insert ON public.bot_contact_profiles
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_contact_profiles_team_update ON public.bot_contact_profiles
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_contact_profiles_team_delete ON public.bot_contact_profiles
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,20 @@
+-- 0139_contact_timezones
+-- Remove contact timezone sources and per-schedule timezones.
+
+ALTER TABLE public.schedule
+    DROP COLUMN IF EXISTS timezone;
+
+UPDATE public.bot_contact_profiles
+SET onboarding_status = 'completed'
+WHERE onboarding_status = 'pending';
+
+ALTER TABLE public.bot_contact_profiles
+    DROP CONSTRAINT IF EXISTS bot_contact_profiles_onboarding_status_check;
+ALTER TABLE public.bot_contact_profiles
+    ADD CONSTRAINT bot_contact_profiles_onboarding_status_check CHECK (onboarding_status IN ('collecting', 'completed'));
+
+ALTER TABLE public.bot_contact_profiles
+    DROP CONSTRAINT IF EXISTS bot_contact_profiles_timezone_source_check;
+
+ALTER TABLE public.bot_contact_profiles
+    DROP COLUMN IF EXISTS timezone_source;
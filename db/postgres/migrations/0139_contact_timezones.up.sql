@@ -0,0 +1,21 @@
+-- 0139_contact_timezones
+-- Record where a contact's timezone came from so platform-reported values
+-- never overwrite one the contact gave, and let schedules carry the timezone
+-- their cron pattern is written in. Profiles created from a platform-reported
+-- timezone start as 'pending' so onboarding still greets the contact.
+
+ALTER TABLE public.bot_contact_profiles
+    ADD COLUMN IF NOT EXISTS timezone_source TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE public.bot_contact_profiles
+    DROP CONSTRAINT IF EXISTS bot_contact_profiles_timezone_source_check;
+ALTER TABLE public.bot_contact_profiles
+    ADD CONSTRAINT bot_contact_profiles_timezone_source_check CHECK (timezone_source IN ('', 'user', 'platform'));
+
+ALTER TABLE public.bot_contact_profiles
+    DROP CONSTRAINT IF EXISTS bot_contact_profiles_onboarding_status_check;
+ALTER TABLE public.bot_contact_profiles
+    ADD CONSTRAINT bot_contact_profiles_onboarding_status_check CHECK (onboarding_status IN ('pending', 'collecting', 'completed'));
+
+ALTER TABLE public.schedule
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT '';
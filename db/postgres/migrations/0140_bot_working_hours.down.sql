@@ -0,0 +1,5 @@
+-- 0140_bot_working_hours
+-- Remove working hours and the deferred inbound message queue.
+
+DROP TABLE IF EXISTS public.deferred_inbound_messages;
+DROP TABLE IF EXISTS public.bot_working_hours;
@@ -0,0 +1,69 @@
+-- 0140_bot_working_hours
+-- Store per-bot working hours and the private messages received outside them
+-- whose replies are deferred until the bot's next opening time.
+
+CREATE TABLE IF NOT EXISTS public.bot_working_hours (
+    bot_id     UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    config     JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.bot_working_hours ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_working_hours FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_working_hours_team_select ON public.bot_working_hours;
+DROP POLICY IF EXISTS bot_working_hours_team_insert ON public.bot_working_hours;
+DROP POLICY IF EXISTS bot_working_hours_team_update ON public.bot_working_hours;
+DROP POLICY IF EXISTS bot_working_hours_team_delete ON public.bot_working_hours;
+
+CREATE POLICY bot_working_hours_team_select ON public.bot_working_hours
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_working_hours_team_insert ON public.bot_working_hours
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_working_hours_team_update ON public.bot_working_hours
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_working_hours_team_delete ON public.bot_working_hours
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE TABLE IF NOT EXISTS public.deferred_inbound_messages (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id      UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                             REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id       UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    channel_type TEXT        NOT NULL,
+    message      JSONB       NOT NULL,
+    deliver_at   TIMESTAMPTZ NOT NULL,
+    status       TEXT        NOT NULL DEFAULT 'pending'
+                             CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    error        TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS deferred_inbound_messages_due_idx
+    ON public.deferred_inbound_messages (team_id, deliver_at)
+    WHERE status = 'pending';
+
+ALTER TABLE public.deferred_inbound_messages ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.deferred_inbound_messages FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS deferred_inbound_messages_team_select ON public.deferred_inbound_messages;
+DROP POLICY IF EXISTS deferred_inbound_messages_team_insert ON public.deferred_inbound_messages;
+DROP POLICY IF EXISTS deferred_inbound_messages_team_update ON public.deferred_inbound_messages;
+DROP POLICY IF EXISTS deferred_inbound_messages_team_delete ON public.deferred_inbound_messages;
+
+CREATE POLICY deferred_inbound_messages_team_select ON public.deferred_inbound_messages
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY deferred_inbound_messages_team_insert ON public.deferred_inbound_messages
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY deferred_inbound_messages_team_update ON public.deferred_inbound_messages
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY deferred_inbound_messages_team_delete ON public.deferred_inbound_messages
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0141_memory_node_expiry
+-- Drop the memory node expiry index.
+
+DROP INDEX IF EXISTS idx_memory_nodes_expires;
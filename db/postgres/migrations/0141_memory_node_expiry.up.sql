@@ -0,0 +1,5 @@
+-- 0141_memory_node_expiry
+-- Index memory nodes that carry a TTL so the expiry reaper can find the bots
+-- with expired memories without scanning every node.
+
+CREATE INDEX IF NOT EXISTS idx_memory_nodes_expires ON memory_nodes (expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- 0142_bot_conversation_flows
+-- Remove per-bot conversation flows.
+
+DROP TABLE IF EXISTS public.bot_conversation_flows;
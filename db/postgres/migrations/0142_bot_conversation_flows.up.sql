@@ -0,0 +1,30 @@
+-- 0142_bot_conversation_flows
+-- Store per-bot conversation flows: guided question sequences that fill a
+-- form alongside free-form chat. Run progress lives in route metadata.
+
+CREATE TABLE IF NOT EXISTS public.bot_conversation_flows (
+    bot_id     UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    config     JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.bot_conversation_flows ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_conversation_flows FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_conversation_flows_team_select ON public.bot_conversation_flows;
+DROP POLICY IF EXISTS bot_conversation_flows_team_insert ON public.bot_conversation_flows;
+DROP POLICY IF EXISTS bot_conversation_flows_team_update ON public.bot_conversation_flows;
+DROP POLICY IF EXISTS bot_conversation_flows_team_delete ON public.bot_conversation_flows;
+
+CREATE POLICY bot_conversation_flows_team_select ON public.bot_conversation_flows
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_conversation_flows_team_insert ON public.bot_conversation_flows
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_conversation_flows_team_update ON public.bot_conversation_flows
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_conversation_flows_team_delete ON public.bot_conversation_flows
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,13 @@
+-- 0143_rerank_models
+-- Remove rerank models.
+
+DELETE FROM provider_template_models WHERE type = 'rerank';
+DELETE FROM models WHERE type = 'rerank';
+
+ALTER TABLE provider_template_models DROP CONSTRAINT IF EXISTS provider_template_models_type_check;
+ALTER TABLE provider_template_models ADD CONSTRAINT provider_template_models_type_check CHECK (
+  type IN ('chat', 'embedding', 'speech', 'transcription', 'video')
+);
+
+ALTER TABLE models DROP CONSTRAINT IF EXISTS models_type_check;
+ALTER TABLE models ADD CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding', 'speech', 'transcription', 'video'));
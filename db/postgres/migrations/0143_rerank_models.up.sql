@@ -0,0 +1,10 @@
+-- 0143_rerank_models
+-- Allow rerank models, used to reorder memory search candidates.
+
+ALTER TABLE models DROP CONSTRAINT IF EXISTS models_type_check;
+ALTER TABLE models ADD CONSTRAINT models_type_check CHECK (type IN ('chat', 'embedding', 'speech', 'transcription', 'video', 'rerank'));
+
+ALTER TABLE provider_template_models DROP CONSTRAINT IF EXISTS provider_template_models_type_check;
+ALTER TABLE provider_template_models ADD CONSTRAINT provider_template_models_type_check CHECK (
+  type IN ('chat', 'embedding', 'speech', 'transcription', 'video', 'rerank')
+);
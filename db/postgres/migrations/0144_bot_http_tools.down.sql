@@ -0,0 +1,4 @@
+-- 0144_bot_http_tools
+-- Remove per-bot HTTP tools.
+
+DROP TABLE IF EXISTS public.bot_http_tools;
@@ -0,0 +1,38 @@
+-- 0144_bot_http_tools
+-- Store per-bot HTTP tools imported from OpenAPI specs. Each row is one
+-- imported API: its base URL, the generated tool operations and the
+-- credentials requests are sent with.
+
+CREATE TABLE IF NOT EXISTS public.bot_http_tools (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    bot_id     UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    name       TEXT        NOT NULL,
+    base_url   TEXT        NOT NULL,
+    operations JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    auth       JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    enabled    BOOLEAN     NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT bot_http_tools_unique UNIQUE (bot_id, name)
+);
+
+ALTER TABLE public.bot_http_tools ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_http_tools FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_http_tools_team_select ON public.bot_http_tools;
+DROP POLICY IF EXISTS bot_http_tools_team_insert ON public.bot_http_tools;
+DROP POLICY IF EXISTS bot_http_tools_team_update ON public.bot_http_tools;
+DROP POLICY IF EXISTS bot_http_tools_team_delete ON public.bot_http_tools;
+
+CREATE POLICY bot_http_tools_team_select ON public.bot_http_tools
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_http_tools_team_insert ON public.bot_http_tools
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_http_tools_team_update ON public.bot_http_tools
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_http_tools_team_delete ON public.bot_http_tools
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
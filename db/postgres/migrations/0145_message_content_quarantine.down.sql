@@ -0,0 +1,4 @@
+-- 0145_message_content_quarantine
+-- Remove the history message content quarantine.
+
+DROP TABLE IF EXISTS public.bot_history_message_quarantine;
@@ -0,0 +1,39 @@
+-- 0145_message_content_quarantine
+-- Keep history message content that failed schema validation. The message
+-- row is written with placeholder content and the original payload is kept
+-- here, sealed like message content, for inspection and manual recovery.
+
+CREATE TABLE IF NOT EXISTS public.bot_history_message_quarantine (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id     UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    message_id UUID        REFERENCES public.bot_history_messages(id) ON DELETE SET NULL,
+    role       TEXT        NOT NULL,
+    payload    TEXT        NOT NULL,
+    reason     TEXT        NOT NULL DEFAULT '',
+    source     TEXT        NOT NULL CHECK (source IN ('persist', 'repair')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS bot_history_message_quarantine_bot_created_idx
+    ON public.bot_history_message_quarantine (team_id, bot_id, created_at DESC);
+
+ALTER TABLE public.bot_history_message_quarantine ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_history_message_quarantine FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_history_message_quarantine_team_select ON public.bot_history_message_quarantine;
+DROP POLICY IF EXISTS bot_history_message_quarantine_team_insert ON public.bot_history_message_quarantine;
+DROP POLICY IF EXISTS bot_history_message_quarantine_team_update ON public.bot_history_message_quarantine;
+DROP POLICY IF EXISTS bot_history_message_quarantine_team_delete ON public.bot_history_message_quarantine;
+
+CREATE POLICY bot_history_message_quarantine_team_select ON public.bot_history_message_quarantine
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_quarantine_team_insert ON public.bot_history_message_quarantine
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_quarantine_team_update ON public.bot_history_message_quarantine
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_quarantine_team_delete ON public.bot_history_message_quarantine
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,5 @@
+-- 0146_memory_compact_schedules
+-- Remove scheduled memory compaction and its run history.
+
+DROP TABLE IF EXISTS public.memory_compact_runs;
+DROP TABLE IF EXISTS public.bot_memory_compact_schedules;
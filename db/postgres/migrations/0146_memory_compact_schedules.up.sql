@@ -0,0 +1,72 @@
+-- 0146_memory_compact_schedules
+-- Let a bot compact its memories on a cron schedule and keep a history of
+-- compaction runs, both scheduled and manual.
+
+CREATE TABLE IF NOT EXISTS public.bot_memory_compact_schedules (
+    bot_id     UUID             PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id    UUID             NOT NULL DEFAULT public.memoh_current_team_id()
+                                REFERENCES public.teams(id) ON DELETE RESTRICT,
+    pattern    TEXT             NOT NULL,
+    timezone   TEXT             NOT NULL DEFAULT '',
+    ratio      DOUBLE PRECISION NOT NULL CHECK (ratio > 0 AND ratio <= 1),
+    decay_days INTEGER          NOT NULL DEFAULT 0 CHECK (decay_days >= 0),
+    enabled    BOOLEAN          NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS public.memory_compact_runs (
+    id            UUID             PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id       UUID             NOT NULL DEFAULT public.memoh_current_team_id()
+                                   REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id        UUID             NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    source        TEXT             NOT NULL CHECK (source IN ('manual', 'schedule')),
+    status        TEXT             NOT NULL DEFAULT 'running'
+                                   CHECK (status IN ('running', 'ok', 'error')),
+    ratio         DOUBLE PRECISION NOT NULL,
+    decay_days    INTEGER          NOT NULL DEFAULT 0,
+    before_count  INTEGER          NOT NULL DEFAULT 0,
+    after_count   INTEGER          NOT NULL DEFAULT 0,
+    error_message TEXT             NOT NULL DEFAULT '',
+    started_at    TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    completed_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS memory_compact_runs_bot_started_idx
+    ON public.memory_compact_runs (team_id, bot_id, started_at DESC);
+
+ALTER TABLE public.bot_memory_compact_schedules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_memory_compact_schedules FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.memory_compact_runs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.memory_compact_runs FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_memory_compact_schedules_team_select ON public.bot_memory_compact_schedules;
+DROP POLICY IF EXISTS bot_memory_compact_schedules_team_insert ON public.bot_memory_compact_schedules;
+DROP POLICY IF EXISTS bot_memory_compact_schedules_team_update ON public.bot_memory_compact_schedules;
+DROP POLICY IF EXISTS bot_memory_compact_schedules_team_delete ON public.bot_memory_compact_schedules;
+DROP POLICY IF EXISTS memory_compact_runs_team_select ON public.memory_compact_runs;
+DROP POLICY IF EXISTS memory_compact_runs_team_insert ON public.memory_compact_runs;
+DROP POLICY IF EXISTS memory_compact_runs_team_update ON public.memory_compact_runs;
+DROP POLICY IF EXISTS memory_compact_runs_team_delete ON public.memory_compact_runs;
+
+CREATE POLICY bot_memory_compact_schedules_team_select ON public.bot_memory_compact_schedules
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_compact_schedules_team_insert ON public.bot_memory_compact_schedules
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_compact_schedules_team_update ON public.bot_memory_compact_schedules
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_compact_schedules_team_delete ON public.bot_memory_compact_schedules
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
+
+CREATE POLICY memory_compact_runs_team_select ON public.memory_compact_runs
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_compact_runs_team_insert ON public.memory_compact_runs
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_compact_runs_team_update ON public.memory_compact_runs
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY memory_compact_runs_team_delete ON public.memory_compact_runs
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,5 @@
+-- 0147_message_archives
+-- Remove archived message pointers. Archived rows keep only their preview;
+-- restore their content from cold storage before rolling back.
+
+DROP TABLE IF EXISTS public.bot_history_message_archives;
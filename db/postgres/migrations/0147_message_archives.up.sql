@@ -0,0 +1,38 @@
+-- 0147_message_archives
+-- Point archived history messages at their content in cold storage. The
+-- tiering job moves old message content into compressed objects and leaves a
+-- short text preview in the message row. message_id has no foreign key on
+-- purpose: when a message is deleted its pointer stays behind so the job can
+-- delete the orphaned object too.
+
+CREATE TABLE IF NOT EXISTS public.bot_history_message_archives (
+    message_id  UUID        PRIMARY KEY,
+    team_id     UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                            REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id      UUID        NOT NULL,
+    object_key  TEXT        NOT NULL,
+    size_bytes  BIGINT      NOT NULL DEFAULT 0,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS bot_history_message_archives_bot_idx
+    ON public.bot_history_message_archives (team_id, bot_id);
+
+ALTER TABLE public.bot_history_message_archives ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_history_message_archives FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_history_message_archives_team_select ON public.bot_history_message_archives;
+DROP POLICY IF EXISTS bot_history_message_archives_team_insert ON public.bot_history_message_archives;
+DROP POLICY IF EXISTS bot_history_message_archives_team_update ON public.bot_history_message_archives;
+DROP POLICY IF EXISTS bot_history_message_archives_team_delete ON public.bot_history_message_archives;
+
+CREATE POLICY bot_history_message_archives_team_select ON public.bot_history_message_archives
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_archives_team_insert ON public.bot_history_message_archives
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_archives_team_update ON public.bot_history_message_archives
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_message_archives_team_delete ON public.bot_history_message_archives
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0148_feature_flags
+-- Remove feature flag overrides; every flag falls back to its default.
+
+DROP TABLE IF EXISTS public.feature_flags;
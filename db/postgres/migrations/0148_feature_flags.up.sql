@@ -0,0 +1,34 @@
+-- 0148_feature_flags
+-- Feature flag overrides. A row without bot_id sets the flag for the whole
+-- deployment; a row with bot_id overrides it for one bot. Flags without a
+-- row keep the default declared in code.
+
+CREATE TABLE IF NOT EXISTS public.feature_flags (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id    UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    flag_key   TEXT        NOT NULL,
+    bot_id     UUID        REFERENCES public.bots(id) ON DELETE CASCADE,
+    enabled    BOOLEAN     NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT feature_flags_scope_unique UNIQUE NULLS NOT DISTINCT (team_id, flag_key, bot_id)
+);
+
+ALTER TABLE public.feature_flags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.feature_flags FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS feature_flags_team_select ON public.feature_flags;
+DROP POLICY IF EXISTS feature_flags_team_insert ON public.feature_flags;
+DROP POLICY IF EXISTS feature_flags_team_update ON public.feature_flags;
+DROP POLICY IF EXISTS feature_flags_team_delete ON public.feature_flags;
+
+CREATE POLICY feature_flags_team_select ON public.feature_flags
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY feature_flags_team_insert ON public.feature_flags
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY feature_flags_team_update ON public.feature_flags
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY feature_flags_team_delete ON public.feature_flags
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0149_auth_refresh_tokens
+-- Remove refresh tokens; clients sign in again when their access token expires.
+
+DROP TABLE IF EXISTS public.auth_refresh_tokens;
@@ -0,0 +1,24 @@
+-- 0149_auth_refresh_tokens
+-- Long-lived refresh tokens that mint new access tokens without a password.
+-- Only a SHA-256 hash of each token is stored. Rotation revokes the presented
+-- token and issues a successor in the same family; presenting a rotated token
+-- again revokes the whole family. Users are global principals, so like users
+-- the table is not team-scoped.
+
+CREATE TABLE IF NOT EXISTS public.auth_refresh_tokens (
+    id             UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id        UUID        NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    family_id      UUID        NOT NULL,
+    token_hash     TEXT        NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at     TIMESTAMPTZ,
+    revoked_reason TEXT,
+    CONSTRAINT auth_refresh_tokens_hash_unique UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_refresh_tokens_user
+    ON public.auth_refresh_tokens (user_id)
+    WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_auth_refresh_tokens_family
+    ON public.auth_refresh_tokens (family_id);
@@ -0,0 +1,8 @@
+-- 0150_workspace_roles
+-- PostgreSQL can not drop enum values, so the values stay; members holding
+-- the newer roles fall back to member.
+
+UPDATE public.team_members
+SET role = 'member',
+    updated_at = now()
+WHERE role::text IN ('operator', 'bot_manager', 'viewer');
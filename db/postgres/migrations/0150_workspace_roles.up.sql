@@ -0,0 +1,8 @@
+-- 0150_workspace_roles
+-- Add roles between member and admin. Their permissions are resolved in
+-- code (internal/policy); the database only stores the role name. Admin
+-- stays the only role the last-active-admin guard counts.
+
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'operator';
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'bot_manager';
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'viewer';
@@ -0,0 +1,5 @@
+-- 0151_history_search_indexes
+-- Stop semantic history indexing. Embeddings already written stay in the
+-- pgvector database until its own migration is rolled back.
+
+DROP TABLE IF EXISTS public.bot_history_search_indexes;
@@ -0,0 +1,40 @@
+-- 0151_history_search_indexes
+-- Track bots whose history is embedded for semantic search. The embeddings
+-- live in the pgvector database; this row holds the embedding model and how
+-- far the indexer got, ordered by (created_at, id).
+
+CREATE TABLE IF NOT EXISTS public.bot_history_search_indexes (
+    bot_id             UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id            UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                   REFERENCES public.teams(id) ON DELETE RESTRICT,
+    embedding_model_id UUID        NOT NULL REFERENCES public.models(id) ON DELETE CASCADE,
+    cursor_created_at  TIMESTAMPTZ,
+    cursor_message_id  UUID,
+    indexed_count      BIGINT      NOT NULL DEFAULT 0,
+    last_error         TEXT        NOT NULL DEFAULT '',
+    last_indexed_at    TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_history_search_indexes_team
+    ON public.bot_history_search_indexes (team_id);
+
+ALTER TABLE public.bot_history_search_indexes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_history_search_indexes FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_history_search_indexes_team_select ON public.bot_history_search_indexes;
+DROP POLICY IF EXISTS bot_history_search_indexes_team_insert ON public.bot_history_search_indexes;
+DROP POLICY IF EXISTS bot_history_search_indexes_team_update ON public.bot_history_search_indexes;
+DROP POLICY IF EXISTS bot_history_search_indexes_team_delete ON public.bot_history_search_indexes;
+
+CREATE POLICY bot_history_search_indexes_team_select ON public.bot_history_search_indexes
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_search_indexes_team_insert ON public.bot_history_search_indexes
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_search_indexes_team_update ON public.bot_history_search_indexes
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_history_search_indexes_team_delete ON public.bot_history_search_indexes
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0152_maintenance_mode
+-- Remove maintenance mode; bots answer normally again.
+
+DROP TABLE IF EXISTS public.maintenance_mode;
@@ -0,0 +1,33 @@
+-- 0152_maintenance_mode
+-- Deployment-wide maintenance mode. While enabled, bots answer every
+-- conversation with the maintenance notice instead of running the agent and
+-- scheduled runs are skipped; channel adapters stay connected.
+
+CREATE TABLE IF NOT EXISTS public.maintenance_mode (
+    team_id    UUID        PRIMARY KEY DEFAULT public.memoh_current_team_id()
+                           REFERENCES public.teams(id) ON DELETE RESTRICT,
+    enabled    BOOLEAN     NOT NULL DEFAULT false,
+    message    TEXT        NOT NULL DEFAULT '',
+    updated_by UUID        REFERENCES public.users(id) ON DELETE SET NULL,
+    started_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.maintenance_mode ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.maintenance_mode FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS maintenance_mode_team_select ON public.maintenance_mode;
+DROP POLICY IF EXISTS maintenance_mode_team_insert ON public.maintenance_mode;
+DROP POLICY IF EXISTS maintenance_mode_team_update ON public.maintenance_mode;
+DROP POLICY IF EXISTS maintenance_mode_team_delete ON public.maintenance_mode;
+
+CREATE POLICY maintenance_mode_team_select ON public.maintenance_mode
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY maintenance_mode_team_insert ON public.maintenance_mode
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY maintenance_mode_team_update ON public.maintenance_mode
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY maintenance_mode_team_delete ON public.maintenance_mode
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,6 @@
+-- 0153_inbound_rate_limit
+-- Remove the per-bot inbound rate limit.
+
+ALTER TABLE bots
+  DROP COLUMN IF EXISTS inbound_rate_burst,
+  DROP COLUMN IF EXISTS inbound_rate_limit;
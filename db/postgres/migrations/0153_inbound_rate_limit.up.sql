@@ -0,0 +1,8 @@
+-- 0153_inbound_rate_limit
+-- Per-bot inbound rate limit. Each sender gets a token bucket of
+-- inbound_rate_burst messages refilled at inbound_rate_limit messages per
+-- minute; zero disables the limit.
+
+ALTER TABLE bots
+  ADD COLUMN IF NOT EXISTS inbound_rate_limit INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS inbound_rate_burst INTEGER NOT NULL DEFAULT 0;
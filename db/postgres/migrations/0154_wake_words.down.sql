@@ -0,0 +1,7 @@
+-- 0154_wake_words
+-- Remove per-bot wake words.
+
+ALTER TABLE bots
+  DROP COLUMN IF EXISTS wake_word_cooldown_seconds,
+  DROP COLUMN IF EXISTS wake_words,
+  DROP COLUMN IF EXISTS wake_words_enabled;
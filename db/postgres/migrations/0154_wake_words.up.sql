@@ -0,0 +1,9 @@
+-- 0154_wake_words
+-- Per-bot wake words. When enabled, a group message that contains one of the
+-- wake words triggers the bot as if it had been mentioned, at most once per
+-- conversation every wake_word_cooldown_seconds.
+
+ALTER TABLE bots
+  ADD COLUMN IF NOT EXISTS wake_words_enabled BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS wake_words TEXT[] NOT NULL DEFAULT '{}',
+  ADD COLUMN IF NOT EXISTS wake_word_cooldown_seconds INTEGER NOT NULL DEFAULT 0;
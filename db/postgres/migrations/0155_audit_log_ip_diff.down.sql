@@ -0,0 +1,8 @@
+-- 0155_audit_log_ip_diff
+-- Remove audit entry client IPs and diffs.
+
+DROP INDEX IF EXISTS idx_audit_log_action;
+
+ALTER TABLE public.audit_log
+  DROP COLUMN IF EXISTS diff,
+  DROP COLUMN IF EXISTS actor_ip;
@@ -0,0 +1,10 @@
+-- 0155_audit_log_ip_diff
+-- Record the client IP and a before/after diff with each audit entry, and
+-- index entries by action for the audit query endpoints.
+
+ALTER TABLE public.audit_log
+  ADD COLUMN IF NOT EXISTS actor_ip TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS diff JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_action
+    ON public.audit_log (team_id, action, created_at DESC);
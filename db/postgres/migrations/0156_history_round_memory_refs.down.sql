@@ -0,0 +1,5 @@
+-- 0156_history_round_memory_refs
+-- Remove the pending memory extraction marker from round keys.
+
+ALTER TABLE public.bot_history_round_keys
+  DROP COLUMN IF EXISTS memory_pending;
@@ -0,0 +1,7 @@
+-- 0156_history_round_memory_refs
+-- Record on each round key whether the round still owes memory extraction,
+-- written in the same transaction as the round so a crash between the
+-- history write and extraction is recovered on replay.
+
+ALTER TABLE public.bot_history_round_keys
+  ADD COLUMN IF NOT EXISTS memory_pending BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+-- 0157_bot_persona
+-- Remove per-bot persona settings.
+
+ALTER TABLE bots
+  DROP COLUMN IF EXISTS persona;
@@ -0,0 +1,6 @@
+-- 0157_bot_persona
+-- Per-bot persona preset and personality sliders, rendered into the system
+-- prompt. Channel routes may override it under metadata.persona.
+
+ALTER TABLE bots
+  ADD COLUMN IF NOT EXISTS persona JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,4 @@
+-- 0158_bot_memory_reviews
+-- Remove the weekly memory review digest settings.
+
+DROP TABLE IF EXISTS public.bot_memory_reviews;
@@ -0,0 +1,39 @@
+-- 0158_bot_memory_reviews
+-- Opt-in weekly digest of a bot's newly added memories, sent to a channel the
+-- owner picks so they can keep or forget each one.
+
+CREATE TABLE IF NOT EXISTS public.bot_memory_reviews (
+    bot_id           UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id          UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                 REFERENCES public.teams(id) ON DELETE RESTRICT,
+    enabled          BOOLEAN     NOT NULL DEFAULT false,
+    channel_type     TEXT        NOT NULL DEFAULT '',
+    target           TEXT        NOT NULL DEFAULT '',
+    next_review_at   TIMESTAMPTZ,
+    last_reviewed_at TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_memory_reviews_due
+    ON public.bot_memory_reviews (team_id, next_review_at)
+    WHERE enabled AND next_review_at IS NOT NULL;
+
+ALTER TABLE public.bot_memory_reviews ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_memory_reviews FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_memory_reviews_team_select ON public.bot_memory_reviews;
+DROP POLICY IF EXISTS bot_memory_reviews_team_insert ON public.bot_memory_reviews;
+DROP POLICY IF EXISTS bot_memory_reviews_team_update ON public.bot_memory_reviews;
+DROP POLICY IF EXISTS bot_memory_reviews_team_delete ON public.bot_memory_reviews;
+
+CREATE POLICY bot_memory_reviews_team_select ON public.bot_memory_reviews
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_reviews_team_insert ON public.bot_memory_reviews
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_reviews_team_update ON public.bot_memory_reviews
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_memory_reviews_team_delete ON public.bot_memory_reviews
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
@@ -0,0 +1,4 @@
+-- 0159_channel_outbox
+-- Remove the outbound channel retry queue.
+
+DROP TABLE IF EXISTS public.channel_outbox;
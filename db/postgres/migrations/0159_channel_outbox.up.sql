@@ -0,0 +1,49 @@
+-- 0159_channel_outbox
+-- Keep outbound channel messages whose delivery failed on a transient
+-- platform error, so a worker can retry them with backoff and operators can
+-- inspect and requeue the ones that ran out of attempts.
+
+CREATE TABLE IF NOT EXISTS public.channel_outbox (
+    id                UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id           UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                                  REFERENCES public.teams(id) ON DELETE RESTRICT,
+    bot_id            UUID        NOT NULL REFERENCES public.bots(id) ON DELETE CASCADE,
+    channel_config_id TEXT        NOT NULL DEFAULT '',
+    channel_type      TEXT        NOT NULL,
+    target            TEXT        NOT NULL,
+    message           JSONB       NOT NULL,
+    error             TEXT        NOT NULL DEFAULT '',
+    attempts          INTEGER     NOT NULL DEFAULT 0,
+    status            TEXT        NOT NULL DEFAULT 'pending'
+                                  CHECK (status IN ('pending', 'sent', 'dead')),
+    next_attempt_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at           TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS channel_outbox_due_idx
+    ON public.channel_outbox (team_id, next_attempt_at)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS channel_outbox_status_created_idx
+    ON public.channel_outbox (team_id, status, created_at DESC);
+
+ALTER TABLE public.channel_outbox ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.channel_outbox FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS channel_outbox_team_select ON public.channel_outbox;
+DROP POLICY IF EXISTS channel_outbox_team_insert ON public.channel_outbox;
+DROP POLICY IF EXISTS channel_outbox_team_update ON public.channel_outbox;
+DROP POLICY IF EXISTS channel_outbox_team_delete ON public.channel_outbox;
+
+CREATE POLICY channel_outbox_team_select ON public.channel_outbox
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY channel_outbox_team_insert ON public.channel_outbox
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY channel_outbox_team_update ON public.channel_outbox
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY channel_outbox_team_delete ON public.channel_outbox
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
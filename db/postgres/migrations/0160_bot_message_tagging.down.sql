@@ -0,0 +1,5 @@
+-- 0160_bot_message_tagging
+-- Remove the message tagging settings. Tags already written to message
+-- metadata are kept.
+
+DROP TABLE IF EXISTS public.bot_message_tagging;
@@ -0,0 +1,41 @@
+-- 0160_bot_message_tagging
+-- Opt-in tagging of a bot's user messages with sentiment and topics from a
+-- configurable taxonomy. Tags are written to the message metadata under
+-- "classification"; this table holds the per-bot settings and worker state.
+
+CREATE TABLE IF NOT EXISTS public.bot_message_tagging (
+    bot_id         UUID        PRIMARY KEY REFERENCES public.bots(id) ON DELETE CASCADE,
+    team_id        UUID        NOT NULL DEFAULT public.memoh_current_team_id()
+                               REFERENCES public.teams(id) ON DELETE RESTRICT,
+    enabled        BOOLEAN     NOT NULL DEFAULT false,
+    model_id       UUID        REFERENCES public.models(id) ON DELETE SET NULL,
+    topics         TEXT[]      NOT NULL DEFAULT '{}',
+    enabled_at     TIMESTAMPTZ,
+    last_error     TEXT        NOT NULL DEFAULT '',
+    last_tagged_at TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_message_tagging_enabled
+    ON public.bot_message_tagging (team_id)
+    WHERE enabled;
+
+ALTER TABLE public.bot_message_tagging ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.bot_message_tagging FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS bot_message_tagging_team_select ON public.bot_message_tagging;
+DROP POLICY IF EXISTS bot_message_tagging_team_insert ON public.bot_message_tagging;
+DROP POLICY IF EXISTS bot_message_tagging_team_update ON public.bot_message_tagging;
+DROP POLICY IF EXISTS bot_message_tagging_team_delete ON public.bot_message_tagging;
+
+CREATE POLICY bot_message_tagging_team_select ON public.bot_message_tagging
+    FOR SELECT USING (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_message_tagging_team_insert ON public.bot_message_tagging
+    FOR INSERT WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_message_tagging_team_update ON public.bot_message_tagging
+    FOR UPDATE
+    USING (team_id = public.memoh_current_team_id())
+    WITH CHECK (team_id = public.memoh_current_team_id());
+CREATE POLICY bot_message_tagging_team_delete ON public.bot_message_tagging
+    FOR DELETE USING (team_id = public.memoh_current_team_id());
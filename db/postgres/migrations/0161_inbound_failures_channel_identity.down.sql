@@ -0,0 +1,7 @@
+-- 0161_inbound_failures_channel_identity
+-- Remove the channel identity link from dead-lettered inbound messages.
+
+DROP INDEX IF EXISTS public.inbound_failures_channel_identity_idx;
+
+ALTER TABLE public.inbound_failures
+  DROP COLUMN IF EXISTS channel_identity_id;
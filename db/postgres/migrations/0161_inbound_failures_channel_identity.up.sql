@@ -0,0 +1,11 @@
+-- 0161_inbound_failures_channel_identity
+-- Link dead-lettered inbound messages to the sender's channel identity so
+-- erasure finds them. Deleting the identity deletes its failures.
+
+ALTER TABLE public.inbound_failures
+  ADD COLUMN IF NOT EXISTS channel_identity_id UUID
+    REFERENCES public.channel_identities(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS inbound_failures_channel_identity_idx
+    ON public.inbound_failures (channel_identity_id)
+    WHERE channel_identity_id IS NOT NULL;
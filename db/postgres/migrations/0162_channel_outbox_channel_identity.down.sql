@@ -0,0 +1,9 @@
+-- 0162_channel_outbox_channel_identity
+-- Remove the channel identity link and the sent-entry index from the
+-- outbound channel retry queue.
+
+DROP INDEX IF EXISTS public.channel_outbox_sent_idx;
+DROP INDEX IF EXISTS public.channel_outbox_channel_identity_idx;
+
+ALTER TABLE public.channel_outbox
+  DROP COLUMN IF EXISTS channel_identity_id;
@@ -0,0 +1,16 @@
+-- 0162_channel_outbox_channel_identity
+-- Link queued outbound messages to the recipient's channel identity so
+-- erasure finds them, and index sent entries for retention pruning.
+-- Deleting the identity deletes its entries.
+
+ALTER TABLE public.channel_outbox
+  ADD COLUMN IF NOT EXISTS channel_identity_id UUID
+    REFERENCES public.channel_identities(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS channel_outbox_channel_identity_idx
+    ON public.channel_outbox (channel_identity_id)
+    WHERE channel_identity_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS channel_outbox_sent_idx
+    ON public.channel_outbox (team_id, sent_at)
+    WHERE status = 'sent';
@@ -0,0 +1,5 @@
+-- 0163_history_round_memory_lease
+-- Remove the memory extraction lease from round keys.
+
+ALTER TABLE public.bot_history_round_keys
+  DROP COLUMN IF EXISTS memory_claimed_at;
@@ -0,0 +1,7 @@
+-- 0163_history_round_memory_lease
+-- Lease pending memory extraction on round keys so concurrent replays of the
+-- same round do not extract its memories twice. A claim older than the lease
+-- is treated as abandoned and may be taken over.
+
+ALTER TABLE public.bot_history_round_keys
+  ADD COLUMN IF NOT EXISTS memory_claimed_at TIMESTAMPTZ;
@@ -0,0 +1,17 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (actor_user_id, actor_ip, action, target_type, target_id, details, diff)
+VALUES (sqlc.arg(actor_user_id), sqlc.arg(actor_ip), sqlc.arg(action), sqlc.arg(target_type), sqlc.arg(target_id), sqlc.arg(details), sqlc.arg(diff))
+RETURNING id, team_id, actor_user_id, action, target_type, target_id, details, created_at, actor_ip, diff;
+
+-- name: ListAuditLogs :many
+SELECT id, team_id, actor_user_id, action, target_type, target_id, details, created_at, actor_ip, diff
+FROM audit_log
+WHERE team_id = public.memoh_current_team_id()
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action)::text)
+  AND (sqlc.narg(target_type)::text IS NULL OR target_type = sqlc.narg(target_type)::text)
+  AND (sqlc.narg(target_id)::text IS NULL OR target_id = sqlc.narg(target_id)::text)
+  AND (sqlc.narg(actor_user_id)::uuid IS NULL OR actor_user_id = sqlc.narg(actor_user_id)::uuid)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(before)::timestamptz IS NULL OR created_at < sqlc.narg(before)::timestamptz)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,40 @@
+-- name: CreateRefreshToken :one
+INSERT INTO auth_refresh_tokens (user_id, family_id, token_hash, expires_at)
+VALUES (
+  sqlc.arg(user_id),
+  COALESCE(sqlc.narg(family_id)::uuid, gen_random_uuid()),
+  sqlc.arg(token_hash),
+  sqlc.arg(expires_at)
+)
+RETURNING id, user_id, family_id, token_hash, expires_at, created_at, revoked_at, revoked_reason;
+
+-- name: GetRefreshTokenByHash :one
+SELECT id, user_id, family_id, token_hash, expires_at, created_at, revoked_at, revoked_reason
+FROM auth_refresh_tokens
+WHERE token_hash = sqlc.arg(token_hash);
+
+-- name: RevokeRefreshToken :execrows
+UPDATE auth_refresh_tokens
+SET revoked_at = now(),
+    revoked_reason = sqlc.arg(reason)
+WHERE id = sqlc.arg(id)
+  AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :execrows
+UPDATE auth_refresh_tokens
+SET revoked_at = now(),
+    revoked_reason = sqlc.arg(reason)
+WHERE family_id = sqlc.arg(family_id)
+  AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokensByUser :execrows
+UPDATE auth_refresh_tokens
+SET revoked_at = now(),
+    revoked_reason = sqlc.arg(reason)
+WHERE user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL;
+
+-- name: DeleteExpiredRefreshTokensByUser :execrows
+DELETE FROM auth_refresh_tokens
+WHERE user_id = sqlc.arg(user_id)
+  AND expires_at < now();
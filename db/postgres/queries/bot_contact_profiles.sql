@@ -0,0 +1,52 @@
+-- name: GetBotContactProfile :one
+SELECT bot_id, channel_identity_id, team_id, display_name, timezone, language, timezone_source, onboarding_status, pending_fields, linked_user_id, created_at, updated_at, completed_at
+FROM bot_contact_profiles
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND channel_identity_id = sqlc.arg(channel_identity_id);
+
+-- name: CreateBotContactProfile :one
+-- Returns no row when the contact was already onboarded, so exactly one
+-- caller observes the first contact. A pending profile, created from a
+-- platform-reported timezone, is taken over.
+INSERT INTO bot_contact_profiles (bot_id, channel_identity_id, display_name, onboarding_status, pending_fields, linked_user_id)
+VALUES (sqlc.arg(bot_id), sqlc.arg(channel_identity_id), sqlc.arg(display_name), sqlc.arg(onboarding_status), sqlc.arg(pending_fields)::text[], sqlc.narg(linked_user_id))
+ON CONFLICT (bot_id, channel_identity_id) DO UPDATE
+SET display_name = EXCLUDED.display_name,
+    onboarding_status = EXCLUDED.onboarding_status,
+    pending_fields = EXCLUDED.pending_fields,
+    linked_user_id = EXCLUDED.linked_user_id,
+    updated_at = now()
+WHERE bot_contact_profiles.onboarding_status = 'pending'
+RETURNING bot_id, channel_identity_id, team_id, display_name, timezone, language, timezone_source, onboarding_status, pending_fields, linked_user_id, created_at, updated_at, completed_at;
+
+-- name: UpdateBotContactProfile :one
+UPDATE bot_contact_profiles
+SET display_name = sqlc.arg(display_name),
+    timezone = sqlc.arg(timezone),
+    language = sqlc.arg(language),
+    timezone_source = sqlc.arg(timezone_source),
+    onboarding_status = sqlc.arg(onboarding_status),
+    pending_fields = sqlc.arg(pending_fields)::text[],
+    linked_user_id = sqlc.narg(linked_user_id),
+    completed_at = CASE
+        WHEN sqlc.arg(onboarding_status) = 'completed' THEN COALESCE(completed_at, now())
+        ELSE NULL
+    END,
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND channel_identity_id = sqlc.arg(channel_identity_id)
+RETURNING bot_id, channel_identity_id, team_id, display_name, timezone, language, timezone_source, onboarding_status, pending_fields, linked_user_id, created_at, updated_at, completed_at;
+
+-- name: UpsertBotContactPlatformTimezone :exec
+-- Stores the timezone a platform reports for a contact. A timezone the
+-- contact gave themselves is never overwritten.
+INSERT INTO bot_contact_profiles (bot_id, channel_identity_id, timezone, timezone_source, onboarding_status)
+VALUES (sqlc.arg(bot_id), sqlc.arg(channel_identity_id), sqlc.arg(timezone), 'platform', 'pending')
+ON CONFLICT (bot_id, channel_identity_id) DO UPDATE
+SET timezone = EXCLUDED.timezone,
+    timezone_source = 'platform',
+    updated_at = now()
+WHERE bot_contact_profiles.timezone_source <> 'user'
+  AND bot_contact_profiles.timezone <> EXCLUDED.timezone;
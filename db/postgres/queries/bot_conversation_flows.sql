@@ -0,0 +1,13 @@
+-- name: GetBotConversationFlows :one
+SELECT bot_id, team_id, config, updated_at
+FROM bot_conversation_flows
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: UpsertBotConversationFlows :one
+INSERT INTO bot_conversation_flows (bot_id, config)
+VALUES (sqlc.arg(bot_id), sqlc.arg(config))
+ON CONFLICT (bot_id) DO UPDATE
+SET config = EXCLUDED.config,
+    updated_at = now()
+RETURNING bot_id, team_id, config, updated_at;
@@ -0,0 +1,10 @@
+-- name: CreateBotDataKey :execrows
+INSERT INTO bot_data_keys (bot_id, wrapped_key)
+VALUES (sqlc.arg(bot_id), sqlc.arg(wrapped_key))
+ON CONFLICT (team_id, bot_id) DO NOTHING;
+
+-- name: GetBotDataKey :one
+SELECT wrapped_key
+FROM bot_data_keys
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
@@ -0,0 +1,109 @@
+-- name: CreateBotFeed :one
+INSERT INTO bot_feeds (bot_id, url, title, poll_interval_minutes, ingest_memory, collection_id, digest_channel, digest_target, digest_pattern, enabled, next_poll_at, next_digest_at)
+VALUES (sqlc.arg(bot_id), sqlc.arg(url), sqlc.arg(title), sqlc.arg(poll_interval_minutes), sqlc.arg(ingest_memory), sqlc.narg(collection_id), sqlc.arg(digest_channel), sqlc.arg(digest_target), sqlc.arg(digest_pattern), sqlc.arg(enabled), sqlc.narg(next_poll_at), sqlc.narg(next_digest_at))
+RETURNING id, team_id, bot_id, url, title, poll_interval_minutes, ingest_memory, collection_id, digest_channel, digest_target, digest_pattern, enabled, etag, last_modified, last_error, last_polled_at, next_poll_at, next_digest_at, created_at, updated_at;
+
+-- name: GetBotFeed :one
+SELECT id, team_id, bot_id, url, title, poll_interval_minutes, ingest_memory, collection_id, digest_channel, digest_target, digest_pattern, enabled, etag, last_modified, last_error, last_polled_at, next_poll_at, next_digest_at, created_at, updated_at
+FROM bot_feeds
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListBotFeeds :many
+SELECT id, team_id, bot_id, url, title, poll_interval_minutes, ingest_memory, collection_id, digest_channel, digest_target, digest_pattern, enabled, etag, last_modified, last_error, last_polled_at, next_poll_at, next_digest_at, created_at, updated_at
+FROM bot_feeds
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+ORDER BY created_at, id;
+
+-- name: ListDueBotFeeds :many
+SELECT id, team_id, bot_id, url, title, poll_interval_minutes, ingest_memory, collection_id, digest_channel, digest_target, digest_pattern, enabled, etag, last_modified, last_error, last_polled_at, next_poll_at, next_digest_at, created_at, updated_at
+FROM bot_feeds
+WHERE team_id = public.memoh_current_team_id()
+  AND enabled
+  AND next_poll_at IS NOT NULL
+  AND next_poll_at <= sqlc.arg(now)
+ORDER BY next_poll_at, id;
+
+-- name: ListDueBotFeedDigests :many
+SELECT id, team_id, bot_id, url, title, poll_interval_minutes, ingest_memory, collection_id, digest_channel, digest_target, digest_pattern, enabled, etag, last_modified, last_error, last_polled_at, next_poll_at, next_digest_at, created_at, updated_at
+FROM bot_feeds
+WHERE team_id = public.memoh_current_team_id()
+  AND enabled
+  AND digest_channel <> ''
+  AND next_digest_at IS NOT NULL
+  AND next_digest_at <= sqlc.arg(now)
+ORDER BY next_digest_at, id;
+
+-- name: UpdateBotFeed :one
+UPDATE bot_feeds
+SET url = sqlc.arg(url),
+    title = sqlc.arg(title),
+    poll_interval_minutes = sqlc.arg(poll_interval_minutes),
+    ingest_memory = sqlc.arg(ingest_memory),
+    collection_id = sqlc.narg(collection_id),
+    digest_channel = sqlc.arg(digest_channel),
+    digest_target = sqlc.arg(digest_target),
+    digest_pattern = sqlc.arg(digest_pattern),
+    enabled = sqlc.arg(enabled),
+    next_poll_at = sqlc.narg(next_poll_at),
+    next_digest_at = sqlc.narg(next_digest_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, bot_id, url, title, poll_interval_minutes, ingest_memory, collection_id, digest_channel, digest_target, digest_pattern, enabled, etag, last_modified, last_error, last_polled_at, next_poll_at, next_digest_at, created_at, updated_at;
+
+-- name: RecordBotFeedPoll :exec
+UPDATE bot_feeds
+SET title = CASE WHEN title = '' THEN sqlc.arg(feed_title) ELSE title END,
+    etag = sqlc.arg(etag),
+    last_modified = sqlc.arg(last_modified),
+    last_error = sqlc.arg(last_error),
+    last_polled_at = now(),
+    next_poll_at = sqlc.narg(next_poll_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: SetBotFeedNextDigest :exec
+UPDATE bot_feeds
+SET next_digest_at = sqlc.narg(next_digest_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: DeleteBotFeed :execrows
+DELETE FROM bot_feeds
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND id = sqlc.arg(id);
+
+-- name: InsertBotFeedItem :one
+INSERT INTO bot_feed_items (feed_id, guid, title, link, summary, published_at)
+VALUES (sqlc.arg(feed_id), sqlc.arg(guid), sqlc.arg(title), sqlc.arg(link), sqlc.arg(summary), sqlc.narg(published_at))
+ON CONFLICT (feed_id, guid) DO NOTHING
+RETURNING id, team_id, feed_id, guid, title, link, summary, published_at, digested_at, created_at;
+
+-- name: ListBotFeedItems :many
+SELECT id, team_id, feed_id, guid, title, link, summary, published_at, digested_at, created_at
+FROM bot_feed_items
+WHERE team_id = public.memoh_current_team_id()
+  AND feed_id = sqlc.arg(feed_id)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListUndigestedBotFeedItems :many
+SELECT id, team_id, feed_id, guid, title, link, summary, published_at, digested_at, created_at
+FROM bot_feed_items
+WHERE team_id = public.memoh_current_team_id()
+  AND feed_id = sqlc.arg(feed_id)
+  AND digested_at IS NULL
+ORDER BY created_at, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: MarkBotFeedItemsDigested :exec
+UPDATE bot_feed_items
+SET digested_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND feed_id = sqlc.arg(feed_id)
+  AND id = ANY(sqlc.arg(ids)::uuid[]);
@@ -0,0 +1,23 @@
+-- name: ListBotHTTPTools :many
+SELECT id, bot_id, team_id, name, base_url, operations, auth, enabled, created_at, updated_at
+FROM bot_http_tools
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+ORDER BY name;
+
+-- name: UpsertBotHTTPTool :one
+INSERT INTO bot_http_tools (bot_id, name, base_url, operations, auth, enabled)
+VALUES (sqlc.arg(bot_id), sqlc.arg(name), sqlc.arg(base_url), sqlc.arg(operations), sqlc.arg(auth), sqlc.arg(enabled))
+ON CONFLICT (bot_id, name) DO UPDATE
+SET base_url = EXCLUDED.base_url,
+    operations = EXCLUDED.operations,
+    auth = EXCLUDED.auth,
+    enabled = EXCLUDED.enabled,
+    updated_at = now()
+RETURNING id, bot_id, team_id, name, base_url, operations, auth, enabled, created_at, updated_at;
+
+-- name: DeleteBotHTTPTool :exec
+DELETE FROM bot_http_tools
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND id = sqlc.arg(id);
@@ -0,0 +1,39 @@
+-- name: GetBotMemoryReview :one
+SELECT bot_id, team_id, enabled, channel_type, target, next_review_at, last_reviewed_at, created_at, updated_at
+FROM bot_memory_reviews
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: UpsertBotMemoryReview :one
+INSERT INTO bot_memory_reviews (bot_id, enabled, channel_type, target, next_review_at)
+VALUES (sqlc.arg(bot_id), sqlc.arg(enabled), sqlc.arg(channel_type), sqlc.arg(target), sqlc.narg(next_review_at))
+ON CONFLICT (bot_id) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    channel_type = EXCLUDED.channel_type,
+    target = EXCLUDED.target,
+    next_review_at = EXCLUDED.next_review_at,
+    updated_at = now()
+RETURNING bot_id, team_id, enabled, channel_type, target, next_review_at, last_reviewed_at, created_at, updated_at;
+
+-- name: ListDueBotMemoryReviews :many
+SELECT bot_id, team_id, enabled, channel_type, target, next_review_at, last_reviewed_at, created_at, updated_at
+FROM bot_memory_reviews
+WHERE team_id = public.memoh_current_team_id()
+  AND enabled
+  AND next_review_at IS NOT NULL
+  AND next_review_at <= sqlc.arg(now)
+ORDER BY next_review_at, bot_id;
+
+-- name: SetBotMemoryReviewNextAt :exec
+UPDATE bot_memory_reviews
+SET next_review_at = sqlc.narg(next_review_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: MarkBotMemoryReviewSent :exec
+UPDATE bot_memory_reviews
+SET last_reviewed_at = sqlc.arg(reviewed_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
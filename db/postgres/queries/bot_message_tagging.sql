@@ -0,0 +1,82 @@
+-- name: GetBotMessageTagging :one
+SELECT bot_id, team_id, enabled, model_id, topics, enabled_at, last_error, last_tagged_at, created_at, updated_at
+FROM bot_message_tagging
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: UpsertBotMessageTagging :one
+INSERT INTO bot_message_tagging (bot_id, enabled, model_id, topics, enabled_at)
+VALUES (sqlc.arg(bot_id), sqlc.arg(enabled), sqlc.narg(model_id), sqlc.arg(topics)::text[], sqlc.narg(enabled_at))
+ON CONFLICT (bot_id) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    model_id = EXCLUDED.model_id,
+    topics = EXCLUDED.topics,
+    enabled_at = EXCLUDED.enabled_at,
+    updated_at = now()
+RETURNING bot_id, team_id, enabled, model_id, topics, enabled_at, last_error, last_tagged_at, created_at, updated_at;
+
+-- name: ListEnabledBotMessageTagging :many
+SELECT bot_id, team_id, enabled, model_id, topics, enabled_at, last_error, last_tagged_at, created_at, updated_at
+FROM bot_message_tagging
+WHERE team_id = public.memoh_current_team_id()
+  AND enabled
+ORDER BY bot_id;
+
+-- name: RecordBotMessageTaggingRun :exec
+UPDATE bot_message_tagging
+SET last_error = sqlc.arg(last_error),
+    last_tagged_at = COALESCE(sqlc.narg(tagged_at), last_tagged_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: ListUntaggedUserMessages :many
+SELECT id, content, created_at
+FROM bot_history_messages
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND role = 'user'
+  AND created_at >= sqlc.arg(since)
+  AND metadata->'classification' IS NULL
+ORDER BY created_at, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: SetMessageClassification :exec
+UPDATE bot_history_messages
+SET metadata = jsonb_set(COALESCE(metadata, '{}'::jsonb), '{classification}', sqlc.arg(classification)::jsonb)
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND id = sqlc.arg(id);
+
+-- name: CountMessageSentimentsByDay :many
+SELECT
+  date_trunc('day', created_at AT TIME ZONE 'UTC')::date AS day,
+  (metadata->'classification'->>'sentiment')::text AS sentiment,
+  COUNT(*)::bigint AS messages
+FROM bot_history_messages
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND role = 'user'
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
+  AND COALESCE(metadata->'classification'->>'sentiment', '') <> ''
+GROUP BY 1, 2
+ORDER BY 1, 2;
+
+-- name: CountMessageTopics :many
+SELECT topic::text AS topic, COUNT(*)::bigint AS messages
+FROM bot_history_messages m,
+  jsonb_array_elements_text(
+    CASE WHEN jsonb_typeof(m.metadata->'classification'->'topics') = 'array'
+         THEN m.metadata->'classification'->'topics'
+         ELSE '[]'::jsonb
+    END
+  ) AS topic
+WHERE m.team_id = public.memoh_current_team_id()
+  AND m.bot_id = sqlc.arg(bot_id)
+  AND m.role = 'user'
+  AND m.created_at >= sqlc.arg(from_time)
+  AND m.created_at < sqlc.arg(to_time)
+GROUP BY 1
+ORDER BY messages DESC, topic
+LIMIT sqlc.arg(row_limit);
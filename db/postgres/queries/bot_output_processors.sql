@@ -0,0 +1,13 @@
+-- name: GetBotOutputProcessors :one
+SELECT bot_id, team_id, processors, updated_at
+FROM bot_output_processors
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: UpsertBotOutputProcessors :one
+INSERT INTO bot_output_processors (bot_id, processors)
+VALUES (sqlc.arg(bot_id), sqlc.arg(processors))
+ON CONFLICT (bot_id) DO UPDATE
+SET processors = EXCLUDED.processors,
+    updated_at = now()
+RETURNING bot_id, team_id, processors, updated_at;
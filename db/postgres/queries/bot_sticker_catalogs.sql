@@ -0,0 +1,13 @@
+-- name: GetBotStickerCatalog :one
+SELECT bot_id, team_id, stickers, updated_at
+FROM bot_sticker_catalogs
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: UpsertBotStickerCatalog :one
+INSERT INTO bot_sticker_catalogs (bot_id, stickers)
+VALUES (sqlc.arg(bot_id), sqlc.arg(stickers))
+ON CONFLICT (bot_id) DO UPDATE
+SET stickers = EXCLUDED.stickers,
+    updated_at = now()
+RETURNING bot_id, team_id, stickers, updated_at;
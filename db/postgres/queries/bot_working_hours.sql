@@ -0,0 +1,45 @@
+-- name: GetBotWorkingHours :one
+SELECT bot_id, team_id, config, updated_at
+FROM bot_working_hours
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: UpsertBotWorkingHours :one
+INSERT INTO bot_working_hours (bot_id, config)
+VALUES (sqlc.arg(bot_id), sqlc.arg(config))
+ON CONFLICT (bot_id) DO UPDATE
+SET config = EXCLUDED.config,
+    updated_at = now()
+RETURNING bot_id, team_id, config, updated_at;
+
+-- name: InsertDeferredInboundMessage :one
+INSERT INTO deferred_inbound_messages (bot_id, channel_type, message, deliver_at)
+VALUES (sqlc.arg(bot_id), sqlc.arg(channel_type), sqlc.arg(message), sqlc.arg(deliver_at))
+RETURNING id, team_id, bot_id, channel_type, message, deliver_at, status, attempts, error, created_at, delivered_at;
+
+-- name: ListDueDeferredInboundMessages :many
+SELECT id, team_id, bot_id, channel_type, message, deliver_at, status, attempts, error, created_at, delivered_at
+FROM deferred_inbound_messages
+WHERE team_id = public.memoh_current_team_id()
+  AND status = 'pending'
+  AND deliver_at <= sqlc.arg(now)
+ORDER BY deliver_at, created_at, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: MarkDeferredInboundMessageDelivered :exec
+UPDATE deferred_inbound_messages
+SET status = 'delivered',
+    attempts = attempts + 1,
+    error = '',
+    delivered_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: MarkDeferredInboundMessageFailed :exec
+UPDATE deferred_inbound_messages
+SET status = sqlc.arg(status),
+    attempts = attempts + 1,
+    error = sqlc.arg(error),
+    deliver_at = sqlc.arg(deliver_at)
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
@@ -53,3 +53,7 @@ WHERE
 ORDER BY ci.updated_at DESC
 LIMIT sqlc.arg(limit_count);
 
+
+-- name: DeleteChannelIdentity :execrows
+DELETE FROM channel_identities
+WHERE team_id = public.memoh_current_team_id() AND id = $1;
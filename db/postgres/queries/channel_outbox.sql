@@ -0,0 +1,95 @@
+-- name: InsertChannelOutbox :one
+-- The recipient's channel identity links the entry to the identity for
+-- erasure; without one, a target that is a known identity's subject (a
+-- direct chat) is used.
+INSERT INTO channel_outbox (bot_id, channel_config_id, channel_type, target, channel_identity_id, message, error, next_attempt_at)
+VALUES (
+    sqlc.arg(bot_id),
+    sqlc.arg(channel_config_id),
+    sqlc.arg(channel_type),
+    sqlc.arg(target),
+    COALESCE(
+        sqlc.narg(channel_identity_id)::uuid,
+        (SELECT ci.id FROM channel_identities ci
+         WHERE ci.team_id = public.memoh_current_team_id()
+           AND ci.channel_type = sqlc.arg(channel_type)
+           AND ci.channel_subject_id = sqlc.arg(target))
+    ),
+    sqlc.arg(message),
+    sqlc.arg(error),
+    sqlc.arg(next_attempt_at)
+)
+RETURNING id, team_id, bot_id, channel_config_id, channel_type, target, message, error, attempts, status, next_attempt_at, created_at, updated_at, sent_at, channel_identity_id;
+
+-- name: GetChannelOutbox :one
+SELECT id, team_id, bot_id, channel_config_id, channel_type, target, message, error, attempts, status, next_attempt_at, created_at, updated_at, sent_at, channel_identity_id
+FROM channel_outbox
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListChannelOutbox :many
+SELECT id, team_id, bot_id, channel_config_id, channel_type, target, message, error, attempts, status, next_attempt_at, created_at, updated_at, sent_at, channel_identity_id
+FROM channel_outbox
+WHERE team_id = public.memoh_current_team_id()
+  AND status = sqlc.arg(status)
+  AND (sqlc.narg(bot_id)::uuid IS NULL OR bot_id = sqlc.narg(bot_id)::uuid)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ClaimDueChannelOutbox :many
+UPDATE channel_outbox
+SET next_attempt_at = now() + make_interval(secs => sqlc.arg(lease_seconds)::int),
+    updated_at = now()
+WHERE id IN (
+    SELECT id
+    FROM channel_outbox
+    WHERE team_id = public.memoh_current_team_id()
+      AND status = 'pending'
+      AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(row_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, team_id, bot_id, channel_config_id, channel_type, target, message, error, attempts, status, next_attempt_at, created_at, updated_at, sent_at, channel_identity_id;
+
+-- name: MarkChannelOutboxSent :one
+UPDATE channel_outbox
+SET status = 'sent',
+    attempts = attempts + 1,
+    sent_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, bot_id, channel_config_id, channel_type, target, message, error, attempts, status, next_attempt_at, created_at, updated_at, sent_at, channel_identity_id;
+
+-- name: MarkChannelOutboxFailed :one
+UPDATE channel_outbox
+SET status = sqlc.arg(status),
+    error = sqlc.arg(error),
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, bot_id, channel_config_id, channel_type, target, message, error, attempts, status, next_attempt_at, created_at, updated_at, sent_at, channel_identity_id;
+
+-- name: RequeueChannelOutbox :one
+UPDATE channel_outbox
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, bot_id, channel_config_id, channel_type, target, message, error, attempts, status, next_attempt_at, created_at, updated_at, sent_at, channel_identity_id;
+
+-- name: DeleteSentChannelOutboxBefore :execrows
+DELETE FROM channel_outbox
+WHERE team_id = public.memoh_current_team_id()
+  AND status = 'sent'
+  AND sent_at < sqlc.arg(before);
+
+-- name: DeleteChannelOutboxByChannelIdentity :execrows
+DELETE FROM channel_outbox
+WHERE team_id = public.memoh_current_team_id()
+  AND channel_identity_id = sqlc.arg(channel_identity_id)::uuid;
@@ -23,6 +23,9 @@ RETURNING
   default_reply_target AS reply_target,
   active_session_id,
   metadata,
+  status,
+  inactive_reason,
+  inactive_at,
   created_at,
   updated_at;
 
@@ -38,6 +41,9 @@ SELECT
   default_reply_target AS reply_target,
   active_session_id,
   metadata,
+  status,
+  inactive_reason,
+  inactive_at,
   created_at,
   updated_at
 FROM bot_channel_routes
@@ -60,6 +66,9 @@ SELECT
   default_reply_target AS reply_target,
   active_session_id,
   metadata,
+  status,
+  inactive_reason,
+  inactive_at,
   created_at,
   updated_at
 FROM bot_channel_routes
@@ -77,12 +86,38 @@ SELECT
   default_reply_target AS reply_target,
   active_session_id,
   metadata,
+  status,
+  inactive_reason,
+  inactive_at,
   created_at,
   updated_at
 FROM bot_channel_routes
 WHERE team_id = public.memoh_current_team_id() AND bot_id = sqlc.arg(bot_id)
 ORDER BY created_at ASC;
 
+-- name: ListInactiveChatRoutes :many
+SELECT
+  id,
+  bot_id,
+  channel_type AS platform,
+  channel_config_id,
+  external_conversation_id AS conversation_id,
+  external_thread_id AS thread_id,
+  conversation_type,
+  default_reply_target AS reply_target,
+  active_session_id,
+  metadata,
+  status,
+  inactive_reason,
+  inactive_at,
+  created_at,
+  updated_at
+FROM bot_channel_routes
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND status = 'inactive'
+ORDER BY inactive_at DESC;
+
 -- name: ListChatRouteThreadProjectionsByIDs :many
 SELECT
   id,
@@ -103,6 +138,24 @@ UPDATE bot_channel_routes
 SET metadata = sqlc.arg(metadata), updated_at = now()
 WHERE team_id = public.memoh_current_team_id() AND id = sqlc.arg(id);
 
+-- name: MarkChatRoutesInactive :execrows
+UPDATE bot_channel_routes
+SET status = 'inactive', inactive_reason = sqlc.arg(reason), inactive_at = now(), updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND channel_type = sqlc.arg(platform)
+  AND external_conversation_id = sqlc.arg(conversation_id)
+  AND status = 'active';
+
+-- name: ReactivateChatRoutes :execrows
+UPDATE bot_channel_routes
+SET status = 'active', inactive_reason = '', inactive_at = NULL, updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND channel_type = sqlc.arg(platform)
+  AND external_conversation_id = sqlc.arg(conversation_id)
+  AND status = 'inactive';
+
 -- name: SetRouteActiveSession :exec
 WITH destination_session AS MATERIALIZED (
   SELECT session.id
@@ -133,3 +186,28 @@ DELETE FROM bot_channel_routes route
 WHERE route.team_id = public.memoh_current_team_id()
   AND route.id = sqlc.arg(id)
   AND (SELECT count(*) FROM route_sessions) >= 0;
+
+-- name: DeleteInactiveChatRoutesBefore :execrows
+-- Lock the routes' sessions before the routes, in the same order as
+-- DeleteChatRoute, so pruning cannot deadlock with a concurrent delete.
+WITH stale_routes AS MATERIALIZED (
+  SELECT route.id
+  FROM bot_channel_routes route
+  WHERE route.team_id = public.memoh_current_team_id()
+    AND route.status = 'inactive'
+    AND route.inactive_at < sqlc.arg(before)
+),
+route_sessions AS MATERIALIZED (
+  SELECT session.id
+  FROM bot_sessions session
+  WHERE session.team_id = public.memoh_current_team_id()
+    AND session.route_id IN (SELECT stale_routes.id FROM stale_routes)
+  ORDER BY session.id
+  FOR UPDATE
+)
+DELETE FROM bot_channel_routes route
+WHERE route.team_id = public.memoh_current_team_id()
+  AND route.id IN (SELECT stale_routes.id FROM stale_routes)
+  AND route.status = 'inactive'
+  AND route.inactive_at < sqlc.arg(before)
+  AND (SELECT count(*) FROM route_sessions) >= 0;
@@ -0,0 +1,52 @@
+-- name: CreateDataExportJob :one
+INSERT INTO data_export_jobs (requested_by_user_id, subject_type, subject_id)
+VALUES (sqlc.arg(requested_by_user_id), sqlc.arg(subject_type), sqlc.arg(subject_id))
+RETURNING id, team_id, requested_by_user_id, subject_type, subject_id, status, error, file_path, size_bytes, created_at, updated_at, completed_at, expires_at;
+
+-- name: GetDataExportJob :one
+SELECT id, team_id, requested_by_user_id, subject_type, subject_id, status, error, file_path, size_bytes, created_at, updated_at, completed_at, expires_at
+FROM data_export_jobs
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListUnfinishedDataExportJobs :many
+SELECT id, team_id, requested_by_user_id, subject_type, subject_id, status, error, file_path, size_bytes, created_at, updated_at, completed_at, expires_at
+FROM data_export_jobs
+WHERE team_id = public.memoh_current_team_id()
+  AND status IN ('pending', 'running')
+ORDER BY created_at, id;
+
+-- name: MarkDataExportJobRunning :execrows
+UPDATE data_export_jobs
+SET status = 'running', updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND status IN ('pending', 'running');
+
+-- name: CompleteDataExportJob :exec
+UPDATE data_export_jobs
+SET status = 'completed',
+    error = '',
+    file_path = sqlc.arg(file_path),
+    size_bytes = sqlc.arg(size_bytes),
+    completed_at = now(),
+    expires_at = sqlc.arg(expires_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: FailDataExportJob :exec
+UPDATE data_export_jobs
+SET status = 'failed',
+    error = sqlc.arg(error),
+    completed_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: DeleteExpiredDataExportJobs :many
+DELETE FROM data_export_jobs
+WHERE team_id = public.memoh_current_team_id()
+  AND expires_at IS NOT NULL
+  AND expires_at <= sqlc.arg(now)::timestamptz
+RETURNING file_path;
@@ -0,0 +1,19 @@
+-- name: ListFeatureFlags :many
+SELECT id, team_id, flag_key, bot_id, enabled, updated_at
+FROM feature_flags
+WHERE team_id = public.memoh_current_team_id()
+ORDER BY flag_key, bot_id NULLS FIRST;
+
+-- name: UpsertFeatureFlag :one
+INSERT INTO feature_flags (flag_key, bot_id, enabled)
+VALUES (sqlc.arg(flag_key), sqlc.narg(bot_id), sqlc.arg(enabled))
+ON CONFLICT ON CONSTRAINT feature_flags_scope_unique DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    updated_at = now()
+RETURNING id, team_id, flag_key, bot_id, enabled, updated_at;
+
+-- name: DeleteFeatureFlag :execrows
+DELETE FROM feature_flags
+WHERE team_id = public.memoh_current_team_id()
+  AND flag_key = sqlc.arg(flag_key)
+  AND bot_id IS NOT DISTINCT FROM sqlc.narg(bot_id);
@@ -0,0 +1,146 @@
+-- name: CreateGitHubRepo :one
+INSERT INTO github_repos (owner, name, installation_id, collection_id, docs_paths, sync_status)
+VALUES (sqlc.arg(owner), sqlc.arg(name), sqlc.arg(installation_id), sqlc.narg(collection_id), sqlc.arg(docs_paths), sqlc.arg(sync_status))
+RETURNING id, team_id, owner, name, installation_id, collection_id, docs_paths, docs_tree_sha, sync_status, last_error, synced_at, created_at, updated_at;
+
+-- name: GetGitHubRepo :one
+SELECT id, team_id, owner, name, installation_id, collection_id, docs_paths, docs_tree_sha, sync_status, last_error, synced_at, created_at, updated_at
+FROM github_repos
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: GetGitHubRepoByName :one
+SELECT id, team_id, owner, name, installation_id, collection_id, docs_paths, docs_tree_sha, sync_status, last_error, synced_at, created_at, updated_at
+FROM github_repos
+WHERE team_id = public.memoh_current_team_id()
+  AND lower(owner) = lower(sqlc.arg(owner))
+  AND lower(name) = lower(sqlc.arg(name));
+
+-- name: ListGitHubRepos :many
+SELECT id, team_id, owner, name, installation_id, collection_id, docs_paths, docs_tree_sha, sync_status, last_error, synced_at, created_at, updated_at
+FROM github_repos
+WHERE team_id = public.memoh_current_team_id()
+ORDER BY owner, name, id;
+
+-- name: UpdateGitHubRepo :one
+UPDATE github_repos
+SET collection_id = sqlc.narg(collection_id),
+    docs_paths = sqlc.arg(docs_paths),
+    docs_tree_sha = sqlc.arg(docs_tree_sha),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, owner, name, installation_id, collection_id, docs_paths, docs_tree_sha, sync_status, last_error, synced_at, created_at, updated_at;
+
+-- name: SetGitHubRepoInstallation :exec
+UPDATE github_repos
+SET installation_id = sqlc.arg(installation_id),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: DeleteGitHubRepo :execrows
+DELETE FROM github_repos
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: MarkGitHubRepoSyncPending :one
+UPDATE github_repos
+SET sync_status = 'pending',
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, owner, name, installation_id, collection_id, docs_paths, docs_tree_sha, sync_status, last_error, synced_at, created_at, updated_at;
+
+-- name: ListPendingGitHubRepos :many
+SELECT id, team_id, owner, name, installation_id, collection_id, docs_paths, docs_tree_sha, sync_status, last_error, synced_at, created_at, updated_at
+FROM github_repos
+WHERE team_id = public.memoh_current_team_id()
+  AND sync_status = 'pending'
+ORDER BY updated_at, id;
+
+-- name: MarkGitHubRepoSyncRunning :execrows
+UPDATE github_repos
+SET sync_status = 'running',
+    last_error = '',
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND sync_status = 'pending';
+
+-- name: CompleteGitHubRepoSync :exec
+UPDATE github_repos
+SET sync_status = 'completed',
+    docs_tree_sha = sqlc.arg(docs_tree_sha),
+    last_error = '',
+    synced_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: FailGitHubRepoSync :exec
+UPDATE github_repos
+SET sync_status = 'failed',
+    last_error = sqlc.arg(last_error),
+    synced_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListGitHubRepoDocuments :many
+SELECT id, team_id, repo_id, path, blob_sha, document_id, created_at, updated_at
+FROM github_repo_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND repo_id = sqlc.arg(repo_id)
+ORDER BY path;
+
+-- name: UpsertGitHubRepoDocument :exec
+INSERT INTO github_repo_documents (repo_id, path, blob_sha, document_id)
+VALUES (sqlc.arg(repo_id), sqlc.arg(path), sqlc.arg(blob_sha), sqlc.arg(document_id))
+ON CONFLICT (repo_id, path) DO UPDATE
+SET blob_sha = EXCLUDED.blob_sha,
+    document_id = EXCLUDED.document_id,
+    updated_at = now();
+
+-- name: DeleteGitHubRepoDocument :exec
+DELETE FROM github_repo_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND repo_id = sqlc.arg(repo_id)
+  AND path = sqlc.arg(path);
+
+-- name: UpsertGitHubRepoSubscription :one
+INSERT INTO github_repo_subscriptions (repo_id, bot_id, events, can_comment)
+VALUES (sqlc.arg(repo_id), sqlc.arg(bot_id), sqlc.arg(events), sqlc.arg(can_comment))
+ON CONFLICT (repo_id, bot_id) DO UPDATE
+SET events = EXCLUDED.events,
+    can_comment = EXCLUDED.can_comment,
+    updated_at = now()
+RETURNING id, team_id, repo_id, bot_id, events, can_comment, created_at, updated_at;
+
+-- name: GetGitHubRepoSubscription :one
+SELECT id, team_id, repo_id, bot_id, events, can_comment, created_at, updated_at
+FROM github_repo_subscriptions
+WHERE team_id = public.memoh_current_team_id()
+  AND repo_id = sqlc.arg(repo_id)
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: ListGitHubRepoSubscriptions :many
+SELECT id, team_id, repo_id, bot_id, events, can_comment, created_at, updated_at
+FROM github_repo_subscriptions
+WHERE team_id = public.memoh_current_team_id()
+  AND repo_id = sqlc.arg(repo_id)
+ORDER BY created_at, id;
+
+-- name: ListGitHubBotSubscriptions :many
+SELECT s.id, s.team_id, s.repo_id, s.bot_id, s.events, s.can_comment, s.created_at, s.updated_at, r.owner, r.name
+FROM github_repo_subscriptions s
+JOIN github_repos r ON r.id = s.repo_id
+WHERE s.team_id = public.memoh_current_team_id()
+  AND s.bot_id = sqlc.arg(bot_id)
+ORDER BY r.owner, r.name, s.id;
+
+-- name: DeleteGitHubRepoSubscription :execrows
+DELETE FROM github_repo_subscriptions
+WHERE team_id = public.memoh_current_team_id()
+  AND repo_id = sqlc.arg(repo_id)
+  AND bot_id = sqlc.arg(bot_id);
@@ -0,0 +1,13 @@
+-- name: ClaimGroupReply :one
+-- Returns the bot that owns the reply. A repeated claim by the winner is
+-- idempotent so redelivered messages keep the same owner.
+INSERT INTO group_reply_claims (channel_type, conversation_id, message_id, bot_id)
+VALUES (sqlc.arg(channel_type), sqlc.arg(conversation_id), sqlc.arg(message_id), sqlc.arg(bot_id))
+ON CONFLICT (team_id, channel_type, conversation_id, message_id)
+DO UPDATE SET bot_id = group_reply_claims.bot_id
+RETURNING bot_id;
+
+-- name: DeleteGroupReplyClaimsBefore :execrows
+DELETE FROM group_reply_claims
+WHERE team_id = public.memoh_current_team_id()
+  AND claimed_at < sqlc.arg(before);
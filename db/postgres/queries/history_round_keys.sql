@@ -0,0 +1,40 @@
+-- name: ClaimHistoryRoundKey :execrows
+INSERT INTO bot_history_round_keys (session_id, round_key)
+VALUES (sqlc.arg(session_id), sqlc.arg(round_key))
+ON CONFLICT (team_id, session_id, round_key) DO NOTHING;
+
+-- name: SetHistoryRoundKeyMessages :exec
+UPDATE bot_history_round_keys
+SET message_ids = sqlc.arg(message_ids)::uuid[]
+WHERE team_id = public.memoh_current_team_id()
+  AND session_id = sqlc.arg(session_id)
+  AND round_key = sqlc.arg(round_key);
+
+-- name: GetHistoryRoundKeyMessages :one
+SELECT message_ids
+FROM bot_history_round_keys
+WHERE team_id = public.memoh_current_team_id()
+  AND session_id = sqlc.arg(session_id)
+  AND round_key = sqlc.arg(round_key);
+
+-- name: SetHistoryRoundKeyMemoryPending :exec
+-- The round's writer runs its extraction, so marking a round pending also
+-- claims it; clearing it drops the claim.
+UPDATE bot_history_round_keys
+SET memory_pending = sqlc.arg(memory_pending),
+    memory_claimed_at = CASE WHEN sqlc.arg(memory_pending) THEN now() END
+WHERE team_id = public.memoh_current_team_id()
+  AND session_id = sqlc.arg(session_id)
+  AND round_key = sqlc.arg(round_key);
+
+-- name: ClaimHistoryRoundKeyMemory :execrows
+-- Takes over the pending memory extraction of a round unless another caller
+-- holds a live claim on it.
+UPDATE bot_history_round_keys
+SET memory_claimed_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND session_id = sqlc.arg(session_id)
+  AND round_key = sqlc.arg(round_key)
+  AND memory_pending
+  AND (memory_claimed_at IS NULL
+    OR memory_claimed_at <= now() - make_interval(secs => sqlc.arg(lease_seconds)::int));
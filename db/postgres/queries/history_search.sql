@@ -0,0 +1,95 @@
+-- name: GetHistorySearchIndex :one
+SELECT bot_id, team_id, embedding_model_id, cursor_created_at, cursor_message_id, indexed_count, last_error, last_indexed_at, created_at, updated_at
+FROM bot_history_search_indexes
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: ListHistorySearchIndexes :many
+SELECT bot_id, team_id, embedding_model_id, cursor_created_at, cursor_message_id, indexed_count, last_error, last_indexed_at, created_at, updated_at
+FROM bot_history_search_indexes
+WHERE team_id = public.memoh_current_team_id()
+ORDER BY bot_id;
+
+-- name: UpsertHistorySearchIndex :one
+-- Changing the embedding model starts the index over.
+INSERT INTO bot_history_search_indexes (bot_id, embedding_model_id)
+VALUES (sqlc.arg(bot_id), sqlc.arg(embedding_model_id))
+ON CONFLICT (bot_id) DO UPDATE SET
+  embedding_model_id = EXCLUDED.embedding_model_id,
+  cursor_created_at = CASE WHEN bot_history_search_indexes.embedding_model_id = EXCLUDED.embedding_model_id
+    THEN bot_history_search_indexes.cursor_created_at ELSE NULL END,
+  cursor_message_id = CASE WHEN bot_history_search_indexes.embedding_model_id = EXCLUDED.embedding_model_id
+    THEN bot_history_search_indexes.cursor_message_id ELSE NULL END,
+  indexed_count = CASE WHEN bot_history_search_indexes.embedding_model_id = EXCLUDED.embedding_model_id
+    THEN bot_history_search_indexes.indexed_count ELSE 0 END,
+  last_error = '',
+  updated_at = now()
+RETURNING bot_id, team_id, embedding_model_id, cursor_created_at, cursor_message_id, indexed_count, last_error, last_indexed_at, created_at, updated_at;
+
+-- name: DeleteHistorySearchIndex :execrows
+DELETE FROM bot_history_search_indexes
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: ResetHistorySearchIndex :execrows
+UPDATE bot_history_search_indexes
+SET cursor_created_at = NULL,
+    cursor_message_id = NULL,
+    indexed_count = 0,
+    last_error = '',
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: AdvanceHistorySearchIndex :exec
+-- Only advances when the model is unchanged, so a concurrent model switch is
+-- not overwritten by a pass that started with the old model.
+UPDATE bot_history_search_indexes
+SET cursor_created_at = sqlc.arg(cursor_created_at),
+    cursor_message_id = sqlc.arg(cursor_message_id),
+    indexed_count = indexed_count + sqlc.arg(indexed)::bigint,
+    last_error = '',
+    last_indexed_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND embedding_model_id = sqlc.arg(embedding_model_id);
+
+-- name: RecordHistorySearchIndexError :exec
+UPDATE bot_history_search_indexes
+SET last_error = sqlc.arg(last_error),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: ListHistoryMessagesForSearchIndex :many
+-- User and assistant messages after the indexer cursor, in cursor order.
+SELECT m.id, m.session_id, m.role, m.content, m.created_at
+FROM bot_history_messages m
+WHERE m.team_id = public.memoh_current_team_id()
+  AND m.bot_id = sqlc.arg(bot_id)
+  AND m.role IN ('user', 'assistant')
+  AND (
+    sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (m.created_at, m.id) > (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id)::uuid)
+  )
+ORDER BY m.created_at, m.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetHistoryMessagesForSearch :many
+SELECT
+  m.id,
+  m.bot_id,
+  m.session_id,
+  m.sender_channel_identity_id,
+  m.role,
+  m.content,
+  m.created_at,
+  ci.display_name AS sender_display_name,
+  s.channel_type AS platform
+FROM bot_visible_history_messages m
+LEFT JOIN channel_identities ci ON ci.id = m.sender_channel_identity_id AND ci.team_id = public.memoh_current_team_id()
+LEFT JOIN bot_sessions s ON s.id = m.session_id AND s.team_id = public.memoh_current_team_id()
+WHERE m.team_id = public.memoh_current_team_id()
+  AND m.bot_id = sqlc.arg(bot_id)
+  AND m.id = ANY(sqlc.arg(ids)::uuid[]);
@@ -0,0 +1,60 @@
+-- name: InsertInboundFailure :one
+-- The sender's channel identity, when it is known, links the failure to
+-- the identity for erasure.
+INSERT INTO inbound_failures (bot_id, channel_config_id, channel_type, channel_identity_id, message, error)
+VALUES (
+    sqlc.arg(bot_id),
+    sqlc.arg(channel_config_id),
+    sqlc.arg(channel_type),
+    (SELECT ci.id FROM channel_identities ci
+     WHERE ci.team_id = public.memoh_current_team_id()
+       AND ci.channel_type = sqlc.arg(channel_type)
+       AND ci.channel_subject_id = sqlc.arg(sender_subject_id)),
+    sqlc.arg(message),
+    sqlc.arg(error)
+)
+RETURNING id, team_id, bot_id, channel_config_id, channel_type, message, error, attempts, status, created_at, updated_at, replayed_at, channel_identity_id;
+
+-- name: GetInboundFailure :one
+SELECT id, team_id, bot_id, channel_config_id, channel_type, message, error, attempts, status, created_at, updated_at, replayed_at, channel_identity_id
+FROM inbound_failures
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListInboundFailures :many
+SELECT id, team_id, bot_id, channel_config_id, channel_type, message, error, attempts, status, created_at, updated_at, replayed_at, channel_identity_id
+FROM inbound_failures
+WHERE team_id = public.memoh_current_team_id()
+  AND status = sqlc.arg(status)
+  AND (sqlc.narg(bot_id)::uuid IS NULL OR bot_id = sqlc.narg(bot_id)::uuid)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: MarkInboundFailureReplayed :one
+UPDATE inbound_failures
+SET status = 'replayed',
+    attempts = attempts + 1,
+    replayed_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, bot_id, channel_config_id, channel_type, message, error, attempts, status, created_at, updated_at, replayed_at, channel_identity_id;
+
+-- name: MarkInboundFailureReplayFailed :one
+UPDATE inbound_failures
+SET error = sqlc.arg(error),
+    attempts = attempts + 1,
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, bot_id, channel_config_id, channel_type, message, error, attempts, status, created_at, updated_at, replayed_at, channel_identity_id;
+
+-- name: DeleteInboundFailuresBefore :execrows
+DELETE FROM inbound_failures
+WHERE team_id = public.memoh_current_team_id()
+  AND created_at < sqlc.arg(before);
+
+-- name: DeleteInboundFailuresByChannelIdentity :execrows
+DELETE FROM inbound_failures
+WHERE team_id = public.memoh_current_team_id()
+  AND channel_identity_id = sqlc.arg(channel_identity_id)::uuid;
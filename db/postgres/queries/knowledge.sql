@@ -0,0 +1,212 @@
+-- name: CreateKnowledgeCollection :one
+INSERT INTO knowledge_collections (name, description, embedding_model_id, chunk_size, chunk_overlap, metadata_extraction)
+VALUES (sqlc.arg(name), sqlc.arg(description), sqlc.narg(embedding_model_id), sqlc.arg(chunk_size), sqlc.arg(chunk_overlap), sqlc.arg(metadata_extraction))
+RETURNING id, team_id, name, description, embedding_model_id, chunk_size, chunk_overlap, metadata_extraction, created_at, updated_at;
+
+-- name: GetKnowledgeCollection :one
+SELECT id, team_id, name, description, embedding_model_id, chunk_size, chunk_overlap, metadata_extraction, created_at, updated_at
+FROM knowledge_collections
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListKnowledgeCollections :many
+SELECT id, team_id, name, description, embedding_model_id, chunk_size, chunk_overlap, metadata_extraction, created_at, updated_at
+FROM knowledge_collections
+WHERE team_id = public.memoh_current_team_id()
+ORDER BY name, id;
+
+-- name: UpdateKnowledgeCollection :one
+UPDATE knowledge_collections
+SET name = sqlc.arg(name),
+    description = sqlc.arg(description),
+    embedding_model_id = sqlc.narg(embedding_model_id),
+    chunk_size = sqlc.arg(chunk_size),
+    chunk_overlap = sqlc.arg(chunk_overlap),
+    metadata_extraction = sqlc.arg(metadata_extraction),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, name, description, embedding_model_id, chunk_size, chunk_overlap, metadata_extraction, created_at, updated_at;
+
+-- name: DeleteKnowledgeCollection :execrows
+DELETE FROM knowledge_collections
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: CreateKnowledgeDocument :one
+INSERT INTO knowledge_documents (collection_id, title, content, content_hash, metadata, source_url, crawl_source_id, connector_id, external_id, external_version)
+VALUES (sqlc.arg(collection_id), sqlc.arg(title), sqlc.arg(content), sqlc.arg(content_hash), sqlc.arg(metadata), sqlc.arg(source_url), sqlc.narg(crawl_source_id), sqlc.narg(connector_id), sqlc.arg(external_id), sqlc.arg(external_version))
+RETURNING id, team_id, collection_id, title, content, content_hash, chunk_count, metadata, source_url, crawl_source_id, connector_id, external_id, external_version, created_at, updated_at;
+
+-- name: SetKnowledgeDocumentProcessing :exec
+UPDATE knowledge_documents
+SET chunk_count = sqlc.arg(chunk_count),
+    metadata = sqlc.arg(metadata),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: GetKnowledgeDocument :one
+SELECT id, team_id, collection_id, title, content, content_hash, chunk_count, metadata, source_url, crawl_source_id, connector_id, external_id, external_version, created_at, updated_at
+FROM knowledge_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND collection_id = sqlc.arg(collection_id)
+  AND id = sqlc.arg(id);
+
+-- name: GetKnowledgeDocumentBySourceURL :one
+SELECT id, team_id, collection_id, title, content, content_hash, chunk_count, metadata, source_url, crawl_source_id, connector_id, external_id, external_version, created_at, updated_at
+FROM knowledge_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND collection_id = sqlc.arg(collection_id)
+  AND source_url = sqlc.arg(source_url)
+ORDER BY created_at, id
+LIMIT 1;
+
+-- name: UpdateKnowledgeDocumentContent :one
+UPDATE knowledge_documents
+SET title = sqlc.arg(title),
+    content = sqlc.arg(content),
+    content_hash = sqlc.arg(content_hash),
+    crawl_source_id = sqlc.narg(crawl_source_id),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, collection_id, title, content, content_hash, chunk_count, metadata, source_url, crawl_source_id, connector_id, external_id, external_version, created_at, updated_at;
+
+-- name: GetKnowledgeDocumentByExternalID :one
+SELECT id, team_id, collection_id, title, content, content_hash, chunk_count, metadata, source_url, crawl_source_id, connector_id, external_id, external_version, created_at, updated_at
+FROM knowledge_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND connector_id = sqlc.arg(connector_id)
+  AND external_id = sqlc.arg(external_id);
+
+-- name: UpdateKnowledgeConnectorDocument :one
+UPDATE knowledge_documents
+SET title = sqlc.arg(title),
+    content = sqlc.arg(content),
+    content_hash = sqlc.arg(content_hash),
+    source_url = sqlc.arg(source_url),
+    external_version = sqlc.arg(external_version),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, collection_id, title, content, content_hash, chunk_count, metadata, source_url, crawl_source_id, connector_id, external_id, external_version, created_at, updated_at;
+
+-- name: ListKnowledgeDocuments :many
+SELECT id, team_id, collection_id, title, content_hash, chunk_count, metadata, source_url, length(content)::bigint AS content_length, created_at, updated_at
+FROM knowledge_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND collection_id = sqlc.arg(collection_id)
+ORDER BY created_at DESC, id;
+
+-- name: ListKnowledgeCrawlDocuments :many
+SELECT id, collection_id, source_url
+FROM knowledge_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND crawl_source_id = sqlc.arg(crawl_source_id)
+ORDER BY source_url, id;
+
+-- name: ListKnowledgeConnectorDocuments :many
+SELECT id, collection_id, external_id
+FROM knowledge_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND connector_id = sqlc.arg(connector_id)
+ORDER BY external_id, id;
+
+-- name: DeleteKnowledgeDocument :execrows
+DELETE FROM knowledge_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND collection_id = sqlc.arg(collection_id)
+  AND id = sqlc.arg(id);
+
+-- name: SearchKnowledgeDocumentsText :many
+SELECT id, collection_id, title, content, metadata,
+       CAST(ts_rank(to_tsvector('simple', title || ' ' || content), websearch_to_tsquery('simple', sqlc.arg(query))) AS double precision) AS score
+FROM knowledge_documents
+WHERE team_id = public.memoh_current_team_id()
+  AND collection_id = sqlc.arg(collection_id)
+  AND to_tsvector('simple', title || ' ' || content) @@ websearch_to_tsquery('simple', sqlc.arg(query))
+ORDER BY score DESC, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: AttachBotKnowledgeCollection :exec
+INSERT INTO bot_knowledge_collections (bot_id, collection_id)
+VALUES (sqlc.arg(bot_id), sqlc.arg(collection_id))
+ON CONFLICT (bot_id, collection_id) DO NOTHING;
+
+-- name: DetachBotKnowledgeCollection :execrows
+DELETE FROM bot_knowledge_collections
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND collection_id = sqlc.arg(collection_id);
+
+-- name: ListBotKnowledgeCollections :many
+SELECT c.id, c.team_id, c.name, c.description, c.embedding_model_id, c.chunk_size, c.chunk_overlap, c.metadata_extraction, c.created_at, c.updated_at
+FROM bot_knowledge_collections b
+JOIN knowledge_collections c ON c.id = b.collection_id
+WHERE b.team_id = public.memoh_current_team_id()
+  AND b.bot_id = sqlc.arg(bot_id)
+ORDER BY c.name, c.id;
+
+-- name: EnqueueKnowledgeReprocessJob :one
+INSERT INTO knowledge_reprocess_jobs (collection_id)
+VALUES (sqlc.arg(collection_id))
+ON CONFLICT (collection_id) WHERE status = 'pending'
+DO UPDATE SET updated_at = now()
+RETURNING id, team_id, collection_id, status, error, documents_total, documents_done, created_at, updated_at, completed_at;
+
+-- name: GetKnowledgeReprocessJob :one
+SELECT id, team_id, collection_id, status, error, documents_total, documents_done, created_at, updated_at, completed_at
+FROM knowledge_reprocess_jobs
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListKnowledgeReprocessJobs :many
+SELECT id, team_id, collection_id, status, error, documents_total, documents_done, created_at, updated_at, completed_at
+FROM knowledge_reprocess_jobs
+WHERE team_id = public.memoh_current_team_id()
+  AND collection_id = sqlc.arg(collection_id)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListUnfinishedKnowledgeReprocessJobs :many
+SELECT id, team_id, collection_id, status, error, documents_total, documents_done, created_at, updated_at, completed_at
+FROM knowledge_reprocess_jobs
+WHERE team_id = public.memoh_current_team_id()
+  AND status IN ('pending', 'running')
+ORDER BY created_at, id;
+
+-- name: MarkKnowledgeReprocessJobRunning :execrows
+UPDATE knowledge_reprocess_jobs
+SET status = 'running',
+    documents_total = sqlc.arg(documents_total),
+    documents_done = 0,
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND status IN ('pending', 'running');
+
+-- name: SetKnowledgeReprocessJobProgress :exec
+UPDATE knowledge_reprocess_jobs
+SET documents_done = sqlc.arg(documents_done),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: CompleteKnowledgeReprocessJob :exec
+UPDATE knowledge_reprocess_jobs
+SET status = 'completed',
+    error = '',
+    completed_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: FailKnowledgeReprocessJob :exec
+UPDATE knowledge_reprocess_jobs
+SET status = 'failed',
+    error = sqlc.arg(error),
+    completed_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
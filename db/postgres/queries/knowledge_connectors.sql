@@ -0,0 +1,166 @@
+-- name: CreateKnowledgeConnector :one
+INSERT INTO knowledge_connectors (collection_id, type, name, config, sync_pattern, enabled, next_sync_at)
+VALUES (sqlc.arg(collection_id), sqlc.arg(type), sqlc.arg(name), sqlc.arg(config), sqlc.arg(sync_pattern), sqlc.arg(enabled), sqlc.narg(next_sync_at))
+RETURNING id, team_id, collection_id, type, name, config, sync_pattern, enabled, cursor, next_sync_at, created_at, updated_at;
+
+-- name: GetKnowledgeConnector :one
+SELECT id, team_id, collection_id, type, name, config, sync_pattern, enabled, cursor, next_sync_at, created_at, updated_at
+FROM knowledge_connectors
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListKnowledgeConnectors :many
+SELECT id, team_id, collection_id, type, name, config, sync_pattern, enabled, cursor, next_sync_at, created_at, updated_at
+FROM knowledge_connectors
+WHERE team_id = public.memoh_current_team_id()
+  AND collection_id = sqlc.arg(collection_id)
+ORDER BY name, id;
+
+-- name: ListDueKnowledgeConnectors :many
+SELECT id, team_id, collection_id, type, name, config, sync_pattern, enabled, cursor, next_sync_at, created_at, updated_at
+FROM knowledge_connectors
+WHERE team_id = public.memoh_current_team_id()
+  AND enabled
+  AND next_sync_at IS NOT NULL
+  AND next_sync_at <= sqlc.arg(now)
+ORDER BY next_sync_at, id;
+
+-- name: UpdateKnowledgeConnector :one
+UPDATE knowledge_connectors
+SET name = sqlc.arg(name),
+    config = sqlc.arg(config),
+    sync_pattern = sqlc.arg(sync_pattern),
+    enabled = sqlc.arg(enabled),
+    cursor = sqlc.arg(cursor),
+    next_sync_at = sqlc.narg(next_sync_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, collection_id, type, name, config, sync_pattern, enabled, cursor, next_sync_at, created_at, updated_at;
+
+-- name: SetKnowledgeConnectorNextSync :exec
+UPDATE knowledge_connectors
+SET next_sync_at = sqlc.narg(next_sync_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: SetKnowledgeConnectorCursor :exec
+UPDATE knowledge_connectors
+SET cursor = sqlc.arg(cursor),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: DeleteKnowledgeConnector :execrows
+DELETE FROM knowledge_connectors
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: GetKnowledgeConnectorToken :one
+SELECT id, team_id, connector_id, account, access_token, refresh_token, token_type, expires_at, scope, state, created_at, updated_at
+FROM knowledge_connector_tokens
+WHERE team_id = public.memoh_current_team_id()
+  AND connector_id = sqlc.arg(connector_id);
+
+-- name: GetKnowledgeConnectorTokenByState :one
+SELECT id, team_id, connector_id, account, access_token, refresh_token, token_type, expires_at, scope, state, created_at, updated_at
+FROM knowledge_connector_tokens
+WHERE team_id = public.memoh_current_team_id()
+  AND state = sqlc.arg(state)
+  AND state != '';
+
+-- name: SetKnowledgeConnectorOAuthState :exec
+INSERT INTO knowledge_connector_tokens (connector_id, state)
+VALUES (sqlc.arg(connector_id), sqlc.arg(state))
+ON CONFLICT (connector_id) DO UPDATE SET
+  state      = EXCLUDED.state,
+  updated_at = now();
+
+-- name: UpsertKnowledgeConnectorToken :one
+INSERT INTO knowledge_connector_tokens (connector_id, account, access_token, refresh_token, token_type, expires_at, scope, state)
+VALUES (sqlc.arg(connector_id), sqlc.arg(account), sqlc.arg(access_token), sqlc.arg(refresh_token), sqlc.arg(token_type), sqlc.narg(expires_at), sqlc.arg(scope), '')
+ON CONFLICT (connector_id) DO UPDATE SET
+  account       = EXCLUDED.account,
+  access_token  = EXCLUDED.access_token,
+  refresh_token = EXCLUDED.refresh_token,
+  token_type    = EXCLUDED.token_type,
+  expires_at    = EXCLUDED.expires_at,
+  scope         = EXCLUDED.scope,
+  state         = '',
+  updated_at    = now()
+RETURNING id, team_id, connector_id, account, access_token, refresh_token, token_type, expires_at, scope, state, created_at, updated_at;
+
+-- name: DeleteKnowledgeConnectorToken :execrows
+DELETE FROM knowledge_connector_tokens
+WHERE team_id = public.memoh_current_team_id()
+  AND connector_id = sqlc.arg(connector_id);
+
+-- name: EnqueueKnowledgeConnectorSync :one
+INSERT INTO knowledge_connector_syncs (connector_id, full_sync)
+VALUES (sqlc.arg(connector_id), sqlc.arg(full_sync))
+ON CONFLICT (connector_id) WHERE status = 'pending'
+DO UPDATE SET full_sync = knowledge_connector_syncs.full_sync OR EXCLUDED.full_sync,
+              updated_at = now()
+RETURNING id, team_id, connector_id, status, error, full_sync, items_seen, documents_ingested, documents_skipped, documents_removed, created_at, updated_at, completed_at;
+
+-- name: GetKnowledgeConnectorSync :one
+SELECT id, team_id, connector_id, status, error, full_sync, items_seen, documents_ingested, documents_skipped, documents_removed, created_at, updated_at, completed_at
+FROM knowledge_connector_syncs
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListKnowledgeConnectorSyncs :many
+SELECT id, team_id, connector_id, status, error, full_sync, items_seen, documents_ingested, documents_skipped, documents_removed, created_at, updated_at, completed_at
+FROM knowledge_connector_syncs
+WHERE team_id = public.memoh_current_team_id()
+  AND connector_id = sqlc.arg(connector_id)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListUnfinishedKnowledgeConnectorSyncs :many
+SELECT id, team_id, connector_id, status, error, full_sync, items_seen, documents_ingested, documents_skipped, documents_removed, created_at, updated_at, completed_at
+FROM knowledge_connector_syncs
+WHERE team_id = public.memoh_current_team_id()
+  AND status IN ('pending', 'running')
+ORDER BY created_at, id;
+
+-- name: MarkKnowledgeConnectorSyncRunning :execrows
+UPDATE knowledge_connector_syncs
+SET status = 'running',
+    items_seen = 0,
+    documents_ingested = 0,
+    documents_skipped = 0,
+    documents_removed = 0,
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND status IN ('pending', 'running');
+
+-- name: SetKnowledgeConnectorSyncProgress :exec
+UPDATE knowledge_connector_syncs
+SET items_seen = sqlc.arg(items_seen),
+    documents_ingested = sqlc.arg(documents_ingested),
+    documents_skipped = sqlc.arg(documents_skipped),
+    documents_removed = sqlc.arg(documents_removed),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: CompleteKnowledgeConnectorSync :exec
+UPDATE knowledge_connector_syncs
+SET status = 'completed',
+    error = '',
+    completed_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: FailKnowledgeConnectorSync :exec
+UPDATE knowledge_connector_syncs
+SET status = 'failed',
+    error = sqlc.arg(error),
+    completed_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
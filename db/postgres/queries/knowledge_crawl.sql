@@ -0,0 +1,123 @@
+-- name: CreateKnowledgeCrawlSource :one
+INSERT INTO knowledge_crawl_sources (collection_id, name, seed_urls, max_depth, max_pages, include_patterns, exclude_patterns, delay_ms, refresh_pattern, enabled, next_run_at)
+VALUES (sqlc.arg(collection_id), sqlc.arg(name), sqlc.arg(seed_urls), sqlc.arg(max_depth), sqlc.arg(max_pages), sqlc.arg(include_patterns), sqlc.arg(exclude_patterns), sqlc.arg(delay_ms), sqlc.arg(refresh_pattern), sqlc.arg(enabled), sqlc.narg(next_run_at))
+RETURNING id, team_id, collection_id, name, seed_urls, max_depth, max_pages, include_patterns, exclude_patterns, delay_ms, refresh_pattern, enabled, next_run_at, created_at, updated_at;
+
+-- name: GetKnowledgeCrawlSource :one
+SELECT id, team_id, collection_id, name, seed_urls, max_depth, max_pages, include_patterns, exclude_patterns, delay_ms, refresh_pattern, enabled, next_run_at, created_at, updated_at
+FROM knowledge_crawl_sources
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListKnowledgeCrawlSources :many
+SELECT id, team_id, collection_id, name, seed_urls, max_depth, max_pages, include_patterns, exclude_patterns, delay_ms, refresh_pattern, enabled, next_run_at, created_at, updated_at
+FROM knowledge_crawl_sources
+WHERE team_id = public.memoh_current_team_id()
+  AND collection_id = sqlc.arg(collection_id)
+ORDER BY name, id;
+
+-- name: ListDueKnowledgeCrawlSources :many
+SELECT id, team_id, collection_id, name, seed_urls, max_depth, max_pages, include_patterns, exclude_patterns, delay_ms, refresh_pattern, enabled, next_run_at, created_at, updated_at
+FROM knowledge_crawl_sources
+WHERE team_id = public.memoh_current_team_id()
+  AND enabled
+  AND next_run_at IS NOT NULL
+  AND next_run_at <= sqlc.arg(now)
+ORDER BY next_run_at, id;
+
+-- name: UpdateKnowledgeCrawlSource :one
+UPDATE knowledge_crawl_sources
+SET name = sqlc.arg(name),
+    seed_urls = sqlc.arg(seed_urls),
+    max_depth = sqlc.arg(max_depth),
+    max_pages = sqlc.arg(max_pages),
+    include_patterns = sqlc.arg(include_patterns),
+    exclude_patterns = sqlc.arg(exclude_patterns),
+    delay_ms = sqlc.arg(delay_ms),
+    refresh_pattern = sqlc.arg(refresh_pattern),
+    enabled = sqlc.arg(enabled),
+    next_run_at = sqlc.narg(next_run_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, collection_id, name, seed_urls, max_depth, max_pages, include_patterns, exclude_patterns, delay_ms, refresh_pattern, enabled, next_run_at, created_at, updated_at;
+
+-- name: SetKnowledgeCrawlSourceNextRun :exec
+UPDATE knowledge_crawl_sources
+SET next_run_at = sqlc.narg(next_run_at),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: DeleteKnowledgeCrawlSource :execrows
+DELETE FROM knowledge_crawl_sources
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: EnqueueKnowledgeCrawlRun :one
+INSERT INTO knowledge_crawl_runs (source_id)
+VALUES (sqlc.arg(source_id))
+ON CONFLICT (source_id) WHERE status = 'pending'
+DO UPDATE SET updated_at = now()
+RETURNING id, team_id, source_id, status, error, pages_fetched, pages_ingested, pages_skipped, pages_removed, created_at, updated_at, completed_at;
+
+-- name: GetKnowledgeCrawlRun :one
+SELECT id, team_id, source_id, status, error, pages_fetched, pages_ingested, pages_skipped, pages_removed, created_at, updated_at, completed_at
+FROM knowledge_crawl_runs
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: ListKnowledgeCrawlRuns :many
+SELECT id, team_id, source_id, status, error, pages_fetched, pages_ingested, pages_skipped, pages_removed, created_at, updated_at, completed_at
+FROM knowledge_crawl_runs
+WHERE team_id = public.memoh_current_team_id()
+  AND source_id = sqlc.arg(source_id)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListUnfinishedKnowledgeCrawlRuns :many
+SELECT id, team_id, source_id, status, error, pages_fetched, pages_ingested, pages_skipped, pages_removed, created_at, updated_at, completed_at
+FROM knowledge_crawl_runs
+WHERE team_id = public.memoh_current_team_id()
+  AND status IN ('pending', 'running')
+ORDER BY created_at, id;
+
+-- name: MarkKnowledgeCrawlRunRunning :execrows
+UPDATE knowledge_crawl_runs
+SET status = 'running',
+    pages_fetched = 0,
+    pages_ingested = 0,
+    pages_skipped = 0,
+    pages_removed = 0,
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND status IN ('pending', 'running');
+
+-- name: SetKnowledgeCrawlRunProgress :exec
+UPDATE knowledge_crawl_runs
+SET pages_fetched = sqlc.arg(pages_fetched),
+    pages_ingested = sqlc.arg(pages_ingested),
+    pages_skipped = sqlc.arg(pages_skipped),
+    pages_removed = sqlc.arg(pages_removed),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: CompleteKnowledgeCrawlRun :exec
+UPDATE knowledge_crawl_runs
+SET status = 'completed',
+    error = '',
+    completed_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: FailKnowledgeCrawlRun :exec
+UPDATE knowledge_crawl_runs
+SET status = 'failed',
+    error = sqlc.arg(error),
+    completed_at = now(),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
@@ -0,0 +1,27 @@
+-- name: GetMaintenanceMode :one
+SELECT team_id, enabled, message, updated_by, started_at, updated_at
+FROM maintenance_mode
+WHERE team_id = public.memoh_current_team_id();
+
+-- name: EnableMaintenanceMode :one
+-- Keeps started_at when maintenance is already on so updating the notice
+-- does not restart the window.
+INSERT INTO maintenance_mode (enabled, message, updated_by, started_at)
+VALUES (true, sqlc.arg(message), sqlc.narg(updated_by), now())
+ON CONFLICT (team_id) DO UPDATE
+SET enabled = true,
+    message = EXCLUDED.message,
+    updated_by = EXCLUDED.updated_by,
+    started_at = CASE WHEN maintenance_mode.enabled THEN maintenance_mode.started_at ELSE now() END,
+    updated_at = now()
+RETURNING team_id, enabled, message, updated_by, started_at, updated_at;
+
+-- name: DisableMaintenanceMode :one
+INSERT INTO maintenance_mode (enabled, updated_by)
+VALUES (false, sqlc.narg(updated_by))
+ON CONFLICT (team_id) DO UPDATE
+SET enabled = false,
+    updated_by = EXCLUDED.updated_by,
+    started_at = NULL,
+    updated_at = now()
+RETURNING team_id, enabled, message, updated_by, started_at, updated_at;
@@ -0,0 +1,53 @@
+-- name: GetBotMemoryCompactSchedule :one
+SELECT bot_id, team_id, pattern, timezone, ratio, decay_days, enabled, created_at, updated_at
+FROM bot_memory_compact_schedules
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: ListEnabledBotMemoryCompactSchedules :many
+SELECT bot_id, team_id, pattern, timezone, ratio, decay_days, enabled, created_at, updated_at
+FROM bot_memory_compact_schedules
+WHERE team_id = public.memoh_current_team_id()
+  AND enabled = true
+ORDER BY created_at;
+
+-- name: UpsertBotMemoryCompactSchedule :one
+INSERT INTO bot_memory_compact_schedules (bot_id, pattern, timezone, ratio, decay_days, enabled)
+VALUES (sqlc.arg(bot_id), sqlc.arg(pattern), sqlc.arg(timezone), sqlc.arg(ratio), sqlc.arg(decay_days), sqlc.arg(enabled))
+ON CONFLICT (bot_id) DO UPDATE
+SET pattern = EXCLUDED.pattern,
+    timezone = EXCLUDED.timezone,
+    ratio = EXCLUDED.ratio,
+    decay_days = EXCLUDED.decay_days,
+    enabled = EXCLUDED.enabled,
+    updated_at = now()
+RETURNING bot_id, team_id, pattern, timezone, ratio, decay_days, enabled, created_at, updated_at;
+
+-- name: DeleteBotMemoryCompactSchedule :exec
+DELETE FROM bot_memory_compact_schedules
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: CreateMemoryCompactRun :one
+INSERT INTO memory_compact_runs (bot_id, source, ratio, decay_days)
+VALUES (sqlc.arg(bot_id), sqlc.arg(source), sqlc.arg(ratio), sqlc.arg(decay_days))
+RETURNING id, team_id, bot_id, source, status, ratio, decay_days, before_count, after_count, error_message, started_at, completed_at;
+
+-- name: CompleteMemoryCompactRun :one
+UPDATE memory_compact_runs
+SET status = sqlc.arg(status),
+    before_count = sqlc.arg(before_count),
+    after_count = sqlc.arg(after_count),
+    error_message = sqlc.arg(error_message),
+    completed_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+RETURNING id, team_id, bot_id, source, status, ratio, decay_days, before_count, after_count, error_message, started_at, completed_at;
+
+-- name: ListMemoryCompactRuns :many
+SELECT id, team_id, bot_id, source, status, ratio, decay_days, before_count, after_count, error_message, started_at, completed_at
+FROM memory_compact_runs
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+ORDER BY started_at DESC, id
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,34 @@
+-- name: CreateMemoryFlag :one
+INSERT INTO memory_flags (bot_id, memory_id, memory, reason, session_id)
+VALUES (sqlc.arg(bot_id), sqlc.arg(memory_id), sqlc.arg(memory), sqlc.arg(reason), sqlc.arg(session_id))
+ON CONFLICT (bot_id, memory_id) WHERE status = 'pending' DO UPDATE
+SET memory = EXCLUDED.memory,
+    reason = EXCLUDED.reason,
+    session_id = EXCLUDED.session_id
+RETURNING id, team_id, bot_id, memory_id, memory, reason, session_id, status, created_at, resolved_at;
+
+-- name: ListMemoryFlags :many
+SELECT id, team_id, bot_id, memory_id, memory, reason, session_id, status, created_at, resolved_at
+FROM memory_flags
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status)::text)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: GetMemoryFlag :one
+SELECT id, team_id, bot_id, memory_id, memory, reason, session_id, status, created_at, resolved_at
+FROM memory_flags
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id);
+
+-- name: ResolveMemoryFlag :one
+UPDATE memory_flags
+SET status = sqlc.arg(status),
+    resolved_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id)
+  AND status = 'pending'
+RETURNING id, team_id, bot_id, memory_id, memory, reason, session_id, status, created_at, resolved_at;
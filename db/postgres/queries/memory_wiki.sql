@@ -46,6 +46,21 @@ WHERE team_id = public.memoh_current_team_id() AND bot_id = $1 AND id = $2;
 DELETE FROM memory_nodes
 WHERE team_id = public.memoh_current_team_id() AND bot_id = $1;
 
+-- name: ListBotsWithExpiredMemoryNodes :many
+SELECT DISTINCT bot_id FROM memory_nodes
+WHERE team_id = public.memoh_current_team_id()
+  AND expires_at IS NOT NULL
+  AND expires_at <= sqlc.arg(now)
+LIMIT sqlc.arg(row_limit);
+
+-- name: DeleteExpiredMemoryNodes :many
+DELETE FROM memory_nodes
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND expires_at IS NOT NULL
+  AND expires_at <= sqlc.arg(now)
+RETURNING id;
+
 -- name: CountMemoryNodesByBot :one
 SELECT COUNT(*) FROM memory_nodes
 WHERE team_id = public.memoh_current_team_id() AND bot_id = $1;
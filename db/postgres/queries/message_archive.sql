@@ -0,0 +1,55 @@
+-- name: ListMessagesForArchive :many
+-- Messages created before the cutoff whose content is no longer loaded as
+-- model context: they were compacted, or their session has been idle since
+-- the cutoff.
+SELECT m.id, m.bot_id, m.role, m.content, m.created_at
+FROM bot_history_messages m
+WHERE m.team_id = public.memoh_current_team_id()
+  AND m.created_at < sqlc.arg(before)
+  AND (sqlc.narg(after_id)::uuid IS NULL OR m.id > sqlc.narg(after_id)::uuid)
+  AND NOT EXISTS (
+    SELECT 1 FROM bot_history_message_archives a
+    WHERE a.message_id = m.id
+      AND a.team_id = public.memoh_current_team_id()
+  )
+  AND (
+    m.compact_id IS NOT NULL
+    OR m.session_id IS NULL
+    OR NOT EXISTS (
+      SELECT 1 FROM bot_history_messages newer
+      WHERE newer.team_id = public.memoh_current_team_id()
+        AND newer.session_id = m.session_id
+        AND newer.created_at >= sqlc.arg(before)
+    )
+  )
+ORDER BY m.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: ArchiveMessageContent :execrows
+WITH archived AS (
+  INSERT INTO bot_history_message_archives (message_id, bot_id, object_key, size_bytes)
+  VALUES (sqlc.arg(message_id), sqlc.arg(bot_id), sqlc.arg(object_key), sqlc.arg(size_bytes))
+  ON CONFLICT (message_id) DO NOTHING
+  RETURNING message_id
+)
+UPDATE bot_history_messages
+SET content = sqlc.arg(stub)
+WHERE team_id = public.memoh_current_team_id()
+  AND id IN (SELECT message_id FROM archived);
+
+-- name: ListOrphanedMessageArchives :many
+SELECT a.message_id, a.object_key
+FROM bot_history_message_archives a
+WHERE a.team_id = public.memoh_current_team_id()
+  AND NOT EXISTS (
+    SELECT 1 FROM bot_history_messages m
+    WHERE m.id = a.message_id
+      AND m.team_id = public.memoh_current_team_id()
+  )
+ORDER BY a.message_id
+LIMIT sqlc.arg(row_limit);
+
+-- name: DeleteMessageArchive :exec
+DELETE FROM bot_history_message_archives
+WHERE team_id = public.memoh_current_team_id()
+  AND message_id = sqlc.arg(message_id);
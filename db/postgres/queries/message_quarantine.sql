@@ -0,0 +1,19 @@
+-- name: InsertMessageQuarantine :one
+INSERT INTO bot_history_message_quarantine (bot_id, message_id, role, payload, reason, source)
+VALUES (sqlc.arg(bot_id), sqlc.narg(message_id)::uuid, sqlc.arg(role), sqlc.arg(payload), sqlc.arg(reason), sqlc.arg(source))
+RETURNING id, team_id, bot_id, message_id, role, payload, reason, source, created_at;
+
+-- name: ListMessageContentsForRepair :many
+SELECT id, bot_id, role, content
+FROM bot_history_messages
+WHERE team_id = public.memoh_current_team_id()
+  AND (sqlc.narg(bot_id)::uuid IS NULL OR bot_id = sqlc.narg(bot_id)::uuid)
+  AND (sqlc.narg(after_id)::uuid IS NULL OR id > sqlc.narg(after_id)::uuid)
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
+
+-- name: UpdateMessageContent :exec
+UPDATE bot_history_messages
+SET content = sqlc.arg(content)
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
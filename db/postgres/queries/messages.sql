@@ -2526,10 +2526,12 @@ SELECT
   m.content,
   m.created_at,
   ci.display_name AS sender_display_name,
-  s.channel_type AS platform
+  s.channel_type AS platform,
+  a.object_key AS archive_key
 FROM bot_visible_history_messages m
 LEFT JOIN channel_identities ci ON ci.id = m.sender_channel_identity_id AND ci.team_id = public.memoh_current_team_id()
 LEFT JOIN bot_sessions s ON s.id = m.session_id AND s.team_id = public.memoh_current_team_id()
+LEFT JOIN bot_history_message_archives a ON a.message_id = m.id AND a.team_id = public.memoh_current_team_id()
 WHERE m.team_id = public.memoh_current_team_id()
   AND m.bot_id = sqlc.arg(bot_id)
   AND (sqlc.narg(session_id)::uuid IS NULL OR m.session_id = sqlc.narg(session_id)::uuid)
@@ -2796,3 +2798,39 @@ FROM bot_history_messages m
 WHERE m.team_id = public.memoh_current_team_id()
   AND m.compact_id = $1
 ORDER BY m.created_at ASC, m.id ASC;
+
+-- name: ListMessageRefsBySenderChannelIdentity :many
+SELECT id, bot_id
+FROM bot_history_messages
+WHERE team_id = public.memoh_current_team_id()
+  AND sender_channel_identity_id = sqlc.arg(channel_identity_id)::uuid
+ORDER BY created_at, id;
+
+-- name: CountMessageAssetsByMessageIDs :one
+SELECT count(*)::bigint
+FROM bot_history_message_assets
+WHERE team_id = public.memoh_current_team_id()
+  AND message_id = ANY(sqlc.arg(message_ids)::uuid[]);
+
+-- name: ListMessagesForDataExport :many
+SELECT
+  m.id,
+  m.bot_id,
+  m.session_id,
+  m.sender_channel_identity_id,
+  m.sender_account_user_id AS sender_user_id,
+  m.role,
+  m.content,
+  m.display_text,
+  m.created_at,
+  s.channel_type AS platform,
+  a.object_key AS archive_key
+FROM bot_history_messages m
+LEFT JOIN bot_sessions s ON s.id = m.session_id AND s.team_id = public.memoh_current_team_id()
+LEFT JOIN bot_history_message_archives a ON a.message_id = m.id AND a.team_id = public.memoh_current_team_id()
+WHERE m.team_id = public.memoh_current_team_id()
+  AND (
+    m.sender_channel_identity_id = ANY(sqlc.arg(channel_identity_ids)::uuid[])
+    OR m.sender_account_user_id = sqlc.narg(user_id)::uuid
+  )
+ORDER BY m.created_at ASC, m.id ASC;
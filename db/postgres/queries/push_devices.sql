@@ -0,0 +1,56 @@
+-- name: UpsertPushDevice :one
+INSERT INTO push_devices (bot_id, user_id, platform, token, name)
+VALUES (sqlc.arg(bot_id), sqlc.arg(user_id), sqlc.arg(platform), sqlc.arg(token), sqlc.arg(name))
+ON CONFLICT (bot_id, platform, token) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    name = EXCLUDED.name,
+    updated_at = now()
+RETURNING id, team_id, bot_id, user_id, platform, token, name, muted, muted_until, last_used_at, created_at, updated_at;
+
+-- name: ListPushDevices :many
+SELECT id, team_id, bot_id, user_id, platform, token, name, muted, muted_until, last_used_at, created_at, updated_at
+FROM push_devices
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND user_id = sqlc.arg(user_id)
+ORDER BY created_at, id;
+
+-- name: ListActivePushDevices :many
+SELECT id, team_id, bot_id, user_id, platform, token, name, muted, muted_until, last_used_at, created_at, updated_at
+FROM push_devices
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+  AND user_id = sqlc.arg(user_id)
+  AND NOT muted
+  AND (muted_until IS NULL OR muted_until <= now())
+ORDER BY created_at, id;
+
+-- name: UpdatePushDeviceMute :one
+UPDATE push_devices
+SET muted = sqlc.arg(muted),
+    muted_until = sqlc.narg(muted_until),
+    updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id)
+  AND user_id = sqlc.arg(user_id)
+RETURNING id, team_id, bot_id, user_id, platform, token, name, muted, muted_until, last_used_at, created_at, updated_at;
+
+-- name: TouchPushDevice :exec
+UPDATE push_devices
+SET last_used_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: DeletePushDevice :execrows
+DELETE FROM push_devices
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id)
+  AND user_id = sqlc.arg(user_id);
+
+-- name: DeletePushDevicesByToken :exec
+DELETE FROM push_devices
+WHERE team_id = public.memoh_current_team_id()
+  AND platform = sqlc.arg(platform)
+  AND token = sqlc.arg(token);
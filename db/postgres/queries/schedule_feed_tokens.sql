@@ -0,0 +1,29 @@
+-- name: CreateScheduleFeedToken :one
+INSERT INTO schedule_feed_tokens (bot_id, name, token_hash, created_by)
+VALUES (sqlc.arg(bot_id), sqlc.arg(name), sqlc.arg(token_hash), sqlc.narg(created_by))
+RETURNING id, team_id, bot_id, name, token_hash, created_by, last_used_at, created_at;
+
+-- name: ListScheduleFeedTokens :many
+SELECT id, team_id, bot_id, name, token_hash, created_by, last_used_at, created_at
+FROM schedule_feed_tokens
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = sqlc.arg(bot_id)
+ORDER BY created_at DESC;
+
+-- name: GetScheduleFeedTokenByHash :one
+SELECT id, team_id, bot_id, name, token_hash, created_by, last_used_at, created_at
+FROM schedule_feed_tokens
+WHERE team_id = public.memoh_current_team_id()
+  AND token_hash = sqlc.arg(token_hash);
+
+-- name: TouchScheduleFeedToken :exec
+UPDATE schedule_feed_tokens
+SET last_used_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id);
+
+-- name: DeleteScheduleFeedToken :execrows
+DELETE FROM schedule_feed_tokens
+WHERE team_id = public.memoh_current_team_id()
+  AND id = sqlc.arg(id)
+  AND bot_id = sqlc.arg(bot_id);
@@ -33,3 +33,7 @@ WHERE team_id = public.memoh_current_team_id() AND session_id = $1;
 -- name: DeleteSessionEventsByBot :exec
 DELETE FROM bot_session_events
 WHERE team_id = public.memoh_current_team_id() AND bot_id = $1;
+
+-- name: DeleteSessionEventsBySenderChannelIdentity :execrows
+DELETE FROM bot_session_events
+WHERE team_id = public.memoh_current_team_id() AND sender_channel_identity_id = sqlc.arg(channel_identity_id)::uuid;
@@ -32,7 +32,13 @@ SELECT
   bots.overlay_provider,
   bots.overlay_enabled,
   bots.overlay_config,
-  bots.command_ui_language
+  bots.command_ui_language,
+  bots.inbound_rate_limit,
+  bots.inbound_rate_burst,
+  bots.wake_words_enabled,
+  bots.wake_words,
+  bots.wake_word_cooldown_seconds,
+  bots.persona
 FROM bots
 LEFT JOIN models AS chat_models ON chat_models.id = bots.chat_model_id AND chat_models.team_id = public.memoh_current_team_id()
 LEFT JOIN models AS heartbeat_models ON heartbeat_models.id = bots.heartbeat_model_id AND heartbeat_models.team_id = public.memoh_current_team_id()
@@ -84,9 +90,15 @@ WITH updated AS (
       overlay_enabled = sqlc.arg(overlay_enabled),
       overlay_config = sqlc.arg(overlay_config),
       command_ui_language = sqlc.arg(command_ui_language),
+      inbound_rate_limit = sqlc.arg(inbound_rate_limit),
+      inbound_rate_burst = sqlc.arg(inbound_rate_burst),
+      wake_words_enabled = sqlc.arg(wake_words_enabled),
+      wake_words = sqlc.arg(wake_words)::text[],
+      wake_word_cooldown_seconds = sqlc.arg(wake_word_cooldown_seconds),
+      persona = sqlc.arg(persona),
       updated_at = now()
   WHERE bots.team_id = public.memoh_current_team_id() AND bots.id = sqlc.arg(id)
-  RETURNING bots.id, bots.language, bots.reasoning_enabled, bots.reasoning_effort, bots.heartbeat_enabled, bots.heartbeat_interval, bots.heartbeat_prompt, bots.compaction_enabled, bots.compaction_threshold, bots.compaction_ratio, bots.timezone, bots.chat_model_id, bots.chat_runtime, bots.chat_acp_agent_id, bots.chat_acp_project_path, bots.chat_acp_project_mode, bots.heartbeat_model_id, bots.compaction_model_id, bots.image_model_id, bots.search_provider_id, bots.fetch_provider_id, bots.memory_provider_id, bots.tts_model_id, bots.transcription_model_id, bots.video_model_id, bots.persist_full_tool_results, bots.show_tool_calls_in_im, bots.tool_approval_config, bots.display_enabled, bots.overlay_provider, bots.overlay_enabled, bots.overlay_config, bots.command_ui_language
+  RETURNING bots.id, bots.language, bots.reasoning_enabled, bots.reasoning_effort, bots.heartbeat_enabled, bots.heartbeat_interval, bots.heartbeat_prompt, bots.compaction_enabled, bots.compaction_threshold, bots.compaction_ratio, bots.timezone, bots.chat_model_id, bots.chat_runtime, bots.chat_acp_agent_id, bots.chat_acp_project_path, bots.chat_acp_project_mode, bots.heartbeat_model_id, bots.compaction_model_id, bots.image_model_id, bots.search_provider_id, bots.fetch_provider_id, bots.memory_provider_id, bots.tts_model_id, bots.transcription_model_id, bots.video_model_id, bots.persist_full_tool_results, bots.show_tool_calls_in_im, bots.tool_approval_config, bots.display_enabled, bots.overlay_provider, bots.overlay_enabled, bots.overlay_config, bots.command_ui_language, bots.inbound_rate_limit, bots.inbound_rate_burst, bots.wake_words_enabled, bots.wake_words, bots.wake_word_cooldown_seconds, bots.persona
 )
 SELECT
   updated.id AS bot_id,
@@ -121,7 +133,13 @@ SELECT
   updated.overlay_provider,
   updated.overlay_enabled,
   updated.overlay_config,
-  updated.command_ui_language
+  updated.command_ui_language,
+  updated.inbound_rate_limit,
+  updated.inbound_rate_burst,
+  updated.wake_words_enabled,
+  updated.wake_words,
+  updated.wake_word_cooldown_seconds,
+  updated.persona
 FROM updated
 LEFT JOIN models AS chat_models ON chat_models.id = updated.chat_model_id AND chat_models.team_id = public.memoh_current_team_id()
 LEFT JOIN models AS heartbeat_models ON heartbeat_models.id = updated.heartbeat_model_id AND heartbeat_models.team_id = public.memoh_current_team_id()
@@ -167,5 +185,11 @@ SET language = 'auto',
     overlay_provider = '',
     overlay_enabled = false,
     overlay_config = '{}'::jsonb,
+    inbound_rate_limit = 0,
+    inbound_rate_burst = 0,
+    wake_words_enabled = false,
+    wake_words = '{}',
+    wake_word_cooldown_seconds = 0,
+    persona = '{}'::jsonb,
     updated_at = now()
 WHERE team_id = public.memoh_current_team_id() AND id = $1;
@@ -0,0 +1,43 @@
+-- name: GetUsageReportMessagesByPlatform :many
+SELECT
+  COALESCE(NULLIF(s.channel_type, ''), 'unknown')::text AS platform,
+  COUNT(*) FILTER (WHERE m.role = 'user')::bigint AS user_messages,
+  COUNT(*) FILTER (WHERE m.role = 'assistant')::bigint AS bot_messages,
+  COUNT(DISTINCT m.sender_channel_identity_id) FILTER (WHERE m.role = 'user')::bigint AS active_users
+FROM bot_history_messages m
+LEFT JOIN bot_sessions s ON s.id = m.session_id AND s.team_id = public.memoh_current_team_id()
+WHERE m.team_id = public.memoh_current_team_id() AND m.bot_id = sqlc.arg(bot_id)
+  AND m.role IN ('user', 'assistant')
+  AND m.created_at >= sqlc.arg(from_time)
+  AND m.created_at < sqlc.arg(to_time)
+GROUP BY 1
+ORDER BY user_messages DESC, platform;
+
+-- name: CountUsageReportActiveUsers :one
+SELECT COUNT(DISTINCT m.sender_channel_identity_id)::bigint AS active_users
+FROM bot_history_messages m
+WHERE m.team_id = public.memoh_current_team_id() AND m.bot_id = sqlc.arg(bot_id)
+  AND m.role = 'user'
+  AND m.created_at >= sqlc.arg(from_time)
+  AND m.created_at < sqlc.arg(to_time);
+
+-- name: GetUsageReportToolCalls :many
+SELECT
+  COALESCE(part->>'toolName', part->>'tool_name')::text AS tool_name,
+  COUNT(*)::bigint AS calls
+FROM bot_history_messages m,
+  jsonb_array_elements(
+    CASE WHEN jsonb_typeof(m.content->'content') = 'array' THEN m.content->'content'
+         WHEN jsonb_typeof(m.content) = 'array' THEN m.content
+         ELSE '[]'::jsonb
+    END
+  ) AS part
+WHERE m.team_id = public.memoh_current_team_id() AND m.bot_id = sqlc.arg(bot_id)
+  AND m.role = 'assistant'
+  AND m.created_at >= sqlc.arg(from_time)
+  AND m.created_at < sqlc.arg(to_time)
+  AND part->>'type' = 'tool-call'
+  AND COALESCE(part->>'toolName', part->>'tool_name', '') <> ''
+GROUP BY 1
+ORDER BY calls DESC, tool_name
+LIMIT sqlc.arg(row_limit);
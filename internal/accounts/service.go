@@ -26,8 +26,24 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInactiveAccount    = errors.New("account is inactive")
 	ErrInvalidTitleModel  = errors.New("invalid title model")
+	ErrInvalidRole        = errors.New("invalid role")
 )
 
+// Workspace roles. Admin holds every permission; the others are narrower
+// grants resolved by the policy package.
+const (
+	RoleAdmin      = "admin"
+	RoleOperator   = "operator"
+	RoleBotManager = "bot_manager"
+	RoleMember     = "member"
+	RoleViewer     = "viewer"
+)
+
+// Roles returns the assignable roles, most privileged first.
+func Roles() []string {
+	return []string{RoleAdmin, RoleOperator, RoleBotManager, RoleMember, RoleViewer}
+}
+
 // NewService creates a new accounts service.
 func NewService(log *slog.Logger, store dbstore.AccountStore) *Service {
 	if log == nil {
@@ -140,17 +156,30 @@ func (s *Service) SearchAccounts(ctx context.Context, query string, limit int) (
 
 // IsAdmin checks if the user has admin role.
 func (s *Service) IsAdmin(ctx context.Context, userID string) (bool, error) {
+	role, err := s.Role(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return role == RoleAdmin, nil
+}
+
+// Role returns the user's role in the current workspace. Inactive members and
+// users without a membership have no role and get "".
+func (s *Service) Role(ctx context.Context, userID string) (string, error) {
 	if s.store == nil {
-		return false, errors.New("account store not configured")
+		return "", errors.New("account store not configured")
 	}
 	row, err := s.store.GetByUserID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, db.ErrNotFound) {
-			return false, nil
+			return "", nil
 		}
-		return false, err
+		return "", err
+	}
+	if !row.IsActive {
+		return "", nil
 	}
-	return row.IsActive && isAdminRole(row.Role), nil
+	return strings.ToLower(strings.TrimSpace(row.Role)), nil
 }
 
 // Create creates a new account for an existing user.
@@ -359,28 +388,16 @@ func (s *Service) RemoveMember(ctx context.Context, userID string) error {
 }
 
 func normalizeRole(raw string) (string, error) {
-	role := strings.ToLower(strings.TrimSpace(raw))
+	role := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(raw)), "-", "_")
 	if role == "" {
-		return "member", nil
+		return RoleMember, nil
 	}
-	if role != "member" && role != "admin" {
-		return "", fmt.Errorf("invalid role: %s", raw)
-	}
-	return role, nil
-}
-
-func isAdminRole(role any) bool {
-	if role == nil {
-		return false
-	}
-	switch v := role.(type) {
-	case string:
-		return strings.EqualFold(v, "admin")
-	case fmt.Stringer:
-		return strings.EqualFold(v.String(), "admin")
-	default:
-		return strings.EqualFold(fmt.Sprint(v), "admin")
+	for _, known := range Roles() {
+		if role == known {
+			return role, nil
+		}
 	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidRole, raw)
 }
 
 func toAccount(row dbstore.AccountRecord) Account {
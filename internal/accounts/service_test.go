@@ -135,6 +135,39 @@ func TestUpdateAdminLeavesMembershipStateUnspecified(t *testing.T) {
 	}
 }
 
+func TestUpdateAdminAssignsRoles(t *testing.T) {
+	store := &testAccountStore{record: dbstore.AccountRecord{ID: "user-1", Role: "member", IsActive: true}}
+	svc := NewService(nil, store)
+
+	role := "Bot-Manager"
+	if _, err := svc.UpdateAdmin(context.Background(), "user-1", UpdateAccountRequest{Role: &role}); err != nil {
+		t.Fatalf("UpdateAdmin() error = %v", err)
+	}
+	if store.adminUpdated.Role != RoleBotManager {
+		t.Fatalf("role = %q, want %q", store.adminUpdated.Role, RoleBotManager)
+	}
+
+	role = "superuser"
+	if _, err := svc.UpdateAdmin(context.Background(), "user-1", UpdateAccountRequest{Role: &role}); !errors.Is(err, ErrInvalidRole) {
+		t.Fatalf("UpdateAdmin() error = %v, want ErrInvalidRole", err)
+	}
+}
+
+func TestRoleIsEmptyForInactiveAccount(t *testing.T) {
+	store := &testAccountStore{record: dbstore.AccountRecord{ID: "user-1", Role: "operator", IsActive: true}}
+	svc := NewService(nil, store)
+
+	role, err := svc.Role(context.Background(), "user-1")
+	if err != nil || role != RoleOperator {
+		t.Fatalf("Role() = %q, %v, want %q", role, err, RoleOperator)
+	}
+	store.record.IsActive = false
+	role, err = svc.Role(context.Background(), "user-1")
+	if err != nil || role != "" {
+		t.Fatalf("Role() inactive = %q, %v, want no role", role, err)
+	}
+}
+
 func TestUpdateProfileValidatesAndPersistsTitleModel(t *testing.T) {
 	modelID := "11111111-1111-1111-1111-111111111111"
 	store := &testAccountStore{
@@ -42,6 +42,11 @@ type UpdateAccountRequest struct {
 	IsActive *bool   `json:"is_active,omitempty"`
 }
 
+// UpdateRoleRequest is the input for assigning a workspace role.
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}
+
 // UpdateProfileRequest is the input for self-service profile updates.
 type UpdateProfileRequest struct {
 	DisplayName  *string                `json:"display_name,omitempty"`
@@ -0,0 +1,39 @@
+// Package channelsticker adapts Channel sticker catalogs to Agent messaging
+// stickers.
+package channelsticker
+
+import (
+	"context"
+
+	"github.com/memohai/memoh/internal/channel/stickers"
+	"github.com/memohai/memoh/internal/messaging"
+)
+
+type CatalogLister interface {
+	List(ctx context.Context, botID string) ([]stickers.Sticker, error)
+}
+
+type Source struct {
+	catalog CatalogLister
+}
+
+func NewSource(catalog CatalogLister) *Source {
+	return &Source{catalog: catalog}
+}
+
+func (s *Source) ListStickers(ctx context.Context, botID string) ([]messaging.Sticker, error) {
+	catalog, err := s.catalog.List(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]messaging.Sticker, 0, len(catalog))
+	for _, item := range catalog {
+		out = append(out, messaging.Sticker{
+			Name:        item.Name,
+			Description: item.Description,
+			Emoji:       item.Emoji,
+			Platforms:   item.Platforms,
+		})
+	}
+	return out, nil
+}
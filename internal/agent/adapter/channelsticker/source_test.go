@@ -0,0 +1,34 @@
+package channelsticker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel/stickers"
+)
+
+type fakeCatalogLister struct {
+	items []stickers.Sticker
+}
+
+func (f fakeCatalogLister) List(context.Context, string) ([]stickers.Sticker, error) {
+	return f.items, nil
+}
+
+func TestSourceProjectsCatalogSticker(t *testing.T) {
+	t.Parallel()
+
+	source := NewSource(fakeCatalogLister{items: []stickers.Sticker{{
+		Name:        "wave",
+		Description: "Greeting",
+		Emoji:       "👋",
+		Platforms:   map[string]string{"telegram": "CAACAgIAAx"},
+	}}})
+	got, err := source.ListStickers(context.Background(), "bot-1")
+	if err != nil {
+		t.Fatalf("ListStickers: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "wave" || got[0].Emoji != "👋" || got[0].NativeKey("Telegram") != "CAACAgIAAx" {
+		t.Fatalf("unexpected stickers: %#v", got)
+	}
+}
@@ -36,7 +36,7 @@ type acpContextRenderInput struct {
 }
 
 func (s *Service) buildACPContextMarkdown(ctx context.Context, req ChatRequest, agentID, projectPath string) string {
-	timezoneName, timezoneLocation := s.resolveTimezone(ctx, req.BotID, req.UserID)
+	timezoneName, timezoneLocation := s.resolveTimezone(ctx, req.BotID, req.SourceChannelIdentityID, req.UserID)
 	now := time.Now().UTC()
 	if timezoneLocation != nil {
 		now = now.In(timezoneLocation)
@@ -145,7 +145,7 @@ func TestSelectorToReadPathPreservesOrderEndToEnd(t *testing.T) {
 
 	got := replaceCompactedHistoryRecords(records, map[string]string{compactID: res.Summary}, contextfrag.Scope{})
 	want := []ModelMessage{
-		{Role: "user", Content: newTextContent("<summary>\n" + res.Summary + "\n</summary>")},
+		{Role: "system", Content: newTextContent("<summary>\n" + res.Summary + "\n</summary>")},
 		{Role: "assistant", Content: newTextContent("ask you something")},
 		{Role: "tool", Content: newTextContent("answered")},
 		{Role: "user", Content: newTextContent("mid q")},
@@ -0,0 +1,213 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	historyfrag "github.com/memohai/memoh/internal/agent/context/history"
+	textprune "github.com/memohai/memoh/internal/prune"
+)
+
+const (
+	DefaultToolHistoryKeepTurns      = 3
+	DefaultToolHistoryResultMaxBytes = 8 * 1024
+
+	toolHistoryCollapsedMarker = "[memoh collapsed]"
+	toolHistoryPreviewRunes    = 160
+)
+
+// ToolHistoryBudget controls how tool traffic from earlier turns is encoded
+// in the model context. Non-positive fields fall back to the defaults.
+type ToolHistoryBudget struct {
+	// KeepTurns is how many of the most recent user turns keep raw tool
+	// results. Results in older turns collapse to a one-line outcome next
+	// to their (untouched) tool call.
+	KeepTurns int
+	// ResultMaxBytes caps raw results in the kept turns other than the
+	// latest one; longer results are reduced to their head and tail.
+	ResultMaxBytes int
+}
+
+func (b ToolHistoryBudget) normalized() ToolHistoryBudget {
+	if b.KeepTurns <= 0 {
+		b.KeepTurns = DefaultToolHistoryKeepTurns
+	}
+	if b.ResultMaxBytes <= 0 {
+		b.ResultMaxBytes = DefaultToolHistoryResultMaxBytes
+	}
+	return b
+}
+
+func (b ToolHistoryBudget) resultPruneConfig() textprune.Config {
+	return textprune.Config{
+		MaxBytes:  b.ResultMaxBytes,
+		MaxLines:  gatewayToolPayloadMaxLines,
+		HeadBytes: b.ResultMaxBytes * 3 / 4,
+		TailBytes: b.ResultMaxBytes / 4,
+		HeadLines: gatewayToolResultHeadLines,
+		TailLines: gatewayToolResultTailLines,
+		Marker:    gatewayToolPayloadPrunedMarker,
+	}
+}
+
+// collapseToolHistory shrinks tool results by age. The latest turn is left
+// as is so a resumed tool loop still sees what it just produced; earlier
+// kept turns are capped at ResultMaxBytes and anything older is replaced by
+// a "<tool> ok|error: <preview>" line. Tool messages keep their role, call
+// ID and part shape, so call/result pairing stays valid for providers.
+func collapseToolHistory(messages []historyfrag.HistoryRecord, budget ToolHistoryBudget) []historyfrag.HistoryRecord {
+	if len(messages) == 0 {
+		return messages
+	}
+	budget = budget.normalized()
+	turnAge := make([]int, len(messages))
+	age := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		turnAge[i] = age
+		if strings.EqualFold(strings.TrimSpace(messages[i].ModelMessage.Role), "user") {
+			age++
+		}
+	}
+
+	pruneCfg := budget.resultPruneConfig()
+	callNames := map[string]string{}
+	out := make([]historyfrag.HistoryRecord, 0, len(messages))
+	staleUsage := false
+	for i, item := range messages {
+		msg := item.ModelMessage
+		for _, call := range msg.ToolCalls {
+			callNames[call.ID] = call.Function.Name
+		}
+		if turnAge[i] > 0 && strings.EqualFold(strings.TrimSpace(msg.Role), "tool") {
+			var changed bool
+			if turnAge[i] >= budget.KeepTurns {
+				msg, changed = collapseToolMessage(msg, callNames)
+			} else if pruned, ok := pruneToolResultParts(msg.Content, pruneCfg); ok {
+				msg.Content, changed = pruned, true
+			} else if text := msg.TextContent(); textprune.Exceeds(text, pruneCfg.MaxBytes, pruneCfg.MaxLines) {
+				msg.Content, changed = newTextContent(textprune.PruneWithEdges(text, "tool result", pruneCfg)), true
+			}
+			if changed {
+				item.ModelMessage = msg
+				staleUsage = true
+			}
+		}
+		if staleUsage {
+			item.UsageInputTokens = nil
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func collapseToolMessage(msg ModelMessage, callNames map[string]string) (ModelMessage, bool) {
+	var parts []map[string]json.RawMessage
+	if err := json.Unmarshal(msg.Content, &parts); err == nil && len(parts) > 0 {
+		changed := false
+		for _, part := range parts {
+			var partType, toolName string
+			_ = json.Unmarshal(part["type"], &partType)
+			if partType != "tool-result" {
+				continue
+			}
+			_ = json.Unmarshal(part["toolName"], &toolName)
+			status, text := toolOutputOutcome(part["output"])
+			if strings.HasPrefix(text, toolHistoryCollapsedMarker) {
+				continue
+			}
+			output, err := json.Marshal(map[string]string{"type": "text", "value": collapsedToolLine(toolName, status, text)})
+			if err != nil {
+				continue
+			}
+			part["output"] = output
+			changed = true
+		}
+		if !changed {
+			return msg, false
+		}
+		rebuilt, err := json.Marshal(parts)
+		if err != nil {
+			return msg, false
+		}
+		msg.Content = rebuilt
+		return msg, true
+	}
+
+	// Backward-compat: tool messages persisted as plain strings.
+	text := msg.TextContent()
+	if text == "" || strings.HasPrefix(text, toolHistoryCollapsedMarker) {
+		return msg, false
+	}
+	name := strings.TrimSpace(msg.Name)
+	if name == "" {
+		name = callNames[msg.ToolCallID]
+	}
+	msg.Content = newTextContent(collapsedToolLine(name, "ok", text))
+	return msg, true
+}
+
+// toolOutputOutcome reads the status and text of a tool-result output
+// ({"type": "text"|"json"|"content"|"error-text"|..., "value": ...}).
+func toolOutputOutcome(raw json.RawMessage) (string, string) {
+	var output struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &output); err != nil {
+		return "ok", strings.TrimSpace(string(raw))
+	}
+	status := "ok"
+	switch {
+	case strings.HasPrefix(output.Type, "error"):
+		status = "error"
+	case output.Type == "execution-denied":
+		status = "denied"
+	}
+	switch output.Type {
+	case "text", "error-text":
+		var s string
+		if err := json.Unmarshal(output.Value, &s); err == nil {
+			return status, s
+		}
+	case "content":
+		var items []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(output.Value, &items); err == nil {
+			texts := make([]string, 0, len(items))
+			for _, item := range items {
+				if item.Type == "text" {
+					texts = append(texts, item.Text)
+				} else if item.Type != "" {
+					texts = append(texts, "<"+item.Type+">")
+				}
+			}
+			return status, strings.Join(texts, " ")
+		}
+	}
+	return status, strings.TrimSpace(string(output.Value))
+}
+
+func collapsedToolLine(toolName, status, text string) string {
+	if toolName = strings.TrimSpace(toolName); toolName == "" {
+		toolName = "tool"
+	}
+	size := len(text)
+	preview := strings.Join(strings.Fields(text), " ")
+	truncated := false
+	if utf8.RuneCountInString(preview) > toolHistoryPreviewRunes {
+		preview = string([]rune(preview)[:toolHistoryPreviewRunes])
+		truncated = true
+	}
+	line := fmt.Sprintf("%s %s %s", toolHistoryCollapsedMarker, toolName, status)
+	if preview != "" {
+		line += ": " + preview
+	}
+	if truncated {
+		line += fmt.Sprintf("… (%d bytes)", size)
+	}
+	return line
+}
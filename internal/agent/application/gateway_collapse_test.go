@@ -0,0 +1,115 @@
+package application
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	historyfrag "github.com/memohai/memoh/internal/agent/context/history"
+)
+
+func toolResultContent(t *testing.T, toolName, outputType string, value any) json.RawMessage {
+	t.Helper()
+	content, err := json.Marshal([]any{map[string]any{
+		"type":       "tool-result",
+		"toolCallId": "call-" + toolName,
+		"toolName":   toolName,
+		"output":     map[string]any{"type": outputType, "value": value},
+	}})
+	if err != nil {
+		t.Fatalf("marshal tool content: %v", err)
+	}
+	return content
+}
+
+func toolResultOutputValue(t *testing.T, msg ModelMessage) string {
+	t.Helper()
+	var parts []struct {
+		ToolCallID string `json:"toolCallId"`
+		Output     struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"output"`
+	}
+	if err := json.Unmarshal(msg.Content, &parts); err != nil || len(parts) != 1 {
+		t.Fatalf("expected one tool-result part, got %s (%v)", string(msg.Content), err)
+	}
+	if parts[0].ToolCallID == "" {
+		t.Fatalf("expected toolCallId to be preserved: %s", string(msg.Content))
+	}
+	return parts[0].Output.Value
+}
+
+func TestCollapseToolHistoryByTurnAge(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("line of output\n", 400)
+	tokens := 99
+	in := []historyfrag.HistoryRecord{
+		historyRecord("u-1", ModelMessage{Role: "user", Content: newTextContent("list files")}, nil),
+		historyRecord("a-1", ModelMessage{Role: "assistant", ToolCalls: []ToolCall{{ID: "call-old", Type: "function", Function: ToolCallFunction{Name: "exec", Arguments: `{"cmd":"ls"}`}}}}, nil),
+		historyRecord("t-1", ModelMessage{Role: "tool", ToolCallID: "call-old", Content: newTextContent("a.txt\nb.txt")}, nil),
+		historyRecord("t-2", ModelMessage{Role: "tool", Content: toolResultContent(t, "read", "error-text", "permission denied")}, nil),
+		historyRecord("u-2", ModelMessage{Role: "user", Content: newTextContent("read it")}, func(record *historyfrag.HistoryRecord) {
+			record.UsageInputTokens = &tokens
+		}),
+		historyRecord("t-3", ModelMessage{Role: "tool", Content: toolResultContent(t, "read", "text", long)}, nil),
+		historyRecord("u-3", ModelMessage{Role: "user", Content: newTextContent("again")}, nil),
+		historyRecord("t-4", ModelMessage{Role: "tool", Content: toolResultContent(t, "read", "text", long)}, nil),
+	}
+
+	out := collapseToolHistory(in, ToolHistoryBudget{KeepTurns: 2, ResultMaxBytes: 1024})
+	if len(out) != len(in) {
+		t.Fatalf("expected %d records, got %d", len(in), len(out))
+	}
+
+	if got := out[2].ModelMessage.TextContent(); got != "[memoh collapsed] exec ok: a.txt b.txt" {
+		t.Fatalf("unexpected collapsed plain result: %q", got)
+	}
+	if got := toolResultOutputValue(t, out[3].ModelMessage); got != "[memoh collapsed] read error: permission denied" {
+		t.Fatalf("unexpected collapsed part result: %q", got)
+	}
+	if len(out[1].ModelMessage.ToolCalls) != 1 || out[1].ModelMessage.ToolCalls[0].Function.Arguments != `{"cmd":"ls"}` {
+		t.Fatalf("tool calls must be kept: %+v", out[1].ModelMessage.ToolCalls)
+	}
+
+	capped := toolResultOutputValue(t, out[5].ModelMessage)
+	if len(capped) > 1024 || !strings.Contains(capped, gatewayToolPayloadPrunedMarker) {
+		t.Fatalf("expected kept result capped to budget, got %d bytes", len(capped))
+	}
+	if got := toolResultOutputValue(t, out[7].ModelMessage); got != long {
+		t.Fatalf("latest turn must stay raw")
+	}
+	if out[4].UsageInputTokens != nil {
+		t.Fatalf("expected usage tokens after a collapsed record to be cleared")
+	}
+}
+
+func TestCollapseToolHistoryIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	in := []historyfrag.HistoryRecord{
+		historyRecord("u-1", ModelMessage{Role: "user", Content: newTextContent("q")}, nil),
+		historyRecord("t-1", ModelMessage{Role: "tool", Content: toolResultContent(t, "search", "json", map[string]any{"hits": 3})}, nil),
+		historyRecord("u-2", ModelMessage{Role: "user", Content: newTextContent("q2")}, nil),
+		historyRecord("u-3", ModelMessage{Role: "user", Content: newTextContent("q3")}, nil),
+	}
+	budget := ToolHistoryBudget{KeepTurns: 1}
+	once := collapseToolHistory(in, budget)
+	if got := toolResultOutputValue(t, once[1].ModelMessage); got != `[memoh collapsed] search ok: {"hits":3}` {
+		t.Fatalf("unexpected collapsed json result: %q", got)
+	}
+	twice := collapseToolHistory(once, budget)
+	if string(twice[1].ModelMessage.Content) != string(once[1].ModelMessage.Content) {
+		t.Fatalf("collapsing twice changed content: %s", string(twice[1].ModelMessage.Content))
+	}
+}
+
+func TestCollapsedToolLineTruncatesPreview(t *testing.T) {
+	t.Parallel()
+
+	line := collapsedToolLine("", "ok", strings.Repeat("数据 ", 200))
+	if !strings.HasPrefix(line, "[memoh collapsed] tool ok: 数据") || !strings.HasSuffix(line, "(1400 bytes)") {
+		t.Fatalf("unexpected line: %q", line)
+	}
+}
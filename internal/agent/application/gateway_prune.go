@@ -27,6 +27,16 @@ const (
 	gatewayToolPayloadPrunedMarker = textprune.DefaultMarker
 )
 
+var gatewayToolResultPruneConfig = textprune.Config{
+	MaxBytes:  gatewayToolPayloadMaxBytes,
+	MaxLines:  gatewayToolPayloadMaxLines,
+	HeadBytes: gatewayToolResultHeadBytes,
+	TailBytes: gatewayToolResultTailBytes,
+	HeadLines: gatewayToolResultHeadLines,
+	TailLines: gatewayToolResultTailLines,
+	Marker:    gatewayToolPayloadPrunedMarker,
+}
+
 func pruneHistoryForGateway(messages []historyfrag.HistoryRecord) []historyfrag.HistoryRecord {
 	if len(messages) == 0 {
 		return messages
@@ -103,7 +113,7 @@ func pruneToolCalls(calls []ToolCall) ([]ToolCall, bool) {
 func pruneToolMessage(msg ModelMessage) (ModelMessage, bool) {
 	// Vercel AI SDK schema requires tool messages to carry an array of tool-result parts.
 	// Prune outputs inside those parts (preserving shape) so the gateway prompt remains valid.
-	if pruned, ok := pruneToolResultParts(msg.Content); ok {
+	if pruned, ok := pruneToolResultParts(msg.Content, gatewayToolResultPruneConfig); ok {
 		msg.Content = pruned
 		return msg, true
 	}
@@ -124,7 +134,7 @@ func pruneToolMessage(msg ModelMessage) (ModelMessage, bool) {
 	return msg, true
 }
 
-func pruneToolResultParts(content json.RawMessage) (json.RawMessage, bool) {
+func pruneToolResultParts(content json.RawMessage, cfg textprune.Config) (json.RawMessage, bool) {
 	if len(content) == 0 {
 		return nil, false
 	}
@@ -158,7 +168,7 @@ func pruneToolResultParts(content json.RawMessage) (json.RawMessage, bool) {
 			out = append(out, raw)
 			continue
 		}
-		pruned, didPrune := pruneToolOutput(outputRaw)
+		pruned, didPrune := pruneToolOutput(outputRaw, cfg)
 		if !didPrune {
 			out = append(out, raw)
 			continue
@@ -184,7 +194,7 @@ func pruneToolResultParts(content json.RawMessage) (json.RawMessage, bool) {
 	return json.RawMessage(rebuilt), true
 }
 
-func pruneToolOutput(raw json.RawMessage) (json.RawMessage, bool) {
+func pruneToolOutput(raw json.RawMessage, cfg textprune.Config) (json.RawMessage, bool) {
 	var output map[string]json.RawMessage
 	if err := json.Unmarshal(raw, &output); err != nil {
 		return nil, false
@@ -205,17 +215,10 @@ func pruneToolOutput(raw json.RawMessage) (json.RawMessage, bool) {
 			return nil, false
 		}
 		var s string
-		if err := json.Unmarshal(valueRaw, &s); err != nil || !exceedsTextBudget(s) {
+		if err := json.Unmarshal(valueRaw, &s); err != nil || !textprune.Exceeds(s, cfg.MaxBytes, cfg.MaxLines) {
 			return nil, false
 		}
-		s = pruneStringEdges(
-			s,
-			gatewayToolResultHeadBytes,
-			gatewayToolResultTailBytes,
-			gatewayToolResultHeadLines,
-			gatewayToolResultTailLines,
-			"tool result",
-		)
+		s = textprune.PruneWithEdges(s, "tool result", cfg)
 		data, err := json.Marshal(s)
 		if err != nil {
 			return nil, false
@@ -228,17 +231,10 @@ func pruneToolOutput(raw json.RawMessage) (json.RawMessage, bool) {
 		return json.RawMessage(rebuilt), true
 
 	case "json", "error-json":
-		if !hasValue || !exceedsTextBudget(string(valueRaw)) {
+		if !hasValue || !textprune.Exceeds(string(valueRaw), cfg.MaxBytes, cfg.MaxLines) {
 			return nil, false
 		}
-		pruned := pruneStringEdges(
-			string(valueRaw),
-			gatewayToolResultHeadBytes,
-			gatewayToolResultTailBytes,
-			gatewayToolResultHeadLines,
-			gatewayToolResultTailLines,
-			"tool result (json)",
-		)
+		pruned := textprune.PruneWithEdges(string(valueRaw), "tool result (json)", cfg)
 		data, err := json.Marshal(pruned)
 		if err != nil {
 			return nil, false
@@ -270,17 +266,10 @@ func pruneToolOutput(raw json.RawMessage) (json.RawMessage, bool) {
 				continue
 			}
 			text, ok := textAny.(string)
-			if !ok || !exceedsTextBudget(text) {
+			if !ok || !textprune.Exceeds(text, cfg.MaxBytes, cfg.MaxLines) {
 				continue
 			}
-			items[i]["text"] = pruneStringEdges(
-				text,
-				gatewayToolResultHeadBytes,
-				gatewayToolResultTailBytes,
-				gatewayToolResultHeadLines,
-				gatewayToolResultTailLines,
-				"tool result (content)",
-			)
+			items[i]["text"] = textprune.PruneWithEdges(text, "tool result (content)", cfg)
 			didPrune = true
 		}
 		if !didPrune {
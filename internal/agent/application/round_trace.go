@@ -0,0 +1,111 @@
+package application
+
+import (
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/agent/runtime/native"
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
+)
+
+// roundTrace times a streamed round from stream events. The result is stored
+// on the round's last assistant message and served by the message trace API.
+type roundTrace struct {
+	mu          sync.Mutex
+	now         func() time.Time
+	started     time.Time
+	modelCalled time.Time
+	firstOutput time.Time
+	toolStarts  map[string]time.Time
+	trace       messagepkg.RoundTrace
+}
+
+func newRoundTrace(now func() time.Time) *roundTrace {
+	return &roundTrace{
+		now:        now,
+		started:    now(),
+		toolStarts: map[string]time.Time{},
+	}
+}
+
+// resolved marks the end of context resolution, just before the model is
+// called.
+func (t *roundTrace) resolved(rc resolvedContext) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.modelCalled = t.now()
+	t.trace.ResolveMs = t.modelCalled.Sub(t.started).Milliseconds()
+	t.trace.HistoryTokens = rc.estimatedTokens
+	t.trace.ModelID = rc.model.ID
+	t.trace.ModelSlug = rc.model.ModelID
+	t.trace.ModelName = rc.model.Name
+	t.trace.ProviderName = rc.provider.Name
+}
+
+func (t *roundTrace) observe(event native.StreamEvent) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if t.firstOutput.IsZero() && !t.modelCalled.IsZero() && isTracedOutput(event.Type) {
+		t.firstOutput = now
+		t.trace.FirstOutputMs = now.Sub(t.modelCalled).Milliseconds()
+	}
+	switch event.Type {
+	case native.EventRetry:
+		t.trace.Retries++
+	case native.EventToolCallStart:
+		if _, ok := t.toolStarts[event.ToolCallID]; !ok {
+			t.toolStarts[event.ToolCallID] = now
+			t.trace.ToolCalls = append(t.trace.ToolCalls, messagepkg.ToolCallTrace{
+				ToolCallID: event.ToolCallID,
+				Name:       event.ToolName,
+				Status:     "pending",
+			})
+		}
+	case native.EventToolCallEnd:
+		started, ok := t.toolStarts[event.ToolCallID]
+		if !ok {
+			return
+		}
+		for i := range t.trace.ToolCalls {
+			call := &t.trace.ToolCalls[i]
+			if call.ToolCallID != event.ToolCallID {
+				continue
+			}
+			call.DurationMs = now.Sub(started).Milliseconds()
+			call.Status = "ok"
+			if event.Error != "" {
+				call.Status = "error"
+			}
+		}
+	}
+}
+
+func isTracedOutput(eventType native.StreamEventType) bool {
+	switch eventType {
+	case native.EventTextStart, native.EventTextDelta, native.EventReasoningStart,
+		native.EventReasoningDelta, native.EventToolCallInputStart, native.EventToolCallStart:
+		return true
+	}
+	return false
+}
+
+// metadata returns the trace as message metadata, timed up to now.
+func (t *roundTrace) metadata(memoryWrite bool) map[string]any {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	trace := t.trace
+	trace.TotalMs = t.now().Sub(t.started).Milliseconds()
+	trace.ToolCalls = append([]messagepkg.ToolCallTrace(nil), t.trace.ToolCalls...)
+	t.mu.Unlock()
+	trace.MemoryWrite = memoryWrite
+	return map[string]any{messagepkg.TraceMetadataKey: trace}
+}
@@ -37,12 +37,14 @@ import (
 	"github.com/memohai/memoh/internal/chat/timeline"
 	"github.com/memohai/memoh/internal/db/postgres/sqlc"
 	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/featureflags"
 	"github.com/memohai/memoh/internal/hooks"
 	memprovider "github.com/memohai/memoh/internal/memory/adapters"
 	"github.com/memohai/memoh/internal/models"
 	"github.com/memohai/memoh/internal/oauthctx"
 	"github.com/memohai/memoh/internal/providers"
 	"github.com/memohai/memoh/internal/settings"
+	"github.com/memohai/memoh/internal/telemetry"
 	"github.com/memohai/memoh/internal/workspace"
 )
 
@@ -85,6 +87,12 @@ type PlatformIdentitySource interface {
 	ListPlatformIdentities(ctx context.Context, botID string) ([]PlatformIdentity, error)
 }
 
+// ContactTimezoneSource supplies the timezone known for a channel contact:
+// the one they gave during onboarding or the one their platform reports.
+type ContactTimezoneSource interface {
+	ContactTimezone(ctx context.Context, botID, channelIdentityID string) (string, error)
+}
+
 type botPermissionChecker interface {
 	HasBotPermission(ctx context.Context, botID, accountID, permission string) (bool, error)
 }
@@ -99,6 +107,7 @@ type Service struct {
 	modelsService      *models.Service
 	queries            dbstore.Queries
 	memoryRegistry     *memprovider.Registry
+	knowledge          knowledgeRetriever
 	messageService     messagepkg.Service
 	settingsService    *settings.Service
 	accountService     *accounts.Service
@@ -109,6 +118,7 @@ type Service struct {
 	skillLoader        SkillLoader
 	assetLoader        gatewayAssetLoader
 	platformIdentities PlatformIdentitySource
+	contactTimezones   ContactTimezoneSource
 	botPermissions     botPermissionChecker
 	workspaceTargets   workspaceTargetResolver
 	pipeline           *timeline.Pipeline
@@ -117,6 +127,7 @@ type Service struct {
 	toolApproval       *toolapproval.Service
 	userInput          userInputService
 	hookService        *hooks.Service
+	featureFlags       featureflags.Checker
 	memoryContextMu    sync.Mutex
 	memoryContextCache *memprovider.MemoryContextCache
 	acpPromptMu        sync.Mutex
@@ -131,6 +142,8 @@ type Service struct {
 	sessionCompactions  map[string]*sessionCompactionGate
 	timeout             time.Duration
 	memorySearchTimeout time.Duration
+	toolHistory         ToolHistoryBudget
+	compactionPercent   int
 	clockLocation       *time.Location
 	logger              *slog.Logger
 	allowedTeam         string
@@ -163,7 +176,7 @@ func NewService(
 	// - Timeout: overall request lifetime cap (prevents stuck SSE body reads)
 	streamHTTPClient := &http.Client{
 		Timeout: 10 * time.Minute, // overall cap, matches the application timeout
-		Transport: &http.Transport{
+		Transport: telemetry.Transport(&http.Transport{
 			DialContext: (&net.Dialer{
 				Timeout:   30 * time.Second,
 				KeepAlive: 30 * time.Second,
@@ -173,7 +186,7 @@ func NewService(
 			MaxIdleConns:          100,
 			MaxIdleConnsPerHost:   10,
 			IdleConnTimeout:       90 * time.Second,
-		},
+		}),
 	}
 
 	return &Service{
@@ -200,6 +213,11 @@ func (s *Service) SetMemoryRegistry(registry *memprovider.Registry) {
 }
 
 // SetSkillLoader sets the skill loader used to populate usable skills in gateway requests.
+// SetFeatureFlags sets the flags that gate risky features per bot.
+func (s *Service) SetFeatureFlags(flags featureflags.Checker) {
+	s.featureFlags = flags
+}
+
 func (s *Service) SetSkillLoader(sl SkillLoader) {
 	s.skillLoader = sl
 }
@@ -225,6 +243,12 @@ func (s *Service) SetPlatformIdentitySource(source PlatformIdentitySource) {
 	s.platformIdentities = source
 }
 
+// SetContactTimezoneSource configures the per-contact timezone lookup that
+// takes precedence over bot and account timezones.
+func (s *Service) SetContactTimezoneSource(source ContactTimezoneSource) {
+	s.contactTimezones = source
+}
+
 // SetCompactionService configures the compaction service for context compaction.
 func (s *Service) SetCompactionService(service *compaction.Service) {
 	s.compactionService = service
@@ -236,6 +260,19 @@ func (s *Service) SetBackgroundManager(m *background.Manager) {
 	s.bgManager = m
 }
 
+// SetToolHistoryBudget configures how tool results from earlier turns are
+// collapsed when history is loaded into the model context.
+func (s *Service) SetToolHistoryBudget(budget ToolHistoryBudget) {
+	s.toolHistory = budget
+}
+
+// SetCompactionContextPercent configures the share of the model context
+// window at which history is compacted before a request is sent. Values
+// outside 1-100 keep the default.
+func (s *Service) SetCompactionContextPercent(percent int) {
+	s.compactionPercent = percent
+}
+
 func (s *Service) SetToolApprovalService(service *toolapproval.Service) {
 	s.toolApproval = service
 }
@@ -308,9 +345,12 @@ type resolvedContext struct {
 	compactableTokens           int // raw history eligible for compaction
 	compactableTokensKnown      bool
 	contextTokenBudget          int // token budget used to clamp compaction triggers
+	trace                       *roundTrace
 }
 
-func (s *Service) resolve(ctx context.Context, req ChatRequest) (resolvedContext, error) {
+func (s *Service) resolve(ctx context.Context, req ChatRequest) (_ resolvedContext, retErr error) {
+	ctx, span := startChatSpan(ctx, "chat.resolve", req)
+	defer func() { telemetry.End(span, retErr) }()
 	modelQuery := modelQueryText(req)
 	if strings.TrimSpace(modelQuery) == "" && len(req.Attachments) == 0 {
 		return resolvedContext{}, errors.New("query or attachments is required")
@@ -354,6 +394,11 @@ func (s *Service) resolve(ctx context.Context, req ChatRequest) (resolvedContext
 		pruned, _ := pruneMessageForGateway(*memoryMsg)
 		memoryMsg = &pruned
 	}
+	knowledgeMsg := s.loadKnowledgeContextMessage(ctx, req)
+	if knowledgeMsg != nil {
+		pruned, _ := pruneMessageForGateway(*knowledgeMsg)
+		knowledgeMsg = &pruned
+	}
 
 	// When the DCP pipeline has data for this session, build context from
 	// the rendered event stream (RC) + bot turn responses (TR) instead of
@@ -397,14 +442,12 @@ func (s *Service) resolve(ctx context.Context, req ChatRequest) (resolvedContext
 		estimatedTokens = prepared.estimatedTokens
 		compactableTokens = prepared.compactableTokens
 		compactableTokensKnown = true
-		// When context reaches the shared budget share, run synchronous
-		// compaction before sending the request. contextTokenBudget is the
+		// When the estimated context reaches the configured budget share, run
+		// synchronous compaction before sending the request instead of
+		// waiting for the provider to report usage. contextTokenBudget is the
 		// authoritative limit for how much context the user wants to send
 		// to the LLM.
-		compactionThreshold := 0
-		if contextTokenBudget > 0 {
-			compactionThreshold = contextTokenBudget * compactionBudgetThresholdPercent / 100
-		}
+		compactionThreshold := compactionBudgetThreshold(contextTokenBudget, s.compactionContextPercent())
 		// The trigger only counts raw (compactable) rows: active summaries can
 		// never be compacted away, so including them would make the trigger
 		// self-sustaining once accumulated summaries cross the threshold.
@@ -447,6 +490,9 @@ func (s *Service) resolve(ctx context.Context, req ChatRequest) (resolvedContext
 	if memoryMsg != nil {
 		messages = append(messages, *memoryMsg)
 	}
+	if knowledgeMsg != nil {
+		messages = append(messages, *knowledgeMsg)
+	}
 	if requestedSkillMsg := buildRequestedSkillContextMessage(req.RequestedSkills); requestedSkillMsg != nil {
 		messages = append(messages, *requestedSkillMsg)
 	}
@@ -551,7 +597,9 @@ func (s *Service) resolve(ctx context.Context, req ChatRequest) (resolvedContext
 }
 
 // Chat sends a synchronous chat request and stores the result.
-func (s *Service) Chat(ctx context.Context, req ChatRequest) (ChatResponse, error) {
+func (s *Service) Chat(ctx context.Context, req ChatRequest) (_ ChatResponse, retErr error) {
+	ctx, span := startChatSpan(ctx, "chat", req)
+	defer func() { telemetry.End(span, retErr) }()
 	if err := rejectReservedSkillMetadataIfPresent(req); err != nil {
 		return ChatResponse{}, err
 	}
@@ -652,7 +700,7 @@ func (s *Service) buildBaseRunConfig(ctx context.Context, p baseRunConfigParams)
 		return native.RunConfig{}, models.GetResponse{}, sqlc.Provider{}, err
 	}
 	botInfo, loopDetectionEnabled := s.loadBotRuntimeInfo(ctx, p.BotID)
-	userTimezoneName, userClockLocation := s.resolveTimezone(ctx, p.BotID, p.UserID)
+	userTimezoneName, userClockLocation := s.resolveTimezone(ctx, p.BotID, p.ChannelIdentityID, p.UserID)
 
 	chatID := p.ChatID
 	if chatID == "" {
@@ -742,6 +790,7 @@ func (s *Service) buildBaseRunConfig(ctx context.Context, p baseRunConfigParams)
 			SessionToken:      p.SessionToken,
 		},
 		Bot:               botInfo,
+		PersonaSection:    s.resolvePersonaSection(ctx, botSettings.Persona, p.RouteID),
 		Skills:            agentSkills,
 		LoopDetection:     native.LoopDetectionConfig{Enabled: loopDetectionEnabled},
 		BackgroundManager: s.bgManager,
@@ -1263,6 +1312,7 @@ func (s *Service) prepareRunConfig(ctx context.Context, cfg native.RunConfig) na
 		Now:                       now,
 		Timezone:                  cfg.Identity.Timezone,
 		PlatformIdentitiesSection: platformIdentitiesSection,
+		PersonaSection:            cfg.PersonaSection,
 	})
 	if beforePromptContext != "" {
 		cfg.System += "\n\n" + formatServiceHookContext(hooks.EventBeforePromptBuild, beforePromptContext)
@@ -12,21 +12,39 @@ import (
 	"github.com/memohai/memoh/internal/settings"
 )
 
-// compactionBudgetThresholdPercent is the shared budget share at which
+// defaultCompactionBudgetPercent is the default shared budget share at which
 // compaction triggers: the pre-send synchronous backstop fires when
 // compactable history reaches it, and async triggers clamp the user
 // threshold to it so they fire before the blocking backstop does.
-const compactionBudgetThresholdPercent = 70
+const defaultCompactionBudgetPercent = 70
+
+// compactionContextPercent returns the configured budget share, falling back
+// to the default when unset or out of range.
+func (s *Service) compactionContextPercent() int {
+	if s == nil || s.compactionPercent <= 0 || s.compactionPercent > 100 {
+		return defaultCompactionBudgetPercent
+	}
+	return s.compactionPercent
+}
+
+// compactionBudgetThreshold is the token count at which the budget share is
+// reached. It is zero when the context window is unknown.
+func compactionBudgetThreshold(contextTokenBudget, percent int) int {
+	if contextTokenBudget <= 0 {
+		return 0
+	}
+	return contextTokenBudget * percent / 100
+}
 
 // effectiveCompactionThreshold clamps the user-configured absolute threshold
 // to the budget share, so an absolute default (e.g. 100000) still fires on
 // models whose context window never reaches it. A non-positive threshold
 // keeps async compaction disabled.
-func effectiveCompactionThreshold(threshold, contextTokenBudget int) int {
+func effectiveCompactionThreshold(threshold, contextTokenBudget, percent int) int {
 	if threshold <= 0 || contextTokenBudget <= 0 {
 		return threshold
 	}
-	budgetThreshold := contextTokenBudget * compactionBudgetThresholdPercent / 100
+	budgetThreshold := compactionBudgetThreshold(contextTokenBudget, percent)
 	if budgetThreshold > 0 && budgetThreshold < threshold {
 		return budgetThreshold
 	}
@@ -60,7 +78,7 @@ func (s *Service) maybeCompact(ctx context.Context, req ChatRequest, rc resolved
 		)
 		return
 	}
-	threshold := effectiveCompactionThreshold(botSettings.CompactionThreshold, rc.contextTokenBudget)
+	threshold := effectiveCompactionThreshold(botSettings.CompactionThreshold, rc.contextTokenBudget, s.compactionContextPercent())
 	if !compaction.ShouldCompact(inputTokens, threshold) {
 		s.logger.Info("compaction: skipped, below threshold",
 			slog.Int("input_tokens", inputTokens),
@@ -93,8 +111,9 @@ func (s *Service) maybeCompact(ctx context.Context, req ChatRequest, rc resolved
 	}
 }
 
-// runCompactionSync runs compaction synchronously when context reaches
-// 70% of the model's context window and reports the session-scoped result.
+// runCompactionSync runs compaction synchronously when context reaches the
+// configured share of the model's context window and reports the
+// session-scoped result.
 // A noop (failure cooldown, another compaction in flight, or nothing to
 // compact) leaves this turn's context untouched: the request proceeds as-is,
 // possibly still above the threshold, and the next turn re-evaluates.
@@ -98,23 +98,51 @@ func TestEffectiveCompactionThreshold(t *testing.T) {
 		name      string
 		threshold int
 		budget    int
+		percent   int
 		want      int
 	}{
-		{name: "clamps to budget share when user threshold exceeds it", threshold: 100000, budget: 10000, want: 7000},
-		{name: "keeps lower user threshold", threshold: 5000, budget: 200000, want: 5000},
-		{name: "keeps threshold when budget unknown", threshold: 100000, budget: 0, want: 100000},
-		{name: "zero threshold stays disabled", threshold: 0, budget: 200000, want: 0},
+		{name: "clamps to budget share when user threshold exceeds it", threshold: 100000, budget: 10000, percent: 70, want: 7000},
+		{name: "clamps to configured budget share", threshold: 100000, budget: 10000, percent: 80, want: 8000},
+		{name: "keeps lower user threshold", threshold: 5000, budget: 200000, percent: 70, want: 5000},
+		{name: "keeps threshold when budget unknown", threshold: 100000, budget: 0, percent: 70, want: 100000},
+		{name: "zero threshold stays disabled", threshold: 0, budget: 200000, percent: 70, want: 0},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			if got := effectiveCompactionThreshold(tc.threshold, tc.budget); got != tc.want {
-				t.Fatalf("effectiveCompactionThreshold(%d, %d) = %d, want %d", tc.threshold, tc.budget, got, tc.want)
+			if got := effectiveCompactionThreshold(tc.threshold, tc.budget, tc.percent); got != tc.want {
+				t.Fatalf("effectiveCompactionThreshold(%d, %d, %d) = %d, want %d", tc.threshold, tc.budget, tc.percent, got, tc.want)
 			}
 		})
 	}
 }
 
+func TestCompactionContextPercent(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		configured int
+		want       int
+	}{
+		{configured: 0, want: defaultCompactionBudgetPercent},
+		{configured: 80, want: 80},
+		{configured: 150, want: defaultCompactionBudgetPercent},
+	}
+	for _, tc := range cases {
+		svc := &Service{}
+		svc.SetCompactionContextPercent(tc.configured)
+		if got := svc.compactionContextPercent(); got != tc.want {
+			t.Fatalf("compactionContextPercent() with %d = %d, want %d", tc.configured, got, tc.want)
+		}
+	}
+	if got := compactionBudgetThreshold(200000, 80); got != 160000 {
+		t.Fatalf("compactionBudgetThreshold(200000, 80) = %d, want 160000", got)
+	}
+	if got := compactionBudgetThreshold(0, 80); got != 0 {
+		t.Fatalf("compactionBudgetThreshold(0, 80) = %d, want 0", got)
+	}
+}
+
 func TestAsyncCompactionInputTokensPrefersKnownCompactableHistory(t *testing.T) {
 	t.Parallel()
 
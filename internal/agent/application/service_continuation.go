@@ -20,6 +20,7 @@ func (s *Service) prepareContinuationRunConfig(
 		return native.RunConfig{}, err
 	}
 	loaded = pruneHistoryForGateway(loaded)
+	loaded = collapseToolHistory(loaded, s.toolHistory)
 	loaded, err = s.replaceCompactedMessages(ctx, summaryScope.SessionID, summaryScope, loaded, compactionArtifactBoundary{})
 	if err != nil {
 		return native.RunConfig{}, err
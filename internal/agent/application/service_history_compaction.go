@@ -45,7 +45,7 @@ func historyContextFragsForMessages(messages []ModelMessage, records []historyfr
 }
 
 func looksLikeSummaryMessage(msg ModelMessage) bool {
-	return strings.EqualFold(strings.TrimSpace(msg.Role), "user") &&
+	return strings.EqualFold(strings.TrimSpace(msg.Role), "system") &&
 		strings.HasPrefix(strings.TrimSpace(msg.TextContent()), "<summary>")
 }
 
@@ -76,7 +76,7 @@ func TestHistoryRecordPathPreservesLegacyServiceMessagePipeline(t *testing.T) {
 	got, tokens := trimMessagesByTokens(nil, records, 0)
 
 	want := []ModelMessage{
-		{Role: "user", Content: newTextContent("<summary>\ncondensed\n</summary>")},
+		{Role: "system", Content: newTextContent("<summary>\ncondensed\n</summary>")},
 		{Role: "user", Content: newTextContent("missing summary body")},
 		{Role: "user", Content: newTextContent("plain string content")},
 		assistantToolCall,
@@ -91,7 +91,7 @@ func TestHistoryRecordPathPreservesLegacyServiceMessagePipeline(t *testing.T) {
 
 	repaired := repairToolCallClosures(sanitizeMessages(got), syntheticToolClosureError)
 	assertSameJSON(t, modelMessagesToSDKMessages(nonNilModelMessages(repaired)), []sdk.Message{
-		sdk.UserMessage("<summary>\ncondensed\n</summary>"),
+		sdk.SystemMessage("<summary>\ncondensed\n</summary>"),
 		sdk.UserMessage("missing summary body"),
 		sdk.UserMessage("plain string content"),
 		assistantToolCallSDK,
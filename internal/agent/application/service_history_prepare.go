@@ -31,6 +31,7 @@ func (s *Service) prepareHistoryContext(
 	if err != nil {
 		return preparedHistoryContext{}, err
 	}
+	loaded = collapseToolHistory(loaded, s.toolHistory)
 	loaded, err = s.replaceCompactedMessages(
 		ctx,
 		req.ThreadID,
@@ -70,7 +70,7 @@ func TestReplaceCompactedHistoryRecordsUsesTypedSummaryRecord(t *testing.T) {
 
 	got := replaceCompactedHistoryRecords(records, map[string]string{"compact-1": "condensed"}, contextfrag.Scope{})
 	wantMessages := []ModelMessage{
-		{Role: "user", Content: newTextContent("<summary>\ncondensed\n</summary>")},
+		{Role: "system", Content: newTextContent("<summary>\ncondensed\n</summary>")},
 		{Role: "user", Content: newTextContent("new")},
 	}
 	if gotMessages := historyfrag.ToModelMessages(got); !reflect.DeepEqual(gotMessages, wantMessages) {
@@ -189,7 +189,7 @@ func TestReplaceCompactedHistoryRecordsKeepsMustKeepIslandOrdering(t *testing.T)
 
 	got := replaceCompactedHistoryRecords(records, map[string]string{"compact-1": "condensed"}, contextfrag.Scope{})
 	want := []ModelMessage{
-		{Role: "user", Content: newTextContent("<summary>\ncondensed\n</summary>")},
+		{Role: "system", Content: newTextContent("<summary>\ncondensed\n</summary>")},
 		{Role: "assistant", Content: newTextContent("ask you something")},
 		{Role: "tool", Content: newTextContent("answered")},
 		{Role: "user", Content: newTextContent("mid q")},
@@ -0,0 +1,52 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+// knowledgeSearchTimeout bounds chat-time collection retrieval. It is looser
+// than the memory timeout because vector collections embed the query first.
+const knowledgeSearchTimeout = 3 * time.Second
+
+type knowledgeRetriever interface {
+	Retrieve(ctx context.Context, botID, query string, limit int) ([]knowledge.Passage, error)
+}
+
+// SetKnowledgeService sets the retriever for knowledge collections attached
+// to bots. Collections are searched independently of the memory provider.
+func (s *Service) SetKnowledgeService(retriever knowledgeRetriever) {
+	s.knowledge = retriever
+}
+
+// loadKnowledgeContextMessage returns passages from the bot's attached
+// knowledge collections, or nil when none match. Retrieval failures only
+// drop the context; they never fail the turn.
+func (s *Service) loadKnowledgeContextMessage(ctx context.Context, req ChatRequest) *ModelMessage {
+	if s.knowledge == nil {
+		return nil
+	}
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil
+	}
+	searchCtx, cancel := context.WithTimeout(ctx, knowledgeSearchTimeout)
+	defer cancel()
+	passages, err := s.knowledge.Retrieve(searchCtx, req.BotID, query, knowledge.DefaultRetrieveLimit)
+	if err != nil {
+		s.logger.Warn("knowledge retrieval failed", slog.String("bot_id", req.BotID), slog.Any("error", err))
+		return nil
+	}
+	contextText := knowledge.FormatContext(passages)
+	if contextText == "" {
+		return nil
+	}
+	return &ModelMessage{
+		Role:    "user",
+		Content: newTextContent(contextText),
+	}
+}
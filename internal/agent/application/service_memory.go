@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/memohai/memoh/internal/featureflags"
 	"github.com/memohai/memoh/internal/hooks"
 	memprovider "github.com/memohai/memoh/internal/memory/adapters"
 )
@@ -42,6 +43,8 @@ func (s *Service) resolveMemoryProviderWithID(ctx context.Context, botID string)
 }
 
 func (s *Service) loadMemoryContextMessage(ctx context.Context, req ChatRequest) *ModelMessage {
+	ctx, span := startChatSpan(ctx, "memory.search", req)
+	defer span.End()
 	builtQuery := s.buildMemoryQuery(ctx, req)
 	if strings.TrimSpace(builtQuery.Query) == "" {
 		return nil
@@ -80,9 +83,12 @@ func (s *Service) loadMemoryContextMessage(ctx context.Context, req ChatRequest)
 
 	searchCtx, cancel := context.WithTimeout(ctx, s.effectiveMemorySearchTimeout())
 	result, err := p.OnBeforeChat(searchCtx, memprovider.BeforeChatRequest{
-		Query:  builtQuery.Query,
-		BotID:  req.BotID,
-		ChatID: req.ChatID,
+		Query:             builtQuery.Query,
+		BotID:             req.BotID,
+		ChatID:            req.ChatID,
+		UserID:            strings.TrimSpace(req.UserID),
+		ChannelIdentityID: strings.TrimSpace(req.SourceChannelIdentityID),
+		Fusion:            s.memorySearchFusion(ctx, req.BotID),
 	})
 	cancel()
 
@@ -192,14 +198,26 @@ func (s *Service) getMemoryContextCache() *memprovider.MemoryContextCache {
 	return s.memoryContextCache
 }
 
+// memorySearchFusion returns the classic recall fusion when hybrid search is
+// switched off for the bot, and nil to keep the provider's configuration.
+func (s *Service) memorySearchFusion(ctx context.Context, botID string) *memprovider.SearchFusion {
+	if s.featureFlags == nil || s.featureFlags.Enabled(ctx, featureflags.HybridSearch, botID) {
+		return nil
+	}
+	return memprovider.ClassicSearchFusion()
+}
+
 func (*Service) memoryContextCacheKey(ctx context.Context, req ChatRequest, providerID string, p memprovider.Provider, query string) memprovider.MemoryContextCacheKey {
 	memoryVersion := ""
 	if versioned, ok := p.(memprovider.MemoryVersionProvider); ok {
 		memoryVersion = versioned.MemoryVersion(ctx, req.BotID)
 	}
+	// Context may be scoped per user, so users sharing a chat never share
+	// a cached payload.
 	return memprovider.MemoryContextCacheKey{
 		BotID:         strings.TrimSpace(req.BotID),
 		ChatID:        strings.TrimSpace(req.ChatID),
+		UserID:        memprovider.MemoryScopeUserID(req.UserID, req.SourceChannelIdentityID),
 		ProviderID:    strings.TrimSpace(providerID),
 		QueryHash:     memprovider.MemoryContextQueryHash(query),
 		MemoryVersion: strings.TrimSpace(memoryVersion),
@@ -213,19 +231,24 @@ func (s *Service) effectiveMemorySearchTimeout() time.Duration {
 	return s.memorySearchTimeout
 }
 
-func (s *Service) storeMemory(ctx context.Context, req ChatRequest, messages []ModelMessage) {
+// storeMemory feeds the round to the bot's memory provider. It reports
+// whether it is done with the round: only a failed provider write leaves it
+// owed. Rounds without memory or content, and ones a hook denied, are done.
+func (s *Service) storeMemory(ctx context.Context, req ChatRequest, messages []ModelMessage) bool {
+	ctx, span := startChatSpan(ctx, "memory.store", req)
+	defer span.End()
 	botID := strings.TrimSpace(req.BotID)
 	if botID == "" {
-		return
+		return true
 	}
 	memMsgs := toProviderMessages(messages)
 	if len(memMsgs) == 0 {
-		return
+		return true
 	}
 
 	p := s.resolveMemoryProvider(ctx, botID)
 	if p == nil {
-		return
+		return true
 	}
 	before, err := s.runChatHook(ctx, req, hooks.EventBeforeMemoryWrite, func(hreq *hooks.Request) {
 		hreq.Memory = map[string]any{
@@ -236,10 +259,10 @@ func (s *Service) storeMemory(ctx context.Context, req ChatRequest, messages []M
 	if err != nil {
 		s.logHookWarn(hooks.EventBeforeMemoryWrite, botID, req.ThreadID, err)
 		if before.Decision == hooks.DecisionDeny {
-			return
+			return true
 		}
 	}
-	_, tzLoc := s.resolveTimezone(ctx, req.BotID, req.UserID)
+	_, tzLoc := s.resolveTimezone(ctx, req.BotID, req.SourceChannelIdentityID, req.UserID)
 	if err := p.OnAfterChat(ctx, memprovider.AfterChatRequest{
 		BotID:             botID,
 		Messages:          memMsgs,
@@ -249,7 +272,7 @@ func (s *Service) storeMemory(ctx context.Context, req ChatRequest, messages []M
 		TimezoneLocation:  tzLoc,
 	}); err != nil {
 		s.logger.Warn("memory provider OnAfterChat failed", slog.String("bot_id", botID), slog.Any("error", err))
-		return
+		return false
 	}
 	_, _ = s.runChatHook(ctx, req, hooks.EventMemoryExtracted, func(hreq *hooks.Request) {
 		hreq.Memory = map[string]any{
@@ -265,6 +288,7 @@ func (s *Service) storeMemory(ctx context.Context, req ChatRequest, messages []M
 	}); err != nil {
 		s.logHookWarn(hooks.EventAfterMemoryWrite, botID, req.ThreadID, err)
 	}
+	return true
 }
 
 func toProviderMessages(messages []ModelMessage) []memprovider.Message {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/memohai/memoh/internal/agent/runtime/native"
 	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/persona"
 	"github.com/memohai/memoh/internal/settings"
 )
 
@@ -46,6 +47,29 @@ func (s *Service) loadBotRuntimeInfo(ctx context.Context, botID string) (native.
 	return info, parseLoopDetectionEnabledFromMetadata(row.Metadata)
 }
 
+// resolvePersonaSection renders the bot's persona, with the route's override
+// layered on top, as a system prompt section.
+func (s *Service) resolvePersonaSection(ctx context.Context, botPersona persona.Persona, routeID string) string {
+	resolved := botPersona
+	if routeID = strings.TrimSpace(routeID); routeID != "" && s.queries != nil {
+		if routeUUID, err := db.ParseUUID(routeID); err == nil {
+			row, err := s.queries.GetChatRouteByID(ctx, routeUUID)
+			if err != nil {
+				s.logger.Debug("failed to load route persona override",
+					slog.String("route_id", routeID),
+					slog.Any("error", err),
+				)
+			} else {
+				var metadata map[string]any
+				if json.Unmarshal(row.Metadata, &metadata) == nil {
+					resolved = persona.Override(resolved, persona.FromMetadata(metadata))
+				}
+			}
+		}
+	}
+	return persona.Fragment(resolved)
+}
+
 func parseLoopDetectionEnabledFromMetadata(payload []byte) bool {
 	if len(payload) == 0 {
 		return false
@@ -2,7 +2,10 @@ package application
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"strings"
 
@@ -21,6 +24,9 @@ type storeRoundOptions struct {
 	SkipMemory              bool
 	AllowEmptyAssistantText bool
 	MessageMetadataByIndex  map[int]map[string]any
+	// LastAssistantMetadata is merged into the metadata of the round's last
+	// assistant message, which carries round-level data such as its trace.
+	LastAssistantMetadata map[string]any
 }
 
 func (s *Service) storeRoundWithOptions(ctx context.Context, req ChatRequest, messages []ModelMessage, modelID string, opts storeRoundOptions) error {
@@ -65,9 +71,12 @@ func (s *Service) storeRoundWithOptionsResult(ctx context.Context, req ChatReque
 		return nil, nil
 	}
 
-	persisted := s.storeMessages(ctx, req, filtered, modelID, opts)
-	if !opts.SkipMemory && !req.SkipMemoryExtraction {
-		go s.storeMemory(context.WithoutCancel(ctx), req, filtered)
+	persisted, write, roundKey := s.storeMessages(ctx, req, filtered, modelID, opts)
+	// Memory extraction follows the history write: a replayed round already
+	// fed memory on its first attempt unless its memory ref is still
+	// pending, and a failed round never happened.
+	if (write == roundWritten || write == roundReplayedMemoryPending) && !opts.SkipMemory && !req.SkipMemoryExtraction {
+		go s.storeRoundMemory(context.WithoutCancel(ctx), req, filtered, roundKey)
 	}
 
 	return persisted, nil
@@ -109,12 +118,31 @@ func (s *Service) StoreRound(ctx context.Context, botID, sessionID, channelIdent
 	return s.storeRound(ctx, req, modelMessages, modelID)
 }
 
-func (s *Service) storeMessages(ctx context.Context, req ChatRequest, messages []ModelMessage, modelID string, opts storeRoundOptions) []messagepkg.Message {
+// roundWrite reports how storeMessages settled a round.
+type roundWrite int
+
+const (
+	// roundWritten means the round was persisted by this call, or there was
+	// nothing to persist it into.
+	roundWritten roundWrite = iota
+	// roundReplayed means an earlier attempt with the same idempotency key
+	// already committed the round.
+	roundReplayed
+	// roundReplayedMemoryPending is roundReplayed for a round whose memory
+	// extraction never completed, now claimed by this call.
+	roundReplayedMemoryPending
+	// roundFailed means the atomic round write was rolled back.
+	roundFailed
+)
+
+// storeMessages persists the round and reports how it settled together with
+// its idempotency key, which is empty when the round has none.
+func (s *Service) storeMessages(ctx context.Context, req ChatRequest, messages []ModelMessage, modelID string, opts storeRoundOptions) ([]messagepkg.Message, roundWrite, string) {
 	if s.messageService == nil {
-		return nil
+		return nil, roundWritten, ""
 	}
 	if strings.TrimSpace(req.BotID) == "" {
-		return nil
+		return nil, roundWritten, ""
 	}
 
 	// Check bot setting for full tool result persistence.
@@ -127,18 +155,17 @@ func (s *Service) storeMessages(ctx context.Context, req ChatRequest, messages [
 	senderChannelIdentityID, senderUserID := s.resolvePersistSenderIDs(ctx, req)
 	sessionMode, runtimeType := s.persistSessionRuntimeSnapshot(ctx, req)
 
-	// Determine the last assistant message index for outbound asset attachment.
+	// Determine the last assistant message index for outbound asset
+	// attachment and round-level metadata.
 	lastAssistantIdx := -1
-	if req.OutboundAssetCollector != nil {
-		for i := len(messages) - 1; i >= 0; i-- {
-			if messages[i].Role == "assistant" {
-				lastAssistantIdx = i
-				break
-			}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "assistant" {
+			lastAssistantIdx = i
+			break
 		}
 	}
 	var outboundAssets []messagepkg.AssetRef
-	if lastAssistantIdx >= 0 {
+	if lastAssistantIdx >= 0 && req.OutboundAssetCollector != nil {
 		outboundAssets = outboundAssetRefsToMessageRefs(req.OutboundAssetCollector())
 	}
 
@@ -226,6 +253,9 @@ func (s *Service) storeMessages(ctx context.Context, req ChatRequest, messages [
 		if extraMeta := opts.MessageMetadataByIndex[i]; len(extraMeta) > 0 {
 			persistMeta = mergeMetadata(persistMeta, extraMeta)
 		}
+		if i == lastAssistantIdx && len(opts.LastAssistantMetadata) > 0 {
+			persistMeta = mergeMetadata(persistMeta, opts.LastAssistantMetadata)
+		}
 		persistInputs = append(persistInputs, messagepkg.PersistInput{
 			BotID:                   req.BotID,
 			SessionID:               req.ThreadID,
@@ -247,16 +277,97 @@ func (s *Service) storeMessages(ctx context.Context, req ChatRequest, messages [
 			SkipHistoryTurn:         req.SkipHistoryTurn,
 		})
 	}
+	if atomic, ok := s.messageService.(messagepkg.AtomicRoundPersister); ok && strings.TrimSpace(req.ThreadID) != "" {
+		roundKey := roundIdempotencyKey(req, persistInputs)
+		persisted, handled, err := atomic.PersistRound(ctx, persistInputs, messagepkg.RoundPersistenceOptions{
+			IdempotencyKey: roundKey,
+			Atomic:         true,
+			MemoryPending:  roundKey != "" && !opts.SkipMemory && !req.SkipMemoryExtraction,
+		})
+		if handled {
+			switch {
+			case errors.Is(err, messagepkg.ErrRoundMemoryPending):
+				s.logger.Info("round already persisted with memory pending, skipping duplicate write",
+					slog.String("bot_id", req.BotID),
+					slog.String("session_id", req.ThreadID),
+				)
+				return persisted, roundReplayedMemoryPending, roundKey
+			case errors.Is(err, messagepkg.ErrRoundAlreadyPersisted):
+				s.logger.Info("round already persisted, skipping duplicate write",
+					slog.String("bot_id", req.BotID),
+					slog.String("session_id", req.ThreadID),
+				)
+				return persisted, roundReplayed, roundKey
+			case err != nil:
+				s.logger.Warn("persist round failed", slog.Any("error", err))
+				return nil, roundFailed, roundKey
+			}
+			return persisted, roundWritten, roundKey
+		}
+	}
 	if batcher, ok := s.messageService.(messagepkg.ToolTailRoundPersister); ok {
 		if persisted, handled, err := batcher.PersistToolTailRound(ctx, persistInputs); handled || err != nil {
 			if err != nil {
 				s.logger.Warn("persist tool tail round failed", slog.Any("error", err))
-				return nil
+				return nil, roundFailed, ""
 			}
-			return persisted
+			return persisted, roundWritten, ""
 		}
 	}
-	return s.persistMessageInputs(ctx, persistInputs, turnRequestMessageID)
+	return s.persistMessageInputs(ctx, persistInputs, turnRequestMessageID), roundWritten, ""
+}
+
+// storeRoundMemory extracts the round's memories and then clears the round's
+// pending memory ref, so only a round whose extraction finished is skipped
+// when it is replayed. A failed extraction keeps the ref pending; a replay
+// takes it over once the claim made when writing or replaying the round
+// expires.
+func (s *Service) storeRoundMemory(ctx context.Context, req ChatRequest, messages []ModelMessage, roundKey string) {
+	if !s.storeMemory(ctx, req, messages) || roundKey == "" {
+		return
+	}
+	completer, ok := s.messageService.(messagepkg.RoundMemoryCompleter)
+	if !ok {
+		return
+	}
+	if err := completer.CompleteRoundMemory(ctx, req.ThreadID, roundKey); err != nil {
+		s.logger.Warn("complete round memory ref failed",
+			slog.String("bot_id", req.BotID),
+			slog.String("session_id", req.ThreadID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// roundIdempotencyKey derives the key that makes one round write idempotent
+// from stable inputs: the chat and session, the inbound message the turn
+// answers, and the serialized round. The inbound identity keeps separate
+// turns with identical text apart; the round content keeps apart the several
+// rounds one turn may persist (tool approval and user input continuations),
+// so a retried write of the same output collapses onto the original. Rounds
+// without an inbound message get no key: they persist atomically but cannot
+// be deduplicated.
+func roundIdempotencyKey(req ChatRequest, inputs []messagepkg.PersistInput) string {
+	inbound := []string{
+		strings.TrimSpace(req.PersistedUserMessageID),
+		strings.TrimSpace(req.ExternalMessageID),
+		strings.TrimSpace(req.EventID),
+	}
+	if strings.Join(inbound, "") == "" {
+		return ""
+	}
+	h := sha256.New()
+	for _, part := range append([]string{strings.TrimSpace(req.ChatID), strings.TrimSpace(req.ThreadID)}, inbound...) {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	for _, input := range inputs {
+		h.Write([]byte(input.Role))
+		h.Write([]byte{0})
+		h.Write(input.Content)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 func workspaceTargetMetadata(target *WorkspaceTarget) map[string]any {
@@ -163,7 +163,7 @@ func TestStoreMessagesUsesToolTailBatch(t *testing.T) {
 		logger:         slog.New(slog.DiscardHandler),
 	}
 
-	persisted := resolver.storeMessages(context.Background(), ChatRequest{
+	persisted, _, _ := resolver.storeMessages(context.Background(), ChatRequest{
 		BotID:       storeRoundBotID,
 		ThreadID:    "33333333-3333-3333-3333-333333333333",
 		Query:       "hello",
@@ -187,6 +187,135 @@ func TestStoreMessagesUsesToolTailBatch(t *testing.T) {
 	}
 }
 
+type atomicRecordingMessageService struct {
+	recordingMessageService
+	roundInputs  []messagepkg.PersistInput
+	roundKeys    []string
+	roundOptions []messagepkg.RoundPersistenceOptions
+	replay       bool
+}
+
+func (s *atomicRecordingMessageService) PersistRound(_ context.Context, inputs []messagepkg.PersistInput, options messagepkg.RoundPersistenceOptions) ([]messagepkg.Message, bool, error) {
+	s.roundInputs = append(s.roundInputs, inputs...)
+	s.roundKeys = append(s.roundKeys, options.IdempotencyKey)
+	s.roundOptions = append(s.roundOptions, options)
+	if s.replay {
+		return recordedMessages(inputs), true, messagepkg.ErrRoundAlreadyPersisted
+	}
+	return recordedMessages(inputs), true, nil
+}
+
+func TestStoreMessagesPersistsRoundAtomicallyWithStableKey(t *testing.T) {
+	t.Parallel()
+
+	messages := &atomicRecordingMessageService{}
+	resolver := &Service{
+		messageService: messages,
+		logger:         slog.New(slog.DiscardHandler),
+	}
+	req := ChatRequest{
+		BotID:             storeRoundBotID,
+		ThreadID:          "33333333-3333-3333-3333-333333333333",
+		Query:             "hello",
+		ExternalMessageID: "platform-message-1",
+		SessionType:       "chat",
+		RuntimeType:       "model",
+	}
+	round := []ModelMessage{
+		{Role: "user", Content: newTextContent("hello")},
+		{Role: "assistant", Content: newTextContent("hi")},
+	}
+
+	for range 2 {
+		persisted, write, _ := resolver.storeMessages(context.Background(), req, round, "", storeRoundOptions{})
+		if write != roundWritten || len(persisted) != 2 {
+			t.Fatalf("storeMessages() = %d messages, write %v; want 2 written", len(persisted), write)
+		}
+	}
+	if len(messages.persisted) != 0 {
+		t.Fatalf("fallback Persist called %d times, want 0", len(messages.persisted))
+	}
+	if len(messages.roundKeys) != 2 || messages.roundKeys[0] == "" || messages.roundKeys[0] != messages.roundKeys[1] {
+		t.Fatalf("round keys = %#v, want one stable non-empty key", messages.roundKeys)
+	}
+
+	if opts := messages.roundOptions[0]; !opts.Atomic || !opts.MemoryPending {
+		t.Fatalf("round options = %+v, want atomic with a pending memory ref", opts)
+	}
+
+	req.ExternalMessageID = "platform-message-2"
+	resolver.storeMessages(context.Background(), req, round, "", storeRoundOptions{})
+	if messages.roundKeys[2] == messages.roundKeys[0] {
+		t.Fatal("distinct turns with identical text share a round key")
+	}
+	req.ExternalMessageID = "platform-message-1"
+	req.ChatID = "other-chat"
+	resolver.storeMessages(context.Background(), req, round, "", storeRoundOptions{})
+	if messages.roundKeys[3] == messages.roundKeys[0] {
+		t.Fatal("the same inbound message id in another chat shares a round key")
+	}
+}
+
+func TestStoreMessagesWithoutInboundMessageHasNoRoundKey(t *testing.T) {
+	t.Parallel()
+
+	messages := &atomicRecordingMessageService{}
+	resolver := &Service{
+		messageService: messages,
+		logger:         slog.New(slog.DiscardHandler),
+	}
+	req := ChatRequest{
+		BotID:       storeRoundBotID,
+		ThreadID:    "33333333-3333-3333-3333-333333333333",
+		Query:       "hello",
+		SessionType: "chat",
+		RuntimeType: "model",
+	}
+	round := []ModelMessage{
+		{Role: "user", Content: newTextContent("hello")},
+		{Role: "assistant", Content: newTextContent("hi")},
+	}
+
+	for range 2 {
+		resolver.storeMessages(context.Background(), req, round, "", storeRoundOptions{})
+	}
+	if messages.roundKeys[0] != "" || messages.roundKeys[1] != "" {
+		t.Fatalf("round keys = %#v, want none without an inbound message", messages.roundKeys)
+	}
+	if opts := messages.roundOptions[0]; !opts.Atomic || opts.MemoryPending {
+		t.Fatalf("round options = %+v, want atomic without a memory ref", opts)
+	}
+}
+
+func TestStoreMessagesReportsReplayedRound(t *testing.T) {
+	t.Parallel()
+
+	messages := &atomicRecordingMessageService{replay: true}
+	resolver := &Service{
+		messageService: messages,
+		logger:         slog.New(slog.DiscardHandler),
+	}
+
+	persisted, write, _ := resolver.storeMessages(context.Background(), ChatRequest{
+		BotID:       storeRoundBotID,
+		ThreadID:    "33333333-3333-3333-3333-333333333333",
+		Query:       "hello",
+		EventID:     "44444444-4444-4444-4444-444444444444",
+		SessionType: "chat",
+		RuntimeType: "model",
+	}, []ModelMessage{
+		{Role: "user", Content: newTextContent("hello")},
+		{Role: "assistant", Content: newTextContent("hi")},
+	}, "", storeRoundOptions{})
+
+	if write != roundReplayed {
+		t.Fatalf("write = %v, want roundReplayed", write)
+	}
+	if len(persisted) != 2 {
+		t.Fatalf("persisted messages = %d, want the 2 original messages", len(persisted))
+	}
+}
+
 func TestFindAssistantMessageForToolCall(t *testing.T) {
 	t.Parallel()
 
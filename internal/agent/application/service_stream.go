@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	sdk "github.com/memohai/twilight-ai/sdk"
 
 	"github.com/memohai/memoh/internal/agent/runtime/native"
 	messagepkg "github.com/memohai/memoh/internal/chat/message"
+	"github.com/memohai/memoh/internal/telemetry"
 )
 
 // WSStreamEvent represents a raw JSON event forwarded from the agent.
@@ -81,7 +83,9 @@ func extractTerminalSnapshot(data []byte) (terminalSnapshot, bool) {
 func (s *Service) StreamChat(ctx context.Context, req ChatRequest) (<-chan StreamChunk, <-chan error) {
 	chunkCh := make(chan StreamChunk)
 	errCh := make(chan error, 1)
+	ctx, span := startChatSpan(ctx, "chat.stream", req)
 	go func() {
+		defer span.End()
 		defer close(chunkCh)
 		defer close(errCh)
 		streamReq := req
@@ -138,6 +142,7 @@ func (s *Service) StreamChat(ctx context.Context, req ChatRequest) (<-chan Strea
 				return
 			}
 		}
+		trace := newRoundTrace(time.Now)
 		rc, err := s.resolve(streamCtx, streamReq)
 		if err != nil {
 			s.logger.Error("agent stream resolve failed",
@@ -161,6 +166,8 @@ func (s *Service) StreamChat(ctx context.Context, req ChatRequest) (<-chan Strea
 		idleCtx, idleCancel := withIdleTimeout(streamCtx)
 		defer idleCancel.Stop()
 
+		rc.trace = trace
+		trace.resolved(rc)
 		eventCh := s.agent.Stream(idleCtx, cfg)
 		stored := false
 		clientGone := false
@@ -170,6 +177,7 @@ func (s *Service) StreamChat(ctx context.Context, req ChatRequest) (<-chan Strea
 		var hasVisibleOutput bool
 		for event := range eventCh {
 			idleCancel.Reset() // each event resets the idle timer
+			trace.observe(event)
 
 			// Track tool calls for adaptive idle timeout and progress events
 			if event.Type == native.EventToolCallStart {
@@ -293,7 +301,9 @@ func (s *Service) streamChatWSResultWithHooks(
 	abortCh <-chan struct{},
 	preflight func(context.Context) error,
 	postPersist func(context.Context, []messagepkg.Message) error,
-) ([]messagepkg.Message, error) {
+) (_ []messagepkg.Message, retErr error) {
+	ctx, span := startChatSpan(ctx, "chat.stream", req)
+	defer func() { telemetry.End(span, retErr) }()
 	if err := rejectReservedSkillMetadataIfPresent(req); err != nil {
 		return nil, err
 	}
@@ -342,6 +352,7 @@ func (s *Service) streamChatWSResultWithHooks(
 			return nil, err
 		}
 	}
+	trace := newRoundTrace(time.Now)
 	rc, err := s.resolve(ctx, req)
 	if err != nil {
 		s.logger.Error("StreamChatWS: resolve failed",
@@ -374,6 +385,8 @@ func (s *Service) streamChatWSResultWithHooks(
 	idleCtx, idleCancel := withIdleTimeout(streamCtx)
 	defer idleCancel.Stop()
 
+	rc.trace = trace
+	trace.resolved(rc)
 	agentEventCh := s.agent.Stream(idleCtx, cfg)
 	modelID := rc.model.ID
 	stored := false
@@ -386,6 +399,7 @@ func (s *Service) streamChatWSResultWithHooks(
 	postPersistApplied := false
 	for event := range agentEventCh {
 		idleCancel.Reset() // each event resets the idle timer
+		trace.observe(event)
 
 		// Track tool calls for adaptive idle timeout
 		if event.Type == native.EventToolCallStart {
@@ -526,6 +540,7 @@ func (s *Service) persistTerminalSnapshotResult(ctx context.Context, req ChatReq
 
 	persisted, err := s.storeRoundWithOptionsResult(ctx, storeReq, roundMessages, rc.model.ID, storeRoundOptions{
 		AllowPendingToolCalls: snap.deferredToolID != "",
+		LastAssistantMetadata: rc.trace.metadata(!req.SkipMemoryExtraction),
 	})
 	if err != nil {
 		return nil, err
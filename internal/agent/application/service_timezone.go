@@ -11,16 +11,21 @@ import (
 )
 
 // resolveTimezone resolves the effective timezone for a request.
-// Priority: bot timezone > user timezone > system default.
-func (s *Service) resolveTimezone(ctx context.Context, botID, userID string) (string, *time.Location) {
+// Priority: contact timezone > bot timezone > user timezone > system default.
+func (s *Service) resolveTimezone(ctx context.Context, botID, channelIdentityID, userID string) (string, *time.Location) {
 	fallbackName, fallbackLocation := s.systemTimezoneDefaults()
 
-	// 1. Try bot timezone first.
+	// 1. The sender's own timezone, so "tomorrow at 9" means their 9am.
+	if name, loc, ok := s.loadContactTimezone(ctx, botID, channelIdentityID); ok {
+		return name, loc
+	}
+
+	// 2. Bot timezone.
 	if name, loc, ok := s.loadBotTimezone(ctx, botID); ok {
 		return name, loc
 	}
 
-	// 2. Fall back to user timezone.
+	// 3. Fall back to user timezone.
 	if name, loc, ok := s.loadUserTimezone(ctx, userID); ok {
 		return name, loc
 	}
@@ -35,6 +40,29 @@ func (s *Service) systemTimezoneDefaults() (string, *time.Location) {
 	return timezone.DefaultName, timezone.MustResolve(timezone.DefaultName)
 }
 
+func (s *Service) loadContactTimezone(ctx context.Context, botID, channelIdentityID string) (string, *time.Location, bool) {
+	if s.contactTimezones == nil || strings.TrimSpace(botID) == "" || strings.TrimSpace(channelIdentityID) == "" {
+		return "", nil, false
+	}
+	tz, err := s.contactTimezones.ContactTimezone(ctx, botID, channelIdentityID)
+	if err != nil || strings.TrimSpace(tz) == "" {
+		return "", nil, false
+	}
+	loc, name, err := timezone.Resolve(tz)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warn("resolve contact timezone failed",
+				slog.String("bot_id", botID),
+				slog.String("channel_identity_id", channelIdentityID),
+				slog.String("timezone", tz),
+				slog.Any("error", err),
+			)
+		}
+		return "", nil, false
+	}
+	return name, loc, true
+}
+
 func (s *Service) loadBotTimezone(ctx context.Context, botID string) (string, *time.Location, bool) {
 	if s.queries == nil || strings.TrimSpace(botID) == "" {
 		return "", nil, false
@@ -0,0 +1,31 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeContactTimezones map[string]string
+
+func (f fakeContactTimezones) ContactTimezone(_ context.Context, botID, channelIdentityID string) (string, error) {
+	return f[botID+"|"+channelIdentityID], nil
+}
+
+func TestResolveTimezonePrefersContactTimezone(t *testing.T) {
+	t.Parallel()
+
+	svc := &Service{
+		clockLocation:    time.UTC,
+		contactTimezones: fakeContactTimezones{"bot-1|ci-1": "Asia/Tokyo", "bot-1|ci-2": "Not/AZone"},
+	}
+	if name, loc := svc.resolveTimezone(context.Background(), "bot-1", "ci-1", ""); name != "Asia/Tokyo" || loc.String() != "Asia/Tokyo" {
+		t.Fatalf("contact timezone = %q (%v)", name, loc)
+	}
+	// Unknown and invalid contact timezones fall through to the defaults.
+	for _, identityID := range []string{"ci-2", "ci-3", ""} {
+		if name, _ := svc.resolveTimezone(context.Background(), "bot-1", identityID, ""); name != "UTC" {
+			t.Fatalf("identity %q resolved %q, want UTC", identityID, name)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package application
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/memohai/memoh/internal/telemetry"
+)
+
+var tracer = telemetry.Tracer("agent/application")
+
+// startChatSpan starts a span named name for work done on behalf of req.
+func startChatSpan(ctx context.Context, name string, req ChatRequest) (context.Context, oteltrace.Span) {
+	return tracer.Start(ctx, name, oteltrace.WithAttributes(
+		attribute.String("memoh.bot_id", strings.TrimSpace(req.BotID)),
+		attribute.String("memoh.chat_id", strings.TrimSpace(req.ChatID)),
+		attribute.String("memoh.session_id", strings.TrimSpace(req.ThreadID)),
+	))
+}
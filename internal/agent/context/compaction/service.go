@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
 	"github.com/memohai/memoh/internal/db"
 	"github.com/memohai/memoh/internal/db/postgres/sqlc"
 	dbstore "github.com/memohai/memoh/internal/db/store"
@@ -43,6 +44,7 @@ type inflightRun struct {
 type Service struct {
 	queries     dbstore.Queries
 	hookService *hooks.Service
+	cipher      messagepkg.ContentCipher
 	logger      *slog.Logger
 	nowFn       func() time.Time
 
@@ -118,6 +120,11 @@ func (s *Service) SetHookService(h *hooks.Service) {
 	s.hookService = h
 }
 
+// SetContentCipher lets compaction read history that was sealed at rest.
+func (s *Service) SetContentCipher(cipher messagepkg.ContentCipher) {
+	s.cipher = cipher
+}
+
 // ShouldCompact returns true if inputTokens exceeds the threshold.
 func ShouldCompact(inputTokens, threshold int) bool {
 	return threshold > 0 && inputTokens >= threshold
@@ -23,6 +23,7 @@ func (s *Service) doCompaction(ctx context.Context, botUUID pgtype.UUID, session
 	if len(rows) == 0 {
 		return Result{Status: StatusNoop}, nil
 	}
+	s.openRows(ctx, rows)
 
 	messages, barrierCount := itemsFromRows(rows)
 	if barrierCount > 0 {
@@ -259,3 +260,26 @@ func (s *Service) completeLog(ctx context.Context, logID pgtype.UUID, status, su
 	}
 	return nil
 }
+
+// openRows replaces sealed content with plaintext in place so candidates are
+// built from what the model originally saw. Rows that fail to open stay
+// sealed and surface as unparseable barriers.
+func (s *Service) openRows(ctx context.Context, rows []sqlc.ListUncompactedMessagesBySessionRow) {
+	if s.cipher == nil {
+		return
+	}
+	for i := range rows {
+		content, err := s.cipher.OpenJSON(ctx, rows[i].BotID, rows[i].Content)
+		if err != nil {
+			s.logger.Warn("compaction: open message content failed", slog.String("message_id", rows[i].ID.String()), slog.Any("error", err))
+			continue
+		}
+		rows[i].Content = content
+		if rows[i].DisplayText.Valid {
+			text, err := s.cipher.OpenText(ctx, rows[i].BotID, rows[i].DisplayText.String)
+			if err == nil {
+				rows[i].DisplayText.String = text
+			}
+		}
+	}
+}
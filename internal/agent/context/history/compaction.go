@@ -30,7 +30,7 @@ func summaryRecordBase(compactID string, summary string, scope contextfrag.Scope
 		},
 		SourceKind: SourceCompactionLog,
 		ModelMessage: turn.ModelMessage{
-			Role:    "user",
+			Role:    "system",
 			Content: turn.NewTextContent("<summary>\n" + summary + "\n</summary>"),
 		},
 		Scope: scope,
@@ -0,0 +1,100 @@
+package event
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Wire schema versions for stream events that cross a process boundary.
+//
+// Version 1 is the flat StreamEvent object, which is what runtimes produce
+// and what the channel layer consumes. Version 2 wraps that object in an
+// Envelope carrying an explicit version and type so either side can detect
+// and skip frames it does not understand instead of misreading them.
+const (
+	SchemaVersionLegacy  = 1
+	SchemaVersionV2      = 2
+	CurrentSchemaVersion = SchemaVersionV2
+)
+
+// ErrUnsupportedSchemaVersion is returned when an envelope declares a
+// schema version this binary cannot decode.
+var ErrUnsupportedSchemaVersion = errors.New("unsupported stream event schema version")
+
+// JSONSchemaV2 is the JSON Schema document describing the version 2
+// envelope. It is the reference for non-Go consumers of the stream.
+//
+//go:embed schema/stream_event.v2.schema.json
+var JSONSchemaV2 []byte
+
+// Envelope is the version 2 wire form of a StreamEvent. Event holds the
+// version 1 object unchanged so the legacy shape stays recoverable without
+// re-encoding.
+type Envelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	Type          StreamEventType `json:"type"`
+	Event         json.RawMessage `json:"event"`
+}
+
+// KnownTypes lists every StreamEventType defined by this schema version.
+var KnownTypes = []StreamEventType{
+	AgentStart, TextStart, TextDelta, TextEnd,
+	ReasoningStart, ReasoningDelta, ReasoningEnd,
+	ToolCallInputStart, ToolCallStart, ToolCallMetadata, ToolCallProgress, ToolCallEnd,
+	ToolApprovalRequest, UserInputRequest,
+	Attachment, Reaction, Speech,
+	AgentEnd, AgentAbort, Retry, Progress, Error,
+}
+
+// Known reports whether t is defined by the current schema. Consumers
+// should skip unknown types rather than fail the stream.
+func (t StreamEventType) Known() bool {
+	for _, known := range KnownTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeEnvelope wraps a version 1 payload into a version 2 envelope.
+func EncodeEnvelope(payload json.RawMessage) (json.RawMessage, error) {
+	var head struct {
+		Type StreamEventType `json:"type"`
+	}
+	if err := json.Unmarshal(payload, &head); err != nil {
+		return nil, fmt.Errorf("decode stream event type: %w", err)
+	}
+	return json.Marshal(Envelope{
+		SchemaVersion: SchemaVersionV2,
+		Type:          head.Type,
+		Event:         payload,
+	})
+}
+
+// DecodeEnvelope parses a version 2 envelope. Envelopes from a newer
+// schema are rejected with ErrUnsupportedSchemaVersion.
+func DecodeEnvelope(data []byte) (Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return Envelope{}, fmt.Errorf("decode stream event envelope: %w", err)
+	}
+	if env.SchemaVersion != SchemaVersionV2 {
+		return Envelope{}, fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, env.SchemaVersion)
+	}
+	if len(env.Event) == 0 {
+		return Envelope{}, errors.New("stream event envelope has no event")
+	}
+	return env, nil
+}
+
+// Decode returns the typed StreamEvent carried by the envelope.
+func (e Envelope) Decode() (StreamEvent, error) {
+	var out StreamEvent
+	if err := json.Unmarshal(e.Event, &out); err != nil {
+		return StreamEvent{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,56 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestJSONSchemaV2ListsKnownTypes(t *testing.T) {
+	var doc struct {
+		Defs struct {
+			EventType struct {
+				Enum []StreamEventType `json:"enum"`
+			} `json:"eventType"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(JSONSchemaV2, &doc); err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	if !slices.Equal(doc.Defs.EventType.Enum, KnownTypes) {
+		t.Fatalf("schema enum = %v, want %v", doc.Defs.EventType.Enum, KnownTypes)
+	}
+}
+
+func TestEnvelopeRoundTripKeepsLegacyPayload(t *testing.T) {
+	legacy := json.RawMessage(`{"type":"tool_call_start","toolName":"read","toolCallId":"call-1"}`)
+	data, err := EncodeEnvelope(legacy)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	env, err := DecodeEnvelope(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if env.Type != ToolCallStart || string(env.Event) != string(legacy) {
+		t.Fatalf("envelope = %#v", env)
+	}
+	evt, err := env.Decode()
+	if err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if evt.ToolName != "read" || evt.ToolCallID != "call-1" {
+		t.Fatalf("event = %#v", evt)
+	}
+}
+
+func TestDecodeEnvelopeRejectsNewerSchema(t *testing.T) {
+	_, err := DecodeEnvelope([]byte(`{"schema_version":3,"type":"text_delta","event":{"type":"text_delta"}}`))
+	if !errors.Is(err, ErrUnsupportedSchemaVersion) {
+		t.Fatalf("err = %v, want ErrUnsupportedSchemaVersion", err)
+	}
+	if StreamEventType("future_event").Known() {
+		t.Fatal("unknown type reported as known")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/memohai/memoh/internal/agent/background"
 	userinput "github.com/memohai/memoh/internal/agent/decision/input"
 	tools "github.com/memohai/memoh/internal/agent/tool"
+	"github.com/memohai/memoh/internal/extension"
 	"github.com/memohai/memoh/internal/hooks"
 	"github.com/memohai/memoh/internal/models"
 	"github.com/memohai/memoh/internal/workspace/bridge"
@@ -26,6 +27,7 @@ type Agent struct {
 	toolProviders  []tools.ToolProvider
 	bridgeProvider bridge.Provider
 	hookService    *hooks.Service
+	extensions     *extension.Chain
 	logger         *slog.Logger
 	limits         Limits
 }
@@ -40,6 +42,7 @@ func New(deps Deps) *Agent {
 		client:         sdk.NewClient(),
 		bridgeProvider: deps.BridgeProvider,
 		hookService:    deps.HookService,
+		extensions:     deps.Extensions,
 		logger:         logger.With(slog.String("service", "agent/runtime/native")),
 		limits:         deps.Limits.Normalize(),
 	}
@@ -174,7 +177,7 @@ func (a *Agent) runStream(ctx context.Context, cfg RunConfig, ch chan<- StreamEv
 	}
 	limit := a.Limits().ToolOutputLimit()
 	sdkTools, readMediaState := decorateReadMediaTools(cfg.Model, sdkTools)
-	cfg = cfg.RefreshContextFragWithDynamicMutators(readMediaState != nil, a != nil && a.hookService != nil, true)
+	cfg = cfg.RefreshContextFragWithDynamicMutators(readMediaState != nil, a.modelCallContextMutable(), true)
 	sdkTools = tools.WrapToolOutputLimits(sdkTools, limit)
 	approvalTools := append([]sdk.Tool(nil), sdkTools...)
 	sdkTools = a.wrapToolsWithHooks(ctx, cfg, sdkTools)
@@ -268,18 +271,23 @@ func (a *Agent) runStream(ctx context.Context, cfg RunConfig, ch chan<- StreamEv
 	}
 
 	prepareStep = a.wrapPrepareStepWithModelHook(streamCtx, cfg, prepareStep)
+	prepareStep = a.wrapPrepareStepWithModelExtensions(streamCtx, cfg, prepareStep)
 	var err error
 	cfg, err = a.applyBeforeModelCallHook(streamCtx, cfg, 0)
+	if err == nil {
+		cfg, err = a.applyBeforeModelCallExtensions(streamCtx, cfg, 0)
+	}
 	if err != nil {
 		turnError = err.Error()
 		sendEvent(ctx, ch, StreamEvent{Type: EventError, Error: turnError})
 		return
 	}
-	cfg = cfg.RefreshContextFragWithDynamicMutators(readMediaState != nil, a != nil && a.hookService != nil, true)
+	cfg = cfg.RefreshContextFragWithDynamicMutators(readMediaState != nil, a.modelCallContextMutable(), true)
 	opts := a.buildGenerateOptions(cfg, sdkTools, approvalTools, prepareStep)
 	modelStepIndex := 0
 	opts = append(opts, sdk.WithOnStep(func(step *sdk.StepResult) *sdk.GenerateParams {
 		a.runAfterModelCallHook(streamCtx, cfg, step, modelStepIndex)
+		a.runAfterModelCallExtensions(streamCtx, cfg, step, modelStepIndex)
 		modelStepIndex++
 		return nil
 	}))
@@ -668,7 +676,7 @@ func (a *Agent) runGenerate(ctx context.Context, cfg RunConfig) (result *Generat
 	}
 	limit := a.Limits().ToolOutputLimit()
 	sdkTools, readMediaState := decorateReadMediaTools(cfg.Model, sdkTools)
-	cfg = cfg.RefreshContextFragWithDynamicMutators(readMediaState != nil, a != nil && a.hookService != nil, false)
+	cfg = cfg.RefreshContextFragWithDynamicMutators(readMediaState != nil, a.modelCallContextMutable(), false)
 	sdkTools = tools.WrapToolOutputLimits(sdkTools, limit)
 	approvalTools := append([]sdk.Tool(nil), sdkTools...)
 	sdkTools = a.wrapToolsWithHooks(ctx, cfg, sdkTools)
@@ -694,16 +702,22 @@ func (a *Agent) runGenerate(ctx context.Context, cfg RunConfig) (result *Generat
 	}
 
 	prepareStep = a.wrapPrepareStepWithModelHook(genCtx, cfg, prepareStep)
+	prepareStep = a.wrapPrepareStepWithModelExtensions(genCtx, cfg, prepareStep)
 	cfg, err := a.applyBeforeModelCallHook(genCtx, cfg, 0)
 	if err != nil {
 		return nil, err
 	}
-	cfg = cfg.RefreshContextFragWithDynamicMutators(readMediaState != nil, a != nil && a.hookService != nil, false)
+	cfg, err = a.applyBeforeModelCallExtensions(genCtx, cfg, 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.RefreshContextFragWithDynamicMutators(readMediaState != nil, a.modelCallContextMutable(), false)
 	opts := a.buildGenerateOptions(cfg, sdkTools, approvalTools, prepareStep)
 	modelStepIndex := 0
 	opts = append(opts,
 		sdk.WithOnStep(func(step *sdk.StepResult) *sdk.GenerateParams {
 			a.runAfterModelCallHook(genCtx, cfg, step, modelStepIndex)
+			a.runAfterModelCallExtensions(genCtx, cfg, step, modelStepIndex)
 			modelStepIndex++
 			if cfg.LoopDetection.Enabled {
 				if toolLoopAbortCallIDs.Any() {
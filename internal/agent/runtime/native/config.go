@@ -4,6 +4,7 @@ import (
 	"log/slog"
 
 	agenttools "github.com/memohai/memoh/internal/agent/tool"
+	"github.com/memohai/memoh/internal/extension"
 	"github.com/memohai/memoh/internal/hooks"
 	"github.com/memohai/memoh/internal/workspace/bridge"
 )
@@ -24,6 +25,7 @@ type Limits struct {
 type Deps struct {
 	BridgeProvider bridge.Provider
 	HookService    *hooks.Service
+	Extensions     *extension.Chain
 	Logger         *slog.Logger
 	Limits         Limits
 }
@@ -0,0 +1,101 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	sdk "github.com/memohai/twilight-ai/sdk"
+
+	"github.com/memohai/memoh/internal/extension"
+)
+
+// modelCallContextMutable reports whether before-model-call hooks or
+// extensions may add messages between steps.
+func (a *Agent) modelCallContextMutable() bool {
+	return a != nil && (a.hookService != nil || a.extensions.Handles(extension.HookBeforeModelCall))
+}
+
+func modelCallEvent(cfg RunConfig, step int, messageCount int) *extension.ModelCall {
+	return &extension.ModelCall{
+		BotID:        cfg.Identity.BotID,
+		SessionID:    cfg.Identity.SessionID,
+		ChatID:       cfg.Identity.ChatID,
+		SessionType:  cfg.SessionType,
+		Model:        modelID(cfg.Model),
+		Step:         step,
+		MessageCount: messageCount,
+	}
+}
+
+// applyBeforeModelCallExtensions runs the extensions before the first model
+// call of a turn. Unlike later steps, a block here refuses the turn.
+func (a *Agent) applyBeforeModelCallExtensions(ctx context.Context, cfg RunConfig, step int) (RunConfig, error) {
+	if a == nil || !a.extensions.Handles(extension.HookBeforeModelCall) {
+		return cfg, nil
+	}
+	call := modelCallEvent(cfg, step, len(cfg.Messages))
+	if err := a.extensions.OnBeforeModelCall(ctx, call); err != nil {
+		return cfg, fmt.Errorf("model call refused: %w", err)
+	}
+	if strings.TrimSpace(call.AppendContext) != "" {
+		cfg.Messages = append(cfg.Messages, sdk.UserMessage(formatExtensionContext(call.AppendContext)))
+		cfg = cfg.RefreshContextFrag()
+	}
+	return cfg, nil
+}
+
+func (a *Agent) wrapPrepareStepWithModelExtensions(ctx context.Context, cfg RunConfig, base func(*sdk.GenerateParams) *sdk.GenerateParams) func(*sdk.GenerateParams) *sdk.GenerateParams {
+	if a == nil || !a.extensions.Handles(extension.HookBeforeModelCall) {
+		return base
+	}
+	step := 1
+	return func(p *sdk.GenerateParams) *sdk.GenerateParams {
+		if base != nil {
+			if override := base(p); override != nil {
+				p = override
+			}
+		}
+		call := modelCallEvent(cfg, step, len(p.Messages))
+		step++
+		if err := a.extensions.OnBeforeModelCall(ctx, call); err != nil {
+			// The turn is already under way; a block mid-turn is only logged.
+			if a.logger != nil {
+				a.logger.Warn("before model call extension blocked a later step",
+					slog.String("bot_id", cfg.Identity.BotID),
+					slog.String("session_id", cfg.Identity.SessionID),
+					slog.Any("error", err),
+				)
+			}
+			return p
+		}
+		if strings.TrimSpace(call.AppendContext) != "" {
+			p.Messages = append(p.Messages, sdk.UserMessage(formatExtensionContext(call.AppendContext)))
+		}
+		return p
+	}
+}
+
+func (a *Agent) runAfterModelCallExtensions(ctx context.Context, cfg RunConfig, step *sdk.StepResult, stepIndex int) {
+	if a == nil || step == nil || !a.extensions.Handles(extension.HookAfterModelCall) {
+		return
+	}
+	a.extensions.OnAfterModelCall(context.WithoutCancel(ctx), &extension.ModelResult{
+		BotID:        cfg.Identity.BotID,
+		SessionID:    cfg.Identity.SessionID,
+		ChatID:       cfg.Identity.ChatID,
+		SessionType:  cfg.SessionType,
+		Model:        modelID(cfg.Model),
+		Step:         stepIndex,
+		FinishReason: string(step.FinishReason),
+		Text:         step.Text,
+		InputTokens:  step.Usage.InputTokens,
+		OutputTokens: step.Usage.OutputTokens,
+		ToolCalls:    len(step.ToolCalls),
+	})
+}
+
+func formatExtensionContext(text string) string {
+	return "[Extension Context]\n" + strings.TrimSpace(text)
+}
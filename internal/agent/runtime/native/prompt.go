@@ -130,7 +130,7 @@ func GenerateSystemPrompt(params SystemPromptParams) string {
 		"botInfoSection":            botInfoSection,
 		"skillsSection":             skillsSection,
 		"platformIdentitiesSection": strings.TrimSpace(params.PlatformIdentitiesSection),
-		"mainAgentSections":         buildMainAgentSections(strings.TrimSpace(params.PlatformIdentitiesSection), skillsSection, fileSections),
+		"mainAgentSections":         buildMainAgentSections(strings.TrimSpace(params.PlatformIdentitiesSection), skillsSection, fileSections, params.PersonaSection),
 		"subagentSections":          buildSubagentSections(strings.TrimSpace(params.PlatformIdentitiesSection)),
 		"fileSections":              fileSections,
 	})
@@ -146,6 +146,9 @@ type SystemPromptParams struct {
 	Now                       time.Time
 	Timezone                  string
 	PlatformIdentitiesSection string
+	// PersonaSection is the rendered persona preset and sliders; it follows
+	// the workspace files so it refines rather than replaces them.
+	PersonaSection string
 }
 
 func buildBotInfoSection(bot BotInfo) string {
@@ -307,7 +310,7 @@ func splitHeadTail(maxBytes int) (int, int) {
 	return headBytes, tailBytes
 }
 
-func buildMainAgentSections(platformIdentitiesSection string, skillsSection, fileSections string) string {
+func buildMainAgentSections(platformIdentitiesSection string, skillsSection, fileSections, personaSection string) string {
 	identitiesSection := render(includes["_identities"], map[string]string{
 		"platformIdentitiesSection": platformIdentitiesSection,
 	})
@@ -316,6 +319,7 @@ func buildMainAgentSections(platformIdentitiesSection string, skillsSection, fil
 		identitiesSection,
 		skillsSection,
 		fileSections,
+		personaSection,
 	}
 	return joinPromptSections(sections...)
 }
@@ -29,6 +29,27 @@ func TestGenerateSystemPromptIncludesPlatformIdentitiesInChat(t *testing.T) {
 	}
 }
 
+func TestGenerateSystemPromptAppendsPersonaAfterFiles(t *testing.T) {
+	t.Parallel()
+
+	prompt := GenerateSystemPrompt(SystemPromptParams{
+		SessionType:    sessionmode.Chat,
+		Now:            time.Unix(1, 0).UTC(),
+		Timezone:       "UTC",
+		Files:          []SystemFile{{Filename: "SOUL.md", Content: "soul body"}},
+		PersonaSection: "## Persona\n\n- Never use emoji.",
+	})
+
+	persona := strings.Index(prompt, "## Persona")
+	files := strings.Index(prompt, "soul body")
+	if persona < 0 || files < 0 {
+		t.Fatalf("expected persona and file sections in prompt:\n%s", prompt)
+	}
+	if persona < files {
+		t.Fatalf("expected persona after workspace files")
+	}
+}
+
 func TestGenerateSystemPromptIncludesCommonAndModeContracts(t *testing.T) {
 	t.Parallel()
 
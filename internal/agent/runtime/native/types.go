@@ -106,6 +106,7 @@ type RunConfig struct {
 	InlineImages                []sdk.ImagePart
 	Identity                    SessionContext
 	Bot                         BotInfo
+	PersonaSection              string
 	Skills                      []SkillEntry
 	LoopDetection               LoopDetectionConfig
 	Retry                       RetryConfig
@@ -29,6 +29,11 @@ func NewFederationProvider(log *slog.Logger, source mcp.ToolSource) *FederationP
 	}
 }
 
+// Source returns the tool source the provider serves.
+func (f *FederationProvider) Source() mcp.ToolSource {
+	return f.source
+}
+
 func (f *FederationProvider) Tools(ctx context.Context, session SessionContext) ([]sdk.Tool, error) {
 	if f.source == nil {
 		return nil, nil
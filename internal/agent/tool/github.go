@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	sdk "github.com/memohai/twilight-ai/sdk"
+
+	"github.com/memohai/memoh/internal/github"
+)
+
+type GitHubProvider struct {
+	logger  *slog.Logger
+	service *github.Service
+}
+
+func NewGitHubProvider(log *slog.Logger, service *github.Service) *GitHubProvider {
+	return &GitHubProvider{
+		logger:  log.With(slog.String("tool", "github")),
+		service: service,
+	}
+}
+
+func (p *GitHubProvider) Tools(_ context.Context, session SessionContext) ([]sdk.Tool, error) {
+	if !p.service.Enabled() {
+		return nil, nil
+	}
+	sess := session
+	return []sdk.Tool{
+		{
+			Name: ToolCommentGitHubIssue().String(), Description: "Comment on a GitHub issue or pull request as the GitHub App. The bot must be subscribed to the repository with commenting allowed.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"repo":   map[string]any{"type": "string", "description": "Repository as owner/name"},
+					"number": map[string]any{"type": "integer", "description": "Issue or pull request number"},
+					"body":   map[string]any{"type": "string", "description": "Comment body in GitHub Markdown"},
+				},
+				"required": []string{"repo", "number", "body"},
+			},
+			Execute: func(ctx *sdk.ToolExecContext, input any) (any, error) {
+				return p.execComment(ctx.Context, sess, inputAsMap(input))
+			},
+		},
+	}, nil
+}
+
+func (p *GitHubProvider) execComment(ctx context.Context, session SessionContext, args map[string]any) (any, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if botID == "" {
+		return nil, errors.New("bot_id is required")
+	}
+	number, ok, err := IntArg(args, "number")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("number is required")
+	}
+	comment, err := p.service.CommentOnIssue(ctx, botID, StringArg(args, "repo"), number, StringArg(args, "body"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"comment_id": comment.ID, "url": comment.URL, "status": "posted"}, nil
+}
@@ -34,11 +34,17 @@ type HistoryMessageReader interface {
 	ListBeforeBySession(ctx context.Context, sessionID string, before time.Time, limit int32) ([]messagepkg.Message, error)
 }
 
+// HistoryArchiveReader loads message content moved to cold storage.
+type HistoryArchiveReader interface {
+	Load(ctx context.Context, key string) ([]byte, error)
+}
+
 // HistoryProvider exposes list_sessions, get_messages, and search_messages tools.
 type HistoryProvider struct {
 	sessions SessionLister
 	messages HistoryMessageReader
 	queries  dbstore.Queries
+	archive  HistoryArchiveReader
 	logger   *slog.Logger
 }
 
@@ -54,6 +60,11 @@ func NewHistoryProvider(log *slog.Logger, sessions SessionLister, messages Histo
 	}
 }
 
+// SetArchiveReader restores archived message content in search results.
+func (p *HistoryProvider) SetArchiveReader(r HistoryArchiveReader) {
+	p.archive = r
+}
+
 func (*HistoryProvider) Usage(_ context.Context, _ SessionContext, available AvailableTools) string {
 	var parts []string
 	listSessionsRef := ""
@@ -406,7 +417,16 @@ func (p *HistoryProvider) execSearchMessages(ctx context.Context, sess SessionCo
 
 	messages := make([]map[string]any, 0, len(rows))
 	for _, row := range rows {
-		text := extractTextContent(row.Content)
+		content := row.Content
+		if row.ArchiveKey.Valid && p.archive != nil {
+			// Archived rows hold a preview; search shows the full message.
+			if archived, err := p.archive.Load(ctx, row.ArchiveKey.String); err == nil {
+				content = archived
+			} else {
+				p.logger.Warn("load archived message failed", slog.String("message_id", row.ID.String()), slog.Any("error", err))
+			}
+		}
+		text := extractTextContent(content)
 
 		entry := map[string]any{
 			"id":         row.ID.String(),
@@ -39,15 +39,18 @@ func ToolKillBackground() Name      { return newName("kill_background") }
 func ToolWait() Name                { return newName("wait") }
 func ToolWaitUntil() Name           { return newName("wait_until") }
 
-func ToolSend() Name  { return newName("send") }
-func ToolReact() Name { return newName("react") }
-func ToolSpeak() Name { return newName("speak") }
+func ToolSend() Name        { return newName("send") }
+func ToolReact() Name       { return newName("react") }
+func ToolSpeak() Name       { return newName("speak") }
+func ToolSendSticker() Name { return newName("send_sticker") }
 
 func ToolGetContacts() Name    { return newName("get_contacts") }
 func ToolListSessions() Name   { return newName("list_sessions") }
 func ToolGetMessages() Name    { return newName("get_messages") }
 func ToolSearchMessages() Name { return newName("search_messages") }
 func ToolSearchMemory() Name   { return newName(memprovider.ToolSearchMemory) }
+func ToolListMemories() Name   { return newName("list_memories") }
+func ToolFlagMemory() Name     { return newName("flag_memory") }
 func ToolListSkills() Name     { return newName("list_skills") }
 func ToolUseSkill() Name       { return newName("use_skill") }
 func ToolSpawnAgent() Name     { return newName("spawn_agent") }
@@ -79,14 +82,17 @@ func ToolSendEmail() Name         { return newName("send_email") }
 func ToolListEmail() Name         { return newName("list_email") }
 func ToolReadEmail() Name         { return newName("read_email") }
 
+func ToolCommentGitHubIssue() Name { return newName("comment_github_issue") }
+
 var all = []Name{
 	ToolRead(), ToolWrite(), ToolList(), ToolEdit(), ToolExec(), ToolApplyPatch(), ToolListExecutionLocations(), ToolListBackground(), ToolGetBackgroundStatus(), ToolKillBackground(), ToolWait(), ToolWaitUntil(),
-	ToolSend(), ToolReact(), ToolSpeak(),
-	ToolGetContacts(), ToolListSessions(), ToolGetMessages(), ToolSearchMessages(), ToolSearchMemory(), ToolListSkills(), ToolUseSkill(), ToolSpawnAgent(), ToolSendMessage(), ToolListAgents(), ToolListModels(),
+	ToolSend(), ToolReact(), ToolSpeak(), ToolSendSticker(),
+	ToolGetContacts(), ToolListSessions(), ToolGetMessages(), ToolSearchMessages(), ToolSearchMemory(), ToolListMemories(), ToolFlagMemory(), ToolListSkills(), ToolUseSkill(), ToolSpawnAgent(), ToolSendMessage(), ToolListAgents(), ToolListModels(),
 	ToolListSchedule(), ToolGetSchedule(), ToolCreateSchedule(), ToolUpdateSchedule(), ToolDeleteSchedule(),
 	ToolBrowserAction(), ToolBrowserObserve(), ToolComputerObserve(), ToolComputerAction(), ToolBrowserRemoteSession(),
 	ToolWebSearch(), ToolWebFetch(), ToolGenerateImage(), ToolGenerateVideo(), ToolTranscribeAudio(), ToolAskUser(),
 	ToolListEmailAccounts(), ToolSendEmail(), ToolListEmail(), ToolReadEmail(),
+	ToolCommentGitHubIssue(),
 }
 
 // All returns the complete built-in Memoh tool catalog.
@@ -3,13 +3,16 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"sort"
 	"strings"
 
 	sdk "github.com/memohai/twilight-ai/sdk"
 
 	"github.com/memohai/memoh/internal/mcp"
 	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	memflags "github.com/memohai/memoh/internal/memory/flags"
 	"github.com/memohai/memoh/internal/settings"
 )
 
@@ -18,32 +21,54 @@ type MemorySettingsReader interface {
 	GetBot(ctx context.Context, botID string) (settings.Settings, error)
 }
 
+// MemoryFlagCreator queues memories the agent flags as outdated for the bot
+// owner to review.
+type MemoryFlagCreator interface {
+	Create(ctx context.Context, botID string, req memflags.CreateRequest) (memflags.Flag, error)
+}
+
+const (
+	defaultListMemoriesLimit = 50
+	maxListMemoriesLimit     = 200
+)
+
 type MemoryProvider struct {
 	registry *memprovider.Registry
 	settings MemorySettingsReader
+	flags    MemoryFlagCreator
 	logger   *slog.Logger
 }
 
-func NewMemoryProvider(log *slog.Logger, registry *memprovider.Registry, settingsSvc MemorySettingsReader) *MemoryProvider {
+func NewMemoryProvider(log *slog.Logger, registry *memprovider.Registry, settingsSvc MemorySettingsReader, flagService MemoryFlagCreator) *MemoryProvider {
 	if log == nil {
 		log = slog.Default()
 	}
 	return &MemoryProvider{
 		registry: registry,
 		settings: settingsSvc,
+		flags:    flagService,
 		logger:   log.With(slog.String("tool", "memory")),
 	}
 }
 
-func (*MemoryProvider) Usage(_ context.Context, _ SessionContext, available AvailableTools) string {
-	ref, ok := available.Ref(ToolSearchMemory())
-	if !ok {
+func (p *MemoryProvider) Usage(_ context.Context, _ SessionContext, available AvailableTools) string {
+	var parts []string
+	if ref, ok := available.Ref(ToolSearchMemory()); ok {
+		parts = append(parts,
+			"Use "+ref+" to recall durable user preferences, prior conversations, project context, and other long-term facts beyond the current context window.",
+			"When retrieved memory conflicts with the latest user message or visible context, treat the latest user message and current context as authoritative.",
+		)
+	}
+	if ref, ok := available.Ref(ToolListMemories()); ok {
+		parts = append(parts, "Use "+ref+" when the user asks what you remember about them.")
+	}
+	if ref, ok := available.Ref(ToolFlagMemory()); ok && p.flags != nil {
+		parts = append(parts, "When the user says a memory is wrong or outdated (\"forget my old address\"), find it and use "+ref+" with its id. Flagged memories are not deleted until the bot owner confirms, so tell the user the change is waiting for confirmation.")
+	}
+	if len(parts) == 0 {
 		return ""
 	}
-	return usageSection("Long-term memory", []string{
-		"Use " + ref + " to recall durable user preferences, prior conversations, project context, and other long-term facts beyond the current context window.",
-		"When retrieved memory conflicts with the latest user message or visible context, treat the latest user message and current context as authoritative.",
-	})
+	return usageSection("Long-term memory", parts)
 }
 
 func (p *MemoryProvider) Tools(ctx context.Context, session SessionContext) ([]sdk.Tool, error) {
@@ -75,7 +100,140 @@ func (p *MemoryProvider) Tools(ctx context.Context, session SessionContext) ([]s
 			},
 		})
 	}
-	return tools, nil
+	return append(tools, p.introspectionTools(provider, session)...), nil
+}
+
+// introspectionTools let the agent see what it remembers about the bot and
+// flag memories the user says are wrong. Flags only queue the memory for the
+// owner; the agent never deletes memories itself.
+func (p *MemoryProvider) introspectionTools(provider memprovider.Provider, session SessionContext) []sdk.Tool {
+	botID := strings.TrimSpace(session.BotID)
+	tools := []sdk.Tool{
+		{
+			Name:        ToolListMemories().String(),
+			Description: "List the long-term memories stored for the current bot, most recent first. Use search_memory instead to find memories about a topic.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"limit": map[string]any{"type": "integer", "description": "Maximum number of memories to return"},
+				},
+			},
+			Execute: func(ctx *sdk.ToolExecContext, input any) (any, error) {
+				limit := defaultListMemoriesLimit
+				if value, ok, err := IntArg(inputAsMap(input), "limit"); err != nil {
+					return nil, err
+				} else if ok && value > 0 {
+					limit = min(value, maxListMemoriesLimit)
+				}
+				items, err := listBotMemories(ctx.Context, provider, botID)
+				if err != nil {
+					return nil, err
+				}
+				total := len(items)
+				if len(items) > limit {
+					items = items[:limit]
+				}
+				results := make([]map[string]any, 0, len(items))
+				for _, item := range items {
+					results = append(results, map[string]any{
+						"id":         item.ID,
+						"memory":     item.Memory,
+						"updated_at": item.UpdatedAt,
+					})
+				}
+				return map[string]any{"total": total, "results": results}, nil
+			},
+		},
+	}
+	if p.flags == nil {
+		return tools
+	}
+	return append(tools, sdk.Tool{
+		Name:        ToolFlagMemory().String(),
+		Description: "Flag one of your memories as outdated or wrong. The memory is queued for the bot owner to confirm and is only deleted after confirmation.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"memory_id": map[string]any{"type": "string", "description": "Memory ID from list_memories or search_memory"},
+				"reason":    map[string]any{"type": "string", "description": "Why the memory is outdated, e.g. what the user said instead"},
+			},
+			"required": []string{"memory_id", "reason"},
+		},
+		Execute: func(ctx *sdk.ToolExecContext, input any) (any, error) {
+			args := inputAsMap(input)
+			if botID == "" {
+				return nil, errors.New("bot_id is required")
+			}
+			memoryID := StringArg(args, "memory_id")
+			if memoryID == "" {
+				return nil, errors.New("memory_id is required")
+			}
+			if !strings.Contains(memoryID, ":") {
+				memoryID = botID + ":" + memoryID
+			}
+			if !memflags.OwnedByBot(botID, memoryID) {
+				return nil, errors.New("memory does not belong to this bot")
+			}
+			items, err := listBotMemories(ctx.Context, provider, botID)
+			if err != nil {
+				return nil, err
+			}
+			var memory *memprovider.MemoryItem
+			for i := range items {
+				if items[i].ID == memoryID {
+					memory = &items[i]
+					break
+				}
+			}
+			if memory == nil {
+				return nil, errors.New("memory not found")
+			}
+			flag, err := p.flags.Create(ctx.Context, botID, memflags.CreateRequest{
+				MemoryID:  memoryID,
+				Memory:    memory.Memory,
+				Reason:    StringArg(args, "reason"),
+				SessionID: session.SessionID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{
+				"flag_id":   flag.ID,
+				"memory_id": flag.MemoryID,
+				"status":    flag.Status,
+				"message":   "Queued for the bot owner to confirm. The memory stays until they do.",
+			}, nil
+		},
+	})
+}
+
+// listBotMemories returns the bot's shared memories with canonical
+// "<botID>:" IDs, most recently updated first.
+func listBotMemories(ctx context.Context, provider memprovider.Provider, botID string) ([]memprovider.MemoryItem, error) {
+	if botID == "" {
+		return nil, errors.New("bot_id is required")
+	}
+	resp, err := provider.GetAll(ctx, memprovider.GetAllRequest{
+		BotID:   botID,
+		Filters: map[string]any{"namespace": "bot", "scopeId": botID},
+		NoStats: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]memprovider.MemoryItem, 0, len(resp.Results))
+	for _, item := range memprovider.DeduplicateItems(resp.Results) {
+		if !strings.Contains(item.ID, ":") {
+			item.ID = botID + ":" + item.ID
+		}
+		if memflags.OwnedByBot(botID, item.ID) {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].UpdatedAt > items[j].UpdatedAt
+	})
+	return items, nil
 }
 
 func (p *MemoryProvider) resolveProvider(ctx context.Context, botID string) memprovider.Provider {
@@ -107,6 +265,7 @@ func toMCPSession(s SessionContext) mcp.ToolSessionContext {
 		ChatID:            s.ChatID,
 		SessionID:         s.SessionID,
 		SessionType:       s.SessionType,
+		UserID:            s.UserID,
 		ChannelIdentityID: s.ChannelIdentityID,
 		SessionToken:      s.SessionToken,
 		CurrentPlatform:   s.CurrentPlatform,
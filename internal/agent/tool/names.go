@@ -22,15 +22,18 @@ func ToolKillBackground() ToolName      { return toolname.ToolKillBackground() }
 func ToolWait() ToolName                { return toolname.ToolWait() }
 func ToolWaitUntil() ToolName           { return toolname.ToolWaitUntil() }
 
-func ToolSend() ToolName  { return toolname.ToolSend() }
-func ToolReact() ToolName { return toolname.ToolReact() }
-func ToolSpeak() ToolName { return toolname.ToolSpeak() }
+func ToolSend() ToolName        { return toolname.ToolSend() }
+func ToolReact() ToolName       { return toolname.ToolReact() }
+func ToolSpeak() ToolName       { return toolname.ToolSpeak() }
+func ToolSendSticker() ToolName { return toolname.ToolSendSticker() }
 
 func ToolGetContacts() ToolName    { return toolname.ToolGetContacts() }
 func ToolListSessions() ToolName   { return toolname.ToolListSessions() }
 func ToolGetMessages() ToolName    { return toolname.ToolGetMessages() }
 func ToolSearchMessages() ToolName { return toolname.ToolSearchMessages() }
 func ToolSearchMemory() ToolName   { return toolname.ToolSearchMemory() }
+func ToolListMemories() ToolName   { return toolname.ToolListMemories() }
+func ToolFlagMemory() ToolName     { return toolname.ToolFlagMemory() }
 func ToolListSkills() ToolName     { return toolname.ToolListSkills() }
 func ToolUseSkill() ToolName       { return toolname.ToolUseSkill() }
 func ToolSpawnAgent() ToolName     { return toolname.ToolSpawnAgent() }
@@ -62,6 +65,8 @@ func ToolSendEmail() ToolName         { return toolname.ToolSendEmail() }
 func ToolListEmail() ToolName         { return toolname.ToolListEmail() }
 func ToolReadEmail() ToolName         { return toolname.ToolReadEmail() }
 
+func ToolCommentGitHubIssue() ToolName { return toolname.ToolCommentGitHubIssue() }
+
 func toolRef(name ToolName) string {
 	return "`" + name.String() + "`"
 }
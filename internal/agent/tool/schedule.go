@@ -3,8 +3,10 @@ package tools
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	sdk "github.com/memohai/twilight-ai/sdk"
 
@@ -13,6 +15,7 @@ import (
 
 type ScheduleProvider struct {
 	service Scheduler
+	limits  sched.Limits
 	logger  *slog.Logger
 }
 
@@ -31,14 +34,21 @@ func NewScheduleProvider(log *slog.Logger, service Scheduler) *ScheduleProvider
 	}
 	return &ScheduleProvider{
 		service: service,
+		limits:  sched.DefaultLimits(),
 		logger:  log.With(slog.String("tool", "schedule")),
 	}
 }
 
+// SetLimits replaces the per-bot limits on schedules the agent creates or
+// reschedules.
+func (p *ScheduleProvider) SetLimits(limits sched.Limits) {
+	p.limits = limits
+}
+
 // Usage describes how the schedule tool group works together. Injected only
 // when the schedule tools are registered (main-agent sessions with a schedule
 // service); guidance is emitted only when schedule tools are actually present.
-func (*ScheduleProvider) Usage(_ context.Context, _ SessionContext, available AvailableTools) string {
+func (p *ScheduleProvider) Usage(_ context.Context, session SessionContext, available AvailableTools) string {
 	var parts []string
 	delivery := "include an instruction to deliver results to a person or channel when messaging is available"
 	if sendRef, ok := available.Ref(ToolSend()); ok {
@@ -50,6 +60,12 @@ func (*ScheduleProvider) Usage(_ context.Context, _ SessionContext, available Av
 		parts = append(parts, "You can create and manage scheduled tasks via cron.")
 		parts = append(parts, "Use "+createRef+" to create a new task — fill `command` with natural language.")
 		parts = append(parts, "When the cron pattern fires, you will receive a message with your `command`; "+delivery+".")
+		if tz := sessionTimezone(session); tz != "" {
+			parts = append(parts, "Cron patterns are interpreted in the user's timezone ("+tz+"); write times as the user means them, without converting.")
+		}
+		if limits := p.limitsUsage(); limits != "" {
+			parts = append(parts, limits)
+		}
 	}
 	if ref, ok := available.Ref(ToolListSchedule()); ok {
 		parts = append(parts, "Use "+ref+" to list scheduled tasks.")
@@ -117,7 +133,7 @@ func (p *ScheduleProvider) Tools(_ context.Context, session SessionContext) ([]s
 			},
 		},
 		{
-			Name: ToolCreateSchedule().String(), Description: "Create a new cron-scheduled task. Fill `command` with a natural-language instruction; when the cron `pattern` fires, the task runs in its own session and you receive a message containing that `command`. Include explicit platform and target in delivery instructions when results should be sent to a person or channel. Set `max_calls` to null for unlimited runs.",
+			Name: ToolCreateSchedule().String(), Description: "Create a new cron-scheduled task. Fill `command` with a natural-language instruction; when the cron `pattern` fires, the task runs in its own session and you receive a message containing that `command`. The pattern is interpreted in the user's timezone shown in the context. Include explicit platform and target in delivery instructions when results should be sent to a person or channel. Set `max_calls` to null for unlimited runs.",
 			Parameters: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
@@ -141,7 +157,17 @@ func (p *ScheduleProvider) Tools(_ context.Context, session SessionContext) ([]s
 				if name == "" || description == "" || pattern == "" || command == "" {
 					return nil, errors.New("name, description, pattern, command are required")
 				}
-				req := sched.CreateRequest{Name: name, Description: description, Pattern: pattern, Command: command}
+				if err := p.limits.CheckPattern(pattern); err != nil {
+					return nil, err
+				}
+				existing, err := p.service.List(ctx.Context, botID)
+				if err != nil {
+					return nil, err
+				}
+				if err := p.limits.CheckCount(len(existing)); err != nil {
+					return nil, err
+				}
+				req := sched.CreateRequest{Name: name, Description: description, Pattern: pattern, Command: command, Timezone: sessionTimezone(sess)}
 				maxCalls, err := parseNullableIntArg(args, "max_calls")
 				if err != nil {
 					return nil, err
@@ -195,7 +221,14 @@ func (p *ScheduleProvider) Tools(_ context.Context, session SessionContext) ([]s
 					req.Description = &v
 				}
 				if v := StringArg(args, "pattern"); v != "" {
+					if err := p.limits.CheckPattern(v); err != nil {
+						return nil, err
+					}
 					req.Pattern = &v
+					// A rewritten pattern is in the current user's timezone.
+					if tz := sessionTimezone(sess); tz != "" {
+						req.Timezone = &tz
+					}
 				}
 				if v := StringArg(args, "command"); v != "" {
 					req.Command = &v
@@ -205,14 +238,14 @@ func (p *ScheduleProvider) Tools(_ context.Context, session SessionContext) ([]s
 				} else if ok {
 					req.Enabled = &enabled
 				}
-				item, err := p.service.Update(ctx.Context, id, req)
+				existing, err := p.service.Get(ctx.Context, id)
 				if err != nil {
 					return nil, err
 				}
-				if item.BotID != botID {
+				if existing.BotID != botID {
 					return nil, errors.New("bot mismatch")
 				}
-				return item, nil
+				return p.service.Update(ctx.Context, id, req)
 			},
 		},
 		{
@@ -250,6 +283,22 @@ func (p *ScheduleProvider) Tools(_ context.Context, session SessionContext) ([]s
 	}, nil
 }
 
+// limitsUsage tells the agent about its limits up front, so it does not
+// learn them from failed calls.
+func (p *ScheduleProvider) limitsUsage() string {
+	var limits []string
+	if p.limits.MaxPerBot > 0 {
+		limits = append(limits, fmt.Sprintf("at most %d scheduled tasks", p.limits.MaxPerBot))
+	}
+	if p.limits.MinInterval > 0 {
+		limits = append(limits, "none running more often than every "+p.limits.MinInterval.String())
+	}
+	if len(limits) == 0 {
+		return ""
+	}
+	return "You may have " + strings.Join(limits, ", ") + "."
+}
+
 func parseNullableIntArg(arguments map[string]any, key string) (sched.NullableInt, error) {
 	req := sched.NullableInt{}
 	if arguments == nil {
@@ -275,3 +324,12 @@ func parseNullableIntArg(arguments map[string]any, key string) (sched.NullableIn
 func emptyObjectSchema() map[string]any {
 	return map[string]any{"type": "object", "properties": map[string]any{}}
 }
+
+// sessionTimezone names the timezone the session's user reads times in, or
+// "" when it is unknown and schedules should follow the bot's timezone.
+func sessionTimezone(session SessionContext) string {
+	if session.TimezoneLocation == nil || session.TimezoneLocation == time.Local {
+		return ""
+	}
+	return session.TimezoneLocation.String()
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/memohai/twilight-ai/sdk"
+
+	sched "github.com/memohai/memoh/internal/schedule"
+)
+
+type scheduleTestService struct {
+	items   []sched.Schedule
+	updated bool
+}
+
+func (s *scheduleTestService) List(context.Context, string) ([]sched.Schedule, error) {
+	return s.items, nil
+}
+
+func (s *scheduleTestService) Get(_ context.Context, id string) (sched.Schedule, error) {
+	for _, item := range s.items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return sched.Schedule{}, errors.New("schedule not found")
+}
+
+func (s *scheduleTestService) Create(_ context.Context, botID string, req sched.CreateRequest) (sched.Schedule, error) {
+	item := sched.Schedule{ID: "new", BotID: botID, Name: req.Name, Pattern: req.Pattern, Timezone: req.Timezone}
+	s.items = append(s.items, item)
+	return item, nil
+}
+
+func (s *scheduleTestService) Update(ctx context.Context, id string, _ sched.UpdateRequest) (sched.Schedule, error) {
+	s.updated = true
+	return s.Get(ctx, id)
+}
+
+func (*scheduleTestService) Delete(context.Context, string) error {
+	return nil
+}
+
+func scheduleTool(t *testing.T, provider *ScheduleProvider, name ToolName) sdk.Tool {
+	t.Helper()
+	tools, err := provider.Tools(context.Background(), SessionContext{BotID: "bot-1"})
+	if err != nil {
+		t.Fatalf("Tools: %v", err)
+	}
+	for _, tool := range tools {
+		if tool.Name == name.String() {
+			return tool
+		}
+	}
+	t.Fatalf("tool %q not found", name.String())
+	return sdk.Tool{}
+}
+
+func TestScheduleToolsEnforceLimits(t *testing.T) {
+	service := &scheduleTestService{items: []sched.Schedule{{ID: "s1", BotID: "bot-1"}}}
+	provider := NewScheduleProvider(nil, service)
+	provider.SetLimits(sched.Limits{MaxPerBot: 2, MinInterval: time.Hour})
+	create := scheduleTool(t, provider, ToolCreateSchedule())
+	execCtx := &sdk.ToolExecContext{Context: context.Background()}
+	input := func(pattern string) map[string]any {
+		return map[string]any{"name": "remind", "description": "weekly reminder", "pattern": pattern, "command": "remind me"}
+	}
+
+	if _, err := create.Execute(execCtx, input("*/5 * * * *")); !errors.Is(err, sched.ErrLimitExceeded) {
+		t.Fatalf("frequent pattern error = %v", err)
+	}
+	if _, err := create.Execute(execCtx, input("0 9 * * 1")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := create.Execute(execCtx, input("0 9 * * 2")); !errors.Is(err, sched.ErrLimitExceeded) {
+		t.Fatalf("create over limit error = %v", err)
+	}
+}
+
+func TestScheduleUpdateRejectsOtherBots(t *testing.T) {
+	service := &scheduleTestService{items: []sched.Schedule{{ID: "other", BotID: "bot-2"}}}
+	update := scheduleTool(t, NewScheduleProvider(nil, service), ToolUpdateSchedule())
+	_, err := update.Execute(&sdk.ToolExecContext{Context: context.Background()}, map[string]any{"id": "other", "name": "renamed"})
+	if err == nil || service.updated {
+		t.Fatalf("update of another bot's schedule: err = %v, updated = %v", err, service.updated)
+	}
+}
+
+func TestScheduleCreateUsesSessionTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	service := &scheduleTestService{}
+	tools, err := NewScheduleProvider(nil, service).Tools(context.Background(), SessionContext{BotID: "bot-1", TimezoneLocation: loc})
+	if err != nil {
+		t.Fatalf("Tools: %v", err)
+	}
+	for _, tool := range tools {
+		if tool.Name != ToolCreateSchedule().String() {
+			continue
+		}
+		input := map[string]any{"name": "standup", "description": "daily standup", "pattern": "0 9 * * 1-5", "command": "remind me"}
+		if _, err := tool.Execute(&sdk.ToolExecContext{Context: context.Background()}, input); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if len(service.items) != 1 || service.items[0].Timezone != "America/Chicago" {
+		t.Fatalf("created = %+v", service.items)
+	}
+}
@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	sdk "github.com/memohai/twilight-ai/sdk"
+
+	"github.com/memohai/memoh/internal/messaging"
+)
+
+type StickerProvider struct {
+	catalog  messaging.StickerReader
+	sender   messaging.Sender
+	resolver messaging.ChannelTypeResolver
+	logger   *slog.Logger
+}
+
+func NewStickerProvider(log *slog.Logger, catalog messaging.StickerReader, sender messaging.Sender, resolver messaging.ChannelTypeResolver) *StickerProvider {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &StickerProvider{
+		catalog:  catalog,
+		sender:   sender,
+		resolver: resolver,
+		logger:   log.With(slog.String("tool", "sticker")),
+	}
+}
+
+func (*StickerProvider) Usage(_ context.Context, _ SessionContext, available AvailableTools) string {
+	ref, ok := available.Ref(ToolSendSticker())
+	if !ok {
+		return ""
+	}
+	return usageSection("Stickers", []string{
+		ref + ": Send one of your stickers when it fits the mood of the conversation, as a person would. Pick by name from the catalog; do not send one with every reply.",
+	})
+}
+
+func (p *StickerProvider) Tools(ctx context.Context, session SessionContext) ([]sdk.Tool, error) {
+	if session.IsSubagent || p.catalog == nil || p.sender == nil || p.resolver == nil {
+		return nil, nil
+	}
+	botID := strings.TrimSpace(session.BotID)
+	if botID == "" {
+		return nil, nil
+	}
+	catalog, err := p.catalog.ListStickers(ctx, botID)
+	if err != nil {
+		p.logger.Warn("load sticker catalog failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return nil, nil
+	}
+	if platform := strings.TrimSpace(session.CurrentPlatform); platform != "" {
+		catalog = stickersForPlatform(catalog, platform)
+	}
+	if len(catalog) == 0 {
+		return nil, nil
+	}
+	names := make([]any, 0, len(catalog))
+	lines := make([]string, 0, len(catalog))
+	for _, s := range catalog {
+		names = append(names, s.Name)
+		line := "- " + s.Name
+		if s.Emoji != "" {
+			line += " " + s.Emoji
+		}
+		if s.Description != "" {
+			line += ": " + s.Description
+		}
+		lines = append(lines, line)
+	}
+	sess := session
+	description, platformDescription, targetDescription, required := stickerToolPromptMetadata(session)
+	return []sdk.Tool{{
+		Name:        ToolSendSticker().String(),
+		Description: description + " Available stickers:\n" + strings.Join(lines, "\n"),
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":     map[string]any{"type": "string", "description": "Catalog name of the sticker to send", "enum": names},
+				"platform": map[string]any{"type": "string", "description": platformDescription},
+				"target":   map[string]any{"type": "string", "description": targetDescription},
+				"reply_to": map[string]any{"type": "string", "description": "Message ID to reply to with the sticker."},
+			},
+			"required": required,
+		},
+		Execute: func(execCtx *sdk.ToolExecContext, input any) (any, error) {
+			return p.execSendSticker(execCtx.Context, sess, inputAsMap(input))
+		},
+	}}, nil
+}
+
+func stickerToolPromptMetadata(session SessionContext) (description string, platformDescription string, targetDescription string, required []string) {
+	if session.CanOmitMessagingTarget() {
+		return "Send a sticker from your catalog. When target is omitted, sends to the current conversation. Platforms without the native sticker get its emoji instead.",
+			"Channel platform name. Defaults to current session platform.",
+			"Channel target (chat/group/thread ID). Optional — omit to send in the current conversation.",
+			[]string{"name"}
+	}
+	return "Send a sticker from your catalog. Specify platform and target in this session. Platforms without the native sticker get its emoji instead.",
+		"Channel platform name. Required in this session.",
+		"Channel target (chat/group/thread ID). Required in this session.",
+		[]string{"name", "platform", "target"}
+}
+
+func (p *StickerProvider) execSendSticker(ctx context.Context, session SessionContext, args map[string]any) (any, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if botID == "" {
+		return nil, errors.New("bot_id is required")
+	}
+	name := FirstStringArg(args, "name")
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	platform := FirstStringArg(args, "platform")
+	if platform == "" {
+		platform = strings.TrimSpace(session.CurrentPlatform)
+	}
+	if platform == "" {
+		return nil, errors.New("platform is required")
+	}
+	channelType, err := p.resolver.ParseChannelType(platform)
+	if err != nil {
+		return nil, err
+	}
+	target := FirstStringArg(args, "target")
+	if target == "" {
+		target = defaultSpeakTargetForPlatform(args, session, channelType)
+	}
+	if target == "" {
+		return nil, errors.New("target is required")
+	}
+	catalog, err := p.catalog.ListStickers(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	sticker, ok := findSticker(catalog, name)
+	if !ok {
+		return nil, fmt.Errorf("sticker %q is not in the catalog", name)
+	}
+	var (
+		msg       messaging.Message
+		delivered string
+	)
+	if key := sticker.NativeKey(channelType.String()); key != "" {
+		msg.Attachments = []messaging.Attachment{{
+			Type:           messaging.AttachmentSticker,
+			PlatformKey:    key,
+			SourcePlatform: channelType.String(),
+		}}
+		delivered = "sticker"
+	} else if sticker.Emoji != "" {
+		msg.Text = sticker.Emoji
+		delivered = "emoji"
+	} else {
+		return nil, fmt.Errorf("sticker %q is not available on %s", sticker.Name, channelType)
+	}
+	if replyTo := FirstStringArg(args, "reply_to"); replyTo != "" {
+		msg.Reply = &messaging.ReplyRef{MessageID: replyTo}
+	}
+	if err := p.sender.Send(ctx, botID, channelType, messaging.SendRequest{Target: target, Message: msg}); err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"ok": true, "bot_id": botID, "platform": channelType.String(), "target": target,
+		"sticker": sticker.Name, "sent_as": delivered,
+	}, nil
+}
+
+// stickersForPlatform keeps the stickers that can be sent on platform,
+// natively or as their fallback emoji.
+func stickersForPlatform(catalog []messaging.Sticker, platform string) []messaging.Sticker {
+	var out []messaging.Sticker
+	for _, s := range catalog {
+		if s.NativeKey(platform) != "" || s.Emoji != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func findSticker(catalog []messaging.Sticker, name string) (messaging.Sticker, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, s := range catalog {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return messaging.Sticker{}, false
+}
@@ -166,15 +166,18 @@ func TestBuiltInToolsHaveUsageGuidanceOrExplicitExemption(t *testing.T) {
 		ToolWait():                   "background",
 		ToolWaitUntil():              "background",
 
-		ToolSend():  "messaging",
-		ToolReact(): "messaging",
-		ToolSpeak(): "tts",
+		ToolSend():        "messaging",
+		ToolReact():       "messaging",
+		ToolSpeak():       "tts",
+		ToolSendSticker(): "stickers",
 
 		ToolGetContacts():    "contacts",
 		ToolListSessions():   "history",
 		ToolGetMessages():    "history",
 		ToolSearchMessages(): "history",
 		ToolSearchMemory():   "memory",
+		ToolListMemories():   "memory",
+		ToolFlagMemory():     "memory",
 		ToolListSkills():     "skills",
 		ToolUseSkill():       "skills",
 
@@ -200,14 +203,15 @@ func TestBuiltInToolsHaveUsageGuidanceOrExplicitExemption(t *testing.T) {
 		ToolGenerateImage(): "image-gen",
 	}
 	exempt := map[ToolName]string{
-		ToolWebSearch():         "self-describing one-shot search tool",
-		ToolWebFetch():          "self-describing one-shot fetch tool",
-		ToolGenerateVideo():     "self-describing media generation tool",
-		ToolTranscribeAudio():   "self-describing media transcription tool",
-		ToolListEmailAccounts(): "email tool descriptions carry account/read/write semantics",
-		ToolSendEmail():         "email tool descriptions carry account/read/write semantics",
-		ToolListEmail():         "email tool descriptions carry account/read/write semantics",
-		ToolReadEmail():         "email tool descriptions carry account/read/write semantics",
+		ToolWebSearch():          "self-describing one-shot search tool",
+		ToolWebFetch():           "self-describing one-shot fetch tool",
+		ToolGenerateVideo():      "self-describing media generation tool",
+		ToolTranscribeAudio():    "self-describing media transcription tool",
+		ToolListEmailAccounts():  "email tool descriptions carry account/read/write semantics",
+		ToolSendEmail():          "email tool descriptions carry account/read/write semantics",
+		ToolListEmail():          "email tool descriptions carry account/read/write semantics",
+		ToolReadEmail():          "email tool descriptions carry account/read/write semantics",
+		ToolCommentGitHubIssue(): "self-describing one-shot comment tool",
 	}
 
 	for _, name := range BuiltInToolNames() {
@@ -726,7 +730,7 @@ func TestSpeakToolPromptMetadataGatesCurrentConversationTarget(t *testing.T) {
 func TestMemoryProviderUsageGatesSearchMemory(t *testing.T) {
 	t.Parallel()
 
-	provider := NewMemoryProvider(nil, nil, nil)
+	provider := NewMemoryProvider(nil, nil, nil, nil)
 	if got := provider.Usage(context.Background(), SessionContext{}, AvailableTools{}); got != "" {
 		t.Fatalf("Usage without search_memory = %q, want empty", got)
 	}
@@ -10,9 +10,11 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	userinput "github.com/memohai/memoh/internal/agent/decision/input"
+	agentevent "github.com/memohai/memoh/internal/agent/event"
 	"github.com/memohai/memoh/internal/agent/turn"
 	"github.com/memohai/memoh/internal/agent/turn/turnpb"
 )
@@ -50,7 +52,7 @@ func (c *Client) StartTurn(ctx context.Context, cmd turn.StartTurnCommand) (turn
 		return nil, err
 	}
 	runCtx, cancel := context.WithCancel(ctx)
-	stream, err := c.client.Run(runCtx)
+	stream, err := c.client.Run(withEventSchema(runCtx))
 	if err != nil {
 		cancel()
 		return nil, mapClientError(err)
@@ -70,7 +72,7 @@ func (c *Client) StartTurn(ctx context.Context, cmd turn.StartTurnCommand) (turn
 		return nil, errors.New("turn rpc: missing started frame")
 	}
 	h := &runHandle{
-		id: started.GetRunId(), stream: stream,
+		id: started.GetRunId(), stream: stream, schema: responseEventSchema(stream.Header),
 		events: make(chan turn.Event, 16), errs: make(chan error, 1),
 		ctx: runCtx, cancel: cancel, done: make(chan struct{}),
 		logger: c.logger,
@@ -84,11 +86,11 @@ func (c *Client) RespondToolApproval(ctx context.Context, input turn.ToolApprova
 	if err != nil {
 		return err
 	}
-	stream, err := c.client.RespondToolApproval(ctx, &turnpb.JsonRequest{Json: data})
+	stream, err := c.client.RespondToolApproval(withEventSchema(ctx), &turnpb.JsonRequest{Json: data})
 	if err != nil {
 		return mapClientError(err)
 	}
-	return receiveContinuation(ctx, stream.Recv, eventCh)
+	return receiveContinuation(ctx, c.logger, stream.Header, stream.Recv, eventCh)
 }
 
 func (c *Client) RespondUserInput(ctx context.Context, input turn.UserInputResponse, eventCh chan<- json.RawMessage) error {
@@ -96,11 +98,11 @@ func (c *Client) RespondUserInput(ctx context.Context, input turn.UserInputRespo
 	if err != nil {
 		return err
 	}
-	stream, err := c.client.RespondUserInput(ctx, &turnpb.JsonRequest{Json: data})
+	stream, err := c.client.RespondUserInput(withEventSchema(ctx), &turnpb.JsonRequest{Json: data})
 	if err != nil {
 		return mapClientError(err)
 	}
-	return receiveContinuation(ctx, stream.Recv, eventCh)
+	return receiveContinuation(ctx, c.logger, stream.Header, stream.Recv, eventCh)
 }
 
 func (c *Client) AdvancePlainTextUserInput(ctx context.Context, input userinput.AdvanceTextInput) (userinput.AdvanceTextResult, error) {
@@ -121,7 +123,19 @@ func (c *Client) AdvancePlainTextUserInput(ctx context.Context, input userinput.
 
 type continuationReceiver func() (*turnpb.EventResponse, error)
 
-func receiveContinuation(ctx context.Context, recv continuationReceiver, eventCh chan<- json.RawMessage) error {
+// responseEventSchema reads the schema version the server confirmed in its
+// response header. A server that predates negotiation sends no header and
+// is treated as legacy.
+func responseEventSchema(header func() (metadata.MD, error)) int {
+	md, err := header()
+	if err != nil {
+		return agentevent.SchemaVersionLegacy
+	}
+	return min(eventSchemaFromMetadata(md), agentevent.CurrentSchemaVersion)
+}
+
+func receiveContinuation(ctx context.Context, log *slog.Logger, header func() (metadata.MD, error), recv continuationReceiver, eventCh chan<- json.RawMessage) error {
+	schema := responseEventSchema(header)
 	for {
 		event, err := recv()
 		if errors.Is(err, io.EOF) {
@@ -130,8 +144,9 @@ func receiveContinuation(ctx context.Context, recv continuationReceiver, eventCh
 		if err != nil {
 			return mapClientError(err)
 		}
+		payload, _ := decodeEventPayload(log, schema, event.GetPayload(), event.GetKind())
 		select {
-		case eventCh <- json.RawMessage(event.GetPayload()):
+		case eventCh <- payload:
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -140,6 +155,7 @@ func receiveContinuation(ctx context.Context, recv continuationReceiver, eventCh
 
 type runHandle struct {
 	id     string
+	schema int
 	stream turnpb.TurnService_RunClient
 	events chan turn.Event
 	errs   chan error
@@ -229,8 +245,10 @@ func (h *runHandle) pump() {
 		if event == nil {
 			continue
 		}
+		decoded := eventFromProto(event)
+		decoded.Payload, decoded.Kind = decodeEventPayload(h.logger, h.schema, event.GetPayload(), event.GetKind())
 		select {
-		case h.events <- eventFromProto(event):
+		case h.events <- decoded:
 		case <-h.ctx.Done():
 			return
 		}
@@ -0,0 +1,81 @@
+package grpctransport
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/memohai/memoh/internal/agent/event"
+)
+
+// EventSchemaMetadataKey carries the stream event schema version. The client
+// sends the highest version it decodes; the server answers in its response
+// header with the version it will emit. Peers that never set the key keep
+// exchanging the flat version 1 payloads.
+const EventSchemaMetadataKey = "x-memoh-event-schema"
+
+// withEventSchema advertises the client's schema version on outgoing calls.
+func withEventSchema(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, EventSchemaMetadataKey, strconv.Itoa(event.CurrentSchemaVersion))
+}
+
+// negotiateEventSchema picks the version the server emits for this call: the
+// lower of what the client accepts and what this binary produces.
+func negotiateEventSchema(ctx context.Context) int {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return min(eventSchemaFromMetadata(md), event.CurrentSchemaVersion)
+}
+
+// eventSchemaFromMetadata reads the schema version from call metadata,
+// defaulting to the legacy version when absent or malformed.
+func eventSchemaFromMetadata(md metadata.MD) int {
+	values := md.Get(EventSchemaMetadataKey)
+	if len(values) == 0 {
+		return event.SchemaVersionLegacy
+	}
+	version, err := strconv.Atoi(values[0])
+	if err != nil || version < event.SchemaVersionLegacy {
+		return event.SchemaVersionLegacy
+	}
+	return version
+}
+
+func eventSchemaHeader(version int) metadata.MD {
+	return metadata.Pairs(EventSchemaMetadataKey, strconv.Itoa(version))
+}
+
+// encodeEventPayload converts a runtime payload into the negotiated wire
+// form. Payloads that cannot be wrapped are sent unchanged; the client shim
+// passes non-envelope payloads through as legacy events.
+func encodeEventPayload(log *slog.Logger, version int, payload json.RawMessage) json.RawMessage {
+	if version < event.SchemaVersionV2 {
+		return payload
+	}
+	wrapped, err := event.EncodeEnvelope(payload)
+	if err != nil {
+		log.Warn("stream event sent without schema envelope", slog.Any("error", err))
+		return payload
+	}
+	return wrapped
+}
+
+// decodeEventPayload is the compatibility shim on the consuming side: it
+// returns the legacy payload and its kind so the channel layer keeps reading
+// the version 1 shape regardless of what was negotiated.
+func decodeEventPayload(log *slog.Logger, version int, payload []byte, kind string) (json.RawMessage, string) {
+	if version < event.SchemaVersionV2 {
+		return json.RawMessage(payload), kind
+	}
+	env, err := event.DecodeEnvelope(payload)
+	if err != nil {
+		log.Warn("stream event envelope decode failed; passing payload through", slog.Any("error", err))
+		return json.RawMessage(payload), kind
+	}
+	if !env.Type.Known() {
+		log.Debug("stream event of unknown type forwarded", slog.String("type", string(env.Type)))
+	}
+	return env.Event, string(env.Type)
+}
@@ -7,10 +7,12 @@ import (
 	"io"
 	"log/slog"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	userinput "github.com/memohai/memoh/internal/agent/decision/input"
+	agentevent "github.com/memohai/memoh/internal/agent/event"
 	"github.com/memohai/memoh/internal/agent/turn"
 	"github.com/memohai/memoh/internal/agent/turn/turnpb"
 )
@@ -54,6 +56,7 @@ func (s *Server) Run(stream turnpb.TurnService_RunServer) error {
 		return s.mapError("start turn", err)
 	}
 	defer handle.Cancel()
+	schema := s.announceEventSchema(stream)
 	if err := stream.Send(&turnpb.RunResponse{Body: &turnpb.RunResponse_Started{Started: &turnpb.Started{RunId: handle.RunID()}}}); err != nil {
 		return err
 	}
@@ -118,7 +121,9 @@ func (s *Server) Run(stream turnpb.TurnService_RunServer) error {
 				events = nil
 				continue
 			}
-			if err := stream.Send(&turnpb.RunResponse{Body: &turnpb.RunResponse_Event{Event: eventToProto(event)}}); err != nil {
+			wire := eventToProto(event)
+			wire.Payload = encodeEventPayload(s.logger, schema, wire.Payload)
+			if err := stream.Send(&turnpb.RunResponse{Body: &turnpb.RunResponse_Event{Event: wire}}); err != nil {
 				return err
 			}
 		case err, ok := <-errs:
@@ -153,7 +158,8 @@ func (s *Server) RespondToolApproval(req *turnpb.JsonRequest, stream turnpb.Turn
 	if err := unmarshalToolApprovalResponse(req.GetJson(), &input); err != nil {
 		return status.Error(codes.InvalidArgument, "invalid tool approval payload")
 	}
-	return s.streamContinuation(stream.Context(), stream.Send, func(ch chan<- json.RawMessage) error {
+	schema := s.announceEventSchema(stream)
+	return s.streamContinuation(stream.Context(), schema, stream.Send, func(ch chan<- json.RawMessage) error {
 		return s.service.RespondToolApproval(stream.Context(), input, ch)
 	})
 }
@@ -163,12 +169,13 @@ func (s *Server) RespondUserInput(req *turnpb.JsonRequest, stream turnpb.TurnSer
 	if err := unmarshalUserInputResponse(req.GetJson(), &input); err != nil {
 		return status.Error(codes.InvalidArgument, "invalid user input payload")
 	}
-	return s.streamContinuation(stream.Context(), stream.Send, func(ch chan<- json.RawMessage) error {
+	schema := s.announceEventSchema(stream)
+	return s.streamContinuation(stream.Context(), schema, stream.Send, func(ch chan<- json.RawMessage) error {
 		return s.service.RespondUserInput(stream.Context(), input, ch)
 	})
 }
 
-func (s *Server) streamContinuation(ctx context.Context, send func(*turnpb.EventResponse) error, run func(chan<- json.RawMessage) error) error {
+func (s *Server) streamContinuation(ctx context.Context, schema int, send func(*turnpb.EventResponse) error, run func(chan<- json.RawMessage) error) error {
 	eventCh := make(chan json.RawMessage, 64)
 	errCh := make(chan error, 1)
 	go func() {
@@ -185,7 +192,7 @@ func (s *Server) streamContinuation(ctx context.Context, send func(*turnpb.Event
 				continue
 			}
 			seq++
-			if err := send(&turnpb.EventResponse{Seq: seq, Kind: kindOf(payload), Payload: payload}); err != nil {
+			if err := send(&turnpb.EventResponse{Seq: seq, Kind: kindOf(payload), Payload: encodeEventPayload(s.logger, schema, payload)}); err != nil {
 				return err
 			}
 		case err := <-errCh:
@@ -206,6 +213,21 @@ func (s *Server) streamContinuation(ctx context.Context, send func(*turnpb.Event
 	return nil
 }
 
+// announceEventSchema negotiates the event schema for one call and confirms
+// it in the response header. When the header cannot be set the client will
+// assume the legacy schema, so the server falls back to it as well.
+func (s *Server) announceEventSchema(stream grpc.ServerStream) int {
+	schema := negotiateEventSchema(stream.Context())
+	if schema < agentevent.SchemaVersionV2 {
+		return schema
+	}
+	if err := stream.SetHeader(eventSchemaHeader(schema)); err != nil {
+		s.logger.Warn("event schema header not set; using legacy events", slog.Any("error", err))
+		return agentevent.SchemaVersionLegacy
+	}
+	return schema
+}
+
 // kindOf extracts the "type" field from a raw event payload, best effort.
 func kindOf(payload json.RawMessage) string {
 	var env struct {
@@ -12,10 +12,12 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/test/bufconn"
 
 	acpfeedback "github.com/memohai/memoh/internal/agent/decision/feedback"
 	userinput "github.com/memohai/memoh/internal/agent/decision/input"
+	agentevent "github.com/memohai/memoh/internal/agent/event"
 	"github.com/memohai/memoh/internal/agent/turn"
 	"github.com/memohai/memoh/internal/agent/turn/turnpb"
 	sessionpkg "github.com/memohai/memoh/internal/chat/thread"
@@ -471,3 +473,139 @@ func TestUnknownControlFrameIgnored(t *testing.T) {
 		t.Fatalf("unexpected run error after unknown frame: %v", err)
 	}
 }
+
+// TestEventSchemaNegotiation pins the compatibility shim: a peer that never
+// advertises a schema keeps receiving flat legacy payloads, while one that
+// asks for v2 gets the envelope and a confirming response header.
+func TestEventSchemaNegotiation(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	server := intrpc.NewServer("secret")
+	turnpb.RegisterTurnServiceServer(server, NewServer(nil, &fakeService{}))
+	go func() { _ = server.Serve(lis) }()
+	defer func() { server.Stop(); _ = lis.Close() }()
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStreamInterceptor(intrpc.StreamClientAuth("secret")),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	raw := turnpb.NewTurnServiceClient(conn)
+	start, err := marshalStartTurnCommand(turn.StartTurnCommand{TeamID: "team-1", ThreadID: "thread-1"})
+	if err != nil {
+		t.Fatalf("marshal start: %v", err)
+	}
+
+	firstEvent := func(ctx context.Context) (*turnpb.EventResponse, metadata.MD) {
+		t.Helper()
+		stream, err := raw.Run(ctx)
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		if err := stream.Send(&turnpb.RunRequest{Body: &turnpb.RunRequest_StartJson{StartJson: start}}); err != nil {
+			t.Fatalf("send start: %v", err)
+		}
+		for {
+			frame, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("recv: %v", err)
+			}
+			if event := frame.GetEvent(); event != nil {
+				header, _ := stream.Header()
+				return event, header
+			}
+		}
+	}
+
+	legacy, header := firstEvent(context.Background())
+	if string(legacy.GetPayload()) != `{"type":"text_delta","text":"hi"}` {
+		t.Fatalf("legacy payload = %s", legacy.GetPayload())
+	}
+	if got := header.Get(EventSchemaMetadataKey); len(got) != 0 {
+		t.Fatalf("legacy peer got schema header %v", got)
+	}
+
+	v2, header := firstEvent(withEventSchema(context.Background()))
+	if got := header.Get(EventSchemaMetadataKey); len(got) != 1 || got[0] != "2" {
+		t.Fatalf("schema header = %v, want [2]", got)
+	}
+	env, err := agentevent.DecodeEnvelope(v2.GetPayload())
+	if err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if env.Type != agentevent.TextDelta || string(env.Event) != `{"type":"text_delta","text":"hi"}` {
+		t.Fatalf("envelope = %#v", env)
+	}
+}
+
+// continuationService emits one event on the tool approval continuation.
+type continuationService struct {
+	fakeService
+}
+
+func (*continuationService) RespondToolApproval(_ context.Context, _ turn.ToolApprovalResponse, ch chan<- json.RawMessage) error {
+	ch <- json.RawMessage(`{"type":"text_delta","text":"hi"}`)
+	return nil
+}
+
+// TestEventSchemaNegotiationOnContinuation pins the same negotiation for the
+// continuation streams that resume a turn after approval or user input.
+func TestEventSchemaNegotiationOnContinuation(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	server := intrpc.NewServer("secret")
+	turnpb.RegisterTurnServiceServer(server, NewServer(nil, &continuationService{}))
+	go func() { _ = server.Serve(lis) }()
+	defer func() { server.Stop(); _ = lis.Close() }()
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStreamInterceptor(intrpc.StreamClientAuth("secret")),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	raw := turnpb.NewTurnServiceClient(conn)
+	req, err := marshalToolApprovalResponse(turn.ToolApprovalResponse{})
+	if err != nil {
+		t.Fatalf("marshal approval: %v", err)
+	}
+
+	firstEvent := func(ctx context.Context) (*turnpb.EventResponse, metadata.MD) {
+		t.Helper()
+		stream, err := raw.RespondToolApproval(ctx, &turnpb.JsonRequest{Json: req})
+		if err != nil {
+			t.Fatalf("respond tool approval: %v", err)
+		}
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		header, _ := stream.Header()
+		return event, header
+	}
+
+	legacy, header := firstEvent(context.Background())
+	if string(legacy.GetPayload()) != `{"type":"text_delta","text":"hi"}` {
+		t.Fatalf("legacy payload = %s", legacy.GetPayload())
+	}
+	if got := header.Get(EventSchemaMetadataKey); len(got) != 0 {
+		t.Fatalf("legacy peer got schema header %v", got)
+	}
+
+	v2, header := firstEvent(withEventSchema(context.Background()))
+	if got := header.Get(EventSchemaMetadataKey); len(got) != 1 || got[0] != "2" {
+		t.Fatalf("schema header = %v, want [2]", got)
+	}
+	env, err := agentevent.DecodeEnvelope(v2.GetPayload())
+	if err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if env.Type != agentevent.TextDelta || string(env.Event) != `{"type":"text_delta","text":"hi"}` {
+		t.Fatalf("envelope = %#v", env)
+	}
+}
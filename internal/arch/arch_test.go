@@ -283,8 +283,11 @@ func TestChannelDoesNotImportEcho(t *testing.T) {
 		"internal/channel/webhook_handler.go":                 "channel-owned webhook HTTP endpoint",
 		"internal/channel/adapters/feishu/webhook_handler.go": "platform webhook endpoint",
 		"internal/channel/adapters/line/adapter.go":           "platform webhook endpoint",
+		"internal/channel/adapters/slack/webhook.go":          "platform webhook endpoint",
+		"internal/channel/adapters/webhook/inbound.go":        "platform webhook endpoint",
 		"internal/channel/adapters/wechatoa/inbound.go":       "platform webhook endpoint",
 		"internal/channel/adapters/weixin/qr_handler.go":      "weixin QR login HTTP endpoint",
+		"internal/channel/adapters/whatsapp/webhook.go":       "platform webhook endpoint",
 	}
 	root := repoRoot(t)
 	for _, file := range goFiles(t, root, "internal/channel") {
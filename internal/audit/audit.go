@@ -0,0 +1,102 @@
+// Package audit records privileged administrative actions. Entries are
+// append-only. Callers that can write inside the transaction performing the
+// action use Record so an action is never committed without its record;
+// handlers use Service.Log after the action succeeded.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionIdentityDataErased   = "identity.data_erased"
+	ActionUserCreated          = "user.created"
+	ActionBotTransferred       = "bot.transferred"
+	ActionChannelConfigUpdated = "channel_config.updated"
+	ActionChannelConfigDeleted = "channel_config.deleted"
+	ActionProviderKeyChanged   = "provider.key_changed"
+	ActionMemoryDeletedAll     = "memory.deleted_all"
+)
+
+// Target types recorded in the audit log.
+const (
+	TargetChannelIdentity = "channel_identity"
+	TargetUser            = "user"
+	TargetBot             = "bot"
+	TargetChannelConfig   = "channel_config"
+	TargetProvider        = "provider"
+)
+
+// Store persists audit entries. *sqlc.Queries satisfies it.
+type Store interface {
+	CreateAuditLog(ctx context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error)
+}
+
+// Entry describes one audited action. Diff holds the fields the action
+// changed; build it with Diff so secrets are redacted.
+type Entry struct {
+	ActorUserID string
+	ActorIP     string
+	Action      string
+	TargetType  string
+	TargetID    string
+	Details     map[string]any
+	Diff        map[string]Change
+}
+
+// Record writes entry and returns the new log entry id.
+func Record(ctx context.Context, store Store, entry Entry) (string, error) {
+	if store == nil {
+		return "", errors.New("audit store not configured")
+	}
+	if strings.TrimSpace(entry.Action) == "" || strings.TrimSpace(entry.TargetType) == "" {
+		return "", errors.New("audit action and target type are required")
+	}
+	var actorID pgtype.UUID
+	if id := strings.TrimSpace(entry.ActorUserID); id != "" {
+		pgID, err := db.ParseUUID(id)
+		if err != nil {
+			return "", fmt.Errorf("invalid audit actor id: %w", err)
+		}
+		actorID = pgID
+	}
+	details := entry.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	payload, err := json.Marshal(details)
+	if err != nil {
+		return "", fmt.Errorf("marshal audit details: %w", err)
+	}
+	diff := entry.Diff
+	if diff == nil {
+		diff = map[string]Change{}
+	}
+	diffPayload, err := json.Marshal(diff)
+	if err != nil {
+		return "", fmt.Errorf("marshal audit diff: %w", err)
+	}
+	row, err := store.CreateAuditLog(ctx, sqlc.CreateAuditLogParams{
+		ActorUserID: actorID,
+		ActorIp:     strings.TrimSpace(entry.ActorIP),
+		Action:      entry.Action,
+		TargetType:  entry.TargetType,
+		TargetID:    strings.TrimSpace(entry.TargetID),
+		Details:     payload,
+		Diff:        diffPayload,
+	})
+	if err != nil {
+		return "", fmt.Errorf("record audit entry: %w", err)
+	}
+	return row.ID.String(), nil
+}
@@ -0,0 +1,199 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	testActorID = "33333333-3333-3333-3333-333333333333"
+	testLogID   = "44444444-4444-4444-4444-444444444444"
+)
+
+type fakeAuditQueries struct {
+	dbstore.Queries
+
+	created []sqlc.CreateAuditLogParams
+	listed  []sqlc.ListAuditLogsParams
+	rows    []sqlc.AuditLog
+	fail    bool
+}
+
+func (f *fakeAuditQueries) CreateAuditLog(_ context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error) {
+	if f.fail {
+		return sqlc.AuditLog{}, errors.New("boom")
+	}
+	f.created = append(f.created, arg)
+	return sqlc.AuditLog{ID: db.ParseUUIDOrEmpty(testLogID)}, nil
+}
+
+func (f *fakeAuditQueries) ListAuditLogs(_ context.Context, arg sqlc.ListAuditLogsParams) ([]sqlc.AuditLog, error) {
+	f.listed = append(f.listed, arg)
+	return f.rows, nil
+}
+
+func TestDiffReportsChangedFieldsAndRedactsSecrets(t *testing.T) {
+	t.Parallel()
+	before := map[string]any{
+		"name":     "openai",
+		"api_key":  "sk-old",
+		"disabled": false,
+		"credentials": map[string]any{
+			"app_id":     "a1",
+			"app_secret": "s1",
+		},
+		"routing": map[string]any{"mode": "a"},
+		"removed": "x",
+	}
+	after := map[string]any{
+		"name":     "openai",
+		"api_key":  "sk-new",
+		"disabled": true,
+		"credentials": map[string]any{
+			"app_id":     "a2",
+			"app_secret": "s1",
+		},
+		"routing": map[string]any{"mode": "b"},
+		"added":   "y",
+	}
+
+	got := Diff(before, after)
+	want := map[string]Change{
+		"api_key":            {From: Redacted, To: Redacted},
+		"disabled":           {From: false, To: true},
+		"credentials.app_id": {From: Redacted, To: Redacted},
+		"routing.mode":       {From: "a", To: "b"},
+		"removed":            {From: "x"},
+		"added":              {To: "y"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Diff() = %+v, want %+v", got, want)
+	}
+	for key, change := range want {
+		if got[key] != change {
+			t.Fatalf("Diff()[%q] = %+v, want %+v", key, got[key], change)
+		}
+	}
+}
+
+func TestDiffRedactsSecretSetForTheFirstTime(t *testing.T) {
+	t.Parallel()
+	got := Diff(map[string]any{"api_key": ""}, map[string]any{"api_key": "sk-new"})
+	if change := got["api_key"]; change.From != "" || change.To != Redacted {
+		t.Fatalf("Diff()[api_key] = %+v, want empty to redacted", change)
+	}
+}
+
+func TestRecordPersistsActorIPAndDiff(t *testing.T) {
+	t.Parallel()
+	store := &fakeAuditQueries{}
+	id, err := Record(context.Background(), store, Entry{
+		ActorUserID: testActorID,
+		ActorIP:     " 10.0.0.1 ",
+		Action:      ActionProviderKeyChanged,
+		TargetType:  TargetProvider,
+		TargetID:    "p1",
+		Diff:        Diff(map[string]any{"api_key": "a"}, map[string]any{"api_key": "b"}),
+	})
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if id != testLogID || len(store.created) != 1 {
+		t.Fatalf("Record() = %q with %d rows", id, len(store.created))
+	}
+	arg := store.created[0]
+	if arg.ActorIp != "10.0.0.1" || arg.ActorUserID.String() != testActorID {
+		t.Fatalf("CreateAuditLog actor = %q/%s", arg.ActorIp, arg.ActorUserID.String())
+	}
+	var diff map[string]Change
+	if err := json.Unmarshal(arg.Diff, &diff); err != nil {
+		t.Fatalf("unmarshal diff: %v", err)
+	}
+	if diff["api_key"].To != Redacted {
+		t.Fatalf("stored diff = %+v, want redacted api_key", diff)
+	}
+	if string(arg.Details) != "{}" {
+		t.Fatalf("details = %s, want {}", arg.Details)
+	}
+}
+
+func TestServiceLogSwallowsStoreErrors(t *testing.T) {
+	t.Parallel()
+	store := &fakeAuditQueries{fail: true}
+	NewService(nil, store).Log(context.Background(), Entry{Action: ActionUserCreated, TargetType: TargetUser})
+
+	var nilService *Service
+	nilService.Log(context.Background(), Entry{Action: ActionUserCreated, TargetType: TargetUser})
+}
+
+func TestServiceListAppliesFilter(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeAuditQueries{rows: []sqlc.AuditLog{{
+		ID:          db.ParseUUIDOrEmpty(testLogID),
+		ActorUserID: db.ParseUUIDOrEmpty(testActorID),
+		ActorIp:     "10.0.0.1",
+		Action:      ActionBotTransferred,
+		TargetType:  TargetBot,
+		TargetID:    "bot-1",
+		Details:     []byte(`{"new_owner_user_id":"u2"}`),
+		Diff:        []byte(`{"owner_user_id":{"from":"u1","to":"u2"}}`),
+		CreatedAt:   pgtype.Timestamptz{Time: created, Valid: true},
+	}}}
+	service := NewService(nil, store)
+
+	since := created.Add(-time.Hour)
+	logs, err := service.List(context.Background(), Filter{
+		Action:      ActionBotTransferred,
+		ActorUserID: testActorID,
+		Since:       since,
+		Limit:       10,
+	})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	params := store.listed[0]
+	if params.Action.String != ActionBotTransferred || !params.Action.Valid || params.TargetType.Valid {
+		t.Fatalf("List() params text filters = %+v", params)
+	}
+	if !params.Since.Valid || !params.Since.Time.Equal(since) || params.Before.Valid || params.RowLimit != 10 {
+		t.Fatalf("List() params = %+v", params)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("List() = %d logs, want 1", len(logs))
+	}
+	got := logs[0]
+	if got.ID != testLogID || got.ActorUserID != testActorID || got.ActorIP != "10.0.0.1" || !got.CreatedAt.Equal(created) {
+		t.Fatalf("List()[0] = %+v", got)
+	}
+	if got.Details["new_owner_user_id"] != "u2" || got.Diff["owner_user_id"].To != "u2" {
+		t.Fatalf("List()[0] payload = %+v / %+v", got.Details, got.Diff)
+	}
+}
+
+func TestServiceListRejectsInvalidFilter(t *testing.T) {
+	t.Parallel()
+	service := NewService(nil, &fakeAuditQueries{})
+	for _, filter := range []Filter{
+		{ActorUserID: "not-a-uuid"},
+		{Limit: -1},
+		{Limit: MaxListLimit + 1},
+	} {
+		if _, err := service.List(context.Background(), filter); !errors.Is(err, ErrInvalidFilter) {
+			t.Fatalf("List(%+v) error = %v, want ErrInvalidFilter", filter, err)
+		}
+	}
+	logs, err := service.List(context.Background(), Filter{})
+	if err != nil || logs == nil {
+		t.Fatalf("List(empty) = %v, %v", logs, err)
+	}
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the value of a secret field in a diff, so the log shows
+// that a key changed without storing it.
+const Redacted = "[redacted]"
+
+// Change is one field's value before and after an action. A nil side means
+// the field was absent.
+type Change struct {
+	From any `json:"from,omitempty"`
+	To   any `json:"to,omitempty"`
+}
+
+// secretFieldMarkers name fields whose values never enter the audit log.
+var secretFieldMarkers = []string{"secret", "token", "password", "passwd", "api_key", "apikey", "private_key", "credential", "encoding_aes_key"}
+
+// Diff returns the fields that differ between before and after. Nested maps
+// are compared field by field under dotted paths; values of secret fields,
+// and of every field nested under one, are replaced with Redacted.
+func Diff(before, after map[string]any) map[string]Change {
+	out := map[string]Change{}
+	diffInto(out, "", false, before, after)
+	return out
+}
+
+func diffInto(out map[string]Change, prefix string, secret bool, before, after map[string]any) {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		from, hadFrom := before[key]
+		to, hadTo := after[key]
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		fieldSecret := secret || isSecretField(key)
+		fromMap, fromIsMap := from.(map[string]any)
+		toMap, toIsMap := to.(map[string]any)
+		if fromIsMap && toIsMap {
+			diffInto(out, path, fieldSecret, fromMap, toMap)
+			continue
+		}
+		if hadFrom && hadTo && reflect.DeepEqual(from, to) {
+			continue
+		}
+		if fieldSecret {
+			from, to = redactPresent(hadFrom, from), redactPresent(hadTo, to)
+		}
+		out[path] = Change{From: from, To: to}
+	}
+}
+
+func redactPresent(present bool, v any) any {
+	if !present || v == nil || v == "" {
+		return v
+	}
+	return Redacted
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range secretFieldMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
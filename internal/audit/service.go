@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+// List limits.
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+// ErrInvalidFilter is returned by List for a malformed filter.
+var ErrInvalidFilter = errors.New("invalid audit filter")
+
+// Log is a recorded audit entry.
+type Log struct {
+	ID          string            `json:"id"`
+	ActorUserID string            `json:"actor_user_id,omitempty"`
+	ActorIP     string            `json:"actor_ip,omitempty"`
+	Action      string            `json:"action"`
+	TargetType  string            `json:"target_type"`
+	TargetID    string            `json:"target_id"`
+	Details     map[string]any    `json:"details"`
+	Diff        map[string]Change `json:"diff"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// Filter narrows List. Empty fields match everything; Before pages back from
+// the oldest entry of the previous page.
+type Filter struct {
+	Action      string
+	TargetType  string
+	TargetID    string
+	ActorUserID string
+	Since       time.Time
+	Before      time.Time
+	Limit       int
+}
+
+type auditQueries interface {
+	Store
+	ListAuditLogs(ctx context.Context, arg sqlc.ListAuditLogsParams) ([]sqlc.AuditLog, error)
+}
+
+// Service writes audit entries for actions that commit outside the log's
+// transaction and queries the log.
+type Service struct {
+	queries dbstore.Queries
+	logger  *slog.Logger
+}
+
+// NewService creates an audit service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "audit")),
+	}
+}
+
+func (s *Service) store() (auditQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("audit queries not configured")
+	}
+	store, ok := s.queries.(auditQueries)
+	if !ok {
+		return nil, errors.New("audit queries not supported")
+	}
+	return store, nil
+}
+
+// Log records entry for an action that already happened. A failure is
+// logged, not returned: the action cannot be rolled back at this point, and
+// the error log keeps the missing record visible.
+func (s *Service) Log(ctx context.Context, entry Entry) {
+	if s == nil {
+		return
+	}
+	store, err := s.store()
+	if err == nil {
+		_, err = Record(context.WithoutCancel(ctx), store, entry)
+	}
+	if err != nil {
+		s.logger.Error("record audit entry failed",
+			slog.String("action", entry.Action),
+			slog.String("target_type", entry.TargetType),
+			slog.String("target_id", entry.TargetID),
+			slog.String("actor_user_id", entry.ActorUserID),
+			slog.Any("error", err))
+	}
+}
+
+// List returns audit entries matching filter, newest first.
+func (s *Service) List(ctx context.Context, filter Filter) ([]Log, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	params := sqlc.ListAuditLogsParams{
+		Action:     optionalText(filter.Action),
+		TargetType: optionalText(filter.TargetType),
+		TargetID:   optionalText(filter.TargetID),
+		RowLimit:   DefaultListLimit,
+	}
+	if id := strings.TrimSpace(filter.ActorUserID); id != "" {
+		pgID, err := db.ParseUUID(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: actor_user_id: %w", ErrInvalidFilter, err)
+		}
+		params.ActorUserID = pgID
+	}
+	if !filter.Since.IsZero() {
+		params.Since = pgtype.Timestamptz{Time: filter.Since, Valid: true}
+	}
+	if !filter.Before.IsZero() {
+		params.Before = pgtype.Timestamptz{Time: filter.Before, Valid: true}
+	}
+	switch {
+	case filter.Limit < 0 || filter.Limit > MaxListLimit:
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, MaxListLimit)
+	case filter.Limit > 0:
+		params.RowLimit = int32(filter.Limit) //nolint:gosec // bounded by MaxListLimit
+	}
+	rows, err := store.ListAuditLogs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]Log, 0, len(rows))
+	for _, row := range rows {
+		logs = append(logs, toLog(row))
+	}
+	return logs, nil
+}
+
+func toLog(row sqlc.AuditLog) Log {
+	entry := Log{
+		ID:         row.ID.String(),
+		ActorIP:    row.ActorIp,
+		Action:     row.Action,
+		TargetType: row.TargetType,
+		TargetID:   row.TargetID,
+		Details:    map[string]any{},
+		Diff:       map[string]Change{},
+	}
+	if row.ActorUserID.Valid {
+		entry.ActorUserID = row.ActorUserID.String()
+	}
+	if row.CreatedAt.Valid {
+		entry.CreatedAt = row.CreatedAt.Time
+	}
+	if len(row.Details) > 0 {
+		_ = json.Unmarshal(row.Details, &entry.Details)
+	}
+	if len(row.Diff) > 0 {
+		_ = json.Unmarshal(row.Diff, &entry.Diff)
+	}
+	return entry
+}
+
+func optionalText(s string) pgtype.Text {
+	s = strings.TrimSpace(s)
+	return pgtype.Text{String: s, Valid: s != ""}
+}
@@ -197,6 +197,24 @@ func ChatTokenFromContext(c echo.Context) (ChatToken, error) {
 	return info, nil
 }
 
+// ParseRequestToken validates the bearer token of a request whose path skips
+// JWTMiddleware and stores it in the context the way the middleware does.
+func ParseRequestToken(c echo.Context, secret string) error {
+	header := strings.TrimSpace(c.Request().Header.Get(echo.HeaderAuthorization))
+	raw, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || strings.TrimSpace(raw) == "" {
+		return echo.NewHTTPError(http.StatusUnauthorized, "missing token")
+	}
+	token, err := jwt.Parse(strings.TrimSpace(raw), func(*jwt.Token) (any, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+	}
+	c.Set("user", token)
+	return nil
+}
+
 // RefreshTokenFromContext extracts the current token from context and issues a new one
 // with the same claims but a renewed expiration time.
 func RefreshTokenFromContext(c echo.Context, secret string, defaultExpiresIn time.Duration) (string, time.Time, error) {
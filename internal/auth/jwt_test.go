@@ -99,6 +99,43 @@ func TestRefreshTokenFromContext_MissingUser(t *testing.T) {
 	assert.Equal(t, "invalid token", httpErr.Message)
 }
 
+func TestParseRequestToken(t *testing.T) {
+	secret := "test-secret"
+	valid, _, err := GenerateToken("user-123", secret, time.Minute)
+	require.NoError(t, err)
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		claimUserID: "user-123",
+		"exp":       time.Now().Add(-time.Minute).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+	forged, _, err := GenerateToken("user-123", "other-secret", time.Minute)
+	require.NoError(t, err)
+
+	e := echo.New()
+	for name, header := range map[string]string{
+		"missing": "",
+		"scheme":  "Basic " + valid,
+		"expired": "Bearer " + expired,
+		"forged":  "Bearer " + forged,
+	} {
+		req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, header)
+		c := e.NewContext(req, httptest.NewRecorder())
+		err := ParseRequestToken(c, secret)
+		httpErr := &echo.HTTPError{}
+		require.ErrorAs(t, err, &httpErr, name)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.Code, name)
+	}
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+valid)
+	c := e.NewContext(req, httptest.NewRecorder())
+	require.NoError(t, ParseRequestToken(c, secret))
+	userID, err := UserIDFromContext(c)
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", userID)
+}
+
 func TestJWTMiddlewareRejectsInactiveAccountSession(t *testing.T) {
 	const secret = "test-secret"
 	token, _, err := GenerateToken("user-123", secret, time.Hour)
@@ -0,0 +1,233 @@
+// Package refresh issues and rotates the long-lived refresh tokens that let
+// clients mint new access tokens without signing in again.
+package refresh
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid refresh token")
+	// ErrTokenReused means an already rotated token was presented again. The
+	// whole token family is revoked, since one of its holders is not the
+	// legitimate client.
+	ErrTokenReused = errors.New("refresh token reused")
+	ErrInvalidUser = errors.New("invalid user id")
+)
+
+// Revocation reasons recorded on revoked tokens.
+const (
+	reasonRotated = "rotated"
+	reasonLogout  = "logout"
+	reasonReused  = "reused"
+	reasonAdmin   = "admin"
+)
+
+const tokenBytes = 32
+
+// Token is a newly issued refresh token. The raw value is only ever returned
+// here; the database keeps its hash.
+type Token struct {
+	Value     string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+type tokenQueries interface {
+	CreateRefreshToken(ctx context.Context, arg sqlc.CreateRefreshTokenParams) (sqlc.AuthRefreshToken, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (sqlc.AuthRefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, arg sqlc.RevokeRefreshTokenParams) (int64, error)
+	RevokeRefreshTokenFamily(ctx context.Context, arg sqlc.RevokeRefreshTokenFamilyParams) (int64, error)
+	RevokeRefreshTokensByUser(ctx context.Context, arg sqlc.RevokeRefreshTokensByUserParams) (int64, error)
+	DeleteExpiredRefreshTokensByUser(ctx context.Context, userID pgtype.UUID) (int64, error)
+}
+
+// Service issues, rotates and revokes refresh tokens.
+type Service struct {
+	queries   dbstore.Queries
+	expiresIn time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// NewService creates a refresh token service whose tokens stay valid for
+// expiresIn after they are issued.
+func NewService(log *slog.Logger, queries dbstore.Queries, expiresIn time.Duration) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries:   queries,
+		expiresIn: expiresIn,
+		logger:    log.With(slog.String("service", "refresh_tokens")),
+		now:       time.Now,
+	}
+}
+
+func (s *Service) store() (tokenQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("refresh token service not configured")
+	}
+	store, ok := s.queries.(tokenQueries)
+	if !ok {
+		return nil, errors.New("refresh token queries not supported by store")
+	}
+	return store, nil
+}
+
+// Issue starts a new token family for the user, typically at login.
+func (s *Service) Issue(ctx context.Context, userID string) (Token, error) {
+	store, err := s.store()
+	if err != nil {
+		return Token{}, err
+	}
+	pgUserID, err := db.ParseUUID(strings.TrimSpace(userID))
+	if err != nil {
+		return Token{}, ErrInvalidUser
+	}
+	if _, err := store.DeleteExpiredRefreshTokensByUser(ctx, pgUserID); err != nil {
+		s.logger.Warn("delete expired refresh tokens failed", slog.String("user_id", userID), slog.Any("error", err))
+	}
+	return s.create(ctx, store, pgUserID, pgtype.UUID{})
+}
+
+// Rotate revokes the presented token and issues its successor in the same
+// family. Unknown, expired and revoked tokens are rejected with
+// ErrInvalidToken; a token that was already rotated is rejected with
+// ErrTokenReused after its family is revoked.
+func (s *Service) Rotate(ctx context.Context, value string) (Token, error) {
+	store, err := s.store()
+	if err != nil {
+		return Token{}, err
+	}
+	row, err := s.lookup(ctx, store, value)
+	if err != nil {
+		return Token{}, err
+	}
+	if row.RevokedAt.Valid {
+		if row.RevokedReason.String != reasonRotated {
+			return Token{}, ErrInvalidToken
+		}
+		if _, err := store.RevokeRefreshTokenFamily(ctx, sqlc.RevokeRefreshTokenFamilyParams{
+			Reason:   pgtype.Text{String: reasonReused, Valid: true},
+			FamilyID: row.FamilyID,
+		}); err != nil {
+			return Token{}, err
+		}
+		s.logger.Warn("rotated refresh token reused; family revoked",
+			slog.String("user_id", row.UserID.String()),
+			slog.String("family_id", row.FamilyID.String()),
+		)
+		return Token{}, ErrTokenReused
+	}
+	if !row.ExpiresAt.Time.After(s.now()) {
+		return Token{}, ErrInvalidToken
+	}
+	n, err := store.RevokeRefreshToken(ctx, sqlc.RevokeRefreshTokenParams{
+		Reason: pgtype.Text{String: reasonRotated, Valid: true},
+		ID:     row.ID,
+	})
+	if err != nil {
+		return Token{}, err
+	}
+	if n == 0 {
+		// A concurrent refresh rotated it first.
+		return Token{}, ErrInvalidToken
+	}
+	return s.create(ctx, store, row.UserID, row.FamilyID)
+}
+
+// Revoke ends the session the token belongs to by revoking its family. Unknown
+// tokens are ignored so that logging out twice is not an error.
+func (s *Service) Revoke(ctx context.Context, value string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	row, err := s.lookup(ctx, store, value)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			return nil
+		}
+		return err
+	}
+	_, err = store.RevokeRefreshTokenFamily(ctx, sqlc.RevokeRefreshTokenFamilyParams{
+		Reason:   pgtype.Text{String: reasonLogout, Valid: true},
+		FamilyID: row.FamilyID,
+	})
+	return err
+}
+
+// RevokeUser revokes every live refresh token of the user and returns how
+// many were revoked. Access tokens already issued stay valid until they
+// expire.
+func (s *Service) RevokeUser(ctx context.Context, userID string) (int64, error) {
+	store, err := s.store()
+	if err != nil {
+		return 0, err
+	}
+	pgUserID, err := db.ParseUUID(strings.TrimSpace(userID))
+	if err != nil {
+		return 0, ErrInvalidUser
+	}
+	return store.RevokeRefreshTokensByUser(ctx, sqlc.RevokeRefreshTokensByUserParams{
+		Reason: pgtype.Text{String: reasonAdmin, Valid: true},
+		UserID: pgUserID,
+	})
+}
+
+func (*Service) lookup(ctx context.Context, store tokenQueries, value string) (sqlc.AuthRefreshToken, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return sqlc.AuthRefreshToken{}, ErrInvalidToken
+	}
+	row, err := store.GetRefreshTokenByHash(ctx, hashToken(value))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.AuthRefreshToken{}, ErrInvalidToken
+		}
+		return sqlc.AuthRefreshToken{}, err
+	}
+	return row, nil
+}
+
+func (s *Service) create(ctx context.Context, store tokenQueries, userID, familyID pgtype.UUID) (Token, error) {
+	if s.expiresIn <= 0 {
+		return Token{}, errors.New("refresh token expiry not configured")
+	}
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return Token{}, err
+	}
+	value := base64.RawURLEncoding.EncodeToString(raw)
+	expiresAt := s.now().UTC().Add(s.expiresIn)
+	if _, err := store.CreateRefreshToken(ctx, sqlc.CreateRefreshTokenParams{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(value),
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	}); err != nil {
+		return Token{}, err
+	}
+	return Token{Value: value, UserID: userID.String(), ExpiresAt: expiresAt}, nil
+}
+
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
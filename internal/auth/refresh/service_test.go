@@ -0,0 +1,184 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const testUserID = "0b7c2f8e-3d4a-4f1b-9c6e-5a2d8e7f1c30"
+
+type fakeTokenQueries struct {
+	dbstore.Queries
+
+	rows   []*sqlc.AuthRefreshToken
+	nextID byte
+}
+
+func (f *fakeTokenQueries) newUUID() pgtype.UUID {
+	f.nextID++
+	return pgtype.UUID{Bytes: [16]byte{15: f.nextID}, Valid: true}
+}
+
+func (f *fakeTokenQueries) CreateRefreshToken(_ context.Context, arg sqlc.CreateRefreshTokenParams) (sqlc.AuthRefreshToken, error) {
+	row := &sqlc.AuthRefreshToken{
+		ID:        f.newUUID(),
+		UserID:    arg.UserID,
+		FamilyID:  arg.FamilyID,
+		TokenHash: arg.TokenHash,
+		ExpiresAt: arg.ExpiresAt,
+	}
+	if !row.FamilyID.Valid {
+		row.FamilyID = f.newUUID()
+	}
+	f.rows = append(f.rows, row)
+	return *row, nil
+}
+
+func (f *fakeTokenQueries) GetRefreshTokenByHash(_ context.Context, tokenHash string) (sqlc.AuthRefreshToken, error) {
+	for _, row := range f.rows {
+		if row.TokenHash == tokenHash {
+			return *row, nil
+		}
+	}
+	return sqlc.AuthRefreshToken{}, pgx.ErrNoRows
+}
+
+func (f *fakeTokenQueries) revoke(match func(*sqlc.AuthRefreshToken) bool, reason pgtype.Text) int64 {
+	var n int64
+	for _, row := range f.rows {
+		if match(row) && !row.RevokedAt.Valid {
+			row.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			row.RevokedReason = reason
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeTokenQueries) RevokeRefreshToken(_ context.Context, arg sqlc.RevokeRefreshTokenParams) (int64, error) {
+	return f.revoke(func(row *sqlc.AuthRefreshToken) bool { return row.ID == arg.ID }, arg.Reason), nil
+}
+
+func (f *fakeTokenQueries) RevokeRefreshTokenFamily(_ context.Context, arg sqlc.RevokeRefreshTokenFamilyParams) (int64, error) {
+	return f.revoke(func(row *sqlc.AuthRefreshToken) bool { return row.FamilyID == arg.FamilyID }, arg.Reason), nil
+}
+
+func (f *fakeTokenQueries) RevokeRefreshTokensByUser(_ context.Context, arg sqlc.RevokeRefreshTokensByUserParams) (int64, error) {
+	return f.revoke(func(row *sqlc.AuthRefreshToken) bool { return row.UserID == arg.UserID }, arg.Reason), nil
+}
+
+func (*fakeTokenQueries) DeleteExpiredRefreshTokensByUser(context.Context, pgtype.UUID) (int64, error) {
+	return 0, nil
+}
+
+func newTestService(queries *fakeTokenQueries) (*Service, *time.Time) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc := NewService(nil, queries, time.Hour)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func TestRotateIssuesSuccessorAndRejectsReuse(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queries := &fakeTokenQueries{}
+	svc, _ := newTestService(queries)
+
+	first, err := svc.Issue(ctx, testUserID)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if first.UserID != testUserID || first.Value == "" {
+		t.Fatalf("Issue = %+v", first)
+	}
+	if queries.rows[0].TokenHash == first.Value {
+		t.Fatal("raw token stored instead of its hash")
+	}
+
+	second, err := svc.Rotate(ctx, first.Value)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if second.Value == first.Value || second.UserID != testUserID {
+		t.Fatalf("Rotate = %+v", second)
+	}
+	if queries.rows[1].FamilyID != queries.rows[0].FamilyID {
+		t.Fatal("successor left the token family")
+	}
+
+	if _, err := svc.Rotate(ctx, first.Value); !errors.Is(err, ErrTokenReused) {
+		t.Fatalf("Rotate reused token error = %v", err)
+	}
+	if _, err := svc.Rotate(ctx, second.Value); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Rotate after reuse error = %v, want family revoked", err)
+	}
+}
+
+func TestRotateRejectsExpiredAndUnknownTokens(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	svc, now := newTestService(&fakeTokenQueries{})
+
+	token, err := svc.Issue(ctx, testUserID)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	*now = now.Add(time.Hour)
+	if _, err := svc.Rotate(ctx, token.Value); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Rotate expired error = %v", err)
+	}
+	for _, value := range []string{"", "unknown"} {
+		if _, err := svc.Rotate(ctx, value); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Rotate(%q) error = %v", value, err)
+		}
+	}
+	if _, err := svc.Issue(ctx, "not-a-uuid"); !errors.Is(err, ErrInvalidUser) {
+		t.Fatalf("Issue bad user error = %v", err)
+	}
+}
+
+func TestRevokeEndsSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queries := &fakeTokenQueries{}
+	svc, _ := newTestService(queries)
+
+	web, _ := svc.Issue(ctx, testUserID)
+	rotated, _ := svc.Rotate(ctx, web.Value)
+	channel, _ := svc.Issue(ctx, testUserID)
+
+	if err := svc.Revoke(ctx, rotated.Value); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := svc.Revoke(ctx, rotated.Value); err != nil {
+		t.Fatalf("Revoke twice: %v", err)
+	}
+	if err := svc.Revoke(ctx, "unknown"); err != nil {
+		t.Fatalf("Revoke unknown: %v", err)
+	}
+	if _, err := svc.Rotate(ctx, rotated.Value); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Rotate after logout error = %v", err)
+	}
+
+	n, err := svc.RevokeUser(ctx, testUserID)
+	if err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("RevokeUser revoked %d tokens, want the other session only", n)
+	}
+	if _, err := svc.Rotate(ctx, channel.Value); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Rotate after revoke-all error = %v", err)
+	}
+}
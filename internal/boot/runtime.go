@@ -14,6 +14,7 @@ import (
 type RuntimeConfig struct {
 	JwtSecret            string `json:"-"`
 	JwtExpiresIn         time.Duration
+	RefreshExpiresIn     time.Duration
 	ServerAddr           string
 	ContainerdSocketPath string
 	ContainerBackend     string // "docker", "containerd", or "apple"
@@ -30,6 +31,14 @@ func ProvideRuntimeConfig(cfg config.Config) (*RuntimeConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid jwt expires in: %w", err)
 	}
+	refreshRaw := strings.TrimSpace(cfg.Auth.RefreshTokenExpiresIn)
+	if refreshRaw == "" {
+		refreshRaw = config.DefaultRefreshTokenExpiresIn
+	}
+	refreshExpiresIn, err := time.ParseDuration(refreshRaw)
+	if err != nil || refreshExpiresIn <= 0 {
+		return nil, fmt.Errorf("invalid refresh token expires in %q", refreshRaw)
+	}
 
 	backend := normalizeContainerBackend(cfg.Container.Backend)
 	if backend == "" {
@@ -48,6 +57,7 @@ func ProvideRuntimeConfig(cfg config.Config) (*RuntimeConfig, error) {
 	ret := &RuntimeConfig{
 		JwtSecret:            cfg.Auth.JWTSecret,
 		JwtExpiresIn:         jwtExpiresIn,
+		RefreshExpiresIn:     refreshExpiresIn,
 		ServerAddr:           cfg.Server.Addr,
 		ContainerdSocketPath: cfg.Containerd.SocketPath,
 		ContainerBackend:     backend,
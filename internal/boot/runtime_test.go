@@ -2,6 +2,7 @@ package boot
 
 import (
 	"testing"
+	"time"
 
 	"github.com/memohai/memoh/internal/config"
 )
@@ -113,3 +114,29 @@ func TestProvideRuntimeConfigBackendIgnoresEnvOverride(t *testing.T) {
 		t.Fatalf("ContainerBackend = %q, want docker", rc.ContainerBackend)
 	}
 }
+
+func TestProvideRuntimeConfigRefreshTokenExpiry(t *testing.T) {
+	cfg := config.Config{
+		Auth: config.AuthConfig{
+			JWTSecret:    "secret",
+			JWTExpiresIn: "24h",
+		},
+		Timezone: config.DefaultTimezone,
+		Container: config.ContainerConfig{
+			Backend: "docker",
+		},
+	}
+	rc, err := ProvideRuntimeConfig(cfg)
+	if err != nil {
+		t.Fatalf("ProvideRuntimeConfig returned error: %v", err)
+	}
+	if rc.RefreshExpiresIn != 720*time.Hour {
+		t.Fatalf("RefreshExpiresIn = %s, want the default", rc.RefreshExpiresIn)
+	}
+	for _, raw := range []string{"soon", "0s", "-1h"} {
+		cfg.Auth.RefreshTokenExpiresIn = raw
+		if _, err := ProvideRuntimeConfig(cfg); err == nil {
+			t.Fatalf("refresh_token_expires_in %q accepted", raw)
+		}
+	}
+}
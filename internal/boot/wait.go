@@ -0,0 +1,52 @@
+package boot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	waitInitialBackoff = 500 * time.Millisecond
+	waitMaxBackoff     = 5 * time.Second
+)
+
+// WaitFor calls probe until it succeeds, backing off from half a second up
+// to five seconds between tries. It gives up with the last error once
+// budget has passed, so a dependency that starts a little after the server
+// (a docker compose race) does not fail startup. A budget of zero or less
+// probes once.
+func WaitFor(ctx context.Context, log *slog.Logger, name string, budget time.Duration, probe func(context.Context) error) error {
+	deadline := time.Now().Add(budget)
+	backoff := waitInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := probe(ctx)
+		if err == nil {
+			if attempt > 1 && log != nil {
+				log.Info("startup dependency ready", slog.String("dependency", name), slog.Int("attempts", attempt))
+			}
+			return nil
+		}
+		wait := min(backoff, time.Until(deadline))
+		if wait <= 0 {
+			return fmt.Errorf("%s not reachable after %d attempts: %w", name, attempt, err)
+		}
+		if log != nil {
+			log.Warn("startup dependency not ready, retrying",
+				slog.String("dependency", name),
+				slog.Int("attempt", attempt),
+				slog.Duration("retry_in", wait),
+				slog.Any("error", err),
+			)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s: %w", name, ctx.Err())
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, waitMaxBackoff)
+	}
+}
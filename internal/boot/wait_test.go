@@ -0,0 +1,44 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForRetriesUntilReady(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	err := WaitFor(context.Background(), nil, "postgres", 5*time.Second, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WaitFor = %v, want nil", err)
+	}
+	if calls != 3 {
+		t.Fatalf("probe called %d times, want 3", calls)
+	}
+}
+
+func TestWaitForGivesUpAfterBudget(t *testing.T) {
+	t.Parallel()
+
+	refused := errors.New("connection refused")
+	calls := 0
+	err := WaitFor(context.Background(), nil, "pgvector", 0, func(context.Context) error {
+		calls++
+		return refused
+	})
+	if !errors.Is(err, refused) {
+		t.Fatalf("WaitFor = %v, want wrapped probe error", err)
+	}
+	if calls != 1 {
+		t.Fatalf("probe called %d times with no budget, want 1", calls)
+	}
+}
@@ -27,6 +27,7 @@ type Service struct {
 	queries               dbstore.Queries
 	logger                *slog.Logger
 	containerLifecycle    ContainerLifecycle
+	dataCleaners          []DataCleaner
 	checkers              []RuntimeChecker
 	containerReachability func(ctx context.Context, botID string) error
 }
@@ -66,6 +67,13 @@ func (s *Service) SetContainerReachability(fn func(ctx context.Context, botID st
 	s.containerReachability = fn
 }
 
+// AddDataCleaner registers a cleaner that runs while a bot is deleted.
+func (s *Service) AddDataCleaner(c DataCleaner) {
+	if c != nil {
+		s.dataCleaners = append(s.dataCleaners, c)
+	}
+}
+
 // AddRuntimeChecker registers an additional runtime checker.
 func (s *Service) AddRuntimeChecker(c RuntimeChecker) {
 	if c != nil {
@@ -606,6 +614,16 @@ func (s *Service) enqueueDeleteLifecycle(ctx context.Context, botID string) {
 				)
 			}
 		}
+		// Cleaners run before the bot row goes away: they may need the bot's
+		// settings to find where its data lives.
+		for _, cleaner := range s.dataCleaners {
+			if err := cleaner.CleanupBotData(lifecycleCtx, botID); err != nil {
+				s.logger.Error("bot data cleanup failed",
+					slog.String("bot_id", botID),
+					slog.Any("error", err),
+				)
+			}
+		}
 
 		botUUID, err := db.ParseUUID(botID)
 		if err != nil {
@@ -88,6 +88,13 @@ type ContainerLifecycle interface {
 	CleanupBotContainer(ctx context.Context, botID string, preserveData bool) error
 }
 
+// DataCleaner removes bot data the database does not cascade, such as
+// memories and vector embeddings held by memory providers, when the bot is
+// deleted.
+type DataCleaner interface {
+	CleanupBotData(ctx context.Context, botID string) error
+}
+
 // RuntimeChecker produces runtime check items for a bot.
 type RuntimeChecker interface {
 	// ListChecks evaluates dynamic runtime checks for a bot.
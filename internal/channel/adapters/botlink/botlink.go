@@ -0,0 +1,318 @@
+package botlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// hopWindow bounds how long a received hop count is carried onto replies.
+// A message sent after the window starts a fresh exchange at hop 1.
+const hopWindow = 10 * time.Minute
+
+// Adapter delivers messages between bots of the same deployment. Each bot's
+// connection registers a peer; Send hands the message straight to the target
+// peer's inbound handler, so it flows through the normal processor.
+type Adapter struct {
+	logger *slog.Logger
+	mu     sync.RWMutex
+	peers  map[string]*peer // keyed by bot ID
+	hops   map[hopKey]hopRecord
+	now    func() time.Time
+}
+
+type peer struct {
+	ctx     context.Context
+	cfg     channel.ChannelConfig
+	handler channel.InboundHandler
+}
+
+// hopKey identifies the direction receiver <- sender.
+type hopKey struct {
+	receiver string
+	sender   string
+}
+
+type hopRecord struct {
+	hops int
+	at   time.Time
+}
+
+// NewAdapter creates a bot-to-bot Adapter with the given logger.
+func NewAdapter(log *slog.Logger) *Adapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Adapter{
+		logger: log.With(slog.String("adapter", "botlink")),
+		peers:  make(map[string]*peer),
+		hops:   make(map[hopKey]hopRecord),
+		now:    time.Now,
+	}
+}
+
+// Type returns the bot-to-bot channel type.
+func (*Adapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the bot-to-bot channel metadata.
+func (*Adapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Bot",
+		Capabilities: channel.ChannelCapabilities{
+			Text:           true,
+			Markdown:       true,
+			BlockStreaming: true,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"contacts": {
+					Type:        channel.FieldString,
+					Title:       "Bot Contacts",
+					Description: "Comma-separated IDs of bots this bot may exchange messages with. Both bots must list each other.",
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"bot_id": {Type: channel.FieldString, Required: true},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "bot_id",
+			Hints: []channel.TargetHint{
+				{Label: "Bot ID", Example: "8c1f0e7a-2b3d-4c5e-9f60-7a8b9c0d1e2f"},
+			},
+		},
+	}
+}
+
+// --- ConfigNormalizer ---
+
+// NormalizeConfig normalizes the contact list of a bot channel configuration.
+func (*Adapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a peer-bot binding configuration.
+func (*Adapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// --- TargetResolver ---
+
+// NormalizeTarget strips an optional "bot:" prefix from a target bot ID.
+func (*Adapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives the target bot ID from a peer-bot binding configuration.
+func (*Adapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// --- BindingMatcher ---
+
+// MatchBinding reports whether a peer-bot binding matches the given criteria.
+func (*Adapter) MatchBinding(config map[string]any, criteria channel.BindingCriteria) bool {
+	return matchBinding(config, criteria)
+}
+
+// BuildUserConfig constructs a peer-bot binding config from an Identity.
+func (*Adapter) BuildUserConfig(identity channel.Identity) map[string]any {
+	return buildUserConfig(identity)
+}
+
+// --- Receiver ---
+
+// Connect registers the bot as a reachable peer until the connection stops.
+func (a *Adapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
+	botID := strings.TrimSpace(cfg.BotID)
+	if botID == "" {
+		return nil, errors.New("bot channel requires bot id")
+	}
+	a.logger.Info("start", slog.String("config_id", cfg.ID), slog.String("bot_id", botID))
+	connCtx, cancel := context.WithCancel(ctx)
+	p := &peer{ctx: connCtx, cfg: cfg, handler: handler}
+	a.mu.Lock()
+	a.peers[botID] = p
+	a.mu.Unlock()
+
+	stop := func(_ context.Context) error {
+		a.logger.Info("stop", slog.String("config_id", cfg.ID), slog.String("bot_id", botID))
+		a.mu.Lock()
+		if a.peers[botID] == p {
+			delete(a.peers, botID)
+		}
+		a.mu.Unlock()
+		cancel()
+		return nil
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+// --- Sender ---
+
+// Send delivers a message to the target bot's inbound handler. Both bots
+// must list each other as contacts and the target must be connected.
+func (a *Adapter) Send(_ context.Context, cfg channel.ChannelConfig, msg channel.PreparedOutboundMessage) error {
+	senderID := strings.TrimSpace(cfg.BotID)
+	targetID := normalizeTarget(msg.Target)
+	if targetID == "" {
+		return errors.New("bot target is required")
+	}
+	if targetID == senderID {
+		return errors.New("bot cannot message itself")
+	}
+	text := strings.TrimSpace(msg.Message.Message.PlainText())
+	if text == "" {
+		return errors.New("message text is required")
+	}
+	if !parseConfig(cfg.Credentials).hasContact(targetID) {
+		return fmt.Errorf("bot %s is not a contact", targetID)
+	}
+
+	a.mu.Lock()
+	target, ok := a.peers[targetID]
+	if !ok {
+		a.mu.Unlock()
+		return fmt.Errorf("bot %s is not connected", targetID)
+	}
+	if !parseConfig(target.cfg.Credentials).hasContact(senderID) {
+		a.mu.Unlock()
+		return fmt.Errorf("bot %s does not accept messages from bot %s", targetID, senderID)
+	}
+	now := a.now()
+	hops := 1
+	if rec, found := a.hops[hopKey{receiver: senderID, sender: targetID}]; found && now.Sub(rec.at) <= hopWindow {
+		hops = rec.hops + 1
+	}
+	a.hops[hopKey{receiver: targetID, sender: senderID}] = hopRecord{hops: hops, at: now}
+	a.pruneHopsLocked(now)
+	a.mu.Unlock()
+
+	inbound := channel.InboundMessage{
+		Channel: Type,
+		Message: channel.Message{
+			ID:     uuid.NewString(),
+			Format: msg.Message.Message.Format,
+			Text:   text,
+		},
+		BotID:       targetID,
+		ReplyTarget: senderID,
+		Sender: channel.Identity{
+			SubjectID:   senderID,
+			DisplayName: senderDisplayName(cfg),
+			Attributes:  map[string]string{"bot_id": senderID},
+		},
+		Conversation: channel.Conversation{
+			ID:   senderID,
+			Type: channel.ConversationTypePrivate,
+		},
+		ReceivedAt: now.UTC(),
+		Source:     string(Type),
+		Metadata:   map[string]any{channel.MetadataKeyHopCount: hops},
+	}
+	// Deliver asynchronously so the sender's reply pipeline never waits on,
+	// or deadlocks with, the receiver's turn.
+	go func() {
+		if err := target.handler(target.ctx, target.cfg, inbound); err != nil {
+			a.logger.Error("deliver failed",
+				slog.String("from_bot_id", senderID),
+				slog.String("to_bot_id", targetID),
+				slog.Any("error", err),
+			)
+		}
+	}()
+	return nil
+}
+
+func (a *Adapter) pruneHopsLocked(now time.Time) {
+	for key, rec := range a.hops {
+		if now.Sub(rec.at) > hopWindow {
+			delete(a.hops, key)
+		}
+	}
+}
+
+func senderDisplayName(cfg channel.ChannelConfig) string {
+	if name, ok := cfg.SelfIdentity["name"].(string); ok && strings.TrimSpace(name) != "" {
+		return strings.TrimSpace(name)
+	}
+	return strings.TrimSpace(cfg.BotID)
+}
+
+// --- StreamSender (block-streaming: buffer deltas, send final as one message) ---
+
+// OpenStream opens a block-streaming session that buffers all deltas and
+// delivers the final text as one message when the stream is closed.
+func (a *Adapter) OpenStream(_ context.Context, cfg channel.ChannelConfig, target string, _ channel.StreamOptions) (channel.PreparedOutboundStream, error) {
+	target = normalizeTarget(target)
+	if target == "" {
+		return nil, errors.New("bot target is required")
+	}
+	return &blockStream{adapter: a, cfg: cfg, target: target}, nil
+}
+
+// blockStream buffers streaming deltas and sends the final message as one
+// Send call when the stream is closed.
+type blockStream struct {
+	adapter     *Adapter
+	cfg         channel.ChannelConfig
+	target      string
+	textBuilder strings.Builder
+	final       *channel.PreparedMessage
+	closed      bool
+}
+
+func (s *blockStream) Push(_ context.Context, event channel.PreparedStreamEvent) error {
+	if s.closed {
+		return nil
+	}
+	switch event.Type {
+	case channel.StreamEventDelta:
+		if strings.TrimSpace(event.Delta) != "" && event.Phase != channel.StreamPhaseReasoning {
+			s.textBuilder.WriteString(event.Delta)
+		}
+	case channel.StreamEventFinal:
+		if event.Final != nil {
+			msg := event.Final.Message
+			s.final = &msg
+		}
+	}
+	return nil
+}
+
+func (s *blockStream) Close(ctx context.Context) error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	prepared := channel.PreparedMessage{Message: channel.Message{Format: channel.MessageFormatPlain}}
+	if s.final != nil {
+		prepared = *s.final
+	}
+	if strings.TrimSpace(prepared.Message.Text) == "" {
+		prepared.Message.Text = strings.TrimSpace(s.textBuilder.String())
+	}
+	if strings.TrimSpace(prepared.Message.PlainText()) == "" {
+		return nil
+	}
+	return s.adapter.Send(ctx, s.cfg, channel.PreparedOutboundMessage{
+		Target:  s.target,
+		Message: prepared,
+	})
+}
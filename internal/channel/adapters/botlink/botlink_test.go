@@ -0,0 +1,107 @@
+package botlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func connectPeer(t *testing.T, adapter *Adapter, botID, contacts string) (channel.ChannelConfig, <-chan channel.InboundMessage) {
+	t.Helper()
+	cfg := channel.ChannelConfig{
+		ID:           "cfg-" + botID,
+		BotID:        botID,
+		ChannelType:  Type,
+		Credentials:  map[string]any{"contacts": contacts},
+		SelfIdentity: map[string]any{"name": "Bot " + botID},
+	}
+	received := make(chan channel.InboundMessage, 4)
+	conn, err := adapter.Connect(context.Background(), cfg, func(_ context.Context, _ channel.ChannelConfig, msg channel.InboundMessage) error {
+		received <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Stop(context.Background()) })
+	return cfg, received
+}
+
+func receive(t *testing.T, ch <-chan channel.InboundMessage) channel.InboundMessage {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered")
+		return channel.InboundMessage{}
+	}
+}
+
+func textMessage(target, text string) channel.PreparedOutboundMessage {
+	return channel.PreparedOutboundMessage{
+		Target:  target,
+		Message: channel.PreparedMessage{Message: channel.Message{Text: text}},
+	}
+}
+
+func TestSendDeliversAndCountsHops(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewAdapter(nil)
+	cfgA, inboxA := connectPeer(t, adapter, "bot-a", "bot-b")
+	cfgB, inboxB := connectPeer(t, adapter, "bot-b", "bot:bot-a")
+
+	if err := adapter.Send(context.Background(), cfgA, textMessage("bot-b", "hello")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	first := receive(t, inboxB)
+	if first.BotID != "bot-b" || first.ReplyTarget != "bot-a" || first.Message.Text != "hello" {
+		t.Fatalf("unexpected delivery: %+v", first)
+	}
+	if first.Sender.DisplayName != "Bot bot-a" || first.Sender.Attribute("bot_id") != "bot-a" {
+		t.Fatalf("unexpected sender: %+v", first.Sender)
+	}
+	if got := channel.HopCount(first); got != 1 {
+		t.Fatalf("first hop = %d, want 1", got)
+	}
+
+	if err := adapter.Send(context.Background(), cfgB, textMessage(first.ReplyTarget, "hi back")); err != nil {
+		t.Fatalf("reply Send: %v", err)
+	}
+	if got := channel.HopCount(receive(t, inboxA)); got != 2 {
+		t.Fatalf("reply hop = %d, want 2", got)
+	}
+}
+
+func TestSendRequiresMutualContacts(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewAdapter(nil)
+	cfgA, _ := connectPeer(t, adapter, "bot-a", "bot-b")
+	cfgB, _ := connectPeer(t, adapter, "bot-b", "")
+
+	if err := adapter.Send(context.Background(), cfgA, textMessage("bot-b", "hello")); err == nil {
+		t.Fatal("expected error when target does not list sender")
+	}
+	if err := adapter.Send(context.Background(), cfgB, textMessage("bot-a", "hello")); err == nil {
+		t.Fatal("expected error when sender does not list target")
+	}
+	if err := adapter.Send(context.Background(), cfgA, textMessage("bot-c", "hello")); err == nil {
+		t.Fatal("expected error for unknown bot")
+	}
+}
+
+func TestNormalizeConfigDedupesContacts(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{"contacts": " bot-a, bot:bot-b\nbot-a "})
+	if err != nil {
+		t.Fatalf("normalizeConfig: %v", err)
+	}
+	if got["contacts"] != "bot-a,bot-b" {
+		t.Fatalf("contacts = %q", got["contacts"])
+	}
+}
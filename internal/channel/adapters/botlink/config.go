@@ -0,0 +1,108 @@
+package botlink
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Config is a bot's botlink channel configuration.
+type Config struct {
+	// Contacts lists the bot IDs this bot exchanges messages with. Delivery
+	// needs both sides to list each other.
+	Contacts []string
+}
+
+// UserConfig identifies a peer bot.
+type UserConfig struct {
+	BotID string
+}
+
+func (c Config) hasContact(botID string) bool {
+	return slices.Contains(c.Contacts, botID)
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg := parseConfig(raw)
+	return map[string]any{"contacts": strings.Join(cfg.Contacts, ",")}, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"bot_id": cfg.BotID}, nil
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	return cfg.BotID, nil
+}
+
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, string(Type)+":")
+	return strings.TrimSpace(value)
+}
+
+func matchBinding(raw map[string]any, criteria channel.BindingCriteria) bool {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return false
+	}
+	if value := criteria.Attribute("bot_id"); value != "" && value == cfg.BotID {
+		return true
+	}
+	return criteria.SubjectID != "" && criteria.SubjectID == cfg.BotID
+}
+
+func buildUserConfig(identity channel.Identity) map[string]any {
+	botID := identity.Attribute("bot_id")
+	if botID == "" {
+		botID = strings.TrimSpace(identity.SubjectID)
+	}
+	if botID == "" {
+		return map[string]any{}
+	}
+	return map[string]any{"bot_id": botID}
+}
+
+// parseConfig accepts contacts as a comma- or whitespace-separated string or
+// a list, and drops blanks and duplicates.
+func parseConfig(raw map[string]any) Config {
+	var values []string
+	switch v := raw["contacts"].(type) {
+	case string:
+		values = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' || r == '\t' })
+	case []string:
+		values = v
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	cfg := Config{}
+	for _, value := range values {
+		value = normalizeTarget(value)
+		if value != "" && !cfg.hasContact(value) {
+			cfg.Contacts = append(cfg.Contacts, value)
+		}
+	}
+	return cfg
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	botID := normalizeTarget(channel.ReadString(raw, "bot_id", "botId"))
+	if botID == "" {
+		return UserConfig{}, errors.New("bot user config requires bot_id")
+	}
+	return UserConfig{BotID: botID}, nil
+}
@@ -0,0 +1,8 @@
+// Package botlink implements the internal channel that lets two bots in the
+// same deployment message each other through the normal channel pipeline.
+package botlink
+
+import "github.com/memohai/memoh/internal/channel"
+
+// Type is the registered ChannelType identifier for bot-to-bot messaging.
+const Type channel.ChannelType = "bot"
@@ -27,6 +27,7 @@ const (
 	discordMaxURLActionLabel   = 80
 	discordMaxURLActionURL     = 512
 	discordMaxAllowedMentions  = 100
+	discordMaxStickers         = 3
 )
 
 // assetOpener reads stored asset bytes by content hash.
@@ -81,6 +82,9 @@ func (*DiscordAdapter) Descriptor() channel.Descriptor {
 			Streaming:      true,
 			BlockStreaming: true,
 			Reactions:      true,
+			Stickers:       true,
+			Edit:           true,
+			Unsend:         true,
 		},
 		ConfigSchema: channel.ConfigSchema{
 			Version: 1,
@@ -336,6 +340,10 @@ func sendDiscordMessage(ctx context.Context, session *discordgo.Session, channel
 	if len(msg.Message.Attachments) > 0 {
 		files := make([]*discordgo.File, 0, len(msg.Message.Attachments))
 		for _, att := range msg.Message.Attachments {
+			if isDiscordSticker(att) {
+				messageSend.StickerIDs = append(messageSend.StickerIDs, strings.TrimSpace(att.NativeRef))
+				continue
+			}
 			file, err := discordPreparedAttachmentToFile(ctx, att)
 			if err != nil {
 				return err
@@ -350,8 +358,8 @@ func sendDiscordMessage(ctx context.Context, session *discordgo.Session, channel
 		}
 	}
 
-	// Validate: must have content or files
-	if messageSend.Content == "" && len(messageSend.Files) == 0 && len(messageSend.Components) == 0 {
+	// Validate: must have content, files or stickers
+	if messageSend.Content == "" && len(messageSend.Files) == 0 && len(messageSend.StickerIDs) == 0 && len(messageSend.Components) == 0 {
 		return errors.New("cannot send empty message: no content and no valid attachments")
 	}
 
@@ -374,7 +382,15 @@ func validateDiscordPreparedOutbound(msg channel.PreparedOutboundMessage) error
 		}
 	}
 	if len(msg.Message.Attachments) > 0 {
+		stickers := 0
 		for _, att := range msg.Message.Attachments {
+			if isDiscordSticker(att) {
+				stickers++
+				continue
+			}
+			if att.Logical.Type == channel.AttachmentSticker {
+				return errors.New("discord sticker requires a sticker id")
+			}
 			if att.Kind != channel.PreparedAttachmentUpload {
 				return fmt.Errorf("discord attachment requires upload source, got %s", att.Kind)
 			}
@@ -382,6 +398,9 @@ func validateDiscordPreparedOutbound(msg channel.PreparedOutboundMessage) error
 				return errors.New("discord attachment upload is not openable")
 			}
 		}
+		if stickers > discordMaxStickers {
+			return fmt.Errorf("discord messages support at most %d stickers", discordMaxStickers)
+		}
 		return nil
 	}
 	if content == "" && len(components) == 0 {
@@ -490,6 +509,14 @@ func truncateDiscordTextRunes(text string, limit int) string {
 }
 
 // discordPreparedAttachmentToFile converts a prepared attachment to discordgo.File.
+// isDiscordSticker reports whether att is a guild or standard sticker sent
+// by id rather than uploaded.
+func isDiscordSticker(att channel.PreparedAttachment) bool {
+	return att.Logical.Type == channel.AttachmentSticker &&
+		att.Kind == channel.PreparedAttachmentNativeRef &&
+		strings.TrimSpace(att.NativeRef) != ""
+}
+
 func discordPreparedAttachmentToFile(ctx context.Context, att channel.PreparedAttachment) (*discordgo.File, error) {
 	// Get file name
 	name := att.Name
@@ -606,6 +633,70 @@ func (*DiscordAdapter) ProcessingFailed(_ context.Context, _ channel.ChannelConf
 	return nil
 }
 
+// Update edits an already-sent message in place (text + URL buttons),
+// satisfying channel.MessageEditor. Passing empty Actions removes the buttons;
+// attachments of the original message are left untouched.
+func (a *DiscordAdapter) Update(_ context.Context, cfg channel.ChannelConfig, target string, messageID string, msg channel.PreparedMessage) error {
+	discordCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	session, err := a.getOrCreateSession(discordCfg.BotToken, cfg.ID)
+	if err != nil {
+		return err
+	}
+	edit, err := buildDiscordMessageEdit(target, messageID, msg.Message)
+	if err != nil {
+		return err
+	}
+	_, err = session.ChannelMessageEditComplex(edit)
+	return err
+}
+
+// Unsend deletes a previously-sent message, satisfying channel.MessageEditor.
+func (a *DiscordAdapter) Unsend(_ context.Context, cfg channel.ChannelConfig, target string, messageID string) error {
+	discordCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	session, err := a.getOrCreateSession(discordCfg.BotToken, cfg.ID)
+	if err != nil {
+		return err
+	}
+	channelID, messageID := strings.TrimSpace(target), strings.TrimSpace(messageID)
+	if channelID == "" || messageID == "" {
+		return errors.New("discord target and message id are required")
+	}
+	return session.ChannelMessageDelete(channelID, messageID)
+}
+
+func buildDiscordMessageEdit(target, messageID string, msg channel.Message) (*discordgo.MessageEdit, error) {
+	channelID, messageID := strings.TrimSpace(target), strings.TrimSpace(messageID)
+	if channelID == "" || messageID == "" {
+		return nil, errors.New("discord target and message id are required")
+	}
+	body := renderDiscordMessagePartsContent(msg)
+	if body == "" {
+		body = msg.Text
+	}
+	content := truncateDiscordText(body)
+	if content == "" {
+		return nil, errors.New("cannot edit discord message to empty content")
+	}
+	components, err := discordURLActionComponents(msg.Actions)
+	if err != nil {
+		return nil, err
+	}
+	if components == nil {
+		components = []discordgo.MessageComponent{}
+	}
+	edit := discordgo.NewMessageEdit(channelID, messageID)
+	edit.SetContent(content)
+	edit.Components = &components
+	edit.AllowedMentions = discordAllowedMentionsForMessage(msg)
+	return edit, nil
+}
+
 func (a *DiscordAdapter) React(_ context.Context, cfg channel.ChannelConfig, target string, messageID string, emoji string) error {
 	discordCfg, err := parseConfig(cfg.Credentials)
 	if err != nil {
@@ -67,6 +67,42 @@ func TestDiscordDescriptorAdvertisesURLButtonsOnly(t *testing.T) {
 	}
 }
 
+func TestDiscordDescriptorAdvertisesEditAndUnsend(t *testing.T) {
+	t.Parallel()
+
+	caps := (&DiscordAdapter{}).Descriptor().Capabilities
+	if !caps.Edit || !caps.Unsend {
+		t.Fatalf("Discord descriptor must advertise edit and unsend, got edit=%v unsend=%v", caps.Edit, caps.Unsend)
+	}
+	var _ channel.MessageEditor = (*DiscordAdapter)(nil)
+}
+
+func TestBuildDiscordMessageEditClearsComponentsWithoutActions(t *testing.T) {
+	t.Parallel()
+
+	edit, err := buildDiscordMessageEdit(" ch-1 ", "msg-1", channel.Message{Text: "Updated"})
+	if err != nil {
+		t.Fatalf("buildDiscordMessageEdit: %v", err)
+	}
+	if edit.Channel != "ch-1" || edit.ID != "msg-1" || edit.Content == nil || *edit.Content != "Updated" {
+		t.Fatalf("unexpected edit: %+v", edit)
+	}
+	data, err := json.Marshal(edit)
+	if err != nil {
+		t.Fatalf("marshal edit: %v", err)
+	}
+	if !strings.Contains(string(data), `"components":[]`) {
+		t.Fatalf("edit without actions must clear components, got %s", data)
+	}
+
+	if _, err := buildDiscordMessageEdit("ch-1", "", channel.Message{Text: "x"}); err == nil {
+		t.Fatal("expected error for missing message id")
+	}
+	if _, err := buildDiscordMessageEdit("ch-1", "msg-1", channel.Message{}); err == nil {
+		t.Fatal("expected error for empty content")
+	}
+}
+
 func TestMimeExtension(t *testing.T) {
 	tests := []struct {
 		mime string
@@ -190,7 +190,13 @@ func normalizeInboundMode(raw string) (string, error) {
 	}
 }
 
+// openBaseURLForTest points the open API at a test server.
+var openBaseURLForTest string
+
 func (c Config) openBaseURL() string {
+	if openBaseURLForTest != "" {
+		return openBaseURLForTest
+	}
 	if c.Region == regionLark {
 		return lark.LarkBaseUrl
 	}
@@ -20,6 +20,7 @@ import (
 	"github.com/memohai/memoh/internal/channel"
 	"github.com/memohai/memoh/internal/channel/adapters/feishu/wsclient"
 	"github.com/memohai/memoh/internal/channel/common"
+	"github.com/memohai/memoh/internal/channel/webhookauth"
 	"github.com/memohai/memoh/internal/media"
 )
 
@@ -29,8 +30,9 @@ type assetOpener interface {
 
 // FeishuAdapter implements the channel.Adapter, channel.Sender, and channel.Receiver interfaces for Feishu.
 type FeishuAdapter struct {
-	logger *slog.Logger
-	assets assetOpener
+	logger         *slog.Logger
+	assets         assetOpener
+	webhookReplays *webhookauth.ReplayGuard
 }
 
 const processingBusyReactionType = "Typing"
@@ -108,7 +110,8 @@ func NewFeishuAdapter(log *slog.Logger) *FeishuAdapter {
 		log = slog.Default()
 	}
 	return &FeishuAdapter{
-		logger: log.With(slog.String("adapter", "feishu")),
+		logger:         log.With(slog.String("adapter", "feishu")),
+		webhookReplays: webhookauth.NewReplayGuard(webhookauth.DefaultMaxSkew),
 	}
 }
 
@@ -134,6 +137,7 @@ func (*FeishuAdapter) Descriptor() channel.Descriptor {
 			Attachments:    true,
 			Media:          true,
 			Reactions:      true,
+			LinkPreviews:   true,
 			Reply:          true,
 			Streaming:      true,
 			BlockStreaming: true,
@@ -556,6 +560,7 @@ func (a *FeishuAdapter) buildEventDispatcher(
 	eventDispatcher.OnP2MessageReactionDeletedV1(func(_ context.Context, _ *larkim.P2MessageReactionDeletedV1) error {
 		return nil
 	})
+	a.registerMembershipHandlers(connCtx, cfg, eventDispatcher, handler)
 	return eventDispatcher
 }
 
@@ -0,0 +1,44 @@
+package feishu
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel/adaptertest"
+)
+
+// TestRecordedFixtures replays recorded event callbacks and checks the
+// inbound mapping and the open API calls. It points the package-wide open
+// API base URL at a test server, so it does not run in parallel.
+func TestRecordedFixtures(t *testing.T) {
+	for _, fx := range adaptertest.LoadFixtures(t, "testdata/fixtures") {
+		t.Run(fx.Name, func(t *testing.T) {
+			api := adaptertest.NewAPIServer(t, fx.Responses)
+			openBaseURLForTest = api.URL
+			t.Cleanup(func() { openBaseURLForTest = "" })
+
+			adapter := NewFeishuAdapter(nil)
+			cfg := fx.Config(Type)
+			inbox := adaptertest.NewInbox()
+
+			req := fx.HTTPRequest(t, "/channels/feishu/webhook/"+cfg.ID)
+			rec := httptest.NewRecorder()
+			if err := adapter.HandleWebhook(context.Background(), cfg, inbox.Handler, req, rec); err != nil {
+				t.Fatalf("HandleWebhook: %v", err)
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			adaptertest.AssertInbound(t, fx, inbox.Messages())
+
+			if fx.Reply != nil {
+				if err := adapter.Send(context.Background(), cfg, fx.ReplyMessage(t, inbox.Messages())); err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+			}
+			adaptertest.AssertCalls(t, fx, api.Calls())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package feishu
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// registerMembershipHandlers reports the bot being removed from or added to
+// a group chat so the chat's routes follow the bot's membership.
+func (a *FeishuAdapter) registerMembershipHandlers(
+	ctx context.Context,
+	cfg channel.ChannelConfig,
+	eventDispatcher *dispatcher.EventDispatcher,
+	handler channel.InboundHandler,
+) {
+	eventDispatcher.OnP2ChatMemberBotDeletedV1(func(_ context.Context, event *larkim.P2ChatMemberBotDeletedV1) error {
+		if event == nil || event.Event == nil {
+			return nil
+		}
+		return a.dispatchMembershipEvent(ctx, cfg, handler, channel.MembershipEventBotRemoved, event.Event.ChatId)
+	})
+	eventDispatcher.OnP2ChatMemberBotAddedV1(func(_ context.Context, event *larkim.P2ChatMemberBotAddedV1) error {
+		if event == nil || event.Event == nil {
+			return nil
+		}
+		return a.dispatchMembershipEvent(ctx, cfg, handler, channel.MembershipEventBotAdded, event.Event.ChatId)
+	})
+}
+
+func (a *FeishuAdapter) dispatchMembershipEvent(
+	ctx context.Context,
+	cfg channel.ChannelConfig,
+	handler channel.InboundHandler,
+	event string,
+	chatID *string,
+) error {
+	if ctx.Err() != nil || chatID == nil || strings.TrimSpace(*chatID) == "" {
+		return nil
+	}
+	msg := channel.NewMembershipEvent(cfg, Type, event, channel.Conversation{
+		ID:   strings.TrimSpace(*chatID),
+		Type: channel.ConversationTypeGroup,
+	}, channel.Identity{})
+	if a.logger != nil {
+		a.logger.Info("membership event received",
+			slog.String("config_id", cfg.ID),
+			slog.String("event", event),
+			slog.String("chat_id", msg.Conversation.ID),
+		)
+	}
+	return handler(ctx, cfg, msg)
+}
@@ -0,0 +1,20 @@
+package feishu
+
+import "strings"
+
+// MentionID accepts open IDs, bare or as "open_id:" directory entry IDs.
+// Directory entries listed by user_id cannot be mentioned in cards.
+func (*FeishuAdapter) MentionID(id string) (string, bool) {
+	id = strings.TrimSpace(id)
+	if strings.HasPrefix(id, "user_id:") {
+		return "", false
+	}
+	id = strings.TrimPrefix(id, "open_id:")
+	return id, isSafeFeishuMentionID(id)
+}
+
+// FormatMention renders lark_md's <at> tag; Feishu shows the member's own
+// name in place of the label.
+func (*FeishuAdapter) FormatMention(id, _ string) string {
+	return `<at user_id="` + id + `"></at>`
+}
@@ -33,6 +33,8 @@ func renderFeishuMessagePartsLarkMD(msg channel.Message) string {
 			writeFeishuRichBlockquotePart(&b, part)
 		case channel.MessagePartListItem:
 			writeFeishuRichListItemPart(&b, part)
+		case channel.MessagePartLinkPreview:
+			writeFeishuRichLinkPreviewPart(&b, part)
 		}
 	}
 	return strings.TrimSpace(b.String())
@@ -72,6 +74,36 @@ func writeFeishuRichLinkPart(b *strings.Builder, part channel.MessagePart) {
 	b.WriteString(")")
 }
 
+// writeFeishuRichLinkPreviewPart renders an unfurled link as a small card
+// block: site name, bold linked title, then the description.
+func writeFeishuRichLinkPreviewPart(b *strings.Builder, part channel.MessagePart) {
+	preview, ok := channel.LinkPreviewFromPart(part)
+	if !ok || !isAllowedFeishuRichHref(preview.URL) {
+		return
+	}
+	title := preview.Title
+	if title == "" {
+		title = preview.URL
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n\n")
+	}
+	b.WriteString("---\n")
+	if preview.SiteName != "" {
+		b.WriteString(escapeFeishuInlineLarkMD(channel.CollapseMessagePartTextLine(preview.SiteName)))
+		b.WriteString("\n")
+	}
+	b.WriteString("**[")
+	b.WriteString(escapeFeishuLinkText(title))
+	b.WriteString("](")
+	b.WriteString(escapeFeishuLinkURL(preview.URL))
+	b.WriteString(")**")
+	if preview.Description != "" {
+		b.WriteString("\n")
+		b.WriteString(escapeFeishuInlineLarkMD(channel.CollapseMessagePartTextLine(preview.Description)))
+	}
+}
+
 // writeFeishuRichMentionPart emits Feishu's lark_md <at user_id="…"></at>
 // tag when the canonical Part carries a safe open_id. Feishu open IDs are
 // lowercase alphanumeric with a single underscore-separated prefix
@@ -2,11 +2,13 @@ package feishu
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	larkevent "github.com/larksuite/oapi-sdk-go/v3/event"
@@ -14,9 +16,30 @@ import (
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 
 	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/webhookauth"
 )
 
-const webhookMaxBodyBytes int64 = 1 << 20 // 1 MiB
+const (
+	webhookMaxBodyBytes int64 = 1 << 20 // 1 MiB
+
+	webhookSignatureHeader = "X-Lark-Signature"
+	webhookTimestampHeader = "X-Lark-Request-Timestamp"
+	webhookNonceHeader     = "X-Lark-Request-Nonce"
+)
+
+// webhookSignatureScheme is Feishu's event signature: the hex SHA-256 of
+// timestamp, nonce, encrypt key and body, concatenated.
+var webhookSignatureScheme = webhookauth.Scheme{
+	SignatureHeader: webhookSignatureHeader,
+	Encoding:        webhookauth.Hex,
+	TimestampHeader: webhookTimestampHeader,
+	Sum: func(encryptKey string, header http.Header, body []byte) []byte {
+		h := sha256.New()
+		_, _ = h.Write([]byte(header.Get(webhookTimestampHeader) + header.Get(webhookNonceHeader) + encryptKey))
+		_, _ = h.Write(body)
+		return h.Sum(nil)
+	},
+}
 
 // HandleWebhook processes Feishu/Lark event-subscription callbacks.
 func (a *FeishuAdapter) HandleWebhook(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler, r *http.Request, w http.ResponseWriter) error {
@@ -63,6 +86,20 @@ func (a *FeishuAdapter) HandleWebhook(ctx context.Context, cfg channel.ChannelCo
 	if challengeResp := buildWebhookChallengeResponse(webhookReq); challengeResp != nil {
 		return writeEventResponse(w, challengeResp)
 	}
+	// Events are signed with the encrypt key; without one, the
+	// verification token checked above is all Feishu sends.
+	var replayKey string
+	if encryptKey := strings.TrimSpace(feishuCfg.EncryptKey); encryptKey != "" {
+		now := time.Now()
+		if err := webhookSignatureScheme.Verify(r.Header, payload, encryptKey, now); err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid feishu webhook signature")
+		}
+		replayKey = cfg.ID + ":" + r.Header.Get(webhookSignatureHeader)
+		if !a.webhookReplays.Claim(replayKey, now) {
+			w.WriteHeader(http.StatusOK)
+			return nil
+		}
+	}
 	eventDispatcher.OnP2MessageReceiveV1(func(_ context.Context, event *larkim.P2MessageReceiveV1) error {
 		msg := extractFeishuInbound(event, botOpenID, a.logger)
 		if strings.TrimSpace(msg.Message.PlainText()) == "" && len(msg.Message.Attachments) == 0 {
@@ -73,6 +110,7 @@ func (a *FeishuAdapter) HandleWebhook(ctx context.Context, cfg channel.ChannelCo
 		msg.BotID = cfg.BotID
 		return handler(ctx, cfg, msg)
 	})
+	a.registerMembershipHandlers(ctx, cfg, eventDispatcher, handler)
 
 	resp := eventDispatcher.Handle(ctx, &larkevent.EventReq{
 		Header:     r.Header,
@@ -83,6 +121,10 @@ func (a *FeishuAdapter) HandleWebhook(ctx context.Context, cfg channel.ChannelCo
 		w.WriteHeader(http.StatusOK)
 		return nil
 	}
+	if resp.StatusCode >= http.StatusMultipleChoices && replayKey != "" {
+		// Feishu retries failed deliveries; let the retry through.
+		a.webhookReplays.Release(replayKey)
+	}
 	return writeEventResponse(w, resp)
 }
 
@@ -106,11 +148,10 @@ func validateWebhookCallbackAuth(fuzzy larkevent.EventFuzzy, cfg Config) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "feishu webhook requires encrypt_key or verification_token")
 	}
 
-	requestToken := webhookRequestToken(fuzzy)
 	if expectedToken == "" {
 		return nil
 	}
-	if requestToken == "" || requestToken != expectedToken {
+	if webhookauth.VerifyToken(webhookRequestToken(fuzzy), expectedToken) != nil {
 		return echo.NewHTTPError(http.StatusUnauthorized, "invalid feishu webhook token")
 	}
 	return nil
@@ -0,0 +1,71 @@
+package gotify
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// defaultTarget is the only target: the application token decides where
+// Gotify shows a message.
+const defaultTarget = "default"
+
+// priorities maps the configurable priority names to Gotify's levels.
+var priorities = map[string]int{
+	"low":    2,
+	"normal": 5,
+	"high":   8,
+}
+
+// Config is a bot's Gotify channel configuration.
+type Config struct {
+	ServerURL string
+	AppToken  string `json:"AppToken"` //nolint:gosec // G117: token field, handled securely
+	Priority  string
+}
+
+func (c Config) messageURL() string {
+	return strings.TrimRight(c.ServerURL, "/") + "/message"
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"serverURL": cfg.ServerURL,
+		"appToken":  cfg.AppToken,
+		"priority":  cfg.Priority,
+	}, nil
+}
+
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, string(Type)+":")
+	return strings.TrimSpace(value)
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	serverURL := strings.TrimSpace(channel.ReadString(raw, "serverURL", "server_url"))
+	parsed, err := url.Parse(serverURL)
+	if serverURL == "" || err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Config{}, errors.New("gotify serverURL must be an http(s) URL")
+	}
+	token := strings.TrimSpace(channel.ReadString(raw, "appToken", "app_token"))
+	if token == "" {
+		return Config{}, errors.New("gotify appToken is required")
+	}
+	priority := strings.ToLower(strings.TrimSpace(channel.ReadString(raw, "priority")))
+	if _, ok := priorities[priority]; priority != "" && !ok {
+		return Config{}, fmt.Errorf("gotify priority %q is not one of low, normal, high", priority)
+	}
+	return Config{
+		ServerURL: strings.TrimRight(serverURL, "/"),
+		AppToken:  token,
+		Priority:  priority,
+	}, nil
+}
@@ -0,0 +1,9 @@
+// Package gotify implements an outbound channel that posts a bot's messages
+// to a Gotify application, so schedule results and alerts reach desktop and
+// phone notifications without a chat platform.
+package gotify
+
+import "github.com/memohai/memoh/internal/channel"
+
+// Type is the registered ChannelType identifier for Gotify.
+const Type channel.ChannelType = "gotify"
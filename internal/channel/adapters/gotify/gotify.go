@@ -0,0 +1,158 @@
+package gotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Adapter posts outbound messages to a Gotify application. It is send-only.
+type Adapter struct {
+	logger *slog.Logger
+	client *http.Client
+}
+
+// NewAdapter creates a Gotify Adapter with the given logger.
+func NewAdapter(log *slog.Logger) *Adapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Adapter{
+		logger: log.With(slog.String("adapter", "gotify")),
+		client: httpClient,
+	}
+}
+
+// Type returns the Gotify channel type.
+func (*Adapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the Gotify channel metadata.
+func (*Adapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Gotify",
+		Capabilities: channel.ChannelCapabilities{
+			Text:     true,
+			Markdown: true,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"serverURL": {
+					Type:     channel.FieldString,
+					Required: true,
+					Order:    1,
+					Title:    "Server URL",
+					Example:  "https://gotify.example.com",
+				},
+				"appToken": {
+					Type:        channel.FieldSecret,
+					Required:    true,
+					Order:       2,
+					Title:       "Application Token",
+					Description: "Token of the Gotify application messages are posted as.",
+				},
+				"priority": {
+					Type:  channel.FieldEnum,
+					Order: 3,
+					Title: "Priority",
+					Enum:  []string{"low", "normal", "high"},
+				},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: defaultTarget,
+			Hints: []channel.TargetHint{
+				{Label: "Configured application", Example: defaultTarget},
+			},
+		},
+	}
+}
+
+// --- ConfigNormalizer ---
+
+// NormalizeConfig validates and normalizes a Gotify channel configuration.
+func (*Adapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig rejects user bindings; every message goes to the
+// configured application.
+func (*Adapter) NormalizeUserConfig(map[string]any) (map[string]any, error) {
+	return nil, errors.New("gotify channel does not support user bindings")
+}
+
+// --- Sender ---
+
+type messageRequest struct {
+	Message  string         `json:"message"`
+	Priority *int           `json:"priority,omitempty"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// Send posts a message to the configured application.
+func (a *Adapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.PreparedOutboundMessage) error {
+	gotifyCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	if target := normalizeTarget(msg.Target); target != defaultTarget {
+		return fmt.Errorf("gotify target must be %q", defaultTarget)
+	}
+	message := msg.Message.Message
+	text := strings.TrimSpace(message.Text)
+	markdown := message.Format == channel.MessageFormatMarkdown
+	if text == "" || len(message.Parts) > 0 {
+		text = strings.TrimSpace(message.PlainText())
+		markdown = false
+	}
+	if text == "" {
+		return errors.New("message text is required")
+	}
+	payload := messageRequest{Message: text}
+	if priority, ok := priorities[gotifyCfg.Priority]; ok {
+		payload.Priority = &priority
+	}
+	if markdown {
+		payload.Extras = map[string]any{
+			"client::display": map[string]string{"contentType": "text/markdown"},
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gotifyCfg.messageURL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", gotifyCfg.AppToken)
+	resp, err := a.client.Do(req) //nolint:gosec // G704: URL is user-configured, validated at config level
+	if err != nil {
+		return fmt.Errorf("gotify send: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		a.logger.Error("send failed", slog.String("config_id", cfg.ID), slog.Int("status", resp.StatusCode))
+		return fmt.Errorf("gotify send: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package gotify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	got, err := normalizeConfig(map[string]any{"server_url": "https://gotify.local/", "app_token": " AbC ", "priority": "Normal"})
+	if err != nil {
+		t.Fatalf("normalizeConfig: %v", err)
+	}
+	if got["serverURL"] != "https://gotify.local" || got["appToken"] != "AbC" || got["priority"] != "normal" {
+		t.Fatalf("normalized = %v", got)
+	}
+	for name, raw := range map[string]map[string]any{
+		"missing server": {"appToken": "t"},
+		"missing token":  {"serverURL": "https://gotify.local"},
+		"bad priority":   {"serverURL": "https://gotify.local", "appToken": "t", "priority": "urgent"},
+	} {
+		if _, err := normalizeConfig(raw); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSendPostsMessage(t *testing.T) {
+	var got map[string]any
+	var path, key string
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.Header.Get("X-Gotify-Key")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewAdapter(nil)
+	cfg := channel.ChannelConfig{ID: "cfg-1", ChannelType: Type, Credentials: map[string]any{
+		"serverURL": server.URL,
+		"appToken":  "app-token",
+		"priority":  "high",
+	}}
+	msg := channel.PreparedOutboundMessage{
+		Target: "default",
+		Message: channel.PreparedMessage{Message: channel.Message{
+			Format: channel.MessageFormatMarkdown,
+			Text:   "Disk usage is at **92%**",
+		}},
+	}
+	if err := adapter.Send(context.Background(), cfg, msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if path != "/message" || key != "app-token" {
+		t.Fatalf("request path=%q key=%q", path, key)
+	}
+	display, _ := got["extras"].(map[string]any)["client::display"].(map[string]any)
+	if got["message"] != "Disk usage is at **92%**" || got["priority"] != float64(8) || display["contentType"] != "text/markdown" {
+		t.Fatalf("posted = %v", got)
+	}
+
+	msg.Target = "someone"
+	if err := adapter.Send(context.Background(), cfg, msg); err == nil {
+		t.Fatal("Send to non-default target succeeded")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/line/line-bot-sdk-go/v8/linebot/webhook"
 
 	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/webhookauth"
 	"github.com/memohai/memoh/internal/media"
 )
 
@@ -23,6 +24,17 @@ const (
 	eventResultRetryable eventResult = "retryable_failure"
 )
 
+// signatureScheme checks x-line-signature: the base64 HMAC-SHA256 of the raw
+// body keyed with the channel secret. The body carries no signing time;
+// resent events are dropped by webhook event id dedup.
+var signatureScheme = webhookauth.Scheme{
+	SignatureHeader: "x-line-signature",
+	Encoding:        webhookauth.Base64,
+	Sum: func(secret string, _ http.Header, body []byte) []byte {
+		return webhookauth.HMACSHA256(secret, body)
+	},
+}
+
 type callbackBudget struct {
 	mediaEvents int
 	mediaBytes  int64
@@ -61,7 +73,7 @@ func (a *Adapter) HandleWebhook(ctx context.Context, cfg channel.ChannelConfig,
 			return a.httpError(http.StatusInternalServerError, "failed to read request body")
 		}
 	}
-	if !webhook.ValidateSignature(creds.ChannelSecret, r.Header.Get("x-line-signature"), body) {
+	if signatureScheme.Verify(r.Header, body, creds.ChannelSecret, time.Now()) != nil {
 		return a.httpError(http.StatusForbidden, "invalid signature")
 	}
 
@@ -14,6 +14,9 @@ type Config struct {
 	UserID             string
 	SyncTimeoutSeconds int
 	AutoJoinInvites    bool
+	// EncryptedRoomNotice posts a one-time plaintext notice in rooms that send
+	// end-to-end encrypted events, which the adapter cannot decrypt.
+	EncryptedRoomNotice bool
 }
 
 type UserConfig struct {
@@ -27,11 +30,12 @@ func normalizeConfig(raw map[string]any) (map[string]any, error) {
 		return nil, err
 	}
 	out := map[string]any{
-		"homeserverUrl":      cfg.HomeserverURL,
-		"accessToken":        cfg.AccessToken,
-		"userId":             cfg.UserID,
-		"syncTimeoutSeconds": cfg.SyncTimeoutSeconds,
-		"autoJoinInvites":    cfg.AutoJoinInvites,
+		"homeserverUrl":       cfg.HomeserverURL,
+		"accessToken":         cfg.AccessToken,
+		"userId":              cfg.UserID,
+		"syncTimeoutSeconds":  cfg.SyncTimeoutSeconds,
+		"autoJoinInvites":     cfg.AutoJoinInvites,
+		"encryptedRoomNotice": cfg.EncryptedRoomNotice,
 	}
 	return out, nil
 }
@@ -105,12 +109,14 @@ func parseConfig(raw map[string]any) (Config, error) {
 		timeout = 0
 	}
 	autoJoinInvites := readBool(raw, true, "autoJoinInvites", "auto_join_invites")
+	encryptedRoomNotice := readBool(raw, true, "encryptedRoomNotice", "encrypted_room_notice")
 	return Config{
-		HomeserverURL:      homeserverURL,
-		AccessToken:        accessToken,
-		UserID:             userID,
-		SyncTimeoutSeconds: timeout,
-		AutoJoinInvites:    autoJoinInvites,
+		HomeserverURL:       homeserverURL,
+		AccessToken:         accessToken,
+		UserID:              userID,
+		SyncTimeoutSeconds:  timeout,
+		AutoJoinInvites:     autoJoinInvites,
+		EncryptedRoomNotice: encryptedRoomNotice,
 	}, nil
 }
 
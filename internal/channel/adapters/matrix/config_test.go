@@ -39,6 +39,9 @@ func TestParseConfigDefaultsAutoJoinInvites(t *testing.T) {
 	if !cfg.AutoJoinInvites {
 		t.Fatal("expected autoJoinInvites default to true")
 	}
+	if !cfg.EncryptedRoomNotice {
+		t.Fatal("expected encryptedRoomNotice default to true")
+	}
 }
 
 func TestParseUserConfigRequiresTarget(t *testing.T) {
@@ -33,6 +33,9 @@ const (
 	matrixDefaultTimeout  = 30 * time.Second
 	matrixEditThrottle    = 1200 * time.Millisecond
 	matrixRoutingStateKey = "_matrix"
+
+	matrixEncryptedEventType = "m.room.encrypted"
+	matrixEncryptedNotice    = "This room is end-to-end encrypted and I can't read encrypted messages yet. Please talk to me in an unencrypted room."
 )
 
 type assetOpener interface {
@@ -56,6 +59,9 @@ type MatrixAdapter struct {
 
 	directRoomMu sync.Mutex
 	directRooms  map[string]map[string]string
+
+	encryptedMu    sync.Mutex
+	encryptedRooms map[string]map[string]struct{}
 }
 
 type matrixSyncResponse struct {
@@ -167,9 +173,10 @@ func NewMatrixAdapter(log *slog.Logger) *MatrixAdapter {
 		httpClient: &http.Client{
 			Timeout: matrixDefaultTimeout,
 		},
-		seen:        make(map[string]map[string]time.Time),
-		roomTypes:   make(map[string]map[string]string),
-		directRooms: make(map[string]map[string]string),
+		seen:           make(map[string]map[string]time.Time),
+		roomTypes:      make(map[string]map[string]string),
+		directRooms:    make(map[string]map[string]string),
+		encryptedRooms: make(map[string]map[string]struct{}),
 	}
 }
 
@@ -237,6 +244,11 @@ func (*MatrixAdapter) Descriptor() channel.Descriptor {
 					Type:  channel.FieldBool,
 					Title: "Auto-Join Invites",
 				},
+				"encryptedRoomNotice": {
+					Type:        channel.FieldBool,
+					Title:       "Encrypted Room Notice",
+					Description: "Tell users once per room that end-to-end encrypted messages cannot be read",
+				},
 			},
 		},
 		UserConfigSchema: channel.ConfigSchema{
@@ -600,6 +612,10 @@ func (a *MatrixAdapter) handleInvites(ctx context.Context, cfg channel.ChannelCo
 }
 
 func (a *MatrixAdapter) handleEvent(ctx context.Context, cfg channel.ChannelConfig, parsed Config, evt matrixEvent, handler channel.InboundHandler) (bool, error) {
+	if evt.Type == matrixEncryptedEventType {
+		a.handleEncryptedEvent(ctx, cfg, parsed, evt)
+		return false, nil
+	}
 	if evt.Type != "m.room.message" {
 		return false, nil
 	}
@@ -716,6 +732,61 @@ func (a *MatrixAdapter) handleEvent(ctx context.Context, cfg channel.ChannelConf
 	return true, handler(ctx, cfg, msg)
 }
 
+// handleEncryptedEvent drops an end-to-end encrypted event the adapter cannot
+// decrypt, warning once per room and optionally posting a plaintext notice so
+// the sender knows why the bot stays silent.
+func (a *MatrixAdapter) handleEncryptedEvent(ctx context.Context, cfg channel.ChannelConfig, parsed Config, evt matrixEvent) {
+	sender := strings.TrimSpace(evt.Sender)
+	roomID := strings.TrimSpace(evt.RoomID)
+	if sender == "" || roomID == "" || strings.EqualFold(sender, parsed.UserID) {
+		return
+	}
+	if !a.markEncryptedRoom(cfg.ID, roomID) {
+		return
+	}
+	if a.logger != nil {
+		a.logger.Warn("dropping encrypted matrix events; end-to-end encryption is not supported",
+			slog.String("config_id", cfg.ID),
+			slog.String("room_id", roomID),
+			slog.String("sender", sender),
+		)
+	}
+	if !parsed.EncryptedRoomNotice {
+		return
+	}
+	content := map[string]any{
+		"msgtype": "m.notice",
+		"body":    matrixEncryptedNotice,
+	}
+	if _, err := a.sendTextEvent(ctx, parsed, roomID, content); err != nil && a.logger != nil {
+		a.logger.Warn("failed to send matrix encrypted room notice",
+			slog.String("config_id", cfg.ID),
+			slog.String("room_id", roomID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// markEncryptedRoom records that roomID sent encrypted events and reports
+// whether this is the first time for the config.
+func (a *MatrixAdapter) markEncryptedRoom(configID, roomID string) bool {
+	a.encryptedMu.Lock()
+	defer a.encryptedMu.Unlock()
+	if a.encryptedRooms == nil {
+		a.encryptedRooms = make(map[string]map[string]struct{})
+	}
+	byConfig := a.encryptedRooms[configID]
+	if byConfig == nil {
+		byConfig = make(map[string]struct{})
+		a.encryptedRooms[configID] = byConfig
+	}
+	if _, ok := byConfig[roomID]; ok {
+		return false
+	}
+	byConfig[roomID] = struct{}{}
+	return true
+}
+
 func (a *MatrixAdapter) fetchRoomEvent(ctx context.Context, cfg Config, roomID, eventID string) (matrixEvent, error) {
 	path := fmt.Sprintf("/_matrix/client/v3/rooms/%s/event/%s", url.PathEscape(strings.TrimSpace(roomID)), url.PathEscape(strings.TrimSpace(eventID)))
 	var evt matrixEvent
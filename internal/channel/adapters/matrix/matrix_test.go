@@ -603,6 +603,81 @@ func TestMatrixHandleEventMarksDirectConversationFromJoinedMembers(t *testing.T)
 	}
 }
 
+func TestMatrixHandleEventNoticesEncryptedRoomOnce(t *testing.T) {
+	var notices []map[string]any
+	adapter := NewMatrixAdapter(nil)
+	adapter.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPut || !strings.Contains(req.URL.Path, "/rooms/!secret:example.com/send/m.room.message/") {
+			t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+		}
+		var content map[string]any
+		if err := json.NewDecoder(req.Body).Decode(&content); err != nil {
+			t.Fatalf("decode notice: %v", err)
+		}
+		notices = append(notices, content)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"event_id":"$notice"}`)),
+			Header:     make(http.Header),
+		}, nil
+	})}
+
+	cfg := Config{HomeserverURL: "https://matrix.example.com", AccessToken: "tok", UserID: "@memoh:example.com", EncryptedRoomNotice: true}
+	for i := 0; i < 2; i++ {
+		delivered, err := adapter.handleEvent(
+			context.Background(),
+			channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1"},
+			cfg,
+			matrixEvent{
+				EventID: fmt.Sprintf("$enc%d", i+1),
+				Type:    "m.room.encrypted",
+				Sender:  "@alex:example.com",
+				RoomID:  "!secret:example.com",
+				Content: map[string]any{"algorithm": "m.megolm.v1.aes-sha2", "ciphertext": "..."},
+			},
+			func(context.Context, channel.ChannelConfig, channel.InboundMessage) error {
+				t.Fatal("encrypted event must not reach the inbound handler")
+				return nil
+			},
+		)
+		if err != nil {
+			t.Fatalf("handleEvent returned error: %v", err)
+		}
+		if delivered {
+			t.Fatal("expected encrypted event to be dropped")
+		}
+	}
+	if len(notices) != 1 {
+		t.Fatalf("expected one encrypted room notice, got %d", len(notices))
+	}
+	if msgtype, _ := notices[0]["msgtype"].(string); msgtype != "m.notice" {
+		t.Fatalf("notice msgtype = %q, want m.notice", msgtype)
+	}
+}
+
+func TestMatrixHandleEventSkipsEncryptedNoticeWhenDisabled(t *testing.T) {
+	adapter := NewMatrixAdapter(nil)
+	adapter.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+		return nil, nil
+	})}
+	delivered, err := adapter.handleEvent(
+		context.Background(),
+		channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1"},
+		Config{HomeserverURL: "https://matrix.example.com", AccessToken: "tok", UserID: "@memoh:example.com"},
+		matrixEvent{
+			EventID: "$enc",
+			Type:    "m.room.encrypted",
+			Sender:  "@alex:example.com",
+			RoomID:  "!secret:example.com",
+		},
+		nil,
+	)
+	if err != nil || delivered {
+		t.Fatalf("handleEvent = (%v, %v), want dropped without error", delivered, err)
+	}
+}
+
 func TestMatrixSyncOnceAutoJoinsInvitedRooms(t *testing.T) {
 	joinRequests := 0
 	adapter := NewMatrixAdapter(nil)
@@ -0,0 +1,462 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// This file implements the part of MQTT 3.1.1 the channel needs: a clean
+// session with QoS 0 and 1 publish and subscribe. Incoming QoS 2 cannot
+// happen because subscriptions never ask for more than QoS 1.
+
+const (
+	packetConnect     = 1
+	packetConnack     = 2
+	packetPublish     = 3
+	packetPuback      = 4
+	packetSubscribe   = 8
+	packetSuback      = 9
+	packetPingreq     = 12
+	packetPingresp    = 13
+	packetDisconnect  = 14
+	protocolLevel311  = 4
+	connectFlagClean  = 0x02
+	connectFlagPass   = 0x40
+	connectFlagUser   = 0x80
+	subackFailure     = 0x80
+	maxRemainingBytes = 268435455
+)
+
+const (
+	dialTimeout     = 15 * time.Second
+	ackTimeout      = 15 * time.Second
+	keepAlive       = 60 * time.Second
+	maxPacketBytes  = 1 << 20
+	maxPendingAcks  = 64
+	writeTimeout    = 10 * time.Second
+	pingRespTimeout = 15 * time.Second
+)
+
+var errClientClosed = errors.New("mqtt connection closed")
+
+type clientOptions struct {
+	address  string
+	useTLS   bool
+	clientID string
+	username string
+	password string
+}
+
+// message is a PUBLISH received from the broker.
+type message struct {
+	topic    string
+	payload  []byte
+	retained bool
+}
+
+// client is one MQTT connection. Messages from subscriptions are passed to
+// onMessage from the read loop, so the callback must not block.
+type client struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	onMessage func(message)
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint16
+	pending map[uint16]chan []byte
+	pongs   chan struct{}
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+func dial(ctx context.Context, opts clientOptions, onMessage func(message)) (*client, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	if opts.useTLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", opts.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", opts.address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mqtt dial: %w", err)
+	}
+	c, err := handshake(ctx, conn, opts, onMessage)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// handshake sends CONNECT over an open connection and starts the client
+// once the broker accepts it.
+func handshake(ctx context.Context, conn net.Conn, opts clientOptions, onMessage func(message)) (*client, error) {
+	deadline := time.Now().Add(ackTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+	c := &client{
+		conn:      conn,
+		reader:    bufio.NewReader(conn),
+		onMessage: onMessage,
+		pending:   map[uint16]chan []byte{},
+		pongs:     make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	if err := c.write(packetConnect<<4, connectBody(opts)); err != nil {
+		return nil, fmt.Errorf("mqtt connect: %w", err)
+	}
+	header, body, err := readPacket(c.reader)
+	if err != nil {
+		return nil, fmt.Errorf("mqtt connack: %w", err)
+	}
+	if header>>4 != packetConnack || len(body) != 2 {
+		return nil, errors.New("mqtt connack: unexpected packet")
+	}
+	if code := body[1]; code != 0 {
+		return nil, fmt.Errorf("mqtt connect refused: %s", connackReason(code))
+	}
+	_ = conn.SetDeadline(time.Time{})
+	go c.readLoop()
+	go c.pingLoop()
+	return c, nil
+}
+
+func connectBody(opts clientOptions) []byte {
+	flags := byte(connectFlagClean)
+	if opts.username != "" {
+		flags |= connectFlagUser
+		if opts.password != "" {
+			flags |= connectFlagPass
+		}
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel311, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(keepAlive/time.Second))
+	body = appendString(body, opts.clientID)
+	if flags&connectFlagUser != 0 {
+		body = appendString(body, opts.username)
+	}
+	if flags&connectFlagPass != 0 {
+		body = appendString(body, opts.password)
+	}
+	return body
+}
+
+func connackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "client identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad username or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("code %d", code)
+	}
+}
+
+// Done is closed when the connection ends.
+func (c *client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err reports why the connection ended.
+func (c *client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close disconnects cleanly.
+func (c *client) Close() error {
+	_ = c.write(packetDisconnect<<4, nil)
+	c.shutdown(errClientClosed)
+	return nil
+}
+
+func (c *client) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		_ = c.conn.Close()
+		close(c.done)
+	})
+}
+
+// Subscribe subscribes to topic filters and waits for the broker to accept
+// every one of them.
+func (c *client) Subscribe(ctx context.Context, filters []string, qos byte) error {
+	id, ack, err := c.reserveID()
+	if err != nil {
+		return err
+	}
+	body := binary.BigEndian.AppendUint16(nil, id)
+	for _, filter := range filters {
+		body = appendString(body, filter)
+		body = append(body, qos)
+	}
+	if err := c.write(packetSubscribe<<4|0x02, body); err != nil {
+		c.releaseID(id)
+		return err
+	}
+	codes, err := c.awaitAck(ctx, id, ack)
+	if err != nil {
+		return fmt.Errorf("mqtt subscribe: %w", err)
+	}
+	for i, code := range codes {
+		if code == subackFailure && i < len(filters) {
+			return fmt.Errorf("mqtt subscribe: broker rejected %q", filters[i])
+		}
+	}
+	return nil
+}
+
+// Publish sends a message. With QoS 1 it waits for the broker's PUBACK.
+func (c *client) Publish(ctx context.Context, topic string, payload []byte, qos byte, retain bool) error {
+	header := byte(packetPublish<<4) | qos<<1
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+	if qos == 0 {
+		return c.write(header, append(body, payload...))
+	}
+	id, ack, err := c.reserveID()
+	if err != nil {
+		return err
+	}
+	body = binary.BigEndian.AppendUint16(body, id)
+	if err := c.write(header, append(body, payload...)); err != nil {
+		c.releaseID(id)
+		return err
+	}
+	if _, err := c.awaitAck(ctx, id, ack); err != nil {
+		return fmt.Errorf("mqtt publish: %w", err)
+	}
+	return nil
+}
+
+func (c *client) reserveID() (uint16, chan []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, nil, c.err
+	}
+	if len(c.pending) >= maxPendingAcks {
+		return 0, nil, errors.New("mqtt: too many unacknowledged packets")
+	}
+	for {
+		c.nextID++
+		if c.nextID == 0 {
+			continue
+		}
+		if _, used := c.pending[c.nextID]; !used {
+			break
+		}
+	}
+	ack := make(chan []byte, 1)
+	c.pending[c.nextID] = ack
+	return c.nextID, ack, nil
+}
+
+func (c *client) releaseID(id uint16) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+func (c *client) awaitAck(ctx context.Context, id uint16, ack chan []byte) ([]byte, error) {
+	timer := time.NewTimer(ackTimeout)
+	defer timer.Stop()
+	select {
+	case body := <-ack:
+		return body, nil
+	case <-ctx.Done():
+		c.releaseID(id)
+		return nil, ctx.Err()
+	case <-timer.C:
+		c.releaseID(id)
+		return nil, errors.New("timed out waiting for acknowledgement")
+	case <-c.done:
+		return nil, c.Err()
+	}
+}
+
+func (c *client) readLoop() {
+	for {
+		header, body, err := readPacket(c.reader)
+		if err != nil {
+			c.shutdown(err)
+			return
+		}
+		switch header >> 4 {
+		case packetPublish:
+			if err := c.handlePublish(header, body); err != nil {
+				c.shutdown(err)
+				return
+			}
+		case packetPuback, packetSuback:
+			if len(body) < 2 {
+				c.shutdown(errors.New("mqtt: malformed acknowledgement"))
+				return
+			}
+			id := binary.BigEndian.Uint16(body)
+			c.mu.Lock()
+			ack, ok := c.pending[id]
+			delete(c.pending, id)
+			c.mu.Unlock()
+			if ok {
+				ack <- body[2:]
+			}
+		case packetPingresp:
+			select {
+			case c.pongs <- struct{}{}:
+			default:
+			}
+		default:
+		}
+	}
+}
+
+func (c *client) handlePublish(header byte, body []byte) error {
+	topic, rest, err := readString(body)
+	if err != nil {
+		return err
+	}
+	qos := (header >> 1) & 0x03
+	if qos > 0 {
+		if len(rest) < 2 {
+			return errors.New("mqtt: malformed publish")
+		}
+		id := rest[:2]
+		rest = rest[2:]
+		if err := c.write(packetPuback<<4, id); err != nil {
+			return err
+		}
+	}
+	if c.onMessage != nil {
+		c.onMessage(message{topic: topic, payload: rest, retained: header&0x01 != 0})
+	}
+	return nil
+}
+
+// pingLoop keeps the connection alive and detects a broker that stopped
+// answering.
+func (c *client) pingLoop() {
+	ticker := time.NewTicker(keepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+		if err := c.write(packetPingreq<<4, nil); err != nil {
+			c.shutdown(err)
+			return
+		}
+		select {
+		case <-c.done:
+			return
+		case <-c.pongs:
+		case <-time.After(pingRespTimeout):
+			c.shutdown(errors.New("mqtt: broker stopped responding"))
+			return
+		}
+	}
+}
+
+func (c *client) write(header byte, body []byte) error {
+	if len(body) > maxRemainingBytes {
+		return errors.New("mqtt: packet too large")
+	}
+	packet := make([]byte, 0, len(body)+5)
+	packet = append(packet, header)
+	packet = appendRemainingLength(packet, len(body))
+	packet = append(packet, body...)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length := 0
+	for shift := 0; ; shift += 7 {
+		if shift > 21 {
+			return 0, nil, errors.New("mqtt: malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length |= int(b&0x7F) << shift
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if length > maxPacketBytes {
+		return 0, nil, fmt.Errorf("mqtt: packet of %d bytes exceeds limit", length)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func appendRemainingLength(b []byte, length int) []byte {
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if length == 0 {
+			return b
+		}
+	}
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s))) //nolint:gosec // strings are bounded by topic and credential limits
+	return append(b, s...)
+}
+
+func readString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("mqtt: malformed string")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("mqtt: malformed string")
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
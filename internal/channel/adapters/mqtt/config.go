@@ -0,0 +1,265 @@
+package mqtt
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+const (
+	// defaultTarget publishes to the reply topic in the bot's channel
+	// configuration.
+	defaultTarget = "default"
+	maxTopicBytes = 1024
+)
+
+// Config is a bot's MQTT channel configuration. Messages on SubscribeTopics
+// trigger the bot. The bot answers on ReplyTopic and may also publish to any
+// topic matching PublishTopics, which is how it sends commands to devices.
+type Config struct {
+	Address         string
+	TLS             bool
+	ClientID        string
+	Username        string
+	Password        string `json:"Password"` //nolint:gosec // G117: password field, handled securely
+	SubscribeTopics []string
+	ReplyTopic      string
+	PublishTopics   []string
+	QoS             byte
+}
+
+// canPublish reports whether the bot may publish to topic.
+func (c Config) canPublish(topic string) bool {
+	if topic == c.ReplyTopic {
+		return true
+	}
+	for _, filter := range c.PublishTopics {
+		if topicMatches(filter, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// UserConfig binds a user to a topic the bot publishes to for them.
+type UserConfig struct {
+	Topic string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	scheme := "mqtt"
+	if cfg.TLS {
+		scheme = "mqtts"
+	}
+	return map[string]any{
+		"brokerURL":       scheme + "://" + cfg.Address,
+		"clientID":        cfg.ClientID,
+		"username":        cfg.Username,
+		"password":        cfg.Password,
+		"subscribeTopics": strings.Join(cfg.SubscribeTopics, ","),
+		"replyTopic":      cfg.ReplyTopic,
+		"publishTopics":   strings.Join(cfg.PublishTopics, ","),
+		"qos":             fmt.Sprint(cfg.QoS),
+	}, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"topic": cfg.Topic}, nil
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	return cfg.Topic, nil
+}
+
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, string(Type)+":")
+	return strings.TrimSpace(value)
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	address, useTLS, err := parseBrokerURL(channel.ReadString(raw, "brokerURL", "broker_url"))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{
+		Address:  address,
+		TLS:      useTLS,
+		ClientID: strings.TrimSpace(channel.ReadString(raw, "clientID", "client_id")),
+		Username: strings.TrimSpace(channel.ReadString(raw, "username")),
+		Password: channel.ReadString(raw, "password"),
+	}
+	if len(cfg.ClientID) > 23 {
+		// Brokers only have to accept identifiers up to 23 bytes.
+		return Config{}, errors.New("mqtt clientID must be at most 23 characters")
+	}
+	if cfg.Password != "" && cfg.Username == "" {
+		return Config{}, errors.New("mqtt username is required with password")
+	}
+	for _, filter := range readTopics(raw, "subscribeTopics", "subscribe_topics") {
+		if err := validateFilter(filter); err != nil {
+			return Config{}, fmt.Errorf("mqtt subscribeTopics: %w", err)
+		}
+		cfg.SubscribeTopics = append(cfg.SubscribeTopics, filter)
+	}
+	if len(cfg.SubscribeTopics) == 0 {
+		return Config{}, errors.New("mqtt subscribeTopics requires at least one topic")
+	}
+	cfg.ReplyTopic = normalizeTarget(channel.ReadString(raw, "replyTopic", "reply_topic"))
+	if err := validateTopic(cfg.ReplyTopic); err != nil {
+		return Config{}, fmt.Errorf("mqtt replyTopic: %w", err)
+	}
+	for _, filter := range readTopics(raw, "publishTopics", "publish_topics") {
+		if err := validateFilter(filter); err != nil {
+			return Config{}, fmt.Errorf("mqtt publishTopics: %w", err)
+		}
+		cfg.PublishTopics = append(cfg.PublishTopics, filter)
+	}
+	switch qos := strings.TrimSpace(channel.ReadString(raw, "qos")); qos {
+	case "", "0":
+	case "1":
+		cfg.QoS = 1
+	default:
+		return Config{}, fmt.Errorf("mqtt qos %q is not 0 or 1", qos)
+	}
+	return cfg, nil
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	topic := normalizeTarget(channel.ReadString(raw, "topic"))
+	if err := validateTopic(topic); err != nil {
+		return UserConfig{}, fmt.Errorf("mqtt user config topic: %w", err)
+	}
+	return UserConfig{Topic: topic}, nil
+}
+
+// parseBrokerURL accepts mqtt://, tcp://, mqtts://, ssl:// and tls:// URLs
+// and fills in the standard port when it is missing.
+func parseBrokerURL(raw string) (string, bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false, errors.New("mqtt brokerURL is required")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Hostname() == "" {
+		return "", false, errors.New("mqtt brokerURL must look like mqtt://host:1883 or mqtts://host:8883")
+	}
+	var useTLS bool
+	port := "1883"
+	switch strings.ToLower(parsed.Scheme) {
+	case "mqtt", "tcp":
+	case "mqtts", "ssl", "tls":
+		useTLS = true
+		port = "8883"
+	default:
+		return "", false, fmt.Errorf("mqtt brokerURL scheme %q is not supported", parsed.Scheme)
+	}
+	if parsed.Port() != "" {
+		port = parsed.Port()
+	}
+	return net.JoinHostPort(parsed.Hostname(), port), useTLS, nil
+}
+
+// readTopics accepts topics as a comma- or newline-separated string or a
+// list, and drops blanks and duplicates. Topics may contain spaces, so
+// spaces do not separate them.
+func readTopics(raw map[string]any, keys ...string) []string {
+	var values []string
+	for _, key := range keys {
+		switch v := raw[key].(type) {
+		case string:
+			values = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '\n' })
+		case []string:
+			values = v
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		default:
+			continue
+		}
+		break
+	}
+	var topics []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !slices.Contains(topics, value) {
+			topics = append(topics, value)
+		}
+	}
+	return topics
+}
+
+// validateTopic checks a topic the bot publishes to.
+func validateTopic(topic string) error {
+	if err := validateFilter(topic); err != nil {
+		return err
+	}
+	if strings.ContainsAny(topic, "+#") {
+		return fmt.Errorf("topic %q must not contain wildcards", topic)
+	}
+	return nil
+}
+
+// validateFilter checks a subscription filter: "+" must fill a whole level
+// and "#" may only be the last level.
+func validateFilter(filter string) error {
+	if filter == "" {
+		return errors.New("topic is required")
+	}
+	if len(filter) > maxTopicBytes || !utf8.ValidString(filter) || strings.ContainsRune(filter, 0) {
+		return fmt.Errorf("topic %q is not valid", filter)
+	}
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if strings.ContainsAny(level, "+#") && len(level) > 1 {
+			return fmt.Errorf("topic %q has a wildcard inside a level", filter)
+		}
+		if level == "#" && i != len(levels)-1 {
+			return fmt.Errorf("topic %q has # before the last level", filter)
+		}
+	}
+	return nil
+}
+
+// topicMatches reports whether topic matches a subscription filter. As in
+// the MQTT spec, a leading wildcard does not match topics starting with "$".
+func topicMatches(filter, topic string) bool {
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
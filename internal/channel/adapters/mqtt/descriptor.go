@@ -0,0 +1,10 @@
+// Package mqtt implements a channel that connects a bot to an MQTT broker,
+// such as the one Home Assistant uses. Messages on subscribed topics trigger
+// the bot, and the bot publishes replies and commands back to allowed
+// topics.
+package mqtt
+
+import "github.com/memohai/memoh/internal/channel"
+
+// Type is the registered ChannelType identifier for MQTT.
+const Type channel.ChannelType = "mqtt"
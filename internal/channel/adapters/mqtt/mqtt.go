@@ -0,0 +1,455 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/common"
+	"github.com/memohai/memoh/internal/redact"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+const (
+	maxInboundRunes = 8000
+	maxPayloadRunes = 65536
+	reconnectDelay  = 5 * time.Second
+)
+
+// Adapter connects bots to MQTT brokers. Each connected config keeps one
+// broker connection, which Send reuses when it is up.
+type Adapter struct {
+	logger *slog.Logger
+	mu     sync.RWMutex
+	conns  map[string]*client // keyed by config ID
+}
+
+// NewAdapter creates an MQTT Adapter with the given logger.
+func NewAdapter(log *slog.Logger) *Adapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Adapter{
+		logger: log.With(slog.String("adapter", "mqtt")),
+		conns:  make(map[string]*client),
+	}
+}
+
+// Type returns the MQTT channel type.
+func (*Adapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the MQTT channel metadata.
+func (*Adapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "MQTT",
+		Capabilities: channel.ChannelCapabilities{
+			Text:           true,
+			BlockStreaming: true,
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			TextChunkLimit: maxPayloadRunes,
+			ChunkerMode:    channel.ChunkerModeText,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"brokerURL": {
+					Type:        channel.FieldString,
+					Required:    true,
+					Order:       1,
+					Title:       "Broker URL",
+					Description: "Use mqtts:// for TLS. The port defaults to 1883, or 8883 with TLS.",
+					Example:     "mqtt://homeassistant.local:1883",
+				},
+				"username": {
+					Type:  channel.FieldString,
+					Order: 2,
+					Title: "Username",
+				},
+				"password": {
+					Type:  channel.FieldSecret,
+					Order: 3,
+					Title: "Password",
+				},
+				"clientID": {
+					Type:        channel.FieldString,
+					Order:       4,
+					Title:       "Client ID",
+					Description: "At most 23 characters. Defaults to an ID derived from this channel.",
+				},
+				"subscribeTopics": {
+					Type:        channel.FieldString,
+					Required:    true,
+					Order:       5,
+					Title:       "Subscribe Topics",
+					Description: "Comma-separated topic filters. Each message on them triggers the bot. Retained messages are ignored.",
+					Example:     "memoh/ask,homeassistant/event/+",
+				},
+				"replyTopic": {
+					Type:        channel.FieldString,
+					Required:    true,
+					Order:       6,
+					Title:       "Reply Topic",
+					Description: "Topic the bot publishes its replies to.",
+					Example:     "memoh/reply",
+				},
+				"publishTopics": {
+					Type:        channel.FieldString,
+					Order:       7,
+					Title:       "Publish Topics",
+					Description: "Comma-separated topic filters the bot may also publish commands to.",
+					Example:     "homeassistant/+/+/set",
+				},
+				"qos": {
+					Type:  channel.FieldEnum,
+					Order: 8,
+					Title: "QoS",
+					Enum:  []string{"0", "1"},
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"topic": {Type: channel.FieldString, Required: true},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "default | topic",
+			Hints: []channel.TargetHint{
+				{Label: "Reply topic", Example: defaultTarget},
+				{Label: "Topic", Example: "homeassistant/light/kitchen/set"},
+			},
+		},
+	}
+}
+
+// --- ConfigNormalizer ---
+
+// NormalizeConfig validates and normalizes an MQTT channel configuration.
+func (*Adapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a user's MQTT topic binding.
+func (*Adapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// --- TargetResolver ---
+
+// NormalizeTarget strips an optional "mqtt:" prefix from a topic.
+func (*Adapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives the topic from a user's MQTT binding.
+func (*Adapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// ResolveOutboundTarget maps the "default" target to the reply topic.
+func (*Adapter) ResolveOutboundTarget(_ context.Context, cfg channel.ChannelConfig, target string) (string, error) {
+	target = normalizeTarget(target)
+	if target != defaultTarget {
+		return target, nil
+	}
+	mqttCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return "", err
+	}
+	return mqttCfg.ReplyTopic, nil
+}
+
+// --- Receiver ---
+
+// Connect subscribes to the configured topics and keeps the broker
+// connection up until the connection stops.
+func (a *Adapter) Connect(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler) (channel.Connection, error) {
+	a.logger.Info("start", slog.String("config_id", cfg.ID))
+	mqttCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	redact.SetSecrets("mqtt:"+cfg.ID, mqttCfg.Password)
+
+	connCtx, cancel := context.WithCancel(ctx)
+	c, err := a.connect(connCtx, cfg, mqttCfg, handler)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go a.runLoop(connCtx, cfg, mqttCfg, handler, c)
+
+	stop := func(_ context.Context) error {
+		a.logger.Info("stop", slog.String("config_id", cfg.ID))
+		cancel()
+		return nil
+	}
+	return channel.NewConnection(cfg, stop), nil
+}
+
+// connect dials the broker, subscribes, and makes the connection available
+// to Send.
+func (a *Adapter) connect(ctx context.Context, cfg channel.ChannelConfig, mqttCfg Config, handler channel.InboundHandler) (*client, error) {
+	onMessage := func(msg message) {
+		a.handleMessage(ctx, cfg, mqttCfg, handler, msg)
+	}
+	c, err := dial(ctx, clientOptions{
+		address:  mqttCfg.Address,
+		useTLS:   mqttCfg.TLS,
+		clientID: clientID(cfg, mqttCfg),
+		username: mqttCfg.Username,
+		password: mqttCfg.Password,
+	}, onMessage)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Subscribe(ctx, mqttCfg.SubscribeTopics, mqttCfg.QoS); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	a.mu.Lock()
+	a.conns[cfg.ID] = c
+	a.mu.Unlock()
+	a.logger.Info("connected", slog.String("config_id", cfg.ID), slog.String("broker", mqttCfg.Address))
+	return c, nil
+}
+
+// runLoop waits for the connection to end and reconnects until ctx is done.
+func (a *Adapter) runLoop(ctx context.Context, cfg channel.ChannelConfig, mqttCfg Config, handler channel.InboundHandler, c *client) {
+	for {
+		select {
+		case <-ctx.Done():
+			a.forget(cfg.ID, c)
+			_ = c.Close()
+			return
+		case <-c.Done():
+			a.forget(cfg.ID, c)
+			a.logger.Warn("disconnected", slog.String("config_id", cfg.ID), slog.Any("error", c.Err()))
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+			next, err := a.connect(ctx, cfg, mqttCfg, handler)
+			if err == nil {
+				c = next
+				break
+			}
+			a.logger.Warn("reconnect failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+	}
+}
+
+func (a *Adapter) forget(configID string, c *client) {
+	a.mu.Lock()
+	if a.conns[configID] == c {
+		delete(a.conns, configID)
+	}
+	a.mu.Unlock()
+}
+
+func (a *Adapter) handleMessage(ctx context.Context, cfg channel.ChannelConfig, mqttCfg Config, handler channel.InboundHandler, msg message) {
+	inbound, ok := buildInboundMessage(mqttCfg, msg)
+	if !ok {
+		return
+	}
+	a.logger.Info("inbound received",
+		slog.String("config_id", cfg.ID),
+		slog.String("topic", msg.topic),
+		slog.String("text", common.SummarizeText(inbound.Message.Text)),
+	)
+	go func() {
+		if err := handler(ctx, cfg, inbound); err != nil {
+			a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+	}()
+}
+
+// buildInboundMessage turns a broker message into a message for the bot.
+// Retained messages are skipped because the broker replays them on every
+// subscribe, and so are messages on topics the bot publishes to, which would
+// otherwise loop its own replies back to it.
+func buildInboundMessage(cfg Config, msg message) (channel.InboundMessage, bool) {
+	if msg.retained || cfg.canPublish(msg.topic) || !utf8.Valid(msg.payload) {
+		return channel.InboundMessage{}, false
+	}
+	text := strings.TrimSpace(string(msg.payload))
+	if text == "" {
+		return channel.InboundMessage{}, false
+	}
+	text = textutil.TruncateRunesWithSuffix(text, maxInboundRunes, "...")
+	return channel.InboundMessage{
+		Channel: Type,
+		Message: channel.Message{
+			Format: channel.MessageFormatPlain,
+			Text:   text,
+		},
+		ReplyTarget: cfg.ReplyTopic,
+		Sender: channel.Identity{
+			SubjectID:   msg.topic,
+			DisplayName: msg.topic,
+			Attributes:  map[string]string{"topic": msg.topic},
+		},
+		// All topics share one conversation so the bot keeps the context of
+		// earlier events when handling a new one.
+		Conversation: channel.Conversation{
+			ID:   cfg.ReplyTopic,
+			Type: channel.ConversationTypePrivate,
+		},
+		ReceivedAt: time.Now().UTC(),
+		Source:     string(Type),
+		Metadata: map[string]any{
+			"is_mentioned": true,
+			"topic":        msg.topic,
+		},
+	}, true
+}
+
+// clientID returns the configured client ID, or one derived from the config
+// ID that fits the 23 bytes every broker accepts.
+func clientID(cfg channel.ChannelConfig, mqttCfg Config) string {
+	if mqttCfg.ClientID != "" {
+		return mqttCfg.ClientID
+	}
+	id := strings.ReplaceAll(cfg.ID, "-", "")
+	if len(id) > 17 {
+		id = id[:17]
+	}
+	return "memoh-" + id
+}
+
+// publishClientID returns a client ID for a short-lived publishing
+// connection. It differs from clientID because a broker drops the older of
+// two connections sharing an ID.
+func publishClientID(cfg channel.ChannelConfig, mqttCfg Config) string {
+	id := clientID(cfg, mqttCfg)
+	if len(id) > 22 {
+		id = id[:22]
+	}
+	return id + "p"
+}
+
+// --- Sender ---
+
+// Send publishes the message text to the target topic. The topic must be
+// the reply topic or match one of the configured publish topics.
+func (a *Adapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.PreparedOutboundMessage) error {
+	mqttCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	topic := normalizeTarget(msg.Target)
+	if topic == defaultTarget {
+		topic = mqttCfg.ReplyTopic
+	}
+	if err := validateTopic(topic); err != nil {
+		return fmt.Errorf("mqtt target: %w", err)
+	}
+	if !mqttCfg.canPublish(topic) {
+		return fmt.Errorf("mqtt topic %q is not in the channel's publish topics", topic)
+	}
+	payload := strings.TrimSpace(msg.Message.Message.PlainText())
+	if payload == "" {
+		return errors.New("message text is required")
+	}
+
+	a.mu.RLock()
+	c := a.conns[cfg.ID]
+	a.mu.RUnlock()
+	if c == nil {
+		// Not connected, e.g. while reconnecting: publish over a short-lived
+		// connection instead.
+		c, err = dial(ctx, clientOptions{
+			address:  mqttCfg.Address,
+			useTLS:   mqttCfg.TLS,
+			clientID: publishClientID(cfg, mqttCfg),
+			username: mqttCfg.Username,
+			password: mqttCfg.Password,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = c.Close() }()
+	}
+	if err := c.Publish(ctx, topic, []byte(payload), mqttCfg.QoS, false); err != nil {
+		a.logger.Error("publish failed", slog.String("config_id", cfg.ID), slog.String("topic", topic), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// --- StreamSender (block-streaming: buffer deltas, send final as one message) ---
+
+// OpenStream opens a block-streaming session that publishes the final
+// message once when the stream is closed.
+func (a *Adapter) OpenStream(_ context.Context, cfg channel.ChannelConfig, target string, _ channel.StreamOptions) (channel.PreparedOutboundStream, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, errors.New("mqtt target is required")
+	}
+	return &blockStream{adapter: a, cfg: cfg, target: target}, nil
+}
+
+// blockStream buffers streaming deltas and publishes the final message as
+// one Send call when the stream is closed.
+type blockStream struct {
+	adapter     *Adapter
+	cfg         channel.ChannelConfig
+	target      string
+	textBuilder strings.Builder
+	final       *channel.PreparedMessage
+	closed      bool
+}
+
+func (s *blockStream) Push(_ context.Context, event channel.PreparedStreamEvent) error {
+	if s.closed {
+		return nil
+	}
+	switch event.Type {
+	case channel.StreamEventDelta:
+		if event.Phase != channel.StreamPhaseReasoning {
+			s.textBuilder.WriteString(event.Delta)
+		}
+	case channel.StreamEventFinal:
+		if event.Final != nil {
+			msg := event.Final.Message
+			s.final = &msg
+		}
+	}
+	return nil
+}
+
+func (s *blockStream) Close(ctx context.Context) error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	prepared := channel.PreparedMessage{Message: channel.Message{Format: channel.MessageFormatPlain}}
+	if s.final != nil {
+		prepared = *s.final
+	}
+	if strings.TrimSpace(prepared.Message.Text) == "" {
+		prepared.Message.Text = strings.TrimSpace(s.textBuilder.String())
+	}
+	if strings.TrimSpace(prepared.Message.PlainText()) == "" {
+		return nil
+	}
+	return s.adapter.Send(ctx, s.cfg, channel.PreparedOutboundMessage{
+		Target:  s.target,
+		Message: prepared,
+	})
+}
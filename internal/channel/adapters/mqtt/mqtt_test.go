@@ -0,0 +1,185 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	got, err := normalizeConfig(map[string]any{
+		"brokerURL":       "mqtts://ha.local",
+		"username":        "memoh",
+		"password":        "secret",
+		"subscribeTopics": "memoh/ask, homeassistant/event/+\nmemoh/ask",
+		"replyTopic":      "mqtt:memoh/reply",
+		"publishTopics":   []any{"homeassistant/+/+/set"},
+		"qos":             float64(1),
+	})
+	if err != nil {
+		t.Fatalf("normalizeConfig: %v", err)
+	}
+	if got["brokerURL"] != "mqtts://ha.local:8883" || got["subscribeTopics"] != "memoh/ask,homeassistant/event/+" ||
+		got["replyTopic"] != "memoh/reply" || got["publishTopics"] != "homeassistant/+/+/set" || got["qos"] != "1" {
+		t.Fatalf("normalized = %v", got)
+	}
+	base := func(overrides map[string]any) map[string]any {
+		raw := map[string]any{"brokerURL": "mqtt://ha.local", "subscribeTopics": "memoh/ask", "replyTopic": "memoh/reply"}
+		for k, v := range overrides {
+			raw[k] = v
+		}
+		return raw
+	}
+	for name, raw := range map[string]map[string]any{
+		"missing broker":      base(map[string]any{"brokerURL": ""}),
+		"bad scheme":          base(map[string]any{"brokerURL": "http://ha.local"}),
+		"missing subscribe":   base(map[string]any{"subscribeTopics": ""}),
+		"bad filter":          base(map[string]any{"subscribeTopics": "home/#/x"}),
+		"partial wildcard":    base(map[string]any{"publishTopics": "home/light+"}),
+		"wildcard reply":      base(map[string]any{"replyTopic": "memoh/+"}),
+		"long client id":      base(map[string]any{"clientID": "abcdefghijklmnopqrstuvwxyz"}),
+		"password no user":    base(map[string]any{"password": "secret"}),
+		"unsupported qos":     base(map[string]any{"qos": "2"}),
+		"missing reply topic": base(map[string]any{"replyTopic": ""}),
+	} {
+		if _, err := normalizeConfig(raw); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestTopicMatches(t *testing.T) {
+	cases := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"home/+/set", "home/light/set", true},
+		{"home/+/set", "home/light/kitchen/set", false},
+		{"home/#", "home", true},
+		{"home/#", "home/light/kitchen", true},
+		{"home/light", "home/light/set", false},
+		{"#", "$SYS/broker/uptime", false},
+		{"+/broker", "$SYS/broker", false},
+		{"$SYS/#", "$SYS/broker", true},
+	}
+	for _, tc := range cases {
+		if got := topicMatches(tc.filter, tc.topic); got != tc.want {
+			t.Errorf("topicMatches(%q, %q) = %v, want %v", tc.filter, tc.topic, got, tc.want)
+		}
+	}
+}
+
+func TestBuildInboundMessage(t *testing.T) {
+	cfg := Config{
+		SubscribeTopics: []string{"home/#"},
+		ReplyTopic:      "home/memoh/reply",
+		PublishTopics:   []string{"home/+/set"},
+	}
+	msg, ok := buildInboundMessage(cfg, message{topic: "home/door/event", payload: []byte(" front door opened \n")})
+	if !ok {
+		t.Fatal("event was skipped")
+	}
+	if msg.Message.Text != "front door opened" || msg.ReplyTarget != "home/memoh/reply" ||
+		msg.Sender.SubjectID != "home/door/event" || msg.Conversation.Type != channel.ConversationTypePrivate {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	for name, skipped := range map[string]message{
+		"retained":   {topic: "home/door/state", payload: []byte("closed"), retained: true},
+		"own reply":  {topic: "home/memoh/reply", payload: []byte("done")},
+		"own cmd":    {topic: "home/light/set", payload: []byte("ON")},
+		"binary":     {topic: "home/cam/image", payload: []byte{0xff, 0xd8, 0xff}},
+		"empty text": {topic: "home/door/event", payload: []byte("  ")},
+	} {
+		if _, ok := buildInboundMessage(cfg, skipped); ok {
+			t.Errorf("%s: expected message to be skipped", name)
+		}
+	}
+}
+
+func TestClientSubscribeAndPublish(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	published := make(chan message, 1)
+	go fakeBroker(t, ln, published)
+
+	received := make(chan message, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := dial(ctx, clientOptions{address: ln.Addr().String(), clientID: "memoh-test", username: "u", password: "p"}, func(msg message) {
+		received <- msg
+	})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if err := c.Subscribe(ctx, []string{"home/#"}, 1); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	select {
+	case msg := <-received:
+		if msg.topic != "home/door/event" || string(msg.payload) != "opened" {
+			t.Fatalf("received = %+v", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("no message from broker")
+	}
+
+	if err := c.Publish(ctx, "home/light/set", []byte("ON"), 1, false); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	msg := <-published
+	if msg.topic != "home/light/set" || string(msg.payload) != "ON" {
+		t.Fatalf("published = %+v", msg)
+	}
+}
+
+// fakeBroker accepts one client, acknowledges its connect and subscribe,
+// delivers one QoS 1 message, and reports what the client publishes.
+func fakeBroker(t *testing.T, ln net.Listener, published chan<- message) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	send := func(header byte, body []byte) {
+		packet := appendRemainingLength([]byte{header}, len(body))
+		_, _ = conn.Write(append(packet, body...))
+	}
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch header >> 4 {
+		case packetConnect:
+			send(packetConnack<<4, []byte{0, 0})
+		case packetSubscribe:
+			send(packetSuback<<4, append(body[:2:2], 1))
+			payload := appendString(nil, "home/door/event")
+			payload = binary.BigEndian.AppendUint16(payload, 7)
+			send(packetPublish<<4|1<<1, append(payload, "opened"...))
+		case packetPublish:
+			topic, rest, err := readString(body)
+			if err != nil {
+				t.Errorf("read topic: %v", err)
+				return
+			}
+			send(packetPuback<<4, rest[:2])
+			published <- message{topic: topic, payload: rest[2:]}
+		case packetPuback, packetPingreq:
+		case packetDisconnect:
+			return
+		}
+	}
+}
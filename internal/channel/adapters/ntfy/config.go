@@ -0,0 +1,127 @@
+package ntfy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+const (
+	defaultServerURL = "https://ntfy.sh"
+	// defaultTarget sends to the topic in the bot's channel configuration.
+	defaultTarget = "default"
+)
+
+// topicPattern matches the topic names ntfy accepts.
+var topicPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// priorities maps the configurable priority names to ntfy's levels.
+var priorities = map[string]int{
+	"min":     1,
+	"low":     2,
+	"default": 3,
+	"high":    4,
+	"urgent":  5,
+}
+
+// Config is a bot's ntfy channel configuration. A server needs either an
+// access token or a username and password when the topic is protected.
+type Config struct {
+	ServerURL   string
+	Topic       string
+	AccessToken string `json:"AccessToken"` //nolint:gosec // G117: token field, handled securely
+	Username    string
+	Password    string `json:"Password"` //nolint:gosec // G117: password field, handled securely
+	Priority    string
+}
+
+func (c Config) publishURL() string {
+	return strings.TrimRight(c.ServerURL, "/")
+}
+
+// UserConfig binds a user to their own topic on the bot's server.
+type UserConfig struct {
+	Topic string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"serverURL":   cfg.ServerURL,
+		"topic":       cfg.Topic,
+		"accessToken": cfg.AccessToken,
+		"username":    cfg.Username,
+		"password":    cfg.Password,
+		"priority":    cfg.Priority,
+	}, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"topic": cfg.Topic}, nil
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	return cfg.Topic, nil
+}
+
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, string(Type)+":")
+	return strings.TrimSpace(value)
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	serverURL := strings.TrimSpace(channel.ReadString(raw, "serverURL", "server_url"))
+	if serverURL == "" {
+		serverURL = defaultServerURL
+	}
+	parsed, err := url.Parse(serverURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Config{}, errors.New("ntfy serverURL must be an http(s) URL")
+	}
+	topic := normalizeTarget(channel.ReadString(raw, "topic"))
+	if !topicPattern.MatchString(topic) {
+		return Config{}, errors.New("ntfy topic is required and may only contain letters, digits, - and _")
+	}
+	cfg := Config{
+		ServerURL:   strings.TrimRight(serverURL, "/"),
+		Topic:       topic,
+		AccessToken: strings.TrimSpace(channel.ReadString(raw, "accessToken", "access_token")),
+		Username:    strings.TrimSpace(channel.ReadString(raw, "username")),
+		Password:    channel.ReadString(raw, "password"),
+		Priority:    strings.ToLower(strings.TrimSpace(channel.ReadString(raw, "priority"))),
+	}
+	if cfg.AccessToken != "" && cfg.Username != "" {
+		return Config{}, errors.New("ntfy accepts either an accessToken or a username and password, not both")
+	}
+	if cfg.Username != "" && cfg.Password == "" {
+		return Config{}, errors.New("ntfy password is required with username")
+	}
+	if _, ok := priorities[cfg.Priority]; cfg.Priority != "" && !ok {
+		return Config{}, fmt.Errorf("ntfy priority %q is not one of min, low, default, high, urgent", cfg.Priority)
+	}
+	return cfg, nil
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	topic := normalizeTarget(channel.ReadString(raw, "topic"))
+	if !topicPattern.MatchString(topic) {
+		return UserConfig{}, errors.New("ntfy user config requires a valid topic")
+	}
+	return UserConfig{Topic: topic}, nil
+}
@@ -0,0 +1,9 @@
+// Package ntfy implements an outbound channel that publishes a bot's messages
+// to an ntfy topic, so schedule results and alerts reach desktop and phone
+// notifications without a chat platform.
+package ntfy
+
+import "github.com/memohai/memoh/internal/channel"
+
+// Type is the registered ChannelType identifier for ntfy.
+const Type channel.ChannelType = "ntfy"
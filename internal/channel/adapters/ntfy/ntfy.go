@@ -0,0 +1,220 @@
+package ntfy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// maxMessageRunes keeps messages under ntfy's 4096-byte limit, above which
+// the server turns them into file attachments, whatever the script.
+const maxMessageRunes = 1365
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Adapter publishes outbound messages to ntfy topics. It is send-only.
+type Adapter struct {
+	logger *slog.Logger
+	client *http.Client
+}
+
+// NewAdapter creates an ntfy Adapter with the given logger.
+func NewAdapter(log *slog.Logger) *Adapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Adapter{
+		logger: log.With(slog.String("adapter", "ntfy")),
+		client: httpClient,
+	}
+}
+
+// Type returns the ntfy channel type.
+func (*Adapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the ntfy channel metadata.
+func (*Adapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "ntfy",
+		Capabilities: channel.ChannelCapabilities{
+			Text:     true,
+			Markdown: true,
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			TextChunkLimit: maxMessageRunes,
+			ChunkerMode:    channel.ChunkerModeMarkdown,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"serverURL": {
+					Type:        channel.FieldString,
+					Order:       1,
+					Title:       "Server URL",
+					Description: "ntfy server URL. Defaults to https://ntfy.sh.",
+					Example:     "https://ntfy.example.com",
+				},
+				"topic": {
+					Type:        channel.FieldString,
+					Required:    true,
+					Order:       2,
+					Title:       "Topic",
+					Description: "Topic messages are published to when the target is \"default\".",
+					Example:     "memoh-alerts",
+				},
+				"accessToken": {
+					Type:        channel.FieldSecret,
+					Order:       3,
+					Title:       "Access Token",
+					Description: "Token for protected topics. Leave empty for public topics or when using username and password.",
+				},
+				"username": {
+					Type:  channel.FieldString,
+					Order: 4,
+					Title: "Username",
+				},
+				"password": {
+					Type:  channel.FieldSecret,
+					Order: 5,
+					Title: "Password",
+				},
+				"priority": {
+					Type:  channel.FieldEnum,
+					Order: 6,
+					Title: "Priority",
+					Enum:  []string{"min", "low", "default", "high", "urgent"},
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"topic": {Type: channel.FieldString, Required: true},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "default | topic",
+			Hints: []channel.TargetHint{
+				{Label: "Configured topic", Example: defaultTarget},
+				{Label: "Topic", Example: "memoh-alerts"},
+			},
+		},
+	}
+}
+
+// --- ConfigNormalizer ---
+
+// NormalizeConfig validates and normalizes an ntfy channel configuration.
+func (*Adapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a user's ntfy topic binding.
+func (*Adapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// --- TargetResolver ---
+
+// NormalizeTarget strips an optional "ntfy:" prefix from a topic.
+func (*Adapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives the topic from a user's ntfy binding.
+func (*Adapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// ResolveOutboundTarget maps the "default" target to the configured topic.
+func (*Adapter) ResolveOutboundTarget(_ context.Context, cfg channel.ChannelConfig, target string) (string, error) {
+	target = normalizeTarget(target)
+	if target != defaultTarget {
+		return target, nil
+	}
+	ntfyCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return "", err
+	}
+	return ntfyCfg.Topic, nil
+}
+
+// --- Sender ---
+
+type publishRequest struct {
+	Topic    string `json:"topic"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority,omitempty"`
+	Markdown bool   `json:"markdown,omitempty"`
+}
+
+// Send publishes a message to the target topic on the configured server.
+func (a *Adapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.PreparedOutboundMessage) error {
+	ntfyCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	topic := normalizeTarget(msg.Target)
+	if topic == defaultTarget {
+		topic = ntfyCfg.Topic
+	}
+	if !topicPattern.MatchString(topic) {
+		return fmt.Errorf("invalid ntfy topic %q", topic)
+	}
+	message := msg.Message.Message
+	text := strings.TrimSpace(message.Text)
+	markdown := message.Format == channel.MessageFormatMarkdown
+	if text == "" || len(message.Parts) > 0 {
+		text = strings.TrimSpace(message.PlainText())
+		markdown = false
+	}
+	if text == "" {
+		return errors.New("message text is required")
+	}
+	body, err := json.Marshal(publishRequest{
+		Topic:    topic,
+		Message:  text,
+		Priority: priorities[ntfyCfg.Priority],
+		Markdown: markdown,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ntfyCfg.publishURL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case ntfyCfg.AccessToken != "":
+		req.Header.Set("Authorization", "Bearer "+ntfyCfg.AccessToken)
+	case ntfyCfg.Username != "":
+		req.SetBasicAuth(ntfyCfg.Username, ntfyCfg.Password)
+	}
+	resp, err := a.client.Do(req) //nolint:gosec // G704: URL is user-configured, validated at config level
+	if err != nil {
+		return fmt.Errorf("ntfy publish: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		a.logger.Error("publish failed", slog.String("config_id", cfg.ID), slog.Int("status", resp.StatusCode))
+		return fmt.Errorf("ntfy publish: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
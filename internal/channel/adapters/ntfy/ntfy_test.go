@@ -0,0 +1,87 @@
+package ntfy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	got, err := normalizeConfig(map[string]any{"topic": "ntfy:alerts", "priority": "HIGH"})
+	if err != nil {
+		t.Fatalf("normalizeConfig: %v", err)
+	}
+	if got["serverURL"] != defaultServerURL || got["topic"] != "alerts" || got["priority"] != "high" {
+		t.Fatalf("normalized = %v", got)
+	}
+	for name, raw := range map[string]map[string]any{
+		"missing topic":   {},
+		"bad topic":       {"topic": "a/b"},
+		"bad server":      {"topic": "alerts", "serverURL": "ftp://ntfy.local"},
+		"bad priority":    {"topic": "alerts", "priority": "loud"},
+		"token and basic": {"topic": "alerts", "accessToken": "tk", "username": "u", "password": "p"},
+		"user no pass":    {"topic": "alerts", "username": "u"},
+	} {
+		if _, err := normalizeConfig(raw); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestSendPublishesToTopic(t *testing.T) {
+	var got publishRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		if got.Topic == "forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"forbidden"}`))
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewAdapter(nil)
+	cfg := channel.ChannelConfig{ID: "cfg-1", ChannelType: Type, Credentials: map[string]any{
+		"serverURL":   server.URL + "/",
+		"topic":       "alerts",
+		"accessToken": "tk_secret",
+		"priority":    "urgent",
+	}}
+	target, err := adapter.ResolveOutboundTarget(context.Background(), cfg, "default")
+	if err != nil || target != "alerts" {
+		t.Fatalf("ResolveOutboundTarget = %q, %v", target, err)
+	}
+	msg := channel.PreparedOutboundMessage{
+		Target: target,
+		Message: channel.PreparedMessage{Message: channel.Message{
+			Format: channel.MessageFormatMarkdown,
+			Text:   "**Backup** finished",
+		}},
+	}
+	if err := adapter.Send(context.Background(), cfg, msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Topic != "alerts" || got.Message != "**Backup** finished" || got.Priority != 5 || !got.Markdown {
+		t.Fatalf("published = %+v", got)
+	}
+	if auth != "Bearer tk_secret" {
+		t.Fatalf("authorization = %q", auth)
+	}
+
+	msg.Target = "forbidden"
+	if err := adapter.Send(context.Background(), cfg, msg); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("Send to forbidden topic error = %v", err)
+	}
+	msg.Target = "../admin"
+	if err := adapter.Send(context.Background(), cfg, msg); err == nil {
+		t.Fatal("Send to invalid topic succeeded")
+	}
+}
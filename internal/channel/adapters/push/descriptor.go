@@ -0,0 +1,8 @@
+// Package push implements the outbound channel that delivers a bot's
+// messages to the companion mobile app as push notifications.
+package push
+
+import "github.com/memohai/memoh/internal/channel"
+
+// Type is the registered ChannelType identifier for push notifications.
+const Type channel.ChannelType = "push"
@@ -0,0 +1,100 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+	pushsvc "github.com/memohai/memoh/internal/push"
+)
+
+// Notifier sends a notification to a user's devices registered for a bot.
+type Notifier interface {
+	Notify(ctx context.Context, botID, userID string, n pushsvc.Notification) error
+}
+
+// Adapter delivers outbound messages, such as proactive messages and
+// schedule results, to the target user's companion app devices. It is
+// send-only: replies come back through the app's chat.
+type Adapter struct {
+	notifier Notifier
+}
+
+// NewAdapter creates a push Adapter backed by the given notifier.
+func NewAdapter(notifier Notifier) *Adapter {
+	return &Adapter{notifier: notifier}
+}
+
+// Type returns the push channel type.
+func (*Adapter) Type() channel.ChannelType {
+	return Type
+}
+
+// Descriptor returns the push channel metadata.
+func (*Adapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Mobile Push",
+		Configless:  true,
+		Capabilities: channel.ChannelCapabilities{
+			Text: true,
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"user_id": {Type: channel.FieldString, Required: true},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "user_id",
+			Hints: []channel.TargetHint{
+				{Label: "User ID", Example: "8c1f0e7a-2b3d-4c5e-9f60-7a8b9c0d1e2f"},
+			},
+		},
+	}
+}
+
+// --- TargetResolver ---
+
+// NormalizeTarget strips an optional "push:" prefix from a target user ID.
+func (*Adapter) NormalizeTarget(raw string) string {
+	return normalizeTarget(raw)
+}
+
+// ResolveTarget derives the target user ID from a push binding configuration.
+func (*Adapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	userID := normalizeTarget(channel.ReadString(userConfig, "user_id", "userId"))
+	if userID == "" {
+		return "", errors.New("push user_id is required")
+	}
+	return userID, nil
+}
+
+// --- Sender ---
+
+// Send notifies the target user's unmuted devices registered for the bot.
+func (a *Adapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.PreparedOutboundMessage) error {
+	if a.notifier == nil {
+		return errors.New("push notifications not configured")
+	}
+	userID := normalizeTarget(msg.Target)
+	if userID == "" {
+		return errors.New("push target is required")
+	}
+	text := strings.TrimSpace(msg.Message.Message.PlainText())
+	if text == "" {
+		return errors.New("message text is required")
+	}
+	botID := strings.TrimSpace(cfg.BotID)
+	return a.notifier.Notify(ctx, botID, userID, pushsvc.Notification{
+		Body: text,
+		Data: map[string]string{"bot_id": botID},
+	})
+}
+
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, string(Type)+":")
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,56 @@
+package push
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+	pushsvc "github.com/memohai/memoh/internal/push"
+)
+
+type fakeNotifier struct {
+	botID  string
+	userID string
+	sent   []pushsvc.Notification
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, botID, userID string, n pushsvc.Notification) error {
+	f.botID, f.userID = botID, userID
+	f.sent = append(f.sent, n)
+	return nil
+}
+
+func TestSendNotifiesTargetUser(t *testing.T) {
+	notifier := &fakeNotifier{}
+	adapter := NewAdapter(notifier)
+	cfg := channel.ChannelConfig{BotID: "bot-1", ChannelType: Type}
+	msg := channel.PreparedOutboundMessage{
+		Target:  "push:user-1",
+		Message: channel.PreparedMessage{Message: channel.Message{Text: " Daily summary is ready "}},
+	}
+	if err := adapter.Send(context.Background(), cfg, msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if notifier.botID != "bot-1" || notifier.userID != "user-1" {
+		t.Fatalf("notified bot=%q user=%q", notifier.botID, notifier.userID)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].Body != "Daily summary is ready" || notifier.sent[0].Data["bot_id"] != "bot-1" {
+		t.Fatalf("sent = %+v", notifier.sent)
+	}
+
+	msg.Target = " "
+	if err := adapter.Send(context.Background(), cfg, msg); err == nil {
+		t.Fatal("Send without target succeeded")
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	adapter := NewAdapter(nil)
+	target, err := adapter.ResolveTarget(map[string]any{"user_id": "push:user-1"})
+	if err != nil || target != "user-1" {
+		t.Fatalf("ResolveTarget = %q, %v", target, err)
+	}
+	if _, err := adapter.ResolveTarget(map[string]any{}); err == nil {
+		t.Fatal("ResolveTarget without user_id succeeded")
+	}
+}
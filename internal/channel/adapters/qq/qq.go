@@ -101,6 +101,7 @@ func (*QQAdapter) Descriptor() channel.Descriptor {
 			ChunkerMode:         channel.ChunkerModeMarkdown,
 			MediaOrder:          channel.OutboundOrderTextFirst,
 			InlineTextWithMedia: true,
+			ReplyPacingMs:       1500,
 		},
 		ConfigSchema: channel.ConfigSchema{
 			Version: 1,
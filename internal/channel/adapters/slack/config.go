@@ -7,10 +7,17 @@ import (
 	"github.com/memohai/memoh/internal/channel"
 )
 
+const (
+	inboundModeSocket  = "socket"
+	inboundModeWebhook = "webhook"
+)
+
 // Config holds the Slack bot credentials extracted from a channel configuration.
 type Config struct {
-	BotToken string // xoxb-...
-	AppToken string // xapp-... (required for Socket Mode)
+	BotToken      string // xoxb-...
+	AppToken      string // xapp-... (required for Socket Mode)
+	SigningSecret string // required for Events API webhooks
+	InboundMode   string
 }
 
 // UserConfig holds the identifiers used to target a Slack user or channel.
@@ -25,10 +32,17 @@ func normalizeConfig(raw map[string]any) (map[string]any, error) {
 	if err != nil {
 		return nil, err
 	}
-	return map[string]any{
-		"botToken": cfg.BotToken,
-		"appToken": cfg.AppToken,
-	}, nil
+	result := map[string]any{
+		"botToken":    cfg.BotToken,
+		"inboundMode": cfg.InboundMode,
+	}
+	if cfg.AppToken != "" {
+		result["appToken"] = cfg.AppToken
+	}
+	if cfg.SigningSecret != "" {
+		result["signingSecret"] = cfg.SigningSecret
+	}
+	return result, nil
 }
 
 func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
@@ -104,11 +118,35 @@ func parseConfig(raw map[string]any) (Config, error) {
 	if botToken == "" {
 		return Config{}, errors.New("slack botToken is required")
 	}
+	inboundMode, err := normalizeInboundMode(channel.ReadString(raw, "inboundMode", "inbound_mode"))
+	if err != nil {
+		return Config{}, err
+	}
 	appToken := strings.TrimSpace(channel.ReadString(raw, "appToken", "app_token"))
-	if appToken == "" {
+	if inboundMode == inboundModeSocket && appToken == "" {
 		return Config{}, errors.New("slack appToken is required for Socket Mode")
 	}
-	return Config{BotToken: botToken, AppToken: appToken}, nil
+	signingSecret := strings.TrimSpace(channel.ReadString(raw, "signingSecret", "signing_secret"))
+	if inboundMode == inboundModeWebhook && signingSecret == "" {
+		return Config{}, errors.New("slack signingSecret is required for Events API webhooks")
+	}
+	return Config{
+		BotToken:      botToken,
+		AppToken:      appToken,
+		SigningSecret: signingSecret,
+		InboundMode:   inboundMode,
+	}, nil
+}
+
+func normalizeInboundMode(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", inboundModeSocket:
+		return inboundModeSocket, nil
+	case inboundModeWebhook:
+		return inboundModeWebhook, nil
+	default:
+		return "", errors.New("slack inbound_mode must be socket or webhook")
+	}
 }
 
 func parseUserConfig(raw map[string]any) (UserConfig, error) {
@@ -18,6 +18,7 @@ import (
 
 	"github.com/memohai/memoh/internal/channel"
 	"github.com/memohai/memoh/internal/channel/common"
+	"github.com/memohai/memoh/internal/channel/webhookauth"
 	"github.com/memohai/memoh/internal/media"
 )
 
@@ -51,6 +52,7 @@ type cachedSlackChannelName struct {
 
 type cachedSlackUserName struct {
 	displayName string
+	timezone    string
 	cachedAt    time.Time
 }
 
@@ -61,6 +63,8 @@ type SlackAdapter struct {
 	seenMessages     map[string]time.Time              // keyed by configID:messageTS
 	channelNames     map[string]cachedSlackChannelName // keyed by configID:channelID
 	userNames        map[string]cachedSlackUserName    // keyed by configID:userID
+	selfUserIDs      map[string]string                 // keyed by config ID, webhook mode only
+	webhookReplays   *webhookauth.ReplayGuard
 	assets           assetOpener
 	apiFactory       func(Config, ...slack.Option) *slack.Client
 	authTest         func(*slack.Client) (*slack.AuthTestResponse, error)
@@ -75,6 +79,7 @@ var (
 	_ channel.StreamSender             = (*SlackAdapter)(nil)
 	_ channel.Reactor                  = (*SlackAdapter)(nil)
 	_ channel.Receiver                 = (*SlackAdapter)(nil)
+	_ channel.WebhookReceiver          = (*SlackAdapter)(nil)
 	_ channel.AttachmentResolver       = (*SlackAdapter)(nil)
 	_ channel.SelfDiscoverer           = (*SlackAdapter)(nil)
 	_ channel.ConfigNormalizer         = (*SlackAdapter)(nil)
@@ -88,11 +93,13 @@ func NewSlackAdapter(log *slog.Logger) *SlackAdapter {
 		log = slog.Default()
 	}
 	return &SlackAdapter{
-		logger:       log.With(slog.String("adapter", "slack")),
-		connections:  make(map[string]*slackConnection),
-		seenMessages: make(map[string]time.Time),
-		channelNames: make(map[string]cachedSlackChannelName),
-		userNames:    make(map[string]cachedSlackUserName),
+		logger:         log.With(slog.String("adapter", "slack")),
+		connections:    make(map[string]*slackConnection),
+		seenMessages:   make(map[string]time.Time),
+		channelNames:   make(map[string]cachedSlackChannelName),
+		userNames:      make(map[string]cachedSlackUserName),
+		selfUserIDs:    make(map[string]string),
+		webhookReplays: webhookauth.NewReplayGuard(webhookMaxClockSkew),
 		apiFactory: func(cfg Config, options ...slack.Option) *slack.Client {
 			opts := []slack.Option{
 				slack.OptionRetry(3),
@@ -158,10 +165,21 @@ func (*SlackAdapter) Descriptor() channel.Descriptor {
 				},
 				"appToken": {
 					Type:        channel.FieldSecret,
-					Required:    true,
 					Title:       "App-Level Token",
 					Description: "Slack App-Level Token for Socket Mode (xapp-...)",
 				},
+				"signingSecret": {
+					Type:        channel.FieldSecret,
+					Title:       "Signing Secret",
+					Description: "Verifies Events API webhook requests; required in webhook mode",
+				},
+				"inboundMode": {
+					Type:        channel.FieldEnum,
+					Title:       "Inbound Mode",
+					Description: "Receive events over Socket Mode or Events API webhooks",
+					Enum:        []string{inboundModeSocket, inboundModeWebhook},
+					Example:     inboundModeSocket,
+				},
 			},
 		},
 		UserConfigSchema: channel.ConfigSchema{
@@ -240,6 +258,13 @@ func (a *SlackAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig, h
 		return nil, err
 	}
 
+	if slackCfg.InboundMode == inboundModeWebhook {
+		if a.logger != nil {
+			a.logger.Info("webhook mode enabled; socket mode connect skipped", slog.String("config_id", cfg.ID))
+		}
+		return channel.NewConnection(cfg, func(context.Context) error { return nil }), nil
+	}
+
 	conn, err := a.getOrCreateConnection(cfg, slackCfg)
 	if err != nil {
 		return nil, err
@@ -373,6 +398,10 @@ func (a *SlackAdapter) handleSocketModeEvent(
 			a.handleMessageEvent(ctx, conn, ev, cfg, handler, selfUserID)
 		case *slackevents.AppMentionEvent:
 			a.handleAppMentionEvent(ctx, conn, ev, cfg, handler, selfUserID)
+		case *slackevents.MemberLeftChannelEvent:
+			a.handleMembershipEvent(ctx, cfg, handler, channel.MembershipEventBotRemoved, ev.User, ev.Channel, selfUserID)
+		case *slackevents.MemberJoinedChannelEvent:
+			a.handleMembershipEvent(ctx, cfg, handler, channel.MembershipEventBotAdded, ev.User, ev.Channel, selfUserID)
 		}
 
 	case socketmode.EventTypeConnecting:
@@ -440,7 +469,7 @@ func (a *SlackAdapter) handleMessageEvent(
 	}
 
 	// Resolve user display name
-	displayName := a.resolveUserDisplayName(conn.api, cfg.ID, ev.User)
+	displayName, timezone := a.resolveUserProfile(conn.api, cfg.ID, ev.User)
 
 	isMentioned := strings.Contains(ev.Text, "<@"+selfUserID+">")
 
@@ -494,6 +523,9 @@ func (a *SlackAdapter) handleMessageEvent(
 			"subtype":         ev.SubType,
 		},
 	}
+	if timezone != "" {
+		msg.Sender.Attributes["timezone"] = timezone
+	}
 
 	if a.logger != nil {
 		a.logger.Info("inbound received",
@@ -511,6 +543,41 @@ func (a *SlackAdapter) handleMessageEvent(
 	}()
 }
 
+// handleMembershipEvent reports the bot leaving or joining a channel. Slack
+// sends member events for every member, so events about other users are
+// ignored.
+func (a *SlackAdapter) handleMembershipEvent(
+	ctx context.Context,
+	cfg channel.ChannelConfig,
+	handler channel.InboundHandler,
+	event string,
+	userID string,
+	channelID string,
+	selfUserID string,
+) {
+	userID = strings.TrimSpace(userID)
+	channelID = strings.TrimSpace(channelID)
+	if userID == "" || channelID == "" || userID != strings.TrimSpace(selfUserID) {
+		return
+	}
+	msg := channel.NewMembershipEvent(cfg, Type, event, channel.Conversation{
+		ID:   channelID,
+		Type: channel.ConversationTypeGroup,
+	}, channel.Identity{})
+	if a.logger != nil {
+		a.logger.Info("membership event received",
+			slog.String("config_id", cfg.ID),
+			slog.String("event", event),
+			slog.String("channel_id", channelID),
+		)
+	}
+	go func() {
+		if err := handler(ctx, cfg, msg); err != nil && a.logger != nil {
+			a.logger.Error("handle inbound failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+	}()
+}
+
 func (a *SlackAdapter) handleAppMentionEvent(
 	ctx context.Context,
 	conn *slackConnection,
@@ -534,7 +601,7 @@ func (a *SlackAdapter) handleAppMentionEvent(
 		return
 	}
 
-	displayName := a.resolveUserDisplayName(conn.api, cfg.ID, ev.User)
+	displayName, timezone := a.resolveUserProfile(conn.api, cfg.ID, ev.User)
 
 	threadID := ev.ThreadTimeStamp
 	conversationName, conversationType := a.lookupConversationInfo(ctx, conn.api, cfg.ID, ev.Channel)
@@ -576,6 +643,9 @@ func (a *SlackAdapter) handleAppMentionEvent(
 			"thread_ts":    threadID,
 		},
 	}
+	if timezone != "" {
+		msg.Sender.Attributes["timezone"] = timezone
+	}
 
 	if a.logger != nil {
 		a.logger.Info("app mention received",
@@ -1157,10 +1227,17 @@ func (a *SlackAdapter) clearConnection(appToken string) {
 }
 
 func (a *SlackAdapter) resolveUserDisplayName(api *slack.Client, configID, userID string) string {
+	displayName, _ := a.resolveUserProfile(api, configID, userID)
+	return displayName
+}
+
+// resolveUserProfile returns the user's display name and the IANA timezone
+// from their Slack profile ("" when unknown).
+func (a *SlackAdapter) resolveUserProfile(api *slack.Client, configID, userID string) (string, string) {
 	configID = strings.TrimSpace(configID)
 	userID = strings.TrimSpace(userID)
 	if api == nil || configID == "" || userID == "" {
-		return userID
+		return userID, ""
 	}
 	cacheKey := configID + ":" + userID
 
@@ -1170,12 +1247,12 @@ func (a *SlackAdapter) resolveUserDisplayName(api *slack.Client, configID, userI
 	cached, ok := a.userNames[cacheKey]
 	a.mu.RUnlock()
 	if ok && cached.cachedAt.After(expireBefore) {
-		return cached.displayName
+		return cached.displayName, cached.timezone
 	}
 
 	userInfo, err := api.GetUserInfo(userID)
 	if err != nil {
-		return userID
+		return userID, ""
 	}
 	displayName := strings.TrimSpace(userInfo.Profile.DisplayName)
 	if displayName == "" {
@@ -1188,10 +1265,12 @@ func (a *SlackAdapter) resolveUserDisplayName(api *slack.Client, configID, userI
 		displayName = userID
 	}
 
+	timezone := strings.TrimSpace(userInfo.TZ)
+
 	a.mu.Lock()
-	a.userNames[cacheKey] = cachedSlackUserName{displayName: displayName, cachedAt: time.Now().UTC()}
+	a.userNames[cacheKey] = cachedSlackUserName{displayName: displayName, timezone: timezone, cachedAt: time.Now().UTC()}
 	a.mu.Unlock()
-	return displayName
+	return displayName, timezone
 }
 
 func (*SlackAdapter) collectAttachments(msg *slack.Msg) []channel.Attachment {
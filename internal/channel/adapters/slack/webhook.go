@@ -0,0 +1,158 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/webhookauth"
+)
+
+const (
+	webhookMaxBodyBytes int64 = 1 << 20 // 1 MiB
+	// webhookMaxClockSkew bounds the request timestamp age, as Slack
+	// recommends, so captured requests cannot be replayed later.
+	webhookMaxClockSkew = 5 * time.Minute
+
+	slackSignatureHeader = "X-Slack-Signature"
+	slackTimestampHeader = "X-Slack-Request-Timestamp"
+)
+
+// HandleWebhook processes Slack Events API callbacks. Requests are verified
+// with the app's signing secret; the url_verification handshake is answered
+// inline and message events go through the same handlers as Socket Mode.
+func (a *SlackAdapter) HandleWebhook(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler, r *http.Request, w http.ResponseWriter) error {
+	if a == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "slack adapter is nil")
+	}
+	if handler == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "slack inbound handler is nil")
+	}
+	if r.Method != http.MethodPost {
+		return echo.NewHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+	slackCfg, err := parseConfig(cfg.Credentials)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if slackCfg.InboundMode != inboundModeWebhook {
+		return echo.NewHTTPError(http.StatusBadRequest, "slack inbound_mode is not webhook")
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, webhookMaxBodyBytes+1))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("read body: %v", err))
+	}
+	if int64(len(payload)) > webhookMaxBodyBytes {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("payload too large: max %d bytes", webhookMaxBodyBytes))
+	}
+	now := time.Now()
+	if err := verifySlackSignature(r.Header, payload, slackCfg.SigningSecret, now); err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+	// A resent copy of a request already handled is acknowledged unhandled.
+	if !a.webhookReplays.Claim(cfg.ID+":"+r.Header.Get(slackSignatureHeader), now) {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+
+	var envelope struct {
+		Type      string `json:"type"`
+		Challenge string `json:"challenge"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid slack event payload: %v", err))
+	}
+	if envelope.Type == slackevents.URLVerification {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(envelope.Challenge))
+		return nil
+	}
+
+	event, err := slackevents.ParseEvent(json.RawMessage(payload), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid slack event payload: %v", err))
+	}
+	if event.Type == slackevents.CallbackEvent {
+		// Slack expects an answer within three seconds and the handlers
+		// finish asynchronously, so they must outlive the request.
+		eventCtx := context.WithoutCancel(ctx)
+		conn := &slackConnection{api: a.newAPIClient(slackCfg)}
+		selfUserID := a.webhookSelfUserID(cfg.ID, conn)
+		switch ev := event.InnerEvent.Data.(type) {
+		case *slackevents.MessageEvent:
+			a.handleMessageEvent(eventCtx, conn, ev, cfg, handler, selfUserID)
+		case *slackevents.AppMentionEvent:
+			a.handleAppMentionEvent(eventCtx, conn, ev, cfg, handler, selfUserID)
+		case *slackevents.MemberLeftChannelEvent:
+			a.handleMembershipEvent(eventCtx, cfg, handler, channel.MembershipEventBotRemoved, ev.User, ev.Channel, selfUserID)
+		case *slackevents.MemberJoinedChannelEvent:
+			a.handleMembershipEvent(eventCtx, cfg, handler, channel.MembershipEventBotAdded, ev.User, ev.Channel, selfUserID)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// webhookSelfUserID returns the bot's own user ID, cached per config, so the
+// message handlers can ignore the bot's messages and detect mentions.
+func (a *SlackAdapter) webhookSelfUserID(configID string, conn *slackConnection) string {
+	a.mu.RLock()
+	selfUserID, ok := a.selfUserIDs[configID]
+	a.mu.RUnlock()
+	if ok {
+		return selfUserID
+	}
+	if a.authTest == nil {
+		return ""
+	}
+	resp, err := a.authTest(conn.api)
+	if err != nil || resp == nil {
+		if a.logger != nil {
+			a.logger.Warn("slack auth test failed", slog.String("config_id", configID), slog.Any("error", err))
+		}
+		return ""
+	}
+	a.mu.Lock()
+	a.selfUserIDs[configID] = resp.UserID
+	a.mu.Unlock()
+	return resp.UserID
+}
+
+// slackSignatureScheme is the v0 request signature: an HMAC-SHA256 of
+// "v0:<timestamp>:<body>" keyed with the signing secret.
+var slackSignatureScheme = webhookauth.Scheme{
+	SignatureHeader: slackSignatureHeader,
+	Prefix:          "v0=",
+	Encoding:        webhookauth.Hex,
+	TimestampHeader: slackTimestampHeader,
+	MaxSkew:         webhookMaxClockSkew,
+	Sum: func(secret string, header http.Header, body []byte) []byte {
+		timestamp := strings.TrimSpace(header.Get(slackTimestampHeader))
+		return webhookauth.HMACSHA256(secret, []byte("v0:"+timestamp+":"), body)
+	},
+}
+
+func verifySlackSignature(header http.Header, body []byte, signingSecret string, now time.Time) error {
+	err := slackSignatureScheme.Verify(header, body, signingSecret, now)
+	switch {
+	case errors.Is(err, webhookauth.ErrMissingSignature):
+		return errors.New("missing slack signature headers")
+	case errors.Is(err, webhookauth.ErrStaleTimestamp):
+		return errors.New("stale slack request timestamp")
+	case err != nil:
+		return errors.New("invalid slack signature")
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+const testSigningSecret = "test-signing-secret"
+
+func signSlackRequest(req *http.Request, body string, secret string, at time.Time) {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("v0:" + timestamp + ":" + body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+func webhookTestConfig() channel.ChannelConfig {
+	return channel.ChannelConfig{
+		ID:    "cfg-1",
+		BotID: "bot-1",
+		Credentials: map[string]any{
+			"botToken":      testBotToken,
+			"signingSecret": testSigningSecret,
+			"inboundMode":   inboundModeWebhook,
+		},
+	}
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1710000000, 0)
+	body := `{"type":"event_callback"}`
+	tests := []struct {
+		name   string
+		secret string
+		at     time.Time
+		body   string
+		ok     bool
+	}{
+		{name: "valid", secret: testSigningSecret, at: now, body: body, ok: true},
+		{name: "wrong secret", secret: "other", at: now, body: body},
+		{name: "tampered body", secret: testSigningSecret, at: now, body: body + " "},
+		{name: "stale timestamp", secret: testSigningSecret, at: now.Add(-10 * time.Minute), body: body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			signSlackRequest(req, tt.body, tt.secret, tt.at)
+			err := verifySlackSignature(req.Header, []byte(body), testSigningSecret, now)
+			if tt.ok && err != nil {
+				t.Fatalf("expected valid signature, got %v", err)
+			}
+			if !tt.ok && err == nil {
+				t.Fatal("expected signature to be rejected")
+			}
+		})
+	}
+}
+
+func TestSlackParseConfigRequiresSigningSecretInWebhookMode(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseConfig(map[string]any{"botToken": testBotToken, "inboundMode": "webhook"}); err == nil {
+		t.Fatal("expected webhook mode without signingSecret to fail")
+	}
+	cfg, err := parseConfig(map[string]any{"botToken": testBotToken, "signingSecret": testSigningSecret, "inbound_mode": "Webhook"})
+	if err != nil {
+		t.Fatalf("parseConfig returned error: %v", err)
+	}
+	if cfg.InboundMode != inboundModeWebhook || cfg.AppToken != "" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if _, err := parseConfig(map[string]any{"botToken": testBotToken}); err == nil {
+		t.Fatal("expected socket mode without appToken to fail")
+	}
+}
+
+func TestSlackHandleWebhookAnswersURLVerification(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewSlackAdapter(nil)
+	body := `{"type":"url_verification","challenge":"abc123"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	signSlackRequest(req, body, testSigningSecret, time.Now())
+	rec := httptest.NewRecorder()
+
+	err := adapter.HandleWebhook(context.Background(), webhookTestConfig(), func(context.Context, channel.ChannelConfig, channel.InboundMessage) error {
+		return nil
+	}, req, rec)
+	if err != nil {
+		t.Fatalf("HandleWebhook returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK || rec.Body.String() != "abc123" {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestSlackHandleWebhookRejectsUnsignedRequest(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewSlackAdapter(nil)
+	body := `{"type":"url_verification","challenge":"abc123"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	err := adapter.HandleWebhook(context.Background(), webhookTestConfig(), func(context.Context, channel.ChannelConfig, channel.InboundMessage) error {
+		return nil
+	}, req, rec)
+	if err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+}
+
+func TestSlackHandleWebhookAcknowledgesReplayedRequest(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewSlackAdapter(nil)
+	body := `{"type":"url_verification","challenge":"abc123"}`
+	signed := httptest.NewRequest(http.MethodPost, "/", nil)
+	signSlackRequest(signed, body, testSigningSecret, time.Now())
+	handler := func(context.Context, channel.ChannelConfig, channel.InboundMessage) error { return nil }
+
+	var responses []string
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header = signed.Header.Clone()
+		rec := httptest.NewRecorder()
+		if err := adapter.HandleWebhook(context.Background(), webhookTestConfig(), handler, req, rec); err != nil {
+			t.Fatalf("HandleWebhook returned error: %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d", rec.Code)
+		}
+		responses = append(responses, rec.Body.String())
+	}
+	if responses[0] != "abc123" || responses[1] != "" {
+		t.Fatalf("responses = %q, want the replay acknowledged unhandled", responses)
+	}
+}
+
+func TestSlackHandleWebhookDispatchesMessageEvent(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewSlackAdapter(nil)
+	adapter.apiFactory = func(cfg Config, _ ...slack.Option) *slack.Client {
+		return slack.New(
+			cfg.BotToken,
+			slack.OptionAPIURL("https://slack.test/api/"),
+			slack.OptionHTTPClient(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("not found")), Header: make(http.Header)}, nil
+			})}),
+		)
+	}
+	authCalls := 0
+	adapter.authTest = func(*slack.Client) (*slack.AuthTestResponse, error) {
+		authCalls++
+		if authCalls > 1 {
+			return nil, errors.New("auth test should be cached")
+		}
+		return &slack.AuthTestResponse{UserID: "UBOT"}, nil
+	}
+	msgCh := make(chan channel.InboundMessage, 2)
+	handler := func(_ context.Context, _ channel.ChannelConfig, msg channel.InboundMessage) error {
+		msgCh <- msg
+		return nil
+	}
+
+	for i, ts := range []string{"1710000000.000100", "1710000000.000200"} {
+		body := `{"type":"event_callback","team_id":"T1","event":{"type":"message","channel":"D123","channel_type":"im","user":"U123","text":"hello","ts":"` + ts + `"}}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		signSlackRequest(req, body, testSigningSecret, time.Now())
+		rec := httptest.NewRecorder()
+		if err := adapter.HandleWebhook(context.Background(), webhookTestConfig(), handler, req, rec); err != nil {
+			t.Fatalf("HandleWebhook %d returned error: %v", i, err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("HandleWebhook %d status = %d", i, rec.Code)
+		}
+		select {
+		case msg := <-msgCh:
+			if msg.Message.PlainText() != "hello" {
+				t.Fatalf("unexpected message text: %q", msg.Message.PlainText())
+			}
+			if got, _ := msg.Metadata["bot_alias"].(string); got != "UBOT" {
+				t.Fatalf("bot_alias = %q, want UBOT", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for inbound message")
+		}
+	}
+	if authCalls != 1 {
+		t.Fatalf("auth test calls = %d, want 1", authCalls)
+	}
+}
@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel/adaptertest"
+)
+
+// TestRecordedFixtures replays recorded updates through the long poller
+// against a fake Bot API and checks the inbound mapping and the API calls.
+// Other tests swap the package-level bot hooks, so it does not run in
+// parallel.
+func TestRecordedFixtures(t *testing.T) {
+	for _, fx := range adaptertest.LoadFixtures(t, "testdata/fixtures") {
+		t.Run(fx.Name, func(t *testing.T) {
+			api := adaptertest.NewAPIServer(t, fx.Responses)
+			api.Handle("/getUpdates", updatesOnce(t, fx.Updates))
+			cfg := fx.Config(Type)
+			cfg.Credentials["apiBaseURL"] = api.URL
+			adapter := NewTelegramAdapter(nil)
+			inbox := adaptertest.NewInbox()
+
+			conn, err := adapter.Connect(context.Background(), cfg, inbox.Handler)
+			if err != nil {
+				t.Fatalf("Connect: %v", err)
+			}
+			t.Cleanup(func() { _ = conn.Stop(context.Background()) })
+			inbound := inbox.Wait(t, len(fx.Inbound), 5*time.Second)
+			adaptertest.AssertInbound(t, fx, inbound)
+
+			if fx.Reply != nil {
+				if err := adapter.Send(context.Background(), cfg, fx.ReplyMessage(t, inbound)); err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+			}
+			adaptertest.AssertCalls(t, fx, api.Calls())
+		})
+	}
+}
+
+// updatesOnce answers the first getUpdates with the recorded updates and
+// later polls with an empty batch after a short wait.
+func updatesOnce(t *testing.T, updates []json.RawMessage) http.HandlerFunc {
+	t.Helper()
+	first, err := json.Marshal(map[string]any{"ok": true, "result": updates})
+	if err != nil {
+		t.Fatalf("marshal updates: %v", err)
+	}
+	var once sync.Once
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		served := false
+		once.Do(func() {
+			served = true
+			_, _ = w.Write(first)
+		})
+		if served {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(50 * time.Millisecond):
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+	}
+}
@@ -0,0 +1,20 @@
+package telegram
+
+import (
+	tele "gopkg.in/telebot.v4"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// telegramLinkPreviewsDisabled reports whether cfg turns link previews off.
+func telegramLinkPreviewsDisabled(cfg channel.ChannelConfig) bool {
+	return channel.LinkPreviewMode(cfg) == channel.LinkPreviewsOff
+}
+
+// telegramSendOptions returns send options for a text message.
+func telegramSendOptions(parseMode string, noPreview bool) *tele.SendOptions {
+	return &tele.SendOptions{
+		ParseMode:             parseMode,
+		DisableWebPagePreview: noPreview,
+	}
+}
@@ -0,0 +1,17 @@
+package telegram
+
+import "strings"
+
+// MentionID accepts numeric Telegram user IDs; usernames are already
+// linked by Telegram itself.
+func (*TelegramAdapter) MentionID(id string) (string, bool) {
+	id = strings.TrimSpace(id)
+	return id, isTelegramNumericMentionID(id)
+}
+
+// FormatMention renders a markdown link to the user's profile, which
+// Telegram delivers as a text mention that notifies the user.
+func (*TelegramAdapter) FormatMention(id, label string) string {
+	label = strings.NewReplacer("[", "", "]", "").Replace(label)
+	return "[" + label + "](tg://user?id=" + id + ")"
+}
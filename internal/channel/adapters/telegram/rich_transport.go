@@ -57,6 +57,7 @@ func sendTelegramRichMessageReturnMessage(
 	rich telegramInputRichMessage,
 	replyTo int,
 	actions []channel.Action,
+	noPreview bool,
 ) (chatID int64, messageID int, err error) {
 	if !rich.hasContent() {
 		return 0, 0, nil
@@ -81,6 +82,9 @@ func sendTelegramRichMessageReturnMessage(
 	if markup != nil && len(markup.InlineKeyboard) > 0 {
 		payload["reply_markup"] = markup
 	}
+	if noPreview {
+		payload["link_preview_options"] = map[string]any{"is_disabled": true}
+	}
 	data, err := bot.Raw("sendRichMessage", payload)
 	if err != nil {
 		return 0, 0, err
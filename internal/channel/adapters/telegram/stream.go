@@ -117,7 +117,7 @@ func (s *telegramOutboundStream) ensureStreamMessage(ctx context.Context, text s
 	} else {
 		text = strings.TrimSpace(text) + telegramStreamPendingSuffix
 	}
-	chatID, msgID, err := sendTelegramTextReturnMessage(bot, s.target, text, replyTo, s.parseMode)
+	chatID, msgID, err := sendTelegramTextReturnMessage(bot, s.target, text, replyTo, s.parseMode, telegramLinkPreviewsDisabled(s.cfg))
 	if err != nil {
 		s.mu.Unlock()
 		return err
@@ -164,7 +164,7 @@ func (s *telegramOutboundStream) editStreamMessage(ctx context.Context, text str
 	if testEditFunc != nil {
 		editErr = testEditFunc(bot, chatID, msgID, text, s.parseMode)
 	} else {
-		editErr = editTelegramMessageText(bot, chatID, msgID, text, s.parseMode)
+		editErr = editTelegramMessageText(bot, chatID, msgID, text, s.parseMode, telegramLinkPreviewsDisabled(s.cfg))
 	}
 	if editErr != nil {
 		if isTelegramTooManyRequests(editErr) {
@@ -218,7 +218,7 @@ func (s *telegramOutboundStream) editStreamMessageFinal(ctx context.Context, tex
 			// Raw (non-swallowing) edit so an unrecoverable failure is visible
 			// below and the answer can be recovered, instead of being silently
 			// dropped by editTelegramMessageText's no-op-on-unrecoverable wrapper.
-			editErr = rawEditTelegramMessageText(bot, chatID, msgID, text, s.parseMode)
+			editErr = rawEditTelegramMessageText(bot, chatID, msgID, text, s.parseMode, telegramLinkPreviewsDisabled(s.cfg))
 		}
 		// not-modified means the message already shows this text — treat as done.
 		if editErr == nil || isTelegramMessageNotModified(editErr) {
@@ -274,7 +274,7 @@ func (s *telegramOutboundStream) sendDraft(ctx context.Context, text string) err
 		return err
 	}
 
-	draftErr := sendTelegramDraft(bot, s.streamChatID, s.draftID, text, s.parseMode)
+	draftErr := sendTelegramDraft(bot, s.streamChatID, s.draftID, text, s.parseMode, telegramLinkPreviewsDisabled(s.cfg))
 	if draftErr != nil {
 		if isTelegramTooManyRequests(draftErr) {
 			d := getTelegramRetryAfter(draftErr)
@@ -310,9 +310,9 @@ func (s *telegramOutboundStream) sendPermanentMessage(ctx context.Context, text
 	}
 	var sendErr error
 	if parseMode == "" && runeLenTelegramText(text) > telegramMaxMessageLength {
-		sendErr = sendTelegramTextChunksWithActions(bot, s.target, text, replyTo, parseMode, nil)
+		sendErr = sendTelegramTextChunksWithActions(bot, s.target, text, replyTo, parseMode, nil, telegramLinkPreviewsDisabled(s.cfg))
 	} else {
-		sendErr = sendTelegramText(bot, s.target, text, replyTo, parseMode)
+		sendErr = sendTelegramText(bot, s.target, text, replyTo, parseMode, telegramLinkPreviewsDisabled(s.cfg))
 	}
 	if sendErr != nil {
 		return sendErr
@@ -369,7 +369,7 @@ func (s *telegramOutboundStream) deliverFinalTextWithActions(ctx context.Context
 	}
 	if s.isPrivateChat {
 		s.resetStreamState()
-		if err := sendTelegramTextWithActions(bot, s.target, text, replyTo, parseMode, actions); err != nil {
+		if err := sendTelegramTextWithActions(bot, s.target, text, replyTo, parseMode, actions, telegramLinkPreviewsDisabled(s.cfg)); err != nil {
 			return err
 		}
 		s.markDraftPermanentSent()
@@ -385,7 +385,7 @@ func (s *telegramOutboundStream) deliverFinalTextWithActions(ctx context.Context
 	msgID := s.streamMsgID
 	s.mu.Unlock()
 	if msgID > 0 && chatID != 0 {
-		editErr := rawEditTelegramMessageTextWithActions(bot, chatID, msgID, text, parseMode, actions)
+		editErr := rawEditTelegramMessageTextWithActions(bot, chatID, msgID, text, parseMode, actions, telegramLinkPreviewsDisabled(s.cfg))
 		if editErr == nil || isTelegramMessageNotModified(editErr) {
 			s.resetStreamState()
 			return nil
@@ -395,7 +395,7 @@ func (s *telegramOutboundStream) deliverFinalTextWithActions(ctx context.Context
 		}
 	}
 	s.resetStreamState()
-	return sendTelegramTextWithActions(bot, s.target, text, replyTo, parseMode, actions)
+	return sendTelegramTextWithActions(bot, s.target, text, replyTo, parseMode, actions, telegramLinkPreviewsDisabled(s.cfg))
 }
 
 func (s *telegramOutboundStream) pushToolCallStart(ctx context.Context, tc *channel.StreamToolCall) error {
@@ -500,13 +500,13 @@ func (s *telegramOutboundStream) sendToolCallMessage(
 			editErr := error(nil)
 			switch {
 			case p.Status == channel.ToolCallStatusApprovalRequired && len(actions) > 0 && testEditFunc == nil:
-				editErr = editTelegramMessageTextWithActions(bot, existing.chatID, existing.msgID, text, parseMode, actions)
+				editErr = editTelegramMessageTextWithActions(bot, existing.chatID, existing.msgID, text, parseMode, actions, telegramLinkPreviewsDisabled(s.cfg))
 			case (p.Status == channel.ToolCallStatusCompleted || p.Status == channel.ToolCallStatusFailed) && existing.hasActions && testEditFunc == nil:
-				editErr = editTelegramMessageTextWithActions(bot, existing.chatID, existing.msgID, text, parseMode, nil)
+				editErr = editTelegramMessageTextWithActions(bot, existing.chatID, existing.msgID, text, parseMode, nil, telegramLinkPreviewsDisabled(s.cfg))
 			case testEditFunc != nil:
 				editErr = testEditFunc(bot, existing.chatID, existing.msgID, text, parseMode)
 			default:
-				editErr = editTelegramMessageText(bot, existing.chatID, existing.msgID, text, parseMode)
+				editErr = editTelegramMessageText(bot, existing.chatID, existing.msgID, text, parseMode, telegramLinkPreviewsDisabled(s.cfg))
 			}
 			if editErr == nil {
 				if p.Status != channel.ToolCallStatusApprovalRequired {
@@ -539,9 +539,9 @@ func (s *telegramOutboundStream) sendToolCallMessage(
 		sendErr error
 	)
 	if len(actions) > 0 {
-		chatID, msgID, sendErr = sendTelegramTextWithActionsReturnMessage(bot, s.target, text, replyTo, parseMode, actions)
+		chatID, msgID, sendErr = sendTelegramTextWithActionsReturnMessage(bot, s.target, text, replyTo, parseMode, actions, telegramLinkPreviewsDisabled(s.cfg))
 	} else {
-		chatID, msgID, sendErr = sendTelegramTextReturnMessage(bot, s.target, text, replyTo, parseMode)
+		chatID, msgID, sendErr = sendTelegramTextReturnMessage(bot, s.target, text, replyTo, parseMode, telegramLinkPreviewsDisabled(s.cfg))
 	}
 	if sendErr != nil {
 		return sendErr
@@ -738,7 +738,7 @@ func (s *telegramOutboundStream) pushFinal(ctx context.Context, event channel.Pr
 			if err != nil {
 				return err
 			}
-			if err := sendTelegramTextWithActions(bot, s.target, finalText, replyTo, s.parseMode, msg.Message.Actions); err != nil {
+			if err := sendTelegramTextWithActions(bot, s.target, finalText, replyTo, s.parseMode, msg.Message.Actions, telegramLinkPreviewsDisabled(s.cfg)); err != nil {
 				return err
 			}
 			s.markDraftPermanentSent()
@@ -791,7 +791,7 @@ func (s *telegramOutboundStream) pushFinalRich(ctx context.Context, msg channel.
 				return err
 			}
 			s.resetStreamState()
-			return sendTelegramTextWithActions(bot, s.target, fallbackText, replyTo, fallbackParseMode, msg.Message.Actions)
+			return sendTelegramTextWithActions(bot, s.target, fallbackText, replyTo, fallbackParseMode, msg.Message.Actions, telegramLinkPreviewsDisabled(s.cfg))
 		}
 		return s.deliverFinalText(ctx, fallbackText, fallbackParseMode)
 	}
@@ -817,7 +817,7 @@ func (s *telegramOutboundStream) pushFinalRich(ctx context.Context, msg channel.
 		// still sees the final.
 	}
 
-	if _, _, err := sendTelegramRichMessageReturnMessage(bot, s.target, rich, replyTo, msg.Message.Actions); err != nil {
+	if _, _, err := sendTelegramRichMessageReturnMessage(bot, s.target, rich, replyTo, msg.Message.Actions, telegramLinkPreviewsDisabled(s.cfg)); err != nil {
 		return s.deliverFinalTextWithActions(ctx, fallbackText, fallbackParseMode, msg.Message.Actions)
 	}
 	s.markDraftPermanentSent()
@@ -861,7 +861,7 @@ func (s *telegramOutboundStream) deliverLongPlainFallback(ctx context.Context, t
 	if !replyToFirstChunk {
 		replyTo = 0
 	}
-	if err := sendTelegramTextChunkListWithActions(bot, s.target, chunks, replyTo, "", actions); err != nil {
+	if err := sendTelegramTextChunkListWithActions(bot, s.target, chunks, replyTo, "", actions, telegramLinkPreviewsDisabled(s.cfg)); err != nil {
 		return err
 	}
 	s.markDraftPermanentSent()
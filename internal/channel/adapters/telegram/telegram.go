@@ -229,6 +229,7 @@ func (*TelegramAdapter) Descriptor() channel.Descriptor {
 			Buttons:         true,
 			Attachments:     true,
 			Media:           true,
+			Stickers:        true,
 			Streaming:       true,
 			BlockStreaming:  true,
 			Edit:            true,
@@ -239,6 +240,7 @@ func (*TelegramAdapter) Descriptor() channel.Descriptor {
 			TextChunkLimit:     telegramMaxMessageLength,
 			RichTextChunkLimit: telegramMaxRichMessageLength,
 			ChunkerMode:        channel.ChunkerModeMarkdown,
+			ReplyPacingMs:      800,
 		},
 		ConfigSchema: channel.ConfigSchema{
 			Version: 1,
@@ -460,6 +462,12 @@ func (a *TelegramAdapter) Connect(ctx context.Context, cfg channel.ChannelConfig
 			a.handleTelegramCallback(connCtx, cfg, handler, bot, upd)
 			return false
 		}
+		if upd.MyChatMember != nil {
+			if msg, ok := buildTelegramMembershipEvent(cfg, upd.MyChatMember); ok {
+				a.dispatchInbound(connCtx, cfg, handler, msg)
+			}
+			return false
+		}
 		if upd.Message == nil {
 			return false
 		}
@@ -550,6 +558,47 @@ func (a *TelegramAdapter) buildTelegramInboundMessage(bot *tele.Bot, cfg channel
 	})
 }
 
+// buildTelegramMembershipEvent turns a my_chat_member update into a membership
+// event when the bot left or was removed from a chat (or blocked in a private
+// chat), or joined one again.
+func buildTelegramMembershipEvent(cfg channel.ChannelConfig, update *tele.ChatMemberUpdate) (channel.InboundMessage, bool) {
+	if update == nil || update.Chat == nil || update.NewChatMember == nil {
+		return channel.InboundMessage{}, false
+	}
+	wasMember := update.OldChatMember != nil && isTelegramMemberRole(update.OldChatMember.Role)
+	isMember := isTelegramMemberRole(update.NewChatMember.Role)
+	var event string
+	switch {
+	case wasMember && !isMember:
+		event = channel.MembershipEventBotRemoved
+	case !wasMember && isMember:
+		event = channel.MembershipEventBotAdded
+	default:
+		return channel.InboundMessage{}, false
+	}
+	var actor channel.Identity
+	if update.Sender != nil {
+		actor = channel.Identity{
+			SubjectID:   strconv.FormatInt(update.Sender.ID, 10),
+			DisplayName: strings.TrimSpace(update.Sender.FirstName + " " + update.Sender.LastName),
+		}
+	}
+	return channel.NewMembershipEvent(cfg, Type, event, channel.Conversation{
+		ID:   strconv.FormatInt(update.Chat.ID, 10),
+		Type: normalizeTelegramConversationType(string(update.Chat.Type)),
+		Name: strings.TrimSpace(update.Chat.Title),
+	}, actor), true
+}
+
+func isTelegramMemberRole(role tele.MemberStatus) bool {
+	switch role {
+	case tele.Creator, tele.Administrator, tele.Member, tele.Restricted:
+		return true
+	default:
+		return false
+	}
+}
+
 // handleTelegramCallback acknowledges and routes an inline-keyboard callback.
 // Interactive callbacks (namespace "m~") re-render the originating message in
 // place: pagination/selection re-dispatch a synthetic command, dismiss strips
@@ -591,7 +640,7 @@ func (a *TelegramAdapter) handleTelegramCallback(ctx context.Context, cfg channe
 			// breadcrumb of what was opened instead of having it vanish.
 			if cb.Message != nil && cb.Message.Chat != nil {
 				if title := collapseToTitle(cb.Message.Text); title != "" {
-					_ = editTelegramMessageText(bot, cb.Message.Chat.ID, cb.Message.ID, title, "")
+					_ = editTelegramMessageText(bot, cb.Message.Chat.ID, cb.Message.ID, title, "", telegramLinkPreviewsDisabled(cfg))
 				}
 			}
 			return
@@ -647,11 +696,12 @@ func (a *TelegramAdapter) buildTelegramCallbackInboundMessage(cfg channel.Channe
 		// A tap on the bot's own keyboard is by definition directed at the bot,
 		// so the command path runs even in group chats.
 		extraMeta["is_mentioned"] = true
-		if !parsed.IsSkillActivation() {
+		if !parsed.IsSkillActivation() && !parsed.IsMemoryReview() {
 			// Pagination/selection: re-render the existing message in place
 			// rather than posting a new one. Skill activation instead starts a
-			// fresh chat turn: reply as a NEW message so the skill list card
-			// (and its keyboard) survives for further taps.
+			// fresh chat turn, and a memory review tap acts on one memory of
+			// the digest: reply as a NEW message so the card (and its
+			// keyboard) survives for further taps.
 			extraMeta["edit_message_id"] = strconv.Itoa(cb.Message.ID)
 		}
 	} else {
@@ -764,7 +814,7 @@ func (a *TelegramAdapter) handleAskUserWizardCallback(ctx context.Context, cfg c
 	}
 	if result.Changed && chatID != 0 && msgID != 0 {
 		text, actions := renderAskUserPage(req.ID, loc, req.UIPayload, req.Interaction)
-		_ = editTelegramMessageTextWithActions(bot, chatID, msgID, text, "", actions)
+		_ = editTelegramMessageTextWithActions(bot, chatID, msgID, text, "", actions, telegramLinkPreviewsDisabled(cfg))
 	}
 	// Free-text input is opt-in. Auto-prompting on page entry adds another
 	// message and reply preview before the user has chosen to answer.
@@ -873,7 +923,7 @@ func (a *TelegramAdapter) tryHandleAskUserTextReply(ctx context.Context, cfg cha
 	}
 	if cardChatID != 0 && cardMsgID != 0 {
 		body, actions := renderAskUserPage(req.ID, loc, req.UIPayload, req.Interaction)
-		_ = editTelegramMessageTextWithActions(bot, cardChatID, cardMsgID, body, "", actions)
+		_ = editTelegramMessageTextWithActions(bot, cardChatID, cardMsgID, body, "", actions, telegramLinkPreviewsDisabled(cfg))
 	}
 	return true
 }
@@ -894,7 +944,7 @@ func askUserCardLocation(chatID int64, req userinput.Request) (int64, int) {
 func (a *TelegramAdapter) submitAskUser(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler, bot *tele.Bot, update *tele.Update, loc *i18n.Localizer, req userinput.Request, cardChatID int64, cardMsgID int) {
 	if bot != nil && cardChatID != 0 && cardMsgID != 0 {
 		summary := formatAskUserSubmittedSummary(loc, req.UIPayload, req.Interaction)
-		_ = editTelegramMessageTextWithActions(bot, cardChatID, cardMsgID, summary, "", nil)
+		_ = editTelegramMessageTextWithActions(bot, cardChatID, cardMsgID, summary, "", nil, telegramLinkPreviewsDisabled(cfg))
 	}
 	msg, ok := a.buildAskUserSubmitInbound(cfg, update, req, cardMsgID)
 	if !ok {
@@ -1195,12 +1245,12 @@ func (a *TelegramAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, m
 			}
 		}
 		if text != "" && !usedCaption {
-			return sendTelegramText(bot, to, text, replyTo, parseMode)
+			return sendTelegramText(bot, to, text, replyTo, parseMode, telegramLinkPreviewsDisabled(cfg))
 		}
 		return nil
 	}
 	if rich.hasContent() {
-		if _, _, err := sendTelegramRichMessageReturnMessage(bot, to, rich, replyTo, msg.Message.Message.Actions); err == nil {
+		if _, _, err := sendTelegramRichMessageReturnMessage(bot, to, rich, replyTo, msg.Message.Message.Actions, telegramLinkPreviewsDisabled(cfg)); err == nil {
 			return nil
 		} else if a.logger != nil {
 			a.logger.Warn("telegram: rich message send failed, falling back to text",
@@ -1210,12 +1260,12 @@ func (a *TelegramAdapter) Send(ctx context.Context, cfg channel.ChannelConfig, m
 		}
 	}
 	if shouldSplitTelegramPlainFallback(msg.Message.Message, rich, text, parseMode) {
-		return sendTelegramTextChunksWithActions(bot, to, text, replyTo, parseMode, msg.Message.Message.Actions)
+		return sendTelegramTextChunksWithActions(bot, to, text, replyTo, parseMode, msg.Message.Message.Actions, telegramLinkPreviewsDisabled(cfg))
 	}
 	if len(msg.Message.Message.Actions) > 0 {
-		return sendTelegramTextWithActions(bot, to, text, replyTo, parseMode, msg.Message.Message.Actions)
+		return sendTelegramTextWithActions(bot, to, text, replyTo, parseMode, msg.Message.Message.Actions, telegramLinkPreviewsDisabled(cfg))
 	}
-	return sendTelegramText(bot, to, text, replyTo, parseMode)
+	return sendTelegramText(bot, to, text, replyTo, parseMode, telegramLinkPreviewsDisabled(cfg))
 }
 
 func (*TelegramAdapter) ValidatePreparedOutbound(_ context.Context, _ channel.ChannelConfig, _ string, msg channel.PreparedOutboundMessage) error {
@@ -1293,7 +1343,7 @@ func (a *TelegramAdapter) Update(_ context.Context, cfg channel.ChannelConfig, t
 			)
 		}
 	}
-	return editTelegramMessageTextWithActions(bot, chatID, mid, text, parseMode, msg.Message.Actions)
+	return editTelegramMessageTextWithActions(bot, chatID, mid, text, parseMode, msg.Message.Actions, telegramLinkPreviewsDisabled(cfg))
 }
 
 // Unsend deletes a previously-sent message, satisfying channel.MessageEditor.
@@ -1453,15 +1503,15 @@ func telegramRecipient(target string) (tele.Recipient, int64, error) {
 	return tele.ChatID(chatID), chatID, nil
 }
 
-func sendTelegramText(bot *tele.Bot, target string, text string, replyTo int, parseMode string) error {
-	_, _, err := sendTelegramTextReturnMessage(bot, target, text, replyTo, parseMode)
+func sendTelegramText(bot *tele.Bot, target string, text string, replyTo int, parseMode string, noPreview bool) error {
+	_, _, err := sendTelegramTextReturnMessage(bot, target, text, replyTo, parseMode, noPreview)
 	return err
 }
 
 var sendTextForTest func(bot *tele.Bot, target string, text string, replyTo int, parseMode string) (int64, int, error)
 
 // sendTelegramTextReturnMessage sends a text message and returns the chat ID and message ID for later editing.
-func sendTelegramTextReturnMessage(bot *tele.Bot, target string, text string, replyTo int, parseMode string) (chatID int64, messageID int, err error) {
+func sendTelegramTextReturnMessage(bot *tele.Bot, target string, text string, replyTo int, parseMode string, noPreview bool) (chatID int64, messageID int, err error) {
 	text = truncateTelegramText(sanitizeTelegramText(text))
 	if sendTextForTest != nil {
 		return sendTextForTest(bot, target, text, replyTo, parseMode)
@@ -1470,7 +1520,7 @@ func sendTelegramTextReturnMessage(bot *tele.Bot, target string, text string, re
 	if parseErr != nil {
 		return 0, 0, parseErr
 	}
-	opts := &tele.SendOptions{ParseMode: parseMode}
+	opts := telegramSendOptions(parseMode, noPreview)
 	if replyTo > 0 {
 		opts.ReplyTo = &tele.Message{ID: replyTo}
 	}
@@ -1486,20 +1536,20 @@ func sendTelegramTextReturnMessage(bot *tele.Bot, target string, text string, re
 	return chatID, messageID, nil
 }
 
-func sendTelegramTextWithActions(bot *tele.Bot, target string, text string, replyTo int, parseMode string, actions []channel.Action) error {
-	_, _, err := sendTelegramTextWithActionsReturnMessage(bot, target, text, replyTo, parseMode, actions)
+func sendTelegramTextWithActions(bot *tele.Bot, target string, text string, replyTo int, parseMode string, actions []channel.Action, noPreview bool) error {
+	_, _, err := sendTelegramTextWithActionsReturnMessage(bot, target, text, replyTo, parseMode, actions, noPreview)
 	return err
 }
 
-func sendTelegramTextChunksWithActions(bot *tele.Bot, target string, text string, replyTo int, parseMode string, actions []channel.Action) error {
+func sendTelegramTextChunksWithActions(bot *tele.Bot, target string, text string, replyTo int, parseMode string, actions []channel.Action, noPreview bool) error {
 	chunks := channel.ChunkText(text, telegramMaxMessageLength)
 	if len(chunks) == 0 {
-		return sendTelegramTextWithActions(bot, target, text, replyTo, parseMode, actions)
+		return sendTelegramTextWithActions(bot, target, text, replyTo, parseMode, actions, noPreview)
 	}
-	return sendTelegramTextChunkListWithActions(bot, target, chunks, replyTo, parseMode, actions)
+	return sendTelegramTextChunkListWithActions(bot, target, chunks, replyTo, parseMode, actions, noPreview)
 }
 
-func sendTelegramTextChunkListWithActions(bot *tele.Bot, target string, chunks []string, replyTo int, parseMode string, actions []channel.Action) error {
+func sendTelegramTextChunkListWithActions(bot *tele.Bot, target string, chunks []string, replyTo int, parseMode string, actions []channel.Action, noPreview bool) error {
 	for i, chunk := range chunks {
 		chunkReplyTo := replyTo
 		if i > 0 {
@@ -1507,19 +1557,19 @@ func sendTelegramTextChunkListWithActions(bot *tele.Bot, target string, chunks [
 		}
 		isLast := i == len(chunks)-1
 		if isLast && len(actions) > 0 {
-			if err := sendTelegramTextWithActions(bot, target, chunk, chunkReplyTo, parseMode, actions); err != nil {
+			if err := sendTelegramTextWithActions(bot, target, chunk, chunkReplyTo, parseMode, actions, noPreview); err != nil {
 				return err
 			}
 			continue
 		}
-		if err := sendTelegramText(bot, target, chunk, chunkReplyTo, parseMode); err != nil {
+		if err := sendTelegramText(bot, target, chunk, chunkReplyTo, parseMode, noPreview); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func sendTelegramTextWithActionsReturnMessage(bot *tele.Bot, target string, text string, replyTo int, parseMode string, actions []channel.Action) (chatID int64, messageID int, err error) {
+func sendTelegramTextWithActionsReturnMessage(bot *tele.Bot, target string, text string, replyTo int, parseMode string, actions []channel.Action, noPreview bool) (chatID int64, messageID int, err error) {
 	recipient, parsedChatID, parseErr := telegramRecipient(target)
 	if parseErr != nil {
 		return 0, 0, parseErr
@@ -1528,7 +1578,7 @@ func sendTelegramTextWithActionsReturnMessage(bot *tele.Bot, target string, text
 	if err != nil {
 		return 0, 0, err
 	}
-	opts := &tele.SendOptions{ParseMode: parseMode}
+	opts := telegramSendOptions(parseMode, noPreview)
 	if replyTo > 0 {
 		opts.ReplyTo = &tele.Message{ID: replyTo}
 	}
@@ -1562,8 +1612,8 @@ func collapseToTitle(text string) string {
 	return ""
 }
 
-func editTelegramMessageText(bot *tele.Bot, chatID int64, messageID int, text string, parseMode string) error {
-	err := rawEditTelegramMessageText(bot, chatID, messageID, text, parseMode)
+func editTelegramMessageText(bot *tele.Bot, chatID int64, messageID int, text string, parseMode string, noPreview bool) error {
+	err := rawEditTelegramMessageText(bot, chatID, messageID, text, parseMode, noPreview)
 	if err != nil && (isTelegramMessageNotModified(err) || isTelegramEditUnrecoverable(err)) {
 		return nil
 	}
@@ -1576,34 +1626,34 @@ func editTelegramMessageText(bot *tele.Bot, chatID int64, messageID int, text st
 // deleted card should be a quiet no-op, not a burned retry). The streaming final
 // path uses the raw form instead so it can SEE an unrecoverable error and recover
 // the answer (post it as a new message) rather than dropping it silently.
-func rawEditTelegramMessageText(bot *tele.Bot, chatID int64, messageID int, text string, parseMode string) error {
+func rawEditTelegramMessageText(bot *tele.Bot, chatID int64, messageID int, text string, parseMode string, noPreview bool) error {
 	text = truncateTelegramText(sanitizeTelegramText(text))
 	if sendEditForTest != nil {
 		return sendEditForTest(bot, chatID, messageID, text, parseMode)
 	}
 	stored := &tele.StoredMessage{MessageID: strconv.Itoa(messageID), ChatID: chatID}
-	opts := &tele.SendOptions{ParseMode: parseMode}
+	opts := telegramSendOptions(parseMode, noPreview)
 	_, err := bot.Edit(stored, text, opts)
 	return err
 }
 
-func editTelegramMessageTextWithActions(bot *tele.Bot, chatID int64, messageID int, text string, parseMode string, actions []channel.Action) error {
+func editTelegramMessageTextWithActions(bot *tele.Bot, chatID int64, messageID int, text string, parseMode string, actions []channel.Action, noPreview bool) error {
 	// With no actions, omit reply_markup entirely. Passing a *ReplyMarkup with
 	// an empty InlineKeyboard would serialize to {}, which telebot treats as
 	// "remove keyboard". For text-only updates after model selection we want
 	// to update the text AND remove the stale keyboard, which editTelegramMessageText
 	// achieves by sending no reply_markup at all.
 	if len(actions) == 0 {
-		return editTelegramMessageText(bot, chatID, messageID, text, parseMode)
+		return editTelegramMessageText(bot, chatID, messageID, text, parseMode, noPreview)
 	}
-	err := rawEditTelegramMessageTextWithActions(bot, chatID, messageID, text, parseMode, actions)
+	err := rawEditTelegramMessageTextWithActions(bot, chatID, messageID, text, parseMode, actions, noPreview)
 	if err != nil && (isTelegramMessageNotModified(err) || isTelegramEditUnrecoverable(err)) {
 		return nil
 	}
 	return err
 }
 
-func rawEditTelegramMessageTextWithActions(bot *tele.Bot, chatID int64, messageID int, text string, parseMode string, actions []channel.Action) error {
+func rawEditTelegramMessageTextWithActions(bot *tele.Bot, chatID int64, messageID int, text string, parseMode string, actions []channel.Action, noPreview bool) error {
 	text, markup, err := telegramTextWithActionMarkup(text, actions)
 	if err != nil {
 		return err
@@ -1612,7 +1662,8 @@ func rawEditTelegramMessageTextWithActions(bot *tele.Bot, chatID int64, messageI
 		return sendEditForTest(bot, chatID, messageID, text, parseMode)
 	}
 	stored := &tele.StoredMessage{MessageID: strconv.Itoa(messageID), ChatID: chatID}
-	opts := &tele.SendOptions{ParseMode: parseMode, ReplyMarkup: markup}
+	opts := telegramSendOptions(parseMode, noPreview)
+	opts.ReplyMarkup = markup
 	_, err = bot.Edit(stored, text, opts)
 	return err
 }
@@ -1717,7 +1768,7 @@ var sendDraftForTest func(bot *tele.Bot, chatID int64, draftID int, text string,
 
 // sendTelegramDraft calls the sendMessageDraft Bot API method to stream a
 // partial message to a private chat while it is being generated.
-func sendTelegramDraft(bot *tele.Bot, chatID int64, draftID int, text string, parseMode string) error {
+func sendTelegramDraft(bot *tele.Bot, chatID int64, draftID int, text string, parseMode string, noPreview bool) error {
 	text = truncateTelegramText(sanitizeTelegramText(text))
 	if strings.TrimSpace(text) == "" {
 		return nil
@@ -1725,7 +1776,7 @@ func sendTelegramDraft(bot *tele.Bot, chatID int64, draftID int, text string, pa
 	if sendDraftForTest != nil {
 		return sendDraftForTest(bot, chatID, draftID, text, parseMode)
 	}
-	opts := &tele.SendOptions{ParseMode: parseMode}
+	opts := telegramSendOptions(parseMode, noPreview)
 	return bot.SendDraft(tele.ChatID(chatID), draftID, text, opts)
 }
 
@@ -1868,6 +1919,10 @@ func sendTelegramAttachmentImpl(ctx context.Context, bot *tele.Bot, target strin
 	case channel.AttachmentGIF:
 		_, sendErr := bot.Send(recipient, &tele.Animation{File: file, Caption: caption, FileName: name}, opts)
 		return sendErr
+	case channel.AttachmentSticker:
+		// Telegram stickers cannot carry a caption.
+		_, sendErr := bot.Send(recipient, &tele.Sticker{File: file}, opts)
+		return sendErr
 	default:
 		return fmt.Errorf("unsupported attachment type: %s", att.Logical.Type)
 	}
@@ -1139,7 +1139,7 @@ func TestSendTelegramTextWithActionsUsesLabelFallbackForActionOnlyMessage(t *tes
 	_, _, err := sendTelegramTextWithActionsReturnMessage(bot, "123", "", 0, "", []channel.Action{{
 		Label: "Open",
 		URL:   "https://example.com",
-	}})
+	}}, false)
 	if err != nil {
 		t.Fatalf("sendTelegramTextWithActionsReturnMessage: %v", err)
 	}
@@ -1160,7 +1160,7 @@ func TestSendTelegramTextWithActionsRejectsActionOnlyInvalidButtons(t *testing.T
 	_, _, err := sendTelegramTextWithActionsReturnMessage(bot, "123", "", 0, "", []channel.Action{{
 		Label: "Too large",
 		Value: strings.Repeat("x", telegramMaxCallbackDataBytes+1),
-	}})
+	}}, false)
 	if err == nil || !strings.Contains(err.Error(), "callback data") {
 		t.Fatalf("expected invalid action error, got %v", err)
 	}
@@ -1175,7 +1175,7 @@ func TestSendTelegramTextWithActionsRejectsTextInvalidButtons(t *testing.T) {
 	_, _, err := sendTelegramTextWithActionsReturnMessage(bot, "123", "Choose", 0, "", []channel.Action{{
 		Label: "Unsafe",
 		URL:   "javascript:alert(1)",
-	}})
+	}}, false)
 	if err == nil || !strings.Contains(err.Error(), "url must be http(s)") {
 		t.Fatalf("expected invalid action error, got %v", err)
 	}
@@ -1190,7 +1190,7 @@ func TestSendTelegramRichMessageRejectsInvalidActionsBeforeRequest(t *testing.T)
 	_, _, err := sendTelegramRichMessageReturnMessage(bot, "123", telegramInputRichMessage{HTML: "<p>Choose</p>"}, 0, []channel.Action{{
 		Label: "Too large",
 		Value: strings.Repeat("x", telegramMaxCallbackDataBytes+1),
-	}})
+	}}, false)
 	if err == nil || !strings.Contains(err.Error(), "callback data") {
 		t.Fatalf("expected invalid rich action error, got %v", err)
 	}
@@ -1713,7 +1713,7 @@ func TestEditTelegramMessageText_429ReturnsError(t *testing.T) {
 	defer func() { sendEditForTest = origSend }()
 
 	bot := &tele.Bot{Token: "test"}
-	err := editTelegramMessageText(bot, 1, 1, "hi", "")
+	err := editTelegramMessageText(bot, 1, 1, "hi", "", false)
 	if err == nil {
 		t.Fatal("editTelegramMessageText on 429 should return error for caller to handle")
 	}
@@ -1952,3 +1952,35 @@ func TestBuildTelegramPaginationCallbackKeepsEditInPlace(t *testing.T) {
 		t.Fatalf("reply = %+v, want AttachmentsKnown=true — the tapped card is the bot's own message", msg.Message.Reply)
 	}
 }
+
+func TestBuildTelegramMembershipEvent(t *testing.T) {
+	t.Parallel()
+
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: "bot-1"}
+	chat := &tele.Chat{ID: -100123, Type: tele.ChatSuperGroup, Title: "Team"}
+	update := func(oldRole, newRole tele.MemberStatus) *tele.ChatMemberUpdate {
+		return &tele.ChatMemberUpdate{
+			Chat:          chat,
+			Sender:        &tele.User{ID: 42, FirstName: "Ada"},
+			OldChatMember: &tele.ChatMember{Role: oldRole},
+			NewChatMember: &tele.ChatMember{Role: newRole},
+		}
+	}
+
+	msg, ok := buildTelegramMembershipEvent(cfg, update(tele.Member, tele.Kicked))
+	if !ok || channel.MembershipEvent(msg) != channel.MembershipEventBotRemoved {
+		t.Fatalf("expected bot_removed event, got ok=%v msg=%+v", ok, msg)
+	}
+	if msg.Conversation.ID != "-100123" || msg.BotID != "bot-1" || msg.Sender.SubjectID != "42" {
+		t.Fatalf("unexpected event: %+v", msg)
+	}
+
+	msg, ok = buildTelegramMembershipEvent(cfg, update(tele.Left, tele.Administrator))
+	if !ok || channel.MembershipEvent(msg) != channel.MembershipEventBotAdded {
+		t.Fatalf("expected bot_added event, got ok=%v msg=%+v", ok, msg)
+	}
+
+	if _, ok := buildTelegramMembershipEvent(cfg, update(tele.Member, tele.Administrator)); ok {
+		t.Fatal("promotion must not be reported as a membership change")
+	}
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/redact"
+)
+
+// minSecretLength keeps the shared HMAC secret from being guessable.
+const minSecretLength = 16
+
+// Config is a bot's webhook channel configuration. The secret signs both
+// directions: the integration signs inbound requests with it and verifies the
+// signature on outbound callbacks.
+type Config struct {
+	CallbackURL string
+	Secret      string `json:"Secret"` //nolint:gosec // G117: secret field, handled securely
+}
+
+// UserConfig identifies a user by their ID in the integrated system.
+type UserConfig struct {
+	UserID string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"callbackUrl": cfg.CallbackURL,
+		"secret":      cfg.Secret,
+	}, nil
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	cfg := Config{
+		CallbackURL: strings.TrimSpace(channel.ReadString(raw, "callbackUrl", "callback_url")),
+		Secret:      strings.TrimSpace(channel.ReadString(raw, "secret")),
+	}
+	if cfg.CallbackURL == "" {
+		return Config{}, errors.New("webhook callbackUrl is required")
+	}
+	parsed, err := url.Parse(cfg.CallbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Config{}, errors.New("webhook callbackUrl must be an http(s) URL")
+	}
+	if len(cfg.Secret) < minSecretLength {
+		return Config{}, errors.New("webhook secret must be at least 16 characters")
+	}
+	return cfg, nil
+}
+
+// parseConfigForUse parses the config and registers its secret for log
+// redaction.
+func parseConfigForUse(configID string, raw map[string]any) (Config, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return Config{}, err
+	}
+	redact.SetSecrets("webhook:"+configID, cfg.Secret)
+	return cfg, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"user_id": cfg.UserID}, nil
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	userID := normalizeTarget(channel.ReadString(raw, "userId", "user_id"))
+	if userID == "" {
+		return UserConfig{}, errors.New("webhook user config requires user_id")
+	}
+	return UserConfig{UserID: userID}, nil
+}
+
+// normalizeTarget strips an optional "webhook:" prefix. Targets are opaque
+// conversation IDs of the integrated system.
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, string(Type)+":")
+	return strings.TrimSpace(value)
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	return cfg.UserID, nil
+}
+
+func matchBinding(raw map[string]any, criteria channel.BindingCriteria) bool {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return false
+	}
+	if value := normalizeTarget(criteria.Attribute("user_id")); value != "" && value == cfg.UserID {
+		return true
+	}
+	return normalizeTarget(criteria.SubjectID) == cfg.UserID
+}
+
+func buildUserConfig(identity channel.Identity) map[string]any {
+	if value := normalizeTarget(identity.Attribute("user_id")); value != "" {
+		return map[string]any{"user_id": value}
+	}
+	if value := normalizeTarget(identity.SubjectID); value != "" {
+		return map[string]any{"user_id": value}
+	}
+	return map[string]any{}
+}
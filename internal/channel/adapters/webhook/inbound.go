@@ -0,0 +1,219 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/media"
+)
+
+const webhookMaxBodyBytes int64 = 25 << 20 // room for inline base64 attachments
+
+// inboundPayload is the JSON body the integrated system POSTs for each
+// message.
+type inboundPayload struct {
+	MessageID    string              `json:"message_id"`
+	Conversation inboundConversation `json:"conversation"`
+	Sender       inboundSender       `json:"sender"`
+	Text         string              `json:"text"`
+	Format       string              `json:"format"`
+	ReplyTo      string              `json:"reply_to"`
+	Mentioned    bool                `json:"mentioned"`
+	Timestamp    int64               `json:"timestamp"`
+	Attachments  []inboundAttachment `json:"attachments"`
+	Metadata     map[string]any      `json:"metadata"`
+}
+
+// inboundConversation defaults to the sender's private conversation when the
+// ID is empty.
+type inboundConversation struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	ThreadID string `json:"thread_id"`
+}
+
+type inboundSender struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+type inboundAttachment struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Base64  string `json:"base64"`
+	Name    string `json:"name"`
+	Mime    string `json:"mime"`
+	Size    int64  `json:"size"`
+	Caption string `json:"caption"`
+}
+
+// HandleWebhook accepts one signed message per POST. It answers 202 once the
+// message is queued, including for duplicates of an already accepted
+// message_id, so the integration can retry safely.
+func (a *Adapter) HandleWebhook(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler, r *http.Request, w http.ResponseWriter) error {
+	if r.Method != http.MethodPost {
+		return echo.NewHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+	hookCfg, err := parseConfigForUse(cfg.ID, cfg.Credentials)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "webhook channel not configured")
+	}
+	if handler == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "webhook inbound handler is nil")
+	}
+	body, err := media.ReadAllWithLimit(r.Body, webhookMaxBodyBytes)
+	if err != nil {
+		if errors.Is(err, media.ErrAssetTooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "payload too large")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to read request body")
+	}
+	if !verifySignature(hookCfg.Secret, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body, a.now()) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid signature")
+	}
+	var payload inboundPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid webhook payload")
+	}
+	msg, err := buildInboundMessage(cfg, payload)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if !a.claimMessage(cfg.ID, msg.Message.ID) {
+		w.WriteHeader(http.StatusAccepted)
+		return nil
+	}
+	if err := handler(ctx, cfg, msg); err != nil {
+		a.forgetMessage(cfg.ID, msg.Message.ID)
+		if channel.IsInboundQueueFull(err) {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "webhook inbound queue full")
+		}
+		a.logger.Warn("webhook inbound handling failed",
+			slog.String("config_id", cfg.ID),
+			slog.String("message_id", msg.Message.ID),
+			slog.Any("error", err),
+		)
+		return echo.NewHTTPError(http.StatusInternalServerError, "webhook processing failed")
+	}
+	w.WriteHeader(http.StatusAccepted)
+	return nil
+}
+
+func buildInboundMessage(cfg channel.ChannelConfig, payload inboundPayload) (channel.InboundMessage, error) {
+	messageID := strings.TrimSpace(payload.MessageID)
+	if messageID == "" {
+		return channel.InboundMessage{}, errors.New("message_id is required")
+	}
+	senderID := normalizeTarget(payload.Sender.ID)
+	if senderID == "" {
+		return channel.InboundMessage{}, errors.New("sender.id is required")
+	}
+	conversationID := normalizeTarget(payload.Conversation.ID)
+	if conversationID == "" {
+		conversationID = senderID
+	}
+	conversationType := strings.TrimSpace(payload.Conversation.Type)
+	switch conversationType {
+	case "":
+		conversationType = channel.ConversationTypePrivate
+	case channel.ConversationTypePrivate, channel.ConversationTypeGroup, channel.ConversationTypeThread:
+	default:
+		return channel.InboundMessage{}, errors.New("conversation.type must be private, group or thread")
+	}
+
+	message := channel.Message{
+		ID:     messageID,
+		Format: channel.MessageFormatPlain,
+		Text:   strings.TrimSpace(payload.Text),
+	}
+	if strings.TrimSpace(payload.Format) == string(channel.MessageFormatMarkdown) {
+		message.Format = channel.MessageFormatMarkdown
+	}
+	for _, raw := range payload.Attachments {
+		att, err := buildInboundAttachment(raw)
+		if err != nil {
+			return channel.InboundMessage{}, err
+		}
+		message.Attachments = append(message.Attachments, att)
+	}
+	if message.IsEmpty() {
+		return channel.InboundMessage{}, errors.New("text or attachments are required")
+	}
+	if replyTo := strings.TrimSpace(payload.ReplyTo); replyTo != "" {
+		message.Reply = &channel.ReplyRef{MessageID: replyTo}
+	}
+	threadID := strings.TrimSpace(payload.Conversation.ThreadID)
+	if threadID != "" {
+		message.Thread = &channel.ThreadRef{ID: threadID}
+	}
+
+	attributes := map[string]string{"user_id": senderID}
+	for key, value := range payload.Sender.Attributes {
+		if key = strings.TrimSpace(key); key != "" && key != "user_id" {
+			attributes[key] = strings.TrimSpace(value)
+		}
+	}
+	metadata := make(map[string]any, len(payload.Metadata)+1)
+	for key, value := range payload.Metadata {
+		metadata[key] = value
+	}
+	metadata["is_mentioned"] = payload.Mentioned
+
+	receivedAt := time.Now().UTC()
+	if payload.Timestamp > 0 {
+		receivedAt = time.Unix(payload.Timestamp, 0).UTC()
+	}
+	return channel.InboundMessage{
+		Channel:     Type,
+		Message:     message,
+		BotID:       cfg.BotID,
+		ReplyTarget: conversationID,
+		Sender: channel.Identity{
+			SubjectID:   senderID,
+			DisplayName: strings.TrimSpace(payload.Sender.Name),
+			Attributes:  attributes,
+		},
+		Conversation: channel.Conversation{
+			ID:       conversationID,
+			Type:     conversationType,
+			Name:     strings.TrimSpace(payload.Conversation.Name),
+			ThreadID: threadID,
+		},
+		ReceivedAt: receivedAt,
+		Source:     Type.String(),
+		Metadata:   metadata,
+	}, nil
+}
+
+// buildInboundAttachment accepts an http(s) URL, which the inbound pipeline
+// downloads, or inline base64 content.
+func buildInboundAttachment(raw inboundAttachment) (channel.Attachment, error) {
+	rawURL := strings.TrimSpace(raw.URL)
+	rawBase64 := strings.TrimSpace(raw.Base64)
+	switch {
+	case rawURL != "" && !channel.IsHTTPURL(rawURL):
+		return channel.Attachment{}, errors.New("attachment url must be http(s)")
+	case rawURL == "" && rawBase64 == "":
+		return channel.Attachment{}, errors.New("attachment requires url or base64")
+	}
+	return channel.NormalizeInboundChannelAttachment(channel.Attachment{
+		Type:           channel.AttachmentType(strings.TrimSpace(raw.Type)),
+		URL:            rawURL,
+		Base64:         rawBase64,
+		SourcePlatform: Type.String(),
+		Name:           strings.TrimSpace(raw.Name),
+		Mime:           strings.TrimSpace(raw.Mime),
+		Size:           raw.Size,
+		Caption:        strings.TrimSpace(raw.Caption),
+	}), nil
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/redact"
+)
+
+// OpenStream returns a block stream: a delivered callback cannot be edited,
+// so deltas are buffered and sent as one callback when the reply is final.
+// The callback's reply_to names the message being answered.
+func (a *Adapter) OpenStream(_ context.Context, cfg channel.ChannelConfig, target string, opts channel.StreamOptions) (channel.PreparedOutboundStream, error) {
+	target = normalizeTarget(target)
+	if target == "" {
+		return nil, errors.New("webhook target is required")
+	}
+	return &outboundStream{adapter: a, cfg: cfg, target: target, reply: opts.Reply}, nil
+}
+
+type outboundStream struct {
+	adapter *Adapter
+	cfg     channel.ChannelConfig
+	target  string
+	reply   *channel.ReplyRef
+
+	mu          sync.Mutex
+	closed      bool
+	textBuilder strings.Builder
+	attachments []channel.PreparedAttachment
+}
+
+func (s *outboundStream) Push(ctx context.Context, event channel.PreparedStreamEvent) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.New("webhook stream is closed")
+	}
+	switch event.Type {
+	case channel.StreamEventDelta:
+		if event.Phase != channel.StreamPhaseReasoning {
+			s.textBuilder.WriteString(event.Delta)
+		}
+		s.mu.Unlock()
+		return nil
+	case channel.StreamEventAttachment:
+		s.attachments = append(s.attachments, event.Attachments...)
+		s.mu.Unlock()
+		return nil
+	case channel.StreamEventFinal:
+		var prepared channel.PreparedMessage
+		if event.Final != nil {
+			prepared = event.Final.Message
+		}
+		prepared = s.fillBufferedLocked(prepared)
+		s.mu.Unlock()
+		return s.send(ctx, prepared)
+	case channel.StreamEventError:
+		errText := redact.Text(strings.TrimSpace(event.Error))
+		s.textBuilder.Reset()
+		s.attachments = nil
+		s.mu.Unlock()
+		if errText == "" {
+			return nil
+		}
+		return s.send(ctx, channel.PreparedMessage{
+			Message: channel.Message{Format: channel.MessageFormatPlain, Text: "Error: " + errText},
+		})
+	default:
+		s.mu.Unlock()
+		return nil
+	}
+}
+
+func (s *outboundStream) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	prepared := s.fillBufferedLocked(channel.PreparedMessage{Message: channel.Message{Format: channel.MessageFormatPlain}})
+	s.mu.Unlock()
+	return s.send(ctx, prepared)
+}
+
+// fillBufferedLocked completes prepared with the buffered text and
+// attachments and clears the buffer.
+func (s *outboundStream) fillBufferedLocked(prepared channel.PreparedMessage) channel.PreparedMessage {
+	if strings.TrimSpace(prepared.Message.Text) == "" && len(prepared.Message.Parts) == 0 {
+		prepared.Message.Text = strings.TrimSpace(s.textBuilder.String())
+	}
+	if len(prepared.Attachments) == 0 && len(s.attachments) > 0 {
+		prepared.Attachments = s.attachments
+		prepared.Message.Attachments = make([]channel.Attachment, 0, len(s.attachments))
+		for _, att := range s.attachments {
+			prepared.Message.Attachments = append(prepared.Message.Attachments, att.Logical)
+		}
+	}
+	s.textBuilder.Reset()
+	s.attachments = nil
+	return prepared
+}
+
+func (s *outboundStream) send(ctx context.Context, prepared channel.PreparedMessage) error {
+	if prepared.Message.IsEmpty() && len(prepared.Attachments) == 0 {
+		return nil
+	}
+	if prepared.Message.Reply == nil {
+		prepared.Message.Reply = s.reply
+	}
+	return s.adapter.Send(ctx, s.cfg, channel.PreparedOutboundMessage{Target: s.target, Message: prepared})
+}
@@ -0,0 +1,368 @@
+// Package webhook implements a generic HTTP channel for integrating chat
+// systems that have no dedicated adapter: the system POSTs signed messages to
+// /channels/webhook/:config_id and receives the bot's replies as signed POSTs
+// to its callback URL.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/attachment"
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/webhookauth"
+	"github.com/memohai/memoh/internal/media"
+)
+
+// Type is the registered ChannelType identifier for the generic webhook
+// channel.
+const Type channel.ChannelType = "webhook"
+
+const (
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of
+	// "<timestamp>.<body>" keyed with the config secret.
+	SignatureHeader = "X-Memoh-Signature"
+	// TimestampHeader carries the Unix time in seconds the request was signed.
+	TimestampHeader = "X-Memoh-Timestamp"
+
+	// signatureTolerance bounds the clock skew accepted on inbound requests,
+	// so a captured request cannot be replayed later.
+	signatureTolerance = 5 * time.Minute
+	// inlineAttachmentMaxBytes caps attachments sent inline as base64 when no
+	// public URL is available.
+	inlineAttachmentMaxBytes = 20 << 20
+	messageDedupeTTL         = 24 * time.Hour
+)
+
+// Adapter connects bots to an external chat system over signed HTTP webhooks.
+type Adapter struct {
+	logger *slog.Logger
+	http   *http.Client
+	now    func() time.Time
+
+	seenMu sync.Mutex
+	seen   map[string]time.Time // keyed by configID:messageID
+}
+
+var (
+	_ channel.Sender          = (*Adapter)(nil)
+	_ channel.StreamSender    = (*Adapter)(nil)
+	_ channel.WebhookReceiver = (*Adapter)(nil)
+	_ channel.Receiver        = (*Adapter)(nil)
+)
+
+// NewAdapter creates a webhook Adapter with the given logger.
+func NewAdapter(log *slog.Logger) *Adapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Adapter{
+		logger: log.With(slog.String("adapter", "webhook")),
+		http:   &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Type returns the webhook channel type.
+func (*Adapter) Type() channel.ChannelType { return Type }
+
+// Descriptor returns the webhook channel metadata.
+func (*Adapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "Webhook",
+		Capabilities: channel.ChannelCapabilities{
+			Text:           true,
+			Markdown:       true,
+			Attachments:    true,
+			Media:          true,
+			Reply:          true,
+			Threads:        true,
+			BlockStreaming: true,
+			ChatTypes: []string{
+				channel.ConversationTypePrivate,
+				channel.ConversationTypeGroup,
+				channel.ConversationTypeThread,
+			},
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			ChunkerMode:         channel.ChunkerModeMarkdown,
+			InlineTextWithMedia: true,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"callbackUrl": {
+					Type:        channel.FieldString,
+					Required:    true,
+					Order:       0,
+					Title:       "Callback URL",
+					Description: "Bot replies are POSTed here as JSON, signed like inbound requests",
+					Example:     "https://chat.example.com/memoh/callback",
+				},
+				"secret": {
+					Type:        channel.FieldSecret,
+					Required:    true,
+					Order:       10,
+					Title:       "Signing Secret",
+					Description: "Shared HMAC-SHA256 key (at least 16 characters) for the X-Memoh-Signature header in both directions",
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"user_id": {Type: channel.FieldString, Required: true, Title: "User ID"},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "conversation ID in the integrated system",
+			Hints: []channel.TargetHint{
+				{Label: "Conversation ID", Example: "room-42"},
+			},
+		},
+	}
+}
+
+// NormalizeConfig validates and normalizes a webhook channel configuration.
+func (*Adapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a webhook user binding.
+func (*Adapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// NormalizeTarget strips an optional "webhook:" prefix from a target.
+func (*Adapter) NormalizeTarget(raw string) string { return normalizeTarget(raw) }
+
+// ResolveTarget returns the user ID of a user binding.
+func (*Adapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// MatchBinding reports whether a user binding matches the criteria.
+func (*Adapter) MatchBinding(config map[string]any, criteria channel.BindingCriteria) bool {
+	return matchBinding(config, criteria)
+}
+
+// BuildUserConfig builds a user binding from an inbound identity.
+func (*Adapter) BuildUserConfig(identity channel.Identity) map[string]any {
+	return buildUserConfig(identity)
+}
+
+// Connect validates the config. Inbound messages arrive on the webhook, so
+// there is no long-lived connection.
+func (*Adapter) Connect(_ context.Context, cfg channel.ChannelConfig, _ channel.InboundHandler) (channel.Connection, error) {
+	if _, err := parseConfigForUse(cfg.ID, cfg.Credentials); err != nil {
+		return nil, err
+	}
+	return channel.NewConnection(cfg, func(context.Context) error { return nil }), nil
+}
+
+// --- Sender ---
+
+type callbackPayload struct {
+	Event       string               `json:"event"`
+	BotID       string               `json:"bot_id"`
+	ConfigID    string               `json:"config_id"`
+	Target      string               `json:"target"`
+	ThreadID    string               `json:"thread_id,omitempty"`
+	ReplyTo     string               `json:"reply_to,omitempty"`
+	Text        string               `json:"text,omitempty"`
+	Format      string               `json:"format,omitempty"`
+	Attachments []callbackAttachment `json:"attachments,omitempty"`
+}
+
+type callbackAttachment struct {
+	Type    string `json:"type"`
+	URL     string `json:"url,omitempty"`
+	Base64  string `json:"base64,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Mime    string `json:"mime,omitempty"`
+	Size    int64  `json:"size,omitempty"`
+	Caption string `json:"caption,omitempty"`
+}
+
+// Send POSTs the message to the configured callback URL. Any 2xx response
+// counts as delivered.
+func (a *Adapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.PreparedOutboundMessage) error {
+	hookCfg, err := parseConfigForUse(cfg.ID, cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	target := normalizeTarget(msg.Target)
+	if target == "" {
+		return errors.New("webhook target is required")
+	}
+	message := msg.Message.Message
+	payload := callbackPayload{
+		Event:    "message",
+		BotID:    cfg.BotID,
+		ConfigID: cfg.ID,
+		Target:   target,
+		Text:     strings.TrimSpace(message.Text),
+		Format:   string(message.Format),
+	}
+	if payload.Text == "" || len(message.Parts) > 0 {
+		payload.Text = strings.TrimSpace(message.PlainText())
+		payload.Format = string(channel.MessageFormatPlain)
+	}
+	if payload.Format != string(channel.MessageFormatMarkdown) {
+		payload.Format = string(channel.MessageFormatPlain)
+	}
+	if message.Thread != nil {
+		payload.ThreadID = strings.TrimSpace(message.Thread.ID)
+	}
+	if message.Reply != nil {
+		payload.ReplyTo = strings.TrimSpace(message.Reply.MessageID)
+	}
+	for _, att := range msg.Message.Attachments {
+		item, err := callbackAttachmentFor(ctx, att)
+		if err != nil {
+			return err
+		}
+		payload.Attachments = append(payload.Attachments, item)
+	}
+	if payload.Text == "" && len(payload.Attachments) == 0 {
+		return errors.New("webhook message is required")
+	}
+	if err := a.postCallback(ctx, hookCfg, payload); err != nil {
+		a.logger.Error("callback failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// callbackAttachmentFor links public URLs and inlines everything else as
+// base64.
+func callbackAttachmentFor(ctx context.Context, att channel.PreparedAttachment) (callbackAttachment, error) {
+	item := callbackAttachment{
+		Type:    string(att.Logical.Type),
+		Name:    strings.TrimSpace(att.Name),
+		Mime:    attachment.NormalizeMime(att.Mime),
+		Size:    att.Size,
+		Caption: strings.TrimSpace(att.Logical.Caption),
+	}
+	if item.Name == "" {
+		item.Name = strings.TrimSpace(att.Logical.Name)
+	}
+	switch {
+	case att.Kind == channel.PreparedAttachmentPublicURL && strings.TrimSpace(att.PublicURL) != "":
+		item.URL = strings.TrimSpace(att.PublicURL)
+	case att.Kind == channel.PreparedAttachmentUpload && att.Open != nil:
+		reader, err := att.Open(ctx)
+		if err != nil {
+			return callbackAttachment{}, err
+		}
+		defer func() { _ = reader.Close() }()
+		data, err := media.ReadAllWithLimit(reader, inlineAttachmentMaxBytes)
+		if err != nil {
+			return callbackAttachment{}, fmt.Errorf("webhook inline attachment: %w", err)
+		}
+		item.Base64 = base64.StdEncoding.EncodeToString(data)
+		item.Size = int64(len(data))
+	default:
+		return callbackAttachment{}, fmt.Errorf("webhook does not support attachment kind %q", att.Kind)
+	}
+	return item, nil
+}
+
+func (a *Adapter) postCallback(ctx context.Context, cfg Config, payload callbackPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(a.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, sign(cfg.Secret, timestamp, body))
+	resp, err := a.http.Do(req) //nolint:gosec // G704: URL is user-configured, validated at config level
+	if err != nil {
+		return fmt.Errorf("webhook callback: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("webhook callback: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// --- signatures ---
+
+// signatureScheme signs "<timestamp>.<body>" with the config secret, in
+// both directions.
+var signatureScheme = webhookauth.Scheme{
+	SignatureHeader: SignatureHeader,
+	Prefix:          "sha256=",
+	Encoding:        webhookauth.Hex,
+	TimestampHeader: TimestampHeader,
+	MaxSkew:         signatureTolerance,
+	Sum: func(secret string, header http.Header, body []byte) []byte {
+		return webhookauth.HMACSHA256(secret, []byte(strings.TrimSpace(header.Get(TimestampHeader))), []byte{'.'}, body)
+	},
+}
+
+// sign returns the signature header value for body signed at timestamp.
+func sign(secret, timestamp string, body []byte) string {
+	return signatureScheme.Sign(secret, http.Header{TimestampHeader: []string{timestamp}}, body)
+}
+
+// verifySignature checks the signature and rejects timestamps outside
+// signatureTolerance.
+func verifySignature(secret, timestamp, header string, body []byte, now time.Time) bool {
+	h := http.Header{}
+	h.Set(TimestampHeader, timestamp)
+	h.Set(SignatureHeader, header)
+	return signatureScheme.Verify(h, body, secret, now) == nil
+}
+
+// --- webhook dedup ---
+
+// claimMessage reports whether messageID is new for the config, so retried
+// deliveries are processed once.
+func (a *Adapter) claimMessage(configID, messageID string) bool {
+	key := configID + ":" + messageID
+	now := a.now()
+	a.seenMu.Lock()
+	defer a.seenMu.Unlock()
+	if len(a.seen) >= 512 {
+		for seenKey, seenAt := range a.seen {
+			if now.Sub(seenAt) > messageDedupeTTL {
+				delete(a.seen, seenKey)
+			}
+		}
+	}
+	if _, ok := a.seen[key]; ok {
+		return false
+	}
+	a.seen[key] = now
+	return true
+}
+
+func (a *Adapter) forgetMessage(configID, messageID string) {
+	a.seenMu.Lock()
+	defer a.seenMu.Unlock()
+	delete(a.seen, configID+":"+messageID)
+}
@@ -0,0 +1,280 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+const testSecret = "0123456789abcdef"
+
+var testNow = time.Unix(1_760_000_000, 0)
+
+func testConfig(callbackURL string) channel.ChannelConfig {
+	return channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{
+			"callbackUrl": callbackURL,
+			"secret":      testSecret,
+		},
+	}
+}
+
+func testAdapter() *Adapter {
+	a := NewAdapter(nil)
+	a.now = func() time.Time { return testNow }
+	return a
+}
+
+func signedRequest(t *testing.T, body string, at time.Time) *http.Request {
+	t.Helper()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/channels/webhook/cfg-1", strings.NewReader(body))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, sign(testSecret, timestamp, []byte(body)))
+	return req
+}
+
+func httpStatus(err error) int {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return 0
+}
+
+func TestNormalizeConfig(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{
+		"callback_url": " https://chat.example.com/hook ",
+		"secret":       testSecret,
+	})
+	if err != nil {
+		t.Fatalf("normalizeConfig: %v", err)
+	}
+	if got["callbackUrl"] != "https://chat.example.com/hook" {
+		t.Fatalf("normalized = %v", got)
+	}
+	if _, err := normalizeConfig(map[string]any{"callbackUrl": "ftp://example.com", "secret": testSecret}); err == nil {
+		t.Fatal("expected non-http callbackUrl to fail")
+	}
+	if _, err := normalizeConfig(map[string]any{"callbackUrl": "https://example.com", "secret": "short"}); err == nil {
+		t.Fatal("expected short secret to fail")
+	}
+	if got := normalizeTarget("webhook: room-42 "); got != "room-42" {
+		t.Fatalf("normalizeTarget = %q", got)
+	}
+}
+
+func TestHandleWebhookDispatchesSignedMessage(t *testing.T) {
+	t.Parallel()
+
+	a := testAdapter()
+	var got []channel.InboundMessage
+	handler := func(_ context.Context, _ channel.ChannelConfig, msg channel.InboundMessage) error {
+		got = append(got, msg)
+		return nil
+	}
+	body := `{
+		"message_id": "m-1",
+		"conversation": {"id": "room-42", "type": "group", "name": "Ops"},
+		"sender": {"id": "u-7", "name": "Ada", "attributes": {"email": "ada@example.com"}},
+		"text": "@bot deploy status?",
+		"mentioned": true,
+		"reply_to": "m-0",
+		"attachments": [{"url": "https://files.example.com/log.txt", "name": "log.txt", "mime": "text/plain"}],
+		"metadata": {"tenant": "acme"}
+	}`
+	rec := httptest.NewRecorder()
+	if err := a.HandleWebhook(context.Background(), testConfig("https://chat.example.com/hook"), handler, signedRequest(t, body, testNow), rec); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected one inbound message, got %d", len(got))
+	}
+	msg := got[0]
+	if msg.Message.ID != "m-1" || msg.Message.Text != "@bot deploy status?" {
+		t.Fatalf("message = %+v", msg.Message)
+	}
+	if msg.Conversation.ID != "room-42" || msg.Conversation.Type != channel.ConversationTypeGroup || msg.ReplyTarget != "room-42" {
+		t.Fatalf("conversation = %+v, reply target %q", msg.Conversation, msg.ReplyTarget)
+	}
+	if msg.Sender.SubjectID != "u-7" || msg.Sender.Attribute("user_id") != "u-7" || msg.Sender.Attribute("email") != "ada@example.com" {
+		t.Fatalf("sender = %+v", msg.Sender)
+	}
+	if msg.Message.Reply == nil || msg.Message.Reply.MessageID != "m-0" {
+		t.Fatalf("reply = %+v", msg.Message.Reply)
+	}
+	if len(msg.Message.Attachments) != 1 || msg.Message.Attachments[0].URL != "https://files.example.com/log.txt" {
+		t.Fatalf("attachments = %+v", msg.Message.Attachments)
+	}
+	if msg.Metadata["is_mentioned"] != true || msg.Metadata["tenant"] != "acme" {
+		t.Fatalf("metadata = %v", msg.Metadata)
+	}
+
+	// A retried delivery is acknowledged without dispatching again.
+	rec = httptest.NewRecorder()
+	if err := a.HandleWebhook(context.Background(), testConfig("https://chat.example.com/hook"), handler, signedRequest(t, body, testNow), rec); err != nil {
+		t.Fatalf("HandleWebhook retry: %v", err)
+	}
+	if rec.Code != http.StatusAccepted || len(got) != 1 {
+		t.Fatalf("retry: status %d, dispatched %d", rec.Code, len(got))
+	}
+}
+
+func TestHandleWebhookRejectsInvalidRequests(t *testing.T) {
+	t.Parallel()
+
+	handler := func(context.Context, channel.ChannelConfig, channel.InboundMessage) error { return nil }
+	cfg := testConfig("https://chat.example.com/hook")
+	valid := `{"message_id":"m-1","sender":{"id":"u-1"},"text":"hi"}`
+
+	tampered := signedRequest(t, valid, testNow)
+	tampered.Body = io.NopCloser(strings.NewReader(`{"message_id":"m-1","sender":{"id":"u-2"},"text":"hi"}`))
+	stale := signedRequest(t, valid, testNow.Add(-10*time.Minute))
+	noSender := signedRequest(t, `{"message_id":"m-1","text":"hi"}`, testNow)
+	badType := signedRequest(t, `{"message_id":"m-1","sender":{"id":"u-1"},"conversation":{"type":"channel"},"text":"hi"}`, testNow)
+
+	cases := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{name: "tampered body", req: tampered, want: http.StatusUnauthorized},
+		{name: "stale timestamp", req: stale, want: http.StatusUnauthorized},
+		{name: "missing sender", req: noSender, want: http.StatusBadRequest},
+		{name: "unknown conversation type", req: badType, want: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		err := testAdapter().HandleWebhook(context.Background(), cfg, handler, tc.req, httptest.NewRecorder())
+		if got := httpStatus(err); got != tc.want {
+			t.Fatalf("%s: status = %d (err %v), want %d", tc.name, got, err, tc.want)
+		}
+	}
+}
+
+func TestHandleWebhookDefaultsToPrivateConversation(t *testing.T) {
+	t.Parallel()
+
+	msg, err := buildInboundMessage(testConfig("https://chat.example.com/hook"), inboundPayload{
+		MessageID: "m-1",
+		Text:      "hello",
+		Sender:    inboundSender{ID: "u-1"},
+	})
+	if err != nil {
+		t.Fatalf("buildInboundMessage: %v", err)
+	}
+	if msg.Conversation.ID != "u-1" || msg.Conversation.Type != channel.ConversationTypePrivate {
+		t.Fatalf("conversation = %+v", msg.Conversation)
+	}
+}
+
+func TestSendPostsSignedCallback(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !verifySignature(testSecret, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body, testNow) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		mu.Lock()
+		bodies = append(bodies, decoded)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	a := testAdapter()
+	err := a.Send(context.Background(), testConfig(server.URL), channel.PreparedOutboundMessage{
+		Target: "webhook:room-42",
+		Message: channel.PreparedMessage{
+			Message: channel.Message{
+				Format: channel.MessageFormatMarkdown,
+				Text:   "**done**",
+				Reply:  &channel.ReplyRef{MessageID: "m-1"},
+			},
+			Attachments: []channel.PreparedAttachment{
+				{
+					Logical:   channel.Attachment{Type: channel.AttachmentImage},
+					Kind:      channel.PreparedAttachmentPublicURL,
+					PublicURL: "https://cdn.example.com/a.png",
+					Name:      "a.png",
+				},
+				{
+					Logical: channel.Attachment{Type: channel.AttachmentFile},
+					Kind:    channel.PreparedAttachmentUpload,
+					Name:    "b.txt",
+					Mime:    "text/plain",
+					Open: func(context.Context) (io.ReadCloser, error) {
+						return io.NopCloser(strings.NewReader("hello")), nil
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("expected one callback, got %d", len(bodies))
+	}
+	body := bodies[0]
+	if body["target"] != "room-42" || body["text"] != "**done**" || body["format"] != "markdown" || body["reply_to"] != "m-1" || body["bot_id"] != "bot-1" {
+		t.Fatalf("callback = %v", body)
+	}
+	attachments, _ := body["attachments"].([]any)
+	if len(attachments) != 2 {
+		t.Fatalf("attachments = %v", body["attachments"])
+	}
+	if first, _ := attachments[0].(map[string]any); first["url"] != "https://cdn.example.com/a.png" {
+		t.Fatalf("first attachment = %v", first)
+	}
+	if second, _ := attachments[1].(map[string]any); second["base64"] != "aGVsbG8=" || second["name"] != "b.txt" {
+		t.Fatalf("second attachment = %v", second)
+	}
+}
+
+func TestSendReportsCallbackFailure(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = io.WriteString(w, "upstream down")
+	}))
+	t.Cleanup(server.Close)
+
+	err := testAdapter().Send(context.Background(), testConfig(server.URL), channel.PreparedOutboundMessage{
+		Target:  "room-42",
+		Message: channel.PreparedMessage{Message: channel.Message{Text: "hi"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "status 502") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
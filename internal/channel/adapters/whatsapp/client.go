@@ -0,0 +1,214 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+const defaultGraphBaseURL = "https://graph.facebook.com"
+
+// graphClient calls the WhatsApp Business Cloud API for one phone number.
+type graphClient struct {
+	http    *http.Client
+	baseURL string
+	cfg     Config
+}
+
+type graphError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+type sendResponse struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
+
+type mediaInfo struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	FileSize int64  `json:"file_size"`
+}
+
+type phoneNumberInfo struct {
+	ID                 string `json:"id"`
+	DisplayPhoneNumber string `json:"display_phone_number"`
+	VerifiedName       string `json:"verified_name"`
+}
+
+func (c *graphClient) endpoint(parts ...string) string {
+	escaped := make([]string, 0, len(parts)+1)
+	escaped = append(escaped, c.cfg.APIVersion)
+	for _, part := range parts {
+		escaped = append(escaped, url.PathEscape(part))
+	}
+	return strings.TrimRight(c.baseURL, "/") + "/" + strings.Join(escaped, "/")
+}
+
+// sendMessage posts a message object to /messages and returns its wamid.
+func (c *graphClient) sendMessage(ctx context.Context, payload map[string]any) (string, error) {
+	payload["messaging_product"] = "whatsapp"
+	var out sendResponse
+	if err := c.postJSON(ctx, c.endpoint(c.cfg.PhoneNumberID, "messages"), payload, &out); err != nil {
+		return "", err
+	}
+	if len(out.Messages) == 0 {
+		return "", nil
+	}
+	return strings.TrimSpace(out.Messages[0].ID), nil
+}
+
+// markRead sends the read receipt for an inbound message and, optionally,
+// shows the typing indicator until the next reply or for up to 25 seconds.
+func (c *graphClient) markRead(ctx context.Context, messageID string, typing bool) error {
+	payload := map[string]any{
+		"messaging_product": "whatsapp",
+		"status":            "read",
+		"message_id":        messageID,
+	}
+	if typing {
+		payload["typing_indicator"] = map[string]string{"type": "text"}
+	}
+	return c.postJSON(ctx, c.endpoint(c.cfg.PhoneNumberID, "messages"), payload, nil)
+}
+
+// uploadMedia uploads content to the phone number's media store and returns
+// the media ID to reference in a message.
+func (c *graphClient) uploadMedia(ctx context.Context, name, mimeType string, content io.Reader) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("messaging_product", "whatsapp"); err != nil {
+		return "", err
+	}
+	if err := writer.WriteField("type", mimeType); err != nil {
+		return "", err
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, name))
+	header.Set("Content-Type", mimeType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(c.cfg.PhoneNumberID, "media"), &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(req, &out); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(out.ID) == "" {
+		return "", errors.New("whatsapp upload media returned empty id")
+	}
+	return strings.TrimSpace(out.ID), nil
+}
+
+// getMediaInfo resolves a media ID to its short-lived download URL.
+func (c *graphClient) getMediaInfo(ctx context.Context, mediaID string) (mediaInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint(mediaID), nil)
+	if err != nil {
+		return mediaInfo{}, err
+	}
+	var out mediaInfo
+	if err := c.do(req, &out); err != nil {
+		return mediaInfo{}, err
+	}
+	if strings.TrimSpace(out.URL) == "" {
+		return mediaInfo{}, errors.New("whatsapp media info returned empty url")
+	}
+	return out, nil
+}
+
+// downloadMedia opens a media URL returned by getMediaInfo. The caller must
+// close the response body.
+func (c *graphClient) downloadMedia(ctx context.Context, mediaURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.AccessToken)
+	resp, err := c.http.Do(req) //nolint:gosec // G704: URL comes from the Graph API media lookup
+	if err != nil {
+		return nil, fmt.Errorf("whatsapp download media: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("whatsapp download media: status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (c *graphClient) getPhoneNumber(ctx context.Context) (phoneNumberInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint(c.cfg.PhoneNumberID)+"?fields=display_phone_number,verified_name", nil)
+	if err != nil {
+		return phoneNumberInfo{}, err
+	}
+	var out phoneNumberInfo
+	if err := c.do(req, &out); err != nil {
+		return phoneNumberInfo{}, err
+	}
+	return out, nil
+}
+
+func (c *graphClient) postJSON(ctx context.Context, endpoint string, payload any, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, out)
+}
+
+func (c *graphClient) do(req *http.Request, out any) error {
+	req.Header.Set("Authorization", "Bearer "+c.cfg.AccessToken)
+	resp, err := c.http.Do(req) //nolint:gosec // G704: Graph API base URL is fixed by the adapter
+	if err != nil {
+		return fmt.Errorf("whatsapp api: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("whatsapp api: read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var envelope struct {
+			Error graphError `json:"error"`
+		}
+		if json.Unmarshal(data, &envelope) == nil && envelope.Error.Message != "" {
+			return fmt.Errorf("whatsapp api: status %d: %s (code %d)", resp.StatusCode, envelope.Error.Message, envelope.Error.Code)
+		}
+		return fmt.Errorf("whatsapp api: status %d", resp.StatusCode)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("whatsapp api: decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package whatsapp
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/redact"
+)
+
+const defaultAPIVersion = "v21.0"
+
+var apiVersionPattern = regexp.MustCompile(`^v\d+\.\d+$`)
+
+// Config holds the WhatsApp Business Cloud API credentials of one phone
+// number.
+type Config struct {
+	PhoneNumberID string
+	AccessToken   string `json:"AccessToken"` //nolint:gosec // G117: token field, handled securely
+	AppSecret     string // verifies X-Hub-Signature-256 on webhook callbacks
+	VerifyToken   string // echoed back by Meta when the webhook is subscribed
+	APIVersion    string
+}
+
+// UserConfig identifies a WhatsApp user by their WhatsApp ID (wa_id), which
+// is the phone number in international format without "+".
+type UserConfig struct {
+	WaID string
+}
+
+func normalizeConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"phoneNumberId": cfg.PhoneNumberID,
+		"accessToken":   cfg.AccessToken,
+		"appSecret":     cfg.AppSecret,
+		"verifyToken":   cfg.VerifyToken,
+		"apiVersion":    cfg.APIVersion,
+	}, nil
+}
+
+func parseConfig(raw map[string]any) (Config, error) {
+	cfg := Config{
+		PhoneNumberID: strings.TrimSpace(channel.ReadString(raw, "phoneNumberId", "phone_number_id")),
+		AccessToken:   strings.TrimSpace(channel.ReadString(raw, "accessToken", "access_token")),
+		AppSecret:     strings.TrimSpace(channel.ReadString(raw, "appSecret", "app_secret")),
+		VerifyToken:   strings.TrimSpace(channel.ReadString(raw, "verifyToken", "verify_token")),
+		APIVersion:    strings.TrimSpace(channel.ReadString(raw, "apiVersion", "api_version")),
+	}
+	if cfg.PhoneNumberID == "" {
+		return Config{}, errors.New("whatsapp phoneNumberId is required")
+	}
+	if cfg.AccessToken == "" {
+		return Config{}, errors.New("whatsapp accessToken is required")
+	}
+	if cfg.AppSecret == "" {
+		return Config{}, errors.New("whatsapp appSecret is required")
+	}
+	if cfg.VerifyToken == "" {
+		return Config{}, errors.New("whatsapp verifyToken is required")
+	}
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = defaultAPIVersion
+	}
+	if !apiVersionPattern.MatchString(cfg.APIVersion) {
+		return Config{}, errors.New("whatsapp apiVersion must look like v21.0")
+	}
+	return cfg, nil
+}
+
+// parseConfigForUse parses the config and registers its secrets for log
+// redaction.
+func parseConfigForUse(raw map[string]any) (Config, error) {
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return Config{}, err
+	}
+	redact.SetSecrets("whatsapp:"+cfg.PhoneNumberID, cfg.AccessToken, cfg.AppSecret, cfg.VerifyToken)
+	return cfg, nil
+}
+
+func normalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"wa_id": cfg.WaID}, nil
+}
+
+func parseUserConfig(raw map[string]any) (UserConfig, error) {
+	waID := normalizeTarget(channel.ReadString(raw, "waId", "wa_id", "phone_number"))
+	if waID == "" {
+		return UserConfig{}, errors.New("whatsapp user config requires wa_id")
+	}
+	return UserConfig{WaID: waID}, nil
+}
+
+// normalizeTarget reduces a phone number or prefixed target to a bare wa_id:
+// digits only, without "+", spaces or dashes.
+func normalizeTarget(raw string) string {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, string(Type)+":")
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func resolveTarget(raw map[string]any) (string, error) {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return "", err
+	}
+	return cfg.WaID, nil
+}
+
+func matchBinding(raw map[string]any, criteria channel.BindingCriteria) bool {
+	cfg, err := parseUserConfig(raw)
+	if err != nil {
+		return false
+	}
+	if value := normalizeTarget(criteria.Attribute("wa_id")); value != "" && value == cfg.WaID {
+		return true
+	}
+	return normalizeTarget(criteria.SubjectID) == cfg.WaID
+}
+
+func buildUserConfig(identity channel.Identity) map[string]any {
+	if value := normalizeTarget(identity.Attribute("wa_id")); value != "" {
+		return map[string]any{"wa_id": value}
+	}
+	if value := normalizeTarget(identity.SubjectID); value != "" {
+		return map[string]any{"wa_id": value}
+	}
+	return map[string]any{}
+}
@@ -0,0 +1,44 @@
+package whatsapp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel/adaptertest"
+)
+
+func TestRecordedFixtures(t *testing.T) {
+	t.Parallel()
+
+	for _, fx := range adaptertest.LoadFixtures(t, "testdata/fixtures") {
+		t.Run(fx.Name, func(t *testing.T) {
+			t.Parallel()
+
+			api := adaptertest.NewAPIServer(t, fx.Responses)
+			adapter := NewAdapter(nil)
+			adapter.baseURL = api.URL
+			cfg := fx.Config(Type)
+			inbox := adaptertest.NewInbox()
+
+			req := fx.HTTPRequest(t, "/channels/whatsapp/webhook/"+cfg.ID)
+			req.Header.Set("X-Hub-Signature-256", signatureScheme.Sign(testAppSecret, req.Header, fx.Request.Body))
+			rec := httptest.NewRecorder()
+			if err := adapter.HandleWebhook(context.Background(), cfg, inbox.Handler, req, rec); err != nil {
+				t.Fatalf("HandleWebhook: %v", err)
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			adaptertest.AssertInbound(t, fx, inbox.Messages())
+
+			if fx.Reply != nil {
+				if err := adapter.Send(context.Background(), cfg, fx.ReplyMessage(t, inbox.Messages())); err != nil {
+					t.Fatalf("Send: %v", err)
+				}
+			}
+			adaptertest.AssertCalls(t, fx, api.Calls())
+		})
+	}
+}
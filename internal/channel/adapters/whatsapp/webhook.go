@@ -0,0 +1,337 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/webhookauth"
+	"github.com/memohai/memoh/internal/media"
+)
+
+const webhookMaxBodyBytes int64 = 1 << 20 // 1 MiB
+
+type webhookPayload struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		ID      string `json:"id"`
+		Changes []struct {
+			Field string       `json:"field"`
+			Value webhookValue `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+type webhookValue struct {
+	Metadata struct {
+		DisplayPhoneNumber string `json:"display_phone_number"`
+		PhoneNumberID      string `json:"phone_number_id"`
+	} `json:"metadata"`
+	Contacts []struct {
+		Profile struct {
+			Name string `json:"name"`
+		} `json:"profile"`
+		WaID string `json:"wa_id"`
+	} `json:"contacts"`
+	Messages []webhookMessage `json:"messages"`
+}
+
+type webhookMessage struct {
+	From      string `json:"from"`
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Text      *struct {
+		Body string `json:"body"`
+	} `json:"text,omitempty"`
+	Image    *webhookMedia `json:"image,omitempty"`
+	Audio    *webhookMedia `json:"audio,omitempty"`
+	Video    *webhookMedia `json:"video,omitempty"`
+	Document *webhookMedia `json:"document,omitempty"`
+	Sticker  *webhookMedia `json:"sticker,omitempty"`
+	Location *struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+		Name      string  `json:"name"`
+		Address   string  `json:"address"`
+	} `json:"location,omitempty"`
+	Button *struct {
+		Text    string `json:"text"`
+		Payload string `json:"payload"`
+	} `json:"button,omitempty"`
+	Interactive *struct {
+		Type        string             `json:"type"`
+		ButtonReply *webhookReplyTitle `json:"button_reply,omitempty"`
+		ListReply   *webhookReplyTitle `json:"list_reply,omitempty"`
+	} `json:"interactive,omitempty"`
+	Context *struct {
+		From string `json:"from"`
+		ID   string `json:"id"`
+	} `json:"context,omitempty"`
+}
+
+type webhookMedia struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	SHA256   string `json:"sha256"`
+	Caption  string `json:"caption"`
+	Filename string `json:"filename"`
+	Voice    bool   `json:"voice"`
+	Animated bool   `json:"animated"`
+}
+
+type webhookReplyTitle struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// HandleWebhook serves the Cloud API webhook: GET answers Meta's subscription
+// handshake, POST delivers message notifications signed with the app secret.
+// Status notifications (sent, delivered, read) are acknowledged and ignored.
+func (a *Adapter) HandleWebhook(ctx context.Context, cfg channel.ChannelConfig, handler channel.InboundHandler, r *http.Request, w http.ResponseWriter) error {
+	waCfg, err := parseConfigForUse(cfg.Credentials)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "whatsapp channel not configured")
+	}
+	switch r.Method {
+	case http.MethodGet:
+		return handleVerifyRequest(waCfg, r, w)
+	case http.MethodPost:
+		return a.handleNotification(ctx, waCfg, cfg, handler, r, w)
+	default:
+		return echo.NewHTTPError(http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func handleVerifyRequest(cfg Config, r *http.Request, w http.ResponseWriter) error {
+	query := r.URL.Query()
+	if query.Get("hub.mode") != "subscribe" {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid hub.mode")
+	}
+	if webhookauth.VerifyToken(query.Get("hub.verify_token"), cfg.VerifyToken) != nil {
+		return echo.NewHTTPError(http.StatusForbidden, "invalid verify token")
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(query.Get("hub.challenge"))) //nolint:gosec // Meta requires echoing the challenge verbatim.
+	return nil
+}
+
+func (a *Adapter) handleNotification(ctx context.Context, waCfg Config, cfg channel.ChannelConfig, handler channel.InboundHandler, r *http.Request, w http.ResponseWriter) error {
+	if handler == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "whatsapp inbound handler is nil")
+	}
+	body, err := media.ReadAllWithLimit(r.Body, webhookMaxBodyBytes)
+	if err != nil {
+		if errors.Is(err, media.ErrAssetTooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "payload too large")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to read request body")
+	}
+	if signatureScheme.Verify(r.Header, body, waCfg.AppSecret, time.Now()) != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid signature")
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid whatsapp payload")
+	}
+
+	var queueFull, failed bool
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+			// One app can subscribe several phone numbers to the same URL.
+			if id := strings.TrimSpace(change.Value.Metadata.PhoneNumberID); id != "" && id != waCfg.PhoneNumberID {
+				continue
+			}
+			for _, raw := range change.Value.Messages {
+				msg, ok := buildInboundMessage(cfg, change.Value, raw)
+				if !ok {
+					continue
+				}
+				if !a.claimMessage(cfg.ID, msg.Message.ID) {
+					continue
+				}
+				if err := handler(ctx, cfg, msg); err != nil {
+					a.forgetMessage(cfg.ID, msg.Message.ID)
+					if channel.IsInboundQueueFull(err) {
+						queueFull = true
+						continue
+					}
+					failed = true
+					a.logger.Warn("whatsapp inbound handling failed",
+						slog.String("config_id", cfg.ID),
+						slog.String("message_id", msg.Message.ID),
+						slog.Any("error", err),
+					)
+				}
+			}
+		}
+	}
+	// Meta redelivers on non-2xx responses; dedup drops the messages that
+	// already went through.
+	if queueFull {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "whatsapp inbound queue full")
+	}
+	if failed {
+		return echo.NewHTTPError(http.StatusInternalServerError, "whatsapp webhook processing failed")
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// signatureScheme checks X-Hub-Signature-256: "sha256=" followed by the hex
+// HMAC-SHA256 of the raw body keyed with the app secret. The body carries
+// no signing time; redeliveries are dropped by message id dedup.
+var signatureScheme = webhookauth.Scheme{
+	SignatureHeader: "X-Hub-Signature-256",
+	Prefix:          "sha256=",
+	Encoding:        webhookauth.Hex,
+	Sum: func(secret string, _ http.Header, body []byte) []byte {
+		return webhookauth.HMACSHA256(secret, body)
+	},
+}
+
+func buildInboundMessage(cfg channel.ChannelConfig, value webhookValue, raw webhookMessage) (channel.InboundMessage, bool) {
+	waID := normalizeTarget(raw.From)
+	messageID := strings.TrimSpace(raw.ID)
+	if waID == "" || messageID == "" {
+		return channel.InboundMessage{}, false
+	}
+	message, ok := buildMessageContent(raw)
+	if !ok || message.IsEmpty() {
+		return channel.InboundMessage{}, false
+	}
+	message.ID = messageID
+	if raw.Context != nil && strings.TrimSpace(raw.Context.ID) != "" {
+		message.Reply = &channel.ReplyRef{
+			MessageID: strings.TrimSpace(raw.Context.ID),
+			Sender:    strings.TrimSpace(raw.Context.From),
+		}
+	}
+
+	displayName := ""
+	for _, contact := range value.Contacts {
+		if normalizeTarget(contact.WaID) == waID {
+			displayName = strings.TrimSpace(contact.Profile.Name)
+			break
+		}
+	}
+	receivedAt := time.Now().UTC()
+	if seconds, err := strconv.ParseInt(strings.TrimSpace(raw.Timestamp), 10, 64); err == nil && seconds > 0 {
+		receivedAt = time.Unix(seconds, 0).UTC()
+	}
+	return channel.InboundMessage{
+		Channel:     Type,
+		Message:     message,
+		BotID:       cfg.BotID,
+		ReplyTarget: waID,
+		RouteKey:    channel.GenerateRoutingKey(Type.String(), cfg.BotID, waID, channel.ConversationTypePrivate, waID),
+		Sender: channel.Identity{
+			SubjectID:   waID,
+			DisplayName: displayName,
+			Attributes: map[string]string{
+				"wa_id": waID,
+			},
+		},
+		Conversation: channel.Conversation{
+			ID:   waID,
+			Type: channel.ConversationTypePrivate,
+			Name: displayName,
+		},
+		ReceivedAt: receivedAt,
+		Source:     Type.String(),
+		Metadata: map[string]any{
+			"message_type":    raw.Type,
+			"phone_number_id": strings.TrimSpace(value.Metadata.PhoneNumberID),
+		},
+	}, true
+}
+
+func buildMessageContent(raw webhookMessage) (channel.Message, bool) {
+	msg := channel.Message{Format: channel.MessageFormatPlain}
+	switch raw.Type {
+	case "text":
+		if raw.Text == nil {
+			return channel.Message{}, false
+		}
+		msg.Text = strings.TrimSpace(raw.Text.Body)
+	case "image":
+		return mediaMessage(msg, raw.Image, channel.AttachmentImage)
+	case "audio":
+		attType := channel.AttachmentAudio
+		if raw.Audio != nil && raw.Audio.Voice {
+			attType = channel.AttachmentVoice
+		}
+		return mediaMessage(msg, raw.Audio, attType)
+	case "video":
+		return mediaMessage(msg, raw.Video, channel.AttachmentVideo)
+	case "document":
+		return mediaMessage(msg, raw.Document, channel.AttachmentFile)
+	case "sticker":
+		return mediaMessage(msg, raw.Sticker, channel.AttachmentSticker)
+	case "location":
+		if raw.Location == nil {
+			return channel.Message{}, false
+		}
+		lat := strconv.FormatFloat(raw.Location.Latitude, 'f', -1, 64)
+		lng := strconv.FormatFloat(raw.Location.Longitude, 'f', -1, 64)
+		label := strings.TrimSpace(strings.Join([]string{raw.Location.Name, raw.Location.Address}, " "))
+		msg.Text = strings.TrimSpace("[location] " + label + " (" + lat + ", " + lng + ")")
+		msg.Metadata = map[string]any{"latitude": raw.Location.Latitude, "longitude": raw.Location.Longitude}
+	case "button":
+		// Quick-reply button on a template message.
+		if raw.Button == nil {
+			return channel.Message{}, false
+		}
+		msg.Text = strings.TrimSpace(raw.Button.Text)
+		msg.Metadata = map[string]any{"button_payload": strings.TrimSpace(raw.Button.Payload)}
+	case "interactive":
+		if raw.Interactive == nil {
+			return channel.Message{}, false
+		}
+		reply := raw.Interactive.ButtonReply
+		if reply == nil {
+			reply = raw.Interactive.ListReply
+		}
+		if reply == nil {
+			return channel.Message{}, false
+		}
+		msg.Text = strings.TrimSpace(reply.Title)
+		msg.Metadata = map[string]any{"reply_id": strings.TrimSpace(reply.ID)}
+	default:
+		return channel.Message{}, false
+	}
+	return msg, true
+}
+
+func mediaMessage(msg channel.Message, item *webhookMedia, attType channel.AttachmentType) (channel.Message, bool) {
+	if item == nil || strings.TrimSpace(item.ID) == "" {
+		return channel.Message{}, false
+	}
+	caption := strings.TrimSpace(item.Caption)
+	msg.Text = caption
+	msg.Attachments = []channel.Attachment{channel.NormalizeInboundChannelAttachment(channel.Attachment{
+		Type:           attType,
+		PlatformKey:    strings.TrimSpace(item.ID),
+		SourcePlatform: Type.String(),
+		Name:           strings.TrimSpace(item.Filename),
+		Mime:           strings.TrimSpace(item.MimeType),
+		Caption:        caption,
+		Metadata: map[string]any{
+			"media_id": strings.TrimSpace(item.ID),
+			"sha256":   strings.TrimSpace(item.SHA256),
+		},
+	})}
+	return msg, true
+}
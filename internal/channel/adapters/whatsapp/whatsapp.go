@@ -0,0 +1,471 @@
+// Package whatsapp implements a channel adapter for the WhatsApp Business
+// Cloud API: inbound messages arrive on the generic channel webhook and
+// replies go out through the Graph API /messages endpoint.
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/attachment"
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/media"
+)
+
+// Type is the registered ChannelType identifier for WhatsApp.
+const Type channel.ChannelType = "whatsapp"
+
+const (
+	// textMaxRunes is the Cloud API limit for a text message body.
+	textMaxRunes = 4096
+	// mediaMaxBytes is the largest media file the Cloud API accepts
+	// (documents; images and audio are smaller).
+	mediaMaxBytes       = 100 << 20
+	messageDedupeTTL    = 24 * time.Hour
+	templateMetadataKey = "whatsapp_template"
+)
+
+// Adapter connects bots to WhatsApp through the Business Cloud API.
+type Adapter struct {
+	logger  *slog.Logger
+	http    *http.Client
+	baseURL string
+
+	seenMu sync.Mutex
+	seen   map[string]time.Time // keyed by configID:wamid
+}
+
+var (
+	_ channel.Sender                   = (*Adapter)(nil)
+	_ channel.StreamSender             = (*Adapter)(nil)
+	_ channel.WebhookReceiver          = (*Adapter)(nil)
+	_ channel.Receiver                 = (*Adapter)(nil)
+	_ channel.AttachmentResolver       = (*Adapter)(nil)
+	_ channel.ProcessingStatusNotifier = (*Adapter)(nil)
+	_ channel.SelfDiscoverer           = (*Adapter)(nil)
+)
+
+// NewAdapter creates a WhatsApp Adapter with the given logger.
+func NewAdapter(log *slog.Logger) *Adapter {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Adapter{
+		logger:  log.With(slog.String("adapter", "whatsapp")),
+		http:    &http.Client{Timeout: 60 * time.Second},
+		baseURL: defaultGraphBaseURL,
+		seen:    make(map[string]time.Time),
+	}
+}
+
+// Type returns the WhatsApp channel type.
+func (*Adapter) Type() channel.ChannelType { return Type }
+
+// Descriptor returns the WhatsApp channel metadata.
+func (*Adapter) Descriptor() channel.Descriptor {
+	return channel.Descriptor{
+		Type:        Type,
+		DisplayName: "WhatsApp",
+		Capabilities: channel.ChannelCapabilities{
+			Text:           true,
+			Attachments:    true,
+			Media:          true,
+			Reply:          true,
+			BlockStreaming: true,
+			ChatTypes:      []string{channel.ConversationTypePrivate},
+		},
+		OutboundPolicy: channel.OutboundPolicy{
+			TextChunkLimit: textMaxRunes,
+			ChunkerMode:    channel.ChunkerModeText,
+			MediaOrder:     channel.OutboundOrderTextFirst,
+		},
+		ConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"phoneNumberId": {
+					Type:        channel.FieldString,
+					Required:    true,
+					Order:       0,
+					Title:       "Phone Number ID",
+					Description: "ID of the business phone number in the Meta app dashboard (not the phone number itself)",
+				},
+				"accessToken": {
+					Type:        channel.FieldSecret,
+					Required:    true,
+					Order:       10,
+					Title:       "Access Token",
+					Description: "System user access token with whatsapp_business_messaging permission",
+				},
+				"appSecret": {
+					Type:        channel.FieldSecret,
+					Required:    true,
+					Order:       20,
+					Title:       "App Secret",
+					Description: "Verifies the X-Hub-Signature-256 header of webhook callbacks",
+				},
+				"verifyToken": {
+					Type:        channel.FieldSecret,
+					Required:    true,
+					Order:       30,
+					Title:       "Verify Token",
+					Description: "Any string; enter the same value when subscribing the webhook in the Meta app dashboard",
+				},
+				"apiVersion": {
+					Type:    channel.FieldString,
+					Order:   40,
+					Title:   "Graph API Version",
+					Example: defaultAPIVersion,
+				},
+			},
+		},
+		UserConfigSchema: channel.ConfigSchema{
+			Version: 1,
+			Fields: map[string]channel.FieldSchema{
+				"wa_id": {Type: channel.FieldString, Required: true, Title: "WhatsApp ID"},
+			},
+		},
+		TargetSpec: channel.TargetSpec{
+			Format: "wa_id (phone number with country code, digits only)",
+			Hints: []channel.TargetHint{
+				{Label: "WhatsApp ID", Example: "15551234567"},
+			},
+		},
+	}
+}
+
+// NormalizeConfig validates and normalizes a WhatsApp channel configuration.
+func (*Adapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeConfig(raw)
+}
+
+// NormalizeUserConfig validates and normalizes a WhatsApp user binding.
+func (*Adapter) NormalizeUserConfig(raw map[string]any) (map[string]any, error) {
+	return normalizeUserConfig(raw)
+}
+
+// NormalizeTarget reduces a phone number to a bare wa_id.
+func (*Adapter) NormalizeTarget(raw string) string { return normalizeTarget(raw) }
+
+// ResolveTarget returns the wa_id of a user binding.
+func (*Adapter) ResolveTarget(userConfig map[string]any) (string, error) {
+	return resolveTarget(userConfig)
+}
+
+// MatchBinding reports whether a user binding matches the criteria.
+func (*Adapter) MatchBinding(config map[string]any, criteria channel.BindingCriteria) bool {
+	return matchBinding(config, criteria)
+}
+
+// BuildUserConfig builds a user binding from an inbound identity.
+func (*Adapter) BuildUserConfig(identity channel.Identity) map[string]any {
+	return buildUserConfig(identity)
+}
+
+// DiscoverSelf looks up the business phone number behind the config.
+func (a *Adapter) DiscoverSelf(ctx context.Context, credentials map[string]any) (map[string]any, string, error) {
+	cfg, err := parseConfigForUse(credentials)
+	if err != nil {
+		return nil, "", err
+	}
+	info, err := a.client(cfg).getPhoneNumber(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("whatsapp get phone number: %w", err)
+	}
+	identity := map[string]any{"phone_number_id": cfg.PhoneNumberID}
+	if value := strings.TrimSpace(info.DisplayPhoneNumber); value != "" {
+		identity["display_phone_number"] = value
+	}
+	if value := strings.TrimSpace(info.VerifiedName); value != "" {
+		identity["verified_name"] = value
+	}
+	return identity, cfg.PhoneNumberID, nil
+}
+
+// Connect validates the config. Inbound messages arrive on the webhook, so
+// there is no long-lived connection.
+func (*Adapter) Connect(_ context.Context, cfg channel.ChannelConfig, _ channel.InboundHandler) (channel.Connection, error) {
+	if _, err := parseConfigForUse(cfg.Credentials); err != nil {
+		return nil, err
+	}
+	return channel.NewConnection(cfg, func(context.Context) error { return nil }), nil
+}
+
+func (a *Adapter) client(cfg Config) *graphClient {
+	return &graphClient{http: a.http, baseURL: a.baseURL, cfg: cfg}
+}
+
+// --- Sender ---
+
+// messageTemplate is a pre-approved template message. Outside the 24-hour
+// customer service window WhatsApp only delivers templates, so callers put
+// one in the message metadata under "whatsapp_template".
+type messageTemplate struct {
+	Name       string `json:"name"`
+	Language   string `json:"language"`
+	Components []any  `json:"components,omitempty"`
+}
+
+// Send delivers text first, then each attachment as its own media message.
+// A template in the metadata replaces the text.
+func (a *Adapter) Send(ctx context.Context, cfg channel.ChannelConfig, msg channel.PreparedOutboundMessage) error {
+	waCfg, err := parseConfigForUse(cfg.Credentials)
+	if err != nil {
+		return err
+	}
+	target := normalizeTarget(msg.Target)
+	if target == "" {
+		return errors.New("whatsapp target is required")
+	}
+	client := a.client(waCfg)
+	replyTo := ""
+	if reply := msg.Message.Message.Reply; reply != nil {
+		replyTo = strings.TrimSpace(reply.MessageID)
+	}
+	payloads := make([]map[string]any, 0, 1+len(msg.Message.Attachments))
+
+	template, hasTemplate, err := templateFromMetadata(msg.Message.Message.Metadata)
+	if err != nil {
+		return err
+	}
+	if hasTemplate {
+		tpl := map[string]any{
+			"name":     template.Name,
+			"language": map[string]string{"code": template.Language},
+		}
+		if len(template.Components) > 0 {
+			tpl["components"] = template.Components
+		}
+		payloads = append(payloads, map[string]any{"type": "template", "template": tpl})
+	} else if text := strings.TrimSpace(msg.Message.Message.PlainText()); text != "" {
+		payloads = append(payloads, map[string]any{
+			"type": "text",
+			"text": map[string]any{"body": text, "preview_url": true},
+		})
+	}
+	for _, att := range msg.Message.Attachments {
+		payload, err := a.mediaPayload(ctx, client, att)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, payload)
+	}
+	if len(payloads) == 0 {
+		return errors.New("whatsapp message is required")
+	}
+
+	for i, payload := range payloads {
+		payload["recipient_type"] = "individual"
+		payload["to"] = target
+		if i == 0 && replyTo != "" {
+			payload["context"] = map[string]string{"message_id": replyTo}
+		}
+		if _, err := client.sendMessage(ctx, payload); err != nil {
+			a.logger.Error("send failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+			return err
+		}
+	}
+	return nil
+}
+
+func templateFromMetadata(metadata map[string]any) (messageTemplate, bool, error) {
+	raw, ok := metadata[templateMetadataKey]
+	if !ok || raw == nil {
+		return messageTemplate{}, false, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return messageTemplate{}, false, fmt.Errorf("whatsapp template: %w", err)
+	}
+	var tpl messageTemplate
+	if err := json.Unmarshal(data, &tpl); err != nil {
+		return messageTemplate{}, false, fmt.Errorf("whatsapp template: %w", err)
+	}
+	tpl.Name = strings.TrimSpace(tpl.Name)
+	tpl.Language = strings.TrimSpace(tpl.Language)
+	if tpl.Name == "" || tpl.Language == "" {
+		return messageTemplate{}, false, errors.New("whatsapp template requires name and language")
+	}
+	return tpl, true, nil
+}
+
+// mediaPayload builds the media message for an attachment, reusing WhatsApp
+// media IDs, linking public URLs and uploading everything else.
+func (a *Adapter) mediaPayload(ctx context.Context, client *graphClient, att channel.PreparedAttachment) (map[string]any, error) {
+	mediaType := mediaTypeFor(att.Logical.Type)
+	object := map[string]any{}
+	switch {
+	case att.Kind == channel.PreparedAttachmentNativeRef && strings.TrimSpace(att.NativeRef) != "":
+		object["id"] = strings.TrimSpace(att.NativeRef)
+	case att.Kind == channel.PreparedAttachmentPublicURL && strings.TrimSpace(att.PublicURL) != "":
+		object["link"] = strings.TrimSpace(att.PublicURL)
+	case att.Kind == channel.PreparedAttachmentUpload && att.Open != nil:
+		mediaID, err := uploadAttachment(ctx, client, att)
+		if err != nil {
+			return nil, err
+		}
+		object["id"] = mediaID
+	default:
+		return nil, fmt.Errorf("whatsapp does not support attachment kind %q", att.Kind)
+	}
+	if caption := strings.TrimSpace(att.Logical.Caption); caption != "" && mediaType != "audio" && mediaType != "sticker" {
+		object["caption"] = caption
+	}
+	if mediaType == "document" {
+		if name := attachmentName(att); name != "" {
+			object["filename"] = name
+		}
+	}
+	return map[string]any{"type": mediaType, mediaType: object}, nil
+}
+
+func uploadAttachment(ctx context.Context, client *graphClient, att channel.PreparedAttachment) (string, error) {
+	reader, err := att.Open(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = reader.Close() }()
+	mimeType := attachment.NormalizeMime(att.Mime)
+	if mimeType == "" {
+		mimeType = attachment.NormalizeMime(att.Logical.Mime)
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	name := attachmentName(att)
+	if name == "" {
+		name = "file"
+	}
+	mediaID, err := client.uploadMedia(ctx, name, mimeType, reader)
+	if err != nil {
+		return "", fmt.Errorf("whatsapp upload media: %w", err)
+	}
+	return mediaID, nil
+}
+
+func mediaTypeFor(attType channel.AttachmentType) string {
+	switch attType {
+	case channel.AttachmentImage:
+		return "image"
+	case channel.AttachmentAudio, channel.AttachmentVoice:
+		return "audio"
+	case channel.AttachmentVideo:
+		return "video"
+	case channel.AttachmentSticker:
+		return "sticker"
+	default:
+		// WhatsApp images are JPEG/PNG only, so GIFs go out as documents too.
+		return "document"
+	}
+}
+
+func attachmentName(att channel.PreparedAttachment) string {
+	if name := strings.TrimSpace(att.Name); name != "" {
+		return name
+	}
+	return strings.TrimSpace(att.Logical.Name)
+}
+
+// --- AttachmentResolver ---
+
+// ResolveAttachment downloads inbound media by its WhatsApp media ID.
+func (a *Adapter) ResolveAttachment(ctx context.Context, cfg channel.ChannelConfig, att channel.Attachment) (channel.AttachmentPayload, error) {
+	waCfg, err := parseConfigForUse(cfg.Credentials)
+	if err != nil {
+		return channel.AttachmentPayload{}, err
+	}
+	mediaID := strings.TrimSpace(att.PlatformKey)
+	if mediaID == "" {
+		return channel.AttachmentPayload{}, errors.New("whatsapp attachment platform_key(media id) is required")
+	}
+	client := a.client(waCfg)
+	info, err := client.getMediaInfo(ctx, mediaID)
+	if err != nil {
+		return channel.AttachmentPayload{}, err
+	}
+	if info.FileSize > mediaMaxBytes {
+		return channel.AttachmentPayload{}, fmt.Errorf("%w: max %d bytes", media.ErrAssetTooLarge, mediaMaxBytes)
+	}
+	resp, err := client.downloadMedia(ctx, info.URL)
+	if err != nil {
+		return channel.AttachmentPayload{}, err
+	}
+	mimeType := attachment.NormalizeMime(info.MimeType)
+	if mimeType == "" {
+		mimeType = attachment.NormalizeMime(resp.Header.Get("Content-Type"))
+	}
+	return channel.AttachmentPayload{
+		Reader: resp.Body,
+		Mime:   mimeType,
+		Name:   strings.TrimSpace(att.Name),
+		Size:   info.FileSize,
+	}, nil
+}
+
+// --- ProcessingStatusNotifier ---
+
+// ProcessingStarted marks the inbound message as read (blue ticks) and shows
+// the typing indicator, which WhatsApp clears on the reply.
+func (a *Adapter) ProcessingStarted(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, info channel.ProcessingStatusInfo) (channel.ProcessingStatusHandle, error) {
+	messageID := strings.TrimSpace(info.SourceMessageID)
+	if messageID == "" {
+		messageID = strings.TrimSpace(msg.Message.ID)
+	}
+	if messageID == "" {
+		return channel.ProcessingStatusHandle{}, nil
+	}
+	waCfg, err := parseConfigForUse(cfg.Credentials)
+	if err != nil {
+		return channel.ProcessingStatusHandle{}, err
+	}
+	if err := a.client(waCfg).markRead(ctx, messageID, true); err != nil {
+		return channel.ProcessingStatusHandle{}, err
+	}
+	return channel.ProcessingStatusHandle{Token: messageID}, nil
+}
+
+// ProcessingCompleted is a no-op: the read receipt stays and the reply ends
+// the typing indicator.
+func (*Adapter) ProcessingCompleted(context.Context, channel.ChannelConfig, channel.InboundMessage, channel.ProcessingStatusInfo, channel.ProcessingStatusHandle) error {
+	return nil
+}
+
+// ProcessingFailed is a no-op; the typing indicator expires on its own.
+func (*Adapter) ProcessingFailed(context.Context, channel.ChannelConfig, channel.InboundMessage, channel.ProcessingStatusInfo, channel.ProcessingStatusHandle, error) error {
+	return nil
+}
+
+// --- webhook dedup ---
+
+// claimMessage reports whether wamid is new for the config. Meta redelivers
+// notifications until it gets a 200, so the same message can arrive twice.
+func (a *Adapter) claimMessage(configID, messageID string) bool {
+	key := configID + ":" + messageID
+	now := time.Now()
+	a.seenMu.Lock()
+	defer a.seenMu.Unlock()
+	if len(a.seen) >= 512 {
+		for seenKey, seenAt := range a.seen {
+			if now.Sub(seenAt) > messageDedupeTTL {
+				delete(a.seen, seenKey)
+			}
+		}
+	}
+	if _, ok := a.seen[key]; ok {
+		return false
+	}
+	a.seen[key] = now
+	return true
+}
+
+func (a *Adapter) forgetMessage(configID, messageID string) {
+	a.seenMu.Lock()
+	defer a.seenMu.Unlock()
+	delete(a.seen, configID+":"+messageID)
+}
@@ -0,0 +1,338 @@
+package whatsapp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+const testAppSecret = "app-secret"
+
+func testConfig() channel.ChannelConfig {
+	return channel.ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: Type,
+		Credentials: map[string]any{
+			"phoneNumberId": "1001",
+			"accessToken":   "access-token",
+			"appSecret":     testAppSecret,
+			"verifyToken":   "verify-me",
+		},
+	}
+}
+
+func signedRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	mac := hmac.New(sha256.New, []byte(testAppSecret))
+	_, _ = mac.Write([]byte(body))
+	req := httptest.NewRequest(http.MethodPost, "/channels/whatsapp/webhook/cfg-1", strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+// graphRecorder fakes the Graph API and records the JSON bodies posted to it.
+type graphRecorder struct {
+	mu     sync.Mutex
+	posts  []map[string]any
+	paths  []string
+	server *httptest.Server
+}
+
+func newGraphRecorder(t *testing.T) *graphRecorder {
+	t.Helper()
+	rec := &graphRecorder{}
+	rec.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rec.mu.Lock()
+		rec.paths = append(rec.paths, r.Method+" "+r.URL.Path)
+		rec.mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v21.0/1001/messages":
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode: %v", err)
+			}
+			rec.mu.Lock()
+			rec.posts = append(rec.posts, body)
+			rec.mu.Unlock()
+			_, _ = io.WriteString(w, `{"messages":[{"id":"wamid.out"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v21.0/media-1":
+			_, _ = io.WriteString(w, `{"id":"media-1","url":"`+rec.server.URL+`/download/media-1","mime_type":"image/jpeg","file_size":5}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/download/media-1":
+			_, _ = io.WriteString(w, "jpeg!")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":{"message":"unknown path","code":100}}`)
+		}
+	}))
+	t.Cleanup(rec.server.Close)
+	return rec
+}
+
+func (r *graphRecorder) adapter() *Adapter {
+	a := NewAdapter(nil)
+	a.baseURL = r.server.URL
+	return a
+}
+
+func TestNormalizeConfigAndTarget(t *testing.T) {
+	t.Parallel()
+
+	got, err := normalizeConfig(map[string]any{
+		"phone_number_id": " 1001 ",
+		"access_token":    "token",
+		"app_secret":      "secret",
+		"verify_token":    "verify",
+	})
+	if err != nil {
+		t.Fatalf("normalizeConfig: %v", err)
+	}
+	if got["phoneNumberId"] != "1001" || got["apiVersion"] != defaultAPIVersion {
+		t.Fatalf("normalized = %v", got)
+	}
+	if _, err := normalizeConfig(map[string]any{"phoneNumberId": "1", "accessToken": "t", "appSecret": "s"}); err == nil {
+		t.Fatal("expected missing verifyToken to fail")
+	}
+	if _, err := normalizeConfig(map[string]any{"phoneNumberId": "1", "accessToken": "t", "appSecret": "s", "verifyToken": "v", "apiVersion": "latest"}); err == nil {
+		t.Fatal("expected bad apiVersion to fail")
+	}
+	if got := normalizeTarget("whatsapp:+1 (555) 123-4567"); got != "15551234567" {
+		t.Fatalf("normalizeTarget = %q", got)
+	}
+}
+
+func TestHandleWebhookVerifiesSubscription(t *testing.T) {
+	t.Parallel()
+
+	adapter := NewAdapter(nil)
+	req := httptest.NewRequest(http.MethodGet, "/?hub.mode=subscribe&hub.verify_token=verify-me&hub.challenge=12345", nil)
+	rec := httptest.NewRecorder()
+	if err := adapter.HandleWebhook(context.Background(), testConfig(), nil, req, rec); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	if rec.Code != http.StatusOK || rec.Body.String() != "12345" {
+		t.Fatalf("response %d %q", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/?hub.mode=subscribe&hub.verify_token=wrong&hub.challenge=12345", nil)
+	if err := adapter.HandleWebhook(context.Background(), testConfig(), nil, req, httptest.NewRecorder()); err == nil {
+		t.Fatal("expected wrong verify token to be rejected")
+	}
+}
+
+func TestHandleWebhookDeliversMessagesOnce(t *testing.T) {
+	t.Parallel()
+
+	body := `{"object":"whatsapp_business_account","entry":[{"id":"waba","changes":[{"field":"messages","value":{
+		"metadata":{"display_phone_number":"15550000000","phone_number_id":"1001"},
+		"contacts":[{"profile":{"name":"Ada"},"wa_id":"15551234567"}],
+		"messages":[
+			{"from":"15551234567","id":"wamid.1","timestamp":"1710000000","type":"text","text":{"body":"hello"},"context":{"from":"15550000000","id":"wamid.prev"}},
+			{"from":"15551234567","id":"wamid.2","timestamp":"1710000001","type":"image","image":{"id":"media-1","mime_type":"image/jpeg","caption":"look"}},
+			{"from":"15551234567","id":"wamid.3","timestamp":"1710000002","type":"reaction"}
+		]}}]},
+		{"id":"waba","changes":[{"field":"messages","value":{"metadata":{"phone_number_id":"2002"},
+		"messages":[{"from":"15551234567","id":"wamid.other","type":"text","text":{"body":"not ours"}}]}}]}]}`
+
+	adapter := NewAdapter(nil)
+	var got []channel.InboundMessage
+	handler := func(_ context.Context, _ channel.ChannelConfig, msg channel.InboundMessage) error {
+		got = append(got, msg)
+		return nil
+	}
+	for range 2 {
+		rec := httptest.NewRecorder()
+		if err := adapter.HandleWebhook(context.Background(), testConfig(), handler, signedRequest(t, body), rec); err != nil {
+			t.Fatalf("HandleWebhook: %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d", rec.Code)
+		}
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 messages delivered once, got %d", len(got))
+	}
+	text := got[0]
+	if text.Message.Text != "hello" || text.ReplyTarget != "15551234567" || text.Sender.DisplayName != "Ada" {
+		t.Fatalf("unexpected text message: %+v", text)
+	}
+	if text.Message.Reply == nil || text.Message.Reply.MessageID != "wamid.prev" {
+		t.Fatalf("expected reply context, got %+v", text.Message.Reply)
+	}
+	image := got[1]
+	if len(image.Message.Attachments) != 1 || image.Message.Attachments[0].PlatformKey != "media-1" || image.Message.Text != "look" {
+		t.Fatalf("unexpected image message: %+v", image.Message)
+	}
+}
+
+func TestHandleWebhookRejectsBadSignatureAndRetriesFailures(t *testing.T) {
+	t.Parallel()
+
+	body := `{"entry":[{"changes":[{"field":"messages","value":{"metadata":{"phone_number_id":"1001"},
+		"messages":[{"from":"15551234567","id":"wamid.1","type":"text","text":{"body":"hi"}}]}}]}]}`
+	adapter := NewAdapter(nil)
+
+	req := signedRequest(t, body)
+	req.Header.Set("X-Hub-Signature-256", "sha256=00")
+	if err := adapter.HandleWebhook(context.Background(), testConfig(), func(context.Context, channel.ChannelConfig, channel.InboundMessage) error {
+		t.Fatal("handler called for unsigned request")
+		return nil
+	}, req, httptest.NewRecorder()); err == nil {
+		t.Fatal("expected bad signature to be rejected")
+	}
+
+	calls := 0
+	handler := func(context.Context, channel.ChannelConfig, channel.InboundMessage) error {
+		calls++
+		if calls == 1 {
+			return channel.ErrInboundQueueFull
+		}
+		return nil
+	}
+	if err := adapter.HandleWebhook(context.Background(), testConfig(), handler, signedRequest(t, body), httptest.NewRecorder()); err == nil {
+		t.Fatal("expected queue full to ask Meta for redelivery")
+	}
+	if err := adapter.HandleWebhook(context.Background(), testConfig(), handler, signedRequest(t, body), httptest.NewRecorder()); err != nil {
+		t.Fatalf("redelivery: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected redelivered message to be handled again, got %d calls", calls)
+	}
+}
+
+func TestSendTextReplyAndMedia(t *testing.T) {
+	t.Parallel()
+
+	graph := newGraphRecorder(t)
+	err := graph.adapter().Send(context.Background(), testConfig(), channel.PreparedOutboundMessage{
+		Target: "+1 555 123 4567",
+		Message: channel.PreparedMessage{
+			Message: channel.Message{Text: "here you go", Reply: &channel.ReplyRef{MessageID: "wamid.1"}},
+			Attachments: []channel.PreparedAttachment{{
+				Logical:   channel.Attachment{Type: channel.AttachmentImage, Caption: "chart"},
+				Kind:      channel.PreparedAttachmentNativeRef,
+				NativeRef: "media-1",
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(graph.posts) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(graph.posts))
+	}
+	text := graph.posts[0]
+	if text["type"] != "text" || text["to"] != "15551234567" || text["messaging_product"] != "whatsapp" {
+		t.Fatalf("unexpected text payload: %v", text)
+	}
+	if ctx, _ := text["context"].(map[string]any); ctx["message_id"] != "wamid.1" {
+		t.Fatalf("expected reply context on first message: %v", text)
+	}
+	image, _ := graph.posts[1]["image"].(map[string]any)
+	if graph.posts[1]["type"] != "image" || image["id"] != "media-1" || image["caption"] != "chart" {
+		t.Fatalf("unexpected image payload: %v", graph.posts[1])
+	}
+	if _, ok := graph.posts[1]["context"]; ok {
+		t.Fatalf("reply context should only be on the first message: %v", graph.posts[1])
+	}
+}
+
+func TestSendTemplateFromMetadata(t *testing.T) {
+	t.Parallel()
+
+	graph := newGraphRecorder(t)
+	err := graph.adapter().Send(context.Background(), testConfig(), channel.PreparedOutboundMessage{
+		Target: "15551234567",
+		Message: channel.PreparedMessage{Message: channel.Message{
+			Text: "fallback",
+			Metadata: map[string]any{templateMetadataKey: map[string]any{
+				"name":     "order_update",
+				"language": "en_US",
+				"components": []any{map[string]any{
+					"type":       "body",
+					"parameters": []any{map[string]any{"type": "text", "text": "#42"}},
+				}},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(graph.posts) != 1 || graph.posts[0]["type"] != "template" {
+		t.Fatalf("expected one template message, got %v", graph.posts)
+	}
+	tpl, _ := graph.posts[0]["template"].(map[string]any)
+	language, _ := tpl["language"].(map[string]any)
+	if tpl["name"] != "order_update" || language["code"] != "en_US" || tpl["components"] == nil {
+		t.Fatalf("unexpected template payload: %v", tpl)
+	}
+
+	err = graph.adapter().Send(context.Background(), testConfig(), channel.PreparedOutboundMessage{
+		Target:  "15551234567",
+		Message: channel.PreparedMessage{Message: channel.Message{Metadata: map[string]any{templateMetadataKey: map[string]any{"name": "x"}}}},
+	})
+	if err == nil {
+		t.Fatal("expected template without language to fail")
+	}
+}
+
+func TestProcessingStartedSendsReadReceipt(t *testing.T) {
+	t.Parallel()
+
+	graph := newGraphRecorder(t)
+	handle, err := graph.adapter().ProcessingStarted(context.Background(), testConfig(), channel.InboundMessage{
+		Message: channel.Message{ID: "wamid.1"},
+	}, channel.ProcessingStatusInfo{})
+	if err != nil {
+		t.Fatalf("ProcessingStarted: %v", err)
+	}
+	if handle.Token != "wamid.1" || len(graph.posts) != 1 {
+		t.Fatalf("handle=%+v posts=%v", handle, graph.posts)
+	}
+	receipt := graph.posts[0]
+	if receipt["status"] != "read" || receipt["message_id"] != "wamid.1" || receipt["typing_indicator"] == nil {
+		t.Fatalf("unexpected read receipt: %v", receipt)
+	}
+}
+
+func TestResolveAttachmentDownloadsMedia(t *testing.T) {
+	t.Parallel()
+
+	graph := newGraphRecorder(t)
+	payload, err := graph.adapter().ResolveAttachment(context.Background(), testConfig(), channel.Attachment{PlatformKey: "media-1"})
+	if err != nil {
+		t.Fatalf("ResolveAttachment: %v", err)
+	}
+	defer func() { _ = payload.Reader.Close() }()
+	data, err := io.ReadAll(payload.Reader)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != "jpeg!" || payload.Mime != "image/jpeg" {
+		t.Fatalf("payload mime=%q data=%q", payload.Mime, data)
+	}
+
+	_, err = graph.adapter().ResolveAttachment(context.Background(), testConfig(), channel.Attachment{PlatformKey: "missing"})
+	if err == nil || !strings.Contains(err.Error(), "unknown path") {
+		t.Fatalf("expected graph error, got %v", err)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Fatal("unexpected cancellation")
+	}
+}
@@ -0,0 +1,399 @@
+// Package adaptertest replays recorded platform payloads against channel
+// adapters. A fixture holds a recorded webhook request or long-poll updates,
+// canned platform API responses, and the expected InboundMessage mapping and
+// outbound API calls. Platform APIs are served by an httptest APIServer that
+// records every call, so adapter tests run offline.
+//
+// Like partsfixture it imports channel, so it may only be used from
+// adapter tests, not from channel's own internal tests.
+package adaptertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Fixture is one recorded platform interaction.
+type Fixture struct {
+	Name string `json:"name"`
+	// Credentials and SelfIdentity configure the channel config the payload
+	// is replayed against.
+	Credentials  map[string]any `json:"credentials"`
+	SelfIdentity map[string]any `json:"self_identity,omitempty"`
+	// Request is a recorded webhook request.
+	Request *Request `json:"request,omitempty"`
+	// Updates are recorded long-poll updates, such as Telegram getUpdates
+	// results.
+	Updates []json.RawMessage `json:"updates,omitempty"`
+	// Responses are canned API responses keyed by path suffix, such as
+	// "/getMe" or "/open-apis/im/v1/messages".
+	Responses map[string]json.RawMessage `json:"responses,omitempty"`
+	// Inbound are the expected inbound messages, matched as JSON subsets.
+	Inbound []json.RawMessage `json:"inbound"`
+	// Reply, when set, is sent back to the first inbound message's reply
+	// target, and Calls are the API calls expected from the whole replay.
+	Reply *Reply `json:"reply,omitempty"`
+	Calls []Call `json:"calls,omitempty"`
+}
+
+// Request is a recorded webhook request.
+type Request struct {
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// Reply is an outbound text message sent after the inbound replay.
+type Reply struct {
+	Text string `json:"text"`
+}
+
+// Call is a platform API call. Body holds the JSON body, or the form or
+// multipart fields as a JSON object; expected bodies are matched as subsets.
+type Call struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// LoadFixtures reads every *.json fixture in dir, sorted by file name.
+func LoadFixtures(t testing.TB, dir string) []Fixture {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("list fixtures: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no fixtures in %s", dir)
+	}
+	sort.Strings(paths)
+	fixtures := make([]Fixture, 0, len(paths))
+	for _, path := range paths {
+		raw, err := os.ReadFile(path) //nolint:gosec // fixture paths come from the test's own testdata directory
+		if err != nil {
+			t.Fatalf("read fixture %s: %v", path, err)
+		}
+		var fx Fixture
+		if err := json.Unmarshal(raw, &fx); err != nil {
+			t.Fatalf("parse fixture %s: %v", path, err)
+		}
+		if fx.Name == "" {
+			fx.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		fixtures = append(fixtures, fx)
+	}
+	return fixtures
+}
+
+// Config returns the channel config a fixture is replayed against.
+func (fx Fixture) Config(channelType channel.ChannelType) channel.ChannelConfig {
+	return channel.ChannelConfig{
+		ID:           "cfg-" + fx.Name,
+		BotID:        "bot-1",
+		ChannelType:  channelType,
+		Credentials:  fx.Credentials,
+		SelfIdentity: fx.SelfIdentity,
+	}
+}
+
+// HTTPRequest builds the recorded webhook request, POSTed to target unless
+// the recording says otherwise.
+func (fx Fixture) HTTPRequest(t testing.TB, target string) *http.Request {
+	t.Helper()
+	if fx.Request == nil {
+		t.Fatalf("fixture %s has no request", fx.Name)
+	}
+	method := fx.Request.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req := httptest.NewRequestWithContext(context.Background(), method, target, bytes.NewReader(fx.Request.Body))
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range fx.Request.Headers {
+		req.Header.Set(key, value)
+	}
+	return req
+}
+
+// ReplyMessage returns the fixture reply addressed to the first inbound
+// message's reply target.
+func (fx Fixture) ReplyMessage(t testing.TB, inbound []channel.InboundMessage) channel.PreparedOutboundMessage {
+	t.Helper()
+	if fx.Reply == nil || len(inbound) == 0 {
+		t.Fatalf("fixture %s has no reply or no inbound message", fx.Name)
+	}
+	return channel.PreparedOutboundMessage{
+		Target: inbound[0].ReplyTarget,
+		Message: channel.PreparedMessage{Message: channel.Message{
+			Format: channel.MessageFormatPlain,
+			Text:   fx.Reply.Text,
+		}},
+	}
+}
+
+// Inbox collects the messages an adapter hands to its inbound handler.
+type Inbox struct {
+	mu       sync.Mutex
+	messages []channel.InboundMessage
+	notify   chan struct{}
+}
+
+// NewInbox creates an empty inbox.
+func NewInbox() *Inbox {
+	return &Inbox{notify: make(chan struct{}, 1)}
+}
+
+// Handler is the channel.InboundHandler feeding the inbox.
+func (b *Inbox) Handler(_ context.Context, _ channel.ChannelConfig, msg channel.InboundMessage) error {
+	b.mu.Lock()
+	b.messages = append(b.messages, msg)
+	b.mu.Unlock()
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Messages returns the messages received so far.
+func (b *Inbox) Messages() []channel.InboundMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]channel.InboundMessage(nil), b.messages...)
+}
+
+// Wait returns once n messages arrived, failing the test after timeout.
+// Adapters that dispatch asynchronously, such as long-poll ones, need it.
+func (b *Inbox) Wait(t testing.TB, n int, timeout time.Duration) []channel.InboundMessage {
+	t.Helper()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		if got := b.Messages(); len(got) >= n {
+			return got
+		}
+		select {
+		case <-b.notify:
+		case <-deadline.C:
+			t.Fatalf("got %d inbound messages after %s, want %d", len(b.Messages()), timeout, n)
+		}
+	}
+}
+
+// APIServer fakes a platform API: it answers from the fixture responses,
+// or from handlers registered with Handle, and records every call.
+type APIServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	calls     []Call
+	responses map[string]json.RawMessage
+	handlers  map[string]http.HandlerFunc
+}
+
+// NewAPIServer starts a fake API answering with responses, closed when the
+// test ends. Unknown paths get a 404.
+func NewAPIServer(t testing.TB, responses map[string]json.RawMessage) *APIServer {
+	t.Helper()
+	s := &APIServer{responses: responses, handlers: map[string]http.HandlerFunc{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Handle serves paths ending in suffix with h instead of a canned response.
+func (s *APIServer) Handle(suffix string, h http.HandlerFunc) {
+	s.mu.Lock()
+	s.handlers[suffix] = h
+	s.mu.Unlock()
+}
+
+// Calls returns the calls recorded so far.
+func (s *APIServer) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+func (s *APIServer) serve(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	call := Call{Method: r.Method, Path: r.URL.Path, Body: requestBody(r.Header.Get("Content-Type"), raw)}
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	handler := matchSuffix(s.handlers, r.URL.Path)
+	response := matchSuffix(s.responses, r.URL.Path)
+	s.mu.Unlock()
+
+	if handler != nil {
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		handler(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if response == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"ok":false,"code":404,"msg":"no recorded response"}`)
+		return
+	}
+	_, _ = w.Write(response)
+}
+
+// matchSuffix returns the value of the longest key path ends with.
+func matchSuffix[V any](m map[string]V, path string) V {
+	var best V
+	bestLen := -1
+	for key, value := range m {
+		if strings.HasSuffix(path, key) && len(key) > bestLen {
+			best, bestLen = value, len(key)
+		}
+	}
+	return best
+}
+
+// requestBody normalizes a request body to JSON: JSON bodies as sent, form
+// and multipart fields as an object of strings.
+func requestBody(contentType string, raw []byte) json.RawMessage {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	if json.Valid(raw) {
+		return json.RawMessage(raw)
+	}
+	fields := map[string]string{}
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(raw))
+		if err != nil {
+			return nil
+		}
+		for key := range values {
+			fields[key] = values.Get(key)
+		}
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		req := &http.Request{Header: http.Header{"Content-Type": {contentType}}, Body: io.NopCloser(bytes.NewReader(raw))}
+		if err := req.ParseMultipartForm(32 << 20); err != nil {
+			return nil
+		}
+		for key := range req.MultipartForm.Value {
+			fields[key] = req.MultipartForm.Value[key][0]
+		}
+	default:
+		return nil
+	}
+	encoded, _ := json.Marshal(fields)
+	return encoded
+}
+
+// AssertInbound checks that got matches the fixture's expected inbound
+// messages, in order, each as a JSON subset.
+func AssertInbound(t testing.TB, fx Fixture, got []channel.InboundMessage) {
+	t.Helper()
+	if len(got) != len(fx.Inbound) {
+		t.Fatalf("%s: got %d inbound messages, want %d: %s", fx.Name, len(got), len(fx.Inbound), mustJSON(got))
+	}
+	for i, want := range fx.Inbound {
+		if err := MatchJSON(want, mustJSON(got[i])); err != nil {
+			t.Fatalf("%s: inbound[%d]: %v\ngot: %s", fx.Name, i, err, mustJSON(got[i]))
+		}
+	}
+}
+
+// AssertCalls checks that the fixture's expected calls were made in order.
+// Other calls, such as token fetches, may come in between.
+func AssertCalls(t testing.TB, fx Fixture, got []Call) {
+	t.Helper()
+	next := 0
+	for _, call := range got {
+		if next == len(fx.Calls) {
+			return
+		}
+		want := fx.Calls[next]
+		if !strings.EqualFold(call.Method, want.Method) || !strings.HasSuffix(call.Path, want.Path) {
+			continue
+		}
+		if len(want.Body) > 0 && MatchJSON(want.Body, call.Body) != nil {
+			continue
+		}
+		next++
+	}
+	if next < len(fx.Calls) {
+		t.Fatalf("%s: expected call %s %s %s not made; calls: %s", fx.Name, fx.Calls[next].Method, fx.Calls[next].Path, fx.Calls[next].Body, mustJSON(got))
+	}
+}
+
+// MatchJSON reports whether got contains want: objects match when every
+// key of want matches in got, arrays element-wise with equal lengths, and
+// scalars by equality. JSON strings holding JSON documents, such as
+// Feishu's message content, are compared as documents.
+func MatchJSON(want, got json.RawMessage) error {
+	var w, g any
+	if err := json.Unmarshal(want, &w); err != nil {
+		return fmt.Errorf("invalid expected JSON: %w", err)
+	}
+	if len(got) == 0 {
+		return fmt.Errorf("got no JSON, want %s", want)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return matchValue("$", w, g)
+}
+
+func matchValue(path string, want, got any) error {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			if s, isString := got.(string); isString && json.Unmarshal([]byte(s), &g) == nil {
+				return matchValue(path, want, g)
+			}
+			return fmt.Errorf("%s: got %v, want an object", path, got)
+		}
+		for key, value := range w {
+			if err := matchValue(path+"."+key, value, g[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return fmt.Errorf("%s: got %v, want %d elements", path, got, len(w))
+		}
+		for i := range w {
+			if err := matchValue(fmt.Sprintf("%s[%d]", path, i), w[i], g[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if !reflect.DeepEqual(want, got) {
+			return fmt.Errorf("%s: got %v, want %v", path, got, want)
+		}
+		return nil
+	}
+}
+
+func mustJSON(v any) json.RawMessage {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return json.RawMessage(fmt.Sprintf("%q", err.Error()))
+	}
+	return raw
+}
@@ -0,0 +1,32 @@
+package adaptertest
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMatchJSON(t *testing.T) {
+	t.Parallel()
+
+	got := json.RawMessage(`{"receive_id":"ou_1","msg_type":"interactive","content":"{\"elements\":[{\"tag\":\"markdown\"}]}","uuid":"u-1"}`)
+	tests := []struct {
+		name    string
+		want    string
+		matches bool
+	}{
+		{name: "subset", want: `{"receive_id":"ou_1"}`, matches: true},
+		{name: "embedded document", want: `{"content":{"elements":[{"tag":"markdown"}]}}`, matches: true},
+		{name: "wrong value", want: `{"msg_type":"text"}`},
+		{name: "missing key", want: `{"chat_id":"oc_1"}`},
+		{name: "array length", want: `{"content":{"elements":[]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := MatchJSON(json.RawMessage(tt.want), got)
+			if (err == nil) != tt.matches {
+				t.Fatalf("MatchJSON(%s) error = %v, want match %v", tt.want, err, tt.matches)
+			}
+		})
+	}
+}
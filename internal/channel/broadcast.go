@@ -0,0 +1,186 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// defaultBroadcastRate is how many broadcast messages per second go to one
+// channel type. It stays below the per-bot send limits of the platforms
+// (Telegram ~30/s, Discord ~50/s, Slack ~1/s per conversation) so a
+// broadcast does not trip them for the bot's regular replies.
+const defaultBroadcastRate = 5
+
+// Broadcast delivery statuses.
+const (
+	BroadcastStatusPlanned = "planned"
+	BroadcastStatusSent    = "sent"
+	BroadcastStatusFailed  = "failed"
+)
+
+// BroadcastTarget is a conversation a broadcast can reach.
+type BroadcastTarget struct {
+	RouteID          string      `json:"route_id,omitempty"`
+	ChannelType      ChannelType `json:"channel_type"`
+	Target           string      `json:"target"`
+	ConversationType string      `json:"conversation_type,omitempty"`
+}
+
+// BroadcastTargetLister lists the bound conversations of a bot.
+type BroadcastTargetLister interface {
+	ListBroadcastTargets(ctx context.Context, botID string) ([]BroadcastTarget, error)
+}
+
+// BroadcastFilter narrows a broadcast. Empty fields match everything.
+type BroadcastFilter struct {
+	ChannelTypes      []ChannelType `json:"channel_types,omitempty"`
+	ConversationTypes []string      `json:"conversation_types,omitempty"`
+	RouteIDs          []string      `json:"route_ids,omitempty"`
+}
+
+// BroadcastRequest is the input of Manager.Broadcast.
+type BroadcastRequest struct {
+	Message Message         `json:"message"`
+	Filter  BroadcastFilter `json:"filter"`
+	// DryRun lists the conversations the message would reach without
+	// sending it.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// BroadcastDelivery is the outcome of a broadcast for one conversation.
+type BroadcastDelivery struct {
+	BroadcastTarget
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BroadcastResult summarizes a broadcast.
+type BroadcastResult struct {
+	DryRun     bool                `json:"dry_run"`
+	Targets    int                 `json:"targets"`
+	Sent       int                 `json:"sent"`
+	Failed     int                 `json:"failed"`
+	Deliveries []BroadcastDelivery `json:"deliveries"`
+}
+
+// WithBroadcastRate sets how many broadcast messages per second are sent to
+// each channel type. The default is 5.
+func WithBroadcastRate(perSecond float64) ManagerOption {
+	return func(m *Manager) {
+		if perSecond > 0 {
+			m.broadcastRate = perSecond
+		}
+	}
+}
+
+// SetBroadcastTargets sets the source of the conversations a broadcast can
+// reach.
+func (m *Manager) SetBroadcastTargets(lister BroadcastTargetLister) {
+	m.broadcastTargets = lister
+}
+
+// Broadcast sends one message to every bound conversation of a bot that
+// matches the filter. Channel types are sent to in parallel, each at the
+// broadcast rate; a failing conversation is recorded and does not stop the
+// others. With DryRun set nothing is sent.
+func (m *Manager) Broadcast(ctx context.Context, botID string, req BroadcastRequest) (BroadcastResult, error) {
+	if m.broadcastTargets == nil {
+		return BroadcastResult{}, errors.New("channel broadcast not configured")
+	}
+	if !req.DryRun && req.Message.IsEmpty() {
+		return BroadcastResult{}, errors.New("message is required")
+	}
+	targets, err := m.broadcastTargets.ListBroadcastTargets(ctx, botID)
+	if err != nil {
+		return BroadcastResult{}, err
+	}
+	targets = filterBroadcastTargets(targets, req.Filter)
+	result := BroadcastResult{DryRun: req.DryRun, Targets: len(targets), Deliveries: make([]BroadcastDelivery, len(targets))}
+	for i, target := range targets {
+		result.Deliveries[i] = BroadcastDelivery{BroadcastTarget: target, Status: BroadcastStatusPlanned}
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	byType := map[ChannelType][]int{}
+	for i, target := range targets {
+		byType[target.ChannelType] = append(byType[target.ChannelType], i)
+	}
+	var wg sync.WaitGroup
+	for _, indexes := range byType {
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+			limiter := rate.NewLimiter(rate.Limit(m.broadcastRate), 1)
+			for _, i := range indexes {
+				delivery := &result.Deliveries[i]
+				err := limiter.Wait(ctx)
+				if err == nil {
+					err = m.Send(ctx, botID, delivery.ChannelType, SendRequest{Target: delivery.Target, Message: req.Message})
+				}
+				if err != nil {
+					delivery.Status = BroadcastStatusFailed
+					delivery.Error = err.Error()
+					continue
+				}
+				delivery.Status = BroadcastStatusSent
+			}
+		}(indexes)
+	}
+	wg.Wait()
+	for _, delivery := range result.Deliveries {
+		if delivery.Status == BroadcastStatusSent {
+			result.Sent++
+		} else {
+			result.Failed++
+		}
+	}
+	if m.logger != nil {
+		m.logger.Info("broadcast sent", slog.String("bot_id", botID), slog.Int("targets", result.Targets), slog.Int("sent", result.Sent), slog.Int("failed", result.Failed))
+	}
+	return result, nil
+}
+
+// filterBroadcastTargets applies the filter and drops repeated targets, so
+// a conversation with several thread routes gets the message once. The
+// result is ordered by channel type and target.
+func filterBroadcastTargets(targets []BroadcastTarget, filter BroadcastFilter) []BroadcastTarget {
+	seen := map[string]struct{}{}
+	out := make([]BroadcastTarget, 0, len(targets))
+	for _, target := range targets {
+		target.Target = strings.TrimSpace(target.Target)
+		if target.Target == "" {
+			continue
+		}
+		if len(filter.ChannelTypes) > 0 && !slices.Contains(filter.ChannelTypes, target.ChannelType) {
+			continue
+		}
+		if len(filter.ConversationTypes) > 0 && !slices.Contains(filter.ConversationTypes, target.ConversationType) {
+			continue
+		}
+		if len(filter.RouteIDs) > 0 && !slices.Contains(filter.RouteIDs, target.RouteID) {
+			continue
+		}
+		key := target.ChannelType.String() + "\x00" + target.Target
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, target)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].ChannelType != out[j].ChannelType {
+			return out[i].ChannelType < out[j].ChannelType
+		}
+		return out[i].Target < out[j].Target
+	})
+	return out
+}
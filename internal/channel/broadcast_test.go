@@ -0,0 +1,78 @@
+package channel
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type fakeBroadcastTargets []BroadcastTarget
+
+func (f fakeBroadcastTargets) ListBroadcastTargets(context.Context, string) ([]BroadcastTarget, error) {
+	return f, nil
+}
+
+func newBroadcastManager(t *testing.T) (*Manager, *fakeAdapter) {
+	t.Helper()
+	store := &fakeConfigStore{effectiveConfig: ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: ChannelType("test"),
+		UpdatedAt:   time.Now(),
+	}}
+	adapter := &fakeAdapter{channelType: ChannelType("test")}
+	manager := NewManager(slog.New(slog.DiscardHandler), NewRegistry(), store, &fakeInboundProcessorIntegration{}, WithBroadcastRate(1000))
+	manager.RegisterAdapter(adapter)
+	manager.SetBroadcastTargets(fakeBroadcastTargets{
+		{RouteID: "r1", ChannelType: "test", Target: "group-a", ConversationType: ConversationTypeGroup},
+		{RouteID: "r2", ChannelType: "test", Target: "group-a", ConversationType: ConversationTypeGroup},
+		{RouteID: "r3", ChannelType: "test", Target: "alice", ConversationType: ConversationTypePrivate},
+		{RouteID: "r4", ChannelType: "missing", Target: "bob", ConversationType: ConversationTypePrivate},
+	})
+	return manager, adapter
+}
+
+func TestManagerBroadcastSendsOncePerConversation(t *testing.T) {
+	t.Parallel()
+
+	manager, adapter := newBroadcastManager(t)
+	result, err := manager.Broadcast(context.Background(), "bot-1", BroadcastRequest{Message: Message{Text: "maintenance tonight"}})
+	if err != nil {
+		t.Fatalf("Broadcast: %v", err)
+	}
+	if result.Targets != 3 || result.Sent != 2 || result.Failed != 1 {
+		t.Fatalf("result = %+v, want 3 targets, 2 sent, 1 failed", result)
+	}
+	for _, d := range result.Deliveries {
+		if d.ChannelType == "missing" && (d.Status != BroadcastStatusFailed || d.Error == "") {
+			t.Fatalf("unsupported channel delivery = %+v, want failed with error", d)
+		}
+	}
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if len(adapter.sent) != 2 {
+		t.Fatalf("adapter sends = %d, want 2", len(adapter.sent))
+	}
+}
+
+func TestManagerBroadcastDryRunAppliesFilter(t *testing.T) {
+	t.Parallel()
+
+	manager, adapter := newBroadcastManager(t)
+	result, err := manager.Broadcast(context.Background(), "bot-1", BroadcastRequest{
+		DryRun: true,
+		Filter: BroadcastFilter{ChannelTypes: []ChannelType{"test"}, ConversationTypes: []string{ConversationTypePrivate}},
+	})
+	if err != nil {
+		t.Fatalf("Broadcast: %v", err)
+	}
+	if result.Targets != 1 || result.Deliveries[0].Target != "alice" || result.Deliveries[0].Status != BroadcastStatusPlanned {
+		t.Fatalf("dry run = %+v, want alice planned", result)
+	}
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if len(adapter.sent) != 0 {
+		t.Fatalf("dry run sent %d messages", len(adapter.sent))
+	}
+}
@@ -9,6 +9,8 @@ type ChannelCapabilities struct {
 	Attachments     bool     `json:"attachments"`
 	Media           bool     `json:"media"`
 	Reactions       bool     `json:"reactions"`
+	Stickers        bool     `json:"stickers"`
+	LinkPreviews    bool     `json:"link_previews"`
 	Buttons         bool     `json:"buttons"`
 	URLButtons      bool     `json:"url_buttons"`
 	Reply           bool     `json:"reply"`
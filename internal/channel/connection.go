@@ -181,6 +181,9 @@ func (m *Manager) ensureConnection(ctx context.Context, cfg ChannelConfig) error
 	for i := len(m.middlewares) - 1; i >= 0; i-- {
 		handler = m.middlewares[i](handler)
 	}
+	if m.inboundBuffer != nil {
+		handler = m.inboundBuffer.Wrap(cfg, handler)
+	}
 	// Decouple long-lived adapter connections from short-lived request contexts.
 	connectCtx := context.WithoutCancel(ctx)
 	conn, err := receiver.Connect(connectCtx, cfg, handler)
@@ -0,0 +1,329 @@
+// Package deadletter keeps inbound channel messages whose processing failed.
+// Adapters acknowledge platform events before the agent runs, so a failed
+// turn would otherwise lose the message; operators list the failures and
+// replay them once the cause is fixed.
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel"
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusReplayed = "replayed"
+
+	defaultListLimit = 50
+	maxListLimit     = 500
+	// maxErrorBytes caps the stored cause; wrapped provider errors can carry
+	// whole response bodies.
+	maxErrorBytes = 4096
+	// failureRetention bounds how long a failed message, replayed or not,
+	// is kept; pruning runs at most once per pruneInterval.
+	failureRetention = 30 * 24 * time.Hour
+	pruneInterval    = time.Hour
+)
+
+var (
+	ErrFailureNotFound = errors.New("inbound failure not found")
+	ErrAlreadyReplayed = errors.New("inbound failure already replayed")
+	ErrInvalidStatus   = errors.New("invalid inbound failure status")
+)
+
+type failureQueries interface {
+	InsertInboundFailure(ctx context.Context, arg sqlc.InsertInboundFailureParams) (sqlc.InboundFailure, error)
+	DeleteInboundFailuresBefore(ctx context.Context, before pgtype.Timestamptz) (int64, error)
+	GetInboundFailure(ctx context.Context, id pgtype.UUID) (sqlc.InboundFailure, error)
+	ListInboundFailures(ctx context.Context, arg sqlc.ListInboundFailuresParams) ([]sqlc.InboundFailure, error)
+	MarkInboundFailureReplayed(ctx context.Context, id pgtype.UUID) (sqlc.InboundFailure, error)
+	MarkInboundFailureReplayFailed(ctx context.Context, arg sqlc.MarkInboundFailureReplayFailedParams) (sqlc.InboundFailure, error)
+}
+
+// Replayer re-drives a stored message through inbound processing.
+// channel.Manager implements it.
+type Replayer interface {
+	ReplayInbound(ctx context.Context, botID string, channelType channel.ChannelType, msg channel.InboundMessage) error
+}
+
+// Failure is one dead-lettered inbound message.
+type Failure struct {
+	ID              string                 `json:"id"`
+	BotID           string                 `json:"bot_id"`
+	ChannelConfigID string                 `json:"channel_config_id,omitempty"`
+	ChannelType     channel.ChannelType    `json:"channel_type"`
+	Message         channel.InboundMessage `json:"message"`
+	Error           string                 `json:"error"`
+	Attempts        int                    `json:"attempts"`
+	Status          string                 `json:"status"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+	ReplayedAt      *time.Time             `json:"replayed_at,omitempty"`
+}
+
+// ListFilter narrows List. Status defaults to pending.
+type ListFilter struct {
+	BotID  string
+	Status string
+	Limit  int
+}
+
+// Service stores and replays failed inbound messages.
+type Service struct {
+	queries dbstore.Queries
+	cipher  messagepkg.ContentCipher
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewService creates an inbound failure service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "inbound_deadletter")),
+		now:     time.Now,
+	}
+}
+
+// SetContentCipher seals stored messages with the bot's data key, as
+// message content is.
+func (s *Service) SetContentCipher(cipher messagepkg.ContentCipher) {
+	s.cipher = cipher
+}
+
+func (s *Service) store() (failureQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("inbound failure service not configured")
+	}
+	store, ok := s.queries.(failureQueries)
+	if !ok {
+		return nil, errors.New("inbound failure queries not supported by store")
+	}
+	return store, nil
+}
+
+// RecordInboundFailure stores msg with the error that stopped it, linked to
+// the sender's channel identity when one exists. It implements
+// channel.InboundFailureRecorder.
+func (s *Service) RecordInboundFailure(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, cause error) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(cfg.BotID)
+	if botID == "" {
+		botID = strings.TrimSpace(msg.BotID)
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return fmt.Errorf("invalid bot id: %w", err)
+	}
+	channelType := cfg.ChannelType
+	if channelType == "" {
+		channelType = msg.Channel
+	}
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode inbound message: %w", err)
+	}
+	if s.cipher != nil {
+		if raw, err = s.cipher.SealJSON(ctx, pgBotID, raw); err != nil {
+			return fmt.Errorf("seal inbound message: %w", err)
+		}
+	}
+	row, err := store.InsertInboundFailure(ctx, sqlc.InsertInboundFailureParams{
+		BotID:           pgBotID,
+		ChannelConfigID: strings.TrimSpace(cfg.ID),
+		ChannelType:     channelType.String(),
+		SenderSubjectID: strings.TrimSpace(msg.Sender.SubjectID),
+		Message:         raw,
+		Error:           errorText(cause),
+	})
+	if err != nil {
+		return fmt.Errorf("insert inbound failure: %w", err)
+	}
+	s.maybePrune(ctx, store)
+	s.logger.Warn("inbound message dead-lettered",
+		slog.String("failure_id", row.ID.String()),
+		slog.String("bot_id", botID),
+		slog.String("channel", channelType.String()),
+	)
+	return nil
+}
+
+// List returns failures newest first.
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]Failure, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	s.maybePrune(ctx, store)
+	status := strings.TrimSpace(filter.Status)
+	switch status {
+	case "":
+		status = StatusPending
+	case StatusPending, StatusReplayed:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	var pgBotID pgtype.UUID
+	if botID := strings.TrimSpace(filter.BotID); botID != "" {
+		if pgBotID, err = db.ParseUUID(botID); err != nil {
+			return nil, fmt.Errorf("invalid bot id: %w", err)
+		}
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+	rows, err := store.ListInboundFailures(ctx, sqlc.ListInboundFailuresParams{
+		Status:   status,
+		BotID:    pgBotID,
+		RowLimit: int32(limit), //nolint:gosec // bounded by maxListLimit.
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list inbound failures: %w", err)
+	}
+	items := make([]Failure, 0, len(rows))
+	for _, row := range rows {
+		item, err := s.toFailure(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Replay re-drives a pending failure through replayer. A successful replay
+// marks the failure replayed; otherwise it stays pending with the new cause.
+// Either way the attempt is counted and the updated failure returned.
+func (s *Service) Replay(ctx context.Context, id string, replayer Replayer) (Failure, error) {
+	store, err := s.store()
+	if err != nil {
+		return Failure{}, err
+	}
+	if replayer == nil {
+		return Failure{}, errors.New("inbound replayer not configured")
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return Failure{}, ErrFailureNotFound
+	}
+	row, err := store.GetInboundFailure(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Failure{}, ErrFailureNotFound
+		}
+		return Failure{}, fmt.Errorf("get inbound failure: %w", err)
+	}
+	if row.Status == StatusReplayed {
+		return Failure{}, ErrAlreadyReplayed
+	}
+	failure, err := s.toFailure(ctx, row)
+	if err != nil {
+		return Failure{}, err
+	}
+
+	replayErr := replayer.ReplayInbound(ctx, failure.BotID, failure.ChannelType, failure.Message)
+	if replayErr == nil {
+		row, err = store.MarkInboundFailureReplayed(ctx, pgID)
+	} else {
+		s.logger.Warn("inbound replay failed", slog.String("failure_id", failure.ID), slog.Any("error", replayErr))
+		row, err = store.MarkInboundFailureReplayFailed(ctx, sqlc.MarkInboundFailureReplayFailedParams{
+			Error: errorText(replayErr),
+			ID:    pgID,
+		})
+	}
+	if err != nil {
+		return Failure{}, fmt.Errorf("update inbound failure: %w", err)
+	}
+	return s.toFailure(ctx, row)
+}
+
+// maybePrune deletes failures older than failureRetention, at most once per
+// pruneInterval.
+func (s *Service) maybePrune(ctx context.Context, store failureQueries) {
+	now := s.now()
+	s.mu.Lock()
+	if now.Sub(s.lastPruned) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPruned = now
+	s.mu.Unlock()
+
+	before := pgtype.Timestamptz{Time: now.Add(-failureRetention).UTC(), Valid: true}
+	n, err := store.DeleteInboundFailuresBefore(ctx, before)
+	if err != nil {
+		s.logger.Warn("prune inbound failures failed", slog.Any("error", err))
+		return
+	}
+	if n > 0 {
+		s.logger.Info("pruned inbound failures", slog.Int64("count", n))
+	}
+}
+
+func (s *Service) toFailure(ctx context.Context, row sqlc.InboundFailure) (Failure, error) {
+	raw := row.Message
+	if s.cipher != nil {
+		opened, err := s.cipher.OpenJSON(ctx, row.BotID, raw)
+		if err != nil {
+			return Failure{}, fmt.Errorf("open inbound message: %w", err)
+		}
+		raw = opened
+	}
+	var msg channel.InboundMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return Failure{}, fmt.Errorf("decode inbound message: %w", err)
+	}
+	item := Failure{
+		ID:              row.ID.String(),
+		BotID:           row.BotID.String(),
+		ChannelConfigID: row.ChannelConfigID,
+		ChannelType:     channel.ChannelType(row.ChannelType),
+		Message:         msg,
+		Error:           row.Error,
+		Attempts:        int(row.Attempts),
+		Status:          row.Status,
+		CreatedAt:       db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:       db.TimeFromPg(row.UpdatedAt),
+	}
+	if row.ReplayedAt.Valid {
+		replayedAt := row.ReplayedAt.Time
+		item.ReplayedAt = &replayedAt
+	}
+	return item, nil
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	text := err.Error()
+	if len(text) > maxErrorBytes {
+		text = strings.ToValidUTF8(text[:maxErrorBytes], "")
+	}
+	return text
+}
@@ -0,0 +1,198 @@
+package deadletter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const testBotID = "11111111-1111-1111-1111-111111111111"
+
+type fakeFailureQueries struct {
+	dbstore.Queries
+
+	rows   map[pgtype.UUID]sqlc.InboundFailure
+	seq    byte
+	prunes []pgtype.Timestamptz
+}
+
+func (f *fakeFailureQueries) DeleteInboundFailuresBefore(_ context.Context, before pgtype.Timestamptz) (int64, error) {
+	f.prunes = append(f.prunes, before)
+	return 0, nil
+}
+
+func (f *fakeFailureQueries) InsertInboundFailure(_ context.Context, arg sqlc.InsertInboundFailureParams) (sqlc.InboundFailure, error) {
+	f.seq++
+	row := sqlc.InboundFailure{
+		ID:              pgtype.UUID{Bytes: [16]byte{f.seq}, Valid: true},
+		BotID:           arg.BotID,
+		ChannelConfigID: arg.ChannelConfigID,
+		ChannelType:     arg.ChannelType,
+		Message:         arg.Message,
+		Error:           arg.Error,
+		Status:          StatusPending,
+	}
+	f.rows[row.ID] = row
+	return row, nil
+}
+
+func (f *fakeFailureQueries) GetInboundFailure(_ context.Context, id pgtype.UUID) (sqlc.InboundFailure, error) {
+	row, ok := f.rows[id]
+	if !ok {
+		return sqlc.InboundFailure{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeFailureQueries) ListInboundFailures(_ context.Context, arg sqlc.ListInboundFailuresParams) ([]sqlc.InboundFailure, error) {
+	var out []sqlc.InboundFailure
+	for _, row := range f.rows {
+		if row.Status == arg.Status {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeFailureQueries) MarkInboundFailureReplayed(_ context.Context, id pgtype.UUID) (sqlc.InboundFailure, error) {
+	row := f.rows[id]
+	row.Status = StatusReplayed
+	row.Attempts++
+	f.rows[id] = row
+	return row, nil
+}
+
+func (f *fakeFailureQueries) MarkInboundFailureReplayFailed(_ context.Context, arg sqlc.MarkInboundFailureReplayFailedParams) (sqlc.InboundFailure, error) {
+	row := f.rows[arg.ID]
+	row.Error = arg.Error
+	row.Attempts++
+	f.rows[arg.ID] = row
+	return row, nil
+}
+
+// fakeCipher marks sealed documents with a prefix.
+type fakeCipher struct{}
+
+var sealedPrefix = []byte("sealed:")
+
+func (fakeCipher) SealJSON(_ context.Context, _ pgtype.UUID, doc []byte) ([]byte, error) {
+	return append(append([]byte{}, sealedPrefix...), doc...), nil
+}
+
+func (fakeCipher) OpenJSON(_ context.Context, _ pgtype.UUID, stored []byte) ([]byte, error) {
+	return bytes.TrimPrefix(stored, sealedPrefix), nil
+}
+
+func (fakeCipher) SealText(_ context.Context, _ pgtype.UUID, text string) (string, error) {
+	return string(sealedPrefix) + text, nil
+}
+
+func (fakeCipher) OpenText(_ context.Context, _ pgtype.UUID, stored string) (string, error) {
+	return string(bytes.TrimPrefix([]byte(stored), sealedPrefix)), nil
+}
+
+type fakeReplayer struct {
+	err error
+	got []channel.InboundMessage
+}
+
+func (f *fakeReplayer) ReplayInbound(_ context.Context, botID string, channelType channel.ChannelType, msg channel.InboundMessage) error {
+	if botID != testBotID || channelType != "telegram" {
+		return errors.New("unexpected replay target")
+	}
+	f.got = append(f.got, msg)
+	return f.err
+}
+
+func TestRecordAndReplayInboundFailure(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	svc := NewService(nil, &fakeFailureQueries{rows: map[pgtype.UUID]sqlc.InboundFailure{}})
+	cfg := channel.ChannelConfig{ID: "cfg-1", BotID: testBotID, ChannelType: "telegram"}
+	msg := channel.InboundMessage{
+		Channel:      "telegram",
+		BotID:        testBotID,
+		Message:      channel.Message{Text: "hello"},
+		Conversation: channel.Conversation{ID: "chat-1", Type: channel.ConversationTypePrivate},
+	}
+	if err := svc.RecordInboundFailure(ctx, cfg, msg, errors.New("model unavailable")); err != nil {
+		t.Fatalf("RecordInboundFailure returned error: %v", err)
+	}
+
+	pending, err := svc.List(ctx, ListFilter{})
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("List = %d items, %v; want 1 pending", len(pending), err)
+	}
+	failure := pending[0]
+	if failure.Error != "model unavailable" || failure.Message.Message.Text != "hello" || failure.ChannelConfigID != "cfg-1" {
+		t.Fatalf("unexpected failure: %+v", failure)
+	}
+
+	replayer := &fakeReplayer{err: errors.New("still down")}
+	got, err := svc.Replay(ctx, failure.ID, replayer)
+	if err != nil {
+		t.Fatalf("Replay returned error: %v", err)
+	}
+	if got.Status != StatusPending || got.Attempts != 1 || got.Error != "still down" {
+		t.Fatalf("failed replay should stay pending with the new cause: %+v", got)
+	}
+
+	replayer.err = nil
+	got, err = svc.Replay(ctx, failure.ID, replayer)
+	if err != nil || got.Status != StatusReplayed || got.Attempts != 2 {
+		t.Fatalf("Replay = %+v, %v; want replayed after two attempts", got, err)
+	}
+	if len(replayer.got) != 2 || replayer.got[1].Conversation.ID != "chat-1" {
+		t.Fatalf("replayer should receive the stored message: %+v", replayer.got)
+	}
+	if _, err := svc.Replay(ctx, failure.ID, replayer); !errors.Is(err, ErrAlreadyReplayed) {
+		t.Fatalf("expected ErrAlreadyReplayed, got %v", err)
+	}
+}
+
+func TestListRejectsUnknownStatus(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(nil, &fakeFailureQueries{rows: map[pgtype.UUID]sqlc.InboundFailure{}})
+	if _, err := svc.List(context.Background(), ListFilter{Status: "lost"}); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected ErrInvalidStatus, got %v", err)
+	}
+}
+
+func TestRecordSealsMessageAndPrunesOldFailures(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	queries := &fakeFailureQueries{rows: map[pgtype.UUID]sqlc.InboundFailure{}}
+	svc := NewService(nil, queries)
+	svc.SetContentCipher(fakeCipher{})
+	svc.now = func() time.Time { return now }
+	cfg := channel.ChannelConfig{BotID: testBotID, ChannelType: "telegram"}
+	msg := channel.InboundMessage{Channel: "telegram", Message: channel.Message{Text: "my card is 4111"}, Sender: channel.Identity{SubjectID: " 42 "}}
+	if err := svc.RecordInboundFailure(ctx, cfg, msg, errors.New("down")); err != nil {
+		t.Fatalf("RecordInboundFailure returned error: %v", err)
+	}
+	for _, row := range queries.rows {
+		if !bytes.HasPrefix(row.Message, sealedPrefix) {
+			t.Fatalf("stored message = %s, want it sealed", row.Message)
+		}
+	}
+	pending, err := svc.List(ctx, ListFilter{})
+	if err != nil || len(pending) != 1 || pending[0].Message.Message.Text != "my card is 4111" {
+		t.Fatalf("List = %+v, %v; want the opened message", pending, err)
+	}
+	if len(queries.prunes) != 1 || !queries.prunes[0].Time.Equal(now.Add(-failureRetention)) {
+		t.Fatalf("prunes = %+v, want one prune of failures older than the retention", queries.prunes)
+	}
+}
@@ -0,0 +1,253 @@
+// Package directorysync refreshes channel identities from platform
+// directories. Inbound messages only update the profile of their sender;
+// the sync walks group member lists and peer lists of every enabled channel
+// so names and avatars stay current for contacts that rarely write.
+package directorysync
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/identities"
+	"github.com/memohai/memoh/internal/channel/route"
+)
+
+const (
+	// startupDelay lets channel connections settle before the first sync.
+	startupDelay = time.Minute
+	// directoryTimeout bounds each directory API call.
+	directoryTimeout = 30 * time.Second
+	// groupLimit and memberLimit bound how much of a directory one sync
+	// reads per channel config and per group.
+	groupLimit  = 200
+	memberLimit = 500
+)
+
+// ConfigStore lists the bot channel configs of a channel type.
+type ConfigStore interface {
+	ListConfigsByType(ctx context.Context, channelType channel.ChannelType) ([]channel.ChannelConfig, error)
+}
+
+// RouteLister lists a bot's conversation routes. Group routes name the
+// groups a platform cannot enumerate itself.
+type RouteLister interface {
+	List(ctx context.Context, botID string) ([]route.Route, error)
+}
+
+// IdentityStore writes channel identity profiles. It is the same write path
+// inbound messages use.
+type IdentityStore interface {
+	ResolveByChannelIdentity(ctx context.Context, channel, channelSubjectID, displayName string, meta map[string]any) (identities.ChannelIdentity, error)
+}
+
+// Result counts the work of one sync.
+type Result struct {
+	Configs    int
+	Groups     int
+	Identities int
+}
+
+// Service periodically syncs channel identities from platform directories.
+type Service struct {
+	registry   *channel.Registry
+	configs    ConfigStore
+	routes     RouteLister
+	identities IdentityStore
+	logger     *slog.Logger
+}
+
+// NewService creates a directory sync service.
+func NewService(log *slog.Logger, registry *channel.Registry, configs ConfigStore, routes RouteLister, identityStore IdentityStore) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		registry:   registry,
+		configs:    configs,
+		routes:     routes,
+		identities: identityStore,
+		logger:     log.With(slog.String("service", "channel/directorysync")),
+	}
+}
+
+// Run syncs every interval until ctx is cancelled. A non-positive interval
+// disables the sync.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	timer := time.NewTimer(startupDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if result, err := s.Sync(ctx); err != nil {
+			s.logger.Warn("directory sync failed", slog.Any("error", err))
+		} else if result.Identities > 0 {
+			s.logger.Info("directory sync finished",
+				slog.Int("configs", result.Configs),
+				slog.Int("groups", result.Groups),
+				slog.Int("identities", result.Identities))
+		}
+		timer.Reset(interval)
+	}
+}
+
+// Sync refreshes identities from the directories of all enabled channel
+// configs. A config whose directory fails is logged and skipped.
+func (s *Service) Sync(ctx context.Context) (Result, error) {
+	if s == nil || s.registry == nil || s.configs == nil || s.identities == nil {
+		return Result{}, errors.New("directory sync service not configured")
+	}
+	var result Result
+	for _, channelType := range s.registry.Types() {
+		directory, ok := s.registry.DirectoryAdapter(channelType)
+		if !ok || directory == nil {
+			continue
+		}
+		configs, err := s.configs.ListConfigsByType(ctx, channelType)
+		if err != nil {
+			s.logger.Warn("list channel configs failed", slog.String("channel", channelType.String()), slog.Any("error", err))
+			continue
+		}
+		// Identities are shared by every bot on a platform, so a contact
+		// seen through several configs is written once per sync.
+		seen := map[string]bool{}
+		for _, cfg := range configs {
+			if cfg.Disabled {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			result.Configs++
+			groups, synced := s.syncConfig(ctx, directory, cfg, seen)
+			result.Groups += groups
+			result.Identities += synced
+		}
+	}
+	return result, nil
+}
+
+// syncConfig syncs the peers and group members reachable through one
+// channel config and returns how many groups and identities it covered.
+func (s *Service) syncConfig(ctx context.Context, directory channel.ChannelDirectoryAdapter, cfg channel.ChannelConfig, seen map[string]bool) (int, int) {
+	log := s.logger.With(slog.String("channel", cfg.ChannelType.String()), slog.String("bot_id", cfg.BotID))
+	synced := 0
+	peers, err := withTimeout(ctx, func(ctx context.Context) ([]channel.DirectoryEntry, error) {
+		return directory.ListPeers(ctx, cfg, channel.DirectoryQuery{Limit: memberLimit})
+	})
+	if err != nil {
+		log.Debug("list directory peers failed", slog.Any("error", err))
+	}
+	synced += s.writeEntries(ctx, cfg.ChannelType, peers, seen)
+
+	groupIDs := s.groupIDs(ctx, directory, cfg)
+	for _, groupID := range groupIDs {
+		members, err := withTimeout(ctx, func(ctx context.Context) ([]channel.DirectoryEntry, error) {
+			return directory.ListGroupMembers(ctx, cfg, groupID, channel.DirectoryQuery{Limit: memberLimit})
+		})
+		if err != nil {
+			log.Debug("list group members failed", slog.String("group_id", groupID), slog.Any("error", err))
+			continue
+		}
+		synced += s.writeEntries(ctx, cfg.ChannelType, members, seen)
+	}
+	return len(groupIDs), synced
+}
+
+// groupIDs merges the groups the platform lists with the active group routes
+// of the config's bot, for platforms that cannot list the bot's groups.
+func (s *Service) groupIDs(ctx context.Context, directory channel.ChannelDirectoryAdapter, cfg channel.ChannelConfig) []string {
+	var ids []string
+	added := map[string]bool{}
+	add := func(id string) {
+		id = strings.TrimSpace(id)
+		if id == "" || added[id] || len(ids) >= groupLimit {
+			return
+		}
+		added[id] = true
+		ids = append(ids, id)
+	}
+	groups, err := withTimeout(ctx, func(ctx context.Context) ([]channel.DirectoryEntry, error) {
+		return directory.ListGroups(ctx, cfg, channel.DirectoryQuery{Limit: groupLimit})
+	})
+	if err != nil {
+		s.logger.Debug("list directory groups failed",
+			slog.String("channel", cfg.ChannelType.String()),
+			slog.String("bot_id", cfg.BotID),
+			slog.Any("error", err))
+	}
+	for _, group := range groups {
+		add(group.ID)
+	}
+	if s.routes == nil {
+		return ids
+	}
+	routes, err := s.routes.List(ctx, cfg.BotID)
+	if err != nil {
+		s.logger.Debug("list routes failed", slog.String("bot_id", cfg.BotID), slog.Any("error", err))
+		return ids
+	}
+	for _, r := range routes {
+		if r.Platform != cfg.ChannelType.String() || r.Status == route.StatusInactive ||
+			channel.NormalizeConversationType(r.ConversationType) != channel.ConversationTypeGroup {
+			continue
+		}
+		if r.ChannelConfigID != "" && r.ChannelConfigID != cfg.ID {
+			continue
+		}
+		add(r.ExternalConversationID)
+	}
+	return ids
+}
+
+// writeEntries stores the profile of each user entry not yet seen in this
+// sync and returns how many were written.
+func (s *Service) writeEntries(ctx context.Context, channelType channel.ChannelType, entries []channel.DirectoryEntry, seen map[string]bool) int {
+	written := 0
+	for _, entry := range entries {
+		if entry.Kind != "" && entry.Kind != channel.DirectoryEntryUser {
+			continue
+		}
+		subjectID := strings.TrimSpace(entry.ID)
+		if subjectID == "" || seen[subjectID] {
+			continue
+		}
+		seen[subjectID] = true
+		name := strings.TrimSpace(entry.Name)
+		if name == "" {
+			name = strings.TrimSpace(entry.Handle)
+		}
+		avatarURL := strings.TrimSpace(entry.AvatarURL)
+		if name == "" && avatarURL == "" {
+			continue
+		}
+		var meta map[string]any
+		if avatarURL != "" {
+			meta = map[string]any{"avatar_url": avatarURL}
+		}
+		if _, err := s.identities.ResolveByChannelIdentity(ctx, channelType.String(), subjectID, name, meta); err != nil {
+			s.logger.Warn("sync channel identity failed",
+				slog.String("channel", channelType.String()),
+				slog.String("subject_id", subjectID),
+				slog.Any("error", err))
+			continue
+		}
+		written++
+	}
+	return written
+}
+
+func withTimeout(ctx context.Context, fn func(context.Context) ([]channel.DirectoryEntry, error)) ([]channel.DirectoryEntry, error) {
+	callCtx, cancel := context.WithTimeout(ctx, directoryTimeout)
+	defer cancel()
+	return fn(callCtx)
+}
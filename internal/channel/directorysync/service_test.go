@@ -0,0 +1,113 @@
+package directorysync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/identities"
+	"github.com/memohai/memoh/internal/channel/route"
+)
+
+const testChannelType = channel.ChannelType("dir-sync-test")
+
+type fakeDirectory struct {
+	members map[string][]channel.DirectoryEntry
+	listed  []string
+}
+
+func (*fakeDirectory) Type() channel.ChannelType { return testChannelType }
+
+func (*fakeDirectory) Descriptor() channel.Descriptor {
+	return channel.Descriptor{Type: testChannelType, DisplayName: "DirSyncTest"}
+}
+
+func (*fakeDirectory) ListPeers(context.Context, channel.ChannelConfig, channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	return []channel.DirectoryEntry{{Kind: channel.DirectoryEntryUser, ID: "u1", Name: "Alice", AvatarURL: "https://example.com/a.png"}}, nil
+}
+
+func (*fakeDirectory) ListGroups(context.Context, channel.ChannelConfig, channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	return []channel.DirectoryEntry{{Kind: channel.DirectoryEntryGroup, ID: "g1", Name: "Team"}}, nil
+}
+
+func (f *fakeDirectory) ListGroupMembers(_ context.Context, _ channel.ChannelConfig, groupID string, _ channel.DirectoryQuery) ([]channel.DirectoryEntry, error) {
+	f.listed = append(f.listed, groupID)
+	return f.members[groupID], nil
+}
+
+func (*fakeDirectory) ResolveEntry(context.Context, channel.ChannelConfig, string, channel.DirectoryEntryKind) (channel.DirectoryEntry, error) {
+	return channel.DirectoryEntry{}, nil
+}
+
+type fakeConfigs struct{ configs []channel.ChannelConfig }
+
+func (f fakeConfigs) ListConfigsByType(context.Context, channel.ChannelType) ([]channel.ChannelConfig, error) {
+	return f.configs, nil
+}
+
+type fakeRoutes struct{ routes []route.Route }
+
+func (f fakeRoutes) List(context.Context, string) ([]route.Route, error) { return f.routes, nil }
+
+type profile struct {
+	name   string
+	avatar string
+}
+
+type fakeIdentities struct{ profiles map[string]profile }
+
+func (f *fakeIdentities) ResolveByChannelIdentity(_ context.Context, _, subjectID, displayName string, meta map[string]any) (identities.ChannelIdentity, error) {
+	avatar, _ := meta["avatar_url"].(string)
+	f.profiles[subjectID] = profile{name: displayName, avatar: avatar}
+	return identities.ChannelIdentity{ChannelSubjectID: subjectID, DisplayName: displayName}, nil
+}
+
+func TestSyncWritesPeersAndGroupMembers(t *testing.T) {
+	t.Parallel()
+
+	directory := &fakeDirectory{members: map[string][]channel.DirectoryEntry{
+		"g1": {
+			{Kind: channel.DirectoryEntryUser, ID: "u1", Name: "Alice (dup)"},
+			{Kind: channel.DirectoryEntryUser, ID: "u2", Handle: "bob"},
+			{Kind: channel.DirectoryEntryUser, ID: "u3"},
+		},
+		"g2": {{ID: "u4", Name: "Carol"}},
+	}}
+	registry := channel.NewRegistry()
+	registry.MustRegister(directory)
+	configs := fakeConfigs{configs: []channel.ChannelConfig{
+		{ID: "cfg-1", BotID: "bot-1", ChannelType: testChannelType},
+		{ID: "cfg-2", BotID: "bot-2", ChannelType: testChannelType, Disabled: true},
+	}}
+	routes := fakeRoutes{routes: []route.Route{
+		{Platform: string(testChannelType), ChannelConfigID: "cfg-1", ExternalConversationID: "g2", ConversationType: "supergroup", Status: route.StatusActive},
+		{Platform: string(testChannelType), ExternalConversationID: "g3", ConversationType: "group", Status: route.StatusInactive},
+		{Platform: string(testChannelType), ExternalConversationID: "dm", ConversationType: "private", Status: route.StatusActive},
+		{Platform: "other", ExternalConversationID: "g4", ConversationType: "group", Status: route.StatusActive},
+	}}
+	store := &fakeIdentities{profiles: map[string]profile{}}
+
+	result, err := NewService(nil, registry, configs, routes, store).Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if result.Configs != 1 || result.Groups != 2 || result.Identities != 3 {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(directory.listed) != 2 || directory.listed[0] != "g1" || directory.listed[1] != "g2" {
+		t.Fatalf("listed groups = %v", directory.listed)
+	}
+	want := map[string]profile{
+		"u1": {name: "Alice", avatar: "https://example.com/a.png"},
+		"u2": {name: "bob"},
+		"u4": {name: "Carol"},
+	}
+	if len(store.profiles) != len(want) {
+		t.Fatalf("profiles = %+v", store.profiles)
+	}
+	for id, p := range want {
+		if store.profiles[id] != p {
+			t.Fatalf("profile %s = %+v, want %+v", id, store.profiles[id], p)
+		}
+	}
+}
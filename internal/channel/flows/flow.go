@@ -0,0 +1,255 @@
+package flows
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidFlow is returned for flow definitions that cannot be run.
+var ErrInvalidFlow = errors.New("invalid conversation flow")
+
+// Step kinds. Text is the default.
+const (
+	KindText   = "text"
+	KindNumber = "number"
+	KindEmail  = "email"
+	KindURL    = "url"
+	KindChoice = "choice"
+	KindYesNo  = "yes_no"
+)
+
+// Reasons an answer is rejected. The caller localizes them.
+const (
+	InvalidRequired = "required"
+	InvalidNumber   = "number"
+	InvalidEmail    = "email"
+	InvalidURL      = "url"
+	InvalidChoice   = "choice"
+	InvalidYesNo    = "yesNo"
+	InvalidPattern  = "pattern"
+	InvalidTooLong  = "tooLong"
+)
+
+const (
+	maxFlows       = 20
+	maxSteps       = 20
+	maxChoices     = 20
+	maxAnswerRunes = 2000
+	maxPromptRunes = 1000
+	skipAnswer     = "skip"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Config lists the flows a bot can run.
+type Config struct {
+	Flows []Flow `json:"flows"`
+}
+
+// Flow is a guided sequence of questions that fills named slots.
+type Flow struct {
+	// Name is the slug used in /flow start <name>.
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Steps       []Step `json:"steps"`
+	// Completion is sent once every step is answered. The filled-in form is
+	// then handed to the conversation so the bot can act on it.
+	Completion string `json:"completion,omitempty"`
+}
+
+// Step asks one question and stores the validated answer in Slot.
+type Step struct {
+	Slot    string   `json:"slot"`
+	Prompt  string   `json:"prompt"`
+	Kind    string   `json:"kind,omitempty"`
+	Choices []string `json:"choices,omitempty"`
+	// Pattern is an optional regular expression a text answer must match.
+	Pattern   string `json:"pattern,omitempty"`
+	MaxLength int    `json:"max_length,omitempty"`
+	// Optional steps accept "skip" and leave the slot empty.
+	Optional bool `json:"optional,omitempty"`
+}
+
+// DisplayTitle returns the title shown to contacts.
+func (f Flow) DisplayTitle() string {
+	if title := strings.TrimSpace(f.Title); title != "" {
+		return title
+	}
+	return f.Name
+}
+
+// Find returns the flow called name.
+func (c Config) Find(name string) (Flow, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, flow := range c.Flows {
+		if flow.Name == name {
+			return flow, true
+		}
+	}
+	return Flow{}, false
+}
+
+// Normalize validates cfg and returns it in canonical form: trimmed text,
+// lowercase names and kinds, and no duplicate flow or slot names.
+func Normalize(cfg Config) (Config, error) {
+	if len(cfg.Flows) > maxFlows {
+		return Config{}, fmt.Errorf("%w: at most %d flows", ErrInvalidFlow, maxFlows)
+	}
+	out := Config{Flows: make([]Flow, 0, len(cfg.Flows))}
+	names := map[string]bool{}
+	for _, flow := range cfg.Flows {
+		normalized, err := normalizeFlow(flow)
+		if err != nil {
+			return Config{}, err
+		}
+		if names[normalized.Name] {
+			return Config{}, fmt.Errorf("%w: duplicate flow %q", ErrInvalidFlow, normalized.Name)
+		}
+		names[normalized.Name] = true
+		out.Flows = append(out.Flows, normalized)
+	}
+	return out, nil
+}
+
+func normalizeFlow(flow Flow) (Flow, error) {
+	flow.Name = strings.ToLower(strings.TrimSpace(flow.Name))
+	if !namePattern.MatchString(flow.Name) {
+		return Flow{}, fmt.Errorf("%w: flow name %q must be 1-32 lowercase letters, digits, '-' or '_'", ErrInvalidFlow, flow.Name)
+	}
+	flow.Title = strings.TrimSpace(flow.Title)
+	flow.Description = strings.TrimSpace(flow.Description)
+	flow.Completion = strings.TrimSpace(flow.Completion)
+	if len(flow.Steps) == 0 || len(flow.Steps) > maxSteps {
+		return Flow{}, fmt.Errorf("%w: flow %q needs 1 to %d steps", ErrInvalidFlow, flow.Name, maxSteps)
+	}
+	steps := make([]Step, 0, len(flow.Steps))
+	slots := map[string]bool{}
+	for i, step := range flow.Steps {
+		normalized, err := normalizeStep(step)
+		if err != nil {
+			return Flow{}, fmt.Errorf("flow %q step %d: %w", flow.Name, i+1, err)
+		}
+		if slots[normalized.Slot] {
+			return Flow{}, fmt.Errorf("%w: flow %q repeats slot %q", ErrInvalidFlow, flow.Name, normalized.Slot)
+		}
+		slots[normalized.Slot] = true
+		steps = append(steps, normalized)
+	}
+	flow.Steps = steps
+	return flow, nil
+}
+
+func normalizeStep(step Step) (Step, error) {
+	step.Slot = strings.ToLower(strings.TrimSpace(step.Slot))
+	if !namePattern.MatchString(step.Slot) {
+		return Step{}, fmt.Errorf("%w: slot %q must be 1-32 lowercase letters, digits, '-' or '_'", ErrInvalidFlow, step.Slot)
+	}
+	step.Prompt = strings.TrimSpace(step.Prompt)
+	if step.Prompt == "" || utf8.RuneCountInString(step.Prompt) > maxPromptRunes {
+		return Step{}, fmt.Errorf("%w: prompt is required and at most %d characters", ErrInvalidFlow, maxPromptRunes)
+	}
+	step.Kind = strings.ToLower(strings.TrimSpace(step.Kind))
+	if step.Kind == "" {
+		step.Kind = KindText
+	}
+	switch step.Kind {
+	case KindText, KindNumber, KindEmail, KindURL, KindYesNo:
+		step.Choices = nil
+	case KindChoice:
+		choices := make([]string, 0, len(step.Choices))
+		seen := map[string]bool{}
+		for _, choice := range step.Choices {
+			choice = strings.TrimSpace(choice)
+			if choice == "" || seen[strings.ToLower(choice)] {
+				continue
+			}
+			seen[strings.ToLower(choice)] = true
+			choices = append(choices, choice)
+		}
+		if len(choices) < 2 || len(choices) > maxChoices {
+			return Step{}, fmt.Errorf("%w: choice steps need 2 to %d choices", ErrInvalidFlow, maxChoices)
+		}
+		step.Choices = choices
+	default:
+		return Step{}, fmt.Errorf("%w: unknown step kind %q", ErrInvalidFlow, step.Kind)
+	}
+	step.Pattern = strings.TrimSpace(step.Pattern)
+	if step.Pattern != "" {
+		if step.Kind != KindText {
+			return Step{}, fmt.Errorf("%w: pattern only applies to text steps", ErrInvalidFlow)
+		}
+		if _, err := regexp.Compile(step.Pattern); err != nil {
+			return Step{}, fmt.Errorf("%w: pattern: %s", ErrInvalidFlow, err.Error())
+		}
+	}
+	if step.MaxLength < 0 || step.MaxLength > maxAnswerRunes {
+		return Step{}, fmt.Errorf("%w: max_length must be between 0 and %d", ErrInvalidFlow, maxAnswerRunes)
+	}
+	return step, nil
+}
+
+// validateAnswer checks text against step and returns the value to store.
+// When the answer is rejected, reason is one of the Invalid* constants.
+func validateAnswer(step Step, text string) (value, reason string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", InvalidRequired
+	}
+	if step.Optional && strings.EqualFold(text, skipAnswer) {
+		return "", ""
+	}
+	maxLength := step.MaxLength
+	if maxLength == 0 {
+		maxLength = maxAnswerRunes
+	}
+	if utf8.RuneCountInString(text) > maxLength {
+		return "", InvalidTooLong
+	}
+	switch step.Kind {
+	case KindNumber:
+		if _, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", ""), 64); err != nil {
+			return "", InvalidNumber
+		}
+		return strings.ReplaceAll(text, ",", ""), ""
+	case KindEmail:
+		addr, err := mail.ParseAddress(text)
+		if err != nil || addr.Name != "" {
+			return "", InvalidEmail
+		}
+		return addr.Address, ""
+	case KindURL:
+		u, err := url.Parse(text)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", InvalidURL
+		}
+		return u.String(), ""
+	case KindChoice:
+		for i, choice := range step.Choices {
+			if strings.EqualFold(text, choice) || text == strconv.Itoa(i+1) {
+				return choice, ""
+			}
+		}
+		return "", InvalidChoice
+	case KindYesNo:
+		switch strings.ToLower(text) {
+		case "yes", "y", "true", "はい", "是":
+			return "yes", ""
+		case "no", "n", "false", "いいえ", "否":
+			return "no", ""
+		}
+		return "", InvalidYesNo
+	}
+	if step.Pattern != "" {
+		if re, err := regexp.Compile(step.Pattern); err == nil && !re.MatchString(text) {
+			return "", InvalidPattern
+		}
+	}
+	return text, ""
+}
@@ -0,0 +1,88 @@
+package flows
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeCanonicalizesFlows(t *testing.T) {
+	t.Parallel()
+
+	got, err := Normalize(Config{Flows: []Flow{{
+		Name:  " Bug_Report ",
+		Title: " Bug report ",
+		Steps: []Step{
+			{Slot: "Title", Prompt: " What went wrong? "},
+			{Slot: "severity", Prompt: "How bad is it?", Kind: "CHOICE", Choices: []string{"low", " high ", "Low", ""}},
+		},
+	}}})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	flow := got.Flows[0]
+	if flow.Name != "bug_report" || flow.Title != "Bug report" {
+		t.Fatalf("flow = %+v", flow)
+	}
+	if step := flow.Steps[0]; step.Slot != "title" || step.Kind != KindText || step.Prompt != "What went wrong?" {
+		t.Fatalf("text step = %+v", step)
+	}
+	if step := flow.Steps[1]; step.Kind != KindChoice || len(step.Choices) != 2 || step.Choices[1] != "high" {
+		t.Fatalf("choice step = %+v", step)
+	}
+}
+
+func TestNormalizeRejectsInvalidFlows(t *testing.T) {
+	t.Parallel()
+
+	step := Step{Slot: "title", Prompt: "What went wrong?"}
+	cases := map[string]Config{
+		"bad name":       {Flows: []Flow{{Name: "bug report", Steps: []Step{step}}}},
+		"no steps":       {Flows: []Flow{{Name: "bug"}}},
+		"duplicate flow": {Flows: []Flow{{Name: "bug", Steps: []Step{step}}, {Name: "BUG", Steps: []Step{step}}}},
+		"duplicate slot": {Flows: []Flow{{Name: "bug", Steps: []Step{step, step}}}},
+		"empty prompt":   {Flows: []Flow{{Name: "bug", Steps: []Step{{Slot: "title"}}}}},
+		"unknown kind":   {Flows: []Flow{{Name: "bug", Steps: []Step{{Slot: "title", Prompt: "?", Kind: "date"}}}}},
+		"one choice":     {Flows: []Flow{{Name: "bug", Steps: []Step{{Slot: "title", Prompt: "?", Kind: KindChoice, Choices: []string{"a"}}}}}},
+		"bad pattern":    {Flows: []Flow{{Name: "bug", Steps: []Step{{Slot: "title", Prompt: "?", Pattern: "("}}}}},
+	}
+	for name, cfg := range cases {
+		if _, err := Normalize(cfg); !errors.Is(err, ErrInvalidFlow) {
+			t.Fatalf("%s: err = %v, want ErrInvalidFlow", name, err)
+		}
+	}
+}
+
+func TestValidateAnswer(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		step   Step
+		text   string
+		value  string
+		reason string
+	}{
+		{name: "empty", step: Step{Kind: KindText}, text: "  ", reason: InvalidRequired},
+		{name: "skip optional", step: Step{Kind: KindNumber, Optional: true}, text: "Skip"},
+		{name: "skip required", step: Step{Kind: KindText}, text: "skip", value: "skip"},
+		{name: "number", step: Step{Kind: KindNumber}, text: "1,200.5", value: "1200.5"},
+		{name: "not a number", step: Step{Kind: KindNumber}, text: "many", reason: InvalidNumber},
+		{name: "email", step: Step{Kind: KindEmail}, text: "dev@example.com", value: "dev@example.com"},
+		{name: "named email", step: Step{Kind: KindEmail}, text: "Dev <dev@example.com>", reason: InvalidEmail},
+		{name: "url", step: Step{Kind: KindURL}, text: "https://example.com/issue", value: "https://example.com/issue"},
+		{name: "not http", step: Step{Kind: KindURL}, text: "ftp://example.com", reason: InvalidURL},
+		{name: "choice by name", step: Step{Kind: KindChoice, Choices: []string{"Low", "High"}}, text: "high", value: "High"},
+		{name: "choice by number", step: Step{Kind: KindChoice, Choices: []string{"Low", "High"}}, text: "1", value: "Low"},
+		{name: "unknown choice", step: Step{Kind: KindChoice, Choices: []string{"Low", "High"}}, text: "medium", reason: InvalidChoice},
+		{name: "yes", step: Step{Kind: KindYesNo}, text: "Y", value: "yes"},
+		{name: "maybe", step: Step{Kind: KindYesNo}, text: "maybe", reason: InvalidYesNo},
+		{name: "pattern", step: Step{Kind: KindText, Pattern: `^v\d+`}, text: "1.2", reason: InvalidPattern},
+		{name: "too long", step: Step{Kind: KindText, MaxLength: 3}, text: "abcd", reason: InvalidTooLong},
+	}
+	for _, tc := range cases {
+		value, reason := validateAnswer(tc.step, tc.text)
+		if value != tc.value || reason != tc.reason {
+			t.Fatalf("%s: validateAnswer = (%q, %q), want (%q, %q)", tc.name, value, reason, tc.value, tc.reason)
+		}
+	}
+}
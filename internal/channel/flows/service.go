@@ -0,0 +1,397 @@
+// Package flows runs conversation flows: guided question sequences a bot
+// walks a contact through for structured tasks such as collecting a bug
+// report. Each answer is validated into a named slot; once every step is
+// answered the filled-in form is handed to the conversation. Flow
+// definitions are stored per bot, and the progress of a running flow is
+// stored on the conversation route so it survives restarts and can be
+// paused for free-form chat and resumed later.
+package flows
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel/route"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+// runMetadataKey is the route metadata entry holding the running flow.
+const runMetadataKey = "flow_run"
+
+// idleTimeout pauses a flow whose contact went quiet, so a message sent
+// after a long break reaches the conversation instead of answering a
+// question the contact may have forgotten about.
+const idleTimeout = time.Hour
+
+// Status notices, localized by the caller under "flows.".
+const (
+	NoticeList       = "list"
+	NoticeNone       = "none"
+	NoticeUnknown    = "unknown"
+	NoticeStarted    = "started"
+	NoticeBusy       = "busy"
+	NoticeCancelled  = "cancelled"
+	NoticePaused     = "paused"
+	NoticeIdle       = "idle"
+	NoticeResumed    = "resumed"
+	NoticeNotRunning = "notRunning"
+	NoticeUsage      = "usage"
+)
+
+type flowQueries interface {
+	GetBotConversationFlows(ctx context.Context, botID pgtype.UUID) (sqlc.BotConversationFlow, error)
+	UpsertBotConversationFlows(ctx context.Context, arg sqlc.UpsertBotConversationFlowsParams) (sqlc.BotConversationFlow, error)
+}
+
+// RouteStore reads and writes the route metadata that holds run progress.
+// route.DBService implements it.
+type RouteStore interface {
+	GetByID(ctx context.Context, routeID string) (route.Route, error)
+	UpdateMetadata(ctx context.Context, routeID string, metadata map[string]any) error
+}
+
+// Run is the progress of a flow on one route.
+type Run struct {
+	Flow      string            `json:"flow"`
+	Step      int               `json:"step"`
+	Slots     map[string]string `json:"slots"`
+	Paused    bool              `json:"paused,omitempty"`
+	StartedAt time.Time         `json:"started_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Question is the next step to ask. The caller renders the choices and the
+// skip hint in the contact's language.
+type Question struct {
+	Prompt   string
+	Number   int
+	Total    int
+	Kind     string
+	Choices  []string
+	Optional bool
+}
+
+// Result is the filled-in form of a completed flow.
+type Result struct {
+	Flow  Flow
+	Slots map[string]string
+}
+
+// Text renders the form as the message handed to the conversation.
+func (r Result) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Completed form %q (%s):", r.Flow.DisplayTitle(), r.Flow.Name)
+	for _, step := range r.Flow.Steps {
+		value := strings.TrimSpace(r.Slots[step.Slot])
+		if value == "" {
+			value = "(skipped)"
+		}
+		fmt.Fprintf(&b, "\n- %s: %s", step.Slot, value)
+	}
+	return b.String()
+}
+
+// Reply is what the caller should send back.
+type Reply struct {
+	// Notice is one of the Notice* keys; Title is the flow it refers to.
+	Notice string
+	Title  string
+	// Available lists the bot's flows for NoticeList.
+	Available []Flow
+	// Invalid names why the answer was rejected, one of the Invalid* keys.
+	Invalid string
+	Ask     *Question
+	// Result is set when the flow just completed; Completion is the flow's
+	// own closing message, empty for the localized default.
+	Result     *Result
+	Completion string
+	// Handled reports that the inbound text was consumed by the flow and
+	// must not reach the conversation.
+	Handled bool
+}
+
+// Service stores flow definitions and drives runs.
+type Service struct {
+	queries dbstore.Queries
+	routes  RouteStore
+	logger  *slog.Logger
+}
+
+// NewService creates a conversation flow service.
+func NewService(log *slog.Logger, queries dbstore.Queries, routes RouteStore) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		routes:  routes,
+		logger:  log.With(slog.String("service", "conversation_flows")),
+	}
+}
+
+func (s *Service) store() (flowQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("conversation flow service not configured")
+	}
+	store, ok := s.queries.(flowQueries)
+	if !ok {
+		return nil, errors.New("conversation flow queries not supported by store")
+	}
+	return store, nil
+}
+
+// Get returns the bot's flows.
+func (s *Service) Get(ctx context.Context, botID string) (Config, error) {
+	store, err := s.store()
+	if err != nil {
+		return Config{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid bot id: %w", err)
+	}
+	row, err := store.GetBotConversationFlows(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Config{Flows: []Flow{}}, nil
+		}
+		return Config{}, fmt.Errorf("get conversation flows: %w", err)
+	}
+	cfg := Config{Flows: []Flow{}}
+	if len(row.Config) > 0 {
+		if err := json.Unmarshal(row.Config, &cfg); err != nil {
+			return Config{}, fmt.Errorf("decode conversation flows: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// Replace validates cfg and stores it for the bot. Running flows whose
+// definition disappears are dropped on their next message.
+func (s *Service) Replace(ctx context.Context, botID string, cfg Config) (Config, error) {
+	store, err := s.store()
+	if err != nil {
+		return Config{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid bot id: %w", err)
+	}
+	normalized, err := Normalize(cfg)
+	if err != nil {
+		return Config{}, err
+	}
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return Config{}, fmt.Errorf("encode conversation flows: %w", err)
+	}
+	if _, err := store.UpsertBotConversationFlows(ctx, sqlc.UpsertBotConversationFlowsParams{
+		BotID:  pgBotID,
+		Config: raw,
+	}); err != nil {
+		return Config{}, fmt.Errorf("store conversation flows: %w", err)
+	}
+	return normalized, nil
+}
+
+// Command runs a /flow action on a route: list, start <name>, pause,
+// resume or cancel.
+func (s *Service) Command(ctx context.Context, botID, routeID, action string, args []string, now time.Time) (Reply, error) {
+	cfg, err := s.Get(ctx, botID)
+	if err != nil {
+		return Reply{}, err
+	}
+	metadata, run, running, err := s.loadRun(ctx, routeID)
+	if err != nil {
+		return Reply{}, err
+	}
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "", "list":
+		if len(cfg.Flows) == 0 {
+			return Reply{Notice: NoticeNone}, nil
+		}
+		return Reply{Notice: NoticeList, Available: cfg.Flows}, nil
+	case "start":
+		if len(args) == 0 {
+			return Reply{Notice: NoticeUsage}, nil
+		}
+		flow, ok := cfg.Find(args[0])
+		if !ok {
+			return Reply{Notice: NoticeUnknown, Title: strings.TrimSpace(args[0])}, nil
+		}
+		if running {
+			if current, ok := cfg.Find(run.Flow); ok && run.Step < len(current.Steps) {
+				if current.Name != flow.Name {
+					return Reply{Notice: NoticeBusy, Title: current.DisplayTitle()}, nil
+				}
+				return s.resume(ctx, routeID, metadata, run, current, now)
+			}
+		}
+		run = Run{Flow: flow.Name, Slots: map[string]string{}, StartedAt: now, UpdatedAt: now}
+		if err := s.saveRun(ctx, routeID, metadata, &run); err != nil {
+			return Reply{}, err
+		}
+		return Reply{Notice: NoticeStarted, Title: flow.DisplayTitle(), Ask: question(flow, 0)}, nil
+	case "pause", "resume", "cancel", "stop":
+	default:
+		return Reply{Notice: NoticeUsage}, nil
+	}
+
+	flow, ok := cfg.Find(run.Flow)
+	if !running || !ok || run.Step >= len(flow.Steps) {
+		if running {
+			if err := s.saveRun(ctx, routeID, metadata, nil); err != nil {
+				return Reply{}, err
+			}
+		}
+		return Reply{Notice: NoticeNotRunning}, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case "pause":
+		run.Paused = true
+		run.UpdatedAt = now
+		if err := s.saveRun(ctx, routeID, metadata, &run); err != nil {
+			return Reply{}, err
+		}
+		return Reply{Notice: NoticePaused, Title: flow.DisplayTitle()}, nil
+	case "resume":
+		return s.resume(ctx, routeID, metadata, run, flow, now)
+	default:
+		if err := s.saveRun(ctx, routeID, metadata, nil); err != nil {
+			return Reply{}, err
+		}
+		return Reply{Notice: NoticeCancelled, Title: flow.DisplayTitle()}, nil
+	}
+}
+
+// Answer takes text as the answer to the running flow's current question.
+// It does nothing for routes without a running flow or with a paused one.
+// A flow idle for longer than idleTimeout is paused instead, and the text
+// goes on to the conversation.
+func (s *Service) Answer(ctx context.Context, botID, routeID, text string, now time.Time) (Reply, error) {
+	metadata, run, running, err := s.loadRun(ctx, routeID)
+	if err != nil || !running || run.Paused {
+		return Reply{}, err
+	}
+	cfg, err := s.Get(ctx, botID)
+	if err != nil {
+		return Reply{}, err
+	}
+	flow, ok := cfg.Find(run.Flow)
+	if !ok || run.Step >= len(flow.Steps) {
+		s.logger.Info("dropping run of removed or changed flow",
+			slog.String("route_id", routeID), slog.String("flow", run.Flow))
+		return Reply{}, s.saveRun(ctx, routeID, metadata, nil)
+	}
+	if now.Sub(run.UpdatedAt) > idleTimeout {
+		run.Paused = true
+		run.UpdatedAt = now
+		if err := s.saveRun(ctx, routeID, metadata, &run); err != nil {
+			return Reply{}, err
+		}
+		return Reply{Notice: NoticeIdle, Title: flow.DisplayTitle()}, nil
+	}
+
+	step := flow.Steps[run.Step]
+	value, reason := validateAnswer(step, text)
+	if reason != "" {
+		return Reply{Invalid: reason, Ask: question(flow, run.Step), Handled: true}, nil
+	}
+	if run.Slots == nil {
+		run.Slots = map[string]string{}
+	}
+	run.Slots[step.Slot] = value
+	run.Step++
+	run.UpdatedAt = now
+	if run.Step == len(flow.Steps) {
+		if err := s.saveRun(ctx, routeID, metadata, nil); err != nil {
+			return Reply{}, err
+		}
+		return Reply{
+			Title:      flow.DisplayTitle(),
+			Result:     &Result{Flow: flow, Slots: run.Slots},
+			Completion: flow.Completion,
+		}, nil
+	}
+	if err := s.saveRun(ctx, routeID, metadata, &run); err != nil {
+		return Reply{}, err
+	}
+	return Reply{Ask: question(flow, run.Step), Handled: true}, nil
+}
+
+func (s *Service) resume(ctx context.Context, routeID string, metadata map[string]any, run Run, flow Flow, now time.Time) (Reply, error) {
+	run.Paused = false
+	run.UpdatedAt = now
+	if err := s.saveRun(ctx, routeID, metadata, &run); err != nil {
+		return Reply{}, err
+	}
+	return Reply{Notice: NoticeResumed, Title: flow.DisplayTitle(), Ask: question(flow, run.Step)}, nil
+}
+
+// loadRun returns the route metadata and the run stored in it.
+func (s *Service) loadRun(ctx context.Context, routeID string) (map[string]any, Run, bool, error) {
+	if s.routes == nil {
+		return nil, Run{}, false, errors.New("conversation flow route store not configured")
+	}
+	rt, err := s.routes.GetByID(ctx, routeID)
+	if err != nil {
+		return nil, Run{}, false, fmt.Errorf("get route: %w", err)
+	}
+	metadata := rt.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	raw, ok := metadata[runMetadataKey]
+	if !ok || raw == nil {
+		return metadata, Run{}, false, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, Run{}, false, fmt.Errorf("encode flow run: %w", err)
+	}
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil || strings.TrimSpace(run.Flow) == "" {
+		// A damaged entry must not wedge the conversation; treat it as absent.
+		return metadata, Run{}, false, nil
+	}
+	return metadata, run, true, nil
+}
+
+// saveRun stores run in the route metadata, or removes it when run is nil.
+func (s *Service) saveRun(ctx context.Context, routeID string, metadata map[string]any, run *Run) error {
+	updated := make(map[string]any, len(metadata)+1)
+	for k, v := range metadata {
+		updated[k] = v
+	}
+	if run == nil {
+		delete(updated, runMetadataKey)
+	} else {
+		updated[runMetadataKey] = run
+	}
+	if err := s.routes.UpdateMetadata(ctx, routeID, updated); err != nil {
+		return fmt.Errorf("store flow run: %w", err)
+	}
+	return nil
+}
+
+func question(flow Flow, index int) *Question {
+	step := flow.Steps[index]
+	return &Question{
+		Prompt:   step.Prompt,
+		Number:   index + 1,
+		Total:    len(flow.Steps),
+		Kind:     step.Kind,
+		Choices:  append([]string(nil), step.Choices...),
+		Optional: step.Optional,
+	}
+}
@@ -0,0 +1,146 @@
+package flows
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel/route"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	testBot   = "11111111-1111-1111-1111-111111111111"
+	testRoute = "22222222-2222-2222-2222-222222222222"
+)
+
+type fakeFlowQueries struct {
+	dbstore.Queries
+
+	config []byte
+}
+
+func (f *fakeFlowQueries) GetBotConversationFlows(context.Context, pgtype.UUID) (sqlc.BotConversationFlow, error) {
+	if f.config == nil {
+		return sqlc.BotConversationFlow{}, pgx.ErrNoRows
+	}
+	return sqlc.BotConversationFlow{Config: f.config}, nil
+}
+
+func (f *fakeFlowQueries) UpsertBotConversationFlows(_ context.Context, arg sqlc.UpsertBotConversationFlowsParams) (sqlc.BotConversationFlow, error) {
+	f.config = arg.Config
+	return sqlc.BotConversationFlow{BotID: arg.BotID, Config: arg.Config}, nil
+}
+
+type fakeRoutes struct {
+	metadata map[string]any
+}
+
+func (f *fakeRoutes) GetByID(_ context.Context, routeID string) (route.Route, error) {
+	return route.Route{ID: routeID, Metadata: f.metadata}, nil
+}
+
+func (f *fakeRoutes) UpdateMetadata(_ context.Context, _ string, metadata map[string]any) error {
+	f.metadata = metadata
+	return nil
+}
+
+func newTestService(t *testing.T) (*Service, *fakeRoutes) {
+	t.Helper()
+	routes := &fakeRoutes{metadata: map[string]any{"conversation_name": "dm"}}
+	svc := NewService(nil, &fakeFlowQueries{}, routes)
+	if _, err := svc.Replace(context.Background(), testBot, Config{Flows: []Flow{{
+		Name:       "bug_report",
+		Title:      "Bug report",
+		Completion: "Thanks, we're on it.",
+		Steps: []Step{
+			{Slot: "summary", Prompt: "What went wrong?"},
+			{Slot: "severity", Prompt: "How bad is it?", Kind: KindChoice, Choices: []string{"low", "high"}},
+			{Slot: "logs", Prompt: "Link to logs?", Kind: KindURL, Optional: true},
+		},
+	}}}); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	return svc, routes
+}
+
+func TestFlowCollectsSlotsAndHandsOffResult(t *testing.T) {
+	t.Parallel()
+
+	svc, routes := newTestService(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	reply, err := svc.Command(ctx, testBot, testRoute, "start", []string{"bug_report"}, now)
+	if err != nil || reply.Notice != NoticeStarted || reply.Ask == nil || reply.Ask.Number != 1 || reply.Ask.Total != 3 {
+		t.Fatalf("start = %+v, %v", reply, err)
+	}
+	if reply, _ := svc.Answer(ctx, testBot, testRoute, "The app crashes on login", now); !reply.Handled || reply.Ask.Number != 2 {
+		t.Fatalf("first answer = %+v", reply)
+	}
+	if reply, _ := svc.Answer(ctx, testBot, testRoute, "urgent", now); reply.Invalid != InvalidChoice || !reply.Handled || reply.Ask.Number != 2 {
+		t.Fatalf("invalid answer = %+v", reply)
+	}
+	if reply, _ := svc.Answer(ctx, testBot, testRoute, "2", now); reply.Ask == nil || !reply.Ask.Optional {
+		t.Fatalf("second answer = %+v", reply)
+	}
+	reply, err = svc.Answer(ctx, testBot, testRoute, "skip", now)
+	if err != nil || reply.Handled || reply.Result == nil || reply.Completion != "Thanks, we're on it." {
+		t.Fatalf("completion = %+v, %v", reply, err)
+	}
+	if got := reply.Result.Slots; got["summary"] != "The app crashes on login" || got["severity"] != "high" || got["logs"] != "" {
+		t.Fatalf("slots = %+v", got)
+	}
+	if text := reply.Result.Text(); !strings.Contains(text, "- severity: high") || !strings.Contains(text, "- logs: (skipped)") {
+		t.Fatalf("result text = %q", text)
+	}
+	if _, ok := routes.metadata[runMetadataKey]; ok || routes.metadata["conversation_name"] != "dm" {
+		t.Fatalf("route metadata after completion = %+v", routes.metadata)
+	}
+	if reply, _ := svc.Answer(ctx, testBot, testRoute, "hello again", now); reply.Handled || reply.Notice != "" {
+		t.Fatalf("answer without a run = %+v", reply)
+	}
+}
+
+func TestFlowPausesAndResumes(t *testing.T) {
+	t.Parallel()
+
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	if _, err := svc.Command(ctx, testBot, testRoute, "start", []string{"bug_report"}, now); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	if reply, _ := svc.Command(ctx, testBot, testRoute, "pause", nil, now); reply.Notice != NoticePaused {
+		t.Fatalf("pause = %+v", reply)
+	}
+	if reply, _ := svc.Answer(ctx, testBot, testRoute, "what's the weather?", now); reply.Handled {
+		t.Fatalf("paused flow consumed free-form text: %+v", reply)
+	}
+	if reply, _ := svc.Command(ctx, testBot, testRoute, "resume", nil, now); reply.Notice != NoticeResumed || reply.Ask.Number != 1 {
+		t.Fatalf("resume = %+v", reply)
+	}
+	if reply, _ := svc.Answer(ctx, testBot, testRoute, "Crash on login", now); !reply.Handled {
+		t.Fatalf("answer after resume = %+v", reply)
+	}
+
+	later := now.Add(2 * idleTimeout)
+	if reply, _ := svc.Answer(ctx, testBot, testRoute, "back from lunch", later); reply.Notice != NoticeIdle || reply.Handled {
+		t.Fatalf("idle answer = %+v", reply)
+	}
+	if reply, _ := svc.Command(ctx, testBot, testRoute, "start", []string{"bug_report"}, later); reply.Notice != NoticeResumed || reply.Ask.Number != 2 {
+		t.Fatalf("restart of idle flow = %+v", reply)
+	}
+	if reply, _ := svc.Command(ctx, testBot, testRoute, "cancel", nil, later); reply.Notice != NoticeCancelled {
+		t.Fatalf("cancel = %+v", reply)
+	}
+	if reply, _ := svc.Command(ctx, testBot, testRoute, "resume", nil, later); reply.Notice != NoticeNotRunning {
+		t.Fatalf("resume after cancel = %+v", reply)
+	}
+}
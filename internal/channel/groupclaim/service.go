@@ -0,0 +1,110 @@
+// Package groupclaim coordinates several bots sharing one group chat. Each
+// coordinated bot claims a triggering message before answering; the claim is
+// a row keyed on the platform message ID, so exactly one bot wins it.
+package groupclaim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	// claimRetention is how long claims are kept. Platforms redeliver within
+	// minutes, so a day comfortably covers retries.
+	claimRetention = 24 * time.Hour
+	pruneInterval  = time.Hour
+)
+
+type claimQueries interface {
+	ClaimGroupReply(ctx context.Context, arg sqlc.ClaimGroupReplyParams) (pgtype.UUID, error)
+	DeleteGroupReplyClaimsBefore(ctx context.Context, before pgtype.Timestamptz) (int64, error)
+}
+
+// Service claims group replies on behalf of bots.
+type Service struct {
+	queries dbstore.Queries
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewService creates a group reply claim service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "group_claim")),
+		now:     time.Now,
+	}
+}
+
+// Claim reports whether botID owns the reply to messageID in the given
+// conversation. The first bot to claim wins; repeated claims by the winner
+// succeed again so redelivered messages are still answered by it.
+func (s *Service) Claim(ctx context.Context, botID, channelType, conversationID, messageID string) (bool, error) {
+	store, err := s.store()
+	if err != nil {
+		return false, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return false, fmt.Errorf("invalid bot id: %w", err)
+	}
+	messageID = strings.TrimSpace(messageID)
+	if messageID == "" {
+		return false, errors.New("message id is required")
+	}
+	s.maybePrune(ctx, store)
+	owner, err := store.ClaimGroupReply(ctx, sqlc.ClaimGroupReplyParams{
+		ChannelType:    strings.TrimSpace(channelType),
+		ConversationID: strings.TrimSpace(conversationID),
+		MessageID:      messageID,
+		BotID:          pgBotID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("claim group reply: %w", err)
+	}
+	return owner == pgBotID, nil
+}
+
+func (s *Service) maybePrune(ctx context.Context, store claimQueries) {
+	now := s.now()
+	s.mu.Lock()
+	if now.Sub(s.lastPruned) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPruned = now
+	s.mu.Unlock()
+
+	before := pgtype.Timestamptz{Time: now.Add(-claimRetention).UTC(), Valid: true}
+	if _, err := store.DeleteGroupReplyClaimsBefore(ctx, before); err != nil {
+		s.logger.Warn("prune group reply claims failed", slog.Any("error", err))
+	}
+}
+
+func (s *Service) store() (claimQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("group claim service not configured")
+	}
+	store, ok := s.queries.(claimQueries)
+	if !ok {
+		return nil, errors.New("group claim queries not supported by store")
+	}
+	return store, nil
+}
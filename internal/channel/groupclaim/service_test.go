@@ -0,0 +1,79 @@
+package groupclaim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	botA = "11111111-1111-1111-1111-111111111111"
+	botB = "22222222-2222-2222-2222-222222222222"
+)
+
+type fakeClaimQueries struct {
+	dbstore.Queries
+
+	owners map[string]pgtype.UUID
+	pruned int
+}
+
+func (f *fakeClaimQueries) ClaimGroupReply(_ context.Context, arg sqlc.ClaimGroupReplyParams) (pgtype.UUID, error) {
+	key := arg.ChannelType + "|" + arg.ConversationID + "|" + arg.MessageID
+	if owner, ok := f.owners[key]; ok {
+		return owner, nil
+	}
+	f.owners[key] = arg.BotID
+	return arg.BotID, nil
+}
+
+func (f *fakeClaimQueries) DeleteGroupReplyClaimsBefore(context.Context, pgtype.Timestamptz) (int64, error) {
+	f.pruned++
+	return 0, nil
+}
+
+func TestClaimFirstBotWins(t *testing.T) {
+	queries := &fakeClaimQueries{owners: map[string]pgtype.UUID{}}
+	svc := NewService(nil, queries)
+	ctx := context.Background()
+
+	won, err := svc.Claim(ctx, botA, "telegram", "-100", "42")
+	if err != nil || !won {
+		t.Fatalf("first claim = %v, %v; want win", won, err)
+	}
+	if won, _ := svc.Claim(ctx, botB, "telegram", "-100", "42"); won {
+		t.Fatal("second bot won an already claimed message")
+	}
+	if won, _ := svc.Claim(ctx, botA, "telegram", "-100", "42"); !won {
+		t.Fatal("redelivered message lost by the original winner")
+	}
+	if won, _ := svc.Claim(ctx, botB, "telegram", "-100", "43"); !won {
+		t.Fatal("next message should be claimable")
+	}
+	if _, err := svc.Claim(ctx, botA, "telegram", "-100", " "); err == nil {
+		t.Fatal("expected error for empty message id")
+	}
+}
+
+func TestClaimPrunesAtMostHourly(t *testing.T) {
+	queries := &fakeClaimQueries{owners: map[string]pgtype.UUID{}}
+	svc := NewService(nil, queries)
+	now := time.Unix(1700000000, 0)
+	svc.now = func() time.Time { return now }
+
+	_, _ = svc.Claim(context.Background(), botA, "feishu", "oc_1", "om_1")
+	_, _ = svc.Claim(context.Background(), botA, "feishu", "oc_1", "om_2")
+	if queries.pruned != 1 {
+		t.Fatalf("pruned %d times, want 1", queries.pruned)
+	}
+	now = now.Add(pruneInterval)
+	_, _ = svc.Claim(context.Background(), botA, "feishu", "oc_1", "om_3")
+	if queries.pruned != 2 {
+		t.Fatalf("pruned %d times, want 2", queries.pruned)
+	}
+}
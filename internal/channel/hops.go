@@ -0,0 +1,37 @@
+package channel
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MetadataKeyHopCount is the inbound metadata key carrying how many
+	// consecutive bot-to-bot deliveries produced the message. Messages from
+	// people carry no hop count.
+	MetadataKeyHopCount = "hop_count"
+
+	// DefaultMaxHops bounds a bot-to-bot exchange so two bots replying to each
+	// other cannot loop forever.
+	DefaultMaxHops = 8
+)
+
+// HopCount returns the message's bot-to-bot hop count, or 0 when absent.
+func HopCount(msg InboundMessage) int {
+	switch v := msg.Metadata[MetadataKeyHopCount].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		if v > math.MaxInt32 {
+			return math.MaxInt32
+		}
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	}
+	return 0
+}
@@ -4,10 +4,19 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"strings"
+
+	"github.com/memohai/memoh/internal/extension"
+)
+
+const (
+	defaultInboundQueueSize             = 256
+	defaultInboundConversationQueueSize = 64
+	defaultInboundWorkers               = 4
 )
 
 // ErrInboundQueueFull indicates the synchronous inbound queue admission failed
-// because all worker slots are saturated.
+// because the queue, or the message's conversation lane, is full.
 var ErrInboundQueueFull = errors.New("inbound queue full")
 
 // IsInboundQueueFull reports whether err means the inbound queue rejected a
@@ -16,12 +25,19 @@ func IsInboundQueueFull(err error) bool {
 	return errors.Is(err, ErrInboundQueueFull)
 }
 
+// InboundFailureRecorder persists inbound messages whose processing failed
+// after the adapter acknowledged the platform event, so they can be replayed.
+type InboundFailureRecorder interface {
+	RecordInboundFailure(ctx context.Context, cfg ChannelConfig, msg InboundMessage, cause error) error
+}
+
 type inboundTask struct {
 	cfg ChannelConfig
 	msg InboundMessage
 }
 
-// HandleInbound enqueues an inbound message for asynchronous processing by the worker pool.
+// HandleInbound enqueues an inbound message for asynchronous processing by the
+// worker pool. Messages of one conversation are processed in arrival order.
 func (m *Manager) HandleInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage) error {
 	if m.processor == nil {
 		return errors.New("inbound processor not configured")
@@ -30,30 +46,124 @@ func (m *Manager) HandleInbound(ctx context.Context, cfg ChannelConfig, msg Inbo
 	if m.inboundCtx != nil && m.inboundCtx.Err() != nil {
 		return errors.New("inbound dispatcher stopped")
 	}
-	task := inboundTask{
-		cfg: cfg,
-		msg: msg,
+	if m.inboundBuffer != nil && m.inboundBuffer.Full(cfg.ID) {
+		return ErrInboundBackpressure
 	}
-	select {
-	case m.inboundQueue <- task:
-		return nil
-	default:
-		return ErrInboundQueueFull
+	key := inboundOrderKey(cfg, msg)
+	if err := m.inboundQueue.push(key, inboundTask{cfg: cfg, msg: msg}); err != nil {
+		if m.logger != nil {
+			stats := m.inboundQueue.stats()
+			m.logger.Warn("inbound queue full",
+				slog.String("channel", msg.Channel.String()),
+				slog.String("conversation_id", msg.Conversation.ID),
+				slog.Int("queued", stats.Queued),
+				slog.Int("capacity", stats.Capacity),
+			)
+		}
+		return err
 	}
+	return nil
+}
+
+// InboundQueueStats reports the inbound worker pool's queue depth and
+// counters.
+func (m *Manager) InboundQueueStats() InboundQueueStats {
+	stats := m.inboundQueue.stats()
+	stats.Workers = m.inboundWorkers
+	return stats
 }
 
 func (m *Manager) handleInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage) error {
+	if err := m.processInbound(ctx, cfg, msg); err != nil {
+		if m.logger != nil {
+			m.logger.Error("inbound processing failed", slog.String("channel", msg.Channel.String()), slog.Any("error", err))
+		}
+		// Outage errors are spooled by the inbound buffer and replayed later.
+		if m.inboundBuffer == nil || !m.inboundBuffer.IsOutage(err) {
+			m.recordInboundFailure(ctx, cfg, msg, err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *Manager) processInbound(ctx context.Context, cfg ChannelConfig, msg InboundMessage) error {
 	if m.processor == nil {
 		return errors.New("inbound processor not configured")
 	}
-	sender := m.newReplySender(cfg, msg.Channel)
-	if err := m.processor.HandleInbound(ctx, cfg, msg, sender); err != nil {
+	msg, ok := m.applyInboundExtensions(ctx, cfg, msg)
+	if !ok {
+		return nil
+	}
+	sender := m.newReplySender(cfg, msg.Channel, msg.Message.ID)
+	return m.processor.HandleInbound(ctx, cfg, msg, sender)
+}
+
+// applyInboundExtensions lets extensions rewrite the message text or drop the
+// message. Rewritten text replaces the rich parts.
+func (m *Manager) applyInboundExtensions(ctx context.Context, cfg ChannelConfig, msg InboundMessage) (InboundMessage, bool) {
+	if !m.extensions.Handles(extension.HookInbound) || MembershipEvent(msg) != "" {
+		return msg, true
+	}
+	botID := strings.TrimSpace(msg.BotID)
+	if botID == "" {
+		botID = cfg.BotID
+	}
+	text := msg.Message.PlainText()
+	event := &extension.InboundMessage{
+		BotID:            botID,
+		Channel:          msg.Channel.String(),
+		ConversationID:   msg.Conversation.ID,
+		ConversationType: msg.Conversation.Type,
+		SenderID:         msg.Sender.SubjectID,
+		MessageID:        msg.Message.ID,
+		Text:             text,
+	}
+	if err := m.extensions.OnInbound(ctx, event); err != nil {
 		if m.logger != nil {
-			m.logger.Error("inbound processing failed", slog.String("channel", msg.Channel.String()), slog.Any("error", err))
+			m.logger.Info("inbound dropped by extension",
+				slog.String("channel", msg.Channel.String()),
+				slog.String("bot_id", botID),
+				slog.String("conversation_id", msg.Conversation.ID),
+				slog.Any("reason", err),
+			)
 		}
+		return msg, false
+	}
+	if event.Text != text {
+		msg.Message.Text = event.Text
+		msg.Message.Parts = nil
+	}
+	return msg, true
+}
+
+// recordInboundFailure hands a failed message to the failure recorder. The
+// platform event was already acknowledged, so this is the only copy left.
+func (m *Manager) recordInboundFailure(ctx context.Context, cfg ChannelConfig, msg InboundMessage, cause error) {
+	if m.failureRecorder == nil {
+		return
+	}
+	if err := m.failureRecorder.RecordInboundFailure(context.WithoutCancel(ctx), cfg, msg, cause); err != nil && m.logger != nil {
+		m.logger.Error("record inbound failure failed",
+			slog.String("bot_id", cfg.BotID),
+			slog.String("channel", msg.Channel.String()),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// ReplayInbound re-drives a previously failed message through the processor
+// using the bot's current channel config. It runs synchronously and does not
+// record a new failure; the caller owns the failure record.
+func (m *Manager) ReplayInbound(ctx context.Context, botID string, channelType ChannelType, msg InboundMessage) error {
+	if m.service == nil {
+		return errors.New("channel config store not configured")
+	}
+	cfg, err := m.service.ResolveEffectiveConfig(ctx, botID, channelType)
+	if err != nil {
 		return err
 	}
-	return nil
+	return m.processInbound(ctx, cfg, msg)
 }
 
 func (m *Manager) startInboundWorkers(ctx context.Context) {
@@ -72,12 +182,23 @@ func (m *Manager) runInboundWorker(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case task := <-m.inboundQueue:
-			if err := m.handleInbound(ctx, task.cfg, task.msg); err != nil {
+		case key := <-m.inboundQueue.ready:
+			task, ok := m.inboundQueue.take(key)
+			if !ok {
+				continue
+			}
+			var err error
+			if m.inboundBuffer != nil {
+				err = m.inboundBuffer.Handle(ctx, task.cfg, task.msg, m.handleInbound)
+			} else {
+				err = m.handleInbound(ctx, task.cfg, task.msg)
+			}
+			if err != nil {
 				if m.logger != nil {
 					m.logger.Error("inbound processing failed", slog.String("channel", task.msg.Channel.String()), slog.Any("error", err))
 				}
 			}
+			m.inboundQueue.done(key)
 		}
 	}
 }
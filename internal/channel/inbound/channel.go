@@ -18,6 +18,9 @@ import (
 	"time"
 	"unicode"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/memohai/memoh/internal/acl"
 	acpfeedback "github.com/memohai/memoh/internal/agent/decision/feedback"
 	userinput "github.com/memohai/memoh/internal/agent/decision/input"
@@ -27,19 +30,29 @@ import (
 	"github.com/memohai/memoh/internal/bots"
 	"github.com/memohai/memoh/internal/channel"
 	"github.com/memohai/memoh/internal/channel/discuss"
+	"github.com/memohai/memoh/internal/channel/flows"
+	"github.com/memohai/memoh/internal/channel/mentions"
+	"github.com/memohai/memoh/internal/channel/onboarding"
+	"github.com/memohai/memoh/internal/channel/postprocess"
 	"github.com/memohai/memoh/internal/channel/route"
+	"github.com/memohai/memoh/internal/channel/unfurl"
+	"github.com/memohai/memoh/internal/channel/workhours"
 	messagepkg "github.com/memohai/memoh/internal/chat/message"
 	sessionpkg "github.com/memohai/memoh/internal/chat/thread"
 	"github.com/memohai/memoh/internal/chat/timeline"
 	"github.com/memohai/memoh/internal/command"
 	"github.com/memohai/memoh/internal/i18n"
 	"github.com/memohai/memoh/internal/media"
+	"github.com/memohai/memoh/internal/sharedstate"
 	skillset "github.com/memohai/memoh/internal/skills"
 	"github.com/memohai/memoh/internal/slash"
+	"github.com/memohai/memoh/internal/telemetry"
 )
 
 var base64Std = base64.StdEncoding
 
+var tracer = telemetry.Tracer("channel/inbound")
+
 const (
 	silentReplyToken        = "NO_REPLY"
 	minDuplicateTextLength  = 10
@@ -53,6 +66,13 @@ type RouteResolver interface {
 	ResolveConversation(ctx context.Context, input route.ResolveInput) (route.ResolveConversationResult, error)
 }
 
+// RouteLifecycle tracks whether the bot is still a member of routed
+// conversations.
+type RouteLifecycle interface {
+	MarkConversationInactive(ctx context.Context, botID, platform, externalConversationID, reason string) (int64, error)
+	ReactivateConversation(ctx context.Context, botID, platform, externalConversationID string) (int64, error)
+}
+
 type channelReactor interface {
 	React(ctx context.Context, botID string, channelType channel.ChannelType, req channel.ReactRequest) error
 }
@@ -66,6 +86,13 @@ type mediaIngestor interface {
 	channel.ContainerAttachmentIngester
 }
 
+// extractionCache reuses text extracted from an attachment by content hash.
+// The media service implements it; other ingestors skip caching.
+type extractionCache interface {
+	CachedExtraction(botID, contentHash, extractor string) (string, bool)
+	StoreExtraction(botID, contentHash, extractor, text string)
+}
+
 // speechSynthesizer synthesizes text to speech audio.
 type speechSynthesizer interface {
 	Synthesize(ctx context.Context, modelID string, text string, overrideCfg map[string]any) ([]byte, string, error)
@@ -141,6 +168,63 @@ type BotPermissionChecker interface {
 	HasBotPermission(ctx context.Context, botID, accountID, permission string) (bool, error)
 }
 
+// GroupReplyClaimer decides which of several coordinated bots in a group
+// answers a triggering message.
+type GroupReplyClaimer interface {
+	Claim(ctx context.Context, botID, channelType, conversationID, messageID string) (bool, error)
+}
+
+// Onboarder runs the welcome and profile collection flow for contacts
+// messaging a bot in a private conversation.
+type Onboarder interface {
+	Greet(ctx context.Context, cfg onboarding.Config, contact onboarding.Contact, text string) (onboarding.Reply, error)
+	Bound(ctx context.Context, cfg onboarding.Config, contact onboarding.Contact) (onboarding.Reply, error)
+	ObservePlatformTimezone(ctx context.Context, botID, channelIdentityID, timezone string) error
+}
+
+// WorkingHours decides whether a bot is available and queues messages
+// received outside its working hours.
+type WorkingHours interface {
+	Check(ctx context.Context, botID, conversationKey string, now time.Time) (workhours.Decision, error)
+	Defer(ctx context.Context, botID string, channelType channel.ChannelType, msg channel.InboundMessage, deliverAt time.Time) error
+}
+
+// Maintenance reports whether the deployment is in maintenance mode and the
+// operator's notice; an empty notice means the localized default.
+type Maintenance interface {
+	Active(ctx context.Context) (string, bool)
+}
+
+// InboundRateLimitReader returns a bot's per-sender inbound rate limit in
+// messages per minute and its burst; zero per minute means unlimited.
+type InboundRateLimitReader interface {
+	InboundRateLimit(ctx context.Context, botID string) (perMinute, burst int, err error)
+}
+
+// WakeWordSettings is a bot's wake word configuration.
+type WakeWordSettings struct {
+	Enabled  bool
+	Words    []string
+	Cooldown time.Duration
+}
+
+// WakeWordReader returns a bot's wake word configuration.
+type WakeWordReader interface {
+	WakeWords(ctx context.Context, botID string) (WakeWordSettings, error)
+}
+
+// ConversationFlows runs guided question flows stored on a route.
+type ConversationFlows interface {
+	Command(ctx context.Context, botID, routeID, action string, args []string, now time.Time) (flows.Reply, error)
+	Answer(ctx context.Context, botID, routeID, text string, now time.Time) (flows.Reply, error)
+}
+
+// OutputPipelineReader returns a bot's output post-processing pipeline, or
+// nil when the bot has none.
+type OutputPipelineReader interface {
+	Pipeline(ctx context.Context, botID string) (*postprocess.Pipeline, error)
+}
+
 type RequestedSkillResolver interface {
 	ResolveTextRequestedSkills(ctx context.Context, botID string, names []string) ([]skillset.ResolvedSkill, error)
 }
@@ -181,6 +265,7 @@ type NewSessionSpec struct {
 type ChannelInboundProcessor struct {
 	turnSvc             turn.Service
 	routeResolver       RouteResolver
+	routeLifecycle      RouteLifecycle
 	message             messagepkg.Writer
 	mediaService        mediaIngestor
 	reactor             channelReactor
@@ -192,6 +277,7 @@ type ChannelInboundProcessor struct {
 	identity            *IdentityResolver
 	policy              PolicyService
 	dispatcher          *RouteDispatcher
+	turnScheduler       *TurnScheduler
 	acl                 chatACL
 	observer            channel.StreamObserver
 	speechService       speechSynthesizer
@@ -208,6 +294,19 @@ type ChannelInboundProcessor struct {
 	acpProfiles         turn.ACPProfileResolver
 	permissionChecker   BotPermissionChecker
 	skillResolver       RequestedSkillResolver
+	maxHops             int
+	groupClaimer        GroupReplyClaimer
+	onboarder           Onboarder
+	workingHours        WorkingHours
+	maintenance         Maintenance
+	rateLimits          InboundRateLimitReader
+	senderLimits        *senderLimiter
+	wakeWords           WakeWordReader
+	wakeWordCooldowns   *wakeWordCooldowns
+	conversationFlows   ConversationFlows
+	outputPipelines     OutputPipelineReader
+	linkPreviewer       unfurl.Previewer
+	mentionResolver     *mentions.Resolver
 
 	// activeStreams maps "botID:routeID" to a context.CancelFunc for the
 	// currently running agent stream. Used by /stop to abort generation
@@ -244,6 +343,7 @@ func NewChannelInboundProcessor(
 		tokenTTL:      tokenTTL,
 		identity:      identityResolver,
 		policy:        policyService,
+		maxHops:       channel.DefaultMaxHops,
 	}
 }
 
@@ -262,6 +362,15 @@ func (p *ChannelInboundProcessor) IdentityMiddleware() channel.Middleware {
 	return p.identity.Middleware()
 }
 
+// SetRouteLifecycle configures how routes are deactivated when the bot leaves
+// a conversation.
+func (p *ChannelInboundProcessor) SetRouteLifecycle(lifecycle RouteLifecycle) {
+	if p == nil {
+		return
+	}
+	p.routeLifecycle = lifecycle
+}
+
 // SetMediaService configures media ingestion support for inbound attachments.
 func (p *ChannelInboundProcessor) SetMediaService(mediaService mediaIngestor) {
 	if p == nil {
@@ -349,6 +458,133 @@ func (p *ChannelInboundProcessor) SetDispatcher(dispatcher *RouteDispatcher) {
 	p.dispatcher = dispatcher
 }
 
+// SetTurnScheduler configures per-bot admission of agent turns. Without a
+// scheduler every triggering message starts its turn immediately.
+func (p *ChannelInboundProcessor) SetTurnScheduler(scheduler *TurnScheduler) {
+	if p == nil {
+		return
+	}
+	p.turnScheduler = scheduler
+}
+
+// SetMaxHops bounds bot-to-bot exchanges: inbound messages whose hop count
+// exceeds n are dropped. n <= 0 disables the limit.
+func (p *ChannelInboundProcessor) SetMaxHops(n int) {
+	if p == nil {
+		return
+	}
+	p.maxHops = n
+}
+
+// SetGroupReplyClaimer enables claim-based turn taking for channel configs
+// that opt in with the "group_coordination" routing flag.
+func (p *ChannelInboundProcessor) SetGroupReplyClaimer(claimer GroupReplyClaimer) {
+	if p == nil {
+		return
+	}
+	p.groupClaimer = claimer
+}
+
+// SetOnboarder enables the onboarding flow for channel configs that
+// configure it under the "onboarding" routing entry.
+func (p *ChannelInboundProcessor) SetOnboarder(onboarder Onboarder) {
+	if p == nil {
+		return
+	}
+	p.onboarder = onboarder
+}
+
+// SetWorkingHours enables away replies and deferred delivery for private
+// messages received outside a bot's working hours.
+func (p *ChannelInboundProcessor) SetWorkingHours(hours WorkingHours) {
+	if p == nil {
+		return
+	}
+	p.workingHours = hours
+}
+
+// SetMaintenance makes bots answer with the maintenance notice instead of
+// running the agent while maintenance mode is on.
+func (p *ChannelInboundProcessor) SetMaintenance(maintenance Maintenance) {
+	if p == nil {
+		return
+	}
+	p.maintenance = maintenance
+}
+
+// SetInboundRateLimits throttles each sender's directed messages to the
+// bot's configured rate, so one chat user cannot exhaust the bot's model
+// quota.
+func (p *ChannelInboundProcessor) SetInboundRateLimits(reader InboundRateLimitReader) {
+	if p == nil {
+		return
+	}
+	p.rateLimits = reader
+	if p.senderLimits == nil {
+		p.senderLimits = newSenderLimiter()
+	}
+}
+
+// SetSharedState keeps per-sender rate limit buckets in store so the limit
+// holds across replicas. A nil store keeps them in process memory.
+func (p *ChannelInboundProcessor) SetSharedState(store sharedstate.Store) {
+	if p == nil {
+		return
+	}
+	if p.senderLimits == nil {
+		p.senderLimits = newSenderLimiter()
+	}
+	p.senderLimits.shared = store
+}
+
+// SetWakeWords lets bots be called in group chats by their configured wake
+// words as well as by @mention.
+func (p *ChannelInboundProcessor) SetWakeWords(reader WakeWordReader) {
+	if p == nil {
+		return
+	}
+	p.wakeWords = reader
+	if p.wakeWordCooldowns == nil {
+		p.wakeWordCooldowns = newWakeWordCooldowns()
+	}
+}
+
+// SetConversationFlows enables the /flow command and guided flows in
+// private conversations.
+func (p *ChannelInboundProcessor) SetConversationFlows(conversationFlows ConversationFlows) {
+	if p == nil {
+		return
+	}
+	p.conversationFlows = conversationFlows
+}
+
+// SetOutputPipelines enables per-bot post-processing of replies delivered
+// to IM channels.
+func (p *ChannelInboundProcessor) SetOutputPipelines(reader OutputPipelineReader) {
+	if p == nil {
+		return
+	}
+	p.outputPipelines = reader
+}
+
+// SetLinkPreviewer enables link preview cards on channels whose config asks
+// for them and whose adapter renders link_preview parts.
+func (p *ChannelInboundProcessor) SetLinkPreviewer(previewer unfurl.Previewer) {
+	if p == nil {
+		return
+	}
+	p.linkPreviewer = previewer
+}
+
+// SetMentionResolver enables turning "@Name" in IM replies into platform
+// mentions.
+func (p *ChannelInboundProcessor) SetMentionResolver(resolver *mentions.Resolver) {
+	if p == nil {
+		return
+	}
+	p.mentionResolver = resolver
+}
+
 // SetIMDisplayOptions configures the reader used to gate IM-facing stream
 // events (e.g. tool call lifecycle) on bot-level display preferences. When
 // nil, tool call events are always dropped before reaching IM adapters.
@@ -413,6 +649,30 @@ func (p *ChannelInboundProcessor) shouldShowToolCallsInIM(ctx context.Context, b
 	return show
 }
 
+// outputPipeline returns the bot's post-processing pipeline for channelType.
+// Lookup failures are logged and leave replies unprocessed.
+func (p *ChannelInboundProcessor) outputPipeline(ctx context.Context, botID string, channelType channel.ChannelType) *postprocess.Pipeline {
+	if p == nil || p.outputPipelines == nil {
+		return nil
+	}
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return nil
+	}
+	pipeline, err := p.outputPipelines.Pipeline(ctx, botID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn(
+				"output processors lookup failed, delivering replies unprocessed",
+				slog.String("bot_id", botID),
+				slog.Any("error", err),
+			)
+		}
+		return nil
+	}
+	return pipeline.ForPlatform(channelType)
+}
+
 // HandleInbound processes an inbound channel message through identity resolution and chat gateway.
 func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, sender channel.StreamReplySender) (retErr error) {
 	if p.turnSvc == nil {
@@ -421,6 +681,16 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 	if sender == nil {
 		return errors.New("reply sender not configured")
 	}
+	ctx, span := tracer.Start(ctx, "channel.inbound", trace.WithAttributes(
+		attribute.String("memoh.channel", msg.Channel.String()),
+		attribute.String("memoh.bot_id", strings.TrimSpace(cfg.BotID)),
+		attribute.String("memoh.conversation_type", strings.TrimSpace(msg.Conversation.Type)),
+		attribute.String("memoh.message_id", strings.TrimSpace(msg.Message.ID)),
+	))
+	defer func() { telemetry.End(span, retErr) }()
+	if event := channel.MembershipEvent(msg); event != "" {
+		return p.handleMembershipEvent(ctx, cfg, msg, event)
+	}
 	text := strings.TrimSpace(msg.Message.PlainText())
 	if p.logger != nil {
 		p.logger.Debug("inbound handle start",
@@ -438,6 +708,18 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		}
 		return nil
 	}
+	if hops := channel.HopCount(msg); p.maxHops > 0 && hops > p.maxHops {
+		if p.logger != nil {
+			p.logger.Warn("inbound dropped: bot hop limit reached",
+				slog.String("channel", msg.Channel.String()),
+				slog.String("bot_id", strings.TrimSpace(msg.BotID)),
+				slog.String("conversation_id", strings.TrimSpace(msg.Conversation.ID)),
+				slog.Int("hop_count", hops),
+				slog.Int("max_hops", p.maxHops),
+			)
+		}
+		return nil
+	}
 	if err := channel.RejectReservedSkillMetadata(msg.Message); err != nil {
 		return p.sendSlashError(ctx, sender, msg, slash.CodeReservedSkillMetadata)
 	}
@@ -465,6 +747,7 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 	}
 
 	identity := state.Identity
+	msg = p.markWakeWord(ctx, msg, identity)
 
 	// Intercept slash commands before they reach the LLM.
 	// Use raw_text (without prepended quote/forward context) so that
@@ -483,6 +766,7 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 	isToolApprovalCommand := invocationHasResource(invocation, "approve", "reject")
 	isUserInputResponseCommand := invocationHasResource(invocation, "respond")
 	isModeCommand := invocationHasResource(invocation, "now", "next", "btw")
+	isFlowCommand := invocationHasResource(invocation, "flow")
 	var pendingSkillIntent *slash.SkillIntent
 	switch slashDecision.Kind {
 	case slash.DecisionRejectNoop:
@@ -547,7 +831,7 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 
 	// Skip generic command handler for mode-prefix commands (/btw, /now, /next)
 	// so they pass through to mode detection below.
-	if pendingSkillIntent == nil && slashDecision.Kind == slash.DecisionCommandAction && p.commandHandler != nil && !isModeCommand && !isToolApprovalCommand && !isUserInputResponseCommand && invocation != nil && (isDirectedAtBot(msg) || slashDirected) {
+	if pendingSkillIntent == nil && slashDecision.Kind == slash.DecisionCommandAction && p.commandHandler != nil && !isModeCommand && !isToolApprovalCommand && !isUserInputResponseCommand && !isFlowCommand && invocation != nil && (isDirectedAtBot(msg) || slashDirected) {
 		loc := p.localizer(ctx, identity.BotID)
 		result, err := p.commandHandler.ExecuteResult(ctx, command.ExecuteInput{
 			BotID:             strings.TrimSpace(identity.BotID),
@@ -584,10 +868,16 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		} else if mid := strings.TrimSpace(msg.Message.ID); mid != "" {
 			outMsg.Reply = &channel.ReplyRef{MessageID: mid}
 		}
-		return sender.Send(ctx, channel.OutboundMessage{
+		if sendErr := sender.Send(ctx, channel.OutboundMessage{
 			Target:  strings.TrimSpace(msg.ReplyTarget),
 			Message: outMsg,
-		})
+		}); sendErr != nil {
+			return sendErr
+		}
+		if err == nil && invocationHasResource(invocation, "link") {
+			p.welcomeBoundContact(ctx, cfg, msg, sender, identity)
+		}
+		return nil
 	}
 
 	resolvedAttachments := p.ingestInboundAttachments(ctx, cfg, msg, strings.TrimSpace(identity.BotID), msg.Message.Attachments)
@@ -631,6 +921,21 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 	if err != nil {
 		return fmt.Errorf("resolve route conversation: %w", err)
 	}
+	if resolved.Inactive {
+		// The bot was removed from this conversation. Undirected traffic that
+		// still arrives is not persisted; a message addressed to the bot shows
+		// it is back, so the route is reactivated.
+		if !isDirectedAtBot(msg) && !slashDirected {
+			if p.logger != nil {
+				p.logger.Debug("inbound dropped: route inactive",
+					slog.String("channel", msg.Channel.String()),
+					slog.String("route_id", strings.TrimSpace(resolved.RouteID)),
+				)
+			}
+			return nil
+		}
+		p.reactivateConversation(ctx, identity.BotID, msg)
+	}
 
 	// Resolve the active session for this route. Creation happens only after
 	// ACL and command gates so default ACP validation never fires for passive
@@ -713,6 +1018,36 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		return nil
 	}
 
+	p.observeSenderTimezone(ctx, msg, identity)
+	if p.holdForMaintenance(ctx, msg, sender, identity, isDirectedAtBot(msg) || slashDirected) {
+		return nil
+	}
+	if (isDirectedAtBot(msg) || slashDirected) && p.throttleSender(ctx, msg, sender, identity) {
+		return nil
+	}
+	if invocation == nil && pendingSkillIntent == nil && p.runOnboarding(ctx, cfg, msg, sender, identity, text) {
+		return nil
+	}
+	if invocation == nil && pendingSkillIntent == nil && p.holdOutsideWorkingHours(ctx, cfg, msg, sender, identity) {
+		return nil
+	}
+	if isFlowCommand && invocation != nil && (isDirectedAtBot(msg) || slashDirected) {
+		return p.handleFlowCommand(ctx, msg, sender, identity, resolved.RouteID, *invocation)
+	}
+	if invocation == nil && pendingSkillIntent == nil {
+		handled, handoff := p.runConversationFlow(ctx, msg, sender, identity, resolved.RouteID, text)
+		if handled {
+			return nil
+		}
+		if handoff != "" {
+			text = handoff
+			msg.Message.Text = handoff
+			if msg.Metadata == nil {
+				msg.Metadata = make(map[string]any)
+			}
+			msg.Metadata["raw_text"] = handoff
+		}
+	}
 	if isToolApprovalCommand && invocation != nil && (isDirectedAtBot(msg) || slashDirected) {
 		return p.handleToolApprovalCommand(ctx, msg, sender, identity, resolved.RouteID, sessionID, *invocation)
 	}
@@ -937,6 +1272,7 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 				slog.String("route_id", strings.TrimSpace(resolved.RouteID)),
 				slog.Bool("is_mentioned", metadataBool(msg.Metadata, "is_mentioned")),
 				slog.Bool("is_reply_to_bot", metadataBool(msg.Metadata, "is_reply_to_bot")),
+				slog.Bool("is_wake_word", metadataBool(msg.Metadata, "is_wake_word")),
 				slog.String("conversation_type", strings.TrimSpace(msg.Conversation.Type)),
 				slog.String("query", strings.TrimSpace(text)),
 				slog.Int("attachments", len(attachments)),
@@ -945,6 +1281,11 @@ func (p *ChannelInboundProcessor) HandleInbound(ctx context.Context, cfg channel
 		return nil
 	}
 
+	if !p.claimGroupReply(ctx, cfg, msg, identity) {
+		p.persistPassiveMessage(ctx, identity, msg, text, attachments, resolved.RouteID, sessionID, eventID)
+		return nil
+	}
+
 	routeID := strings.TrimSpace(resolved.RouteID)
 
 	// --- Dispatcher-based mode handling (inject / queue) ---
@@ -1118,6 +1459,15 @@ startStream:
 	if !isLocalChannelType(msg.Channel) && !p.shouldShowToolCallsInIM(ctx, identity.BotID) {
 		stream = channel.NewToolCallDroppingStream(stream)
 	}
+	if !isLocalChannelType(msg.Channel) {
+		stream = mentions.NewStream(stream, p.mentionResolver, cfg, msg.Conversation)
+	}
+	if !isLocalChannelType(msg.Channel) && channel.LinkPreviewMode(cfg) == channel.LinkPreviewsCards && p.channelCaps(msg.Channel).LinkPreviews {
+		stream = unfurl.NewStream(stream, p.linkPreviewer)
+	}
+	if !isLocalChannelType(msg.Channel) {
+		stream = postprocess.NewStream(stream, p.outputPipeline(ctx, identity.BotID, msg.Channel))
+	}
 
 	// For non-local channels, wrap the stream so events are mirrored to the
 	// RouteHub (and thus to Web UI and other local subscribers).
@@ -1156,6 +1506,22 @@ startStream:
 		}()
 	}
 
+	// Wait for a turn slot of the bot. The release is deferred after the
+	// queue drain so it runs first: queued tasks re-enter HandleInbound and
+	// must not wait on the slot still held by this turn.
+	if p.turnScheduler != nil {
+		release, acquireErr := p.turnScheduler.Acquire(ctx, identity.BotID, turnSenderKey(identity, routeID), p.turnPriority(ctx, identity))
+		if acquireErr != nil {
+			if statusNotifier != nil {
+				if notifyErr := p.notifyProcessingFailed(ctx, statusNotifier, cfg, msg, statusInfo, statusHandle, acquireErr); notifyErr != nil {
+					p.logProcessingStatusError("processing_failed", msg, identity, notifyErr)
+				}
+			}
+			return acquireErr
+		}
+		defer release()
+	}
+
 	cmd := turn.StartTurnCommand{
 		SchemaVersion:             1,
 		TeamID:                    cfg.TeamID,
@@ -1532,6 +1898,31 @@ func (p *ChannelInboundProcessor) accessDeniedRole(ctx context.Context, identity
 	return role
 }
 
+// turnPriority maps the sender to a TurnScheduler class: the owner and
+// manage-granted identities first, then senders linked to an account, then
+// everyone else. A failed role lookup only costs the sender the owner class.
+func (p *ChannelInboundProcessor) turnPriority(ctx context.Context, identity InboundIdentity) TurnPriority {
+	if p.commandHandler != nil {
+		role, err := p.commandHandler.MemberRole(ctx, identity.BotID, identity.ChannelIdentityID)
+		if err == nil && (role == "owner" || role == "manager") {
+			return PriorityOwner
+		}
+	}
+	if strings.TrimSpace(identity.UserID) != "" {
+		return PriorityMember
+	}
+	return PriorityGuest
+}
+
+// turnSenderKey identifies the sender for round-robin admission, falling
+// back to the route when the channel identity is unknown.
+func turnSenderKey(identity InboundIdentity, routeID string) string {
+	if key := strings.TrimSpace(identity.ChannelIdentityID); key != "" {
+		return key
+	}
+	return "route:" + strings.TrimSpace(routeID)
+}
+
 // drainQueue marks the route as done and processes any queued tasks.
 func (p *ChannelInboundProcessor) drainQueue(ctx context.Context, routeID string) {
 	if p.dispatcher == nil {
@@ -1584,9 +1975,47 @@ func shouldTriggerAssistantResponse(msg channel.InboundMessage) bool {
 	if metadataBool(msg.Metadata, "is_reply_to_bot") {
 		return true
 	}
+	if metadataBool(msg.Metadata, "is_wake_word") {
+		return true
+	}
 	return false
 }
 
+// claimGroupReply reports whether this bot should answer a triggering group
+// message. Bots whose channel config enables group_coordination race for a
+// per-message claim; only the winner replies. Direct conversations, configs
+// without the flag and claim failures all fall through to replying, so a
+// broken claim store never silences a bot.
+func (p *ChannelInboundProcessor) claimGroupReply(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, identity InboundIdentity) bool {
+	if p.groupClaimer == nil || isDirectConversationType(msg.Conversation.Type) || !metadataBool(cfg.Routing, "group_coordination") {
+		return true
+	}
+	messageID := strings.TrimSpace(msg.Message.ID)
+	if messageID == "" {
+		return true
+	}
+	botID := strings.TrimSpace(identity.BotID)
+	claimed, err := p.groupClaimer.Claim(ctx, botID, msg.Channel.String(), strings.TrimSpace(msg.Conversation.ID), messageID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("group reply claim failed, replying anyway",
+				slog.String("bot_id", botID),
+				slog.String("message_id", messageID),
+				slog.Any("error", err),
+			)
+		}
+		return true
+	}
+	if !claimed && p.logger != nil {
+		p.logger.Info("inbound not triggering assistant (group reply claimed by another bot)",
+			slog.String("channel", msg.Channel.String()),
+			slog.String("bot_id", botID),
+			slog.String("message_id", messageID),
+		)
+	}
+	return claimed
+}
+
 // isDirectedAtBot reports whether the message is explicitly directed at this bot,
 // either because it's a direct conversation, the bot is @mentioned, or it's a reply
 // to this bot's message.
@@ -1594,7 +2023,8 @@ func isDirectedAtBot(msg channel.InboundMessage) bool {
 	if isDirectConversationType(msg.Conversation.Type) {
 		return true
 	}
-	return metadataBool(msg.Metadata, "is_mentioned") || metadataBool(msg.Metadata, "is_reply_to_bot")
+	return metadataBool(msg.Metadata, "is_mentioned") || metadataBool(msg.Metadata, "is_reply_to_bot") ||
+		metadataBool(msg.Metadata, "is_wake_word")
 }
 
 func (p *ChannelInboundProcessor) classifyChannelSlash(text string, msg channel.InboundMessage, identity InboundIdentity) slash.Decision {
@@ -1615,7 +2045,7 @@ func (p *ChannelInboundProcessor) classifyChannelSlash(text string, msg channel.
 
 func isChannelControlResource(resource string) bool {
 	switch strings.ToLower(strings.TrimSpace(resource)) {
-	case "start", "new", "stop", "status", "context", "approve", "reject", "respond":
+	case "start", "new", "stop", "status", "context", "approve", "reject", "respond", "flow":
 		return true
 	default:
 		return false
@@ -1962,6 +2392,58 @@ func metadataBool(metadata map[string]any, key string) bool {
 	}
 }
 
+// handleMembershipEvent applies a change of the bot's own membership in a
+// conversation to the conversation's routes.
+func (p *ChannelInboundProcessor) handleMembershipEvent(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage, event string) error {
+	botID := strings.TrimSpace(msg.BotID)
+	if botID == "" {
+		botID = strings.TrimSpace(cfg.BotID)
+	}
+	conversationID := strings.TrimSpace(msg.Conversation.ID)
+	if p.routeLifecycle == nil || botID == "" || conversationID == "" {
+		return nil
+	}
+	switch event {
+	case channel.MembershipEventBotRemoved:
+		n, err := p.routeLifecycle.MarkConversationInactive(ctx, botID, msg.Channel.String(), conversationID, route.InactiveReasonBotRemoved)
+		if err != nil {
+			return fmt.Errorf("mark routes inactive: %w", err)
+		}
+		if p.logger != nil {
+			p.logger.Info("bot removed from conversation; routes marked inactive",
+				slog.String("channel", msg.Channel.String()),
+				slog.String("bot_id", botID),
+				slog.String("conversation_id", conversationID),
+				slog.Int64("routes", n),
+			)
+		}
+	case channel.MembershipEventBotAdded:
+		p.reactivateConversation(ctx, botID, msg)
+	}
+	return nil
+}
+
+func (p *ChannelInboundProcessor) reactivateConversation(ctx context.Context, botID string, msg channel.InboundMessage) {
+	if p.routeLifecycle == nil {
+		return
+	}
+	n, err := p.routeLifecycle.ReactivateConversation(ctx, strings.TrimSpace(botID), msg.Channel.String(), strings.TrimSpace(msg.Conversation.ID))
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("reactivate routes failed", slog.Any("error", err))
+		}
+		return
+	}
+	if n > 0 && p.logger != nil {
+		p.logger.Info("bot back in conversation; routes reactivated",
+			slog.String("channel", msg.Channel.String()),
+			slog.String("bot_id", strings.TrimSpace(botID)),
+			slog.String("conversation_id", strings.TrimSpace(msg.Conversation.ID)),
+			slog.Int64("routes", n),
+		)
+	}
+}
+
 // persistPassiveMessage writes a user message directly into bot_history_messages
 // for group conversations where the bot was not @mentioned. This replaces the
 // old inbox system — the message is stored in the route's active session so it
@@ -2910,6 +3392,8 @@ func (p *ChannelInboundProcessor) transcribeInboundAttachments(ctx context.Conte
 	if err != nil || strings.TrimSpace(modelID) == "" {
 		return ""
 	}
+	cache, _ := p.mediaService.(extractionCache)
+	extractor := "transcription:" + strings.TrimSpace(modelID)
 	transcripts := make([]string, 0, len(attachments))
 	for _, att := range attachments {
 		if att.Type != channel.AttachmentAudio && att.Type != channel.AttachmentVoice {
@@ -2918,6 +3402,12 @@ func (p *ChannelInboundProcessor) transcribeInboundAttachments(ctx context.Conte
 		if strings.TrimSpace(att.ContentHash) == "" {
 			continue
 		}
+		if cache != nil {
+			if text, ok := cache.CachedExtraction(botID, att.ContentHash, extractor); ok {
+				transcripts = append(transcripts, text)
+				continue
+			}
+		}
 		reader, asset, err := p.mediaService.Open(ctx, botID, strings.TrimSpace(att.ContentHash))
 		if err != nil {
 			if p.logger != nil {
@@ -2952,6 +3442,9 @@ func (p *ChannelInboundProcessor) transcribeInboundAttachments(ctx context.Conte
 		if text == "" {
 			continue
 		}
+		if cache != nil {
+			cache.StoreExtraction(botID, att.ContentHash, extractor, text)
+		}
 		transcripts = append(transcripts, text)
 	}
 	if len(transcripts) == 0 {
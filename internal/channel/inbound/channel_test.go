@@ -6,12 +6,14 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -23,7 +25,9 @@ import (
 	"github.com/memohai/memoh/internal/channel"
 	"github.com/memohai/memoh/internal/channel/discuss"
 	"github.com/memohai/memoh/internal/channel/identities"
+	"github.com/memohai/memoh/internal/channel/onboarding"
 	"github.com/memohai/memoh/internal/channel/route"
+	"github.com/memohai/memoh/internal/channel/workhours"
 	messagepkg "github.com/memohai/memoh/internal/chat/message"
 	sessionpkg "github.com/memohai/memoh/internal/chat/thread"
 	"github.com/memohai/memoh/internal/chat/timeline"
@@ -549,6 +553,64 @@ func (*fakeMediaIngestor) AccessPath(_ context.Context, asset media.Asset) strin
 	return "/data/media/" + asset.StorageKey
 }
 
+type cachingMediaIngestor struct {
+	*fakeMediaIngestor
+	extractions map[string]string
+}
+
+func (f *cachingMediaIngestor) CachedExtraction(botID, contentHash, extractor string) (string, bool) {
+	text, ok := f.extractions[botID+"/"+contentHash+"/"+extractor]
+	return text, ok
+}
+
+func (f *cachingMediaIngestor) StoreExtraction(botID, contentHash, extractor, text string) {
+	f.extractions[botID+"/"+contentHash+"/"+extractor] = text
+}
+
+type fakeTranscriptionResult string
+
+func (r fakeTranscriptionResult) GetText() string { return string(r) }
+
+type fakeTranscriber struct {
+	modelID string
+	calls   int
+}
+
+func (f *fakeTranscriber) Transcribe(context.Context, string, []byte, string, string, map[string]any) (TranscriptionResult, error) {
+	f.calls++
+	return fakeTranscriptionResult("see you at noon"), nil
+}
+
+func (f *fakeTranscriber) ResolveTranscriptionModelID(context.Context, string) (string, error) {
+	return f.modelID, nil
+}
+
+func TestTranscribeInboundAttachmentsReusesCachedTranscript(t *testing.T) {
+	t.Parallel()
+
+	processor := NewChannelInboundProcessor(slog.Default(), nil, nil, nil, nil, nil, nil, "", 0)
+	processor.SetMediaService(&cachingMediaIngestor{fakeMediaIngestor: &fakeMediaIngestor{}, extractions: map[string]string{}})
+	transcriber := &fakeTranscriber{modelID: "stt-1"}
+	processor.SetTranscriptionService(transcriber, transcriber)
+	voice := []channel.Attachment{{Type: channel.AttachmentVoice, ContentHash: "hash-voice", Mime: "audio/ogg"}}
+
+	for range 2 {
+		if got := processor.transcribeInboundAttachments(context.Background(), "bot-1", voice); got != "see you at noon" {
+			t.Fatalf("transcript = %q", got)
+		}
+	}
+	if transcriber.calls != 1 {
+		t.Fatalf("transcribed %d times, want the repeat served from the cache", transcriber.calls)
+	}
+
+	// A different transcription model does not reuse the old transcript.
+	transcriber.modelID = "stt-2"
+	processor.transcribeInboundAttachments(context.Background(), "bot-1", voice)
+	if transcriber.calls != 2 {
+		t.Fatalf("transcribed %d times after switching models, want 2", transcriber.calls)
+	}
+}
+
 type fakeStorageProvider struct {
 	objects map[string][]byte
 }
@@ -689,6 +751,105 @@ func TestChannelInboundProcessorWithIdentity(t *testing.T) {
 	}
 }
 
+func TestChannelInboundProcessorDropsMessagesOverHopLimit(t *testing.T) {
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-1"}}
+	policySvc := &fakePolicyService{}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{BotID: "chat-1", RouteID: "route-1"}}
+	gateway := &fakeChatGateway{
+		resp: fakeChatResponse{
+			Messages: []turn.ModelMessage{
+				{Role: "assistant", Content: turn.NewTextContent("AI reply")},
+			},
+		},
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, policySvc, "", 0)
+	processor.SetMaxHops(2)
+	sender := &fakeReplySender{}
+
+	cfg := channel.ChannelConfig{TeamID: "team-test", ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("bot")}
+	msg := channel.InboundMessage{
+		BotID:        "bot-1",
+		Channel:      channel.ChannelType("bot"),
+		Message:      channel.Message{Text: "ping"},
+		ReplyTarget:  "bot-2",
+		Sender:       channel.Identity{SubjectID: "bot-2", DisplayName: "Other Bot"},
+		Conversation: channel.Conversation{ID: "bot-2", Type: channel.ConversationTypePrivate},
+		Metadata:     map[string]any{channel.MetadataKeyHopCount: 3},
+	}
+
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gateway.gotReq.Query != "" {
+		t.Fatalf("expected gateway not to be called, got query %q", gateway.gotReq.Query)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no reply, got: %+v", sender.sent)
+	}
+}
+
+type fakeGroupReplyClaimer struct {
+	claimed bool
+	calls   int
+}
+
+func (f *fakeGroupReplyClaimer) Claim(context.Context, string, string, string, string) (bool, error) {
+	f.calls++
+	return f.claimed, nil
+}
+
+func TestChannelInboundProcessorGroupReplyClaim(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		claimed   bool
+		routing   map[string]any
+		wantCalls int
+		wantReply bool
+	}{
+		{name: "lost claim stays silent", claimed: false, routing: map[string]any{"group_coordination": true}, wantCalls: 1, wantReply: false},
+		{name: "won claim replies", claimed: true, routing: map[string]any{"group_coordination": true}, wantCalls: 1, wantReply: true},
+		{name: "uncoordinated config skips claim", claimed: false, wantCalls: 0, wantReply: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-1"}}
+			policySvc := &fakePolicyService{}
+			chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{BotID: "chat-1", RouteID: "route-1"}}
+			gateway := &fakeChatGateway{
+				resp: fakeChatResponse{
+					Messages: []turn.ModelMessage{
+						{Role: "assistant", Content: turn.NewTextContent("AI reply")},
+					},
+				},
+			}
+			processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, policySvc, "", 0)
+			claimer := &fakeGroupReplyClaimer{claimed: tc.claimed}
+			processor.SetGroupReplyClaimer(claimer)
+			sender := &fakeReplySender{}
+
+			cfg := channel.ChannelConfig{TeamID: "team-test", ID: "cfg-1", BotID: "bot-1", ChannelType: channel.ChannelType("telegram"), Routing: tc.routing}
+			msg := channel.InboundMessage{
+				BotID:        "bot-1",
+				Channel:      channel.ChannelType("telegram"),
+				Message:      channel.Message{ID: "42", Text: "hello bots"},
+				ReplyTarget:  "-100",
+				Sender:       channel.Identity{SubjectID: "ext-1", DisplayName: "User1"},
+				Conversation: channel.Conversation{ID: "-100", Type: channel.ConversationTypeGroup},
+				Metadata:     map[string]any{"is_mentioned": true},
+			}
+
+			if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claimer.calls != tc.wantCalls {
+				t.Fatalf("claim calls = %d, want %d", claimer.calls, tc.wantCalls)
+			}
+			if got := len(sender.sent) > 0; got != tc.wantReply {
+				t.Fatalf("replied = %v, want %v (sent %+v)", got, tc.wantReply, sender.sent)
+			}
+		})
+	}
+}
+
 func TestTurnIdempotencyKeyScopesExternalMessageIDByRoute(t *testing.T) {
 	first := turnIdempotencyKey(channel.ChannelType("telegram"), "route-1", "42")
 	retry := turnIdempotencyKey(channel.ChannelType("telegram"), "route-1", "42")
@@ -935,6 +1096,65 @@ func TestChannelInboundProcessorPlainTextUserInputHandlesDirectedGroupMessage(t
 	}
 }
 
+type fakeWakeWords struct {
+	settings WakeWordSettings
+}
+
+func (f *fakeWakeWords) WakeWords(context.Context, string) (WakeWordSettings, error) {
+	return f.settings, nil
+}
+
+func TestChannelInboundProcessorWakeWordTriggersGroupReply(t *testing.T) {
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-1"}}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{BotID: "chat-1", RouteID: "route-1"}}
+	calls := 0
+	gateway := &fakeChatGateway{
+		resp: fakeChatResponse{
+			Messages: []turn.ModelMessage{
+				{Role: "assistant", Content: turn.NewTextContent("AI reply")},
+			},
+		},
+		onChat: func(turn.StartTurnCommand) { calls++ },
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, &fakePolicyService{}, "", 0)
+	processor.SetACLService(&fakeChatACL{allowed: true})
+	wakeWords := &fakeWakeWords{settings: WakeWordSettings{Enabled: true, Words: []string{"小梦"}, Cooldown: time.Minute}}
+	processor.SetWakeWords(wakeWords)
+	cfg := channel.ChannelConfig{TeamID: "team-test", BotID: "bot-1", ChannelType: channel.ChannelType("telegram")}
+	handle := func(id, text string) {
+		t.Helper()
+		msg := channel.InboundMessage{
+			BotID: "bot-1", Channel: channel.ChannelType("telegram"), ReplyTarget: "group-1",
+			Message:      channel.Message{ID: id, Text: text},
+			Sender:       channel.Identity{SubjectID: "ext-1", DisplayName: "Ada"},
+			Conversation: channel.Conversation{ID: "group-1", Type: channel.ConversationTypeGroup},
+		}
+		if err := processor.HandleInbound(context.Background(), cfg, msg, &fakeReplySender{}); err != nil {
+			t.Fatalf("HandleInbound() error = %v", err)
+		}
+	}
+
+	handle("msg-1", "lunch?")
+	if calls != 0 {
+		t.Fatalf("undirected group message triggered the bot")
+	}
+	handle("msg-2", "小梦，今天天气怎么样")
+	if calls != 1 {
+		t.Fatalf("wake word triggered %d chat calls, want 1", calls)
+	}
+	handle("msg-3", "小梦 再说一次")
+	if calls != 1 {
+		t.Fatalf("wake word within cooldown triggered the bot")
+	}
+
+	wakeWords.settings.Enabled = false
+	wakeWords.settings.Cooldown = 0
+	handle("msg-4", "小梦?")
+	if calls != 1 {
+		t.Fatalf("disabled wake words triggered the bot")
+	}
+}
+
 func TestChannelInboundProcessorRespondReplyUsesReplyTargetAndPreservesAnswer(t *testing.T) {
 	channelIdentitySvc := &fakeChannelIdentityService{
 		channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-1"},
@@ -2444,6 +2664,410 @@ func TestChannelInboundProcessorGroupMentionTriggersReply(t *testing.T) {
 	}
 }
 
+type fakeRouteLifecycle struct {
+	inactive    []string
+	reactivated []string
+}
+
+func (f *fakeRouteLifecycle) MarkConversationInactive(_ context.Context, botID, platform, conversationID, reason string) (int64, error) {
+	f.inactive = append(f.inactive, botID+"/"+platform+"/"+conversationID+"/"+reason)
+	return 1, nil
+}
+
+func (f *fakeRouteLifecycle) ReactivateConversation(_ context.Context, botID, platform, conversationID string) (int64, error) {
+	f.reactivated = append(f.reactivated, botID+"/"+platform+"/"+conversationID)
+	return 1, nil
+}
+
+func TestChannelInboundProcessorRouteLifecycle(t *testing.T) {
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-7"}}
+	policySvc := &fakePolicyService{}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{BotID: "chat-7", RouteID: "route-7", Inactive: true}}
+	gateway := &fakeChatGateway{
+		resp: fakeChatResponse{
+			Messages: []turn.ModelMessage{
+				{Role: "assistant", Content: turn.NewTextContent("AI reply")},
+			},
+		},
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, policySvc, "", 0)
+	lifecycle := &fakeRouteLifecycle{}
+	processor.SetRouteLifecycle(lifecycle)
+	sender := &fakeReplySender{}
+
+	cfg := channel.ChannelConfig{TeamID: "team-test", ID: "cfg-1", BotID: "bot-1"}
+	conv := channel.Conversation{ID: "oc_123", Type: "group"}
+
+	removed := channel.NewMembershipEvent(cfg, channel.ChannelType("feishu"), channel.MembershipEventBotRemoved, conv, channel.Identity{SubjectID: "user-1"})
+	if err := processor.HandleInbound(context.Background(), cfg, removed, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lifecycle.inactive) != 1 || lifecycle.inactive[0] != "bot-1/feishu/oc_123/bot_removed" {
+		t.Fatalf("expected routes marked inactive, got %v", lifecycle.inactive)
+	}
+	if len(chatSvc.persisted) != 0 {
+		t.Fatalf("membership event should not be persisted, got %d", len(chatSvc.persisted))
+	}
+
+	passive := channel.InboundMessage{
+		BotID:        "bot-1",
+		Channel:      channel.ChannelType("feishu"),
+		Message:      channel.Message{ID: "msg-1", Text: "hello everyone"},
+		ReplyTarget:  "chat_id:oc_123",
+		Sender:       channel.Identity{SubjectID: "user-1"},
+		Conversation: conv,
+	}
+	if err := processor.HandleInbound(context.Background(), cfg, passive, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chatSvc.persisted) != 0 {
+		t.Fatalf("undirected message on inactive route should be dropped, got %d persisted", len(chatSvc.persisted))
+	}
+	if len(lifecycle.reactivated) != 0 {
+		t.Fatalf("undirected message should not reactivate routes: %v", lifecycle.reactivated)
+	}
+
+	mention := passive
+	mention.Message = channel.Message{ID: "msg-2", Text: "@bot ping"}
+	mention.Metadata = map[string]any{"is_mentioned": true}
+	if err := processor.HandleInbound(context.Background(), cfg, mention, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lifecycle.reactivated) != 1 || lifecycle.reactivated[0] != "bot-1/feishu/oc_123" {
+		t.Fatalf("expected mention to reactivate routes, got %v", lifecycle.reactivated)
+	}
+	if gateway.gotReq.Query == "" {
+		t.Fatalf("mention on reactivated route should trigger chat call")
+	}
+}
+
+type fakeOnboarder struct {
+	replies   []onboarding.Reply
+	texts     []string
+	timezones []string
+}
+
+func (f *fakeOnboarder) Greet(_ context.Context, _ onboarding.Config, _ onboarding.Contact, text string) (onboarding.Reply, error) {
+	f.texts = append(f.texts, text)
+	if len(f.replies) == 0 {
+		return onboarding.Reply{}, nil
+	}
+	reply := f.replies[0]
+	f.replies = f.replies[1:]
+	return reply, nil
+}
+
+func (*fakeOnboarder) Bound(context.Context, onboarding.Config, onboarding.Contact) (onboarding.Reply, error) {
+	return onboarding.Reply{}, nil
+}
+
+func (f *fakeOnboarder) ObservePlatformTimezone(_ context.Context, _, _, timezone string) error {
+	f.timezones = append(f.timezones, timezone)
+	return nil
+}
+
+func TestChannelInboundProcessorOnboarding(t *testing.T) {
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-onboard"}}
+	policySvc := &fakePolicyService{}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{BotID: "chat-onboard", RouteID: "route-onboard"}}
+	gateway := &fakeChatGateway{
+		resp: fakeChatResponse{
+			Messages: []turn.ModelMessage{
+				{Role: "assistant", Content: turn.NewTextContent("AI reply")},
+			},
+		},
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, policySvc, "", 0)
+	onboarder := &fakeOnboarder{replies: []onboarding.Reply{
+		{Messages: []string{"Welcome!"}, Ask: onboarding.FieldTimezone, Handled: true},
+	}}
+	processor.SetOnboarder(onboarder)
+	sender := &fakeReplySender{}
+
+	cfg := channel.ChannelConfig{
+		TeamID:  "team-test",
+		ID:      "cfg-onboard",
+		BotID:   "bot-1",
+		Routing: map[string]any{onboarding.RoutingKey: map[string]any{"enabled": true}},
+	}
+	msg := channel.InboundMessage{
+		BotID:        "bot-1",
+		Channel:      channel.ChannelType("telegram"),
+		Message:      channel.Message{ID: "msg-1", Text: "hello"},
+		ReplyTarget:  "chat-1",
+		Sender:       channel.Identity{SubjectID: "user-1", DisplayName: "Ada"},
+		Conversation: channel.Conversation{ID: "chat-1", Type: channel.ConversationTypePrivate},
+	}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 2 || sender.sent[0].Message.PlainText() != "Welcome!" || sender.sent[1].Message.PlainText() == "" {
+		t.Fatalf("expected welcome and timezone prompt, got %+v", sender.sent)
+	}
+	if gateway.gotReq.Query != "" {
+		t.Fatalf("consumed onboarding message should not reach the chat gateway")
+	}
+
+	// Once onboarding has nothing to say the message flows to the chat.
+	msg.Message = channel.Message{ID: "msg-2", Text: "what can you do?"}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gateway.gotReq.Query == "" {
+		t.Fatalf("expected chat call after onboarding")
+	}
+	if len(onboarder.texts) != 2 || onboarder.texts[1] != "what can you do?" {
+		t.Fatalf("onboarder saw %q", onboarder.texts)
+	}
+
+	// Group traffic never enters onboarding.
+	group := msg
+	group.Message = channel.Message{ID: "msg-3", Text: "@bot hi"}
+	group.Conversation = channel.Conversation{ID: "group-1", Type: channel.ConversationTypeGroup}
+	group.Metadata = map[string]any{"is_mentioned": true}
+	group.Sender.Attributes = map[string]string{"timezone": "Europe/Berlin"}
+	if err := processor.HandleInbound(context.Background(), cfg, group, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(onboarder.texts) != 2 {
+		t.Fatalf("group message reached onboarding: %q", onboarder.texts)
+	}
+	// The platform timezone is recorded in any conversation.
+	if len(onboarder.timezones) != 1 || onboarder.timezones[0] != "Europe/Berlin" {
+		t.Fatalf("recorded timezones = %q", onboarder.timezones)
+	}
+}
+
+type fakeWorkingHours struct {
+	decision workhours.Decision
+	deferred []channel.InboundMessage
+}
+
+func (f *fakeWorkingHours) Check(context.Context, string, string, time.Time) (workhours.Decision, error) {
+	return f.decision, nil
+}
+
+func (f *fakeWorkingHours) Defer(_ context.Context, _ string, _ channel.ChannelType, msg channel.InboundMessage, _ time.Time) error {
+	f.deferred = append(f.deferred, msg)
+	return nil
+}
+
+func TestChannelInboundProcessorWorkingHours(t *testing.T) {
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-hours"}}
+	policySvc := &fakePolicyService{}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{BotID: "chat-hours", RouteID: "route-hours"}}
+	gateway := &fakeChatGateway{
+		resp: fakeChatResponse{
+			Messages: []turn.ModelMessage{
+				{Role: "assistant", Content: turn.NewTextContent("AI reply")},
+			},
+		},
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, policySvc, "", 0)
+	hours := &fakeWorkingHours{decision: workhours.Decision{
+		Notify:      true,
+		Defer:       true,
+		AwayMessage: "Back {opens_at}.",
+		OpensAt:     time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC),
+		Location:    time.UTC,
+	}}
+	processor.SetWorkingHours(hours)
+	sender := &fakeReplySender{}
+
+	cfg := channel.ChannelConfig{TeamID: "team-test", ID: "cfg-hours", BotID: "bot-1", ChannelType: channel.ChannelType("telegram")}
+	msg := channel.InboundMessage{
+		BotID:        "bot-1",
+		Channel:      channel.ChannelType("telegram"),
+		Message:      channel.Message{ID: "msg-1", Text: "are you there?"},
+		ReplyTarget:  "chat-1",
+		Sender:       channel.Identity{SubjectID: "user-1", DisplayName: "Ada"},
+		Conversation: channel.Conversation{ID: "chat-1", Type: channel.ConversationTypePrivate},
+	}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Message.PlainText() != "Back Thu Oct 15 09:00 UTC." {
+		t.Fatalf("expected away reply, got %+v", sender.sent)
+	}
+	if len(hours.deferred) != 1 || hours.deferred[0].Message.ID != "msg-1" {
+		t.Fatalf("deferred = %+v", hours.deferred)
+	}
+	if gateway.gotReq.Query != "" {
+		t.Fatalf("message outside working hours should not reach the chat gateway")
+	}
+
+	// Once open, messages flow to the chat as usual.
+	hours.decision = workhours.Decision{Open: true}
+	msg.Message = channel.Message{ID: "msg-2", Text: "hello again"}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gateway.gotReq.Query == "" {
+		t.Fatalf("expected chat call during working hours")
+	}
+}
+
+type fakeMaintenance struct {
+	notice string
+	active bool
+}
+
+func (f *fakeMaintenance) Active(context.Context) (string, bool) {
+	return f.notice, f.active
+}
+
+func TestChannelInboundProcessorMaintenance(t *testing.T) {
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-maint"}}
+	policySvc := &fakePolicyService{}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{BotID: "chat-maint", RouteID: "route-maint"}}
+	gateway := &fakeChatGateway{
+		resp: fakeChatResponse{
+			Messages: []turn.ModelMessage{
+				{Role: "assistant", Content: turn.NewTextContent("AI reply")},
+			},
+		},
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, policySvc, "", 0)
+	maintenance := &fakeMaintenance{notice: "Upgrading, back at 14:00.", active: true}
+	processor.SetMaintenance(maintenance)
+	sender := &fakeReplySender{}
+
+	cfg := channel.ChannelConfig{TeamID: "team-test", ID: "cfg-maint", BotID: "bot-1", ChannelType: channel.ChannelType("telegram")}
+	msg := channel.InboundMessage{
+		BotID:        "bot-1",
+		Channel:      channel.ChannelType("telegram"),
+		Message:      channel.Message{ID: "msg-1", Text: "are you there?"},
+		ReplyTarget:  "chat-1",
+		Sender:       channel.Identity{SubjectID: "user-1", DisplayName: "Ada"},
+		Conversation: channel.Conversation{ID: "chat-1", Type: channel.ConversationTypePrivate},
+	}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Message.PlainText() != "Upgrading, back at 14:00." {
+		t.Fatalf("expected maintenance notice, got %+v", sender.sent)
+	}
+	if gateway.gotReq.Query != "" {
+		t.Fatalf("message during maintenance should not reach the chat gateway")
+	}
+
+	// Undirected group chatter is not answered.
+	group := msg
+	group.Message = channel.Message{ID: "msg-2", Text: "lunch?"}
+	group.Conversation = channel.Conversation{ID: "group-1", Type: channel.ConversationTypeGroup}
+	group.ReplyTarget = "group-1"
+	if err := processor.HandleInbound(context.Background(), cfg, group, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("undirected group message answered during maintenance: %+v", sender.sent)
+	}
+
+	// Once maintenance ends, messages flow to the chat as usual.
+	maintenance.active = false
+	msg.Message = channel.Message{ID: "msg-3", Text: "hello again"}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gateway.gotReq.Query == "" {
+		t.Fatalf("expected chat call after maintenance")
+	}
+}
+
+type fakeInboundRateLimits struct {
+	perMinute int
+	burst     int
+}
+
+func (f *fakeInboundRateLimits) InboundRateLimit(context.Context, string) (int, int, error) {
+	return f.perMinute, f.burst, nil
+}
+
+func TestChannelInboundProcessorRateLimitsSender(t *testing.T) {
+	channelIdentitySvc := &fakeChannelIdentityService{channelIdentity: identities.ChannelIdentity{ID: "channelIdentity-flood"}}
+	policySvc := &fakePolicyService{}
+	chatSvc := &fakeChatService{resolveResult: route.ResolveConversationResult{BotID: "chat-flood", RouteID: "route-flood"}}
+	calls := 0
+	gateway := &fakeChatGateway{
+		resp: fakeChatResponse{
+			Messages: []turn.ModelMessage{
+				{Role: "assistant", Content: turn.NewTextContent("AI reply")},
+			},
+		},
+		onChat: func(turn.StartTurnCommand) { calls++ },
+	}
+	processor := NewChannelInboundProcessor(slog.Default(), nil, chatSvc, chatSvc, gateway, channelIdentitySvc, policySvc, "", 0)
+	limits := &fakeInboundRateLimits{perMinute: 1, burst: 2}
+	processor.SetInboundRateLimits(limits)
+
+	cfg := channel.ChannelConfig{TeamID: "team-test", ID: "cfg-flood", BotID: "bot-1", ChannelType: channel.ChannelType("telegram")}
+	slowDowns := 0
+	for i := range 4 {
+		sender := &fakeReplySender{}
+		msg := channel.InboundMessage{
+			BotID:        "bot-1",
+			Channel:      channel.ChannelType("telegram"),
+			Message:      channel.Message{ID: fmt.Sprintf("msg-%d", i), Text: "again"},
+			ReplyTarget:  "chat-1",
+			Sender:       channel.Identity{SubjectID: "user-1", DisplayName: "Ada"},
+			Conversation: channel.Conversation{ID: "chat-1", Type: channel.ConversationTypePrivate},
+		}
+		if err := processor.HandleInbound(context.Background(), cfg, msg, sender); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, out := range sender.sent {
+			if strings.Contains(out.Message.PlainText(), "too quickly") {
+				slowDowns++
+			}
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("chat called %d times, want the burst of 2", calls)
+	}
+	if slowDowns != 1 {
+		t.Fatalf("sent %d slow-down replies, want one per flood", slowDowns)
+	}
+
+	// Lifting the limit lets the sender through again.
+	limits.perMinute = 0
+	msg := channel.InboundMessage{
+		BotID:        "bot-1",
+		Channel:      channel.ChannelType("telegram"),
+		Message:      channel.Message{ID: "msg-after", Text: "hello"},
+		ReplyTarget:  "chat-1",
+		Sender:       channel.Identity{SubjectID: "user-1", DisplayName: "Ada"},
+		Conversation: channel.Conversation{ID: "chat-1", Type: channel.ConversationTypePrivate},
+	}
+	if err := processor.HandleInbound(context.Background(), cfg, msg, &fakeReplySender{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("chat called %d times after lifting the limit, want 3", calls)
+	}
+}
+
+func TestSenderLimiterRefills(t *testing.T) {
+	t.Parallel()
+
+	limiter := newSenderLimiter()
+	now := time.Unix(1710000000, 0)
+	limiter.now = func() time.Time { return now }
+
+	if ok, _ := limiter.allow(context.Background(), "bot-1:user-1", 60, 1); !ok {
+		t.Fatal("first message refused")
+	}
+	if ok, notify := limiter.allow(context.Background(), "bot-1:user-1", 60, 1); ok || !notify {
+		t.Fatalf("second message = %v, %v; want refused with a notice", ok, notify)
+	}
+	if ok, _ := limiter.allow(context.Background(), "bot-1:user-2", 60, 1); !ok {
+		t.Fatal("another sender shares the bucket")
+	}
+	now = now.Add(time.Second)
+	if ok, _ := limiter.allow(context.Background(), "bot-1:user-1", 60, 1); !ok {
+		t.Fatal("bucket did not refill")
+	}
+}
+
 type failingOpenStreamSender struct {
 	err error
 }
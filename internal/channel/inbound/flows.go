@@ -0,0 +1,166 @@
+package inbound
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/flows"
+	"github.com/memohai/memoh/internal/command"
+	"github.com/memohai/memoh/internal/i18n"
+)
+
+// runConversationFlow takes text as the answer to the flow running in a
+// private conversation. It reports whether the text was consumed by the
+// flow; when the flow just completed it returns the filled-in form, which
+// replaces the text handed to the conversation. Flow failures are logged
+// and never block the conversation.
+func (p *ChannelInboundProcessor) runConversationFlow(
+	ctx context.Context,
+	msg channel.InboundMessage,
+	sender channel.StreamReplySender,
+	identity InboundIdentity,
+	routeID string,
+	text string,
+) (bool, string) {
+	if p.conversationFlows == nil || strings.TrimSpace(routeID) == "" ||
+		!isDirectConversationType(msg.Conversation.Type) || isLocalChannelType(msg.Channel) {
+		return false, ""
+	}
+	botID := strings.TrimSpace(identity.BotID)
+	reply, err := p.conversationFlows.Answer(ctx, botID, routeID, text, time.Now())
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("conversation flow failed",
+				slog.String("bot_id", botID),
+				slog.String("route_id", routeID),
+				slog.Any("error", err))
+		}
+		return false, ""
+	}
+	p.sendFlowReply(ctx, msg, sender, identity, reply)
+	if reply.Result != nil {
+		return false, reply.Result.Text()
+	}
+	return reply.Handled, ""
+}
+
+// handleFlowCommand runs /flow [list | start <name> | pause | resume | cancel].
+func (p *ChannelInboundProcessor) handleFlowCommand(
+	ctx context.Context,
+	msg channel.InboundMessage,
+	sender channel.StreamReplySender,
+	identity InboundIdentity,
+	routeID string,
+	invocation command.Invocation,
+) error {
+	loc := p.localizer(ctx, identity.BotID)
+	notice := ""
+	switch {
+	case p.conversationFlows == nil:
+		notice = "flows.unavailable"
+	case !isDirectConversationType(msg.Conversation.Type) || isLocalChannelType(msg.Channel):
+		notice = "flows.directOnly"
+	}
+	if notice != "" {
+		return sender.Send(ctx, channel.OutboundMessage{
+			Target:  strings.TrimSpace(msg.ReplyTarget),
+			Message: applyMessageFormat(channel.Message{Text: loc.T(notice)}, p.channelCaps(msg.Channel)),
+		})
+	}
+	botID := strings.TrimSpace(identity.BotID)
+	reply, err := p.conversationFlows.Command(ctx, botID, routeID, invocation.Parsed.Action, invocation.Parsed.Args, time.Now())
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("flow command failed",
+				slog.String("bot_id", botID),
+				slog.String("route_id", routeID),
+				slog.Any("error", err))
+		}
+		return sender.Send(ctx, channel.OutboundMessage{
+			Target:  strings.TrimSpace(msg.ReplyTarget),
+			Message: plainTextMessage(friendlyOps(loc, "ops.verb.completeCommand"), p.channelCaps(msg.Channel)),
+		})
+	}
+	p.sendFlowReply(ctx, msg, sender, identity, reply)
+	return nil
+}
+
+func (p *ChannelInboundProcessor) sendFlowReply(
+	ctx context.Context,
+	msg channel.InboundMessage,
+	sender channel.StreamReplySender,
+	identity InboundIdentity,
+	reply flows.Reply,
+) {
+	caps := p.channelCaps(msg.Channel)
+	target := strings.TrimSpace(msg.ReplyTarget)
+	for _, text := range flowTexts(p.localizer(ctx, identity.BotID), reply) {
+		if err := sender.Send(ctx, channel.OutboundMessage{
+			Target:  target,
+			Message: applyMessageFormat(channel.Message{Text: text}, caps),
+		}); err != nil {
+			if p.logger != nil {
+				p.logger.Warn("send flow message failed", slog.Any("error", err))
+			}
+			return
+		}
+	}
+}
+
+// flowTexts lists the messages of reply in send order: the status notice,
+// the validation error, the completion message, then the next question.
+func flowTexts(loc *i18n.Localizer, reply flows.Reply) []string {
+	var texts []string
+	switch reply.Notice {
+	case "":
+	case flows.NoticeList:
+		lines := []string{loc.T("flows.list")}
+		for _, flow := range reply.Available {
+			line := "- " + flow.Name
+			if flow.Title != "" {
+				line += " — " + flow.Title
+			}
+			if flow.Description != "" {
+				line += ": " + flow.Description
+			}
+			lines = append(lines, line)
+		}
+		texts = append(texts, strings.Join(lines, "\n"))
+	default:
+		texts = append(texts, loc.T("flows."+reply.Notice, map[string]any{"title": reply.Title}))
+	}
+	if reply.Invalid != "" {
+		texts = append(texts, loc.T("flows.invalid."+reply.Invalid))
+	}
+	if reply.Result != nil {
+		completion := reply.Completion
+		if completion == "" {
+			completion = loc.T("flows.completed", map[string]any{"title": reply.Title})
+		}
+		texts = append(texts, completion)
+	}
+	if reply.Ask != nil {
+		texts = append(texts, flowQuestionText(loc, *reply.Ask))
+	}
+	return texts
+}
+
+// flowQuestionText renders a question with its progress, numbered choices
+// and answer hints.
+func flowQuestionText(loc *i18n.Localizer, q flows.Question) string {
+	lines := []string{fmt.Sprintf("(%d/%d) %s", q.Number, q.Total, q.Prompt)}
+	for i, choice := range q.Choices {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, choice))
+	}
+	if q.Kind == flows.KindYesNo {
+		lines = append(lines, loc.T("flows.yesNo"))
+	}
+	if q.Optional {
+		lines = append(lines, loc.T("flows.optional"))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,37 @@
+package inbound
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel/flows"
+	"github.com/memohai/memoh/internal/i18n"
+)
+
+func TestFlowTextsRenderQuestionAndCompletion(t *testing.T) {
+	t.Parallel()
+
+	loc := i18n.New("en")
+	texts := flowTexts(loc, flows.Reply{
+		Invalid: flows.InvalidChoice,
+		Ask: &flows.Question{
+			Prompt:   "How bad is it?",
+			Number:   2,
+			Total:    3,
+			Kind:     flows.KindChoice,
+			Choices:  []string{"low", "high"},
+			Optional: true,
+		},
+	})
+	if len(texts) != 2 || texts[0] != loc.T("flows.invalid.choice") {
+		t.Fatalf("texts = %q", texts)
+	}
+	if want := "(2/3) How bad is it?\n1. low\n2. high\n" + loc.T("flows.optional"); texts[1] != want {
+		t.Fatalf("question = %q, want %q", texts[1], want)
+	}
+
+	done := flowTexts(loc, flows.Reply{Title: "Bug report", Result: &flows.Result{}})
+	if len(done) != 1 || !strings.Contains(done[0], "Bug report") {
+		t.Fatalf("completion texts = %q", done)
+	}
+}
@@ -107,6 +107,11 @@ func NewIdentityResolver(
 func (r *IdentityResolver) Middleware() channel.Middleware {
 	return func(next channel.InboundHandler) channel.InboundHandler {
 		return func(ctx context.Context, cfg channel.ChannelConfig, msg channel.InboundMessage) error {
+			// Membership events carry no user content; the actor who removed
+			// or added the bot is not resolved as a channel identity.
+			if channel.MembershipEvent(msg) != "" {
+				return next(ctx, cfg, msg)
+			}
 			state, err := r.Resolve(ctx, cfg, msg)
 			if err != nil {
 				return err
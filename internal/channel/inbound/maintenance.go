@@ -0,0 +1,46 @@
+package inbound
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// holdForMaintenance answers messages while the deployment is in maintenance
+// mode. It reports whether the message was held back, in which case the
+// agent does not run. Directed messages get the maintenance notice;
+// undirected group chatter is dropped silently.
+func (p *ChannelInboundProcessor) holdForMaintenance(
+	ctx context.Context,
+	msg channel.InboundMessage,
+	sender channel.StreamReplySender,
+	identity InboundIdentity,
+	directed bool,
+) bool {
+	if p.maintenance == nil {
+		return false
+	}
+	notice, active := p.maintenance.Active(ctx)
+	if !active {
+		return false
+	}
+	if !directed {
+		return true
+	}
+	if strings.TrimSpace(notice) == "" {
+		notice = p.localizer(ctx, identity.BotID).T("maintenance.notice")
+	}
+	out := applyMessageFormat(channel.Message{Text: notice}, p.channelCaps(msg.Channel))
+	if mid := strings.TrimSpace(msg.Message.ID); mid != "" && !isDirectConversationType(msg.Conversation.Type) {
+		out.Reply = &channel.ReplyRef{MessageID: mid}
+	}
+	if err := sender.Send(ctx, channel.OutboundMessage{
+		Target:  strings.TrimSpace(msg.ReplyTarget),
+		Message: out,
+	}); err != nil && p.logger != nil {
+		p.logger.Warn("send maintenance notice failed", slog.Any("error", err))
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package inbound
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/onboarding"
+	"github.com/memohai/memoh/internal/i18n"
+)
+
+// runOnboarding greets new private contacts and collects their profile
+// answers. It reports whether the message was consumed by onboarding.
+// Onboarding failures are logged and never block the conversation.
+func (p *ChannelInboundProcessor) runOnboarding(
+	ctx context.Context,
+	cfg channel.ChannelConfig,
+	msg channel.InboundMessage,
+	sender channel.StreamReplySender,
+	identity InboundIdentity,
+	text string,
+) bool {
+	if p.onboarder == nil || !isDirectConversationType(msg.Conversation.Type) || isLocalChannelType(msg.Channel) {
+		return false
+	}
+	onboardingCfg := onboarding.ParseConfig(cfg.Routing)
+	if !onboardingCfg.Enabled {
+		return false
+	}
+	reply, err := p.onboarder.Greet(ctx, onboardingCfg, onboardingContact(identity), text)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("onboarding failed",
+				slog.String("bot_id", strings.TrimSpace(identity.BotID)),
+				slog.String("channel_identity_id", strings.TrimSpace(identity.ChannelIdentityID)),
+				slog.Any("error", err))
+		}
+		return false
+	}
+	p.sendOnboardingReply(ctx, msg, sender, identity, reply)
+	return reply.Handled
+}
+
+// welcomeBoundContact sends the bind welcome after /link linked the sender's
+// channel identity to an account.
+func (p *ChannelInboundProcessor) welcomeBoundContact(
+	ctx context.Context,
+	cfg channel.ChannelConfig,
+	msg channel.InboundMessage,
+	sender channel.StreamReplySender,
+	identity InboundIdentity,
+) {
+	if p.onboarder == nil || p.identity == nil || !isDirectConversationType(msg.Conversation.Type) {
+		return
+	}
+	onboardingCfg := onboarding.ParseConfig(cfg.Routing)
+	if !onboardingCfg.Enabled {
+		return
+	}
+	// The identity was resolved before the command ran; look the linked
+	// account up again to see whether /link succeeded.
+	userID, err := p.identity.accountUserIDForChannelIdentity(ctx, identity.ChannelIdentityID)
+	if err != nil || strings.TrimSpace(userID) == "" {
+		return
+	}
+	identity.UserID = userID
+	reply, err := p.onboarder.Bound(ctx, onboardingCfg, onboardingContact(identity))
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("onboarding bind welcome failed",
+				slog.String("bot_id", strings.TrimSpace(identity.BotID)),
+				slog.String("channel_identity_id", strings.TrimSpace(identity.ChannelIdentityID)),
+				slog.Any("error", err))
+		}
+		return
+	}
+	p.sendOnboardingReply(ctx, msg, sender, identity, reply)
+}
+
+// observeSenderTimezone records the timezone the platform reports for the
+// sender, so replies and schedules use the contact's local time.
+func (p *ChannelInboundProcessor) observeSenderTimezone(ctx context.Context, msg channel.InboundMessage, identity InboundIdentity) {
+	tz := msg.Sender.Attribute("timezone")
+	if p.onboarder == nil || tz == "" {
+		return
+	}
+	err := p.onboarder.ObservePlatformTimezone(ctx, strings.TrimSpace(identity.BotID), strings.TrimSpace(identity.ChannelIdentityID), tz)
+	if err != nil && p.logger != nil {
+		p.logger.Warn("record sender timezone failed",
+			slog.String("bot_id", strings.TrimSpace(identity.BotID)),
+			slog.String("channel_identity_id", strings.TrimSpace(identity.ChannelIdentityID)),
+			slog.Any("error", err))
+	}
+}
+
+func (p *ChannelInboundProcessor) sendOnboardingReply(
+	ctx context.Context,
+	msg channel.InboundMessage,
+	sender channel.StreamReplySender,
+	identity InboundIdentity,
+	reply onboarding.Reply,
+) {
+	if reply.Empty() {
+		return
+	}
+	texts := onboardingTexts(p.localizer(ctx, identity.BotID), reply)
+	caps := p.channelCaps(msg.Channel)
+	target := strings.TrimSpace(msg.ReplyTarget)
+	for _, text := range texts {
+		if err := sender.Send(ctx, channel.OutboundMessage{
+			Target:  target,
+			Message: applyMessageFormat(channel.Message{Text: text}, caps),
+		}); err != nil {
+			if p.logger != nil {
+				p.logger.Warn("send onboarding message failed", slog.Any("error", err))
+			}
+			return
+		}
+	}
+}
+
+// onboardingTexts lists the messages of reply in send order: the welcome
+// sequence, then the localized validation error, completion note or prompt.
+func onboardingTexts(loc *i18n.Localizer, reply onboarding.Reply) []string {
+	texts := append([]string{}, reply.Messages...)
+	if reply.Invalid != "" {
+		texts = append(texts, loc.T("onboarding.invalid."+reply.Invalid))
+	}
+	if reply.Completed {
+		texts = append(texts, loc.T("onboarding.completed"))
+	}
+	if reply.Ask != "" {
+		texts = append(texts, loc.T("onboarding.ask."+reply.Ask))
+	}
+	return texts
+}
+
+func onboardingContact(identity InboundIdentity) onboarding.Contact {
+	return onboarding.Contact{
+		BotID:             strings.TrimSpace(identity.BotID),
+		ChannelIdentityID: strings.TrimSpace(identity.ChannelIdentityID),
+		UserID:            strings.TrimSpace(identity.UserID),
+		DisplayName:       strings.TrimSpace(identity.DisplayName),
+	}
+}
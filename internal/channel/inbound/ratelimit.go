@@ -0,0 +1,152 @@
+package inbound
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/sharedstate"
+)
+
+// senderBucketIdle is how long an idle sender's bucket is kept. A bucket
+// left alone this long has refilled for any limit of at least one message
+// per minute, so dropping it loses nothing.
+const senderBucketIdle = 10 * time.Minute
+
+// senderLimiter keeps one token bucket per bot and channel identity. With a
+// shared store the buckets live there, so the limit holds across replicas.
+type senderLimiter struct {
+	now    func() time.Time
+	shared sharedstate.Store
+
+	mu      sync.Mutex
+	buckets map[string]*senderBucket
+	swept   time.Time
+}
+
+type senderBucket struct {
+	limiter   *rate.Limiter
+	perMinute int
+	burst     int
+	lastSeen  time.Time
+	// warned is set once the sender was told to slow down, so a flood gets
+	// one reply rather than one per dropped message.
+	warned bool
+}
+
+func newSenderLimiter() *senderLimiter {
+	return &senderLimiter{now: time.Now, buckets: map[string]*senderBucket{}}
+}
+
+// allow takes a token from the sender's bucket. When the bucket is empty it
+// reports false, and notify is true for the first refusal since the sender
+// was last allowed through. Changing the limit starts a fresh bucket.
+func (l *senderLimiter) allow(ctx context.Context, key string, perMinute, burst int) (allowed bool, notify bool) {
+	if perMinute <= 0 {
+		return true, false
+	}
+	if burst <= 0 {
+		burst = perMinute
+	}
+	if l.shared != nil {
+		return l.allowShared(ctx, key, perMinute, burst)
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.swept) >= senderBucketIdle {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) >= senderBucketIdle {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+	b := l.buckets[key]
+	if b == nil || b.perMinute != perMinute || b.burst != burst {
+		b = &senderBucket{
+			limiter:   rate.NewLimiter(rate.Limit(float64(perMinute)/60), burst),
+			perMinute: perMinute,
+			burst:     burst,
+		}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	if b.limiter.AllowN(now, 1) {
+		b.warned = false
+		return true, false
+	}
+	notify = !b.warned
+	b.warned = true
+	return false, notify
+}
+
+// allowShared is allow against the shared store. The first refusal claims a
+// warning marker that lasts until the next token is due, which is when the
+// local bucket would have cleared its warned flag. Store errors let the
+// message through.
+func (l *senderLimiter) allowShared(ctx context.Context, key string, perMinute, burst int) (bool, bool) {
+	ok, err := l.shared.Allow(ctx, "inbound:"+key, perMinute, burst)
+	if ok || err != nil {
+		return true, false
+	}
+	notify, err := l.shared.Claim(ctx, "inbound_warned:"+key, time.Minute/time.Duration(perMinute))
+	return false, notify && err == nil
+}
+
+// throttleSender applies the bot's per-sender inbound rate limit to a
+// message addressed to the bot. It reports whether the message was held
+// back; the sender gets one "slow down" reply per flood. Limit lookup
+// failures are logged and never block the conversation.
+func (p *ChannelInboundProcessor) throttleSender(
+	ctx context.Context,
+	msg channel.InboundMessage,
+	sender channel.StreamReplySender,
+	identity InboundIdentity,
+) bool {
+	if p.rateLimits == nil || isLocalChannelType(msg.Channel) {
+		return false
+	}
+	botID := strings.TrimSpace(identity.BotID)
+	senderID := strings.TrimSpace(identity.ChannelIdentityID)
+	if botID == "" || senderID == "" {
+		return false
+	}
+	perMinute, burst, err := p.rateLimits.InboundRateLimit(ctx, botID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("inbound rate limit lookup failed", slog.String("bot_id", botID), slog.Any("error", err))
+		}
+		return false
+	}
+	allowed, notify := p.senderLimits.allow(ctx, botID+":"+senderID, perMinute, burst)
+	if allowed {
+		return false
+	}
+	if p.logger != nil {
+		p.logger.Info("inbound rate limited",
+			slog.String("channel", msg.Channel.String()),
+			slog.String("bot_id", botID),
+			slog.String("channel_identity_id", senderID),
+		)
+	}
+	if !notify {
+		return true
+	}
+	out := applyMessageFormat(channel.Message{Text: p.localizer(ctx, botID).T("rateLimit.slowDown")}, p.channelCaps(msg.Channel))
+	if mid := strings.TrimSpace(msg.Message.ID); mid != "" && !isDirectConversationType(msg.Conversation.Type) {
+		out.Reply = &channel.ReplyRef{MessageID: mid}
+	}
+	if err := sender.Send(ctx, channel.OutboundMessage{
+		Target:  strings.TrimSpace(msg.ReplyTarget),
+		Message: out,
+	}); err != nil && p.logger != nil {
+		p.logger.Warn("send rate limit reply failed", slog.Any("error", err))
+	}
+	return true
+}
@@ -0,0 +1,204 @@
+package inbound
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// TurnPriority is the admission class of a waiting turn. Lower values are
+// admitted first.
+type TurnPriority int
+
+const (
+	// PriorityOwner covers the bot owner and identities granted manage.
+	PriorityOwner TurnPriority = iota
+	// PriorityMember covers senders linked to a Memoh account.
+	PriorityMember
+	// PriorityGuest covers unlinked channel identities.
+	PriorityGuest
+
+	turnPriorityCount = int(PriorityGuest) + 1
+)
+
+// TurnScheduler bounds how many agent turns run at once for each bot. When a
+// bot is saturated, waiting turns are admitted by priority class and, inside
+// a class, round-robin across senders, so one sender flooding a public bot
+// only delays their own turns.
+type TurnScheduler struct {
+	mu    sync.Mutex
+	limit int
+	bots  map[string]*botTurnPool
+}
+
+type botTurnPool struct {
+	running int
+	classes [turnPriorityCount]turnClassQueue
+}
+
+// turnClassQueue holds the waiters of one priority class. senders lists the
+// senders with waiters in round-robin order.
+type turnClassQueue struct {
+	senders []string
+	waiters map[string][]*turnWaiter
+}
+
+type turnWaiter struct {
+	ready    chan struct{}
+	admitted bool
+}
+
+// NewTurnScheduler creates a scheduler that runs at most limit turns per bot.
+// A non-positive limit admits every turn immediately.
+func NewTurnScheduler(limit int) *TurnScheduler {
+	return &TurnScheduler{
+		limit: limit,
+		bots:  make(map[string]*botTurnPool),
+	}
+}
+
+// Acquire blocks until the turn may run and returns the function that frees
+// its slot. It returns ctx.Err() if ctx ends first.
+func (s *TurnScheduler) Acquire(ctx context.Context, botID, senderKey string, priority TurnPriority) (func(), error) {
+	if s == nil || s.limit <= 0 {
+		return func() {}, nil
+	}
+	botID = strings.TrimSpace(botID)
+	senderKey = strings.TrimSpace(senderKey)
+	if priority < PriorityOwner || int(priority) >= turnPriorityCount {
+		priority = PriorityGuest
+	}
+
+	s.mu.Lock()
+	pool := s.bots[botID]
+	if pool == nil {
+		pool = &botTurnPool{}
+		s.bots[botID] = pool
+	}
+	if pool.running < s.limit && !pool.hasWaiters() {
+		pool.running++
+		s.mu.Unlock()
+		return s.releaseFunc(botID), nil
+	}
+	waiter := &turnWaiter{ready: make(chan struct{})}
+	pool.classes[priority].push(senderKey, waiter)
+	s.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return s.releaseFunc(botID), nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		if waiter.admitted {
+			s.mu.Unlock()
+			s.release(botID)
+		} else {
+			pool.classes[priority].remove(senderKey, waiter)
+			if pool.running == 0 && !pool.hasWaiters() {
+				delete(s.bots, botID)
+			}
+			s.mu.Unlock()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (s *TurnScheduler) releaseFunc(botID string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { s.release(botID) })
+	}
+}
+
+func (s *TurnScheduler) release(botID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pool := s.bots[botID]
+	if pool == nil {
+		return
+	}
+	if pool.running > 0 {
+		pool.running--
+	}
+	for pool.running < s.limit {
+		waiter := pool.next()
+		if waiter == nil {
+			break
+		}
+		waiter.admitted = true
+		pool.running++
+		close(waiter.ready)
+	}
+	if pool.running == 0 && !pool.hasWaiters() {
+		delete(s.bots, botID)
+	}
+}
+
+func (p *botTurnPool) hasWaiters() bool {
+	for i := range p.classes {
+		if len(p.classes[i].senders) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// next pops the first waiter of the highest non-empty class and rotates its
+// sender to the back of that class.
+func (p *botTurnPool) next() *turnWaiter {
+	for i := range p.classes {
+		if waiter := p.classes[i].pop(); waiter != nil {
+			return waiter
+		}
+	}
+	return nil
+}
+
+func (q *turnClassQueue) push(sender string, waiter *turnWaiter) {
+	if q.waiters == nil {
+		q.waiters = make(map[string][]*turnWaiter)
+	}
+	if len(q.waiters[sender]) == 0 {
+		q.senders = append(q.senders, sender)
+	}
+	q.waiters[sender] = append(q.waiters[sender], waiter)
+}
+
+func (q *turnClassQueue) pop() *turnWaiter {
+	if len(q.senders) == 0 {
+		return nil
+	}
+	sender := q.senders[0]
+	q.senders = q.senders[1:]
+	waiters := q.waiters[sender]
+	waiter := waiters[0]
+	if len(waiters) > 1 {
+		q.waiters[sender] = waiters[1:]
+		q.senders = append(q.senders, sender)
+	} else {
+		delete(q.waiters, sender)
+	}
+	return waiter
+}
+
+func (q *turnClassQueue) remove(sender string, waiter *turnWaiter) {
+	waiters := q.waiters[sender]
+	for i, w := range waiters {
+		if w != waiter {
+			continue
+		}
+		waiters = append(waiters[:i], waiters[i+1:]...)
+		break
+	}
+	if len(waiters) > 0 {
+		q.waiters[sender] = waiters
+		return
+	}
+	delete(q.waiters, sender)
+	for i, s := range q.senders {
+		if s == sender {
+			q.senders = append(q.senders[:i], q.senders[i+1:]...)
+			break
+		}
+	}
+}
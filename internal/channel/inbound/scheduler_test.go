@@ -0,0 +1,135 @@
+package inbound
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// queueTurn starts an Acquire in the background and waits until it is
+// enqueued, so tests control the arrival order of waiters.
+func queueTurn(t *testing.T, s *TurnScheduler, botID, sender string, priority TurnPriority, admitted chan<- string) {
+	t.Helper()
+	before := waitingTurns(s, botID)
+	go func() {
+		release, err := s.Acquire(context.Background(), botID, sender, priority)
+		if err != nil {
+			return
+		}
+		admitted <- sender
+		release()
+	}()
+	deadline := time.Now().Add(time.Second)
+	for waitingTurns(s, botID) == before {
+		if time.Now().After(deadline) {
+			t.Fatalf("turn for %s was not queued", sender)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func waitingTurns(s *TurnScheduler, botID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pool := s.bots[botID]
+	if pool == nil {
+		return 0
+	}
+	n := 0
+	for i := range pool.classes {
+		for _, waiters := range pool.classes[i].waiters {
+			n += len(waiters)
+		}
+	}
+	return n
+}
+
+func TestTurnSchedulerAdmitsByPriorityThenRoundRobin(t *testing.T) {
+	t.Parallel()
+
+	s := NewTurnScheduler(1)
+	release, err := s.Acquire(context.Background(), "bot-1", "busy", PriorityGuest)
+	if err != nil {
+		t.Fatalf("Acquire returned error: %v", err)
+	}
+
+	// Each admitted turn releases right away, so admission is strictly serial.
+	admitted := make(chan string, 8)
+	queueTurn(t, s, "bot-1", "spammer", PriorityGuest, admitted)
+	queueTurn(t, s, "bot-1", "spammer", PriorityGuest, admitted)
+	queueTurn(t, s, "bot-1", "spammer", PriorityGuest, admitted)
+	queueTurn(t, s, "bot-1", "guest", PriorityGuest, admitted)
+	queueTurn(t, s, "bot-1", "member", PriorityMember, admitted)
+	queueTurn(t, s, "bot-1", "owner", PriorityOwner, admitted)
+	release()
+
+	want := []string{"owner", "member", "spammer", "guest", "spammer", "spammer"}
+	for i, sender := range want {
+		select {
+		case got := <-admitted:
+			if got != sender {
+				t.Fatalf("admission %d = %s, want %s", i, got, sender)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("admission %d (%s) timed out", i, sender)
+		}
+	}
+}
+
+func TestTurnSchedulerIsolatesBots(t *testing.T) {
+	t.Parallel()
+
+	s := NewTurnScheduler(1)
+	release, err := s.Acquire(context.Background(), "bot-1", "a", PriorityGuest)
+	if err != nil {
+		t.Fatalf("Acquire returned error: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	other, err := s.Acquire(ctx, "bot-2", "a", PriorityGuest)
+	if err != nil {
+		t.Fatalf("another bot should not wait: %v", err)
+	}
+	other()
+}
+
+func TestTurnSchedulerCancelledWaiterLeavesQueue(t *testing.T) {
+	t.Parallel()
+
+	s := NewTurnScheduler(1)
+	release, err := s.Acquire(context.Background(), "bot-1", "a", PriorityGuest)
+	if err != nil {
+		t.Fatalf("Acquire returned error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(ctx, "bot-1", "b", PriorityOwner); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if n := waitingTurns(s, "bot-1"); n != 0 {
+		t.Fatalf("cancelled waiter still queued: %d", n)
+	}
+
+	release()
+	release()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.bots) != 0 {
+		t.Fatalf("idle bot pools should be dropped, got %d", len(s.bots))
+	}
+}
+
+func TestTurnSchedulerDisabled(t *testing.T) {
+	t.Parallel()
+
+	s := NewTurnScheduler(0)
+	for range 3 {
+		if _, err := s.Acquire(context.Background(), "bot-1", "a", PriorityGuest); err != nil {
+			t.Fatalf("disabled scheduler should not block: %v", err)
+		}
+	}
+}
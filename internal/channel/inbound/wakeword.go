@@ -0,0 +1,130 @@
+package inbound
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/width"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// wakeWordCooldownRetention is how long a trigger is remembered; it matches
+// the longest cooldown bot settings allow.
+const wakeWordCooldownRetention = time.Hour
+
+// wakeWordCooldowns remembers when a wake word last triggered each bot in
+// each conversation.
+type wakeWordCooldowns struct {
+	now func() time.Time
+
+	mu    sync.Mutex
+	last  map[string]time.Time
+	swept time.Time
+}
+
+func newWakeWordCooldowns() *wakeWordCooldowns {
+	return &wakeWordCooldowns{now: time.Now, last: map[string]time.Time{}}
+}
+
+// take records a trigger for key unless the previous one is still within
+// cooldown, in which case it reports false.
+func (c *wakeWordCooldowns) take(key string, cooldown time.Duration) bool {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Sub(c.swept) >= wakeWordCooldownRetention {
+		for k, last := range c.last {
+			if now.Sub(last) >= wakeWordCooldownRetention {
+				delete(c.last, k)
+			}
+		}
+		c.swept = now
+	}
+	if last, ok := c.last[key]; ok && now.Sub(last) < cooldown {
+		return false
+	}
+	c.last[key] = now
+	return true
+}
+
+// markWakeWord flags a group message that calls the bot by one of its wake
+// words, so shouldTriggerAssistantResponse treats it like an @mention.
+// Messages already directed at the bot are left alone and do not start the
+// cooldown.
+func (p *ChannelInboundProcessor) markWakeWord(ctx context.Context, msg channel.InboundMessage, identity InboundIdentity) channel.InboundMessage {
+	if p.wakeWords == nil || isDirectedAtBot(msg) {
+		return msg
+	}
+	botID := strings.TrimSpace(identity.BotID)
+	text := rawTextForCommand(msg, strings.TrimSpace(msg.Message.PlainText()))
+	if botID == "" || text == "" {
+		return msg
+	}
+	cfg, err := p.wakeWords.WakeWords(ctx, botID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("wake word lookup failed", slog.String("bot_id", botID), slog.Any("error", err))
+		}
+		return msg
+	}
+	if !cfg.Enabled || !containsWakeWord(text, cfg.Words) {
+		return msg
+	}
+	key := botID + ":" + msg.Channel.String() + ":" + strings.TrimSpace(msg.Conversation.ID)
+	if !p.wakeWordCooldowns.take(key, cfg.Cooldown) {
+		if p.logger != nil {
+			p.logger.Debug("wake word ignored during cooldown", slog.String("bot_id", botID), slog.String("conversation_id", strings.TrimSpace(msg.Conversation.ID)))
+		}
+		return msg
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]any)
+	}
+	msg.Metadata["is_wake_word"] = true
+	return msg
+}
+
+// containsWakeWord reports whether text contains one of words, ignoring case
+// and full/half width. Words ending in a letter or digit of a spaced script
+// must stand alone, so "max" does not fire on "maximum"; CJK words match
+// anywhere.
+func containsWakeWord(text string, words []string) bool {
+	haystack := []rune(foldWakeWord(text))
+	for _, word := range words {
+		needle := []rune(foldWakeWord(word))
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(haystack); i++ {
+			if string(haystack[i:i+len(needle)]) != string(needle) {
+				continue
+			}
+			if needsWordBoundary(needle[0]) && i > 0 && isWordRune(haystack[i-1]) {
+				continue
+			}
+			end := i + len(needle)
+			if needsWordBoundary(needle[len(needle)-1]) && end < len(haystack) && isWordRune(haystack[end]) {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func foldWakeWord(s string) string {
+	return strings.ToLower(width.Fold.String(strings.TrimSpace(s)))
+}
+
+func needsWordBoundary(r rune) bool {
+	return isWordRune(r) && !unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
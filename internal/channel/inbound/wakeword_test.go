@@ -0,0 +1,58 @@
+package inbound
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContainsWakeWord(t *testing.T) {
+	t.Parallel()
+
+	words := []string{"小梦", "Memo"}
+	cases := []struct {
+		text string
+		want bool
+	}{
+		{"小梦，今天天气怎么样", true},
+		{"问问小梦吧", true},
+		{"hey memo, ping", true},
+		{"ＭＥＭＯ help", true},
+		{"MEMO", true},
+		{"memorandum attached", false},
+		{"see the memos", false},
+		{"lunch?", false},
+	}
+	for _, tc := range cases {
+		if got := containsWakeWord(tc.text, words); got != tc.want {
+			t.Errorf("containsWakeWord(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+	if containsWakeWord("小梦", nil) {
+		t.Error("matched without wake words")
+	}
+}
+
+func TestWakeWordCooldowns(t *testing.T) {
+	t.Parallel()
+
+	cooldowns := newWakeWordCooldowns()
+	now := time.Unix(1710000000, 0)
+	cooldowns.now = func() time.Time { return now }
+
+	if !cooldowns.take("bot-1:group-1", time.Minute) {
+		t.Fatal("first trigger refused")
+	}
+	if cooldowns.take("bot-1:group-1", time.Minute) {
+		t.Fatal("trigger within cooldown allowed")
+	}
+	if !cooldowns.take("bot-1:group-2", time.Minute) {
+		t.Fatal("cooldown leaked into another conversation")
+	}
+	now = now.Add(time.Minute)
+	if !cooldowns.take("bot-1:group-1", time.Minute) {
+		t.Fatal("trigger after cooldown refused")
+	}
+	if !cooldowns.take("bot-2:group-1", 0) || !cooldowns.take("bot-2:group-1", 0) {
+		t.Fatal("zero cooldown refused a trigger")
+	}
+}
@@ -0,0 +1,82 @@
+package inbound
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/workhours"
+	"github.com/memohai/memoh/internal/i18n"
+)
+
+// opensAtLayout formats the next opening time in away replies.
+const opensAtLayout = "Mon Jan 2 15:04 MST"
+
+// holdOutsideWorkingHours answers private messages received while the bot is
+// outside its working hours. It reports whether the message was held back:
+// answered with the away reply only, or queued for the next opening time.
+// Working hours failures are logged and never block the conversation.
+func (p *ChannelInboundProcessor) holdOutsideWorkingHours(
+	ctx context.Context,
+	cfg channel.ChannelConfig,
+	msg channel.InboundMessage,
+	sender channel.StreamReplySender,
+	identity InboundIdentity,
+) bool {
+	if p.workingHours == nil || !isDirectConversationType(msg.Conversation.Type) || isLocalChannelType(msg.Channel) {
+		return false
+	}
+	botID := strings.TrimSpace(identity.BotID)
+	conversationKey := msg.Channel.String() + ":" + strings.TrimSpace(msg.Conversation.ID)
+	decision, err := p.workingHours.Check(ctx, botID, conversationKey, time.Now())
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("working hours check failed", slog.String("bot_id", botID), slog.Any("error", err))
+		}
+		return false
+	}
+	if decision.Open {
+		return false
+	}
+	if decision.Defer {
+		if err := p.workingHours.Defer(ctx, botID, cfg.ChannelType, msg, decision.OpensAt); err != nil {
+			if p.logger != nil {
+				p.logger.Warn("defer inbound message failed", slog.String("bot_id", botID), slog.Any("error", err))
+			}
+			return false
+		}
+	}
+	if decision.Notify {
+		text := awayReplyText(p.localizer(ctx, botID), decision)
+		if err := sender.Send(ctx, channel.OutboundMessage{
+			Target:  strings.TrimSpace(msg.ReplyTarget),
+			Message: applyMessageFormat(channel.Message{Text: text}, p.channelCaps(msg.Channel)),
+		}); err != nil && p.logger != nil {
+			p.logger.Warn("send away reply failed", slog.Any("error", err))
+		}
+	}
+	return true
+}
+
+// awayReplyText renders the bot's own away message, or the localized default
+// followed by a note that the message will be answered at opening time.
+func awayReplyText(loc *i18n.Localizer, decision workhours.Decision) string {
+	opensAt := ""
+	if !decision.OpensAt.IsZero() {
+		at := decision.OpensAt
+		if decision.Location != nil {
+			at = at.In(decision.Location)
+		}
+		opensAt = at.Format(opensAtLayout)
+	}
+	if custom := strings.TrimSpace(decision.AwayMessage); custom != "" {
+		return strings.ReplaceAll(custom, "{opens_at}", opensAt)
+	}
+	text := loc.T("workingHours.away", map[string]any{"opens_at": opensAt})
+	if decision.Defer {
+		text += " " + loc.T("workingHours.deferred")
+	}
+	return text
+}
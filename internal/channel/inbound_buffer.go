@@ -0,0 +1,334 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrInboundBackpressure means the adapter's outage buffer is full. It wraps
+// ErrInboundQueueFull so adapters that already treat a full queue as
+// retryable (and let the platform redeliver) handle it the same way.
+var ErrInboundBackpressure = fmt.Errorf("%w: inbound outage buffer full", ErrInboundQueueFull)
+
+const defaultInboundBufferRetryInterval = 5 * time.Second
+
+var unsafeSpoolNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// InboundBufferOptions configures an InboundBuffer.
+type InboundBufferOptions struct {
+	// Dir holds one spool file per channel config.
+	Dir string
+	// MaxEvents bounds each spool. Once reached, new events are refused with
+	// ErrInboundBackpressure.
+	MaxEvents int
+	// RetryInterval is how long to wait before retrying the spool head while
+	// the outage lasts. Defaults to 5s.
+	RetryInterval time.Duration
+	// IsOutage reports whether a processing error means a downstream
+	// dependency is unreachable. Defaults to IsDownstreamUnavailable.
+	IsOutage func(error) bool
+}
+
+// InboundBuffer parks inbound events on disk while a downstream dependency
+// (Postgres, the agent gateway, the server RPC) is unreachable and replays
+// them in arrival order once it recovers. Adapters acknowledge platform
+// events before processing, so without it a short outage loses messages.
+//
+// Each channel config gets its own spool. While a spool is non-empty, new
+// events for that config are appended behind it instead of overtaking it.
+// Spools survive restarts; they resume draining when their config next
+// connects or receives an event. Credentials are never written to disk: the
+// spool keeps the message and replays it with the live config.
+type InboundBuffer struct {
+	dir           string
+	maxEvents     int
+	retryInterval time.Duration
+	isOutage      func(error) bool
+	logger        *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	spools map[string]*inboundSpool
+}
+
+type inboundSpool struct {
+	path     string
+	cfg      ChannelConfig
+	next     InboundHandler
+	pending  int
+	loaded   bool
+	draining bool
+}
+
+type spooledInbound struct {
+	Message    InboundMessage `json:"message"`
+	BufferedAt time.Time      `json:"buffered_at"`
+}
+
+// NewInboundBuffer creates the spool directory and returns a buffer.
+func NewInboundBuffer(log *slog.Logger, opts InboundBufferOptions) (*InboundBuffer, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+	if opts.Dir == "" {
+		return nil, errors.New("inbound buffer dir is required")
+	}
+	if opts.MaxEvents <= 0 {
+		return nil, errors.New("inbound buffer max events must be positive")
+	}
+	if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create inbound buffer dir: %w", err)
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultInboundBufferRetryInterval
+	}
+	if opts.IsOutage == nil {
+		opts.IsOutage = IsDownstreamUnavailable
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &InboundBuffer{
+		dir:           opts.Dir,
+		maxEvents:     opts.MaxEvents,
+		retryInterval: opts.RetryInterval,
+		isOutage:      opts.IsOutage,
+		logger:        log.With(slog.String("component", "inbound_buffer")),
+		ctx:           ctx,
+		cancel:        cancel,
+		spools:        map[string]*inboundSpool{},
+	}, nil
+}
+
+// IsDownstreamUnavailable reports whether err looks like a refused or reset
+// connection to a dependency rather than a failure of the message itself.
+func IsDownstreamUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// Wrap returns next with outage buffering for cfg and resumes any spool left
+// from a previous run.
+func (b *InboundBuffer) Wrap(cfg ChannelConfig, next InboundHandler) InboundHandler {
+	b.mu.Lock()
+	spool := b.attachLocked(cfg, next)
+	b.startDrainLocked(spool)
+	b.mu.Unlock()
+	return func(ctx context.Context, cfg ChannelConfig, msg InboundMessage) error {
+		return b.Handle(ctx, cfg, msg, next)
+	}
+}
+
+// Handle processes msg with next, spooling it instead when a downstream
+// outage is in progress for cfg.
+func (b *InboundBuffer) Handle(ctx context.Context, cfg ChannelConfig, msg InboundMessage, next InboundHandler) error {
+	b.mu.Lock()
+	spool := b.attachLocked(cfg, next)
+	if spool.pending > 0 {
+		err := b.appendLocked(spool, msg)
+		b.startDrainLocked(spool)
+		b.mu.Unlock()
+		return err
+	}
+	b.mu.Unlock()
+
+	err := next(ctx, cfg, msg)
+	if err == nil || !b.isOutage(err) {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if appendErr := b.appendLocked(spool, msg); appendErr != nil {
+		return errors.Join(appendErr, err)
+	}
+	b.logger.Warn("downstream unavailable, buffering inbound events",
+		slog.String("config_id", cfg.ID),
+		slog.String("channel", cfg.ChannelType.String()),
+		slog.Any("error", err),
+	)
+	b.startDrainLocked(spool)
+	return nil
+}
+
+// Full reports whether the spool of configID refuses new events.
+func (b *InboundBuffer) Full(configID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	spool := b.spools[configID]
+	return spool != nil && spool.pending >= b.maxEvents
+}
+
+// IsOutage reports whether err is treated as a downstream outage.
+func (b *InboundBuffer) IsOutage(err error) bool {
+	return b.isOutage(err)
+}
+
+// Close stops draining. Spooled events stay on disk for the next run.
+func (b *InboundBuffer) Close() {
+	b.cancel()
+}
+
+func (b *InboundBuffer) attachLocked(cfg ChannelConfig, next InboundHandler) *inboundSpool {
+	spool := b.spools[cfg.ID]
+	if spool == nil {
+		name := unsafeSpoolNameChars.ReplaceAllString(cfg.ID, "_")
+		spool = &inboundSpool{path: filepath.Join(b.dir, name+".jsonl")}
+		b.spools[cfg.ID] = spool
+	}
+	spool.cfg = cfg
+	spool.next = next
+	if !spool.loaded {
+		pending, err := countSpool(spool.path)
+		if err != nil {
+			b.logger.Error("read inbound buffer failed", slog.String("path", spool.path), slog.Any("error", err))
+		}
+		spool.pending = pending
+		spool.loaded = true
+	}
+	return spool
+}
+
+func (b *InboundBuffer) appendLocked(spool *inboundSpool, msg InboundMessage) error {
+	if spool.pending >= b.maxEvents {
+		return ErrInboundBackpressure
+	}
+	line, err := json.Marshal(spooledInbound{Message: msg, BufferedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("encode buffered inbound: %w", err)
+	}
+	f, err := os.OpenFile(spool.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open inbound buffer: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write inbound buffer: %w", err)
+	}
+	spool.pending++
+	return nil
+}
+
+func (b *InboundBuffer) startDrainLocked(spool *inboundSpool) {
+	if spool.draining || spool.pending == 0 || spool.next == nil {
+		return
+	}
+	spool.draining = true
+	go b.drain(spool)
+}
+
+// drain replays the spool head until the spool is empty. An outage error
+// keeps the head and retries later; any other result (success or a message
+// failure, which the handler already dead-lettered) removes it.
+func (b *InboundBuffer) drain(spool *inboundSpool) {
+	for {
+		b.mu.Lock()
+		line, err := readSpoolHead(spool.path)
+		if err != nil || line == nil {
+			if err != nil {
+				b.logger.Error("read inbound buffer failed", slog.String("path", spool.path), slog.Any("error", err))
+			} else {
+				spool.pending = 0
+				_ = os.Remove(spool.path)
+			}
+			spool.draining = false
+			b.mu.Unlock()
+			return
+		}
+		cfg, next := spool.cfg, spool.next
+		b.mu.Unlock()
+
+		var head spooledInbound
+		if err := json.Unmarshal(line, &head); err != nil {
+			// A torn line from a crash mid-write; nothing to replay.
+			b.logger.Warn("dropping unreadable buffered inbound", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		} else if err := next(b.ctx, cfg, head.Message); err != nil {
+			if b.isOutage(err) {
+				select {
+				case <-b.ctx.Done():
+					b.mu.Lock()
+					spool.draining = false
+					b.mu.Unlock()
+					return
+				case <-time.After(b.retryInterval):
+				}
+				continue
+			}
+			b.logger.Warn("buffered inbound replay failed", slog.String("config_id", cfg.ID), slog.Any("error", err))
+		}
+
+		b.mu.Lock()
+		if err := dropSpoolHead(spool.path); err != nil {
+			b.logger.Error("trim inbound buffer failed", slog.String("path", spool.path), slog.Any("error", err))
+			spool.draining = false
+			b.mu.Unlock()
+			return
+		}
+		spool.pending = max(spool.pending-1, 0)
+		b.mu.Unlock()
+	}
+}
+
+func countSpool(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return bytes.Count(data, []byte{'\n'}), nil
+}
+
+// readSpoolHead returns the first line of the spool, or nil when it is empty.
+func readSpoolHead(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	line, _, _ := bytes.Cut(data, []byte{'\n'})
+	return line, nil
+}
+
+// dropSpoolHead removes the first line of the spool via a temp file rename,
+// so a crash leaves either the old or the new spool.
+func dropSpoolHead(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	_, rest, _ := bytes.Cut(data, []byte{'\n'})
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, rest, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
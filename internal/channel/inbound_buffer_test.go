@@ -0,0 +1,136 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// outageHandler fails with a dial error while down and records the messages
+// it processed otherwise.
+type outageHandler struct {
+	mu   sync.Mutex
+	down bool
+	seen []string
+}
+
+func (h *outageHandler) handle(_ context.Context, _ ChannelConfig, msg InboundMessage) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.down {
+		return fmt.Errorf("insert message: %w", &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded})
+	}
+	h.seen = append(h.seen, msg.Message.Text)
+	return nil
+}
+
+func (h *outageHandler) setDown(down bool) {
+	h.mu.Lock()
+	h.down = down
+	h.mu.Unlock()
+}
+
+func (h *outageHandler) processed() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.seen...)
+}
+
+func waitProcessed(t *testing.T, h *outageHandler, want []string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := h.processed()
+		if fmt.Sprint(got) == fmt.Sprint(want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("processed = %v, want %v", got, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func newTestInboundBuffer(t *testing.T, dir string, maxEvents int) *InboundBuffer {
+	t.Helper()
+	buffer, err := NewInboundBuffer(nil, InboundBufferOptions{Dir: dir, MaxEvents: maxEvents, RetryInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewInboundBuffer returned error: %v", err)
+	}
+	t.Cleanup(buffer.Close)
+	return buffer
+}
+
+func TestInboundBufferSpoolsDuringOutageAndReplaysInOrder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	buffer := newTestInboundBuffer(t, dir, 10)
+	h := &outageHandler{down: true}
+	cfg := ChannelConfig{ID: "cfg/1", ChannelType: "telegram"}
+	handler := buffer.Wrap(cfg, h.handle)
+
+	for _, text := range []string{"one", "two", "three"} {
+		if err := handler(context.Background(), cfg, InboundMessage{Message: Message{Text: text}}); err != nil {
+			t.Fatalf("buffered message %q returned error: %v", text, err)
+		}
+	}
+	if got := h.processed(); len(got) != 0 {
+		t.Fatalf("nothing should be processed during the outage, got %v", got)
+	}
+
+	h.setDown(false)
+	waitProcessed(t, h, []string{"one", "two", "three"})
+	if err := handler(context.Background(), cfg, InboundMessage{Message: Message{Text: "four"}}); err != nil {
+		t.Fatalf("unexpected error after recovery: %v", err)
+	}
+	waitProcessed(t, h, []string{"one", "two", "three", "four"})
+	if _, err := os.Stat(filepath.Join(dir, "cfg_1.jsonl")); !os.IsNotExist(err) {
+		t.Fatalf("drained spool should be removed, stat err = %v", err)
+	}
+}
+
+func TestInboundBufferSignalsBackpressureWhenFull(t *testing.T) {
+	t.Parallel()
+
+	buffer := newTestInboundBuffer(t, t.TempDir(), 2)
+	h := &outageHandler{down: true}
+	cfg := ChannelConfig{ID: "cfg-1"}
+	handler := buffer.Wrap(cfg, h.handle)
+
+	for i := range 2 {
+		if err := handler(context.Background(), cfg, InboundMessage{Message: Message{Text: fmt.Sprint(i)}}); err != nil {
+			t.Fatalf("message %d returned error: %v", i, err)
+		}
+	}
+	err := handler(context.Background(), cfg, InboundMessage{Message: Message{Text: "overflow"}})
+	if !IsInboundQueueFull(err) {
+		t.Fatalf("expected backpressure error, got %v", err)
+	}
+	if !buffer.Full(cfg.ID) {
+		t.Fatal("expected Full to report the saturated spool")
+	}
+}
+
+func TestInboundBufferResumesSpoolAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cfg := ChannelConfig{ID: "cfg-1"}
+	first := newTestInboundBuffer(t, dir, 10)
+	down := &outageHandler{down: true}
+	if err := first.Handle(context.Background(), cfg, InboundMessage{Message: Message{Text: "kept"}}, down.handle); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first.Close()
+
+	second := newTestInboundBuffer(t, dir, 10)
+	h := &outageHandler{}
+	second.Wrap(cfg, h.handle)
+	waitProcessed(t, h, []string{"kept"})
+}
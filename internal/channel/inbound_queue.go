@@ -0,0 +1,138 @@
+package channel
+
+import (
+	"strings"
+	"sync"
+)
+
+// inboundQueue holds inbound messages in one FIFO lane per conversation.
+// Workers take whole lanes from the ready list, so messages of one
+// conversation run one at a time and in order while different conversations
+// run in parallel. A lane is in the ready list only while it has messages and
+// no worker is running one of them.
+type inboundQueue struct {
+	mu        sync.Mutex
+	capacity  int
+	laneLimit int
+	lanes     map[string][]inboundTask
+	// active marks lanes whose head message a worker is processing.
+	active    map[string]bool
+	ready     chan string
+	queued    int
+	processed uint64
+	rejected  uint64
+}
+
+// InboundQueueStats is a snapshot of the inbound worker pool.
+type InboundQueueStats struct {
+	Workers  int `json:"workers"`
+	Capacity int `json:"capacity"`
+	// Queued counts messages waiting for a worker; Active counts messages
+	// being processed.
+	Queued int `json:"queued"`
+	Active int `json:"active"`
+	// Conversations counts conversations with queued or active messages.
+	Conversations int    `json:"conversations"`
+	Processed     uint64 `json:"processed"`
+	// Rejected counts messages refused with ErrInboundQueueFull.
+	Rejected uint64 `json:"rejected"`
+}
+
+func newInboundQueue(capacity, laneLimit int) *inboundQueue {
+	if laneLimit <= 0 || laneLimit > capacity {
+		laneLimit = capacity
+	}
+	return &inboundQueue{
+		capacity:  capacity,
+		laneLimit: laneLimit,
+		lanes:     map[string][]inboundTask{},
+		active:    map[string]bool{},
+		// Each ready key has at least one queued message, so the list never
+		// outgrows the queue and pushes under mu never block.
+		ready: make(chan string, capacity),
+	}
+}
+
+// push appends task to its conversation's lane. It refuses the task when the
+// queue or the lane is full, so one busy conversation cannot take every slot.
+func (q *inboundQueue) push(key string, task inboundTask) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	lane := q.lanes[key]
+	if q.queued >= q.capacity || len(lane) >= q.laneLimit {
+		q.rejected++
+		return ErrInboundQueueFull
+	}
+	q.lanes[key] = append(lane, task)
+	q.queued++
+	if len(lane) == 0 && !q.active[key] {
+		q.ready <- key
+	}
+	return nil
+}
+
+// take claims the head of a ready lane.
+func (q *inboundQueue) take(key string) (inboundTask, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	lane := q.lanes[key]
+	if len(lane) == 0 {
+		return inboundTask{}, false
+	}
+	task := lane[0]
+	lane[0] = inboundTask{}
+	if len(lane) == 1 {
+		delete(q.lanes, key)
+	} else {
+		q.lanes[key] = lane[1:]
+	}
+	q.queued--
+	q.active[key] = true
+	return task, true
+}
+
+// done releases a lane claimed by take and puts it back in the ready list
+// when more messages arrived meanwhile. Re-queueing at the back instead of
+// draining the lane keeps a busy conversation from holding a worker.
+func (q *inboundQueue) done(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.active, key)
+	q.processed++
+	if len(q.lanes[key]) > 0 {
+		q.ready <- key
+	}
+}
+
+func (q *inboundQueue) stats() InboundQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	conversations := len(q.lanes)
+	for key := range q.active {
+		if _, queued := q.lanes[key]; !queued {
+			conversations++
+		}
+	}
+	return InboundQueueStats{
+		Capacity:      q.capacity,
+		Queued:        q.queued,
+		Active:        len(q.active),
+		Conversations: conversations,
+		Processed:     q.processed,
+		Rejected:      q.rejected,
+	}
+}
+
+// inboundOrderKey identifies the conversation whose messages must be handled
+// in order. Messages without a conversation fall back to their reply route.
+func inboundOrderKey(cfg ChannelConfig, msg InboundMessage) string {
+	botID := strings.TrimSpace(msg.BotID)
+	if botID == "" {
+		botID = cfg.BotID
+	}
+	conversationID := strings.TrimSpace(msg.Conversation.ID)
+	if conversationID == "" {
+		return msg.RoutingKey()
+	}
+	return strings.Join([]string{msg.Channel.String(), botID, conversationID}, ":")
+}
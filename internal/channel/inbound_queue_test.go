@@ -0,0 +1,139 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// orderRecordingProcessor blocks each message until released and records the
+// processing order per conversation.
+type orderRecordingProcessor struct {
+	mu      sync.Mutex
+	order   map[string][]string
+	running map[string]int
+	overlap bool
+	started chan string
+	release chan struct{}
+}
+
+func (p *orderRecordingProcessor) HandleInbound(_ context.Context, _ ChannelConfig, msg InboundMessage, _ StreamReplySender) error {
+	conversation := msg.Conversation.ID
+	p.mu.Lock()
+	p.running[conversation]++
+	if p.running[conversation] > 1 {
+		p.overlap = true
+	}
+	p.order[conversation] = append(p.order[conversation], msg.Message.ID)
+	p.mu.Unlock()
+
+	p.started <- conversation
+	<-p.release
+
+	p.mu.Lock()
+	p.running[conversation]--
+	p.mu.Unlock()
+	return nil
+}
+
+func inboundQueueTestMessage(conversation, id string) InboundMessage {
+	return InboundMessage{
+		Channel:      ChannelType("test"),
+		BotID:        "bot-1",
+		Conversation: Conversation{ID: conversation, Type: ConversationTypeGroup},
+		Message:      Message{ID: id, Text: id},
+	}
+}
+
+func TestManagerInboundOrdersWithinConversation(t *testing.T) {
+	processor := &orderRecordingProcessor{
+		order:   map[string][]string{},
+		running: map[string]int{},
+		started: make(chan string, 16),
+		release: make(chan struct{}),
+	}
+	m := NewManager(nil, nil, nil, processor, WithInboundWorkers(4))
+	defer func() { _ = m.Shutdown(context.Background()) }()
+
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1"}
+	for _, msg := range []InboundMessage{
+		inboundQueueTestMessage("busy", "b1"),
+		inboundQueueTestMessage("busy", "b2"),
+		inboundQueueTestMessage("busy", "b3"),
+		inboundQueueTestMessage("quiet", "q1"),
+	} {
+		if err := m.HandleInbound(context.Background(), cfg, msg); err != nil {
+			t.Fatalf("HandleInbound(%s) = %v", msg.Message.ID, err)
+		}
+	}
+
+	// The quiet conversation starts while the busy one is still on b1.
+	started := map[string]bool{}
+	for len(started) < 2 {
+		select {
+		case conversation := <-processor.started:
+			started[conversation] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("conversations did not run in parallel, started %v", started)
+		}
+	}
+	stats := m.InboundQueueStats()
+	if stats.Active != 2 || stats.Queued != 2 || stats.Conversations != 2 || stats.Workers != 4 {
+		t.Fatalf("stats = %+v, want 2 active, 2 queued, 2 conversations, 4 workers", stats)
+	}
+
+	go func() {
+		for range 4 {
+			processor.release <- struct{}{}
+		}
+	}()
+	for range 2 {
+		select {
+		case <-processor.started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("queued messages were not processed")
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for m.InboundQueueStats().Processed < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v, want 4 processed", m.InboundQueueStats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	processor.mu.Lock()
+	defer processor.mu.Unlock()
+	if processor.overlap {
+		t.Fatal("messages of one conversation ran concurrently")
+	}
+	if got := processor.order["busy"]; len(got) != 3 || got[0] != "b1" || got[1] != "b2" || got[2] != "b3" {
+		t.Fatalf("busy order = %v, want [b1 b2 b3]", got)
+	}
+}
+
+func TestInboundQueueLimitsOneConversation(t *testing.T) {
+	t.Parallel()
+
+	q := newInboundQueue(4, 2)
+	for i := range 2 {
+		if err := q.push("busy", inboundTask{}); err != nil {
+			t.Fatalf("push %d = %v", i, err)
+		}
+	}
+	if err := q.push("busy", inboundTask{}); !errors.Is(err, ErrInboundQueueFull) {
+		t.Fatalf("push over conversation limit = %v, want ErrInboundQueueFull", err)
+	}
+	if err := q.push("quiet", inboundTask{}); err != nil {
+		t.Fatalf("other conversation refused: %v", err)
+	}
+	if stats := q.stats(); stats.Queued != 3 || stats.Rejected != 1 || stats.Conversations != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	// Only lanes go on the ready list, not individual messages.
+	if got := len(q.ready); got != 2 {
+		t.Fatalf("ready lanes = %d, want 2", got)
+	}
+}
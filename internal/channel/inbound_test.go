@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"strings"
 	"testing"
+
+	"github.com/memohai/memoh/internal/extension"
 )
 
 // mockAdapter is used for inbound handleInbound tests.
@@ -217,3 +220,112 @@ func TestManager_handleInbound(t *testing.T) {
 		}
 	})
 }
+
+type fakeInboundFailureRecorder struct {
+	msgs   []InboundMessage
+	causes []error
+}
+
+func (f *fakeInboundFailureRecorder) RecordInboundFailure(_ context.Context, _ ChannelConfig, msg InboundMessage, cause error) error {
+	f.msgs = append(f.msgs, msg)
+	f.causes = append(f.causes, cause)
+	return nil
+}
+
+func TestManagerRecordsFailedInboundAndReplays(t *testing.T) {
+	t.Parallel()
+
+	failure := errors.New("model unavailable")
+	processor := &fakeInboundProcessor{err: failure}
+	cfg := ChannelConfig{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelType("test")}
+	m := NewManager(slog.Default(), NewRegistry(), &fakeConfigStore{effectiveConfig: cfg}, processor)
+	recorder := &fakeInboundFailureRecorder{}
+	m.SetInboundFailureRecorder(recorder)
+
+	msg := InboundMessage{Channel: ChannelType("test"), BotID: "bot-1", Message: Message{Text: "hello"}}
+	if err := m.handleInbound(context.Background(), cfg, msg); !errors.Is(err, failure) {
+		t.Fatalf("expected processing error, got %v", err)
+	}
+	if len(recorder.msgs) != 1 || recorder.msgs[0].Message.Text != "hello" || !errors.Is(recorder.causes[0], failure) {
+		t.Fatalf("expected failed message to be recorded, got %+v", recorder.msgs)
+	}
+
+	if err := m.ReplayInbound(context.Background(), "bot-1", ChannelType("test"), msg); !errors.Is(err, failure) {
+		t.Fatalf("expected replay to surface the processing error, got %v", err)
+	}
+	if len(recorder.msgs) != 1 {
+		t.Fatalf("replay must not record a second failure, got %d", len(recorder.msgs))
+	}
+
+	processor.err = nil
+	if err := m.ReplayInbound(context.Background(), "bot-1", ChannelType("test"), msg); err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	if processor.gotCfg.ID != "cfg-1" {
+		t.Fatalf("replay should use the resolved config, got %+v", processor.gotCfg)
+	}
+}
+
+type redactingExtension struct{}
+
+func (redactingExtension) Name() string { return "redact" }
+
+func (redactingExtension) OnInbound(_ context.Context, msg *extension.InboundMessage) error {
+	if strings.Contains(msg.Text, "spam") {
+		return extension.Block("spam")
+	}
+	msg.Text = strings.ReplaceAll(msg.Text, "4111-1111", "[card]")
+	return nil
+}
+
+func (redactingExtension) OnOutbound(_ context.Context, msg *extension.OutboundMessage) error {
+	if strings.Contains(msg.Text, "internal") {
+		return extension.Block("leaks internals")
+	}
+	msg.Text = strings.ToUpper(msg.Text)
+	return nil
+}
+
+func TestManagerRunsExtensions(t *testing.T) {
+	t.Parallel()
+
+	processor := &fakeInboundProcessor{resp: &OutboundMessage{Target: "target-id", Message: Message{Text: "noted"}}}
+	m := NewManager(slog.Default(), NewRegistry(), &fakeConfigStore{}, processor)
+	adapter := &mockAdapter{}
+	m.RegisterAdapter(adapter)
+	m.SetExtensions(extension.NewChain(nil, redactingExtension{}))
+
+	cfg := ChannelConfig{ID: "bot-1", BotID: "bot-1", ChannelType: ChannelType("test")}
+	msg := InboundMessage{
+		Channel:     ChannelType("test"),
+		Message:     Message{Text: "my card is 4111-1111"},
+		ReplyTarget: "target-id",
+	}
+	if err := m.handleInbound(context.Background(), cfg, msg); err != nil {
+		t.Fatalf("handleInbound: %v", err)
+	}
+	if got := processor.gotMsg.Message.Text; got != "my card is [card]" {
+		t.Fatalf("processor got %q, want the rewritten text", got)
+	}
+	if len(adapter.sentMessages) != 1 || adapter.sentMessages[0].Message.PlainText() != "NOTED" {
+		t.Fatalf("sent = %+v, want the rewritten reply", adapter.sentMessages)
+	}
+
+	processor.gotMsg = InboundMessage{}
+	msg.Message.Text = "buy spam"
+	if err := m.handleInbound(context.Background(), cfg, msg); err != nil {
+		t.Fatalf("handleInbound blocked: %v", err)
+	}
+	if processor.gotMsg.Message.Text != "" {
+		t.Fatal("blocked message reached the processor")
+	}
+
+	processor.resp.Message.Text = "internal notes"
+	msg.Message.Text = "hello"
+	if err := m.handleInbound(context.Background(), cfg, msg); err != nil {
+		t.Fatalf("handleInbound: %v", err)
+	}
+	if len(adapter.sentMessages) != 1 {
+		t.Fatalf("blocked reply was sent: %+v", adapter.sentMessages)
+	}
+}
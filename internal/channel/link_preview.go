@@ -0,0 +1,73 @@
+package channel
+
+import "strings"
+
+// LinkPreviewsRoutingKey is the channel config routing option that controls
+// link previews in replies. "off" asks the platform not to expand links
+// (Telegram web page previews); "cards" sends the OpenGraph previews of
+// linked pages as link_preview parts on channels that render them (Feishu).
+// Any other value keeps the platform default.
+const LinkPreviewsRoutingKey = "link_previews"
+
+// Link preview modes.
+const (
+	LinkPreviewsOff   = "off"
+	LinkPreviewsCards = "cards"
+)
+
+// LinkPreviewMode returns the link preview mode configured for cfg, or ""
+// for the platform default.
+func LinkPreviewMode(cfg ChannelConfig) string {
+	raw, _ := cfg.Routing[LinkPreviewsRoutingKey].(string)
+	switch mode := strings.ToLower(strings.TrimSpace(raw)); mode {
+	case LinkPreviewsOff, LinkPreviewsCards:
+		return mode
+	}
+	return ""
+}
+
+// LinkPreview is the unfurled metadata of a linked page.
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+}
+
+// Part returns the preview as a link_preview message part. The title is the
+// part text; the remaining fields travel in its metadata.
+func (p LinkPreview) Part() MessagePart {
+	metadata := map[string]any{}
+	if p.Description != "" {
+		metadata["description"] = p.Description
+	}
+	if p.SiteName != "" {
+		metadata["site_name"] = p.SiteName
+	}
+	if p.ImageURL != "" {
+		metadata["image_url"] = p.ImageURL
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	return MessagePart{Type: MessagePartLinkPreview, URL: p.URL, Text: p.Title, Metadata: metadata}
+}
+
+// LinkPreviewFromPart reads a link_preview part back into a LinkPreview.
+func LinkPreviewFromPart(part MessagePart) (LinkPreview, bool) {
+	if part.Type != MessagePartLinkPreview || strings.TrimSpace(part.URL) == "" {
+		return LinkPreview{}, false
+	}
+	read := func(key string) string {
+		value, _ := part.Metadata[key].(string)
+		return strings.TrimSpace(value)
+	}
+	return LinkPreview{
+		URL:         strings.TrimSpace(part.URL),
+		Title:       strings.TrimSpace(part.Text),
+		Description: read("description"),
+		SiteName:    read("site_name"),
+		ImageURL:    read("image_url"),
+	}, true
+}
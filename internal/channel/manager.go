@@ -9,6 +9,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/memohai/memoh/internal/extension"
+	"github.com/memohai/memoh/internal/featureflags"
 )
 
 // ConfigLister lists channel configs for periodic refresh. Used by connection lifecycle.
@@ -63,11 +66,20 @@ type Manager struct {
 	service         ManagerStore
 	processor       InboundProcessor
 	attachmentStore OutboundAttachmentStore
+	failureRecorder InboundFailureRecorder
+	outbox          OutboxStore
+	inboundBuffer   *InboundBuffer
+	featureFlags    featureflags.Checker
+	extensions      *extension.Chain
+	outboundSent    *outboundDedupe
 	refreshInterval time.Duration
+	broadcastRate   float64
 	logger          *slog.Logger
 	middlewares     []Middleware
 
-	inboundQueue   chan inboundTask
+	broadcastTargets BroadcastTargetLister
+
+	inboundQueue   *inboundQueue
 	inboundWorkers int
 	inboundOnce    sync.Once
 	inboundCtx     context.Context
@@ -81,18 +93,24 @@ type Manager struct {
 // ManagerOption configures a Manager during construction.
 type ManagerOption func(*Manager)
 
-// WithInboundQueueSize sets the capacity of the inbound message queue.
-// The default is 256. Larger values trade memory for lower drop rate under burst load.
-func WithInboundQueueSize(size int) ManagerOption {
+// WithInboundQueueSize sets the capacity of the inbound message queue and how
+// many of its messages one conversation may hold. The defaults are 256 and
+// 64. Larger values trade memory for lower drop rate under burst load.
+func WithInboundQueueSize(size, perConversation int) ManagerOption {
 	return func(m *Manager) {
-		if size > 0 {
-			m.inboundQueue = make(chan inboundTask, size)
+		if size <= 0 {
+			size = m.inboundQueue.capacity
 		}
+		if perConversation <= 0 {
+			perConversation = m.inboundQueue.laneLimit
+		}
+		m.inboundQueue = newInboundQueue(size, perConversation)
 	}
 }
 
 // WithInboundWorkers sets the number of goroutines that process inbound messages
-// concurrently. The default is 4.
+// concurrently. Messages of one conversation are still processed in order,
+// one at a time. The default is 4.
 func WithInboundWorkers(n int) ManagerOption {
 	return func(m *Manager) {
 		if n > 0 {
@@ -111,6 +129,15 @@ func WithRefreshInterval(d time.Duration) ManagerOption {
 	}
 }
 
+// WithOutboundDedupeWindow sets how long identical outbound messages to the
+// same route are suppressed. Zero or a negative value disables suppression;
+// a channel config can override it with OutboundDedupeRoutingKey.
+func WithOutboundDedupeWindow(d time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.outboundSent = newOutboundDedupe(d)
+	}
+}
+
 // NewManager creates a Manager with the given logger, registry, config store, and inbound processor.
 func NewManager(log *slog.Logger, registry *Registry, service ManagerStore, processor InboundProcessor, opts ...ManagerOption) *Manager {
 	if log == nil {
@@ -123,13 +150,15 @@ func NewManager(log *slog.Logger, registry *Registry, service ManagerStore, proc
 		registry:        registry,
 		service:         service,
 		processor:       processor,
+		outboundSent:    newOutboundDedupe(defaultOutboundDedupeWindow),
 		refreshInterval: 5 * time.Minute,
+		broadcastRate:   defaultBroadcastRate,
 		connections:     map[string]*connectionEntry{},
 		connectionMeta:  map[string]ConnectionStatus{},
 		logger:          log.With(slog.String("component", "channel")),
 		middlewares:     []Middleware{},
-		inboundQueue:    make(chan inboundTask, 256),
-		inboundWorkers:  4,
+		inboundQueue:    newInboundQueue(defaultInboundQueueSize, defaultInboundConversationQueueSize),
+		inboundWorkers:  defaultInboundWorkers,
 	}
 	for _, opt := range opts {
 		opt(m)
@@ -153,6 +182,35 @@ func (m *Manager) SetAttachmentStore(store OutboundAttachmentStore) {
 	m.attachmentStore = store
 }
 
+// SetInboundFailureRecorder wires the store that keeps inbound messages whose
+// processing failed.
+func (m *Manager) SetInboundFailureRecorder(recorder InboundFailureRecorder) {
+	m.failureRecorder = recorder
+}
+
+// SetInboundBuffer wires the on-disk buffer that holds inbound events while
+// a downstream dependency is unreachable.
+func (m *Manager) SetInboundBuffer(buffer *InboundBuffer) {
+	m.inboundBuffer = buffer
+}
+
+// SetFeatureFlags wires the flags that gate risky channel behavior per bot.
+func (m *Manager) SetFeatureFlags(flags featureflags.Checker) {
+	m.featureFlags = flags
+}
+
+// SetExtensions wires the deployment's extensions, which see inbound messages
+// before the processor and outbound messages before delivery.
+func (m *Manager) SetExtensions(chain *extension.Chain) {
+	m.extensions = chain
+}
+
+// streamingEditsEnabled reports whether replies for the bot may stream as
+// in-place edits of a preview message.
+func (m *Manager) streamingEditsEnabled(ctx context.Context, botID string) bool {
+	return m.featureFlags == nil || m.featureFlags.Enabled(ctx, featureflags.StreamingEdits, botID)
+}
+
 // RegisterAdapter adds an adapter to the registry and logs the registration.
 func (m *Manager) RegisterAdapter(adapter Adapter) {
 	if adapter == nil {
@@ -210,6 +268,7 @@ func (m *Manager) Start(ctx context.Context) {
 		m.logger.Info("manager start")
 	}
 	m.startInboundWorkers(ctx)
+	m.startOutboxWorker(ctx)
 	go func() {
 		m.refresh(ctx)
 		ticker := time.NewTicker(m.refreshInterval)
@@ -230,6 +289,8 @@ func (m *Manager) Start(ctx context.Context) {
 }
 
 // Send delivers an outbound message to the specified channel, resolving target and config automatically.
+// With an outbox set, a delivery that fails on a transient platform error is
+// queued for retry and reported as sent.
 func (m *Manager) Send(ctx context.Context, botID string, channelType ChannelType, req SendRequest) error {
 	if m.service == nil {
 		return errors.New("channel manager not configured")
@@ -270,19 +331,32 @@ func (m *Manager) Send(ctx context.Context, botID string, channelType ChannelTyp
 	if m.logger != nil {
 		m.logger.Info("send outbound", slog.String("channel", channelType.String()), slog.String("bot_id", botID))
 	}
-	policy := m.resolveOutboundPolicy(channelType)
-	caps, hasCaps := m.registry.GetOutboundCapabilities(channelType, config, target)
-	outbound, err := buildOutboundMessagesWithCaps(OutboundMessage{
+	msg, ok := m.applyOutboundExtensions(ctx, config, OutboundMessage{
 		Target:  target,
 		Message: req.Message,
-	}, policy, caps, hasCaps)
+	})
+	if !ok {
+		return nil
+	}
+	key, ok := m.claimOutbound(config, target, "", msg.Message)
+	if !ok {
+		return nil
+	}
+	policy := m.resolveOutboundPolicy(channelType)
+	caps, hasCaps := m.registry.GetOutboundCapabilities(channelType, config, target)
+	outbound, err := buildOutboundMessagesWithCaps(msg, policy, caps, hasCaps)
 	if err != nil {
+		m.outboundSent.release(key)
 		return err
 	}
 	if err := m.sendAllWithConfig(ctx, sender, config, outbound, policy); err != nil {
+		m.outboundSent.release(key)
 		if m.logger != nil {
 			m.logger.Error("send outbound failed", slog.String("channel", channelType.String()), slog.String("bot_id", botID), slog.Any("error", err))
 		}
+		if m.queueOutbound(ctx, config, SendRequest{Target: target, ChannelIdentityID: req.ChannelIdentityID, Message: req.Message}, err) {
+			return nil
+		}
 		return err
 	}
 	return nil
@@ -351,6 +425,9 @@ func (m *Manager) Shutdown(ctx context.Context) error {
 	if m.inboundCancel != nil {
 		m.inboundCancel()
 	}
+	if m.inboundBuffer != nil {
+		m.inboundBuffer.Close()
+	}
 	m.stopAll(ctx)
 	return nil
 }
@@ -0,0 +1,46 @@
+package channel
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// MetadataKeyMembershipEvent is the inbound metadata key adapters set when
+	// the message reports a change of the bot's own membership in the
+	// conversation instead of carrying user content.
+	MetadataKeyMembershipEvent = "membership_event"
+
+	// MembershipEventBotRemoved reports that the bot was removed from, or left,
+	// the conversation.
+	MembershipEventBotRemoved = "bot_removed"
+	// MembershipEventBotAdded reports that the bot was added to the
+	// conversation again.
+	MembershipEventBotAdded = "bot_added"
+)
+
+// NewMembershipEvent builds the inbound message an adapter dispatches when the
+// bot's membership in a conversation changes.
+func NewMembershipEvent(cfg ChannelConfig, channelType ChannelType, event string, conv Conversation, actor Identity) InboundMessage {
+	return InboundMessage{
+		Channel:      channelType,
+		BotID:        cfg.BotID,
+		Sender:       actor,
+		Conversation: conv,
+		ReceivedAt:   time.Now().UTC(),
+		Source:       string(channelType),
+		Metadata:     map[string]any{MetadataKeyMembershipEvent: event},
+	}
+}
+
+// MembershipEvent returns the message's membership event, or "" for ordinary
+// messages.
+func MembershipEvent(msg InboundMessage) string {
+	event, _ := msg.Metadata[MetadataKeyMembershipEvent].(string)
+	switch event = strings.TrimSpace(event); event {
+	case MembershipEventBotRemoved, MembershipEventBotAdded:
+		return event
+	default:
+		return ""
+	}
+}
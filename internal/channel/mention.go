@@ -0,0 +1,13 @@
+package channel
+
+// MentionFormatter is implemented by adapters whose markdown can carry a
+// native user mention inline, so "@Name" in a reply can ping the user.
+type MentionFormatter interface {
+	// MentionID normalizes a directory entry ID or channel identity subject
+	// ID to the platform user ID, reporting false when the user cannot be
+	// mentioned.
+	MentionID(id string) (string, bool)
+	// FormatMention renders an inline markdown mention of the user with the
+	// given platform ID, labelled with label.
+	FormatMention(id, label string) string
+}
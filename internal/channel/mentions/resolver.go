@@ -0,0 +1,122 @@
+package mentions
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/identities"
+)
+
+const (
+	lookupTimeout   = 3 * time.Second
+	maxTokens       = 5
+	maxGroupMembers = 200
+	maxIdentityHits = 20
+)
+
+// IdentitySearcher searches the channel identities the bot has observed.
+type IdentitySearcher interface {
+	Search(ctx context.Context, query string, limit int) ([]identities.SearchResult, error)
+}
+
+// Resolver finds the users a reply mentions among the conversation members
+// listed by the adapter directory and the channel identities seen before.
+type Resolver struct {
+	registry   *channel.Registry
+	identities IdentitySearcher
+	logger     *slog.Logger
+}
+
+// NewResolver creates a mention resolver. identities may be nil.
+func NewResolver(log *slog.Logger, registry *channel.Registry, identities IdentitySearcher) *Resolver {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Resolver{
+		registry:   registry,
+		identities: identities,
+		logger:     log.With(slog.String("service", "mentions")),
+	}
+}
+
+// Resolve rewrites the @mentions in a markdown or plain reply to platform
+// mentions. Replies built from rich parts are returned unchanged; those
+// carry explicit mention parts. Lookup failures leave mentions as text.
+func (r *Resolver) Resolve(ctx context.Context, cfg channel.ChannelConfig, conversation channel.Conversation, msg channel.Message) channel.Message {
+	if r == nil || r.registry == nil || len(msg.Parts) > 0 || strings.TrimSpace(msg.Text) == "" {
+		return msg
+	}
+	formatter, ok := r.registry.MentionFormatter(cfg.ChannelType)
+	if !ok {
+		return msg
+	}
+	tokens := Tokens(msg.Text, maxTokens)
+	if len(tokens) == 0 {
+		return msg
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	candidates := r.candidates(lookupCtx, cfg, conversation, tokens, formatter)
+	text, replaced := Rewrite(msg.Text, candidates, formatter)
+	if !replaced {
+		return msg
+	}
+	msg.Text = text
+	msg.Format = channel.MessageFormatMarkdown
+	return msg
+}
+
+func (r *Resolver) candidates(ctx context.Context, cfg channel.ChannelConfig, conversation channel.Conversation, tokens []string, formatter channel.MentionFormatter) []Candidate {
+	var candidates []Candidate
+	seen := map[string]struct{}{}
+	add := func(rawID, name, handle string) {
+		id, ok := formatter.MentionID(rawID)
+		if !ok {
+			return
+		}
+		if _, dup := seen[id]; dup {
+			return
+		}
+		seen[id] = struct{}{}
+		candidates = append(candidates, Candidate{ID: id, Name: name, Handle: handle})
+	}
+	if conversation.Type != channel.ConversationTypePrivate && strings.TrimSpace(conversation.ID) != "" {
+		if directory, ok := r.registry.DirectoryAdapter(cfg.ChannelType); ok {
+			members, err := directory.ListGroupMembers(ctx, cfg, strings.TrimSpace(conversation.ID), channel.DirectoryQuery{Limit: maxGroupMembers})
+			if err != nil {
+				r.logger.Debug("list group members failed",
+					slog.String("channel", cfg.ChannelType.String()),
+					slog.String("conversation_id", conversation.ID),
+					slog.Any("error", err),
+				)
+			}
+			for _, member := range members {
+				if member.Kind != "" && member.Kind != channel.DirectoryEntryUser {
+					continue
+				}
+				add(member.ID, member.Name, member.Handle)
+			}
+		}
+	}
+	if r.identities == nil {
+		return candidates
+	}
+	for _, token := range tokens {
+		results, err := r.identities.Search(ctx, token, maxIdentityHits)
+		if err != nil {
+			r.logger.Debug("search channel identities failed", slog.String("query", token), slog.Any("error", err))
+			continue
+		}
+		for _, result := range results {
+			if !strings.EqualFold(result.Channel, cfg.ChannelType.String()) {
+				continue
+			}
+			handle, _ := result.Metadata["username"].(string)
+			add(result.ChannelSubjectID, result.DisplayName, handle)
+		}
+	}
+	return candidates
+}
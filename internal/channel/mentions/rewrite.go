@@ -0,0 +1,166 @@
+// Package mentions turns "@Name" in bot replies into platform mentions, so
+// addressing a group member actually notifies them.
+package mentions
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Candidate is a user an @mention may resolve to. ID is the platform user
+// ID, as normalized by the adapter's MentionFormatter.
+type Candidate struct {
+	ID     string
+	Name   string
+	Handle string
+}
+
+// Tokens returns the distinct words following an @ in text, outside code,
+// in order of appearance. They are the search terms for candidates.
+func Tokens(text string, limit int) []string {
+	var tokens []string
+	seen := map[string]struct{}{}
+	scanMentions(text, func(at int) bool {
+		rest := text[at+1:]
+		end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		token := rest[:end]
+		if token == "" {
+			return true
+		}
+		key := strings.ToLower(token)
+		if _, dup := seen[key]; !dup {
+			seen[key] = struct{}{}
+			tokens = append(tokens, token)
+		}
+		return limit <= 0 || len(tokens) < limit
+	})
+	return tokens
+}
+
+// Rewrite replaces each "@Name" in text that matches exactly one candidate
+// by name or handle with the formatter's native mention. The longest
+// matching name wins, so "@Ann Lee" is preferred over "@Ann". It reports
+// whether anything was replaced.
+func Rewrite(text string, candidates []Candidate, formatter channel.MentionFormatter) (string, bool) {
+	if formatter == nil || len(candidates) == 0 {
+		return text, false
+	}
+	labels := indexLabels(candidates)
+	var (
+		b        strings.Builder
+		last     int
+		replaced bool
+	)
+	scanMentions(text, func(at int) bool {
+		if at < last {
+			return true
+		}
+		rest := text[at+1:]
+		for _, label := range labels {
+			if len(rest) < len(label.text) || !strings.EqualFold(rest[:len(label.text)], label.text) {
+				continue
+			}
+			if next, _ := utf8.DecodeRuneInString(rest[len(label.text):]); isWordRune(next) {
+				continue
+			}
+			if label.ambiguous {
+				return true
+			}
+			end := at + 1 + len(label.text)
+			b.WriteString(text[last:at])
+			b.WriteString(formatter.FormatMention(label.id, text[at:end]))
+			last = end
+			replaced = true
+			return true
+		}
+		return true
+	})
+	if !replaced {
+		return text, false
+	}
+	b.WriteString(text[last:])
+	return b.String(), true
+}
+
+type label struct {
+	text      string
+	id        string
+	ambiguous bool
+}
+
+// indexLabels lists the names and handles of candidates, longest first.
+// A label shared by different users is kept but marked ambiguous so it
+// still shadows shorter labels.
+func indexLabels(candidates []Candidate) []label {
+	byKey := map[string]*label{}
+	var order []string
+	for _, candidate := range candidates {
+		id := strings.TrimSpace(candidate.ID)
+		if id == "" {
+			continue
+		}
+		for _, text := range []string{candidate.Name, strings.TrimPrefix(candidate.Handle, "@")} {
+			text = strings.Join(strings.Fields(text), " ")
+			if text == "" {
+				continue
+			}
+			key := strings.ToLower(text)
+			if existing, ok := byKey[key]; ok {
+				if existing.id != id {
+					existing.ambiguous = true
+				}
+				continue
+			}
+			byKey[key] = &label{text: text, id: id}
+			order = append(order, key)
+		}
+	}
+	labels := make([]label, 0, len(order))
+	for _, key := range order {
+		labels = append(labels, *byKey[key])
+	}
+	sort.SliceStable(labels, func(i, j int) bool { return len(labels[i].text) > len(labels[j].text) })
+	return labels
+}
+
+// scanMentions calls visit with the byte offset of every @ that starts a
+// mention: not inside a code span or fence and not preceded by a word
+// character, which rules out email addresses. Scanning stops when visit
+// returns false.
+func scanMentions(text string, visit func(at int) bool) {
+	codeFence := 0
+	prev := rune(-1)
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r == '`' {
+			run := len(text[i:]) - len(strings.TrimLeft(text[i:], "`"))
+			switch codeFence {
+			case 0:
+				codeFence = run
+			case run:
+				codeFence = 0
+			}
+			i += run
+			prev = '`'
+			continue
+		}
+		if r == '@' && codeFence == 0 && !isWordRune(prev) {
+			if !visit(i) {
+				return
+			}
+		}
+		prev = r
+		i += size
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,58 @@
+package mentions
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testFormatter struct{}
+
+func (testFormatter) MentionID(id string) (string, bool) {
+	id = strings.TrimPrefix(strings.TrimSpace(id), "uid:")
+	return id, id != ""
+}
+
+func (testFormatter) FormatMention(id, label string) string {
+	return "<" + id + "|" + label + ">"
+}
+
+func TestTokensSkipsCodeAndEmail(t *testing.T) {
+	t.Parallel()
+
+	got := Tokens("Hi @Alice and @bob_1, mail me@example.com, not `@code`, again @alice", 0)
+	want := []string{"Alice", "bob_1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tokens = %v, want %v", got, want)
+	}
+}
+
+func TestRewritePrefersLongestName(t *testing.T) {
+	t.Parallel()
+
+	candidates := []Candidate{
+		{ID: "1", Name: "Ann"},
+		{ID: "2", Name: "Ann Lee", Handle: "annlee"},
+	}
+	got, ok := Rewrite("@ann Lee, ask @Ann. Ping @annlee too; @Anna stays.", candidates, testFormatter{})
+	if !ok {
+		t.Fatal("expected a rewrite")
+	}
+	want := "<2|@ann Lee>, ask <1|@Ann>. Ping <2|@annlee> too; @Anna stays."
+	if got != want {
+		t.Fatalf("Rewrite = %q, want %q", got, want)
+	}
+}
+
+func TestRewriteSkipsAmbiguousNames(t *testing.T) {
+	t.Parallel()
+
+	candidates := []Candidate{
+		{ID: "1", Name: "Sam"},
+		{ID: "2", Name: "sam"},
+	}
+	got, ok := Rewrite("thanks @Sam", candidates, testFormatter{})
+	if ok || got != "thanks @Sam" {
+		t.Fatalf("Rewrite = %q, %v; want text unchanged", got, ok)
+	}
+}
@@ -0,0 +1,39 @@
+package mentions
+
+import (
+	"context"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// mentionStream resolves the @mentions of the final reply of an IM stream.
+// Streamed deltas are previews and keep their mentions as text.
+type mentionStream struct {
+	primary      channel.OutboundStream
+	resolver     *Resolver
+	cfg          channel.ChannelConfig
+	conversation channel.Conversation
+}
+
+// NewStream wraps primary so the final message has its mentions resolved
+// for the given conversation. It returns primary unchanged when resolver is
+// nil.
+func NewStream(primary channel.OutboundStream, resolver *Resolver, cfg channel.ChannelConfig, conversation channel.Conversation) channel.OutboundStream {
+	if primary == nil || resolver == nil {
+		return primary
+	}
+	return &mentionStream{primary: primary, resolver: resolver, cfg: cfg, conversation: conversation}
+}
+
+func (s *mentionStream) Push(ctx context.Context, event channel.StreamEvent) error {
+	if event.Type == channel.StreamEventFinal && event.Final != nil {
+		event.Final = &channel.StreamFinalizePayload{
+			Message: s.resolver.Resolve(ctx, s.cfg, s.conversation, event.Final.Message),
+		}
+	}
+	return s.primary.Push(ctx, event)
+}
+
+func (s *mentionStream) Close(ctx context.Context) error {
+	return s.primary.Close(ctx)
+}
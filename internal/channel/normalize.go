@@ -20,6 +20,8 @@ func InferAttachmentType(currentType AttachmentType, mime, name string) Attachme
 		return AttachmentVoice
 	case string(AttachmentVideo):
 		return AttachmentVideo
+	case string(AttachmentSticker):
+		return AttachmentSticker
 	case string(AttachmentFile):
 		// keep inferring below for better classification
 	default:
@@ -34,6 +34,7 @@ func NewTeeStream(primary OutboundStream, observer StreamObserver, botID string,
 }
 
 func (t *teeStream) Push(ctx context.Context, event StreamEvent) error {
+	event = redactStreamEvent(event)
 	err := t.primary.Push(ctx, event)
 	// Notify observer regardless of push error — the event was produced and
 	// should still be visible in monitoring/WebUI even if the primary channel
@@ -2,6 +2,7 @@ package channel
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -148,3 +149,21 @@ func TestTeeStream_MultipleEvents(t *testing.T) {
 		}
 	}
 }
+
+func TestTeeStream_PushRedactsErrors(t *testing.T) {
+	primary := &stubStream{}
+	obs := &recordingObserver{}
+	stream := NewTeeStream(primary, obs, "bot1", "telegram")
+
+	const key = "sk-proj-abcdefghijklmnopqrstuv"
+	event := StreamEvent{Type: StreamEventError, Error: "provider returned 401: invalid api key " + key}
+	if err := stream.Push(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := primary.events[0].Error; strings.Contains(got, key) || !strings.Contains(got, "401") {
+		t.Fatalf("primary error = %q", got)
+	}
+	if got := obs.recorded()[0].Event.Error; strings.Contains(got, key) {
+		t.Fatalf("observer error = %q", got)
+	}
+}
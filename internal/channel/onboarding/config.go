@@ -0,0 +1,109 @@
+package onboarding
+
+import (
+	"strings"
+)
+
+// RoutingKey is the channel config routing entry that configures
+// onboarding. Its value is an object:
+//
+//	{
+//	  "enabled": true,
+//	  "welcome": ["Hi {name}!", "I can answer questions about your orders."],
+//	  "bind_welcome": ["Welcome back {name}, your account is now linked."],
+//	  "collect": ["name", "timezone", "language"]
+//	}
+//
+// bind_welcome defaults to welcome. collect lists the profile fields asked
+// for after the welcome, in order.
+const RoutingKey = "onboarding"
+
+// Profile fields the flow can collect.
+const (
+	FieldName     = "name"
+	FieldTimezone = "timezone"
+	FieldLanguage = "language"
+)
+
+// maxWelcomeMessages bounds the welcome sequence so a misconfigured list
+// cannot flood a new contact.
+const maxWelcomeMessages = 5
+
+// Config is the onboarding setup of one channel config.
+type Config struct {
+	Enabled     bool
+	Welcome     []string
+	BindWelcome []string
+	Collect     []string
+}
+
+// ParseConfig reads the onboarding entry of a channel config's routing map.
+// A missing or malformed entry yields a disabled config.
+func ParseConfig(routing map[string]any) Config {
+	raw, ok := routing[RoutingKey].(map[string]any)
+	if !ok {
+		return Config{}
+	}
+	cfg := Config{
+		Enabled:     readBool(raw["enabled"]),
+		Welcome:     readMessages(raw["welcome"]),
+		BindWelcome: readMessages(raw["bind_welcome"]),
+	}
+	if len(cfg.BindWelcome) == 0 {
+		cfg.BindWelcome = cfg.Welcome
+	}
+	seen := map[string]bool{}
+	for _, field := range readStrings(raw["collect"]) {
+		field = strings.ToLower(field)
+		switch field {
+		case FieldName, FieldTimezone, FieldLanguage:
+			if !seen[field] {
+				seen[field] = true
+				cfg.Collect = append(cfg.Collect, field)
+			}
+		}
+	}
+	return cfg
+}
+
+func readBool(v any) bool {
+	switch value := v.(type) {
+	case bool:
+		return value
+	case string:
+		return strings.EqualFold(strings.TrimSpace(value), "true")
+	}
+	return false
+}
+
+func readMessages(v any) []string {
+	messages := readStrings(v)
+	if len(messages) > maxWelcomeMessages {
+		messages = messages[:maxWelcomeMessages]
+	}
+	return messages
+}
+
+// readStrings accepts a single string or a list of strings and drops blanks.
+func readStrings(v any) []string {
+	var values []string
+	switch value := v.(type) {
+	case string:
+		values = []string{value}
+	case []string:
+		values = value
+	case []any:
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	out := make([]string, 0, len(values))
+	for _, s := range values {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,405 @@
+// Package onboarding greets contacts who message a bot privately for the
+// first time or link their account, and optionally collects a short profile
+// (name, timezone, language) from them. Profiles are stored per bot and
+// channel identity; they also keep the timezone a platform reports for the
+// contact when the contact did not give one.
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	statusCollecting = "collecting"
+	statusCompleted  = "completed"
+
+	timezoneSourceUser = "user"
+
+	maxNameRunes = 64
+	skipAnswer   = "skip"
+)
+
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+type profileQueries interface {
+	GetBotContactProfile(ctx context.Context, arg sqlc.GetBotContactProfileParams) (sqlc.BotContactProfile, error)
+	CreateBotContactProfile(ctx context.Context, arg sqlc.CreateBotContactProfileParams) (sqlc.BotContactProfile, error)
+	UpdateBotContactProfile(ctx context.Context, arg sqlc.UpdateBotContactProfileParams) (sqlc.BotContactProfile, error)
+	UpsertBotContactPlatformTimezone(ctx context.Context, arg sqlc.UpsertBotContactPlatformTimezoneParams) error
+}
+
+// Contact identifies who is messaging the bot.
+type Contact struct {
+	BotID             string
+	ChannelIdentityID string
+	// UserID is the linked account, empty while the identity is unbound.
+	UserID      string
+	DisplayName string
+}
+
+// Reply is what the caller should send back. Welcome messages are already
+// rendered; the prompt fields name a profile field and are localized by the
+// caller.
+type Reply struct {
+	Messages []string
+	// Invalid names the field whose answer was rejected.
+	Invalid string
+	// Ask names the field to prompt for next.
+	Ask string
+	// Completed reports that profile collection just finished.
+	Completed bool
+	// Handled reports that the inbound text was consumed by onboarding and
+	// must not reach the conversation.
+	Handled bool
+}
+
+// Empty reports whether there is nothing to send.
+func (r Reply) Empty() bool {
+	return len(r.Messages) == 0 && r.Invalid == "" && r.Ask == "" && !r.Completed
+}
+
+// Service runs the onboarding flow.
+type Service struct {
+	queries dbstore.Queries
+	logger  *slog.Logger
+
+	// observed remembers the last platform timezone stored per contact so
+	// repeated messages do not rewrite it.
+	observed sync.Map
+}
+
+// NewService creates an onboarding service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "onboarding")),
+	}
+}
+
+// Greet handles a private message from contact. The first message of a new
+// contact gets the welcome sequence; while profile collection is running,
+// text is taken as the answer to the pending question; a contact whose
+// identity got linked to an account since the last message gets the bind
+// welcome.
+func (s *Service) Greet(ctx context.Context, cfg Config, contact Contact, text string) (Reply, error) {
+	if !cfg.Enabled {
+		return Reply{}, nil
+	}
+	store, err := s.store()
+	if err != nil {
+		return Reply{}, err
+	}
+	profile, isNew, err := s.ensureProfile(ctx, store, cfg, contact)
+	if err != nil {
+		return Reply{}, err
+	}
+	if isNew {
+		return firstContactReply(cfg.Welcome, profile, contact), nil
+	}
+	if profile.OnboardingStatus == statusCollecting && len(profile.PendingFields) > 0 {
+		return s.answer(ctx, store, profile, text)
+	}
+	return s.checkBind(ctx, store, cfg, profile, contact)
+}
+
+// Bound handles a contact that just linked their identity to an account.
+// The bind welcome is sent once per linked account.
+func (s *Service) Bound(ctx context.Context, cfg Config, contact Contact) (Reply, error) {
+	if !cfg.Enabled || strings.TrimSpace(contact.UserID) == "" {
+		return Reply{}, nil
+	}
+	store, err := s.store()
+	if err != nil {
+		return Reply{}, err
+	}
+	profile, isNew, err := s.ensureProfile(ctx, store, cfg, contact)
+	if err != nil {
+		return Reply{}, err
+	}
+	if isNew {
+		return firstContactReply(cfg.BindWelcome, profile, contact), nil
+	}
+	return s.checkBind(ctx, store, cfg, profile, contact)
+}
+
+// ContactTimezone returns the IANA timezone stored for a contact, or "" when
+// none is known.
+func (s *Service) ContactTimezone(ctx context.Context, botID, channelIdentityID string) (string, error) {
+	store, err := s.store()
+	if err != nil {
+		return "", err
+	}
+	pgBotID, pgIdentityID, err := parseContactIDs(botID, channelIdentityID)
+	if err != nil {
+		return "", err
+	}
+	row, err := store.GetBotContactProfile(ctx, sqlc.GetBotContactProfileParams{
+		BotID:             pgBotID,
+		ChannelIdentityID: pgIdentityID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get contact profile: %w", err)
+	}
+	return strings.TrimSpace(row.Timezone), nil
+}
+
+// ObservePlatformTimezone stores the timezone a platform reports for a
+// contact. It never replaces a timezone the contact gave during onboarding.
+func (s *Service) ObservePlatformTimezone(ctx context.Context, botID, channelIdentityID, tz string) error {
+	tz, ok := normalizeAnswer(FieldTimezone, strings.TrimSpace(tz))
+	if !ok {
+		return nil
+	}
+	key := strings.TrimSpace(botID) + "|" + strings.TrimSpace(channelIdentityID)
+	if last, ok := s.observed.Load(key); ok && last == tz {
+		return nil
+	}
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, pgIdentityID, err := parseContactIDs(botID, channelIdentityID)
+	if err != nil {
+		return err
+	}
+	if err := store.UpsertBotContactPlatformTimezone(ctx, sqlc.UpsertBotContactPlatformTimezoneParams{
+		BotID:             pgBotID,
+		ChannelIdentityID: pgIdentityID,
+		Timezone:          tz,
+	}); err != nil {
+		return fmt.Errorf("store platform timezone: %w", err)
+	}
+	s.observed.Store(key, tz)
+	return nil
+}
+
+// ensureProfile returns the contact's profile, creating it on first contact.
+// isNew is true only for the caller that created it.
+func (s *Service) ensureProfile(ctx context.Context, store profileQueries, cfg Config, contact Contact) (sqlc.BotContactProfile, bool, error) {
+	pgBotID, pgIdentityID, err := parseContactIDs(contact.BotID, contact.ChannelIdentityID)
+	if err != nil {
+		return sqlc.BotContactProfile{}, false, err
+	}
+	status := statusCompleted
+	if len(cfg.Collect) > 0 {
+		status = statusCollecting
+	}
+	linkedUserID, err := parseOptionalUUID(contact.UserID)
+	if err != nil {
+		return sqlc.BotContactProfile{}, false, fmt.Errorf("invalid user id: %w", err)
+	}
+	row, err := store.CreateBotContactProfile(ctx, sqlc.CreateBotContactProfileParams{
+		BotID:             pgBotID,
+		ChannelIdentityID: pgIdentityID,
+		DisplayName:       strings.TrimSpace(contact.DisplayName),
+		OnboardingStatus:  status,
+		PendingFields:     append([]string{}, cfg.Collect...),
+		LinkedUserID:      linkedUserID,
+	})
+	if err == nil {
+		return row, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return sqlc.BotContactProfile{}, false, fmt.Errorf("create contact profile: %w", err)
+	}
+	row, err = store.GetBotContactProfile(ctx, sqlc.GetBotContactProfileParams{
+		BotID:             pgBotID,
+		ChannelIdentityID: pgIdentityID,
+	})
+	if err != nil {
+		return sqlc.BotContactProfile{}, false, fmt.Errorf("get contact profile: %w", err)
+	}
+	return row, false, nil
+}
+
+// answer records text as the answer to the first pending field. "skip"
+// leaves the field empty.
+func (s *Service) answer(ctx context.Context, store profileQueries, profile sqlc.BotContactProfile, text string) (Reply, error) {
+	field := profile.PendingFields[0]
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Reply{Ask: field, Handled: true}, nil
+	}
+	if !strings.EqualFold(text, skipAnswer) {
+		value, ok := normalizeAnswer(field, text)
+		if !ok {
+			return Reply{Invalid: field, Ask: field, Handled: true}, nil
+		}
+		switch field {
+		case FieldName:
+			profile.DisplayName = value
+		case FieldTimezone:
+			profile.Timezone = value
+			profile.TimezoneSource = timezoneSourceUser
+		case FieldLanguage:
+			profile.Language = value
+		}
+	}
+	profile.PendingFields = profile.PendingFields[1:]
+	reply := Reply{Handled: true}
+	if len(profile.PendingFields) == 0 {
+		profile.OnboardingStatus = statusCompleted
+		reply.Completed = true
+	} else {
+		reply.Ask = profile.PendingFields[0]
+	}
+	if _, err := store.UpdateBotContactProfile(ctx, updateParams(profile)); err != nil {
+		return Reply{}, fmt.Errorf("update contact profile: %w", err)
+	}
+	return reply, nil
+}
+
+// checkBind sends the bind welcome when the contact's identity is linked to
+// an account the profile has not seen yet.
+func (s *Service) checkBind(ctx context.Context, store profileQueries, cfg Config, profile sqlc.BotContactProfile, contact Contact) (Reply, error) {
+	userID := strings.TrimSpace(contact.UserID)
+	if userID == "" || (profile.LinkedUserID.Valid && profile.LinkedUserID.String() == userID) {
+		return Reply{}, nil
+	}
+	linkedUserID, err := db.ParseUUID(userID)
+	if err != nil {
+		return Reply{}, fmt.Errorf("invalid user id: %w", err)
+	}
+	profile.LinkedUserID = linkedUserID
+	if _, err := store.UpdateBotContactProfile(ctx, updateParams(profile)); err != nil {
+		return Reply{}, fmt.Errorf("update contact profile: %w", err)
+	}
+	s.logger.Info("contact linked; sending bind welcome",
+		slog.String("bot_id", contact.BotID),
+		slog.String("channel_identity_id", contact.ChannelIdentityID))
+	return Reply{Messages: renderMessages(cfg.BindWelcome, displayName(profile, contact))}, nil
+}
+
+func (s *Service) store() (profileQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("onboarding service not configured")
+	}
+	store, ok := s.queries.(profileQueries)
+	if !ok {
+		return nil, errors.New("contact profile queries not supported by store")
+	}
+	return store, nil
+}
+
+// firstContactReply greets a new contact and, when profile collection is
+// configured, asks the first question. The triggering message is then
+// consumed so the conversation starts with the answers.
+func firstContactReply(welcome []string, profile sqlc.BotContactProfile, contact Contact) Reply {
+	reply := Reply{Messages: renderMessages(welcome, displayName(profile, contact))}
+	if len(profile.PendingFields) > 0 {
+		reply.Ask = profile.PendingFields[0]
+		reply.Handled = true
+	}
+	return reply
+}
+
+// normalizeAnswer validates an answer and returns its stored form.
+func normalizeAnswer(field, text string) (string, bool) {
+	switch field {
+	case FieldName:
+		if utf8.RuneCountInString(text) > maxNameRunes {
+			return "", false
+		}
+		return text, true
+	case FieldTimezone:
+		if strings.EqualFold(text, "local") {
+			return "", false
+		}
+		loc, err := time.LoadLocation(text)
+		if err != nil {
+			return "", false
+		}
+		return loc.String(), true
+	case FieldLanguage:
+		tag := strings.ReplaceAll(text, "_", "-")
+		if !languageTagPattern.MatchString(tag) {
+			return "", false
+		}
+		parts := strings.Split(tag, "-")
+		parts[0] = strings.ToLower(parts[0])
+		for i := 1; i < len(parts); i++ {
+			if len(parts[i]) == 2 {
+				parts[i] = strings.ToUpper(parts[i])
+			}
+		}
+		return strings.Join(parts, "-"), true
+	}
+	return "", false
+}
+
+func renderMessages(templates []string, name string) []string {
+	if len(templates) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(templates))
+	for _, tpl := range templates {
+		if name == "" {
+			// "Hi {name}!" reads "Hi!" for contacts without a name.
+			tpl = strings.ReplaceAll(tpl, " {name}", "")
+		}
+		out = append(out, strings.ReplaceAll(tpl, "{name}", name))
+	}
+	return out
+}
+
+func displayName(profile sqlc.BotContactProfile, contact Contact) string {
+	if name := strings.TrimSpace(profile.DisplayName); name != "" {
+		return name
+	}
+	return strings.TrimSpace(contact.DisplayName)
+}
+
+func updateParams(profile sqlc.BotContactProfile) sqlc.UpdateBotContactProfileParams {
+	return sqlc.UpdateBotContactProfileParams{
+		DisplayName:       profile.DisplayName,
+		Timezone:          profile.Timezone,
+		Language:          profile.Language,
+		TimezoneSource:    profile.TimezoneSource,
+		OnboardingStatus:  profile.OnboardingStatus,
+		PendingFields:     slices.Clone(profile.PendingFields),
+		LinkedUserID:      profile.LinkedUserID,
+		BotID:             profile.BotID,
+		ChannelIdentityID: profile.ChannelIdentityID,
+	}
+}
+
+func parseContactIDs(botID, channelIdentityID string) (pgBotID, pgIdentityID pgtype.UUID, err error) {
+	pgBotID, err = db.ParseUUID(botID)
+	if err != nil {
+		return pgBotID, pgIdentityID, fmt.Errorf("invalid bot id: %w", err)
+	}
+	pgIdentityID, err = db.ParseUUID(channelIdentityID)
+	if err != nil {
+		return pgBotID, pgIdentityID, fmt.Errorf("invalid channel identity id: %w", err)
+	}
+	return pgBotID, pgIdentityID, nil
+}
+
+func parseOptionalUUID(raw string) (pgtype.UUID, error) {
+	if strings.TrimSpace(raw) == "" {
+		return pgtype.UUID{}, nil
+	}
+	return db.ParseUUID(raw)
+}
@@ -0,0 +1,277 @@
+package onboarding
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	testBot      = "11111111-1111-1111-1111-111111111111"
+	testIdentity = "22222222-2222-2222-2222-222222222222"
+	testUser     = "33333333-3333-3333-3333-333333333333"
+)
+
+type fakeProfileQueries struct {
+	dbstore.Queries
+
+	profiles map[string]sqlc.BotContactProfile
+	upserts  int
+}
+
+func newFakeProfileQueries() *fakeProfileQueries {
+	return &fakeProfileQueries{profiles: map[string]sqlc.BotContactProfile{}}
+}
+
+func (f *fakeProfileQueries) GetBotContactProfile(_ context.Context, arg sqlc.GetBotContactProfileParams) (sqlc.BotContactProfile, error) {
+	row, ok := f.profiles[arg.BotID.String()+"|"+arg.ChannelIdentityID.String()]
+	if !ok {
+		return sqlc.BotContactProfile{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeProfileQueries) CreateBotContactProfile(_ context.Context, arg sqlc.CreateBotContactProfileParams) (sqlc.BotContactProfile, error) {
+	key := arg.BotID.String() + "|" + arg.ChannelIdentityID.String()
+	row, ok := f.profiles[key]
+	if ok && row.OnboardingStatus != "pending" {
+		return sqlc.BotContactProfile{}, pgx.ErrNoRows
+	}
+	row.BotID = arg.BotID
+	row.ChannelIdentityID = arg.ChannelIdentityID
+	row.DisplayName = arg.DisplayName
+	row.OnboardingStatus = arg.OnboardingStatus
+	row.PendingFields = arg.PendingFields
+	row.LinkedUserID = arg.LinkedUserID
+	f.profiles[key] = row
+	return row, nil
+}
+
+func (f *fakeProfileQueries) UpdateBotContactProfile(_ context.Context, arg sqlc.UpdateBotContactProfileParams) (sqlc.BotContactProfile, error) {
+	key := arg.BotID.String() + "|" + arg.ChannelIdentityID.String()
+	row, ok := f.profiles[key]
+	if !ok {
+		return sqlc.BotContactProfile{}, pgx.ErrNoRows
+	}
+	row.DisplayName = arg.DisplayName
+	row.Timezone = arg.Timezone
+	row.Language = arg.Language
+	row.TimezoneSource = arg.TimezoneSource
+	row.OnboardingStatus = arg.OnboardingStatus
+	row.PendingFields = arg.PendingFields
+	row.LinkedUserID = arg.LinkedUserID
+	f.profiles[key] = row
+	return row, nil
+}
+
+func (f *fakeProfileQueries) UpsertBotContactPlatformTimezone(_ context.Context, arg sqlc.UpsertBotContactPlatformTimezoneParams) error {
+	f.upserts++
+	key := arg.BotID.String() + "|" + arg.ChannelIdentityID.String()
+	row, ok := f.profiles[key]
+	if !ok {
+		row = sqlc.BotContactProfile{BotID: arg.BotID, ChannelIdentityID: arg.ChannelIdentityID, OnboardingStatus: "pending"}
+	}
+	if row.TimezoneSource == timezoneSourceUser {
+		return nil
+	}
+	row.Timezone = arg.Timezone
+	row.TimezoneSource = "platform"
+	f.profiles[key] = row
+	return nil
+}
+
+func (f *fakeProfileQueries) profile() sqlc.BotContactProfile {
+	return f.profiles[testBot+"|"+testIdentity]
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := ParseConfig(map[string]any{
+		RoutingKey: map[string]any{
+			"enabled": true,
+			"welcome": []any{"Hi {name}!", " ", "Ask me anything."},
+			"collect": []any{"Timezone", "name", "age", "timezone"},
+		},
+	})
+	if !cfg.Enabled {
+		t.Fatal("expected onboarding enabled")
+	}
+	if want := []string{"Hi {name}!", "Ask me anything."}; !reflect.DeepEqual(cfg.Welcome, want) || !reflect.DeepEqual(cfg.BindWelcome, want) {
+		t.Fatalf("welcome = %q, bind welcome = %q", cfg.Welcome, cfg.BindWelcome)
+	}
+	if want := []string{FieldTimezone, FieldName}; !reflect.DeepEqual(cfg.Collect, want) {
+		t.Fatalf("collect = %q, want %q", cfg.Collect, want)
+	}
+	if ParseConfig(map[string]any{RoutingKey: "yes"}).Enabled {
+		t.Fatal("malformed entry should disable onboarding")
+	}
+}
+
+func TestGreetWelcomesNewContactOnce(t *testing.T) {
+	t.Parallel()
+
+	queries := newFakeProfileQueries()
+	svc := NewService(nil, queries)
+	cfg := Config{Enabled: true, Welcome: []string{"Hi {name}!", "I track your orders."}}
+	contact := Contact{BotID: testBot, ChannelIdentityID: testIdentity, DisplayName: "Ada"}
+
+	reply, err := svc.Greet(context.Background(), cfg, contact, "hello")
+	if err != nil {
+		t.Fatalf("Greet: %v", err)
+	}
+	if want := []string{"Hi Ada!", "I track your orders."}; !reflect.DeepEqual(reply.Messages, want) || reply.Handled {
+		t.Fatalf("first reply = %+v", reply)
+	}
+	reply, err = svc.Greet(context.Background(), cfg, contact, "hello again")
+	if err != nil || !reply.Empty() || reply.Handled {
+		t.Fatalf("second reply = %+v, %v", reply, err)
+	}
+
+	unnamed := Contact{BotID: testBot, ChannelIdentityID: "44444444-4444-4444-4444-444444444444"}
+	reply, _ = svc.Greet(context.Background(), cfg, unnamed, "hello")
+	if len(reply.Messages) == 0 || reply.Messages[0] != "Hi!" {
+		t.Fatalf("unnamed reply = %+v", reply)
+	}
+}
+
+func TestGreetCollectsProfile(t *testing.T) {
+	t.Parallel()
+
+	queries := newFakeProfileQueries()
+	svc := NewService(nil, queries)
+	cfg := Config{
+		Enabled: true,
+		Welcome: []string{"Welcome!"},
+		Collect: []string{FieldName, FieldTimezone, FieldLanguage},
+	}
+	contact := Contact{BotID: testBot, ChannelIdentityID: testIdentity, DisplayName: "ada_l"}
+	ctx := context.Background()
+
+	steps := []struct {
+		text string
+		want Reply
+	}{
+		{text: "hi", want: Reply{Messages: []string{"Welcome!"}, Ask: FieldName, Handled: true}},
+		{text: "Ada", want: Reply{Ask: FieldTimezone, Handled: true}},
+		{text: "Mars/Olympus", want: Reply{Invalid: FieldTimezone, Ask: FieldTimezone, Handled: true}},
+		{text: "Europe/London", want: Reply{Ask: FieldLanguage, Handled: true}},
+		{text: "en_gb", want: Reply{Completed: true, Handled: true}},
+		{text: "what's new?", want: Reply{}},
+	}
+	for _, step := range steps {
+		got, err := svc.Greet(ctx, cfg, contact, step.text)
+		if err != nil {
+			t.Fatalf("Greet(%q): %v", step.text, err)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("Greet(%q) = %+v, want %+v", step.text, got, step.want)
+		}
+	}
+	profile := queries.profile()
+	if profile.DisplayName != "Ada" || profile.Timezone != "Europe/London" || profile.TimezoneSource != timezoneSourceUser || profile.Language != "en-GB" || profile.OnboardingStatus != statusCompleted {
+		t.Fatalf("profile = %+v", profile)
+	}
+}
+
+func TestGreetSkipLeavesFieldEmpty(t *testing.T) {
+	t.Parallel()
+
+	queries := newFakeProfileQueries()
+	svc := NewService(nil, queries)
+	cfg := Config{Enabled: true, Collect: []string{FieldTimezone}}
+	contact := Contact{BotID: testBot, ChannelIdentityID: testIdentity}
+
+	if _, err := svc.Greet(context.Background(), cfg, contact, "hi"); err != nil {
+		t.Fatalf("Greet: %v", err)
+	}
+	reply, err := svc.Greet(context.Background(), cfg, contact, "Skip")
+	if err != nil || !reply.Completed {
+		t.Fatalf("skip reply = %+v, %v", reply, err)
+	}
+	if profile := queries.profile(); profile.Timezone != "" || profile.OnboardingStatus != statusCompleted {
+		t.Fatalf("profile = %+v", profile)
+	}
+}
+
+func TestBindWelcomeSentOncePerAccount(t *testing.T) {
+	t.Parallel()
+
+	queries := newFakeProfileQueries()
+	svc := NewService(nil, queries)
+	cfg := Config{Enabled: true, Welcome: []string{"Hi"}, BindWelcome: []string{"Linked, {name}."}}
+	contact := Contact{BotID: testBot, ChannelIdentityID: testIdentity, DisplayName: "Ada"}
+	ctx := context.Background()
+
+	if _, err := svc.Greet(ctx, cfg, contact, "hi"); err != nil {
+		t.Fatalf("Greet: %v", err)
+	}
+	contact.UserID = testUser
+	reply, err := svc.Bound(ctx, cfg, contact)
+	if err != nil {
+		t.Fatalf("Bound: %v", err)
+	}
+	if want := []string{"Linked, Ada."}; !reflect.DeepEqual(reply.Messages, want) {
+		t.Fatalf("bind reply = %+v", reply)
+	}
+	if reply, _ := svc.Greet(ctx, cfg, contact, "hi"); !reply.Empty() {
+		t.Fatalf("bind welcome repeated: %+v", reply)
+	}
+	if reply, _ := svc.Bound(ctx, cfg, contact); !reply.Empty() {
+		t.Fatalf("bind welcome repeated on Bound: %+v", reply)
+	}
+}
+
+func TestGreetDisabled(t *testing.T) {
+	t.Parallel()
+
+	queries := newFakeProfileQueries()
+	reply, err := NewService(nil, queries).Greet(context.Background(), Config{}, Contact{BotID: testBot, ChannelIdentityID: testIdentity}, "hi")
+	if err != nil || !reply.Empty() || len(queries.profiles) != 0 {
+		t.Fatalf("disabled greet = %+v, %v, profiles %d", reply, err, len(queries.profiles))
+	}
+}
+
+func TestObservePlatformTimezone(t *testing.T) {
+	t.Parallel()
+
+	queries := newFakeProfileQueries()
+	svc := NewService(nil, queries)
+	ctx := context.Background()
+
+	if err := svc.ObservePlatformTimezone(ctx, testBot, testIdentity, "America/New_York"); err != nil {
+		t.Fatalf("ObservePlatformTimezone: %v", err)
+	}
+	_ = svc.ObservePlatformTimezone(ctx, testBot, testIdentity, "America/New_York")
+	_ = svc.ObservePlatformTimezone(ctx, testBot, testIdentity, "Not/AZone")
+	if queries.upserts != 1 {
+		t.Fatalf("upserts = %d, want 1", queries.upserts)
+	}
+	if tz, err := svc.ContactTimezone(ctx, testBot, testIdentity); err != nil || tz != "America/New_York" {
+		t.Fatalf("ContactTimezone = %q, %v", tz, err)
+	}
+
+	// A profile created from a platform timezone still gets the welcome, and
+	// the timezone the contact gives wins over the platform's.
+	cfg := Config{Enabled: true, Welcome: []string{"Welcome!"}, Collect: []string{FieldTimezone}}
+	contact := Contact{BotID: testBot, ChannelIdentityID: testIdentity}
+	reply, err := svc.Greet(ctx, cfg, contact, "hi")
+	if err != nil || len(reply.Messages) != 1 {
+		t.Fatalf("Greet on pending profile = %+v, %v", reply, err)
+	}
+	if _, err := svc.Greet(ctx, cfg, contact, "Asia/Tokyo"); err != nil {
+		t.Fatalf("Greet answer: %v", err)
+	}
+	_ = svc.ObservePlatformTimezone(ctx, testBot, testIdentity, "Europe/Paris")
+	if tz, _ := svc.ContactTimezone(ctx, testBot, testIdentity); tz != "Asia/Tokyo" {
+		t.Fatalf("timezone = %q, want the contact's own", tz)
+	}
+	if tz, err := svc.ContactTimezone(ctx, testBot, "44444444-4444-4444-4444-444444444444"); err != nil || tz != "" {
+		t.Fatalf("unknown contact timezone = %q, %v", tz, err)
+	}
+}
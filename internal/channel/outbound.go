@@ -9,6 +9,8 @@ import (
 	"time"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/memohai/memoh/internal/extension"
 )
 
 // ChunkerMode selects the text chunking strategy.
@@ -42,6 +44,11 @@ type OutboundPolicy struct {
 	InlineTextWithMedia bool          `json:"inline_text_with_media,omitempty"`
 	RetryMax            int           `json:"retry_max,omitempty"`
 	RetryBackoffMs      int           `json:"retry_backoff_ms,omitempty"`
+	// ReplySplitRunes is the target size of each message when reply
+	// splitting is enabled on the channel config.
+	ReplySplitRunes int `json:"reply_split_runes,omitempty"`
+	// ReplyPacingMs is the pause between split reply messages.
+	ReplyPacingMs int `json:"reply_pacing_ms,omitempty"`
 }
 
 // NormalizeOutboundPolicy fills zero-value fields with sensible defaults.
@@ -67,6 +74,12 @@ func NormalizeOutboundPolicy(policy OutboundPolicy) OutboundPolicy {
 	if policy.Chunker == nil {
 		policy.Chunker = DefaultChunker(policy.ChunkerMode)
 	}
+	if policy.ReplySplitRunes <= 0 {
+		policy.ReplySplitRunes = defaultReplySplitRunes
+	}
+	if policy.ReplyPacingMs <= 0 {
+		policy.ReplyPacingMs = defaultReplyPacingMs
+	}
 	return policy
 }
 
@@ -411,6 +424,9 @@ func validateMessageAgainstCapabilities(caps ChannelCapabilities, ok bool, msg M
 	if len(msg.Attachments) > 0 && requiresMedia(msg.Attachments) && !caps.Media {
 		return errors.New("channel does not support media")
 	}
+	if hasStickers(msg.Attachments) && !caps.Stickers {
+		return errors.New("channel does not support stickers")
+	}
 	if len(msg.Actions) > 0 && !caps.Buttons {
 		if !caps.URLButtons {
 			return errors.New("channel does not support actions")
@@ -455,6 +471,37 @@ func (m *Manager) sendAllWithConfig(ctx context.Context, sender Sender, cfg Chan
 	return nil
 }
 
+// applyOutboundExtensions lets extensions rewrite the message text or stop the
+// message, before it is split into deliveries. Rewritten text replaces the
+// rich parts.
+func (m *Manager) applyOutboundExtensions(ctx context.Context, cfg ChannelConfig, msg OutboundMessage) (OutboundMessage, bool) {
+	if !m.extensions.Handles(extension.HookOutbound) {
+		return msg, true
+	}
+	text := msg.Message.PlainText()
+	event := &extension.OutboundMessage{
+		BotID:   cfg.BotID,
+		Channel: cfg.ChannelType.String(),
+		Target:  msg.Target,
+		Text:    text,
+	}
+	if err := m.extensions.OnOutbound(ctx, event); err != nil {
+		if m.logger != nil {
+			m.logger.Info("outbound dropped by extension",
+				slog.String("channel", cfg.ChannelType.String()),
+				slog.String("bot_id", cfg.BotID),
+				slog.Any("reason", err),
+			)
+		}
+		return msg, false
+	}
+	if event.Text != text {
+		msg.Message.Text = event.Text
+		msg.Message.Parts = nil
+	}
+	return msg, true
+}
+
 func (m *Manager) sendWithConfig(ctx context.Context, sender Sender, cfg ChannelConfig, msg OutboundMessage, policy OutboundPolicy) error {
 	prepared, err := m.prepareOutboundForSend(ctx, sender, cfg, msg)
 	if err != nil {
@@ -608,6 +655,15 @@ func requiresMedia(attachments []Attachment) bool {
 	return false
 }
 
+func hasStickers(attachments []Attachment) bool {
+	for _, att := range attachments {
+		if att.Type == AttachmentSticker {
+			return true
+		}
+	}
+	return false
+}
+
 func validateStreamEvent(registry *Registry, channelType ChannelType, event StreamEvent) error {
 	caps, ok := registry.GetCapabilities(channelType)
 	switch event.Type {
@@ -676,7 +732,9 @@ func validateStreamEvent(registry *Registry, channelType ChannelType, event Stre
 	return nil
 }
 
-func (m *Manager) newReplySender(cfg ChannelConfig, channelType ChannelType) StreamReplySender {
+// newReplySender returns the sender for replies to the inbound message
+// turnID. Duplicate suppression is scoped to that turn.
+func (m *Manager) newReplySender(cfg ChannelConfig, channelType ChannelType, turnID string) StreamReplySender {
 	sender, _ := m.registry.GetSender(channelType)
 	streamSender, _ := m.registry.GetStreamSender(channelType)
 	return &managerReplySender{
@@ -685,6 +743,7 @@ func (m *Manager) newReplySender(cfg ChannelConfig, channelType ChannelType) Str
 		streamSender: streamSender,
 		channelType:  channelType,
 		config:       cfg,
+		turnID:       strings.TrimSpace(turnID),
 	}
 }
 
@@ -694,9 +753,16 @@ type managerReplySender struct {
 	streamSender StreamSender
 	channelType  ChannelType
 	config       ChannelConfig
+	turnID       string
 }
 
 func (s *managerReplySender) Send(ctx context.Context, msg OutboundMessage) error {
+	return s.send(ctx, msg, true)
+}
+
+// send delivers msg. Streams pass dedupe=false for the pieces of a final they
+// already claimed as a whole.
+func (s *managerReplySender) send(ctx context.Context, msg OutboundMessage, dedupe bool) error {
 	if s.manager == nil {
 		return errors.New("channel manager not configured")
 	}
@@ -705,13 +771,26 @@ func (s *managerReplySender) Send(ctx context.Context, msg OutboundMessage) erro
 		return err
 	}
 	msg.Target = target
+	msg, ok := s.manager.applyOutboundExtensions(ctx, s.config, msg)
+	if !ok {
+		return nil
+	}
+	key := ""
+	if dedupe {
+		if key, ok = s.manager.claimOutbound(s.config, msg.Target, s.turnID, msg.Message); !ok {
+			return nil
+		}
+	}
 	policy := s.manager.resolveOutboundPolicy(s.channelType)
 	caps, hasCaps := s.manager.registry.GetOutboundCapabilities(s.channelType, s.config, msg.Target)
 	outbound, err := buildOutboundMessagesWithCaps(msg, policy, caps, hasCaps)
+	if err == nil {
+		err = s.manager.sendAllWithConfig(ctx, s.sender, s.config, outbound, policy)
+	}
 	if err != nil {
-		return err
+		s.manager.outboundSent.release(key)
 	}
-	return s.manager.sendAllWithConfig(ctx, s.sender, s.config, outbound, policy)
+	return err
 }
 
 func (s *managerReplySender) OpenStream(ctx context.Context, target string, opts StreamOptions) (OutboundStream, error) {
@@ -741,15 +820,18 @@ func (s *managerReplySender) OpenStream(ctx context.Context, target string, opts
 	return &managerOutboundStream{
 		manager:     s.manager,
 		config:      s.config,
+		turnID:      s.turnID,
 		stream:      stream,
 		channelType: s.channelType,
 		target:      target,
 		reply:       opts.Reply,
 		policy:      s.manager.resolveOutboundPolicy(s.channelType),
+		splitReply:  replySplittingEnabled(s.config),
+		noPreview:   !s.manager.streamingEditsEnabled(ctx, s.config.BotID),
 		sender:      s.sender,
 		send: func(ctx context.Context, msg OutboundMessage) error {
 			msg.Target = target
-			return s.Send(ctx, msg)
+			return s.send(ctx, msg, false)
 		},
 		reopen: func(ctx context.Context) (PreparedOutboundStream, error) {
 			return s.streamSender.OpenStream(ctx, s.config, target, StreamOptions{
@@ -768,11 +850,14 @@ func (s *managerReplySender) OpenStream(ctx context.Context, target string, opts
 type managerOutboundStream struct {
 	manager     *Manager
 	config      ChannelConfig
+	turnID      string
 	stream      PreparedOutboundStream
 	channelType ChannelType
 	target      string
 	reply       *ReplyRef
 	policy      OutboundPolicy // cached at open time; immutable after creation
+	splitReply  bool           // deliver finals as paced short messages, without text previews
+	noPreview   bool           // streaming_edits is off: deliver the final without text previews
 	sender      Sender
 	send        func(ctx context.Context, msg OutboundMessage) error
 	reopen      func(ctx context.Context) (PreparedOutboundStream, error)
@@ -788,6 +873,7 @@ func (s *managerOutboundStream) Push(ctx context.Context, event StreamEvent) err
 	if err := validateStreamEvent(s.manager.registry, s.channelType, event); err != nil {
 		return err
 	}
+	event = redactStreamEvent(event)
 	if event.Type == StreamEventAttachment {
 		if caps, ok := s.manager.registry.GetOutboundCapabilities(s.channelType, s.config, s.target); ok {
 			if err := validateMessageAgainstCapabilities(caps, true, Message{Attachments: event.Attachments}); err != nil {
@@ -822,13 +908,55 @@ func (s *managerOutboundStream) Push(ctx context.Context, event StreamEvent) err
 	}
 
 	if event.Type == StreamEventDelta && event.Delta != "" && event.Phase != StreamPhaseReasoning {
+		if s.splitReply {
+			// Split replies arrive as separate messages; a streamed preview
+			// of the whole reply would defeat the pacing.
+			return nil
+		}
+		if s.noPreview {
+			return nil
+		}
 		return s.pushDelta(ctx, event)
 	}
 
-	if event.Type == StreamEventFinal && event.Final != nil && s.send != nil {
+	if event.Type == StreamEventFinal && event.Final != nil {
+		key, ok := s.claimFinal(event.Final.Message)
+		if !ok {
+			// Close the adapter's message without delivering the text again.
+			return s.pushPrepared(ctx, StreamEvent{
+				Type:     StreamEventFinal,
+				Final:    &StreamFinalizePayload{},
+				Metadata: event.Metadata,
+			})
+		}
+		if err := s.pushFinal(ctx, event, originalFinalText); err != nil {
+			s.manager.outboundSent.release(key)
+			return err
+		}
+		return nil
+	}
+	return s.pushPrepared(ctx, event)
+}
+
+// claimFinal claims the final's content for the stream's route and reports
+// false for a duplicate. Text already streamed as a preview is on screen
+// anyway, so such a final is recorded but never reported as a duplicate.
+func (s *managerOutboundStream) claimFinal(msg Message) (string, bool) {
+	if s.deltaRunes > 0 || s.splitCount > 0 {
+		s.manager.outboundSent.claim(outboundDedupeKey(s.config, s.target, s.turnID, msg), s.manager.outboundSent.windowFor(s.config))
+		return "", true
+	}
+	return s.manager.claimOutbound(s.config, s.target, s.turnID, msg)
+}
+
+func (s *managerOutboundStream) pushFinal(ctx context.Context, event StreamEvent, originalFinalText string) error {
+	if s.send != nil {
 		if s.splitCount > 0 {
 			return s.pushFinalAfterSplit(ctx, event, originalFinalText)
 		}
+		if s.splitReply {
+			return s.pushFinalSplit(ctx, event)
+		}
 		return s.pushFinalWithChunking(ctx, event)
 	}
 	return s.pushPrepared(ctx, event)
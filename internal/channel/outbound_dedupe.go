@@ -0,0 +1,177 @@
+package channel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memohai/memoh/internal/sharedstate"
+)
+
+// OutboundDedupeRoutingKey is the channel config routing option that sets,
+// in seconds, how long a delivered message suppresses an identical one to
+// the same route for that bot. 0 turns suppression off; unset keeps the
+// manager default.
+const OutboundDedupeRoutingKey = "outbound_dedupe_seconds"
+
+// defaultOutboundDedupeWindow is how long a delivered message suppresses an
+// identical one to the same route. It covers send retries, replayed inbound
+// turns after a reconnect, and an agent emitting the same reply twice within
+// a turn; it is kept short because the key already separates turns.
+const defaultOutboundDedupeWindow = 30 * time.Second
+
+// outboundDedupe remembers recently delivered messages per route. Entries
+// are hashes of the route and message content, not the text itself, mapped
+// to when they stop suppressing. With a shared store the entries live there
+// instead, so replicas suppress each other's duplicates.
+type outboundDedupe struct {
+	window time.Duration
+	now    func() time.Time
+	shared sharedstate.Store
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// windowFor returns the suppression window for cfg: its routing
+// override when set, otherwise the manager default.
+func (d *outboundDedupe) windowFor(cfg ChannelConfig) time.Duration {
+	if d == nil {
+		return 0
+	}
+	var seconds float64
+	switch v := cfg.Routing[OutboundDedupeRoutingKey].(type) {
+	case float64:
+		seconds = v
+	case int:
+		seconds = float64(v)
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return d.window
+		}
+		seconds = parsed
+	default:
+		return d.window
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func newOutboundDedupe(window time.Duration) *outboundDedupe {
+	return &outboundDedupe{
+		window: window,
+		now:    time.Now,
+		sent:   map[string]time.Time{},
+	}
+}
+
+// outboundDedupeKey identifies a message on a route: bot, channel, target and
+// thread, the inbound turn it answers, the message it replies to, its
+// normalized text and attachment references. The turn and reply references
+// keep the same short answer to two different messages apart; turnID is the
+// originating inbound message ID and is empty for sends outside a turn.
+// Edits and messages without content have no key and are never suppressed.
+func outboundDedupeKey(cfg ChannelConfig, target, turnID string, msg Message) string {
+	if strings.TrimSpace(msg.ID) != "" {
+		return ""
+	}
+	text := strings.Join(strings.Fields(msg.PlainText()), " ")
+	refs := make([]string, 0, len(msg.Attachments))
+	for _, att := range msg.Attachments {
+		ref := strings.TrimSpace(att.ContentHash)
+		if ref == "" {
+			ref = att.Reference()
+		}
+		refs = append(refs, ref)
+	}
+	if text == "" && len(refs) == 0 {
+		return ""
+	}
+	thread, reply := "", ""
+	if msg.Thread != nil {
+		thread = strings.TrimSpace(msg.Thread.ID)
+	}
+	if msg.Reply != nil {
+		reply = strings.TrimSpace(msg.Reply.MessageID)
+	}
+	sum := sha256.New()
+	for _, part := range []string{cfg.BotID, cfg.ChannelType.String(), strings.TrimSpace(target), strings.TrimSpace(turnID), thread, reply, text, strings.Join(refs, "\n")} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// claim records key as delivered for window. It returns false when the same
+// key is still suppressed, meaning the message is a duplicate.
+func (d *outboundDedupe) claim(key string, window time.Duration) bool {
+	if d == nil || window <= 0 || key == "" {
+		return true
+	}
+	if d.shared != nil {
+		ok, err := d.shared.Claim(context.Background(), outboundDedupeStateKey(key), window)
+		return ok || err != nil
+	}
+	now := d.now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k, until := range d.sent {
+		if !now.Before(until) {
+			delete(d.sent, k)
+		}
+	}
+	if _, ok := d.sent[key]; ok {
+		return false
+	}
+	d.sent[key] = now.Add(window)
+	return true
+}
+
+// release forgets key after a failed delivery so a retry is not suppressed.
+func (d *outboundDedupe) release(key string) {
+	if d == nil || key == "" {
+		return
+	}
+	if d.shared != nil {
+		_ = d.shared.Release(context.Background(), outboundDedupeStateKey(key))
+		return
+	}
+	d.mu.Lock()
+	delete(d.sent, key)
+	d.mu.Unlock()
+}
+
+func outboundDedupeStateKey(key string) string {
+	return "outbound:" + key
+}
+
+// SetSharedState moves outbound duplicate suppression into store so it
+// holds across replicas. A nil store keeps it in process memory.
+func (m *Manager) SetSharedState(store sharedstate.Store) {
+	m.outboundSent.shared = store
+}
+
+// claimOutbound claims msg for target within turnID. It returns the claimed
+// key, which the caller releases if delivery fails, and false for a
+// duplicate.
+func (m *Manager) claimOutbound(cfg ChannelConfig, target, turnID string, msg Message) (string, bool) {
+	key := outboundDedupeKey(cfg, target, turnID, msg)
+	if m.outboundSent.claim(key, m.outboundSent.windowFor(cfg)) {
+		return key, true
+	}
+	if m.logger != nil {
+		m.logger.Info("duplicate outbound suppressed",
+			slog.String("channel", cfg.ChannelType.String()),
+			slog.String("bot_id", cfg.BotID),
+		)
+	}
+	return "", false
+}
@@ -0,0 +1,176 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newDedupeTestManager(t *testing.T, channelType ChannelType, opts ...ManagerOption) (*Manager, *targetResolvingAdapter) {
+	t.Helper()
+	adapter := &targetResolvingAdapter{
+		channelType: channelType,
+		dynamicCaps: &ChannelCapabilities{Text: true, Reply: true, Streaming: true, BlockStreaming: true},
+	}
+	registry := NewRegistry()
+	if err := registry.Register(adapter); err != nil {
+		t.Fatalf("register adapter failed: %v", err)
+	}
+	manager := NewManager(nil, registry, &fakeConfigStore{
+		effectiveConfig: ChannelConfig{BotID: "bot-1", ChannelType: channelType},
+	}, nil, opts...)
+	return manager, adapter
+}
+
+func TestManagerSendSuppressesDuplicatesWithinWindow(t *testing.T) {
+	t.Parallel()
+
+	channelType := ChannelType("dedupe-send")
+	manager, adapter := newDedupeTestManager(t, channelType)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	manager.outboundSent.now = func() time.Time { return now }
+	ctx := context.Background()
+	send := func(text, replyTo string) {
+		t.Helper()
+		msg := Message{Text: text}
+		if replyTo != "" {
+			msg.Reply = &ReplyRef{MessageID: replyTo}
+		}
+		if err := manager.Send(ctx, "bot-1", channelType, SendRequest{Target: "chat-1", Message: msg}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	send("The build is green.", "m1")
+	send("The  build is green. ", "m1")
+	if len(adapter.sent) != 1 {
+		t.Fatalf("sent %d messages, want the retry suppressed", len(adapter.sent))
+	}
+	send("The build is green.", "m2")
+	if len(adapter.sent) != 2 {
+		t.Fatalf("sent %d messages, want a reply to another message delivered", len(adapter.sent))
+	}
+	now = now.Add(defaultOutboundDedupeWindow)
+	send("The build is green.", "m1")
+	if len(adapter.sent) != 3 {
+		t.Fatalf("sent %d messages, want delivery after the window", len(adapter.sent))
+	}
+}
+
+func TestReplySenderRetriesAfterFailedSend(t *testing.T) {
+	t.Parallel()
+
+	channelType := ChannelType("dedupe-retry")
+	manager, adapter := newDedupeTestManager(t, channelType)
+	failures := 1
+	adapter.validate = func(PreparedOutboundMessage) error {
+		if failures > 0 {
+			failures--
+			return errors.New("platform unavailable")
+		}
+		return nil
+	}
+	sender := manager.newReplySender(ChannelConfig{BotID: "bot-1", ChannelType: channelType}, channelType, "in-1")
+	msg := OutboundMessage{Target: "chat-1", Message: Message{Text: "hello"}}
+
+	if err := sender.Send(context.Background(), msg); err == nil {
+		t.Fatal("first Send succeeded, want the platform error")
+	}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("retry Send: %v", err)
+	}
+	if len(adapter.sent) != 1 {
+		t.Fatalf("sent %d messages, want the retry delivered once", len(adapter.sent))
+	}
+}
+
+func TestReplyStreamEmptiesDuplicateFinal(t *testing.T) {
+	t.Parallel()
+
+	channelType := ChannelType("dedupe-stream")
+	manager, adapter := newDedupeTestManager(t, channelType)
+	sender := manager.newReplySender(ChannelConfig{BotID: "bot-1", ChannelType: channelType}, channelType, "in-1")
+	ctx := context.Background()
+
+	if err := sender.Send(ctx, OutboundMessage{Target: "chat-1", Message: Message{Text: "Meeting moved to 3pm."}}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	stream, err := sender.OpenStream(ctx, "chat-1", StreamOptions{})
+	if err != nil {
+		t.Fatalf("OpenStream: %v", err)
+	}
+	if err := stream.Push(ctx, StreamEvent{
+		Type:  StreamEventFinal,
+		Final: &StreamFinalizePayload{Message: Message{Text: "Meeting moved to 3pm."}},
+	}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	events := adapter.openedStream.Events()
+	if len(events) != 1 || events[0].Type != StreamEventFinal {
+		t.Fatalf("stream events = %+v, want one final", events)
+	}
+	if !events[0].Final.Message.IsEmpty() {
+		t.Fatalf("duplicate final delivered %+v, want an empty final", events[0].Final.Message)
+	}
+}
+
+func TestOutboundDedupeCanBeDisabled(t *testing.T) {
+	t.Parallel()
+
+	channelType := ChannelType("dedupe-disabled")
+	manager, adapter := newDedupeTestManager(t, channelType, WithOutboundDedupeWindow(0))
+	for range 2 {
+		if err := manager.Send(context.Background(), "bot-1", channelType, SendRequest{Target: "chat-1", Message: Message{Text: "ping"}}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if len(adapter.sent) != 2 {
+		t.Fatalf("sent %d messages, want both with suppression disabled", len(adapter.sent))
+	}
+}
+
+func TestReplySenderDeliversSameTextForDifferentTurns(t *testing.T) {
+	t.Parallel()
+
+	channelType := ChannelType("dedupe-turns")
+	manager, adapter := newDedupeTestManager(t, channelType)
+	cfg := ChannelConfig{BotID: "bot-1", ChannelType: channelType}
+	ctx := context.Background()
+	msg := OutboundMessage{Target: "chat-1", Message: Message{Text: "Done."}}
+
+	for _, turnID := range []string{"in-1", "in-2"} {
+		if err := manager.newReplySender(cfg, channelType, turnID).Send(ctx, msg); err != nil {
+			t.Fatalf("Send for %s: %v", turnID, err)
+		}
+	}
+	if len(adapter.sent) != 2 {
+		t.Fatalf("sent %d messages, want one per turn", len(adapter.sent))
+	}
+	if err := manager.newReplySender(cfg, channelType, "in-1").Send(ctx, msg); err != nil {
+		t.Fatalf("replayed Send: %v", err)
+	}
+	if len(adapter.sent) != 2 {
+		t.Fatalf("sent %d messages, want the replayed turn suppressed", len(adapter.sent))
+	}
+}
+
+func TestOutboundDedupeWindowFromChannelConfig(t *testing.T) {
+	t.Parallel()
+
+	d := newOutboundDedupe(defaultOutboundDedupeWindow)
+	cases := []struct {
+		routing map[string]any
+		want    time.Duration
+	}{
+		{nil, defaultOutboundDedupeWindow},
+		{map[string]any{OutboundDedupeRoutingKey: float64(5)}, 5 * time.Second},
+		{map[string]any{OutboundDedupeRoutingKey: "0"}, 0},
+		{map[string]any{OutboundDedupeRoutingKey: "soon"}, defaultOutboundDedupeWindow},
+	}
+	for _, tc := range cases {
+		if got := d.windowFor(ChannelConfig{Routing: tc.routing}); got != tc.want {
+			t.Fatalf("windowFor(%v) = %s, want %s", tc.routing, got, tc.want)
+		}
+	}
+}
@@ -421,6 +421,10 @@ func resolvePreparedNativeRef(channelType ChannelType, item Attachment) (string,
 			if strings.HasPrefix(strings.ToLower(ref), "mxc://") {
 				return ref, true
 			}
+		case ChannelTypeDiscord:
+			if item.Type == AttachmentSticker {
+				return ref, true
+			}
 		}
 	}
 	if channelType == ChannelTypeMatrix {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/memohai/memoh/internal/channel/channeltest"
+	"github.com/memohai/memoh/internal/featureflags"
 )
 
 type streamValidationAdapter struct {
@@ -432,7 +433,7 @@ func TestReplySenderValidatesAllSplitMessagesBeforeSending(t *testing.T) {
 	manager := NewManager(nil, registry, &fakeConfigStore{
 		effectiveConfig: ChannelConfig{BotID: "bot-1", ChannelType: channelType},
 	}, nil)
-	sender := manager.newReplySender(ChannelConfig{BotID: "bot-1", ChannelType: channelType}, channelType)
+	sender := manager.newReplySender(ChannelConfig{BotID: "bot-1", ChannelType: channelType}, channelType, "")
 
 	err := sender.Send(context.Background(), OutboundMessage{
 		Target: "chat-1",
@@ -461,7 +462,7 @@ func TestReplyStreamResolvesOutboundTargetBeforeCapabilities(t *testing.T) {
 	manager := NewManager(nil, registry, &fakeConfigStore{
 		effectiveConfig: ChannelConfig{BotID: "bot-1", ChannelType: channelType},
 	}, nil)
-	sender := manager.newReplySender(ChannelConfig{BotID: "bot-1", ChannelType: channelType}, channelType)
+	sender := manager.newReplySender(ChannelConfig{BotID: "bot-1", ChannelType: channelType}, channelType, "")
 
 	stream, err := sender.OpenStream(context.Background(), "alias", StreamOptions{})
 	if err != nil {
@@ -507,7 +508,7 @@ func TestReplyStreamFinalAttachmentActionsSurviveSendBoundary(t *testing.T) {
 	}
 	manager := NewManager(nil, registry, nil, nil)
 	manager.attachmentStore = channeltest.NewMemoryAttachmentStore()
-	sender := manager.newReplySender(ChannelConfig{BotID: "bot-1", ChannelType: channelType}, channelType)
+	sender := manager.newReplySender(ChannelConfig{BotID: "bot-1", ChannelType: channelType}, channelType, "")
 
 	stream, err := sender.OpenStream(context.Background(), "chat-1", StreamOptions{})
 	if err != nil {
@@ -2502,3 +2503,56 @@ func TestCoerceFormatForCaps_PreservesPlainEverywhere(t *testing.T) {
 		})
 	}
 }
+
+type offForBots map[string]bool
+
+func (f offForBots) Enabled(_ context.Context, key featureflags.Key, botID string) bool {
+	return key != featureflags.StreamingEdits || !f[botID]
+}
+
+func TestReplyStreamDropsPreviewsWhenStreamingEditsOff(t *testing.T) {
+	t.Parallel()
+
+	channelType := ChannelTypeTelegram
+	adapter := &targetResolvingAdapter{
+		channelType:    channelType,
+		outboundPolicy: OutboundPolicy{TextChunkLimit: 2000},
+	}
+	registry := NewRegistry()
+	if err := registry.Register(adapter); err != nil {
+		t.Fatalf("register adapter failed: %v", err)
+	}
+	manager := NewManager(nil, registry, nil, nil)
+	manager.attachmentStore = channeltest.NewMemoryAttachmentStore()
+	manager.SetFeatureFlags(offForBots{"bot-off": true})
+
+	ctx := context.Background()
+	for botID, wantEvents := range map[string]int{"bot-off": 1, "bot-on": 3} {
+		adapter.openedStream = nil
+		sender := manager.newReplySender(ChannelConfig{BotID: botID, ChannelType: channelType}, channelType, "")
+		stream, err := sender.OpenStream(ctx, "chat-1", StreamOptions{})
+		if err != nil {
+			t.Fatalf("OpenStream failed: %v", err)
+		}
+		for _, delta := range []string{"Hello ", "there."} {
+			if err := stream.Push(ctx, StreamEvent{Type: StreamEventDelta, Delta: delta}); err != nil {
+				t.Fatalf("Push delta failed: %v", err)
+			}
+		}
+		err = stream.Push(ctx, StreamEvent{
+			Type:  StreamEventFinal,
+			Final: &StreamFinalizePayload{Message: Message{Format: MessageFormatPlain, Text: "Hello there."}},
+		})
+		if err != nil {
+			t.Fatalf("Push final failed: %v", err)
+		}
+
+		events := adapter.openedStream.Events()
+		if len(events) != wantEvents || events[len(events)-1].Type != StreamEventFinal {
+			t.Fatalf("%s: stream events = %+v, want %d ending in the final", botID, events, wantEvents)
+		}
+		if got := events[len(events)-1].Final.Message.Text; got != "Hello there." {
+			t.Fatalf("%s: final = %q", botID, got)
+		}
+	}
+}
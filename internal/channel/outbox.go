@@ -0,0 +1,174 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// outboxPollInterval is how often the retry worker looks for due sends.
+	outboxPollInterval = 15 * time.Second
+	// outboxBatchSize bounds the sends one poll retries.
+	outboxBatchSize = 20
+	// outboxLease keeps a claimed send from being picked up by another
+	// replica while it is retried.
+	outboxLease = 5 * time.Minute
+	// OutboxMaxAttempts is how many deliveries a queued send gets, counting
+	// the failed one that queued it, before it is dead-lettered.
+	OutboxMaxAttempts = 8
+	// outboxBaseBackoff doubles after every failed attempt up to
+	// outboxMaxBackoff: 30s, 1m, 2m, ... 1h.
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+)
+
+// OutboxEntry is a queued outbound send.
+type OutboxEntry struct {
+	ID              string
+	BotID           string
+	ChannelConfigID string
+	ChannelType     ChannelType
+	Request         SendRequest
+	// Attempts counts the deliveries already tried.
+	Attempts int
+}
+
+// OutboxStore persists outbound sends that failed on a transient platform
+// error. Failed marks the entry for another try at retryAt, or
+// dead-letters it when retryAt is zero.
+type OutboxStore interface {
+	EnqueueOutbound(ctx context.Context, entry OutboxEntry, cause error, retryAt time.Time) error
+	ClaimDueOutbound(ctx context.Context, limit int, lease time.Duration) ([]OutboxEntry, error)
+	MarkOutboundSent(ctx context.Context, id string) error
+	MarkOutboundFailed(ctx context.Context, id string, cause error, retryAt time.Time) error
+}
+
+// SetOutbox wires the retry queue. Without it a failed send is returned to
+// the caller and dropped.
+func (m *Manager) SetOutbox(store OutboxStore) {
+	m.outbox = store
+}
+
+type outboxRetryKey struct{}
+
+// queueOutbound hands a send that failed on a transient error to the
+// outbox. It reports whether the send was queued, in which case the caller
+// treats it as accepted.
+func (m *Manager) queueOutbound(ctx context.Context, cfg ChannelConfig, req SendRequest, cause error) bool {
+	if m.outbox == nil || ctx.Value(outboxRetryKey{}) != nil || ctx.Err() != nil || !isTransientSendError(cause) {
+		return false
+	}
+	entry := OutboxEntry{
+		BotID:           cfg.BotID,
+		ChannelConfigID: cfg.ID,
+		ChannelType:     cfg.ChannelType,
+		Request:         req,
+	}
+	if err := m.outbox.EnqueueOutbound(context.WithoutCancel(ctx), entry, cause, time.Now().Add(outboxBackoff(1))); err != nil {
+		if m.logger != nil {
+			m.logger.Error("queue outbound failed", slog.String("channel", cfg.ChannelType.String()), slog.String("bot_id", cfg.BotID), slog.Any("error", err))
+		}
+		return false
+	}
+	if m.logger != nil {
+		m.logger.Warn("outbound queued for retry", slog.String("channel", cfg.ChannelType.String()), slog.String("bot_id", cfg.BotID), slog.Any("error", cause))
+	}
+	return true
+}
+
+func (m *Manager) startOutboxWorker(ctx context.Context) {
+	if m.outbox == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(outboxPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.retryOutbox(ctx)
+			}
+		}
+	}()
+}
+
+// retryOutbox sends every due outbox entry once. A failure is rescheduled
+// with exponential backoff; a permanent error or the last attempt
+// dead-letters the entry.
+func (m *Manager) retryOutbox(ctx context.Context) {
+	entries, err := m.outbox.ClaimDueOutbound(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		if m.logger != nil {
+			m.logger.Warn("claim outbox failed", slog.Any("error", err))
+		}
+		return
+	}
+	retryCtx := context.WithValue(ctx, outboxRetryKey{}, true)
+	for _, entry := range entries {
+		sendErr := m.Send(retryCtx, entry.BotID, entry.ChannelType, entry.Request)
+		if sendErr == nil {
+			err = m.outbox.MarkOutboundSent(ctx, entry.ID)
+		} else {
+			attempts := entry.Attempts + 1
+			var retryAt time.Time
+			if attempts < OutboxMaxAttempts && isTransientSendError(sendErr) {
+				retryAt = time.Now().Add(outboxBackoff(attempts))
+			} else if m.logger != nil {
+				m.logger.Error("outbound dead-lettered",
+					slog.String("outbox_id", entry.ID),
+					slog.String("channel", entry.ChannelType.String()),
+					slog.String("bot_id", entry.BotID),
+					slog.Int("attempts", attempts),
+					slog.Any("error", sendErr),
+				)
+			}
+			err = m.outbox.MarkOutboundFailed(ctx, entry.ID, sendErr, retryAt)
+		}
+		if err != nil && m.logger != nil {
+			m.logger.Warn("update outbox failed", slog.String("outbox_id", entry.ID), slog.Any("error", err))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// outboxBackoff is the delay before the next try after attempts failed
+// deliveries.
+func outboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxMaxBackoff)
+}
+
+// transientSendPattern matches the platform errors worth retrying later:
+// rate limits, 5xx responses and dropped connections. Adapters wrap their
+// SDK errors as text, so the status is matched in the message.
+var transientSendPattern = regexp.MustCompile(`(?i)(status|code|http|error)[^0-9a-z]{0,3}(429|5\d{2})\b|too many requests|rate.?limit|bad gateway|service unavailable|gateway time-?out|temporarily unavailable|timeout|connection (reset|refused)|EOF$`)
+
+// isTransientSendError reports whether a failed send may succeed later.
+func isTransientSendError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || IsDownstreamUnavailable(err) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return transientSendPattern.MatchString(strings.TrimSpace(err.Error()))
+}
@@ -0,0 +1,417 @@
+// Package outbox keeps outbound channel messages whose delivery failed on a
+// transient platform error. channel.Manager queues them here and retries
+// them with backoff; entries that run out of attempts are dead-lettered for
+// operators to inspect and requeue.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel"
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusDead    = "dead"
+
+	defaultListLimit = 50
+	maxListLimit     = 500
+	// maxErrorBytes caps the stored cause; wrapped platform errors can carry
+	// whole response bodies.
+	maxErrorBytes = 4096
+	// sentRetention is how long delivered entries stay listable before they
+	// are pruned; pruning runs at most once per pruneInterval.
+	sentRetention = 7 * 24 * time.Hour
+	pruneInterval = time.Hour
+)
+
+var (
+	ErrEntryNotFound = errors.New("outbox entry not found")
+	ErrAlreadySent   = errors.New("outbox entry already sent")
+	ErrInvalidStatus = errors.New("invalid outbox status")
+)
+
+type outboxQueries interface {
+	InsertChannelOutbox(ctx context.Context, arg sqlc.InsertChannelOutboxParams) (sqlc.ChannelOutbox, error)
+	DeleteSentChannelOutboxBefore(ctx context.Context, before pgtype.Timestamptz) (int64, error)
+	GetChannelOutbox(ctx context.Context, id pgtype.UUID) (sqlc.ChannelOutbox, error)
+	ListChannelOutbox(ctx context.Context, arg sqlc.ListChannelOutboxParams) ([]sqlc.ChannelOutbox, error)
+	ClaimDueChannelOutbox(ctx context.Context, arg sqlc.ClaimDueChannelOutboxParams) ([]sqlc.ChannelOutbox, error)
+	MarkChannelOutboxSent(ctx context.Context, id pgtype.UUID) (sqlc.ChannelOutbox, error)
+	MarkChannelOutboxFailed(ctx context.Context, arg sqlc.MarkChannelOutboxFailedParams) (sqlc.ChannelOutbox, error)
+	RequeueChannelOutbox(ctx context.Context, id pgtype.UUID) (sqlc.ChannelOutbox, error)
+}
+
+// Entry is one queued outbound message.
+type Entry struct {
+	ID              string              `json:"id"`
+	BotID           string              `json:"bot_id"`
+	ChannelConfigID string              `json:"channel_config_id,omitempty"`
+	ChannelType     channel.ChannelType `json:"channel_type"`
+	Target          string              `json:"target"`
+	Message         channel.Message     `json:"message"`
+	Error           string              `json:"error"`
+	Attempts        int                 `json:"attempts"`
+	Status          string              `json:"status"`
+	NextAttemptAt   *time.Time          `json:"next_attempt_at,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	SentAt          *time.Time          `json:"sent_at,omitempty"`
+}
+
+// ListFilter narrows List. Status defaults to dead.
+type ListFilter struct {
+	BotID  string
+	Status string
+	Limit  int
+}
+
+// Service stores queued outbound messages.
+type Service struct {
+	queries dbstore.Queries
+	cipher  messagepkg.ContentCipher
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewService creates an outbox service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "channel_outbox")),
+		now:     time.Now,
+	}
+}
+
+// SetContentCipher seals stored messages with the bot's data key, as
+// message content is.
+func (s *Service) SetContentCipher(cipher messagepkg.ContentCipher) {
+	s.cipher = cipher
+}
+
+func (s *Service) store() (outboxQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("channel outbox service not configured")
+	}
+	store, ok := s.queries.(outboxQueries)
+	if !ok {
+		return nil, errors.New("channel outbox queries not supported by store")
+	}
+	return store, nil
+}
+
+// EnqueueOutbound stores a failed send for retry at retryAt, linked to the
+// recipient's channel identity when one is known. It implements
+// channel.OutboxStore.
+func (s *Service) EnqueueOutbound(ctx context.Context, entry channel.OutboxEntry, cause error, retryAt time.Time) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(strings.TrimSpace(entry.BotID))
+	if err != nil {
+		return fmt.Errorf("invalid bot id: %w", err)
+	}
+	var pgIdentityID pgtype.UUID
+	if identityID := strings.TrimSpace(entry.Request.ChannelIdentityID); identityID != "" {
+		if pgIdentityID, err = db.ParseUUID(identityID); err != nil {
+			return fmt.Errorf("invalid channel identity id: %w", err)
+		}
+	}
+	raw, err := json.Marshal(entry.Request.Message)
+	if err != nil {
+		return fmt.Errorf("encode outbound message: %w", err)
+	}
+	if s.cipher != nil {
+		if raw, err = s.cipher.SealJSON(ctx, pgBotID, raw); err != nil {
+			return fmt.Errorf("seal outbound message: %w", err)
+		}
+	}
+	row, err := store.InsertChannelOutbox(ctx, sqlc.InsertChannelOutboxParams{
+		BotID:             pgBotID,
+		ChannelConfigID:   strings.TrimSpace(entry.ChannelConfigID),
+		ChannelType:       entry.ChannelType.String(),
+		Target:            strings.TrimSpace(entry.Request.Target),
+		ChannelIdentityID: pgIdentityID,
+		Message:           raw,
+		Error:             errorText(cause),
+		NextAttemptAt:     pgtype.Timestamptz{Time: retryAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("insert outbox entry: %w", err)
+	}
+	s.logger.Info("outbound message queued",
+		slog.String("outbox_id", row.ID.String()),
+		slog.String("bot_id", entry.BotID),
+		slog.String("channel", entry.ChannelType.String()),
+	)
+	return nil
+}
+
+// ClaimDueOutbound leases up to limit pending entries that are due. A
+// leased entry is not claimed again until lease passes, so replicas do not
+// send it twice.
+func (s *Service) ClaimDueOutbound(ctx context.Context, limit int, lease time.Duration) ([]channel.OutboxEntry, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ClaimDueChannelOutbox(ctx, sqlc.ClaimDueChannelOutboxParams{
+		LeaseSeconds: int32(lease / time.Second),              //nolint:gosec // leases are minutes long.
+		RowLimit:     int32(min(max(limit, 1), maxListLimit)), //nolint:gosec // bounded by maxListLimit.
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox entries: %w", err)
+	}
+	s.maybePrune(ctx, store)
+	entries := make([]channel.OutboxEntry, 0, len(rows))
+	for _, row := range rows {
+		msg, err := s.decodeMessage(ctx, row)
+		if err != nil {
+			s.logger.Warn("skip undecodable outbox entry", slog.String("outbox_id", row.ID.String()), slog.Any("error", err))
+			if _, markErr := store.MarkChannelOutboxFailed(ctx, sqlc.MarkChannelOutboxFailedParams{
+				Status:        StatusDead,
+				Error:         errorText(err),
+				NextAttemptAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+				ID:            row.ID,
+			}); markErr != nil {
+				s.logger.Warn("dead-letter outbox entry failed", slog.String("outbox_id", row.ID.String()), slog.Any("error", markErr))
+			}
+			continue
+		}
+		entries = append(entries, channel.OutboxEntry{
+			ID:              row.ID.String(),
+			BotID:           row.BotID.String(),
+			ChannelConfigID: row.ChannelConfigID,
+			ChannelType:     channel.ChannelType(row.ChannelType),
+			Request:         channel.SendRequest{Target: row.Target, ChannelIdentityID: row.ChannelIdentityID.String(), Message: msg},
+			Attempts:        int(row.Attempts),
+		})
+	}
+	return entries, nil
+}
+
+// MarkOutboundSent records a successful retry.
+func (s *Service) MarkOutboundSent(ctx context.Context, id string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return ErrEntryNotFound
+	}
+	if _, err := store.MarkChannelOutboxSent(ctx, pgID); err != nil {
+		return fmt.Errorf("mark outbox entry sent: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboundFailed records a failed retry. The entry is tried again at
+// retryAt, or dead-lettered when retryAt is zero.
+func (s *Service) MarkOutboundFailed(ctx context.Context, id string, cause error, retryAt time.Time) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return ErrEntryNotFound
+	}
+	arg := sqlc.MarkChannelOutboxFailedParams{
+		Status:        StatusDead,
+		Error:         errorText(cause),
+		NextAttemptAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ID:            pgID,
+	}
+	if !retryAt.IsZero() {
+		arg.Status = StatusPending
+		arg.NextAttemptAt.Time = retryAt
+	}
+	row, err := store.MarkChannelOutboxFailed(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("mark outbox entry failed: %w", err)
+	}
+	if row.Status == StatusDead {
+		s.logger.Warn("outbound message dead-lettered",
+			slog.String("outbox_id", id),
+			slog.String("bot_id", row.BotID.String()),
+			slog.String("channel", row.ChannelType),
+			slog.Int("attempts", int(row.Attempts)),
+		)
+	}
+	return nil
+}
+
+// List returns entries newest first.
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]Entry, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	s.maybePrune(ctx, store)
+	status := strings.TrimSpace(filter.Status)
+	switch status {
+	case "":
+		status = StatusDead
+	case StatusPending, StatusSent, StatusDead:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	var pgBotID pgtype.UUID
+	if botID := strings.TrimSpace(filter.BotID); botID != "" {
+		if pgBotID, err = db.ParseUUID(botID); err != nil {
+			return nil, fmt.Errorf("invalid bot id: %w", err)
+		}
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+	rows, err := store.ListChannelOutbox(ctx, sqlc.ListChannelOutboxParams{
+		Status:   status,
+		BotID:    pgBotID,
+		RowLimit: int32(limit), //nolint:gosec // bounded by maxListLimit.
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list outbox entries: %w", err)
+	}
+	items := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		item, err := s.toEntry(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Requeue makes a pending or dead entry due now with a fresh set of
+// attempts. The retry worker picks it up on its next poll.
+func (s *Service) Requeue(ctx context.Context, id string) (Entry, error) {
+	store, err := s.store()
+	if err != nil {
+		return Entry{}, err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return Entry{}, ErrEntryNotFound
+	}
+	row, err := store.GetChannelOutbox(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Entry{}, ErrEntryNotFound
+		}
+		return Entry{}, fmt.Errorf("get outbox entry: %w", err)
+	}
+	if row.Status == StatusSent {
+		return Entry{}, ErrAlreadySent
+	}
+	row, err = store.RequeueChannelOutbox(ctx, pgID)
+	if err != nil {
+		return Entry{}, fmt.Errorf("requeue outbox entry: %w", err)
+	}
+	return s.toEntry(ctx, row)
+}
+
+// maybePrune deletes entries delivered more than sentRetention ago, at most
+// once per pruneInterval.
+func (s *Service) maybePrune(ctx context.Context, store outboxQueries) {
+	now := s.now()
+	s.mu.Lock()
+	if now.Sub(s.lastPruned) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPruned = now
+	s.mu.Unlock()
+
+	before := pgtype.Timestamptz{Time: now.Add(-sentRetention).UTC(), Valid: true}
+	n, err := store.DeleteSentChannelOutboxBefore(ctx, before)
+	if err != nil {
+		s.logger.Warn("prune sent outbox entries failed", slog.Any("error", err))
+		return
+	}
+	if n > 0 {
+		s.logger.Info("pruned sent outbox entries", slog.Int64("count", n))
+	}
+}
+
+func (s *Service) decodeMessage(ctx context.Context, row sqlc.ChannelOutbox) (channel.Message, error) {
+	raw := row.Message
+	if s.cipher != nil {
+		opened, err := s.cipher.OpenJSON(ctx, row.BotID, raw)
+		if err != nil {
+			return channel.Message{}, fmt.Errorf("open outbound message: %w", err)
+		}
+		raw = opened
+	}
+	var msg channel.Message
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return channel.Message{}, fmt.Errorf("decode outbound message: %w", err)
+	}
+	return msg, nil
+}
+
+func (s *Service) toEntry(ctx context.Context, row sqlc.ChannelOutbox) (Entry, error) {
+	msg, err := s.decodeMessage(ctx, row)
+	if err != nil {
+		return Entry{}, err
+	}
+	item := Entry{
+		ID:              row.ID.String(),
+		BotID:           row.BotID.String(),
+		ChannelConfigID: row.ChannelConfigID,
+		ChannelType:     channel.ChannelType(row.ChannelType),
+		Target:          row.Target,
+		Message:         msg,
+		Error:           row.Error,
+		Attempts:        int(row.Attempts),
+		Status:          row.Status,
+		CreatedAt:       db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:       db.TimeFromPg(row.UpdatedAt),
+	}
+	if row.Status == StatusPending && row.NextAttemptAt.Valid {
+		next := row.NextAttemptAt.Time
+		item.NextAttemptAt = &next
+	}
+	if row.SentAt.Valid {
+		sentAt := row.SentAt.Time
+		item.SentAt = &sentAt
+	}
+	return item, nil
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	text := err.Error()
+	if len(text) > maxErrorBytes {
+		text = strings.ToValidUTF8(text[:maxErrorBytes], "")
+	}
+	return text
+}
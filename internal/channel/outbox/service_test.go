@@ -0,0 +1,209 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const testBotID = "11111111-1111-1111-1111-111111111111"
+
+type fakeOutboxQueries struct {
+	dbstore.Queries
+
+	rows   map[pgtype.UUID]sqlc.ChannelOutbox
+	seq    byte
+	prunes []pgtype.Timestamptz
+}
+
+func (f *fakeOutboxQueries) DeleteSentChannelOutboxBefore(_ context.Context, before pgtype.Timestamptz) (int64, error) {
+	f.prunes = append(f.prunes, before)
+	return 0, nil
+}
+
+func (f *fakeOutboxQueries) InsertChannelOutbox(_ context.Context, arg sqlc.InsertChannelOutboxParams) (sqlc.ChannelOutbox, error) {
+	f.seq++
+	row := sqlc.ChannelOutbox{
+		ID:                pgtype.UUID{Bytes: [16]byte{f.seq}, Valid: true},
+		BotID:             arg.BotID,
+		ChannelConfigID:   arg.ChannelConfigID,
+		ChannelType:       arg.ChannelType,
+		Target:            arg.Target,
+		ChannelIdentityID: arg.ChannelIdentityID,
+		Message:           arg.Message,
+		Error:             arg.Error,
+		Status:            StatusPending,
+		NextAttemptAt:     arg.NextAttemptAt,
+	}
+	f.rows[row.ID] = row
+	return row, nil
+}
+
+func (f *fakeOutboxQueries) GetChannelOutbox(_ context.Context, id pgtype.UUID) (sqlc.ChannelOutbox, error) {
+	row, ok := f.rows[id]
+	if !ok {
+		return sqlc.ChannelOutbox{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeOutboxQueries) ListChannelOutbox(_ context.Context, arg sqlc.ListChannelOutboxParams) ([]sqlc.ChannelOutbox, error) {
+	var out []sqlc.ChannelOutbox
+	for _, row := range f.rows {
+		if row.Status == arg.Status {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeOutboxQueries) ClaimDueChannelOutbox(_ context.Context, _ sqlc.ClaimDueChannelOutboxParams) ([]sqlc.ChannelOutbox, error) {
+	var out []sqlc.ChannelOutbox
+	for _, row := range f.rows {
+		if row.Status == StatusPending {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeOutboxQueries) MarkChannelOutboxSent(_ context.Context, id pgtype.UUID) (sqlc.ChannelOutbox, error) {
+	row := f.rows[id]
+	row.Status = StatusSent
+	row.Attempts++
+	f.rows[id] = row
+	return row, nil
+}
+
+func (f *fakeOutboxQueries) MarkChannelOutboxFailed(_ context.Context, arg sqlc.MarkChannelOutboxFailedParams) (sqlc.ChannelOutbox, error) {
+	row := f.rows[arg.ID]
+	row.Status = arg.Status
+	row.Error = arg.Error
+	row.NextAttemptAt = arg.NextAttemptAt
+	row.Attempts++
+	f.rows[arg.ID] = row
+	return row, nil
+}
+
+func (f *fakeOutboxQueries) RequeueChannelOutbox(_ context.Context, id pgtype.UUID) (sqlc.ChannelOutbox, error) {
+	row := f.rows[id]
+	row.Status = StatusPending
+	row.Attempts = 0
+	f.rows[id] = row
+	return row, nil
+}
+
+// fakeCipher marks sealed documents with a prefix.
+type fakeCipher struct{}
+
+var sealedPrefix = []byte("sealed:")
+
+func (fakeCipher) SealJSON(_ context.Context, _ pgtype.UUID, doc []byte) ([]byte, error) {
+	return append(append([]byte{}, sealedPrefix...), doc...), nil
+}
+
+func (fakeCipher) OpenJSON(_ context.Context, _ pgtype.UUID, stored []byte) ([]byte, error) {
+	return bytes.TrimPrefix(stored, sealedPrefix), nil
+}
+
+func (fakeCipher) SealText(_ context.Context, _ pgtype.UUID, text string) (string, error) {
+	return string(sealedPrefix) + text, nil
+}
+
+func (fakeCipher) OpenText(_ context.Context, _ pgtype.UUID, stored string) (string, error) {
+	return string(bytes.TrimPrefix([]byte(stored), sealedPrefix)), nil
+}
+
+func TestServiceQueueDeadLetterAndRequeue(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queries := &fakeOutboxQueries{rows: map[pgtype.UUID]sqlc.ChannelOutbox{}}
+	service := NewService(nil, queries)
+
+	err := service.EnqueueOutbound(ctx, channel.OutboxEntry{
+		BotID:       testBotID,
+		ChannelType: "telegram",
+		Request:     channel.SendRequest{Target: "chat-1", Message: channel.Message{Text: "hello"}},
+	}, errors.New("status 502"), time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("EnqueueOutbound: %v", err)
+	}
+	due, err := service.ClaimDueOutbound(ctx, 10, time.Minute)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("ClaimDueOutbound = %v, %v; want one entry", due, err)
+	}
+	if due[0].Request.Target != "chat-1" || due[0].Request.Message.Text != "hello" {
+		t.Fatalf("claimed entry = %+v, want the queued send", due[0])
+	}
+	if err := service.MarkOutboundFailed(ctx, due[0].ID, errors.New("status 503"), time.Time{}); err != nil {
+		t.Fatalf("MarkOutboundFailed: %v", err)
+	}
+
+	dead, err := service.List(ctx, ListFilter{})
+	if err != nil || len(dead) != 1 || dead[0].Error != "status 503" {
+		t.Fatalf("List(dead) = %+v, %v; want the dead-lettered entry", dead, err)
+	}
+	requeued, err := service.Requeue(ctx, dead[0].ID)
+	if err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	if requeued.Status != StatusPending || requeued.Attempts != 0 {
+		t.Fatalf("requeued = %+v, want pending with fresh attempts", requeued)
+	}
+
+	if err := service.MarkOutboundSent(ctx, requeued.ID); err != nil {
+		t.Fatalf("MarkOutboundSent: %v", err)
+	}
+	if _, err := service.Requeue(ctx, requeued.ID); !errors.Is(err, ErrAlreadySent) {
+		t.Fatalf("Requeue sent entry = %v, want ErrAlreadySent", err)
+	}
+	if _, err := service.List(ctx, ListFilter{Status: "bogus"}); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("List(bogus) = %v, want ErrInvalidStatus", err)
+	}
+}
+
+func TestServiceSealsMessagesAndPrunesSentEntries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	queries := &fakeOutboxQueries{rows: map[pgtype.UUID]sqlc.ChannelOutbox{}}
+	service := NewService(nil, queries)
+	service.SetContentCipher(fakeCipher{})
+	service.now = func() time.Time { return now }
+
+	const identityID = "22222222-2222-2222-2222-222222222222"
+	err := service.EnqueueOutbound(ctx, channel.OutboxEntry{
+		BotID:       testBotID,
+		ChannelType: "telegram",
+		Request:     channel.SendRequest{Target: "chat-1", ChannelIdentityID: identityID, Message: channel.Message{Text: "your code is 1234"}},
+	}, errors.New("status 502"), now)
+	if err != nil {
+		t.Fatalf("EnqueueOutbound: %v", err)
+	}
+	for _, row := range queries.rows {
+		if !bytes.HasPrefix(row.Message, sealedPrefix) || row.ChannelIdentityID.String() != identityID {
+			t.Fatalf("stored row = %+v, want a sealed message linked to the identity", row)
+		}
+	}
+	due, err := service.ClaimDueOutbound(ctx, 10, time.Minute)
+	if err != nil || len(due) != 1 || due[0].Request.Message.Text != "your code is 1234" {
+		t.Fatalf("ClaimDueOutbound = %+v, %v; want the opened message", due, err)
+	}
+	if len(queries.prunes) != 1 || !queries.prunes[0].Time.Equal(now.Add(-sentRetention)) {
+		t.Fatalf("prunes = %+v, want one prune of entries sent before the retention", queries.prunes)
+	}
+	if _, err := service.List(ctx, ListFilter{}); err != nil || len(queries.prunes) != 1 {
+		t.Fatalf("List = %v, prunes = %d; want no second prune within the interval", err, len(queries.prunes))
+	}
+}
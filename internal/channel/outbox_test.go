@@ -0,0 +1,189 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+type flakyAdapter struct {
+	*fakeAdapter
+	errs []error
+}
+
+func (f *flakyAdapter) Descriptor() Descriptor {
+	desc := f.fakeAdapter.Descriptor()
+	desc.OutboundPolicy = OutboundPolicy{RetryMax: 1, RetryBackoffMs: 1}
+	return desc
+}
+
+func (f *flakyAdapter) Send(ctx context.Context, cfg ChannelConfig, msg PreparedOutboundMessage) error {
+	f.mu.Lock()
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		f.mu.Unlock()
+		return err
+	}
+	f.mu.Unlock()
+	return f.fakeAdapter.Send(ctx, cfg, msg)
+}
+
+type outboxUpdate struct {
+	id      string
+	sent    bool
+	retryAt time.Time
+}
+
+type fakeOutboxStore struct {
+	mu       sync.Mutex
+	queued   []OutboxEntry
+	retryAts []time.Time
+	due      []OutboxEntry
+	updates  []outboxUpdate
+}
+
+func (f *fakeOutboxStore) EnqueueOutbound(_ context.Context, entry OutboxEntry, _ error, retryAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queued = append(f.queued, entry)
+	f.retryAts = append(f.retryAts, retryAt)
+	return nil
+}
+
+func (f *fakeOutboxStore) ClaimDueOutbound(context.Context, int, time.Duration) ([]OutboxEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	due := f.due
+	f.due = nil
+	return due, nil
+}
+
+func (f *fakeOutboxStore) MarkOutboundSent(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, outboxUpdate{id: id, sent: true})
+	return nil
+}
+
+func (f *fakeOutboxStore) MarkOutboundFailed(_ context.Context, id string, _ error, retryAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, outboxUpdate{id: id, retryAt: retryAt})
+	return nil
+}
+
+func newOutboxManager(t *testing.T, errs ...error) (*Manager, *flakyAdapter, *fakeOutboxStore) {
+	t.Helper()
+	store := &fakeConfigStore{effectiveConfig: ChannelConfig{
+		ID:          "cfg-1",
+		BotID:       "bot-1",
+		ChannelType: ChannelType("test"),
+		UpdatedAt:   time.Now(),
+	}}
+	adapter := &flakyAdapter{fakeAdapter: &fakeAdapter{channelType: ChannelType("test")}, errs: errs}
+	manager := NewManager(slog.New(slog.DiscardHandler), NewRegistry(), store, &fakeInboundProcessorIntegration{}, WithOutboundDedupeWindow(0))
+	manager.RegisterAdapter(adapter)
+	outbox := &fakeOutboxStore{}
+	manager.SetOutbox(outbox)
+	return manager, adapter, outbox
+}
+
+func TestManagerSendQueuesTransientFailure(t *testing.T) {
+	t.Parallel()
+
+	manager, _, outbox := newOutboxManager(t, errors.New("telegram api error: status 502 Bad Gateway"), errors.New("Bad Request: chat not found"))
+	req := SendRequest{Target: "chat-1", Message: Message{Text: "hello"}}
+	if err := manager.Send(context.Background(), "bot-1", ChannelType("test"), req); err != nil {
+		t.Fatalf("Send with transient failure = %v, want queued", err)
+	}
+	if len(outbox.queued) != 1 || outbox.queued[0].Request.Target != "chat-1" || outbox.queued[0].ChannelConfigID != "cfg-1" {
+		t.Fatalf("queued = %+v, want one entry for chat-1", outbox.queued)
+	}
+	if wait := time.Until(outbox.retryAts[0]); wait <= 0 || wait > outboxBaseBackoff {
+		t.Fatalf("first retry in %s, want within %s", wait, outboxBaseBackoff)
+	}
+	if err := manager.Send(context.Background(), "bot-1", ChannelType("test"), req); err == nil {
+		t.Fatal("Send with permanent failure = nil, want error")
+	}
+	if len(outbox.queued) != 1 {
+		t.Fatalf("permanent failure queued: %+v", outbox.queued)
+	}
+}
+
+func TestManagerRetryOutbox(t *testing.T) {
+	t.Parallel()
+
+	transient := errors.New("discord: HTTP 503 Service Unavailable")
+	manager, adapter, outbox := newOutboxManager(t, transient, transient)
+	entry := func(id string, attempts int) OutboxEntry {
+		return OutboxEntry{
+			ID:          id,
+			BotID:       "bot-1",
+			ChannelType: ChannelType("test"),
+			Request:     SendRequest{Target: "chat-" + id, Message: Message{Text: "hello " + id}},
+			Attempts:    attempts,
+		}
+	}
+	outbox.due = []OutboxEntry{entry("retry", 1), entry("dead", OutboxMaxAttempts-1), entry("sent", 2)}
+
+	manager.retryOutbox(context.Background())
+
+	if len(outbox.queued) != 0 {
+		t.Fatalf("retry queued again: %+v", outbox.queued)
+	}
+	if len(outbox.updates) != 3 {
+		t.Fatalf("updates = %+v, want 3", outbox.updates)
+	}
+	if u := outbox.updates[0]; u.id != "retry" || u.sent || u.retryAt.IsZero() {
+		t.Fatalf("retry update = %+v, want rescheduled", u)
+	}
+	if u := outbox.updates[1]; u.id != "dead" || u.sent || !u.retryAt.IsZero() {
+		t.Fatalf("dead update = %+v, want dead-lettered", u)
+	}
+	if u := outbox.updates[2]; u.id != "sent" || !u.sent {
+		t.Fatalf("sent update = %+v, want sent", u)
+	}
+	adapter.mu.Lock()
+	defer adapter.mu.Unlock()
+	if len(adapter.sent) != 1 || adapter.sent[0].Target != "chat-sent" {
+		t.Fatalf("adapter sent = %+v, want chat-sent", adapter.sent)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	t.Parallel()
+
+	for attempts, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		10: time.Hour,
+	} {
+		if got := outboxBackoff(attempts); got != want {
+			t.Errorf("outboxBackoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestIsTransientSendError(t *testing.T) {
+	t.Parallel()
+
+	for text, want := range map[string]bool{
+		"telegram: Too Many Requests: retry after 5": true,
+		"slack api error: status 500":                true,
+		"read tcp: connection reset by peer":         true,
+		"Bad Request: chat not found":                false,
+		"message 5023 not found":                     false,
+	} {
+		if got := isTransientSendError(errors.New(text)); got != want {
+			t.Errorf("isTransientSendError(%q) = %v, want %v", text, got, want)
+		}
+	}
+	if isTransientSendError(context.Canceled) {
+		t.Error("context.Canceled reported transient")
+	}
+}
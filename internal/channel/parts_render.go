@@ -23,7 +23,7 @@ func RenderPartsAsMarkdown(parts []MessagePart) string {
 		switch part.Type {
 		case MessagePartText:
 			writeDegradeMarkdownInline(&b, part.Text, part.Styles)
-		case MessagePartLink:
+		case MessagePartLink, MessagePartLinkPreview:
 			writeDegradeMarkdownLink(&b, part)
 		case MessagePartCodeBlock:
 			writeDegradeMarkdownCodeBlock(&b, part)
@@ -62,7 +62,7 @@ func RenderPartsAsPlain(parts []MessagePart) string {
 			if t != "" {
 				lines = append(lines, t)
 			}
-		case MessagePartLink:
+		case MessagePartLink, MessagePartLinkPreview:
 			text := strings.TrimSpace(part.Text)
 			url := strings.TrimSpace(part.URL)
 			switch {
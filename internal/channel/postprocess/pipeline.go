@@ -0,0 +1,320 @@
+// Package postprocess rewrites a bot's replies before they are delivered to
+// a channel. Each bot has an ordered list of processors (regex replacements,
+// banned-phrase filters, signatures, emoji stripping), optionally limited to
+// some platforms.
+package postprocess
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Processor types.
+const (
+	TypeRegexReplace  = "regex_replace"
+	TypeBannedPhrases = "banned_phrases"
+	TypeSignature     = "signature"
+	TypeStripEmoji    = "strip_emoji"
+)
+
+const (
+	maxProcessors    = 20
+	maxPatternLength = 500
+	maxPhrases       = 100
+	maxTextLength    = 500
+)
+
+var ErrInvalidProcessor = errors.New("invalid output processor")
+
+// Processor is one configured step of a bot's output pipeline. Which fields
+// are used depends on Type.
+type Processor struct {
+	Type string `json:"type"`
+	// Disabled keeps the processor in the list without running it.
+	Disabled bool `json:"disabled,omitempty"`
+	// Platforms limits the processor to these channel types; empty means all.
+	Platforms []string `json:"platforms,omitempty"`
+	// Pattern is the regular expression of a regex_replace processor.
+	Pattern string `json:"pattern,omitempty"`
+	// Phrases are matched case-insensitively by a banned_phrases processor.
+	Phrases []string `json:"phrases,omitempty"`
+	// Replacement replaces matches of regex_replace (which may reference
+	// groups as $1) and banned_phrases processors.
+	Replacement string `json:"replacement,omitempty"`
+	// Text is appended on its own paragraph by a signature processor.
+	Text string `json:"text,omitempty"`
+}
+
+// Pipeline is a compiled, ordered list of processors.
+type Pipeline struct {
+	steps []step
+}
+
+type step struct {
+	kind        string
+	platforms   map[channel.ChannelType]struct{}
+	re          *regexp.Regexp
+	replacement string
+	text        string
+}
+
+// Normalize trims the processors and checks them, returning the list as it
+// should be stored.
+func Normalize(processors []Processor) ([]Processor, error) {
+	if len(processors) > maxProcessors {
+		return nil, fmt.Errorf("%w: at most %d processors are allowed", ErrInvalidProcessor, maxProcessors)
+	}
+	out := make([]Processor, 0, len(processors))
+	for i, p := range processors {
+		p.Type = strings.ToLower(strings.TrimSpace(p.Type))
+		platforms := make([]string, 0, len(p.Platforms))
+		for _, platform := range p.Platforms {
+			if platform = strings.ToLower(strings.TrimSpace(platform)); platform != "" {
+				platforms = append(platforms, platform)
+			}
+		}
+		p.Platforms = platforms
+		switch p.Type {
+		case TypeRegexReplace:
+			if p.Pattern == "" {
+				return nil, fmt.Errorf("%w: processor %d: pattern is required", ErrInvalidProcessor, i)
+			}
+			if len(p.Pattern) > maxPatternLength {
+				return nil, fmt.Errorf("%w: processor %d: pattern is longer than %d characters", ErrInvalidProcessor, i, maxPatternLength)
+			}
+			if _, err := regexp.Compile(p.Pattern); err != nil {
+				return nil, fmt.Errorf("%w: processor %d: %s", ErrInvalidProcessor, i, err.Error())
+			}
+			p.Phrases, p.Text = nil, ""
+		case TypeBannedPhrases:
+			phrases := make([]string, 0, len(p.Phrases))
+			for _, phrase := range p.Phrases {
+				if phrase = strings.TrimSpace(phrase); phrase != "" {
+					phrases = append(phrases, phrase)
+				}
+			}
+			if len(phrases) == 0 {
+				return nil, fmt.Errorf("%w: processor %d: phrases are required", ErrInvalidProcessor, i)
+			}
+			if len(phrases) > maxPhrases {
+				return nil, fmt.Errorf("%w: processor %d: at most %d phrases are allowed", ErrInvalidProcessor, i, maxPhrases)
+			}
+			p.Phrases = phrases
+			p.Pattern, p.Text = "", ""
+		case TypeSignature:
+			p.Text = strings.TrimSpace(p.Text)
+			if p.Text == "" {
+				return nil, fmt.Errorf("%w: processor %d: text is required", ErrInvalidProcessor, i)
+			}
+			if len(p.Text) > maxTextLength {
+				return nil, fmt.Errorf("%w: processor %d: text is longer than %d characters", ErrInvalidProcessor, i, maxTextLength)
+			}
+			p.Pattern, p.Phrases, p.Replacement = "", nil, ""
+		case TypeStripEmoji:
+			p.Pattern, p.Phrases, p.Replacement, p.Text = "", nil, "", ""
+		default:
+			return nil, fmt.Errorf("%w: processor %d: unknown type %q", ErrInvalidProcessor, i, p.Type)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Compile builds the pipeline of the enabled processors. It returns nil when
+// no processor is enabled.
+func Compile(processors []Processor) (*Pipeline, error) {
+	normalized, err := Normalize(processors)
+	if err != nil {
+		return nil, err
+	}
+	var steps []step
+	for _, p := range normalized {
+		if p.Disabled {
+			continue
+		}
+		s := step{kind: p.Type, replacement: p.Replacement, text: p.Text}
+		if len(p.Platforms) > 0 {
+			s.platforms = make(map[channel.ChannelType]struct{}, len(p.Platforms))
+			for _, platform := range p.Platforms {
+				s.platforms[channel.ChannelType(platform)] = struct{}{}
+			}
+		}
+		switch p.Type {
+		case TypeRegexReplace:
+			s.re = regexp.MustCompile(p.Pattern)
+		case TypeBannedPhrases:
+			s.re = bannedPhrasesPattern(p.Phrases)
+		}
+		steps = append(steps, s)
+	}
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	return &Pipeline{steps: steps}, nil
+}
+
+// bannedPhrasesPattern matches any of phrases, preferring the longest.
+func bannedPhrasesPattern(phrases []string) *regexp.Regexp {
+	sorted := append([]string(nil), phrases...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	quoted := make([]string, len(sorted))
+	for i, phrase := range sorted {
+		quoted[i] = regexp.QuoteMeta(phrase)
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+}
+
+// ForPlatform returns the steps that apply to platform, or nil when none do.
+func (p *Pipeline) ForPlatform(platform channel.ChannelType) *Pipeline {
+	if p == nil {
+		return nil
+	}
+	var steps []step
+	for _, s := range p.steps {
+		if s.platforms != nil {
+			if _, ok := s.platforms[platform]; !ok {
+				continue
+			}
+		}
+		steps = append(steps, s)
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	return &Pipeline{steps: steps}
+}
+
+// StreamSafe reports whether text deltas can be processed one by one. Regex
+// replacements and banned phrases can match across deltas, so their output
+// is only correct on the whole reply.
+func (p *Pipeline) StreamSafe() bool {
+	if p == nil {
+		return true
+	}
+	for _, s := range p.steps {
+		if s.kind != TypeStripEmoji && s.kind != TypeSignature {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyDelta processes a streamed text delta. Signatures are left to the
+// final message.
+func (p *Pipeline) ApplyDelta(delta string) string {
+	if p == nil {
+		return delta
+	}
+	for _, s := range p.steps {
+		if s.kind != TypeSignature {
+			delta = s.rewrite(delta)
+		}
+	}
+	return delta
+}
+
+// ApplyText runs the pipeline over a plain reply.
+func (p *Pipeline) ApplyText(text string) string {
+	if p == nil {
+		return text
+	}
+	for _, s := range p.steps {
+		if s.kind == TypeSignature {
+			text = appendSignature(text, s.text)
+			continue
+		}
+		text = s.rewrite(text)
+	}
+	return text
+}
+
+// ApplyMessage runs the pipeline over the text and text parts of msg. Code
+// blocks, links and mentions are left untouched.
+func (p *Pipeline) ApplyMessage(msg channel.Message) channel.Message {
+	if p == nil {
+		return msg
+	}
+	if len(msg.Parts) > 0 {
+		msg.Parts = append([]channel.MessagePart(nil), msg.Parts...)
+	}
+	for _, s := range p.steps {
+		if s.kind == TypeSignature {
+			if strings.TrimSpace(msg.Text) != "" || len(msg.Parts) == 0 {
+				msg.Text = appendSignature(msg.Text, s.text)
+			} else {
+				msg.Parts = append(msg.Parts, channel.MessagePart{Type: channel.MessagePartText, Text: "\n\n" + s.text})
+			}
+			continue
+		}
+		msg.Text = s.rewrite(msg.Text)
+		for i := range msg.Parts {
+			switch msg.Parts[i].Type {
+			case channel.MessagePartCodeBlock, channel.MessagePartLink, channel.MessagePartMention:
+				continue
+			}
+			msg.Parts[i].Text = s.rewrite(msg.Parts[i].Text)
+		}
+	}
+	return msg
+}
+
+func (s step) rewrite(text string) string {
+	if text == "" {
+		return text
+	}
+	switch s.kind {
+	case TypeRegexReplace:
+		return s.re.ReplaceAllString(text, s.replacement)
+	case TypeBannedPhrases:
+		return s.re.ReplaceAllLiteralString(text, s.replacement)
+	case TypeStripEmoji:
+		return stripEmoji(text)
+	}
+	return text
+}
+
+func appendSignature(text, signature string) string {
+	trimmed := strings.TrimRight(text, " \t\n")
+	if strings.HasSuffix(trimmed, signature) {
+		return text
+	}
+	if trimmed == "" {
+		return signature
+	}
+	return trimmed + "\n\n" + signature
+}
+
+func stripEmoji(text string) string {
+	if !strings.ContainsFunc(text, isEmojiRune) {
+		return text
+	}
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		if !isEmojiRune(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isEmojiRune reports whether r is a pictograph or an emoji modifier such as
+// a variation selector, zero-width joiner or tag character.
+func isEmojiRune(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF,
+		r >= 0x2600 && r <= 0x27BF,
+		r >= 0x2B00 && r <= 0x2BFF,
+		r >= 0xE0020 && r <= 0xE007F,
+		r == 0x200D, r == 0xFE0F, r == 0x20E3,
+		r == 0x231A, r == 0x231B, r == 0x23F0, r == 0x23F3,
+		r >= 0x23E9 && r <= 0x23EC:
+		return true
+	}
+	return false
+}
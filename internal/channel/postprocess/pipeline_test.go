@@ -0,0 +1,132 @@
+package postprocess
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+func TestPipelineAppliesProcessorsInOrder(t *testing.T) {
+	t.Parallel()
+
+	pipeline, err := Compile([]Processor{
+		{Type: TypeRegexReplace, Pattern: `(?i)\bcolour\b`, Replacement: "color"},
+		{Type: TypeBannedPhrases, Phrases: []string{"As an AI language model,"}},
+		{Type: TypeStripEmoji},
+		{Type: TypeSignature, Text: "— Memo"},
+	})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	got := pipeline.ApplyText("as an ai language model, I like this Colour 🎨")
+	want := " I like this color\n\n— Memo"
+	if got != want {
+		t.Fatalf("ApplyText() = %q, want %q", got, want)
+	}
+	if again := pipeline.ApplyText(got); again != want {
+		t.Fatalf("signature appended twice: %q", again)
+	}
+}
+
+func TestPipelineForPlatformAndDisabled(t *testing.T) {
+	t.Parallel()
+
+	pipeline, err := Compile([]Processor{
+		{Type: TypeStripEmoji, Platforms: []string{"Telegram"}},
+		{Type: TypeSignature, Text: "sig", Disabled: true},
+	})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if got := pipeline.ForPlatform("discord"); got != nil {
+		t.Fatalf("expected no steps for discord, got %+v", got)
+	}
+	if got := pipeline.ForPlatform("telegram").ApplyText("hi 👋"); got != "hi " {
+		t.Fatalf("unexpected telegram output %q", got)
+	}
+}
+
+func TestCompileRejectsInvalidProcessors(t *testing.T) {
+	t.Parallel()
+
+	for _, processors := range [][]Processor{
+		{{Type: "unknown"}},
+		{{Type: TypeRegexReplace, Pattern: "("}},
+		{{Type: TypeBannedPhrases, Phrases: []string{" "}}},
+		{{Type: TypeSignature}},
+	} {
+		if _, err := Compile(processors); !errors.Is(err, ErrInvalidProcessor) {
+			t.Fatalf("Compile(%+v) error = %v, want ErrInvalidProcessor", processors, err)
+		}
+	}
+	pipeline, err := Compile([]Processor{{Type: TypeStripEmoji, Disabled: true}})
+	if err != nil || pipeline != nil {
+		t.Fatalf("expected nil pipeline for disabled processors, got %+v, %v", pipeline, err)
+	}
+}
+
+func TestApplyMessageSkipsCodeBlocks(t *testing.T) {
+	t.Parallel()
+
+	pipeline, err := Compile([]Processor{{Type: TypeRegexReplace, Pattern: "foo", Replacement: "bar"}})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	msg := pipeline.ApplyMessage(channel.Message{Parts: []channel.MessagePart{
+		{Type: channel.MessagePartText, Text: "foo"},
+		{Type: channel.MessagePartCodeBlock, Text: "foo()"},
+	}})
+	if msg.Parts[0].Text != "bar" || msg.Parts[1].Text != "foo()" {
+		t.Fatalf("unexpected parts: %+v", msg.Parts)
+	}
+}
+
+type recordingStream struct {
+	events []channel.StreamEvent
+}
+
+func (s *recordingStream) Push(_ context.Context, event channel.StreamEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (*recordingStream) Close(context.Context) error { return nil }
+
+func TestStreamWithholdsDeltasForWholeReplyProcessors(t *testing.T) {
+	t.Parallel()
+
+	pipeline, err := Compile([]Processor{{Type: TypeBannedPhrases, Phrases: []string{"secret"}, Replacement: "***"}})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	primary := &recordingStream{}
+	stream := NewStream(primary, pipeline)
+	ctx := context.Background()
+	_ = stream.Push(ctx, channel.StreamEvent{Type: channel.StreamEventDelta, Delta: "sec"})
+	_ = stream.Push(ctx, channel.StreamEvent{Type: channel.StreamEventDelta, Delta: "ret"})
+	_ = stream.Push(ctx, channel.StreamEvent{Type: channel.StreamEventFinal, Final: &channel.StreamFinalizePayload{Message: channel.Message{Text: "the secret"}}})
+
+	if len(primary.events) != 1 {
+		t.Fatalf("expected only the final event, got %+v", primary.events)
+	}
+	if got := primary.events[0].Final.Message.Text; got != "the ***" {
+		t.Fatalf("final text = %q", got)
+	}
+}
+
+func TestStreamProcessesDeltasWhenStreamSafe(t *testing.T) {
+	t.Parallel()
+
+	pipeline, err := Compile([]Processor{{Type: TypeStripEmoji}})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	primary := &recordingStream{}
+	_ = NewStream(primary, pipeline).Push(context.Background(), channel.StreamEvent{Type: channel.StreamEventDelta, Delta: "ok ✅"})
+	if len(primary.events) != 1 || primary.events[0].Delta != "ok " {
+		t.Fatalf("unexpected events: %+v", primary.events)
+	}
+}
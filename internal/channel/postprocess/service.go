@@ -0,0 +1,113 @@
+package postprocess
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+type processorQueries interface {
+	GetBotOutputProcessors(ctx context.Context, botID pgtype.UUID) (sqlc.BotOutputProcessor, error)
+	UpsertBotOutputProcessors(ctx context.Context, arg sqlc.UpsertBotOutputProcessorsParams) (sqlc.BotOutputProcessor, error)
+}
+
+// Service stores the output processors of each bot.
+type Service struct {
+	queries dbstore.Queries
+	logger  *slog.Logger
+}
+
+// NewService creates an output processor service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "output_postprocess")),
+	}
+}
+
+func (s *Service) store() (processorQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("output processor service not configured")
+	}
+	store, ok := s.queries.(processorQueries)
+	if !ok {
+		return nil, errors.New("output processor queries not supported by store")
+	}
+	return store, nil
+}
+
+// List returns the bot's processors in pipeline order.
+func (s *Service) List(ctx context.Context, botID string) ([]Processor, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot id: %w", err)
+	}
+	row, err := store.GetBotOutputProcessors(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []Processor{}, nil
+		}
+		return nil, fmt.Errorf("get output processors: %w", err)
+	}
+	processors := []Processor{}
+	if len(row.Processors) > 0 {
+		if err := json.Unmarshal(row.Processors, &processors); err != nil {
+			return nil, fmt.Errorf("decode output processors: %w", err)
+		}
+	}
+	return processors, nil
+}
+
+// Replace validates processors and stores them as the bot's pipeline,
+// replacing the previous list. The list order is the pipeline order.
+func (s *Service) Replace(ctx context.Context, botID string, processors []Processor) ([]Processor, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot id: %w", err)
+	}
+	normalized, err := Normalize(processors)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("encode output processors: %w", err)
+	}
+	if _, err := store.UpsertBotOutputProcessors(ctx, sqlc.UpsertBotOutputProcessorsParams{
+		BotID:      pgBotID,
+		Processors: raw,
+	}); err != nil {
+		return nil, fmt.Errorf("store output processors: %w", err)
+	}
+	return normalized, nil
+}
+
+// Pipeline returns the bot's compiled pipeline, or nil when it has no
+// enabled processors.
+func (s *Service) Pipeline(ctx context.Context, botID string) (*Pipeline, error) {
+	processors, err := s.List(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(processors)
+}
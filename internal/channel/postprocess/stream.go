@@ -0,0 +1,48 @@
+package postprocess
+
+import (
+	"context"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// processingStream runs a pipeline over the replies pushed to an IM stream.
+// When the pipeline cannot process deltas one by one, deltas are withheld so
+// unprocessed text never reaches the platform, and the reply is delivered
+// whole by the final event.
+type processingStream struct {
+	primary  channel.OutboundStream
+	pipeline *Pipeline
+}
+
+// NewStream wraps primary so text deltas and final messages pass through
+// pipeline. It returns primary unchanged when pipeline is nil.
+func NewStream(primary channel.OutboundStream, pipeline *Pipeline) channel.OutboundStream {
+	if primary == nil || pipeline == nil {
+		return primary
+	}
+	return &processingStream{primary: primary, pipeline: pipeline}
+}
+
+func (s *processingStream) Push(ctx context.Context, event channel.StreamEvent) error {
+	switch event.Type {
+	case channel.StreamEventDelta:
+		if !s.pipeline.StreamSafe() {
+			return nil
+		}
+		event.Delta = s.pipeline.ApplyDelta(event.Delta)
+	case channel.StreamEventFinal:
+		if event.Final != nil {
+			msg := s.pipeline.ApplyMessage(event.Final.Message)
+			if msg.IsEmpty() {
+				return nil
+			}
+			event.Final = &channel.StreamFinalizePayload{Message: msg}
+		}
+	}
+	return s.primary.Push(ctx, event)
+}
+
+func (s *processingStream) Close(ctx context.Context) error {
+	return s.primary.Close(ctx)
+}
@@ -83,6 +83,16 @@ func (r *Registry) DirectoryAdapter(channelType ChannelType) (ChannelDirectoryAd
 	return dir, ok
 }
 
+// MentionFormatter returns the mention formatter for the given channel type if its adapter implements MentionFormatter.
+func (r *Registry) MentionFormatter(channelType ChannelType) (MentionFormatter, bool) {
+	adapter, ok := r.Get(channelType)
+	if !ok {
+		return nil, false
+	}
+	formatter, ok := adapter.(MentionFormatter)
+	return formatter, ok
+}
+
 // List returns all registered adapters.
 func (r *Registry) List() []Adapter {
 	r.mu.RLock()
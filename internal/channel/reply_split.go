@@ -0,0 +1,252 @@
+package channel
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ReplySplittingRoutingKey is the channel config routing flag that enables
+// reply splitting: final replies are delivered as several short messages
+// sent with a pause between them, instead of one streamed wall of text.
+const ReplySplittingRoutingKey = "reply_splitting"
+
+const (
+	defaultReplySplitRunes = 280
+	defaultReplyPacingMs   = 1200
+	maxReplySegments       = 6
+)
+
+// replySplittingEnabled reports whether cfg opts in to reply splitting.
+func replySplittingEnabled(cfg ChannelConfig) bool {
+	switch v := cfg.Routing[ReplySplittingRoutingKey].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(strings.TrimSpace(v), "true")
+	}
+	return false
+}
+
+// SplitReply segments text into messages of roughly target runes, breaking
+// at paragraphs first and sentence ends second. Fenced code blocks are kept
+// whole. No segment exceeds limit, and at most maxReplySegments are
+// returned; longer replies get proportionally longer segments.
+func SplitReply(text string, target, limit int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if target <= 0 {
+		target = defaultReplySplitRunes
+	}
+	if limit > 0 && target > limit {
+		target = limit
+	}
+	units := replyUnits(text)
+	segments := mergeReplyUnits(units, target, limit)
+	for len(segments) > maxReplySegments && (limit <= 0 || target < limit) {
+		target *= 2
+		if limit > 0 && target > limit {
+			target = limit
+		}
+		segments = mergeReplyUnits(units, target, limit)
+	}
+	return segments
+}
+
+// replyUnits breaks text into paragraphs, and paragraphs into sentences.
+// Each unit records whether it starts a new paragraph.
+func replyUnits(text string) []replyUnit {
+	var units []replyUnit
+	for _, paragraph := range replyParagraphs(text) {
+		if strings.HasPrefix(paragraph, "```") {
+			units = append(units, replyUnit{text: paragraph, paragraph: true})
+			continue
+		}
+		for i, sentence := range replySentences(paragraph) {
+			units = append(units, replyUnit{text: sentence, paragraph: i == 0})
+		}
+	}
+	return units
+}
+
+type replyUnit struct {
+	text      string
+	paragraph bool
+}
+
+// replyParagraphs splits text at blank lines outside fenced code blocks.
+func replyParagraphs(text string) []string {
+	var (
+		paragraphs []string
+		current    []string
+		inFence    bool
+	)
+	flush := func() {
+		if p := strings.TrimSpace(strings.Join(current, "\n")); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+		current = current[:0]
+	}
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if !inFence {
+				flush()
+			}
+			current = append(current, line)
+			if inFence {
+				flush()
+			}
+			inFence = !inFence
+			continue
+		}
+		if !inFence && trimmed == "" {
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return paragraphs
+}
+
+// replySentences splits a paragraph after sentence terminators followed by
+// whitespace. Line breaks inside the paragraph are kept in their sentence.
+func replySentences(paragraph string) []string {
+	var (
+		sentences []string
+		start     int
+	)
+	for i, r := range paragraph {
+		if !strings.ContainsRune(sentenceTerminators, r) {
+			continue
+		}
+		end := i + utf8.RuneLen(r)
+		next, _ := utf8.DecodeRuneInString(paragraph[end:])
+		if end < len(paragraph) && !unicode.IsSpace(next) && !isWideTerminator(r) {
+			continue
+		}
+		if s := strings.TrimSpace(paragraph[start:end]); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = end
+	}
+	if s := strings.TrimSpace(paragraph[start:]); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// isWideTerminator reports whether r ends a sentence without a following
+// space, as CJK punctuation does.
+func isWideTerminator(r rune) bool {
+	return r >= 0x3000
+}
+
+func mergeReplyUnits(units []replyUnit, target, limit int) []string {
+	var (
+		segments []string
+		buf      strings.Builder
+	)
+	flush := func() {
+		if s := strings.TrimSpace(buf.String()); s != "" {
+			segments = append(segments, s)
+		}
+		buf.Reset()
+	}
+	for _, unit := range units {
+		sep := " "
+		if unit.paragraph {
+			sep = "\n\n"
+		}
+		if buf.Len() > 0 && runeLen(buf.String())+len(sep)+runeLen(unit.text) > target {
+			flush()
+		}
+		if limit > 0 && runeLen(unit.text) > limit {
+			flush()
+			segments = append(segments, ChunkText(unit.text, limit)...)
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString(sep)
+		}
+		buf.WriteString(unit.text)
+	}
+	flush()
+	return segments
+}
+
+// replyPacing returns the pause before sending segment, scaled by its length
+// relative to the target size so short follow-ups arrive sooner, as if typed.
+func replyPacing(policy OutboundPolicy, segment string) time.Duration {
+	base := time.Duration(policy.ReplyPacingMs) * time.Millisecond
+	target := policy.ReplySplitRunes
+	if base <= 0 || target <= 0 {
+		return base
+	}
+	scaled := base * time.Duration(runeLen(segment)) / time.Duration(target)
+	return min(max(scaled, base/2), base*2)
+}
+
+// pushFinalSplit delivers a final reply as paced short messages: the first
+// segment finalizes the open adapter stream and the rest are sent after a
+// pause each. Replies with rich parts, or that fit in one segment, take the
+// regular chunking path.
+func (s *managerOutboundStream) pushFinalSplit(ctx context.Context, event StreamEvent) error {
+	msg := normalizeOutboundMessage(event.Final.Message)
+	if len(msg.Parts) > 0 {
+		return s.pushFinalWithChunking(ctx, event)
+	}
+	limit := s.policy.TextChunkLimit
+	if msg.Format == MessageFormatMarkdown && s.policy.RichTextChunkLimit > 0 {
+		limit = min(limit, s.policy.RichTextChunkLimit)
+	}
+	segments := SplitReply(msg.PlainText(), s.policy.ReplySplitRunes, limit)
+	if len(segments) <= 1 {
+		return s.pushFinalWithChunking(ctx, event)
+	}
+	hasAttachments := len(msg.Attachments) > 0
+	deliveries, err := s.prepareChunkedFinalSends(ctx, msg, segments, 1, hasAttachments)
+	if err != nil {
+		return err
+	}
+
+	firstMsg := msg
+	firstMsg.Text = segments[0]
+	firstMsg.Attachments = nil
+	firstMsg.Actions = nil
+	if err := s.pushPrepared(ctx, StreamEvent{
+		Type:     StreamEventFinal,
+		Final:    &StreamFinalizePayload{Message: firstMsg},
+		Metadata: event.Metadata,
+	}); err != nil {
+		return err
+	}
+	for idx, item := range deliveries {
+		if !sleepWithContext(ctx, replyPacing(s.policy, item.message.Text)) {
+			return ctx.Err()
+		}
+		if s.sender != nil {
+			err = s.manager.sendPreparedWithConfig(ctx, s.sender, s.config, item, s.policy)
+		} else {
+			err = s.send(ctx, OutboundMessage{Message: item.message})
+		}
+		if err != nil {
+			if s.manager.logger != nil {
+				s.manager.logger.Error("split reply segment send failed",
+					slog.String("channel", s.channelType.String()),
+					slog.Int("segment_index", idx+1),
+					slog.Int("segments", len(segments)),
+					slog.Any("error", err),
+				)
+			}
+			return err
+		}
+	}
+	return nil
+}
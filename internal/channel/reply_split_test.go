@@ -0,0 +1,106 @@
+package channel
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel/channeltest"
+)
+
+func TestSplitReplyBreaksAtParagraphsAndSentences(t *testing.T) {
+	t.Parallel()
+
+	text := "Sure, here is the plan. First we pack the bags.\n\nThen we leave at dawn. We should arrive by noon. Bring snacks!"
+	got := SplitReply(text, 50, 2000)
+	want := []string{
+		"Sure, here is the plan. First we pack the bags.",
+		"Then we leave at dawn. We should arrive by noon.",
+		"Bring snacks!",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("SplitReply() = %q, want %q", got, want)
+	}
+}
+
+func TestSplitReplyKeepsCodeFencesWhole(t *testing.T) {
+	t.Parallel()
+
+	text := "Run this:\n\n```sh\nmake build\n\nmake test\n```\n\nDone."
+	got := SplitReply(text, 10, 2000)
+	if len(got) != 3 || got[1] != "```sh\nmake build\n\nmake test\n```" {
+		t.Fatalf("unexpected segments %q", got)
+	}
+}
+
+func TestSplitReplyCapsSegmentCount(t *testing.T) {
+	t.Parallel()
+
+	text := strings.Repeat("One short sentence. ", 40)
+	got := SplitReply(text, 20, 2000)
+	if len(got) > maxReplySegments {
+		t.Fatalf("expected at most %d segments, got %d", maxReplySegments, len(got))
+	}
+	for _, segment := range got {
+		if runeLen(segment) > 2000 {
+			t.Fatalf("segment exceeds limit: %d runes", runeLen(segment))
+		}
+	}
+}
+
+func TestSplitReplyCJKSentences(t *testing.T) {
+	t.Parallel()
+
+	got := SplitReply("你好。今天天气很好！我们出去走走吧？", 8, 2000)
+	if len(got) != 3 || got[0] != "你好。" {
+		t.Fatalf("unexpected segments %q", got)
+	}
+}
+
+func TestReplyStreamSplitsFinalWhenRoutingEnabled(t *testing.T) {
+	t.Parallel()
+
+	channelType := ChannelTypeTelegram
+	adapter := &targetResolvingAdapter{
+		channelType:    channelType,
+		outboundPolicy: OutboundPolicy{TextChunkLimit: 2000, ReplySplitRunes: 30, ReplyPacingMs: 1},
+	}
+	registry := NewRegistry()
+	if err := registry.Register(adapter); err != nil {
+		t.Fatalf("register adapter failed: %v", err)
+	}
+	manager := NewManager(nil, registry, nil, nil)
+	manager.attachmentStore = channeltest.NewMemoryAttachmentStore()
+	cfg := ChannelConfig{BotID: "bot-1", ChannelType: channelType, Routing: map[string]any{ReplySplittingRoutingKey: true}}
+	sender := manager.newReplySender(cfg, channelType, "")
+
+	stream, err := sender.OpenStream(context.Background(), "chat-1", StreamOptions{})
+	if err != nil {
+		t.Fatalf("OpenStream failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := stream.Push(ctx, StreamEvent{Type: StreamEventDelta, Delta: "Hello there."}); err != nil {
+		t.Fatalf("Push delta failed: %v", err)
+	}
+	err = stream.Push(ctx, StreamEvent{
+		Type: StreamEventFinal,
+		Final: &StreamFinalizePayload{Message: Message{
+			Format: MessageFormatPlain,
+			Text:   "Hello there. How are you doing today?\n\nI found the answer.",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Push final failed: %v", err)
+	}
+
+	events := adapter.openedStream.Events()
+	if len(events) != 1 || events[0].Type != StreamEventFinal {
+		t.Fatalf("expected only the first segment on the stream, got %+v", events)
+	}
+	if got := events[0].Final.Message.Text; got != "Hello there." {
+		t.Fatalf("first segment = %q", got)
+	}
+	if len(adapter.sent) != 2 || adapter.sent[0].Message.Text != "How are you doing today?" || adapter.sent[1].Message.Text != "I found the answer." {
+		t.Fatalf("unexpected follow-up sends: %+v", adapter.sent)
+	}
+}
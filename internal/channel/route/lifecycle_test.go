@@ -0,0 +1,112 @@
+package route
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const lifecycleBotID = "11111111-1111-1111-1111-111111111111"
+
+type fakeLifecycleQueries struct {
+	dbstore.Queries
+
+	marked      []sqlc.MarkChatRoutesInactiveParams
+	reactivated []sqlc.ReactivateChatRoutesParams
+	inactive    []sqlc.ListInactiveChatRoutesRow
+	prunedAt    []time.Time
+}
+
+func (f *fakeLifecycleQueries) MarkChatRoutesInactive(_ context.Context, arg sqlc.MarkChatRoutesInactiveParams) (int64, error) {
+	f.marked = append(f.marked, arg)
+	return 2, nil
+}
+
+func (f *fakeLifecycleQueries) ReactivateChatRoutes(_ context.Context, arg sqlc.ReactivateChatRoutesParams) (int64, error) {
+	f.reactivated = append(f.reactivated, arg)
+	return 1, nil
+}
+
+func (f *fakeLifecycleQueries) ListInactiveChatRoutes(context.Context, pgtype.UUID) ([]sqlc.ListInactiveChatRoutesRow, error) {
+	return f.inactive, nil
+}
+
+func (f *fakeLifecycleQueries) DeleteInactiveChatRoutesBefore(_ context.Context, before pgtype.Timestamptz) (int64, error) {
+	f.prunedAt = append(f.prunedAt, before.Time)
+	return 0, nil
+}
+
+func TestMarkConversationInactivePrunesOncePerInterval(t *testing.T) {
+	queries := &fakeLifecycleQueries{}
+	svc := NewService(nil, queries)
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	svc.SetInactiveRetention(7 * 24 * time.Hour)
+	ctx := context.Background()
+
+	n, err := svc.MarkConversationInactive(ctx, lifecycleBotID, "telegram", "-100", "")
+	if err != nil || n != 2 {
+		t.Fatalf("MarkConversationInactive() = %d, %v", n, err)
+	}
+	if len(queries.marked) != 1 || queries.marked[0].Reason != InactiveReasonBotRemoved || queries.marked[0].ConversationID != "-100" {
+		t.Fatalf("marked = %#v", queries.marked)
+	}
+	if len(queries.prunedAt) != 1 || !queries.prunedAt[0].Equal(now.Add(-7*24*time.Hour)) {
+		t.Fatalf("prunedAt = %v", queries.prunedAt)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if _, err := svc.MarkConversationInactive(ctx, lifecycleBotID, "telegram", "-200", "kicked"); err != nil {
+		t.Fatalf("MarkConversationInactive() error = %v", err)
+	}
+	if len(queries.prunedAt) != 1 {
+		t.Fatalf("pruned again within the interval: %v", queries.prunedAt)
+	}
+
+	now = now.Add(pruneInterval)
+	svc.SetInactiveRetention(0)
+	if _, err := svc.MarkConversationInactive(ctx, lifecycleBotID, "telegram", "-300", ""); err != nil {
+		t.Fatalf("MarkConversationInactive() error = %v", err)
+	}
+	if len(queries.prunedAt) != 1 {
+		t.Fatalf("pruned with retention disabled: %v", queries.prunedAt)
+	}
+}
+
+func TestListInactiveMapsStatus(t *testing.T) {
+	inactiveAt := time.Date(2026, 1, 30, 8, 0, 0, 0, time.UTC)
+	queries := &fakeLifecycleQueries{inactive: []sqlc.ListInactiveChatRoutesRow{{
+		ID:             pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		BotID:          pgtype.UUID{Bytes: [16]byte{2}, Valid: true},
+		Platform:       "slack",
+		ConversationID: "C123",
+		Status:         StatusInactive,
+		InactiveReason: InactiveReasonBotRemoved,
+		InactiveAt:     pgtype.Timestamptz{Time: inactiveAt, Valid: true},
+	}}}
+	svc := NewService(nil, queries)
+
+	routes, err := svc.ListInactive(context.Background(), lifecycleBotID)
+	if err != nil {
+		t.Fatalf("ListInactive() error = %v", err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("routes = %#v", routes)
+	}
+	got := routes[0]
+	if got.Status != StatusInactive || got.InactiveReason != InactiveReasonBotRemoved || got.InactiveAt == nil || !got.InactiveAt.Equal(inactiveAt) {
+		t.Fatalf("route = %#v", got)
+	}
+
+	if _, err := svc.ReactivateConversation(context.Background(), lifecycleBotID, "slack", "C123"); err != nil {
+		t.Fatalf("ReactivateConversation() error = %v", err)
+	}
+	if len(queries.reactivated) != 1 || queries.reactivated[0].Platform != "slack" {
+		t.Fatalf("reactivated = %#v", queries.reactivated)
+	}
+}
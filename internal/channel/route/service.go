@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -16,10 +18,28 @@ import (
 	dbstore "github.com/memohai/memoh/internal/db/store"
 )
 
+// DefaultInactiveRetention is how long routes of conversations the bot was
+// removed from are kept before they are pruned.
+const DefaultInactiveRetention = 30 * 24 * time.Hour
+
+const pruneInterval = time.Hour
+
+type lifecycleQueries interface {
+	MarkChatRoutesInactive(ctx context.Context, arg sqlc.MarkChatRoutesInactiveParams) (int64, error)
+	ReactivateChatRoutes(ctx context.Context, arg sqlc.ReactivateChatRoutesParams) (int64, error)
+	ListInactiveChatRoutes(ctx context.Context, botID pgtype.UUID) ([]sqlc.ListInactiveChatRoutesRow, error)
+	DeleteInactiveChatRoutesBefore(ctx context.Context, before pgtype.Timestamptz) (int64, error)
+}
+
 // DBService manages channel routes and route-to-bot/thread resolution.
 type DBService struct {
 	queries dbstore.Queries
 	logger  *slog.Logger
+	now     func() time.Time
+
+	mu                sync.Mutex
+	inactiveRetention time.Duration
+	lastPruned        time.Time
 }
 
 // NewService creates a channel route service.
@@ -28,11 +48,21 @@ func NewService(log *slog.Logger, queries dbstore.Queries) *DBService {
 		log = slog.Default()
 	}
 	return &DBService{
-		queries: queries,
-		logger:  log.With(slog.String("service", "channel/route")),
+		queries:           queries,
+		logger:            log.With(slog.String("service", "channel/route")),
+		now:               time.Now,
+		inactiveRetention: DefaultInactiveRetention,
 	}
 }
 
+// SetInactiveRetention sets how long inactive routes are kept. Zero or a
+// negative value keeps them forever.
+func (s *DBService) SetInactiveRetention(retention time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inactiveRetention = retention
+}
+
 // Create creates a route.
 func (s *DBService) Create(ctx context.Context, input CreateInput) (Route, error) {
 	pgBotID, err := dbpkg.ParseUUID(input.BotID)
@@ -153,6 +183,119 @@ func (s *DBService) UpdateMetadata(ctx context.Context, routeID string, metadata
 	})
 }
 
+// MarkConversationInactive deactivates every route of an external
+// conversation, e.g. after the bot was removed from a group. It returns the
+// number of routes that changed.
+func (s *DBService) MarkConversationInactive(ctx context.Context, botID, platform, externalConversationID, reason string) (int64, error) {
+	store, err := s.lifecycleStore()
+	if err != nil {
+		return 0, err
+	}
+	pgBotID, err := dbpkg.ParseUUID(botID)
+	if err != nil {
+		return 0, err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = InactiveReasonBotRemoved
+	}
+	n, err := store.MarkChatRoutesInactive(ctx, sqlc.MarkChatRoutesInactiveParams{
+		Reason:         reason,
+		BotID:          pgBotID,
+		Platform:       platform,
+		ConversationID: externalConversationID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("mark routes inactive: %w", err)
+	}
+	s.maybePruneInactive(ctx, store)
+	return n, nil
+}
+
+// ReactivateConversation reactivates the inactive routes of an external
+// conversation once the bot is back in it. It returns the number of routes
+// that changed.
+func (s *DBService) ReactivateConversation(ctx context.Context, botID, platform, externalConversationID string) (int64, error) {
+	store, err := s.lifecycleStore()
+	if err != nil {
+		return 0, err
+	}
+	pgBotID, err := dbpkg.ParseUUID(botID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := store.ReactivateChatRoutes(ctx, sqlc.ReactivateChatRoutesParams{
+		BotID:          pgBotID,
+		Platform:       platform,
+		ConversationID: externalConversationID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("reactivate routes: %w", err)
+	}
+	return n, nil
+}
+
+// ListInactive lists a bot's inactive routes, most recently deactivated first.
+func (s *DBService) ListInactive(ctx context.Context, botID string) ([]Route, error) {
+	store, err := s.lifecycleStore()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := dbpkg.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListInactiveChatRoutes(ctx, pgBotID)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]Route, 0, len(rows))
+	for _, row := range rows {
+		routes = append(routes, withRouteStatus(toRouteFields(
+			row.ID, row.BotID, row.Platform, row.ChannelConfigID,
+			row.ConversationID, row.ThreadID, row.ConversationType, row.ReplyTarget,
+			row.ActiveSessionID, row.Metadata, row.CreatedAt, row.UpdatedAt,
+		), row.Status, row.InactiveReason, row.InactiveAt))
+	}
+	return routes, nil
+}
+
+// maybePruneInactive deletes routes that have been inactive for longer than
+// the retention, at most once per pruneInterval. Sessions and history of a
+// pruned route are kept; they only lose the route link.
+func (s *DBService) maybePruneInactive(ctx context.Context, store lifecycleQueries) {
+	now := s.now()
+	s.mu.Lock()
+	retention := s.inactiveRetention
+	if retention <= 0 || now.Sub(s.lastPruned) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPruned = now
+	s.mu.Unlock()
+
+	before := pgtype.Timestamptz{Time: now.Add(-retention).UTC(), Valid: true}
+	n, err := store.DeleteInactiveChatRoutesBefore(ctx, before)
+	if err != nil {
+		s.logger.Warn("prune inactive routes failed", slog.Any("error", err))
+		return
+	}
+	if n > 0 {
+		s.logger.Info("pruned inactive routes", slog.Int64("count", n))
+	}
+}
+
+func (s *DBService) lifecycleStore() (lifecycleQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("route service not configured")
+	}
+	store, ok := s.queries.(lifecycleQueries)
+	if !ok {
+		return nil, errors.New("route lifecycle queries not supported by store")
+	}
+	return store, nil
+}
+
 // ResolveConversation finds or creates a bot route for an inbound message.
 func (s *DBService) ResolveConversation(ctx context.Context, input ResolveInput) (ResolveConversationResult, error) {
 	route, err := s.Find(ctx, input.BotID, input.Platform, input.ExternalConversationID, input.ExternalThreadID)
@@ -175,7 +318,15 @@ func (s *DBService) ResolveConversation(ctx context.Context, input ResolveInput)
 		if touchErr := s.queries.TouchBotActivity(ctx, pgBotID); touchErr != nil && s.logger != nil {
 			s.logger.Warn("touch bot activity failed", slog.Any("error", touchErr))
 		}
-		return ResolveConversationResult{BotID: route.BotID, RouteID: route.ID, Created: false}, nil
+		if store, storeErr := s.lifecycleStore(); storeErr == nil {
+			s.maybePruneInactive(ctx, store)
+		}
+		return ResolveConversationResult{
+			BotID:    route.BotID,
+			RouteID:  route.ID,
+			Created:  false,
+			Inactive: route.Status == StatusInactive,
+		}, nil
 	}
 	if !errors.Is(err, pgx.ErrNoRows) {
 		return ResolveConversationResult{}, fmt.Errorf("find route: %w", err)
@@ -215,35 +366,35 @@ func (s *DBService) ResolveConversation(ctx context.Context, input ResolveInput)
 }
 
 func toRouteFromCreate(row sqlc.CreateChatRouteRow) Route {
-	return toRouteFields(
+	return withRouteStatus(toRouteFields(
 		row.ID, row.BotID, row.Platform, row.ChannelConfigID,
 		row.ConversationID, row.ThreadID, row.ConversationType, row.ReplyTarget,
 		row.ActiveSessionID, row.Metadata, row.CreatedAt, row.UpdatedAt,
-	)
+	), row.Status, row.InactiveReason, row.InactiveAt)
 }
 
 func toRouteFromFind(row sqlc.FindChatRouteRow) Route {
-	return toRouteFields(
+	return withRouteStatus(toRouteFields(
 		row.ID, row.BotID, row.Platform, row.ChannelConfigID,
 		row.ConversationID, row.ThreadID, row.ConversationType, row.ReplyTarget,
 		row.ActiveSessionID, row.Metadata, row.CreatedAt, row.UpdatedAt,
-	)
+	), row.Status, row.InactiveReason, row.InactiveAt)
 }
 
 func toRouteFromGet(row sqlc.GetChatRouteByIDRow) Route {
-	return toRouteFields(
+	return withRouteStatus(toRouteFields(
 		row.ID, row.BotID, row.Platform, row.ChannelConfigID,
 		row.ConversationID, row.ThreadID, row.ConversationType, row.ReplyTarget,
 		row.ActiveSessionID, row.Metadata, row.CreatedAt, row.UpdatedAt,
-	)
+	), row.Status, row.InactiveReason, row.InactiveAt)
 }
 
 func toRouteFromList(row sqlc.ListChatRoutesRow) Route {
-	return toRouteFields(
+	return withRouteStatus(toRouteFields(
 		row.ID, row.BotID, row.Platform, row.ChannelConfigID,
 		row.ConversationID, row.ThreadID, row.ConversationType, row.ReplyTarget,
 		row.ActiveSessionID, row.Metadata, row.CreatedAt, row.UpdatedAt,
-	)
+	), row.Status, row.InactiveReason, row.InactiveAt)
 }
 
 func toRouteFields(id, botID pgtype.UUID, platform string, channelConfigID pgtype.UUID, externalConversationID string, externalThreadID, conversationType, replyTarget pgtype.Text, activeThreadID pgtype.UUID, metadata []byte, createdAt, updatedAt pgtype.Timestamptz) Route {
@@ -263,6 +414,19 @@ func toRouteFields(id, botID pgtype.UUID, platform string, channelConfigID pgtyp
 	}
 }
 
+func withRouteStatus(route Route, status, inactiveReason string, inactiveAt pgtype.Timestamptz) Route {
+	route.Status = strings.TrimSpace(status)
+	if route.Status == "" {
+		route.Status = StatusActive
+	}
+	route.InactiveReason = strings.TrimSpace(inactiveReason)
+	if inactiveAt.Valid {
+		at := inactiveAt.Time
+		route.InactiveAt = &at
+	}
+	return route
+}
+
 func toPgText(value string) pgtype.Text {
 	value = strings.TrimSpace(value)
 	if value == "" {
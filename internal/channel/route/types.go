@@ -18,15 +18,34 @@ type Route struct {
 	ReplyTarget            string         `json:"reply_target,omitempty"`
 	ActiveThreadID         string         `json:"active_session_id,omitempty"`
 	Metadata               map[string]any `json:"metadata,omitempty"`
+	Status                 string         `json:"status"`
+	InactiveReason         string         `json:"inactive_reason,omitempty"`
+	InactiveAt             *time.Time     `json:"inactive_at,omitempty"`
 	CreatedAt              time.Time      `json:"created_at"`
 	UpdatedAt              time.Time      `json:"updated_at"`
 }
 
+const (
+	// StatusActive routes belong to conversations the bot is a member of.
+	StatusActive = "active"
+	// StatusInactive routes belong to conversations the bot was removed from.
+	// Their passive traffic is not persisted and they are pruned after the
+	// inactive route retention.
+	StatusInactive = "inactive"
+
+	// InactiveReasonBotRemoved marks routes deactivated because the platform
+	// reported the bot's removal from the conversation.
+	InactiveReasonBotRemoved = "bot_removed"
+)
+
 // ResolveConversationResult is returned by ResolveConversation.
 type ResolveConversationResult struct {
 	BotID   string
 	RouteID string
 	Created bool
+	// Inactive reports that the route belongs to a conversation the bot was
+	// removed from.
+	Inactive bool
 }
 
 // CreateInput is the input for creating a route.
@@ -68,4 +87,7 @@ type Service interface {
 	Delete(ctx context.Context, routeID string) error
 	UpdateReplyTarget(ctx context.Context, routeID, replyTarget string) error
 	UpdateMetadata(ctx context.Context, routeID string, metadata map[string]any) error
+	MarkConversationInactive(ctx context.Context, botID, platform, externalConversationID, reason string) (int64, error)
+	ReactivateConversation(ctx context.Context, botID, platform, externalConversationID string) (int64, error)
+	ListInactive(ctx context.Context, botID string) ([]Route, error)
 }
@@ -12,6 +12,7 @@ type Runtime interface {
 	SetWebhookEndpoint(context.Context, string, ChannelType, SetWebhookEndpointRequest) (SetWebhookEndpointResponse, error)
 	Send(context.Context, string, ChannelType, SendRequest) error
 	React(context.Context, string, ChannelType, ReactRequest) error
+	Broadcast(context.Context, string, BroadcastRequest) (BroadcastResult, error)
 	ConnectionStatusesByBot(string) []ConnectionStatus
 }
 
@@ -45,6 +46,10 @@ func (r *LocalRuntime) React(ctx context.Context, botID string, typ ChannelType,
 	return r.Manager.React(ctx, botID, typ, req)
 }
 
+func (r *LocalRuntime) Broadcast(ctx context.Context, botID string, req BroadcastRequest) (BroadcastResult, error) {
+	return r.Manager.Broadcast(ctx, botID, req)
+}
+
 func (r *LocalRuntime) ConnectionStatusesByBot(botID string) []ConnectionStatus {
 	return r.Manager.ConnectionStatusesByBot(botID)
 }
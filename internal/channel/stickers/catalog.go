@@ -0,0 +1,117 @@
+// Package stickers keeps the catalog of platform-native stickers a bot may
+// send. The agent can only pick stickers by catalog name, so owners decide
+// which stickers (and which fallback emoji) their bot uses.
+package stickers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxStickers          = 50
+	maxDescriptionLength = 200
+	maxEmojiLength       = 16
+	maxKeyLength         = 256
+)
+
+var ErrInvalidSticker = errors.New("invalid sticker")
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+
+// Sticker is one catalog entry. Platforms maps a channel type to the native
+// sticker id on that platform (a Telegram file_id, a Discord sticker id).
+// Emoji is sent as text on platforms without a native sticker.
+type Sticker struct {
+	Name string `json:"name"`
+	// Description tells the agent when the sticker fits.
+	Description string            `json:"description,omitempty"`
+	Emoji       string            `json:"emoji,omitempty"`
+	Platforms   map[string]string `json:"platforms,omitempty"`
+}
+
+// NativeKey returns the sticker's id on platform, or "" when it has none.
+func (s Sticker) NativeKey(platform string) string {
+	return s.Platforms[strings.ToLower(strings.TrimSpace(platform))]
+}
+
+// Usable reports whether the sticker can be sent on platform, natively or
+// as its fallback emoji.
+func (s Sticker) Usable(platform string) bool {
+	return s.NativeKey(platform) != "" || s.Emoji != ""
+}
+
+// Normalize trims the catalog and checks it, returning the list as it should
+// be stored.
+func Normalize(stickers []Sticker) ([]Sticker, error) {
+	if len(stickers) > maxStickers {
+		return nil, fmt.Errorf("%w: at most %d stickers are allowed", ErrInvalidSticker, maxStickers)
+	}
+	out := make([]Sticker, 0, len(stickers))
+	seen := make(map[string]struct{}, len(stickers))
+	for i, s := range stickers {
+		s.Name = strings.ToLower(strings.TrimSpace(s.Name))
+		s.Description = strings.TrimSpace(s.Description)
+		s.Emoji = strings.TrimSpace(s.Emoji)
+		if !namePattern.MatchString(s.Name) {
+			return nil, fmt.Errorf("%w: sticker %d: name must be 1-40 lowercase letters, digits, '-' or '_'", ErrInvalidSticker, i)
+		}
+		if _, dup := seen[s.Name]; dup {
+			return nil, fmt.Errorf("%w: duplicate sticker name %q", ErrInvalidSticker, s.Name)
+		}
+		seen[s.Name] = struct{}{}
+		if utf8.RuneCountInString(s.Description) > maxDescriptionLength {
+			return nil, fmt.Errorf("%w: sticker %q: description is too long", ErrInvalidSticker, s.Name)
+		}
+		if utf8.RuneCountInString(s.Emoji) > maxEmojiLength {
+			return nil, fmt.Errorf("%w: sticker %q: emoji is too long", ErrInvalidSticker, s.Name)
+		}
+		var platforms map[string]string
+		for platform, key := range s.Platforms {
+			platform = strings.ToLower(strings.TrimSpace(platform))
+			key = strings.TrimSpace(key)
+			if platform == "" || key == "" {
+				continue
+			}
+			if len(key) > maxKeyLength {
+				return nil, fmt.Errorf("%w: sticker %q: %s id is too long", ErrInvalidSticker, s.Name, platform)
+			}
+			if platforms == nil {
+				platforms = make(map[string]string, len(s.Platforms))
+			}
+			platforms[platform] = key
+		}
+		s.Platforms = platforms
+		if len(s.Platforms) == 0 && s.Emoji == "" {
+			return nil, fmt.Errorf("%w: sticker %q needs a platform sticker id or an emoji", ErrInvalidSticker, s.Name)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// ForPlatform returns the stickers that can be sent on platform, in catalog
+// order.
+func ForPlatform(catalog []Sticker, platform string) []Sticker {
+	var out []Sticker
+	for _, s := range catalog {
+		if s.Usable(platform) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Find returns the catalog entry called name.
+func Find(catalog []Sticker, name string) (Sticker, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, s := range catalog {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Sticker{}, false
+}
@@ -0,0 +1,60 @@
+package stickers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeCleansCatalog(t *testing.T) {
+	t.Parallel()
+
+	got, err := Normalize([]Sticker{{
+		Name:        " Happy_Cat ",
+		Description: " when the user shares good news ",
+		Emoji:       "😸",
+		Platforms:   map[string]string{" Telegram ": " CAACAgIAAxkBAAE ", "discord": " "},
+	}})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	s := got[0]
+	if s.Name != "happy_cat" || s.Description != "when the user shares good news" {
+		t.Fatalf("unexpected sticker %+v", s)
+	}
+	if len(s.Platforms) != 1 || s.NativeKey("telegram") != "CAACAgIAAxkBAAE" {
+		t.Fatalf("unexpected platforms %+v", s.Platforms)
+	}
+}
+
+func TestNormalizeRejectsInvalidCatalogs(t *testing.T) {
+	t.Parallel()
+
+	for _, catalog := range [][]Sticker{
+		{{Name: "has space", Emoji: "👍"}},
+		{{Name: "ok", Emoji: "👍"}, {Name: "OK", Emoji: "👌"}},
+		{{Name: "nothing"}},
+		{{Name: "blank", Platforms: map[string]string{"telegram": " "}}},
+	} {
+		if _, err := Normalize(catalog); !errors.Is(err, ErrInvalidSticker) {
+			t.Fatalf("Normalize(%+v) error = %v, want ErrInvalidSticker", catalog, err)
+		}
+	}
+}
+
+func TestForPlatformUsesNativeOrEmoji(t *testing.T) {
+	t.Parallel()
+
+	catalog := []Sticker{
+		{Name: "wave", Emoji: "👋"},
+		{Name: "party", Platforms: map[string]string{"telegram": "file-1"}},
+	}
+	if got := ForPlatform(catalog, "discord"); len(got) != 1 || got[0].Name != "wave" {
+		t.Fatalf("discord stickers = %+v", got)
+	}
+	if got := ForPlatform(catalog, "Telegram"); len(got) != 2 {
+		t.Fatalf("telegram stickers = %+v", got)
+	}
+	if s, ok := Find(catalog, " PARTY "); !ok || s.NativeKey("telegram") != "file-1" {
+		t.Fatalf("Find() = %+v, %v", s, ok)
+	}
+}
@@ -0,0 +1,103 @@
+package stickers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+type catalogQueries interface {
+	GetBotStickerCatalog(ctx context.Context, botID pgtype.UUID) (sqlc.BotStickerCatalog, error)
+	UpsertBotStickerCatalog(ctx context.Context, arg sqlc.UpsertBotStickerCatalogParams) (sqlc.BotStickerCatalog, error)
+}
+
+// Service stores the sticker catalog of each bot.
+type Service struct {
+	queries dbstore.Queries
+	logger  *slog.Logger
+}
+
+// NewService creates a sticker catalog service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "stickers")),
+	}
+}
+
+func (s *Service) store() (catalogQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("sticker service not configured")
+	}
+	store, ok := s.queries.(catalogQueries)
+	if !ok {
+		return nil, errors.New("sticker queries not supported by store")
+	}
+	return store, nil
+}
+
+// List returns the bot's sticker catalog.
+func (s *Service) List(ctx context.Context, botID string) ([]Sticker, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot id: %w", err)
+	}
+	row, err := store.GetBotStickerCatalog(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return []Sticker{}, nil
+		}
+		return nil, fmt.Errorf("get sticker catalog: %w", err)
+	}
+	catalog := []Sticker{}
+	if len(row.Stickers) > 0 {
+		if err := json.Unmarshal(row.Stickers, &catalog); err != nil {
+			return nil, fmt.Errorf("decode sticker catalog: %w", err)
+		}
+	}
+	return catalog, nil
+}
+
+// Replace validates stickers and stores them as the bot's catalog, replacing
+// the previous one.
+func (s *Service) Replace(ctx context.Context, botID string, stickers []Sticker) ([]Sticker, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot id: %w", err)
+	}
+	normalized, err := Normalize(stickers)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("encode sticker catalog: %w", err)
+	}
+	if _, err := store.UpsertBotStickerCatalog(ctx, sqlc.UpsertBotStickerCatalogParams{
+		BotID:    pgBotID,
+		Stickers: raw,
+	}); err != nil {
+		return nil, fmt.Errorf("store sticker catalog: %w", err)
+	}
+	return normalized, nil
+}
@@ -35,6 +35,8 @@ var builtinToolCallEmoji = map[string]string{
 	"web_fetch":             "🌐",
 
 	"search_memory":   "🧠",
+	"list_memories":   "🧠",
+	"flag_memory":     "🧠",
 	"search_messages": "🧠",
 	"list_sessions":   "🧠",
 
@@ -44,8 +46,10 @@ var builtinToolCallEmoji = map[string]string{
 	"update_schedule": "📅",
 	"delete_schedule": "📅",
 
-	"send":  "💬",
-	"react": "💬",
+	"send":                 "💬",
+	"react":                "💬",
+	"send_sticker":         "💬",
+	"comment_github_issue": "💬",
 
 	"get_contacts": "👥",
 
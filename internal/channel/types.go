@@ -5,6 +5,8 @@ package channel
 import (
 	"strings"
 	"time"
+
+	"github.com/memohai/memoh/internal/redact"
 )
 
 // ChannelType identifies a messaging platform (e.g., "telegram", "feishu").
@@ -182,6 +184,15 @@ type StreamEvent struct {
 	Metadata    map[string]any         `json:"metadata,omitempty"`
 }
 
+// redactStreamEvent masks credentials in the error text of an event. Error
+// strings often wrap upstream responses that echo API keys or headers.
+func redactStreamEvent(event StreamEvent) StreamEvent {
+	if event.Error != "" {
+		event.Error = redact.Text(event.Error)
+	}
+	return event
+}
+
 // SpeechRequest carries text-to-speech synthesis text from a speech_delta stream event.
 type SpeechRequest struct {
 	Text string `json:"text"`
@@ -207,14 +218,15 @@ const (
 type MessagePartType string
 
 const (
-	MessagePartText       MessagePartType = "text"
-	MessagePartLink       MessagePartType = "link"
-	MessagePartCodeBlock  MessagePartType = "code_block"
-	MessagePartMention    MessagePartType = "mention"
-	MessagePartEmoji      MessagePartType = "emoji"
-	MessagePartHeading    MessagePartType = "heading"
-	MessagePartBlockquote MessagePartType = "blockquote"
-	MessagePartListItem   MessagePartType = "list_item"
+	MessagePartText        MessagePartType = "text"
+	MessagePartLink        MessagePartType = "link"
+	MessagePartCodeBlock   MessagePartType = "code_block"
+	MessagePartMention     MessagePartType = "mention"
+	MessagePartEmoji       MessagePartType = "emoji"
+	MessagePartHeading     MessagePartType = "heading"
+	MessagePartBlockquote  MessagePartType = "blockquote"
+	MessagePartListItem    MessagePartType = "list_item"
+	MessagePartLinkPreview MessagePartType = "link_preview"
 )
 
 // MessageTextStyle describes inline formatting for a text part.
@@ -245,12 +257,13 @@ type MessagePart struct {
 type AttachmentType string
 
 const (
-	AttachmentImage AttachmentType = "image"
-	AttachmentAudio AttachmentType = "audio"
-	AttachmentVideo AttachmentType = "video"
-	AttachmentVoice AttachmentType = "voice"
-	AttachmentFile  AttachmentType = "file"
-	AttachmentGIF   AttachmentType = "gif"
+	AttachmentImage   AttachmentType = "image"
+	AttachmentAudio   AttachmentType = "audio"
+	AttachmentVideo   AttachmentType = "video"
+	AttachmentVoice   AttachmentType = "voice"
+	AttachmentFile    AttachmentType = "file"
+	AttachmentGIF     AttachmentType = "gif"
+	AttachmentSticker AttachmentType = "sticker"
 )
 
 // Attachment represents a binary file attached to a message.
@@ -472,4 +485,6 @@ const (
 	ChannelTypeLocal    ChannelType = "local"
 	ChannelTypeSlack    ChannelType = "slack"
 	ChannelTypeLine     ChannelType = "line"
+	ChannelTypeWhatsApp ChannelType = "whatsapp"
+	ChannelTypeWebhook  ChannelType = "webhook"
 )
@@ -0,0 +1,54 @@
+package unfurl
+
+import (
+	"context"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+// Previewer returns the previews of the links in a reply text.
+type Previewer interface {
+	Previews(ctx context.Context, text string) []channel.LinkPreview
+}
+
+// previewStream attaches link previews to the final reply of an IM stream.
+type previewStream struct {
+	primary   channel.OutboundStream
+	previewer Previewer
+}
+
+// NewStream wraps primary so the final message carries link_preview parts
+// for the links in its text. It returns primary unchanged when previewer is
+// nil.
+func NewStream(primary channel.OutboundStream, previewer Previewer) channel.OutboundStream {
+	if primary == nil || previewer == nil {
+		return primary
+	}
+	return &previewStream{primary: primary, previewer: previewer}
+}
+
+func (s *previewStream) Push(ctx context.Context, event channel.StreamEvent) error {
+	if event.Type == channel.StreamEventFinal && event.Final != nil {
+		msg := event.Final.Message
+		if previews := s.previewer.Previews(ctx, msg.PlainText()); len(previews) > 0 {
+			parts := make([]channel.MessagePart, 0, len(msg.Parts)+len(previews)+1)
+			if len(msg.Parts) == 0 {
+				// A plain reply becomes a text part so adapters that render
+				// parts keep the body alongside its previews.
+				parts = append(parts, channel.MessagePart{Type: channel.MessagePartText, Text: msg.Text})
+				msg.Text = ""
+			}
+			parts = append(parts, msg.Parts...)
+			for _, preview := range previews {
+				parts = append(parts, preview.Part())
+			}
+			msg.Parts = parts
+			event.Final = &channel.StreamFinalizePayload{Message: msg}
+		}
+	}
+	return s.primary.Push(ctx, event)
+}
+
+func (s *previewStream) Close(ctx context.Context) error {
+	return s.primary.Close(ctx)
+}
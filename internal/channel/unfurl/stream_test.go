@@ -0,0 +1,47 @@
+package unfurl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/memohai/memoh/internal/channel"
+)
+
+type staticPreviewer []channel.LinkPreview
+
+func (p staticPreviewer) Previews(context.Context, string) []channel.LinkPreview { return p }
+
+type recordingStream struct {
+	events []channel.StreamEvent
+}
+
+func (s *recordingStream) Push(_ context.Context, event channel.StreamEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (*recordingStream) Close(context.Context) error { return nil }
+
+func TestStreamMovesPlainTextIntoParts(t *testing.T) {
+	t.Parallel()
+
+	primary := &recordingStream{}
+	stream := NewStream(primary, staticPreviewer{{URL: "https://example.com", Title: "Example"}})
+	err := stream.Push(context.Background(), channel.StreamEvent{
+		Type:  channel.StreamEventFinal,
+		Final: &channel.StreamFinalizePayload{Message: channel.Message{Text: "see https://example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	msg := primary.events[0].Final.Message
+	if msg.Text != "" {
+		t.Fatalf("Text = %q, want it moved into parts", msg.Text)
+	}
+	if len(msg.Parts) != 2 || msg.Parts[0].Type != channel.MessagePartText || msg.Parts[1].Type != channel.MessagePartLinkPreview {
+		t.Fatalf("Parts = %+v, want text then link_preview", msg.Parts)
+	}
+	if msg.Parts[0].Text != "see https://example.com" {
+		t.Fatalf("text part = %q", msg.Parts[0].Text)
+	}
+}
@@ -0,0 +1,280 @@
+// Package unfurl fetches OpenGraph metadata for the links in bot replies, so
+// channels configured for preview cards can show what a link points to.
+package unfurl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/netguard"
+)
+
+const (
+	userAgent      = "MemohLinkPreview/1.0"
+	fetchTimeout   = 5 * time.Second
+	maxBodyBytes   = 512 << 10
+	maxLinks       = 3
+	maxDescription = 300
+	cacheTTL       = time.Hour
+	maxCacheSize   = 512
+)
+
+var (
+	errNoMetadata = errors.New("page has no preview metadata")
+
+	linkPattern = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+)
+
+// Service unfurls links into previews. Results, including failures, are
+// cached for an hour so the same link is not fetched for every reply.
+type Service struct {
+	client *http.Client
+	now    func() time.Time
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	preview channel.LinkPreview
+	ok      bool
+	expires time.Time
+}
+
+// NewService creates an unfurl service. Its HTTP client refuses to connect
+// to loopback, private and link-local addresses, including after redirects,
+// and never goes through a proxy, which would hide the target address.
+func NewService(log *slog.Logger) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		client: netguard.NewClient(fetchTimeout),
+		now:    time.Now,
+		logger: log.With(slog.String("service", "unfurl")),
+		cache:  map[string]cacheEntry{},
+	}
+}
+
+// Previews unfurls the first few distinct links in text. Links that cannot
+// be fetched or carry no metadata are skipped.
+func (s *Service) Previews(ctx context.Context, text string) []channel.LinkPreview {
+	var previews []channel.LinkPreview
+	for _, link := range ExtractLinks(text, maxLinks) {
+		preview, err := s.Unfurl(ctx, link)
+		if err != nil {
+			s.logger.Debug("unfurl failed", slog.String("url", link), slog.Any("error", err))
+			continue
+		}
+		previews = append(previews, preview)
+	}
+	return previews
+}
+
+// Unfurl returns the preview of one http(s) link.
+func (s *Service) Unfurl(ctx context.Context, rawURL string) (channel.LinkPreview, error) {
+	pageURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Hostname() == "" {
+		return channel.LinkPreview{}, fmt.Errorf("unsupported link: %s", rawURL)
+	}
+	key := pageURL.String()
+	if entry, ok := s.cached(key); ok {
+		if !entry.ok {
+			return channel.LinkPreview{}, errNoMetadata
+		}
+		return entry.preview, nil
+	}
+	preview, err := s.fetch(ctx, pageURL)
+	if err != nil && ctx.Err() != nil {
+		// Do not remember failures caused by the caller giving up.
+		return channel.LinkPreview{}, err
+	}
+	s.store(key, cacheEntry{preview: preview, ok: err == nil, expires: s.now().Add(cacheTTL)})
+	return preview, err
+}
+
+func (s *Service) fetch(ctx context.Context, pageURL *url.URL) (channel.LinkPreview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return channel.LinkPreview{}, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return channel.LinkPreview{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return channel.LinkPreview{}, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return channel.LinkPreview{}, fmt.Errorf("unsupported content type %s", mediaType)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return channel.LinkPreview{}, err
+	}
+	preview := ParseOpenGraph(resp.Request.URL, body)
+	if preview.Title == "" && preview.Description == "" {
+		return channel.LinkPreview{}, errNoMetadata
+	}
+	return preview, nil
+}
+
+func (s *Service) cached(key string) (cacheEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.cache[key]
+	if !ok || s.now().After(entry.expires) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (s *Service) store(key string, entry cacheEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.cache) >= maxCacheSize {
+		now := s.now()
+		for k, e := range s.cache {
+			if now.After(e.expires) {
+				delete(s.cache, k)
+			}
+		}
+		if len(s.cache) >= maxCacheSize {
+			s.cache = map[string]cacheEntry{}
+		}
+	}
+	s.cache[key] = entry
+}
+
+// ExtractLinks returns up to limit distinct http(s) links in text, in order
+// of appearance. Trailing punctuation is not part of a link.
+func ExtractLinks(text string, limit int) []string {
+	var links []string
+	seen := map[string]struct{}{}
+	for _, match := range linkPattern.FindAllString(text, -1) {
+		link := strings.TrimRight(match, ".,;:!?*_~")
+		if _, dup := seen[link]; dup {
+			continue
+		}
+		seen[link] = struct{}{}
+		links = append(links, link)
+		if limit > 0 && len(links) == limit {
+			break
+		}
+	}
+	return links
+}
+
+// ParseOpenGraph reads the OpenGraph tags of an HTML page, falling back to
+// the <title> element and the description meta tag.
+func ParseOpenGraph(pageURL *url.URL, body []byte) channel.LinkPreview {
+	preview := channel.LinkPreview{URL: pageURL.String()}
+	var (
+		title, description string
+		inTitle            bool
+	)
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if preview.Title == "" {
+				preview.Title = title
+			}
+			if preview.Description == "" {
+				preview.Description = description
+			}
+			preview.Title = collapseSpace(preview.Title)
+			preview.Description = truncate(collapseSpace(preview.Description), maxDescription)
+			return preview
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = title == ""
+			case "meta":
+				key, content := metaAttrs(tokenizer, hasAttr)
+				switch key {
+				case "og:title":
+					preview.Title = content
+				case "og:description":
+					preview.Description = content
+				case "og:site_name":
+					preview.SiteName = collapseSpace(content)
+				case "og:image", "og:image:url":
+					if preview.ImageURL == "" {
+						if image, err := pageURL.Parse(content); err == nil && (image.Scheme == "http" || image.Scheme == "https") {
+							preview.ImageURL = image.String()
+						}
+					}
+				case "description":
+					description = content
+				}
+			case "body":
+				// Metadata lives in <head>; skip the rest of the page.
+				inTitle = false
+				if preview.Title != "" {
+					if preview.Description == "" {
+						preview.Description = description
+					}
+					preview.Title = collapseSpace(preview.Title)
+					preview.Description = truncate(collapseSpace(preview.Description), maxDescription)
+					return preview
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				title += string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "title" {
+				inTitle = false
+			}
+		}
+	}
+}
+
+func metaAttrs(tokenizer *html.Tokenizer, hasAttr bool) (key, content string) {
+	for hasAttr {
+		var name, value []byte
+		name, value, hasAttr = tokenizer.TagAttr()
+		switch string(name) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(string(value)))
+			}
+		case "content":
+			content = strings.TrimSpace(string(value))
+		}
+	}
+	return key, content
+}
+
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
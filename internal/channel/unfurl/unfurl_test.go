@@ -0,0 +1,53 @@
+package unfurl
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestExtractLinks(t *testing.T) {
+	t.Parallel()
+
+	text := "See https://example.com/a, then (https://example.com/b). Again https://example.com/a and http://example.org/c!"
+	got := ExtractLinks(text, 2)
+	want := []string{"https://example.com/a", "https://example.com/b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ExtractLinks = %v, want %v", got, want)
+	}
+}
+
+func TestParseOpenGraphPrefersOpenGraphTags(t *testing.T) {
+	t.Parallel()
+
+	pageURL, _ := url.Parse("https://example.com/posts/1")
+	body := []byte(`<html><head>
+<title>Fallback title</title>
+<meta property="og:title" content="  Release   notes ">
+<meta property="og:site_name" content="Example">
+<meta property="og:image" content="/img/cover.png">
+<meta name="description" content="Plain description">
+</head><body><meta property="og:title" content="ignored"></body></html>`)
+
+	got := ParseOpenGraph(pageURL, body)
+	if got.Title != "Release notes" || got.SiteName != "Example" {
+		t.Fatalf("unexpected preview %+v", got)
+	}
+	if got.Description != "Plain description" {
+		t.Fatalf("Description = %q, want fallback description", got.Description)
+	}
+	if got.ImageURL != "https://example.com/img/cover.png" {
+		t.Fatalf("ImageURL = %q, want resolved image URL", got.ImageURL)
+	}
+}
+
+func TestParseOpenGraphFallsBackToTitle(t *testing.T) {
+	t.Parallel()
+
+	pageURL, _ := url.Parse("https://example.com/")
+	got := ParseOpenGraph(pageURL, []byte(`<html><head><title> Home
+page </title></head><body>text</body></html>`))
+	if got.Title != "Home page" || got.URL != "https://example.com/" {
+		t.Fatalf("unexpected preview %+v", got)
+	}
+}
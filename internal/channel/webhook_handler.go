@@ -48,14 +48,25 @@ func NewWebhookServerHandler(log *slog.Logger, store *Store, manager *Manager) *
 func (h *WebhookHandler) Register(e *echo.Echo) {
 	e.GET("/channels/:platform/webhook/:config_id", h.Handle)
 	e.POST("/channels/:platform/webhook/:config_id", h.Handle)
+	e.POST("/channels/webhook/:config_id", h.HandleGenericWebhook)
 }
 
 // Handle resolves the channel config and delegates the request to the adapter.
 func (h *WebhookHandler) Handle(c echo.Context) error {
+	return h.handle(c, c.Param("platform"))
+}
+
+// HandleGenericWebhook serves the short inbound URL of the generic webhook
+// channel, which integrators call directly instead of a platform console.
+func (h *WebhookHandler) HandleGenericWebhook(c echo.Context) error {
+	return h.handle(c, ChannelTypeWebhook.String())
+}
+
+func (h *WebhookHandler) handle(c echo.Context, platform string) error {
 	if h.store == nil || h.manager == nil || h.registry == nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "channel webhook dependencies not configured")
 	}
-	channelType, err := h.registry.ParseChannelType(c.Param("platform"))
+	channelType, err := h.registry.ParseChannelType(platform)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
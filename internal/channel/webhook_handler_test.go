@@ -125,6 +125,52 @@ func TestGenericWebhookHandlerDispatchesToAdapter(t *testing.T) {
 	}
 }
 
+func TestGenericWebhookHandlerServesWebhookChannelShortPath(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	adapter := &fakeWebhookAdapter{channelType: ChannelTypeWebhook}
+	other := &fakeWebhookAdapter{channelType: ChannelType("testhook")}
+	registry.MustRegister(adapter)
+	registry.MustRegister(other)
+
+	store := &fakeWebhookStore{
+		configs: []ChannelConfig{
+			{ID: "cfg-1", BotID: "bot-1", ChannelType: ChannelTypeWebhook},
+			{ID: "cfg-2", BotID: "bot-1", ChannelType: other.channelType},
+		},
+	}
+	manager := &fakeWebhookManager{registry: registry}
+	h := NewWebhookServerHandler(nil, (*Store)(nil), (*Manager)(nil))
+	h.store = store
+	h.manager = manager
+	h.registry = registry
+
+	e := echo.New()
+	h.Register(e)
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/channels/webhook/cfg-1", strings.NewReader(`{}`))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code: %d", rec.Code)
+	}
+	if len(adapter.calls) != 1 || adapter.calls[0].cfg.ID != "cfg-1" {
+		t.Fatalf("expected webhook adapter to be called for cfg-1, got %+v", adapter.calls)
+	}
+
+	// Platform-scoped paths of other channels are unaffected.
+	req = httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/channels/testhook/webhook/cfg-2", strings.NewReader(`{}`))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code on platform path: %d", rec.Code)
+	}
+	if len(other.calls) != 1 {
+		t.Fatalf("expected testhook adapter to be called once, got %d", len(other.calls))
+	}
+}
+
 func TestGenericWebhookHandlerRejectsUnknownConfig(t *testing.T) {
 	t.Parallel()
 
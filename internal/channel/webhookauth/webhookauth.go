@@ -0,0 +1,196 @@
+// Package webhookauth verifies inbound platform webhooks: request
+// signatures, signing-time skew and replayed requests. Adapters describe
+// their platform's signing in a Scheme and keep a ReplayGuard per adapter.
+package webhookauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMissingSignature reports a request without its signature headers.
+	ErrMissingSignature = errors.New("missing webhook signature")
+	// ErrInvalidSignature reports a signature or token that does not match.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrStaleTimestamp reports a signing time outside the allowed skew.
+	ErrStaleTimestamp = errors.New("stale webhook timestamp")
+)
+
+// DefaultMaxSkew bounds the age of a signed request, as Slack recommends.
+const DefaultMaxSkew = 5 * time.Minute
+
+// replayGuardMaxEntries bounds the signatures a ReplayGuard remembers.
+const replayGuardMaxEntries = 4096
+
+// Encoding is how a signature is written in its header.
+type Encoding int
+
+const (
+	Hex Encoding = iota
+	Base64
+)
+
+// Scheme describes how a platform signs its webhook requests.
+type Scheme struct {
+	// SignatureHeader carries the signature, after Prefix.
+	SignatureHeader string
+	Prefix          string
+	Encoding        Encoding
+	// TimestampHeader carries the signing time in Unix seconds. When set,
+	// requests signed more than MaxSkew (DefaultMaxSkew if zero) away from
+	// now are rejected.
+	TimestampHeader string
+	MaxSkew         time.Duration
+	// Sum returns the raw expected signature of a request.
+	Sum func(secret string, header http.Header, body []byte) []byte
+}
+
+// HMACSHA256 returns the HMAC-SHA256 of the concatenated parts.
+func HMACSHA256(secret string, parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, part := range parts {
+		_, _ = mac.Write(part)
+	}
+	return mac.Sum(nil)
+}
+
+// Sign returns the signature header value of a request, for outbound
+// deliveries and tests.
+func (s Scheme) Sign(secret string, header http.Header, body []byte) string {
+	sum := s.Sum(secret, header, body)
+	if s.Encoding == Base64 {
+		return s.Prefix + base64.StdEncoding.EncodeToString(sum)
+	}
+	return s.Prefix + hex.EncodeToString(sum)
+}
+
+// Verify checks the request signature and, for timestamped schemes, the
+// signing time.
+func (s Scheme) Verify(header http.Header, body []byte, secret string, now time.Time) error {
+	value := strings.TrimSpace(header.Get(s.SignatureHeader))
+	if value == "" {
+		return ErrMissingSignature
+	}
+	if s.TimestampHeader != "" {
+		timestamp := strings.TrimSpace(header.Get(s.TimestampHeader))
+		if timestamp == "" {
+			return ErrMissingSignature
+		}
+		if err := CheckTimestamp(timestamp, now, s.MaxSkew); err != nil {
+			return err
+		}
+	}
+	encoded, ok := strings.CutPrefix(value, s.Prefix)
+	if !ok {
+		return ErrInvalidSignature
+	}
+	var got []byte
+	var err error
+	if s.Encoding == Base64 {
+		got, err = base64.StdEncoding.DecodeString(encoded)
+	} else {
+		got, err = hex.DecodeString(encoded)
+	}
+	if err != nil || !hmac.Equal(got, s.Sum(secret, header, body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// CheckTimestamp rejects a Unix-seconds timestamp more than maxSkew
+// (DefaultMaxSkew if zero) away from now, so a captured request cannot be
+// replayed later.
+func CheckTimestamp(timestamp string, now time.Time, maxSkew time.Duration) error {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return ErrStaleTimestamp
+	}
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxSkew
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > maxSkew || skew < -maxSkew {
+		return ErrStaleTimestamp
+	}
+	return nil
+}
+
+// VerifyToken compares a shared verification token, such as Meta's verify
+// token or Feishu's verification token, in constant time.
+func VerifyToken(got, want string) error {
+	want = strings.TrimSpace(want)
+	if want == "" || !hmac.Equal([]byte(strings.TrimSpace(got)), []byte(want)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ReplayGuard remembers the signatures of handled requests for the skew
+// window, so a captured request that is resent while its timestamp is still
+// fresh is handled once. Only timestamped schemes bound the window; for the
+// others adapters rely on message id dedup.
+type ReplayGuard struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewReplayGuard creates a guard remembering requests for window
+// (DefaultMaxSkew if zero).
+func NewReplayGuard(window time.Duration) *ReplayGuard {
+	if window <= 0 {
+		window = DefaultMaxSkew
+	}
+	return &ReplayGuard{window: window, seen: map[string]time.Time{}}
+}
+
+// Claim reports whether key, usually the config id and signature, was not
+// seen within the window, and records it.
+func (g *ReplayGuard) Claim(key string, now time.Time) bool {
+	if g == nil {
+		return true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if at, ok := g.seen[key]; ok && now.Sub(at) < 2*g.window {
+		return false
+	}
+	if len(g.seen) >= replayGuardMaxEntries {
+		for k, at := range g.seen {
+			if now.Sub(at) >= 2*g.window {
+				delete(g.seen, k)
+			}
+		}
+	}
+	if len(g.seen) >= replayGuardMaxEntries {
+		// Still full: forget an arbitrary half rather than refuse requests.
+		for k := range g.seen {
+			if len(g.seen) < replayGuardMaxEntries/2 {
+				break
+			}
+			delete(g.seen, k)
+		}
+	}
+	g.seen[key] = now
+	return true
+}
+
+// Release forgets key after a request failed, so the platform's retry of
+// the same request is handled.
+func (g *ReplayGuard) Release(key string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	delete(g.seen, key)
+	g.mu.Unlock()
+}
@@ -0,0 +1,118 @@
+package webhookauth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var testScheme = Scheme{
+	SignatureHeader: "X-Signature",
+	Prefix:          "v0=",
+	Encoding:        Hex,
+	TimestampHeader: "X-Timestamp",
+	Sum: func(secret string, header http.Header, body []byte) []byte {
+		return HMACSHA256(secret, []byte(header.Get("X-Timestamp")+":"), body)
+	},
+}
+
+func signedHeader(secret string, at time.Time, body []byte) http.Header {
+	header := http.Header{}
+	header.Set("X-Timestamp", strconv.FormatInt(at.Unix(), 10))
+	header.Set("X-Signature", testScheme.Sign(secret, header, body))
+	return header
+}
+
+func TestSchemeVerify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1710000000, 0)
+	body := []byte(`{"type":"event"}`)
+	tests := []struct {
+		name   string
+		header http.Header
+		body   []byte
+		want   error
+	}{
+		{name: "valid", header: signedHeader("secret", now, body), body: body},
+		{name: "clock ahead within skew", header: signedHeader("secret", now.Add(4*time.Minute), body), body: body},
+		{name: "wrong secret", header: signedHeader("other", now, body), body: body, want: ErrInvalidSignature},
+		{name: "tampered body", header: signedHeader("secret", now, body), body: append([]byte(" "), body...), want: ErrInvalidSignature},
+		{name: "stale", header: signedHeader("secret", now.Add(-10*time.Minute), body), body: body, want: ErrStaleTimestamp},
+		{name: "unsigned", header: http.Header{}, body: body, want: ErrMissingSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := testScheme.Verify(tt.header, tt.body, "secret", now); !errors.Is(err, tt.want) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemeBase64WithoutTimestamp(t *testing.T) {
+	t.Parallel()
+
+	scheme := Scheme{
+		SignatureHeader: "X-Line-Signature",
+		Encoding:        Base64,
+		Sum: func(secret string, _ http.Header, body []byte) []byte {
+			return HMACSHA256(secret, body)
+		},
+	}
+	body := []byte(`{"events":[]}`)
+	header := http.Header{}
+	header.Set("X-Line-Signature", scheme.Sign("secret", header, body))
+	if err := scheme.Verify(header, body, "secret", time.Time{}); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	header.Set("X-Line-Signature", "not base64")
+	if err := scheme.Verify(header, body, "secret", time.Time{}); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify() error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	t.Parallel()
+
+	if err := VerifyToken(" token ", "token"); err != nil {
+		t.Fatalf("VerifyToken() error = %v", err)
+	}
+	if err := VerifyToken("other", "token"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("VerifyToken(other) error = %v", err)
+	}
+	if err := VerifyToken("", ""); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatal("VerifyToken accepted an unconfigured token")
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1710000000, 0)
+	guard := NewReplayGuard(time.Minute)
+	if !guard.Claim("cfg:sig", now) {
+		t.Fatal("first request rejected")
+	}
+	if guard.Claim("cfg:sig", now.Add(90*time.Second)) {
+		t.Fatal("replay within the window accepted")
+	}
+	if !guard.Claim("cfg:other", now) {
+		t.Fatal("distinct request rejected")
+	}
+	guard.Release("cfg:sig")
+	if !guard.Claim("cfg:sig", now.Add(time.Second)) {
+		t.Fatal("released request rejected")
+	}
+	if !guard.Claim("cfg:sig", now.Add(3*time.Minute)) {
+		t.Fatal("request after the window rejected")
+	}
+
+	var nilGuard *ReplayGuard
+	if !nilGuard.Claim("cfg:sig", now) {
+		t.Fatal("nil guard rejected a request")
+	}
+}
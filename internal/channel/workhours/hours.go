@@ -0,0 +1,237 @@
+// Package workhours keeps a bot's working hours. Private messages received
+// outside them get an away reply and, when configured, are queued and
+// replayed through inbound processing at the next opening time.
+package workhours
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxWindows        = 14
+	maxAwayMessageLen = 1000
+)
+
+var ErrInvalidHours = errors.New("invalid working hours")
+
+// Hours is a bot's working hours configuration. Windows are evaluated in
+// Timezone; a window whose end is not after its start runs past midnight
+// into the next day.
+type Hours struct {
+	Enabled  bool     `json:"enabled"`
+	Timezone string   `json:"timezone,omitempty"`
+	Windows  []Window `json:"windows"`
+	// AwayMessage replaces the default localized away reply. {opens_at} is
+	// replaced with the next opening time.
+	AwayMessage string `json:"away_message,omitempty"`
+	// DeferReplies queues messages received outside working hours and
+	// answers them at the next opening time. Without it the away reply is
+	// the only answer.
+	DeferReplies bool `json:"defer_replies"`
+}
+
+// Window is a daily opening period. Days lists weekdays as "mon" to "sun";
+// empty means every day. Start and End are "HH:MM", and End may be "24:00".
+type Window struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// schedule is a validated Hours ready for evaluation.
+type schedule struct {
+	loc     *time.Location
+	windows []window
+}
+
+type window struct {
+	days       [7]bool
+	start, end int // minutes after midnight
+}
+
+// Normalize validates h and returns it in canonical form: lower-case
+// three-letter days and zero-padded times. Disabled hours are stored as
+// given as long as they parse.
+func Normalize(h Hours) (Hours, error) {
+	h.Timezone = strings.TrimSpace(h.Timezone)
+	h.AwayMessage = strings.TrimSpace(h.AwayMessage)
+	if len([]rune(h.AwayMessage)) > maxAwayMessageLen {
+		return Hours{}, fmt.Errorf("%w: away_message exceeds %d characters", ErrInvalidHours, maxAwayMessageLen)
+	}
+	if h.Enabled && h.Timezone == "" {
+		return Hours{}, fmt.Errorf("%w: timezone is required", ErrInvalidHours)
+	}
+	if h.Enabled && len(h.Windows) == 0 {
+		return Hours{}, fmt.Errorf("%w: at least one window is required", ErrInvalidHours)
+	}
+	if len(h.Windows) > maxWindows {
+		return Hours{}, fmt.Errorf("%w: at most %d windows", ErrInvalidHours, maxWindows)
+	}
+	if h.Timezone != "" {
+		loc, err := time.LoadLocation(h.Timezone)
+		if err != nil || strings.EqualFold(h.Timezone, "local") {
+			return Hours{}, fmt.Errorf("%w: unknown timezone %q", ErrInvalidHours, h.Timezone)
+		}
+		h.Timezone = loc.String()
+	}
+	windows := make([]Window, 0, len(h.Windows))
+	for i, w := range h.Windows {
+		compiled, err := compileWindow(w)
+		if err != nil {
+			return Hours{}, fmt.Errorf("%w: window %d: %w", ErrInvalidHours, i+1, err)
+		}
+		windows = append(windows, compiled.canonical(w))
+	}
+	h.Windows = windows
+	return h, nil
+}
+
+func compile(h Hours) (schedule, error) {
+	loc, err := time.LoadLocation(h.Timezone)
+	if err != nil {
+		return schedule{}, err
+	}
+	s := schedule{loc: loc, windows: make([]window, 0, len(h.Windows))}
+	for _, w := range h.Windows {
+		compiled, err := compileWindow(w)
+		if err != nil {
+			return schedule{}, err
+		}
+		s.windows = append(s.windows, compiled)
+	}
+	return s, nil
+}
+
+func compileWindow(w Window) (window, error) {
+	var out window
+	if len(w.Days) == 0 {
+		for i := range out.days {
+			out.days[i] = true
+		}
+	}
+	for _, day := range w.Days {
+		key := strings.ToLower(strings.TrimSpace(day))
+		if len(key) > 3 {
+			key = key[:3]
+		}
+		weekday, ok := weekdays[key]
+		if !ok {
+			return window{}, fmt.Errorf("unknown day %q", day)
+		}
+		out.days[weekday] = true
+	}
+	var err error
+	if out.start, err = parseClock(w.Start); err != nil || out.start == 24*60 {
+		return window{}, fmt.Errorf("invalid start %q", w.Start)
+	}
+	if out.end, err = parseClock(w.End); err != nil {
+		return window{}, fmt.Errorf("invalid end %q", w.End)
+	}
+	if out.start == out.end {
+		return window{}, errors.New("start and end are equal")
+	}
+	return out, nil
+}
+
+func (w window) canonical(src Window) Window {
+	out := Window{Start: formatClock(w.start), End: formatClock(w.end)}
+	if len(src.Days) == 0 {
+		return out
+	}
+	for _, name := range []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"} {
+		if w.days[weekdays[name]] {
+			out.Days = append(out.Days, name)
+		}
+	}
+	return out
+}
+
+// parseClock reads "HH:MM" into minutes after midnight; "24:00" is the end
+// of the day.
+func parseClock(raw string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(raw), ":")
+	if !ok {
+		return 0, errors.New("expected HH:MM")
+	}
+	hours, err := strconv.Atoi(hh)
+	if err != nil {
+		return 0, err
+	}
+	minutes, err := strconv.Atoi(mm)
+	if err != nil {
+		return 0, err
+	}
+	if hours < 0 || minutes < 0 || minutes > 59 || hours > 24 || (hours == 24 && minutes != 0) {
+		return 0, errors.New("out of range")
+	}
+	return hours*60 + minutes, nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// at returns the wall-clock time minutes after midnight of day in loc.
+func at(day time.Time, minutes int, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, loc)
+}
+
+// open reports whether t falls inside one of the windows. Windows that run
+// past midnight are checked from the day they start.
+func (s schedule) open(t time.Time) bool {
+	local := t.In(s.loc)
+	for _, w := range s.windows {
+		for offset := -1; offset <= 0; offset++ {
+			day := local.AddDate(0, 0, offset)
+			if !w.days[day.Weekday()] {
+				continue
+			}
+			start := at(day, w.start, s.loc)
+			end := at(day, w.end, s.loc)
+			if w.end <= w.start {
+				end = at(day.AddDate(0, 0, 1), w.end, s.loc)
+			}
+			if !local.Before(start) && local.Before(end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextOpening returns the first window start after t within a week, or the
+// zero time when no window ever opens.
+func (s schedule) nextOpening(t time.Time) time.Time {
+	local := t.In(s.loc)
+	var next time.Time
+	for offset := 0; offset <= 7; offset++ {
+		day := local.AddDate(0, 0, offset)
+		for _, w := range s.windows {
+			if !w.days[day.Weekday()] {
+				continue
+			}
+			start := at(day, w.start, s.loc)
+			if start.After(local) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+	return next
+}
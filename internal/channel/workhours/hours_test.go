@@ -0,0 +1,98 @@
+package workhours
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	return loc
+}
+
+func TestNormalizeCanonicalizesWindows(t *testing.T) {
+	t.Parallel()
+
+	got, err := Normalize(Hours{
+		Enabled:  true,
+		Timezone: " Europe/Berlin ",
+		Windows: []Window{
+			{Days: []string{"Friday", "MON", "tue"}, Start: "9:00", End: "17:30"},
+			{Start: "22:00", End: "24:00"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	want := []Window{
+		{Days: []string{"mon", "tue", "fri"}, Start: "09:00", End: "17:30"},
+		{Start: "22:00", End: "24:00"},
+	}
+	if got.Timezone != "Europe/Berlin" || !reflect.DeepEqual(got.Windows, want) {
+		t.Fatalf("Normalize = %+v", got)
+	}
+}
+
+func TestNormalizeRejectsInvalidHours(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]Hours{
+		"missing timezone": {Enabled: true, Windows: []Window{{Start: "09:00", End: "17:00"}}},
+		"unknown timezone": {Enabled: true, Timezone: "Mars/Olympus", Windows: []Window{{Start: "09:00", End: "17:00"}}},
+		"no windows":       {Enabled: true, Timezone: "UTC"},
+		"bad day":          {Timezone: "UTC", Windows: []Window{{Days: []string{"someday"}, Start: "09:00", End: "17:00"}}},
+		"bad clock":        {Timezone: "UTC", Windows: []Window{{Start: "25:00", End: "17:00"}}},
+		"empty window":     {Timezone: "UTC", Windows: []Window{{Start: "09:00", End: "09:00"}}},
+	}
+	for name, hours := range cases {
+		if _, err := Normalize(hours); !errors.Is(err, ErrInvalidHours) {
+			t.Fatalf("%s: err = %v, want ErrInvalidHours", name, err)
+		}
+	}
+}
+
+func TestScheduleOpenAndNextOpening(t *testing.T) {
+	t.Parallel()
+
+	loc := mustLocation(t, "America/New_York")
+	sched, err := compile(Hours{
+		Timezone: "America/New_York",
+		Windows: []Window{
+			{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"},
+			{Days: []string{"sat"}, Start: "22:00", End: "02:00"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		at   time.Time
+		open bool
+		next time.Time
+	}{
+		{name: "weekday office hours", at: time.Date(2026, 10, 14, 10, 0, 0, 0, loc), open: true},
+		{name: "weekday evening", at: time.Date(2026, 10, 14, 18, 0, 0, 0, loc), next: time.Date(2026, 10, 15, 9, 0, 0, 0, loc)},
+		{name: "friday evening waits for saturday night", at: time.Date(2026, 10, 16, 17, 0, 0, 0, loc), next: time.Date(2026, 10, 17, 22, 0, 0, 0, loc)},
+		{name: "overnight window after midnight", at: time.Date(2026, 10, 18, 1, 30, 0, 0, loc), open: true},
+		{name: "sunday waits for monday", at: time.Date(2026, 10, 18, 12, 0, 0, 0, loc), next: time.Date(2026, 10, 19, 9, 0, 0, 0, loc)},
+	}
+	for _, tc := range cases {
+		if got := sched.open(tc.at); got != tc.open {
+			t.Fatalf("%s: open = %v, want %v", tc.name, got, tc.open)
+		}
+		if tc.open {
+			continue
+		}
+		if got := sched.nextOpening(tc.at); !got.Equal(tc.next) {
+			t.Fatalf("%s: next opening = %v, want %v", tc.name, got, tc.next)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package workhours
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	statusFailed  = "failed"
+	statusPending = "pending"
+
+	deliveryInterval    = time.Minute
+	deliveryBatchSize   = 50
+	maxDeliveryAttempts = 5
+	retryBaseDelay      = time.Minute
+	// maxErrorBytes caps the stored cause; wrapped provider errors can carry
+	// whole response bodies.
+	maxErrorBytes = 4096
+	// maxAwayNotices bounds the away-notice memory before expired entries
+	// are swept.
+	maxAwayNotices = 4096
+)
+
+type hoursQueries interface {
+	GetBotWorkingHours(ctx context.Context, botID pgtype.UUID) (sqlc.BotWorkingHour, error)
+	UpsertBotWorkingHours(ctx context.Context, arg sqlc.UpsertBotWorkingHoursParams) (sqlc.BotWorkingHour, error)
+	InsertDeferredInboundMessage(ctx context.Context, arg sqlc.InsertDeferredInboundMessageParams) (sqlc.DeferredInboundMessage, error)
+	ListDueDeferredInboundMessages(ctx context.Context, arg sqlc.ListDueDeferredInboundMessagesParams) ([]sqlc.DeferredInboundMessage, error)
+	MarkDeferredInboundMessageDelivered(ctx context.Context, id pgtype.UUID) error
+	MarkDeferredInboundMessageFailed(ctx context.Context, arg sqlc.MarkDeferredInboundMessageFailedParams) error
+}
+
+// Replayer re-drives a deferred message through inbound processing.
+// channel.Manager implements it.
+type Replayer interface {
+	ReplayInbound(ctx context.Context, botID string, channelType channel.ChannelType, msg channel.InboundMessage) error
+}
+
+// Decision is the outcome of checking a message against working hours.
+type Decision struct {
+	Open bool
+	// Notify is set for the first closed-hours message of a conversation in
+	// each closed period, so the away reply is not repeated.
+	Notify      bool
+	AwayMessage string
+	Defer       bool
+	OpensAt     time.Time
+	Location    *time.Location
+}
+
+// Service stores working hours and the deferred message queue.
+type Service struct {
+	queries dbstore.Queries
+	logger  *slog.Logger
+
+	noticeMu sync.Mutex
+	notices  map[string]time.Time // key: "botID|conversationKey" → opens at
+}
+
+// NewService creates a working hours service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "working_hours")),
+		notices: map[string]time.Time{},
+	}
+}
+
+func (s *Service) store() (hoursQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("working hours service not configured")
+	}
+	store, ok := s.queries.(hoursQueries)
+	if !ok {
+		return nil, errors.New("working hours queries not supported by store")
+	}
+	return store, nil
+}
+
+// Get returns the bot's working hours; bots without any are always open.
+func (s *Service) Get(ctx context.Context, botID string) (Hours, error) {
+	store, err := s.store()
+	if err != nil {
+		return Hours{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Hours{}, fmt.Errorf("invalid bot id: %w", err)
+	}
+	row, err := store.GetBotWorkingHours(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Hours{Windows: []Window{}}, nil
+		}
+		return Hours{}, fmt.Errorf("get working hours: %w", err)
+	}
+	hours := Hours{Windows: []Window{}}
+	if len(row.Config) > 0 {
+		if err := json.Unmarshal(row.Config, &hours); err != nil {
+			return Hours{}, fmt.Errorf("decode working hours: %w", err)
+		}
+	}
+	return hours, nil
+}
+
+// Replace validates hours and stores them for the bot.
+func (s *Service) Replace(ctx context.Context, botID string, hours Hours) (Hours, error) {
+	store, err := s.store()
+	if err != nil {
+		return Hours{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Hours{}, fmt.Errorf("invalid bot id: %w", err)
+	}
+	normalized, err := Normalize(hours)
+	if err != nil {
+		return Hours{}, err
+	}
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return Hours{}, fmt.Errorf("encode working hours: %w", err)
+	}
+	if _, err := store.UpsertBotWorkingHours(ctx, sqlc.UpsertBotWorkingHoursParams{
+		BotID:  pgBotID,
+		Config: raw,
+	}); err != nil {
+		return Hours{}, fmt.Errorf("store working hours: %w", err)
+	}
+	return normalized, nil
+}
+
+// Check evaluates the bot's working hours at now for a message in the
+// conversation identified by conversationKey.
+func (s *Service) Check(ctx context.Context, botID, conversationKey string, now time.Time) (Decision, error) {
+	hours, err := s.Get(ctx, botID)
+	if err != nil {
+		return Decision{}, err
+	}
+	if !hours.Enabled || len(hours.Windows) == 0 {
+		return Decision{Open: true}, nil
+	}
+	sched, err := compile(hours)
+	if err != nil {
+		return Decision{}, fmt.Errorf("%w: %w", ErrInvalidHours, err)
+	}
+	if sched.open(now) {
+		return Decision{Open: true}, nil
+	}
+	opensAt := sched.nextOpening(now)
+	return Decision{
+		Notify:      s.claimNotice(strings.TrimSpace(botID)+"|"+conversationKey, opensAt, now),
+		AwayMessage: hours.AwayMessage,
+		Defer:       hours.DeferReplies && !opensAt.IsZero(),
+		OpensAt:     opensAt,
+		Location:    sched.loc,
+	}, nil
+}
+
+// claimNotice reports whether the conversation has not yet been told about
+// the closed period ending at opensAt, and records that it now has.
+func (s *Service) claimNotice(key string, opensAt, now time.Time) bool {
+	s.noticeMu.Lock()
+	defer s.noticeMu.Unlock()
+	if last, ok := s.notices[key]; ok && last.Equal(opensAt) {
+		return false
+	}
+	if len(s.notices) >= maxAwayNotices {
+		for k, until := range s.notices {
+			if !until.After(now) {
+				delete(s.notices, k)
+			}
+		}
+	}
+	s.notices[key] = opensAt
+	return true
+}
+
+// Defer queues msg for replay at deliverAt.
+func (s *Service) Defer(ctx context.Context, botID string, channelType channel.ChannelType, msg channel.InboundMessage, deliverAt time.Time) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return fmt.Errorf("invalid bot id: %w", err)
+	}
+	if channelType == "" {
+		channelType = msg.Channel
+	}
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode inbound message: %w", err)
+	}
+	if _, err := store.InsertDeferredInboundMessage(ctx, sqlc.InsertDeferredInboundMessageParams{
+		BotID:       pgBotID,
+		ChannelType: channelType.String(),
+		Message:     raw,
+		DeliverAt:   pgtype.Timestamptz{Time: deliverAt.UTC(), Valid: true},
+	}); err != nil {
+		return fmt.Errorf("insert deferred message: %w", err)
+	}
+	return nil
+}
+
+// DeliverDue replays deferred messages whose time has come and returns how
+// many were delivered. A failed replay is retried with backoff and given
+// up after maxDeliveryAttempts.
+func (s *Service) DeliverDue(ctx context.Context, replayer Replayer, now time.Time) (int, error) {
+	store, err := s.store()
+	if err != nil {
+		return 0, err
+	}
+	if replayer == nil {
+		return 0, errors.New("inbound replayer not configured")
+	}
+	rows, err := store.ListDueDeferredInboundMessages(ctx, sqlc.ListDueDeferredInboundMessagesParams{
+		Now:      pgtype.Timestamptz{Time: now.UTC(), Valid: true},
+		RowLimit: deliveryBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list deferred messages: %w", err)
+	}
+	delivered := 0
+	for _, row := range rows {
+		var msg channel.InboundMessage
+		replayErr := json.Unmarshal(row.Message, &msg)
+		if replayErr == nil {
+			replayErr = replayer.ReplayInbound(ctx, row.BotID.String(), channel.ChannelType(row.ChannelType), msg)
+		}
+		if replayErr == nil {
+			if err := store.MarkDeferredInboundMessageDelivered(ctx, row.ID); err != nil {
+				return delivered, fmt.Errorf("mark deferred message delivered: %w", err)
+			}
+			delivered++
+			continue
+		}
+		attempts := int(row.Attempts) + 1
+		status := statusPending
+		if attempts >= maxDeliveryAttempts {
+			status = statusFailed
+		}
+		s.logger.Warn("deferred message delivery failed",
+			slog.String("message_id", row.ID.String()),
+			slog.String("bot_id", row.BotID.String()),
+			slog.Int("attempts", attempts),
+			slog.Any("error", replayErr))
+		if err := store.MarkDeferredInboundMessageFailed(ctx, sqlc.MarkDeferredInboundMessageFailedParams{
+			Status:    status,
+			Error:     errorText(replayErr),
+			DeliverAt: pgtype.Timestamptz{Time: now.Add(retryBaseDelay << (attempts - 1)).UTC(), Valid: true},
+			ID:        row.ID,
+		}); err != nil {
+			return delivered, fmt.Errorf("mark deferred message failed: %w", err)
+		}
+	}
+	return delivered, nil
+}
+
+// Run delivers due messages every minute until ctx is done.
+func (s *Service) Run(ctx context.Context, replayer Replayer) {
+	ticker := time.NewTicker(deliveryInterval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.DeliverDue(ctx, replayer, time.Now()); err != nil {
+			s.logger.Warn("deliver deferred messages failed", slog.Any("error", err))
+		} else if n > 0 {
+			s.logger.Info("delivered deferred messages", slog.Int("count", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	text := err.Error()
+	if len(text) > maxErrorBytes {
+		text = strings.ToValidUTF8(text[:maxErrorBytes], "")
+	}
+	return text
+}
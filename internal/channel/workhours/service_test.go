@@ -0,0 +1,172 @@
+package workhours
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const testBot = "11111111-1111-1111-1111-111111111111"
+
+type fakeHoursQueries struct {
+	dbstore.Queries
+
+	config   []byte
+	deferred []sqlc.DeferredInboundMessage
+}
+
+func (f *fakeHoursQueries) GetBotWorkingHours(context.Context, pgtype.UUID) (sqlc.BotWorkingHour, error) {
+	if f.config == nil {
+		return sqlc.BotWorkingHour{}, pgx.ErrNoRows
+	}
+	return sqlc.BotWorkingHour{Config: f.config}, nil
+}
+
+func (f *fakeHoursQueries) UpsertBotWorkingHours(_ context.Context, arg sqlc.UpsertBotWorkingHoursParams) (sqlc.BotWorkingHour, error) {
+	f.config = arg.Config
+	return sqlc.BotWorkingHour{BotID: arg.BotID, Config: arg.Config}, nil
+}
+
+func (f *fakeHoursQueries) InsertDeferredInboundMessage(_ context.Context, arg sqlc.InsertDeferredInboundMessageParams) (sqlc.DeferredInboundMessage, error) {
+	row := sqlc.DeferredInboundMessage{
+		ID:          pgtype.UUID{Bytes: [16]byte{byte(len(f.deferred) + 1)}, Valid: true},
+		BotID:       arg.BotID,
+		ChannelType: arg.ChannelType,
+		Message:     arg.Message,
+		DeliverAt:   arg.DeliverAt,
+		Status:      statusPending,
+	}
+	f.deferred = append(f.deferred, row)
+	return row, nil
+}
+
+func (f *fakeHoursQueries) ListDueDeferredInboundMessages(_ context.Context, arg sqlc.ListDueDeferredInboundMessagesParams) ([]sqlc.DeferredInboundMessage, error) {
+	var due []sqlc.DeferredInboundMessage
+	for _, row := range f.deferred {
+		if row.Status == statusPending && !row.DeliverAt.Time.After(arg.Now.Time) {
+			due = append(due, row)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeHoursQueries) MarkDeferredInboundMessageDelivered(_ context.Context, id pgtype.UUID) error {
+	for i := range f.deferred {
+		if f.deferred[i].ID == id {
+			f.deferred[i].Status = "delivered"
+			f.deferred[i].Attempts++
+		}
+	}
+	return nil
+}
+
+func (f *fakeHoursQueries) MarkDeferredInboundMessageFailed(_ context.Context, arg sqlc.MarkDeferredInboundMessageFailedParams) error {
+	for i := range f.deferred {
+		if f.deferred[i].ID == arg.ID {
+			f.deferred[i].Status = arg.Status
+			f.deferred[i].Error = arg.Error
+			f.deferred[i].DeliverAt = arg.DeliverAt
+			f.deferred[i].Attempts++
+		}
+	}
+	return nil
+}
+
+type fakeReplayer struct {
+	err      error
+	messages []channel.InboundMessage
+}
+
+func (f *fakeReplayer) ReplayInbound(_ context.Context, _ string, _ channel.ChannelType, msg channel.InboundMessage) error {
+	f.messages = append(f.messages, msg)
+	return f.err
+}
+
+func TestCheckNotifiesOncePerClosedPeriod(t *testing.T) {
+	t.Parallel()
+
+	queries := &fakeHoursQueries{}
+	svc := NewService(nil, queries)
+	ctx := context.Background()
+	if _, err := svc.Replace(ctx, testBot, Hours{
+		Enabled:      true,
+		Timezone:     "UTC",
+		Windows:      []Window{{Start: "09:00", End: "17:00"}},
+		DeferReplies: true,
+	}); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+
+	evening := time.Date(2026, 10, 14, 20, 0, 0, 0, time.UTC)
+	decision, err := svc.Check(ctx, testBot, "telegram:chat-1", evening)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if decision.Open || !decision.Notify || !decision.Defer || !decision.OpensAt.Equal(time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("closed decision = %+v", decision)
+	}
+	if again, _ := svc.Check(ctx, testBot, "telegram:chat-1", evening.Add(time.Hour)); again.Notify {
+		t.Fatalf("away notice repeated in the same closed period: %+v", again)
+	}
+	if other, _ := svc.Check(ctx, testBot, "telegram:chat-2", evening); !other.Notify {
+		t.Fatalf("other conversation should be notified: %+v", other)
+	}
+	if open, _ := svc.Check(ctx, testBot, "telegram:chat-1", evening.Add(14*time.Hour)); !open.Open {
+		t.Fatalf("expected open at 10:00: %+v", open)
+	}
+}
+
+func TestCheckWithoutHoursIsOpen(t *testing.T) {
+	t.Parallel()
+
+	decision, err := NewService(nil, &fakeHoursQueries{}).Check(context.Background(), testBot, "chat", time.Now())
+	if err != nil || !decision.Open {
+		t.Fatalf("decision = %+v, %v", decision, err)
+	}
+}
+
+func TestDeliverDueReplaysAndRetries(t *testing.T) {
+	t.Parallel()
+
+	queries := &fakeHoursQueries{}
+	svc := NewService(nil, queries)
+	ctx := context.Background()
+	opensAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	msg := channel.InboundMessage{Channel: "telegram", Message: channel.Message{ID: "m1", Text: "are you there?"}}
+	if err := svc.Defer(ctx, testBot, "", msg, opensAt); err != nil {
+		t.Fatalf("Defer: %v", err)
+	}
+
+	replayer := &fakeReplayer{}
+	if n, err := svc.DeliverDue(ctx, replayer, opensAt.Add(-time.Minute)); err != nil || n != 0 || len(replayer.messages) != 0 {
+		t.Fatalf("early delivery = %d, %v, replayed %d", n, err, len(replayer.messages))
+	}
+
+	replayer.err = errors.New("gateway down")
+	if n, err := svc.DeliverDue(ctx, replayer, opensAt); err != nil || n != 0 {
+		t.Fatalf("failed delivery = %d, %v", n, err)
+	}
+	if row := queries.deferred[0]; row.Status != statusPending || row.Error != "gateway down" || !row.DeliverAt.Time.After(opensAt) {
+		t.Fatalf("after failure row = %+v", row)
+	}
+
+	replayer.err = nil
+	n, err := svc.DeliverDue(ctx, replayer, opensAt.Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("delivery = %d, %v", n, err)
+	}
+	if got := replayer.messages[len(replayer.messages)-1]; got.Message.Text != "are you there?" || queries.deferred[0].ChannelType != "telegram" {
+		t.Fatalf("replayed %+v from %+v", got, queries.deferred[0])
+	}
+	if queries.deferred[0].Status != "delivered" {
+		t.Fatalf("status = %q", queries.deferred[0].Status)
+	}
+}
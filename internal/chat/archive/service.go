@@ -0,0 +1,301 @@
+// Package archive tiers old history message content to cold storage. The
+// content of messages older than a configured age moves into one compressed
+// object per message; the message row keeps a short text preview and a
+// pointer row records where the content went. Export and search load the
+// content back through Load.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/storage"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+const (
+	// startupDelay keeps the first pass away from server startup.
+	startupDelay = 5 * time.Minute
+	// runInterval is how often messages crossing the age limit are archived.
+	runInterval = 6 * time.Hour
+	batchSize   = 200
+	// previewRunes bounds the text kept in an archived message row.
+	previewRunes = 280
+)
+
+type archiveQueries interface {
+	ListMessagesForArchive(ctx context.Context, arg sqlc.ListMessagesForArchiveParams) ([]sqlc.ListMessagesForArchiveRow, error)
+	ArchiveMessageContent(ctx context.Context, arg sqlc.ArchiveMessageContentParams) (int64, error)
+	ListOrphanedMessageArchives(ctx context.Context, rowLimit int32) ([]sqlc.ListOrphanedMessageArchivesRow, error)
+	DeleteMessageArchive(ctx context.Context, messageID pgtype.UUID) error
+}
+
+// Result counts the work of one archive pass.
+type Result struct {
+	Archived int
+	// Skipped counts messages whose content could not be read; they are
+	// retried on the next pass.
+	Skipped int
+	// Removed counts objects deleted because their message was deleted.
+	Removed int
+}
+
+// Service moves old message content to cold storage and loads it back.
+type Service struct {
+	queries dbstore.Queries
+	objects storage.Provider
+	cipher  messagepkg.ContentCipher
+	logger  *slog.Logger
+}
+
+// NewService creates an archive service storing objects in provider.
+func NewService(log *slog.Logger, queries dbstore.Queries, provider storage.Provider) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		objects: provider,
+		logger:  log.With(slog.String("service", "message_archive")),
+	}
+}
+
+// SetContentCipher opens sealed content to build previews and seals the
+// preview row. Objects keep content exactly as stored, sealed or not.
+func (s *Service) SetContentCipher(cipher messagepkg.ContentCipher) {
+	s.cipher = cipher
+}
+
+func (s *Service) store() (archiveQueries, error) {
+	if s == nil || s.queries == nil || s.objects == nil {
+		return nil, errors.New("message archive service not configured")
+	}
+	store, ok := s.queries.(archiveQueries)
+	if !ok {
+		return nil, errors.New("message archive queries not supported by store")
+	}
+	return store, nil
+}
+
+// Run archives messages older than afterMonths every few hours until ctx is
+// cancelled. A non-positive afterMonths disables archiving.
+func (s *Service) Run(ctx context.Context, afterMonths int) {
+	if afterMonths <= 0 {
+		return
+	}
+	timer := time.NewTimer(startupDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		result, err := s.Archive(ctx, time.Now().AddDate(0, -afterMonths, 0))
+		if err != nil {
+			s.logger.Warn("message archive pass failed", slog.Any("error", err))
+		}
+		if result.Archived > 0 || result.Skipped > 0 || result.Removed > 0 {
+			s.logger.Info("message archive pass finished",
+				slog.Int("archived", result.Archived),
+				slog.Int("skipped", result.Skipped),
+				slog.Int("removed", result.Removed))
+		}
+		timer.Reset(runInterval)
+	}
+}
+
+// Archive moves the content of messages created before cutoff to cold
+// storage, then deletes the objects of messages that no longer exist. Only
+// messages no longer loaded as model context are archived: compacted ones
+// and those of sessions idle since cutoff.
+func (s *Service) Archive(ctx context.Context, cutoff time.Time) (Result, error) {
+	store, err := s.store()
+	if err != nil {
+		return Result{}, err
+	}
+	var result Result
+	var after pgtype.UUID
+	for {
+		rows, err := store.ListMessagesForArchive(ctx, sqlc.ListMessagesForArchiveParams{
+			Before:   pgtype.Timestamptz{Time: cutoff.UTC(), Valid: true},
+			AfterID:  after,
+			RowLimit: batchSize,
+		})
+		if err != nil {
+			return result, fmt.Errorf("list messages: %w", err)
+		}
+		for _, row := range rows {
+			archived, err := s.archiveRow(ctx, store, row)
+			if err != nil {
+				return result, err
+			}
+			if archived {
+				result.Archived++
+			} else {
+				result.Skipped++
+			}
+		}
+		if len(rows) < batchSize {
+			break
+		}
+		after = rows[len(rows)-1].ID
+	}
+	removed, err := s.removeOrphans(ctx, store)
+	result.Removed = removed
+	return result, err
+}
+
+// archiveRow writes one message's stored content to an object and swaps the
+// row content for a preview. Unreadable content is skipped, a storage or
+// database failure ends the pass.
+func (s *Service) archiveRow(ctx context.Context, store archiveQueries, row sqlc.ListMessagesForArchiveRow) (bool, error) {
+	content := row.Content
+	if s.cipher != nil {
+		opened, err := s.cipher.OpenJSON(ctx, row.BotID, row.Content)
+		if err != nil {
+			s.logger.Warn("archive: open message content failed", slog.String("message_id", row.ID.String()), slog.Any("error", err))
+			return false, nil
+		}
+		content = opened
+	}
+	stub, err := previewContent(row.Role, content)
+	if err != nil {
+		return false, err
+	}
+	if s.cipher != nil {
+		if stub, err = s.cipher.SealJSON(ctx, row.BotID, stub); err != nil {
+			return false, fmt.Errorf("seal archived message preview: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(row.Content); err != nil {
+		return false, err
+	}
+	if err := zw.Close(); err != nil {
+		return false, err
+	}
+	key := ObjectKey(row.BotID, row.ID, row.CreatedAt.Time)
+	size := int64(buf.Len())
+	if err := s.objects.Put(ctx, key, &buf); err != nil {
+		return false, fmt.Errorf("store archived message %s: %w", row.ID.String(), err)
+	}
+	if _, err := store.ArchiveMessageContent(ctx, sqlc.ArchiveMessageContentParams{
+		MessageID: row.ID,
+		BotID:     row.BotID,
+		ObjectKey: key,
+		SizeBytes: size,
+		Stub:      stub,
+	}); err != nil {
+		return false, fmt.Errorf("archive message %s: %w", row.ID.String(), err)
+	}
+	return true, nil
+}
+
+func (s *Service) removeOrphans(ctx context.Context, store archiveQueries) (int, error) {
+	removed := 0
+	for {
+		rows, err := store.ListOrphanedMessageArchives(ctx, batchSize)
+		if err != nil {
+			return removed, fmt.Errorf("list orphaned archives: %w", err)
+		}
+		for _, row := range rows {
+			if err := s.objects.Delete(ctx, row.ObjectKey); err != nil {
+				return removed, fmt.Errorf("delete archived object %s: %w", row.ObjectKey, err)
+			}
+			if err := store.DeleteMessageArchive(ctx, row.MessageID); err != nil {
+				return removed, fmt.Errorf("delete archive pointer: %w", err)
+			}
+			removed++
+		}
+		if len(rows) < batchSize {
+			return removed, nil
+		}
+	}
+}
+
+// Load returns the archived content under key as it was stored in the
+// message row: sealed when content encryption is enabled.
+func (s *Service) Load(ctx context.Context, key string) ([]byte, error) {
+	if s == nil || s.objects == nil {
+		return nil, errors.New("message archive service not configured")
+	}
+	rc, err := s.objects.Open(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("open archived message: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+	zr, err := gzip.NewReader(rc)
+	if err != nil {
+		return nil, fmt.Errorf("read archived message: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("read archived message: %w", err)
+	}
+	return content, nil
+}
+
+// ObjectKey is the storage key of a message's archived content, grouped by
+// bot and month.
+func ObjectKey(botID, messageID pgtype.UUID, createdAt time.Time) string {
+	return fmt.Sprintf("messages/%s/%s/%s.json.gz", botID.String(), createdAt.UTC().Format("2006-01"), messageID.String())
+}
+
+// previewContent is the document left in an archived message row: the
+// message's text, shortened, so listings and keyword search still see it.
+func previewContent(role string, content []byte) ([]byte, error) {
+	var doc struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	_ = json.Unmarshal(content, &doc)
+	if strings.TrimSpace(doc.Role) == "" {
+		doc.Role = role
+	}
+	return json.Marshal(struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}{
+		Role:    doc.Role,
+		Content: textutil.TruncateRunesWithSuffix(contentText(doc.Content), previewRunes, "…"),
+	})
+}
+
+// contentText joins the text of string content or of its text parts.
+func contentText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	lines := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == "text" && strings.TrimSpace(part.Text) != "" {
+			lines = append(lines, strings.TrimSpace(part.Text))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
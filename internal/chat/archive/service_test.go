@@ -0,0 +1,142 @@
+package archive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/storage/providers/localfs"
+)
+
+type fakeArchiveQueries struct {
+	dbstore.Queries
+
+	rows     []sqlc.ListMessagesForArchiveRow
+	archived map[pgtype.UUID]sqlc.ArchiveMessageContentParams
+	orphans  []sqlc.ListOrphanedMessageArchivesRow
+	deleted  []pgtype.UUID
+}
+
+func (f *fakeArchiveQueries) ListMessagesForArchive(_ context.Context, arg sqlc.ListMessagesForArchiveParams) ([]sqlc.ListMessagesForArchiveRow, error) {
+	var out []sqlc.ListMessagesForArchiveRow
+	for _, row := range f.rows {
+		if _, ok := f.archived[row.ID]; ok {
+			continue
+		}
+		if arg.AfterID.Valid && row.ID.String() <= arg.AfterID.String() {
+			continue
+		}
+		if !row.CreatedAt.Time.Before(arg.Before.Time) {
+			continue
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+func (f *fakeArchiveQueries) ArchiveMessageContent(_ context.Context, arg sqlc.ArchiveMessageContentParams) (int64, error) {
+	if f.archived == nil {
+		f.archived = map[pgtype.UUID]sqlc.ArchiveMessageContentParams{}
+	}
+	f.archived[arg.MessageID] = arg
+	return 1, nil
+}
+
+func (f *fakeArchiveQueries) ListOrphanedMessageArchives(context.Context, int32) ([]sqlc.ListOrphanedMessageArchivesRow, error) {
+	out := f.orphans
+	f.orphans = nil
+	return out, nil
+}
+
+func (f *fakeArchiveQueries) DeleteMessageArchive(_ context.Context, messageID pgtype.UUID) error {
+	f.deleted = append(f.deleted, messageID)
+	return nil
+}
+
+func uuid(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func TestArchiveMovesOldContentAndLoadsItBack(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	old := pgtype.Timestamptz{Time: now.AddDate(0, -7, 0), Valid: true}
+	recent := pgtype.Timestamptz{Time: now.AddDate(0, 0, -1), Valid: true}
+	content := []byte(`{"role":"assistant","content":[{"type":"text","text":"the answer"},{"type":"tool-result","toolName":"read","output":"long output"}]}`)
+	queries := &fakeArchiveQueries{rows: []sqlc.ListMessagesForArchiveRow{
+		{ID: uuid(1), BotID: uuid(9), Role: "assistant", Content: content, CreatedAt: old},
+		{ID: uuid(2), BotID: uuid(9), Role: "user", Content: []byte(`{"role":"user","content":"hi"}`), CreatedAt: recent},
+	}}
+	root := t.TempDir()
+	svc := NewService(nil, queries, localfs.New(root))
+
+	result, err := svc.Archive(context.Background(), now.AddDate(0, -6, 0))
+	if err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	if result.Archived != 1 || len(queries.archived) != 1 {
+		t.Fatalf("result = %+v, archived = %v", result, queries.archived)
+	}
+	params := queries.archived[uuid(1)]
+	if string(params.Stub) != `{"role":"assistant","content":"the answer"}` {
+		t.Fatalf("stub = %s", params.Stub)
+	}
+	if params.ObjectKey != ObjectKey(uuid(9), uuid(1), old.Time) || params.SizeBytes == 0 {
+		t.Fatalf("params = %+v", params)
+	}
+
+	loaded, err := svc.Load(context.Background(), params.ObjectKey)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if string(loaded) != string(content) {
+		t.Fatalf("loaded = %s, want %s", loaded, content)
+	}
+
+	queries.orphans = []sqlc.ListOrphanedMessageArchivesRow{{MessageID: uuid(1), ObjectKey: params.ObjectKey}}
+	result, err = svc.Archive(context.Background(), now.AddDate(0, -6, 0))
+	if err != nil {
+		t.Fatalf("Archive (orphans): %v", err)
+	}
+	if result.Archived != 0 || result.Removed != 1 || len(queries.deleted) != 1 {
+		t.Fatalf("result = %+v, deleted = %v", result, queries.deleted)
+	}
+	if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(params.ObjectKey))); !os.IsNotExist(err) {
+		t.Fatalf("orphaned object still exists: %v", err)
+	}
+}
+
+func TestPreviewContent(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		role    string
+		content string
+		want    string
+	}{
+		{name: "string", role: "user", content: `{"role":"user","content":"hello"}`, want: `{"role":"user","content":"hello"}`},
+		{name: "tool call only", role: "assistant", content: `{"role":"assistant","content":[],"tool_calls":[{"id":"c1"}]}`, want: `{"role":"assistant","content":""}`},
+		{name: "missing role", role: "tool", content: `{}`, want: `{"role":"tool","content":""}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := previewContent(tc.role, []byte(tc.content))
+			if err != nil {
+				t.Fatalf("previewContent: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("previewContent = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
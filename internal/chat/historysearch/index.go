@@ -0,0 +1,191 @@
+package historysearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	sdk "github.com/memohai/twilight-ai/sdk"
+	"github.com/pgvector/pgvector-go"
+
+	pgvectordb "github.com/memohai/memoh/internal/db/pgvector"
+	pgvectorsqlc "github.com/memohai/memoh/internal/db/pgvector/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/models"
+)
+
+// embeddedMessage is one message vector written to the index.
+type embeddedMessage struct {
+	MessageID pgtype.UUID
+	SessionID pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+	Vector    []float32
+}
+
+// messageHit is a message returned from the vector index.
+type messageHit struct {
+	MessageID pgtype.UUID
+	Score     float64
+}
+
+// vectorIndex stores message embeddings, one namespace per bot. It holds
+// message IDs only; the text stays in the main database.
+type vectorIndex interface {
+	Upsert(ctx context.Context, teamID, botID, modelID pgtype.UUID, messages []embeddedMessage) error
+	Search(ctx context.Context, teamID, botID, modelID, sessionID pgtype.UUID, vector []float32, limit int) ([]messageHit, error)
+	DeleteBot(ctx context.Context, teamID, botID pgtype.UUID) error
+}
+
+// embedFunc embeds text with the bot's embedding model.
+type embedFunc func(ctx context.Context, queries dbstore.Queries, modelID pgtype.UUID, text string) ([]float32, error)
+
+type pgvectorIndex struct {
+	store *pgvectordb.Store
+}
+
+func newPGVectorIndex(store *pgvectordb.Store) vectorIndex {
+	if store == nil {
+		return nil
+	}
+	return &pgvectorIndex{store: store}
+}
+
+// withTeamTx binds the pgvector RLS context transaction-locally, mirroring the
+// knowledge index.
+func (r *pgvectorIndex) withTeamTx(ctx context.Context, teamID pgtype.UUID, fn func(*pgvectorsqlc.Queries) error) error {
+	tx, err := r.store.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("history search index: begin team transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, "SELECT set_config('memoh.team_id', $1, true)", teamID.String()); err != nil {
+		return fmt.Errorf("history search index: bind team: %w", err)
+	}
+	if err := fn(r.store.Queries().WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pgvectorIndex) Upsert(ctx context.Context, teamID, botID, modelID pgtype.UUID, messages []embeddedMessage) error {
+	return r.withTeamTx(ctx, teamID, func(q *pgvectorsqlc.Queries) error {
+		for _, msg := range messages {
+			if err := q.UpsertHistoryMessageEmbedding(ctx, pgvectorsqlc.UpsertHistoryMessageEmbeddingParams{
+				TeamID:     teamID,
+				BotID:      botID,
+				MessageID:  msg.MessageID,
+				ModelID:    modelID,
+				SessionID:  msg.SessionID,
+				Dimensions: int32(len(msg.Vector)), //nolint:gosec // embedding sizes are far below int32.
+				Embedding:  pgvector.NewVector(msg.Vector),
+				CreatedAt:  msg.CreatedAt,
+			}); err != nil {
+				return fmt.Errorf("upsert message embedding: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *pgvectorIndex) Search(ctx context.Context, teamID, botID, modelID, sessionID pgtype.UUID, vector []float32, limit int) ([]messageHit, error) {
+	var hits []messageHit
+	err := r.withTeamTx(ctx, teamID, func(q *pgvectorsqlc.Queries) error {
+		rows, err := q.SearchHistoryMessageEmbeddings(ctx, pgvectorsqlc.SearchHistoryMessageEmbeddingsParams{
+			Embedding: pgvector.NewVector(vector),
+			TeamID:    teamID,
+			BotID:     botID,
+			ModelID:   modelID,
+			SessionID: sessionID,
+			RowLimit:  int32(limit), //nolint:gosec // bounded by the caller.
+		})
+		if err != nil {
+			return fmt.Errorf("search message embeddings: %w", err)
+		}
+		hits = make([]messageHit, 0, len(rows))
+		for _, row := range rows {
+			hits = append(hits, messageHit{MessageID: row.MessageID, Score: row.Score})
+		}
+		return nil
+	})
+	return hits, err
+}
+
+func (r *pgvectorIndex) DeleteBot(ctx context.Context, teamID, botID pgtype.UUID) error {
+	return r.withTeamTx(ctx, teamID, func(q *pgvectorsqlc.Queries) error {
+		return q.DeleteBotHistoryMessageEmbeddings(ctx, pgvectorsqlc.DeleteBotHistoryMessageEmbeddingsParams{
+			TeamID: teamID,
+			BotID:  botID,
+		})
+	})
+}
+
+// embeddingModel is a resolved, enabled embedding model.
+type embeddingModel struct {
+	clientType string
+	baseURL    string
+	apiKey     string
+	modelID    string
+	dimensions int
+}
+
+func resolveEmbeddingModel(ctx context.Context, queries dbstore.Queries, id pgtype.UUID) (embeddingModel, error) {
+	if queries == nil {
+		return embeddingModel{}, errors.New("queries are required")
+	}
+	row, err := queries.GetModelByID(ctx, id)
+	if err != nil {
+		return embeddingModel{}, fmt.Errorf("%w: %s", ErrInvalidEmbeddingModel, id.String())
+	}
+	if row.Type != "embedding" || !row.Enable || !row.ProviderID.Valid {
+		return embeddingModel{}, fmt.Errorf("%w: %s is not an enabled embedding model", ErrInvalidEmbeddingModel, id.String())
+	}
+	provider, err := queries.GetProviderByID(ctx, row.ProviderID)
+	if err != nil {
+		return embeddingModel{}, fmt.Errorf("get embedding provider: %w", err)
+	}
+	var modelCfg struct {
+		Dimensions *int `json:"dimensions"`
+	}
+	if len(row.Config) > 0 {
+		_ = json.Unmarshal(row.Config, &modelCfg)
+	}
+	var providerCfg map[string]any
+	if len(provider.Config) > 0 {
+		_ = json.Unmarshal(provider.Config, &providerCfg)
+	}
+	baseURL, _ := providerCfg["base_url"].(string)
+	apiKey, _ := providerCfg["api_key"].(string)
+	spec := embeddingModel{
+		clientType: strings.TrimSpace(provider.ClientType),
+		baseURL:    strings.TrimSpace(baseURL),
+		apiKey:     strings.TrimSpace(apiKey),
+		modelID:    strings.TrimSpace(row.ModelID),
+	}
+	if modelCfg.Dimensions != nil {
+		spec.dimensions = *modelCfg.Dimensions
+	}
+	return spec, nil
+}
+
+func embedWithModel(ctx context.Context, queries dbstore.Queries, modelID pgtype.UUID, text string) ([]float32, error) {
+	spec, err := resolveEmbeddingModel(ctx, queries, modelID)
+	if err != nil {
+		return nil, err
+	}
+	model := models.NewSDKEmbeddingModel(spec.clientType, spec.baseURL, spec.apiKey, spec.modelID, models.DefaultProviderRequestTimeout, nil)
+	vec, err := sdk.NewClient().Embed(ctx, text, sdk.WithEmbeddingModel(model))
+	if err != nil {
+		return nil, fmt.Errorf("embed: %w", err)
+	}
+	out := make([]float32, len(vec))
+	for i, v := range vec {
+		out[i] = float32(v)
+	}
+	if spec.dimensions > 0 && len(out) != spec.dimensions {
+		return nil, fmt.Errorf("embedding dimensions = %d, want %d", len(out), spec.dimensions)
+	}
+	return out, nil
+}
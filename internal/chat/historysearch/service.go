@@ -0,0 +1,423 @@
+// Package historysearch searches a bot's persisted conversation history
+// across all sessions. Besides keyword matching, a bot can enable a semantic
+// index: a background worker embeds its user and assistant messages into a
+// per-bot pgvector namespace, and search embeds the query and ranks messages
+// by similarity. The vector namespace holds message IDs only; text is read
+// back from the main database, opening sealed content as needed.
+package historysearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
+	"github.com/memohai/memoh/internal/db"
+	pgvectordb "github.com/memohai/memoh/internal/db/pgvector"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+	// maxEmbedRunes bounds the text embedded per message; long messages are
+	// represented by their beginning.
+	maxEmbedRunes = 4000
+)
+
+var (
+	ErrIndexNotEnabled       = errors.New("semantic history search is not enabled for this bot")
+	ErrInvalidEmbeddingModel = errors.New("invalid embedding model")
+	ErrInvalidQuery          = errors.New("invalid search query")
+	ErrVectorIndexDisabled   = errors.New("pgvector is not configured")
+)
+
+type searchQueries interface {
+	GetHistorySearchIndex(ctx context.Context, botID pgtype.UUID) (sqlc.BotHistorySearchIndex, error)
+	ListHistorySearchIndexes(ctx context.Context) ([]sqlc.BotHistorySearchIndex, error)
+	UpsertHistorySearchIndex(ctx context.Context, arg sqlc.UpsertHistorySearchIndexParams) (sqlc.BotHistorySearchIndex, error)
+	DeleteHistorySearchIndex(ctx context.Context, botID pgtype.UUID) (int64, error)
+	ResetHistorySearchIndex(ctx context.Context, botID pgtype.UUID) (int64, error)
+	AdvanceHistorySearchIndex(ctx context.Context, arg sqlc.AdvanceHistorySearchIndexParams) error
+	RecordHistorySearchIndexError(ctx context.Context, arg sqlc.RecordHistorySearchIndexErrorParams) error
+	ListHistoryMessagesForSearchIndex(ctx context.Context, arg sqlc.ListHistoryMessagesForSearchIndexParams) ([]sqlc.ListHistoryMessagesForSearchIndexRow, error)
+	GetHistoryMessagesForSearch(ctx context.Context, arg sqlc.GetHistoryMessagesForSearchParams) ([]sqlc.GetHistoryMessagesForSearchRow, error)
+	SearchMessages(ctx context.Context, arg sqlc.SearchMessagesParams) ([]sqlc.SearchMessagesRow, error)
+}
+
+// Service manages semantic history indexes, runs the indexing worker and
+// answers history searches.
+type Service struct {
+	queries dbstore.Queries
+	index   vectorIndex
+	embed   embedFunc
+	cipher  messagepkg.ContentCipher
+	logger  *slog.Logger
+}
+
+// NewService creates a history search service. Without a pgvector store only
+// keyword search is available.
+func NewService(log *slog.Logger, queries dbstore.Queries, vectors *pgvectordb.Store) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		index:   newPGVectorIndex(vectors),
+		embed:   embedWithModel,
+		logger:  log.With(slog.String("service", "history_search")),
+	}
+}
+
+// SetContentCipher opens sealed message content for embedding and results.
+func (s *Service) SetContentCipher(cipher messagepkg.ContentCipher) {
+	s.cipher = cipher
+}
+
+func (s *Service) store() (searchQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("history search service not configured")
+	}
+	store, ok := s.queries.(searchQueries)
+	if !ok {
+		return nil, errors.New("history search queries not supported by store")
+	}
+	return store, nil
+}
+
+// GetIndex returns the bot's semantic index, or ErrIndexNotEnabled.
+func (s *Service) GetIndex(ctx context.Context, botID string) (Index, error) {
+	store, err := s.store()
+	if err != nil {
+		return Index{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Index{}, err
+	}
+	row, err := store.GetHistorySearchIndex(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Index{}, ErrIndexNotEnabled
+		}
+		return Index{}, fmt.Errorf("get history search index: %w", err)
+	}
+	return toIndex(row), nil
+}
+
+// EnableIndex turns on semantic search for the bot. The worker embeds the
+// existing history in the background, oldest first.
+func (s *Service) EnableIndex(ctx context.Context, botID string, req EnableRequest) (Index, error) {
+	store, err := s.store()
+	if err != nil {
+		return Index{}, err
+	}
+	if s.index == nil {
+		return Index{}, ErrVectorIndexDisabled
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Index{}, err
+	}
+	modelID, err := db.ParseUUID(strings.TrimSpace(req.EmbeddingModelID))
+	if err != nil {
+		return Index{}, fmt.Errorf("%w: embedding_model_id is required", ErrInvalidEmbeddingModel)
+	}
+	if _, err := resolveEmbeddingModel(ctx, s.queries, modelID); err != nil {
+		return Index{}, err
+	}
+	current, err := store.GetHistorySearchIndex(ctx, pgBotID)
+	switch {
+	case err == nil:
+		if current.EmbeddingModelID != modelID {
+			if err := s.index.DeleteBot(ctx, current.TeamID, pgBotID); err != nil {
+				return Index{}, fmt.Errorf("drop previous embeddings: %w", err)
+			}
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return Index{}, fmt.Errorf("get history search index: %w", err)
+	}
+	row, err := store.UpsertHistorySearchIndex(ctx, sqlc.UpsertHistorySearchIndexParams{
+		BotID:            pgBotID,
+		EmbeddingModelID: modelID,
+	})
+	if err != nil {
+		return Index{}, fmt.Errorf("enable history search index: %w", err)
+	}
+	return toIndex(row), nil
+}
+
+// DisableIndex turns off semantic search and drops the bot's embeddings.
+func (s *Service) DisableIndex(ctx context.Context, botID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	row, err := store.GetHistorySearchIndex(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrIndexNotEnabled
+		}
+		return fmt.Errorf("get history search index: %w", err)
+	}
+	if s.index != nil {
+		if err := s.index.DeleteBot(ctx, row.TeamID, pgBotID); err != nil {
+			return fmt.Errorf("drop embeddings: %w", err)
+		}
+	}
+	if _, err := store.DeleteHistorySearchIndex(ctx, pgBotID); err != nil {
+		return fmt.Errorf("disable history search index: %w", err)
+	}
+	return nil
+}
+
+// RebuildIndex drops the bot's embeddings and embeds its history again, for
+// example after importing messages with past timestamps.
+func (s *Service) RebuildIndex(ctx context.Context, botID string) (Index, error) {
+	store, err := s.store()
+	if err != nil {
+		return Index{}, err
+	}
+	if s.index == nil {
+		return Index{}, ErrVectorIndexDisabled
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Index{}, err
+	}
+	row, err := store.GetHistorySearchIndex(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Index{}, ErrIndexNotEnabled
+		}
+		return Index{}, fmt.Errorf("get history search index: %w", err)
+	}
+	if err := s.index.DeleteBot(ctx, row.TeamID, pgBotID); err != nil {
+		return Index{}, fmt.Errorf("drop embeddings: %w", err)
+	}
+	if _, err := store.ResetHistorySearchIndex(ctx, pgBotID); err != nil {
+		return Index{}, fmt.Errorf("reset history search index: %w", err)
+	}
+	return s.GetIndex(ctx, botID)
+}
+
+// Search finds messages in the bot's history. Semantic hits of messages
+// deleted or hidden since they were embedded are dropped.
+func (s *Service) Search(ctx context.Context, botID string, req SearchRequest) (SearchResponse, error) {
+	store, err := s.store()
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return SearchResponse{}, fmt.Errorf("%w: q is required", ErrInvalidQuery)
+	}
+	var sessionID pgtype.UUID
+	if raw := strings.TrimSpace(req.SessionID); raw != "" {
+		if sessionID, err = db.ParseUUID(raw); err != nil {
+			return SearchResponse{}, fmt.Errorf("%w: invalid session_id", ErrInvalidQuery)
+		}
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+	switch mode {
+	case "", ModeAuto:
+		row, err := store.GetHistorySearchIndex(ctx, pgBotID)
+		switch {
+		case err == nil && s.index != nil:
+			resp, err := s.searchSemantic(ctx, store, row, query, sessionID, limit)
+			if errors.Is(err, pgvectordb.ErrNotReady) {
+				// The vector database has not come up since boot.
+				return s.searchKeyword(ctx, store, pgBotID, query, sessionID, limit)
+			}
+			return resp, err
+		case err == nil || errors.Is(err, pgx.ErrNoRows):
+			return s.searchKeyword(ctx, store, pgBotID, query, sessionID, limit)
+		default:
+			return SearchResponse{}, fmt.Errorf("get history search index: %w", err)
+		}
+	case ModeSemantic:
+		if s.index == nil {
+			return SearchResponse{}, ErrVectorIndexDisabled
+		}
+		row, err := store.GetHistorySearchIndex(ctx, pgBotID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return SearchResponse{}, ErrIndexNotEnabled
+			}
+			return SearchResponse{}, fmt.Errorf("get history search index: %w", err)
+		}
+		return s.searchSemantic(ctx, store, row, query, sessionID, limit)
+	case ModeKeyword:
+		return s.searchKeyword(ctx, store, pgBotID, query, sessionID, limit)
+	default:
+		return SearchResponse{}, fmt.Errorf("%w: unknown mode %q", ErrInvalidQuery, req.Mode)
+	}
+}
+
+func (s *Service) searchSemantic(ctx context.Context, store searchQueries, index sqlc.BotHistorySearchIndex, query string, sessionID pgtype.UUID, limit int) (SearchResponse, error) {
+	vector, err := s.embed(ctx, s.queries, index.EmbeddingModelID, query)
+	if err != nil {
+		return SearchResponse{}, fmt.Errorf("embed query: %w", err)
+	}
+	hits, err := s.index.Search(ctx, index.TeamID, index.BotID, index.EmbeddingModelID, sessionID, vector, limit)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	resp := SearchResponse{Mode: ModeSemantic, Items: []Hit{}}
+	if len(hits) == 0 {
+		return resp, nil
+	}
+	ids := make([]pgtype.UUID, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.MessageID)
+	}
+	rows, err := store.GetHistoryMessagesForSearch(ctx, sqlc.GetHistoryMessagesForSearchParams{
+		BotID: index.BotID,
+		Ids:   ids,
+	})
+	if err != nil {
+		return SearchResponse{}, fmt.Errorf("load messages: %w", err)
+	}
+	byID := make(map[pgtype.UUID]sqlc.GetHistoryMessagesForSearchRow, len(rows))
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+	for _, hit := range hits {
+		row, ok := byID[hit.MessageID]
+		if !ok {
+			continue
+		}
+		resp.Items = append(resp.Items, Hit{
+			MessageID:         row.ID.String(),
+			SessionID:         uuidString(row.SessionID),
+			Role:              row.Role,
+			Text:              s.messageText(ctx, row.BotID, row.Content),
+			SenderDisplayName: db.TextToString(row.SenderDisplayName),
+			Platform:          db.TextToString(row.Platform),
+			Score:             hit.Score,
+			CreatedAt:         db.TimeFromPg(row.CreatedAt),
+		})
+	}
+	sort.SliceStable(resp.Items, func(i, j int) bool { return resp.Items[i].Score > resp.Items[j].Score })
+	return resp, nil
+}
+
+func (s *Service) searchKeyword(ctx context.Context, store searchQueries, botID pgtype.UUID, query string, sessionID pgtype.UUID, limit int) (SearchResponse, error) {
+	rows, err := store.SearchMessages(ctx, sqlc.SearchMessagesParams{
+		BotID:     botID,
+		SessionID: sessionID,
+		Keyword:   pgtype.Text{String: query, Valid: true},
+		MaxCount:  int32(limit), //nolint:gosec // bounded by maxLimit.
+	})
+	if err != nil {
+		return SearchResponse{}, fmt.Errorf("search messages: %w", err)
+	}
+	resp := SearchResponse{Mode: ModeKeyword, Items: make([]Hit, 0, len(rows))}
+	for _, row := range rows {
+		resp.Items = append(resp.Items, Hit{
+			MessageID:         row.ID.String(),
+			SessionID:         uuidString(row.SessionID),
+			Role:              row.Role,
+			Text:              s.messageText(ctx, row.BotID, row.Content),
+			SenderDisplayName: db.TextToString(row.SenderDisplayName),
+			Platform:          db.TextToString(row.Platform),
+			CreatedAt:         db.TimeFromPg(row.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// messageText opens stored content and returns its text. Content that cannot
+// be opened yields no text rather than failing the search.
+func (s *Service) messageText(ctx context.Context, botID pgtype.UUID, stored []byte) string {
+	content := stored
+	if s.cipher != nil {
+		opened, err := s.cipher.OpenJSON(ctx, botID, stored)
+		if err != nil {
+			s.logger.Warn("history search: open message content failed", slog.Any("error", err))
+			return ""
+		}
+		content = opened
+	}
+	var doc struct {
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return ""
+	}
+	return contentText(doc.Content)
+}
+
+// contentText joins the text of string content or of its text parts.
+func contentText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	lines := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == "text" && strings.TrimSpace(part.Text) != "" {
+			lines = append(lines, strings.TrimSpace(part.Text))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func toIndex(row sqlc.BotHistorySearchIndex) Index {
+	return Index{
+		BotID:            row.BotID.String(),
+		EmbeddingModelID: row.EmbeddingModelID.String(),
+		IndexedCount:     row.IndexedCount,
+		LastError:        row.LastError,
+		LastIndexedAt:    optionalTime(row.LastIndexedAt),
+		CreatedAt:        db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:        db.TimeFromPg(row.UpdatedAt),
+	}
+}
+
+func optionalTime(value pgtype.Timestamptz) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time
+	return &t
+}
+
+func uuidString(value pgtype.UUID) string {
+	if !value.Valid {
+		return ""
+	}
+	return value.String()
+}
@@ -0,0 +1,316 @@
+package historysearch
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+type fakeSearchQueries struct {
+	dbstore.Queries
+
+	index    *sqlc.BotHistorySearchIndex
+	messages []sqlc.ListHistoryMessagesForSearchIndexRow
+	hidden   map[pgtype.UUID]bool
+	keyword  []sqlc.SearchMessagesRow
+	errors   []string
+}
+
+func (f *fakeSearchQueries) GetHistorySearchIndex(context.Context, pgtype.UUID) (sqlc.BotHistorySearchIndex, error) {
+	if f.index == nil {
+		return sqlc.BotHistorySearchIndex{}, pgx.ErrNoRows
+	}
+	return *f.index, nil
+}
+
+func (f *fakeSearchQueries) ListHistorySearchIndexes(context.Context) ([]sqlc.BotHistorySearchIndex, error) {
+	if f.index == nil {
+		return nil, nil
+	}
+	return []sqlc.BotHistorySearchIndex{*f.index}, nil
+}
+
+func (f *fakeSearchQueries) UpsertHistorySearchIndex(_ context.Context, arg sqlc.UpsertHistorySearchIndexParams) (sqlc.BotHistorySearchIndex, error) {
+	if f.index == nil || f.index.EmbeddingModelID != arg.EmbeddingModelID {
+		f.index = &sqlc.BotHistorySearchIndex{BotID: arg.BotID, TeamID: uuid(200)}
+	}
+	f.index.EmbeddingModelID = arg.EmbeddingModelID
+	return *f.index, nil
+}
+
+func (f *fakeSearchQueries) DeleteHistorySearchIndex(context.Context, pgtype.UUID) (int64, error) {
+	f.index = nil
+	return 1, nil
+}
+
+func (f *fakeSearchQueries) ResetHistorySearchIndex(context.Context, pgtype.UUID) (int64, error) {
+	f.index.CursorCreatedAt, f.index.CursorMessageID, f.index.IndexedCount = pgtype.Timestamptz{}, pgtype.UUID{}, 0
+	return 1, nil
+}
+
+func (f *fakeSearchQueries) AdvanceHistorySearchIndex(_ context.Context, arg sqlc.AdvanceHistorySearchIndexParams) error {
+	f.index.CursorCreatedAt = arg.CursorCreatedAt
+	f.index.CursorMessageID = arg.CursorMessageID
+	f.index.IndexedCount += arg.Indexed
+	return nil
+}
+
+func (f *fakeSearchQueries) RecordHistorySearchIndexError(_ context.Context, arg sqlc.RecordHistorySearchIndexErrorParams) error {
+	f.errors = append(f.errors, arg.LastError)
+	return nil
+}
+
+func (f *fakeSearchQueries) ListHistoryMessagesForSearchIndex(_ context.Context, arg sqlc.ListHistoryMessagesForSearchIndexParams) ([]sqlc.ListHistoryMessagesForSearchIndexRow, error) {
+	var out []sqlc.ListHistoryMessagesForSearchIndexRow
+	for _, row := range f.messages {
+		if arg.AfterCreatedAt.Valid && !row.CreatedAt.Time.After(arg.AfterCreatedAt.Time) {
+			continue
+		}
+		out = append(out, row)
+		if len(out) == int(arg.RowLimit) {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSearchQueries) GetHistoryMessagesForSearch(_ context.Context, arg sqlc.GetHistoryMessagesForSearchParams) ([]sqlc.GetHistoryMessagesForSearchRow, error) {
+	var out []sqlc.GetHistoryMessagesForSearchRow
+	for _, row := range f.messages {
+		if f.hidden[row.ID] {
+			continue
+		}
+		for _, id := range arg.Ids {
+			if id == row.ID {
+				out = append(out, sqlc.GetHistoryMessagesForSearchRow{
+					ID:        row.ID,
+					BotID:     arg.BotID,
+					SessionID: row.SessionID,
+					Role:      row.Role,
+					Content:   row.Content,
+					CreatedAt: row.CreatedAt,
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSearchQueries) SearchMessages(_ context.Context, arg sqlc.SearchMessagesParams) ([]sqlc.SearchMessagesRow, error) {
+	if !arg.Keyword.Valid {
+		return nil, errors.New("keyword search without keyword")
+	}
+	return f.keyword, nil
+}
+
+type fakeVectorIndex struct {
+	vectors map[pgtype.UUID][]float32
+	deleted int
+}
+
+func (f *fakeVectorIndex) Upsert(_ context.Context, _, _, _ pgtype.UUID, messages []embeddedMessage) error {
+	if f.vectors == nil {
+		f.vectors = map[pgtype.UUID][]float32{}
+	}
+	for _, msg := range messages {
+		f.vectors[msg.MessageID] = msg.Vector
+	}
+	return nil
+}
+
+func (f *fakeVectorIndex) Search(_ context.Context, _, _, _, _ pgtype.UUID, vector []float32, limit int) ([]messageHit, error) {
+	hits := make([]messageHit, 0, len(f.vectors))
+	for id, stored := range f.vectors {
+		hits = append(hits, messageHit{MessageID: id, Score: cosine(vector, stored)})
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func (f *fakeVectorIndex) DeleteBot(context.Context, pgtype.UUID, pgtype.UUID) error {
+	f.vectors = nil
+	f.deleted++
+	return nil
+}
+
+// wordEmbed embeds text as counts over a tiny vocabulary.
+func wordEmbed(_ context.Context, _ dbstore.Queries, _ pgtype.UUID, text string) ([]float32, error) {
+	vocabulary := []string{"contract", "renewal", "lunch", "weather"}
+	vec := make([]float32, len(vocabulary)+1)
+	vec[len(vocabulary)] = 0.1
+	for i, word := range vocabulary {
+		vec[i] = float32(strings.Count(strings.ToLower(text), word))
+	}
+	return vec, nil
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func uuid(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func message(id byte, minute int, role, content string) sqlc.ListHistoryMessagesForSearchIndexRow {
+	return sqlc.ListHistoryMessagesForSearchIndexRow{
+		ID:        uuid(id),
+		SessionID: uuid(50),
+		Role:      role,
+		Content:   []byte(content),
+		CreatedAt: pgtype.Timestamptz{Time: time.Date(2026, 9, 1, 12, minute, 0, 0, time.UTC), Valid: true},
+	}
+}
+
+func newTestService(queries *fakeSearchQueries, index *fakeVectorIndex) *Service {
+	svc := NewService(nil, queries, nil)
+	svc.index = index
+	svc.embed = wordEmbed
+	return svc
+}
+
+func indexedQueries() *fakeSearchQueries {
+	return &fakeSearchQueries{
+		index: &sqlc.BotHistorySearchIndex{BotID: uuid(9), TeamID: uuid(200), EmbeddingModelID: uuid(100)},
+		messages: []sqlc.ListHistoryMessagesForSearchIndexRow{
+			message(1, 0, "user", `{"role":"user","content":"Where should we go for lunch?"}`),
+			message(2, 1, "assistant", `{"role":"assistant","content":[{"type":"text","text":"The contract renewal is due in March."}]}`),
+			message(3, 2, "assistant", `{"role":"assistant","content":[{"type":"tool-call","toolName":"read"}]}`),
+			message(4, 3, "user", `{"role":"user","content":"Nice weather today"}`),
+		},
+	}
+}
+
+func TestSweepEmbedsMessagesAndAdvancesCursor(t *testing.T) {
+	t.Parallel()
+
+	queries := indexedQueries()
+	index := &fakeVectorIndex{}
+	svc := newTestService(queries, index)
+
+	svc.sweep(context.Background())
+	if len(index.vectors) != 3 {
+		t.Fatalf("embedded %d messages, want 3 with text", len(index.vectors))
+	}
+	if queries.index.IndexedCount != 3 || queries.index.CursorMessageID != uuid(4) {
+		t.Fatalf("index = %+v, want cursor at the last message", queries.index)
+	}
+
+	queries.messages = append(queries.messages, message(5, 4, "user", `{"role":"user","content":"lunch again"}`))
+	svc.sweep(context.Background())
+	if len(index.vectors) != 4 || queries.index.IndexedCount != 4 {
+		t.Fatalf("second sweep embedded %d (count %d), want only the new message", len(index.vectors), queries.index.IndexedCount)
+	}
+}
+
+func TestSweepKeepsCursorWhenEmbeddingFails(t *testing.T) {
+	t.Parallel()
+
+	queries := indexedQueries()
+	svc := newTestService(queries, &fakeVectorIndex{})
+	svc.embed = func(context.Context, dbstore.Queries, pgtype.UUID, string) ([]float32, error) {
+		return nil, errors.New("provider down")
+	}
+
+	svc.sweep(context.Background())
+	if queries.index.CursorMessageID.Valid {
+		t.Fatal("cursor advanced past messages that were not embedded")
+	}
+	if len(queries.errors) != 1 || !strings.Contains(queries.errors[0], "provider down") {
+		t.Fatalf("recorded errors = %v", queries.errors)
+	}
+}
+
+func TestSemanticSearchRanksBySimilarity(t *testing.T) {
+	t.Parallel()
+
+	queries := indexedQueries()
+	svc := newTestService(queries, &fakeVectorIndex{})
+	svc.sweep(context.Background())
+	queries.hidden = map[pgtype.UUID]bool{uuid(1): true}
+
+	resp, err := svc.Search(context.Background(), uuid(9).String(), SearchRequest{Query: "when we talked about the contract renewal", Limit: 2})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if resp.Mode != ModeSemantic {
+		t.Fatalf("mode = %q, want semantic", resp.Mode)
+	}
+	if len(resp.Items) == 0 || resp.Items[0].MessageID != uuid(2).String() {
+		t.Fatalf("items = %+v, want the contract message first", resp.Items)
+	}
+	if resp.Items[0].Text != "The contract renewal is due in March." {
+		t.Fatalf("text = %q", resp.Items[0].Text)
+	}
+	for _, item := range resp.Items {
+		if item.MessageID == uuid(1).String() {
+			t.Fatal("hidden message returned")
+		}
+	}
+}
+
+func TestSearchFallsBackToKeywordWithoutIndex(t *testing.T) {
+	t.Parallel()
+
+	queries := &fakeSearchQueries{keyword: []sqlc.SearchMessagesRow{{
+		ID:      uuid(1),
+		BotID:   uuid(9),
+		Role:    "user",
+		Content: []byte(`{"role":"user","content":"contract"}`),
+	}}}
+	svc := newTestService(queries, &fakeVectorIndex{})
+
+	resp, err := svc.Search(context.Background(), uuid(9).String(), SearchRequest{Query: "contract"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if resp.Mode != ModeKeyword || len(resp.Items) != 1 || resp.Items[0].Text != "contract" {
+		t.Fatalf("resp = %+v, want one keyword hit", resp)
+	}
+	if _, err := svc.Search(context.Background(), uuid(9).String(), SearchRequest{Query: "contract", Mode: ModeSemantic}); !errors.Is(err, ErrIndexNotEnabled) {
+		t.Fatalf("semantic search error = %v, want ErrIndexNotEnabled", err)
+	}
+	if _, err := svc.Search(context.Background(), uuid(9).String(), SearchRequest{Query: "  "}); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("empty query error = %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestRebuildDropsEmbeddings(t *testing.T) {
+	t.Parallel()
+
+	queries := indexedQueries()
+	index := &fakeVectorIndex{}
+	svc := newTestService(queries, index)
+	svc.sweep(context.Background())
+
+	got, err := svc.RebuildIndex(context.Background(), uuid(9).String())
+	if err != nil {
+		t.Fatalf("RebuildIndex: %v", err)
+	}
+	if got.IndexedCount != 0 || index.deleted != 1 || len(index.vectors) != 0 {
+		t.Fatalf("rebuild left index %+v with %d vectors", got, len(index.vectors))
+	}
+	svc.sweep(context.Background())
+	if len(index.vectors) != 3 {
+		t.Fatalf("re-embedded %d messages, want 3", len(index.vectors))
+	}
+}
@@ -0,0 +1,56 @@
+package historysearch
+
+import "time"
+
+// Search modes. ModeAuto uses the semantic index when the bot has one and
+// falls back to keyword matching otherwise.
+const (
+	ModeAuto     = "auto"
+	ModeSemantic = "semantic"
+	ModeKeyword  = "keyword"
+)
+
+// Index is the semantic search index of one bot's history. IndexedCount
+// counts messages embedded since the index was enabled or last rebuilt.
+type Index struct {
+	BotID            string     `json:"bot_id"`
+	EmbeddingModelID string     `json:"embedding_model_id"`
+	IndexedCount     int64      `json:"indexed_count"`
+	LastError        string     `json:"last_error,omitempty"`
+	LastIndexedAt    *time.Time `json:"last_indexed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// EnableRequest turns on semantic search for a bot with an embedding model.
+// Switching to another model drops the existing embeddings.
+type EnableRequest struct {
+	EmbeddingModelID string `json:"embedding_model_id"`
+}
+
+// SearchRequest searches a bot's whole history, or one session of it.
+type SearchRequest struct {
+	Query     string
+	Mode      string
+	SessionID string
+	Limit     int
+}
+
+// Hit is a message matching a search. Score is the cosine similarity for
+// semantic matches and zero for keyword matches.
+type Hit struct {
+	MessageID         string    `json:"message_id"`
+	SessionID         string    `json:"session_id,omitempty"`
+	Role              string    `json:"role"`
+	Text              string    `json:"text"`
+	SenderDisplayName string    `json:"sender_display_name,omitempty"`
+	Platform          string    `json:"platform,omitempty"`
+	Score             float64   `json:"score"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// SearchResponse lists hits, best first, and the mode that produced them.
+type SearchResponse struct {
+	Mode  string `json:"mode"`
+	Items []Hit  `json:"items"`
+}
@@ -0,0 +1,135 @@
+package historysearch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+const (
+	sweepInterval = time.Minute
+	batchSize     = 64
+	// maxBatchesPerSweep keeps one bot with a long backlog from holding up
+	// the others; the rest is embedded on later sweeps.
+	maxBatchesPerSweep = 8
+)
+
+// Run embeds new history messages of every indexed bot each minute until
+// done is closed.
+func (s *Service) Run(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	s.sweep(ctx)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *Service) sweep(ctx context.Context) {
+	store, err := s.store()
+	if err != nil || s.index == nil {
+		return
+	}
+	indexes, err := store.ListHistorySearchIndexes(ctx)
+	if err != nil {
+		s.logger.Error("history search sweep failed", slog.Any("error", err))
+		return
+	}
+	for _, index := range indexes {
+		if ctx.Err() != nil {
+			return
+		}
+		indexed, err := s.indexBot(ctx, store, index)
+		if err != nil {
+			s.logger.Warn("history search indexing failed",
+				slog.String("bot_id", index.BotID.String()), slog.Any("error", err))
+			if recErr := store.RecordHistorySearchIndexError(ctx, sqlc.RecordHistorySearchIndexErrorParams{
+				LastError: textutil.TruncateRunesWithSuffix(err.Error(), 500, "…"),
+				BotID:     index.BotID,
+			}); recErr != nil {
+				s.logger.Warn("record history search error failed", slog.Any("error", recErr))
+			}
+			continue
+		}
+		if indexed > 0 {
+			s.logger.Debug("history search indexed messages",
+				slog.String("bot_id", index.BotID.String()), slog.Int("count", indexed))
+		}
+	}
+}
+
+// indexBot embeds up to maxBatchesPerSweep batches of messages after the
+// index cursor. The cursor only moves once a batch is stored, so a failed
+// embedding call is retried on the next sweep.
+func (s *Service) indexBot(ctx context.Context, store searchQueries, index sqlc.BotHistorySearchIndex) (int, error) {
+	total := 0
+	cursorAt, cursorID := index.CursorCreatedAt, index.CursorMessageID
+	for range maxBatchesPerSweep {
+		rows, err := store.ListHistoryMessagesForSearchIndex(ctx, sqlc.ListHistoryMessagesForSearchIndexParams{
+			BotID:          index.BotID,
+			AfterCreatedAt: cursorAt,
+			AfterID:        cursorID,
+			RowLimit:       batchSize,
+		})
+		if err != nil {
+			return total, fmt.Errorf("list messages: %w", err)
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+		batch := make([]embeddedMessage, 0, len(rows))
+		for _, row := range rows {
+			text := s.messageText(ctx, index.BotID, row.Content)
+			if text == "" {
+				continue
+			}
+			vector, err := s.embed(ctx, s.queries, index.EmbeddingModelID, textutil.TruncateRunes(text, maxEmbedRunes))
+			if err != nil {
+				return total, fmt.Errorf("embed message %s: %w", row.ID.String(), err)
+			}
+			batch = append(batch, embeddedMessage{
+				MessageID: row.ID,
+				SessionID: row.SessionID,
+				CreatedAt: row.CreatedAt,
+				Vector:    vector,
+			})
+		}
+		if len(batch) > 0 {
+			if err := s.index.Upsert(ctx, index.TeamID, index.BotID, index.EmbeddingModelID, batch); err != nil {
+				return total, err
+			}
+		}
+		last := rows[len(rows)-1]
+		cursorAt, cursorID = last.CreatedAt, last.ID
+		if err := store.AdvanceHistorySearchIndex(ctx, sqlc.AdvanceHistorySearchIndexParams{
+			CursorCreatedAt:  cursorAt,
+			CursorMessageID:  cursorID,
+			Indexed:          int64(len(batch)),
+			BotID:            index.BotID,
+			EmbeddingModelID: index.EmbeddingModelID,
+		}); err != nil {
+			return total, fmt.Errorf("advance cursor: %w", err)
+		}
+		total += len(batch)
+		if len(rows) < batchSize {
+			return total, nil
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,344 @@
+package message
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbpkg "github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+// ErrInvalidContent reports message content that is not a ModelMessage
+// document: valid JSON whose content is a string or a list of typed parts.
+var ErrInvalidContent = errors.New("invalid message content")
+
+// MetadataContentQuarantineID is set on messages whose content failed
+// validation. It holds the id of the quarantined original payload.
+const MetadataContentQuarantineID = "content_quarantine_id"
+
+const (
+	quarantineSourcePersist = "persist"
+	quarantineSourceRepair  = "repair"
+
+	defaultRepairBatchSize = 500
+)
+
+type contentQuarantineQueries interface {
+	InsertMessageQuarantine(ctx context.Context, arg sqlc.InsertMessageQuarantineParams) (sqlc.BotHistoryMessageQuarantine, error)
+}
+
+type contentRepairQueries interface {
+	contentQuarantineQueries
+	ListMessageContentsForRepair(ctx context.Context, arg sqlc.ListMessageContentsForRepairParams) ([]sqlc.ListMessageContentsForRepairRow, error)
+	UpdateMessageContent(ctx context.Context, arg sqlc.UpdateMessageContentParams) error
+}
+
+// envelopeKeys are the ModelMessage fields; an object with none of them but
+// a "type" is a bare content part.
+var envelopeKeys = []string{"role", "content", "tool_calls", "tool_call_id", "name"}
+
+// NormalizeContent validates a ModelMessage document and rewrites the legacy
+// shapes history loading tolerates into one: empty content becomes {}, and a
+// bare string, part list or single part is wrapped as the message content.
+// Valid documents are returned unchanged.
+func NormalizeContent(role string, raw json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return json.RawMessage("{}"), nil
+	}
+	if !json.Valid(trimmed) {
+		return nil, fmt.Errorf("%w: not valid JSON", ErrInvalidContent)
+	}
+	switch trimmed[0] {
+	case '"':
+		return wrapContent(role, trimmed)
+	case '[':
+		if err := validateParts(trimmed); err != nil {
+			return nil, err
+		}
+		return wrapContent(role, trimmed)
+	case '{':
+	default:
+		return nil, fmt.Errorf("%w: document must be an object", ErrInvalidContent)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidContent, err.Error())
+	}
+	if isBarePart(fields) {
+		if err := validatePart(fields); err != nil {
+			return nil, err
+		}
+		return wrapContent(role, json.RawMessage("["+string(trimmed)+"]"))
+	}
+	for _, key := range []string{"role", "tool_call_id", "name"} {
+		if value, ok := fields[key]; ok && !isJSONString(value) && !isJSONNull(value) {
+			return nil, fmt.Errorf("%w: %s must be a string", ErrInvalidContent, key)
+		}
+	}
+	if content, ok := fields["content"]; ok && !isJSONNull(content) && !isJSONString(content) {
+		if err := validateParts(content); err != nil {
+			return nil, err
+		}
+	}
+	if calls, ok := fields["tool_calls"]; ok && !isJSONNull(calls) {
+		if err := validateToolCalls(calls); err != nil {
+			return nil, err
+		}
+	}
+	return raw, nil
+}
+
+func wrapContent(role string, content json.RawMessage) (json.RawMessage, error) {
+	doc, err := json.Marshal(struct {
+		Role    string          `json:"role,omitempty"`
+		Content json.RawMessage `json:"content"`
+	}{Role: strings.TrimSpace(role), Content: content})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidContent, err.Error())
+	}
+	return doc, nil
+}
+
+func isBarePart(fields map[string]json.RawMessage) bool {
+	if _, ok := fields["type"]; !ok {
+		return false
+	}
+	for _, key := range envelopeKeys {
+		if _, ok := fields[key]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func validateParts(raw json.RawMessage) error {
+	var parts []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return fmt.Errorf("%w: content must be a string or a list of parts", ErrInvalidContent)
+	}
+	for _, part := range parts {
+		if err := validatePart(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePart(part map[string]json.RawMessage) error {
+	var partType string
+	if err := json.Unmarshal(part["type"], &partType); err != nil || strings.TrimSpace(partType) == "" {
+		return fmt.Errorf("%w: content part needs a type", ErrInvalidContent)
+	}
+	if text, ok := part["text"]; ok && !isJSONString(text) {
+		return fmt.Errorf("%w: %s part text must be a string", ErrInvalidContent, partType)
+	}
+	return nil
+}
+
+func validateToolCalls(raw json.RawMessage) error {
+	var calls []struct {
+		Function *struct {
+			Name      json.RawMessage `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(raw, &calls); err != nil {
+		return fmt.Errorf("%w: tool_calls must be a list of objects", ErrInvalidContent)
+	}
+	for _, call := range calls {
+		if call.Function == nil || !isJSONString(call.Function.Name) {
+			return fmt.Errorf("%w: tool call needs a function name", ErrInvalidContent)
+		}
+		if len(call.Function.Arguments) > 0 && !isJSONString(call.Function.Arguments) && !isJSONNull(call.Function.Arguments) {
+			return fmt.Errorf("%w: tool call arguments must be a string", ErrInvalidContent)
+		}
+	}
+	return nil
+}
+
+func isJSONString(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && raw[0] == '"'
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// placeholderContent stands in for content that failed validation.
+func placeholderContent(role string) json.RawMessage {
+	doc, _ := wrapContent(role, json.RawMessage(`""`))
+	return doc
+}
+
+// quarantineContent stores an invalid payload, sealed like message content,
+// and returns the quarantine row id.
+func (s *DBService) quarantineContent(ctx context.Context, botID, messageID pgtype.UUID, role string, payload []byte, reason, source string) (string, error) {
+	store, ok := s.queries.(contentQuarantineQueries)
+	if !ok {
+		return "", errors.New("message quarantine not supported by store")
+	}
+	text := string(payload)
+	if s.cipher != nil {
+		sealed, err := s.cipher.SealText(ctx, botID, text)
+		if err != nil {
+			return "", fmt.Errorf("seal quarantined content: %w", err)
+		}
+		text = sealed
+	}
+	row, err := store.InsertMessageQuarantine(ctx, sqlc.InsertMessageQuarantineParams{
+		BotID:     botID,
+		MessageID: messageID,
+		Role:      role,
+		Payload:   text,
+		Reason:    reason,
+		Source:    source,
+	})
+	if err != nil {
+		return "", fmt.Errorf("quarantine message content: %w", err)
+	}
+	return row.ID.String(), nil
+}
+
+// validatePersistContent normalizes input content. Invalid content is
+// quarantined and replaced by a placeholder so the message still keeps its
+// place in history; the quarantine id is returned for the message metadata.
+func (s *DBService) validatePersistContent(ctx context.Context, botID pgtype.UUID, input PersistInput) (json.RawMessage, string) {
+	content, err := NormalizeContent(input.Role, input.Content)
+	if err == nil {
+		return content, ""
+	}
+	s.logger.Warn("invalid message content, quarantining",
+		slog.String("bot_id", input.BotID),
+		slog.String("role", input.Role),
+		slog.Any("error", err),
+	)
+	quarantineID, qErr := s.quarantineContent(ctx, botID, pgtype.UUID{}, input.Role, input.Content, err.Error(), quarantineSourcePersist)
+	if qErr != nil {
+		s.logger.Warn("quarantine message content failed", slog.String("bot_id", input.BotID), slog.Any("error", qErr))
+	}
+	return placeholderContent(input.Role), quarantineID
+}
+
+// withMetadataValue returns a copy of metadata with key set, leaving the
+// caller's map untouched.
+func withMetadataValue(metadata map[string]any, key string, value any) map[string]any {
+	out := make(map[string]any, len(metadata)+1)
+	for k, v := range metadata {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// RepairOptions scopes a content repair pass.
+type RepairOptions struct {
+	// BotID limits the pass to one bot; empty scans every bot.
+	BotID string
+	// DryRun counts what would change without writing.
+	DryRun    bool
+	BatchSize int
+}
+
+// RepairResult counts the outcome of a content repair pass.
+type RepairResult struct {
+	Scanned     int `json:"scanned"`
+	Normalized  int `json:"normalized"`
+	Quarantined int `json:"quarantined"`
+	// Unreadable counts sealed rows that could not be opened; they are left
+	// untouched.
+	Unreadable int `json:"unreadable"`
+}
+
+// RepairContent scans stored history, rewrites legacy content shapes and
+// quarantines content that fails validation, replacing it with a
+// placeholder.
+func (s *DBService) RepairContent(ctx context.Context, opts RepairOptions) (RepairResult, error) {
+	store, ok := s.queries.(contentRepairQueries)
+	if !ok {
+		return RepairResult{}, errors.New("message repair not supported by store")
+	}
+	var botID pgtype.UUID
+	if strings.TrimSpace(opts.BotID) != "" {
+		parsed, err := dbpkg.ParseUUID(opts.BotID)
+		if err != nil {
+			return RepairResult{}, fmt.Errorf("invalid bot id: %w", err)
+		}
+		botID = parsed
+	}
+	batch := opts.BatchSize
+	if batch <= 0 {
+		batch = defaultRepairBatchSize
+	}
+
+	var result RepairResult
+	var after pgtype.UUID
+	for {
+		rows, err := store.ListMessageContentsForRepair(ctx, sqlc.ListMessageContentsForRepairParams{
+			BotID:    botID,
+			AfterID:  after,
+			RowLimit: int32(batch), //nolint:gosec // batch is a small positive page size
+		})
+		if err != nil {
+			return result, fmt.Errorf("list messages: %w", err)
+		}
+		for _, row := range rows {
+			result.Scanned++
+			if err := s.repairRow(ctx, store, row, opts.DryRun, &result); err != nil {
+				return result, err
+			}
+		}
+		if len(rows) < batch {
+			return result, nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
+
+func (s *DBService) repairRow(ctx context.Context, store contentRepairQueries, row sqlc.ListMessageContentsForRepairRow, dryRun bool, result *RepairResult) error {
+	content := row.Content
+	if s.cipher != nil {
+		opened, err := s.cipher.OpenJSON(ctx, row.BotID, row.Content)
+		if err != nil {
+			result.Unreadable++
+			return nil
+		}
+		content = opened
+	}
+	normalized, err := NormalizeContent(row.Role, content)
+	if err == nil && bytes.Equal(normalized, content) {
+		return nil
+	}
+	if err == nil {
+		result.Normalized++
+	} else {
+		result.Quarantined++
+		normalized = placeholderContent(row.Role)
+	}
+	if dryRun {
+		return nil
+	}
+	if err != nil {
+		if _, qErr := s.quarantineContent(ctx, row.BotID, row.ID, row.Role, content, err.Error(), quarantineSourceRepair); qErr != nil {
+			return qErr
+		}
+	}
+	if s.cipher != nil {
+		if normalized, err = s.cipher.SealJSON(ctx, row.BotID, normalized); err != nil {
+			return fmt.Errorf("seal message content: %w", err)
+		}
+	}
+	if err := store.UpdateMessageContent(ctx, sqlc.UpdateMessageContentParams{Content: normalized, ID: row.ID}); err != nil {
+		return fmt.Errorf("update message %s: %w", row.ID.String(), err)
+	}
+	return nil
+}
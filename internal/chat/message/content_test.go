@@ -0,0 +1,156 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+func TestNormalizeContent(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		want    string
+		invalid bool
+	}{
+		{name: "empty", raw: "", want: `{}`},
+		{name: "null", raw: "null", want: `{}`},
+		{name: "envelope kept", raw: `{"role":"user","content":"hi"}`, want: `{"role":"user","content":"hi"}`},
+		{name: "parts envelope kept", raw: `{"role":"assistant","content":[{"type":"text","text":"ok"}],"tool_calls":[{"id":"c1","type":"function","function":{"name":"read","arguments":"{}"}}]}`, want: `{"role":"assistant","content":[{"type":"text","text":"ok"}],"tool_calls":[{"id":"c1","type":"function","function":{"name":"read","arguments":"{}"}}]}`},
+		{name: "bare string", raw: `"hello"`, want: `{"role":"user","content":"hello"}`},
+		{name: "bare parts", raw: `[{"type":"text","text":"hello"}]`, want: `{"role":"user","content":[{"type":"text","text":"hello"}]}`},
+		{name: "bare part", raw: `{"type":"text","text":"hello"}`, want: `{"role":"user","content":[{"type":"text","text":"hello"}]}`},
+		{name: "not json", raw: `{"role":"user"`, invalid: true},
+		{name: "scalar", raw: `42`, invalid: true},
+		{name: "content object", raw: `{"role":"user","content":{"text":"hi"}}`, invalid: true},
+		{name: "untyped part", raw: `{"content":[{"text":"hi"}]}`, invalid: true},
+		{name: "non-string text", raw: `{"content":[{"type":"text","text":7}]}`, invalid: true},
+		{name: "tool call without name", raw: `{"tool_calls":[{"id":"c1","function":{}}]}`, invalid: true},
+		{name: "non-string role", raw: `{"role":1,"content":"hi"}`, invalid: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizeContent("user", json.RawMessage(tc.raw))
+			if tc.invalid {
+				if !errors.Is(err, ErrInvalidContent) {
+					t.Fatalf("NormalizeContent(%s) error = %v, want ErrInvalidContent", tc.raw, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeContent(%s) error = %v", tc.raw, err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("NormalizeContent(%s) = %s, want %s", tc.raw, got, tc.want)
+			}
+		})
+	}
+}
+
+type quarantineQueries struct {
+	runtimeSnapshotQueries
+
+	quarantined []sqlc.InsertMessageQuarantineParams
+	rows        []sqlc.ListMessageContentsForRepairRow
+	updated     map[string][]byte
+}
+
+func (q *quarantineQueries) InsertMessageQuarantine(_ context.Context, arg sqlc.InsertMessageQuarantineParams) (sqlc.BotHistoryMessageQuarantine, error) {
+	q.quarantined = append(q.quarantined, arg)
+	return sqlc.BotHistoryMessageQuarantine{ID: testMessageUUID("44444444-4444-4444-4444-444444444444")}, nil
+}
+
+func (q *quarantineQueries) ListMessageContentsForRepair(_ context.Context, arg sqlc.ListMessageContentsForRepairParams) ([]sqlc.ListMessageContentsForRepairRow, error) {
+	var out []sqlc.ListMessageContentsForRepairRow
+	for _, row := range q.rows {
+		if arg.AfterID.Valid && row.ID.String() <= arg.AfterID.String() {
+			continue
+		}
+		out = append(out, row)
+		if len(out) == int(arg.RowLimit) {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (q *quarantineQueries) UpdateMessageContent(_ context.Context, arg sqlc.UpdateMessageContentParams) error {
+	if q.updated == nil {
+		q.updated = map[string][]byte{}
+	}
+	q.updated[arg.ID.String()] = arg.Content
+	return nil
+}
+
+func TestPersistQuarantinesInvalidContent(t *testing.T) {
+	queries := &quarantineQueries{}
+	svc := NewService(nil, queries)
+
+	metadata := map[string]any{"platform": "telegram"}
+	msg, err := svc.Persist(context.Background(), PersistInput{
+		BotID:     "11111111-1111-1111-1111-111111111111",
+		SessionID: "22222222-2222-2222-2222-222222222222",
+		Role:      "assistant",
+		Content:   []byte(`{"role":"assistant","content":{"broken":true}}`),
+		Metadata:  metadata,
+	})
+	if err != nil {
+		t.Fatalf("Persist() error = %v", err)
+	}
+	if len(queries.quarantined) != 1 || queries.quarantined[0].Source != quarantineSourcePersist {
+		t.Fatalf("quarantined = %+v", queries.quarantined)
+	}
+	if queries.quarantined[0].Payload != `{"role":"assistant","content":{"broken":true}}` {
+		t.Fatalf("quarantined payload = %s", queries.quarantined[0].Payload)
+	}
+	if string(queries.created.Content) != `{"role":"assistant","content":""}` {
+		t.Fatalf("stored content = %s, want placeholder", queries.created.Content)
+	}
+	if msg.Metadata[MetadataContentQuarantineID] != "44444444-4444-4444-4444-444444444444" {
+		t.Fatalf("metadata = %+v, want quarantine id", msg.Metadata)
+	}
+	if _, ok := metadata[MetadataContentQuarantineID]; ok {
+		t.Fatal("caller metadata was modified")
+	}
+}
+
+func TestRepairContent(t *testing.T) {
+	botID := testMessageUUID("11111111-1111-1111-1111-111111111111")
+	queries := &quarantineQueries{rows: []sqlc.ListMessageContentsForRepairRow{
+		{ID: testMessageUUID("00000000-0000-0000-0000-000000000001"), BotID: botID, Role: "user", Content: []byte(`{"role": "user", "content": "ok"}`)},
+		{ID: testMessageUUID("00000000-0000-0000-0000-000000000002"), BotID: botID, Role: "user", Content: []byte(`[{"type": "text", "text": "legacy"}]`)},
+		{ID: testMessageUUID("00000000-0000-0000-0000-000000000003"), BotID: botID, Role: "tool", Content: []byte(`{"content": 5}`)},
+	}}
+	svc := NewService(nil, queries)
+
+	dry, err := svc.RepairContent(context.Background(), RepairOptions{DryRun: true, BatchSize: 2})
+	if err != nil {
+		t.Fatalf("RepairContent(dry run) error = %v", err)
+	}
+	if dry != (RepairResult{Scanned: 3, Normalized: 1, Quarantined: 1}) {
+		t.Fatalf("dry run result = %+v", dry)
+	}
+	if len(queries.updated) != 0 || len(queries.quarantined) != 0 {
+		t.Fatalf("dry run wrote: updated=%v quarantined=%v", queries.updated, queries.quarantined)
+	}
+
+	result, err := svc.RepairContent(context.Background(), RepairOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("RepairContent() error = %v", err)
+	}
+	if result != dry {
+		t.Fatalf("result = %+v, want %+v", result, dry)
+	}
+	if got := string(queries.updated["00000000-0000-0000-0000-000000000002"]); got != `{"role":"user","content":[{"type":"text","text":"legacy"}]}` {
+		t.Fatalf("normalized content = %s", got)
+	}
+	if got := string(queries.updated["00000000-0000-0000-0000-000000000003"]); got != `{"role":"tool","content":""}` {
+		t.Fatalf("quarantined content = %s", got)
+	}
+	if len(queries.quarantined) != 1 || queries.quarantined[0].MessageID != testMessageUUID("00000000-0000-0000-0000-000000000003") || queries.quarantined[0].Source != quarantineSourceRepair {
+		t.Fatalf("quarantined = %+v", queries.quarantined)
+	}
+}
@@ -27,6 +27,17 @@ type DBService struct {
 	queries   dbstore.Queries
 	logger    *slog.Logger
 	publisher event.Publisher
+	cipher    ContentCipher
+}
+
+// ContentCipher seals message content before it is written and opens it on
+// read. *encryption.Keyring implements it; values that were never sealed
+// must open unchanged.
+type ContentCipher interface {
+	SealJSON(ctx context.Context, botID pgtype.UUID, doc []byte) ([]byte, error)
+	OpenJSON(ctx context.Context, botID pgtype.UUID, stored []byte) ([]byte, error)
+	SealText(ctx context.Context, botID pgtype.UUID, text string) (string, error)
+	OpenText(ctx context.Context, botID pgtype.UUID, stored string) (string, error)
 }
 
 type historyTurnWriter interface {
@@ -58,6 +69,24 @@ type toolTailRoundWriter interface {
 	SupportsAtomicDirectHistoryTurnWrites() bool
 }
 
+type historyRoundKeyWriter interface {
+	ClaimHistoryRoundKey(ctx context.Context, arg sqlc.ClaimHistoryRoundKeyParams) (int64, error)
+	GetHistoryRoundKeyMessages(ctx context.Context, arg sqlc.GetHistoryRoundKeyMessagesParams) ([]pgtype.UUID, error)
+	SetHistoryRoundKeyMessages(ctx context.Context, arg sqlc.SetHistoryRoundKeyMessagesParams) error
+}
+
+// historyRoundMemoryWriter tracks whether a round keyed in
+// bot_history_round_keys still owes memory extraction, and who is running it.
+type historyRoundMemoryWriter interface {
+	SetHistoryRoundKeyMemoryPending(ctx context.Context, arg sqlc.SetHistoryRoundKeyMemoryPendingParams) error
+	ClaimHistoryRoundKeyMemory(ctx context.Context, arg sqlc.ClaimHistoryRoundKeyMemoryParams) (int64, error)
+}
+
+// roundMemoryLease bounds how long a claim on a round's memory extraction
+// keeps replays away. A claim that outlives it belongs to an attempt that
+// died before completing, and the next replay takes the work over.
+const roundMemoryLease = 15 * time.Minute
+
 type messageCleanupQueries interface {
 	DeleteMessagesByIDs(ctx context.Context, ids []pgtype.UUID) error
 }
@@ -86,6 +115,12 @@ func NewService(log *slog.Logger, queries dbstore.Queries, publishers ...event.P
 	}
 }
 
+// SetContentCipher enables at-rest encryption of message content and display
+// text. Rows written before it was set keep reading as plaintext.
+func (s *DBService) SetContentCipher(cipher ContentCipher) {
+	s.cipher = cipher
+}
+
 // Persist writes a single message to bot_history_messages.
 func (s *DBService) Persist(ctx context.Context, input PersistInput) (Message, error) {
 	const maxTurnSequenceRetries = 3
@@ -258,6 +293,7 @@ func (s *DBService) PersistToolTailRound(ctx context.Context, inputs []PersistIn
 	messages := make([]Message, len(rows))
 	for i, row := range rows {
 		messages[i] = toMessageFromToolTailRound(row, prepared[i].createArg, prepared[i].metadata)
+		s.openMessage(ctx, &messages[i])
 		s.publishMessageCreated(messages[i])
 	}
 	return messages, true, nil
@@ -266,13 +302,16 @@ func (s *DBService) PersistToolTailRound(ctx context.Context, inputs []PersistIn
 // PersistRound writes all messages and history links under one PostgreSQL
 // transaction. Distributed callers additionally validate their runtime fence
 // in that transaction; local replacements use the same atomic write without a
-// distributed ownership token.
+// distributed ownership token. An idempotency key claims the round inside the
+// same transaction, so a retried write either commits everything once or
+// returns the originally persisted messages with ErrRoundAlreadyPersisted.
 func (s *DBService) PersistRound(ctx context.Context, inputs []PersistInput, options RoundPersistenceOptions) ([]Message, bool, error) {
 	if s == nil || s.queries == nil || len(inputs) == 0 {
 		return nil, false, nil
 	}
 	_, fenced := runtimefence.FromContext(ctx)
-	if !fenced && options.Replacement == nil {
+	roundKey := strings.TrimSpace(options.IdempotencyKey)
+	if !fenced && options.Replacement == nil && roundKey == "" && !options.Atomic {
 		return nil, false, nil
 	}
 	botID := strings.TrimSpace(inputs[0].BotID)
@@ -285,15 +324,43 @@ func (s *DBService) PersistRound(ctx context.Context, inputs []PersistInput, opt
 			return nil, true, errors.New("atomic round spans multiple sessions")
 		}
 	}
+	pgSessionID, err := dbpkg.ParseUUID(sessionID)
+	if err != nil {
+		return nil, true, err
+	}
 
 	const maxTurnSequenceRetries = 3
 	var lastErr error
 	for attempt := 0; attempt < maxTurnSequenceRetries; attempt++ {
 		persisted := make([]Message, 0, len(inputs))
+		replayed := false
+		memoryPending := false
 		persist := func(queries dbstore.Queries) error {
 			txService := *s
 			txService.queries = queries
 			txService.publisher = nil
+			if roundKey != "" {
+				claimed, previous, err := txService.claimRoundKey(ctx, pgSessionID, roundKey)
+				if err != nil {
+					return err
+				}
+				if !claimed {
+					replayed = true
+					persisted = previous
+					memoryPending, err = txService.claimRoundMemory(ctx, pgSessionID, roundKey)
+					return err
+				}
+			}
+			if !fenced && options.Replacement == nil {
+				batch, handled, err := txService.PersistToolTailRound(ctx, inputs)
+				if err != nil {
+					return err
+				}
+				if handled {
+					persisted = append(persisted, batch...)
+					return txService.recordRoundKey(ctx, pgSessionID, roundKey, persisted, options.MemoryPending)
+				}
+			}
 			turnRequestMessageID := strings.TrimSpace(inputs[0].TurnRequestMessageID)
 			for _, original := range inputs {
 				input := original
@@ -314,7 +381,7 @@ func (s *DBService) PersistRound(ctx context.Context, inputs []PersistInput, opt
 					return err
 				}
 			}
-			return nil
+			return txService.recordRoundKey(ctx, pgSessionID, roundKey, persisted, options.MemoryPending)
 		}
 		var err error
 		if fenced {
@@ -327,8 +394,14 @@ func (s *DBService) PersistRound(ctx context.Context, inputs []PersistInput, opt
 			err = txer.InTx(ctx, persist)
 		}
 		if err == nil {
+			if replayed {
+				if memoryPending {
+					return persisted, true, ErrRoundMemoryPending
+				}
+				return persisted, true, ErrRoundAlreadyPersisted
+			}
 			for i, message := range persisted {
-				if !inputs[i].SkipHistoryTurn {
+				if i < len(inputs) && !inputs[i].SkipHistoryTurn {
 					s.publishMessageCreated(message)
 				}
 			}
@@ -342,6 +415,133 @@ func (s *DBService) PersistRound(ctx context.Context, inputs []PersistInput, opt
 	return nil, true, lastErr
 }
 
+// claimRoundKey claims roundKey for the session. When the key was claimed by
+// an earlier committed round, it returns claimed=false together with the
+// messages that round persisted. Stores without round-key support never
+// deduplicate.
+func (s *DBService) claimRoundKey(ctx context.Context, sessionID pgtype.UUID, roundKey string) (bool, []Message, error) {
+	writer, ok := s.queries.(historyRoundKeyWriter)
+	if !ok {
+		return true, nil, nil
+	}
+	claimed, err := writer.ClaimHistoryRoundKey(ctx, sqlc.ClaimHistoryRoundKeyParams{
+		SessionID: sessionID,
+		RoundKey:  roundKey,
+	})
+	if err != nil {
+		return false, nil, fmt.Errorf("claim round key: %w", err)
+	}
+	if claimed > 0 {
+		return true, nil, nil
+	}
+	ids, err := writer.GetHistoryRoundKeyMessages(ctx, sqlc.GetHistoryRoundKeyMessagesParams{
+		SessionID: sessionID,
+		RoundKey:  roundKey,
+	})
+	if err != nil {
+		return false, nil, fmt.Errorf("load round key messages: %w", err)
+	}
+	previous := make([]Message, 0, len(ids))
+	for _, id := range ids {
+		row, err := s.queries.GetMessageByIDBySession(ctx, sqlc.GetMessageByIDBySessionParams{
+			SessionID: sessionID,
+			MessageID: id,
+		})
+		if err != nil {
+			// Rows superseded or hidden after the original write are no
+			// longer part of the visible round.
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return false, nil, err
+		}
+		previous = append(previous, toMessageFromIDBySessionRow(row))
+	}
+	s.hydrate(ctx, previous)
+	return false, previous, nil
+}
+
+// recordRoundKey stores the message ids written under a claimed round key so
+// replays can return them, and marks the round as owing memory extraction
+// when memoryPending is set. Both writes share the round's transaction.
+func (s *DBService) recordRoundKey(ctx context.Context, sessionID pgtype.UUID, roundKey string, persisted []Message, memoryPending bool) error {
+	if roundKey == "" {
+		return nil
+	}
+	writer, ok := s.queries.(historyRoundKeyWriter)
+	if !ok {
+		return nil
+	}
+	ids := make([]pgtype.UUID, 0, len(persisted))
+	for _, message := range persisted {
+		id, err := dbpkg.ParseUUID(message.ID)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := writer.SetHistoryRoundKeyMessages(ctx, sqlc.SetHistoryRoundKeyMessagesParams{
+		MessageIds: ids,
+		SessionID:  sessionID,
+		RoundKey:   roundKey,
+	}); err != nil {
+		return fmt.Errorf("record round key messages: %w", err)
+	}
+	if !memoryPending {
+		return nil
+	}
+	return s.setRoundMemoryPending(ctx, sessionID, roundKey, true)
+}
+
+func (s *DBService) setRoundMemoryPending(ctx context.Context, sessionID pgtype.UUID, roundKey string, pending bool) error {
+	writer, ok := s.queries.(historyRoundMemoryWriter)
+	if !ok {
+		return nil
+	}
+	if err := writer.SetHistoryRoundKeyMemoryPending(ctx, sqlc.SetHistoryRoundKeyMemoryPendingParams{
+		MemoryPending: pending,
+		SessionID:     sessionID,
+		RoundKey:      roundKey,
+	}); err != nil {
+		return fmt.Errorf("record round memory ref: %w", err)
+	}
+	return nil
+}
+
+// claimRoundMemory claims the memory extraction still owed by the committed
+// round under roundKey. It reports false when the round owes nothing or
+// another attempt holds a live claim, so concurrent replays extract at most
+// once per lease.
+func (s *DBService) claimRoundMemory(ctx context.Context, sessionID pgtype.UUID, roundKey string) (bool, error) {
+	writer, ok := s.queries.(historyRoundMemoryWriter)
+	if !ok {
+		return false, nil
+	}
+	claimed, err := writer.ClaimHistoryRoundKeyMemory(ctx, sqlc.ClaimHistoryRoundKeyMemoryParams{
+		SessionID:    sessionID,
+		RoundKey:     roundKey,
+		LeaseSeconds: int32(roundMemoryLease / time.Second),
+	})
+	if err != nil {
+		return false, fmt.Errorf("claim round memory ref: %w", err)
+	}
+	return claimed > 0, nil
+}
+
+// CompleteRoundMemory clears the pending memory extraction of the round
+// persisted under roundKey once its memories were written.
+func (s *DBService) CompleteRoundMemory(ctx context.Context, sessionID, roundKey string) error {
+	roundKey = strings.TrimSpace(roundKey)
+	if s == nil || s.queries == nil || roundKey == "" {
+		return nil
+	}
+	pgSessionID, err := dbpkg.ParseUUID(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.setRoundMemoryPending(ctx, pgSessionID, roundKey, false)
+}
+
 func isToolTailRoundShape(inputs []PersistInput) bool {
 	if len(inputs) != 4 {
 		return false
@@ -405,14 +605,23 @@ func (s *DBService) preparePersistMessage(ctx context.Context, input PersistInpu
 	}
 
 	metadata := nonNilMap(input.Metadata)
+	content, quarantineID := s.validatePersistContent(ctx, pgBotID, input)
+	if quarantineID != "" {
+		metadata = withMetadataValue(metadata, MetadataContentQuarantineID, quarantineID)
+	}
 	metaBytes, err := json.Marshal(metadata)
 	if err != nil {
 		return preparedPersistMessage{}, fmt.Errorf("marshal message metadata: %w", err)
 	}
 
-	content := input.Content
-	if len(content) == 0 {
-		content = []byte("{}")
+	displayText := input.DisplayText
+	if s.cipher != nil {
+		if content, err = s.cipher.SealJSON(ctx, pgBotID, content); err != nil {
+			return preparedPersistMessage{}, fmt.Errorf("seal message content: %w", err)
+		}
+		if displayText, err = s.cipher.SealText(ctx, pgBotID, displayText); err != nil {
+			return preparedPersistMessage{}, fmt.Errorf("seal message display text: %w", err)
+		}
 	}
 
 	sessionMode, runtimeType := resolveRuntimeSnapshotWithQueries(ctx, s.queries, pgSessionID, input.SessionMode, input.RuntimeType)
@@ -432,7 +641,7 @@ func (s *DBService) preparePersistMessage(ctx context.Context, input PersistInpu
 			RuntimeType:             runtimeType,
 			ModelID:                 pgModelID,
 			EventID:                 pgEventID,
-			DisplayText:             toPgText(input.DisplayText),
+			DisplayText:             toPgText(displayText),
 		},
 		metadata:  metadata,
 		botID:     pgBotID,
@@ -591,6 +800,7 @@ func persistDirectHistoryMessage(
 }
 
 func (s *DBService) finishPersistedMessage(ctx context.Context, result Message, pgMsgID pgtype.UUID, assets []AssetRef) (Message, error) {
+	s.openMessage(ctx, &result)
 	for _, ref := range assets {
 		role := ref.Role
 		if strings.TrimSpace(role) == "" {
@@ -873,7 +1083,7 @@ func (s *DBService) List(ctx context.Context, botID string) ([]Message, error) {
 		return nil, err
 	}
 	msgs := toMessagesFromList(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -891,7 +1101,7 @@ func (s *DBService) ListSince(ctx context.Context, botID string, since time.Time
 		return nil, err
 	}
 	msgs := toMessagesFromSince(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -909,7 +1119,7 @@ func (s *DBService) ListActiveSince(ctx context.Context, botID string, since tim
 		return nil, err
 	}
 	msgs := toMessagesFromActiveSince(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -927,7 +1137,7 @@ func (s *DBService) ListLatest(ctx context.Context, botID string, limit int32) (
 		return nil, err
 	}
 	msgs := toMessagesFromLatest(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -946,7 +1156,7 @@ func (s *DBService) ListBefore(ctx context.Context, botID string, before time.Ti
 		return nil, err
 	}
 	msgs := toMessagesFromBefore(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -963,7 +1173,7 @@ func (s *DBService) ListBySession(ctx context.Context, sessionID string) ([]Mess
 		return nil, err
 	}
 	msgs := toMessagesFromSessionList(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -981,7 +1191,7 @@ func (s *DBService) ListSinceBySession(ctx context.Context, sessionID string, si
 		return nil, err
 	}
 	msgs := toMessagesFromSinceBySession(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -999,7 +1209,7 @@ func (s *DBService) ListActiveSinceBySession(ctx context.Context, sessionID stri
 		return nil, err
 	}
 	msgs := toMessagesFromActiveSinceBySession(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -1017,7 +1227,7 @@ func (s *DBService) ListLatestBySession(ctx context.Context, sessionID string, l
 		return nil, err
 	}
 	msgs := toMessagesFromLatestBySession(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -1036,7 +1246,7 @@ func (s *DBService) ListLatestUIBySession(ctx context.Context, sessionID string,
 		return nil, err
 	}
 	msgs := toMessagesFromLatestUIBySession(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -1055,7 +1265,7 @@ func (s *DBService) ListBeforeBySession(ctx context.Context, sessionID string, b
 		return nil, err
 	}
 	msgs := toMessagesFromBeforeBySession(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -1092,7 +1302,7 @@ func (s *DBService) ListBeforeMessageBySession(ctx context.Context, sessionID st
 		return nil, err
 	}
 	msgs := toMessagesFromBeforeCursorBySession(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -1129,7 +1339,7 @@ func (s *DBService) LocateByExternalIDBySession(ctx context.Context, sessionID s
 	}
 	messages := toMessagesFromLocateWindowByExternalIDBySession(rows)
 
-	s.enrichAssets(ctx, messages)
+	s.hydrate(ctx, messages)
 	return LocateResult{Messages: messages, TargetID: uuidString(rows[0].TargetID)}, nil
 }
 
@@ -1151,7 +1361,7 @@ func (s *DBService) GetByIDBySession(ctx context.Context, sessionID string, mess
 	}
 	msg := toMessageFromIDBySessionRow(row)
 	msgs := []Message{msg}
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs[0], nil
 }
 
@@ -1172,7 +1382,7 @@ func (s *DBService) ListVisibleFromBySession(ctx context.Context, sessionID stri
 		return nil, err
 	}
 	msgs := toMessagesFromVisibleFromBySession(rows)
-	s.enrichAssets(ctx, msgs)
+	s.hydrate(ctx, msgs)
 	return msgs, nil
 }
 
@@ -2273,6 +2483,37 @@ func (s *DBService) publishMessageCreated(message Message) {
 }
 
 // enrichAssets batch-loads asset links for a list of messages (single-table query).
+// hydrate prepares rows read from the store for callers: it opens sealed
+// content and attaches asset references.
+func (s *DBService) hydrate(ctx context.Context, messages []Message) {
+	for i := range messages {
+		s.openMessage(ctx, &messages[i])
+	}
+	s.enrichAssets(ctx, messages)
+}
+
+// openMessage replaces sealed content with plaintext in place. A value that
+// fails to open is left sealed and logged rather than failing the whole read.
+func (s *DBService) openMessage(ctx context.Context, m *Message) {
+	if s.cipher == nil {
+		return
+	}
+	botID, err := dbpkg.ParseUUID(m.BotID)
+	if err != nil {
+		return
+	}
+	if content, err := s.cipher.OpenJSON(ctx, botID, m.Content); err != nil {
+		s.logger.Warn("open message content failed", slog.String("message_id", m.ID), slog.Any("error", err))
+	} else {
+		m.Content = content
+	}
+	if text, err := s.cipher.OpenText(ctx, botID, m.DisplayContent); err != nil {
+		s.logger.Warn("open message display text failed", slog.String("message_id", m.ID), slog.Any("error", err))
+	} else {
+		m.DisplayContent = text
+	}
+}
+
 func (s *DBService) enrichAssets(ctx context.Context, messages []Message) {
 	if len(messages) == 0 {
 		return
@@ -368,6 +368,131 @@ func TestPersistRetriesTurnSequenceUniqueViolation(t *testing.T) {
 	}
 }
 
+type replayedRoundQueries struct {
+	dbstore.Queries
+
+	created       int
+	memoryPending bool
+	memoryClaimed bool
+}
+
+func (q *replayedRoundQueries) ClaimHistoryRoundKeyMemory(context.Context, sqlc.ClaimHistoryRoundKeyMemoryParams) (int64, error) {
+	if !q.memoryPending || q.memoryClaimed {
+		return 0, nil
+	}
+	q.memoryClaimed = true
+	return 1, nil
+}
+
+func (q *replayedRoundQueries) SetHistoryRoundKeyMemoryPending(_ context.Context, arg sqlc.SetHistoryRoundKeyMemoryPendingParams) error {
+	q.memoryPending = arg.MemoryPending
+	q.memoryClaimed = arg.MemoryPending
+	return nil
+}
+
+func (q *replayedRoundQueries) InTx(_ context.Context, fn func(dbstore.Queries) error) error {
+	return fn(q)
+}
+
+func (*replayedRoundQueries) ClaimHistoryRoundKey(context.Context, sqlc.ClaimHistoryRoundKeyParams) (int64, error) {
+	return 0, nil
+}
+
+func (*replayedRoundQueries) GetHistoryRoundKeyMessages(context.Context, sqlc.GetHistoryRoundKeyMessagesParams) ([]pgtype.UUID, error) {
+	return []pgtype.UUID{testMessageUUID("88888888-8888-8888-8888-888888888888")}, nil
+}
+
+func (*replayedRoundQueries) SetHistoryRoundKeyMessages(context.Context, sqlc.SetHistoryRoundKeyMessagesParams) error {
+	return nil
+}
+
+func (*replayedRoundQueries) GetMessageByIDBySession(_ context.Context, arg sqlc.GetMessageByIDBySessionParams) (sqlc.GetMessageByIDBySessionRow, error) {
+	return sqlc.GetMessageByIDBySessionRow{
+		ID:        arg.MessageID,
+		SessionID: arg.SessionID,
+		Role:      "assistant",
+		Content:   []byte(`{"type":"text","text":"hello"}`),
+		CreatedAt: pgtype.Timestamptz{Valid: true},
+	}, nil
+}
+
+func (*replayedRoundQueries) ListMessageAssetsBatch(context.Context, []pgtype.UUID) ([]sqlc.ListMessageAssetsBatchRow, error) {
+	return nil, nil
+}
+
+func (q *replayedRoundQueries) CreateMessage(context.Context, sqlc.CreateMessageParams) (sqlc.CreateMessageRow, error) {
+	q.created++
+	return sqlc.CreateMessageRow{}, errors.New("unexpected CreateMessage")
+}
+
+func TestPersistRoundReturnsOriginalMessagesForReplayedKey(t *testing.T) {
+	queries := &replayedRoundQueries{}
+	publisher := &recordingPublisher{}
+	svc := NewService(nil, queries, publisher)
+
+	persisted, handled, err := svc.PersistRound(context.Background(), []PersistInput{{
+		BotID:     "11111111-1111-1111-1111-111111111111",
+		SessionID: "22222222-2222-2222-2222-222222222222",
+		Role:      "assistant",
+		Content:   []byte(`{"type":"text","text":"hello"}`),
+	}}, RoundPersistenceOptions{IdempotencyKey: "round-1"})
+	if !handled {
+		t.Fatal("PersistRound() handled = false, want true")
+	}
+	if !errors.Is(err, ErrRoundAlreadyPersisted) {
+		t.Fatalf("PersistRound() error = %v, want ErrRoundAlreadyPersisted", err)
+	}
+	if len(persisted) != 1 || persisted[0].ID != "88888888-8888-8888-8888-888888888888" {
+		t.Fatalf("persisted = %#v, want original message", persisted)
+	}
+	if queries.created != 0 {
+		t.Fatalf("CreateMessage calls = %d, want 0", queries.created)
+	}
+	if len(publisher.events) != 0 {
+		t.Fatalf("published events = %d, want 0", len(publisher.events))
+	}
+}
+
+func TestPersistRoundReportsReplayWithPendingMemory(t *testing.T) {
+	queries := &replayedRoundQueries{memoryPending: true}
+	svc := NewService(nil, queries)
+	inputs := []PersistInput{{
+		BotID:     "11111111-1111-1111-1111-111111111111",
+		SessionID: "22222222-2222-2222-2222-222222222222",
+		Role:      "assistant",
+		Content:   []byte(`{"type":"text","text":"hello"}`),
+	}}
+
+	_, _, err := svc.PersistRound(context.Background(), inputs, RoundPersistenceOptions{IdempotencyKey: "round-1", MemoryPending: true})
+	if !errors.Is(err, ErrRoundMemoryPending) || !errors.Is(err, ErrRoundAlreadyPersisted) {
+		t.Fatalf("PersistRound() error = %v, want ErrRoundMemoryPending", err)
+	}
+	_, _, err = svc.PersistRound(context.Background(), inputs, RoundPersistenceOptions{IdempotencyKey: "round-1", MemoryPending: true})
+	if errors.Is(err, ErrRoundMemoryPending) || !errors.Is(err, ErrRoundAlreadyPersisted) {
+		t.Fatalf("PersistRound() during live claim error = %v, want plain ErrRoundAlreadyPersisted", err)
+	}
+	if err := svc.CompleteRoundMemory(context.Background(), inputs[0].SessionID, "round-1"); err != nil {
+		t.Fatalf("CompleteRoundMemory() error = %v", err)
+	}
+	_, _, err = svc.PersistRound(context.Background(), inputs, RoundPersistenceOptions{IdempotencyKey: "round-1", MemoryPending: true})
+	if errors.Is(err, ErrRoundMemoryPending) || !errors.Is(err, ErrRoundAlreadyPersisted) {
+		t.Fatalf("PersistRound() after completion error = %v, want plain ErrRoundAlreadyPersisted", err)
+	}
+}
+
+func TestPersistRoundWithoutKeyOrFenceIsNotHandled(t *testing.T) {
+	svc := NewService(nil, &replayedRoundQueries{})
+
+	_, handled, err := svc.PersistRound(context.Background(), []PersistInput{{
+		BotID:     "11111111-1111-1111-1111-111111111111",
+		SessionID: "22222222-2222-2222-2222-222222222222",
+		Role:      "assistant",
+	}}, RoundPersistenceOptions{})
+	if handled || err != nil {
+		t.Fatalf("PersistRound() = handled %v, err %v; want unhandled", handled, err)
+	}
+}
+
 func testMessageUUID(value string) pgtype.UUID {
 	var id pgtype.UUID
 	if err := id.Scan(value); err != nil {
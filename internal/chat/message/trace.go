@@ -0,0 +1,159 @@
+package message
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// TraceMetadataKey is the metadata key of the RoundTrace stored on the last
+// assistant message of a streamed round.
+const TraceMetadataKey = "trace"
+
+// RoundTrace records where the time of one agent round went. It is captured
+// while the round streams, since a round's messages are persisted together
+// and their timestamps say nothing about latency.
+type RoundTrace struct {
+	ModelID      string `json:"model_id,omitempty"`
+	ModelSlug    string `json:"model_slug,omitempty"`
+	ModelName    string `json:"model_name,omitempty"`
+	ProviderName string `json:"provider_name,omitempty"`
+	// ResolveMs covers model selection and loading history, memory and the
+	// system prompt before the model is called.
+	ResolveMs int64 `json:"resolve_ms"`
+	// HistoryTokens is the estimated size of the context sent to the model.
+	HistoryTokens int `json:"history_tokens"`
+	// FirstOutputMs is the gateway latency from calling the model to its
+	// first streamed output.
+	FirstOutputMs int64           `json:"first_output_ms"`
+	TotalMs       int64           `json:"total_ms"`
+	Retries       int             `json:"retries,omitempty"`
+	ToolCalls     []ToolCallTrace `json:"tool_calls,omitempty"`
+	// MemoryWrite reports whether the round was queued for memory
+	// extraction after it was stored.
+	MemoryWrite bool `json:"memory_write"`
+}
+
+// ToolCallTrace is the timing of one tool call in a round.
+type ToolCallTrace struct {
+	ToolCallID string `json:"tool_call_id"`
+	Name       string `json:"name"`
+	DurationMs int64  `json:"duration_ms"`
+	// Status is "error" when the tool failed and "pending" when the round
+	// ended before the tool returned.
+	Status string `json:"status"`
+}
+
+// TraceStep is one persisted message of a traced round.
+type TraceStep struct {
+	MessageID string     `json:"message_id"`
+	Role      string     `json:"role"`
+	Usage     TraceUsage `json:"usage"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TraceUsage is the token usage of one model call or a whole round.
+type TraceUsage struct {
+	InputTokens     int64 `json:"input_tokens"`
+	OutputTokens    int64 `json:"output_tokens"`
+	CacheReadTokens int64 `json:"cache_read_tokens"`
+	ReasoningTokens int64 `json:"reasoning_tokens"`
+}
+
+func (u *TraceUsage) add(other TraceUsage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.ReasoningTokens += other.ReasoningTokens
+}
+
+// TurnTrace is the cost and latency breakdown of one history turn. Timing is
+// nil for rounds stored before tracing or by runtimes that do not stream,
+// such as ACP agents.
+type TurnTrace struct {
+	TurnID             string      `json:"turn_id"`
+	RequestMessageID   string      `json:"request_message_id,omitempty"`
+	AssistantMessageID string      `json:"assistant_message_id,omitempty"`
+	ModelCalls         int         `json:"model_calls"`
+	Usage              TraceUsage  `json:"usage"`
+	Steps              []TraceStep `json:"steps"`
+	Timing             *RoundTrace `json:"timing,omitempty"`
+}
+
+// BuildTurnTrace assembles the trace of turn from its messages, which must
+// start at the turn's request message in history order. Messages after the
+// turn's assistant message are ignored.
+func BuildTurnTrace(turn HistoryTurn, messages []Message) TurnTrace {
+	trace := TurnTrace{
+		TurnID:             turn.ID,
+		RequestMessageID:   turn.RequestMessageID,
+		AssistantMessageID: turn.AssistantMessageID,
+		Steps:              []TraceStep{},
+	}
+	for i, msg := range messages {
+		if i > 0 && turn.AssistantMessageID == "" && msg.Role == "user" && msg.DisplayContent != "" {
+			// Without a bound assistant message the next visible user
+			// message starts the next turn.
+			break
+		}
+		usage := parseTraceUsage(msg.Usage)
+		if msg.Role == "assistant" && len(msg.Usage) > 0 {
+			trace.ModelCalls++
+			trace.Usage.add(usage)
+		}
+		trace.Steps = append(trace.Steps, TraceStep{
+			MessageID: msg.ID,
+			Role:      msg.Role,
+			Usage:     usage,
+			CreatedAt: msg.CreatedAt,
+		})
+		if timing, ok := roundTraceFromMetadata(msg.Metadata); ok {
+			trace.Timing = &timing
+		}
+		if msg.ID == turn.AssistantMessageID {
+			break
+		}
+	}
+	return trace
+}
+
+func parseTraceUsage(raw json.RawMessage) TraceUsage {
+	if len(raw) == 0 {
+		return TraceUsage{}
+	}
+	var usage struct {
+		InputTokens       int64 `json:"inputTokens"`
+		OutputTokens      int64 `json:"outputTokens"`
+		InputTokenDetails struct {
+			CacheReadTokens int64 `json:"cacheReadTokens"`
+		} `json:"inputTokenDetails"`
+		OutputTokenDetails struct {
+			ReasoningTokens int64 `json:"reasoningTokens"`
+		} `json:"outputTokenDetails"`
+	}
+	if err := json.Unmarshal(raw, &usage); err != nil {
+		return TraceUsage{}
+	}
+	return TraceUsage{
+		InputTokens:     usage.InputTokens,
+		OutputTokens:    usage.OutputTokens,
+		CacheReadTokens: usage.InputTokenDetails.CacheReadTokens,
+		ReasoningTokens: usage.OutputTokenDetails.ReasoningTokens,
+	}
+}
+
+func roundTraceFromMetadata(metadata map[string]any) (RoundTrace, bool) {
+	raw, ok := metadata[TraceMetadataKey]
+	if !ok || raw == nil {
+		return RoundTrace{}, false
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return RoundTrace{}, false
+	}
+	var trace RoundTrace
+	if err := json.Unmarshal(data, &trace); err != nil {
+		return RoundTrace{}, false
+	}
+	return trace, strings.TrimSpace(string(data)) != "{}"
+}
@@ -0,0 +1,56 @@
+package message
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildTurnTraceSumsUsageUpToAssistantMessage(t *testing.T) {
+	t.Parallel()
+
+	turn := HistoryTurn{ID: "turn-1", RequestMessageID: "m1", AssistantMessageID: "m4"}
+	messages := []Message{
+		{ID: "m1", Role: "user", DisplayContent: "hi"},
+		{ID: "m2", Role: "assistant", Usage: json.RawMessage(`{"inputTokens":100,"outputTokens":10,"inputTokenDetails":{"cacheReadTokens":40}}`)},
+		{ID: "m3", Role: "tool"},
+		{ID: "m4", Role: "assistant", Usage: json.RawMessage(`{"inputTokens":150,"outputTokens":20,"outputTokenDetails":{"reasoningTokens":5}}`), Metadata: map[string]any{
+			TraceMetadataKey: RoundTrace{ModelID: "model-1", ResolveMs: 12, FirstOutputMs: 340, TotalMs: 900, ToolCalls: []ToolCallTrace{{ToolCallID: "call-1", Name: "web_search", DurationMs: 200, Status: "ok"}}},
+		}},
+		{ID: "m5", Role: "user", DisplayContent: "next"},
+	}
+
+	trace := BuildTurnTrace(turn, messages)
+	if len(trace.Steps) != 4 {
+		t.Fatalf("expected 4 steps, got %d", len(trace.Steps))
+	}
+	if trace.ModelCalls != 2 {
+		t.Fatalf("expected 2 model calls, got %d", trace.ModelCalls)
+	}
+	want := TraceUsage{InputTokens: 250, OutputTokens: 30, CacheReadTokens: 40, ReasoningTokens: 5}
+	if trace.Usage != want {
+		t.Fatalf("unexpected usage: %+v", trace.Usage)
+	}
+	if trace.Timing == nil || trace.Timing.FirstOutputMs != 340 || len(trace.Timing.ToolCalls) != 1 {
+		t.Fatalf("unexpected timing: %+v", trace.Timing)
+	}
+}
+
+func TestBuildTurnTraceStopsAtNextUserMessageWhenUnbound(t *testing.T) {
+	t.Parallel()
+
+	turn := HistoryTurn{ID: "turn-1", RequestMessageID: "m1"}
+	messages := []Message{
+		{ID: "m1", Role: "user", DisplayContent: "hi"},
+		{ID: "m2", Role: "assistant", Usage: json.RawMessage(`{"inputTokens":10}`)},
+		{ID: "m3", Role: "user", DisplayContent: "next"},
+		{ID: "m4", Role: "assistant", Usage: json.RawMessage(`{"inputTokens":20}`)},
+	}
+
+	trace := BuildTurnTrace(turn, messages)
+	if len(trace.Steps) != 2 || trace.Usage.InputTokens != 10 {
+		t.Fatalf("unexpected trace: %+v", trace)
+	}
+	if trace.Timing != nil {
+		t.Fatalf("expected no timing, got %+v", trace.Timing)
+	}
+}
@@ -3,6 +3,8 @@ package message
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -119,6 +121,34 @@ type TurnReplacement struct {
 
 type RoundPersistenceOptions struct {
 	Replacement *TurnReplacement
+	// IdempotencyKey identifies one logical round within its session. A
+	// retried write carrying an already-claimed key persists nothing and
+	// returns the originally persisted messages with ErrRoundAlreadyPersisted.
+	IdempotencyKey string
+	// Atomic persists the round in one transaction even without an
+	// IdempotencyKey; such rounds are never deduplicated.
+	Atomic bool
+	// MemoryPending records, in the round's transaction, that memory
+	// extraction is owed for the round and claims it for the caller. It needs
+	// an IdempotencyKey; clear it with RoundMemoryCompleter once the memories
+	// are written.
+	MemoryPending bool
+}
+
+// ErrRoundAlreadyPersisted reports that a round with the same idempotency key
+// was committed earlier. PersistRound returns it together with the messages
+// written by the original attempt.
+var ErrRoundAlreadyPersisted = errors.New("history round already persisted")
+
+// ErrRoundMemoryPending is ErrRoundAlreadyPersisted for a round whose memory
+// extraction never completed and whose claim has lapsed. The replaying caller
+// now holds the claim and should run the extraction.
+var ErrRoundMemoryPending = fmt.Errorf("%w with memory extraction pending", ErrRoundAlreadyPersisted)
+
+// RoundMemoryCompleter clears the memory ref recorded by
+// RoundPersistenceOptions.MemoryPending.
+type RoundMemoryCompleter interface {
+	CompleteRoundMemory(ctx context.Context, sessionID, roundKey string) error
 }
 
 // AtomicRoundPersister writes a complete round in one transaction.
@@ -0,0 +1,128 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+// Analytics aggregates the tags of a bot's user messages over a period.
+type Analytics struct {
+	BotID      string         `json:"bot_id"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Tagged     int64          `json:"tagged"`
+	Sentiments SentimentCount `json:"sentiments"`
+	Daily      []DailyCount   `json:"daily"`
+	Topics     []TopicCount   `json:"topics"`
+}
+
+// SentimentCount counts tagged messages per sentiment.
+type SentimentCount struct {
+	Positive int64 `json:"positive"`
+	Neutral  int64 `json:"neutral"`
+	Negative int64 `json:"negative"`
+}
+
+// DailyCount is one UTC day of the sentiment series.
+type DailyCount struct {
+	Day string `json:"day"`
+	SentimentCount
+}
+
+// TopicCount is how many messages were tagged with a topic.
+type TopicCount struct {
+	Topic    string `json:"topic"`
+	Messages int64  `json:"messages"`
+}
+
+// AnalyticsDays clamps a requested analytics window to the supported range.
+func AnalyticsDays(days int) int {
+	if days <= 0 {
+		return defaultAnalytics
+	}
+	return min(days, maxAnalyticsDays)
+}
+
+// Analytics returns the sentiment series and top topics of the last days
+// UTC days, today included.
+func (s *Service) Analytics(ctx context.Context, botID string, days int) (Analytics, error) {
+	store, err := s.store()
+	if err != nil {
+		return Analytics{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Analytics{}, err
+	}
+	days = AnalyticsDays(days)
+	now := s.now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -days)
+	fromTime := pgtype.Timestamptz{Time: from, Valid: true}
+	toTime := pgtype.Timestamptz{Time: to, Valid: true}
+
+	sentiments, err := store.CountMessageSentimentsByDay(ctx, sqlc.CountMessageSentimentsByDayParams{
+		BotID:    pgBotID,
+		FromTime: fromTime,
+		ToTime:   toTime,
+	})
+	if err != nil {
+		return Analytics{}, fmt.Errorf("count message sentiments: %w", err)
+	}
+	topics, err := store.CountMessageTopics(ctx, sqlc.CountMessageTopicsParams{
+		BotID:    pgBotID,
+		FromTime: fromTime,
+		ToTime:   toTime,
+		RowLimit: analyticsTopLimit,
+	})
+	if err != nil {
+		return Analytics{}, fmt.Errorf("count message topics: %w", err)
+	}
+
+	result := Analytics{
+		BotID:  botID,
+		From:   from,
+		To:     to,
+		Daily:  make([]DailyCount, 0, days),
+		Topics: make([]TopicCount, 0, len(topics)),
+	}
+	byDay := map[string]*SentimentCount{}
+	for i := range days {
+		day := from.AddDate(0, 0, i).Format(time.DateOnly)
+		result.Daily = append(result.Daily, DailyCount{Day: day})
+		byDay[day] = &result.Daily[i].SentimentCount
+	}
+	for _, row := range sentiments {
+		if !row.Day.Valid {
+			continue
+		}
+		count, ok := byDay[row.Day.Time.Format(time.DateOnly)]
+		if !ok {
+			continue
+		}
+		count.add(row.Sentiment, row.Messages)
+		result.Sentiments.add(row.Sentiment, row.Messages)
+		result.Tagged += row.Messages
+	}
+	for _, row := range topics {
+		result.Topics = append(result.Topics, TopicCount{Topic: row.Topic, Messages: row.Messages})
+	}
+	return result, nil
+}
+
+func (c *SentimentCount) add(sentiment string, messages int64) {
+	switch sentiment {
+	case SentimentPositive:
+		c.Positive += messages
+	case SentimentNeutral:
+		c.Neutral += messages
+	case SentimentNegative:
+		c.Negative += messages
+	}
+}
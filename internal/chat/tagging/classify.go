@@ -0,0 +1,173 @@
+package tagging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/memohai/twilight-ai/sdk"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	"github.com/memohai/memoh/internal/models"
+	"github.com/memohai/memoh/internal/oauthctx"
+	"github.com/memohai/memoh/internal/providers"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+const (
+	classifyGenerateTimeout = 60 * time.Second
+	// maxMessageRunes caps each message in the prompt; the opening of a
+	// message is enough to tell what it is about.
+	maxMessageRunes = 1000
+)
+
+// classifyWithModel tags texts with the bot's tagging model, or the owner's
+// title model when none is set.
+func (s *Service) classifyWithModel(ctx context.Context, cfg sqlc.BotMessageTagging, texts []string) (map[int]Tags, error) {
+	if s.modelsService == nil || s.providersService == nil {
+		return nil, errNoModel
+	}
+	modelID, ownerUserID, err := s.resolveModel(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if modelID == "" {
+		return nil, errNoModel
+	}
+	model, err := s.modelsService.GetByID(ctx, modelID)
+	if err != nil {
+		return nil, err
+	}
+	if !model.Enable {
+		return nil, fmt.Errorf("message tagging model %s is disabled", modelID)
+	}
+	provider, err := models.FetchProviderByID(ctx, s.queries, model.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerUserID != "" {
+		ctx = oauthctx.WithUserID(ctx, ownerUserID)
+	}
+	creds, err := s.providersService.ResolveModelCredentials(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	sdkModel := models.NewSDKChatModel(models.SDKModelConfig{
+		ModelID:        model.ModelID,
+		ClientType:     provider.ClientType,
+		APIKey:         creds.APIKey,
+		CodexAccountID: creds.CodexAccountID,
+		BaseURL:        providers.ProviderConfigString(provider, "base_url"),
+	})
+
+	topics := topicsOf(cfg)
+	genCtx, cancel := context.WithTimeout(ctx, classifyGenerateTimeout)
+	defer cancel()
+	reply, err := sdk.NewClient().GenerateText(genCtx,
+		sdk.WithModel(sdkModel),
+		sdk.WithMessages([]sdk.Message{sdk.UserMessage(classifyPrompt(topics, texts))}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	results, err := parseClassification(reply, len(texts), topics)
+	if err != nil {
+		return nil, err
+	}
+	for i, tags := range results {
+		tags.ModelID = modelID
+		results[i] = tags
+	}
+	return results, nil
+}
+
+// resolveModel returns the tagging model and the bot owner, whose provider
+// credentials are used.
+func (s *Service) resolveModel(ctx context.Context, cfg sqlc.BotMessageTagging) (modelID, ownerUserID string, err error) {
+	bot, err := s.queries.GetBotByID(ctx, cfg.BotID)
+	if err != nil {
+		return "", "", err
+	}
+	if bot.OwnerUserID.Valid {
+		ownerUserID = bot.OwnerUserID.String()
+	}
+	if cfg.ModelID.Valid {
+		return cfg.ModelID.String(), ownerUserID, nil
+	}
+	if ownerUserID == "" || s.accountService == nil {
+		return "", ownerUserID, nil
+	}
+	account, err := s.accountService.Get(ctx, ownerUserID)
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(account.TitleModelID), ownerUserID, nil
+}
+
+func classifyPrompt(topics, texts []string) string {
+	var b strings.Builder
+	b.WriteString("Classify each user message below by sentiment and topic.\n")
+	b.WriteString("Sentiment is one of: positive, neutral, negative.\n")
+	b.WriteString("Topics are zero or more of: ")
+	b.WriteString(strings.Join(topics, ", "))
+	b.WriteString(".\n")
+	b.WriteString(`Return ONLY a JSON array with one object per message, like [{"id":0,"sentiment":"neutral","topics":["question"]}].`)
+	b.WriteString("\n\n")
+	for i, text := range texts {
+		b.WriteString("Message ")
+		b.WriteString(strconv.Itoa(i))
+		b.WriteString(":\n")
+		b.WriteString(textutil.TruncateRunes(text, maxMessageRunes))
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
+
+// parseClassification reads the model's JSON reply. Entries with unknown ids
+// or sentiments are dropped and topics outside the taxonomy are ignored.
+func parseClassification(reply string, count int, topics []string) (map[int]Tags, error) {
+	reply = strings.TrimSpace(reply)
+	if start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]"); start >= 0 && end > start {
+		reply = reply[start : end+1]
+	}
+	var entries []struct {
+		ID        int      `json:"id"`
+		Sentiment string   `json:"sentiment"`
+		Topics    []string `json:"topics"`
+	}
+	if err := json.Unmarshal([]byte(reply), &entries); err != nil {
+		return nil, fmt.Errorf("parse message tags: %w", err)
+	}
+	results := make(map[int]Tags, len(entries))
+	for _, entry := range entries {
+		sentiment := strings.ToLower(strings.TrimSpace(entry.Sentiment))
+		if entry.ID < 0 || entry.ID >= count || !validSentiment(sentiment) {
+			continue
+		}
+		tags := Tags{Sentiment: sentiment, Topics: []string{}}
+		for _, topic := range entry.Topics {
+			topic = strings.ToLower(strings.TrimSpace(topic))
+			if slices.Contains(topics, topic) && !slices.Contains(tags.Topics, topic) {
+				tags.Topics = append(tags.Topics, topic)
+			}
+		}
+		results[entry.ID] = tags
+	}
+	if len(results) == 0 && count > 0 {
+		return nil, errors.New("model returned no usable message tags")
+	}
+	return results, nil
+}
+
+func validSentiment(sentiment string) bool {
+	switch sentiment {
+	case SentimentPositive, SentimentNeutral, SentimentNegative:
+		return true
+	}
+	return false
+}
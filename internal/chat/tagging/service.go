@@ -0,0 +1,499 @@
+// Package tagging tags a bot's user messages with a sentiment and coarse
+// topics from a per-bot taxonomy, using a cheap model in the background, so
+// owners can see what users ask about. Tags are stored in the message
+// metadata under "classification" and aggregated by Analytics.
+package tagging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/accounts"
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/models"
+	"github.com/memohai/memoh/internal/providers"
+)
+
+const (
+	// MetadataKey is the message metadata key holding a message's tags.
+	MetadataKey = "classification"
+
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+
+	sweepInterval = time.Minute
+	// batchSize is how many messages go into one model call; a sweep tags at
+	// most maxBatchesPerBot batches per bot so one busy bot cannot hold the
+	// worker.
+	batchSize        = 20
+	maxBatchesPerBot = 5
+	// failureBackoff pauses a bot whose model calls fail.
+	failureBackoff = 15 * time.Minute
+
+	maxTopics         = 30
+	maxTopicRunes     = 40
+	defaultAnalytics  = 30
+	maxAnalyticsDays  = 365
+	analyticsTopLimit = 20
+)
+
+// DefaultTopics is the taxonomy of bots that did not set their own.
+var DefaultTopics = []string{"question", "how-to", "problem", "feedback", "request", "chitchat", "other"}
+
+var (
+	// ErrInvalidSettings is returned for tagging settings that cannot be
+	// saved.
+	ErrInvalidSettings = errors.New("invalid message tagging settings")
+	errNoModel         = errors.New("no model configured for message tagging")
+)
+
+type taggingQueries interface {
+	GetBotMessageTagging(ctx context.Context, botID pgtype.UUID) (sqlc.BotMessageTagging, error)
+	UpsertBotMessageTagging(ctx context.Context, arg sqlc.UpsertBotMessageTaggingParams) (sqlc.BotMessageTagging, error)
+	ListEnabledBotMessageTagging(ctx context.Context) ([]sqlc.BotMessageTagging, error)
+	RecordBotMessageTaggingRun(ctx context.Context, arg sqlc.RecordBotMessageTaggingRunParams) error
+	ListUntaggedUserMessages(ctx context.Context, arg sqlc.ListUntaggedUserMessagesParams) ([]sqlc.ListUntaggedUserMessagesRow, error)
+	SetMessageClassification(ctx context.Context, arg sqlc.SetMessageClassificationParams) error
+	CountMessageSentimentsByDay(ctx context.Context, arg sqlc.CountMessageSentimentsByDayParams) ([]sqlc.CountMessageSentimentsByDayRow, error)
+	CountMessageTopics(ctx context.Context, arg sqlc.CountMessageTopicsParams) ([]sqlc.CountMessageTopicsRow, error)
+}
+
+// Settings is a bot's message tagging configuration. Without a model the
+// owner's title model is used.
+type Settings struct {
+	BotID        string     `json:"bot_id"`
+	Enabled      bool       `json:"enabled"`
+	ModelID      string     `json:"model_id,omitempty"`
+	Topics       []string   `json:"topics"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	LastTaggedAt *time.Time `json:"last_tagged_at,omitempty"`
+}
+
+// UpdateRequest changes the fields that are set. An empty model_id goes back
+// to the owner's title model and empty topics to DefaultTopics.
+type UpdateRequest struct {
+	Enabled *bool     `json:"enabled,omitempty"`
+	ModelID *string   `json:"model_id,omitempty"`
+	Topics  *[]string `json:"topics,omitempty"`
+}
+
+// Tags is what a message's metadata holds under MetadataKey. Messages
+// without text get an empty sentiment, so they are not picked up again.
+type Tags struct {
+	Sentiment string    `json:"sentiment"`
+	Topics    []string  `json:"topics"`
+	ModelID   string    `json:"model_id,omitempty"`
+	TaggedAt  time.Time `json:"tagged_at"`
+}
+
+// classifyFunc tags texts with one model call. The result holds an entry
+// for each text the model answered, keyed by its index in texts.
+type classifyFunc func(ctx context.Context, cfg sqlc.BotMessageTagging, texts []string) (map[int]Tags, error)
+
+// Service stores tagging settings, runs the tagging worker and aggregates
+// the tags.
+type Service struct {
+	queries          dbstore.Queries
+	modelsService    *models.Service
+	providersService *providers.Service
+	accountService   *accounts.Service
+	cipher           messagepkg.ContentCipher
+	classify         classifyFunc
+	logger           *slog.Logger
+	now              func() time.Time
+	// pausedUntil holds bots whose model calls failed. Only Run's goroutine
+	// touches it.
+	pausedUntil map[string]time.Time
+}
+
+// NewService creates a message tagging service. Tagging needs the model
+// services; without them enabled bots record an error.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	s := &Service{
+		queries:     queries,
+		logger:      log.With(slog.String("service", "message_tagging")),
+		now:         time.Now,
+		pausedUntil: map[string]time.Time{},
+	}
+	s.classify = s.classifyWithModel
+	return s
+}
+
+// SetModelServices sets the services used to reach the tagging model.
+func (s *Service) SetModelServices(modelsService *models.Service, providersService *providers.Service) {
+	s.modelsService = modelsService
+	s.providersService = providersService
+}
+
+// SetAccountService enables falling back to the bot owner's title model.
+func (s *Service) SetAccountService(service *accounts.Service) {
+	s.accountService = service
+}
+
+// SetContentCipher lets the worker read encrypted message content.
+func (s *Service) SetContentCipher(cipher messagepkg.ContentCipher) {
+	s.cipher = cipher
+}
+
+func (s *Service) store() (taggingQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("message tagging service not configured")
+	}
+	store, ok := s.queries.(taggingQueries)
+	if !ok {
+		return nil, errors.New("message tagging queries not supported by store")
+	}
+	return store, nil
+}
+
+// Get returns the tagging settings of a bot. Bots that never set them get
+// disabled defaults.
+func (s *Service) Get(ctx context.Context, botID string) (Settings, error) {
+	store, err := s.store()
+	if err != nil {
+		return Settings{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Settings{}, err
+	}
+	row, err := store.GetBotMessageTagging(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Settings{BotID: botID, Topics: DefaultTopics}, nil
+		}
+		return Settings{}, fmt.Errorf("get message tagging: %w", err)
+	}
+	return toSettings(row), nil
+}
+
+// Update changes the tagging settings of a bot. Only messages sent after
+// tagging was last enabled are tagged.
+func (s *Service) Update(ctx context.Context, botID string, req UpdateRequest) (Settings, error) {
+	store, err := s.store()
+	if err != nil {
+		return Settings{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Settings{}, err
+	}
+	current, err := s.Get(ctx, botID)
+	if err != nil {
+		return Settings{}, err
+	}
+	next := current
+	if req.Enabled != nil {
+		next.Enabled = *req.Enabled
+	}
+	if req.ModelID != nil {
+		next.ModelID = strings.TrimSpace(*req.ModelID)
+	}
+	if req.Topics != nil {
+		if next.Topics, err = normalizeTopics(*req.Topics); err != nil {
+			return Settings{}, err
+		}
+	}
+	modelID := pgtype.UUID{}
+	if next.ModelID != "" {
+		if modelID, err = db.ParseUUID(next.ModelID); err != nil {
+			return Settings{}, fmt.Errorf("%w: invalid model_id", ErrInvalidSettings)
+		}
+	}
+	enabledAt := pgtype.Timestamptz{}
+	switch {
+	case !next.Enabled:
+	case current.Enabled && current.EnabledAt != nil:
+		enabledAt = pgtype.Timestamptz{Time: current.EnabledAt.UTC(), Valid: true}
+	default:
+		enabledAt = pgtype.Timestamptz{Time: s.now().UTC(), Valid: true}
+	}
+	row, err := store.UpsertBotMessageTagging(ctx, sqlc.UpsertBotMessageTaggingParams{
+		BotID:     pgBotID,
+		Enabled:   next.Enabled,
+		ModelID:   modelID,
+		Topics:    storedTopics(next.Topics),
+		EnabledAt: enabledAt,
+	})
+	if err != nil {
+		return Settings{}, fmt.Errorf("save message tagging: %w", err)
+	}
+	return toSettings(row), nil
+}
+
+// normalizeTopics lowercases, trims and dedupes a taxonomy. An empty list
+// means DefaultTopics.
+func normalizeTopics(raw []string) ([]string, error) {
+	seen := map[string]bool{}
+	topics := make([]string, 0, len(raw))
+	for _, topic := range raw {
+		topic = strings.ToLower(strings.Join(strings.Fields(topic), " "))
+		if topic == "" || seen[topic] {
+			continue
+		}
+		if len([]rune(topic)) > maxTopicRunes {
+			return nil, fmt.Errorf("%w: topic %q is longer than %d characters", ErrInvalidSettings, topic, maxTopicRunes)
+		}
+		seen[topic] = true
+		topics = append(topics, topic)
+	}
+	if len(topics) > maxTopics {
+		return nil, fmt.Errorf("%w: at most %d topics", ErrInvalidSettings, maxTopics)
+	}
+	if len(topics) == 0 {
+		return DefaultTopics, nil
+	}
+	return topics, nil
+}
+
+// storedTopics keeps the default taxonomy out of the table, so bots follow
+// changes to DefaultTopics.
+func storedTopics(topics []string) []string {
+	if len(topics) == len(DefaultTopics) {
+		same := true
+		for i := range topics {
+			if topics[i] != DefaultTopics[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return []string{}
+		}
+	}
+	return topics
+}
+
+func topicsOf(row sqlc.BotMessageTagging) []string {
+	if len(row.Topics) == 0 {
+		return DefaultTopics
+	}
+	return row.Topics
+}
+
+// Run tags new user messages every sweep interval until done is closed.
+func (s *Service) Run(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.Sweep(ctx, time.Now()); err != nil {
+			s.logger.Warn("message tagging sweep failed", slog.Any("error", err))
+		} else if n > 0 {
+			s.logger.Debug("messages tagged", slog.Int("count", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep tags untagged user messages of every bot with tagging enabled and
+// returns how many were tagged. A bot whose model call fails records the
+// error and is skipped for a while.
+func (s *Service) Sweep(ctx context.Context, now time.Time) (int, error) {
+	store, err := s.store()
+	if err != nil {
+		return 0, err
+	}
+	rows, err := store.ListEnabledBotMessageTagging(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list message tagging bots: %w", err)
+	}
+	tagged := 0
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return tagged, ctx.Err()
+		}
+		botID := row.BotID.String()
+		if until, ok := s.pausedUntil[botID]; ok && now.Before(until) {
+			continue
+		}
+		n, err := s.tagBot(ctx, store, row, now)
+		tagged += n
+		run := sqlc.RecordBotMessageTaggingRunParams{BotID: row.BotID}
+		if n > 0 {
+			run.TaggedAt = pgtype.Timestamptz{Time: now.UTC(), Valid: true}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return tagged, ctx.Err()
+			}
+			s.pausedUntil[botID] = now.Add(failureBackoff)
+			run.LastError = err.Error()
+			s.logger.Warn("tag messages failed", slog.String("bot_id", botID), slog.Any("error", err))
+		} else {
+			delete(s.pausedUntil, botID)
+		}
+		if err != nil || n > 0 || row.LastError != "" {
+			if recErr := store.RecordBotMessageTaggingRun(context.WithoutCancel(ctx), run); recErr != nil {
+				s.logger.Warn("record message tagging run failed", slog.String("bot_id", botID), slog.Any("error", recErr))
+			}
+		}
+	}
+	return tagged, nil
+}
+
+func (s *Service) tagBot(ctx context.Context, store taggingQueries, row sqlc.BotMessageTagging, now time.Time) (int, error) {
+	if !row.EnabledAt.Valid {
+		return 0, nil
+	}
+	tagged := 0
+	for range maxBatchesPerBot {
+		pending, err := store.ListUntaggedUserMessages(ctx, sqlc.ListUntaggedUserMessagesParams{
+			BotID:    row.BotID,
+			Since:    row.EnabledAt,
+			RowLimit: batchSize,
+		})
+		if err != nil {
+			return tagged, fmt.Errorf("list untagged messages: %w", err)
+		}
+		if len(pending) == 0 {
+			return tagged, nil
+		}
+		n, err := s.tagBatch(ctx, store, row, pending, now)
+		tagged += n
+		if err != nil {
+			return tagged, err
+		}
+		if n == 0 || len(pending) < batchSize {
+			return tagged, nil
+		}
+	}
+	return tagged, nil
+}
+
+// tagBatch classifies one batch and stores the tags of every message the
+// model answered; unanswered messages are retried on the next sweep.
+func (s *Service) tagBatch(ctx context.Context, store taggingQueries, row sqlc.BotMessageTagging, pending []sqlc.ListUntaggedUserMessagesRow, now time.Time) (int, error) {
+	results := map[int]Tags{}
+	var texts []string
+	var indexes []int
+	for i, msg := range pending {
+		text := s.messageText(ctx, row.BotID, msg.Content)
+		if text == "" {
+			results[i] = Tags{Topics: []string{}}
+			continue
+		}
+		indexes = append(indexes, i)
+		texts = append(texts, text)
+	}
+	if len(texts) > 0 {
+		classified, err := s.classify(ctx, row, texts)
+		if err != nil {
+			return 0, err
+		}
+		for j, tags := range classified {
+			if j >= 0 && j < len(indexes) {
+				results[indexes[j]] = tags
+			}
+		}
+	}
+
+	tagged := 0
+	for i, tags := range results {
+		tags.TaggedAt = now.UTC()
+		payload, err := json.Marshal(tags)
+		if err != nil {
+			return tagged, err
+		}
+		if err := store.SetMessageClassification(ctx, sqlc.SetMessageClassificationParams{
+			Classification: payload,
+			BotID:          row.BotID,
+			ID:             pending[i].ID,
+		}); err != nil {
+			return tagged, fmt.Errorf("save message tags: %w", err)
+		}
+		tagged++
+	}
+	return tagged, nil
+}
+
+// messageText opens stored content and returns its text.
+func (s *Service) messageText(ctx context.Context, botID pgtype.UUID, stored []byte) string {
+	content := stored
+	if s.cipher != nil {
+		opened, err := s.cipher.OpenJSON(ctx, botID, stored)
+		if err != nil {
+			s.logger.Warn("message tagging: open message content failed", slog.Any("error", err))
+			return ""
+		}
+		content = opened
+	}
+	var doc struct {
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return ""
+	}
+	return contentText(doc.Content)
+}
+
+// contentText joins the text of string content or of its text parts.
+func contentText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	lines := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == "text" && strings.TrimSpace(part.Text) != "" {
+			lines = append(lines, strings.TrimSpace(part.Text))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func timePtr(ts pgtype.Timestamptz) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+func toSettings(row sqlc.BotMessageTagging) Settings {
+	settings := Settings{
+		BotID:        row.BotID.String(),
+		Enabled:      row.Enabled,
+		Topics:       topicsOf(row),
+		EnabledAt:    timePtr(row.EnabledAt),
+		LastError:    row.LastError,
+		LastTaggedAt: timePtr(row.LastTaggedAt),
+	}
+	if row.ModelID.Valid {
+		settings.ModelID = row.ModelID.String()
+	}
+	return settings
+}
@@ -0,0 +1,236 @@
+package tagging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+type fakeTaggingQueries struct {
+	dbstore.Queries
+
+	enabled    []sqlc.BotMessageTagging
+	pending    []sqlc.ListUntaggedUserMessagesRow
+	tagged     map[string]Tags
+	runs       []sqlc.RecordBotMessageTaggingRunParams
+	sentiments []sqlc.CountMessageSentimentsByDayRow
+	topics     []sqlc.CountMessageTopicsRow
+}
+
+func (*fakeTaggingQueries) GetBotMessageTagging(context.Context, pgtype.UUID) (sqlc.BotMessageTagging, error) {
+	return sqlc.BotMessageTagging{}, nil
+}
+
+func (*fakeTaggingQueries) UpsertBotMessageTagging(_ context.Context, arg sqlc.UpsertBotMessageTaggingParams) (sqlc.BotMessageTagging, error) {
+	return sqlc.BotMessageTagging{BotID: arg.BotID, Enabled: arg.Enabled, ModelID: arg.ModelID, Topics: arg.Topics, EnabledAt: arg.EnabledAt}, nil
+}
+
+func (f *fakeTaggingQueries) ListEnabledBotMessageTagging(context.Context) ([]sqlc.BotMessageTagging, error) {
+	return f.enabled, nil
+}
+
+func (f *fakeTaggingQueries) RecordBotMessageTaggingRun(_ context.Context, arg sqlc.RecordBotMessageTaggingRunParams) error {
+	f.runs = append(f.runs, arg)
+	return nil
+}
+
+func (f *fakeTaggingQueries) ListUntaggedUserMessages(_ context.Context, arg sqlc.ListUntaggedUserMessagesParams) ([]sqlc.ListUntaggedUserMessagesRow, error) {
+	var rows []sqlc.ListUntaggedUserMessagesRow
+	for _, row := range f.pending {
+		if _, ok := f.tagged[row.ID.String()]; !ok && len(rows) < int(arg.RowLimit) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeTaggingQueries) SetMessageClassification(_ context.Context, arg sqlc.SetMessageClassificationParams) error {
+	var tags Tags
+	if err := json.Unmarshal(arg.Classification, &tags); err != nil {
+		return err
+	}
+	f.tagged[arg.ID.String()] = tags
+	return nil
+}
+
+func (f *fakeTaggingQueries) CountMessageSentimentsByDay(context.Context, sqlc.CountMessageSentimentsByDayParams) ([]sqlc.CountMessageSentimentsByDayRow, error) {
+	return f.sentiments, nil
+}
+
+func (f *fakeTaggingQueries) CountMessageTopics(context.Context, sqlc.CountMessageTopicsParams) ([]sqlc.CountMessageTopicsRow, error) {
+	return f.topics, nil
+}
+
+func testUUID(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{15: b}, Valid: true}
+}
+
+func userMessage(id byte, text string) sqlc.ListUntaggedUserMessagesRow {
+	content, _ := json.Marshal(map[string]any{"role": "user", "content": text})
+	return sqlc.ListUntaggedUserMessagesRow{ID: testUUID(id), Content: content}
+}
+
+func TestSweepTagsAnsweredMessages(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	queries := &fakeTaggingQueries{
+		enabled: []sqlc.BotMessageTagging{{
+			BotID:     testUUID(1),
+			Enabled:   true,
+			EnabledAt: pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true},
+		}},
+		pending: []sqlc.ListUntaggedUserMessagesRow{
+			userMessage(10, "How do I reset my password?"),
+			userMessage(11, "   "),
+			userMessage(12, "Thanks, that worked!"),
+			userMessage(13, "The model skips this one"),
+		},
+		tagged: map[string]Tags{},
+	}
+	svc := NewService(nil, queries)
+	var calls [][]string
+	svc.classify = func(_ context.Context, _ sqlc.BotMessageTagging, texts []string) (map[int]Tags, error) {
+		calls = append(calls, texts)
+		if len(calls) > 1 {
+			return nil, errors.New("model down")
+		}
+		return map[int]Tags{
+			0: {Sentiment: SentimentNeutral, Topics: []string{"how-to"}},
+			1: {Sentiment: SentimentPositive, Topics: []string{"feedback"}},
+		}, nil
+	}
+
+	n, err := svc.Sweep(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("tagged = %d, want 3", n)
+	}
+	if len(calls) != 1 || len(calls[0]) != 3 {
+		t.Fatalf("classify calls = %q, want one call without the empty message", calls)
+	}
+	if got := queries.tagged[testUUID(10).String()]; got.Sentiment != SentimentNeutral || got.Topics[0] != "how-to" || !got.TaggedAt.Equal(now) {
+		t.Fatalf("message 10 tags = %+v", got)
+	}
+	if got := queries.tagged[testUUID(12).String()]; got.Sentiment != SentimentPositive {
+		t.Fatalf("message 12 tags = %+v", got)
+	}
+	if got, ok := queries.tagged[testUUID(11).String()]; !ok || got.Sentiment != "" {
+		t.Fatalf("empty message tags = %+v, %v, want an empty sentiment", got, ok)
+	}
+	if _, ok := queries.tagged[testUUID(13).String()]; ok {
+		t.Fatal("unanswered message was tagged")
+	}
+	if len(queries.runs) != 1 || queries.runs[0].LastError != "" || !queries.runs[0].TaggedAt.Valid {
+		t.Fatalf("runs = %+v", queries.runs)
+	}
+
+	// A failing model records the error and pauses the bot.
+	if _, err := svc.Sweep(context.Background(), now.Add(time.Minute)); err != nil {
+		t.Fatalf("second Sweep: %v", err)
+	}
+	if len(queries.runs) != 2 || queries.runs[1].LastError != "model down" {
+		t.Fatalf("runs = %+v, want the model error recorded", queries.runs)
+	}
+	if _, err := svc.Sweep(context.Background(), now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("third Sweep: %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("classify calls = %d, want the paused bot skipped", len(calls))
+	}
+}
+
+func TestParseClassificationKeepsTaxonomy(t *testing.T) {
+	t.Parallel()
+
+	reply := "```json\n" + `[
+		{"id": 0, "sentiment": "Negative", "topics": ["problem", "billing", "PROBLEM"]},
+		{"id": 1, "sentiment": "angry", "topics": ["problem"]},
+		{"id": 7, "sentiment": "neutral", "topics": []}
+	]` + "\n```"
+	got, err := parseClassification(reply, 2, DefaultTopics)
+	if err != nil {
+		t.Fatalf("parseClassification: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("results = %+v, want only message 0", got)
+	}
+	if tags := got[0]; tags.Sentiment != SentimentNegative || len(tags.Topics) != 1 || tags.Topics[0] != "problem" {
+		t.Fatalf("message 0 tags = %+v", tags)
+	}
+	if _, err := parseClassification("I cannot help with that.", 1, DefaultTopics); err == nil {
+		t.Fatal("parseClassification accepted a reply without JSON")
+	}
+}
+
+func TestUpdateNormalizesTopics(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	svc := NewService(nil, &fakeTaggingQueries{})
+	svc.now = func() time.Time { return now }
+	enabled := true
+	topics := []string{" Billing ", "billing", "Account   access", ""}
+	got, err := svc.Update(context.Background(), testUUID(1).String(), UpdateRequest{Enabled: &enabled, Topics: &topics})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(got.Topics) != 2 || got.Topics[0] != "billing" || got.Topics[1] != "account access" {
+		t.Fatalf("topics = %q", got.Topics)
+	}
+	if got.EnabledAt == nil || !got.EnabledAt.Equal(now) {
+		t.Fatalf("enabled_at = %v, want %s", got.EnabledAt, now)
+	}
+
+	long := []string{"a topic name that is far too long to be a coarse topic"}
+	if _, err := svc.Update(context.Background(), testUUID(1).String(), UpdateRequest{Topics: &long}); !errors.Is(err, ErrInvalidSettings) {
+		t.Fatalf("Update with long topic = %v, want ErrInvalidSettings", err)
+	}
+	empty := []string{}
+	got, err = svc.Update(context.Background(), testUUID(1).String(), UpdateRequest{Topics: &empty})
+	if err != nil || len(got.Topics) != len(DefaultTopics) {
+		t.Fatalf("Update with no topics = %q, %v, want the defaults", got.Topics, err)
+	}
+}
+
+func TestAnalyticsFillsEveryDay(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 9, 15, 0, 0, 0, time.UTC)
+	queries := &fakeTaggingQueries{
+		sentiments: []sqlc.CountMessageSentimentsByDayRow{
+			{Day: pgtype.Date{Time: time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC), Valid: true}, Sentiment: SentimentNegative, Messages: 2},
+			{Day: pgtype.Date{Time: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), Valid: true}, Sentiment: SentimentPositive, Messages: 5},
+			{Day: pgtype.Date{Time: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), Valid: true}, Sentiment: SentimentNeutral, Messages: 1},
+		},
+		topics: []sqlc.CountMessageTopicsRow{{Topic: "question", Messages: 4}},
+	}
+	svc := NewService(nil, queries)
+	svc.now = func() time.Time { return now }
+
+	got, err := svc.Analytics(context.Background(), testUUID(1).String(), 3)
+	if err != nil {
+		t.Fatalf("Analytics: %v", err)
+	}
+	if len(got.Daily) != 3 || got.Daily[0].Day != "2026-03-07" || got.Daily[2].Day != "2026-03-09" {
+		t.Fatalf("daily = %+v", got.Daily)
+	}
+	if got.Daily[0].Negative != 2 || got.Daily[1] != (DailyCount{Day: "2026-03-08"}) || got.Daily[2].Positive != 5 {
+		t.Fatalf("daily = %+v", got.Daily)
+	}
+	if got.Tagged != 8 || got.Sentiments != (SentimentCount{Positive: 5, Neutral: 1, Negative: 2}) {
+		t.Fatalf("totals = %d %+v", got.Tagged, got.Sentiments)
+	}
+	if !got.To.Equal(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)) || len(got.Topics) != 1 {
+		t.Fatalf("analytics = %+v", got)
+	}
+}
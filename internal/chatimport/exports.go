@@ -0,0 +1,308 @@
+// Package chatimport backfills a bot's conversation history from chat
+// exports made by messaging apps, so a new bot starts with the context of
+// conversations that happened before it joined.
+package chatimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats.
+const (
+	FormatTelegram = "telegram"
+	FormatWhatsApp = "whatsapp"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported export format")
+	ErrInvalidExport     = errors.New("invalid export")
+)
+
+// Export is a parsed chat export.
+type Export struct {
+	Platform         string
+	ConversationID   string
+	ConversationName string
+	ConversationType string
+	Messages         []Message
+}
+
+// Message is one message of an export.
+type Message struct {
+	ExternalID string
+	Sender     string
+	SenderID   string
+	Text       string
+	SentAt     time.Time
+}
+
+// Parse reads an export in the given format. loc is the time zone of
+// timestamps the export writes without one (WhatsApp); nil means UTC.
+func Parse(format string, data []byte, loc *time.Location) (Export, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatTelegram:
+		return ParseTelegram(data)
+	case FormatWhatsApp:
+		return ParseWhatsApp(data, loc)
+	default:
+		return Export{}, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+type telegramExport struct {
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	ID       int64             `json:"id"`
+	Messages []telegramMessage `json:"messages"`
+	Chats    *json.RawMessage  `json:"chats"`
+}
+
+type telegramMessage struct {
+	ID           int64           `json:"id"`
+	Type         string          `json:"type"`
+	DateUnixtime string          `json:"date_unixtime"`
+	Date         string          `json:"date"`
+	From         string          `json:"from"`
+	FromID       string          `json:"from_id"`
+	Text         json.RawMessage `json:"text"`
+}
+
+// ParseTelegram reads the result.json of a single chat exported from
+// Telegram Desktop. Service messages (joins, pins, calls) are skipped.
+func ParseTelegram(data []byte) (Export, error) {
+	var raw telegramExport
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Export{}, fmt.Errorf("%w: %s", ErrInvalidExport, err.Error())
+	}
+	if raw.Chats != nil && len(raw.Messages) == 0 {
+		return Export{}, fmt.Errorf("%w: full account exports are not supported, export a single chat", ErrInvalidExport)
+	}
+	out := Export{
+		Platform:         FormatTelegram,
+		ConversationID:   telegramChatID(raw.Type, raw.ID),
+		ConversationName: strings.TrimSpace(raw.Name),
+		ConversationType: telegramConversationType(raw.Type),
+	}
+	for _, m := range raw.Messages {
+		if m.Type != "message" {
+			continue
+		}
+		text := strings.TrimSpace(telegramText(m.Text))
+		if text == "" {
+			continue
+		}
+		sentAt, err := telegramTime(m)
+		if err != nil {
+			return Export{}, fmt.Errorf("%w: message %d: %s", ErrInvalidExport, m.ID, err.Error())
+		}
+		out.Messages = append(out.Messages, Message{
+			ExternalID: strconv.FormatInt(m.ID, 10),
+			Sender:     strings.TrimSpace(m.From),
+			SenderID:   strings.TrimPrefix(strings.TrimSpace(m.FromID), "user"),
+			Text:       text,
+			SentAt:     sentAt,
+		})
+	}
+	return out, nil
+}
+
+// telegramChatID converts the export's chat ID to the Bot API chat ID the
+// Telegram adapter routes by, so imported history joins existing routes.
+func telegramChatID(chatType string, id int64) string {
+	if id == 0 {
+		return ""
+	}
+	switch chatType {
+	case "private_supergroup", "public_supergroup", "private_channel", "public_channel":
+		return "-100" + strconv.FormatInt(id, 10)
+	case "private_group":
+		return "-" + strconv.FormatInt(id, 10)
+	default:
+		return strconv.FormatInt(id, 10)
+	}
+}
+
+func telegramConversationType(chatType string) string {
+	switch chatType {
+	case "personal_chat", "bot_chat", "saved_messages":
+		return "private"
+	case "private_channel", "public_channel":
+		return "channel"
+	default:
+		return "group"
+	}
+}
+
+// telegramText flattens the text field, which is either a string or a list
+// of strings and formatted entities.
+func telegramText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var plain string
+	if err := json.Unmarshal(raw, &plain); err == nil {
+		return plain
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, item := range items {
+		var s string
+		if err := json.Unmarshal(item, &s); err == nil {
+			b.WriteString(s)
+			continue
+		}
+		var entity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(item, &entity); err == nil {
+			b.WriteString(entity.Text)
+		}
+	}
+	return b.String()
+}
+
+func telegramTime(m telegramMessage) (time.Time, error) {
+	if m.DateUnixtime != "" {
+		sec, err := strconv.ParseInt(m.DateUnixtime, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date_unixtime %q", m.DateUnixtime)
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	// Older exports only carry the local time of the exporting device.
+	t, err := time.Parse("2006-01-02T15:04:05", m.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", m.Date)
+	}
+	return t, nil
+}
+
+// whatsAppLine matches the first line of a message in both the Android
+// ("12/31/22, 10:15 PM - Alice: hi") and iOS ("[31/12/2022, 22:15:03]
+// Alice: hi") export layouts.
+var whatsAppLine = regexp.MustCompile(`^\[?(\d{1,2})[/.](\d{1,2})[/.](\d{2,4}),? (\d{1,2}):(\d{2})(?::(\d{2}))? ?([AaPp]\.? ?[Mm]\.?)?\]?(?: -)? (.*)$`)
+
+var (
+	// whatsAppInvisible drops the direction marks iOS exports insert and
+	// turns the no-break spaces around AM/PM into plain spaces.
+	whatsAppInvisible = strings.NewReplacer("\u200e", "", "\u200f", "", "\u202f", " ", "\u00a0", " ")
+	whatsAppMeridiem  = strings.NewReplacer(".", "", " ", "")
+)
+
+// whatsAppOmitted are the placeholders exports write for attachments.
+var whatsAppOmitted = map[string]struct{}{
+	"<Media omitted>": {},
+	"image omitted":   {},
+	"video omitted":   {},
+	"audio omitted":   {},
+	"sticker omitted": {},
+	"GIF omitted":     {},
+}
+
+type whatsAppEntry struct {
+	fields [6]int
+	pm     string
+	body   string
+}
+
+// ParseWhatsApp reads a chat exported from WhatsApp as text. Day-first and
+// month-first dates are told apart from the export as a whole; system
+// notices and attachment placeholders are skipped.
+func ParseWhatsApp(data []byte, loc *time.Location) (Export, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var entries []whatsAppEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(whatsAppInvisible.Replace(scanner.Text()), "\r")
+		match := whatsAppLine.FindStringSubmatch(line)
+		if match == nil {
+			if n := len(entries); n > 0 {
+				entries[n-1].body += "\n" + line
+			}
+			continue
+		}
+		entry := whatsAppEntry{pm: strings.ToLower(whatsAppMeridiem.Replace(match[7])), body: match[8]}
+		for i := range 6 {
+			entry.fields[i], _ = strconv.Atoi(match[i+1])
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return Export{}, fmt.Errorf("%w: %s", ErrInvalidExport, err.Error())
+	}
+	if len(entries) == 0 {
+		return Export{}, fmt.Errorf("%w: no WhatsApp messages found", ErrInvalidExport)
+	}
+	dayFirst := false
+	for _, e := range entries {
+		if e.fields[0] > 12 {
+			dayFirst = true
+			break
+		}
+	}
+	out := Export{Platform: FormatWhatsApp}
+	senders := map[string]struct{}{}
+	for _, e := range entries {
+		sender, text, ok := strings.Cut(e.body, ": ")
+		text = strings.TrimSpace(text)
+		if !ok || text == "" {
+			continue
+		}
+		if _, omitted := whatsAppOmitted[text]; omitted {
+			continue
+		}
+		sentAt, err := whatsAppTime(e, dayFirst, loc)
+		if err != nil {
+			return Export{}, fmt.Errorf("%w: %s", ErrInvalidExport, err.Error())
+		}
+		sender = strings.TrimSpace(sender)
+		senders[sender] = struct{}{}
+		out.Messages = append(out.Messages, Message{Sender: sender, Text: text, SentAt: sentAt})
+	}
+	out.ConversationType = "private"
+	if len(senders) > 2 {
+		out.ConversationType = "group"
+	}
+	sort.SliceStable(out.Messages, func(i, j int) bool { return out.Messages[i].SentAt.Before(out.Messages[j].SentAt) })
+	return out, nil
+}
+
+func whatsAppTime(e whatsAppEntry, dayFirst bool, loc *time.Location) (time.Time, error) {
+	month, day := e.fields[0], e.fields[1]
+	if dayFirst {
+		day, month = month, day
+	}
+	year, hour := e.fields[2], e.fields[3]
+	if year < 100 {
+		year += 2000
+	}
+	switch e.pm {
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	}
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || e.fields[4] > 59 || e.fields[5] > 59 {
+		return time.Time{}, fmt.Errorf("invalid timestamp %d/%d/%d %d:%02d", e.fields[0], e.fields[1], e.fields[2], e.fields[3], e.fields[4])
+	}
+	return time.Date(year, time.Month(month), day, hour, e.fields[4], e.fields[5], 0, loc).UTC(), nil
+}
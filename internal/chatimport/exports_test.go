@@ -0,0 +1,122 @@
+package chatimport
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseTelegramSingleChat(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+  "name": "Weekend Trip",
+  "type": "private_supergroup",
+  "id": 1234567890,
+  "messages": [
+    {"id": 1, "type": "service", "date": "2024-03-01T09:00:00", "date_unixtime": "1709283600", "actor": "Alice", "action": "create_group", "text": ""},
+    {"id": 2, "type": "message", "date": "2024-03-01T09:01:00", "date_unixtime": "1709283660", "from": "Alice", "from_id": "user42", "text": "Who books the cabin?"},
+    {"id": 3, "type": "message", "date": "2024-03-01T09:02:00", "date_unixtime": "1709283720", "from": "Bob", "from_id": "user43", "text": ["I will, see ", {"type": "link", "text": "https://example.com"}]},
+    {"id": 4, "type": "message", "date": "2024-03-01T09:03:00", "date_unixtime": "1709283780", "from": "Bob", "from_id": "user43", "text": "", "photo": "photos/1.jpg"}
+  ]
+}`)
+	export, err := ParseTelegram(data)
+	if err != nil {
+		t.Fatalf("ParseTelegram returned error: %v", err)
+	}
+	if export.ConversationID != "-1001234567890" || export.ConversationType != "group" || export.ConversationName != "Weekend Trip" {
+		t.Fatalf("unexpected conversation: %+v", export)
+	}
+	if len(export.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(export.Messages))
+	}
+	first, second := export.Messages[0], export.Messages[1]
+	if first.ExternalID != "2" || first.SenderID != "42" || first.Sender != "Alice" {
+		t.Fatalf("unexpected first message: %+v", first)
+	}
+	if !first.SentAt.Equal(time.Unix(1709283660, 0)) {
+		t.Fatalf("unexpected first timestamp: %v", first.SentAt)
+	}
+	if second.Text != "I will, see https://example.com" {
+		t.Fatalf("unexpected flattened text: %q", second.Text)
+	}
+}
+
+func TestParseTelegramRejectsAccountExport(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseTelegram([]byte(`{"about": "", "chats": {"list": []}}`))
+	if !errors.Is(err, ErrInvalidExport) {
+		t.Fatalf("expected ErrInvalidExport, got %v", err)
+	}
+}
+
+func TestParseWhatsAppAndroid(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("12/31/22, 10:15 PM - Messages and calls are end-to-end encrypted.\n" +
+		"12/31/22, 10:15 PM - Alice: Happy new year\n" +
+		"almost!\n" +
+		"12/31/22, 10:16 PM - Bob: <Media omitted>\n" +
+		"1/1/23, 12:01 AM - Bob: Happy new year!\n")
+	export, err := ParseWhatsApp(data, time.UTC)
+	if err != nil {
+		t.Fatalf("ParseWhatsApp returned error: %v", err)
+	}
+	if len(export.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d: %+v", len(export.Messages), export.Messages)
+	}
+	if export.ConversationType != "private" {
+		t.Fatalf("expected private conversation, got %q", export.ConversationType)
+	}
+	first := export.Messages[0]
+	if first.Sender != "Alice" || first.Text != "Happy new year\nalmost!" {
+		t.Fatalf("unexpected first message: %+v", first)
+	}
+	if want := time.Date(2022, 12, 31, 22, 15, 0, 0, time.UTC); !first.SentAt.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, first.SentAt)
+	}
+	if want := time.Date(2023, 1, 1, 0, 1, 0, 0, time.UTC); !export.Messages[1].SentAt.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, export.Messages[1].SentAt)
+	}
+}
+
+func TestParseWhatsAppIOSDayFirst(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("CET", 3600)
+	data := []byte("[03/02/2023, 09:05:03] Alice: Morning\r\n" +
+		"[\u200e13/02/2023, 18:30:00] Bob: \u200eimage omitted\r\n" +
+		"[13/02/2023, 18:31:10] Carol: Dinner at 8?\r\n")
+	export, err := ParseWhatsApp(data, loc)
+	if err != nil {
+		t.Fatalf("ParseWhatsApp returned error: %v", err)
+	}
+	if len(export.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d: %+v", len(export.Messages), export.Messages)
+	}
+	if want := time.Date(2023, 2, 3, 9, 5, 3, 0, loc); !export.Messages[0].SentAt.Equal(want) {
+		t.Fatalf("expected day-first %v, got %v", want, export.Messages[0].SentAt)
+	}
+	if export.Messages[1].Sender != "Carol" {
+		t.Fatalf("unexpected second sender: %q", export.Messages[1].Sender)
+	}
+}
+
+func TestParseWhatsAppRejectsNonExport(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseWhatsApp([]byte("just some notes\n"), nil)
+	if !errors.Is(err, ErrInvalidExport) {
+		t.Fatalf("expected ErrInvalidExport, got %v", err)
+	}
+}
+
+func TestParseUnsupportedFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse("signal", nil, nil)
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
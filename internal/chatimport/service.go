@@ -0,0 +1,236 @@
+package chatimport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/agent/turn"
+	"github.com/memohai/memoh/internal/channel/identities"
+	"github.com/memohai/memoh/internal/channel/route"
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
+	sessionpkg "github.com/memohai/memoh/internal/chat/thread"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+const (
+	// MaxMessages caps one import; split larger histories into several
+	// exports.
+	MaxMessages = 20000
+	// memoryBatchSize is the number of messages handed to memory
+	// extraction at a time, roughly one conversation round's worth.
+	memoryBatchSize = 40
+)
+
+var (
+	ErrEmptyExport          = errors.New("export contains no messages")
+	ErrTooManyMessages      = fmt.Errorf("export has more than %d messages", MaxMessages)
+	ErrConversationRequired = errors.New("conversation id is required for this export")
+)
+
+// SessionEnsurer returns the active session of a route, creating one when
+// the route has none.
+type SessionEnsurer interface {
+	EnsureActive(ctx context.Context, botID, routeID, channelType string) (sessionpkg.Thread, error)
+}
+
+// IdentityUpserter records the channel identities of imported senders.
+type IdentityUpserter interface {
+	UpsertChannelIdentity(ctx context.Context, channel, channelSubjectID, displayName string, metadata map[string]any) (identities.ChannelIdentity, error)
+}
+
+// Options tune one import.
+type Options struct {
+	// ConversationID overrides the conversation the export belongs to. It
+	// is required for WhatsApp exports, which carry no chat ID.
+	ConversationID string
+	// ExtractMemory runs the bot's memory provider over the imported
+	// messages after they are stored.
+	ExtractMemory bool
+}
+
+// Result summarizes an import.
+type Result struct {
+	RouteID          string   `json:"route_id"`
+	SessionID        string   `json:"session_id"`
+	Imported         int      `json:"imported"`
+	MemoryBatches    int      `json:"memory_batches,omitempty"`
+	MemoryExtraction bool     `json:"memory_extraction"`
+	Errors           []string `json:"errors,omitempty"`
+}
+
+// Service backfills chat exports into bot routes.
+type Service struct {
+	routes     route.Resolver
+	sessions   SessionEnsurer
+	messages   messagepkg.Writer
+	identities IdentityUpserter
+	logger     *slog.Logger
+
+	memoryRegistry  *memprovider.Registry
+	settingsService *settings.Service
+}
+
+// NewService creates a chat import service. identities may be nil, in
+// which case imported messages are stored without sender identities.
+func NewService(log *slog.Logger, routes route.Resolver, sessions SessionEnsurer, messages messagepkg.Writer, identities IdentityUpserter) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		routes:     routes,
+		sessions:   sessions,
+		messages:   messages,
+		identities: identities,
+		logger:     log.With(slog.String("service", "chatimport")),
+	}
+}
+
+func (s *Service) SetMemoryRegistry(registry *memprovider.Registry) {
+	s.memoryRegistry = registry
+}
+
+func (s *Service) SetSettingsService(svc *settings.Service) {
+	s.settingsService = svc
+}
+
+// Import stores the export's messages in the active session of the route
+// for its conversation, oldest first, as passive user messages stamped
+// with their original send time. Importing the same export twice stores
+// its messages twice.
+func (s *Service) Import(ctx context.Context, botID string, export Export, opts Options) (Result, error) {
+	botID = strings.TrimSpace(botID)
+	if len(export.Messages) == 0 {
+		return Result{}, ErrEmptyExport
+	}
+	if len(export.Messages) > MaxMessages {
+		return Result{}, ErrTooManyMessages
+	}
+	conversationID := strings.TrimSpace(opts.ConversationID)
+	if conversationID == "" {
+		conversationID = strings.TrimSpace(export.ConversationID)
+	}
+	if conversationID == "" {
+		return Result{}, ErrConversationRequired
+	}
+	metadata := map[string]any{"imported_from": export.Platform}
+	if export.ConversationName != "" {
+		metadata["conversation_name"] = export.ConversationName
+	}
+	resolved, err := s.routes.ResolveConversation(ctx, route.ResolveInput{
+		BotID:                  botID,
+		Platform:               export.Platform,
+		ExternalConversationID: conversationID,
+		ConversationType:       export.ConversationType,
+		Metadata:               metadata,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("resolve route: %w", err)
+	}
+	session, err := s.sessions.EnsureActive(ctx, botID, resolved.RouteID, export.Platform)
+	if err != nil {
+		return Result{}, fmt.Errorf("ensure session: %w", err)
+	}
+	result := Result{RouteID: resolved.RouteID, SessionID: session.ID}
+
+	senderIdentities := map[string]string{}
+	for _, msg := range export.Messages {
+		identityID := s.senderIdentity(ctx, export.Platform, msg, senderIdentities)
+		if err := s.persist(ctx, botID, session.ID, resolved.RouteID, conversationID, export, msg, identityID); err != nil {
+			return result, fmt.Errorf("store message %d: %w", result.Imported+1, err)
+		}
+		result.Imported++
+	}
+
+	if opts.ExtractMemory {
+		result.MemoryExtraction = true
+		s.extractMemory(ctx, botID, export, &result)
+	}
+	return result, nil
+}
+
+// senderIdentity returns the channel identity of the message sender, when
+// the export identifies senders and identities are recorded.
+func (s *Service) senderIdentity(ctx context.Context, platform string, msg Message, cache map[string]string) string {
+	if s.identities == nil || msg.SenderID == "" {
+		return ""
+	}
+	if id, ok := cache[msg.SenderID]; ok {
+		return id
+	}
+	identity, err := s.identities.UpsertChannelIdentity(ctx, platform, msg.SenderID, msg.Sender, nil)
+	if err != nil {
+		s.logger.Warn("record imported sender failed", slog.String("platform", platform), slog.Any("error", err))
+	}
+	cache[msg.SenderID] = identity.ID
+	return identity.ID
+}
+
+func (s *Service) persist(ctx context.Context, botID, sessionID, routeID, conversationID string, export Export, msg Message, identityID string) error {
+	text := turn.FormatUserHeader(turn.UserMessageHeaderInput{
+		MessageID:         msg.ExternalID,
+		ChannelIdentityID: identityID,
+		DisplayName:       msg.Sender,
+		Channel:           export.Platform,
+		ConversationType:  export.ConversationType,
+		ConversationName:  export.ConversationName,
+		Target:            conversationID,
+		Time:              msg.SentAt,
+	}, msg.Text)
+	content, err := json.Marshal(turn.ModelMessage{Role: "user", Content: turn.NewTextContent(text)})
+	if err != nil {
+		return err
+	}
+	_, err = s.messages.Persist(ctx, messagepkg.PersistInput{
+		BotID:                   botID,
+		SessionID:               sessionID,
+		SenderChannelIdentityID: identityID,
+		ExternalMessageID:       msg.ExternalID,
+		Role:                    "user",
+		Content:                 content,
+		Metadata: map[string]any{
+			"route_id":      routeID,
+			"platform":      export.Platform,
+			"imported":      true,
+			"sent_at":       msg.SentAt.UTC().Format(time.RFC3339),
+			"sender_name":   msg.Sender,
+			"imported_from": export.Platform,
+		},
+		DisplayText: msg.Text,
+	})
+	return err
+}
+
+// extractMemory hands the imported messages to the bot's memory provider
+// in batches. Failed batches are reported and do not undo the import.
+func (s *Service) extractMemory(ctx context.Context, botID string, export Export, result *Result) {
+	provider, err := memprovider.ResolveBotProvider(ctx, s.memoryRegistry, s.settingsService, botID)
+	if err != nil {
+		result.Errors = append(result.Errors, "memory extraction: "+err.Error())
+		return
+	}
+	for start := 0; start < len(export.Messages); start += memoryBatchSize {
+		end := min(start+memoryBatchSize, len(export.Messages))
+		batch := make([]memprovider.Message, 0, end-start)
+		for _, msg := range export.Messages[start:end] {
+			batch = append(batch, memprovider.Message{
+				Role:    "user",
+				Content: fmt.Sprintf("[%s] %s: %s", msg.SentAt.UTC().Format("2006-01-02 15:04"), msg.Sender, msg.Text),
+			})
+		}
+		if err := provider.OnAfterChat(ctx, memprovider.AfterChatRequest{BotID: botID, Messages: batch}); err != nil {
+			s.logger.Warn("imported history memory extraction failed", slog.String("bot_id", botID), slog.Any("error", err))
+			result.Errors = append(result.Errors, fmt.Sprintf("memory extraction for messages %d-%d: %s", start+1, end, err.Error()))
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		result.MemoryBatches++
+	}
+}
@@ -24,6 +24,7 @@ const (
 	callbackKindRange         = "range"
 	callbackKindConfirmNew    = "confirm_new"
 	callbackKindSkillActivate = "skill_activate"
+	callbackKindMemoryReview  = "memory_review"
 	callbackKindDismiss       = "dismiss"
 	callbackKindNoop          = "noop"
 )
@@ -55,6 +56,11 @@ func (p ParsedCallback) IsDismiss() bool { return p.Kind == callbackKindDismiss
 // card in place.
 func (p ParsedCallback) IsSkillActivation() bool { return p.Kind == callbackKindSkillActivate }
 
+// IsMemoryReview reports whether the callback keeps or forgets a memory from
+// a memory review digest. Like skill activation, the reply goes out as a new
+// message so the digest keeps its buttons for the remaining memories.
+func (p ParsedCallback) IsMemoryReview() bool { return p.Kind == callbackKindMemoryReview }
+
 // IsNoop reports whether the callback is inert (e.g. the page indicator).
 func (p ParsedCallback) IsNoop() bool { return p.Kind == callbackKindNoop }
 
@@ -147,6 +153,30 @@ func EncodeConfirmNewCallback(mode string) string {
 	return fmt.Sprintf("%scn~%s", callbackNamespace, mode)
 }
 
+// Memory review actions carried by EncodeMemoryReviewCallback.
+const (
+	MemoryReviewKeep   = "keep"
+	MemoryReviewForget = "forget"
+)
+
+// EncodeMemoryReviewCallback builds the callback_data for a keep/forget
+// button on a memory review digest. Layout: "m~mr~{k|f}~{localID}", where
+// localID is the memory ID without its "{botID}:" prefix. Tapping
+// re-dispatches "/memory keep|forget {localID}". IDs too long for Telegram's
+// 64-byte limit are stashed like list args ("#<hash>").
+func EncodeMemoryReviewCallback(action, localID string) string {
+	code := "k"
+	if action == MemoryReviewForget {
+		code = "f"
+	}
+	base := callbackNamespace + "mr~" + code + "~"
+	token := url.QueryEscape(strings.TrimSpace(localID))
+	if encoded := base + token; len(encoded) <= telegramCallbackLimit {
+		return encoded
+	}
+	return base + "#" + stashArgs(token)
+}
+
 // DecodeCallback parses an interactive callback_data string. The bool is false
 // for data that is not one of our interactive callbacks.
 func DecodeCallback(data string) (ParsedCallback, bool) {
@@ -224,6 +254,32 @@ func DecodeCallback(data string) (ParsedCallback, bool) {
 			return ParsedCallback{}, false
 		}
 		return ParsedCallback{Kind: callbackKindSkillActivate, SelectID: strings.TrimSpace(name)}, true
+	case strings.HasPrefix(body, "mr~"):
+		parts := strings.SplitN(strings.TrimPrefix(body, "mr~"), "~", 2)
+		if len(parts) != 2 {
+			return ParsedCallback{}, false
+		}
+		var action string
+		switch parts[0] {
+		case "k":
+			action = MemoryReviewKeep
+		case "f":
+			action = MemoryReviewForget
+		default:
+			return ParsedCallback{}, false
+		}
+		token := parts[1]
+		if strings.HasPrefix(token, "#") {
+			hash := strings.TrimPrefix(token, "#")
+			argsStashMu.Lock()
+			token = argsStash[hash]
+			argsStashMu.Unlock()
+		}
+		id, err := url.QueryUnescape(token)
+		if err != nil || strings.TrimSpace(id) == "" {
+			return ParsedCallback{}, false
+		}
+		return ParsedCallback{Kind: callbackKindMemoryReview, Action: action, SelectID: strings.TrimSpace(id)}, true
 	}
 	return ParsedCallback{}, false
 }
@@ -252,6 +308,8 @@ func (p ParsedCallback) SyntheticCommand() string {
 			return ""
 		}
 		return "/" + name
+	case callbackKindMemoryReview:
+		return fmt.Sprintf("/memory %s %s", p.Action, p.SelectID)
 	default:
 		return ""
 	}
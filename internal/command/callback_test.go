@@ -323,3 +323,27 @@ func TestSkillActivateCallbackStashMissInvalidates(t *testing.T) {
 		}
 	}
 }
+
+func TestMemoryReviewCallbackRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		action  string
+		localID string
+		want    string
+	}{
+		{MemoryReviewKeep, "mem_1712345678901234567_3", "/memory keep mem_1712345678901234567_3"},
+		{MemoryReviewForget, "mem_1712345678901234567_3", "/memory forget mem_1712345678901234567_3"},
+		{MemoryReviewForget, strings.Repeat("x", 80), "/memory forget " + strings.Repeat("x", 80)},
+	} {
+		data := EncodeMemoryReviewCallback(tt.action, tt.localID)
+		if len(data) > telegramCallbackLimit {
+			t.Fatalf("callback_data %q exceeds %d bytes", data, telegramCallbackLimit)
+		}
+		parsed, ok := DecodeCallback(data)
+		if !ok || !parsed.IsMemoryReview() {
+			t.Fatalf("DecodeCallback(%q) = %+v, %v", data, parsed, ok)
+		}
+		if got := parsed.SyntheticCommand(); got != tt.want {
+			t.Fatalf("SyntheticCommand = %q, want %q", got, tt.want)
+		}
+	}
+}
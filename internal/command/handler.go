@@ -74,6 +74,7 @@ type Handler struct {
 	modelsService      *models.Service
 	providersService   *providers.Service
 	memProvService     *memprovider.Service
+	memoryRegistry     *memprovider.Registry
 	searchProvService  *searchproviders.Service
 	emailService       *emailpkg.Service
 	emailOutboxService *emailpkg.OutboxService
@@ -162,6 +163,12 @@ func (h *Handler) SetCompactionService(s *compaction.Service, q dbstore.Queries)
 	h.sqlcQueries = q
 }
 
+// SetMemoryRegistry configures the registry the /memory keep and forget
+// actions use to reach the bot's memory provider.
+func (h *Handler) SetMemoryRegistry(registry *memprovider.Registry) {
+	h.memoryRegistry = registry
+}
+
 // CurrentContext resolves the bot's current model/heartbeat/reasoning state for
 // enriching command output (e.g. the /new confirmation). It is a read-only view
 // over existing bot settings and makes no changes.
@@ -184,7 +191,7 @@ func (h *Handler) CurrentContext(ctx context.Context, botID string) (CurrentCont
 // topLevelCommands are standalone commands (no sub-actions) that IsCommand
 // recognises and that are handled outside the regular resource-group dispatch
 // (the channel inbound processor has the routing context they need). Only
-// /help, /start, /new, /stop are advertised in /help output. /approve, /reject,
+// /help, /start, /new, /stop, /flow are advertised in /help output. /approve, /reject,
 // and /respond are internal continuation protocol verbs that users discover via
 // the active prompt, not via the help listing.
 //
@@ -195,6 +202,7 @@ var topLevelCommands = map[string]struct{}{
 	"start":   {},
 	"new":     {},
 	"stop":    {},
+	"flow":    {},
 	"approve": {},
 	"reject":  {},
 	"respond": {},
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
 	"github.com/memohai/memoh/internal/settings"
 )
 
@@ -130,5 +131,55 @@ func (h *Handler) buildMemoryGroup() *CommandGroup {
 			return cc.T("cmd.memory.notFound", map[string]any{"name": fmt.Sprintf("%q", name), "command": CmdRef("memory list")}), nil
 		},
 	})
+	g.Register(SubCommand{
+		Name:    MemoryReviewKeep,
+		Usage:   "keep <id> - Keep a memory from the review digest",
+		IsWrite: true,
+		Handler: func(cc CommandContext) (string, error) {
+			localID, ok := memoryReviewArg(cc)
+			if !ok {
+				return cc.T("cmd.memory.reviewUsage"), nil
+			}
+			return cc.T("cmd.memory.kept", map[string]any{"id": localID}), nil
+		},
+	})
+	g.Register(SubCommand{
+		Name:    MemoryReviewForget,
+		Usage:   "forget <id> - Delete a memory from the review digest",
+		IsWrite: true,
+		Handler: func(cc CommandContext) (string, error) {
+			localID, ok := memoryReviewArg(cc)
+			if !ok {
+				return cc.T("cmd.memory.reviewUsage"), nil
+			}
+			if h.memoryRegistry == nil {
+				return cc.T("cmd.memory.unavailable"), nil
+			}
+			provider, err := memprovider.ResolveBotProvider(cc.Ctx, h.memoryRegistry, h.settingsService, cc.BotID)
+			if err != nil {
+				return "", err
+			}
+			if _, err := provider.Delete(cc.Ctx, cc.BotID+":"+localID); err != nil {
+				return "", err
+			}
+			return cc.T("cmd.memory.forgotten", map[string]any{"id": localID}), nil
+		},
+	})
 	return g
 }
+
+// memoryReviewArg returns the memory ID argument without its "{botID}:"
+// prefix. IDs that name another bot's memory are rejected.
+func memoryReviewArg(cc CommandContext) (string, bool) {
+	if len(cc.Args) < 1 {
+		return "", false
+	}
+	id := strings.TrimSpace(cc.Args[0])
+	if botID, localID, found := strings.Cut(id, ":"); found {
+		if botID != cc.BotID {
+			return "", false
+		}
+		id = strings.TrimSpace(localID)
+	}
+	return id, id != ""
+}
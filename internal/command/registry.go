@@ -139,6 +139,7 @@ func (r *Registry) GlobalHelp(t *i18n.Localizer, commandTarget string) string {
 	b.WriteString("- " + CommandText("start", "") + " — " + t.T("cmd.help.top.start") + "\n")
 	b.WriteString("- " + CommandText("new", "") + " — " + t.T("cmd.help.top.new") + "\n")
 	b.WriteString("- " + CommandText("stop", "") + " — " + t.T("cmd.help.top.stop") + "\n")
+	b.WriteString("- " + CommandText("flow", "") + " — " + t.T("cmd.help.top.flow") + "\n")
 	for _, name := range r.order {
 		group := r.groups[name]
 		fmt.Fprintf(&b, "- %s — %s\n", CommandText(group.Name, ""), commandDescription(t, group))
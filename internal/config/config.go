@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/BurntSushi/toml"
+	gommonbytes "github.com/labstack/gommon/bytes"
 )
 
 const (
@@ -29,6 +31,7 @@ const (
 	DefaultCNIBinaryDir          = "/opt/cni/bin"
 	DefaultCNIConfigDir          = "/etc/cni/net.d"
 	DefaultJWTExpiresIn          = "24h"
+	DefaultRefreshTokenExpiresIn = "720h"
 	DefaultDatabaseDriver        = "postgres"
 	DefaultPGHost                = "127.0.0.1"
 	DefaultPGPort                = 5432
@@ -77,9 +80,18 @@ type Config struct {
 	Supermarket    SupermarketConfig    `toml:"supermarket"`
 	OAuthClients   OAuthClientsConfig   `toml:"oauth_clients"`
 	SessionRuntime SessionRuntimeConfig `toml:"session_runtime"`
+	Redis          RedisConfig          `toml:"redis"`
+	Startup        StartupConfig        `toml:"startup"`
 	InstanceID     string               `toml:"instance_id"`
 	BridgeTLS      BridgeTLSConfig      `toml:"bridge_tls"`
 	WebhookTunnel  WebhookTunnelConfig  `toml:"webhook_tunnel"`
+	Offline        OfflineConfig        `toml:"offline"`
+	Encryption     EncryptionConfig     `toml:"encryption"`
+	GitHub         GitHubConfig         `toml:"github"`
+	Push           PushConfig           `toml:"push"`
+	History        HistoryConfig        `toml:"history"`
+	Extensions     ExtensionsConfig     `toml:"extensions"`
+	Telemetry      TelemetryConfig      `toml:"telemetry"`
 }
 
 const (
@@ -128,11 +140,94 @@ type LogConfig struct {
 type ServerConfig struct {
 	Addr          string `toml:"addr"`
 	RPCListenAddr string `toml:"rpc_listen_addr"`
+	// RateLimitPerMinute caps API requests per authenticated user per
+	// minute, with bursts of up to RateLimitBurst (zero uses the per-minute
+	// value). Zero disables the limit.
+	RateLimitPerMinute int `toml:"rate_limit_per_minute"`
+	RateLimitBurst     int `toml:"rate_limit_burst"`
+	// MaxBodySize caps authenticated request bodies ("16M", "512K"); empty
+	// leaves them unlimited. BodyLimits overrides it per route group, keyed
+	// by route prefix as registered (e.g. "/bots/:bot_id/web/media"). The
+	// longest matching prefix wins and "0" lifts the cap for that group.
+	MaxBodySize string            `toml:"max_body_size"`
+	BodyLimits  map[string]string `toml:"body_limits"`
+}
+
+// BodyLimitBytes parses MaxBodySize and BodyLimits into byte counts; zero
+// means unlimited.
+func (c ServerConfig) BodyLimitBytes() (int64, map[string]int64, error) {
+	parse := func(name, raw string) (int64, error) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return 0, nil
+		}
+		n, err := gommonbytes.Parse(raw)
+		if err != nil {
+			return 0, fmt.Errorf("invalid server %s %q: %w", name, raw, err)
+		}
+		if n < 0 {
+			return 0, fmt.Errorf("invalid server %s %q: must not be negative", name, raw)
+		}
+		return n, nil
+	}
+	defaultLimit, err := parse("max_body_size", c.MaxBodySize)
+	if err != nil {
+		return 0, nil, err
+	}
+	groups := make(map[string]int64, len(c.BodyLimits))
+	for prefix, raw := range c.BodyLimits {
+		prefix = strings.TrimSpace(prefix)
+		if !strings.HasPrefix(prefix, "/") {
+			return 0, nil, fmt.Errorf("invalid server body_limits route %q: must start with /", prefix)
+		}
+		if strings.TrimSpace(raw) == "" {
+			return 0, nil, fmt.Errorf("invalid server body_limits %q: size is required", prefix)
+		}
+		n, err := parse("body_limits "+prefix, raw)
+		if err != nil {
+			return 0, nil, err
+		}
+		groups[prefix] = n
+	}
+	return defaultLimit, groups, nil
 }
 
 type ChannelConfig struct {
 	Addr          string `toml:"addr"`
 	RPCListenAddr string `toml:"rpc_listen_addr"`
+	// InboundBufferDir holds the per-adapter spools of inbound events that
+	// arrived while Postgres or the agent was unreachable.
+	// InboundBufferMaxEvents bounds each spool; zero disables buffering.
+	InboundBufferDir       string `toml:"inbound_buffer_dir"`
+	InboundBufferMaxEvents int    `toml:"inbound_buffer_max_events"`
+	// InboundWorkers is how many inbound messages are processed at once,
+	// each conversation still one message at a time. InboundQueueSize bounds
+	// the messages waiting for a worker and InboundConversationQueueSize the
+	// share one conversation may hold; a full queue refuses new events so
+	// the platform redelivers them. Zero keeps the defaults.
+	InboundWorkers               int `toml:"inbound_workers"`
+	InboundQueueSize             int `toml:"inbound_queue_size"`
+	InboundConversationQueueSize int `toml:"inbound_conversation_queue_size"`
+	// InactiveRouteRetentionDays is how long routes of conversations the bot
+	// was removed from are kept before they are pruned; zero keeps them.
+	InactiveRouteRetentionDays int `toml:"inactive_route_retention_days"`
+	// DirectorySyncIntervalHours is how often contact names and avatars are
+	// refreshed from platform directories; zero disables the sync.
+	DirectorySyncIntervalHours int `toml:"directory_sync_interval_hours"`
+}
+
+const (
+	DefaultChannelInboundBufferDir           = "data/inbound-buffer"
+	DefaultChannelInboundBufferMaxEvents     = 1000
+	DefaultChannelInactiveRouteRetentionDays = 30
+	DefaultChannelDirectorySyncIntervalHours = 12
+)
+
+func (c ChannelConfig) InboundBufferPath() string {
+	if strings.TrimSpace(c.InboundBufferDir) != "" {
+		return absPath(c.InboundBufferDir)
+	}
+	return absPath(DefaultChannelInboundBufferDir)
 }
 
 type InternalRPCConfig struct {
@@ -187,6 +282,198 @@ func (c WebhookTunnelConfig) Validate() error {
 	}
 }
 
+// EncryptionConfig enables application-level encryption of message content
+// and memory text. MasterKey is 32 random bytes, base64 encoded; it wraps
+// the per-bot data keys stored in the database and must be kept outside it.
+type EncryptionConfig struct {
+	MasterKey string `toml:"master_key" json:"-"`
+}
+
+func (c EncryptionConfig) Enabled() bool {
+	return strings.TrimSpace(c.MasterKey) != ""
+}
+
+func (c EncryptionConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(c.MasterKey))
+	if err != nil || len(raw) != 32 {
+		return errors.New("encryption.master_key must be 32 bytes, base64 encoded")
+	}
+	return nil
+}
+
+const DefaultGitHubAPIBaseURL = "https://api.github.com"
+
+// GitHubConfig is the GitHub App the server uses to read repositories and
+// comment on issues. WebhookSecret verifies the app's webhook deliveries.
+type GitHubConfig struct {
+	AppID          int64  `toml:"app_id"`
+	PrivateKeyPath string `toml:"private_key_path"`
+	WebhookSecret  string `toml:"webhook_secret" json:"-"`
+	APIBaseURL     string `toml:"api_base_url"`
+}
+
+func (c GitHubConfig) Enabled() bool {
+	return c.AppID != 0
+}
+
+// APIBaseURLOrDefault returns the REST API base URL without a trailing
+// slash; GitHub Enterprise Server installs set it to https://host/api/v3.
+func (c GitHubConfig) APIBaseURLOrDefault() string {
+	if value := strings.TrimRight(strings.TrimSpace(c.APIBaseURL), "/"); value != "" {
+		return value
+	}
+	return DefaultGitHubAPIBaseURL
+}
+
+func (c GitHubConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.AppID < 0 {
+		return errors.New("github.app_id must be positive")
+	}
+	if strings.TrimSpace(c.PrivateKeyPath) == "" {
+		return errors.New("github.private_key_path is required when github.app_id is set")
+	}
+	if strings.TrimSpace(c.WebhookSecret) == "" {
+		return errors.New("github.webhook_secret is required when github.app_id is set")
+	}
+	return nil
+}
+
+// PushConfig holds the push credentials of the companion mobile app. APNs
+// and FCM are configured independently; devices of an unconfigured platform
+// cannot be registered.
+type PushConfig struct {
+	APNsKeyID   string `toml:"apns_key_id"`
+	APNsTeamID  string `toml:"apns_team_id"`
+	APNsKeyPath string `toml:"apns_key_path"`
+	// APNsTopic is the app's bundle ID.
+	APNsTopic   string `toml:"apns_topic"`
+	APNsSandbox bool   `toml:"apns_sandbox"`
+	// FCMCredentialsPath is a Firebase service account JSON key.
+	FCMCredentialsPath string `toml:"fcm_credentials_path"`
+}
+
+func (c PushConfig) APNsEnabled() bool {
+	return strings.TrimSpace(c.APNsKeyID) != ""
+}
+
+func (c PushConfig) FCMEnabled() bool {
+	return strings.TrimSpace(c.FCMCredentialsPath) != ""
+}
+
+func (c PushConfig) Validate() error {
+	if c.APNsEnabled() {
+		if strings.TrimSpace(c.APNsTeamID) == "" || strings.TrimSpace(c.APNsKeyPath) == "" || strings.TrimSpace(c.APNsTopic) == "" {
+			return errors.New("push.apns_team_id, push.apns_key_path and push.apns_topic are required when push.apns_key_id is set")
+		}
+	}
+	return nil
+}
+
+// HistoryConfig controls cold storage of old chat history.
+type HistoryConfig struct {
+	// ArchiveAfterMonths moves the content of messages older than this many
+	// months into compressed objects under ArchiveDir, leaving a preview in
+	// the database. Zero disables archiving.
+	ArchiveAfterMonths int    `toml:"archive_after_months"`
+	ArchiveDir         string `toml:"archive_dir"`
+}
+
+const DefaultHistoryArchiveDir = "data/message-archive"
+
+func (c HistoryConfig) ArchivePath() string {
+	if strings.TrimSpace(c.ArchiveDir) != "" {
+		return absPath(c.ArchiveDir)
+	}
+	return absPath(DefaultHistoryArchiveDir)
+}
+
+// ExtensionsConfig lists the external gRPC plugins consulted at the
+// extension hook points, after the extensions compiled into the binary.
+type ExtensionsConfig struct {
+	Plugins []ExtensionPluginConfig `toml:"plugins"`
+}
+
+type ExtensionPluginConfig struct {
+	Name   string `toml:"name"`
+	Target string `toml:"target"`
+	// Timeout bounds each call, as a Go duration. A plugin that does not
+	// answer in time is skipped for that event.
+	Timeout string `toml:"timeout"`
+	// Hooks limits the hook points sent to the plugin; empty sends all.
+	Hooks []string `toml:"hooks"`
+}
+
+const DefaultExtensionPluginTimeout = "2s"
+
+func (c ExtensionPluginConfig) TimeoutOrDefault() string {
+	if strings.TrimSpace(c.Timeout) != "" {
+		return strings.TrimSpace(c.Timeout)
+	}
+	return DefaultExtensionPluginTimeout
+}
+
+func (c ExtensionsConfig) Validate() error {
+	names := make(map[string]struct{}, len(c.Plugins))
+	for _, plugin := range c.Plugins {
+		name := strings.TrimSpace(plugin.Name)
+		if name == "" {
+			return errors.New("extensions.plugins.name is required")
+		}
+		if _, ok := names[name]; ok {
+			return fmt.Errorf("duplicate extension plugin %q", name)
+		}
+		names[name] = struct{}{}
+		if strings.TrimSpace(plugin.Target) == "" {
+			return fmt.Errorf("extension plugin %q: target is required", name)
+		}
+		timeout, err := time.ParseDuration(plugin.TimeoutOrDefault())
+		if err != nil {
+			return fmt.Errorf("invalid extension plugin %q timeout %q: %w", name, plugin.Timeout, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("invalid extension plugin %q timeout %q: must be positive", name, plugin.Timeout)
+		}
+	}
+	return nil
+}
+
+// TelemetryConfig controls OpenTelemetry tracing. Spans are exported over
+// OTLP/HTTP to Endpoint (host:port, or a full URL) when Enabled is set.
+type TelemetryConfig struct {
+	Enabled     bool   `toml:"enabled"`
+	Endpoint    string `toml:"endpoint"`
+	Insecure    bool   `toml:"insecure"`
+	ServiceName string `toml:"service_name"`
+	// SampleRatio is the fraction of new traces recorded, 0 to 1. Zero
+	// records every trace; traces started upstream keep their decision.
+	SampleRatio float64 `toml:"sample_ratio"`
+}
+
+const DefaultTelemetryServiceName = "memoh"
+
+func (c TelemetryConfig) ServiceNameOrDefault() string {
+	if strings.TrimSpace(c.ServiceName) != "" {
+		return strings.TrimSpace(c.ServiceName)
+	}
+	return DefaultTelemetryServiceName
+}
+
+func (c TelemetryConfig) Validate() error {
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("invalid telemetry sample_ratio %v: must be between 0 and 1", c.SampleRatio)
+	}
+	if c.Enabled && strings.TrimSpace(c.Endpoint) == "" {
+		return errors.New("telemetry.endpoint is required when telemetry is enabled")
+	}
+	return nil
+}
+
 type AdminConfig struct {
 	Username string `toml:"username"`
 	Password string `toml:"password" json:"-"`
@@ -196,14 +483,41 @@ type AdminConfig struct {
 type AuthConfig struct {
 	JWTSecret    string `toml:"jwt_secret"    json:"-"`
 	JWTExpiresIn string `toml:"jwt_expires_in"`
+	// RefreshTokenExpiresIn is how long a refresh token can mint new access
+	// tokens; each refresh issues a successor with a fresh lifetime.
+	RefreshTokenExpiresIn string `toml:"refresh_token_expires_in"`
 }
 
 type AgentConfig struct {
 	ToolOutputMaxBytes  int `toml:"tool_output_max_bytes"`
 	ToolOutputMaxLines  int `toml:"tool_output_max_lines"`
 	SystemFilesMaxBytes int `toml:"system_files_max_bytes"`
+	// ScheduleMaxPerBot and ScheduleMinIntervalSeconds limit the schedules
+	// a bot's agent can set up for itself. Zero disables a limit.
+	ScheduleMaxPerBot          int `toml:"schedule_max_per_bot"`
+	ScheduleMinIntervalSeconds int `toml:"schedule_min_interval_seconds"`
+	// HistoryToolKeepTurns is how many recent user turns keep raw tool
+	// results in the model context; older results collapse to one-line
+	// outcomes. HistoryToolResultMaxBytes caps the kept results.
+	HistoryToolKeepTurns      int `toml:"history_tool_keep_turns"`
+	HistoryToolResultMaxBytes int `toml:"history_tool_result_max_bytes"`
+	// MaxConcurrentTurnsPerBot caps the chat turns a bot runs at once; the
+	// rest wait, owner first and fairly across senders. Zero disables it.
+	MaxConcurrentTurnsPerBot int `toml:"max_concurrent_turns_per_bot"`
+	// CompactionContextPercent is the share of a model's context window the
+	// estimated history may reach before it is compacted ahead of the request.
+	CompactionContextPercent int `toml:"compaction_context_percent"`
 }
 
+const (
+	DefaultAgentScheduleMaxPerBot          = 20
+	DefaultAgentScheduleMinIntervalSeconds = 300
+	DefaultAgentHistoryToolKeepTurns       = 3
+	DefaultAgentHistoryToolResultMaxBytes  = 8 * 1024
+	DefaultAgentMaxConcurrentTurnsPerBot   = 4
+	DefaultAgentCompactionContextPercent   = 70
+)
+
 const (
 	SessionRuntimeBackendMemory = "memory"
 	SessionRuntimeBackendRedis  = "redis"
@@ -227,6 +541,70 @@ type SessionRuntimeRedisConfig struct {
 	KeyPrefix string `toml:"key_prefix"`
 }
 
+// DefaultRedisKeyPrefix namespaces the shared state keys.
+const DefaultRedisKeyPrefix = "memoh:state:"
+
+// RedisConfig points replicas at a Redis or Valkey server for the state they
+// must share: API and inbound rate limit buckets and the outbound duplicate
+// window. Unset, that state is kept in each process's memory. Live run
+// snapshots are configured separately under session_runtime.
+type RedisConfig struct {
+	URL       string `toml:"url"`
+	KeyPrefix string `toml:"key_prefix"`
+}
+
+// Enabled reports whether a Redis server is configured.
+func (c RedisConfig) Enabled() bool {
+	return strings.TrimSpace(c.URL) != ""
+}
+
+func (c RedisConfig) KeyPrefixOrDefault() string {
+	if strings.TrimSpace(c.KeyPrefix) != "" {
+		return strings.TrimSpace(c.KeyPrefix)
+	}
+	return DefaultRedisKeyPrefix
+}
+
+// DefaultStartupDependencyWait bounds how long boot retries a dependency.
+const DefaultStartupDependencyWait = "60s"
+
+// StartupConfig controls how the server waits for the services it depends
+// on when they come up after it, as in a docker compose start.
+type StartupConfig struct {
+	// DependencyWait is how long boot retries Postgres, pgvector and the
+	// container runtime before failing (Postgres) or starting degraded.
+	// "0s" disables retrying.
+	DependencyWait string `toml:"dependency_wait"`
+}
+
+func (c StartupConfig) DependencyWaitOrDefault() string {
+	if strings.TrimSpace(c.DependencyWait) != "" {
+		return strings.TrimSpace(c.DependencyWait)
+	}
+	return DefaultStartupDependencyWait
+}
+
+// DependencyWaitDuration returns the parsed wait budget. Validate rejects
+// values that do not parse.
+func (c StartupConfig) DependencyWaitDuration() time.Duration {
+	wait, err := time.ParseDuration(c.DependencyWaitOrDefault())
+	if err != nil || wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+func (c StartupConfig) Validate() error {
+	wait, err := time.ParseDuration(c.DependencyWaitOrDefault())
+	if err != nil {
+		return fmt.Errorf("invalid startup dependency_wait %q: %w", c.DependencyWait, err)
+	}
+	if wait < 0 {
+		return fmt.Errorf("invalid startup dependency_wait %q: must not be negative", c.DependencyWait)
+	}
+	return nil
+}
+
 func (c SessionRuntimeConfig) BackendOrDefault() string {
 	backend := strings.TrimSpace(strings.ToLower(c.Backend))
 	if backend == "" {
@@ -570,8 +948,11 @@ func Load(path string) (Config, error) {
 			RPCListenAddr: DefaultServerRPCListenAddr,
 		},
 		Channel: ChannelConfig{
-			Addr:          DefaultChannelHTTPAddr,
-			RPCListenAddr: DefaultChannelRPCListenAddr,
+			Addr:                       DefaultChannelHTTPAddr,
+			RPCListenAddr:              DefaultChannelRPCListenAddr,
+			InboundBufferMaxEvents:     DefaultChannelInboundBufferMaxEvents,
+			InactiveRouteRetentionDays: DefaultChannelInactiveRouteRetentionDays,
+			DirectorySyncIntervalHours: DefaultChannelDirectorySyncIntervalHours,
 		},
 		InternalRPC: InternalRPCConfig{
 			ServerTarget:  DefaultServerRPCTarget,
@@ -583,12 +964,19 @@ func Load(path string) (Config, error) {
 			Email:    "you@example.com",
 		},
 		Auth: AuthConfig{
-			JWTExpiresIn: DefaultJWTExpiresIn,
+			JWTExpiresIn:          DefaultJWTExpiresIn,
+			RefreshTokenExpiresIn: DefaultRefreshTokenExpiresIn,
 		},
 		Agent: AgentConfig{
-			ToolOutputMaxBytes:  DefaultAgentToolOutputBytes,
-			ToolOutputMaxLines:  DefaultAgentToolOutputLines,
-			SystemFilesMaxBytes: DefaultAgentSystemFilesBytes,
+			ToolOutputMaxBytes:         DefaultAgentToolOutputBytes,
+			ToolOutputMaxLines:         DefaultAgentToolOutputLines,
+			SystemFilesMaxBytes:        DefaultAgentSystemFilesBytes,
+			ScheduleMaxPerBot:          DefaultAgentScheduleMaxPerBot,
+			ScheduleMinIntervalSeconds: DefaultAgentScheduleMinIntervalSeconds,
+			HistoryToolKeepTurns:       DefaultAgentHistoryToolKeepTurns,
+			HistoryToolResultMaxBytes:  DefaultAgentHistoryToolResultMaxBytes,
+			MaxConcurrentTurnsPerBot:   DefaultAgentMaxConcurrentTurnsPerBot,
+			CompactionContextPercent:   DefaultAgentCompactionContextPercent,
 		},
 		Timezone: DefaultTimezone,
 		Database: DatabaseConfig{
@@ -690,7 +1078,28 @@ func (cfg Config) validate() error {
 	if err := cfg.SessionRuntime.Validate(); err != nil {
 		return err
 	}
-	return nil
+	if err := cfg.Startup.Validate(); err != nil {
+		return err
+	}
+	if _, _, err := cfg.Server.BodyLimitBytes(); err != nil {
+		return err
+	}
+	if err := cfg.Encryption.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.GitHub.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Push.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Extensions.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Telemetry.Validate(); err != nil {
+		return err
+	}
+	return cfg.validateOffline()
 }
 
 // SplitChannelRuntime reports whether the channel runtime runs as a
@@ -772,6 +1181,12 @@ func (cfg *Config) applyBridgeTLSEnvOverrides() {
 	if value := strings.TrimSpace(os.Getenv("MEMOH_INTERNAL_RPC_CHANNEL_TARGET")); value != "" {
 		cfg.InternalRPC.ChannelTarget = value
 	}
+	if value := strings.TrimSpace(os.Getenv("MEMOH_ENCRYPTION_MASTER_KEY")); value != "" {
+		cfg.Encryption.MasterKey = value
+	}
+	if value := strings.TrimSpace(os.Getenv("MEMOH_GITHUB_WEBHOOK_SECRET")); value != "" {
+		cfg.GitHub.WebhookSecret = value
+	}
 }
 
 func (cfg *Config) resolvePaths() {
@@ -785,6 +1200,15 @@ func (cfg *Config) resolvePaths() {
 	if strings.TrimSpace(cfg.OAuthClients.ConfigPath) != "" {
 		cfg.OAuthClients.ConfigPath = cfg.OAuthClients.Path()
 	}
+	if strings.TrimSpace(cfg.GitHub.PrivateKeyPath) != "" {
+		cfg.GitHub.PrivateKeyPath = absPath(cfg.GitHub.PrivateKeyPath)
+	}
+	if strings.TrimSpace(cfg.Push.APNsKeyPath) != "" {
+		cfg.Push.APNsKeyPath = absPath(cfg.Push.APNsKeyPath)
+	}
+	if strings.TrimSpace(cfg.Push.FCMCredentialsPath) != "" {
+		cfg.Push.FCMCredentialsPath = absPath(cfg.Push.FCMCredentialsPath)
+	}
 }
 
 func containerHasWorkspaceFields(values map[string]any) bool {
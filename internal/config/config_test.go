@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -602,3 +603,170 @@ func TestWorkspaceImagePullCandidatesDoesNotMirrorCustomImages(t *testing.T) {
 		t.Fatalf("unexpected candidates: %v", got)
 	}
 }
+
+func TestOfflineCheckEndpointAcceptsOnlyLocalHosts(t *testing.T) {
+	t.Parallel()
+
+	offline := OfflineConfig{Enabled: true, AllowedHosts: []string{"llm.corp.example", ".gpu.example"}}
+	for _, endpoint := range []string{
+		"http://127.0.0.1:11434/v1",
+		"http://localhost:8000",
+		"http://ollama:11434",
+		"http://10.0.3.7/v1",
+		"http://[fd00::1]:8000",
+		"https://vllm.cluster.internal/v1",
+		"https://llm.corp.example/v1",
+		"https://a100.gpu.example",
+	} {
+		if err := offline.CheckEndpoint(endpoint); err != nil {
+			t.Errorf("CheckEndpoint(%q) = %v, want nil", endpoint, err)
+		}
+	}
+	for _, endpoint := range []string{
+		"https://api.openai.com/v1",
+		"https://8.8.8.8",
+		"https://evil.example/llm.corp.example",
+		"",
+	} {
+		if err := offline.CheckEndpoint(endpoint); !errors.Is(err, ErrEndpointNotLocal) {
+			t.Errorf("CheckEndpoint(%q) = %v, want ErrEndpointNotLocal", endpoint, err)
+		}
+	}
+}
+
+func TestLoadOfflineRejectsPublicSupermarket(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("[offline]\nenabled = true\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(configPath); err == nil || !strings.Contains(err.Error(), "supermarket.base_url") {
+		t.Fatalf("expected public supermarket to fail offline validation, got %v", err)
+	}
+
+	local := "[offline]\nenabled = true\n[supermarket]\nbase_url = \"http://supermarket.local\"\n"
+	if err := os.WriteFile(configPath, []byte(local), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("load offline config: %v", err)
+	}
+	if !cfg.Offline.Enabled {
+		t.Fatal("expected offline mode to be enabled")
+	}
+}
+
+func TestLoadGitHubRequiresWebhookSecret(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	body := "[github]\napp_id = 42\nprivate_key_path = \"github.pem\"\n"
+	if err := os.WriteFile(configPath, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(configPath); err == nil || !strings.Contains(err.Error(), "github.webhook_secret") {
+		t.Fatalf("expected missing webhook secret to fail, got %v", err)
+	}
+
+	t.Setenv("MEMOH_GITHUB_WEBHOOK_SECRET", "test-only-secret")
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("load github config: %v", err)
+	}
+	if !cfg.GitHub.Enabled() || cfg.GitHub.WebhookSecret != "test-only-secret" {
+		t.Fatalf("github config = %#v", cfg.GitHub)
+	}
+	if !filepath.IsAbs(cfg.GitHub.PrivateKeyPath) {
+		t.Fatalf("private key path = %q, want absolute", cfg.GitHub.PrivateKeyPath)
+	}
+	if cfg.GitHub.APIBaseURLOrDefault() != DefaultGitHubAPIBaseURL {
+		t.Fatalf("api base url = %q", cfg.GitHub.APIBaseURLOrDefault())
+	}
+}
+
+func TestLoadPushValidatesAPNs(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("[push]\napns_key_id = \"ABC123\"\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(configPath); err == nil || !strings.Contains(err.Error(), "push.apns_topic") {
+		t.Fatalf("expected incomplete APNs config to fail, got %v", err)
+	}
+
+	body := "[push]\napns_key_id = \"ABC123\"\napns_team_id = \"TEAM\"\napns_key_path = \"apns.p8\"\napns_topic = \"ai.memoh.app\"\nfcm_credentials_path = \"fcm.json\"\n"
+	if err := os.WriteFile(configPath, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("load push config: %v", err)
+	}
+	if !cfg.Push.APNsEnabled() || !cfg.Push.FCMEnabled() {
+		t.Fatalf("push config = %#v", cfg.Push)
+	}
+	if !filepath.IsAbs(cfg.Push.APNsKeyPath) || !filepath.IsAbs(cfg.Push.FCMCredentialsPath) {
+		t.Fatalf("push key paths = %q, %q, want absolute", cfg.Push.APNsKeyPath, cfg.Push.FCMCredentialsPath)
+	}
+
+	offline := "[offline]\nenabled = true\n[supermarket]\nbase_url = \"http://supermarket.local\"\n[push]\nfcm_credentials_path = \"fcm.json\"\n"
+	if err := os.WriteFile(configPath, []byte(offline), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(configPath); err == nil || !strings.Contains(err.Error(), "push") {
+		t.Fatalf("expected push to fail offline validation, got %v", err)
+	}
+}
+
+func TestLoadOfflineRejectsKnowledgeConnectors(t *testing.T) {
+	dir := t.TempDir()
+	clientsPath := filepath.Join(dir, "oauth-clients.toml")
+	clients := "[clients.knowledge_notion]\nclient_id = \"${MEMOH_TEST_NOTION_CLIENT_ID}\"\n[clients.linear]\nclient_id = \"linear-id\"\n"
+	if err := os.WriteFile(clientsPath, []byte(clients), 0o600); err != nil {
+		t.Fatalf("write oauth clients: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.toml")
+	body := "[offline]\nenabled = true\n[supermarket]\nbase_url = \"http://supermarket.local\"\n[oauth_clients]\nconfig_path = \"" + filepath.ToSlash(clientsPath) + "\"\n"
+	if err := os.WriteFile(configPath, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(configPath); err != nil {
+		t.Fatalf("load offline config with an unset connector client: %v", err)
+	}
+
+	t.Setenv("MEMOH_TEST_NOTION_CLIENT_ID", "notion-id")
+	if _, err := Load(configPath); err == nil || !strings.Contains(err.Error(), "knowledge_notion") {
+		t.Fatalf("expected the Notion connector to fail offline validation, got %v", err)
+	}
+}
+
+func TestLoadValidatesExtensionPlugins(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	for body, want := range map[string]string{
+		"[[extensions.plugins]]\ntarget = \"127.0.0.1:9300\"\n":                                                            "name is required",
+		"[[extensions.plugins]]\nname = \"a\"\n":                                                                           "target is required",
+		"[[extensions.plugins]]\nname = \"a\"\ntarget = \"x:1\"\n[[extensions.plugins]]\nname = \"a\"\ntarget = \"y:1\"\n": "duplicate extension plugin",
+		"[[extensions.plugins]]\nname = \"a\"\ntarget = \"x:1\"\ntimeout = \"soon\"\n":                                     "invalid extension plugin",
+		"[offline]\nenabled = true\n[supermarket]\nbase_url = \"http://supermarket.local\"\n[[extensions.plugins]]\nname = \"a\"\ntarget = \"dns:///plugins.example.com:443\"\n": "must run locally",
+	} {
+		if err := os.WriteFile(configPath, []byte(body), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if _, err := Load(configPath); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("Load(%q) error = %v, want %q", body, err, want)
+		}
+	}
+
+	body := "[offline]\nenabled = true\n[supermarket]\nbase_url = \"http://supermarket.local\"\n[[extensions.plugins]]\nname = \"compliance\"\ntarget = \"compliance:9300\"\nhooks = [\"inbound\"]\n"
+	if err := os.WriteFile(configPath, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("load extension config: %v", err)
+	}
+	if len(cfg.Extensions.Plugins) != 1 || cfg.Extensions.Plugins[0].TimeoutOrDefault() != DefaultExtensionPluginTimeout {
+		t.Fatalf("extensions = %#v", cfg.Extensions)
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ErrEndpointNotLocal is returned when offline mode rejects an endpoint that
+// is not a local or private address.
+var ErrEndpointNotLocal = errors.New("endpoint is not local")
+
+// OfflineConfig is the airgapped deployment profile. When enabled, model and
+// embedding traffic may only target local endpoints, built-in web search and
+// fetch tools are withheld from agents, and startup fails if any configured
+// endpoint points at a public service.
+type OfflineConfig struct {
+	Enabled bool `toml:"enabled"`
+	// AllowedHosts lists extra hostnames treated as local. An entry starting
+	// with "." matches every subdomain.
+	AllowedHosts []string `toml:"allowed_hosts"`
+}
+
+// knowledgeConnectorClients are the OAuth client keys of the knowledge
+// connectors, which sync from hosted Notion, Confluence and Google Drive.
+var knowledgeConnectorClients = []string{"knowledge_notion", "knowledge_confluence", "knowledge_google_drive"}
+
+// localHostSuffixes are DNS suffixes reserved for private networks.
+var localHostSuffixes = []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"}
+
+// CheckEndpoint returns ErrEndpointNotLocal unless raw is an http(s) URL
+// whose host is loopback, private, link-local, a single-label name (such as
+// a compose service), under a private-use suffix, or explicitly allowed.
+// Hostnames are not resolved, so the result does not depend on DNS.
+func (c OfflineConfig) CheckEndpoint(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("%w: %q", ErrEndpointNotLocal, raw)
+	}
+	if c.isLocalHost(u.Hostname()) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrEndpointNotLocal, u.Host)
+}
+
+func (c OfflineConfig) isLocalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+	}
+	if host == "localhost" || !strings.Contains(host, ".") {
+		return true
+	}
+	for _, suffix := range localHostSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	for _, allowed := range c.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == "" {
+			continue
+		}
+		if host == strings.TrimPrefix(allowed, ".") || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOffline checks the endpoints that live in the config file itself.
+// Endpoints stored in the database are checked by the server at startup.
+func (cfg Config) validateOffline() error {
+	if !cfg.Offline.Enabled {
+		return nil
+	}
+	if err := cfg.Offline.CheckEndpoint(cfg.Supermarket.GetBaseURL()); err != nil {
+		return fmt.Errorf("offline mode: supermarket.base_url must point to a local mirror: %w", err)
+	}
+	if cfg.GitHub.Enabled() {
+		if err := cfg.Offline.CheckEndpoint(cfg.GitHub.APIBaseURLOrDefault()); err != nil {
+			return fmt.Errorf("offline mode: github.api_base_url must point to a local GitHub Enterprise Server: %w", err)
+		}
+	}
+	if cfg.Push.APNsEnabled() || cfg.Push.FCMEnabled() {
+		return errors.New("offline mode: push notifications require Apple and Google push services; remove the [push] credentials")
+	}
+	if cfg.WebhookTunnel.EffectiveMode() != WebhookTunnelModeDisabled {
+		return fmt.Errorf("offline mode: webhook_tunnel mode %q requires Cloudflare; set it to %q", cfg.WebhookTunnel.EffectiveMode(), WebhookTunnelModeDisabled)
+	}
+	for _, plugin := range cfg.Extensions.Plugins {
+		if err := cfg.Offline.checkGRPCTarget(plugin.Target); err != nil {
+			return fmt.Errorf("offline mode: extension plugin %q must run locally: %w", plugin.Name, err)
+		}
+	}
+	return cfg.checkOfflineKnowledgeConnectors()
+}
+
+// checkOfflineKnowledgeConnectors fails when the OAuth clients file sets up a
+// knowledge connector. Client IDs are expanded from the environment the same
+// way the OAuth client registry does, so an unset variable configures nothing.
+func (cfg Config) checkOfflineKnowledgeConnectors() error {
+	path := cfg.OAuthClients.Path()
+	//nolint:gosec // OAuth client config path is controlled by server configuration.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("offline mode: read oauth clients config: %w", err)
+	}
+	var raw struct {
+		Clients map[string]struct {
+			ClientID string `toml:"client_id"`
+		} `toml:"clients"`
+	}
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return fmt.Errorf("offline mode: parse oauth clients config: %w", err)
+	}
+	for _, ref := range knowledgeConnectorClients {
+		if os.ExpandEnv(strings.TrimSpace(raw.Clients[ref].ClientID)) != "" {
+			return fmt.Errorf("offline mode: the %s knowledge connector syncs from a hosted service; remove its client_id from %s", ref, path)
+		}
+	}
+	return nil
+}
+
+// checkGRPCTarget is CheckEndpoint for gRPC dial targets such as
+// "host:port", "dns:///host:port" and "unix:///path".
+func (c OfflineConfig) checkGRPCTarget(target string) error {
+	target = strings.TrimSpace(target)
+	if strings.HasPrefix(target, "unix:") {
+		return nil
+	}
+	if i := strings.Index(target, ":///"); i >= 0 {
+		target = target[i+len(":///"):]
+	}
+	host, _, err := net.SplitHostPort(target)
+	if err != nil {
+		host = target
+	}
+	if host != "" && c.isLocalHost(host) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrEndpointNotLocal, target)
+}
@@ -67,6 +67,18 @@ func NewDefaultService(log *slog.Logger, client *containerd.Client, cfg config.C
 	}
 }
 
+// Ping reports whether the containerd daemon is serving.
+func (s *DefaultService) Ping(ctx context.Context) error {
+	serving, err := s.client.IsServing(ctx)
+	if err != nil {
+		return err
+	}
+	if !serving {
+		return errors.New("containerd is not serving")
+	}
+	return nil
+}
+
 func (s *DefaultService) runtimeTypeOrDefault() string {
 	runtimeType := strings.TrimSpace(s.runtimeType)
 	if runtimeType == "" {
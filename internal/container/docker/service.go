@@ -62,6 +62,12 @@ func (s *Service) Close() error {
 	return s.client.Close()
 }
 
+// Ping reports whether the Docker daemon answers.
+func (s *Service) Ping(ctx context.Context) error {
+	_, err := s.client.Ping(ctx)
+	return err
+}
+
 func (s *Service) PullImage(ctx context.Context, ref string, opts *containerapi.PullImageOptions) (containerapi.ImageInfo, error) {
 	ref = config.NormalizeImageRef(strings.TrimSpace(ref))
 	if ref == "" {
@@ -48,6 +48,12 @@ type ListTasksOptions struct {
 	ContainerID string
 }
 
+// HealthChecker is implemented by backends that reach a separate daemon.
+// Ping fails while the daemon cannot be reached.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
 // ImageService groups image and registry operations.
 type ImageService interface {
 	PullImage(ctx context.Context, ref string, opts *PullImageOptions) (ImageInfo, error)
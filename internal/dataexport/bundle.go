@@ -0,0 +1,375 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/channel/identities"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+// Bundle layout.
+const (
+	profileEntry  = "profile.json"
+	messagesEntry = "messages.jsonl"
+	memoriesEntry = "memories.json"
+	mediaDir      = "media/"
+	errorsEntry   = "errors.json"
+)
+
+// Profile is the profile.json entry of a bundle.
+type Profile struct {
+	SubjectType       string                       `json:"subject_type"`
+	SubjectID         string                       `json:"subject_id"`
+	GeneratedAt       time.Time                    `json:"generated_at"`
+	Account           *accounts.Account            `json:"account,omitempty"`
+	ChannelIdentities []identities.ChannelIdentity `json:"channel_identities"`
+}
+
+// MessageRecord is one line of messages.jsonl. Only messages the subject sent
+// are included; bot replies belong to the bot's history, not the subject.
+type MessageRecord struct {
+	ID                      string          `json:"id"`
+	BotID                   string          `json:"bot_id"`
+	SessionID               string          `json:"session_id,omitempty"`
+	Platform                string          `json:"platform,omitempty"`
+	Role                    string          `json:"role"`
+	SenderChannelIdentityID string          `json:"sender_channel_identity_id,omitempty"`
+	SenderUserID            string          `json:"sender_user_id,omitempty"`
+	Content                 json.RawMessage `json:"content,omitempty"`
+	DisplayText             string          `json:"display_text,omitempty"`
+	Media                   []MediaRecord   `json:"media,omitempty"`
+	CreatedAt               time.Time       `json:"created_at"`
+}
+
+// MediaRecord points a message attachment at its file inside the bundle.
+// Path is empty when the file could not be located; errors.json says why.
+type MediaRecord struct {
+	ContentHash string `json:"content_hash"`
+	Name        string `json:"name,omitempty"`
+	Mime        string `json:"mime,omitempty"`
+	Path        string `json:"path,omitempty"`
+}
+
+// subject is everything that identifies the data subject's records.
+type subject struct {
+	userID      string
+	identityIDs []string
+}
+
+func (s *Service) writeBundle(ctx context.Context, row sqlc.DataExportJob, dst io.Writer) error {
+	subj, profile, err := s.collectProfile(ctx, row)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(dst)
+	var problems []string
+
+	if err := writeJSONEntry(zw, profileEntry, profile); err != nil {
+		return err
+	}
+	botIDs, mediaProblems, err := s.writeMessages(ctx, zw, subj)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, mediaProblems...)
+
+	memories := make([]memprovider.MemoryItem, 0)
+	for _, botID := range botIDs {
+		items, err := s.collectMemories(ctx, botID, subj)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("memories for bot %s: %v", botID, err))
+			continue
+		}
+		memories = append(memories, items...)
+	}
+	if err := writeJSONEntry(zw, memoriesEntry, memories); err != nil {
+		return err
+	}
+	// Partial failures are recorded in the bundle rather than failing the
+	// whole export, so the subject still receives everything readable.
+	if len(problems) > 0 {
+		if err := writeJSONEntry(zw, errorsEntry, problems); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (s *Service) collectProfile(ctx context.Context, row sqlc.DataExportJob) (subject, Profile, error) {
+	subjectID := row.SubjectID.String()
+	profile := Profile{
+		SubjectType:       row.SubjectType,
+		SubjectID:         subjectID,
+		GeneratedAt:       s.now().UTC(),
+		ChannelIdentities: []identities.ChannelIdentity{},
+	}
+	switch row.SubjectType {
+	case SubjectUser:
+		if s.accounts == nil || s.identities == nil {
+			return subject{}, Profile{}, errors.New("account and identity services are required")
+		}
+		account, err := s.accounts.Get(ctx, subjectID)
+		if err != nil {
+			return subject{}, Profile{}, fmt.Errorf("load account: %w", err)
+		}
+		profile.Account = &account
+		subj := subject{userID: subjectID}
+		bindings, err := s.queries.ListChannelIdentityBindingsForUser(ctx, row.SubjectID)
+		if err != nil {
+			return subject{}, Profile{}, fmt.Errorf("list identity bindings: %w", err)
+		}
+		for _, binding := range bindings {
+			identity, err := s.identities.GetByID(ctx, binding.ChannelIdentityID.String())
+			if err != nil {
+				if errors.Is(err, identities.ErrChannelIdentityNotFound) {
+					continue
+				}
+				return subject{}, Profile{}, fmt.Errorf("load channel identity: %w", err)
+			}
+			profile.ChannelIdentities = append(profile.ChannelIdentities, identity)
+			subj.identityIDs = append(subj.identityIDs, identity.ID)
+		}
+		return subj, profile, nil
+	case SubjectChannelIdentity:
+		if s.identities == nil {
+			return subject{}, Profile{}, errors.New("identity service is required")
+		}
+		identity, err := s.identities.GetByID(ctx, subjectID)
+		if err != nil {
+			return subject{}, Profile{}, fmt.Errorf("load channel identity: %w", err)
+		}
+		profile.ChannelIdentities = append(profile.ChannelIdentities, identity)
+		return subject{identityIDs: []string{identity.ID}}, profile, nil
+	default:
+		return subject{}, Profile{}, ErrInvalidSubject
+	}
+}
+
+// writeMessages streams the subject's messages into messages.jsonl, copies
+// their media into media/, and returns the bots the subject talked to.
+func (s *Service) writeMessages(ctx context.Context, zw *zip.Writer, subj subject) ([]string, []string, error) {
+	reader, ok := s.queries.(subjectQueries)
+	if !ok {
+		return nil, nil, errors.New("data export queries not supported by store")
+	}
+	params := sqlc.ListMessagesForDataExportParams{ChannelIdentityIds: make([]pgtype.UUID, 0, len(subj.identityIDs))}
+	for _, id := range subj.identityIDs {
+		params.ChannelIdentityIds = append(params.ChannelIdentityIds, db.ParseUUIDOrEmpty(id))
+	}
+	if subj.userID != "" {
+		params.UserID = db.ParseUUIDOrEmpty(subj.userID)
+	}
+	rows, err := reader.ListMessagesForDataExport(ctx, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list messages: %w", err)
+	}
+
+	assets := map[pgtype.UUID][]sqlc.ListMessageAssetsBatchRow{}
+	if len(rows) > 0 {
+		ids := make([]pgtype.UUID, 0, len(rows))
+		for _, r := range rows {
+			ids = append(ids, r.ID)
+		}
+		assetRows, err := s.queries.ListMessageAssetsBatch(ctx, ids)
+		if err != nil {
+			return nil, nil, fmt.Errorf("list message media: %w", err)
+		}
+		for _, a := range assetRows {
+			assets[a.MessageID] = append(assets[a.MessageID], a)
+		}
+	}
+
+	out, err := zw.Create(messagesEntry)
+	if err != nil {
+		return nil, nil, err
+	}
+	enc := json.NewEncoder(out)
+	botIDs := []string{}
+	seenBots := map[pgtype.UUID]struct{}{}
+	var problems []string
+	var pending []pendingMedia
+	resolved := map[string]MediaRecord{}
+	for _, r := range rows {
+		if _, ok := seenBots[r.BotID]; !ok {
+			seenBots[r.BotID] = struct{}{}
+			botIDs = append(botIDs, r.BotID.String())
+		}
+		record, err := s.messageRecord(ctx, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, a := range assets[r.ID] {
+			key := record.BotID + "/" + strings.ToLower(a.ContentHash)
+			m, ok := resolved[key]
+			if !ok {
+				m = MediaRecord{ContentHash: a.ContentHash}
+				if err := s.resolveMedia(ctx, record.BotID, &m); err != nil {
+					problems = append(problems, fmt.Sprintf("media %s for bot %s: %v", a.ContentHash, record.BotID, err))
+				} else {
+					pending = append(pending, pendingMedia{botID: record.BotID, record: m})
+				}
+				resolved[key] = m
+			}
+			m.Name = a.Name
+			record.Media = append(record.Media, m)
+		}
+		if err := enc.Encode(record); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Media go after messages.jsonl: a zip entry must be finished before the
+	// next one is created.
+	for _, p := range pending {
+		if err := s.copyMedia(ctx, zw, p.botID, p.record); err != nil {
+			problems = append(problems, fmt.Sprintf("media %s for bot %s: %v", p.record.ContentHash, p.botID, err))
+		}
+	}
+	return botIDs, problems, nil
+}
+
+type pendingMedia struct {
+	botID  string
+	record MediaRecord
+}
+
+func (s *Service) messageRecord(ctx context.Context, r sqlc.ListMessagesForDataExportRow) (MessageRecord, error) {
+	content, displayText := r.Content, r.DisplayText.String
+	if r.ArchiveKey.Valid && s.archive != nil {
+		archived, err := s.archive.Load(ctx, r.ArchiveKey.String)
+		if err != nil {
+			return MessageRecord{}, fmt.Errorf("restore archived message %s: %w", r.ID.String(), err)
+		}
+		content = archived
+	}
+	if s.cipher != nil {
+		var err error
+		if content, err = s.cipher.OpenJSON(ctx, r.BotID, content); err != nil {
+			return MessageRecord{}, fmt.Errorf("open message %s: %w", r.ID.String(), err)
+		}
+		if displayText, err = s.cipher.OpenText(ctx, r.BotID, displayText); err != nil {
+			return MessageRecord{}, fmt.Errorf("open message %s: %w", r.ID.String(), err)
+		}
+	}
+	record := MessageRecord{
+		ID:          r.ID.String(),
+		BotID:       r.BotID.String(),
+		Platform:    r.Platform.String,
+		Role:        r.Role,
+		DisplayText: displayText,
+		CreatedAt:   r.CreatedAt.Time,
+	}
+	if len(content) > 0 {
+		record.Content = json.RawMessage(content)
+	}
+	if r.SessionID.Valid {
+		record.SessionID = r.SessionID.String()
+	}
+	if r.SenderChannelIdentityID.Valid {
+		record.SenderChannelIdentityID = r.SenderChannelIdentityID.String()
+	}
+	if r.SenderUserID.Valid {
+		record.SenderUserID = r.SenderUserID.String()
+	}
+	return record, nil
+}
+
+// resolveMedia fills in where the file will live inside the bundle:
+// media/<bot_id>/<content_hash><ext>.
+func (s *Service) resolveMedia(ctx context.Context, botID string, m *MediaRecord) error {
+	if s.media == nil {
+		return errors.New("media service not configured")
+	}
+	asset, err := s.media.Stat(ctx, botID, m.ContentHash)
+	if err != nil {
+		return err
+	}
+	m.Mime = asset.Mime
+	m.Path = mediaEntryName(botID, m.ContentHash, asset.StorageKey)
+	return nil
+}
+
+func (s *Service) copyMedia(ctx context.Context, zw *zip.Writer, botID string, m MediaRecord) error {
+	rc, _, err := s.media.Open(ctx, botID, m.ContentHash)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	// Media are usually already compressed; store them as-is.
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: m.Path, Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, rc)
+	return err
+}
+
+func mediaEntryName(botID, contentHash, storageKey string) string {
+	return mediaDir + botID + "/" + strings.ToLower(contentHash) + path.Ext(storageKey)
+}
+
+// collectMemories returns the bot's memories whose profile metadata names the
+// subject.
+func (s *Service) collectMemories(ctx context.Context, botID string, subj subject) ([]memprovider.MemoryItem, error) {
+	if s.memoryRegistry == nil {
+		return nil, nil
+	}
+	provider, err := memprovider.ResolveBotProvider(ctx, s.memoryRegistry, s.settingsService, botID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := provider.GetAll(ctx, memprovider.GetAllRequest{BotID: botID, NoStats: true})
+	if err != nil {
+		return nil, err
+	}
+	refs := map[string]struct{}{}
+	identityIDs := map[string]struct{}{}
+	if subj.userID != "" {
+		refs["user:"+subj.userID] = struct{}{}
+	}
+	for _, id := range subj.identityIDs {
+		identityIDs[id] = struct{}{}
+		refs["channel_identity:"+id] = struct{}{}
+	}
+	items := make([]memprovider.MemoryItem, 0)
+	for _, item := range all.Results {
+		_, refMatch := refs[metadataString(item.Metadata, "profile_ref")]
+		_, identityMatch := identityIDs[metadataString(item.Metadata, "profile_channel_identity_id")]
+		userMatch := subj.userID != "" && metadataString(item.Metadata, "profile_user_id") == subj.userID
+		if refMatch || identityMatch || userMatch {
+			if item.BotID == "" {
+				item.BotID = botID
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func writeJSONEntry(zw *zip.Writer, name string, value any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+func metadataString(metadata map[string]any, key string) string {
+	value, _ := metadata[key].(string)
+	return strings.TrimSpace(value)
+}
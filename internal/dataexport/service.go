@@ -0,0 +1,441 @@
+// Package dataexport builds data-subject export bundles: a zip holding every
+// message, memory, media file and profile record Memoh keeps about one user
+// or channel identity. Bundles are generated asynchronously by a background
+// worker and handed out through short-lived signed download URLs.
+package dataexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/channel/identities"
+	msgarchive "github.com/memohai/memoh/internal/chat/archive"
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/media"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+const (
+	SubjectUser            = "user"
+	SubjectChannelIdentity = "channel_identity"
+
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+
+	// DefaultRetention is how long a finished bundle stays downloadable
+	// before the worker deletes it.
+	DefaultRetention = 24 * time.Hour
+
+	queueSize     = 64
+	sweepInterval = 10 * time.Minute
+)
+
+var (
+	ErrJobNotFound     = errors.New("data export job not found")
+	ErrSubjectNotFound = errors.New("data export subject not found")
+	ErrBundleNotReady  = errors.New("data export bundle is not ready")
+	ErrInvalidSubject  = errors.New("invalid data export subject type")
+)
+
+// Job is the public view of a data export job.
+type Job struct {
+	ID                string    `json:"id"`
+	SubjectType       string    `json:"subject_type"`
+	SubjectID         string    `json:"subject_id"`
+	RequestedByUserID string    `json:"requested_by_user_id,omitempty"`
+	Status            string    `json:"status"`
+	Error             string    `json:"error,omitempty"`
+	SizeBytes         int64     `json:"size_bytes,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	CompletedAt       time.Time `json:"completed_at,omitempty"`
+	ExpiresAt         time.Time `json:"expires_at,omitempty"`
+	DownloadURL       string    `json:"download_url,omitempty"`
+}
+
+type jobQueries interface {
+	CreateDataExportJob(ctx context.Context, arg sqlc.CreateDataExportJobParams) (sqlc.DataExportJob, error)
+	GetDataExportJob(ctx context.Context, id pgtype.UUID) (sqlc.DataExportJob, error)
+	ListUnfinishedDataExportJobs(ctx context.Context) ([]sqlc.DataExportJob, error)
+	MarkDataExportJobRunning(ctx context.Context, id pgtype.UUID) (int64, error)
+	CompleteDataExportJob(ctx context.Context, arg sqlc.CompleteDataExportJobParams) error
+	FailDataExportJob(ctx context.Context, arg sqlc.FailDataExportJobParams) error
+	DeleteExpiredDataExportJobs(ctx context.Context, now pgtype.Timestamptz) ([]string, error)
+}
+
+type subjectQueries interface {
+	ListMessagesForDataExport(ctx context.Context, arg sqlc.ListMessagesForDataExportParams) ([]sqlc.ListMessagesForDataExportRow, error)
+}
+
+// Service queues, builds and serves data export bundles.
+type Service struct {
+	queries         dbstore.Queries
+	accounts        *accounts.Service
+	identities      *identities.Service
+	media           *media.Service
+	cipher          messagepkg.ContentCipher
+	archive         *msgarchive.Service
+	memoryRegistry  *memprovider.Registry
+	settingsService *settings.Service
+	dir             string
+	retention       time.Duration
+	logger          *slog.Logger
+	now             func() time.Time
+	queue           chan string
+}
+
+// NewService creates a data export service writing bundles under dir.
+func NewService(log *slog.Logger, queries dbstore.Queries, accountService *accounts.Service, identityService *identities.Service, dir string) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries:    queries,
+		accounts:   accountService,
+		identities: identityService,
+		dir:        dir,
+		retention:  DefaultRetention,
+		logger:     log.With(slog.String("service", "data_export")),
+		now:        time.Now,
+		queue:      make(chan string, queueSize),
+	}
+}
+
+// SetMediaService enables copying media files into bundles.
+func (s *Service) SetMediaService(svc *media.Service) {
+	s.media = svc
+}
+
+// SetContentCipher opens message content sealed at rest before it is written
+// to a bundle.
+func (s *Service) SetContentCipher(c messagepkg.ContentCipher) {
+	s.cipher = c
+}
+
+// SetMessageArchive restores message content moved to cold storage before
+// it is written to a bundle.
+func (s *Service) SetMessageArchive(svc *msgarchive.Service) {
+	s.archive = svc
+}
+
+// SetMemoryRegistry enables exporting memories formed about the subject.
+func (s *Service) SetMemoryRegistry(registry *memprovider.Registry) {
+	s.memoryRegistry = registry
+}
+
+// SetSettingsService sets the settings service used to resolve each bot's
+// memory provider.
+func (s *Service) SetSettingsService(svc *settings.Service) {
+	s.settingsService = svc
+}
+
+// Request records an export job for the subject and queues it for the
+// worker. The subject must exist.
+func (s *Service) Request(ctx context.Context, actorUserID, subjectType, subjectID string) (Job, error) {
+	store, err := s.jobStore()
+	if err != nil {
+		return Job{}, err
+	}
+	subjectID, err = s.resolveSubject(ctx, subjectType, subjectID)
+	if err != nil {
+		return Job{}, err
+	}
+	pgSubjectID, err := db.ParseUUID(subjectID)
+	if err != nil {
+		return Job{}, err
+	}
+	var pgActorID pgtype.UUID
+	if actorUserID != "" {
+		if pgActorID, err = db.ParseUUID(actorUserID); err != nil {
+			return Job{}, err
+		}
+	}
+	row, err := store.CreateDataExportJob(ctx, sqlc.CreateDataExportJobParams{
+		RequestedByUserID: pgActorID,
+		SubjectType:       subjectType,
+		SubjectID:         pgSubjectID,
+	})
+	if err != nil {
+		return Job{}, fmt.Errorf("create data export job: %w", err)
+	}
+	job := toJob(row)
+	s.enqueue(job.ID)
+	return job, nil
+}
+
+// Get returns the job with the given id.
+func (s *Service) Get(ctx context.Context, jobID string) (Job, error) {
+	row, err := s.getRow(ctx, jobID)
+	if err != nil {
+		return Job{}, err
+	}
+	return toJob(row), nil
+}
+
+// OpenBundle opens the finished bundle of a completed, unexpired job. The
+// caller closes the file.
+func (s *Service) OpenBundle(ctx context.Context, jobID string) (*os.File, Job, error) {
+	row, err := s.getRow(ctx, jobID)
+	if err != nil {
+		return nil, Job{}, err
+	}
+	job := toJob(row)
+	if job.Status != StatusCompleted || row.FilePath == "" || (!job.ExpiresAt.IsZero() && !s.now().Before(job.ExpiresAt)) {
+		return nil, job, ErrBundleNotReady
+	}
+	f, err := os.Open(row.FilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, job, ErrBundleNotReady
+		}
+		return nil, job, err
+	}
+	return f, job, nil
+}
+
+// Run processes queued jobs until done is closed. Jobs left pending or
+// running by a previous process are picked up first, and the periodic sweep
+// deletes expired bundles and retries jobs that did not fit in the queue.
+// Jobs are built one at a time.
+func (s *Service) Run(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	s.sweep(ctx)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.process(ctx, id)
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *Service) enqueue(jobID string) {
+	select {
+	case s.queue <- jobID:
+	default:
+		// The job stays pending in the database; the next sweep runs it.
+		s.logger.Warn("data export queue full, deferring job", slog.String("job_id", jobID))
+	}
+}
+
+func (s *Service) sweep(ctx context.Context) {
+	store, err := s.jobStore()
+	if err != nil {
+		s.logger.Error("data export sweep failed", slog.Any("error", err))
+		return
+	}
+	paths, err := store.DeleteExpiredDataExportJobs(ctx, pgtype.Timestamptz{Time: s.now().UTC(), Valid: true})
+	if err != nil {
+		s.logger.Warn("delete expired data exports failed", slog.Any("error", err))
+	}
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.Warn("remove expired data export failed", slog.String("path", p), slog.Any("error", err))
+		}
+	}
+	rows, err := store.ListUnfinishedDataExportJobs(ctx)
+	if err != nil {
+		s.logger.Warn("list unfinished data exports failed", slog.Any("error", err))
+		return
+	}
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return
+		}
+		s.process(ctx, row.ID.String())
+	}
+}
+
+// process builds one job's bundle. A job that is already finished (it was
+// both queued and picked up by a sweep) is skipped.
+func (s *Service) process(ctx context.Context, jobID string) {
+	store, err := s.jobStore()
+	if err != nil {
+		s.logger.Error("data export failed", slog.String("job_id", jobID), slog.Any("error", err))
+		return
+	}
+	row, err := s.getRow(ctx, jobID)
+	if err != nil {
+		s.logger.Warn("load data export job failed", slog.String("job_id", jobID), slog.Any("error", err))
+		return
+	}
+	claimed, err := store.MarkDataExportJobRunning(ctx, row.ID)
+	if err != nil || claimed == 0 {
+		return
+	}
+
+	path, size, err := s.build(ctx, row)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down: leave the job running so the next start retries it.
+			return
+		}
+		s.logger.Error("data export failed", slog.String("job_id", jobID), slog.Any("error", err))
+		if failErr := store.FailDataExportJob(context.WithoutCancel(ctx), sqlc.FailDataExportJobParams{
+			ID:    row.ID,
+			Error: err.Error(),
+		}); failErr != nil {
+			s.logger.Warn("mark data export failed", slog.String("job_id", jobID), slog.Any("error", failErr))
+		}
+		return
+	}
+	if err := store.CompleteDataExportJob(ctx, sqlc.CompleteDataExportJobParams{
+		ID:        row.ID,
+		FilePath:  path,
+		SizeBytes: size,
+		ExpiresAt: pgtype.Timestamptz{Time: s.now().UTC().Add(s.retention), Valid: true},
+	}); err != nil {
+		s.logger.Error("complete data export failed", slog.String("job_id", jobID), slog.Any("error", err))
+		_ = os.Remove(path)
+		return
+	}
+	s.logger.Info("data export completed", slog.String("job_id", jobID), slog.Int64("size_bytes", size))
+}
+
+// build writes the bundle to a temporary file and renames it into place once
+// complete, so a crash never leaves a truncated bundle behind a job row.
+func (s *Service) build(ctx context.Context, row sqlc.DataExportJob) (string, int64, error) {
+	if strings.TrimSpace(s.dir) == "" {
+		return "", 0, errors.New("data export directory not configured")
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", 0, fmt.Errorf("create export directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, "export-*.zip.tmp")
+	if err != nil {
+		return "", 0, fmt.Errorf("create export file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	writeErr := s.writeBundle(ctx, row, tmp)
+	closeErr := tmp.Close()
+	if writeErr != nil {
+		return "", 0, writeErr
+	}
+	if closeErr != nil {
+		return "", 0, closeErr
+	}
+	finalPath := filepath.Join(s.dir, row.ID.String()+".zip")
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		return "", 0, fmt.Errorf("finalize export file: %w", err)
+	}
+	info, err := os.Stat(finalPath)
+	if err != nil {
+		return "", 0, err
+	}
+	return finalPath, info.Size(), nil
+}
+
+// resolveSubject checks the subject exists and returns its canonical id.
+func (s *Service) resolveSubject(ctx context.Context, subjectType, subjectID string) (string, error) {
+	switch subjectType {
+	case SubjectUser:
+		if s.accounts == nil {
+			return "", errors.New("account service not configured")
+		}
+		account, err := s.accounts.Get(ctx, subjectID)
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				return "", ErrSubjectNotFound
+			}
+			return "", err
+		}
+		return account.ID, nil
+	case SubjectChannelIdentity:
+		if s.identities == nil {
+			return "", errors.New("identity service not configured")
+		}
+		identity, err := s.identities.GetByID(ctx, subjectID)
+		if err != nil {
+			if errors.Is(err, identities.ErrChannelIdentityNotFound) {
+				return "", ErrSubjectNotFound
+			}
+			return "", err
+		}
+		return identity.ID, nil
+	default:
+		return "", ErrInvalidSubject
+	}
+}
+
+func (s *Service) getRow(ctx context.Context, jobID string) (sqlc.DataExportJob, error) {
+	store, err := s.jobStore()
+	if err != nil {
+		return sqlc.DataExportJob{}, err
+	}
+	pgID, err := db.ParseUUID(jobID)
+	if err != nil {
+		return sqlc.DataExportJob{}, ErrJobNotFound
+	}
+	row, err := store.GetDataExportJob(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.DataExportJob{}, ErrJobNotFound
+		}
+		return sqlc.DataExportJob{}, err
+	}
+	return row, nil
+}
+
+func (s *Service) jobStore() (jobQueries, error) {
+	if s.queries == nil {
+		return nil, errors.New("data export service not configured")
+	}
+	store, ok := s.queries.(jobQueries)
+	if !ok {
+		return nil, errors.New("data export queries not supported by store")
+	}
+	return store, nil
+}
+
+func toJob(row sqlc.DataExportJob) Job {
+	job := Job{
+		ID:          row.ID.String(),
+		SubjectType: row.SubjectType,
+		SubjectID:   row.SubjectID.String(),
+		Status:      row.Status,
+		Error:       row.Error,
+		SizeBytes:   row.SizeBytes,
+		CreatedAt:   row.CreatedAt.Time,
+	}
+	if row.RequestedByUserID.Valid {
+		job.RequestedByUserID = row.RequestedByUserID.String()
+	}
+	if row.CompletedAt.Valid {
+		job.CompletedAt = row.CompletedAt.Time
+	}
+	if row.ExpiresAt.Valid {
+		job.ExpiresAt = row.ExpiresAt.Time
+	}
+	return job
+}
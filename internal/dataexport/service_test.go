@@ -0,0 +1,249 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	neturl "net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel/identities"
+	msgarchive "github.com/memohai/memoh/internal/chat/archive"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/storage/providers/localfs"
+)
+
+const (
+	testIdentityID = "11111111-1111-1111-1111-111111111111"
+	testBotID      = "22222222-2222-2222-2222-222222222222"
+	testActorID    = "33333333-3333-3333-3333-333333333333"
+	testJobID      = "44444444-4444-4444-4444-444444444444"
+)
+
+type fakeExportQueries struct {
+	dbstore.Queries
+
+	job      *sqlc.DataExportJob
+	messages []sqlc.ListMessagesForDataExportRow
+}
+
+func (*fakeExportQueries) GetChannelIdentityByID(_ context.Context, id pgtype.UUID) (sqlc.ChannelIdentity, error) {
+	if id.String() != testIdentityID {
+		return sqlc.ChannelIdentity{}, pgx.ErrNoRows
+	}
+	return sqlc.ChannelIdentity{
+		ID:               id,
+		ChannelType:      "telegram",
+		ChannelSubjectID: "12345",
+		DisplayName:      pgtype.Text{String: "Alice", Valid: true},
+	}, nil
+}
+
+func (f *fakeExportQueries) CreateDataExportJob(_ context.Context, arg sqlc.CreateDataExportJobParams) (sqlc.DataExportJob, error) {
+	f.job = &sqlc.DataExportJob{
+		ID:                db.ParseUUIDOrEmpty(testJobID),
+		RequestedByUserID: arg.RequestedByUserID,
+		SubjectType:       arg.SubjectType,
+		SubjectID:         arg.SubjectID,
+		Status:            StatusPending,
+	}
+	return *f.job, nil
+}
+
+func (f *fakeExportQueries) GetDataExportJob(_ context.Context, id pgtype.UUID) (sqlc.DataExportJob, error) {
+	if f.job == nil || f.job.ID != id {
+		return sqlc.DataExportJob{}, pgx.ErrNoRows
+	}
+	return *f.job, nil
+}
+
+func (f *fakeExportQueries) MarkDataExportJobRunning(context.Context, pgtype.UUID) (int64, error) {
+	if f.job.Status != StatusPending && f.job.Status != StatusRunning {
+		return 0, nil
+	}
+	f.job.Status = StatusRunning
+	return 1, nil
+}
+
+func (f *fakeExportQueries) CompleteDataExportJob(_ context.Context, arg sqlc.CompleteDataExportJobParams) error {
+	f.job.Status = StatusCompleted
+	f.job.FilePath = arg.FilePath
+	f.job.SizeBytes = arg.SizeBytes
+	f.job.ExpiresAt = arg.ExpiresAt
+	return nil
+}
+
+func (f *fakeExportQueries) FailDataExportJob(_ context.Context, arg sqlc.FailDataExportJobParams) error {
+	f.job.Status = StatusFailed
+	f.job.Error = arg.Error
+	return nil
+}
+
+func (*fakeExportQueries) ListUnfinishedDataExportJobs(context.Context) ([]sqlc.DataExportJob, error) {
+	return nil, nil
+}
+
+func (*fakeExportQueries) DeleteExpiredDataExportJobs(context.Context, pgtype.Timestamptz) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeExportQueries) ListMessagesForDataExport(context.Context, sqlc.ListMessagesForDataExportParams) ([]sqlc.ListMessagesForDataExportRow, error) {
+	return f.messages, nil
+}
+
+func (*fakeExportQueries) ListMessageAssetsBatch(context.Context, []pgtype.UUID) ([]sqlc.ListMessageAssetsBatchRow, error) {
+	return nil, nil
+}
+
+func TestRequestBuildsChannelIdentityBundle(t *testing.T) {
+	queries := &fakeExportQueries{
+		messages: []sqlc.ListMessagesForDataExportRow{{
+			ID:                      db.ParseUUIDOrEmpty("55555555-5555-5555-5555-555555555555"),
+			BotID:                   db.ParseUUIDOrEmpty(testBotID),
+			SenderChannelIdentityID: db.ParseUUIDOrEmpty(testIdentityID),
+			Role:                    "user",
+			Content:                 []byte(`{"role":"user","content":"hello"}`),
+			CreatedAt:               pgtype.Timestamptz{Time: time.Unix(1700000000, 0), Valid: true},
+		}},
+	}
+	svc := NewService(nil, queries, nil, identities.NewService(nil, queries), t.TempDir())
+
+	job, err := svc.Request(context.Background(), testActorID, SubjectChannelIdentity, testIdentityID)
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	if job.Status != StatusPending || job.RequestedByUserID != testActorID {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+	svc.process(context.Background(), <-svc.queue)
+	if queries.job.Status != StatusCompleted {
+		t.Fatalf("status = %q (%s), want completed", queries.job.Status, queries.job.Error)
+	}
+
+	f, done, err := svc.OpenBundle(context.Background(), testJobID)
+	if err != nil {
+		t.Fatalf("OpenBundle: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	if done.SizeBytes == 0 {
+		t.Fatal("bundle size not recorded")
+	}
+	zr, err := zip.NewReader(f, done.SizeBytes)
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+	entries := map[string]*zip.File{}
+	for _, file := range zr.File {
+		entries[file.Name] = file
+	}
+	for _, name := range []string{profileEntry, messagesEntry, memoriesEntry} {
+		if entries[name] == nil {
+			t.Fatalf("bundle missing %s", name)
+		}
+	}
+	rc, err := entries[messagesEntry].Open()
+	if err != nil {
+		t.Fatalf("open messages: %v", err)
+	}
+	defer func() { _ = rc.Close() }()
+	scanner := bufio.NewScanner(rc)
+	var lines []MessageRecord
+	for scanner.Scan() {
+		var record MessageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("decode message: %v", err)
+		}
+		lines = append(lines, record)
+	}
+	if len(lines) != 1 || lines[0].SenderChannelIdentityID != testIdentityID || !strings.Contains(string(lines[0].Content), "hello") {
+		t.Fatalf("unexpected messages: %+v", lines)
+	}
+
+	// A duplicate queue entry must not rebuild a finished job.
+	svc.process(context.Background(), testJobID)
+	if queries.job.Status != StatusCompleted {
+		t.Fatalf("status = %q after reprocessing", queries.job.Status)
+	}
+}
+
+func TestRequestUnknownSubject(t *testing.T) {
+	queries := &fakeExportQueries{}
+	svc := NewService(nil, queries, nil, identities.NewService(nil, queries), t.TempDir())
+
+	if _, err := svc.Request(context.Background(), testActorID, SubjectChannelIdentity, testBotID); !errors.Is(err, ErrSubjectNotFound) {
+		t.Fatalf("err = %v, want ErrSubjectNotFound", err)
+	}
+	if _, err := svc.Request(context.Background(), testActorID, "bot", testIdentityID); !errors.Is(err, ErrInvalidSubject) {
+		t.Fatalf("err = %v, want ErrInvalidSubject", err)
+	}
+}
+
+func TestSignerDownloadURL(t *testing.T) {
+	signer := NewSigner("secret")
+	now := time.Unix(1700000000, 0).UTC()
+	job := Job{ID: testJobID, Status: StatusCompleted, ExpiresAt: now.Add(10 * time.Minute)}
+
+	signed, ok := signer.SignedDownloadURL(job, now)
+	if !ok {
+		t.Fatal("expected signed url")
+	}
+	u, err := neturl.Parse(signed)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if u.Path != DownloadPath(testJobID) || !IsDownloadPath(u.Path) {
+		t.Fatalf("path = %q", u.Path)
+	}
+	if !signer.Validate(u.Path, u.Query(), now) {
+		t.Fatal("valid signature rejected")
+	}
+	// The link never outlives the bundle.
+	if signer.Validate(u.Path, u.Query(), job.ExpiresAt.Add(time.Second)) {
+		t.Fatal("signature accepted after bundle expiry")
+	}
+	if signer.Validate(DownloadPath("other"), u.Query(), now) {
+		t.Fatal("signature accepted for another job")
+	}
+	if _, ok := signer.SignedDownloadURL(Job{ID: testJobID, Status: StatusRunning}, now); ok {
+		t.Fatal("signed url issued for unfinished job")
+	}
+}
+
+func TestMessageRecordRestoresArchivedContent(t *testing.T) {
+	objects := localfs.New(t.TempDir())
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(`{"role":"user","content":"the full message"}`))
+	_ = zw.Close()
+	if err := objects.Put(context.Background(), "messages/archived.json.gz", &buf); err != nil {
+		t.Fatalf("put object: %v", err)
+	}
+	queries := &fakeExportQueries{}
+	svc := NewService(nil, queries, nil, identities.NewService(nil, queries), t.TempDir())
+	svc.SetMessageArchive(msgarchive.NewService(nil, queries, objects))
+
+	record, err := svc.messageRecord(context.Background(), sqlc.ListMessagesForDataExportRow{
+		ID:         db.ParseUUIDOrEmpty("55555555-5555-5555-5555-555555555555"),
+		BotID:      db.ParseUUIDOrEmpty(testBotID),
+		Role:       "user",
+		Content:    []byte(`{"role":"user","content":"the full…"}`),
+		ArchiveKey: pgtype.Text{String: "messages/archived.json.gz", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("messageRecord: %v", err)
+	}
+	if string(record.Content) != `{"role":"user","content":"the full message"}` {
+		t.Fatalf("content = %s, want archived content", record.Content)
+	}
+}
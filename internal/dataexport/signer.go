@@ -0,0 +1,97 @@
+package dataexport
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DownloadURLTTL bounds how long a handed-out download link works. The
+	// link never outlives the bundle itself.
+	DownloadURLTTL = time.Hour
+
+	QueryExpires   = "exp"
+	QuerySignature = "sig"
+
+	downloadPathPrefix = "/data-exports/"
+	downloadPathSuffix = "/download"
+)
+
+// DownloadPath is the unauthenticated path serving a job's bundle.
+func DownloadPath(jobID string) string {
+	return downloadPathPrefix + neturl.PathEscape(jobID) + downloadPathSuffix
+}
+
+// IsDownloadPath reports whether path is a bundle download path. Requests to
+// it carry a signature instead of a session token.
+func IsDownloadPath(path string) bool {
+	if !strings.HasPrefix(path, downloadPathPrefix) || !strings.HasSuffix(path, downloadPathSuffix) {
+		return false
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(path, downloadPathPrefix), downloadPathSuffix)
+	return id != "" && !strings.Contains(id, "/")
+}
+
+// Signer signs bundle download paths with the server secret, the same way
+// public channel media links are signed.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner returns nil when secret is empty; a nil signer signs nothing and
+// validates nothing.
+func NewSigner(secret string) *Signer {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return nil
+	}
+	return &Signer{secret: []byte(secret)}
+}
+
+// SignedDownloadURL returns the job's download path with an expiry and
+// signature. The link expires after DownloadURLTTL or when the bundle does,
+// whichever comes first.
+func (s *Signer) SignedDownloadURL(job Job, now time.Time) (string, bool) {
+	if s == nil || job.Status != StatusCompleted {
+		return "", false
+	}
+	expires := now.UTC().Add(DownloadURLTTL)
+	if !job.ExpiresAt.IsZero() && job.ExpiresAt.Before(expires) {
+		expires = job.ExpiresAt
+	}
+	if !expires.After(now) {
+		return "", false
+	}
+	path := DownloadPath(job.ID)
+	values := neturl.Values{}
+	values.Set(QueryExpires, strconv.FormatInt(expires.Unix(), 10))
+	values.Set(QuerySignature, s.signature(path, expires.Unix()))
+	return path + "?" + values.Encode(), true
+}
+
+// Validate checks a download request's signature and expiry.
+func (s *Signer) Validate(path string, query neturl.Values, now time.Time) bool {
+	if s == nil || !IsDownloadPath(path) {
+		return false
+	}
+	expires, err := strconv.ParseInt(strings.TrimSpace(query.Get(QueryExpires)), 10, 64)
+	if err != nil || expires <= 0 || now.UTC().Unix() > expires {
+		return false
+	}
+	sig := strings.TrimSpace(query.Get(QuerySignature))
+	return sig != "" && hmac.Equal([]byte(sig), []byte(s.signature(path, expires)))
+}
+
+func (s *Signer) signature(path string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("data-export\n"))
+	mac.Write([]byte(path))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
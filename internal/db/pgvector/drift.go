@@ -0,0 +1,214 @@
+package pgvector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// expectedTable describes the objects a migrated pgvector table must have.
+// Migrations only run once per version, so objects dropped by hand after
+// that (or lost in a partial restore) are caught here instead.
+type expectedTable struct {
+	name     string
+	indexes  map[string]string
+	policies []string
+}
+
+var expectedSchema = []expectedTable{
+	{
+		name: "memory_node_embeddings",
+		indexes: map[string]string{
+			"idx_memory_node_embeddings_team_bot_model": "CREATE INDEX IF NOT EXISTS idx_memory_node_embeddings_team_bot_model ON public.memory_node_embeddings (team_id, bot_id, model_id)",
+		},
+		policies: []string{
+			"memory_node_embeddings_team_select",
+			"memory_node_embeddings_team_insert",
+			"memory_node_embeddings_team_update",
+			"memory_node_embeddings_team_delete",
+		},
+	},
+	{
+		name: "knowledge_chunk_embeddings",
+		indexes: map[string]string{
+			"idx_knowledge_chunk_embeddings_team_collection_model": "CREATE INDEX IF NOT EXISTS idx_knowledge_chunk_embeddings_team_collection_model ON public.knowledge_chunk_embeddings (team_id, collection_id, model_id)",
+		},
+		policies: []string{
+			"knowledge_chunk_embeddings_team_select",
+			"knowledge_chunk_embeddings_team_insert",
+			"knowledge_chunk_embeddings_team_update",
+			"knowledge_chunk_embeddings_team_delete",
+		},
+	},
+	{
+		name: "history_message_embeddings",
+		indexes: map[string]string{
+			"idx_history_message_embeddings_team_bot_model": "CREATE INDEX IF NOT EXISTS idx_history_message_embeddings_team_bot_model ON public.history_message_embeddings (team_id, bot_id, model_id)",
+		},
+		policies: []string{
+			"history_message_embeddings_team_select",
+			"history_message_embeddings_team_insert",
+			"history_message_embeddings_team_update",
+			"history_message_embeddings_team_delete",
+		},
+	},
+}
+
+// tableState is what the database reports for one expected table.
+type tableState struct {
+	exists      bool
+	rlsEnabled  bool
+	rlsForced   bool
+	indexes     map[string]bool
+	policyNames map[string]bool
+}
+
+// schemaRepair is a statement that restores a drifted object without
+// touching data.
+type schemaRepair struct {
+	description string
+	statement   string
+}
+
+// planSchemaRepairs compares observed state with expectedSchema. Missing
+// indexes and disabled row-level security are repaired in place; missing
+// tables or policies cannot be recreated safely outside the migration
+// stream and are reported as problems.
+func planSchemaRepairs(observed map[string]tableState) ([]schemaRepair, []string) {
+	var (
+		repairs  []schemaRepair
+		problems []string
+	)
+	for _, table := range expectedSchema {
+		state := observed[table.name]
+		if !state.exists {
+			problems = append(problems, fmt.Sprintf("table %s is missing", table.name))
+			continue
+		}
+		qualified := "public." + table.name
+		if !state.rlsEnabled {
+			repairs = append(repairs, schemaRepair{
+				description: table.name + ": row level security disabled",
+				statement:   "ALTER TABLE " + qualified + " ENABLE ROW LEVEL SECURITY",
+			})
+		}
+		if !state.rlsForced {
+			repairs = append(repairs, schemaRepair{
+				description: table.name + ": row level security not forced",
+				statement:   "ALTER TABLE " + qualified + " FORCE ROW LEVEL SECURITY",
+			})
+		}
+		for _, index := range sortedKeys(table.indexes) {
+			if !state.indexes[index] {
+				repairs = append(repairs, schemaRepair{
+					description: table.name + ": index " + index + " missing",
+					statement:   table.indexes[index],
+				})
+			}
+		}
+		var missing []string
+		for _, policy := range table.policies {
+			if !state.policyNames[policy] {
+				missing = append(missing, policy)
+			}
+		}
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("table %s is missing policies %s", table.name, strings.Join(missing, ", ")))
+		}
+	}
+	return repairs, problems
+}
+
+// reconcileSchema detects drift between the migrated schema and the
+// database, applies the safe repairs and fails on the rest.
+func reconcileSchema(ctx context.Context, logger *slog.Logger, pool *pgxpool.Pool) error {
+	observed, err := readSchemaState(ctx, pool)
+	if err != nil {
+		return fmt.Errorf("pgvector schema check: %w", err)
+	}
+	repairs, problems := planSchemaRepairs(observed)
+	if len(problems) > 0 {
+		return fmt.Errorf("pgvector schema drift: %s; re-run the pgvector migrations from an earlier version", strings.Join(problems, "; "))
+	}
+	for _, repair := range repairs {
+		logger.Warn("pgvector schema drift, repairing", slog.String("drift", repair.description))
+		if _, err := pool.Exec(ctx, repair.statement); err != nil {
+			return fmt.Errorf("pgvector schema repair (%s): %w", repair.description, err)
+		}
+	}
+	return nil
+}
+
+func readSchemaState(ctx context.Context, pool *pgxpool.Pool) (map[string]tableState, error) {
+	names := make([]string, 0, len(expectedSchema))
+	for _, table := range expectedSchema {
+		names = append(names, table.name)
+	}
+	observed := make(map[string]tableState, len(names))
+	rows, err := pool.Query(ctx, `
+		SELECT c.relname, c.relrowsecurity, c.relforcerowsecurity
+		  FROM pg_class c
+		  JOIN pg_namespace n ON n.oid = c.relnamespace
+		 WHERE n.nspname = 'public' AND c.relkind = 'r' AND c.relname = ANY($1)`, names)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		state := tableState{exists: true, indexes: map[string]bool{}, policyNames: map[string]bool{}}
+		if err := rows.Scan(&name, &state.rlsEnabled, &state.rlsForced); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		observed[name] = state
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := collectNames(ctx, pool, `SELECT tablename, indexname FROM pg_indexes WHERE schemaname = 'public' AND tablename = ANY($1)`, names, func(table, name string) {
+		if state, ok := observed[table]; ok {
+			state.indexes[name] = true
+		}
+	}); err != nil {
+		return nil, err
+	}
+	if err := collectNames(ctx, pool, `SELECT tablename, policyname FROM pg_policies WHERE schemaname = 'public' AND tablename = ANY($1)`, names, func(table, name string) {
+		if state, ok := observed[table]; ok {
+			state.policyNames[name] = true
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return observed, nil
+}
+
+func collectNames(ctx context.Context, pool *pgxpool.Pool, query string, tables []string, add func(table, name string)) error {
+	rows, err := pool.Query(ctx, query, tables)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, name string
+		if err := rows.Scan(&table, &name); err != nil {
+			return err
+		}
+		add(table, name)
+	}
+	return rows.Err()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,101 @@
+package pgvector
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func healthyState() map[string]tableState {
+	observed := map[string]tableState{}
+	for _, table := range expectedSchema {
+		state := tableState{exists: true, rlsEnabled: true, rlsForced: true, indexes: map[string]bool{}, policyNames: map[string]bool{}}
+		for index := range table.indexes {
+			state.indexes[index] = true
+		}
+		for _, policy := range table.policies {
+			state.policyNames[policy] = true
+		}
+		observed[table.name] = state
+	}
+	return observed
+}
+
+func TestPlanSchemaRepairsHealthy(t *testing.T) {
+	t.Parallel()
+	repairs, problems := planSchemaRepairs(healthyState())
+	if len(repairs) != 0 || len(problems) != 0 {
+		t.Fatalf("healthy schema: repairs=%v problems=%v", repairs, problems)
+	}
+}
+
+func TestPlanSchemaRepairsFixesIndexesAndRLS(t *testing.T) {
+	t.Parallel()
+	observed := healthyState()
+	state := observed["memory_node_embeddings"]
+	state.rlsForced = false
+	delete(state.indexes, "idx_memory_node_embeddings_team_bot_model")
+	observed["memory_node_embeddings"] = state
+
+	repairs, problems := planSchemaRepairs(observed)
+	if len(problems) != 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+	if len(repairs) != 2 {
+		t.Fatalf("expected 2 repairs, got %+v", repairs)
+	}
+	if !strings.Contains(repairs[0].statement, "FORCE ROW LEVEL SECURITY") {
+		t.Fatalf("expected RLS repair first, got %q", repairs[0].statement)
+	}
+	if !strings.HasPrefix(repairs[1].statement, "CREATE INDEX IF NOT EXISTS idx_memory_node_embeddings_team_bot_model") {
+		t.Fatalf("expected index repair, got %q", repairs[1].statement)
+	}
+}
+
+func TestPlanSchemaRepairsReportsUnsafeDrift(t *testing.T) {
+	t.Parallel()
+	observed := healthyState()
+	delete(observed, "knowledge_chunk_embeddings")
+	delete(observed["memory_node_embeddings"].policyNames, "memory_node_embeddings_team_delete")
+
+	_, problems := planSchemaRepairs(observed)
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got %v", problems)
+	}
+}
+
+func TestExpectedSchemaMatchesMigrations(t *testing.T) {
+	t.Parallel()
+	migrations, err := MigrationsFS()
+	if err != nil {
+		t.Fatalf("MigrationsFS: %v", err)
+	}
+	var all strings.Builder
+	entries, err := fs.ReadDir(migrations, ".")
+	if err != nil {
+		t.Fatalf("read migrations: %v", err)
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		data, err := fs.ReadFile(migrations, entry.Name())
+		if err != nil {
+			t.Fatalf("read %s: %v", entry.Name(), err)
+		}
+		all.Write(data)
+	}
+	sql := all.String()
+	for _, table := range expectedSchema {
+		for index := range table.indexes {
+			if !strings.Contains(sql, index) {
+				t.Fatalf("expected index %s is not created by any migration", index)
+			}
+		}
+		for _, policy := range table.policies {
+			if !strings.Contains(sql, "CREATE POLICY "+policy) {
+				t.Fatalf("expected policy %s is not created by any migration", policy)
+			}
+		}
+	}
+}
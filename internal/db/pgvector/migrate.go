@@ -1,6 +1,7 @@
 package pgvector
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"log/slog"
@@ -13,7 +14,7 @@ import (
 const migrationsPath = "pgvector/migrations"
 
 // SchemaVersion is the newest pgvector migration understood by this binary.
-const SchemaVersion = uint(2)
+const SchemaVersion = uint(4)
 
 // MigrationsFS returns the independently versioned pgvector migration set.
 func MigrationsFS() (fs.FS, error) {
@@ -39,6 +40,15 @@ func MigrateUp(logger *slog.Logger, cfg config.PGVectorConfig) error {
 	if err := validateMigrationStatus(status, false); err != nil {
 		return err
 	}
+	if status.Version < SchemaVersion {
+		report, err := db.PreflightPostgres(context.Background(), cfg.PostgresConfig(), "vector")
+		if err != nil {
+			return fmt.Errorf("pgvector preflight: %w", err)
+		}
+		if err := report.Err(); err != nil {
+			return fmt.Errorf("pgvector: %w", err)
+		}
+	}
 	if err := db.RunMigrate(logger, cfg.PostgresConfig(), migrations, "up", nil); err != nil {
 		return fmt.Errorf("pgvector migrations: %w", err)
 	}
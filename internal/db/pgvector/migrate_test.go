@@ -189,11 +189,12 @@ INSERT INTO public.memory_node_embeddings (
 		t.Fatalf("sqlc upsert: %v", err)
 	}
 	rows, err := queries.SearchMemoryNodeEmbeddings(ctx, pgvectorsqlc.SearchMemoryNodeEmbeddingsParams{
-		Embedding: pgvector.NewVector([]float32{1, 0}),
-		TeamID:    teamID,
-		BotID:     botID,
-		ModelID:   modelID,
-		RowLimit:  5,
+		Embedding:  pgvector.NewVector([]float32{1, 0}),
+		TeamID:     teamID,
+		BotID:      botID,
+		ModelID:    modelID,
+		Dimensions: 2,
+		RowLimit:   5,
 	})
 	if err != nil {
 		t.Fatalf("sqlc search: %v", err)
@@ -91,16 +91,18 @@ FROM public.memory_node_embeddings
 WHERE team_id = $2
   AND bot_id = $3
   AND model_id = $4
+  AND dimensions = $5
 ORDER BY embedding <=> $1::vector
-LIMIT $5
+LIMIT $6
 `
 
 type SearchMemoryNodeEmbeddingsParams struct {
-	Embedding pgvector_go.Vector `json:"embedding"`
-	TeamID    pgtype.UUID        `json:"team_id"`
-	BotID     pgtype.UUID        `json:"bot_id"`
-	ModelID   pgtype.UUID        `json:"model_id"`
-	RowLimit  int32              `json:"row_limit"`
+	Embedding  pgvector_go.Vector `json:"embedding"`
+	TeamID     pgtype.UUID        `json:"team_id"`
+	BotID      pgtype.UUID        `json:"bot_id"`
+	ModelID    pgtype.UUID        `json:"model_id"`
+	Dimensions int32              `json:"dimensions"`
+	RowLimit   int32              `json:"row_limit"`
 }
 
 type SearchMemoryNodeEmbeddingsRow struct {
@@ -114,6 +116,7 @@ func (q *Queries) SearchMemoryNodeEmbeddings(ctx context.Context, arg SearchMemo
 		arg.TeamID,
 		arg.BotID,
 		arg.ModelID,
+		arg.Dimensions,
 		arg.RowLimit,
 	)
 	if err != nil {
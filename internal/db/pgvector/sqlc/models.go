@@ -9,6 +9,29 @@ import (
 	pgvector_go "github.com/pgvector/pgvector-go"
 )
 
+type HistoryMessageEmbedding struct {
+	TeamID     pgtype.UUID        `json:"team_id"`
+	BotID      pgtype.UUID        `json:"bot_id"`
+	MessageID  pgtype.UUID        `json:"message_id"`
+	ModelID    pgtype.UUID        `json:"model_id"`
+	SessionID  pgtype.UUID        `json:"session_id"`
+	Dimensions int32              `json:"dimensions"`
+	Embedding  pgvector_go.Vector `json:"embedding"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type KnowledgeChunkEmbedding struct {
+	TeamID       pgtype.UUID        `json:"team_id"`
+	CollectionID pgtype.UUID        `json:"collection_id"`
+	DocumentID   pgtype.UUID        `json:"document_id"`
+	ChunkIndex   int32              `json:"chunk_index"`
+	ModelID      pgtype.UUID        `json:"model_id"`
+	Dimensions   int32              `json:"dimensions"`
+	Content      string             `json:"content"`
+	Embedding    pgvector_go.Vector `json:"embedding"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
 type MemoryNodeEmbedding struct {
 	BotID      pgtype.UUID        `json:"bot_id"`
 	NodeID     string             `json:"node_id"`
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,48 +16,100 @@ import (
 	pgvectorsqlc "github.com/memohai/memoh/internal/db/pgvector/sqlc"
 )
 
+// ErrNotReady is returned while a store opened with NewPending has not yet
+// reached its database.
+var ErrNotReady = errors.New("pgvector: store is not ready")
+
 // Store is the shared typed connection to the optional pgvector database.
-// Schema migration and vector type registration happen once when it opens.
+// Schema migration, drift repair and vector type registration happen once
+// when it opens. A pending store (NewPending) reports ErrNotReady until
+// Connect succeeds, so callers built against it start working without a
+// restart once the database comes up.
 type Store struct {
+	conn atomic.Pointer[storeConn]
+}
+
+type storeConn struct {
 	pool    *pgxpool.Pool
 	queries *pgvectorsqlc.Queries
 }
 
 func Open(ctx context.Context, logger *slog.Logger, cfg config.PGVectorConfig) (*Store, error) {
-	if err := MigrateUp(logger, cfg); err != nil {
+	s := NewPending()
+	if err := s.Connect(ctx, logger, cfg); err != nil {
 		return nil, err
 	}
+	return s, nil
+}
+
+// NewPending returns a store that is not connected yet.
+func NewPending() *Store {
+	return &Store{}
+}
+
+// Connect migrates and opens the database behind a pending store. It is a
+// no-op once the store is ready.
+func (s *Store) Connect(ctx context.Context, logger *slog.Logger, cfg config.PGVectorConfig) error {
+	if s.Ready() {
+		return nil
+	}
+	if err := MigrateUp(logger, cfg); err != nil {
+		return err
+	}
 	poolCfg, err := pgxpool.ParseConfig(db.DSN(cfg.PostgresConfig()))
 	if err != nil {
-		return nil, fmt.Errorf("pgvector: parse dsn: %w", err)
+		return fmt.Errorf("pgvector: parse dsn: %w", err)
 	}
 	poolCfg.AfterConnect = pgxvec.RegisterTypes
 	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
-		return nil, fmt.Errorf("pgvector: connect: %w", err)
+		return fmt.Errorf("pgvector: connect: %w", err)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if err := reconcileSchema(ctx, logger, pool); err != nil {
+		pool.Close()
+		return err
 	}
-	return &Store{
-		pool:    pool,
-		queries: pgvectorsqlc.New(pool),
-	}, nil
+	if !s.conn.CompareAndSwap(nil, &storeConn{pool: pool, queries: pgvectorsqlc.New(pool)}) {
+		pool.Close()
+	}
+	return nil
+}
+
+// Ready reports whether the store has reached its database.
+func (s *Store) Ready() bool {
+	return s != nil && s.conn.Load() != nil
 }
 
+// Queries returns nil while the store is not ready.
 func (s *Store) Queries() *pgvectorsqlc.Queries {
 	if s == nil {
 		return nil
 	}
-	return s.queries
+	if conn := s.conn.Load(); conn != nil {
+		return conn.queries
+	}
+	return nil
 }
 
 func (s *Store) Begin(ctx context.Context) (pgx.Tx, error) {
-	if s == nil || s.pool == nil {
+	if s == nil {
 		return nil, errors.New("pgvector: store is not open")
 	}
-	return s.pool.Begin(ctx)
+	conn := s.conn.Load()
+	if conn == nil {
+		return nil, ErrNotReady
+	}
+	return conn.pool.Begin(ctx)
 }
 
 func (s *Store) Close() {
-	if s != nil && s.pool != nil {
-		s.pool.Close()
+	if s == nil {
+		return
+	}
+	if conn := s.conn.Swap(nil); conn != nil {
+		conn.pool.Close()
 	}
 }
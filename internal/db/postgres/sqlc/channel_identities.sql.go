@@ -48,6 +48,19 @@ func (q *Queries) CreateChannelIdentity(ctx context.Context, arg CreateChannelId
 	return i, err
 }
 
+const deleteChannelIdentity = `-- name: DeleteChannelIdentity :execrows
+DELETE FROM channel_identities
+WHERE team_id = public.memoh_current_team_id() AND id = $1
+`
+
+func (q *Queries) DeleteChannelIdentity(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteChannelIdentity, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getChannelIdentityByChannelSubject = `-- name: GetChannelIdentityByChannelSubject :one
 SELECT id, channel_type, channel_subject_id, display_name, avatar_url, metadata, created_at, updated_at, team_id
 FROM channel_identities
@@ -36,6 +36,9 @@ RETURNING
   default_reply_target AS reply_target,
   active_session_id,
   metadata,
+  status,
+  inactive_reason,
+  inactive_at,
   created_at,
   updated_at
 `
@@ -62,6 +65,9 @@ type CreateChatRouteRow struct {
 	ReplyTarget      pgtype.Text        `json:"reply_target"`
 	ActiveSessionID  pgtype.UUID        `json:"active_session_id"`
 	Metadata         []byte             `json:"metadata"`
+	Status           string             `json:"status"`
+	InactiveReason   string             `json:"inactive_reason"`
+	InactiveAt       pgtype.Timestamptz `json:"inactive_at"`
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 }
@@ -89,6 +95,9 @@ func (q *Queries) CreateChatRoute(ctx context.Context, arg CreateChatRouteParams
 		&i.ReplyTarget,
 		&i.ActiveSessionID,
 		&i.Metadata,
+		&i.Status,
+		&i.InactiveReason,
+		&i.InactiveAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -115,6 +124,40 @@ func (q *Queries) DeleteChatRoute(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteInactiveChatRoutesBefore = `-- name: DeleteInactiveChatRoutesBefore :execrows
+WITH stale_routes AS MATERIALIZED (
+  SELECT route.id
+  FROM bot_channel_routes route
+  WHERE route.team_id = public.memoh_current_team_id()
+    AND route.status = 'inactive'
+    AND route.inactive_at < $1
+),
+route_sessions AS MATERIALIZED (
+  SELECT session.id
+  FROM bot_sessions session
+  WHERE session.team_id = public.memoh_current_team_id()
+    AND session.route_id IN (SELECT stale_routes.id FROM stale_routes)
+  ORDER BY session.id
+  FOR UPDATE
+)
+DELETE FROM bot_channel_routes route
+WHERE route.team_id = public.memoh_current_team_id()
+  AND route.id IN (SELECT stale_routes.id FROM stale_routes)
+  AND route.status = 'inactive'
+  AND route.inactive_at < $1
+  AND (SELECT count(*) FROM route_sessions) >= 0
+`
+
+// Lock the routes' sessions before the routes, in the same order as
+// DeleteChatRoute, so pruning cannot deadlock with a concurrent delete.
+func (q *Queries) DeleteInactiveChatRoutesBefore(ctx context.Context, before pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteInactiveChatRoutesBefore, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const findChatRoute = `-- name: FindChatRoute :one
 SELECT
   id,
@@ -127,6 +170,9 @@ SELECT
   default_reply_target AS reply_target,
   active_session_id,
   metadata,
+  status,
+  inactive_reason,
+  inactive_at,
   created_at,
   updated_at
 FROM bot_channel_routes
@@ -156,6 +202,9 @@ type FindChatRouteRow struct {
 	ReplyTarget      pgtype.Text        `json:"reply_target"`
 	ActiveSessionID  pgtype.UUID        `json:"active_session_id"`
 	Metadata         []byte             `json:"metadata"`
+	Status           string             `json:"status"`
+	InactiveReason   string             `json:"inactive_reason"`
+	InactiveAt       pgtype.Timestamptz `json:"inactive_at"`
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 }
@@ -179,6 +228,9 @@ func (q *Queries) FindChatRoute(ctx context.Context, arg FindChatRouteParams) (F
 		&i.ReplyTarget,
 		&i.ActiveSessionID,
 		&i.Metadata,
+		&i.Status,
+		&i.InactiveReason,
+		&i.InactiveAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -197,6 +249,9 @@ SELECT
   default_reply_target AS reply_target,
   active_session_id,
   metadata,
+  status,
+  inactive_reason,
+  inactive_at,
   created_at,
   updated_at
 FROM bot_channel_routes
@@ -214,6 +269,9 @@ type GetChatRouteByIDRow struct {
 	ReplyTarget      pgtype.Text        `json:"reply_target"`
 	ActiveSessionID  pgtype.UUID        `json:"active_session_id"`
 	Metadata         []byte             `json:"metadata"`
+	Status           string             `json:"status"`
+	InactiveReason   string             `json:"inactive_reason"`
+	InactiveAt       pgtype.Timestamptz `json:"inactive_at"`
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 }
@@ -232,6 +290,9 @@ func (q *Queries) GetChatRouteByID(ctx context.Context, id pgtype.UUID) (GetChat
 		&i.ReplyTarget,
 		&i.ActiveSessionID,
 		&i.Metadata,
+		&i.Status,
+		&i.InactiveReason,
+		&i.InactiveAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -292,6 +353,9 @@ SELECT
   default_reply_target AS reply_target,
   active_session_id,
   metadata,
+  status,
+  inactive_reason,
+  inactive_at,
   created_at,
   updated_at
 FROM bot_channel_routes
@@ -310,6 +374,9 @@ type ListChatRoutesRow struct {
 	ReplyTarget      pgtype.Text        `json:"reply_target"`
 	ActiveSessionID  pgtype.UUID        `json:"active_session_id"`
 	Metadata         []byte             `json:"metadata"`
+	Status           string             `json:"status"`
+	InactiveReason   string             `json:"inactive_reason"`
+	InactiveAt       pgtype.Timestamptz `json:"inactive_at"`
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 }
@@ -334,6 +401,9 @@ func (q *Queries) ListChatRoutes(ctx context.Context, botID pgtype.UUID) ([]List
 			&i.ReplyTarget,
 			&i.ActiveSessionID,
 			&i.Metadata,
+			&i.Status,
+			&i.InactiveReason,
+			&i.InactiveAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -347,6 +417,138 @@ func (q *Queries) ListChatRoutes(ctx context.Context, botID pgtype.UUID) ([]List
 	return items, nil
 }
 
+const listInactiveChatRoutes = `-- name: ListInactiveChatRoutes :many
+SELECT
+  id,
+  bot_id,
+  channel_type AS platform,
+  channel_config_id,
+  external_conversation_id AS conversation_id,
+  external_thread_id AS thread_id,
+  conversation_type,
+  default_reply_target AS reply_target,
+  active_session_id,
+  metadata,
+  status,
+  inactive_reason,
+  inactive_at,
+  created_at,
+  updated_at
+FROM bot_channel_routes
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = $1
+  AND status = 'inactive'
+ORDER BY inactive_at DESC
+`
+
+type ListInactiveChatRoutesRow struct {
+	ID               pgtype.UUID        `json:"id"`
+	BotID            pgtype.UUID        `json:"bot_id"`
+	Platform         string             `json:"platform"`
+	ChannelConfigID  pgtype.UUID        `json:"channel_config_id"`
+	ConversationID   string             `json:"conversation_id"`
+	ThreadID         pgtype.Text        `json:"thread_id"`
+	ConversationType pgtype.Text        `json:"conversation_type"`
+	ReplyTarget      pgtype.Text        `json:"reply_target"`
+	ActiveSessionID  pgtype.UUID        `json:"active_session_id"`
+	Metadata         []byte             `json:"metadata"`
+	Status           string             `json:"status"`
+	InactiveReason   string             `json:"inactive_reason"`
+	InactiveAt       pgtype.Timestamptz `json:"inactive_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) ListInactiveChatRoutes(ctx context.Context, botID pgtype.UUID) ([]ListInactiveChatRoutesRow, error) {
+	rows, err := q.db.Query(ctx, listInactiveChatRoutes, botID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListInactiveChatRoutesRow
+	for rows.Next() {
+		var i ListInactiveChatRoutesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BotID,
+			&i.Platform,
+			&i.ChannelConfigID,
+			&i.ConversationID,
+			&i.ThreadID,
+			&i.ConversationType,
+			&i.ReplyTarget,
+			&i.ActiveSessionID,
+			&i.Metadata,
+			&i.Status,
+			&i.InactiveReason,
+			&i.InactiveAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markChatRoutesInactive = `-- name: MarkChatRoutesInactive :execrows
+UPDATE bot_channel_routes
+SET status = 'inactive', inactive_reason = $1, inactive_at = now(), updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = $2
+  AND channel_type = $3
+  AND external_conversation_id = $4
+  AND status = 'active'
+`
+
+type MarkChatRoutesInactiveParams struct {
+	Reason         string      `json:"reason"`
+	BotID          pgtype.UUID `json:"bot_id"`
+	Platform       string      `json:"platform"`
+	ConversationID string      `json:"conversation_id"`
+}
+
+func (q *Queries) MarkChatRoutesInactive(ctx context.Context, arg MarkChatRoutesInactiveParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markChatRoutesInactive,
+		arg.Reason,
+		arg.BotID,
+		arg.Platform,
+		arg.ConversationID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reactivateChatRoutes = `-- name: ReactivateChatRoutes :execrows
+UPDATE bot_channel_routes
+SET status = 'active', inactive_reason = '', inactive_at = NULL, updated_at = now()
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = $1
+  AND channel_type = $2
+  AND external_conversation_id = $3
+  AND status = 'inactive'
+`
+
+type ReactivateChatRoutesParams struct {
+	BotID          pgtype.UUID `json:"bot_id"`
+	Platform       string      `json:"platform"`
+	ConversationID string      `json:"conversation_id"`
+}
+
+func (q *Queries) ReactivateChatRoutes(ctx context.Context, arg ReactivateChatRoutesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reactivateChatRoutes, arg.BotID, arg.Platform, arg.ConversationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const setRouteActiveSession = `-- name: SetRouteActiveSession :exec
 WITH destination_session AS MATERIALIZED (
   SELECT session.id
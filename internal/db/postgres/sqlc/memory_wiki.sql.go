@@ -55,6 +55,40 @@ func (q *Queries) DeleteAllMemoryNodesByBot(ctx context.Context, botID pgtype.UU
 	return err
 }
 
+const deleteExpiredMemoryNodes = `-- name: DeleteExpiredMemoryNodes :many
+DELETE FROM memory_nodes
+WHERE team_id = public.memoh_current_team_id()
+  AND bot_id = $1
+  AND expires_at IS NOT NULL
+  AND expires_at <= $2
+RETURNING id
+`
+
+type DeleteExpiredMemoryNodesParams struct {
+	BotID pgtype.UUID        `json:"bot_id"`
+	Now   pgtype.Timestamptz `json:"now"`
+}
+
+func (q *Queries) DeleteExpiredMemoryNodes(ctx context.Context, arg DeleteExpiredMemoryNodesParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, deleteExpiredMemoryNodes, arg.BotID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const deleteMemoryEdgesByRelForBot = `-- name: DeleteMemoryEdgesByRelForBot :exec
 DELETE FROM memory_edges
 WHERE team_id = public.memoh_current_team_id() AND bot_id = $1 AND rel = $2
@@ -164,6 +198,39 @@ func (q *Queries) InsertMemoryEdge(ctx context.Context, arg InsertMemoryEdgePara
 	return err
 }
 
+const listBotsWithExpiredMemoryNodes = `-- name: ListBotsWithExpiredMemoryNodes :many
+SELECT DISTINCT bot_id FROM memory_nodes
+WHERE team_id = public.memoh_current_team_id()
+  AND expires_at IS NOT NULL
+  AND expires_at <= $1
+LIMIT $2
+`
+
+type ListBotsWithExpiredMemoryNodesParams struct {
+	Now      pgtype.Timestamptz `json:"now"`
+	RowLimit int32              `json:"row_limit"`
+}
+
+func (q *Queries) ListBotsWithExpiredMemoryNodes(ctx context.Context, arg ListBotsWithExpiredMemoryNodesParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listBotsWithExpiredMemoryNodes, arg.Now, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var bot_id pgtype.UUID
+		if err := rows.Scan(&bot_id); err != nil {
+			return nil, err
+		}
+		items = append(items, bot_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMemoryEdgesByBot = `-- name: ListMemoryEdgesByBot :many
 SELECT id, bot_id, src_node, dst_node, rel, weight, metadata, created_at, team_id FROM memory_edges
 WHERE team_id = public.memoh_current_team_id() AND bot_id = $1
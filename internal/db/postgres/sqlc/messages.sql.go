@@ -411,6 +411,20 @@ func (q *Queries) ClearHistoryBySession(ctx context.Context, targetSessionID pgt
 	return err
 }
 
+const countMessageAssetsByMessageIDs = `-- name: CountMessageAssetsByMessageIDs :one
+SELECT count(*)::bigint
+FROM bot_history_message_assets
+WHERE team_id = public.memoh_current_team_id()
+  AND message_id = ANY($1::uuid[])
+`
+
+func (q *Queries) CountMessageAssetsByMessageIDs(ctx context.Context, messageIds []pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countMessageAssetsByMessageIDs, messageIds)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const countMessagesByBot = `-- name: CountMessagesByBot :one
 SELECT COUNT(*) FROM bot_visible_history_messages
 WHERE team_id = public.memoh_current_team_id()
@@ -2954,6 +2968,39 @@ func (q *Queries) ListMessageRefsByCompactID(ctx context.Context, compactID pgty
 	return items, nil
 }
 
+const listMessageRefsBySenderChannelIdentity = `-- name: ListMessageRefsBySenderChannelIdentity :many
+SELECT id, bot_id
+FROM bot_history_messages
+WHERE team_id = public.memoh_current_team_id()
+  AND sender_channel_identity_id = $1::uuid
+ORDER BY created_at, id
+`
+
+type ListMessageRefsBySenderChannelIdentityRow struct {
+	ID    pgtype.UUID `json:"id"`
+	BotID pgtype.UUID `json:"bot_id"`
+}
+
+func (q *Queries) ListMessageRefsBySenderChannelIdentity(ctx context.Context, channelIdentityID pgtype.UUID) ([]ListMessageRefsBySenderChannelIdentityRow, error) {
+	rows, err := q.db.Query(ctx, listMessageRefsBySenderChannelIdentity, channelIdentityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMessageRefsBySenderChannelIdentityRow
+	for rows.Next() {
+		var i ListMessageRefsBySenderChannelIdentityRow
+		if err := rows.Scan(&i.ID, &i.BotID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMessages = `-- name: ListMessages :many
 SELECT
   m.id,
@@ -3903,6 +3950,81 @@ func (q *Queries) ListMessagesBySession(ctx context.Context, sessionID pgtype.UU
 	return items, nil
 }
 
+const listMessagesForDataExport = `-- name: ListMessagesForDataExport :many
+SELECT
+  m.id,
+  m.bot_id,
+  m.session_id,
+  m.sender_channel_identity_id,
+  m.sender_account_user_id AS sender_user_id,
+  m.role,
+  m.content,
+  m.display_text,
+  m.created_at,
+  s.channel_type AS platform,
+  a.object_key AS archive_key
+FROM bot_history_messages m
+LEFT JOIN bot_sessions s ON s.id = m.session_id AND s.team_id = public.memoh_current_team_id()
+LEFT JOIN bot_history_message_archives a ON a.message_id = m.id AND a.team_id = public.memoh_current_team_id()
+WHERE m.team_id = public.memoh_current_team_id()
+  AND (
+    m.sender_channel_identity_id = ANY($1::uuid[])
+    OR m.sender_account_user_id = $2::uuid
+  )
+ORDER BY m.created_at ASC, m.id ASC
+`
+
+type ListMessagesForDataExportParams struct {
+	ChannelIdentityIds []pgtype.UUID `json:"channel_identity_ids"`
+	UserID             pgtype.UUID   `json:"user_id"`
+}
+
+type ListMessagesForDataExportRow struct {
+	ID                      pgtype.UUID        `json:"id"`
+	BotID                   pgtype.UUID        `json:"bot_id"`
+	SessionID               pgtype.UUID        `json:"session_id"`
+	SenderChannelIdentityID pgtype.UUID        `json:"sender_channel_identity_id"`
+	SenderUserID            pgtype.UUID        `json:"sender_user_id"`
+	Role                    string             `json:"role"`
+	Content                 []byte             `json:"content"`
+	DisplayText             pgtype.Text        `json:"display_text"`
+	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	Platform                pgtype.Text        `json:"platform"`
+	ArchiveKey              pgtype.Text        `json:"archive_key"`
+}
+
+func (q *Queries) ListMessagesForDataExport(ctx context.Context, arg ListMessagesForDataExportParams) ([]ListMessagesForDataExportRow, error) {
+	rows, err := q.db.Query(ctx, listMessagesForDataExport, arg.ChannelIdentityIds, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMessagesForDataExportRow
+	for rows.Next() {
+		var i ListMessagesForDataExportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BotID,
+			&i.SessionID,
+			&i.SenderChannelIdentityID,
+			&i.SenderUserID,
+			&i.Role,
+			&i.Content,
+			&i.DisplayText,
+			&i.CreatedAt,
+			&i.Platform,
+			&i.ArchiveKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMessagesLatest = `-- name: ListMessagesLatest :many
 SELECT
   m.id,
@@ -5479,10 +5601,12 @@ SELECT
   m.content,
   m.created_at,
   ci.display_name AS sender_display_name,
-  s.channel_type AS platform
+  s.channel_type AS platform,
+  a.object_key AS archive_key
 FROM bot_visible_history_messages m
 LEFT JOIN channel_identities ci ON ci.id = m.sender_channel_identity_id AND ci.team_id = public.memoh_current_team_id()
 LEFT JOIN bot_sessions s ON s.id = m.session_id AND s.team_id = public.memoh_current_team_id()
+LEFT JOIN bot_history_message_archives a ON a.message_id = m.id AND a.team_id = public.memoh_current_team_id()
 WHERE m.team_id = public.memoh_current_team_id()
   AND m.bot_id = $1
   AND ($2::uuid IS NULL OR m.session_id = $2::uuid)
@@ -5526,6 +5650,7 @@ type SearchMessagesRow struct {
 	CreatedAt               pgtype.Timestamptz `json:"created_at"`
 	SenderDisplayName       pgtype.Text        `json:"sender_display_name"`
 	Platform                pgtype.Text        `json:"platform"`
+	ArchiveKey              pgtype.Text        `json:"archive_key"`
 }
 
 func (q *Queries) SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error) {
@@ -5556,6 +5681,7 @@ func (q *Queries) SearchMessages(ctx context.Context, arg SearchMessagesParams)
 			&i.CreatedAt,
 			&i.SenderDisplayName,
 			&i.Platform,
+			&i.ArchiveKey,
 		); err != nil {
 			return nil, err
 		}
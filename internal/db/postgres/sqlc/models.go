@@ -8,52 +8,82 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AuditLog struct {
+	ID          pgtype.UUID        `json:"id"`
+	TeamID      pgtype.UUID        `json:"team_id"`
+	ActorUserID pgtype.UUID        `json:"actor_user_id"`
+	Action      string             `json:"action"`
+	TargetType  string             `json:"target_type"`
+	TargetID    string             `json:"target_id"`
+	Details     []byte             `json:"details"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	ActorIp     string             `json:"actor_ip"`
+	Diff        []byte             `json:"diff"`
+}
+
+type AuthRefreshToken struct {
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	FamilyID      pgtype.UUID        `json:"family_id"`
+	TokenHash     string             `json:"token_hash"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	RevokedAt     pgtype.Timestamptz `json:"revoked_at"`
+	RevokedReason pgtype.Text        `json:"revoked_reason"`
+}
+
 type Bot struct {
-	ID                     pgtype.UUID        `json:"id"`
-	OwnerUserID            pgtype.UUID        `json:"owner_user_id"`
-	Name                   string             `json:"name"`
-	DisplayName            pgtype.Text        `json:"display_name"`
-	AvatarUrl              pgtype.Text        `json:"avatar_url"`
-	Timezone               pgtype.Text        `json:"timezone"`
-	IsActive               bool               `json:"is_active"`
-	Status                 string             `json:"status"`
-	Language               string             `json:"language"`
-	CommandUiLanguage      string             `json:"command_ui_language"`
-	ReasoningEnabled       bool               `json:"reasoning_enabled"`
-	ReasoningEffort        string             `json:"reasoning_effort"`
-	ChatModelID            pgtype.UUID        `json:"chat_model_id"`
-	ChatRuntime            string             `json:"chat_runtime"`
-	ChatAcpAgentID         pgtype.Text        `json:"chat_acp_agent_id"`
-	ChatAcpProjectPath     string             `json:"chat_acp_project_path"`
-	ChatAcpProjectMode     string             `json:"chat_acp_project_mode"`
-	SearchProviderID       pgtype.UUID        `json:"search_provider_id"`
-	FetchProviderID        pgtype.UUID        `json:"fetch_provider_id"`
-	MemoryProviderID       pgtype.UUID        `json:"memory_provider_id"`
-	HeartbeatEnabled       bool               `json:"heartbeat_enabled"`
-	HeartbeatInterval      int32              `json:"heartbeat_interval"`
-	HeartbeatPrompt        string             `json:"heartbeat_prompt"`
-	HeartbeatModelID       pgtype.UUID        `json:"heartbeat_model_id"`
-	CompactionEnabled      bool               `json:"compaction_enabled"`
-	CompactionThreshold    int32              `json:"compaction_threshold"`
-	CompactionRatio        int32              `json:"compaction_ratio"`
-	CompactionModelID      pgtype.UUID        `json:"compaction_model_id"`
-	ImageModelID           pgtype.UUID        `json:"image_model_id"`
-	DiscussProbeModelID    pgtype.UUID        `json:"discuss_probe_model_id"`
-	TtsModelID             pgtype.UUID        `json:"tts_model_id"`
-	TranscriptionModelID   pgtype.UUID        `json:"transcription_model_id"`
-	VideoModelID           pgtype.UUID        `json:"video_model_id"`
-	PersistFullToolResults bool               `json:"persist_full_tool_results"`
-	ShowToolCallsInIm      bool               `json:"show_tool_calls_in_im"`
-	ToolApprovalConfig     []byte             `json:"tool_approval_config"`
-	DisplayEnabled         bool               `json:"display_enabled"`
-	OverlayProvider        string             `json:"overlay_provider"`
-	OverlayEnabled         bool               `json:"overlay_enabled"`
-	OverlayConfig          []byte             `json:"overlay_config"`
-	Metadata               []byte             `json:"metadata"`
-	CreatedAt              pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt              pgtype.Timestamptz `json:"updated_at"`
-	AclDefaultEffect       string             `json:"acl_default_effect"`
-	TeamID                 pgtype.UUID        `json:"team_id"`
+	ID                      pgtype.UUID        `json:"id"`
+	OwnerUserID             pgtype.UUID        `json:"owner_user_id"`
+	Name                    string             `json:"name"`
+	DisplayName             pgtype.Text        `json:"display_name"`
+	AvatarUrl               pgtype.Text        `json:"avatar_url"`
+	Timezone                pgtype.Text        `json:"timezone"`
+	IsActive                bool               `json:"is_active"`
+	Status                  string             `json:"status"`
+	Language                string             `json:"language"`
+	CommandUiLanguage       string             `json:"command_ui_language"`
+	ReasoningEnabled        bool               `json:"reasoning_enabled"`
+	ReasoningEffort         string             `json:"reasoning_effort"`
+	ChatModelID             pgtype.UUID        `json:"chat_model_id"`
+	ChatRuntime             string             `json:"chat_runtime"`
+	ChatAcpAgentID          pgtype.Text        `json:"chat_acp_agent_id"`
+	ChatAcpProjectPath      string             `json:"chat_acp_project_path"`
+	ChatAcpProjectMode      string             `json:"chat_acp_project_mode"`
+	SearchProviderID        pgtype.UUID        `json:"search_provider_id"`
+	FetchProviderID         pgtype.UUID        `json:"fetch_provider_id"`
+	MemoryProviderID        pgtype.UUID        `json:"memory_provider_id"`
+	HeartbeatEnabled        bool               `json:"heartbeat_enabled"`
+	HeartbeatInterval       int32              `json:"heartbeat_interval"`
+	HeartbeatPrompt         string             `json:"heartbeat_prompt"`
+	HeartbeatModelID        pgtype.UUID        `json:"heartbeat_model_id"`
+	CompactionEnabled       bool               `json:"compaction_enabled"`
+	CompactionThreshold     int32              `json:"compaction_threshold"`
+	CompactionRatio         int32              `json:"compaction_ratio"`
+	CompactionModelID       pgtype.UUID        `json:"compaction_model_id"`
+	ImageModelID            pgtype.UUID        `json:"image_model_id"`
+	DiscussProbeModelID     pgtype.UUID        `json:"discuss_probe_model_id"`
+	TtsModelID              pgtype.UUID        `json:"tts_model_id"`
+	TranscriptionModelID    pgtype.UUID        `json:"transcription_model_id"`
+	VideoModelID            pgtype.UUID        `json:"video_model_id"`
+	PersistFullToolResults  bool               `json:"persist_full_tool_results"`
+	ShowToolCallsInIm       bool               `json:"show_tool_calls_in_im"`
+	ToolApprovalConfig      []byte             `json:"tool_approval_config"`
+	DisplayEnabled          bool               `json:"display_enabled"`
+	OverlayProvider         string             `json:"overlay_provider"`
+	OverlayEnabled          bool               `json:"overlay_enabled"`
+	OverlayConfig           []byte             `json:"overlay_config"`
+	Metadata                []byte             `json:"metadata"`
+	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt               pgtype.Timestamptz `json:"updated_at"`
+	AclDefaultEffect        string             `json:"acl_default_effect"`
+	TeamID                  pgtype.UUID        `json:"team_id"`
+	InboundRateLimit        int32              `json:"inbound_rate_limit"`
+	InboundRateBurst        int32              `json:"inbound_rate_burst"`
+	WakeWordsEnabled        bool               `json:"wake_words_enabled"`
+	WakeWords               []string           `json:"wake_words"`
+	WakeWordCooldownSeconds int32              `json:"wake_word_cooldown_seconds"`
+	Persona                 []byte             `json:"persona"`
 }
 
 type BotAclRule struct {
@@ -113,11 +143,44 @@ type BotChannelRoute struct {
 	DefaultReplyTarget     pgtype.Text        `json:"default_reply_target"`
 	ActiveSessionID        pgtype.UUID        `json:"active_session_id"`
 	Metadata               []byte             `json:"metadata"`
+	Status                 string             `json:"status"`
+	InactiveReason         string             `json:"inactive_reason"`
+	InactiveAt             pgtype.Timestamptz `json:"inactive_at"`
 	CreatedAt              pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt              pgtype.Timestamptz `json:"updated_at"`
 	TeamID                 pgtype.UUID        `json:"team_id"`
 }
 
+type BotContactProfile struct {
+	BotID             pgtype.UUID        `json:"bot_id"`
+	ChannelIdentityID pgtype.UUID        `json:"channel_identity_id"`
+	TeamID            pgtype.UUID        `json:"team_id"`
+	DisplayName       string             `json:"display_name"`
+	Timezone          string             `json:"timezone"`
+	Language          string             `json:"language"`
+	TimezoneSource    string             `json:"timezone_source"`
+	OnboardingStatus  string             `json:"onboarding_status"`
+	PendingFields     []string           `json:"pending_fields"`
+	LinkedUserID      pgtype.UUID        `json:"linked_user_id"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	CompletedAt       pgtype.Timestamptz `json:"completed_at"`
+}
+
+type BotConversationFlow struct {
+	BotID     pgtype.UUID        `json:"bot_id"`
+	TeamID    pgtype.UUID        `json:"team_id"`
+	Config    []byte             `json:"config"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BotDataKey struct {
+	TeamID     pgtype.UUID        `json:"team_id"`
+	BotID      pgtype.UUID        `json:"bot_id"`
+	WrappedKey []byte             `json:"wrapped_key"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
 type BotEmailBinding struct {
 	ID              pgtype.UUID        `json:"id"`
 	BotID           pgtype.UUID        `json:"bot_id"`
@@ -132,6 +195,42 @@ type BotEmailBinding struct {
 	TeamID          pgtype.UUID        `json:"team_id"`
 }
 
+type BotFeed struct {
+	ID                  pgtype.UUID        `json:"id"`
+	TeamID              pgtype.UUID        `json:"team_id"`
+	BotID               pgtype.UUID        `json:"bot_id"`
+	Url                 string             `json:"url"`
+	Title               string             `json:"title"`
+	PollIntervalMinutes int32              `json:"poll_interval_minutes"`
+	IngestMemory        bool               `json:"ingest_memory"`
+	CollectionID        pgtype.UUID        `json:"collection_id"`
+	DigestChannel       string             `json:"digest_channel"`
+	DigestTarget        string             `json:"digest_target"`
+	DigestPattern       string             `json:"digest_pattern"`
+	Enabled             bool               `json:"enabled"`
+	Etag                string             `json:"etag"`
+	LastModified        string             `json:"last_modified"`
+	LastError           string             `json:"last_error"`
+	LastPolledAt        pgtype.Timestamptz `json:"last_polled_at"`
+	NextPollAt          pgtype.Timestamptz `json:"next_poll_at"`
+	NextDigestAt        pgtype.Timestamptz `json:"next_digest_at"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BotFeedItem struct {
+	ID          pgtype.UUID        `json:"id"`
+	TeamID      pgtype.UUID        `json:"team_id"`
+	FeedID      pgtype.UUID        `json:"feed_id"`
+	Guid        string             `json:"guid"`
+	Title       string             `json:"title"`
+	Link        string             `json:"link"`
+	Summary     string             `json:"summary"`
+	PublishedAt pgtype.Timestamptz `json:"published_at"`
+	DigestedAt  pgtype.Timestamptz `json:"digested_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type BotHeartbeatLog struct {
 	ID           pgtype.UUID        `json:"id"`
 	BotID        pgtype.UUID        `json:"bot_id"`
@@ -175,6 +274,15 @@ type BotHistoryMessage struct {
 	TeamID                  pgtype.UUID        `json:"team_id"`
 }
 
+type BotHistoryMessageArchive struct {
+	MessageID  pgtype.UUID        `json:"message_id"`
+	TeamID     pgtype.UUID        `json:"team_id"`
+	BotID      pgtype.UUID        `json:"bot_id"`
+	ObjectKey  string             `json:"object_key"`
+	SizeBytes  int64              `json:"size_bytes"`
+	ArchivedAt pgtype.Timestamptz `json:"archived_at"`
+}
+
 type BotHistoryMessageAsset struct {
 	ID          pgtype.UUID        `json:"id"`
 	MessageID   pgtype.UUID        `json:"message_id"`
@@ -211,6 +319,105 @@ type BotHistoryMessageCompact struct {
 	TeamID          pgtype.UUID        `json:"team_id"`
 }
 
+type BotHistoryMessageQuarantine struct {
+	ID        pgtype.UUID        `json:"id"`
+	TeamID    pgtype.UUID        `json:"team_id"`
+	BotID     pgtype.UUID        `json:"bot_id"`
+	MessageID pgtype.UUID        `json:"message_id"`
+	Role      string             `json:"role"`
+	Payload   string             `json:"payload"`
+	Reason    string             `json:"reason"`
+	Source    string             `json:"source"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type BotHistoryRoundKey struct {
+	TeamID          pgtype.UUID        `json:"team_id"`
+	SessionID       pgtype.UUID        `json:"session_id"`
+	RoundKey        string             `json:"round_key"`
+	MessageIds      []pgtype.UUID      `json:"message_ids"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	MemoryPending   bool               `json:"memory_pending"`
+	MemoryClaimedAt pgtype.Timestamptz `json:"memory_claimed_at"`
+}
+
+type BotHistorySearchIndex struct {
+	BotID            pgtype.UUID        `json:"bot_id"`
+	TeamID           pgtype.UUID        `json:"team_id"`
+	EmbeddingModelID pgtype.UUID        `json:"embedding_model_id"`
+	CursorCreatedAt  pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorMessageID  pgtype.UUID        `json:"cursor_message_id"`
+	IndexedCount     int64              `json:"indexed_count"`
+	LastError        string             `json:"last_error"`
+	LastIndexedAt    pgtype.Timestamptz `json:"last_indexed_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BotHttpTool struct {
+	ID         pgtype.UUID        `json:"id"`
+	BotID      pgtype.UUID        `json:"bot_id"`
+	TeamID     pgtype.UUID        `json:"team_id"`
+	Name       string             `json:"name"`
+	BaseUrl    string             `json:"base_url"`
+	Operations []byte             `json:"operations"`
+	Auth       []byte             `json:"auth"`
+	Enabled    bool               `json:"enabled"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BotKnowledgeCollection struct {
+	TeamID       pgtype.UUID        `json:"team_id"`
+	BotID        pgtype.UUID        `json:"bot_id"`
+	CollectionID pgtype.UUID        `json:"collection_id"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type BotMemoryCompactSchedule struct {
+	BotID     pgtype.UUID        `json:"bot_id"`
+	TeamID    pgtype.UUID        `json:"team_id"`
+	Pattern   string             `json:"pattern"`
+	Timezone  string             `json:"timezone"`
+	Ratio     float64            `json:"ratio"`
+	DecayDays int32              `json:"decay_days"`
+	Enabled   bool               `json:"enabled"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BotMemoryReview struct {
+	BotID          pgtype.UUID        `json:"bot_id"`
+	TeamID         pgtype.UUID        `json:"team_id"`
+	Enabled        bool               `json:"enabled"`
+	ChannelType    string             `json:"channel_type"`
+	Target         string             `json:"target"`
+	NextReviewAt   pgtype.Timestamptz `json:"next_review_at"`
+	LastReviewedAt pgtype.Timestamptz `json:"last_reviewed_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BotMessageTagging struct {
+	BotID        pgtype.UUID        `json:"bot_id"`
+	TeamID       pgtype.UUID        `json:"team_id"`
+	Enabled      bool               `json:"enabled"`
+	ModelID      pgtype.UUID        `json:"model_id"`
+	Topics       []string           `json:"topics"`
+	EnabledAt    pgtype.Timestamptz `json:"enabled_at"`
+	LastError    string             `json:"last_error"`
+	LastTaggedAt pgtype.Timestamptz `json:"last_tagged_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BotOutputProcessor struct {
+	BotID      pgtype.UUID        `json:"bot_id"`
+	TeamID     pgtype.UUID        `json:"team_id"`
+	Processors []byte             `json:"processors"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
 type BotPluginInstallation struct {
 	ID          pgtype.UUID        `json:"id"`
 	BotID       pgtype.UUID        `json:"bot_id"`
@@ -296,6 +503,13 @@ type BotSessionEvent struct {
 	TeamID                  pgtype.UUID        `json:"team_id"`
 }
 
+type BotStickerCatalog struct {
+	BotID     pgtype.UUID        `json:"bot_id"`
+	TeamID    pgtype.UUID        `json:"team_id"`
+	Stickers  []byte             `json:"stickers"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type BotStorageBinding struct {
 	ID                pgtype.UUID        `json:"id"`
 	BotID             pgtype.UUID        `json:"bot_id"`
@@ -343,6 +557,13 @@ type BotVisibleHistoryMessage struct {
 	CreatedAt               pgtype.Timestamptz `json:"created_at"`
 }
 
+type BotWorkingHour struct {
+	BotID     pgtype.UUID        `json:"bot_id"`
+	TeamID    pgtype.UUID        `json:"team_id"`
+	Config    []byte             `json:"config"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type BotWorkspaceResourceLimit struct {
 	BotID         pgtype.UUID        `json:"bot_id"`
 	CpuMillicores int64              `json:"cpu_millicores"`
@@ -353,6 +574,24 @@ type BotWorkspaceResourceLimit struct {
 	TeamID        pgtype.UUID        `json:"team_id"`
 }
 
+type ChannelOutbox struct {
+	ID                pgtype.UUID        `json:"id"`
+	TeamID            pgtype.UUID        `json:"team_id"`
+	BotID             pgtype.UUID        `json:"bot_id"`
+	ChannelConfigID   string             `json:"channel_config_id"`
+	ChannelType       string             `json:"channel_type"`
+	Target            string             `json:"target"`
+	Message           []byte             `json:"message"`
+	Error             string             `json:"error"`
+	Attempts          int32              `json:"attempts"`
+	Status            string             `json:"status"`
+	NextAttemptAt     pgtype.Timestamptz `json:"next_attempt_at"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	SentAt            pgtype.Timestamptz `json:"sent_at"`
+	ChannelIdentityID pgtype.UUID        `json:"channel_identity_id"`
+}
+
 type ChannelIdentity struct {
 	ID               pgtype.UUID        `json:"id"`
 	ChannelType      string             `json:"channel_type"`
@@ -403,6 +642,36 @@ type ContainerVersion struct {
 	TeamID      pgtype.UUID        `json:"team_id"`
 }
 
+type DataExportJob struct {
+	ID                pgtype.UUID        `json:"id"`
+	TeamID            pgtype.UUID        `json:"team_id"`
+	RequestedByUserID pgtype.UUID        `json:"requested_by_user_id"`
+	SubjectType       string             `json:"subject_type"`
+	SubjectID         pgtype.UUID        `json:"subject_id"`
+	Status            string             `json:"status"`
+	Error             string             `json:"error"`
+	FilePath          string             `json:"file_path"`
+	SizeBytes         int64              `json:"size_bytes"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	CompletedAt       pgtype.Timestamptz `json:"completed_at"`
+	ExpiresAt         pgtype.Timestamptz `json:"expires_at"`
+}
+
+type DeferredInboundMessage struct {
+	ID          pgtype.UUID        `json:"id"`
+	TeamID      pgtype.UUID        `json:"team_id"`
+	BotID       pgtype.UUID        `json:"bot_id"`
+	ChannelType string             `json:"channel_type"`
+	Message     []byte             `json:"message"`
+	DeliverAt   pgtype.Timestamptz `json:"deliver_at"`
+	Status      string             `json:"status"`
+	Attempts    int32              `json:"attempts"`
+	Error       string             `json:"error"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	DeliveredAt pgtype.Timestamptz `json:"delivered_at"`
+}
+
 type EmailOauthToken struct {
 	ID              pgtype.UUID        `json:"id"`
 	EmailProviderID pgtype.UUID        `json:"email_provider_id"`
@@ -446,6 +715,15 @@ type EmailProvider struct {
 	TeamID    pgtype.UUID        `json:"team_id"`
 }
 
+type FeatureFlag struct {
+	ID        pgtype.UUID        `json:"id"`
+	TeamID    pgtype.UUID        `json:"team_id"`
+	FlagKey   string             `json:"flag_key"`
+	BotID     pgtype.UUID        `json:"bot_id"`
+	Enabled   bool               `json:"enabled"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type FetchProvider struct {
 	ID        pgtype.UUID        `json:"id"`
 	Name      string             `json:"name"`
@@ -457,6 +735,192 @@ type FetchProvider struct {
 	TeamID    pgtype.UUID        `json:"team_id"`
 }
 
+type GithubRepo struct {
+	ID             pgtype.UUID        `json:"id"`
+	TeamID         pgtype.UUID        `json:"team_id"`
+	Owner          string             `json:"owner"`
+	Name           string             `json:"name"`
+	InstallationID int64              `json:"installation_id"`
+	CollectionID   pgtype.UUID        `json:"collection_id"`
+	DocsPaths      []string           `json:"docs_paths"`
+	DocsTreeSha    string             `json:"docs_tree_sha"`
+	SyncStatus     string             `json:"sync_status"`
+	LastError      string             `json:"last_error"`
+	SyncedAt       pgtype.Timestamptz `json:"synced_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type GithubRepoDocument struct {
+	ID         pgtype.UUID        `json:"id"`
+	TeamID     pgtype.UUID        `json:"team_id"`
+	RepoID     pgtype.UUID        `json:"repo_id"`
+	Path       string             `json:"path"`
+	BlobSha    string             `json:"blob_sha"`
+	DocumentID pgtype.UUID        `json:"document_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type GithubRepoSubscription struct {
+	ID         pgtype.UUID        `json:"id"`
+	TeamID     pgtype.UUID        `json:"team_id"`
+	RepoID     pgtype.UUID        `json:"repo_id"`
+	BotID      pgtype.UUID        `json:"bot_id"`
+	Events     []string           `json:"events"`
+	CanComment bool               `json:"can_comment"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type GroupReplyClaim struct {
+	TeamID         pgtype.UUID        `json:"team_id"`
+	ChannelType    string             `json:"channel_type"`
+	ConversationID string             `json:"conversation_id"`
+	MessageID      string             `json:"message_id"`
+	BotID          pgtype.UUID        `json:"bot_id"`
+	ClaimedAt      pgtype.Timestamptz `json:"claimed_at"`
+}
+
+type InboundFailure struct {
+	ID                pgtype.UUID        `json:"id"`
+	TeamID            pgtype.UUID        `json:"team_id"`
+	BotID             pgtype.UUID        `json:"bot_id"`
+	ChannelConfigID   string             `json:"channel_config_id"`
+	ChannelType       string             `json:"channel_type"`
+	Message           []byte             `json:"message"`
+	Error             string             `json:"error"`
+	Attempts          int32              `json:"attempts"`
+	Status            string             `json:"status"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	ReplayedAt        pgtype.Timestamptz `json:"replayed_at"`
+	ChannelIdentityID pgtype.UUID        `json:"channel_identity_id"`
+}
+
+type KnowledgeCollection struct {
+	ID                 pgtype.UUID        `json:"id"`
+	TeamID             pgtype.UUID        `json:"team_id"`
+	Name               string             `json:"name"`
+	Description        string             `json:"description"`
+	EmbeddingModelID   pgtype.UUID        `json:"embedding_model_id"`
+	ChunkSize          int32              `json:"chunk_size"`
+	ChunkOverlap       int32              `json:"chunk_overlap"`
+	MetadataExtraction []byte             `json:"metadata_extraction"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+}
+
+type KnowledgeConnector struct {
+	ID           pgtype.UUID        `json:"id"`
+	TeamID       pgtype.UUID        `json:"team_id"`
+	CollectionID pgtype.UUID        `json:"collection_id"`
+	Type         string             `json:"type"`
+	Name         string             `json:"name"`
+	Config       []byte             `json:"config"`
+	SyncPattern  string             `json:"sync_pattern"`
+	Enabled      bool               `json:"enabled"`
+	Cursor       string             `json:"cursor"`
+	NextSyncAt   pgtype.Timestamptz `json:"next_sync_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type KnowledgeConnectorSync struct {
+	ID                pgtype.UUID        `json:"id"`
+	TeamID            pgtype.UUID        `json:"team_id"`
+	ConnectorID       pgtype.UUID        `json:"connector_id"`
+	Status            string             `json:"status"`
+	Error             string             `json:"error"`
+	FullSync          bool               `json:"full_sync"`
+	ItemsSeen         int32              `json:"items_seen"`
+	DocumentsIngested int32              `json:"documents_ingested"`
+	DocumentsSkipped  int32              `json:"documents_skipped"`
+	DocumentsRemoved  int32              `json:"documents_removed"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	CompletedAt       pgtype.Timestamptz `json:"completed_at"`
+}
+
+type KnowledgeConnectorToken struct {
+	ID           pgtype.UUID        `json:"id"`
+	TeamID       pgtype.UUID        `json:"team_id"`
+	ConnectorID  pgtype.UUID        `json:"connector_id"`
+	Account      string             `json:"account"`
+	AccessToken  string             `json:"access_token"`
+	RefreshToken string             `json:"refresh_token"`
+	TokenType    string             `json:"token_type"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	Scope        string             `json:"scope"`
+	State        string             `json:"state"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type KnowledgeCrawlRun struct {
+	ID            pgtype.UUID        `json:"id"`
+	TeamID        pgtype.UUID        `json:"team_id"`
+	SourceID      pgtype.UUID        `json:"source_id"`
+	Status        string             `json:"status"`
+	Error         string             `json:"error"`
+	PagesFetched  int32              `json:"pages_fetched"`
+	PagesIngested int32              `json:"pages_ingested"`
+	PagesSkipped  int32              `json:"pages_skipped"`
+	PagesRemoved  int32              `json:"pages_removed"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	CompletedAt   pgtype.Timestamptz `json:"completed_at"`
+}
+
+type KnowledgeCrawlSource struct {
+	ID              pgtype.UUID        `json:"id"`
+	TeamID          pgtype.UUID        `json:"team_id"`
+	CollectionID    pgtype.UUID        `json:"collection_id"`
+	Name            string             `json:"name"`
+	SeedUrls        []byte             `json:"seed_urls"`
+	MaxDepth        int32              `json:"max_depth"`
+	MaxPages        int32              `json:"max_pages"`
+	IncludePatterns []byte             `json:"include_patterns"`
+	ExcludePatterns []byte             `json:"exclude_patterns"`
+	DelayMs         int32              `json:"delay_ms"`
+	RefreshPattern  string             `json:"refresh_pattern"`
+	Enabled         bool               `json:"enabled"`
+	NextRunAt       pgtype.Timestamptz `json:"next_run_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type KnowledgeDocument struct {
+	ID              pgtype.UUID        `json:"id"`
+	TeamID          pgtype.UUID        `json:"team_id"`
+	CollectionID    pgtype.UUID        `json:"collection_id"`
+	Title           string             `json:"title"`
+	Content         string             `json:"content"`
+	ContentHash     string             `json:"content_hash"`
+	ChunkCount      int32              `json:"chunk_count"`
+	Metadata        []byte             `json:"metadata"`
+	SourceUrl       string             `json:"source_url"`
+	CrawlSourceID   pgtype.UUID        `json:"crawl_source_id"`
+	ConnectorID     pgtype.UUID        `json:"connector_id"`
+	ExternalID      string             `json:"external_id"`
+	ExternalVersion string             `json:"external_version"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+}
+
+type KnowledgeReprocessJob struct {
+	ID             pgtype.UUID        `json:"id"`
+	TeamID         pgtype.UUID        `json:"team_id"`
+	CollectionID   pgtype.UUID        `json:"collection_id"`
+	Status         string             `json:"status"`
+	Error          string             `json:"error"`
+	DocumentsTotal int32              `json:"documents_total"`
+	DocumentsDone  int32              `json:"documents_done"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	CompletedAt    pgtype.Timestamptz `json:"completed_at"`
+}
+
 type LifecycleEvent struct {
 	ID          string             `json:"id"`
 	ContainerID string             `json:"container_id"`
@@ -466,6 +930,15 @@ type LifecycleEvent struct {
 	TeamID      pgtype.UUID        `json:"team_id"`
 }
 
+type MaintenanceMode struct {
+	TeamID    pgtype.UUID        `json:"team_id"`
+	Enabled   bool               `json:"enabled"`
+	Message   string             `json:"message"`
+	UpdatedBy pgtype.UUID        `json:"updated_by"`
+	StartedAt pgtype.Timestamptz `json:"started_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type McpConnection struct {
 	ID                            pgtype.UUID        `json:"id"`
 	BotID                         pgtype.UUID        `json:"bot_id"`
@@ -530,6 +1003,21 @@ type MediaAsset struct {
 	TeamID            pgtype.UUID        `json:"team_id"`
 }
 
+type MemoryCompactRun struct {
+	ID           pgtype.UUID        `json:"id"`
+	TeamID       pgtype.UUID        `json:"team_id"`
+	BotID        pgtype.UUID        `json:"bot_id"`
+	Source       string             `json:"source"`
+	Status       string             `json:"status"`
+	Ratio        float64            `json:"ratio"`
+	DecayDays    int32              `json:"decay_days"`
+	BeforeCount  int32              `json:"before_count"`
+	AfterCount   int32              `json:"after_count"`
+	ErrorMessage string             `json:"error_message"`
+	StartedAt    pgtype.Timestamptz `json:"started_at"`
+	CompletedAt  pgtype.Timestamptz `json:"completed_at"`
+}
+
 type MemoryEdge struct {
 	ID        int64              `json:"id"`
 	BotID     pgtype.UUID        `json:"bot_id"`
@@ -542,6 +1030,19 @@ type MemoryEdge struct {
 	TeamID    pgtype.UUID        `json:"team_id"`
 }
 
+type MemoryFlag struct {
+	ID         pgtype.UUID        `json:"id"`
+	TeamID     pgtype.UUID        `json:"team_id"`
+	BotID      pgtype.UUID        `json:"bot_id"`
+	MemoryID   string             `json:"memory_id"`
+	Memory     string             `json:"memory"`
+	Reason     string             `json:"reason"`
+	SessionID  string             `json:"session_id"`
+	Status     string             `json:"status"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ResolvedAt pgtype.Timestamptz `json:"resolved_at"`
+}
+
 type MemoryNode struct {
 	ID               string             `json:"id"`
 	BotID            pgtype.UUID        `json:"bot_id"`
@@ -627,6 +1128,21 @@ type ProviderOauthToken struct {
 	TeamID           pgtype.UUID        `json:"team_id"`
 }
 
+type PushDevice struct {
+	ID         pgtype.UUID        `json:"id"`
+	TeamID     pgtype.UUID        `json:"team_id"`
+	BotID      pgtype.UUID        `json:"bot_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Platform   string             `json:"platform"`
+	Token      string             `json:"token"`
+	Name       string             `json:"name"`
+	Muted      bool               `json:"muted"`
+	MutedUntil pgtype.Timestamptz `json:"muted_until"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Schedule struct {
 	ID           pgtype.UUID        `json:"id"`
 	Name         string             `json:"name"`
@@ -639,9 +1155,21 @@ type Schedule struct {
 	Enabled      bool               `json:"enabled"`
 	Command      string             `json:"command"`
 	BotID        pgtype.UUID        `json:"bot_id"`
+	Timezone     string             `json:"timezone"`
 	TeamID       pgtype.UUID        `json:"team_id"`
 }
 
+type ScheduleFeedToken struct {
+	ID         pgtype.UUID        `json:"id"`
+	TeamID     pgtype.UUID        `json:"team_id"`
+	BotID      pgtype.UUID        `json:"bot_id"`
+	Name       string             `json:"name"`
+	TokenHash  string             `json:"token_hash"`
+	CreatedBy  pgtype.UUID        `json:"created_by"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
 type ScheduleLog struct {
 	ID           pgtype.UUID        `json:"id"`
 	ScheduleID   pgtype.UUID        `json:"schedule_id"`
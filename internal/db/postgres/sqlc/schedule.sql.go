@@ -12,9 +12,9 @@ import (
 )
 
 const createSchedule = `-- name: CreateSchedule :one
-INSERT INTO schedule (name, description, pattern, max_calls, enabled, command, bot_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, team_id
+INSERT INTO schedule (name, description, pattern, max_calls, enabled, command, bot_id, timezone)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, timezone, team_id
 `
 
 type CreateScheduleParams struct {
@@ -25,6 +25,7 @@ type CreateScheduleParams struct {
 	Enabled     bool        `json:"enabled"`
 	Command     string      `json:"command"`
 	BotID       pgtype.UUID `json:"bot_id"`
+	Timezone    string      `json:"timezone"`
 }
 
 func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams) (Schedule, error) {
@@ -36,6 +37,7 @@ func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams)
 		arg.Enabled,
 		arg.Command,
 		arg.BotID,
+		arg.Timezone,
 	)
 	var i Schedule
 	err := row.Scan(
@@ -50,6 +52,7 @@ func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams)
 		&i.Enabled,
 		&i.Command,
 		&i.BotID,
+		&i.Timezone,
 		&i.TeamID,
 	)
 	return i, err
@@ -66,7 +69,7 @@ func (q *Queries) DeleteSchedule(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getScheduleByID = `-- name: GetScheduleByID :one
-SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, team_id
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, timezone, team_id
 FROM schedule
 WHERE team_id = public.memoh_current_team_id() AND id = $1
 `
@@ -86,6 +89,7 @@ func (q *Queries) GetScheduleByID(ctx context.Context, id pgtype.UUID) (Schedule
 		&i.Enabled,
 		&i.Command,
 		&i.BotID,
+		&i.Timezone,
 		&i.TeamID,
 	)
 	return i, err
@@ -100,7 +104,7 @@ SET current_calls = current_calls + 1,
     END,
     updated_at = now()
 WHERE team_id = public.memoh_current_team_id() AND id = $1
-RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, team_id
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, timezone, team_id
 `
 
 func (q *Queries) IncrementScheduleCalls(ctx context.Context, id pgtype.UUID) (Schedule, error) {
@@ -118,13 +122,14 @@ func (q *Queries) IncrementScheduleCalls(ctx context.Context, id pgtype.UUID) (S
 		&i.Enabled,
 		&i.Command,
 		&i.BotID,
+		&i.Timezone,
 		&i.TeamID,
 	)
 	return i, err
 }
 
 const listEnabledSchedules = `-- name: ListEnabledSchedules :many
-SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, team_id
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, timezone, team_id
 FROM schedule
 WHERE team_id = public.memoh_current_team_id() AND enabled = true
 ORDER BY created_at DESC
@@ -151,6 +156,7 @@ func (q *Queries) ListEnabledSchedules(ctx context.Context) ([]Schedule, error)
 			&i.Enabled,
 			&i.Command,
 			&i.BotID,
+			&i.Timezone,
 			&i.TeamID,
 		); err != nil {
 			return nil, err
@@ -164,7 +170,7 @@ func (q *Queries) ListEnabledSchedules(ctx context.Context) ([]Schedule, error)
 }
 
 const listSchedulesByBot = `-- name: ListSchedulesByBot :many
-SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, team_id
+SELECT id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, timezone, team_id
 FROM schedule
 WHERE team_id = public.memoh_current_team_id() AND bot_id = $1
 ORDER BY created_at DESC
@@ -191,6 +197,7 @@ func (q *Queries) ListSchedulesByBot(ctx context.Context, botID pgtype.UUID) ([]
 			&i.Enabled,
 			&i.Command,
 			&i.BotID,
+			&i.Timezone,
 			&i.TeamID,
 		); err != nil {
 			return nil, err
@@ -211,9 +218,10 @@ SET name = $2,
     max_calls = $5,
     enabled = $6,
     command = $7,
+    timezone = $8,
     updated_at = now()
 WHERE team_id = public.memoh_current_team_id() AND id = $1
-RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, team_id
+RETURNING id, name, description, pattern, max_calls, current_calls, created_at, updated_at, enabled, command, bot_id, timezone, team_id
 `
 
 type UpdateScheduleParams struct {
@@ -224,6 +232,7 @@ type UpdateScheduleParams struct {
 	MaxCalls    pgtype.Int4 `json:"max_calls"`
 	Enabled     bool        `json:"enabled"`
 	Command     string      `json:"command"`
+	Timezone    string      `json:"timezone"`
 }
 
 func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams) (Schedule, error) {
@@ -235,6 +244,7 @@ func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams)
 		arg.MaxCalls,
 		arg.Enabled,
 		arg.Command,
+		arg.Timezone,
 	)
 	var i Schedule
 	err := row.Scan(
@@ -249,6 +259,7 @@ func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams)
 		&i.Enabled,
 		&i.Command,
 		&i.BotID,
+		&i.Timezone,
 		&i.TeamID,
 	)
 	return i, err
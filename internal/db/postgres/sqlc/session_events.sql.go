@@ -72,6 +72,19 @@ func (q *Queries) DeleteSessionEventsByBot(ctx context.Context, botID pgtype.UUI
 	return err
 }
 
+const deleteSessionEventsBySenderChannelIdentity = `-- name: DeleteSessionEventsBySenderChannelIdentity :execrows
+DELETE FROM bot_session_events
+WHERE team_id = public.memoh_current_team_id() AND sender_channel_identity_id = $1::uuid
+`
+
+func (q *Queries) DeleteSessionEventsBySenderChannelIdentity(ctx context.Context, channelIdentityID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSessionEventsBySenderChannelIdentity, channelIdentityID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const listSessionEventsByBot = `-- name: ListSessionEventsByBot :many
 SELECT id, bot_id, session_id, event_kind, event_data, external_message_id, sender_channel_identity_id, received_at_ms, created_at, team_id FROM bot_session_events
 WHERE team_id = public.memoh_current_team_id() AND bot_id = $1
@@ -44,6 +44,12 @@ SET language = 'auto',
     overlay_provider = '',
     overlay_enabled = false,
     overlay_config = '{}'::jsonb,
+    inbound_rate_limit = 0,
+    inbound_rate_burst = 0,
+    wake_words_enabled = false,
+    wake_words = '{}',
+    wake_word_cooldown_seconds = 0,
+    persona = '{}'::jsonb,
     updated_at = now()
 WHERE team_id = public.memoh_current_team_id() AND id = $1
 `
@@ -87,7 +93,13 @@ SELECT
   bots.overlay_provider,
   bots.overlay_enabled,
   bots.overlay_config,
-  bots.command_ui_language
+  bots.command_ui_language,
+  bots.inbound_rate_limit,
+  bots.inbound_rate_burst,
+  bots.wake_words_enabled,
+  bots.wake_words,
+  bots.wake_word_cooldown_seconds,
+  bots.persona
 FROM bots
 LEFT JOIN models AS chat_models ON chat_models.id = bots.chat_model_id AND chat_models.team_id = public.memoh_current_team_id()
 LEFT JOIN models AS heartbeat_models ON heartbeat_models.id = bots.heartbeat_model_id AND heartbeat_models.team_id = public.memoh_current_team_id()
@@ -103,39 +115,45 @@ WHERE bots.team_id = public.memoh_current_team_id() AND bots.id = $1
 `
 
 type GetSettingsByBotIDRow struct {
-	BotID                  pgtype.UUID `json:"bot_id"`
-	Language               string      `json:"language"`
-	ReasoningEnabled       bool        `json:"reasoning_enabled"`
-	ReasoningEffort        string      `json:"reasoning_effort"`
-	HeartbeatEnabled       bool        `json:"heartbeat_enabled"`
-	HeartbeatInterval      int32       `json:"heartbeat_interval"`
-	HeartbeatPrompt        string      `json:"heartbeat_prompt"`
-	CompactionEnabled      bool        `json:"compaction_enabled"`
-	CompactionThreshold    int32       `json:"compaction_threshold"`
-	CompactionRatio        int32       `json:"compaction_ratio"`
-	Timezone               pgtype.Text `json:"timezone"`
-	ChatModelID            pgtype.UUID `json:"chat_model_id"`
-	ChatRuntime            string      `json:"chat_runtime"`
-	ChatAcpAgentID         pgtype.Text `json:"chat_acp_agent_id"`
-	ChatAcpProjectPath     string      `json:"chat_acp_project_path"`
-	ChatAcpProjectMode     string      `json:"chat_acp_project_mode"`
-	HeartbeatModelID       pgtype.UUID `json:"heartbeat_model_id"`
-	CompactionModelID      pgtype.UUID `json:"compaction_model_id"`
-	SearchProviderID       pgtype.UUID `json:"search_provider_id"`
-	FetchProviderID        pgtype.UUID `json:"fetch_provider_id"`
-	MemoryProviderID       pgtype.UUID `json:"memory_provider_id"`
-	ImageModelID           pgtype.UUID `json:"image_model_id"`
-	TtsModelID             pgtype.UUID `json:"tts_model_id"`
-	TranscriptionModelID   pgtype.UUID `json:"transcription_model_id"`
-	VideoModelID           pgtype.UUID `json:"video_model_id"`
-	PersistFullToolResults bool        `json:"persist_full_tool_results"`
-	ShowToolCallsInIm      bool        `json:"show_tool_calls_in_im"`
-	ToolApprovalConfig     []byte      `json:"tool_approval_config"`
-	DisplayEnabled         bool        `json:"display_enabled"`
-	OverlayProvider        string      `json:"overlay_provider"`
-	OverlayEnabled         bool        `json:"overlay_enabled"`
-	OverlayConfig          []byte      `json:"overlay_config"`
-	CommandUiLanguage      string      `json:"command_ui_language"`
+	BotID                   pgtype.UUID `json:"bot_id"`
+	Language                string      `json:"language"`
+	ReasoningEnabled        bool        `json:"reasoning_enabled"`
+	ReasoningEffort         string      `json:"reasoning_effort"`
+	HeartbeatEnabled        bool        `json:"heartbeat_enabled"`
+	HeartbeatInterval       int32       `json:"heartbeat_interval"`
+	HeartbeatPrompt         string      `json:"heartbeat_prompt"`
+	CompactionEnabled       bool        `json:"compaction_enabled"`
+	CompactionThreshold     int32       `json:"compaction_threshold"`
+	CompactionRatio         int32       `json:"compaction_ratio"`
+	Timezone                pgtype.Text `json:"timezone"`
+	ChatModelID             pgtype.UUID `json:"chat_model_id"`
+	ChatRuntime             string      `json:"chat_runtime"`
+	ChatAcpAgentID          pgtype.Text `json:"chat_acp_agent_id"`
+	ChatAcpProjectPath      string      `json:"chat_acp_project_path"`
+	ChatAcpProjectMode      string      `json:"chat_acp_project_mode"`
+	HeartbeatModelID        pgtype.UUID `json:"heartbeat_model_id"`
+	CompactionModelID       pgtype.UUID `json:"compaction_model_id"`
+	SearchProviderID        pgtype.UUID `json:"search_provider_id"`
+	FetchProviderID         pgtype.UUID `json:"fetch_provider_id"`
+	MemoryProviderID        pgtype.UUID `json:"memory_provider_id"`
+	ImageModelID            pgtype.UUID `json:"image_model_id"`
+	TtsModelID              pgtype.UUID `json:"tts_model_id"`
+	TranscriptionModelID    pgtype.UUID `json:"transcription_model_id"`
+	VideoModelID            pgtype.UUID `json:"video_model_id"`
+	PersistFullToolResults  bool        `json:"persist_full_tool_results"`
+	ShowToolCallsInIm       bool        `json:"show_tool_calls_in_im"`
+	ToolApprovalConfig      []byte      `json:"tool_approval_config"`
+	DisplayEnabled          bool        `json:"display_enabled"`
+	OverlayProvider         string      `json:"overlay_provider"`
+	OverlayEnabled          bool        `json:"overlay_enabled"`
+	OverlayConfig           []byte      `json:"overlay_config"`
+	CommandUiLanguage       string      `json:"command_ui_language"`
+	InboundRateLimit        int32       `json:"inbound_rate_limit"`
+	InboundRateBurst        int32       `json:"inbound_rate_burst"`
+	WakeWordsEnabled        bool        `json:"wake_words_enabled"`
+	WakeWords               []string    `json:"wake_words"`
+	WakeWordCooldownSeconds int32       `json:"wake_word_cooldown_seconds"`
+	Persona                 []byte      `json:"persona"`
 }
 
 func (q *Queries) GetSettingsByBotID(ctx context.Context, id pgtype.UUID) (GetSettingsByBotIDRow, error) {
@@ -175,6 +193,12 @@ func (q *Queries) GetSettingsByBotID(ctx context.Context, id pgtype.UUID) (GetSe
 		&i.OverlayEnabled,
 		&i.OverlayConfig,
 		&i.CommandUiLanguage,
+		&i.InboundRateLimit,
+		&i.InboundRateBurst,
+		&i.WakeWordsEnabled,
+		&i.WakeWords,
+		&i.WakeWordCooldownSeconds,
+		&i.Persona,
 	)
 	return i, err
 }
@@ -217,9 +241,15 @@ WITH updated AS (
       overlay_enabled = $31,
       overlay_config = $32,
       command_ui_language = $33,
+      inbound_rate_limit = $34,
+      inbound_rate_burst = $35,
+      wake_words_enabled = $36,
+      wake_words = $37::text[],
+      wake_word_cooldown_seconds = $38,
+      persona = $39,
       updated_at = now()
-  WHERE bots.team_id = public.memoh_current_team_id() AND bots.id = $34
-  RETURNING bots.id, bots.language, bots.reasoning_enabled, bots.reasoning_effort, bots.heartbeat_enabled, bots.heartbeat_interval, bots.heartbeat_prompt, bots.compaction_enabled, bots.compaction_threshold, bots.compaction_ratio, bots.timezone, bots.chat_model_id, bots.chat_runtime, bots.chat_acp_agent_id, bots.chat_acp_project_path, bots.chat_acp_project_mode, bots.heartbeat_model_id, bots.compaction_model_id, bots.image_model_id, bots.search_provider_id, bots.fetch_provider_id, bots.memory_provider_id, bots.tts_model_id, bots.transcription_model_id, bots.video_model_id, bots.persist_full_tool_results, bots.show_tool_calls_in_im, bots.tool_approval_config, bots.display_enabled, bots.overlay_provider, bots.overlay_enabled, bots.overlay_config, bots.command_ui_language
+  WHERE bots.team_id = public.memoh_current_team_id() AND bots.id = $40
+  RETURNING bots.id, bots.language, bots.reasoning_enabled, bots.reasoning_effort, bots.heartbeat_enabled, bots.heartbeat_interval, bots.heartbeat_prompt, bots.compaction_enabled, bots.compaction_threshold, bots.compaction_ratio, bots.timezone, bots.chat_model_id, bots.chat_runtime, bots.chat_acp_agent_id, bots.chat_acp_project_path, bots.chat_acp_project_mode, bots.heartbeat_model_id, bots.compaction_model_id, bots.image_model_id, bots.search_provider_id, bots.fetch_provider_id, bots.memory_provider_id, bots.tts_model_id, bots.transcription_model_id, bots.video_model_id, bots.persist_full_tool_results, bots.show_tool_calls_in_im, bots.tool_approval_config, bots.display_enabled, bots.overlay_provider, bots.overlay_enabled, bots.overlay_config, bots.command_ui_language, bots.inbound_rate_limit, bots.inbound_rate_burst, bots.wake_words_enabled, bots.wake_words, bots.wake_word_cooldown_seconds, bots.persona
 )
 SELECT
   updated.id AS bot_id,
@@ -254,7 +284,13 @@ SELECT
   updated.overlay_provider,
   updated.overlay_enabled,
   updated.overlay_config,
-  updated.command_ui_language
+  updated.command_ui_language,
+  updated.inbound_rate_limit,
+  updated.inbound_rate_burst,
+  updated.wake_words_enabled,
+  updated.wake_words,
+  updated.wake_word_cooldown_seconds,
+  updated.persona
 FROM updated
 LEFT JOIN models AS chat_models ON chat_models.id = updated.chat_model_id AND chat_models.team_id = public.memoh_current_team_id()
 LEFT JOIN models AS heartbeat_models ON heartbeat_models.id = updated.heartbeat_model_id AND heartbeat_models.team_id = public.memoh_current_team_id()
@@ -269,76 +305,88 @@ LEFT JOIN models AS video_models ON video_models.id = updated.video_model_id AND
 `
 
 type UpsertBotSettingsParams struct {
-	Language               string      `json:"language"`
-	ReasoningEnabled       bool        `json:"reasoning_enabled"`
-	ReasoningEffort        string      `json:"reasoning_effort"`
-	HeartbeatEnabled       bool        `json:"heartbeat_enabled"`
-	HeartbeatInterval      int32       `json:"heartbeat_interval"`
-	HeartbeatPrompt        string      `json:"heartbeat_prompt"`
-	CompactionEnabled      bool        `json:"compaction_enabled"`
-	CompactionThreshold    int32       `json:"compaction_threshold"`
-	CompactionRatio        int32       `json:"compaction_ratio"`
-	Timezone               pgtype.Text `json:"timezone"`
-	ChatModelID            pgtype.UUID `json:"chat_model_id"`
-	ChatRuntime            string      `json:"chat_runtime"`
-	ChatAcpAgentID         pgtype.Text `json:"chat_acp_agent_id"`
-	ChatAcpProjectPath     string      `json:"chat_acp_project_path"`
-	ChatAcpProjectMode     string      `json:"chat_acp_project_mode"`
-	HeartbeatModelID       pgtype.UUID `json:"heartbeat_model_id"`
-	CompactionModelID      pgtype.UUID `json:"compaction_model_id"`
-	SearchProviderID       pgtype.UUID `json:"search_provider_id"`
-	FetchProviderIDSet     bool        `json:"fetch_provider_id_set"`
-	FetchProviderID        pgtype.UUID `json:"fetch_provider_id"`
-	MemoryProviderID       pgtype.UUID `json:"memory_provider_id"`
-	ImageModelID           pgtype.UUID `json:"image_model_id"`
-	TtsModelID             pgtype.UUID `json:"tts_model_id"`
-	TranscriptionModelID   pgtype.UUID `json:"transcription_model_id"`
-	VideoModelID           pgtype.UUID `json:"video_model_id"`
-	PersistFullToolResults bool        `json:"persist_full_tool_results"`
-	ShowToolCallsInIm      bool        `json:"show_tool_calls_in_im"`
-	ToolApprovalConfig     []byte      `json:"tool_approval_config"`
-	DisplayEnabled         bool        `json:"display_enabled"`
-	OverlayProvider        string      `json:"overlay_provider"`
-	OverlayEnabled         bool        `json:"overlay_enabled"`
-	OverlayConfig          []byte      `json:"overlay_config"`
-	CommandUiLanguage      string      `json:"command_ui_language"`
-	ID                     pgtype.UUID `json:"id"`
+	Language                string      `json:"language"`
+	ReasoningEnabled        bool        `json:"reasoning_enabled"`
+	ReasoningEffort         string      `json:"reasoning_effort"`
+	HeartbeatEnabled        bool        `json:"heartbeat_enabled"`
+	HeartbeatInterval       int32       `json:"heartbeat_interval"`
+	HeartbeatPrompt         string      `json:"heartbeat_prompt"`
+	CompactionEnabled       bool        `json:"compaction_enabled"`
+	CompactionThreshold     int32       `json:"compaction_threshold"`
+	CompactionRatio         int32       `json:"compaction_ratio"`
+	Timezone                pgtype.Text `json:"timezone"`
+	ChatModelID             pgtype.UUID `json:"chat_model_id"`
+	ChatRuntime             string      `json:"chat_runtime"`
+	ChatAcpAgentID          pgtype.Text `json:"chat_acp_agent_id"`
+	ChatAcpProjectPath      string      `json:"chat_acp_project_path"`
+	ChatAcpProjectMode      string      `json:"chat_acp_project_mode"`
+	HeartbeatModelID        pgtype.UUID `json:"heartbeat_model_id"`
+	CompactionModelID       pgtype.UUID `json:"compaction_model_id"`
+	SearchProviderID        pgtype.UUID `json:"search_provider_id"`
+	FetchProviderIDSet      bool        `json:"fetch_provider_id_set"`
+	FetchProviderID         pgtype.UUID `json:"fetch_provider_id"`
+	MemoryProviderID        pgtype.UUID `json:"memory_provider_id"`
+	ImageModelID            pgtype.UUID `json:"image_model_id"`
+	TtsModelID              pgtype.UUID `json:"tts_model_id"`
+	TranscriptionModelID    pgtype.UUID `json:"transcription_model_id"`
+	VideoModelID            pgtype.UUID `json:"video_model_id"`
+	PersistFullToolResults  bool        `json:"persist_full_tool_results"`
+	ShowToolCallsInIm       bool        `json:"show_tool_calls_in_im"`
+	ToolApprovalConfig      []byte      `json:"tool_approval_config"`
+	DisplayEnabled          bool        `json:"display_enabled"`
+	OverlayProvider         string      `json:"overlay_provider"`
+	OverlayEnabled          bool        `json:"overlay_enabled"`
+	OverlayConfig           []byte      `json:"overlay_config"`
+	CommandUiLanguage       string      `json:"command_ui_language"`
+	InboundRateLimit        int32       `json:"inbound_rate_limit"`
+	InboundRateBurst        int32       `json:"inbound_rate_burst"`
+	WakeWordsEnabled        bool        `json:"wake_words_enabled"`
+	WakeWords               []string    `json:"wake_words"`
+	WakeWordCooldownSeconds int32       `json:"wake_word_cooldown_seconds"`
+	Persona                 []byte      `json:"persona"`
+	ID                      pgtype.UUID `json:"id"`
 }
 
 type UpsertBotSettingsRow struct {
-	BotID                  pgtype.UUID `json:"bot_id"`
-	Language               string      `json:"language"`
-	ReasoningEnabled       bool        `json:"reasoning_enabled"`
-	ReasoningEffort        string      `json:"reasoning_effort"`
-	HeartbeatEnabled       bool        `json:"heartbeat_enabled"`
-	HeartbeatInterval      int32       `json:"heartbeat_interval"`
-	HeartbeatPrompt        string      `json:"heartbeat_prompt"`
-	CompactionEnabled      bool        `json:"compaction_enabled"`
-	CompactionThreshold    int32       `json:"compaction_threshold"`
-	CompactionRatio        int32       `json:"compaction_ratio"`
-	Timezone               pgtype.Text `json:"timezone"`
-	ChatModelID            pgtype.UUID `json:"chat_model_id"`
-	ChatRuntime            string      `json:"chat_runtime"`
-	ChatAcpAgentID         pgtype.Text `json:"chat_acp_agent_id"`
-	ChatAcpProjectPath     string      `json:"chat_acp_project_path"`
-	ChatAcpProjectMode     string      `json:"chat_acp_project_mode"`
-	HeartbeatModelID       pgtype.UUID `json:"heartbeat_model_id"`
-	CompactionModelID      pgtype.UUID `json:"compaction_model_id"`
-	SearchProviderID       pgtype.UUID `json:"search_provider_id"`
-	FetchProviderID        pgtype.UUID `json:"fetch_provider_id"`
-	MemoryProviderID       pgtype.UUID `json:"memory_provider_id"`
-	ImageModelID           pgtype.UUID `json:"image_model_id"`
-	TtsModelID             pgtype.UUID `json:"tts_model_id"`
-	TranscriptionModelID   pgtype.UUID `json:"transcription_model_id"`
-	VideoModelID           pgtype.UUID `json:"video_model_id"`
-	PersistFullToolResults bool        `json:"persist_full_tool_results"`
-	ShowToolCallsInIm      bool        `json:"show_tool_calls_in_im"`
-	ToolApprovalConfig     []byte      `json:"tool_approval_config"`
-	DisplayEnabled         bool        `json:"display_enabled"`
-	OverlayProvider        string      `json:"overlay_provider"`
-	OverlayEnabled         bool        `json:"overlay_enabled"`
-	OverlayConfig          []byte      `json:"overlay_config"`
-	CommandUiLanguage      string      `json:"command_ui_language"`
+	BotID                   pgtype.UUID `json:"bot_id"`
+	Language                string      `json:"language"`
+	ReasoningEnabled        bool        `json:"reasoning_enabled"`
+	ReasoningEffort         string      `json:"reasoning_effort"`
+	HeartbeatEnabled        bool        `json:"heartbeat_enabled"`
+	HeartbeatInterval       int32       `json:"heartbeat_interval"`
+	HeartbeatPrompt         string      `json:"heartbeat_prompt"`
+	CompactionEnabled       bool        `json:"compaction_enabled"`
+	CompactionThreshold     int32       `json:"compaction_threshold"`
+	CompactionRatio         int32       `json:"compaction_ratio"`
+	Timezone                pgtype.Text `json:"timezone"`
+	ChatModelID             pgtype.UUID `json:"chat_model_id"`
+	ChatRuntime             string      `json:"chat_runtime"`
+	ChatAcpAgentID          pgtype.Text `json:"chat_acp_agent_id"`
+	ChatAcpProjectPath      string      `json:"chat_acp_project_path"`
+	ChatAcpProjectMode      string      `json:"chat_acp_project_mode"`
+	HeartbeatModelID        pgtype.UUID `json:"heartbeat_model_id"`
+	CompactionModelID       pgtype.UUID `json:"compaction_model_id"`
+	SearchProviderID        pgtype.UUID `json:"search_provider_id"`
+	FetchProviderID         pgtype.UUID `json:"fetch_provider_id"`
+	MemoryProviderID        pgtype.UUID `json:"memory_provider_id"`
+	ImageModelID            pgtype.UUID `json:"image_model_id"`
+	TtsModelID              pgtype.UUID `json:"tts_model_id"`
+	TranscriptionModelID    pgtype.UUID `json:"transcription_model_id"`
+	VideoModelID            pgtype.UUID `json:"video_model_id"`
+	PersistFullToolResults  bool        `json:"persist_full_tool_results"`
+	ShowToolCallsInIm       bool        `json:"show_tool_calls_in_im"`
+	ToolApprovalConfig      []byte      `json:"tool_approval_config"`
+	DisplayEnabled          bool        `json:"display_enabled"`
+	OverlayProvider         string      `json:"overlay_provider"`
+	OverlayEnabled          bool        `json:"overlay_enabled"`
+	OverlayConfig           []byte      `json:"overlay_config"`
+	CommandUiLanguage       string      `json:"command_ui_language"`
+	InboundRateLimit        int32       `json:"inbound_rate_limit"`
+	InboundRateBurst        int32       `json:"inbound_rate_burst"`
+	WakeWordsEnabled        bool        `json:"wake_words_enabled"`
+	WakeWords               []string    `json:"wake_words"`
+	WakeWordCooldownSeconds int32       `json:"wake_word_cooldown_seconds"`
+	Persona                 []byte      `json:"persona"`
 }
 
 func (q *Queries) UpsertBotSettings(ctx context.Context, arg UpsertBotSettingsParams) (UpsertBotSettingsRow, error) {
@@ -376,6 +424,12 @@ func (q *Queries) UpsertBotSettings(ctx context.Context, arg UpsertBotSettingsPa
 		arg.OverlayEnabled,
 		arg.OverlayConfig,
 		arg.CommandUiLanguage,
+		arg.InboundRateLimit,
+		arg.InboundRateBurst,
+		arg.WakeWordsEnabled,
+		arg.WakeWords,
+		arg.WakeWordCooldownSeconds,
+		arg.Persona,
 		arg.ID,
 	)
 	var i UpsertBotSettingsRow
@@ -413,6 +467,12 @@ func (q *Queries) UpsertBotSettings(ctx context.Context, arg UpsertBotSettingsPa
 		&i.OverlayEnabled,
 		&i.OverlayConfig,
 		&i.CommandUiLanguage,
+		&i.InboundRateLimit,
+		&i.InboundRateBurst,
+		&i.WakeWordsEnabled,
+		&i.WakeWords,
+		&i.WakeWordCooldownSeconds,
+		&i.Persona,
 	)
 	return i, err
 }
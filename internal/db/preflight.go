@@ -0,0 +1,267 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+const (
+	// minServerVersionNum is the oldest server the migrations run on;
+	// gen_random_uuid() is built in from PostgreSQL 13.
+	minServerVersionNum = 130000
+	// preflightListLimit bounds how many offending objects one line names.
+	preflightListLimit = 5
+)
+
+// ErrPreflightFailed is returned when the connecting role cannot run the
+// migrations.
+var ErrPreflightFailed = errors.New("database preflight failed")
+
+// PostgresExtensions are the extensions the primary migrations create.
+var PostgresExtensions = []string{"pgcrypto"}
+
+// MissingPrivilege is one thing the connecting role lacks, with the
+// statement an administrator can run to grant it.
+type MissingPrivilege struct {
+	Privilege string
+	Object    string
+	Fix       string
+}
+
+func (m MissingPrivilege) String() string {
+	out := m.Privilege + " on " + m.Object
+	if m.Fix != "" {
+		out += " (fix: " + m.Fix + ")"
+	}
+	return out
+}
+
+// PreflightReport is the result of checking a database before migrating it.
+// Managed services (Supabase, RDS, Cloud SQL) often hand out roles without
+// CREATE on the public schema or the right to create extensions; the report
+// names each gap instead of failing halfway through a migration.
+type PreflightReport struct {
+	Role          string
+	Database      string
+	ServerVersion string
+	Missing       []MissingPrivilege
+	Warnings      []string
+}
+
+// OK reports whether the role can run the migrations.
+func (r PreflightReport) OK() bool {
+	return len(r.Missing) == 0
+}
+
+// Err returns nil when the report is OK, or an ErrPreflightFailed listing
+// every missing privilege.
+func (r PreflightReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	lines := make([]string, 0, len(r.Missing))
+	for _, m := range r.Missing {
+		lines = append(lines, m.String())
+	}
+	return fmt.Errorf("%w: role %q is missing %s", ErrPreflightFailed, r.Role, strings.Join(lines, "; "))
+}
+
+// preflightFacts is what the checks read from the server.
+type preflightFacts struct {
+	role             string
+	database         string
+	serverVersion    string
+	serverVersionNum int
+	superuser        bool
+	bypassRLS        bool
+	databaseCreate   bool
+	publicExists     bool
+	publicUsage      bool
+	publicCreate     bool
+	templateExists   bool
+	templateOwned    bool
+	extensions       []extensionFacts
+	foreignTables    []string
+}
+
+type extensionFacts struct {
+	name      string
+	available bool
+	installed bool
+	trusted   bool
+}
+
+// PreflightPostgres connects with cfg and checks that the role can run the
+// migrations and create extensions.
+func PreflightPostgres(ctx context.Context, cfg config.PostgresConfig, extensions ...string) (PreflightReport, error) {
+	conn, err := pgx.Connect(ctx, DSN(cfg))
+	if err != nil {
+		return PreflightReport{}, fmt.Errorf("connect: %w", err)
+	}
+	defer func() { _ = conn.Close(context.WithoutCancel(ctx)) }()
+	return Preflight(ctx, conn, extensions...)
+}
+
+// Preflight checks that the connected role can run the migrations.
+func Preflight(ctx context.Context, conn *pgx.Conn, extensions ...string) (PreflightReport, error) {
+	facts, err := readPreflightFacts(ctx, conn, extensions)
+	if err != nil {
+		return PreflightReport{}, err
+	}
+	return evaluatePreflight(facts), nil
+}
+
+func readPreflightFacts(ctx context.Context, conn *pgx.Conn, extensions []string) (preflightFacts, error) {
+	var f preflightFacts
+	err := conn.QueryRow(ctx, `
+SELECT current_user,
+       current_database(),
+       current_setting('server_version'),
+       current_setting('server_version_num')::int,
+       r.rolsuper,
+       r.rolbypassrls,
+       has_database_privilege(current_database(), 'CREATE'),
+       pub.oid IS NOT NULL,
+       COALESCE(has_schema_privilege(pub.oid, 'USAGE'), false),
+       COALESCE(has_schema_privilege(pub.oid, 'CREATE'), false),
+       tpl.oid IS NOT NULL,
+       COALESCE(pg_has_role(tpl.nspowner, 'USAGE'), false)
+  FROM pg_roles r
+  LEFT JOIN pg_namespace pub ON pub.nspname = 'public'
+  LEFT JOIN pg_namespace tpl ON tpl.nspname = 'template'
+ WHERE r.rolname = current_user`).Scan(
+		&f.role, &f.database, &f.serverVersion, &f.serverVersionNum,
+		&f.superuser, &f.bypassRLS, &f.databaseCreate,
+		&f.publicExists, &f.publicUsage, &f.publicCreate,
+		&f.templateExists, &f.templateOwned,
+	)
+	if err != nil {
+		return preflightFacts{}, fmt.Errorf("read role privileges: %w", err)
+	}
+	if f.serverVersionNum < minServerVersionNum {
+		// The remaining catalog columns are newer than the minimum server.
+		return f, nil
+	}
+
+	if len(extensions) > 0 {
+		rows, err := conn.Query(ctx, `
+SELECT e.name,
+       e.installed_version IS NOT NULL,
+       COALESCE(bool_or(v.trusted), false)
+  FROM pg_available_extensions e
+  LEFT JOIN pg_available_extension_versions v ON v.name = e.name
+ WHERE e.name = ANY($1::text[])
+ GROUP BY e.name, e.installed_version`, extensions)
+		if err != nil {
+			return preflightFacts{}, fmt.Errorf("read extensions: %w", err)
+		}
+		found := map[string]extensionFacts{}
+		for rows.Next() {
+			ext := extensionFacts{available: true}
+			if err := rows.Scan(&ext.name, &ext.installed, &ext.trusted); err != nil {
+				rows.Close()
+				return preflightFacts{}, fmt.Errorf("read extensions: %w", err)
+			}
+			found[ext.name] = ext
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return preflightFacts{}, fmt.Errorf("read extensions: %w", err)
+		}
+		for _, name := range extensions {
+			ext, ok := found[name]
+			if !ok {
+				ext = extensionFacts{name: name}
+			}
+			f.extensions = append(f.extensions, ext)
+		}
+	}
+
+	// Memoh tables are the team-scoped ones plus the migration ledger, so
+	// tables of other applications sharing the schema are not reported.
+	rows, err := conn.Query(ctx, `
+SELECT c.relname
+  FROM pg_class c
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+ WHERE n.nspname = 'public'
+   AND c.relkind IN ('r', 'p')
+   AND (c.relname = 'schema_migrations'
+        OR EXISTS (SELECT 1 FROM pg_attribute a
+                    WHERE a.attrelid = c.oid AND a.attname = 'team_id' AND NOT a.attisdropped))
+   AND NOT pg_has_role(c.relowner, 'USAGE')
+ ORDER BY c.relname`)
+	if err != nil {
+		return preflightFacts{}, fmt.Errorf("read table owners: %w", err)
+	}
+	tables, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return preflightFacts{}, fmt.Errorf("read table owners: %w", err)
+	}
+	f.foreignTables = tables
+	return f, nil
+}
+
+func evaluatePreflight(f preflightFacts) PreflightReport {
+	report := PreflightReport{Role: f.role, Database: f.database, ServerVersion: f.serverVersion}
+	role := quoteIdent(f.role)
+	missing := func(privilege, object, fix string) {
+		report.Missing = append(report.Missing, MissingPrivilege{Privilege: privilege, Object: object, Fix: fix})
+	}
+
+	if f.serverVersionNum < minServerVersionNum {
+		missing("PostgreSQL 13 or newer", "server", "upgrade the server (running "+f.serverVersion+")")
+		return report
+	}
+	if !f.publicExists {
+		missing("schema", "public", "CREATE SCHEMA public AUTHORIZATION "+role)
+	} else {
+		if !f.publicUsage {
+			missing("USAGE", "schema public", "GRANT USAGE ON SCHEMA public TO "+role)
+		}
+		if !f.publicCreate {
+			// PostgreSQL 15 revoked CREATE on public from PUBLIC.
+			missing("CREATE", "schema public", "GRANT CREATE ON SCHEMA public TO "+role)
+		}
+	}
+	switch {
+	case !f.templateExists && !f.databaseCreate:
+		missing("CREATE", "database "+quoteIdent(f.database), "GRANT CREATE ON DATABASE "+quoteIdent(f.database)+" TO "+role)
+	case f.templateExists && !f.templateOwned:
+		missing("ownership", "schema template", "ALTER SCHEMA template OWNER TO "+role)
+	}
+	for _, ext := range f.extensions {
+		switch {
+		case ext.installed:
+		case !ext.available:
+			missing("extension", ext.name, "install "+ext.name+" on the server or enable it in the provider console")
+		case f.superuser || (ext.trusted && f.databaseCreate):
+		default:
+			missing("CREATE EXTENSION", ext.name, "run CREATE EXTENSION IF NOT EXISTS "+ext.name+" as an administrator")
+		}
+	}
+	if len(f.foreignTables) > 0 {
+		names := f.foreignTables
+		more := ""
+		if len(names) > preflightListLimit {
+			more = fmt.Sprintf(" and %d more", len(names)-preflightListLimit)
+			names = names[:preflightListLimit]
+		}
+		missing("ownership", "tables "+strings.Join(names, ", ")+more,
+			"connect as the role that ran earlier migrations or ALTER TABLE ... OWNER TO "+role)
+	}
+	if f.superuser || f.bypassRLS {
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("role %q bypasses row-level security; team isolation policies are not enforced for its connections", f.role))
+	}
+	return report
+}
+
+func quoteIdent(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
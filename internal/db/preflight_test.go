@@ -0,0 +1,78 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEvaluatePreflightReportsRestrictedManagedRole(t *testing.T) {
+	t.Parallel()
+
+	report := evaluatePreflight(preflightFacts{
+		role:             "app_user",
+		database:         "postgres",
+		serverVersion:    "15.4",
+		serverVersionNum: 150004,
+		publicExists:     true,
+		publicUsage:      true,
+		extensions: []extensionFacts{
+			{name: "pgcrypto", available: true, trusted: true},
+			{name: "vector"},
+		},
+		foreignTables: []string{"bots", "channels", "messages", "schema_migrations", "teams", "users"},
+	})
+	if report.OK() {
+		t.Fatal("report OK for a role without CREATE")
+	}
+	err := report.Err()
+	if !errors.Is(err, ErrPreflightFailed) {
+		t.Fatalf("Err() = %v, want ErrPreflightFailed", err)
+	}
+	for _, want := range []string{
+		`GRANT CREATE ON SCHEMA public TO "app_user"`,
+		`GRANT CREATE ON DATABASE "postgres" TO "app_user"`,
+		"CREATE EXTENSION IF NOT EXISTS pgcrypto",
+		"install vector",
+		"tables bots, channels, messages, schema_migrations, teams and 1 more",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("Err() missing %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "USAGE") {
+		t.Fatalf("Err() reports a privilege the role has:\n%v", err)
+	}
+}
+
+func TestEvaluatePreflightAcceptsOwnerRole(t *testing.T) {
+	t.Parallel()
+
+	report := evaluatePreflight(preflightFacts{
+		role:             "memoh",
+		serverVersionNum: 160002,
+		databaseCreate:   true,
+		publicExists:     true,
+		publicUsage:      true,
+		publicCreate:     true,
+		templateExists:   true,
+		templateOwned:    true,
+		bypassRLS:        true,
+		extensions:       []extensionFacts{{name: "pgcrypto", available: true, trusted: true}},
+	})
+	if err := report.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "bypasses row-level security") {
+		t.Fatalf("Warnings = %q, want RLS bypass warning", report.Warnings)
+	}
+}
+
+func TestEvaluatePreflightRejectsOldServer(t *testing.T) {
+	t.Parallel()
+
+	report := evaluatePreflight(preflightFacts{role: "memoh", serverVersion: "12.17", serverVersionNum: 120017})
+	if len(report.Missing) != 1 || !strings.Contains(report.Missing[0].String(), "12.17") {
+		t.Fatalf("Missing = %+v, want one server version entry", report.Missing)
+	}
+}
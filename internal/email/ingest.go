@@ -0,0 +1,166 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+// Binding config keys that let users teach a bot by forwarding email.
+const (
+	// BindingConfigMemoryIngest selects which inbound mail is saved to the
+	// bot's memory: MemoryIngestOff, MemoryIngestForwarded, or MemoryIngestAll.
+	// Memory is only reachable when the email runtime is embedded in the
+	// server; a standalone channel process notifies the bot instead.
+	BindingConfigMemoryIngest = "memory_ingest"
+	// BindingConfigMemoryIngestSenders limits ingestion to these sender
+	// addresses. Anyone who knows the address can mail it, so set this
+	// unless the inbox only receives trusted mail.
+	BindingConfigMemoryIngestSenders = "memory_ingest_senders"
+)
+
+const (
+	MemoryIngestOff = "off"
+	// MemoryIngestForwarded saves forwarded threads to memory; other mail
+	// notifies the bot as usual.
+	MemoryIngestForwarded = "forwarded"
+	// MemoryIngestAll makes the binding a dedicated ingest address: every
+	// email is saved to memory and the bot is not asked to handle it.
+	MemoryIngestAll = "all"
+)
+
+const (
+	maxIngestMessageRunes = 2000
+	maxIngestRunes        = 8000
+)
+
+type memoryIngestConfig struct {
+	mode    string
+	senders []string
+}
+
+func parseMemoryIngestConfig(cfg map[string]any) (memoryIngestConfig, error) {
+	out := memoryIngestConfig{mode: MemoryIngestOff}
+	if raw, ok := cfg[BindingConfigMemoryIngest]; ok && raw != nil {
+		mode, ok := raw.(string)
+		if !ok {
+			return out, fmt.Errorf("%s must be a string", BindingConfigMemoryIngest)
+		}
+		switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+		case "", MemoryIngestOff:
+		case MemoryIngestForwarded, MemoryIngestAll:
+			out.mode = mode
+		default:
+			return out, fmt.Errorf("%s must be one of %q, %q, %q", BindingConfigMemoryIngest, MemoryIngestOff, MemoryIngestForwarded, MemoryIngestAll)
+		}
+	}
+	if raw, ok := cfg[BindingConfigMemoryIngestSenders]; ok && raw != nil {
+		list, ok := raw.([]any)
+		if !ok {
+			return out, fmt.Errorf("%s must be a list of addresses", BindingConfigMemoryIngestSenders)
+		}
+		for _, item := range list {
+			value, _ := item.(string)
+			addr, err := mail.ParseAddress(strings.TrimSpace(value))
+			if err != nil {
+				return out, fmt.Errorf("%s: invalid address %q", BindingConfigMemoryIngestSenders, value)
+			}
+			out.senders = append(out.senders, strings.ToLower(addr.Address))
+		}
+	}
+	return out, nil
+}
+
+// ErrInvalidBindingConfig is returned for binding config the trigger cannot
+// act on.
+var ErrInvalidBindingConfig = errors.New("invalid binding config")
+
+func validateBindingConfig(cfg map[string]any) error {
+	if _, err := parseMemoryIngestConfig(cfg); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidBindingConfig, err.Error())
+	}
+	return nil
+}
+
+// allowsSender reports whether mail from the From header may be ingested.
+func (c memoryIngestConfig) allowsSender(from string) bool {
+	if len(c.senders) == 0 {
+		return true
+	}
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(c.senders, strings.ToLower(addr.Address))
+}
+
+// ingestMemory saves the thread to the bot's memory, tagged with its
+// participants so memory search can filter by who was on it.
+func (t *Trigger) ingestMemory(ctx context.Context, binding BindingResponse, inbound InboundEmail, thread Thread) error {
+	provider, err := memprovider.ResolveBotProvider(ctx, t.memoryRegistry, t.settingsService, binding.BotID)
+	if err != nil {
+		return err
+	}
+	metadata := map[string]any{
+		"source":        "email",
+		"binding_id":    binding.ID,
+		"email_address": binding.EmailAddress,
+		"subject":       thread.Subject,
+		"message_id":    inbound.MessageID,
+		"forwarded_by":  inbound.From,
+		"participants":  thread.Participants,
+	}
+	_, err = provider.Add(ctx, memprovider.AddRequest{
+		Message:  threadMemoryText(inbound, thread),
+		BotID:    binding.BotID,
+		Metadata: metadata,
+	})
+	return err
+}
+
+// threadMemoryText renders the thread as a compact, structured summary.
+func threadMemoryText(inbound InboundEmail, thread Thread) string {
+	var b strings.Builder
+	if thread.Forwarded() {
+		fmt.Fprintf(&b, "Email thread %q forwarded by %s", thread.Subject, inbound.From)
+	} else {
+		fmt.Fprintf(&b, "Email %q from %s", thread.Subject, inbound.From)
+	}
+	if !inbound.ReceivedAt.IsZero() {
+		b.WriteString(" on " + inbound.ReceivedAt.UTC().Format("2006-01-02"))
+	}
+	b.WriteString(".")
+	if len(thread.Participants) > 0 {
+		b.WriteString("\nParticipants: " + strings.Join(thread.Participants, ", "))
+	}
+	if thread.Note != "" {
+		if thread.Forwarded() {
+			b.WriteString("\n\nNote from the sender:\n")
+		} else {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(textutil.TruncateRunesWithSuffix(thread.Note, maxIngestMessageRunes, "…"))
+	}
+	for _, msg := range thread.Messages {
+		b.WriteString("\n\n---\nFrom: " + msg.From)
+		if msg.Date != "" {
+			b.WriteString("\nDate: " + msg.Date)
+		}
+		if msg.To != "" {
+			b.WriteString("\nTo: " + msg.To)
+		}
+		if msg.Cc != "" {
+			b.WriteString("\nCc: " + msg.Cc)
+		}
+		if msg.Body != "" {
+			b.WriteString("\n\n" + textutil.TruncateRunesWithSuffix(msg.Body, maxIngestMessageRunes, "…"))
+		}
+	}
+	return textutil.TruncateRunesWithSuffix(b.String(), maxIngestRunes, "…")
+}
@@ -311,6 +311,9 @@ func (s *Service) CreateBinding(ctx context.Context, botID string, req CreateBin
 	if req.CanDelete != nil {
 		canDelete = *req.CanDelete
 	}
+	if err := validateBindingConfig(req.Config); err != nil {
+		return BindingResponse{}, err
+	}
 	configJSON, err := json.Marshal(req.Config)
 	if err != nil {
 		return BindingResponse{}, fmt.Errorf("marshal config: %w", err)
@@ -412,6 +415,9 @@ func (s *Service) UpdateBinding(ctx context.Context, id string, req UpdateBindin
 	}
 	config := current.Config
 	if req.Config != nil {
+		if err := validateBindingConfig(req.Config); err != nil {
+			return BindingResponse{}, err
+		}
 		configJSON, marshalErr := json.Marshal(req.Config)
 		if marshalErr != nil {
 			return BindingResponse{}, fmt.Errorf("marshal config: %w", marshalErr)
@@ -0,0 +1,228 @@
+package email
+
+import (
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+)
+
+// Thread is an inbound email split into the messages it forwards, with
+// quoting and signatures removed.
+type Thread struct {
+	Subject string
+	// Note is what the sender wrote above the forwarded messages.
+	Note string
+	// Messages are the forwarded messages, oldest first.
+	Messages []ThreadMessage
+	// Participants are the addresses (or names, when a client dropped the
+	// address) of everyone on the forwarded messages.
+	Participants []string
+}
+
+type ThreadMessage struct {
+	From    string
+	To      string
+	Cc      string
+	Date    string
+	Subject string
+	Body    string
+}
+
+// Forwarded reports whether the email forwarded any messages.
+func (t Thread) Forwarded() bool {
+	return len(t.Messages) > 0
+}
+
+var (
+	forwardMarkers = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)^-{2,}\s*forwarded message\s*-{2,}$`),
+		regexp.MustCompile(`(?i)^begin forwarded message:?$`),
+		regexp.MustCompile(`(?i)^-{2,}\s*original message\s*-{2,}$`),
+	}
+	// Outlook separates forwarded messages with a rule followed directly by
+	// the header block.
+	outlookRule     = regexp.MustCompile(`^_{10,}$`)
+	headerLine      = regexp.MustCompile(`^(?i)(from|sent|date|to|cc|subject):\s*(.*)$`)
+	replyAttributor = regexp.MustCompile(`(?i)^on .+ wrote:$`)
+	mobileSignature = regexp.MustCompile(`(?i)^(sent from my |get outlook for )`)
+	bareAddress     = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// ParseThread splits mail into the sender's note and the messages it
+// forwards. Gmail, Apple Mail, and Outlook forward markers are recognized.
+func ParseThread(mail InboundEmail) Thread {
+	body := mail.BodyText
+	if strings.TrimSpace(body) == "" && strings.TrimSpace(mail.BodyHTML) != "" {
+		if text, err := htmltomarkdown.ConvertString(mail.BodyHTML); err == nil {
+			body = text
+		}
+	}
+	body = strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\r", "\n")
+	lines := strings.Split(body, "\n")
+
+	thread := Thread{Subject: strings.TrimSpace(mail.Subject)}
+	start := 0
+	var sections [][]string
+	for i := 0; i < len(lines); i++ {
+		if !isForwardMarker(lines, i) {
+			continue
+		}
+		sections = append(sections, lines[start:i])
+		start = i + 1
+	}
+	sections = append(sections, lines[start:])
+
+	thread.Note = cleanBody(sections[0])
+	for _, section := range sections[1:] {
+		msg := parseForwardedMessage(section)
+		if msg.From == "" && msg.Body == "" {
+			continue
+		}
+		thread.Messages = append(thread.Messages, msg)
+	}
+	// Each forward nests the older messages below it.
+	slices.Reverse(thread.Messages)
+
+	seen := map[string]bool{}
+	for _, msg := range thread.Messages {
+		for _, field := range []string{msg.From, msg.To, msg.Cc} {
+			for _, p := range participants(field) {
+				key := strings.ToLower(p)
+				if !seen[key] {
+					seen[key] = true
+					thread.Participants = append(thread.Participants, p)
+				}
+			}
+		}
+	}
+	return thread
+}
+
+func isForwardMarker(lines []string, i int) bool {
+	line := strings.TrimSpace(lines[i])
+	for _, marker := range forwardMarkers {
+		if marker.MatchString(line) {
+			return true
+		}
+	}
+	if outlookRule.MatchString(line) {
+		for j := i + 1; j < len(lines); j++ {
+			next := strings.TrimSpace(lines[j])
+			if next == "" {
+				continue
+			}
+			m := headerLine.FindStringSubmatch(next)
+			return m != nil && strings.EqualFold(m[1], "from")
+		}
+	}
+	return false
+}
+
+// parseForwardedMessage reads the header block a client writes above a
+// forwarded message and cleans the body below it.
+func parseForwardedMessage(lines []string) ThreadMessage {
+	var msg ThreadMessage
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	last := ""
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			i++
+			break
+		}
+		m := headerLine.FindStringSubmatch(line)
+		if m == nil {
+			if last == "" || (lines[i][0] != ' ' && lines[i][0] != '\t') {
+				break
+			}
+			// Folded header value.
+			m = []string{"", last, line}
+		}
+		last = m[1]
+		value := strings.TrimSpace(m[2])
+		switch strings.ToLower(m[1]) {
+		case "from":
+			msg.From = joinHeader(msg.From, value)
+		case "sent", "date":
+			msg.Date = joinHeader(msg.Date, value)
+		case "to":
+			msg.To = joinHeader(msg.To, value)
+		case "cc":
+			msg.Cc = joinHeader(msg.Cc, value)
+		case "subject":
+			msg.Subject = joinHeader(msg.Subject, value)
+		}
+	}
+	msg.Body = cleanBody(lines[min(i, len(lines)):])
+	return msg
+}
+
+func joinHeader(existing, value string) string {
+	if existing == "" {
+		return value
+	}
+	return existing + " " + value
+}
+
+// cleanBody drops quoted replies, reply attributions, and signatures.
+func cleanBody(lines []string) string {
+	var out []string
+	blank := false
+	for _, raw := range lines {
+		line := strings.TrimRight(raw, " \t")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "--" {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") || replyAttributor.MatchString(trimmed) || mobileSignature.MatchString(trimmed) {
+			continue
+		}
+		if trimmed == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// participants lists the addresses in a header value, falling back to the
+// raw names when a client wrote names only.
+func participants(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	// Outlook writes "Name <mailto:addr>" and separates recipients with ";".
+	normalized := strings.ReplaceAll(strings.ReplaceAll(value, "mailto:", ""), ";", ",")
+	if list, err := mail.ParseAddressList(normalized); err == nil {
+		out := make([]string, 0, len(list))
+		for _, addr := range list {
+			out = append(out, strings.ToLower(addr.Address))
+		}
+		return out
+	}
+	if found := bareAddress.FindAllString(normalized, -1); len(found) > 0 {
+		for i := range found {
+			found[i] = strings.ToLower(found[i])
+		}
+		return found
+	}
+	var out []string
+	for _, name := range strings.Split(normalized, ",") {
+		if name = strings.Trim(strings.TrimSpace(name), `"`); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+package email
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseThreadGmailForward(t *testing.T) {
+	thread := ParseThread(InboundEmail{
+		From:    "Owner <owner@example.com>",
+		Subject: "Fwd: Launch plan",
+		BodyText: "Keep this in mind for the launch.\r\n" +
+			"\r\n" +
+			"---------- Forwarded message ---------\r\n" +
+			"From: Alice Smith <alice@example.com>\r\n" +
+			"Date: Mon, 2 Mar 2026 at 09:12\r\n" +
+			"Subject: Launch plan\r\n" +
+			"To: Bob <bob@example.com>, carol@example.com\r\n" +
+			"\r\n" +
+			"We ship on Friday.\r\n" +
+			"\r\n" +
+			"On Sun, 1 Mar 2026 at 18:00, Bob <bob@example.com> wrote:\r\n" +
+			"> Can we ship this week?\r\n" +
+			"> Thanks\r\n" +
+			"\r\n" +
+			"-- \r\n" +
+			"Alice Smith\r\n" +
+			"VP Product\r\n",
+	})
+
+	if !thread.Forwarded() || len(thread.Messages) != 1 {
+		t.Fatalf("messages = %#v, want one forwarded message", thread.Messages)
+	}
+	if thread.Note != "Keep this in mind for the launch." {
+		t.Fatalf("note = %q", thread.Note)
+	}
+	msg := thread.Messages[0]
+	if msg.From != "Alice Smith <alice@example.com>" || msg.Subject != "Launch plan" || msg.Date != "Mon, 2 Mar 2026 at 09:12" {
+		t.Fatalf("headers = %#v", msg)
+	}
+	if msg.Body != "We ship on Friday." {
+		t.Fatalf("body = %q, want quoting and signature stripped", msg.Body)
+	}
+	want := []string{"alice@example.com", "bob@example.com", "carol@example.com"}
+	if !slices.Equal(thread.Participants, want) {
+		t.Fatalf("participants = %v, want %v", thread.Participants, want)
+	}
+}
+
+func TestParseThreadNestedForwardsOldestFirst(t *testing.T) {
+	thread := ParseThread(InboundEmail{
+		From:    "owner@example.com",
+		Subject: "Fwd: Fwd: Contract",
+		BodyText: "Begin forwarded message:\n" +
+			"\n" +
+			"From: Dan <dan@example.com>\n" +
+			"Subject: Fwd: Contract\n" +
+			"To: owner@example.com\n" +
+			"\n" +
+			"See below.\n" +
+			"\n" +
+			"________________________________\n" +
+			"From: Erin <mailto:erin@example.com>\n" +
+			"Sent: Tuesday, March 3, 2026 10:00 AM\n" +
+			"To: Dan <dan@example.com>; Frank <frank@example.com>\n" +
+			"Subject: Contract\n" +
+			"\n" +
+			"Signed copy attached.\n" +
+			"Sent from my iPhone\n",
+	})
+
+	if len(thread.Messages) != 2 {
+		t.Fatalf("messages = %#v, want 2", thread.Messages)
+	}
+	if thread.Messages[0].Body != "Signed copy attached." || thread.Messages[1].Body != "See below." {
+		t.Fatalf("messages not oldest first: %#v", thread.Messages)
+	}
+	if thread.Messages[0].Date != "Tuesday, March 3, 2026 10:00 AM" {
+		t.Fatalf("outlook Sent header not read as date: %#v", thread.Messages[0])
+	}
+	want := []string{"erin@example.com", "dan@example.com", "frank@example.com", "owner@example.com"}
+	if !slices.Equal(thread.Participants, want) {
+		t.Fatalf("participants = %v, want %v", thread.Participants, want)
+	}
+}
+
+func TestParseThreadWithoutForward(t *testing.T) {
+	thread := ParseThread(InboundEmail{
+		From:     "owner@example.com",
+		Subject:  "Note",
+		BodyHTML: "<p>Remember the <b>budget</b> is fixed.</p>",
+	})
+	if thread.Forwarded() {
+		t.Fatalf("plain email parsed as forward: %#v", thread.Messages)
+	}
+	if !strings.Contains(thread.Note, "budget") {
+		t.Fatalf("note = %q, want HTML body converted", thread.Note)
+	}
+}
+
+func TestParseMemoryIngestConfig(t *testing.T) {
+	cfg, err := parseMemoryIngestConfig(map[string]any{
+		BindingConfigMemoryIngest:        "Forwarded",
+		BindingConfigMemoryIngestSenders: []any{"Owner <OWNER@example.com>"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.mode != MemoryIngestForwarded {
+		t.Fatalf("mode = %q", cfg.mode)
+	}
+	if !cfg.allowsSender("owner@example.com") || cfg.allowsSender("Mallory <mallory@example.com>") {
+		t.Fatalf("sender allowlist not applied: %#v", cfg.senders)
+	}
+
+	if cfg, err := parseMemoryIngestConfig(nil); err != nil || cfg.mode != MemoryIngestOff || !cfg.allowsSender("anyone@example.com") {
+		t.Fatalf("default config = %#v, %v", cfg, err)
+	}
+	for _, bad := range []map[string]any{
+		{BindingConfigMemoryIngest: "sometimes"},
+		{BindingConfigMemoryIngest: true},
+		{BindingConfigMemoryIngestSenders: "owner@example.com"},
+		{BindingConfigMemoryIngestSenders: []any{"not an address"}},
+	} {
+		if err := validateBindingConfig(bad); err == nil {
+			t.Fatalf("config %v accepted", bad)
+		}
+	}
+}
+
+func TestThreadMemoryText(t *testing.T) {
+	inbound := InboundEmail{From: "owner@example.com", Subject: "Fwd: Launch plan"}
+	thread := Thread{
+		Subject:      "Fwd: Launch plan",
+		Note:         "Keep this in mind.",
+		Participants: []string{"alice@example.com"},
+		Messages:     []ThreadMessage{{From: "alice@example.com", Date: "Mon", Body: "We ship on Friday."}},
+	}
+	text := threadMemoryText(inbound, thread)
+	for _, want := range []string{
+		`Email thread "Fwd: Launch plan" forwarded by owner@example.com.`,
+		"Participants: alice@example.com",
+		"Note from the sender:\nKeep this in mind.",
+		"From: alice@example.com\nDate: Mon\n\nWe ship on Friday.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("memory text missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/settings"
 )
 
 // ChatTriggerer triggers a proactive bot conversation (e.g. when a new email arrives).
@@ -12,11 +15,14 @@ type ChatTriggerer interface {
 }
 
 // Trigger notifies bots when a new email arrives and immediately triggers
-// the bot's LLM to process it.
+// the bot's LLM to process it. Bindings configured for memory ingest also
+// save the mail to the bot's memory.
 type Trigger struct {
-	logger        *slog.Logger
-	emailService  *Service
-	chatTriggerer ChatTriggerer
+	logger          *slog.Logger
+	emailService    *Service
+	chatTriggerer   ChatTriggerer
+	memoryRegistry  *memprovider.Registry
+	settingsService *settings.Service
 }
 
 func NewTrigger(log *slog.Logger, emailService *Service, chatTriggerer ChatTriggerer) *Trigger {
@@ -27,6 +33,14 @@ func NewTrigger(log *slog.Logger, emailService *Service, chatTriggerer ChatTrigg
 	}
 }
 
+func (t *Trigger) SetMemoryRegistry(registry *memprovider.Registry) {
+	t.memoryRegistry = registry
+}
+
+func (t *Trigger) SetSettingsService(svc *settings.Service) {
+	t.settingsService = svc
+}
+
 // HandleInbound triggers a conversation for each bound bot so it can process
 // the incoming email.
 func (t *Trigger) HandleInbound(ctx context.Context, providerID string, mail InboundEmail) error {
@@ -41,9 +55,36 @@ func (t *Trigger) HandleInbound(ctx context.Context, providerID string, mail Inb
 		return err
 	}
 
+	var thread *Thread
 	for _, binding := range bindings {
 		content := fmt.Sprintf("New email received at %s from %s — %s", binding.EmailAddress, mail.From, mail.Subject)
 
+		ingest, err := parseMemoryIngestConfig(binding.Config)
+		if err != nil {
+			t.logger.Warn("invalid email binding memory config", slog.String("binding_id", binding.ID), slog.Any("error", err))
+		}
+		if ingest.mode != MemoryIngestOff && ingest.allowsSender(mail.From) {
+			if thread == nil {
+				parsed := ParseThread(mail)
+				thread = &parsed
+			}
+			if ingest.mode == MemoryIngestAll || thread.Forwarded() {
+				if err := t.ingestMemory(ctx, binding, mail, *thread); err != nil {
+					t.logger.Error("failed to save email to memory",
+						slog.String("bot_id", binding.BotID),
+						slog.Any("error", err))
+				} else {
+					t.logger.Info("email saved to bot memory",
+						slog.String("bot_id", binding.BotID),
+						slog.Int("messages", len(thread.Messages)))
+					if ingest.mode == MemoryIngestAll {
+						continue
+					}
+					content += "\n(The forwarded thread was saved to your memory.)"
+				}
+			}
+		}
+
 		t.logger.Info("bot notified of new email",
 			slog.String("bot_id", binding.BotID),
 			slog.String("from", mail.From))
@@ -0,0 +1,241 @@
+// Package encryption seals conversation content at rest. Each bot gets its
+// own random data key; the data key is stored wrapped (AES-256-GCM) by the
+// deployment master key, so a database dump alone never yields plaintext.
+//
+// Sealed values are self-describing, which keeps the layer transparent:
+// Open passes legacy plaintext through unchanged, and a deployment can turn
+// encryption on without rewriting existing rows.
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+const (
+	keyLen = 32 // AES-256
+
+	// envelopeField marks a sealed JSON document. A JSON object keeps the
+	// value valid for JSONB columns.
+	envelopeField = "$memoh_enc"
+	envelopeV1    = 1
+
+	// textPrefix marks a sealed TEXT value.
+	textPrefix = "memoh:enc:v1:"
+)
+
+var (
+	// ErrInvalidMasterKey is returned for a master key that is not 32 bytes
+	// of base64.
+	ErrInvalidMasterKey = errors.New("encryption: master key must be 32 bytes, base64 encoded")
+	// ErrAuth is returned when a sealed value or wrapped key fails
+	// authentication: the wrong master key or tampered data.
+	ErrAuth = errors.New("encryption: authentication failed")
+)
+
+// KeyStore persists wrapped per-bot data keys. *sqlc.Queries satisfies it.
+type KeyStore interface {
+	CreateBotDataKey(ctx context.Context, arg sqlc.CreateBotDataKeyParams) (int64, error)
+	GetBotDataKey(ctx context.Context, botID pgtype.UUID) ([]byte, error)
+}
+
+// Keyring resolves per-bot data keys and seals or opens content with them.
+// A nil *Keyring is valid and leaves every value untouched.
+type Keyring struct {
+	master cipher.AEAD
+	store  KeyStore
+
+	mu   sync.Mutex
+	keys map[pgtype.UUID]cipher.AEAD
+}
+
+// ParseMasterKey decodes a base64 master key.
+func ParseMasterKey(encoded string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(raw) != keyLen {
+		return nil, ErrInvalidMasterKey
+	}
+	return raw, nil
+}
+
+// NewKeyring returns a keyring wrapping data keys with masterKey.
+func NewKeyring(masterKey []byte, store KeyStore) (*Keyring, error) {
+	if len(masterKey) != keyLen {
+		return nil, ErrInvalidMasterKey
+	}
+	if store == nil {
+		return nil, errors.New("encryption: key store is required")
+	}
+	master, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Keyring{master: master, store: store, keys: map[pgtype.UUID]cipher.AEAD{}}, nil
+}
+
+// SealJSON encrypts a JSON document for botID. The result is itself a JSON
+// object so it can be stored in the same JSONB column.
+func (k *Keyring) SealJSON(ctx context.Context, botID pgtype.UUID, doc []byte) ([]byte, error) {
+	if k == nil || len(doc) == 0 {
+		return doc, nil
+	}
+	ct, err := k.seal(ctx, botID, doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]any{envelopeField: envelopeV1, "ct": ct})
+}
+
+// OpenJSON reverses SealJSON. Documents that were never sealed are returned
+// unchanged.
+func (k *Keyring) OpenJSON(ctx context.Context, botID pgtype.UUID, stored []byte) ([]byte, error) {
+	if k == nil || !bytes.Contains(stored, []byte(`"`+envelopeField+`"`)) {
+		return stored, nil
+	}
+	var env struct {
+		Version int    `json:"$memoh_enc"`
+		CT      string `json:"ct"`
+	}
+	if err := json.Unmarshal(stored, &env); err != nil || env.Version != envelopeV1 || env.CT == "" {
+		return stored, nil
+	}
+	return k.open(ctx, botID, env.CT)
+}
+
+// SealText encrypts a TEXT value for botID. Empty values stay empty.
+func (k *Keyring) SealText(ctx context.Context, botID pgtype.UUID, text string) (string, error) {
+	if k == nil || text == "" {
+		return text, nil
+	}
+	ct, err := k.seal(ctx, botID, []byte(text))
+	if err != nil {
+		return "", err
+	}
+	return textPrefix + ct, nil
+}
+
+// OpenText reverses SealText. Values that were never sealed are returned
+// unchanged.
+func (k *Keyring) OpenText(ctx context.Context, botID pgtype.UUID, stored string) (string, error) {
+	if k == nil || !strings.HasPrefix(stored, textPrefix) {
+		return stored, nil
+	}
+	plain, err := k.open(ctx, botID, strings.TrimPrefix(stored, textPrefix))
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// IsSealedText reports whether a TEXT value was produced by SealText.
+func IsSealedText(stored string) bool {
+	return strings.HasPrefix(stored, textPrefix)
+}
+
+func (k *Keyring) seal(ctx context.Context, botID pgtype.UUID, plain []byte) (string, error) {
+	aead, err := k.dataKey(ctx, botID)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealWith(aead, plain, botID.Bytes[:])), nil
+}
+
+func (k *Keyring) open(ctx context.Context, botID pgtype.UUID, encoded string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrAuth
+	}
+	aead, err := k.dataKey(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	return openWith(aead, raw, botID.Bytes[:])
+}
+
+// dataKey returns the bot's data key, creating and storing it on first use.
+// Concurrent creators race on the insert; everyone then reads the winner.
+func (k *Keyring) dataKey(ctx context.Context, botID pgtype.UUID) (cipher.AEAD, error) {
+	if !botID.Valid {
+		return nil, errors.New("encryption: bot id is required")
+	}
+	k.mu.Lock()
+	aead, ok := k.keys[botID]
+	k.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	wrapped, err := k.store.GetBotDataKey(ctx, botID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		fresh := make([]byte, keyLen)
+		if _, err := rand.Read(fresh); err != nil {
+			return nil, fmt.Errorf("encryption: generate data key: %w", err)
+		}
+		if _, err := k.store.CreateBotDataKey(ctx, sqlc.CreateBotDataKeyParams{
+			BotID:      botID,
+			WrappedKey: sealWith(k.master, fresh, botID.Bytes[:]),
+		}); err != nil {
+			return nil, fmt.Errorf("encryption: store data key: %w", err)
+		}
+		wrapped, err = k.store.GetBotDataKey(ctx, botID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encryption: load data key: %w", err)
+	}
+	raw, err := openWith(k.master, wrapped, botID.Bytes[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err = newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.Lock()
+	k.keys[botID] = aead
+	k.mu.Unlock()
+	return aead, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealWith returns nonce || ciphertext. The bot id is bound as associated
+// data so a value copied into another bot's rows fails to open.
+func sealWith(aead cipher.AEAD, plain, ad []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("encryption: read nonce: %v", err))
+	}
+	return aead.Seal(nonce, nonce, plain, ad)
+}
+
+func openWith(aead cipher.AEAD, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrAuth
+	}
+	nonce, ct := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ct, ad)
+	if err != nil {
+		return nil, ErrAuth
+	}
+	return plain, nil
+}
@@ -0,0 +1,114 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+type memoryKeyStore struct {
+	keys map[pgtype.UUID][]byte
+}
+
+func (m *memoryKeyStore) CreateBotDataKey(_ context.Context, arg sqlc.CreateBotDataKeyParams) (int64, error) {
+	if _, ok := m.keys[arg.BotID]; ok {
+		return 0, nil
+	}
+	m.keys[arg.BotID] = arg.WrappedKey
+	return 1, nil
+}
+
+func (m *memoryKeyStore) GetBotDataKey(_ context.Context, botID pgtype.UUID) ([]byte, error) {
+	key, ok := m.keys[botID]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return key, nil
+}
+
+func testBotID(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func testMasterKey(b byte) []byte {
+	key := make([]byte, keyLen)
+	for i := range key {
+		key[i] = b
+	}
+	return key
+}
+
+func TestKeyringRoundTripsJSONAndText(t *testing.T) {
+	store := &memoryKeyStore{keys: map[pgtype.UUID][]byte{}}
+	keyring, err := NewKeyring(testMasterKey(1), store)
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	ctx := context.Background()
+	bot := testBotID(1)
+
+	doc := []byte(`{"role":"user","content":"secret plans"}`)
+	sealed, err := keyring.SealJSON(ctx, bot, doc)
+	if err != nil {
+		t.Fatalf("seal json: %v", err)
+	}
+	if strings.Contains(string(sealed), "secret") {
+		t.Fatalf("sealed document leaks plaintext: %s", sealed)
+	}
+	opened, err := keyring.OpenJSON(ctx, bot, sealed)
+	if err != nil || string(opened) != string(doc) {
+		t.Fatalf("open json = %s, %v", opened, err)
+	}
+
+	text, err := keyring.SealText(ctx, bot, "secret plans")
+	if err != nil || !IsSealedText(text) {
+		t.Fatalf("seal text = %q, %v", text, err)
+	}
+	plain, err := keyring.OpenText(ctx, bot, text)
+	if err != nil || plain != "secret plans" {
+		t.Fatalf("open text = %q, %v", plain, err)
+	}
+	if len(store.keys) != 1 {
+		t.Fatalf("stored %d data keys, want 1", len(store.keys))
+	}
+}
+
+func TestKeyringPassesLegacyPlaintextThrough(t *testing.T) {
+	keyring, err := NewKeyring(testMasterKey(1), &memoryKeyStore{keys: map[pgtype.UUID][]byte{}})
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	legacy := []byte(`{"content":"hello"}`)
+	opened, err := keyring.OpenJSON(context.Background(), testBotID(1), legacy)
+	if err != nil || string(opened) != string(legacy) {
+		t.Fatalf("open legacy = %s, %v", opened, err)
+	}
+	var nilKeyring *Keyring
+	if out, _ := nilKeyring.SealText(context.Background(), testBotID(1), "hello"); out != "hello" {
+		t.Fatalf("nil keyring sealed text: %q", out)
+	}
+}
+
+func TestKeyringRejectsWrongMasterKeyAndOtherBot(t *testing.T) {
+	store := &memoryKeyStore{keys: map[pgtype.UUID][]byte{}}
+	ctx := context.Background()
+	keyring, _ := NewKeyring(testMasterKey(1), store)
+	sealed, err := keyring.SealText(ctx, testBotID(1), "secret")
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+
+	wrong, _ := NewKeyring(testMasterKey(2), store)
+	if _, err := wrong.OpenText(ctx, testBotID(1), sealed); !errors.Is(err, ErrAuth) {
+		t.Fatalf("open with wrong master key err = %v, want ErrAuth", err)
+	}
+	if _, err := keyring.OpenText(ctx, testBotID(2), sealed); !errors.Is(err, ErrAuth) {
+		t.Fatalf("open as another bot err = %v, want ErrAuth", err)
+	}
+}
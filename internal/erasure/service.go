@@ -0,0 +1,256 @@
+// Package erasure implements the right-to-be-forgotten workflow: removing
+// everything Memoh stored about one channel identity across the message,
+// memory and identity services, and recording that it happened.
+package erasure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/audit"
+	"github.com/memohai/memoh/internal/channel/identities"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+// ErrChannelIdentityNotFound is returned when the identity does not exist or
+// was already erased.
+var ErrChannelIdentityNotFound = identities.ErrChannelIdentityNotFound
+
+// Report describes what an erasure removed. Memory removal runs against
+// external providers before the database transaction; failures there are
+// listed in Errors and do not block removing the rest.
+type Report struct {
+	ChannelIdentityID string    `json:"channel_identity_id"`
+	Channel           string    `json:"channel"`
+	ErasedAt          time.Time `json:"erased_at"`
+	BotIDs            []string  `json:"bot_ids"`
+	Messages          int       `json:"messages"`
+	MediaReferences   int64     `json:"media_references"`
+	SessionEvents     int64     `json:"session_events"`
+	InboundFailures   int64     `json:"inbound_failures"`
+	OutboxEntries     int64     `json:"outbox_entries"`
+	Memories          int       `json:"memories"`
+	ContactRecord     bool      `json:"contact_record"`
+	AuditLogID        string    `json:"audit_log_id"`
+	Errors            []string  `json:"errors,omitempty"`
+}
+
+type erasureQueries interface {
+	ListMessageRefsBySenderChannelIdentity(ctx context.Context, channelIdentityID pgtype.UUID) ([]sqlc.ListMessageRefsBySenderChannelIdentityRow, error)
+	CountMessageAssetsByMessageIDs(ctx context.Context, messageIDs []pgtype.UUID) (int64, error)
+	DeleteSessionEventsBySenderChannelIdentity(ctx context.Context, channelIdentityID pgtype.UUID) (int64, error)
+	DeleteInboundFailuresByChannelIdentity(ctx context.Context, channelIdentityID pgtype.UUID) (int64, error)
+	DeleteChannelOutboxByChannelIdentity(ctx context.Context, channelIdentityID pgtype.UUID) (int64, error)
+	DeleteChannelIdentity(ctx context.Context, id pgtype.UUID) (int64, error)
+	audit.Store
+}
+
+type transactionalQueries interface {
+	InTx(ctx context.Context, fn func(dbstore.Queries) error) error
+}
+
+// Service orchestrates identity data erasure.
+type Service struct {
+	queries         dbstore.Queries
+	identities      *identities.Service
+	settingsService *settings.Service
+	memoryRegistry  *memprovider.Registry
+	logger          *slog.Logger
+	now             func() time.Time
+}
+
+// NewService creates an erasure service.
+func NewService(log *slog.Logger, queries dbstore.Queries, identityService *identities.Service) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries:    queries,
+		identities: identityService,
+		logger:     log.With(slog.String("service", "erasure")),
+		now:        time.Now,
+	}
+}
+
+// SetMemoryRegistry enables removal of memories formed about the identity.
+func (s *Service) SetMemoryRegistry(registry *memprovider.Registry) {
+	s.memoryRegistry = registry
+}
+
+// SetSettingsService sets the settings service used to resolve each bot's
+// memory provider.
+func (s *Service) SetSettingsService(svc *settings.Service) {
+	s.settingsService = svc
+}
+
+// EraseChannelIdentity removes the identity's messages (and with them their
+// media references), inbound session events, dead-lettered inbound messages,
+// queued outbound messages, memories and the identity record itself, then
+// records the erasure in the audit log. Database writes commit together.
+func (s *Service) EraseChannelIdentity(ctx context.Context, actorUserID, channelIdentityID string) (Report, error) {
+	if s.queries == nil || s.identities == nil {
+		return Report{}, errors.New("erasure service not configured")
+	}
+	identity, err := s.identities.GetByID(ctx, channelIdentityID)
+	if err != nil {
+		return Report{}, err
+	}
+	pgIdentityID, err := db.ParseUUID(identity.ID)
+	if err != nil {
+		return Report{}, err
+	}
+	reader, ok := s.queries.(erasureQueries)
+	if !ok {
+		return Report{}, errors.New("erasure queries not supported by store")
+	}
+	txer, ok := s.queries.(transactionalQueries)
+	if !ok {
+		return Report{}, errors.New("erasure requires transactional store")
+	}
+
+	report := Report{
+		ChannelIdentityID: identity.ID,
+		Channel:           identity.Channel,
+		ErasedAt:          s.now().UTC(),
+		BotIDs:            []string{},
+	}
+	refs, err := reader.ListMessageRefsBySenderChannelIdentity(ctx, pgIdentityID)
+	if err != nil {
+		return Report{}, fmt.Errorf("list identity messages: %w", err)
+	}
+	seenBots := map[pgtype.UUID]struct{}{}
+	for _, ref := range refs {
+		if _, ok := seenBots[ref.BotID]; ok {
+			continue
+		}
+		seenBots[ref.BotID] = struct{}{}
+		report.BotIDs = append(report.BotIDs, ref.BotID.String())
+	}
+
+	for _, botID := range report.BotIDs {
+		removed, err := s.eraseMemories(ctx, botID, identity.ID)
+		report.Memories += removed
+		if err != nil {
+			s.logger.Warn("erase identity memories failed", slog.String("bot_id", botID), slog.Any("error", err))
+			report.Errors = append(report.Errors, fmt.Sprintf("memories for bot %s: %v", botID, err))
+		}
+	}
+
+	err = txer.InTx(ctx, func(q dbstore.Queries) error {
+		tx, ok := q.(erasureQueries)
+		if !ok {
+			return errors.New("erasure queries not supported by transaction")
+		}
+		// Re-read inside the transaction so messages that arrived since the
+		// memory pass are removed too.
+		refs, err := tx.ListMessageRefsBySenderChannelIdentity(ctx, pgIdentityID)
+		if err != nil {
+			return fmt.Errorf("list identity messages: %w", err)
+		}
+		ids := make([]pgtype.UUID, 0, len(refs))
+		for _, ref := range refs {
+			ids = append(ids, ref.ID)
+		}
+		if len(ids) > 0 {
+			if report.MediaReferences, err = tx.CountMessageAssetsByMessageIDs(ctx, ids); err != nil {
+				return fmt.Errorf("count media references: %w", err)
+			}
+			if err := q.DeleteMessagesByIDs(ctx, ids); err != nil {
+				return fmt.Errorf("delete messages: %w", err)
+			}
+		}
+		report.Messages = len(ids)
+		if report.SessionEvents, err = tx.DeleteSessionEventsBySenderChannelIdentity(ctx, pgIdentityID); err != nil {
+			return fmt.Errorf("delete session events: %w", err)
+		}
+		if report.InboundFailures, err = tx.DeleteInboundFailuresByChannelIdentity(ctx, pgIdentityID); err != nil {
+			return fmt.Errorf("delete inbound failures: %w", err)
+		}
+		if report.OutboxEntries, err = tx.DeleteChannelOutboxByChannelIdentity(ctx, pgIdentityID); err != nil {
+			return fmt.Errorf("delete outbox entries: %w", err)
+		}
+		deleted, err := tx.DeleteChannelIdentity(ctx, pgIdentityID)
+		if err != nil {
+			return fmt.Errorf("delete channel identity: %w", err)
+		}
+		report.ContactRecord = deleted > 0
+		report.AuditLogID, err = audit.Record(ctx, tx, audit.Entry{
+			ActorUserID: actorUserID,
+			Action:      audit.ActionIdentityDataErased,
+			TargetType:  audit.TargetChannelIdentity,
+			TargetID:    identity.ID,
+			Details:     report.auditDetails(),
+		})
+		return err
+	})
+	if err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+// auditDetails is the report without personal data: the identity's subject
+// id and display name are gone with the record and must not be copied here.
+func (r Report) auditDetails() map[string]any {
+	details := map[string]any{
+		"channel":          r.Channel,
+		"bot_ids":          r.BotIDs,
+		"messages":         r.Messages,
+		"media_references": r.MediaReferences,
+		"session_events":   r.SessionEvents,
+		"inbound_failures": r.InboundFailures,
+		"outbox_entries":   r.OutboxEntries,
+		"memories":         r.Memories,
+		"contact_record":   r.ContactRecord,
+	}
+	if len(r.Errors) > 0 {
+		details["errors"] = r.Errors
+	}
+	return details
+}
+
+// eraseMemories deletes the bot's memories whose profile metadata names the
+// identity.
+func (s *Service) eraseMemories(ctx context.Context, botID, channelIdentityID string) (int, error) {
+	if s.memoryRegistry == nil {
+		return 0, nil
+	}
+	provider, err := memprovider.ResolveBotProvider(ctx, s.memoryRegistry, s.settingsService, botID)
+	if err != nil {
+		return 0, err
+	}
+	all, err := provider.GetAll(ctx, memprovider.GetAllRequest{BotID: botID, NoStats: true})
+	if err != nil {
+		return 0, err
+	}
+	profileRef := "channel_identity:" + channelIdentityID
+	ids := make([]string, 0)
+	for _, item := range all.Results {
+		if metadataString(item.Metadata, "profile_channel_identity_id") == channelIdentityID ||
+			metadataString(item.Metadata, "profile_ref") == profileRef {
+			ids = append(ids, item.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if _, err := provider.DeleteBatch(ctx, ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+func metadataString(metadata map[string]any, key string) string {
+	value, _ := metadata[key].(string)
+	return strings.TrimSpace(value)
+}
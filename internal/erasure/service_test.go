@@ -0,0 +1,169 @@
+package erasure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/audit"
+	"github.com/memohai/memoh/internal/channel/identities"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	testIdentityID = "11111111-1111-1111-1111-111111111111"
+	testBotID      = "22222222-2222-2222-2222-222222222222"
+	testActorID    = "33333333-3333-3333-3333-333333333333"
+)
+
+type fakeErasureQueries struct {
+	dbstore.Queries
+
+	identity       *sqlc.ChannelIdentity
+	messages       []sqlc.ListMessageRefsBySenderChannelIdentityRow
+	deletedIDs     []pgtype.UUID
+	identityGone   bool
+	audit          []sqlc.CreateAuditLogParams
+	inTx           bool
+	failIdentityRm bool
+}
+
+func (f *fakeErasureQueries) GetChannelIdentityByID(_ context.Context, _ pgtype.UUID) (sqlc.ChannelIdentity, error) {
+	if f.identity == nil || f.identityGone {
+		return sqlc.ChannelIdentity{}, pgx.ErrNoRows
+	}
+	return *f.identity, nil
+}
+
+func (f *fakeErasureQueries) ListMessageRefsBySenderChannelIdentity(context.Context, pgtype.UUID) ([]sqlc.ListMessageRefsBySenderChannelIdentityRow, error) {
+	return f.messages, nil
+}
+
+func (*fakeErasureQueries) CountMessageAssetsByMessageIDs(_ context.Context, ids []pgtype.UUID) (int64, error) {
+	return int64(len(ids) * 2), nil
+}
+
+func (f *fakeErasureQueries) DeleteMessagesByIDs(_ context.Context, ids []pgtype.UUID) error {
+	f.deletedIDs = append(f.deletedIDs, ids...)
+	return nil
+}
+
+func (*fakeErasureQueries) DeleteSessionEventsBySenderChannelIdentity(context.Context, pgtype.UUID) (int64, error) {
+	return 3, nil
+}
+
+func (*fakeErasureQueries) DeleteInboundFailuresByChannelIdentity(context.Context, pgtype.UUID) (int64, error) {
+	return 2, nil
+}
+
+func (*fakeErasureQueries) DeleteChannelOutboxByChannelIdentity(context.Context, pgtype.UUID) (int64, error) {
+	return 1, nil
+}
+
+func (f *fakeErasureQueries) DeleteChannelIdentity(context.Context, pgtype.UUID) (int64, error) {
+	if f.failIdentityRm {
+		return 0, errors.New("boom")
+	}
+	f.identityGone = true
+	return 1, nil
+}
+
+func (f *fakeErasureQueries) CreateAuditLog(_ context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error) {
+	if !f.inTx {
+		return sqlc.AuditLog{}, errors.New("audit written outside transaction")
+	}
+	f.audit = append(f.audit, arg)
+	return sqlc.AuditLog{ID: db.ParseUUIDOrEmpty("44444444-4444-4444-4444-444444444444")}, nil
+}
+
+func (f *fakeErasureQueries) InTx(_ context.Context, fn func(dbstore.Queries) error) error {
+	f.inTx = true
+	defer func() { f.inTx = false }()
+	return fn(f)
+}
+
+func newFakeErasureQueries() *fakeErasureQueries {
+	botID := db.ParseUUIDOrEmpty(testBotID)
+	return &fakeErasureQueries{
+		identity: &sqlc.ChannelIdentity{
+			ID:               db.ParseUUIDOrEmpty(testIdentityID),
+			ChannelType:      "telegram",
+			ChannelSubjectID: "secret-subject",
+			DisplayName:      pgtype.Text{String: "Alice", Valid: true},
+		},
+		messages: []sqlc.ListMessageRefsBySenderChannelIdentityRow{
+			{ID: db.ParseUUIDOrEmpty("55555555-5555-5555-5555-555555555555"), BotID: botID},
+			{ID: db.ParseUUIDOrEmpty("66666666-6666-6666-6666-666666666666"), BotID: botID},
+		},
+	}
+}
+
+func TestEraseChannelIdentityReportsAndAudits(t *testing.T) {
+	queries := newFakeErasureQueries()
+	svc := NewService(nil, queries, identities.NewService(nil, queries))
+
+	report, err := svc.EraseChannelIdentity(context.Background(), testActorID, testIdentityID)
+	if err != nil {
+		t.Fatalf("EraseChannelIdentity: %v", err)
+	}
+	if report.Messages != 2 || report.MediaReferences != 4 || report.SessionEvents != 3 || !report.ContactRecord {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if report.InboundFailures != 2 || report.OutboxEntries != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.BotIDs) != 1 || report.BotIDs[0] != testBotID {
+		t.Fatalf("bot ids = %v", report.BotIDs)
+	}
+	if len(queries.deletedIDs) != 2 {
+		t.Fatalf("deleted %d messages, want 2", len(queries.deletedIDs))
+	}
+	if report.AuditLogID == "" || len(queries.audit) != 1 {
+		t.Fatalf("audit entry not recorded: %+v", queries.audit)
+	}
+	entry := queries.audit[0]
+	if entry.Action != audit.ActionIdentityDataErased || entry.TargetID != testIdentityID {
+		t.Fatalf("unexpected audit entry: %+v", entry)
+	}
+	var details map[string]any
+	if err := json.Unmarshal(entry.Details, &details); err != nil {
+		t.Fatalf("audit details: %v", err)
+	}
+	if details["messages"] != float64(2) || details["inbound_failures"] != float64(2) || details["outbox_entries"] != float64(1) {
+		t.Fatalf("audit details = %v", details)
+	}
+	if strings.Contains(string(entry.Details), "secret-subject") || strings.Contains(string(entry.Details), "Alice") {
+		t.Fatalf("audit details leak personal data: %s", entry.Details)
+	}
+}
+
+func TestEraseChannelIdentityNotFound(t *testing.T) {
+	queries := newFakeErasureQueries()
+	queries.identity = nil
+	svc := NewService(nil, queries, identities.NewService(nil, queries))
+
+	_, err := svc.EraseChannelIdentity(context.Background(), testActorID, testIdentityID)
+	if !errors.Is(err, ErrChannelIdentityNotFound) {
+		t.Fatalf("err = %v, want ErrChannelIdentityNotFound", err)
+	}
+}
+
+func TestEraseChannelIdentityFailureSkipsAudit(t *testing.T) {
+	queries := newFakeErasureQueries()
+	queries.failIdentityRm = true
+	svc := NewService(nil, queries, identities.NewService(nil, queries))
+
+	if _, err := svc.EraseChannelIdentity(context.Background(), testActorID, testIdentityID); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(queries.audit) != 0 {
+		t.Fatalf("audit recorded for failed erasure: %+v", queries.audit)
+	}
+}
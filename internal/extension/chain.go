@@ -0,0 +1,149 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Chain runs extensions in order at each hook point; every extension sees the
+// changes of the ones before it. Extensions fail open: an error other than
+// ErrBlocked, or a panic, is logged and that extension's changes are
+// discarded. A nil Chain does nothing.
+type Chain struct {
+	exts   []Extension
+	logger *slog.Logger
+}
+
+// NewChain creates a chain of the given extensions, skipping nil ones.
+func NewChain(log *slog.Logger, exts ...Extension) *Chain {
+	if log == nil {
+		log = slog.Default()
+	}
+	c := &Chain{logger: log.With(slog.String("service", "extensions"))}
+	for _, ext := range exts {
+		if ext != nil {
+			c.exts = append(c.exts, ext)
+		}
+	}
+	return c
+}
+
+// hookSelector is implemented by extensions that only take part in some of
+// the hook points whose interfaces they implement.
+type hookSelector interface {
+	Handles(hook string) bool
+}
+
+// Handles reports whether any extension takes part in the hook point, so
+// callers can skip building events nobody reads.
+func (c *Chain) Handles(hook string) bool {
+	if c == nil {
+		return false
+	}
+	for _, ext := range c.exts {
+		if handles(ext, hook) {
+			return true
+		}
+	}
+	return false
+}
+
+func handles(ext Extension, hook string) bool {
+	if selector, ok := ext.(hookSelector); ok && !selector.Handles(hook) {
+		return false
+	}
+	var ok bool
+	switch hook {
+	case HookInbound:
+		_, ok = ext.(InboundHook)
+	case HookBeforeModelCall:
+		_, ok = ext.(BeforeModelCallHook)
+	case HookAfterModelCall:
+		_, ok = ext.(AfterModelCallHook)
+	case HookOutbound:
+		_, ok = ext.(OutboundHook)
+	}
+	return ok
+}
+
+// OnInbound runs the inbound hooks. A returned error wraps ErrBlocked and
+// means the message must be dropped.
+func (c *Chain) OnInbound(ctx context.Context, msg *InboundMessage) error {
+	return runHook(ctx, c, HookInbound, msg, InboundHook.OnInbound)
+}
+
+// OnBeforeModelCall runs the before-model-call hooks. A returned error wraps
+// ErrBlocked.
+func (c *Chain) OnBeforeModelCall(ctx context.Context, call *ModelCall) error {
+	return runHook(ctx, c, HookBeforeModelCall, call, BeforeModelCallHook.OnBeforeModelCall)
+}
+
+// OnAfterModelCall runs the after-model-call hooks. They can not block, so
+// ErrBlocked is logged like any other error.
+func (c *Chain) OnAfterModelCall(ctx context.Context, result *ModelResult) {
+	if err := runHook(ctx, c, HookAfterModelCall, result, AfterModelCallHook.OnAfterModelCall); err != nil {
+		c.logger.Warn("after model call extension tried to block", slog.Any("error", err))
+	}
+}
+
+// OnOutbound runs the outbound hooks. A returned error wraps ErrBlocked and
+// means the message must not be sent.
+func (c *Chain) OnOutbound(ctx context.Context, msg *OutboundMessage) error {
+	return runHook(ctx, c, HookOutbound, msg, OutboundHook.OnOutbound)
+}
+
+// Close closes the extensions that hold resources, such as plugin
+// connections.
+func (c *Chain) Close() error {
+	if c == nil {
+		return nil
+	}
+	var errs []error
+	for _, ext := range c.exts {
+		if closer, ok := ext.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("close extension %s: %w", ext.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func runHook[H any, T any](ctx context.Context, c *Chain, hook string, event *T, invoke func(H, context.Context, *T) error) error {
+	if c == nil || event == nil {
+		return nil
+	}
+	for _, ext := range c.exts {
+		h, ok := ext.(H)
+		if !ok || !handles(ext, hook) {
+			continue
+		}
+		before := *event
+		err := invokeHook(ctx, h, event, invoke)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, ErrBlocked) {
+			return fmt.Errorf("extension %s: %w", ext.Name(), err)
+		}
+		*event = before
+		c.logger.Warn("extension hook failed",
+			slog.String("extension", ext.Name()),
+			slog.String("hook", hook),
+			slog.Any("error", err),
+		)
+	}
+	return nil
+}
+
+func invokeHook[H any, T any](ctx context.Context, h H, event *T, invoke func(H, context.Context, *T) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return invoke(h, ctx, event)
+}
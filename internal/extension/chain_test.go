@@ -0,0 +1,124 @@
+package extension
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeExtension struct {
+	name     string
+	inbound  func(*InboundMessage) error
+	outbound func(*OutboundMessage) error
+}
+
+func (f *fakeExtension) Name() string { return f.name }
+
+func (f *fakeExtension) OnInbound(_ context.Context, msg *InboundMessage) error {
+	return f.inbound(msg)
+}
+
+func (f *fakeExtension) OnOutbound(_ context.Context, msg *OutboundMessage) error {
+	if f.outbound == nil {
+		return nil
+	}
+	return f.outbound(msg)
+}
+
+type inboundOnly struct{}
+
+func (inboundOnly) Name() string { return "inbound-only" }
+
+func (inboundOnly) OnInbound(context.Context, *InboundMessage) error { return nil }
+
+func TestChainRunsHooksInOrder(t *testing.T) {
+	t.Parallel()
+
+	chain := NewChain(nil,
+		&fakeExtension{name: "upper", inbound: func(msg *InboundMessage) error {
+			msg.Text = strings.ToUpper(msg.Text)
+			return nil
+		}},
+		nil,
+		&fakeExtension{name: "suffix", inbound: func(msg *InboundMessage) error {
+			msg.Text += "!"
+			return nil
+		}},
+	)
+	msg := &InboundMessage{BotID: "bot", Text: "hi"}
+	if err := chain.OnInbound(context.Background(), msg); err != nil {
+		t.Fatalf("OnInbound: %v", err)
+	}
+	if msg.Text != "HI!" {
+		t.Fatalf("text = %q, want changes applied in order", msg.Text)
+	}
+}
+
+func TestChainFailsOpen(t *testing.T) {
+	t.Parallel()
+
+	chain := NewChain(nil,
+		&fakeExtension{name: "broken", inbound: func(msg *InboundMessage) error {
+			msg.Text = "half-done"
+			return errors.New("backend down")
+		}},
+		&fakeExtension{name: "panics", inbound: func(msg *InboundMessage) error {
+			msg.Text = "also half-done"
+			panic("boom")
+		}},
+		&fakeExtension{name: "suffix", inbound: func(msg *InboundMessage) error {
+			msg.Text += "!"
+			return nil
+		}},
+	)
+	msg := &InboundMessage{Text: "hi"}
+	if err := chain.OnInbound(context.Background(), msg); err != nil {
+		t.Fatalf("OnInbound: %v", err)
+	}
+	if msg.Text != "hi!" {
+		t.Fatalf("text = %q, want failed extensions' changes discarded", msg.Text)
+	}
+}
+
+func TestChainStopsOnBlock(t *testing.T) {
+	t.Parallel()
+
+	reached := false
+	chain := NewChain(nil,
+		&fakeExtension{name: "policy", inbound: func(*InboundMessage) error {
+			return Block("off-topic")
+		}},
+		&fakeExtension{name: "later", inbound: func(*InboundMessage) error {
+			reached = true
+			return nil
+		}},
+	)
+	err := chain.OnInbound(context.Background(), &InboundMessage{Text: "hi"})
+	if !errors.Is(err, ErrBlocked) {
+		t.Fatalf("OnInbound error = %v, want ErrBlocked", err)
+	}
+	if !strings.Contains(err.Error(), "policy") || !strings.Contains(err.Error(), "off-topic") {
+		t.Fatalf("error %q does not name the extension and reason", err)
+	}
+	if reached {
+		t.Fatal("extension after the blocking one ran")
+	}
+}
+
+func TestChainHandles(t *testing.T) {
+	t.Parallel()
+
+	chain := NewChain(nil, inboundOnly{})
+	if !chain.Handles(HookInbound) || chain.Handles(HookOutbound) || chain.Handles(HookBeforeModelCall) {
+		t.Fatal("Handles does not follow the implemented hook interfaces")
+	}
+	var nilChain *Chain
+	if nilChain.Handles(HookInbound) {
+		t.Fatal("nil chain handles hooks")
+	}
+	if err := nilChain.OnOutbound(context.Background(), &OutboundMessage{}); err != nil {
+		t.Fatalf("nil chain OnOutbound: %v", err)
+	}
+	nilChain.OnAfterModelCall(context.Background(), &ModelResult{})
+}
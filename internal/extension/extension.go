@@ -0,0 +1,149 @@
+// Package extension lets a deployment run its own logic at fixed points of
+// message handling without forking core services: when a channel message
+// arrives, before and after every model call, and before a message is sent
+// back to a channel. Extensions are compiled into the binary with Register or
+// served by an external process over the ExtensionPlugin gRPC service.
+//
+// Unlike the per-bot hooks in the hooks package, extensions apply to every
+// bot of the deployment and are configured by the operator.
+package extension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Hook names identify the interception points. They are also the hook values
+// sent to gRPC plugins.
+const (
+	HookInbound         = "inbound"
+	HookBeforeModelCall = "before_model_call"
+	HookAfterModelCall  = "after_model_call"
+	HookOutbound        = "outbound"
+)
+
+// ErrBlocked stops the message or model call an extension intercepted. Hooks
+// return it through Block; any other error is logged and the extension is
+// skipped.
+var ErrBlocked = errors.New("blocked by extension")
+
+// Block returns an error that makes the chain drop the intercepted message or
+// refuse the model call.
+func Block(reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrBlocked
+	}
+	return fmt.Errorf("%w: %s", ErrBlocked, reason)
+}
+
+// Extension is implemented by every extension. It takes part in the hook
+// points whose interfaces it also implements.
+type Extension interface {
+	Name() string
+}
+
+// InboundHook sees channel messages after the sender is identified and
+// before commands or the agent handle them. It may rewrite Text.
+type InboundHook interface {
+	OnInbound(ctx context.Context, msg *InboundMessage) error
+}
+
+// BeforeModelCallHook runs before the first model call of a turn and before
+// every following step. It may set AppendContext; blocking is only honoured
+// before the first call.
+type BeforeModelCallHook interface {
+	OnBeforeModelCall(ctx context.Context, call *ModelCall) error
+}
+
+// AfterModelCallHook observes each finished model step. Its error is only
+// logged.
+type AfterModelCallHook interface {
+	OnAfterModelCall(ctx context.Context, result *ModelResult) error
+}
+
+// OutboundHook sees messages before they are delivered to a channel. It may
+// rewrite Text. Streamed previews are not intercepted.
+type OutboundHook interface {
+	OnOutbound(ctx context.Context, msg *OutboundMessage) error
+}
+
+type InboundMessage struct {
+	BotID            string `json:"bot_id"`
+	Channel          string `json:"channel"`
+	ConversationID   string `json:"conversation_id,omitempty"`
+	ConversationType string `json:"conversation_type,omitempty"`
+	SenderID         string `json:"sender_id,omitempty"`
+	MessageID        string `json:"message_id,omitempty"`
+	Text             string `json:"text"`
+}
+
+type ModelCall struct {
+	BotID        string `json:"bot_id"`
+	SessionID    string `json:"session_id,omitempty"`
+	ChatID       string `json:"chat_id,omitempty"`
+	SessionType  string `json:"session_type,omitempty"`
+	Model        string `json:"model,omitempty"`
+	Step         int    `json:"step"`
+	MessageCount int    `json:"message_count"`
+	// AppendContext is added to the conversation as a user message before
+	// the call.
+	AppendContext string `json:"append_context,omitempty"`
+}
+
+type ModelResult struct {
+	BotID        string `json:"bot_id"`
+	SessionID    string `json:"session_id,omitempty"`
+	ChatID       string `json:"chat_id,omitempty"`
+	SessionType  string `json:"session_type,omitempty"`
+	Model        string `json:"model,omitempty"`
+	Step         int    `json:"step"`
+	FinishReason string `json:"finish_reason,omitempty"`
+	Text         string `json:"text,omitempty"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+	ToolCalls    int    `json:"tool_calls"`
+}
+
+type OutboundMessage struct {
+	BotID   string `json:"bot_id"`
+	Channel string `json:"channel"`
+	Target  string `json:"target"`
+	Text    string `json:"text"`
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []Extension
+)
+
+// Register adds a compiled-in extension, typically from an init function of
+// a package imported by the deployment's build. It panics on a nil
+// extension or a duplicate name.
+func Register(ext Extension) {
+	if ext == nil {
+		panic("extension: Register extension is nil")
+	}
+	name := strings.TrimSpace(ext.Name())
+	if name == "" {
+		panic("extension: Register extension has no name")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, existing := range registry {
+		if existing.Name() == name {
+			panic("extension: Register called twice for " + name)
+		}
+	}
+	registry = append(registry, ext)
+}
+
+// Registered returns the compiled-in extensions in registration order.
+func Registered() []Extension {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]Extension(nil), registry...)
+}
@@ -0,0 +1,145 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/memohai/memoh/internal/extension/extensionpb"
+)
+
+// DefaultPluginTimeout bounds a plugin call when PluginOptions sets none.
+const DefaultPluginTimeout = 2 * time.Second
+
+const maxPluginMessageBytes = 4 << 20
+
+// PluginOptions describes an external plugin serving ExtensionPlugin.
+type PluginOptions struct {
+	Name   string
+	Target string
+	// Timeout bounds each call. A plugin that does not answer in time is
+	// skipped for that event.
+	Timeout time.Duration
+	// Hooks limits the hook points sent to the plugin; empty sends all.
+	Hooks []string
+}
+
+// Plugin is an extension served by an external process over gRPC. Events are
+// sent as JSON; a reply payload replaces the event.
+type Plugin struct {
+	name    string
+	timeout time.Duration
+	hooks   map[string]struct{}
+	conn    *grpc.ClientConn
+	client  extensionpb.ExtensionPluginClient
+}
+
+// DialPlugin connects to a plugin. The connection is established lazily, so
+// a plugin that is down at startup is skipped until it comes up.
+func DialPlugin(opts PluginOptions) (*Plugin, error) {
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		return nil, errors.New("extension plugin name is required")
+	}
+	target := strings.TrimSpace(opts.Target)
+	if target == "" {
+		return nil, fmt.Errorf("extension plugin %s: target is required", name)
+	}
+	var hooks map[string]struct{}
+	for _, hook := range opts.Hooks {
+		hook = strings.TrimSpace(hook)
+		switch hook {
+		case HookInbound, HookBeforeModelCall, HookAfterModelCall, HookOutbound:
+		default:
+			return nil, fmt.Errorf("extension plugin %s: unknown hook %q", name, hook)
+		}
+		if hooks == nil {
+			hooks = make(map[string]struct{}, len(opts.Hooks))
+		}
+		hooks[hook] = struct{}{}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPluginTimeout
+	}
+	conn, err := grpc.NewClient(
+		target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(maxPluginMessageBytes),
+			grpc.MaxCallSendMsgSize(maxPluginMessageBytes),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("extension plugin %s: %w", name, err)
+	}
+	return &Plugin{
+		name:    name,
+		timeout: timeout,
+		hooks:   hooks,
+		conn:    conn,
+		client:  extensionpb.NewExtensionPluginClient(conn),
+	}, nil
+}
+
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Handles reports whether the plugin is configured for the hook point.
+func (p *Plugin) Handles(hook string) bool {
+	if len(p.hooks) == 0 {
+		return true
+	}
+	_, ok := p.hooks[hook]
+	return ok
+}
+
+func (p *Plugin) Close() error {
+	return p.conn.Close()
+}
+
+func (p *Plugin) OnInbound(ctx context.Context, msg *InboundMessage) error {
+	return p.call(ctx, HookInbound, msg)
+}
+
+func (p *Plugin) OnBeforeModelCall(ctx context.Context, call *ModelCall) error {
+	return p.call(ctx, HookBeforeModelCall, call)
+}
+
+func (p *Plugin) OnAfterModelCall(ctx context.Context, result *ModelResult) error {
+	return p.call(ctx, HookAfterModelCall, result)
+}
+
+func (p *Plugin) OnOutbound(ctx context.Context, msg *OutboundMessage) error {
+	return p.call(ctx, HookOutbound, msg)
+}
+
+func (p *Plugin) call(ctx context.Context, hook string, event any) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	resp, err := p.client.Call(ctx, &extensionpb.CallRequest{Hook: hook, Payload: payload})
+	if err != nil {
+		return err
+	}
+	if resp.GetBlocked() {
+		return Block(resp.GetReason())
+	}
+	if len(resp.GetPayload()) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.GetPayload(), event); err != nil {
+		return fmt.Errorf("decode %s reply: %w", hook, err)
+	}
+	return nil
+}
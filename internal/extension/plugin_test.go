@@ -0,0 +1,139 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/memohai/memoh/internal/extension/extensionpb"
+)
+
+type fakePluginServer struct {
+	extensionpb.UnimplementedExtensionPluginServer
+
+	hooks []string
+}
+
+func (s *fakePluginServer) Call(_ context.Context, req *extensionpb.CallRequest) (*extensionpb.CallResponse, error) {
+	s.hooks = append(s.hooks, req.GetHook())
+	switch req.GetHook() {
+	case HookInbound:
+		var msg InboundMessage
+		if err := json.Unmarshal(req.GetPayload(), &msg); err != nil {
+			return nil, err
+		}
+		msg.Text = "[" + msg.BotID + "] " + msg.Text
+		payload, _ := json.Marshal(msg)
+		return &extensionpb.CallResponse{Payload: payload}, nil
+	case HookOutbound:
+		return &extensionpb.CallResponse{Blocked: true, Reason: "contains secrets"}, nil
+	}
+	return &extensionpb.CallResponse{}, nil
+}
+
+func startPluginServer(t *testing.T, server extensionpb.ExtensionPluginServer) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	extensionpb.RegisterExtensionPluginServer(grpcServer, server)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		grpcServer.Stop()
+		<-done
+	})
+	return listener.Addr().String()
+}
+
+func TestPluginRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := &fakePluginServer{}
+	plugin, err := DialPlugin(PluginOptions{
+		Name:   "audit",
+		Target: startPluginServer(t, server),
+		Hooks:  []string{HookInbound, HookOutbound},
+	})
+	if err != nil {
+		t.Fatalf("DialPlugin: %v", err)
+	}
+	chain := NewChain(nil, plugin)
+	t.Cleanup(func() { _ = chain.Close() })
+	ctx := context.Background()
+
+	msg := &InboundMessage{BotID: "bot-1", Channel: "telegram", Text: "hello"}
+	if err := chain.OnInbound(ctx, msg); err != nil {
+		t.Fatalf("OnInbound: %v", err)
+	}
+	if msg.Text != "[bot-1] hello" || msg.Channel != "telegram" {
+		t.Fatalf("inbound = %+v, want the plugin's rewrite", msg)
+	}
+
+	err = chain.OnOutbound(ctx, &OutboundMessage{BotID: "bot-1", Text: "the key is 42"})
+	if !errors.Is(err, ErrBlocked) {
+		t.Fatalf("OnOutbound error = %v, want ErrBlocked", err)
+	}
+
+	// Hooks the plugin is not configured for are never sent.
+	call := &ModelCall{BotID: "bot-1"}
+	if err := chain.OnBeforeModelCall(ctx, call); err != nil {
+		t.Fatalf("OnBeforeModelCall: %v", err)
+	}
+	if chain.Handles(HookBeforeModelCall) {
+		t.Fatal("chain handles a hook the plugin is not configured for")
+	}
+	if len(server.hooks) != 2 {
+		t.Fatalf("plugin saw hooks %v, want inbound and outbound only", server.hooks)
+	}
+}
+
+func TestPluginUnavailableFailsOpen(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	target := listener.Addr().String()
+	_ = listener.Close()
+
+	plugin, err := DialPlugin(PluginOptions{Name: "gone", Target: target})
+	if err != nil {
+		t.Fatalf("DialPlugin: %v", err)
+	}
+	chain := NewChain(nil, plugin)
+	t.Cleanup(func() { _ = chain.Close() })
+
+	msg := &InboundMessage{Text: "hello"}
+	if err := chain.OnInbound(context.Background(), msg); err != nil {
+		t.Fatalf("OnInbound with plugin down: %v", err)
+	}
+	if msg.Text != "hello" {
+		t.Fatalf("text = %q, want unchanged", msg.Text)
+	}
+}
+
+func TestDialPluginValidatesOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, opts := range []PluginOptions{
+		{Target: "127.0.0.1:1"},
+		{Name: "no-target"},
+		{Name: "bad-hook", Target: "127.0.0.1:1", Hooks: []string{"on_everything"}},
+	} {
+		if _, err := DialPlugin(opts); err == nil {
+			t.Fatalf("DialPlugin(%+v) succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Package featureflags gates risky features so they can be rolled out
+// incrementally. Flags are declared in code with a default; rows in
+// feature_flags override the default for the whole deployment or for one
+// bot, and a bot override wins over the deployment one.
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+// Key names a declared flag.
+type Key string
+
+const (
+	// HybridSearch applies the memory provider's configured hybrid search
+	// fusion. Off, recall keeps the better of the semantic and lexical score
+	// per memory.
+	HybridSearch Key = "hybrid_search"
+	// StreamingEdits streams replies by editing the sent message in place on
+	// channels that support it. Off, those channels send the final reply
+	// only.
+	StreamingEdits Key = "streaming_edits"
+)
+
+// Flag describes a declared flag.
+type Flag struct {
+	Key         Key    `json:"key"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+var declared = []Flag{
+	{Key: HybridSearch, Description: "Apply the memory provider's hybrid search fusion to recall and memory search.", Default: true},
+	{Key: StreamingEdits, Description: "Stream replies by editing the sent message in place on channels that support it.", Default: true},
+}
+
+// Flags returns the declared flags.
+func Flags() []Flag {
+	return append([]Flag(nil), declared...)
+}
+
+func lookup(key Key) (Flag, bool) {
+	for _, f := range declared {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+var (
+	ErrUnknownFlag      = errors.New("unknown feature flag")
+	ErrInvalidBotID     = errors.New("invalid bot id")
+	ErrOverrideNotFound = errors.New("feature flag override not found")
+)
+
+// cacheTTL bounds how long a process serves flags without reloading them.
+// Writes invalidate the cache of the process that made them; other processes
+// (the split-mode channel service) pick changes up within this window.
+const cacheTTL = 30 * time.Second
+
+// Checker reports whether a flag is on for a bot, or deployment-wide when
+// botID is empty.
+type Checker interface {
+	Enabled(ctx context.Context, key Key, botID string) bool
+}
+
+// State is a declared flag with its overrides.
+type State struct {
+	Flag
+	// Deployment is the deployment-wide override, nil when unset.
+	Deployment *bool `json:"deployment,omitempty"`
+	// Bots maps bot ids to their overrides.
+	Bots map[string]bool `json:"bots,omitempty"`
+	// Enabled is the deployment-wide effective value.
+	Enabled bool `json:"enabled"`
+}
+
+type flagQueries interface {
+	ListFeatureFlags(ctx context.Context) ([]sqlc.FeatureFlag, error)
+	UpsertFeatureFlag(ctx context.Context, arg sqlc.UpsertFeatureFlagParams) (sqlc.FeatureFlag, error)
+	DeleteFeatureFlag(ctx context.Context, arg sqlc.DeleteFeatureFlagParams) (int64, error)
+}
+
+// overrides is a loaded copy of the feature_flags table.
+type overrides struct {
+	deployment map[Key]bool
+	bots       map[Key]map[string]bool
+}
+
+// Service reads flags through a short-lived cache and writes overrides.
+type Service struct {
+	queries dbstore.Queries
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu       sync.Mutex
+	cached   *overrides
+	loadedAt time.Time
+}
+
+// NewService creates a feature flag service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "feature_flags")),
+		now:     time.Now,
+	}
+}
+
+func (s *Service) store() (flagQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("feature flag service not configured")
+	}
+	store, ok := s.queries.(flagQueries)
+	if !ok {
+		return nil, errors.New("feature flag queries not supported by store")
+	}
+	return store, nil
+}
+
+// Enabled reports whether key is on for botID. Unknown keys are off. When
+// overrides cannot be loaded the defaults apply.
+func (s *Service) Enabled(ctx context.Context, key Key, botID string) bool {
+	flag, ok := lookup(key)
+	if !ok {
+		return false
+	}
+	if s == nil {
+		return flag.Default
+	}
+	return s.load(ctx).enabled(flag, strings.TrimSpace(botID))
+}
+
+// Invalidate drops the cached overrides so the next read reloads them.
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+}
+
+// List returns every declared flag with its current overrides.
+func (s *Service) List(ctx context.Context) ([]State, error) {
+	loaded, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.cached, s.loadedAt = loaded, s.now()
+	s.mu.Unlock()
+	states := make([]State, 0, len(declared))
+	for _, flag := range declared {
+		states = append(states, loaded.state(flag))
+	}
+	return states, nil
+}
+
+// Set overrides key for botID, or deployment-wide when botID is empty.
+func (s *Service) Set(ctx context.Context, key Key, botID string, enabled bool) (State, error) {
+	flag, pgBotID, err := s.resolve(key, botID)
+	if err != nil {
+		return State{}, err
+	}
+	store, err := s.store()
+	if err != nil {
+		return State{}, err
+	}
+	if _, err := store.UpsertFeatureFlag(ctx, sqlc.UpsertFeatureFlagParams{
+		FlagKey: string(key),
+		BotID:   pgBotID,
+		Enabled: enabled,
+	}); err != nil {
+		return State{}, err
+	}
+	s.Invalidate()
+	return s.stateOf(ctx, flag)
+}
+
+// Clear removes the override of key for botID, or the deployment-wide one
+// when botID is empty.
+func (s *Service) Clear(ctx context.Context, key Key, botID string) (State, error) {
+	flag, pgBotID, err := s.resolve(key, botID)
+	if err != nil {
+		return State{}, err
+	}
+	store, err := s.store()
+	if err != nil {
+		return State{}, err
+	}
+	n, err := store.DeleteFeatureFlag(ctx, sqlc.DeleteFeatureFlagParams{FlagKey: string(key), BotID: pgBotID})
+	if err != nil {
+		return State{}, err
+	}
+	if n == 0 {
+		return State{}, ErrOverrideNotFound
+	}
+	s.Invalidate()
+	return s.stateOf(ctx, flag)
+}
+
+func (*Service) resolve(key Key, botID string) (Flag, pgtype.UUID, error) {
+	flag, ok := lookup(key)
+	if !ok {
+		return Flag{}, pgtype.UUID{}, ErrUnknownFlag
+	}
+	botID = strings.TrimSpace(botID)
+	if botID == "" {
+		return flag, pgtype.UUID{}, nil
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Flag{}, pgtype.UUID{}, ErrInvalidBotID
+	}
+	return flag, pgBotID, nil
+}
+
+func (s *Service) stateOf(ctx context.Context, flag Flag) (State, error) {
+	loaded, err := s.fetch(ctx)
+	if err != nil {
+		return State{}, err
+	}
+	return loaded.state(flag), nil
+}
+
+// load returns the cached overrides, reloading them after cacheTTL. A failed
+// reload keeps serving what was loaded before (or the defaults) until the
+// next window, so a database outage does not cost a query per message.
+func (s *Service) load(ctx context.Context) *overrides {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if s.cached != nil && now.Sub(s.loadedAt) < cacheTTL {
+		return s.cached
+	}
+	loaded, err := s.fetch(ctx)
+	if err != nil {
+		s.logger.Warn("load feature flags failed", slog.Any("error", err))
+		if s.cached == nil {
+			s.cached = &overrides{}
+		}
+		s.loadedAt = now
+		return s.cached
+	}
+	s.cached, s.loadedAt = loaded, now
+	return loaded
+}
+
+func (s *Service) fetch(ctx context.Context) (*overrides, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListFeatureFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loaded := &overrides{deployment: map[Key]bool{}, bots: map[Key]map[string]bool{}}
+	for _, row := range rows {
+		key := Key(row.FlagKey)
+		if !row.BotID.Valid {
+			loaded.deployment[key] = row.Enabled
+			continue
+		}
+		if loaded.bots[key] == nil {
+			loaded.bots[key] = map[string]bool{}
+		}
+		loaded.bots[key][row.BotID.String()] = row.Enabled
+	}
+	return loaded, nil
+}
+
+func (o *overrides) enabled(flag Flag, botID string) bool {
+	if botID != "" {
+		if v, ok := o.bots[flag.Key][botID]; ok {
+			return v
+		}
+	}
+	if v, ok := o.deployment[flag.Key]; ok {
+		return v
+	}
+	return flag.Default
+}
+
+func (o *overrides) state(flag Flag) State {
+	state := State{Flag: flag, Enabled: o.enabled(flag, "")}
+	if v, ok := o.deployment[flag.Key]; ok {
+		state.Deployment = &v
+	}
+	if bots := o.bots[flag.Key]; len(bots) > 0 {
+		state.Bots = make(map[string]bool, len(bots))
+		for id, v := range bots {
+			state.Bots[id] = v
+		}
+	}
+	return state
+}
@@ -0,0 +1,188 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const testBotID = "6f3b1c9e-0c1d-4c3e-9a53-2d9f1b7a8e01"
+
+type fakeFlagQueries struct {
+	dbstore.Queries
+
+	rows  []sqlc.FeatureFlag
+	lists int
+	err   error
+}
+
+func (f *fakeFlagQueries) ListFeatureFlags(context.Context) ([]sqlc.FeatureFlag, error) {
+	f.lists++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]sqlc.FeatureFlag(nil), f.rows...), nil
+}
+
+func (f *fakeFlagQueries) UpsertFeatureFlag(_ context.Context, arg sqlc.UpsertFeatureFlagParams) (sqlc.FeatureFlag, error) {
+	for i, row := range f.rows {
+		if row.FlagKey == arg.FlagKey && row.BotID == arg.BotID {
+			f.rows[i].Enabled = arg.Enabled
+			return f.rows[i], nil
+		}
+	}
+	row := sqlc.FeatureFlag{FlagKey: arg.FlagKey, BotID: arg.BotID, Enabled: arg.Enabled}
+	f.rows = append(f.rows, row)
+	return row, nil
+}
+
+func (f *fakeFlagQueries) DeleteFeatureFlag(_ context.Context, arg sqlc.DeleteFeatureFlagParams) (int64, error) {
+	for i, row := range f.rows {
+		if row.FlagKey == arg.FlagKey && row.BotID == arg.BotID {
+			f.rows = append(f.rows[:i], f.rows[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func newTestService(queries *fakeFlagQueries) (*Service, *time.Time) {
+	now := time.Unix(1710000000, 0)
+	svc := NewService(nil, queries)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func TestEnabledPrecedence(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queries := &fakeFlagQueries{}
+	svc, _ := newTestService(queries)
+
+	if !svc.Enabled(ctx, HybridSearch, testBotID) {
+		t.Fatal("default-on flag reported off")
+	}
+	if svc.Enabled(ctx, Key("no_such_flag"), testBotID) {
+		t.Fatal("unknown flag reported on")
+	}
+
+	if _, err := svc.Set(ctx, HybridSearch, "", false); err != nil {
+		t.Fatalf("Set deployment: %v", err)
+	}
+	if svc.Enabled(ctx, HybridSearch, testBotID) || svc.Enabled(ctx, HybridSearch, "") {
+		t.Fatal("deployment override ignored")
+	}
+
+	state, err := svc.Set(ctx, HybridSearch, testBotID, true)
+	if err != nil {
+		t.Fatalf("Set bot: %v", err)
+	}
+	if !svc.Enabled(ctx, HybridSearch, testBotID) {
+		t.Fatal("bot override ignored")
+	}
+	if svc.Enabled(ctx, HybridSearch, "7a0e2f4c-1111-4222-8333-944455556666") {
+		t.Fatal("bot override leaked to another bot")
+	}
+	if state.Enabled || state.Deployment == nil || *state.Deployment || !state.Bots[testBotID] {
+		t.Fatalf("state = %+v", state)
+	}
+
+	if _, err := svc.Clear(ctx, HybridSearch, ""); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if _, err := svc.Clear(ctx, HybridSearch, ""); !errors.Is(err, ErrOverrideNotFound) {
+		t.Fatalf("Clear twice error = %v", err)
+	}
+	if !svc.Enabled(ctx, HybridSearch, "") {
+		t.Fatal("cleared deployment override still applies")
+	}
+}
+
+func TestEnabledCachesOverrides(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	botID, err := db.ParseUUID(testBotID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := &fakeFlagQueries{rows: []sqlc.FeatureFlag{{FlagKey: string(StreamingEdits), BotID: botID, Enabled: false}}}
+	svc, now := newTestService(queries)
+
+	for range 3 {
+		if svc.Enabled(ctx, StreamingEdits, testBotID) {
+			t.Fatal("bot override ignored")
+		}
+	}
+	if queries.lists != 1 {
+		t.Fatalf("lists = %d, want cached after the first read", queries.lists)
+	}
+
+	// A write from another process shows up once the cache expires.
+	queries.rows[0].Enabled = true
+	if svc.Enabled(ctx, StreamingEdits, testBotID) {
+		t.Fatal("cache bypassed before expiry")
+	}
+	*now = now.Add(cacheTTL)
+	if !svc.Enabled(ctx, StreamingEdits, testBotID) {
+		t.Fatal("cache not reloaded after expiry")
+	}
+
+	// A failed reload keeps the last overrides for another window.
+	queries.err = errors.New("db down")
+	*now = now.Add(cacheTTL)
+	if !svc.Enabled(ctx, StreamingEdits, testBotID) {
+		t.Fatal("stale overrides dropped on reload failure")
+	}
+	lists := queries.lists
+	svc.Enabled(ctx, StreamingEdits, testBotID)
+	if queries.lists != lists {
+		t.Fatal("reload retried before the next window")
+	}
+}
+
+func TestEnabledDefaultsWithoutStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queries := &fakeFlagQueries{err: errors.New("db down")}
+	svc, _ := newTestService(queries)
+	if !svc.Enabled(ctx, StreamingEdits, testBotID) {
+		t.Fatal("defaults not applied when overrides cannot load")
+	}
+	var nilService *Service
+	if !nilService.Enabled(ctx, StreamingEdits, testBotID) {
+		t.Fatal("nil service did not apply defaults")
+	}
+}
+
+func TestSetRejectsUnknownFlagAndBadBot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	svc, _ := newTestService(&fakeFlagQueries{})
+	if _, err := svc.Set(ctx, Key("nope"), "", true); !errors.Is(err, ErrUnknownFlag) {
+		t.Fatalf("Set unknown error = %v", err)
+	}
+	if _, err := svc.Set(ctx, HybridSearch, "not-a-uuid", true); !errors.Is(err, ErrInvalidBotID) {
+		t.Fatalf("Set bad bot error = %v", err)
+	}
+	states, err := svc.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(states) != len(Flags()) {
+		t.Fatalf("List = %d flags, want %d", len(states), len(Flags()))
+	}
+	for _, state := range states {
+		if state.Enabled != state.Default || state.Deployment != nil || state.Bots != nil {
+			t.Fatalf("state without overrides = %+v", state)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	sdk "github.com/memohai/twilight-ai/sdk"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	"github.com/memohai/memoh/internal/models"
+	"github.com/memohai/memoh/internal/providers"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+const (
+	// maxDigestItems bounds one digest; older undigested items go first and
+	// the rest wait for the next digest.
+	maxDigestItems         = 30
+	digestItemSummaryRunes = 280
+	digestPromptItemRunes  = 1500
+	digestPromptMaxRunes   = 24000
+	digestGenerateTimeout  = 2 * time.Minute
+)
+
+var errNoSummaryModel = errors.New("no chat model configured for digest summaries")
+
+// deliverDigest sends the undigested items of a feed to its digest channel
+// and marks them digested. Items stay undigested when delivery fails, so the
+// next digest includes them.
+func (s *Service) deliverDigest(ctx context.Context, store feedQueries, row sqlc.BotFeed) {
+	feedID := row.ID.String()
+	if s.channels == nil {
+		s.logger.Warn("feed digest skipped: channel delivery not configured", slog.String("feed_id", feedID))
+		return
+	}
+	items, err := store.ListUndigestedBotFeedItems(ctx, sqlc.ListUndigestedBotFeedItemsParams{
+		FeedID:   row.ID,
+		RowLimit: maxDigestItems,
+	})
+	if err != nil {
+		s.logger.Warn("list undigested feed items failed", slog.String("feed_id", feedID), slog.Any("error", err))
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+	text := s.composeDigest(ctx, row, items)
+	if err := s.channels.Send(ctx, row.BotID.String(), channel.ChannelType(row.DigestChannel), channel.SendRequest{
+		Target: row.DigestTarget,
+		Message: channel.Message{
+			Format: channel.MessageFormatMarkdown,
+			Text:   text,
+		},
+	}); err != nil {
+		s.logger.Warn("send feed digest failed", slog.String("feed_id", feedID), slog.Any("error", err))
+		return
+	}
+	ids := make([]pgtype.UUID, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	if err := store.MarkBotFeedItemsDigested(context.WithoutCancel(ctx), sqlc.MarkBotFeedItemsDigestedParams{
+		FeedID: row.ID,
+		Ids:    ids,
+	}); err != nil {
+		s.logger.Warn("mark feed items digested failed", slog.String("feed_id", feedID), slog.Any("error", err))
+	}
+}
+
+// composeDigest summarizes the items with the bot's chat model and falls
+// back to listing them when no model is available or the call fails.
+func (s *Service) composeDigest(ctx context.Context, row sqlc.BotFeed, items []sqlc.BotFeedItem) string {
+	header := fmt.Sprintf("**%s**: %d new item", feedDisplayName(row), len(items))
+	if len(items) != 1 {
+		header += "s"
+	}
+	if s.summarize != nil {
+		summary, err := s.summarize(ctx, row.BotID.String(), digestPrompt(row, items))
+		switch {
+		case err == nil && strings.TrimSpace(summary) != "":
+			return header + "\n\n" + strings.TrimSpace(summary)
+		case err != nil && !errors.Is(err, errNoSummaryModel):
+			s.logger.Warn("summarize feed digest failed", slog.String("feed_id", row.ID.String()), slog.Any("error", err))
+		}
+	}
+	return header + "\n\n" + digestList(items)
+}
+
+func digestList(items []sqlc.BotFeedItem) string {
+	var b strings.Builder
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if item.Link != "" {
+			fmt.Fprintf(&b, "- [%s](%s)", item.Title, item.Link)
+		} else {
+			fmt.Fprintf(&b, "- %s", item.Title)
+		}
+		if summary := strings.Join(strings.Fields(item.Summary), " "); summary != "" {
+			b.WriteString("\n  ")
+			b.WriteString(textutil.TruncateRunesWithSuffix(summary, digestItemSummaryRunes, "…"))
+		}
+	}
+	return b.String()
+}
+
+func digestPrompt(row sqlc.BotFeed, items []sqlc.BotFeedItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Write a short digest of the following new items from the feed %q for a chat message. "+
+		"Group related items, keep each point to one or two sentences, and link every item you mention using Markdown links. "+
+		"Return ONLY the digest.\n", feedDisplayName(row))
+	for _, item := range items {
+		var entry strings.Builder
+		fmt.Fprintf(&entry, "\nTitle: %s\n", item.Title)
+		if item.Link != "" {
+			fmt.Fprintf(&entry, "Link: %s\n", item.Link)
+		}
+		if item.Summary != "" {
+			fmt.Fprintf(&entry, "Content: %s\n", textutil.TruncateRunesWithSuffix(item.Summary, digestPromptItemRunes, "…"))
+		}
+		if len([]rune(b.String()))+len([]rune(entry.String())) > digestPromptMaxRunes {
+			break
+		}
+		b.WriteString(entry.String())
+	}
+	return b.String()
+}
+
+// summarizeWithChatModel runs the prompt on the bot's chat model.
+func (s *Service) summarizeWithChatModel(ctx context.Context, botID, prompt string) (string, error) {
+	if s.settingsService == nil || s.modelsService == nil || s.providersService == nil {
+		return "", errNoSummaryModel
+	}
+	botSettings, err := s.settingsService.GetBot(ctx, botID)
+	if err != nil {
+		return "", err
+	}
+	modelID := strings.TrimSpace(botSettings.ChatModelID)
+	if modelID == "" {
+		return "", errNoSummaryModel
+	}
+	model, err := s.modelsService.GetByID(ctx, modelID)
+	if err != nil {
+		return "", err
+	}
+	if !model.Enable {
+		return "", errNoSummaryModel
+	}
+	provider, err := models.FetchProviderByID(ctx, s.queries, model.ProviderID)
+	if err != nil {
+		return "", err
+	}
+	creds, err := s.providersService.ResolveModelCredentials(ctx, provider)
+	if err != nil {
+		return "", err
+	}
+	sdkModel := models.NewSDKChatModel(models.SDKModelConfig{
+		ModelID:        model.ModelID,
+		ClientType:     provider.ClientType,
+		APIKey:         creds.APIKey,
+		CodexAccountID: creds.CodexAccountID,
+		BaseURL:        providers.ProviderConfigString(provider, "base_url"),
+	})
+
+	genCtx, cancel := context.WithTimeout(ctx, digestGenerateTimeout)
+	defer cancel()
+	return sdk.NewClient().GenerateText(genCtx,
+		sdk.WithModel(sdkModel),
+		sdk.WithMessages([]sdk.Message{sdk.UserMessage(prompt)}),
+	)
+}
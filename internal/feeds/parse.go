@@ -0,0 +1,267 @@
+package feeds
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"golang.org/x/net/html/charset"
+
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+// maxSummaryRunes bounds the stored text of one item.
+const maxSummaryRunes = 8000
+
+var errUnknownFormat = errors.New("not an RSS or Atom feed")
+
+// parsedFeed is a feed document in a format-independent shape. Entries keep
+// the order of the document, which is newest first for most feeds.
+type parsedFeed struct {
+	Title   string
+	Entries []parsedEntry
+}
+
+type parsedEntry struct {
+	GUID        string
+	Title       string
+	Link        string
+	Summary     string
+	PublishedAt time.Time
+}
+
+// rssDocument covers RSS 0.9x/2.0, where items live inside <channel>, and
+// RSS 1.0 (RDF), where they are siblings of it.
+type rssDocument struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+}
+
+type atomDocument struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   atomText   `xml:"summary"`
+	Content   atomText   `xml:"content"`
+}
+
+// atomText is an Atom text construct. XHTML content is inline markup rather
+// than escaped text.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) html() string {
+	if t.Type == "xhtml" {
+		return t.Inner
+	}
+	return t.Text
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// parseFeed decodes an RSS, RDF, or Atom document. Entries without any
+// identifying field are dropped.
+func parseFeed(body []byte) (parsedFeed, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return parsedFeed{}, errUnknownFormat
+			}
+			return parsedFeed{}, fmt.Errorf("parse feed: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(start.Name.Local) {
+		case "rss", "rdf":
+			var doc rssDocument
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return parsedFeed{}, fmt.Errorf("parse rss feed: %w", err)
+			}
+			return fromRSS(doc), nil
+		case "feed":
+			var doc atomDocument
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return parsedFeed{}, fmt.Errorf("parse atom feed: %w", err)
+			}
+			return fromAtom(doc), nil
+		default:
+			return parsedFeed{}, errUnknownFormat
+		}
+	}
+}
+
+func fromRSS(doc rssDocument) parsedFeed {
+	feed := parsedFeed{Title: cleanText(doc.Channel.Title)}
+	items := make([]rssItem, 0, len(doc.Channel.Items)+len(doc.Items))
+	items = append(items, doc.Channel.Items...)
+	items = append(items, doc.Items...)
+	for _, item := range items {
+		body := item.Content
+		if strings.TrimSpace(body) == "" {
+			body = item.Description
+		}
+		published := item.PubDate
+		if strings.TrimSpace(published) == "" {
+			published = item.Date
+		}
+		entry := parsedEntry{
+			GUID:        firstNonEmpty(item.GUID, item.About, item.Link),
+			Title:       cleanText(item.Title),
+			Link:        strings.TrimSpace(item.Link),
+			Summary:     htmlToText(body),
+			PublishedAt: parseDate(published),
+		}
+		if entry, ok := completeEntry(entry); ok {
+			feed.Entries = append(feed.Entries, entry)
+		}
+	}
+	return feed
+}
+
+func fromAtom(doc atomDocument) parsedFeed {
+	feed := parsedFeed{Title: cleanText(doc.Title)}
+	for _, item := range doc.Entries {
+		body := item.Content.html()
+		if strings.TrimSpace(body) == "" {
+			body = item.Summary.html()
+		}
+		published := item.Published
+		if strings.TrimSpace(published) == "" {
+			published = item.Updated
+		}
+		link := atomAlternateLink(item.Links)
+		entry := parsedEntry{
+			GUID:        firstNonEmpty(item.ID, link),
+			Title:       cleanText(item.Title),
+			Link:        link,
+			Summary:     htmlToText(body),
+			PublishedAt: parseDate(published),
+		}
+		if entry, ok := completeEntry(entry); ok {
+			feed.Entries = append(feed.Entries, entry)
+		}
+	}
+	return feed
+}
+
+// completeEntry falls back to a content hash for entries that carry neither
+// a guid nor a link, and rejects entries with nothing to show.
+func completeEntry(entry parsedEntry) (parsedEntry, bool) {
+	if entry.Title == "" && entry.Summary == "" && entry.Link == "" {
+		return entry, false
+	}
+	if entry.GUID == "" {
+		sum := sha256.Sum256([]byte(entry.Title + "\n" + entry.Summary))
+		entry.GUID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	if entry.Title == "" {
+		entry.Title = firstNonEmpty(entry.Link, textutil.TruncateRunesWithSuffix(entry.Summary, 80, "…"))
+	}
+	return entry, true
+}
+
+func atomAlternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	if len(links) > 0 {
+		return strings.TrimSpace(links[0].Href)
+	}
+	return ""
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339Nano,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseDate accepts the date formats feeds use in practice; unparseable
+// dates yield the zero time.
+func parseDate(raw string) time.Time {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// htmlToText turns the HTML body of an item into Markdown.
+func htmlToText(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	text, err := htmltomarkdown.ConvertString(raw)
+	if err != nil {
+		text = raw
+	}
+	return textutil.TruncateRunesWithSuffix(strings.TrimSpace(text), maxSummaryRunes, "…")
+}
+
+func cleanText(raw string) string {
+	return strings.Join(strings.Fields(raw), " ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package feeds
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFeedRSS(t *testing.T) {
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>  Example   Blog </title>
+    <atom:link href="https://example.com/feed.xml" rel="self"/>
+    <item>
+      <title>Second post</title>
+      <link>https://example.com/second</link>
+      <guid isPermaLink="false">post-2</guid>
+      <pubDate>Tue, 10 Jun 2025 09:30:00 GMT</pubDate>
+      <description>Short teaser</description>
+      <content:encoded><![CDATA[<p>The <strong>full</strong> text.</p>]]></content:encoded>
+    </item>
+    <item>
+      <title>First post</title>
+      <link>https://example.com/first</link>
+      <description>&lt;p&gt;Escaped &amp;amp; converted&lt;/p&gt;</description>
+    </item>
+    <item></item>
+  </channel>
+</rss>`
+	feed, err := parseFeed([]byte(body))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	if feed.Title != "Example Blog" {
+		t.Fatalf("title = %q", feed.Title)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("entries = %d, want 2 (empty item dropped)", len(feed.Entries))
+	}
+	second := feed.Entries[0]
+	if second.GUID != "post-2" || second.Link != "https://example.com/second" {
+		t.Fatalf("second = %+v", second)
+	}
+	if second.Summary != "The **full** text." {
+		t.Fatalf("summary = %q, want content:encoded as markdown", second.Summary)
+	}
+	if want := time.Date(2025, 6, 10, 9, 30, 0, 0, time.UTC); !second.PublishedAt.Equal(want) {
+		t.Fatalf("published = %v, want %v", second.PublishedAt, want)
+	}
+	first := feed.Entries[1]
+	if first.GUID != "https://example.com/first" {
+		t.Fatalf("guid = %q, want link fallback", first.GUID)
+	}
+	if first.Summary != "Escaped & converted" {
+		t.Fatalf("summary = %q", first.Summary)
+	}
+	if !first.PublishedAt.IsZero() {
+		t.Fatalf("published = %v, want zero", first.PublishedAt)
+	}
+}
+
+func TestParseFeedRDF(t *testing.T) {
+	body := `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://example.org/">
+    <title>RDF Site</title>
+  </channel>
+  <item rdf:about="https://example.org/a">
+    <title>Item A</title>
+    <link>https://example.org/a?utm=1</link>
+    <dc:date>2024-01-02T03:04:05Z</dc:date>
+  </item>
+</rdf:RDF>`
+	feed, err := parseFeed([]byte(body))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	if feed.Title != "RDF Site" || len(feed.Entries) != 1 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	entry := feed.Entries[0]
+	if entry.GUID != "https://example.org/a" {
+		t.Fatalf("guid = %q, want rdf:about", entry.GUID)
+	}
+	if entry.PublishedAt.IsZero() {
+		t.Fatal("dc:date not parsed")
+	}
+}
+
+func TestParseFeedAtom(t *testing.T) {
+	body := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom Site</title>
+  <entry>
+    <id>urn:uuid:1</id>
+    <title>Release notes</title>
+    <link rel="edit" href="https://example.net/edit/1"/>
+    <link rel="alternate" type="text/html" href="https://example.net/posts/1"/>
+    <updated>2025-03-04T05:06:07+02:00</updated>
+    <summary>Summary only</summary>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Inline <em>markup</em></p></div></content>
+  </entry>
+  <entry>
+    <link href="https://example.net/posts/2"/>
+    <summary type="html">&lt;b&gt;Bold&lt;/b&gt; teaser</summary>
+  </entry>
+</feed>`
+	feed, err := parseFeed([]byte(body))
+	if err != nil {
+		t.Fatalf("parseFeed: %v", err)
+	}
+	if feed.Title != "Atom Site" || len(feed.Entries) != 2 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	first := feed.Entries[0]
+	if first.GUID != "urn:uuid:1" || first.Link != "https://example.net/posts/1" {
+		t.Fatalf("first = %+v", first)
+	}
+	if !strings.Contains(first.Summary, "Inline *markup*") {
+		t.Fatalf("summary = %q, want xhtml content", first.Summary)
+	}
+	if want := time.Date(2025, 3, 4, 3, 6, 7, 0, time.UTC); !first.PublishedAt.Equal(want) {
+		t.Fatalf("published = %v, want updated %v", first.PublishedAt, want)
+	}
+	second := feed.Entries[1]
+	if second.GUID != "https://example.net/posts/2" || second.Title != "https://example.net/posts/2" {
+		t.Fatalf("second = %+v, want link as guid and title", second)
+	}
+	if second.Summary != "**Bold** teaser" {
+		t.Fatalf("summary = %q", second.Summary)
+	}
+}
+
+func TestParseFeedRejectsOtherDocuments(t *testing.T) {
+	for _, body := range []string{
+		`<!DOCTYPE html><html><body>Not a feed</body></html>`,
+		``,
+	} {
+		if _, err := parseFeed([]byte(body)); !errors.Is(err, errUnknownFormat) {
+			t.Fatalf("parseFeed(%q) error = %v, want errUnknownFormat", body, err)
+		}
+	}
+}
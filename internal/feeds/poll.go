@@ -0,0 +1,333 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	"github.com/memohai/memoh/internal/knowledge"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+const (
+	feedUserAgent = "MemohFeedReader/1.0"
+	fetchTimeout  = 30 * time.Second
+	maxFeedBytes  = 5 << 20
+	// maxItemsPerPoll bounds how many new items one poll ingests, so
+	// subscribing to a feed with a long history does not flood memory.
+	maxItemsPerPoll = 20
+)
+
+// Run polls feeds until done is closed. It sweeps on start and then every
+// minute, polling feeds whose interval elapsed and sending scheduled
+// digests; feeds queued by PollFeed are polled as they arrive. Feeds are
+// polled one at a time.
+func (s *Service) Run(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	s.sweep(ctx)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.poll(ctx, id)
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *Service) enqueue(feedID string) {
+	select {
+	case s.queue <- feedID:
+	default:
+		// The feed keeps its due time in the database; the next sweep polls it.
+		s.logger.Warn("feed poll queue full, deferring poll", slog.String("feed_id", feedID))
+	}
+}
+
+func (s *Service) sweep(ctx context.Context) {
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("feed sweep failed", slog.Any("error", err))
+		return
+	}
+	now := s.now()
+	if !s.pollingDisabled {
+		due, err := store.ListDueBotFeeds(ctx, pgtype.Timestamptz{Time: now.UTC(), Valid: true})
+		if err != nil {
+			s.logger.Warn("list due feeds failed", slog.Any("error", err))
+		}
+		for _, row := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			s.poll(ctx, row.ID.String())
+		}
+	}
+
+	digests, err := store.ListDueBotFeedDigests(ctx, pgtype.Timestamptz{Time: now.UTC(), Valid: true})
+	if err != nil {
+		s.logger.Warn("list due feed digests failed", slog.Any("error", err))
+		return
+	}
+	for _, row := range digests {
+		if ctx.Err() != nil {
+			return
+		}
+		// Move the schedule forward first so a failing delivery is retried at
+		// the next digest time rather than every sweep.
+		if err := store.SetBotFeedNextDigest(ctx, sqlc.SetBotFeedNextDigestParams{
+			NextDigestAt: nextDigest(row.DigestChannel, row.DigestPattern, row.Enabled, now),
+			ID:           row.ID,
+		}); err != nil {
+			s.logger.Warn("schedule feed digest failed", slog.String("feed_id", row.ID.String()), slog.Any("error", err))
+			continue
+		}
+		s.deliverDigest(ctx, store, row)
+	}
+}
+
+// poll fetches one feed, stores and ingests its new items, and sends the
+// digest right away for feeds without a digest schedule.
+func (s *Service) poll(ctx context.Context, feedID string) {
+	if s.pollingDisabled {
+		return
+	}
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("feed poll failed", slog.String("feed_id", feedID), slog.Any("error", err))
+		return
+	}
+	pgFeedID, err := db.ParseUUID(feedID)
+	if err != nil {
+		return
+	}
+	row, err := store.GetBotFeed(ctx, pgFeedID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Warn("load feed failed", slog.String("feed_id", feedID), slog.Any("error", err))
+		}
+		return
+	}
+
+	record := sqlc.RecordBotFeedPollParams{
+		Etag:         row.Etag,
+		LastModified: row.LastModified,
+		NextPollAt:   optionalTime(s.now().Add(time.Duration(row.PollIntervalMinutes)*time.Minute), row.Enabled),
+		ID:           row.ID,
+	}
+	result, err := s.fetch(ctx, row)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Warn("feed poll failed", slog.String("feed_id", feedID), slog.Any("error", err))
+		record.LastError = err.Error()
+		s.recordPoll(ctx, store, record)
+		return
+	}
+	record.FeedTitle = result.feed.Title
+	if row.Title == "" {
+		row.Title = result.feed.Title
+	}
+	record.Etag = result.etag
+	record.LastModified = result.lastModified
+
+	added, ingestErr := s.storeItems(ctx, store, row, result.feed.Entries)
+	if ingestErr != nil {
+		record.LastError = ingestErr.Error()
+	}
+	s.recordPoll(ctx, store, record)
+	if added > 0 && row.Enabled && row.DigestChannel != "" && row.DigestPattern == "" {
+		s.deliverDigest(ctx, store, row)
+	}
+}
+
+func (s *Service) recordPoll(ctx context.Context, store feedQueries, record sqlc.RecordBotFeedPollParams) {
+	if err := store.RecordBotFeedPoll(context.WithoutCancel(ctx), record); err != nil {
+		s.logger.Warn("record feed poll failed", slog.String("feed_id", record.ID.String()), slog.Any("error", err))
+	}
+}
+
+type fetchResult struct {
+	feed         parsedFeed
+	etag         string
+	lastModified string
+}
+
+// fetch downloads and parses a feed. The request is conditional on the
+// validators of the previous poll; an unchanged feed yields no entries.
+func (s *Service) fetch(ctx context.Context, row sqlc.BotFeed) (fetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, row.Url, nil)
+	if err != nil {
+		return fetchResult{}, err
+	}
+	req.Header.Set("User-Agent", feedUserAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.5")
+	if row.Etag != "" {
+		req.Header.Set("If-None-Match", row.Etag)
+	}
+	if row.LastModified != "" {
+		req.Header.Set("If-Modified-Since", row.LastModified)
+	}
+	resp, err := s.client.Do(req) //nolint:gosec // fetches feed URLs configured by the bot owner.
+	if err != nil {
+		return fetchResult{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotModified {
+		return fetchResult{etag: row.Etag, lastModified: row.LastModified}, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fetchResult{}, fmt.Errorf("http status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+	if err != nil {
+		return fetchResult{}, err
+	}
+	feed, err := parseFeed(body)
+	if err != nil {
+		return fetchResult{}, err
+	}
+	return fetchResult{
+		feed:         feed,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// storeItems records the entries not seen before, oldest first, and ingests
+// each into the feed's targets. It returns how many items were new. An item
+// whose ingestion fails is still recorded, so it is not ingested twice into
+// the targets that did succeed; the first failure is returned.
+func (s *Service) storeItems(ctx context.Context, store feedQueries, row sqlc.BotFeed, entries []parsedEntry) (int, error) {
+	if len(entries) > maxItemsPerPoll {
+		entries = entries[:maxItemsPerPoll]
+	}
+	added := 0
+	var firstErr error
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		var published pgtype.Timestamptz
+		if !entry.PublishedAt.IsZero() {
+			published = pgtype.Timestamptz{Time: entry.PublishedAt, Valid: true}
+		}
+		item, err := store.InsertBotFeedItem(ctx, sqlc.InsertBotFeedItemParams{
+			FeedID:      row.ID,
+			Guid:        entry.GUID,
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Summary:     entry.Summary,
+			PublishedAt: published,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Seen in an earlier poll.
+				continue
+			}
+			return added, fmt.Errorf("store feed item: %w", err)
+		}
+		added++
+		if err := s.ingest(ctx, row, item); err != nil {
+			s.logger.Warn("ingest feed item failed",
+				slog.String("feed_id", row.ID.String()), slog.String("item_id", item.ID.String()), slog.Any("error", err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return added, firstErr
+}
+
+// ingest adds one item to the bot's memory and the feed's collection, as
+// configured.
+func (s *Service) ingest(ctx context.Context, row sqlc.BotFeed, item sqlc.BotFeedItem) error {
+	var errs []error
+	if row.IngestMemory {
+		if err := s.ingestMemory(ctx, row, item); err != nil {
+			errs = append(errs, fmt.Errorf("ingest into memory: %w", err))
+		}
+	}
+	if row.CollectionID.Valid && s.collections != nil {
+		content := item.Summary
+		if strings.TrimSpace(content) == "" {
+			content = strings.TrimSpace(item.Title + "\n" + item.Link)
+		}
+		if _, err := s.collections.AddDocument(ctx, row.CollectionID.String(), knowledge.AddDocumentRequest{
+			Title:     item.Title,
+			Content:   content,
+			SourceURL: item.Link,
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("ingest into collection: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) ingestMemory(ctx context.Context, row sqlc.BotFeed, item sqlc.BotFeedItem) error {
+	botID := row.BotID.String()
+	provider, err := memprovider.ResolveBotProvider(ctx, s.memoryRegistry, s.settingsService, botID)
+	if err != nil {
+		return err
+	}
+	_, err = provider.Add(ctx, memprovider.AddRequest{
+		Message: itemMemoryText(row, item),
+		BotID:   botID,
+		Metadata: map[string]any{
+			"source":    "feed",
+			"feed_id":   row.ID.String(),
+			"feed_url":  row.Url,
+			"item_guid": item.Guid,
+			"item_link": item.Link,
+		},
+	})
+	return err
+}
+
+func itemMemoryText(row sqlc.BotFeed, item sqlc.BotFeedItem) string {
+	var b strings.Builder
+	b.WriteString("New item from the feed ")
+	b.WriteString(feedDisplayName(row))
+	b.WriteString(": ")
+	b.WriteString(item.Title)
+	if item.PublishedAt.Valid {
+		b.WriteString(" (published ")
+		b.WriteString(item.PublishedAt.Time.UTC().Format(time.RFC3339))
+		b.WriteString(")")
+	}
+	if item.Link != "" {
+		b.WriteString("\n")
+		b.WriteString(item.Link)
+	}
+	if item.Summary != "" {
+		b.WriteString("\n\n")
+		b.WriteString(item.Summary)
+	}
+	return b.String()
+}
+
+func feedDisplayName(row sqlc.BotFeed) string {
+	if title := strings.TrimSpace(row.Title); title != "" {
+		return title
+	}
+	return row.Url
+}
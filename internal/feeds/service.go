@@ -0,0 +1,580 @@
+// Package feeds polls RSS and Atom feeds that bots subscribe to. New items
+// are ingested into the bot's memory and/or a knowledge collection, and can
+// be summarized into digests delivered to a channel.
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/robfig/cron/v3"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/knowledge"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/models"
+	"github.com/memohai/memoh/internal/netguard"
+	"github.com/memohai/memoh/internal/providers"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+const (
+	defaultPollIntervalMinutes = 60
+	minPollIntervalMinutes     = 5
+	maxPollIntervalMinutes     = 7 * 24 * 60
+	maxTitleLength             = 200
+	defaultItemLimit           = 50
+	maxItemLimit               = 200
+
+	queueSize     = 64
+	sweepInterval = time.Minute
+)
+
+var (
+	ErrFeedNotFound = errors.New("feed not found")
+	ErrFeedExists   = errors.New("bot is already subscribed to this feed")
+	ErrInvalidFeed  = errors.New("invalid feed")
+	// ErrPollingDisabled is returned for new subscriptions and manual polls
+	// while offline mode keeps the deployment off the public internet.
+	ErrPollingDisabled = errors.New("feed polling is disabled in offline mode")
+)
+
+var digestScheduleParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+type feedQueries interface {
+	CreateBotFeed(ctx context.Context, arg sqlc.CreateBotFeedParams) (sqlc.BotFeed, error)
+	DeleteBotFeed(ctx context.Context, arg sqlc.DeleteBotFeedParams) (int64, error)
+	GetBotFeed(ctx context.Context, id pgtype.UUID) (sqlc.BotFeed, error)
+	InsertBotFeedItem(ctx context.Context, arg sqlc.InsertBotFeedItemParams) (sqlc.BotFeedItem, error)
+	ListBotFeedItems(ctx context.Context, arg sqlc.ListBotFeedItemsParams) ([]sqlc.BotFeedItem, error)
+	ListBotFeeds(ctx context.Context, botID pgtype.UUID) ([]sqlc.BotFeed, error)
+	ListDueBotFeedDigests(ctx context.Context, now pgtype.Timestamptz) ([]sqlc.BotFeed, error)
+	ListDueBotFeeds(ctx context.Context, now pgtype.Timestamptz) ([]sqlc.BotFeed, error)
+	ListUndigestedBotFeedItems(ctx context.Context, arg sqlc.ListUndigestedBotFeedItemsParams) ([]sqlc.BotFeedItem, error)
+	MarkBotFeedItemsDigested(ctx context.Context, arg sqlc.MarkBotFeedItemsDigestedParams) error
+	RecordBotFeedPoll(ctx context.Context, arg sqlc.RecordBotFeedPollParams) error
+	SetBotFeedNextDigest(ctx context.Context, arg sqlc.SetBotFeedNextDigestParams) error
+	UpdateBotFeed(ctx context.Context, arg sqlc.UpdateBotFeedParams) (sqlc.BotFeed, error)
+}
+
+// collectionStore is the part of the knowledge service feeds ingest into.
+type collectionStore interface {
+	GetCollection(ctx context.Context, id string) (knowledge.Collection, error)
+	AddDocument(ctx context.Context, collectionID string, req knowledge.AddDocumentRequest) (knowledge.Document, error)
+}
+
+// Service manages feed subscriptions and runs the poll and digest worker.
+type Service struct {
+	queries          dbstore.Queries
+	client           *http.Client
+	collections      collectionStore
+	memoryRegistry   *memprovider.Registry
+	settingsService  *settings.Service
+	modelsService    *models.Service
+	providersService *providers.Service
+	channels         channel.Runtime
+	channelTypes     *channel.Registry
+	summarize        func(ctx context.Context, botID, prompt string) (string, error)
+	logger           *slog.Logger
+	now              func() time.Time
+	queue            chan string
+	// pollingDisabled stops fetching feeds; digests of stored items still
+	// go out. It is set once at startup.
+	pollingDisabled bool
+}
+
+// NewService creates a feed service. Ingestion targets and digest delivery
+// are configured with the setters; a feed whose target is not configured
+// skips it. Feeds are fetched with a client that refuses loopback, private
+// and link-local addresses.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	s := &Service{
+		queries: queries,
+		client:  netguard.NewClient(fetchTimeout),
+		logger:  log.With(slog.String("service", "feeds")),
+		now:     time.Now,
+		queue:   make(chan string, queueSize),
+	}
+	s.summarize = s.summarizeWithChatModel
+	return s
+}
+
+// SetKnowledgeService enables ingesting items into knowledge collections.
+func (s *Service) SetKnowledgeService(svc *knowledge.Service) {
+	if svc != nil {
+		s.collections = svc
+	}
+}
+
+// SetMemoryRegistry enables ingesting items into bot memory.
+func (s *Service) SetMemoryRegistry(registry *memprovider.Registry) {
+	s.memoryRegistry = registry
+}
+
+// SetSettingsService sets the settings service used to resolve each bot's
+// memory provider and chat model.
+func (s *Service) SetSettingsService(svc *settings.Service) {
+	s.settingsService = svc
+}
+
+// SetModelServices enables summarizing digests with the bot's chat model.
+// Without them digests list the new items instead.
+func (s *Service) SetModelServices(modelsService *models.Service, providersService *providers.Service) {
+	s.modelsService = modelsService
+	s.providersService = providersService
+}
+
+// SetChannelRuntime enables digest delivery. registry validates the digest
+// channel of new and updated feeds.
+func (s *Service) SetChannelRuntime(runtime channel.Runtime, registry *channel.Registry) {
+	s.channels = runtime
+	s.channelTypes = registry
+}
+
+// DisablePolling stops fetching feeds. Offline deployments call it before
+// the worker starts.
+func (s *Service) DisablePolling() {
+	s.pollingDisabled = true
+}
+
+func (s *Service) store() (feedQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("feed service not configured")
+	}
+	store, ok := s.queries.(feedQueries)
+	if !ok {
+		return nil, errors.New("feed queries not supported by store")
+	}
+	return store, nil
+}
+
+// CreateFeed subscribes a bot to a feed. Enabled feeds are polled right away
+// and then every poll interval.
+func (s *Service) CreateFeed(ctx context.Context, botID string, req CreateFeedRequest) (Feed, error) {
+	if s.pollingDisabled {
+		return Feed{}, ErrPollingDisabled
+	}
+	store, err := s.store()
+	if err != nil {
+		return Feed{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Feed{}, err
+	}
+	pollInterval := req.PollIntervalMinutes
+	if pollInterval == 0 {
+		pollInterval = defaultPollIntervalMinutes
+	}
+	settings, err := s.normalizeSettings(ctx, feedSettings{
+		url:           req.URL,
+		title:         req.Title,
+		pollInterval:  pollInterval,
+		ingestMemory:  req.IngestMemory,
+		collectionID:  req.CollectionID,
+		digestChannel: req.DigestChannel,
+		digestTarget:  req.DigestTarget,
+		digestPattern: req.DigestPattern,
+	})
+	if err != nil {
+		return Feed{}, err
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+	now := s.now()
+	row, err := store.CreateBotFeed(ctx, sqlc.CreateBotFeedParams{
+		BotID:               pgBotID,
+		Url:                 settings.url,
+		Title:               settings.title,
+		PollIntervalMinutes: int32(settings.pollInterval), //nolint:gosec // validated by normalizeSettings.
+		IngestMemory:        settings.ingestMemory,
+		CollectionID:        settings.collection,
+		DigestChannel:       settings.digestChannel,
+		DigestTarget:        settings.digestTarget,
+		DigestPattern:       settings.digestPattern,
+		Enabled:             enabled,
+		NextPollAt:          optionalTime(now, enabled),
+		NextDigestAt:        nextDigest(settings.digestChannel, settings.digestPattern, enabled, now),
+	})
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return Feed{}, ErrFeedExists
+		}
+		return Feed{}, fmt.Errorf("create feed: %w", err)
+	}
+	feed := toFeed(row)
+	if enabled {
+		s.enqueue(feed.ID)
+	}
+	return feed, nil
+}
+
+// ListFeeds lists the feeds of a bot.
+func (s *Service) ListFeeds(ctx context.Context, botID string) ([]Feed, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListBotFeeds(ctx, pgBotID)
+	if err != nil {
+		return nil, fmt.Errorf("list feeds: %w", err)
+	}
+	items := make([]Feed, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toFeed(row))
+	}
+	return items, nil
+}
+
+// GetFeed returns one feed of a bot.
+func (s *Service) GetFeed(ctx context.Context, botID, feedID string) (Feed, error) {
+	store, err := s.store()
+	if err != nil {
+		return Feed{}, err
+	}
+	row, err := s.getFeed(ctx, store, botID, feedID)
+	if err != nil {
+		return Feed{}, err
+	}
+	return toFeed(row), nil
+}
+
+// UpdateFeed changes a feed. The next poll keeps its time unless the feed
+// was just enabled, and the next digest is recomputed from the pattern.
+func (s *Service) UpdateFeed(ctx context.Context, botID, feedID string, req UpdateFeedRequest) (Feed, error) {
+	store, err := s.store()
+	if err != nil {
+		return Feed{}, err
+	}
+	current, err := s.getFeed(ctx, store, botID, feedID)
+	if err != nil {
+		return Feed{}, err
+	}
+	settings := feedSettings{
+		url:           current.Url,
+		title:         current.Title,
+		pollInterval:  int(current.PollIntervalMinutes),
+		ingestMemory:  current.IngestMemory,
+		digestChannel: current.DigestChannel,
+		digestTarget:  current.DigestTarget,
+		digestPattern: current.DigestPattern,
+	}
+	if current.CollectionID.Valid {
+		settings.collectionID = current.CollectionID.String()
+	}
+	enabled := current.Enabled
+	if req.URL != nil {
+		settings.url = *req.URL
+	}
+	if req.Title != nil {
+		settings.title = *req.Title
+	}
+	if req.PollIntervalMinutes != nil {
+		settings.pollInterval = *req.PollIntervalMinutes
+	}
+	if req.IngestMemory != nil {
+		settings.ingestMemory = *req.IngestMemory
+	}
+	if req.CollectionID != nil {
+		settings.collectionID = *req.CollectionID
+	}
+	if req.DigestChannel != nil {
+		settings.digestChannel = *req.DigestChannel
+	}
+	if req.DigestTarget != nil {
+		settings.digestTarget = *req.DigestTarget
+	}
+	if req.DigestPattern != nil {
+		settings.digestPattern = *req.DigestPattern
+	}
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	if settings, err = s.normalizeSettings(ctx, settings); err != nil {
+		return Feed{}, err
+	}
+	now := s.now()
+	nextPoll := current.NextPollAt
+	switch {
+	case !enabled:
+		nextPoll = pgtype.Timestamptz{}
+	case !nextPoll.Valid || settings.url != current.Url:
+		nextPoll = optionalTime(now, true)
+	}
+	row, err := store.UpdateBotFeed(ctx, sqlc.UpdateBotFeedParams{
+		Url:                 settings.url,
+		Title:               settings.title,
+		PollIntervalMinutes: int32(settings.pollInterval), //nolint:gosec // validated by normalizeSettings.
+		IngestMemory:        settings.ingestMemory,
+		CollectionID:        settings.collection,
+		DigestChannel:       settings.digestChannel,
+		DigestTarget:        settings.digestTarget,
+		DigestPattern:       settings.digestPattern,
+		Enabled:             enabled,
+		NextPollAt:          nextPoll,
+		NextDigestAt:        nextDigest(settings.digestChannel, settings.digestPattern, enabled, now),
+		ID:                  current.ID,
+	})
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return Feed{}, ErrFeedExists
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Feed{}, ErrFeedNotFound
+		}
+		return Feed{}, fmt.Errorf("update feed: %w", err)
+	}
+	return toFeed(row), nil
+}
+
+// DeleteFeed unsubscribes a bot from a feed. Items already ingested into
+// memory or a collection are kept.
+func (s *Service) DeleteFeed(ctx context.Context, botID, feedID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	pgFeedID, err := db.ParseUUID(feedID)
+	if err != nil {
+		return ErrFeedNotFound
+	}
+	n, err := store.DeleteBotFeed(ctx, sqlc.DeleteBotFeedParams{BotID: pgBotID, ID: pgFeedID})
+	if err != nil {
+		return fmt.Errorf("delete feed: %w", err)
+	}
+	if n == 0 {
+		return ErrFeedNotFound
+	}
+	return nil
+}
+
+// PollFeed queues an immediate poll of a feed, whether or not it is enabled.
+func (s *Service) PollFeed(ctx context.Context, botID, feedID string) (Feed, error) {
+	if s.pollingDisabled {
+		return Feed{}, ErrPollingDisabled
+	}
+	store, err := s.store()
+	if err != nil {
+		return Feed{}, err
+	}
+	row, err := s.getFeed(ctx, store, botID, feedID)
+	if err != nil {
+		return Feed{}, err
+	}
+	feed := toFeed(row)
+	s.enqueue(feed.ID)
+	return feed, nil
+}
+
+// ListItems lists the most recent items of a feed, newest first. A limit of
+// zero uses the default.
+func (s *Service) ListItems(ctx context.Context, botID, feedID string, limit int) ([]Item, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	row, err := s.getFeed(ctx, store, botID, feedID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultItemLimit
+	}
+	limit = min(limit, maxItemLimit)
+	rows, err := store.ListBotFeedItems(ctx, sqlc.ListBotFeedItemsParams{
+		FeedID:   row.ID,
+		RowLimit: int32(limit), //nolint:gosec // bounded by maxItemLimit.
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list feed items: %w", err)
+	}
+	items := make([]Item, 0, len(rows))
+	for _, item := range rows {
+		items = append(items, toItem(item))
+	}
+	return items, nil
+}
+
+func (*Service) getFeed(ctx context.Context, store feedQueries, botID, feedID string) (sqlc.BotFeed, error) {
+	pgFeedID, err := db.ParseUUID(feedID)
+	if err != nil {
+		return sqlc.BotFeed{}, ErrFeedNotFound
+	}
+	row, err := store.GetBotFeed(ctx, pgFeedID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.BotFeed{}, ErrFeedNotFound
+		}
+		return sqlc.BotFeed{}, fmt.Errorf("get feed: %w", err)
+	}
+	if row.BotID.String() != strings.TrimSpace(botID) {
+		return sqlc.BotFeed{}, ErrFeedNotFound
+	}
+	return row, nil
+}
+
+// feedSettings is the user-editable part of a feed.
+type feedSettings struct {
+	url           string
+	title         string
+	pollInterval  int
+	ingestMemory  bool
+	collectionID  string
+	digestChannel string
+	digestTarget  string
+	digestPattern string
+
+	// collection is collectionID, resolved by normalizeSettings.
+	collection pgtype.UUID
+}
+
+func (s *Service) normalizeSettings(ctx context.Context, in feedSettings) (feedSettings, error) {
+	out := in
+	feedURL, err := url.Parse(strings.TrimSpace(in.url))
+	if err != nil || (feedURL.Scheme != "http" && feedURL.Scheme != "https") || feedURL.Host == "" {
+		return feedSettings{}, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidFeed)
+	}
+	feedURL.Fragment = ""
+	out.url = feedURL.String()
+	out.title = strings.TrimSpace(in.title)
+	if len([]rune(out.title)) > maxTitleLength {
+		return feedSettings{}, fmt.Errorf("%w: title must be at most %d characters", ErrInvalidFeed, maxTitleLength)
+	}
+	if in.pollInterval < minPollIntervalMinutes || in.pollInterval > maxPollIntervalMinutes {
+		return feedSettings{}, fmt.Errorf("%w: poll_interval_minutes must be between %d and %d",
+			ErrInvalidFeed, minPollIntervalMinutes, maxPollIntervalMinutes)
+	}
+
+	out.collectionID = strings.TrimSpace(in.collectionID)
+	if out.collectionID != "" {
+		if s.collections == nil {
+			return feedSettings{}, fmt.Errorf("%w: knowledge collections are not available", ErrInvalidFeed)
+		}
+		collection, err := s.collections.GetCollection(ctx, out.collectionID)
+		if err != nil {
+			if errors.Is(err, knowledge.ErrCollectionNotFound) {
+				return feedSettings{}, fmt.Errorf("%w: knowledge collection not found", ErrInvalidFeed)
+			}
+			return feedSettings{}, err
+		}
+		if out.collection, err = db.ParseUUID(collection.ID); err != nil {
+			return feedSettings{}, err
+		}
+		out.collectionID = collection.ID
+	}
+
+	out.digestChannel = strings.TrimSpace(in.digestChannel)
+	out.digestTarget = strings.TrimSpace(in.digestTarget)
+	out.digestPattern = strings.TrimSpace(in.digestPattern)
+	if out.digestChannel == "" {
+		if out.digestTarget != "" || out.digestPattern != "" {
+			return feedSettings{}, fmt.Errorf("%w: digest_target and digest_pattern require digest_channel", ErrInvalidFeed)
+		}
+		return out, nil
+	}
+	if s.channelTypes != nil {
+		channelType, err := s.channelTypes.ParseChannelType(out.digestChannel)
+		if err != nil {
+			return feedSettings{}, fmt.Errorf("%w: %s", ErrInvalidFeed, err.Error())
+		}
+		out.digestChannel = channelType.String()
+	}
+	if out.digestTarget == "" {
+		return feedSettings{}, fmt.Errorf("%w: digest_target is required with digest_channel", ErrInvalidFeed)
+	}
+	if out.digestPattern != "" {
+		if _, err := digestScheduleParser.Parse(out.digestPattern); err != nil {
+			return feedSettings{}, fmt.Errorf("%w: digest_pattern: %s", ErrInvalidFeed, err.Error())
+		}
+	}
+	return out, nil
+}
+
+// nextDigest is when a scheduled digest is next due, or NULL for feeds
+// without digests, disabled feeds, and feeds that send a digest after every
+// poll.
+func nextDigest(digestChannel, pattern string, enabled bool, now time.Time) pgtype.Timestamptz {
+	if !enabled || digestChannel == "" || pattern == "" {
+		return pgtype.Timestamptz{}
+	}
+	schedule, err := digestScheduleParser.Parse(pattern)
+	if err != nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: schedule.Next(now).UTC(), Valid: true}
+}
+
+func optionalTime(t time.Time, valid bool) pgtype.Timestamptz {
+	if !valid {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: t.UTC(), Valid: true}
+}
+
+func timePtr(ts pgtype.Timestamptz) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+func toFeed(row sqlc.BotFeed) Feed {
+	feed := Feed{
+		ID:                  row.ID.String(),
+		BotID:               row.BotID.String(),
+		URL:                 row.Url,
+		Title:               row.Title,
+		PollIntervalMinutes: int(row.PollIntervalMinutes),
+		IngestMemory:        row.IngestMemory,
+		DigestChannel:       row.DigestChannel,
+		DigestTarget:        row.DigestTarget,
+		DigestPattern:       row.DigestPattern,
+		Enabled:             row.Enabled,
+		LastError:           row.LastError,
+		LastPolledAt:        timePtr(row.LastPolledAt),
+		NextPollAt:          timePtr(row.NextPollAt),
+		NextDigestAt:        timePtr(row.NextDigestAt),
+		CreatedAt:           row.CreatedAt.Time,
+		UpdatedAt:           row.UpdatedAt.Time,
+	}
+	if row.CollectionID.Valid {
+		feed.CollectionID = row.CollectionID.String()
+	}
+	return feed
+}
+
+func toItem(row sqlc.BotFeedItem) Item {
+	return Item{
+		ID:          row.ID.String(),
+		FeedID:      row.FeedID.String(),
+		GUID:        row.Guid,
+		Title:       row.Title,
+		Link:        row.Link,
+		Summary:     row.Summary,
+		PublishedAt: timePtr(row.PublishedAt),
+		DigestedAt:  timePtr(row.DigestedAt),
+		CreatedAt:   row.CreatedAt.Time,
+	}
+}
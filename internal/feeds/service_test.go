@@ -0,0 +1,398 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/knowledge"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+const (
+	testBotID        = "11111111-1111-1111-1111-111111111111"
+	testFeedID       = "22222222-2222-2222-2222-222222222222"
+	testCollectionID = "33333333-3333-3333-3333-333333333333"
+)
+
+const testRSS = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>News</title>
+  <item><title>Newer</title><link>https://example.com/2</link><guid>2</guid><description>Second body</description></item>
+  <item><title>Older</title><link>https://example.com/1</link><guid>1</guid><description>First body</description></item>
+</channel></rss>`
+
+type fakeFeedQueries struct {
+	dbstore.Queries
+
+	feed     sqlc.BotFeed
+	items    []sqlc.BotFeedItem
+	polls    []sqlc.RecordBotFeedPollParams
+	digested []pgtype.UUID
+	itemSeq  byte
+}
+
+func (f *fakeFeedQueries) CreateBotFeed(_ context.Context, arg sqlc.CreateBotFeedParams) (sqlc.BotFeed, error) {
+	f.feed = sqlc.BotFeed{
+		ID:                  db.ParseUUIDOrEmpty(testFeedID),
+		BotID:               arg.BotID,
+		Url:                 arg.Url,
+		Title:               arg.Title,
+		PollIntervalMinutes: arg.PollIntervalMinutes,
+		IngestMemory:        arg.IngestMemory,
+		CollectionID:        arg.CollectionID,
+		DigestChannel:       arg.DigestChannel,
+		DigestTarget:        arg.DigestTarget,
+		DigestPattern:       arg.DigestPattern,
+		Enabled:             arg.Enabled,
+		NextPollAt:          arg.NextPollAt,
+		NextDigestAt:        arg.NextDigestAt,
+	}
+	return f.feed, nil
+}
+
+func (*fakeFeedQueries) DeleteBotFeed(context.Context, sqlc.DeleteBotFeedParams) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeFeedQueries) GetBotFeed(_ context.Context, id pgtype.UUID) (sqlc.BotFeed, error) {
+	if !f.feed.ID.Valid || f.feed.ID != id {
+		return sqlc.BotFeed{}, pgx.ErrNoRows
+	}
+	return f.feed, nil
+}
+
+func (f *fakeFeedQueries) InsertBotFeedItem(_ context.Context, arg sqlc.InsertBotFeedItemParams) (sqlc.BotFeedItem, error) {
+	for _, item := range f.items {
+		if item.FeedID == arg.FeedID && item.Guid == arg.Guid {
+			return sqlc.BotFeedItem{}, pgx.ErrNoRows
+		}
+	}
+	f.itemSeq++
+	item := sqlc.BotFeedItem{
+		ID:          pgtype.UUID{Bytes: [16]byte{f.itemSeq}, Valid: true},
+		FeedID:      arg.FeedID,
+		Guid:        arg.Guid,
+		Title:       arg.Title,
+		Link:        arg.Link,
+		Summary:     arg.Summary,
+		PublishedAt: arg.PublishedAt,
+	}
+	f.items = append(f.items, item)
+	return item, nil
+}
+
+func (f *fakeFeedQueries) ListBotFeedItems(context.Context, sqlc.ListBotFeedItemsParams) ([]sqlc.BotFeedItem, error) {
+	return f.items, nil
+}
+
+func (f *fakeFeedQueries) ListBotFeeds(context.Context, pgtype.UUID) ([]sqlc.BotFeed, error) {
+	return []sqlc.BotFeed{f.feed}, nil
+}
+
+func (f *fakeFeedQueries) ListDueBotFeedDigests(_ context.Context, now pgtype.Timestamptz) ([]sqlc.BotFeed, error) {
+	if f.feed.NextDigestAt.Valid && !f.feed.NextDigestAt.Time.After(now.Time) {
+		return []sqlc.BotFeed{f.feed}, nil
+	}
+	return nil, nil
+}
+
+func (*fakeFeedQueries) ListDueBotFeeds(context.Context, pgtype.Timestamptz) ([]sqlc.BotFeed, error) {
+	return nil, nil
+}
+
+func (f *fakeFeedQueries) ListUndigestedBotFeedItems(context.Context, sqlc.ListUndigestedBotFeedItemsParams) ([]sqlc.BotFeedItem, error) {
+	var out []sqlc.BotFeedItem
+	for _, item := range f.items {
+		if !item.DigestedAt.Valid {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeFeedQueries) MarkBotFeedItemsDigested(_ context.Context, arg sqlc.MarkBotFeedItemsDigestedParams) error {
+	for _, id := range arg.Ids {
+		for i := range f.items {
+			if f.items[i].ID == id {
+				f.items[i].DigestedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			}
+		}
+	}
+	f.digested = append(f.digested, arg.Ids...)
+	return nil
+}
+
+func (f *fakeFeedQueries) RecordBotFeedPoll(_ context.Context, arg sqlc.RecordBotFeedPollParams) error {
+	f.polls = append(f.polls, arg)
+	if f.feed.Title == "" {
+		f.feed.Title = arg.FeedTitle
+	}
+	f.feed.Etag = arg.Etag
+	f.feed.LastModified = arg.LastModified
+	f.feed.LastError = arg.LastError
+	f.feed.NextPollAt = arg.NextPollAt
+	return nil
+}
+
+func (f *fakeFeedQueries) SetBotFeedNextDigest(_ context.Context, arg sqlc.SetBotFeedNextDigestParams) error {
+	f.feed.NextDigestAt = arg.NextDigestAt
+	return nil
+}
+
+func (*fakeFeedQueries) UpdateBotFeed(context.Context, sqlc.UpdateBotFeedParams) (sqlc.BotFeed, error) {
+	return sqlc.BotFeed{}, errors.New("not implemented")
+}
+
+type fakeCollections struct {
+	added []knowledge.AddDocumentRequest
+}
+
+func (*fakeCollections) GetCollection(_ context.Context, id string) (knowledge.Collection, error) {
+	if id != testCollectionID {
+		return knowledge.Collection{}, knowledge.ErrCollectionNotFound
+	}
+	return knowledge.Collection{ID: id}, nil
+}
+
+func (f *fakeCollections) AddDocument(_ context.Context, _ string, req knowledge.AddDocumentRequest) (knowledge.Document, error) {
+	f.added = append(f.added, req)
+	return knowledge.Document{}, nil
+}
+
+type fakeMemory struct {
+	memprovider.Provider
+
+	added []memprovider.AddRequest
+}
+
+func (f *fakeMemory) Add(_ context.Context, req memprovider.AddRequest) (memprovider.SearchResponse, error) {
+	f.added = append(f.added, req)
+	return memprovider.SearchResponse{}, nil
+}
+
+type fakeChannels struct {
+	channel.Runtime
+
+	sent []channel.SendRequest
+	err  error
+}
+
+func (f *fakeChannels) Send(_ context.Context, _ string, _ channel.ChannelType, req channel.SendRequest) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, req)
+	return nil
+}
+
+func newTestService(queries *fakeFeedQueries) *Service {
+	svc := NewService(slog.Default(), queries)
+	// Test servers listen on loopback, which the guarded client refuses.
+	svc.client = &http.Client{Timeout: fetchTimeout}
+	svc.summarize = func(context.Context, string, string) (string, error) {
+		return "", errNoSummaryModel
+	}
+	return svc
+}
+
+func seedFeed(queries *fakeFeedQueries, feedURL string) {
+	queries.feed = sqlc.BotFeed{
+		ID:                  db.ParseUUIDOrEmpty(testFeedID),
+		BotID:               db.ParseUUIDOrEmpty(testBotID),
+		Url:                 feedURL,
+		PollIntervalMinutes: defaultPollIntervalMinutes,
+		Enabled:             true,
+	}
+}
+
+func TestCreateFeedValidatesSettings(t *testing.T) {
+	svc := newTestService(&fakeFeedQueries{})
+	svc.collections = &fakeCollections{}
+	cases := map[string]CreateFeedRequest{
+		"relative url":       {URL: "/feed.xml"},
+		"unsupported scheme": {URL: "ftp://example.com/feed.xml"},
+		"interval too short": {URL: "https://example.com/feed.xml", PollIntervalMinutes: 1},
+		"unknown collection": {URL: "https://example.com/feed.xml", CollectionID: "44444444-4444-4444-4444-444444444444"},
+		"target only":        {URL: "https://example.com/feed.xml", DigestTarget: "chat-1"},
+		"missing target":     {URL: "https://example.com/feed.xml", DigestChannel: "telegram"},
+		"bad pattern":        {URL: "https://example.com/feed.xml", DigestChannel: "telegram", DigestTarget: "chat-1", DigestPattern: "every day"},
+	}
+	for name, req := range cases {
+		if _, err := svc.CreateFeed(context.Background(), testBotID, req); !errors.Is(err, ErrInvalidFeed) {
+			t.Errorf("%s: error = %v, want ErrInvalidFeed", name, err)
+		}
+	}
+
+	feed, err := svc.CreateFeed(context.Background(), testBotID, CreateFeedRequest{
+		URL:           " https://example.com/feed.xml#top ",
+		CollectionID:  testCollectionID,
+		DigestChannel: "telegram",
+		DigestTarget:  "chat-1",
+		DigestPattern: "0 9 * * *",
+	})
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if feed.URL != "https://example.com/feed.xml" || feed.PollIntervalMinutes != defaultPollIntervalMinutes {
+		t.Fatalf("feed = %+v", feed)
+	}
+	if feed.CollectionID != testCollectionID || feed.NextPollAt == nil || feed.NextDigestAt == nil {
+		t.Fatalf("feed = %+v, want collection and schedules", feed)
+	}
+	select {
+	case id := <-svc.queue:
+		if id != testFeedID {
+			t.Fatalf("queued %q", id)
+		}
+	default:
+		t.Fatal("new feed was not queued for polling")
+	}
+}
+
+func TestOfflineRefusesPolling(t *testing.T) {
+	svc := newTestService(&fakeFeedQueries{})
+	svc.DisablePolling()
+	if _, err := svc.CreateFeed(context.Background(), testBotID, CreateFeedRequest{URL: "https://example.com/feed.xml"}); !errors.Is(err, ErrPollingDisabled) {
+		t.Fatalf("CreateFeed error = %v, want ErrPollingDisabled", err)
+	}
+	if _, err := svc.PollFeed(context.Background(), testBotID, testFeedID); !errors.Is(err, ErrPollingDisabled) {
+		t.Fatalf("PollFeed error = %v, want ErrPollingDisabled", err)
+	}
+}
+
+func TestPollIngestsNewItemsAndSendsDigest(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(testRSS))
+	}))
+	defer server.Close()
+
+	queries := &fakeFeedQueries{}
+	seedFeed(queries, server.URL)
+	queries.feed.IngestMemory = true
+	queries.feed.CollectionID = db.ParseUUIDOrEmpty(testCollectionID)
+	queries.feed.DigestChannel = "telegram"
+	queries.feed.DigestTarget = "chat-1"
+
+	memory := &fakeMemory{}
+	registry := memprovider.NewRegistry(nil)
+	registry.Register(memprovider.DefaultBuiltinProviderID, memory)
+	collections := &fakeCollections{}
+	channels := &fakeChannels{}
+
+	svc := newTestService(queries)
+	svc.memoryRegistry = registry
+	svc.collections = collections
+	svc.channels = channels
+
+	svc.poll(context.Background(), testFeedID)
+	svc.poll(context.Background(), testFeedID)
+
+	if requests != 2 {
+		t.Fatalf("requests = %d, want 2", requests)
+	}
+	if len(queries.items) != 2 || queries.items[0].Guid != "1" {
+		t.Fatalf("items = %+v, want both items stored oldest first", queries.items)
+	}
+	if len(memory.added) != 2 || memory.added[0].Metadata["item_guid"] != "1" {
+		t.Fatalf("memory = %+v", memory.added)
+	}
+	if len(collections.added) != 2 || collections.added[1].SourceURL != "https://example.com/2" {
+		t.Fatalf("collection documents = %+v", collections.added)
+	}
+	if queries.feed.Title != "News" || queries.feed.Etag != `"v1"` || queries.feed.LastError != "" {
+		t.Fatalf("feed = %+v", queries.feed)
+	}
+	if len(channels.sent) != 1 {
+		t.Fatalf("digests sent = %d, want 1 (unchanged feed sends none)", len(channels.sent))
+	}
+	text := channels.sent[0].Message.Text
+	if !strings.HasPrefix(text, "**News**: 2 new items") || !strings.Contains(text, "[Newer](https://example.com/2)") {
+		t.Fatalf("digest = %q", text)
+	}
+	if len(queries.digested) != 2 {
+		t.Fatalf("digested = %d, want 2", len(queries.digested))
+	}
+}
+
+func TestPollRecordsFetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	queries := &fakeFeedQueries{}
+	seedFeed(queries, server.URL)
+	svc := newTestService(queries)
+
+	svc.poll(context.Background(), testFeedID)
+
+	if queries.feed.LastError != "http status 502" {
+		t.Fatalf("last error = %q", queries.feed.LastError)
+	}
+	if !queries.feed.NextPollAt.Valid {
+		t.Fatal("failed poll must still schedule the next poll")
+	}
+}
+
+func TestSweepSendsScheduledDigest(t *testing.T) {
+	now := time.Date(2025, 6, 10, 9, 0, 30, 0, time.UTC)
+	queries := &fakeFeedQueries{}
+	seedFeed(queries, "https://example.com/feed.xml")
+	queries.feed.Title = "News"
+	queries.feed.DigestChannel = "telegram"
+	queries.feed.DigestTarget = "chat-1"
+	queries.feed.DigestPattern = "0 9 * * *"
+	queries.feed.NextDigestAt = pgtype.Timestamptz{Time: now.Add(-time.Minute), Valid: true}
+	queries.items = []sqlc.BotFeedItem{{
+		ID:     pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		FeedID: queries.feed.ID,
+		Guid:   "1",
+		Title:  "Item",
+		Link:   "https://example.com/1",
+	}}
+
+	channels := &fakeChannels{err: errors.New("channel offline")}
+	svc := newTestService(queries)
+	svc.channels = channels
+	svc.now = func() time.Time { return now }
+	svc.summarize = func(context.Context, string, string) (string, error) {
+		return "One thing happened.", nil
+	}
+
+	svc.sweep(context.Background())
+	if len(queries.digested) != 0 {
+		t.Fatal("items must stay undigested when delivery fails")
+	}
+	if want := time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC); !queries.feed.NextDigestAt.Time.Equal(want) {
+		t.Fatalf("next digest = %v, want %v", queries.feed.NextDigestAt.Time, want)
+	}
+
+	channels.err = nil
+	queries.feed.NextDigestAt = pgtype.Timestamptz{Time: now, Valid: true}
+	svc.sweep(context.Background())
+	if len(channels.sent) != 1 || channels.sent[0].Message.Text != "**News**: 1 new item\n\nOne thing happened." {
+		t.Fatalf("sent = %+v", channels.sent)
+	}
+	if channels.sent[0].Target != "chat-1" || len(queries.digested) != 1 {
+		t.Fatalf("sent = %+v, digested = %d", channels.sent[0], len(queries.digested))
+	}
+}
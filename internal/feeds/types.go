@@ -0,0 +1,70 @@
+package feeds
+
+import "time"
+
+// Feed is an RSS or Atom subscription of one bot. New items are ingested
+// into the bot's memory and/or a knowledge collection. When DigestChannel is
+// set, new items are also summarized and sent to DigestTarget on that
+// channel: on the DigestPattern cron schedule, or after every poll that
+// found new items when the pattern is empty.
+type Feed struct {
+	ID                  string     `json:"id"`
+	BotID               string     `json:"bot_id"`
+	URL                 string     `json:"url"`
+	Title               string     `json:"title"`
+	PollIntervalMinutes int        `json:"poll_interval_minutes"`
+	IngestMemory        bool       `json:"ingest_memory"`
+	CollectionID        string     `json:"collection_id,omitempty"`
+	DigestChannel       string     `json:"digest_channel,omitempty"`
+	DigestTarget        string     `json:"digest_target,omitempty"`
+	DigestPattern       string     `json:"digest_pattern,omitempty"`
+	Enabled             bool       `json:"enabled"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastPolledAt        *time.Time `json:"last_polled_at,omitempty"`
+	NextPollAt          *time.Time `json:"next_poll_at,omitempty"`
+	NextDigestAt        *time.Time `json:"next_digest_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// Item is one entry fetched from a feed. DigestedAt is set once the item was
+// part of a delivered digest.
+type Item struct {
+	ID          string     `json:"id"`
+	FeedID      string     `json:"feed_id"`
+	GUID        string     `json:"guid"`
+	Title       string     `json:"title"`
+	Link        string     `json:"link,omitempty"`
+	Summary     string     `json:"summary,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	DigestedAt  *time.Time `json:"digested_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateFeedRequest subscribes a bot to a feed. A zero poll interval uses
+// the default; Enabled defaults to true.
+type CreateFeedRequest struct {
+	URL                 string `json:"url"`
+	Title               string `json:"title,omitempty"`
+	PollIntervalMinutes int    `json:"poll_interval_minutes,omitempty"`
+	IngestMemory        bool   `json:"ingest_memory,omitempty"`
+	CollectionID        string `json:"collection_id,omitempty"`
+	DigestChannel       string `json:"digest_channel,omitempty"`
+	DigestTarget        string `json:"digest_target,omitempty"`
+	DigestPattern       string `json:"digest_pattern,omitempty"`
+	Enabled             *bool  `json:"enabled,omitempty"`
+}
+
+// UpdateFeedRequest changes a feed. An empty CollectionID stops ingesting
+// into a collection and an empty DigestChannel stops digests.
+type UpdateFeedRequest struct {
+	URL                 *string `json:"url,omitempty"`
+	Title               *string `json:"title,omitempty"`
+	PollIntervalMinutes *int    `json:"poll_interval_minutes,omitempty"`
+	IngestMemory        *bool   `json:"ingest_memory,omitempty"`
+	CollectionID        *string `json:"collection_id,omitempty"`
+	DigestChannel       *string `json:"digest_channel,omitempty"`
+	DigestTarget        *string `json:"digest_target,omitempty"`
+	DigestPattern       *string `json:"digest_pattern,omitempty"`
+	Enabled             *bool   `json:"enabled,omitempty"`
+}
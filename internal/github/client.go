@@ -0,0 +1,240 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apiTimeout    = 30 * time.Second
+	apiVersion    = "2022-11-28"
+	userAgent     = "Memoh-GitHub-App"
+	maxErrorBytes = 4 << 10
+	// tokenRefreshMargin renews installation tokens this long before GitHub
+	// expires them, so a token never lapses mid-sync.
+	tokenRefreshMargin = time.Minute
+)
+
+// apiError is a non-2xx response from the GitHub API.
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("github api: status %d", e.Status)
+	}
+	return fmt.Sprintf("github api: status %d: %s", e.Status, e.Message)
+}
+
+func isStatus(err error, status int) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Status == status
+}
+
+type installationToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// appClient calls the GitHub REST API as a GitHub App: with a short-lived
+// app JWT for app endpoints and with cached installation tokens for
+// repository endpoints.
+type appClient struct {
+	baseURL string
+	appID   int64
+	key     *rsa.PrivateKey
+	http    *http.Client
+	now     func() time.Time
+
+	mu     sync.Mutex
+	tokens map[int64]installationToken
+}
+
+func newAppClient(baseURL string, appID int64, key *rsa.PrivateKey) *appClient {
+	return &appClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		appID:   appID,
+		key:     key,
+		http:    &http.Client{Timeout: apiTimeout},
+		now:     time.Now,
+		tokens:  map[int64]installationToken{},
+	}
+}
+
+// appJWT signs the token that authenticates as the app itself. GitHub
+// rejects tokens issued in the future, so iat is backdated for clock skew.
+func (c *appClient) appJWT() (string, error) {
+	now := c.now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    strconv.FormatInt(c.appID, 10),
+		IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(9 * time.Minute)),
+	})
+	return token.SignedString(c.key)
+}
+
+func (c *appClient) installationToken(ctx context.Context, installationID int64) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.tokens[installationID]
+	c.mu.Unlock()
+	if ok && c.now().Add(tokenRefreshMargin).Before(cached.expiresAt) {
+		return cached.token, nil
+	}
+	appToken, err := c.appJWT()
+	if err != nil {
+		return "", fmt.Errorf("sign app jwt: %w", err)
+	}
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", installationID)
+	if err := c.do(ctx, http.MethodPost, path, "Bearer "+appToken, nil, &resp); err != nil {
+		return "", fmt.Errorf("create installation token: %w", err)
+	}
+	c.mu.Lock()
+	c.tokens[installationID] = installationToken{token: resp.Token, expiresAt: resp.ExpiresAt}
+	c.mu.Unlock()
+	return resp.Token, nil
+}
+
+// repoInstallation returns the installation of the app on a repository.
+func (c *appClient) repoInstallation(ctx context.Context, owner, name string) (int64, error) {
+	appToken, err := c.appJWT()
+	if err != nil {
+		return 0, fmt.Errorf("sign app jwt: %w", err)
+	}
+	var resp struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodGet, repoPath(owner, name)+"/installation", "Bearer "+appToken, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+type repository struct {
+	DefaultBranch string `json:"default_branch"`
+	HTMLURL       string `json:"html_url"`
+}
+
+type treeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Sha  string `json:"sha"`
+	Size int64  `json:"size"`
+}
+
+type tree struct {
+	Sha       string      `json:"sha"`
+	Tree      []treeEntry `json:"tree"`
+	Truncated bool        `json:"truncated"`
+}
+
+func (c *appClient) repository(ctx context.Context, installationID int64, owner, name string) (repository, error) {
+	var resp repository
+	err := c.installationDo(ctx, installationID, http.MethodGet, repoPath(owner, name), nil, &resp)
+	return resp, err
+}
+
+func (c *appClient) tree(ctx context.Context, installationID int64, owner, name, ref string) (tree, error) {
+	var resp tree
+	path := repoPath(owner, name) + "/git/trees/" + url.PathEscape(ref) + "?recursive=1"
+	err := c.installationDo(ctx, installationID, http.MethodGet, path, nil, &resp)
+	return resp, err
+}
+
+func (c *appClient) blob(ctx context.Context, installationID int64, owner, name, sha string) ([]byte, error) {
+	var resp struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	path := repoPath(owner, name) + "/git/blobs/" + url.PathEscape(sha)
+	if err := c.installationDo(ctx, installationID, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Encoding != "base64" {
+		return []byte(resp.Content), nil
+	}
+	// GitHub wraps base64 content at 60 columns.
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(resp.Content, "\n", ""))
+}
+
+func (c *appClient) createComment(ctx context.Context, installationID int64, owner, name string, number int, body string) (Comment, error) {
+	var resp struct {
+		ID      int64  `json:"id"`
+		HTMLURL string `json:"html_url"`
+	}
+	path := fmt.Sprintf("%s/issues/%d/comments", repoPath(owner, name), number)
+	if err := c.installationDo(ctx, installationID, http.MethodPost, path, map[string]string{"body": body}, &resp); err != nil {
+		return Comment{}, err
+	}
+	return Comment{ID: resp.ID, URL: resp.HTMLURL}, nil
+}
+
+func (c *appClient) installationDo(ctx context.Context, installationID int64, method, path string, body, out any) error {
+	token, err := c.installationToken(ctx, installationID)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, method, path, "token "+token, body, out)
+}
+
+func (c *appClient) do(ctx context.Context, method, path, authorization string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+		var payload struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(raw, &payload)
+		return &apiError{Status: resp.StatusCode, Message: payload.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func repoPath(owner, name string) string {
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+}
@@ -0,0 +1,657 @@
+// Package github integrates a GitHub App: it syncs repository docs into
+// knowledge collections, forwards issue and pull request webhooks to
+// subscribed bots, and lets bots comment on issues they are allowed to.
+package github
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/config"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+const (
+	maxDocsPaths = 20
+	// maxCommentLength is GitHub's limit on issue comment bodies.
+	maxCommentLength = 65536
+
+	queueSize     = 64
+	sweepInterval = time.Minute
+)
+
+var defaultDocsPaths = []string{"README.md", "docs"}
+
+var (
+	ErrNotConfigured       = errors.New("github app is not configured")
+	ErrRepoNotFound        = errors.New("github repository not found")
+	ErrRepoExists          = errors.New("github repository already added")
+	ErrInvalidRepo         = errors.New("invalid github repository")
+	ErrNotInstalled        = errors.New("github app is not installed on this repository")
+	ErrInvalidSubscription = errors.New("invalid github subscription")
+	ErrNotSubscribed       = errors.New("bot is not subscribed to this repository")
+	ErrCommentNotAllowed   = errors.New("bot is not allowed to comment on this repository")
+	ErrInvalidComment      = errors.New("invalid comment")
+	ErrInvalidSignature    = errors.New("invalid webhook signature")
+	ErrInvalidWebhookBody  = errors.New("invalid webhook payload")
+)
+
+type githubQueries interface {
+	CompleteGitHubRepoSync(ctx context.Context, arg sqlc.CompleteGitHubRepoSyncParams) error
+	CreateGitHubRepo(ctx context.Context, arg sqlc.CreateGitHubRepoParams) (sqlc.GithubRepo, error)
+	DeleteGitHubRepo(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteGitHubRepoDocument(ctx context.Context, arg sqlc.DeleteGitHubRepoDocumentParams) error
+	DeleteGitHubRepoSubscription(ctx context.Context, arg sqlc.DeleteGitHubRepoSubscriptionParams) (int64, error)
+	FailGitHubRepoSync(ctx context.Context, arg sqlc.FailGitHubRepoSyncParams) error
+	GetGitHubRepo(ctx context.Context, id pgtype.UUID) (sqlc.GithubRepo, error)
+	GetGitHubRepoByName(ctx context.Context, arg sqlc.GetGitHubRepoByNameParams) (sqlc.GithubRepo, error)
+	GetGitHubRepoSubscription(ctx context.Context, arg sqlc.GetGitHubRepoSubscriptionParams) (sqlc.GithubRepoSubscription, error)
+	ListGitHubBotSubscriptions(ctx context.Context, botID pgtype.UUID) ([]sqlc.ListGitHubBotSubscriptionsRow, error)
+	ListGitHubRepoDocuments(ctx context.Context, repoID pgtype.UUID) ([]sqlc.GithubRepoDocument, error)
+	ListGitHubRepoSubscriptions(ctx context.Context, repoID pgtype.UUID) ([]sqlc.GithubRepoSubscription, error)
+	ListGitHubRepos(ctx context.Context) ([]sqlc.GithubRepo, error)
+	ListPendingGitHubRepos(ctx context.Context) ([]sqlc.GithubRepo, error)
+	MarkGitHubRepoSyncPending(ctx context.Context, id pgtype.UUID) (sqlc.GithubRepo, error)
+	MarkGitHubRepoSyncRunning(ctx context.Context, id pgtype.UUID) (int64, error)
+	SetGitHubRepoInstallation(ctx context.Context, arg sqlc.SetGitHubRepoInstallationParams) error
+	UpdateGitHubRepo(ctx context.Context, arg sqlc.UpdateGitHubRepoParams) (sqlc.GithubRepo, error)
+	UpsertGitHubRepoDocument(ctx context.Context, arg sqlc.UpsertGitHubRepoDocumentParams) error
+	UpsertGitHubRepoSubscription(ctx context.Context, arg sqlc.UpsertGitHubRepoSubscriptionParams) (sqlc.GithubRepoSubscription, error)
+}
+
+// collectionStore is the part of the knowledge service repository docs are
+// synced into.
+type collectionStore interface {
+	GetCollection(ctx context.Context, id string) (knowledge.Collection, error)
+	AddDocument(ctx context.Context, collectionID string, req knowledge.AddDocumentRequest) (knowledge.Document, error)
+	DeleteDocument(ctx context.Context, collectionID, documentID string) error
+}
+
+// ChatTriggerer starts a bot turn with an inbound message, the way a new
+// email does.
+type ChatTriggerer interface {
+	TriggerBotChat(ctx context.Context, botID, content string) error
+}
+
+// Service manages GitHub repositories and bot subscriptions, handles the
+// app's webhooks, and runs the docs sync worker.
+type Service struct {
+	queries       dbstore.Queries
+	client        *appClient
+	webhookSecret []byte
+	collections   collectionStore
+	chat          ChatTriggerer
+	logger        *slog.Logger
+	now           func() time.Time
+	queue         chan string
+}
+
+// NewService creates the GitHub service. Without an app ID in cfg the
+// service still serves stored repositories, but adding repositories,
+// syncing, and commenting return ErrNotConfigured.
+func NewService(log *slog.Logger, queries dbstore.Queries, cfg config.GitHubConfig) (*Service, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+	s := &Service{
+		queries:       queries,
+		webhookSecret: []byte(strings.TrimSpace(cfg.WebhookSecret)),
+		logger:        log.With(slog.String("service", "github")),
+		now:           time.Now,
+		queue:         make(chan string, queueSize),
+	}
+	if !cfg.Enabled() {
+		return s, nil
+	}
+	key, err := loadPrivateKey(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	s.client = newAppClient(cfg.APIBaseURLOrDefault(), cfg.AppID, key)
+	return s, nil
+}
+
+func loadPrivateKey(keyPath string) (*rsa.PrivateKey, error) {
+	raw, err := os.ReadFile(keyPath) //nolint:gosec // key path comes from server config.
+	if err != nil {
+		return nil, fmt.Errorf("read github app private key: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse github app private key: %w", err)
+	}
+	return key, nil
+}
+
+// SetKnowledgeService enables syncing repository docs into collections.
+func (s *Service) SetKnowledgeService(svc *knowledge.Service) {
+	if svc != nil {
+		s.collections = svc
+	}
+}
+
+// SetChatTriggerer enables forwarding webhook events to subscribed bots.
+func (s *Service) SetChatTriggerer(triggerer ChatTriggerer) {
+	s.chat = triggerer
+}
+
+// Enabled reports whether a GitHub App is configured.
+func (s *Service) Enabled() bool {
+	return s != nil && s.client != nil
+}
+
+func (s *Service) store() (githubQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("github service not configured")
+	}
+	store, ok := s.queries.(githubQueries)
+	if !ok {
+		return nil, errors.New("github queries not supported by store")
+	}
+	return store, nil
+}
+
+// CreateRepo adds a repository the app is installed on. With a collection
+// its docs are synced right away.
+func (s *Service) CreateRepo(ctx context.Context, req CreateRepoRequest) (Repo, error) {
+	store, err := s.store()
+	if err != nil {
+		return Repo{}, err
+	}
+	if s.client == nil {
+		return Repo{}, ErrNotConfigured
+	}
+	owner, name, err := parseRepoName(req.Repo)
+	if err != nil {
+		return Repo{}, err
+	}
+	docsPaths, err := normalizeDocsPaths(req.DocsPaths)
+	if err != nil {
+		return Repo{}, err
+	}
+	collectionID, err := s.resolveCollection(ctx, req.CollectionID)
+	if err != nil {
+		return Repo{}, err
+	}
+	installationID, err := s.client.repoInstallation(ctx, owner, name)
+	if err != nil {
+		if isStatus(err, http.StatusNotFound) {
+			return Repo{}, ErrNotInstalled
+		}
+		return Repo{}, fmt.Errorf("find github app installation: %w", err)
+	}
+	status := SyncStatusIdle
+	if collectionID.Valid {
+		status = SyncStatusPending
+	}
+	row, err := store.CreateGitHubRepo(ctx, sqlc.CreateGitHubRepoParams{
+		Owner:          owner,
+		Name:           name,
+		InstallationID: installationID,
+		CollectionID:   collectionID,
+		DocsPaths:      docsPaths,
+		SyncStatus:     status,
+	})
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return Repo{}, ErrRepoExists
+		}
+		return Repo{}, fmt.Errorf("create github repository: %w", err)
+	}
+	repo := toRepo(row)
+	if status == SyncStatusPending {
+		s.enqueue(repo.ID)
+	}
+	return repo, nil
+}
+
+// ListRepos lists the repositories of the team.
+func (s *Service) ListRepos(ctx context.Context) ([]Repo, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListGitHubRepos(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list github repositories: %w", err)
+	}
+	items := make([]Repo, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toRepo(row))
+	}
+	return items, nil
+}
+
+// GetRepo returns one repository.
+func (s *Service) GetRepo(ctx context.Context, id string) (Repo, error) {
+	store, err := s.store()
+	if err != nil {
+		return Repo{}, err
+	}
+	row, err := getRepo(ctx, store, id)
+	if err != nil {
+		return Repo{}, err
+	}
+	return toRepo(row), nil
+}
+
+// UpdateRepo changes the collection or docs paths of a repository and
+// resyncs it. Moving to another collection removes the synced documents
+// from the old one.
+func (s *Service) UpdateRepo(ctx context.Context, id string, req UpdateRepoRequest) (Repo, error) {
+	store, err := s.store()
+	if err != nil {
+		return Repo{}, err
+	}
+	current, err := getRepo(ctx, store, id)
+	if err != nil {
+		return Repo{}, err
+	}
+	collectionID := current.CollectionID
+	if req.CollectionID != nil {
+		if collectionID, err = s.resolveCollection(ctx, *req.CollectionID); err != nil {
+			return Repo{}, err
+		}
+	}
+	docsPaths := current.DocsPaths
+	if req.DocsPaths != nil {
+		if docsPaths, err = normalizeDocsPaths(*req.DocsPaths); err != nil {
+			return Repo{}, err
+		}
+	}
+	collectionChanged := collectionID != current.CollectionID
+	if !collectionChanged && slices.Equal(docsPaths, current.DocsPaths) {
+		return toRepo(current), nil
+	}
+	if collectionChanged {
+		if err := s.removeDocuments(ctx, store, current); err != nil {
+			return Repo{}, err
+		}
+	}
+	// Clearing the tree sha makes the next sync compare every file again.
+	row, err := store.UpdateGitHubRepo(ctx, sqlc.UpdateGitHubRepoParams{
+		CollectionID: collectionID,
+		DocsPaths:    docsPaths,
+		DocsTreeSha:  "",
+		ID:           current.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Repo{}, ErrRepoNotFound
+		}
+		return Repo{}, fmt.Errorf("update github repository: %w", err)
+	}
+	if !row.CollectionID.Valid {
+		return toRepo(row), nil
+	}
+	if row, err = store.MarkGitHubRepoSyncPending(ctx, row.ID); err != nil {
+		return Repo{}, fmt.Errorf("queue github sync: %w", err)
+	}
+	repo := toRepo(row)
+	s.enqueue(repo.ID)
+	return repo, nil
+}
+
+// DeleteRepo removes a repository, its subscriptions, and the documents
+// synced from it.
+func (s *Service) DeleteRepo(ctx context.Context, id string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	current, err := getRepo(ctx, store, id)
+	if err != nil {
+		return err
+	}
+	if err := s.removeDocuments(ctx, store, current); err != nil {
+		return err
+	}
+	n, err := store.DeleteGitHubRepo(ctx, current.ID)
+	if err != nil {
+		return fmt.Errorf("delete github repository: %w", err)
+	}
+	if n == 0 {
+		return ErrRepoNotFound
+	}
+	return nil
+}
+
+// SyncRepo queues a docs sync of a repository.
+func (s *Service) SyncRepo(ctx context.Context, id string) (Repo, error) {
+	store, err := s.store()
+	if err != nil {
+		return Repo{}, err
+	}
+	if s.client == nil {
+		return Repo{}, ErrNotConfigured
+	}
+	current, err := getRepo(ctx, store, id)
+	if err != nil {
+		return Repo{}, err
+	}
+	if !current.CollectionID.Valid {
+		return Repo{}, fmt.Errorf("%w: set a collection to sync docs into", ErrInvalidRepo)
+	}
+	row, err := store.MarkGitHubRepoSyncPending(ctx, current.ID)
+	if err != nil {
+		return Repo{}, fmt.Errorf("queue github sync: %w", err)
+	}
+	repo := toRepo(row)
+	s.enqueue(repo.ID)
+	return repo, nil
+}
+
+// Subscribe subscribes a bot to a repository's events, or changes an
+// existing subscription.
+func (s *Service) Subscribe(ctx context.Context, botID, repoID string, req SubscribeRequest) (Subscription, error) {
+	store, err := s.store()
+	if err != nil {
+		return Subscription{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Subscription{}, err
+	}
+	repo, err := getRepo(ctx, store, repoID)
+	if err != nil {
+		return Subscription{}, err
+	}
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return Subscription{}, err
+	}
+	row, err := store.UpsertGitHubRepoSubscription(ctx, sqlc.UpsertGitHubRepoSubscriptionParams{
+		RepoID:     repo.ID,
+		BotID:      pgBotID,
+		Events:     events,
+		CanComment: req.CanComment,
+	})
+	if err != nil {
+		return Subscription{}, fmt.Errorf("subscribe to github repository: %w", err)
+	}
+	sub := toSubscription(row)
+	sub.Repo = repo.Owner + "/" + repo.Name
+	return sub, nil
+}
+
+// ListSubscriptions lists the repositories a bot is subscribed to.
+func (s *Service) ListSubscriptions(ctx context.Context, botID string) ([]Subscription, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListGitHubBotSubscriptions(ctx, pgBotID)
+	if err != nil {
+		return nil, fmt.Errorf("list github subscriptions: %w", err)
+	}
+	items := make([]Subscription, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, Subscription{
+			ID:         row.ID.String(),
+			RepoID:     row.RepoID.String(),
+			BotID:      row.BotID.String(),
+			Repo:       row.Owner + "/" + row.Name,
+			Events:     row.Events,
+			CanComment: row.CanComment,
+			CreatedAt:  row.CreatedAt.Time,
+			UpdatedAt:  row.UpdatedAt.Time,
+		})
+	}
+	return items, nil
+}
+
+// Unsubscribe stops forwarding a repository's events to a bot.
+func (s *Service) Unsubscribe(ctx context.Context, botID, repoID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	pgRepoID, err := db.ParseUUID(repoID)
+	if err != nil {
+		return ErrNotSubscribed
+	}
+	n, err := store.DeleteGitHubRepoSubscription(ctx, sqlc.DeleteGitHubRepoSubscriptionParams{RepoID: pgRepoID, BotID: pgBotID})
+	if err != nil {
+		return fmt.Errorf("unsubscribe from github repository: %w", err)
+	}
+	if n == 0 {
+		return ErrNotSubscribed
+	}
+	return nil
+}
+
+// CommentOnIssue posts a comment on an issue or pull request as the app.
+// The bot must be subscribed to the repository with commenting allowed.
+func (s *Service) CommentOnIssue(ctx context.Context, botID, repoName string, number int, body string) (Comment, error) {
+	store, err := s.store()
+	if err != nil {
+		return Comment{}, err
+	}
+	if s.client == nil {
+		return Comment{}, ErrNotConfigured
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Comment{}, err
+	}
+	owner, name, err := parseRepoName(repoName)
+	if err != nil {
+		return Comment{}, err
+	}
+	body = strings.TrimSpace(body)
+	switch {
+	case number <= 0:
+		return Comment{}, fmt.Errorf("%w: issue number must be positive", ErrInvalidComment)
+	case body == "":
+		return Comment{}, fmt.Errorf("%w: body is required", ErrInvalidComment)
+	case len(body) > maxCommentLength:
+		return Comment{}, fmt.Errorf("%w: body must be at most %d bytes", ErrInvalidComment, maxCommentLength)
+	}
+	repo, err := store.GetGitHubRepoByName(ctx, sqlc.GetGitHubRepoByNameParams{Owner: owner, Name: name})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Comment{}, ErrRepoNotFound
+		}
+		return Comment{}, fmt.Errorf("get github repository: %w", err)
+	}
+	sub, err := store.GetGitHubRepoSubscription(ctx, sqlc.GetGitHubRepoSubscriptionParams{RepoID: repo.ID, BotID: pgBotID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Comment{}, ErrNotSubscribed
+		}
+		return Comment{}, fmt.Errorf("get github subscription: %w", err)
+	}
+	if !sub.CanComment {
+		return Comment{}, ErrCommentNotAllowed
+	}
+	comment, err := s.client.createComment(ctx, repo.InstallationID, repo.Owner, repo.Name, number, body)
+	if err != nil {
+		return Comment{}, fmt.Errorf("comment on %s/%s#%d: %w", repo.Owner, repo.Name, number, err)
+	}
+	return comment, nil
+}
+
+func (s *Service) resolveCollection(ctx context.Context, collectionID string) (pgtype.UUID, error) {
+	collectionID = strings.TrimSpace(collectionID)
+	if collectionID == "" {
+		return pgtype.UUID{}, nil
+	}
+	if s.collections == nil {
+		return pgtype.UUID{}, fmt.Errorf("%w: knowledge collections are not available", ErrInvalidRepo)
+	}
+	collection, err := s.collections.GetCollection(ctx, collectionID)
+	if err != nil {
+		if errors.Is(err, knowledge.ErrCollectionNotFound) {
+			return pgtype.UUID{}, fmt.Errorf("%w: knowledge collection not found", ErrInvalidRepo)
+		}
+		return pgtype.UUID{}, err
+	}
+	return db.ParseUUID(collection.ID)
+}
+
+// removeDocuments deletes the documents synced from a repository along with
+// their mappings.
+func (s *Service) removeDocuments(ctx context.Context, store githubQueries, repo sqlc.GithubRepo) error {
+	docs, err := store.ListGitHubRepoDocuments(ctx, repo.ID)
+	if err != nil {
+		return fmt.Errorf("list github documents: %w", err)
+	}
+	for _, doc := range docs {
+		if s.collections != nil && repo.CollectionID.Valid {
+			err := s.collections.DeleteDocument(ctx, repo.CollectionID.String(), doc.DocumentID.String())
+			if err != nil && !errors.Is(err, knowledge.ErrDocumentNotFound) {
+				return fmt.Errorf("delete synced document %s: %w", doc.Path, err)
+			}
+		}
+		if err := store.DeleteGitHubRepoDocument(ctx, sqlc.DeleteGitHubRepoDocumentParams{RepoID: repo.ID, Path: doc.Path}); err != nil {
+			return fmt.Errorf("delete github document %s: %w", doc.Path, err)
+		}
+	}
+	return nil
+}
+
+func getRepo(ctx context.Context, store githubQueries, id string) (sqlc.GithubRepo, error) {
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return sqlc.GithubRepo{}, ErrRepoNotFound
+	}
+	row, err := store.GetGitHubRepo(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.GithubRepo{}, ErrRepoNotFound
+		}
+		return sqlc.GithubRepo{}, fmt.Errorf("get github repository: %w", err)
+	}
+	return row, nil
+}
+
+// parseRepoName accepts "owner/name" or a GitHub repository URL.
+func parseRepoName(raw string) (string, string, error) {
+	value := strings.TrimSpace(raw)
+	if i := strings.Index(value, "://"); i >= 0 {
+		value = value[i+3:]
+		if j := strings.Index(value, "/"); j >= 0 {
+			value = value[j+1:]
+		} else {
+			value = ""
+		}
+	}
+	value = strings.TrimSuffix(strings.Trim(value, "/"), ".git")
+	owner, name, ok := strings.Cut(value, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("%w: repo must be owner/name", ErrInvalidRepo)
+	}
+	return owner, name, nil
+}
+
+// normalizeDocsPaths cleans repository-relative paths. A path matches the
+// file itself or everything below it.
+func normalizeDocsPaths(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return slices.Clone(defaultDocsPaths), nil
+	}
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = strings.Trim(strings.TrimSpace(p), "/")
+		if p == "" {
+			continue
+		}
+		cleaned := path.Clean(p)
+		if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return nil, fmt.Errorf("%w: docs path %q leaves the repository", ErrInvalidRepo, p)
+		}
+		if !slices.Contains(out, cleaned) {
+			out = append(out, cleaned)
+		}
+	}
+	if len(out) == 0 {
+		return slices.Clone(defaultDocsPaths), nil
+	}
+	if len(out) > maxDocsPaths {
+		return nil, fmt.Errorf("%w: at most %d docs paths", ErrInvalidRepo, maxDocsPaths)
+	}
+	return out, nil
+}
+
+func normalizeEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return []string{EventIssues, EventPullRequest}, nil
+	}
+	out := make([]string, 0, len(events))
+	for _, event := range events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		switch event {
+		case EventIssues, EventPullRequest, EventIssueComment:
+		default:
+			return nil, fmt.Errorf("%w: unsupported event %q", ErrInvalidSubscription, event)
+		}
+		if !slices.Contains(out, event) {
+			out = append(out, event)
+		}
+	}
+	return out, nil
+}
+
+func timePtr(ts pgtype.Timestamptz) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+func toRepo(row sqlc.GithubRepo) Repo {
+	repo := Repo{
+		ID:             row.ID.String(),
+		Owner:          row.Owner,
+		Name:           row.Name,
+		FullName:       row.Owner + "/" + row.Name,
+		InstallationID: row.InstallationID,
+		DocsPaths:      row.DocsPaths,
+		SyncStatus:     row.SyncStatus,
+		LastError:      row.LastError,
+		SyncedAt:       timePtr(row.SyncedAt),
+		CreatedAt:      row.CreatedAt.Time,
+		UpdatedAt:      row.UpdatedAt.Time,
+	}
+	if row.CollectionID.Valid {
+		repo.CollectionID = row.CollectionID.String()
+	}
+	return repo
+}
+
+func toSubscription(row sqlc.GithubRepoSubscription) Subscription {
+	return Subscription{
+		ID:         row.ID.String(),
+		RepoID:     row.RepoID.String(),
+		BotID:      row.BotID.String(),
+		Events:     row.Events,
+		CanComment: row.CanComment,
+		CreatedAt:  row.CreatedAt.Time,
+		UpdatedAt:  row.UpdatedAt.Time,
+	}
+}
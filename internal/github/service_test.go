@@ -0,0 +1,459 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/config"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+const (
+	testRepoID       = "11111111-1111-1111-1111-111111111111"
+	testCollectionID = "22222222-2222-2222-2222-222222222222"
+	testBotID        = "33333333-3333-3333-3333-333333333333"
+	testOtherBotID   = "44444444-4444-4444-4444-444444444444"
+	testSecret       = "test-only-secret"
+)
+
+type fakeGitHubQueries struct {
+	dbstore.Queries
+
+	repo sqlc.GithubRepo
+	docs map[string]sqlc.GithubRepoDocument
+	subs []sqlc.GithubRepoSubscription
+}
+
+func newFakeQueries() *fakeGitHubQueries {
+	return &fakeGitHubQueries{
+		repo: sqlc.GithubRepo{
+			ID:             db.ParseUUIDOrEmpty(testRepoID),
+			Owner:          "acme",
+			Name:           "widgets",
+			InstallationID: 7,
+			CollectionID:   db.ParseUUIDOrEmpty(testCollectionID),
+			DocsPaths:      []string{"README.md", "docs"},
+			SyncStatus:     SyncStatusIdle,
+		},
+		docs: map[string]sqlc.GithubRepoDocument{},
+	}
+}
+
+func (f *fakeGitHubQueries) CompleteGitHubRepoSync(_ context.Context, arg sqlc.CompleteGitHubRepoSyncParams) error {
+	f.repo.SyncStatus = SyncStatusCompleted
+	f.repo.DocsTreeSha = arg.DocsTreeSha
+	f.repo.LastError = ""
+	return nil
+}
+
+func (*fakeGitHubQueries) CreateGitHubRepo(context.Context, sqlc.CreateGitHubRepoParams) (sqlc.GithubRepo, error) {
+	return sqlc.GithubRepo{}, errors.New("not implemented")
+}
+
+func (*fakeGitHubQueries) DeleteGitHubRepo(context.Context, pgtype.UUID) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeGitHubQueries) DeleteGitHubRepoDocument(_ context.Context, arg sqlc.DeleteGitHubRepoDocumentParams) error {
+	delete(f.docs, arg.Path)
+	return nil
+}
+
+func (*fakeGitHubQueries) DeleteGitHubRepoSubscription(context.Context, sqlc.DeleteGitHubRepoSubscriptionParams) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeGitHubQueries) FailGitHubRepoSync(_ context.Context, arg sqlc.FailGitHubRepoSyncParams) error {
+	f.repo.SyncStatus = SyncStatusFailed
+	f.repo.LastError = arg.LastError
+	return nil
+}
+
+func (f *fakeGitHubQueries) GetGitHubRepo(_ context.Context, id pgtype.UUID) (sqlc.GithubRepo, error) {
+	if f.repo.ID != id {
+		return sqlc.GithubRepo{}, pgx.ErrNoRows
+	}
+	return f.repo, nil
+}
+
+func (f *fakeGitHubQueries) GetGitHubRepoByName(_ context.Context, arg sqlc.GetGitHubRepoByNameParams) (sqlc.GithubRepo, error) {
+	if !strings.EqualFold(f.repo.Owner, arg.Owner) || !strings.EqualFold(f.repo.Name, arg.Name) {
+		return sqlc.GithubRepo{}, pgx.ErrNoRows
+	}
+	return f.repo, nil
+}
+
+func (f *fakeGitHubQueries) GetGitHubRepoSubscription(_ context.Context, arg sqlc.GetGitHubRepoSubscriptionParams) (sqlc.GithubRepoSubscription, error) {
+	for _, sub := range f.subs {
+		if sub.RepoID == arg.RepoID && sub.BotID == arg.BotID {
+			return sub, nil
+		}
+	}
+	return sqlc.GithubRepoSubscription{}, pgx.ErrNoRows
+}
+
+func (*fakeGitHubQueries) ListGitHubBotSubscriptions(context.Context, pgtype.UUID) ([]sqlc.ListGitHubBotSubscriptionsRow, error) {
+	return nil, nil
+}
+
+func (f *fakeGitHubQueries) ListGitHubRepoDocuments(context.Context, pgtype.UUID) ([]sqlc.GithubRepoDocument, error) {
+	out := make([]sqlc.GithubRepoDocument, 0, len(f.docs))
+	for _, doc := range f.docs {
+		out = append(out, doc)
+	}
+	return out, nil
+}
+
+func (f *fakeGitHubQueries) ListGitHubRepoSubscriptions(context.Context, pgtype.UUID) ([]sqlc.GithubRepoSubscription, error) {
+	return f.subs, nil
+}
+
+func (f *fakeGitHubQueries) ListGitHubRepos(context.Context) ([]sqlc.GithubRepo, error) {
+	return []sqlc.GithubRepo{f.repo}, nil
+}
+
+func (f *fakeGitHubQueries) ListPendingGitHubRepos(context.Context) ([]sqlc.GithubRepo, error) {
+	if f.repo.SyncStatus != SyncStatusPending {
+		return nil, nil
+	}
+	return []sqlc.GithubRepo{f.repo}, nil
+}
+
+func (f *fakeGitHubQueries) MarkGitHubRepoSyncPending(context.Context, pgtype.UUID) (sqlc.GithubRepo, error) {
+	f.repo.SyncStatus = SyncStatusPending
+	return f.repo, nil
+}
+
+func (f *fakeGitHubQueries) MarkGitHubRepoSyncRunning(context.Context, pgtype.UUID) (int64, error) {
+	if f.repo.SyncStatus != SyncStatusPending {
+		return 0, nil
+	}
+	f.repo.SyncStatus = SyncStatusRunning
+	return 1, nil
+}
+
+func (f *fakeGitHubQueries) SetGitHubRepoInstallation(_ context.Context, arg sqlc.SetGitHubRepoInstallationParams) error {
+	f.repo.InstallationID = arg.InstallationID
+	return nil
+}
+
+func (f *fakeGitHubQueries) UpdateGitHubRepo(_ context.Context, arg sqlc.UpdateGitHubRepoParams) (sqlc.GithubRepo, error) {
+	f.repo.CollectionID = arg.CollectionID
+	f.repo.DocsPaths = arg.DocsPaths
+	f.repo.DocsTreeSha = arg.DocsTreeSha
+	return f.repo, nil
+}
+
+func (f *fakeGitHubQueries) UpsertGitHubRepoDocument(_ context.Context, arg sqlc.UpsertGitHubRepoDocumentParams) error {
+	f.docs[arg.Path] = sqlc.GithubRepoDocument{RepoID: arg.RepoID, Path: arg.Path, BlobSha: arg.BlobSha, DocumentID: arg.DocumentID}
+	return nil
+}
+
+func (*fakeGitHubQueries) UpsertGitHubRepoSubscription(context.Context, sqlc.UpsertGitHubRepoSubscriptionParams) (sqlc.GithubRepoSubscription, error) {
+	return sqlc.GithubRepoSubscription{}, errors.New("not implemented")
+}
+
+type fakeCollections struct {
+	added   []knowledge.AddDocumentRequest
+	deleted []string
+	seq     byte
+}
+
+func (*fakeCollections) GetCollection(_ context.Context, id string) (knowledge.Collection, error) {
+	return knowledge.Collection{ID: id}, nil
+}
+
+func (f *fakeCollections) AddDocument(_ context.Context, _ string, req knowledge.AddDocumentRequest) (knowledge.Document, error) {
+	f.seq++
+	f.added = append(f.added, req)
+	id := pgtype.UUID{Valid: true}
+	id.Bytes[15] = f.seq
+	return knowledge.Document{ID: id.String()}, nil
+}
+
+func (f *fakeCollections) DeleteDocument(_ context.Context, _, documentID string) error {
+	f.deleted = append(f.deleted, documentID)
+	return nil
+}
+
+type fakeTriggerer struct {
+	mu    sync.Mutex
+	wg    sync.WaitGroup
+	calls map[string]string
+}
+
+func (f *fakeTriggerer) TriggerBotChat(_ context.Context, botID, content string) error {
+	defer f.wg.Done()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[botID] = content
+	return nil
+}
+
+// newTestService returns a service backed by queries whose GitHub API is
+// served by api.
+func newTestService(t *testing.T, queries *fakeGitHubQueries, api http.Handler) *Service {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyPath, pemBytes, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	svc, err := NewService(slog.New(slog.DiscardHandler), queries, config.GitHubConfig{
+		AppID:          1,
+		PrivateKeyPath: keyPath,
+		WebhookSecret:  testSecret,
+		APIBaseURL:     server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return svc
+}
+
+func tokenHandler(mux *http.ServeMux) {
+	mux.HandleFunc("POST /app/installations/7/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			http.Error(w, "app jwt required", http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      "installation-token",
+			"expires_at": time.Now().Add(time.Hour),
+		})
+	})
+}
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	svc := &Service{webhookSecret: []byte(testSecret)}
+	body := []byte(`{"action":"opened"}`)
+	if err := svc.VerifySignature(sign(body), body); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	for _, signature := range []string{"", "sha1=abc", sign([]byte("other"))} {
+		if err := svc.VerifySignature(signature, body); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("signature %q: err = %v", signature, err)
+		}
+	}
+	if err := (&Service{}).VerifySignature(sign(body), body); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("missing secret: err = %v", err)
+	}
+}
+
+func TestHandleWebhookNotifiesSubscribedBots(t *testing.T) {
+	queries := newFakeQueries()
+	queries.subs = []sqlc.GithubRepoSubscription{
+		{RepoID: queries.repo.ID, BotID: db.ParseUUIDOrEmpty(testBotID), Events: []string{EventIssues}, CanComment: true},
+		{RepoID: queries.repo.ID, BotID: db.ParseUUIDOrEmpty(testOtherBotID), Events: []string{EventPullRequest}},
+	}
+	triggerer := &fakeTriggerer{calls: map[string]string{}}
+	svc := &Service{queries: queries, chat: triggerer, logger: slog.New(slog.DiscardHandler)}
+
+	body := []byte(`{
+		"action": "opened",
+		"installation": {"id": 9},
+		"repository": {"name": "Widgets", "owner": {"login": "acme"}, "default_branch": "main"},
+		"sender": {"login": "octocat", "type": "User"},
+		"issue": {"number": 12, "title": "Crash on start", "body": "Stack trace here", "html_url": "https://github.com/acme/widgets/issues/12"}
+	}`)
+	triggerer.wg.Add(1)
+	if err := svc.HandleWebhook(context.Background(), EventIssues, body); err != nil {
+		t.Fatalf("HandleWebhook: %v", err)
+	}
+	triggerer.wg.Wait()
+
+	if len(triggerer.calls) != 1 {
+		t.Fatalf("calls = %v, want only the bot subscribed to issues", triggerer.calls)
+	}
+	content := triggerer.calls[testBotID]
+	for _, want := range []string{`[GitHub] acme/widgets: @octocat opened issue #12 "Crash on start"`, "issues/12", "Stack trace here", "comment_github_issue"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("content = %q, missing %q", content, want)
+		}
+	}
+	if queries.repo.InstallationID != 9 {
+		t.Fatalf("installation = %d, want the id from the delivery", queries.repo.InstallationID)
+	}
+
+	botComment := []byte(`{
+		"action": "created",
+		"repository": {"name": "widgets", "owner": {"login": "acme"}},
+		"sender": {"login": "memoh[bot]", "type": "Bot"},
+		"issue": {"number": 12, "title": "Crash on start"},
+		"comment": {"body": "Thanks!"}
+	}`)
+	if err := svc.HandleWebhook(context.Background(), EventIssueComment, botComment); err != nil {
+		t.Fatalf("HandleWebhook comment: %v", err)
+	}
+	if err := svc.HandleWebhook(context.Background(), EventIssues, []byte(`{"action":"opened","repository":{"name":"other","owner":{"login":"acme"}}}`)); err != nil {
+		t.Fatalf("unknown repository should be ignored: %v", err)
+	}
+}
+
+func TestSyncRepoReplacesChangedDocsAndRemovesDeleted(t *testing.T) {
+	queries := newFakeQueries()
+	queries.repo.SyncStatus = SyncStatusPending
+	unchangedID := db.ParseUUIDOrEmpty("55555555-5555-5555-5555-555555555555")
+	changedID := db.ParseUUIDOrEmpty("66666666-6666-6666-6666-666666666666")
+	removedID := db.ParseUUIDOrEmpty("77777777-7777-7777-7777-777777777777")
+	queries.docs = map[string]sqlc.GithubRepoDocument{
+		"README.md":        {Path: "README.md", BlobSha: "readme-1", DocumentID: unchangedID},
+		"docs/guide.md":    {Path: "docs/guide.md", BlobSha: "guide-1", DocumentID: changedID},
+		"docs/old-page.md": {Path: "docs/old-page.md", BlobSha: "old-1", DocumentID: removedID},
+	}
+
+	mux := http.NewServeMux()
+	tokenHandler(mux)
+	mux.HandleFunc("GET /repos/acme/widgets", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"default_branch":"main","html_url":"https://github.com/acme/widgets"}`))
+	})
+	mux.HandleFunc("GET /repos/acme/widgets/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token installation-token" {
+			http.Error(w, "installation token required", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"sha":"tree-2","tree":[
+			{"path":"README.md","type":"blob","sha":"readme-1","size":10},
+			{"path":"docs","type":"tree","sha":"docs-tree"},
+			{"path":"docs/guide.md","type":"blob","sha":"guide-2","size":10},
+			{"path":"docs/new.rst","type":"blob","sha":"new-1","size":10},
+			{"path":"docs/logo.png","type":"blob","sha":"logo-1","size":10},
+			{"path":"src/main.md","type":"blob","sha":"src-1","size":10}
+		]}`))
+	})
+	mux.HandleFunc("GET /repos/acme/widgets/git/blobs/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		content := base64.StdEncoding.EncodeToString([]byte("content of " + r.PathValue("sha")))
+		_ = json.NewEncoder(w).Encode(map[string]string{"content": content, "encoding": "base64"})
+	})
+	svc := newTestService(t, queries, mux)
+	collections := &fakeCollections{}
+	svc.collections = collections
+
+	svc.syncRepo(context.Background(), testRepoID)
+
+	if queries.repo.SyncStatus != SyncStatusCompleted || queries.repo.DocsTreeSha != "tree-2" {
+		t.Fatalf("repo = %+v", queries.repo)
+	}
+	if len(collections.added) != 2 {
+		t.Fatalf("added = %+v, want the changed and the new doc", collections.added)
+	}
+	guide := collections.added[0]
+	if guide.Title != "docs/guide.md" || guide.Content != "content of guide-2" ||
+		guide.SourceURL != "https://github.com/acme/widgets/blob/main/docs/guide.md" {
+		t.Fatalf("guide = %+v", guide)
+	}
+	deleted := strings.Join(collections.deleted, ",")
+	if !strings.Contains(deleted, changedID.String()) || !strings.Contains(deleted, removedID.String()) || strings.Contains(deleted, unchangedID.String()) {
+		t.Fatalf("deleted = %v", collections.deleted)
+	}
+	if _, ok := queries.docs["docs/old-page.md"]; ok {
+		t.Fatal("removed doc mapping should be deleted")
+	}
+	if queries.docs["docs/guide.md"].BlobSha != "guide-2" || queries.docs["docs/new.rst"].BlobSha != "new-1" {
+		t.Fatalf("docs = %+v", queries.docs)
+	}
+
+	// An unchanged tree is not walked again.
+	queries.repo.SyncStatus = SyncStatusPending
+	svc.syncRepo(context.Background(), testRepoID)
+	if len(collections.added) != 2 || queries.repo.SyncStatus != SyncStatusCompleted {
+		t.Fatalf("second sync added %d docs, status %s", len(collections.added), queries.repo.SyncStatus)
+	}
+}
+
+func TestCommentOnIssueRequiresPermission(t *testing.T) {
+	queries := newFakeQueries()
+	queries.subs = []sqlc.GithubRepoSubscription{
+		{RepoID: queries.repo.ID, BotID: db.ParseUUIDOrEmpty(testBotID), CanComment: true},
+		{RepoID: queries.repo.ID, BotID: db.ParseUUIDOrEmpty(testOtherBotID)},
+	}
+	var commentBody string
+	mux := http.NewServeMux()
+	tokenHandler(mux)
+	mux.HandleFunc("POST /repos/acme/widgets/issues/12/comments", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Body string `json:"body"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		commentBody = req.Body
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":99,"html_url":"https://github.com/acme/widgets/issues/12#issuecomment-99"}`))
+	})
+	svc := newTestService(t, queries, mux)
+	ctx := context.Background()
+
+	comment, err := svc.CommentOnIssue(ctx, testBotID, "acme/widgets", 12, " Looking into it. ")
+	if err != nil {
+		t.Fatalf("CommentOnIssue: %v", err)
+	}
+	if comment.ID != 99 || commentBody != "Looking into it." {
+		t.Fatalf("comment = %+v, body = %q", comment, commentBody)
+	}
+	if _, err := svc.CommentOnIssue(ctx, testOtherBotID, "acme/widgets", 12, "hi"); !errors.Is(err, ErrCommentNotAllowed) {
+		t.Fatalf("bot without permission: err = %v", err)
+	}
+	if _, err := svc.CommentOnIssue(ctx, "88888888-8888-8888-8888-888888888888", "acme/widgets", 12, "hi"); !errors.Is(err, ErrNotSubscribed) {
+		t.Fatalf("unsubscribed bot: err = %v", err)
+	}
+	if _, err := svc.CommentOnIssue(ctx, testBotID, "acme/unknown", 12, "hi"); !errors.Is(err, ErrRepoNotFound) {
+		t.Fatalf("unknown repo: err = %v", err)
+	}
+	if _, err := svc.CommentOnIssue(ctx, testBotID, "acme/widgets", 12, " "); !errors.Is(err, ErrInvalidComment) {
+		t.Fatalf("empty body: err = %v", err)
+	}
+}
+
+func TestParseRepoNameAndDocsPaths(t *testing.T) {
+	for _, raw := range []string{"acme/widgets", " https://github.com/acme/widgets.git ", "acme/widgets/"} {
+		owner, name, err := parseRepoName(raw)
+		if err != nil || owner != "acme" || name != "widgets" {
+			t.Fatalf("parseRepoName(%q) = %q, %q, %v", raw, owner, name, err)
+		}
+	}
+	if _, _, err := parseRepoName("acme"); !errors.Is(err, ErrInvalidRepo) {
+		t.Fatalf("bare owner: err = %v", err)
+	}
+	paths, err := normalizeDocsPaths([]string{" /docs/ ", "docs", "guides/./intro.md"})
+	if err != nil || strings.Join(paths, ",") != "docs,guides/intro.md" {
+		t.Fatalf("paths = %v, err = %v", paths, err)
+	}
+	if _, err := normalizeDocsPaths([]string{"../secrets"}); !errors.Is(err, ErrInvalidRepo) {
+		t.Fatalf("escaping path: err = %v", err)
+	}
+}
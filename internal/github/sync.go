@@ -0,0 +1,279 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+const (
+	// maxDocBytes skips generated or vendored files that are too large to be
+	// useful as documentation.
+	maxDocBytes = 1 << 20
+	// maxDocsPerRepo bounds one sync so a broad docs path cannot flood the
+	// collection.
+	maxDocsPerRepo = 500
+)
+
+var docExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+	".mdx":      true,
+	".rst":      true,
+	".txt":      true,
+	".adoc":     true,
+}
+
+// Run syncs repository docs until done is closed. It sweeps pending
+// repositories on start and then every minute; repositories queued by
+// CreateRepo, UpdateRepo, SyncRepo, and push webhooks are synced as they
+// arrive. Repositories are synced one at a time.
+func (s *Service) Run(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	s.sweep(ctx)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.syncRepo(ctx, id)
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *Service) enqueue(repoID string) {
+	select {
+	case s.queue <- repoID:
+	default:
+		// The repository stays pending in the database; the next sweep syncs it.
+		s.logger.Warn("github sync queue full, deferring sync", slog.String("repo_id", repoID))
+	}
+}
+
+func (s *Service) sweep(ctx context.Context) {
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("github sync sweep failed", slog.Any("error", err))
+		return
+	}
+	pending, err := store.ListPendingGitHubRepos(ctx)
+	if err != nil {
+		s.logger.Warn("list pending github repositories failed", slog.Any("error", err))
+		return
+	}
+	for _, row := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		s.syncRepo(ctx, row.ID.String())
+	}
+}
+
+// syncRepo mirrors the docs of a pending repository into its collection.
+// Files whose blob is unchanged are skipped, changed files replace their
+// document, and files that disappeared are removed. A sync that fails part
+// way keeps the old tree sha so the next sync retries every file.
+func (s *Service) syncRepo(ctx context.Context, repoID string) {
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("github sync failed", slog.String("repo_id", repoID), slog.Any("error", err))
+		return
+	}
+	pgRepoID, err := db.ParseUUID(repoID)
+	if err != nil {
+		return
+	}
+	// Claiming the repository makes a sync queued twice run once.
+	n, err := store.MarkGitHubRepoSyncRunning(ctx, pgRepoID)
+	if err != nil {
+		s.logger.Warn("claim github sync failed", slog.String("repo_id", repoID), slog.Any("error", err))
+		return
+	}
+	if n == 0 {
+		return
+	}
+	repo, err := store.GetGitHubRepo(ctx, pgRepoID)
+	if err != nil {
+		s.logger.Warn("load github repository failed", slog.String("repo_id", repoID), slog.Any("error", err))
+		return
+	}
+	treeSha, err := s.syncDocs(ctx, store, repo)
+	if err != nil {
+		s.logger.Warn("github sync failed",
+			slog.String("repo", repo.Owner+"/"+repo.Name),
+			slog.Any("error", err))
+		if failErr := store.FailGitHubRepoSync(context.WithoutCancel(ctx), sqlc.FailGitHubRepoSyncParams{
+			LastError: err.Error(),
+			ID:        repo.ID,
+		}); failErr != nil {
+			s.logger.Warn("record github sync failure failed", slog.String("repo_id", repoID), slog.Any("error", failErr))
+		}
+		return
+	}
+	if err := store.CompleteGitHubRepoSync(ctx, sqlc.CompleteGitHubRepoSyncParams{DocsTreeSha: treeSha, ID: repo.ID}); err != nil {
+		s.logger.Warn("record github sync failed", slog.String("repo_id", repoID), slog.Any("error", err))
+	}
+}
+
+// syncDocs returns the tree sha the collection now matches.
+func (s *Service) syncDocs(ctx context.Context, store githubQueries, repo sqlc.GithubRepo) (string, error) {
+	if s.client == nil {
+		return "", ErrNotConfigured
+	}
+	if s.collections == nil || !repo.CollectionID.Valid {
+		return repo.DocsTreeSha, nil
+	}
+	collectionID := repo.CollectionID.String()
+	info, err := s.client.repository(ctx, repo.InstallationID, repo.Owner, repo.Name)
+	if err != nil {
+		return "", fmt.Errorf("get repository: %w", err)
+	}
+	root, err := s.client.tree(ctx, repo.InstallationID, repo.Owner, repo.Name, info.DefaultBranch)
+	if err != nil {
+		return "", fmt.Errorf("list files: %w", err)
+	}
+	if root.Sha != "" && root.Sha == repo.DocsTreeSha {
+		return root.Sha, nil
+	}
+	if root.Truncated {
+		s.logger.Warn("github tree truncated, syncing the files listed",
+			slog.String("repo", repo.Owner+"/"+repo.Name))
+	}
+	wanted := docEntries(root.Tree, repo.DocsPaths)
+
+	existing, err := store.ListGitHubRepoDocuments(ctx, repo.ID)
+	if err != nil {
+		return "", fmt.Errorf("list synced documents: %w", err)
+	}
+	synced := make(map[string]sqlc.GithubRepoDocument, len(existing))
+	for _, doc := range existing {
+		synced[doc.Path] = doc
+	}
+
+	var errs []error
+	for _, entry := range wanted {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		prev, ok := synced[entry.Path]
+		delete(synced, entry.Path)
+		if ok && prev.BlobSha == entry.Sha {
+			continue
+		}
+		if err := s.syncFile(ctx, store, repo, collectionID, info.HTMLURL, info.DefaultBranch, entry, prev, ok); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Path, err))
+		}
+	}
+	for _, doc := range synced {
+		err := s.collections.DeleteDocument(ctx, collectionID, doc.DocumentID.String())
+		if err != nil && !errors.Is(err, knowledge.ErrDocumentNotFound) {
+			errs = append(errs, fmt.Errorf("%s: remove: %w", doc.Path, err))
+			continue
+		}
+		if err := store.DeleteGitHubRepoDocument(ctx, sqlc.DeleteGitHubRepoDocumentParams{RepoID: repo.ID, Path: doc.Path}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: remove: %w", doc.Path, err))
+		}
+	}
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return root.Sha, nil
+}
+
+func (s *Service) syncFile(ctx context.Context, store githubQueries, repo sqlc.GithubRepo, collectionID, htmlURL, branch string, entry treeEntry, prev sqlc.GithubRepoDocument, replace bool) error {
+	content, err := s.client.blob(ctx, repo.InstallationID, repo.Owner, repo.Name, entry.Sha)
+	if err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+	if !utf8.Valid(content) || strings.TrimSpace(string(content)) == "" {
+		return nil
+	}
+	doc, err := s.collections.AddDocument(ctx, collectionID, knowledge.AddDocumentRequest{
+		Title:     entry.Path,
+		Content:   string(content),
+		SourceURL: blobURL(htmlURL, branch, entry.Path),
+	})
+	if err != nil {
+		return fmt.Errorf("add document: %w", err)
+	}
+	pgDocID, err := db.ParseUUID(doc.ID)
+	if err != nil {
+		return err
+	}
+	if err := store.UpsertGitHubRepoDocument(ctx, sqlc.UpsertGitHubRepoDocumentParams{
+		RepoID:     repo.ID,
+		Path:       entry.Path,
+		BlobSha:    entry.Sha,
+		DocumentID: pgDocID,
+	}); err != nil {
+		return fmt.Errorf("record document: %w", err)
+	}
+	if replace {
+		err := s.collections.DeleteDocument(ctx, collectionID, prev.DocumentID.String())
+		if err != nil && !errors.Is(err, knowledge.ErrDocumentNotFound) {
+			return fmt.Errorf("remove previous version: %w", err)
+		}
+	}
+	return nil
+}
+
+// docEntries picks the documentation files under docsPaths from a tree.
+func docEntries(entries []treeEntry, docsPaths []string) []treeEntry {
+	var out []treeEntry
+	for _, entry := range entries {
+		if entry.Type != "blob" || entry.Size > maxDocBytes {
+			continue
+		}
+		if !docExtensions[strings.ToLower(path.Ext(entry.Path))] {
+			continue
+		}
+		if !underDocsPaths(entry.Path, docsPaths) {
+			continue
+		}
+		out = append(out, entry)
+		if len(out) == maxDocsPerRepo {
+			break
+		}
+	}
+	return out
+}
+
+func underDocsPaths(filePath string, docsPaths []string) bool {
+	for _, p := range docsPaths {
+		if filePath == p || strings.HasPrefix(filePath, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func blobURL(htmlURL, branch, filePath string) string {
+	if htmlURL == "" {
+		return ""
+	}
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return htmlURL + "/blob/" + url.PathEscape(branch) + "/" + strings.Join(segments, "/")
+}
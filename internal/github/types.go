@@ -0,0 +1,79 @@
+package github
+
+import "time"
+
+// Event names a bot can subscribe to. They match the X-GitHub-Event header
+// of the webhook deliveries that produce them.
+const (
+	EventIssues       = "issues"
+	EventPullRequest  = "pull_request"
+	EventIssueComment = "issue_comment"
+)
+
+// Sync states of a repository's docs.
+const (
+	SyncStatusIdle      = "idle"
+	SyncStatusPending   = "pending"
+	SyncStatusRunning   = "running"
+	SyncStatusCompleted = "completed"
+	SyncStatusFailed    = "failed"
+)
+
+// Repo is a GitHub repository the app is installed on. When CollectionID is
+// set, the files under DocsPaths on the default branch are kept in sync with
+// that knowledge collection.
+type Repo struct {
+	ID             string     `json:"id"`
+	Owner          string     `json:"owner"`
+	Name           string     `json:"name"`
+	FullName       string     `json:"full_name"`
+	InstallationID int64      `json:"installation_id"`
+	CollectionID   string     `json:"collection_id,omitempty"`
+	DocsPaths      []string   `json:"docs_paths"`
+	SyncStatus     string     `json:"sync_status"`
+	LastError      string     `json:"last_error,omitempty"`
+	SyncedAt       *time.Time `json:"synced_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Subscription forwards a repository's events to a bot. CanComment allows
+// the bot to comment on the repository's issues and pull requests.
+type Subscription struct {
+	ID         string    `json:"id"`
+	RepoID     string    `json:"repo_id"`
+	BotID      string    `json:"bot_id"`
+	Repo       string    `json:"repo,omitempty"`
+	Events     []string  `json:"events"`
+	CanComment bool      `json:"can_comment"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Comment is a comment the app posted on an issue or pull request.
+type Comment struct {
+	ID  int64  `json:"id"`
+	URL string `json:"url"`
+}
+
+// CreateRepoRequest adds a repository, given as "owner/name" or its GitHub
+// URL. Empty DocsPaths sync README.md and the docs directory.
+type CreateRepoRequest struct {
+	Repo         string   `json:"repo"`
+	CollectionID string   `json:"collection_id,omitempty"`
+	DocsPaths    []string `json:"docs_paths,omitempty"`
+}
+
+// UpdateRepoRequest changes where and what a repository syncs. An empty
+// CollectionID stops syncing; documents already synced are removed.
+type UpdateRepoRequest struct {
+	CollectionID *string   `json:"collection_id,omitempty"`
+	DocsPaths    *[]string `json:"docs_paths,omitempty"`
+}
+
+// SubscribeRequest subscribes a bot to a repository. Empty Events subscribe
+// to issues and pull requests.
+type SubscribeRequest struct {
+	Events     []string `json:"events,omitempty"`
+	CanComment bool     `json:"can_comment"`
+}
@@ -0,0 +1,238 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+// maxEventBodyRunes bounds the issue, pull request, or comment text quoted
+// in a bot notification.
+const maxEventBodyRunes = 2000
+
+type webhookUser struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+type webhookIssue struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	Merged      bool      `json:"merged"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+type webhookPayload struct {
+	Action       string `json:"action"`
+	Ref          string `json:"ref"`
+	Installation *struct {
+		ID int64 `json:"id"`
+	} `json:"installation"`
+	Repository struct {
+		Name          string      `json:"name"`
+		Owner         webhookUser `json:"owner"`
+		DefaultBranch string      `json:"default_branch"`
+	} `json:"repository"`
+	Sender      webhookUser   `json:"sender"`
+	Issue       *webhookIssue `json:"issue"`
+	PullRequest *webhookIssue `json:"pull_request"`
+	Comment     *struct {
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	} `json:"comment"`
+}
+
+// VerifySignature checks the X-Hub-Signature-256 header of a webhook
+// delivery against the configured secret.
+func (s *Service) VerifySignature(signature string, body []byte) error {
+	if len(s.webhookSecret) == 0 {
+		return ErrNotConfigured
+	}
+	hexSum, ok := strings.CutPrefix(strings.TrimSpace(signature), "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, s.webhookSecret)
+	_, _ = mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// HandleWebhook processes a verified webhook delivery. Deliveries for
+// repositories that were not added are ignored. Pushes to the default branch
+// queue a docs sync; issue, pull request, and comment events are forwarded
+// to the bots subscribed to them.
+func (s *Service) HandleWebhook(ctx context.Context, event string, body []byte) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidWebhookBody, err.Error())
+	}
+	if event == "ping" || payload.Repository.Name == "" {
+		return nil
+	}
+	repo, err := store.GetGitHubRepoByName(ctx, sqlc.GetGitHubRepoByNameParams{
+		Owner: payload.Repository.Owner.Login,
+		Name:  payload.Repository.Name,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("get github repository: %w", err)
+	}
+	// The app may have been reinstalled since the repository was added.
+	if payload.Installation != nil && payload.Installation.ID != 0 && payload.Installation.ID != repo.InstallationID {
+		if err := store.SetGitHubRepoInstallation(ctx, sqlc.SetGitHubRepoInstallationParams{
+			InstallationID: payload.Installation.ID,
+			ID:             repo.ID,
+		}); err != nil {
+			s.logger.Warn("update github installation failed", slog.String("repo_id", repo.ID.String()), slog.Any("error", err))
+		}
+	}
+
+	if event == "push" {
+		if repo.CollectionID.Valid && payload.Ref == "refs/heads/"+payload.Repository.DefaultBranch {
+			row, err := store.MarkGitHubRepoSyncPending(ctx, repo.ID)
+			if err != nil {
+				return fmt.Errorf("queue github sync: %w", err)
+			}
+			s.enqueue(row.ID.String())
+		}
+		return nil
+	}
+
+	message := formatEvent(repo.Owner+"/"+repo.Name, event, payload)
+	if message == "" {
+		return nil
+	}
+	subs, err := store.ListGitHubRepoSubscriptions(ctx, repo.ID)
+	if err != nil {
+		return fmt.Errorf("list github subscriptions: %w", err)
+	}
+	s.notify(ctx, event, message, subs)
+	return nil
+}
+
+// notify starts a turn for each bot subscribed to event. Turns run in the
+// background so the delivery is acknowledged within GitHub's timeout.
+func (s *Service) notify(ctx context.Context, event, message string, subs []sqlc.GithubRepoSubscription) {
+	if s.chat == nil {
+		return
+	}
+	for _, sub := range subs {
+		if !slices.Contains(sub.Events, event) {
+			continue
+		}
+		content := message
+		if sub.CanComment {
+			content += "\n\nYou can reply on GitHub with the comment_github_issue tool."
+		}
+		botID := sub.BotID.String()
+		go func() {
+			if err := s.chat.TriggerBotChat(context.WithoutCancel(ctx), botID, content); err != nil {
+				s.logger.Error("failed to trigger bot chat for github event",
+					slog.String("bot_id", botID),
+					slog.String("event", event),
+					slog.Any("error", err))
+			}
+		}()
+	}
+}
+
+// formatEvent renders the notification for an event, or "" for actions bots
+// are not told about.
+func formatEvent(repo, event string, payload webhookPayload) string {
+	actor := payload.Sender.Login
+	var (
+		summary string
+		item    *webhookIssue
+		body    string
+		link    string
+	)
+	switch event {
+	case EventIssues:
+		item = payload.Issue
+		switch payload.Action {
+		case "opened", "closed", "reopened":
+			summary = "@" + actor + " " + payload.Action + " issue"
+		default:
+			return ""
+		}
+		if item != nil {
+			body, link = item.Body, item.HTMLURL
+		}
+	case EventPullRequest:
+		item = payload.PullRequest
+		if item == nil {
+			return ""
+		}
+		switch payload.Action {
+		case "opened", "reopened":
+			summary = "@" + actor + " " + payload.Action + " pull request"
+			body = item.Body
+		case "ready_for_review":
+			summary = "@" + actor + " requested review on pull request"
+		case "closed":
+			if item.Merged {
+				summary = "@" + actor + " merged pull request"
+			} else {
+				summary = "@" + actor + " closed pull request"
+			}
+		default:
+			return ""
+		}
+		link = item.HTMLURL
+	case EventIssueComment:
+		// Skip comments from apps, including this one, so bots do not answer
+		// each other in a loop.
+		if payload.Action != "created" || payload.Comment == nil || strings.EqualFold(payload.Sender.Type, "Bot") {
+			return ""
+		}
+		item = payload.Issue
+		kind := "issue"
+		if item != nil && item.PullRequest != nil {
+			kind = "pull request"
+		}
+		summary = "@" + actor + " commented on " + kind
+		body, link = payload.Comment.Body, payload.Comment.HTMLURL
+	default:
+		return ""
+	}
+	if item == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[GitHub] %s: %s #%d %q", repo, summary, item.Number, item.Title)
+	if link != "" {
+		b.WriteString("\n" + link)
+	}
+	if body = strings.TrimSpace(body); body != "" {
+		b.WriteString("\n\n" + textutil.TruncateRunesWithSuffix(body, maxEventBodyRunes, "…"))
+	}
+	return b.String()
+}
@@ -537,11 +537,11 @@ func (h *ACPRuntimeHandler) resolveCurrentUserPermissions(c echo.Context, channe
 	if h.botService == nil || h.accountService == nil {
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "bot services not configured")
 	}
-	isAdmin, err := h.accountService.IsAdmin(c.Request().Context(), channelIdentityID)
+	manageAll, err := managesAllBots(c.Request().Context(), h.accountService, channelIdentityID)
 	if err != nil {
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	perms, err := h.botService.ResolveUserPermissions(c.Request().Context(), botID, channelIdentityID, isAdmin)
+	perms, err := h.botService.ResolveUserPermissions(c.Request().Context(), botID, channelIdentityID, manageAll)
 	if err != nil {
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/audit"
+	"github.com/memohai/memoh/internal/auth"
+	"github.com/memohai/memoh/internal/policy"
+)
+
+// AuditHandler serves the audit log of administrative actions.
+type AuditHandler struct {
+	service        *audit.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+type AuditLogsResponse struct {
+	Items []audit.Log `json:"items"`
+}
+
+func NewAuditHandler(log *slog.Logger, service *audit.Service, accountService *accounts.Service) *AuditHandler {
+	return &AuditHandler{
+		service:        service,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "audit")),
+	}
+}
+
+func (h *AuditHandler) Register(e *echo.Echo) {
+	e.GET("/audit", h.List)
+}
+
+// List godoc
+// @Summary List audit log entries (requires audit.view)
+// @Description Administrative actions with actor, client IP and changed fields, newest first. Secret values in diffs are redacted
+// @Tags audit
+// @Produce json
+// @Param action query string false "Only entries with this action, e.g. bot.transferred"
+// @Param target_type query string false "Only entries on this target type"
+// @Param target_id query string false "Only entries on this target"
+// @Param actor_user_id query string false "Only entries by this user"
+// @Param since query string false "Only entries at or after this RFC 3339 time"
+// @Param before query string false "Only entries before this RFC 3339 time"
+// @Param limit query int false "Maximum items (default 50, max 500)"
+// @Success 200 {object} AuditLogsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /audit [get].
+func (h *AuditHandler) List(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionAuditView); err != nil {
+		return err
+	}
+	filter := audit.Filter{
+		Action:      strings.TrimSpace(c.QueryParam("action")),
+		TargetType:  strings.TrimSpace(c.QueryParam("target_type")),
+		TargetID:    strings.TrimSpace(c.QueryParam("target_id")),
+		ActorUserID: strings.TrimSpace(c.QueryParam("actor_user_id")),
+	}
+	var err error
+	if filter.Since, err = parseAuditTime(c.QueryParam("since")); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid since: "+err.Error())
+	}
+	if filter.Before, err = parseAuditTime(c.QueryParam("before")); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid before: "+err.Error())
+	}
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		filter.Limit = limit
+	}
+	items, err := h.service.List(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, audit.ErrInvalidFilter) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, AuditLogsResponse{Items: items})
+}
+
+func parseAuditTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+// recordAudit logs entry on behalf of the requesting user and client IP.
+// It runs after the action succeeded and never fails the request.
+func recordAudit(c echo.Context, service *audit.Service, entry audit.Entry) {
+	if service == nil {
+		return
+	}
+	if entry.ActorUserID == "" {
+		entry.ActorUserID, _ = auth.UserIDFromContext(c)
+	}
+	entry.ActorIP = c.RealIP()
+	service.Log(c.Request().Context(), entry)
+}
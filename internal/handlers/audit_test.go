@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/audit"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/providers"
+)
+
+const auditTestUserID = "33333333-3333-3333-3333-333333333333"
+
+type recordingAuditQueries struct {
+	dbstore.Queries
+	created []sqlc.CreateAuditLogParams
+}
+
+func (q *recordingAuditQueries) CreateAuditLog(_ context.Context, arg sqlc.CreateAuditLogParams) (sqlc.AuditLog, error) {
+	q.created = append(q.created, arg)
+	return sqlc.AuditLog{}, nil
+}
+
+func (*recordingAuditQueries) ListAuditLogs(context.Context, sqlc.ListAuditLogsParams) ([]sqlc.AuditLog, error) {
+	return nil, nil
+}
+
+func newAuditTestContext() echo.Context {
+	req := httptest.NewRequest(http.MethodPut, "/", nil)
+	req.Header.Set(echo.HeaderXRealIP, "203.0.113.7")
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	c.Set("user", &jwt.Token{Valid: true, Claims: jwt.MapClaims{"sub": auditTestUserID}})
+	return c
+}
+
+func TestRecordAuditFillsActorAndIP(t *testing.T) {
+	t.Parallel()
+	queries := &recordingAuditQueries{}
+	recordAudit(newAuditTestContext(), audit.NewService(slog.Default(), queries), audit.Entry{
+		Action:     audit.ActionMemoryDeletedAll,
+		TargetType: audit.TargetBot,
+		TargetID:   "bot-1",
+	})
+	if len(queries.created) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(queries.created))
+	}
+	got := queries.created[0]
+	if got.ActorUserID.String() != auditTestUserID || got.ActorIp != "203.0.113.7" {
+		t.Fatalf("actor = %s/%q, want %s/203.0.113.7", got.ActorUserID.String(), got.ActorIp, auditTestUserID)
+	}
+
+	recordAudit(newAuditTestContext(), nil, audit.Entry{Action: audit.ActionMemoryDeletedAll, TargetType: audit.TargetBot})
+}
+
+func TestProvidersRecordKeyChangeSkipsEchoedMaskedKey(t *testing.T) {
+	t.Parallel()
+	queries := &recordingAuditQueries{}
+	h := &ProvidersHandler{}
+	h.SetAuditLog(audit.NewService(slog.Default(), queries))
+	provider := providers.GetResponse{ID: "p1", Name: "openai", ClientType: "openai-responses"}
+	masked := map[string]any{"api_key": "sk-proj-********"}
+
+	h.recordKeyChange(newAuditTestContext(), provider, masked, map[string]any{"api_key": "sk-proj-********", "base_url": "https://x"})
+	h.recordKeyChange(newAuditTestContext(), provider, masked, map[string]any{"base_url": "https://y"})
+	if len(queries.created) != 0 {
+		t.Fatalf("recorded %d entries for unchanged key, want 0", len(queries.created))
+	}
+
+	h.recordKeyChange(newAuditTestContext(), provider, masked, map[string]any{"api_key": "sk-proj-rotated"})
+	if len(queries.created) != 1 {
+		t.Fatalf("recorded %d entries for rotated key, want 1", len(queries.created))
+	}
+	got := queries.created[0]
+	if got.Action != audit.ActionProviderKeyChanged || got.TargetID != "p1" {
+		t.Fatalf("entry = %s %s, want provider key change on p1", got.Action, got.TargetID)
+	}
+	var diff map[string]audit.Change
+	if err := json.Unmarshal(got.Diff, &diff); err != nil {
+		t.Fatalf("unmarshal diff: %v", err)
+	}
+	if change := diff["api_key"]; change.From != audit.Redacted || change.To != audit.Redacted {
+		t.Fatalf("diff = %+v, want redacted api_key change", diff)
+	}
+}
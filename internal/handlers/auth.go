@@ -11,10 +11,13 @@ import (
 
 	"github.com/memohai/memoh/internal/accounts"
 	"github.com/memohai/memoh/internal/auth"
+	"github.com/memohai/memoh/internal/auth/refresh"
+	"github.com/memohai/memoh/internal/policy"
 )
 
 type AuthHandler struct {
 	accountService *accounts.Service
+	refreshTokens  *refresh.Service
 	jwtSecret      string
 	expiresIn      time.Duration
 	logger         *slog.Logger
@@ -34,6 +37,9 @@ type LoginResponse struct {
 	DisplayName string `json:"display_name"`
 	Username    string `json:"username"`
 	Timezone    string `json:"timezone,omitempty"`
+	// RefreshToken mints new access tokens through /auth/refresh.
+	RefreshToken     string `json:"refresh_token,omitempty"` //nolint:gosec // intentional: refresh token is the purpose of this response field
+	RefreshExpiresAt string `json:"refresh_expires_at,omitempty"`
 }
 
 func NewAuthHandler(log *slog.Logger, accountService *accounts.Service, jwtSecret string, expiresIn time.Duration) *AuthHandler {
@@ -45,14 +51,21 @@ func NewAuthHandler(log *slog.Logger, accountService *accounts.Service, jwtSecre
 	}
 }
 
+// SetRefreshTokenService enables refresh tokens at login, refresh and logout.
+func (h *AuthHandler) SetRefreshTokenService(service *refresh.Service) {
+	h.refreshTokens = service
+}
+
 func (h *AuthHandler) Register(e *echo.Echo) {
 	e.POST("/auth/login", h.Login)
 	e.POST("/auth/refresh", h.Refresh)
+	e.POST("/auth/logout", h.Logout)
+	e.DELETE("/users/:id/refresh-tokens", h.RevokeUserTokens)
 }
 
 // Login godoc
 // @Summary Login
-// @Description Validate user credentials and issue a JWT
+// @Description Validate user credentials and issue a JWT and a refresh token
 // @Tags auth
 // @Param payload body LoginRequest true "Login request"
 // @Success 200 {object} LoginResponse
@@ -95,7 +108,7 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, LoginResponse{
+	resp := LoginResponse{
 		AccessToken: token,
 		TokenType:   "Bearer",
 		ExpiresAt:   expiresAt.Format(time.RFC3339),
@@ -104,21 +117,40 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		Role:        account.Role,
 		DisplayName: account.DisplayName,
 		Timezone:    account.Timezone,
-	})
+	}
+	if h.refreshTokens != nil {
+		refreshToken, err := h.refreshTokens.Issue(c.Request().Context(), account.ID)
+		if err != nil {
+			h.logger.Error("issue refresh token failed", slog.String("user_id", account.ID), slog.Any("error", err))
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		resp.RefreshToken = refreshToken.Value
+		resp.RefreshExpiresAt = refreshToken.ExpiresAt.Format(time.RFC3339)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"` //nolint:gosec // intentional: JSON request field carrying a user-supplied credential
 }
 
 type RefreshResponse struct {
 	AccessToken string `json:"access_token"` //nolint:gosec // intentional: JWT is the purpose of this response field
 	TokenType   string `json:"token_type"`
 	ExpiresAt   string `json:"expires_at"`
+	// RefreshToken replaces the one presented; the old one is revoked.
+	RefreshToken     string `json:"refresh_token,omitempty"` //nolint:gosec // intentional: refresh token is the purpose of this response field
+	RefreshExpiresAt string `json:"refresh_expires_at,omitempty"`
 }
 
 // Refresh godoc
 // @Summary Refresh Token
-// @Description Issue a new JWT using the existing claims with updated expiration
+// @Description With refresh_token in the body, rotate it and issue a new JWT with it, even after the previous JWT expired. A rotated refresh token presented again revokes its whole session. Without a body, issue a new JWT from the still valid bearer JWT with an updated expiration
 // @Tags auth
 // @Security BearerAuth
+// @Param payload body RefreshRequest false "Refresh request"
 // @Success 200 {object} RefreshResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/refresh [post].
@@ -126,7 +158,28 @@ func (h *AuthHandler) Refresh(c echo.Context) error {
 	if strings.TrimSpace(h.jwtSecret) == "" {
 		return echo.NewHTTPError(http.StatusInternalServerError, "jwt secret not configured")
 	}
+	var req RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if strings.TrimSpace(req.RefreshToken) != "" {
+		return h.rotate(c, req.RefreshToken)
+	}
 
+	// /auth/refresh skips JWTMiddleware so that expired JWTs can reach the
+	// refresh token path; renewing a JWT needs the same checks here.
+	if err := auth.ParseRequestToken(c, h.jwtSecret); err != nil {
+		return err
+	}
+	if _, err := auth.ChatTokenFromContext(c); err != nil {
+		userID, err := auth.UserIDFromContext(c)
+		if err != nil {
+			return err
+		}
+		if err := h.validateSession(c, userID); err != nil {
+			return err
+		}
+	}
 	token, expiresAt, err := auth.RefreshTokenFromContext(c, h.jwtSecret, h.expiresIn)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
@@ -138,3 +191,113 @@ func (h *AuthHandler) Refresh(c echo.Context) error {
 		ExpiresAt:   expiresAt.Format(time.RFC3339),
 	})
 }
+
+func (h *AuthHandler) rotate(c echo.Context, value string) error {
+	if h.refreshTokens == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "refresh tokens not configured")
+	}
+	if h.expiresIn <= 0 {
+		return echo.NewHTTPError(http.StatusInternalServerError, "jwt expiry not configured")
+	}
+	ctx := c.Request().Context()
+	refreshToken, err := h.refreshTokens.Rotate(ctx, value)
+	if err != nil {
+		if errors.Is(err, refresh.ErrInvalidToken) || errors.Is(err, refresh.ErrTokenReused) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if err := h.validateSession(c, refreshToken.UserID); err != nil {
+		if revokeErr := h.refreshTokens.Revoke(ctx, refreshToken.Value); revokeErr != nil {
+			h.logger.Warn("revoke refresh token of inactive user failed", slog.String("user_id", refreshToken.UserID), slog.Any("error", revokeErr))
+		}
+		return err
+	}
+	token, expiresAt, err := auth.GenerateToken(refreshToken.UserID, h.jwtSecret, h.expiresIn)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, RefreshResponse{
+		AccessToken:      token,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt.Format(time.RFC3339),
+		RefreshToken:     refreshToken.Value,
+		RefreshExpiresAt: refreshToken.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` //nolint:gosec // intentional: JSON request field carrying a user-supplied credential
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Revoke the refresh token and every token rotated from it. Unknown or already revoked tokens are accepted. The current JWT stays valid until it expires
+// @Tags auth
+// @Param payload body LogoutRequest true "Logout request"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/logout [post].
+func (h *AuthHandler) Logout(c echo.Context) error {
+	if h.refreshTokens == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "refresh tokens not configured")
+	}
+	var req LogoutRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "refresh_token is required")
+	}
+	if err := h.refreshTokens.Revoke(c.Request().Context(), req.RefreshToken); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+type RevokeRefreshTokensResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// RevokeUserTokens godoc
+// @Summary Revoke a user's refresh tokens (requires users.manage)
+// @Description Signs the user out of every session once their current JWTs expire
+// @Tags auth
+// @Param id path string true "User ID"
+// @Success 200 {object} RevokeRefreshTokensResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/refresh-tokens [delete].
+func (h *AuthHandler) RevokeUserTokens(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionUsersManage); err != nil {
+		return err
+	}
+	if h.refreshTokens == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "refresh tokens not configured")
+	}
+	userID := strings.TrimSpace(c.Param("id"))
+	n, err := h.refreshTokens.RevokeUser(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, refresh.ErrInvalidUser) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		h.logger.Error("revoke refresh tokens failed", slog.String("user_id", userID), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, RevokeRefreshTokensResponse{Revoked: n})
+}
+
+func (h *AuthHandler) validateSession(c echo.Context, userID string) error {
+	if h.accountService == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "user service not configured")
+	}
+	if err := h.accountService.ValidateSession(c.Request().Context(), userID); err != nil {
+		if errors.Is(err, accounts.ErrInactiveAccount) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "user session is no longer active")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
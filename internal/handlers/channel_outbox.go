@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/channel/outbox"
+	"github.com/memohai/memoh/internal/policy"
+)
+
+// ChannelOutboxHandler lets operators inspect outbound channel messages
+// queued after a failed delivery and requeue dead-lettered ones.
+type ChannelOutboxHandler struct {
+	service        *outbox.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+type ChannelOutboxResponse struct {
+	Items []outbox.Entry `json:"items"`
+}
+
+func NewChannelOutboxHandler(log *slog.Logger, service *outbox.Service, accountService *accounts.Service) *ChannelOutboxHandler {
+	return &ChannelOutboxHandler{
+		service:        service,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "channel_outbox")),
+	}
+}
+
+func (h *ChannelOutboxHandler) Register(e *echo.Echo) {
+	e.GET("/channels/outbox", h.List)
+	e.POST("/channels/outbox/:id/requeue", h.Requeue)
+}
+
+// List godoc
+// @Summary List queued outbound channel messages (requires operations.manage)
+// @Description Outbound messages whose delivery failed on a transient platform error, newest first. Pending entries are retried with exponential backoff; dead entries ran out of attempts
+// @Tags channel
+// @Produce json
+// @Param status query string false "dead (default), pending or sent"
+// @Param bot_id query string false "Only entries of this bot"
+// @Param limit query int false "Maximum items (default 50, max 500)"
+// @Success 200 {object} ChannelOutboxResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /channels/outbox [get].
+func (h *ChannelOutboxHandler) List(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage); err != nil {
+		return err
+	}
+	filter := outbox.ListFilter{
+		BotID:  strings.TrimSpace(c.QueryParam("bot_id")),
+		Status: strings.TrimSpace(c.QueryParam("status")),
+	}
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		filter.Limit = limit
+	}
+	items, err := h.service.List(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, outbox.ErrInvalidStatus) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ChannelOutboxResponse{Items: items})
+}
+
+// Requeue godoc
+// @Summary Requeue a queued outbound message (requires operations.manage)
+// @Description Makes a pending or dead entry due now with a fresh set of attempts; the channel retry worker sends it on its next poll
+// @Tags channel
+// @Produce json
+// @Param id path string true "Outbox entry ID"
+// @Success 200 {object} outbox.Entry
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /channels/outbox/{id}/requeue [post].
+func (h *ChannelOutboxHandler) Requeue(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	entry, err := h.service.Requeue(c.Request().Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, outbox.ErrEntryNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, outbox.ErrAlreadySent):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		h.logger.Error("requeue outbox entry failed", slog.String("outbox_id", id), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, entry)
+}
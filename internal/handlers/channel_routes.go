@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel/route"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/persona"
+)
+
+// ChannelRoutesHandler exposes a bot's channel routes for housekeeping.
+type ChannelRoutesHandler struct {
+	routeService   *route.DBService
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+type ChannelRoutesResponse struct {
+	Items []route.Route `json:"items"`
+}
+
+func NewChannelRoutesHandler(log *slog.Logger, routeService *route.DBService, botService *bots.Service, accountService *accounts.Service) *ChannelRoutesHandler {
+	return &ChannelRoutesHandler{
+		routeService:   routeService,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "channel_routes")),
+	}
+}
+
+func (h *ChannelRoutesHandler) Register(e *echo.Echo) {
+	e.GET("/bots/:bot_id/channel-routes/inactive", h.ListInactive)
+	e.PUT("/bots/:bot_id/channel-routes/:route_id/persona", h.SetPersona)
+}
+
+// ListInactive godoc
+// @Summary List a bot's inactive channel routes
+// @Description Routes of groups and channels the bot was removed from, most recently deactivated first. Inactive routes are pruned after channel.inactive_route_retention_days
+// @Tags channel
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} ChannelRoutesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/channel-routes/inactive [get].
+func (h *ChannelRoutesHandler) ListInactive(c echo.Context) error {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccess(c.Request().Context(), h.botService, h.accountService, userID, botID)
+	if err != nil {
+		return err
+	}
+	items, err := h.routeService.ListInactive(c.Request().Context(), bot.ID)
+	if err != nil {
+		h.logger.Error("list inactive routes failed", slog.String("bot_id", bot.ID), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, ChannelRoutesResponse{Items: items})
+}
+
+// SetPersona godoc
+// @Summary Override a channel route's persona
+// @Description Layer persona fields over the bot's persona for one conversation. Unset fields inherit from the bot; an empty object removes the override
+// @Tags channel
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param route_id path string true "Route ID"
+// @Param payload body persona.Persona true "Persona override"
+// @Success 200 {object} route.Route
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/channel-routes/{route_id}/persona [put].
+func (h *ChannelRoutesHandler) SetPersona(c echo.Context) error {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	routeID := strings.TrimSpace(c.Param("route_id"))
+	if botID == "" || routeID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id and route id are required")
+	}
+	if _, err := db.ParseUUID(routeID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid route id")
+	}
+	bot, err := AuthorizeBotAccess(c.Request().Context(), h.botService, h.accountService, userID, botID)
+	if err != nil {
+		return err
+	}
+	var req persona.Persona
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := persona.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	ctx := c.Request().Context()
+	item, err := h.routeService.GetByID(ctx, routeID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "route not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if item.BotID != bot.ID {
+		return echo.NewHTTPError(http.StatusNotFound, "route not found")
+	}
+	metadata := make(map[string]any, len(item.Metadata)+1)
+	maps.Copy(metadata, item.Metadata)
+	if override := persona.ToMetadata(req); len(override) > 0 {
+		metadata[persona.RouteMetadataKey] = override
+	} else {
+		delete(metadata, persona.RouteMetadataKey)
+	}
+	if err := h.routeService.UpdateMetadata(ctx, item.ID, metadata); err != nil {
+		h.logger.Error("update route persona failed", slog.String("route_id", item.ID), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	item.Metadata = metadata
+	return c.JSON(http.StatusOK, item)
+}
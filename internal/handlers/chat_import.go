@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/chatimport"
+)
+
+// maxChatImportBytes bounds uploaded chat exports. Exports with media are
+// archives; only the chat text file is uploaded.
+const maxChatImportBytes = 64 << 20
+
+type ChatImportHandler struct {
+	service        *chatimport.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewChatImportHandler(log *slog.Logger, service *chatimport.Service, botService *bots.Service, accountService *accounts.Service) *ChatImportHandler {
+	return &ChatImportHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "chat_import")),
+	}
+}
+
+func (h *ChatImportHandler) Register(e *echo.Echo) {
+	e.POST("/bots/:bot_id/chat-import", h.Import)
+}
+
+// Import godoc
+// @Summary Import chat history from a messaging app export
+// @Description Backfills the messages of a Telegram Desktop single-chat export (result.json) or a WhatsApp chat export (.txt) into the route of that conversation, optionally running memory extraction over them. WhatsApp exports carry no chat ID, so conversation_id is required for them. Importing the same export twice stores its messages twice
+// @Tags bots
+// @Accept multipart/form-data
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param file formData file true "Chat export file"
+// @Param format formData string false "telegram or whatsapp; inferred from the file extension when omitted"
+// @Param conversation_id formData string false "Platform conversation ID the history belongs to"
+// @Param timezone formData string false "IANA time zone of WhatsApp timestamps, default UTC"
+// @Param extract_memory formData bool false "Run memory extraction over the imported messages"
+// @Success 200 {object} chatimport.Result
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/chat-import [post].
+func (h *ChatImportHandler) Import(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+	if file.Size > maxChatImportBytes {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "export file is too large")
+	}
+	format := strings.TrimSpace(c.FormValue("format"))
+	if format == "" {
+		format = chatImportFormatFromName(file.Filename)
+	}
+	if format == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "format is required")
+	}
+	loc := time.UTC
+	if tz := strings.TrimSpace(c.FormValue("timezone")); tz != "" {
+		loc, err = time.LoadLocation(tz)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid timezone")
+		}
+	}
+	extractMemory := false
+	if raw := strings.TrimSpace(c.FormValue("extract_memory")); raw != "" {
+		extractMemory, err = strconv.ParseBool(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid extract_memory")
+		}
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to open uploaded file")
+	}
+	defer func() { _ = src.Close() }()
+	data, err := io.ReadAll(io.LimitReader(src, maxChatImportBytes))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to read uploaded file")
+	}
+
+	export, err := chatimport.Parse(format, data, loc)
+	if err != nil {
+		return chatImportHTTPError(err)
+	}
+	result, err := h.service.Import(c.Request().Context(), botID, export, chatimport.Options{
+		ConversationID: strings.TrimSpace(c.FormValue("conversation_id")),
+		ExtractMemory:  extractMemory,
+	})
+	if err != nil {
+		h.logger.Warn("chat import failed", slog.String("bot_id", botID), slog.Int("imported", result.Imported), slog.Any("error", err))
+		return chatImportHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *ChatImportHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func chatImportFormatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return chatimport.FormatTelegram
+	case ".txt":
+		return chatimport.FormatWhatsApp
+	default:
+		return ""
+	}
+}
+
+func chatImportHTTPError(err error) error {
+	switch {
+	case errors.Is(err, chatimport.ErrUnsupportedFormat),
+		errors.Is(err, chatimport.ErrInvalidExport),
+		errors.Is(err, chatimport.ErrEmptyExport),
+		errors.Is(err, chatimport.ErrTooManyMessages),
+		errors.Is(err, chatimport.ErrConversationRequired):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel/flows"
+)
+
+type ConversationFlowsHandler struct {
+	service        *flows.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewConversationFlowsHandler(log *slog.Logger, service *flows.Service, botService *bots.Service, accountService *accounts.Service) *ConversationFlowsHandler {
+	return &ConversationFlowsHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "conversation_flows")),
+	}
+}
+
+func (h *ConversationFlowsHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/flows")
+	group.GET("", h.Get)
+	group.PUT("", h.Replace)
+}
+
+// Get godoc
+// @Summary Get a bot's conversation flows
+// @Description Flows are guided question sequences contacts start with /flow start <name> in private chats
+// @Tags flows
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} flows.Config
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/flows [get].
+func (h *ConversationFlowsHandler) Get(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	cfg, err := h.service.Get(c.Request().Context(), botID)
+	if err != nil {
+		return conversationFlowsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, cfg)
+}
+
+// Replace godoc
+// @Summary Replace a bot's conversation flows
+// @Description Each step validates its answer into a named slot. When a flow completes, the filled-in form is handed to the conversation so the bot can act on it. Runs of removed flows are dropped
+// @Tags flows
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param request body flows.Config true "Conversation flows"
+// @Success 200 {object} flows.Config
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/flows [put].
+func (h *ConversationFlowsHandler) Replace(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req flows.Config
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	cfg, err := h.service.Replace(c.Request().Context(), botID, req)
+	if err != nil {
+		return conversationFlowsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, cfg)
+}
+
+func (h *ConversationFlowsHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func conversationFlowsHTTPError(err error) error {
+	if errors.Is(err, flows.ErrInvalidFlow) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
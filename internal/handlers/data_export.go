@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/dataexport"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/policy"
+)
+
+// DataExportHandler serves data-subject export bundles: requesting one,
+// polling its status and downloading it through a signed link.
+type DataExportHandler struct {
+	exports        *dataexport.Service
+	signer         *dataexport.Signer
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+// NewDataExportHandler constructs a DataExportHandler. Download links are
+// signed with signingSecret.
+func NewDataExportHandler(log *slog.Logger, exportService *dataexport.Service, accountService *accounts.Service, signingSecret string) *DataExportHandler {
+	return &DataExportHandler{
+		exports:        exportService,
+		signer:         dataexport.NewSigner(signingSecret),
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "data_export")),
+	}
+}
+
+func (h *DataExportHandler) Register(e *echo.Echo) {
+	e.POST("/users/:id/data-export", h.ExportUser)
+	e.POST("/identities/:id/data-export", h.ExportIdentity)
+	e.GET("/data-exports/:id", h.GetExport)
+	e.GET("/data-exports/:id/download", h.Download)
+}
+
+// ExportUser godoc
+// @Summary Request a data export for a user
+// @Description Queue a zip bundle of the user's messages, memories, media and profile. Users may export themselves; data.manage may export anyone
+// @Tags users
+// @Param id path string true "User ID"
+// @Success 202 {object} dataexport.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/data-export [post].
+func (h *DataExportHandler) ExportUser(c echo.Context) error {
+	actorID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	userID := strings.TrimSpace(c.Param("id"))
+	if _, err := db.ParseUUID(userID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user id")
+	}
+	if userID != actorID {
+		if err := requireUserPermission(c, h.accountService, actorID, policy.PermissionDataManage); err != nil {
+			return err
+		}
+	}
+	return h.request(c, actorID, dataexport.SubjectUser, userID)
+}
+
+// ExportIdentity godoc
+// @Summary Request a data export for a channel identity (requires data.manage)
+// @Description Queue a zip bundle of the identity's messages, memories, media and contact record
+// @Tags identities
+// @Param id path string true "Channel Identity ID"
+// @Success 202 {object} dataexport.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /identities/{id}/data-export [post].
+func (h *DataExportHandler) ExportIdentity(c echo.Context) error {
+	actorID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	if err := requireUserPermission(c, h.accountService, actorID, policy.PermissionDataManage); err != nil {
+		return err
+	}
+	identityID := strings.TrimSpace(c.Param("id"))
+	if _, err := db.ParseUUID(identityID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid channel identity id")
+	}
+	return h.request(c, actorID, dataexport.SubjectChannelIdentity, identityID)
+}
+
+// GetExport godoc
+// @Summary Get a data export job
+// @Description Returns the job status and, once completed, a short-lived signed download URL
+// @Tags users
+// @Param id path string true "Export job ID"
+// @Success 200 {object} dataexport.Job
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /data-exports/{id} [get].
+func (h *DataExportHandler) GetExport(c echo.Context) error {
+	actorID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	job, err := h.exports.Get(c.Request().Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		if errors.Is(err, dataexport.ErrJobNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if job.RequestedByUserID != actorID {
+		if err := requireUserPermission(c, h.accountService, actorID, policy.PermissionDataManage); err != nil {
+			return err
+		}
+	}
+	if url, ok := h.signer.SignedDownloadURL(job, time.Now().UTC()); ok {
+		job.DownloadURL = url
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// Download godoc
+// @Summary Download a data export bundle
+// @Description Authorized by the signed URL returned from the job status endpoint
+// @Tags users
+// @Param id path string true "Export job ID"
+// @Param exp query string true "Expiry"
+// @Param sig query string true "Signature"
+// @Produce application/zip
+// @Success 200 {file} binary
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /data-exports/{id}/download [get].
+func (h *DataExportHandler) Download(c echo.Context) error {
+	req := c.Request()
+	if !h.signer.Validate(req.URL.EscapedPath(), req.URL.Query(), time.Now().UTC()) {
+		return echo.NewHTTPError(http.StatusForbidden, "invalid download signature")
+	}
+	f, job, err := h.exports.OpenBundle(req.Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		if errors.Is(err, dataexport.ErrJobNotFound) || errors.Is(err, dataexport.ErrBundleNotReady) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	defer func() { _ = f.Close() }()
+
+	filename := "memoh-data-export-" + job.ID + ".zip"
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	if info, statErr := f.Stat(); statErr == nil {
+		c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(info.Size(), 10))
+	}
+	return c.Stream(http.StatusOK, "application/zip", f)
+}
+
+func (h *DataExportHandler) request(c echo.Context, actorID, subjectType, subjectID string) error {
+	job, err := h.exports.Request(c.Request().Context(), actorID, subjectType, subjectID)
+	if err != nil {
+		if errors.Is(err, dataexport.ErrSubjectNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		h.logger.Error("request data export failed", slog.String("subject_type", subjectType), slog.String("subject_id", subjectID), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
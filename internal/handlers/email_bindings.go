@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -74,6 +75,9 @@ func (h *EmailBindingsHandler) Create(c echo.Context) error {
 	}
 	resp, err := h.service.CreateBinding(c.Request().Context(), botID, req)
 	if err != nil {
+		if errors.Is(err, email.ErrInvalidBindingConfig) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 	// Refresh provider connections after binding change
@@ -145,6 +149,9 @@ func (h *EmailBindingsHandler) Update(c echo.Context) error {
 	}
 	resp, err := h.service.UpdateBinding(c.Request().Context(), id, req)
 	if err != nil {
+		if errors.Is(err, email.ErrInvalidBindingConfig) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 	_ = h.manager.RefreshProvider(c.Request().Context(), resp.EmailProviderID)
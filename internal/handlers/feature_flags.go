@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/featureflags"
+	"github.com/memohai/memoh/internal/policy"
+)
+
+// FeatureFlagsHandler lets operators roll risky features out per deployment
+// or per bot.
+type FeatureFlagsHandler struct {
+	service        *featureflags.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+type FeatureFlagsResponse struct {
+	Items []featureflags.State `json:"items"`
+}
+
+type SetFeatureFlagRequest struct {
+	Enabled bool `json:"enabled"`
+	// BotID scopes the override to one bot; empty sets it deployment-wide.
+	BotID string `json:"bot_id,omitempty"`
+}
+
+func NewFeatureFlagsHandler(log *slog.Logger, service *featureflags.Service, accountService *accounts.Service) *FeatureFlagsHandler {
+	return &FeatureFlagsHandler{
+		service:        service,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "feature_flags")),
+	}
+}
+
+func (h *FeatureFlagsHandler) Register(e *echo.Echo) {
+	e.GET("/feature-flags", h.List)
+	e.PUT("/feature-flags/:key", h.Set)
+	e.DELETE("/feature-flags/:key", h.Clear)
+}
+
+// List godoc
+// @Summary List feature flags (requires operations.manage)
+// @Description Declared feature flags with their defaults, deployment-wide and per-bot overrides
+// @Tags feature-flags
+// @Produce json
+// @Success 200 {object} FeatureFlagsResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /feature-flags [get].
+func (h *FeatureFlagsHandler) List(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage); err != nil {
+		return err
+	}
+	items, err := h.service.List(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, FeatureFlagsResponse{Items: items})
+}
+
+// Set godoc
+// @Summary Override a feature flag (requires operations.manage)
+// @Description Turns the flag on or off for one bot, or deployment-wide when bot_id is empty. Bot overrides win over the deployment override
+// @Tags feature-flags
+// @Accept json
+// @Produce json
+// @Param key path string true "Flag key"
+// @Param payload body SetFeatureFlagRequest true "Override"
+// @Success 200 {object} featureflags.State
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /feature-flags/{key} [put].
+func (h *FeatureFlagsHandler) Set(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage); err != nil {
+		return err
+	}
+	var req SetFeatureFlagRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	key := featureflags.Key(strings.TrimSpace(c.Param("key")))
+	state, err := h.service.Set(c.Request().Context(), key, req.BotID, req.Enabled)
+	if err != nil {
+		return h.flagError(err, "set feature flag failed", key)
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+// Clear godoc
+// @Summary Remove a feature flag override (requires operations.manage)
+// @Description Removes the bot's override, or the deployment-wide one when bot_id is empty, so the next level applies
+// @Tags feature-flags
+// @Produce json
+// @Param key path string true "Flag key"
+// @Param bot_id query string false "Bot whose override to remove"
+// @Success 200 {object} featureflags.State
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /feature-flags/{key} [delete].
+func (h *FeatureFlagsHandler) Clear(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage); err != nil {
+		return err
+	}
+	key := featureflags.Key(strings.TrimSpace(c.Param("key")))
+	state, err := h.service.Clear(c.Request().Context(), key, c.QueryParam("bot_id"))
+	if err != nil {
+		return h.flagError(err, "clear feature flag failed", key)
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+func (h *FeatureFlagsHandler) flagError(err error, msg string, key featureflags.Key) error {
+	switch {
+	case errors.Is(err, featureflags.ErrUnknownFlag), errors.Is(err, featureflags.ErrOverrideNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, featureflags.ErrInvalidBotID):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	h.logger.Error(msg, slog.String("flag", string(key)), slog.Any("error", err))
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
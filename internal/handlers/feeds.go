@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/feeds"
+)
+
+type FeedsHandler struct {
+	service        *feeds.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewFeedsHandler(log *slog.Logger, service *feeds.Service, botService *bots.Service, accountService *accounts.Service) *FeedsHandler {
+	return &FeedsHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "feeds")),
+	}
+}
+
+func (h *FeedsHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/feeds")
+	group.POST("", h.CreateFeed)
+	group.GET("", h.ListFeeds)
+	group.GET("/:id", h.GetFeed)
+	group.PUT("/:id", h.UpdateFeed)
+	group.DELETE("/:id", h.DeleteFeed)
+	group.POST("/:id/poll", h.PollFeed)
+	group.GET("/:id/items", h.ListItems)
+}
+
+// CreateFeed godoc
+// @Summary Subscribe a bot to an RSS or Atom feed
+// @Description New items are ingested into bot memory and/or a knowledge collection and can be delivered as digests to a channel
+// @Tags feeds
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param request body feeds.CreateFeedRequest true "Feed"
+// @Success 201 {object} feeds.Feed
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/feeds [post].
+func (h *FeedsHandler) CreateFeed(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req feeds.CreateFeedRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.CreateFeed(c.Request().Context(), botID, req)
+	if err != nil {
+		return feedsHTTPError(err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListFeeds godoc
+// @Summary List feeds of a bot
+// @Tags feeds
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {array} feeds.Feed
+// @Failure 403 {object} ErrorResponse
+// @Router /bots/{bot_id}/feeds [get].
+func (h *FeedsHandler) ListFeeds(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	items, err := h.service.ListFeeds(c.Request().Context(), botID)
+	if err != nil {
+		return feedsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// GetFeed godoc
+// @Summary Get a bot feed
+// @Tags feeds
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Feed ID"
+// @Success 200 {object} feeds.Feed
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/feeds/{id} [get].
+func (h *FeedsHandler) GetFeed(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	resp, err := h.service.GetFeed(c.Request().Context(), botID, strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return feedsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateFeed godoc
+// @Summary Update a bot feed
+// @Tags feeds
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Feed ID"
+// @Param request body feeds.UpdateFeedRequest true "Changes"
+// @Success 200 {object} feeds.Feed
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /bots/{bot_id}/feeds/{id} [put].
+func (h *FeedsHandler) UpdateFeed(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req feeds.UpdateFeedRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.UpdateFeed(c.Request().Context(), botID, strings.TrimSpace(c.Param("id")), req)
+	if err != nil {
+		return feedsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteFeed godoc
+// @Summary Unsubscribe a bot from a feed
+// @Description Items already ingested into memory or a collection are kept
+// @Tags feeds
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Feed ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/feeds/{id} [delete].
+func (h *FeedsHandler) DeleteFeed(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	if err := h.service.DeleteFeed(c.Request().Context(), botID, strings.TrimSpace(c.Param("id"))); err != nil {
+		return feedsHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PollFeed godoc
+// @Summary Poll a bot feed now
+// @Tags feeds
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Feed ID"
+// @Success 202 {object} feeds.Feed
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/feeds/{id}/poll [post].
+func (h *FeedsHandler) PollFeed(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	resp, err := h.service.PollFeed(c.Request().Context(), botID, strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return feedsHTTPError(err)
+	}
+	return c.JSON(http.StatusAccepted, resp)
+}
+
+// ListItems godoc
+// @Summary List recent items of a bot feed
+// @Tags feeds
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Feed ID"
+// @Param limit query int false "Maximum number of items (default 50, max 200)"
+// @Success 200 {array} feeds.Item
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/feeds/{id}/items [get].
+func (h *FeedsHandler) ListItems(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	items, err := h.service.ListItems(c.Request().Context(), botID, strings.TrimSpace(c.Param("id")), parseLimit(c.QueryParam("limit")))
+	if err != nil {
+		return feedsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+func (h *FeedsHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func feedsHTTPError(err error) error {
+	switch {
+	case errors.Is(err, feeds.ErrFeedNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, feeds.ErrFeedExists):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, feeds.ErrPollingDisabled):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, feeds.ErrInvalidFeed):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/github"
+)
+
+// maxGitHubWebhookBytes is the largest payload GitHub delivers.
+const maxGitHubWebhookBytes = 25 << 20
+
+type GitHubHandler struct {
+	service        *github.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewGitHubHandler(log *slog.Logger, service *github.Service, botService *bots.Service, accountService *accounts.Service) *GitHubHandler {
+	return &GitHubHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "github")),
+	}
+}
+
+func (h *GitHubHandler) Register(e *echo.Echo) {
+	group := e.Group("/github/repos")
+	group.POST("", h.CreateRepo)
+	group.GET("", h.ListRepos)
+	group.GET("/:id", h.GetRepo)
+	group.PUT("/:id", h.UpdateRepo)
+	group.DELETE("/:id", h.DeleteRepo)
+	group.POST("/:id/sync", h.SyncRepo)
+	e.POST("/github/webhook", h.HandleWebhook)
+
+	botGroup := e.Group("/bots/:bot_id/github-subscriptions")
+	botGroup.GET("", h.ListSubscriptions)
+	botGroup.PUT("/:repo_id", h.Subscribe)
+	botGroup.DELETE("/:repo_id", h.Unsubscribe)
+}
+
+// CreateRepo godoc
+// @Summary Add a GitHub repository
+// @Description Add a repository the GitHub App is installed on. With a collection, its README and docs are synced into it
+// @Tags github
+// @Accept json
+// @Produce json
+// @Param request body github.CreateRepoRequest true "Repository"
+// @Success 201 {object} github.Repo
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /github/repos [post].
+func (h *GitHubHandler) CreateRepo(c echo.Context) error {
+	var req github.CreateRepoRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.CreateRepo(c.Request().Context(), req)
+	if err != nil {
+		return githubHTTPError(err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListRepos godoc
+// @Summary List GitHub repositories
+// @Tags github
+// @Produce json
+// @Success 200 {array} github.Repo
+// @Failure 500 {object} ErrorResponse
+// @Router /github/repos [get].
+func (h *GitHubHandler) ListRepos(c echo.Context) error {
+	items, err := h.service.ListRepos(c.Request().Context())
+	if err != nil {
+		return githubHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// GetRepo godoc
+// @Summary Get a GitHub repository
+// @Tags github
+// @Produce json
+// @Param id path string true "Repository ID"
+// @Success 200 {object} github.Repo
+// @Failure 404 {object} ErrorResponse
+// @Router /github/repos/{id} [get].
+func (h *GitHubHandler) GetRepo(c echo.Context) error {
+	resp, err := h.service.GetRepo(c.Request().Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return githubHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateRepo godoc
+// @Summary Update a GitHub repository
+// @Description Change the collection or docs paths and resync. Moving to another collection removes the synced documents from the old one
+// @Tags github
+// @Accept json
+// @Produce json
+// @Param id path string true "Repository ID"
+// @Param request body github.UpdateRepoRequest true "Changes"
+// @Success 200 {object} github.Repo
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /github/repos/{id} [put].
+func (h *GitHubHandler) UpdateRepo(c echo.Context) error {
+	var req github.UpdateRepoRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.UpdateRepo(c.Request().Context(), strings.TrimSpace(c.Param("id")), req)
+	if err != nil {
+		return githubHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteRepo godoc
+// @Summary Remove a GitHub repository
+// @Description Removes its bot subscriptions and the documents synced from it
+// @Tags github
+// @Param id path string true "Repository ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /github/repos/{id} [delete].
+func (h *GitHubHandler) DeleteRepo(c echo.Context) error {
+	if err := h.service.DeleteRepo(c.Request().Context(), strings.TrimSpace(c.Param("id"))); err != nil {
+		return githubHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SyncRepo godoc
+// @Summary Sync a GitHub repository's docs now
+// @Tags github
+// @Produce json
+// @Param id path string true "Repository ID"
+// @Success 202 {object} github.Repo
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /github/repos/{id}/sync [post].
+func (h *GitHubHandler) SyncRepo(c echo.Context) error {
+	resp, err := h.service.SyncRepo(c.Request().Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return githubHTTPError(err)
+	}
+	return c.JSON(http.StatusAccepted, resp)
+}
+
+// HandleWebhook godoc
+// @Summary GitHub App webhook
+// @Description Receives GitHub App deliveries signed with the configured webhook secret
+// @Tags github
+// @Param X-GitHub-Event header string true "Event name"
+// @Param X-Hub-Signature-256 header string true "HMAC-SHA256 signature"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /github/webhook [post].
+func (h *GitHubHandler) HandleWebhook(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxGitHubWebhookBytes))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "read body failed")
+	}
+	if err := h.service.VerifySignature(c.Request().Header.Get("X-Hub-Signature-256"), body); err != nil {
+		if errors.Is(err, github.ErrNotConfigured) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+	event := strings.TrimSpace(c.Request().Header.Get("X-GitHub-Event"))
+	if err := h.service.HandleWebhook(c.Request().Context(), event, body); err != nil {
+		h.logger.Warn("github webhook failed",
+			slog.String("event", event),
+			slog.String("delivery", c.Request().Header.Get("X-GitHub-Delivery")),
+			slog.Any("error", err))
+		return githubHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListSubscriptions godoc
+// @Summary List a bot's GitHub subscriptions
+// @Tags github
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {array} github.Subscription
+// @Failure 403 {object} ErrorResponse
+// @Router /bots/{bot_id}/github-subscriptions [get].
+func (h *GitHubHandler) ListSubscriptions(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	items, err := h.service.ListSubscriptions(c.Request().Context(), botID)
+	if err != nil {
+		return githubHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// Subscribe godoc
+// @Summary Subscribe a bot to a GitHub repository
+// @Description Forward the repository's issue, pull request, and comment events to the bot. can_comment lets the bot comment on the repository's issues
+// @Tags github
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param repo_id path string true "Repository ID"
+// @Param request body github.SubscribeRequest true "Subscription"
+// @Success 200 {object} github.Subscription
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/github-subscriptions/{repo_id} [put].
+func (h *GitHubHandler) Subscribe(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req github.SubscribeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.Subscribe(c.Request().Context(), botID, strings.TrimSpace(c.Param("repo_id")), req)
+	if err != nil {
+		return githubHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Unsubscribe godoc
+// @Summary Unsubscribe a bot from a GitHub repository
+// @Tags github
+// @Param bot_id path string true "Bot ID"
+// @Param repo_id path string true "Repository ID"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/github-subscriptions/{repo_id} [delete].
+func (h *GitHubHandler) Unsubscribe(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	if err := h.service.Unsubscribe(c.Request().Context(), botID, strings.TrimSpace(c.Param("repo_id"))); err != nil {
+		return githubHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *GitHubHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission); err != nil {
+		return "", err
+	}
+	return botID, nil
+}
+
+func githubHTTPError(err error) error {
+	switch {
+	case errors.Is(err, github.ErrRepoNotFound), errors.Is(err, github.ErrNotSubscribed):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, github.ErrRepoExists):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, github.ErrInvalidRepo), errors.Is(err, github.ErrInvalidSubscription),
+		errors.Is(err, github.ErrNotInstalled), errors.Is(err, github.ErrNotConfigured),
+		errors.Is(err, github.ErrInvalidWebhookBody):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/memohai/memoh/internal/auth"
 	"github.com/memohai/memoh/internal/bots"
 	"github.com/memohai/memoh/internal/identity"
+	"github.com/memohai/memoh/internal/policy"
 )
 
 // RequireChannelIdentityID extracts and validates the channel identity ID from the request context.
@@ -27,8 +28,50 @@ func RequireChannelIdentityID(c echo.Context) (string, error) {
 	return channelIdentityID, nil
 }
 
+// RequirePermission checks that the requesting user's role grants perm and
+// returns the user's channel identity ID.
+func RequirePermission(c echo.Context, accountService *accounts.Service, perm policy.Permission) (string, error) {
+	channelIdentityID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	if err := requireUserPermission(c, accountService, channelIdentityID, perm); err != nil {
+		return "", err
+	}
+	return channelIdentityID, nil
+}
+
+// requireUserPermission checks perm for an already authenticated user. A
+// user that no longer exists gets 401 so clients prompt for a new login.
+func requireUserPermission(c echo.Context, accountService *accounts.Service, userID string, perm policy.Permission) error {
+	if accountService == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "account service not configured")
+	}
+	if err := policy.Require(c.Request().Context(), accountService, userID, perm); err != nil {
+		if errors.Is(err, policy.ErrPermissionDenied) {
+			// A token whose user no longer exists is stale, not underprivileged.
+			if _, getErr := accountService.Get(c.Request().Context(), userID); accountNotFound(getErr) {
+				return echo.NewHTTPError(http.StatusUnauthorized, "user not found, please login again")
+			}
+			return echo.NewHTTPError(http.StatusForbidden, "permission required: "+string(perm))
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+// managesAllBots reports whether the user's role gives owner-level access to
+// every bot in the workspace.
+func managesAllBots(ctx context.Context, accountService *accounts.Service, userID string) (bool, error) {
+	if accountService == nil {
+		return false, errors.New("account service not configured")
+	}
+	return policy.Allowed(ctx, accountService, userID, policy.PermissionBotsManageAll)
+}
+
 // AuthorizeBotAccess validates that the given identity has manage-level access to
-// the specified bot (owner, workspace admin, or a user grant carrying manage).
+// the specified bot (owner, a role managing all bots, or a user grant carrying
+// manage).
 func AuthorizeBotAccess(ctx context.Context, botService *bots.Service, accountService *accounts.Service, channelIdentityID, botID string) (bots.Bot, error) {
 	return AuthorizeBotAccessWithPermission(ctx, botService, accountService, channelIdentityID, botID, bots.PermissionManage)
 }
@@ -39,11 +82,11 @@ func AuthorizeBotAccessWithPermission(ctx context.Context, botService *bots.Serv
 	if botService == nil || accountService == nil {
 		return bots.Bot{}, echo.NewHTTPError(http.StatusInternalServerError, "bot services not configured")
 	}
-	isAdmin, err := accountService.IsAdmin(ctx, channelIdentityID)
+	manageAll, err := managesAllBots(ctx, accountService, channelIdentityID)
 	if err != nil {
 		return bots.Bot{}, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	bot, err := botService.AuthorizeAccessWithPermission(ctx, channelIdentityID, botID, isAdmin, requiredPermission)
+	bot, err := botService.AuthorizeAccessWithPermission(ctx, channelIdentityID, botID, manageAll, requiredPermission)
 	if err != nil {
 		if errors.Is(err, bots.ErrBotNotFound) {
 			return bots.Bot{}, echo.NewHTTPError(http.StatusNotFound, "bot not found")
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/chat/historysearch"
+)
+
+type HistorySearchHandler struct {
+	service        *historysearch.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewHistorySearchHandler(log *slog.Logger, service *historysearch.Service, botService *bots.Service, accountService *accounts.Service) *HistorySearchHandler {
+	return &HistorySearchHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "history_search")),
+	}
+}
+
+func (h *HistorySearchHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/history/search")
+	group.GET("", h.Search)
+	group.GET("/index", h.GetIndex)
+	group.PUT("/index", h.EnableIndex)
+	group.DELETE("/index", h.DisableIndex)
+	group.POST("/index/rebuild", h.RebuildIndex)
+}
+
+// Search godoc
+// @Summary Search a bot's conversation history
+// @Description Searches every session of the bot. Semantic mode ranks messages by similarity to the query and needs the bot's history search index; auto uses it when enabled and keyword matching otherwise.
+// @Tags history
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param q query string true "Query"
+// @Param mode query string false "auto, semantic or keyword (default auto)"
+// @Param session_id query string false "Only search this session"
+// @Param limit query int false "Maximum number of hits (default 20, max 100)"
+// @Success 200 {object} historysearch.SearchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/history/search [get].
+func (h *HistorySearchHandler) Search(c echo.Context) error {
+	botID, err := h.authorizeBot(c)
+	if err != nil {
+		return err
+	}
+	limit, _ := strconv.Atoi(strings.TrimSpace(c.QueryParam("limit")))
+	resp, err := h.service.Search(c.Request().Context(), botID, historysearch.SearchRequest{
+		Query:     c.QueryParam("q"),
+		Mode:      c.QueryParam("mode"),
+		SessionID: c.QueryParam("session_id"),
+		Limit:     limit,
+	})
+	if err != nil {
+		return historySearchHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetIndex godoc
+// @Summary Get a bot's semantic history search index
+// @Tags history
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} historysearch.Index
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/history/search/index [get].
+func (h *HistorySearchHandler) GetIndex(c echo.Context) error {
+	botID, err := h.authorizeBot(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.service.GetIndex(c.Request().Context(), botID)
+	if err != nil {
+		return historySearchHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// EnableIndex godoc
+// @Summary Enable semantic history search for a bot
+// @Description Existing and new messages are embedded in the background. Switching to another embedding model drops the existing embeddings.
+// @Tags history
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param request body historysearch.EnableRequest true "Embedding model"
+// @Success 200 {object} historysearch.Index
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/history/search/index [put].
+func (h *HistorySearchHandler) EnableIndex(c echo.Context) error {
+	botID, err := h.authorizeBot(c)
+	if err != nil {
+		return err
+	}
+	var req historysearch.EnableRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.EnableIndex(c.Request().Context(), botID, req)
+	if err != nil {
+		return historySearchHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DisableIndex godoc
+// @Summary Disable semantic history search for a bot
+// @Description Drops the bot's message embeddings; keyword search keeps working
+// @Tags history
+// @Param bot_id path string true "Bot ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/history/search/index [delete].
+func (h *HistorySearchHandler) DisableIndex(c echo.Context) error {
+	botID, err := h.authorizeBot(c)
+	if err != nil {
+		return err
+	}
+	if err := h.service.DisableIndex(c.Request().Context(), botID); err != nil {
+		return historySearchHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RebuildIndex godoc
+// @Summary Re-embed a bot's whole history
+// @Description Use after importing messages with past timestamps
+// @Tags history
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 202 {object} historysearch.Index
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/history/search/index/rebuild [post].
+func (h *HistorySearchHandler) RebuildIndex(c echo.Context) error {
+	botID, err := h.authorizeBot(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.service.RebuildIndex(c.Request().Context(), botID)
+	if err != nil {
+		return historySearchHTTPError(err)
+	}
+	return c.JSON(http.StatusAccepted, resp)
+}
+
+// authorizeBot requires manage access: history search spans every session
+// of the bot, including ones the caller is not part of.
+func (h *HistorySearchHandler) authorizeBot(c echo.Context) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, bots.PermissionManage)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func historySearchHTTPError(err error) error {
+	switch {
+	case errors.Is(err, historysearch.ErrIndexNotEnabled):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, historysearch.ErrInvalidQuery), errors.Is(err, historysearch.ErrInvalidEmbeddingModel):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, historysearch.ErrVectorIndexDisabled):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/httptools"
+)
+
+type HTTPToolsHandler struct {
+	service        *httptools.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewHTTPToolsHandler(log *slog.Logger, service *httptools.Service, botService *bots.Service, accountService *accounts.Service) *HTTPToolsHandler {
+	return &HTTPToolsHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "http_tools")),
+	}
+}
+
+func (h *HTTPToolsHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/http-tools")
+	group.GET("", h.List)
+	group.POST("/import", h.Import)
+	group.DELETE("/:id", h.Delete)
+}
+
+// List godoc
+// @Summary List a bot's HTTP tool sets
+// @Description Credentials are masked
+// @Tags http-tools
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {array} httptools.ToolSet
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/http-tools [get].
+func (h *HTTPToolsHandler) List(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	sets, err := h.service.List(c.Request().Context(), botID)
+	if err != nil {
+		return httpToolsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, sets)
+}
+
+// Import godoc
+// @Summary Import an OpenAPI spec as HTTP tools
+// @Description Each operation of the spec becomes a tool named <name>_<operationId>, served through the tool gateway. Importing under an existing name replaces that set; omitting auth keeps its stored credentials
+// @Tags http-tools
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param request body httptools.ImportRequest true "Import request"
+// @Success 200 {object} httptools.ToolSet
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/http-tools/import [post].
+func (h *HTTPToolsHandler) Import(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req httptools.ImportRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	set, err := h.service.Import(c.Request().Context(), botID, req)
+	if err != nil {
+		return httpToolsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, set)
+}
+
+// Delete godoc
+// @Summary Delete an HTTP tool set
+// @Tags http-tools
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Tool set ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/http-tools/{id} [delete].
+func (h *HTTPToolsHandler) Delete(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	if err := h.service.Delete(c.Request().Context(), botID, strings.TrimSpace(c.Param("id"))); err != nil {
+		return httpToolsHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *HTTPToolsHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func httpToolsHTTPError(err error) error {
+	if errors.Is(err, httptools.ErrInvalidSpec) || errors.Is(err, httptools.ErrInvalidToolSet) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/agent/application"
+)
+
+const (
+	// idempotencyKeyHeader lets a client retry a chat request after a timeout
+	// without starting a second round.
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotencyReplayedHeader marks a response served from the cache.
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyTTL          = 24 * time.Hour
+	idempotencyCapacity     = 4096
+	maxIdempotencyKeyLength = 255
+)
+
+// errIdempotencyCacheFull rejects a new key while every cached entry is still
+// in flight. Evicting one would let its retries start a second round.
+var errIdempotencyCacheFull = errors.New("too many requests with an idempotency key are in progress")
+
+// idempotentResponse is a completed response kept for replay.
+type idempotentResponse struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte
+	done        chan struct{}
+	response    idempotentResponse
+	completed   bool
+	expiresAt   time.Time
+}
+
+// idempotencyCache remembers the response of each keyed request for
+// idempotencyTTL. Keys live in memory and apply per process only: they do
+// not survive a restart, and retries routed to another replica are not
+// deduplicated.
+type idempotencyCache struct {
+	mu       sync.Mutex
+	entries  map[string]*idempotencyEntry
+	order    []string
+	ttl      time.Duration
+	capacity int
+	now      func() time.Time
+}
+
+func newIdempotencyCache(ttl time.Duration, capacity int) *idempotencyCache {
+	return &idempotencyCache{
+		entries:  make(map[string]*idempotencyEntry, capacity),
+		ttl:      ttl,
+		capacity: capacity,
+		now:      time.Now,
+	}
+}
+
+// begin claims key for a request with fingerprint. It returns the existing
+// entry and false when the key is already claimed, or a fresh entry and true
+// when the caller owns it and must call complete or release. It fails with
+// errIdempotencyCacheFull when no entry can be evicted to make room.
+func (c *idempotencyCache) begin(key string, fingerprint [sha256.Size]byte) (*idempotencyEntry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if entry, ok := c.entries[key]; ok {
+		if !entry.completed || now.Before(entry.expiresAt) {
+			return entry, false, nil
+		}
+		c.removeLocked(key)
+	}
+	if len(c.entries) >= c.capacity && !c.evictLocked(now) {
+		return nil, false, errIdempotencyCacheFull
+	}
+	entry := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	c.entries[key] = entry
+	c.order = append(c.order, key)
+	return entry, true, nil
+}
+
+// evictLocked makes room for one entry: it drops every expired entry, then
+// the oldest completed one if the cache is still full. In-flight entries are
+// never evicted. It reports whether there is room.
+func (c *idempotencyCache) evictLocked(now time.Time) bool {
+	kept := c.order[:0]
+	for _, key := range c.order {
+		if entry := c.entries[key]; entry.completed && !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	c.order = kept
+	if len(c.entries) < c.capacity {
+		return true
+	}
+	for _, key := range c.order {
+		if c.entries[key].completed {
+			c.removeLocked(key)
+			return true
+		}
+	}
+	return false
+}
+
+// complete stores the response for replay and wakes waiting retries.
+func (c *idempotencyCache) complete(entry *idempotencyEntry, response idempotentResponse) {
+	c.mu.Lock()
+	entry.response = response
+	entry.completed = true
+	entry.expiresAt = c.now().Add(c.ttl)
+	c.mu.Unlock()
+	close(entry.done)
+}
+
+// release forgets a claim whose request failed, so a retry runs it again.
+func (c *idempotencyCache) release(key string, entry *idempotencyEntry) {
+	c.mu.Lock()
+	if c.entries[key] == entry {
+		c.removeLocked(key)
+	}
+	c.mu.Unlock()
+	close(entry.done)
+}
+
+func (c *idempotencyCache) removeLocked(key string) {
+	delete(c.entries, key)
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// wait blocks until entry finished or ctx ends. It reports whether the
+// original request completed with a replayable response.
+func (c *idempotencyCache) wait(ctx context.Context, entry *idempotencyEntry) (idempotentResponse, bool, error) {
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return idempotentResponse{}, false, ctx.Err()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return entry.response, entry.completed, nil
+}
+
+// idempotencyCaptureWriter tees the response so it can be replayed.
+type idempotencyCaptureWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *idempotencyCaptureWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *idempotencyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// readIdempotentBody reads the request body for fingerprinting and restores
+// it so next can still bind it.
+func readIdempotentBody(c echo.Context) ([]byte, error) {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	c.Request().Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// serveIdempotent runs next once per Idempotency-Key within scope and
+// replays its response to retries. A retry that arrives while the original
+// is still running waits for it. Reusing a key with a different body is
+// rejected; failed requests (errors and 5xx) are not cached so the client
+// can retry them. Requests without the header run next directly.
+func serveIdempotent(c echo.Context, cache *idempotencyCache, scope string, body []byte, next func() error) error {
+	key := strings.TrimSpace(c.Request().Header.Get(idempotencyKeyHeader))
+	if key == "" || cache == nil {
+		return next()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return echo.NewHTTPError(http.StatusBadRequest, "idempotency key is too long")
+	}
+	cacheKey := scope + "\x00" + key
+	fingerprint := sha256.Sum256(body)
+	for {
+		entry, owner, err := cache.begin(cacheKey, fingerprint)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+		}
+		if owner {
+			return runIdempotent(c, cache, cacheKey, entry, next)
+		}
+		if entry.fingerprint != fingerprint {
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, "idempotency key was already used with a different request body")
+		}
+		response, ok, err := cache.wait(c.Request().Context(), entry)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusConflict, "a request with this idempotency key is still in progress")
+		}
+		if !ok {
+			// The original failed and released the key; run this retry.
+			continue
+		}
+		c.Response().Header().Set(idempotencyReplayedHeader, "true")
+		return c.Blob(response.status, response.contentType, response.body)
+	}
+}
+
+func runIdempotent(c echo.Context, cache *idempotencyCache, cacheKey string, entry *idempotencyEntry, next func() error) error {
+	res := c.Response()
+	original := res.Writer
+	capture := &idempotencyCaptureWriter{ResponseWriter: original, status: http.StatusOK}
+	res.Writer = capture
+	completed := false
+	defer func() {
+		res.Writer = original
+		if !completed {
+			cache.release(cacheKey, entry)
+		}
+	}()
+	if err := next(); err != nil {
+		return err
+	}
+	if !res.Committed || capture.status >= http.StatusInternalServerError {
+		return nil
+	}
+	completed = true
+	cache.complete(entry, idempotentResponse{
+		status:      capture.status,
+		contentType: res.Header().Get(echo.HeaderContentType),
+		body:        bytes.Clone(capture.body.Bytes()),
+	})
+	return nil
+}
+
+// wsIdempotencyClaim is a WebSocket send's hold on its idempotency key. A
+// stream has no response to replay, so a retry of a claimed key is refused
+// instead of waiting. complete and release are nil-safe and only the first
+// call takes effect.
+type wsIdempotencyClaim struct {
+	cache *idempotencyCache
+	key   string
+	entry *idempotencyEntry
+	once  sync.Once
+}
+
+// claimWSIdempotency claims msg's idempotency key within scope. It returns a
+// nil claim when the message carries no key, and an error to send back when
+// the key is already claimed or cannot be claimed.
+func claimWSIdempotency(cache *idempotencyCache, scope string, msg wsClientMessage) (*wsIdempotencyClaim, error) {
+	key := strings.TrimSpace(msg.IdempotencyKey)
+	if key == "" || cache == nil {
+		return nil, nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, errors.New("idempotency key is too long")
+	}
+	// Retries resend the message under a fresh stream, so the stream ID is
+	// not part of what the key covers.
+	msg.StreamID = ""
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := sha256.Sum256(body)
+	cacheKey := scope + "\x00" + key
+	entry, owner, err := cache.begin(cacheKey, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if !owner {
+		if entry.fingerprint != fingerprint {
+			return nil, errors.New("idempotency key was already used with a different message")
+		}
+		return nil, errors.New("a message with this idempotency key was already sent")
+	}
+	return &wsIdempotencyClaim{cache: cache, key: cacheKey, entry: entry}, nil
+}
+
+// complete keeps the key claimed for idempotencyTTL.
+func (c *wsIdempotencyClaim) complete() {
+	if c == nil {
+		return
+	}
+	c.once.Do(func() { c.cache.complete(c.entry, idempotentResponse{}) })
+}
+
+// release frees the key so a retry runs again.
+func (c *wsIdempotencyClaim) release() {
+	if c == nil {
+		return
+	}
+	c.once.Do(func() { c.cache.release(c.key, c.entry) })
+}
+
+// runner completes the claim when the stream succeeds.
+func (c *wsIdempotencyClaim) runner(next wsStreamRunner) wsStreamRunner {
+	if c == nil {
+		return next
+	}
+	return func(ctx context.Context, eventCh chan<- application.WSStreamEvent, abortCh <-chan struct{}) error {
+		err := next(ctx, eventCh, abortCh)
+		if err == nil {
+			c.complete()
+		}
+		return err
+	}
+}
+
+// onFinish releases the claim once the stream ends without completing it,
+// including a stream that never started.
+func (c *wsIdempotencyClaim) onFinish(next func()) func() {
+	if c == nil {
+		return next
+	}
+	return func() {
+		if next != nil {
+			next()
+		}
+		c.release()
+	}
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newIdempotencyTestContext(key, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+func TestServeIdempotentReplaysOriginalResponse(t *testing.T) {
+	t.Parallel()
+	cache := newIdempotencyCache(time.Hour, 16)
+	calls := 0
+	next := func(c echo.Context) func() error {
+		return func() error {
+			calls++
+			return c.JSON(http.StatusOK, map[string]int{"round": calls})
+		}
+	}
+
+	c, rec := newIdempotencyTestContext("k1", `{"text":"hi"}`)
+	if err := serveIdempotent(c, cache, "u1:b1", []byte(`{"text":"hi"}`), next(c)); err != nil {
+		t.Fatalf("first request error = %v", err)
+	}
+	retry, retryRec := newIdempotencyTestContext("k1", `{"text":"hi"}`)
+	if err := serveIdempotent(retry, cache, "u1:b1", []byte(`{"text":"hi"}`), next(retry)); err != nil {
+		t.Fatalf("retry error = %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if retryRec.Body.String() != rec.Body.String() || retryRec.Header().Get(idempotencyReplayedHeader) != "true" {
+		t.Fatalf("retry = %q (replayed %q), want %q", retryRec.Body.String(), retryRec.Header().Get(idempotencyReplayedHeader), rec.Body.String())
+	}
+
+	other, _ := newIdempotencyTestContext("k1", `{"text":"hi"}`)
+	if err := serveIdempotent(other, cache, "u2:b1", []byte(`{"text":"hi"}`), next(other)); err != nil {
+		t.Fatalf("other scope error = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("key in another scope ran %d times, want 2", calls)
+	}
+}
+
+func TestServeIdempotentRejectsKeyReuseWithDifferentBody(t *testing.T) {
+	t.Parallel()
+	cache := newIdempotencyCache(time.Hour, 16)
+	c, _ := newIdempotencyTestContext("k1", "a")
+	if err := serveIdempotent(c, cache, "s", []byte("a"), func() error { return c.NoContent(http.StatusOK) }); err != nil {
+		t.Fatalf("first request error = %v", err)
+	}
+	reuse, _ := newIdempotencyTestContext("k1", "b")
+	err := serveIdempotent(reuse, cache, "s", []byte("b"), func() error {
+		t.Fatal("handler ran for a reused key")
+		return nil
+	})
+	var httpErr *echo.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reuse error = %v, want 422", err)
+	}
+}
+
+func TestServeIdempotentRetriesFailedRequests(t *testing.T) {
+	t.Parallel()
+	cache := newIdempotencyCache(time.Hour, 16)
+	calls := 0
+	c, _ := newIdempotencyTestContext("k1", "a")
+	err := serveIdempotent(c, cache, "s", []byte("a"), func() error {
+		calls++
+		return echo.NewHTTPError(http.StatusInternalServerError, "boom")
+	})
+	if err == nil {
+		t.Fatal("failed request returned nil error")
+	}
+	retry, rec := newIdempotencyTestContext("k1", "a")
+	if err := serveIdempotent(retry, cache, "s", []byte("a"), func() error {
+		calls++
+		return retry.String(http.StatusOK, "ok")
+	}); err != nil {
+		t.Fatalf("retry error = %v", err)
+	}
+	if calls != 2 || rec.Body.String() != "ok" {
+		t.Fatalf("calls = %d body = %q, want retry to run", calls, rec.Body.String())
+	}
+}
+
+func TestServeIdempotentWithoutKeyAlwaysRuns(t *testing.T) {
+	t.Parallel()
+	cache := newIdempotencyCache(time.Hour, 16)
+	calls := 0
+	for range 2 {
+		c, _ := newIdempotencyTestContext("", "a")
+		if err := serveIdempotent(c, cache, "s", []byte("a"), func() error {
+			calls++
+			return c.NoContent(http.StatusOK)
+		}); err != nil {
+			t.Fatalf("request error = %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyCacheExpiresCompletedEntries(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache := newIdempotencyCache(time.Minute, 16)
+	cache.now = func() time.Time { return now }
+	var fingerprint [32]byte
+	entry, owner, _ := cache.begin("k", fingerprint)
+	if !owner {
+		t.Fatal("first begin did not claim the key")
+	}
+	cache.complete(entry, idempotentResponse{status: http.StatusOK})
+	if _, owner, _ := cache.begin("k", fingerprint); owner {
+		t.Fatal("begin claimed a key that is still cached")
+	}
+	now = now.Add(2 * time.Minute)
+	if _, owner, _ := cache.begin("k", fingerprint); !owner {
+		t.Fatal("begin did not reclaim an expired key")
+	}
+}
+
+func TestIdempotencyCacheNeverEvictsInFlightEntries(t *testing.T) {
+	t.Parallel()
+	cache := newIdempotencyCache(time.Hour, 2)
+	var fingerprint [32]byte
+	first, _, _ := cache.begin("a", fingerprint)
+	if _, _, err := cache.begin("b", fingerprint); err != nil {
+		t.Fatalf("begin(b) error = %v", err)
+	}
+	if _, _, err := cache.begin("c", fingerprint); !errors.Is(err, errIdempotencyCacheFull) {
+		t.Fatalf("begin(c) with every entry in flight error = %v, want errIdempotencyCacheFull", err)
+	}
+	cache.complete(first, idempotentResponse{status: http.StatusOK})
+	if _, owner, err := cache.begin("c", fingerprint); err != nil || !owner {
+		t.Fatalf("begin(c) = %v, %v; want it to evict the completed entry", owner, err)
+	}
+	if _, owner, _ := cache.begin("b", fingerprint); owner {
+		t.Fatal("the in-flight entry was evicted")
+	}
+}
+
+func TestClaimWSIdempotencyRefusesResends(t *testing.T) {
+	t.Parallel()
+	cache := newIdempotencyCache(time.Hour, 16)
+	msg := wsClientMessage{Type: "message", StreamID: "s1", Text: "hi", IdempotencyKey: "k1"}
+
+	claim, err := claimWSIdempotency(cache, "ws", msg)
+	if err != nil || claim == nil {
+		t.Fatalf("first claim = %v, %v", claim, err)
+	}
+	msg.StreamID = "s2"
+	if _, err := claimWSIdempotency(cache, "ws", msg); err == nil {
+		t.Fatal("resend during the original stream was not refused")
+	}
+	claim.release()
+	retry, err := claimWSIdempotency(cache, "ws", msg)
+	if err != nil || retry == nil {
+		t.Fatalf("claim after release = %v, %v; want the resend to run", retry, err)
+	}
+	retry.complete()
+	if _, err := claimWSIdempotency(cache, "ws", msg); err == nil {
+		t.Fatal("resend of a completed message was not refused")
+	}
+	msg.Text = "other"
+	if _, err := claimWSIdempotency(cache, "ws", msg); err == nil || !strings.Contains(err.Error(), "different message") {
+		t.Fatalf("key reuse error = %v, want a different-message error", err)
+	}
+	if claim, err := claimWSIdempotency(cache, "ws", wsClientMessage{Type: "message", Text: "hi"}); claim != nil || err != nil {
+		t.Fatalf("claim without key = %v, %v; want none", claim, err)
+	}
+}
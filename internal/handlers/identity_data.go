@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/erasure"
+	"github.com/memohai/memoh/internal/policy"
+)
+
+// IdentityDataHandler exposes the right-to-be-forgotten workflow for channel
+// identities.
+type IdentityDataHandler struct {
+	erasure        *erasure.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+// NewIdentityDataHandler constructs an IdentityDataHandler.
+func NewIdentityDataHandler(log *slog.Logger, erasureService *erasure.Service, accountService *accounts.Service) *IdentityDataHandler {
+	return &IdentityDataHandler{
+		erasure:        erasureService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "identity_data")),
+	}
+}
+
+func (h *IdentityDataHandler) Register(e *echo.Echo) {
+	e.DELETE("/identities/:id/data", h.EraseData)
+}
+
+// EraseData godoc
+// @Summary Erase a channel identity's data (requires data.manage)
+// @Description Remove the identity's messages, media references, session events, memories and contact record, and record the erasure in the audit log
+// @Tags identities
+// @Param id path string true "Channel Identity ID"
+// @Success 200 {object} erasure.Report
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /identities/{id}/data [delete].
+func (h *IdentityDataHandler) EraseData(c echo.Context) error {
+	actorID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	if err := requireUserPermission(c, h.accountService, actorID, policy.PermissionDataManage); err != nil {
+		return err
+	}
+	identityID := strings.TrimSpace(c.Param("id"))
+	if _, err := db.ParseUUID(identityID); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid channel identity id")
+	}
+	report, err := h.erasure.EraseChannelIdentity(c.Request().Context(), actorID, identityID)
+	if err != nil {
+		if errors.Is(err, erasure.ErrChannelIdentityNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		h.logger.Error("erase identity data failed", slog.String("channel_identity_id", identityID), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	h.logger.Info("identity data erased",
+		slog.String("channel_identity_id", identityID),
+		slog.String("audit_log_id", report.AuditLogID),
+		slog.Int("messages", report.Messages),
+		slog.Int("memories", report.Memories),
+	)
+	return c.JSON(http.StatusOK, report)
+}
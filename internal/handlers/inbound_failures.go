@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/channel/deadletter"
+	"github.com/memohai/memoh/internal/policy"
+)
+
+// InboundFailuresHandler lets operators inspect inbound channel messages whose
+// processing failed and replay them through the processor.
+type InboundFailuresHandler struct {
+	service        *deadletter.Service
+	manager        *channel.Manager
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+type InboundFailuresResponse struct {
+	Items []deadletter.Failure `json:"items"`
+}
+
+func NewInboundFailuresHandler(log *slog.Logger, service *deadletter.Service, manager *channel.Manager, accountService *accounts.Service) *InboundFailuresHandler {
+	return &InboundFailuresHandler{
+		service:        service,
+		manager:        manager,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "inbound_failures")),
+	}
+}
+
+func (h *InboundFailuresHandler) Register(e *echo.Echo) {
+	e.GET("/inbound/failures", h.List)
+	e.POST("/inbound/failures/:id/replay", h.Replay)
+}
+
+// List godoc
+// @Summary List failed inbound messages (requires operations.manage)
+// @Description Inbound channel messages whose processing failed after the platform event was acknowledged, newest first
+// @Tags channel
+// @Produce json
+// @Param status query string false "pending (default) or replayed"
+// @Param bot_id query string false "Only failures of this bot"
+// @Param limit query int false "Maximum items (default 50, max 500)"
+// @Success 200 {object} InboundFailuresResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /inbound/failures [get].
+func (h *InboundFailuresHandler) List(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage); err != nil {
+		return err
+	}
+	filter := deadletter.ListFilter{
+		BotID:  strings.TrimSpace(c.QueryParam("bot_id")),
+		Status: strings.TrimSpace(c.QueryParam("status")),
+	}
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		filter.Limit = limit
+	}
+	items, err := h.service.List(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, deadletter.ErrInvalidStatus) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, InboundFailuresResponse{Items: items})
+}
+
+// Replay godoc
+// @Summary Replay a failed inbound message (requires operations.manage)
+// @Description Re-drives the message through inbound processing with the bot's current channel config. The returned failure is marked replayed on success; otherwise it stays pending with the new error
+// @Tags channel
+// @Produce json
+// @Param id path string true "Failure ID"
+// @Success 200 {object} deadletter.Failure
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /inbound/failures/{id}/replay [post].
+func (h *InboundFailuresHandler) Replay(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage); err != nil {
+		return err
+	}
+	if h.manager == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "channel manager not configured")
+	}
+	id := strings.TrimSpace(c.Param("id"))
+	failure, err := h.service.Replay(c.Request().Context(), id, h.manager)
+	if err != nil {
+		switch {
+		case errors.Is(err, deadletter.ErrFailureNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, deadletter.ErrAlreadyReplayed):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		h.logger.Error("replay inbound failure failed", slog.String("failure_id", id), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, failure)
+}
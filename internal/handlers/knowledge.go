@@ -0,0 +1,547 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+type KnowledgeHandler struct {
+	service        *knowledge.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewKnowledgeHandler(log *slog.Logger, service *knowledge.Service, botService *bots.Service, accountService *accounts.Service) *KnowledgeHandler {
+	return &KnowledgeHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "knowledge")),
+	}
+}
+
+func (h *KnowledgeHandler) Register(e *echo.Echo) {
+	group := e.Group("/knowledge/collections")
+	group.POST("", h.CreateCollection)
+	group.GET("", h.ListCollections)
+	group.GET("/:id", h.GetCollection)
+	group.PUT("/:id", h.UpdateCollection)
+	group.DELETE("/:id", h.DeleteCollection)
+	group.POST("/:id/reprocess", h.ReprocessCollection)
+	group.GET("/:id/reprocess-jobs", h.ListReprocessJobs)
+	group.POST("/:id/documents", h.AddDocument)
+	group.POST("/:id/documents/upload", h.UploadDocument)
+	group.GET("/:id/documents", h.ListDocuments)
+	group.GET("/:id/documents/:doc_id", h.GetDocument)
+	group.DELETE("/:id/documents/:doc_id", h.DeleteDocument)
+	group.POST("/:id/crawl-sources", h.CreateCrawlSource)
+	group.GET("/:id/crawl-sources", h.ListCrawlSources)
+	group.GET("/:id/crawl-sources/:source_id", h.GetCrawlSource)
+	group.PUT("/:id/crawl-sources/:source_id", h.UpdateCrawlSource)
+	group.DELETE("/:id/crawl-sources/:source_id", h.DeleteCrawlSource)
+	group.POST("/:id/crawl-sources/:source_id/run", h.RunCrawlSource)
+	group.GET("/:id/crawl-sources/:source_id/runs", h.ListCrawlRuns)
+	group.POST("/:id/connectors", h.CreateConnector)
+	group.GET("/:id/connectors", h.ListConnectors)
+	group.GET("/:id/connectors/:connector_id", h.GetConnector)
+	group.PUT("/:id/connectors/:connector_id", h.UpdateConnector)
+	group.DELETE("/:id/connectors/:connector_id", h.DeleteConnector)
+	group.POST("/:id/connectors/:connector_id/sync", h.SyncConnector)
+	group.GET("/:id/connectors/:connector_id/syncs", h.ListConnectorSyncs)
+	group.GET("/:id/connectors/:connector_id/oauth/authorize", h.AuthorizeConnector)
+	group.DELETE("/:id/connectors/:connector_id/oauth/token", h.DisconnectConnector)
+	e.GET("/knowledge/connector-types", h.ListConnectorTypes)
+	e.GET("/knowledge/connectors/oauth/callback", h.ConnectorOAuthCallback)
+	e.GET(knowledgeConnectorCallbackPath, h.ConnectorOAuthCallback)
+
+	botGroup := e.Group("/bots/:bot_id/knowledge-collections")
+	botGroup.GET("", h.ListBotCollections)
+	botGroup.PUT("/:id", h.AttachCollection)
+	botGroup.DELETE("/:id", h.DetachCollection)
+}
+
+// CreateCollection godoc
+// @Summary Create a knowledge collection
+// @Description Create a named document set that bots can be attached to
+// @Tags knowledge
+// @Accept json
+// @Produce json
+// @Param request body knowledge.CreateCollectionRequest true "Collection"
+// @Success 201 {object} knowledge.Collection
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /knowledge/collections [post].
+func (h *KnowledgeHandler) CreateCollection(c echo.Context) error {
+	var req knowledge.CreateCollectionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.CreateCollection(c.Request().Context(), req)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListCollections godoc
+// @Summary List knowledge collections
+// @Tags knowledge
+// @Produce json
+// @Success 200 {array} knowledge.Collection
+// @Failure 500 {object} ErrorResponse
+// @Router /knowledge/collections [get].
+func (h *KnowledgeHandler) ListCollections(c echo.Context) error {
+	items, err := h.service.ListCollections(c.Request().Context())
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// GetCollection godoc
+// @Summary Get a knowledge collection
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {object} knowledge.Collection
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id} [get].
+func (h *KnowledgeHandler) GetCollection(c echo.Context) error {
+	resp, err := h.service.GetCollection(c.Request().Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateCollection godoc
+// @Summary Update a knowledge collection
+// @Description Update a collection. Changing the embedding model, chunk settings, or metadata extraction re-processes its documents in the background.
+// @Tags knowledge
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param request body knowledge.UpdateCollectionRequest true "Changes"
+// @Success 200 {object} knowledge.UpdateCollectionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /knowledge/collections/{id} [put].
+func (h *KnowledgeHandler) UpdateCollection(c echo.Context) error {
+	var req knowledge.UpdateCollectionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.UpdateCollection(c.Request().Context(), strings.TrimSpace(c.Param("id")), req)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteCollection godoc
+// @Summary Delete a knowledge collection
+// @Description Delete a collection with its documents, vectors, and bot attachments
+// @Tags knowledge
+// @Param id path string true "Collection ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id} [delete].
+func (h *KnowledgeHandler) DeleteCollection(c echo.Context) error {
+	if err := h.service.DeleteCollection(c.Request().Context(), strings.TrimSpace(c.Param("id"))); err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ReprocessCollection godoc
+// @Summary Re-process a knowledge collection
+// @Description Re-chunk, re-embed, and re-extract metadata for every document with the collection's current settings
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 202 {object} knowledge.ReprocessJob
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/reprocess [post].
+func (h *KnowledgeHandler) ReprocessCollection(c echo.Context) error {
+	job, err := h.service.Reprocess(c.Request().Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// ListReprocessJobs godoc
+// @Summary List re-processing jobs of a knowledge collection
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {array} knowledge.ReprocessJob
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/reprocess-jobs [get].
+func (h *KnowledgeHandler) ListReprocessJobs(c echo.Context) error {
+	items, err := h.service.ListReprocessJobs(c.Request().Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// AddDocument godoc
+// @Summary Add a document to a knowledge collection
+// @Tags knowledge
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param request body knowledge.AddDocumentRequest true "Document"
+// @Success 201 {object} knowledge.Document
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/documents [post].
+func (h *KnowledgeHandler) AddDocument(c echo.Context) error {
+	var req knowledge.AddDocumentRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.AddDocument(c.Request().Context(), strings.TrimSpace(c.Param("id")), req)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// UploadDocument godoc
+// @Summary Upload a document file to a knowledge collection
+// @Description Adds a document from a multipart file instead of JSON text. HTML is reduced to its main content as Markdown; plain text, Markdown, CSV and JSON are stored as they are
+// @Tags knowledge
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param file formData file true "Document file"
+// @Param title formData string false "Title; defaults to the HTML title or the file name"
+// @Param source_url formData string false "Where the document came from"
+// @Success 201 {object} knowledge.Document
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/documents/upload [post].
+func (h *KnowledgeHandler) UploadDocument(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+		}
+		return uploadReadError(err)
+	}
+	if file.Size > knowledge.MaxDocumentFileBytes {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, knowledge.ErrDocumentTooLarge.Error())
+	}
+	src, err := file.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to open uploaded file")
+	}
+	defer func() { _ = src.Close() }()
+	resp, err := h.service.AddDocumentFile(c.Request().Context(), strings.TrimSpace(c.Param("id")), knowledge.DocumentFile{
+		Name:        file.Filename,
+		ContentType: file.Header.Get(echo.HeaderContentType),
+		Title:       c.FormValue("title"),
+		SourceURL:   c.FormValue("source_url"),
+		Reader:      src,
+	})
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListDocuments godoc
+// @Summary List documents in a knowledge collection
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {array} knowledge.Document
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/documents [get].
+func (h *KnowledgeHandler) ListDocuments(c echo.Context) error {
+	items, err := h.service.ListDocuments(c.Request().Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// GetDocument godoc
+// @Summary Get a knowledge document
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param doc_id path string true "Document ID"
+// @Success 200 {object} knowledge.Document
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/documents/{doc_id} [get].
+func (h *KnowledgeHandler) GetDocument(c echo.Context) error {
+	resp, err := h.service.GetDocument(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("doc_id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteDocument godoc
+// @Summary Delete a knowledge document
+// @Tags knowledge
+// @Param id path string true "Collection ID"
+// @Param doc_id path string true "Document ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/documents/{doc_id} [delete].
+func (h *KnowledgeHandler) DeleteDocument(c echo.Context) error {
+	if err := h.service.DeleteDocument(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("doc_id"))); err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateCrawlSource godoc
+// @Summary Add a website crawl source to a knowledge collection
+// @Description Crawl pages from seed URLs into the collection, optionally refreshed on a cron schedule
+// @Tags knowledge
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param request body knowledge.CreateCrawlSourceRequest true "Crawl source"
+// @Success 201 {object} knowledge.CrawlSource
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/crawl-sources [post].
+func (h *KnowledgeHandler) CreateCrawlSource(c echo.Context) error {
+	var req knowledge.CreateCrawlSourceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.CreateCrawlSource(c.Request().Context(), strings.TrimSpace(c.Param("id")), req)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListCrawlSources godoc
+// @Summary List crawl sources of a knowledge collection
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {array} knowledge.CrawlSource
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/crawl-sources [get].
+func (h *KnowledgeHandler) ListCrawlSources(c echo.Context) error {
+	items, err := h.service.ListCrawlSources(c.Request().Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// GetCrawlSource godoc
+// @Summary Get a knowledge crawl source
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param source_id path string true "Crawl source ID"
+// @Success 200 {object} knowledge.CrawlSource
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/crawl-sources/{source_id} [get].
+func (h *KnowledgeHandler) GetCrawlSource(c echo.Context) error {
+	resp, err := h.service.GetCrawlSource(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("source_id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateCrawlSource godoc
+// @Summary Update a knowledge crawl source
+// @Tags knowledge
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param source_id path string true "Crawl source ID"
+// @Param request body knowledge.UpdateCrawlSourceRequest true "Crawl source"
+// @Success 200 {object} knowledge.CrawlSource
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/crawl-sources/{source_id} [put].
+func (h *KnowledgeHandler) UpdateCrawlSource(c echo.Context) error {
+	var req knowledge.UpdateCrawlSourceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.UpdateCrawlSource(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("source_id")), req)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteCrawlSource godoc
+// @Summary Delete a knowledge crawl source
+// @Description Documents already crawled stay in the collection
+// @Tags knowledge
+// @Param id path string true "Collection ID"
+// @Param source_id path string true "Crawl source ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/crawl-sources/{source_id} [delete].
+func (h *KnowledgeHandler) DeleteCrawlSource(c echo.Context) error {
+	if err := h.service.DeleteCrawlSource(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("source_id"))); err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RunCrawlSource godoc
+// @Summary Crawl a knowledge crawl source now
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param source_id path string true "Crawl source ID"
+// @Success 202 {object} knowledge.CrawlRun
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/crawl-sources/{source_id}/run [post].
+func (h *KnowledgeHandler) RunCrawlSource(c echo.Context) error {
+	run, err := h.service.RunCrawlSource(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("source_id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusAccepted, run)
+}
+
+// ListCrawlRuns godoc
+// @Summary List runs of a knowledge crawl source
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param source_id path string true "Crawl source ID"
+// @Success 200 {array} knowledge.CrawlRun
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/crawl-sources/{source_id}/runs [get].
+func (h *KnowledgeHandler) ListCrawlRuns(c echo.Context) error {
+	items, err := h.service.ListCrawlRuns(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("source_id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// ListBotCollections godoc
+// @Summary List knowledge collections attached to a bot
+// @Tags knowledge
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {array} knowledge.Collection
+// @Failure 403 {object} ErrorResponse
+// @Router /bots/{bot_id}/knowledge-collections [get].
+func (h *KnowledgeHandler) ListBotCollections(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	items, err := h.service.ListBotCollections(c.Request().Context(), botID)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// AttachCollection godoc
+// @Summary Attach a knowledge collection to a bot
+// @Tags knowledge
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Collection ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/knowledge-collections/{id} [put].
+func (h *KnowledgeHandler) AttachCollection(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	if err := h.service.AttachToBot(c.Request().Context(), botID, strings.TrimSpace(c.Param("id"))); err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DetachCollection godoc
+// @Summary Detach a knowledge collection from a bot
+// @Tags knowledge
+// @Param bot_id path string true "Bot ID"
+// @Param id path string true "Collection ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/knowledge-collections/{id} [delete].
+func (h *KnowledgeHandler) DetachCollection(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	if err := h.service.DetachFromBot(c.Request().Context(), botID, strings.TrimSpace(c.Param("id"))); err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *KnowledgeHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission); err != nil {
+		return "", err
+	}
+	return botID, nil
+}
+
+func knowledgeHTTPError(err error) error {
+	switch {
+	case errors.Is(err, knowledge.ErrCollectionNotFound), errors.Is(err, knowledge.ErrDocumentNotFound),
+		errors.Is(err, knowledge.ErrCrawlSourceNotFound), errors.Is(err, knowledge.ErrConnectorNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, knowledge.ErrExternalSourcesDisabled):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, knowledge.ErrCollectionNameTaken):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, knowledge.ErrDocumentTooLarge):
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, knowledge.ErrUnsupportedDocumentType):
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, knowledge.ErrNameRequired), errors.Is(err, knowledge.ErrContentRequired),
+		errors.Is(err, knowledge.ErrInvalidEmbeddingModel), errors.Is(err, knowledge.ErrInvalidChunking),
+		errors.Is(err, knowledge.ErrInvalidCrawlSource), errors.Is(err, knowledge.ErrInvalidConnector),
+		errors.Is(err, knowledge.ErrConnectorUnavailable), errors.Is(err, knowledge.ErrConnectorUnauthorized),
+		errors.Is(err, knowledge.ErrInvalidOAuthState), strings.Contains(err.Error(), "must be at most"):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+const knowledgeConnectorCallbackPath = "/api/knowledge/connectors/oauth/callback"
+
+// ListConnectorTypes godoc
+// @Summary List knowledge connector types
+// @Description Lists the external sources collections can sync from and whether their OAuth client is configured
+// @Tags knowledge
+// @Produce json
+// @Success 200 {array} knowledge.ConnectorMeta
+// @Router /knowledge/connector-types [get].
+func (h *KnowledgeHandler) ListConnectorTypes(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.service.ListConnectorTypes())
+}
+
+// CreateConnector godoc
+// @Summary Add a connector to a knowledge collection
+// @Description Sync documents from an external source such as Notion, Confluence, or Google Drive; authorize it afterwards
+// @Tags knowledge
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param request body knowledge.CreateConnectorRequest true "Connector"
+// @Success 201 {object} knowledge.Connector
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/connectors [post].
+func (h *KnowledgeHandler) CreateConnector(c echo.Context) error {
+	var req knowledge.CreateConnectorRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.CreateConnector(c.Request().Context(), strings.TrimSpace(c.Param("id")), req)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListConnectors godoc
+// @Summary List connectors of a knowledge collection
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {array} knowledge.Connector
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/connectors [get].
+func (h *KnowledgeHandler) ListConnectors(c echo.Context) error {
+	items, err := h.service.ListConnectors(c.Request().Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// GetConnector godoc
+// @Summary Get a knowledge connector
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param connector_id path string true "Connector ID"
+// @Success 200 {object} knowledge.Connector
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/connectors/{connector_id} [get].
+func (h *KnowledgeHandler) GetConnector(c echo.Context) error {
+	resp, err := h.service.GetConnector(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("connector_id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateConnector godoc
+// @Summary Update a knowledge connector
+// @Description Changing the config makes the next sync a full one
+// @Tags knowledge
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param connector_id path string true "Connector ID"
+// @Param request body knowledge.UpdateConnectorRequest true "Connector"
+// @Success 200 {object} knowledge.Connector
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/connectors/{connector_id} [put].
+func (h *KnowledgeHandler) UpdateConnector(c echo.Context) error {
+	var req knowledge.UpdateConnectorRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.UpdateConnector(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("connector_id")), req)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteConnector godoc
+// @Summary Delete a knowledge connector
+// @Description Documents already synced stay in the collection
+// @Tags knowledge
+// @Param id path string true "Collection ID"
+// @Param connector_id path string true "Connector ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/connectors/{connector_id} [delete].
+func (h *KnowledgeHandler) DeleteConnector(c echo.Context) error {
+	if err := h.service.DeleteConnector(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("connector_id"))); err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SyncConnector godoc
+// @Summary Sync a knowledge connector now
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param connector_id path string true "Connector ID"
+// @Param full query bool false "Re-list every item and remove documents no longer in the source"
+// @Success 202 {object} knowledge.ConnectorSync
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/connectors/{connector_id}/sync [post].
+func (h *KnowledgeHandler) SyncConnector(c echo.Context) error {
+	full := false
+	if raw := strings.TrimSpace(c.QueryParam("full")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "full must be a boolean")
+		}
+		full = parsed
+	}
+	sync, err := h.service.SyncConnector(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("connector_id")), full)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusAccepted, sync)
+}
+
+// ListConnectorSyncs godoc
+// @Summary List syncs of a knowledge connector
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param connector_id path string true "Connector ID"
+// @Success 200 {array} knowledge.ConnectorSync
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/connectors/{connector_id}/syncs [get].
+func (h *KnowledgeHandler) ListConnectorSyncs(c echo.Context) error {
+	items, err := h.service.ListConnectorSyncs(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("connector_id")))
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// AuthorizeConnector godoc
+// @Summary Start OAuth2 authorization for a knowledge connector
+// @Description Returns the authorization URL to redirect the user to
+// @Tags knowledge
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param connector_id path string true "Connector ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/connectors/{connector_id}/oauth/authorize [get].
+func (h *KnowledgeHandler) AuthorizeConnector(c echo.Context) error {
+	callbackURL := ""
+	if baseURL := requestBaseURL(c.Request()); baseURL != "" {
+		callbackURL = strings.TrimRight(baseURL, "/") + knowledgeConnectorCallbackPath
+	}
+	state, err := generateState(callbackURL)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate state")
+	}
+	authURL, err := h.service.AuthorizeConnector(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("connector_id")), callbackURL, state)
+	if err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"auth_url": authURL})
+}
+
+// DisconnectConnector godoc
+// @Summary Revoke the stored OAuth2 token of a knowledge connector
+// @Description Synced documents are kept; scheduled syncs fail until the connector is authorized again
+// @Tags knowledge
+// @Param id path string true "Collection ID"
+// @Param connector_id path string true "Connector ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} ErrorResponse
+// @Router /knowledge/collections/{id}/connectors/{connector_id}/oauth/token [delete].
+func (h *KnowledgeHandler) DisconnectConnector(c echo.Context) error {
+	if err := h.service.DisconnectConnector(c.Request().Context(), strings.TrimSpace(c.Param("id")), strings.TrimSpace(c.Param("connector_id"))); err != nil {
+		return knowledgeHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ConnectorOAuthCallback godoc
+// @Summary OAuth2 callback for knowledge connectors
+// @Description Exchanges the code for tokens and starts a full sync
+// @Tags knowledge
+// @Param code query string true "Authorization code"
+// @Param state query string true "State parameter"
+// @Success 200 {string} string "HTML result page"
+// @Failure 400 {string} string "HTML result page"
+// @Router /knowledge/connectors/oauth/callback [get].
+func (h *KnowledgeHandler) ConnectorOAuthCallback(c echo.Context) error {
+	if msg := strings.TrimSpace(c.QueryParam("error")); msg != "" {
+		if desc := strings.TrimSpace(c.QueryParam("error_description")); desc != "" {
+			msg = desc
+		}
+		return renderKnowledgeConnectorCallbackResult(c, http.StatusBadRequest, "", "error", msg)
+	}
+	code := strings.TrimSpace(c.QueryParam("code"))
+	state := strings.TrimSpace(c.QueryParam("state"))
+	if code == "" {
+		return renderKnowledgeConnectorCallbackResult(c, http.StatusBadRequest, "", "error", "code is required")
+	}
+	if state == "" {
+		return renderKnowledgeConnectorCallbackResult(c, http.StatusBadRequest, "", "error", "state is required")
+	}
+	connector, err := h.service.CompleteConnectorOAuth(c.Request().Context(), state, code, callbackURLFromState(state))
+	if err != nil {
+		h.logger.Error("knowledge connector oauth callback failed", slog.Any("error", err))
+		var httpErr *echo.HTTPError
+		if errors.As(knowledgeHTTPError(err), &httpErr) && httpErr.Code != http.StatusInternalServerError {
+			return renderKnowledgeConnectorCallbackResult(c, httpErr.Code, "", "error", err.Error())
+		}
+		return renderKnowledgeConnectorCallbackResult(c, http.StatusInternalServerError, "", "error", "token exchange failed")
+	}
+	h.logger.Info("knowledge connector authorized", slog.String("connector_id", connector.ID), slog.String("type", connector.Type))
+	return renderKnowledgeConnectorCallbackResult(c, http.StatusOK, connector.ID, "success", "")
+}
+
+func renderKnowledgeConnectorCallbackResult(c echo.Context, statusCode int, connectorID, status, errorMessage string) error {
+	page := template.Must(template.New("knowledge-connector-oauth-result").Parse(`<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>{{if eq .Status "success"}}Connector Connected{{else}}Connector Authorization Failed{{end}}</title>
+  </head>
+  <body style="font-family: sans-serif; padding: 24px;">
+    {{if eq .Status "success"}}
+      <h2>Connector connected</h2>
+      <p>The first sync has started. You can close this window and return to Memoh.</p>
+    {{else}}
+      <h2>Connector authorization failed</h2>
+      <p>{{.Error}}</p>
+    {{end}}
+    <script>
+      window.opener?.postMessage({
+        type: "memoh-knowledge-connector-oauth-callback",
+        status: "{{.Status}}",
+        connectorId: "{{.ConnectorID}}",
+        error: "{{.Error}}"
+      }, "*");
+      setTimeout(() => window.close(), 300);
+    </script>
+  </body>
+</html>`))
+
+	return c.HTML(statusCode, executeHTMLTemplate(page, map[string]string{
+		"ConnectorID": connectorID,
+		"Status":      status,
+		"Error":       errorMessage,
+	}))
+}
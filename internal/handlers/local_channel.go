@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"maps"
 	"net/http"
@@ -66,6 +65,7 @@ type LocalChannelHandler struct {
 	speechModelResolver localSpeechModelResolver
 	wsSkillTurnsMu      sync.Mutex
 	wsSkillTurns        *wsRequestedSkillTurnRegistry
+	idempotency         *idempotencyCache
 	logger              *slog.Logger
 	jwtSecret           string
 	tokenTTL            time.Duration
@@ -87,6 +87,7 @@ func NewLocalChannelHandler(channelType channel.ChannelType, channelManager *cha
 		accountService: accountService,
 		sessionService: sessionService,
 		wsSkillTurns:   newWSRequestedSkillTurnRegistry(),
+		idempotency:    newIdempotencyCache(idempotencyTTL, idempotencyCapacity),
 		logger:         slog.Default().With(slog.String("handler", "local_channel")),
 	}
 }
@@ -127,6 +128,7 @@ func (h *LocalChannelHandler) Register(e *echo.Echo) {
 	group := e.Group(prefix)
 	group.GET("/stream", h.StreamMessages)
 	group.POST("/messages", h.PostMessage)
+	group.POST("/media", h.UploadMedia)
 	group.GET("/ws", h.HandleWebSocket)
 	e.POST("/bots/:bot_id/quick-actions/execute", h.ExecuteQuickAction)
 }
@@ -176,6 +178,7 @@ type CommandActionError struct {
 // @Accept json
 // @Produce json
 // @Param bot_id path string true "Bot ID"
+// @Param Idempotency-Key header string false "Client key that makes retries of this request return the original result"
 // @Param payload body QuickActionExecuteRequest true "Quick action payload"
 // @Success 200 {object} CommandEventResponse
 // @Failure 400 {object} ErrorResponse
@@ -194,6 +197,16 @@ func (h *LocalChannelHandler) ExecuteQuickAction(c echo.Context) error {
 	if _, err := h.authorizeBotAccess(c.Request().Context(), channelIdentityID, botID); err != nil {
 		return err
 	}
+	body, err := readIdempotentBody(c)
+	if err != nil {
+		return err
+	}
+	return serveIdempotent(c, h.idempotency, "quick_actions:"+channelIdentityID+":"+botID, body, func() error {
+		return h.executeQuickAction(c, channelIdentityID, botID)
+	})
+}
+
+func (h *LocalChannelHandler) executeQuickAction(c echo.Context, channelIdentityID, botID string) error {
 	var req QuickActionExecuteRequest
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -512,6 +525,7 @@ type LocalChannelMessageRequest struct {
 // @Accept json
 // @Produce json
 // @Param bot_id path string true "Bot ID"
+// @Param Idempotency-Key header string false "Client key that makes retries of this request return the original result instead of starting another round"
 // @Param payload body LocalChannelMessageRequest true "Message payload"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} ErrorResponse
@@ -533,11 +547,16 @@ func (h *LocalChannelHandler) PostMessage(c echo.Context) error {
 	if h.channelManager == nil || h.channelStore == nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "channel manager not configured")
 	}
-	body, readErr := io.ReadAll(c.Request().Body)
-	if readErr != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, readErr.Error())
+	body, err := readIdempotentBody(c)
+	if err != nil {
+		return err
 	}
-	c.Request().Body = io.NopCloser(bytes.NewReader(body))
+	return serveIdempotent(c, h.idempotency, "messages:"+channelIdentityID+":"+botID, body, func() error {
+		return h.postMessage(c, channelIdentityID, botID, body)
+	})
+}
+
+func (h *LocalChannelHandler) postMessage(c echo.Context, channelIdentityID, botID string, body []byte) error {
 	if jsonBodyHasKey(body, "requested_skills") {
 		event := commandEvent("", "", "", "")
 		event.Type = "command_error"
@@ -656,6 +675,9 @@ type wsClientMessage struct {
 	Reason            string                     `json:"reason,omitempty"`
 	Answers           []userinput.QuestionAnswer `json:"answers,omitempty"`
 	Canceled          bool                       `json:"canceled,omitempty"`
+	// IdempotencyKey lets a client resend a message after a dropped
+	// connection without starting a second round.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 func turnQuestionAnswers(in []userinput.QuestionAnswer) []turn.QuestionAnswer {
@@ -1181,7 +1203,15 @@ func (h *LocalChannelHandler) HandleWebSocket(c echo.Context) error {
 	streamBaseCtx := context.WithoutCancel(c.Request().Context())
 	activeStreams := newWSStreamRegistry()
 
+	// unstarted holds the idempotency claim of a message that has not handed
+	// it to a stream yet. A message that stops short of streaming releases it
+	// before the next read, so the client can resend.
+	var unstarted *wsIdempotencyClaim
+	defer func() { unstarted.release() }()
+
 	for {
+		unstarted.release()
+		unstarted = nil
 		_, raw, readErr := conn.ReadMessage()
 		if readErr != nil {
 			connCancel()
@@ -1305,6 +1335,12 @@ func (h *LocalChannelHandler) HandleWebSocket(c echo.Context) error {
 					continue
 				}
 			}
+			claim, claimErr := claimWSIdempotency(h.idempotency, "ws_messages:"+channelIdentityID+":"+botID, msg)
+			if claimErr != nil {
+				sendWSError(writer, streamID, sessionID, claimErr.Error())
+				continue
+			}
+			unstarted = claim
 			if err := authorizeWorkspaceTargetSelection(perms, workspaceTargetID); err != nil {
 				sendWSError(writer, streamID, sessionID, wsErrorMessage(err))
 				continue
@@ -1344,6 +1380,7 @@ func (h *LocalChannelHandler) HandleWebSocket(c echo.Context) error {
 				if slashErr != nil {
 					sendWSCommandError(writer, msg, slashErr.Code)
 				} else {
+					claim.complete()
 					sendWSCommandResult(writer, msg, actionID, result)
 				}
 				continue
@@ -1561,8 +1598,9 @@ func (h *LocalChannelHandler) HandleWebSocket(c echo.Context) error {
 				}
 				userMessagePersisted = true
 			}
-			h.startWSStream(streamBaseCtx, connCtx, activeStreams, writer, botID, sessionID, streamID, "ws stream error", releaseActiveWSTurn,
-				func(ctx context.Context, eventCh chan<- application.WSStreamEvent, abortCh <-chan struct{}) error {
+			unstarted = nil
+			h.startWSStream(streamBaseCtx, connCtx, activeStreams, writer, botID, sessionID, streamID, "ws stream error", claim.onFinish(releaseActiveWSTurn),
+				claim.runner(func(ctx context.Context, eventCh chan<- application.WSStreamEvent, abortCh <-chan struct{}) error {
 					// Persist inbound attachments into the media store first so each
 					// carries a content_hash. Without one the file is still inlined
 					// for the model to see, but it is never linked to the stored user
@@ -1615,7 +1653,7 @@ func (h *LocalChannelHandler) HandleWebSocket(c echo.Context) error {
 						req.WorkspaceTarget = preparedActivationReq.WorkspaceTarget
 					}
 					return h.agentService.StreamChatWS(ctx, req, eventCh, abortCh)
-				},
+				}),
 			)
 
 		case "retry_message":
@@ -1879,11 +1917,11 @@ func (h *LocalChannelHandler) resolveCurrentUserPermissions(ctx context.Context,
 	if h.botService == nil || h.accountService == nil {
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, "bot services not configured")
 	}
-	isAdmin, err := h.accountService.IsAdmin(ctx, channelIdentityID)
+	manageAll, err := managesAllBots(ctx, h.accountService, channelIdentityID)
 	if err != nil {
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	perms, err := h.botService.ResolveUserPermissions(ctx, botID, channelIdentityID, isAdmin)
+	perms, err := h.botService.ResolveUserPermissions(ctx, botID, channelIdentityID, manageAll)
 	if err != nil {
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
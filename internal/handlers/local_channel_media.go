@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	attachmentpkg "github.com/memohai/memoh/internal/attachment"
+	"github.com/memohai/memoh/internal/media"
+)
+
+// UploadMedia godoc
+// @Summary Upload a media file for a local channel message
+// @Description Streams a multipart file into the bot's media store without buffering it in memory. The response is an attachment object to put in message.attachments (or a WebSocket message's attachments) instead of inlining the file as base64
+// @Tags local-channel
+// @Accept multipart/form-data
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param file formData file true "File to upload"
+// @Success 201 {object} map[string]any
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/web/media [post].
+func (h *LocalChannelHandler) UploadMedia(c echo.Context) error {
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), channelIdentityID, botID); err != nil {
+		return err
+	}
+	if h.mediaService == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "media service not configured")
+	}
+	part, err := multipartFilePart(c, "file")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = part.Close() }()
+
+	reader, mimeType, err := attachmentpkg.PrepareReaderAndMime(part, media.MediaTypeFile, part.Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return uploadReadError(err)
+	}
+	asset, err := h.mediaService.Ingest(c.Request().Context(), media.IngestInput{
+		BotID:       botID,
+		Mime:        mimeType,
+		Reader:      reader,
+		MaxBytes:    media.MaxAssetBytes,
+		OriginalExt: strings.ToLower(filepath.Ext(part.FileName())),
+	})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.Is(err, media.ErrAssetTooLarge) || errors.As(err, &tooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "upload is too large")
+		}
+		h.logger.Error("ingest uploaded media failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to store uploaded file")
+	}
+	bundle := attachmentpkg.Bundle{Name: filepath.Base(part.FileName())}.WithAsset(botID, asset)
+	return c.JSON(http.StatusCreated, bundle.ToMap())
+}
+
+// multipartFilePart returns the named file part of a multipart request
+// without parsing the whole form, so the file can be streamed to storage.
+// Fields sent before the file are skipped.
+func multipartFilePart(c echo.Context, name string) (*multipart.Part, error) {
+	reader, err := c.Request().MultipartReader()
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "multipart/form-data body is required")
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, name+" is required")
+		}
+		if err != nil {
+			return nil, uploadReadError(err)
+		}
+		if part.FormName() == name && part.FileName() != "" {
+			return part, nil
+		}
+		_ = part.Close()
+	}
+}
+
+func uploadReadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "upload is too large")
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, "failed to read uploaded file")
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/maintenance"
+	"github.com/memohai/memoh/internal/policy"
+)
+
+// MaintenanceHandler toggles deployment-wide maintenance mode for planned
+// upgrades.
+type MaintenanceHandler struct {
+	service        *maintenance.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+type EnableMaintenanceRequest struct {
+	// Message is the notice bots send; empty sends the localized default.
+	Message string `json:"message,omitempty"`
+}
+
+func NewMaintenanceHandler(log *slog.Logger, service *maintenance.Service, accountService *accounts.Service) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		service:        service,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "maintenance")),
+	}
+}
+
+func (h *MaintenanceHandler) Register(e *echo.Echo) {
+	e.GET("/maintenance", h.Status)
+	e.PUT("/maintenance", h.Enable)
+	e.DELETE("/maintenance", h.Disable)
+}
+
+// Status godoc
+// @Summary Get maintenance mode (requires operations.manage)
+// @Description Whether maintenance mode is on, the notice bots send and when it started
+// @Tags maintenance
+// @Produce json
+// @Success 200 {object} maintenance.State
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance [get].
+func (h *MaintenanceHandler) Status(c echo.Context) error {
+	if _, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage); err != nil {
+		return err
+	}
+	state, err := h.service.Status(c.Request().Context())
+	if err != nil {
+		return h.maintenanceError(err, "get maintenance mode failed")
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+// Enable godoc
+// @Summary Turn maintenance mode on (requires operations.manage)
+// @Description Bots answer every conversation with the notice instead of running the agent, and schedules are paused. Adapters stay connected. Calling it again while on replaces the notice
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param payload body EnableMaintenanceRequest false "Notice"
+// @Success 200 {object} maintenance.State
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance [put].
+func (h *MaintenanceHandler) Enable(c echo.Context) error {
+	userID, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage)
+	if err != nil {
+		return err
+	}
+	var req EnableMaintenanceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	state, err := h.service.Enable(c.Request().Context(), req.Message, userID)
+	if err != nil {
+		return h.maintenanceError(err, "enable maintenance mode failed")
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+// Disable godoc
+// @Summary Turn maintenance mode off (requires operations.manage)
+// @Description Bots answer normally again and schedules resume. Runs due during maintenance are not caught up
+// @Tags maintenance
+// @Produce json
+// @Success 200 {object} maintenance.State
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /maintenance [delete].
+func (h *MaintenanceHandler) Disable(c echo.Context) error {
+	userID, err := RequirePermission(c, h.accountService, policy.PermissionOperationsManage)
+	if err != nil {
+		return err
+	}
+	state, err := h.service.Disable(c.Request().Context(), userID)
+	if err != nil {
+		return h.maintenanceError(err, "disable maintenance mode failed")
+	}
+	return c.JSON(http.StatusOK, state)
+}
+
+func (h *MaintenanceHandler) maintenanceError(err error, msg string) error {
+	if errors.Is(err, maintenance.ErrMessageTooLong) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	h.logger.Error(msg, slog.Any("error", err))
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
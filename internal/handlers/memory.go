@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,40 +14,61 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/audit"
 	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/featureflags"
 	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	memcompaction "github.com/memohai/memoh/internal/memory/compaction"
+	memflags "github.com/memohai/memoh/internal/memory/flags"
 	"github.com/memohai/memoh/internal/memory/migrate"
+	"github.com/memohai/memoh/internal/schedule"
 	"github.com/memohai/memoh/internal/settings"
 )
 
 // MemoryHandler handles memory CRUD operations scoped by bot.
 type MemoryHandler struct {
-	botService      *bots.Service
-	accountService  *accounts.Service
-	settingsService *settings.Service
-	memoryRegistry  *memprovider.Registry
-	logger          *slog.Logger
+	botService        *bots.Service
+	accountService    *accounts.Service
+	settingsService   *settings.Service
+	memoryRegistry    *memprovider.Registry
+	flagService       *memflags.Service
+	compactionService *memcompaction.Service
+	scheduleService   *schedule.Service
+	featureFlags      featureflags.Checker
+	audit             *audit.Service
+	logger            *slog.Logger
 }
 
 type memoryAddPayload struct {
-	Message          string                `json:"message,omitempty"`
-	Messages         []memprovider.Message `json:"messages,omitempty"`
-	Namespace        string                `json:"namespace,omitempty"`
-	RunID            string                `json:"run_id,omitempty"`
-	Metadata         map[string]any        `json:"metadata,omitempty"`
-	Filters          map[string]any        `json:"filters,omitempty"`
-	Infer            *bool                 `json:"infer,omitempty"`
-	EmbeddingEnabled *bool                 `json:"embedding_enabled,omitempty"`
+	Message   string                `json:"message,omitempty"`
+	Messages  []memprovider.Message `json:"messages,omitempty"`
+	Namespace string                `json:"namespace,omitempty"`
+	RunID     string                `json:"run_id,omitempty"`
+	// UserID scopes the memory to one user of the bot.
+	UserID           string         `json:"user_id,omitempty"`
+	Metadata         map[string]any `json:"metadata,omitempty"`
+	Filters          map[string]any `json:"filters,omitempty"`
+	Infer            *bool          `json:"infer,omitempty"`
+	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
+	// TTLSeconds makes the memory expire after this many seconds.
+	TTLSeconds int64 `json:"ttl_seconds,omitempty"`
 }
 
 type memorySearchPayload struct {
-	Query            string         `json:"query"`
-	RunID            string         `json:"run_id,omitempty"`
+	Query string `json:"query"`
+	RunID string `json:"run_id,omitempty"`
+	// UserID limits results to the user's memories and bot-wide ones.
+	UserID           string         `json:"user_id,omitempty"`
 	Limit            int            `json:"limit,omitempty"`
 	Filters          map[string]any `json:"filters,omitempty"`
 	Sources          []string       `json:"sources,omitempty"`
 	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
 	NoStats          bool           `json:"no_stats,omitempty"`
+	// Fusion overrides the hybrid search fusion configured on the bot's
+	// memory provider.
+	Fusion *memprovider.SearchFusion `json:"fusion,omitempty"`
+	// Rerank reorders results with the provider's rerank model.
+	Rerank bool `json:"rerank,omitempty"`
 }
 
 type memoryDeletePayload struct {
@@ -92,6 +114,29 @@ func (h *MemoryHandler) SetSettingsService(svc *settings.Service) {
 	h.settingsService = svc
 }
 
+// SetFeatureFlags sets the flags that gate hybrid search per bot.
+func (h *MemoryHandler) SetFeatureFlags(flags featureflags.Checker) {
+	h.featureFlags = flags
+}
+
+// SetAuditLog records deleting all of a bot's memories in the audit log.
+func (h *MemoryHandler) SetAuditLog(service *audit.Service) {
+	h.audit = service
+}
+
+// memoryDeleteOutcome summarises a delete-all across namespaces for the
+// audit log: "succeeded", "partial" or "failed".
+func memoryDeleteOutcome(total, failed int) string {
+	switch {
+	case failed == 0:
+		return "succeeded"
+	case failed < total:
+		return "partial"
+	default:
+		return "failed"
+	}
+}
+
 // resolveProvider returns the memory provider for a bot. An explicitly selected
 // provider must be available; only bots without a selected provider may fall
 // back to the builtin default.
@@ -126,12 +171,23 @@ func (h *MemoryHandler) Register(e *echo.Echo) {
 	chatGroup.POST("", h.ChatAdd)
 	chatGroup.POST("/search", h.ChatSearch)
 	chatGroup.POST("/compact", h.ChatCompact)
+	chatGroup.GET("/compact/runs", h.ListCompactRuns)
+	chatGroup.GET("/compact/schedule", h.GetCompactSchedule)
+	chatGroup.PUT("/compact/schedule", h.SetCompactSchedule)
+	chatGroup.DELETE("/compact/schedule", h.DeleteCompactSchedule)
 	chatGroup.POST("/rebuild", h.ChatRebuild)
 	chatGroup.POST("/ingest", h.ChatIngest)
+	chatGroup.POST("/reembed", h.ChatReembed)
+	chatGroup.POST("/import", h.ChatImport)
+	chatGroup.GET("/export", h.ChatExport)
 	chatGroup.GET("/status", h.ChatStatus)
 	chatGroup.GET("", h.ChatGetAll)
 	chatGroup.GET("/usage", h.ChatUsage)
 	chatGroup.GET("/graph", h.ChatGraph)
+	chatGroup.GET("/analytics", h.ChatAnalytics)
+	chatGroup.GET("/flags", h.ListFlags)
+	chatGroup.POST("/flags/:flag_id/confirm", h.ConfirmFlag)
+	chatGroup.POST("/flags/:flag_id/dismiss", h.DismissFlag)
 	chatGroup.DELETE("", h.ChatDelete)
 	chatGroup.PUT("/:memory_id", h.ChatUpdate)
 	chatGroup.DELETE("/:memory_id", h.ChatDeleteOne)
@@ -152,7 +208,7 @@ func (h *MemoryHandler) checkService(ctx context.Context, botID string) (memprov
 
 // ChatAdd godoc
 // @Summary Add memory
-// @Description Add memory into the bot-shared namespace
+// @Description Add memory into the bot-shared namespace. With ttl_seconds the memory is deleted once it expires
 // @Tags memory
 // @Accept json
 // @Produce json
@@ -174,6 +230,9 @@ func (h *MemoryHandler) ChatAdd(c echo.Context) error {
 	if err := c.Bind(&payload); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	if payload.TTLSeconds < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "ttl_seconds must not be negative")
+	}
 
 	namespace, err := normalizeSharedMemoryNamespace(payload.Namespace)
 	if err != nil {
@@ -195,10 +254,12 @@ func (h *MemoryHandler) ChatAdd(c echo.Context) error {
 		Messages:         payload.Messages,
 		BotID:            resolvedBotID,
 		RunID:            payload.RunID,
+		UserID:           strings.TrimSpace(payload.UserID),
 		Metadata:         memprovider.MergeMetadata(payload.Metadata, memprovider.BuildProfileMetadata("", channelIdentityID, "")),
 		Filters:          filters,
 		Infer:            payload.Infer,
 		EmbeddingEnabled: payload.EmbeddingEnabled,
+		TTL:              time.Duration(payload.TTLSeconds) * time.Second,
 	}
 
 	provider, checkErr := h.checkService(c.Request().Context(), resolvedBotID)
@@ -214,7 +275,7 @@ func (h *MemoryHandler) ChatAdd(c echo.Context) error {
 
 // ChatSearch godoc
 // @Summary Search memory
-// @Description Search memory in the bot-shared namespace
+// @Description Search memory in the bot-shared namespace. user_id limits results to that user's memories and bot-wide ones. fusion overrides how the provider combines semantic and lexical matches (max, rrf or relative, with dense_weight and sparse_weight); rerank reorders the top candidates with the provider's rerank model
 // @Tags memory
 // @Accept json
 // @Produce json
@@ -237,6 +298,14 @@ func (h *MemoryHandler) ChatSearch(c echo.Context) error {
 	if err := c.Bind(&payload); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	if payload.Fusion != nil {
+		if err := payload.Fusion.Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	if h.featureFlags != nil && !h.featureFlags.Enabled(c.Request().Context(), featureflags.HybridSearch, botID) {
+		payload.Fusion = memprovider.ClassicSearchFusion()
+	}
 
 	scopes, err := h.resolveEnabledScopes(botID)
 	if err != nil {
@@ -254,11 +323,14 @@ func (h *MemoryHandler) ChatSearch(c echo.Context) error {
 			Query:            payload.Query,
 			BotID:            botID,
 			RunID:            payload.RunID,
+			UserID:           strings.TrimSpace(payload.UserID),
 			Limit:            payload.Limit,
 			Filters:          filters,
 			Sources:          payload.Sources,
 			EmbeddingEnabled: payload.EmbeddingEnabled,
 			NoStats:          payload.NoStats,
+			Fusion:           payload.Fusion,
+			Rerank:           payload.Rerank,
 		}
 		resp, searchErr := provider.Search(c.Request().Context(), req)
 		if searchErr != nil {
@@ -740,14 +812,28 @@ func (h *MemoryHandler) ChatDelete(c echo.Context) error {
 	if err != nil {
 		return err
 	}
+	namespaces := make([]string, 0, len(scopes))
+	failures := map[string]string{}
 	for _, scope := range scopes {
+		namespaces = append(namespaces, scope.Namespace)
 		req := memprovider.DeleteAllRequest{
 			Filters: buildNamespaceFilters(scope.Namespace, scope.ScopeID, nil),
 		}
 		if _, delErr := provider.DeleteAll(c.Request().Context(), req); delErr != nil {
+			failures[scope.Namespace] = delErr.Error()
 			h.logger.Warn("deleteall namespace failed", slog.String("namespace", scope.Namespace), slog.Any("error", delErr))
 		}
 	}
+	details := map[string]any{"namespaces": namespaces, "outcome": memoryDeleteOutcome(len(namespaces), len(failures))}
+	if len(failures) > 0 {
+		details["errors"] = failures
+	}
+	recordAudit(c, h.audit, audit.Entry{
+		Action:     audit.ActionMemoryDeletedAll,
+		TargetType: audit.TargetBot,
+		TargetID:   botID,
+		Details:    details,
+	})
 	return c.JSON(http.StatusOK, memprovider.DeleteResponse{Message: "All memories deleted successfully!"})
 }
 
@@ -897,6 +983,18 @@ func (h *MemoryHandler) ChatCompact(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusNotImplemented, reason)
 	}
 
+	if h.compactionService != nil {
+		// Compacting through the service records the run in the history.
+		result, err := h.compactionService.Compact(c.Request().Context(), botID, memcompaction.Request{
+			Ratio:     ratio,
+			DecayDays: decayDays,
+			Source:    memcompaction.SourceManual,
+		})
+		if err != nil {
+			return memoryCompactHTTPError(err)
+		}
+		return c.JSON(http.StatusOK, result)
+	}
 	scope := scopes[0]
 	filters := buildNamespaceFilters(scope.Namespace, scope.ScopeID, nil)
 	result, err := provider.Compact(c.Request().Context(), filters, ratio, decayDays)
@@ -1024,6 +1122,44 @@ func (h *MemoryHandler) ChatIngest(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
+// ChatReembed godoc
+// @Summary Re-embed memories in the background
+// @Description Reload the embedding model and re-index every memory of the bot into the semantic index, rate-limited. Use it after the embedding provider changed vector dimensions: update the model's dimensions first, then start the job and follow its progress in the memory status.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 202 {object} adapters.ReembedStatus
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/reembed [post].
+func (h *MemoryHandler) ChatReembed(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	provider, checkErr := h.checkService(c.Request().Context(), botID)
+	if checkErr != nil {
+		return checkErr
+	}
+	reembedProvider, ok := provider.(memprovider.ReembedProvider)
+	if !ok {
+		return echo.NewHTTPError(http.StatusConflict, memprovider.ErrReembedUnavailable.Error())
+	}
+	status, err := reembedProvider.StartReembed(c.Request().Context(), botID)
+	switch {
+	case errors.Is(err, memprovider.ErrEmbeddingDimensionsUnresolved):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, memprovider.ErrReembedRunning), errors.Is(err, memprovider.ErrReembedUnavailable):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusAccepted, status)
+}
+
 // ChatStatus godoc
 // @Summary Get memory runtime status
 // @Description Get the resolved memory runtime status for a bot, including index health and source counts
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/labstack/echo/v4"
+
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/memory/migrate"
+)
+
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+	analyticsTopLimit    = 20
+)
+
+type memoryAnalyticsResponse struct {
+	Total     int                     `json:"total"`
+	Days      int                     `json:"days"`
+	Growth    []memoryGrowthPoint     `json:"growth"`
+	Layers    []memoryAnalyticsBucket `json:"layers"`
+	Topics    []memoryAnalyticsBucket `json:"topics"`
+	Languages []memoryAnalyticsBucket `json:"languages"`
+	Entities  []memoryAnalyticsBucket `json:"entities"`
+}
+
+// memoryGrowthPoint is one UTC day: memories captured that day and the
+// running total at its end.
+type memoryGrowthPoint struct {
+	Date  string `json:"date"`
+	Added int    `json:"added"`
+	Total int    `json:"total"`
+}
+
+type memoryAnalyticsBucket struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// ChatAnalytics godoc
+// @Summary Get memory analytics
+// @Description Summarize a bot's memories for dashboards: daily growth over the last days (default 30, max 365), distribution by layer and topic, writing system of the memory text (latin, han, kana, hangul, cyrillic, ...) and the most recurring subjects of the memory graph.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param days query int false "Days of growth history"
+// @Success 200 {object} memoryAnalyticsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/analytics [get].
+func (h *MemoryHandler) ChatAnalytics(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	days := defaultAnalyticsDays
+	if raw := strings.TrimSpace(c.QueryParam("days")); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil || days <= 0 || days > maxAnalyticsDays {
+			return echo.NewHTTPError(http.StatusBadRequest, "days must be between 1 and 365")
+		}
+	}
+	specs, err := h.memorySpecs(c.Request().Context(), botID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, buildMemoryAnalytics(specs, time.Now().UTC(), days))
+}
+
+// memorySpecs returns the bot's deduplicated memories as graph node specs.
+func (h *MemoryHandler) memorySpecs(ctx context.Context, botID string) ([]migrate.NodeSpec, error) {
+	scopes, err := h.resolveEnabledScopes(botID)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := h.checkService(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+
+	var allResults []memprovider.MemoryItem
+	for _, scope := range scopes {
+		resp, getAllErr := provider.GetAll(ctx, memprovider.GetAllRequest{
+			Filters: buildNamespaceFilters(scope.Namespace, scope.ScopeID, nil),
+			NoStats: true,
+		})
+		if getAllErr != nil {
+			continue
+		}
+		allResults = append(allResults, resp.Results...)
+	}
+	allResults = deduplicateMemoryItems(botID, allResults)
+
+	specs := make([]migrate.NodeSpec, 0, len(allResults))
+	for _, item := range allResults {
+		specs = append(specs, memoryItemToGraphNodeSpec(botID, canonicalizeMemoryItem(botID, item)))
+	}
+	return specs, nil
+}
+
+// MemoryGrowth counts the bot's memories captured in [from, to) and those
+// captured before to. Memories without a capture time only count toward the
+// total.
+func (h *MemoryHandler) MemoryGrowth(ctx context.Context, botID string, from, to time.Time) (added, total int, err error) {
+	specs, err := h.memorySpecs(ctx, botID)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, spec := range specs {
+		if spec.CapturedAt.IsZero() {
+			total++
+			continue
+		}
+		if !spec.CapturedAt.Before(to) {
+			continue
+		}
+		total++
+		if !spec.CapturedAt.Before(from) {
+			added++
+		}
+	}
+	return added, total, nil
+}
+
+func buildMemoryAnalytics(specs []migrate.NodeSpec, now time.Time, days int) memoryAnalyticsResponse {
+	resp := memoryAnalyticsResponse{Total: len(specs), Days: days}
+	layers := map[string]int{}
+	topics := map[string]int{}
+	languages := map[string]int{}
+	entities := map[string]int{}
+	added := map[string]int{}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -(days - 1))
+	before := 0
+	for _, spec := range specs {
+		layers[string(spec.Layer)]++
+		if topic := strings.ToLower(strings.TrimSpace(spec.Topic)); topic != "" {
+			topics[topic]++
+		}
+		if script := dominantScript(spec.Body); script != "" {
+			languages[script]++
+		}
+		if subject := strings.TrimSpace(spec.Subject); subject != "" {
+			entities[subject]++
+		}
+		if spec.CapturedAt.IsZero() {
+			continue
+		}
+		captured := spec.CapturedAt.UTC()
+		if captured.Before(start) {
+			before++
+			continue
+		}
+		added[captured.Format(time.DateOnly)]++
+	}
+
+	total := before
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		key := day.Format(time.DateOnly)
+		total += added[key]
+		resp.Growth = append(resp.Growth, memoryGrowthPoint{Date: key, Added: added[key], Total: total})
+	}
+	resp.Layers = topAnalyticsBuckets(layers, 0)
+	resp.Topics = topAnalyticsBuckets(topics, analyticsTopLimit)
+	resp.Languages = topAnalyticsBuckets(languages, 0)
+	resp.Entities = topAnalyticsBuckets(entities, analyticsTopLimit)
+	return resp
+}
+
+func topAnalyticsBuckets(counts map[string]int, limit int) []memoryAnalyticsBucket {
+	out := make([]memoryAnalyticsBucket, 0, len(counts))
+	for key, count := range counts {
+		out = append(out, memoryAnalyticsBucket{Key: key, Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key < out[j].Key
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+var analyticsScripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"hangul", unicode.Hangul},
+	{"kana", unicode.Hiragana},
+	{"kana", unicode.Katakana},
+	{"han", unicode.Han},
+	{"cyrillic", unicode.Cyrillic},
+	{"arabic", unicode.Arabic},
+	{"hebrew", unicode.Hebrew},
+	{"greek", unicode.Greek},
+	{"devanagari", unicode.Devanagari},
+	{"thai", unicode.Thai},
+	{"latin", unicode.Latin},
+}
+
+// analyticsScriptOrder breaks ties between scripts with equal letter counts.
+var analyticsScriptOrder = []string{"latin", "han", "hangul", "cyrillic", "arabic", "hebrew", "greek", "devanagari", "thai", "other"}
+
+// dominantScript names the writing system most letters of text belong to.
+// Any kana marks Japanese text as kana even though it mixes in han.
+func dominantScript(text string) string {
+	counts := map[string]int{}
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		name := "other"
+		for _, script := range analyticsScripts {
+			if unicode.Is(script.table, r) {
+				name = script.name
+				break
+			}
+		}
+		counts[name]++
+	}
+	if counts["kana"] > 0 {
+		return "kana"
+	}
+	best, bestCount := "", 0
+	for _, name := range analyticsScriptOrder {
+		if n := counts[name]; n > bestCount {
+			best, bestCount = name, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/memory/migrate"
+)
+
+func TestBuildMemoryAnalytics(t *testing.T) {
+	now := time.Date(2026, 6, 10, 15, 0, 0, 0, time.UTC)
+	specs := []migrate.NodeSpec{
+		{Body: "User prefers oolong tea", Layer: migrate.LayerPreference, Topic: "Drinks", Subject: "Alice", CapturedAt: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)},
+		{Body: "User lives in Berlin", Layer: migrate.LayerNote, Topic: "location", Subject: "Alice", CapturedAt: time.Date(2026, 6, 9, 9, 0, 0, 0, time.UTC)},
+		{Body: "用户喜欢喝茶", Layer: migrate.LayerPreference, Topic: "drinks", Subject: "Bob", CapturedAt: time.Date(2026, 6, 10, 1, 0, 0, 0, time.UTC)},
+		{Body: "今日はラーメンを食べた", Layer: migrate.LayerActivity, CapturedAt: time.Date(2026, 6, 10, 2, 0, 0, 0, time.UTC)},
+		{Body: "12345", Layer: migrate.LayerNote},
+	}
+
+	resp := buildMemoryAnalytics(specs, now, 3)
+
+	if resp.Total != 5 || len(resp.Growth) != 3 {
+		t.Fatalf("unexpected totals: total=%d growth=%d", resp.Total, len(resp.Growth))
+	}
+	wantGrowth := []memoryGrowthPoint{
+		{Date: "2026-06-08", Added: 0, Total: 1},
+		{Date: "2026-06-09", Added: 1, Total: 2},
+		{Date: "2026-06-10", Added: 2, Total: 4},
+	}
+	for i, want := range wantGrowth {
+		if resp.Growth[i] != want {
+			t.Fatalf("growth[%d] = %+v, want %+v", i, resp.Growth[i], want)
+		}
+	}
+	if resp.Layers[0] != (memoryAnalyticsBucket{Key: "note", Count: 2}) || resp.Layers[1] != (memoryAnalyticsBucket{Key: "preference", Count: 2}) {
+		t.Fatalf("layers should sort by count then key, got %+v", resp.Layers)
+	}
+	if resp.Topics[0] != (memoryAnalyticsBucket{Key: "drinks", Count: 2}) {
+		t.Fatalf("topics should be case-folded, got %+v", resp.Topics)
+	}
+	if resp.Entities[0] != (memoryAnalyticsBucket{Key: "Alice", Count: 2}) {
+		t.Fatalf("unexpected entities: %+v", resp.Entities)
+	}
+	languages := map[string]int{}
+	for _, bucket := range resp.Languages {
+		languages[bucket.Key] = bucket.Count
+	}
+	if languages["latin"] != 2 || languages["han"] != 1 || languages["kana"] != 1 || len(languages) != 3 {
+		t.Fatalf("unexpected languages: %+v", resp.Languages)
+	}
+}
+
+func TestDominantScript(t *testing.T) {
+	cases := map[string]string{
+		"Hello world":      "latin",
+		"Привет, мир":      "cyrillic",
+		"안녕하세요":            "hangul",
+		"東京タワーに行った":        "kana",
+		"OpenAI 发布了新模型和工具": "han",
+		"42 + 7":           "",
+	}
+	for text, want := range cases {
+		if got := dominantScript(text); got != want {
+			t.Fatalf("dominantScript(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	memcompaction "github.com/memohai/memoh/internal/memory/compaction"
+	"github.com/memohai/memoh/internal/schedule"
+)
+
+// memoryCompactRunsResponse lists memory compaction runs.
+type memoryCompactRunsResponse struct {
+	Items []memcompaction.Run `json:"items"`
+}
+
+// SetCompactionService sets the service that runs memory compaction and
+// records its history.
+func (h *MemoryHandler) SetCompactionService(svc *memcompaction.Service) {
+	h.compactionService = svc
+}
+
+// SetScheduleService sets the schedule service holding automatic memory
+// compaction schedules.
+func (h *MemoryHandler) SetScheduleService(svc *schedule.Service) {
+	h.scheduleService = svc
+}
+
+// ListCompactRuns godoc
+// @Summary List memory compaction runs
+// @Description List manual and scheduled memory compaction runs of the bot, newest first.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param limit query int false "Maximum number of runs (default 50, max 200)"
+// @Success 200 {object} memoryCompactRunsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/compact/runs [get].
+func (h *MemoryHandler) ListCompactRuns(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if h.compactionService == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "memory compaction not available")
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+	}
+	items, err := h.compactionService.ListRuns(c.Request().Context(), botID, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, memoryCompactRunsResponse{Items: items})
+}
+
+// GetCompactSchedule godoc
+// @Summary Get memory compaction schedule
+// @Description Get the cron schedule that compacts the bot's memories automatically.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} schedule.MemoryCompaction
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/compact/schedule [get].
+func (h *MemoryHandler) GetCompactSchedule(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if h.scheduleService == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "schedule service not available")
+	}
+	item, err := h.scheduleService.GetMemoryCompaction(c.Request().Context(), botID)
+	if err != nil {
+		return memoryCompactScheduleHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// SetCompactSchedule godoc
+// @Summary Set memory compaction schedule
+// @Description Create or replace the cron schedule that compacts the bot's memories automatically,
+// @Description e.g. pattern "0 3 * * 0" for weekly. ratio and decay_days work as for a manual compact.
+// @Description The pattern runs in timezone, or the bot's timezone when empty.
+// @Tags memory
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param payload body schedule.MemoryCompactionRequest true "Memory compaction schedule"
+// @Success 200 {object} schedule.MemoryCompaction
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/compact/schedule [put].
+func (h *MemoryHandler) SetCompactSchedule(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if h.scheduleService == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "schedule service not available")
+	}
+	var req schedule.MemoryCompactionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// Refuse to schedule runs that could only fail.
+	if h.compactionService != nil && (req.Enabled == nil || *req.Enabled) {
+		capability := h.compactionService.SemanticCapability(c.Request().Context(), botID)
+		if !capability.Semantic {
+			return echo.NewHTTPError(http.StatusNotImplemented, capability.Reason)
+		}
+	}
+	item, err := h.scheduleService.SetMemoryCompaction(c.Request().Context(), botID, req)
+	if err != nil {
+		return memoryCompactScheduleHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// DeleteCompactSchedule godoc
+// @Summary Delete memory compaction schedule
+// @Description Stop compacting the bot's memories automatically.
+// @Tags memory
+// @Param bot_id path string true "Bot ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/compact/schedule [delete].
+func (h *MemoryHandler) DeleteCompactSchedule(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if h.scheduleService == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "schedule service not available")
+	}
+	if err := h.scheduleService.DeleteMemoryCompaction(c.Request().Context(), botID); err != nil {
+		return memoryCompactScheduleHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func memoryCompactHTTPError(err error) error {
+	switch {
+	case errors.Is(err, memcompaction.ErrInvalidRequest):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, memcompaction.ErrNotSupported):
+		return echo.NewHTTPError(http.StatusNotImplemented, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+func memoryCompactScheduleHTTPError(err error) error {
+	switch {
+	case errors.Is(err, schedule.ErrInvalidMemoryCompaction):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, schedule.ErrMemoryCompactionNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
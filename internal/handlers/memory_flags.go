@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	memflags "github.com/memohai/memoh/internal/memory/flags"
+)
+
+// SetFlagService sets the service holding memories the agent flagged as
+// outdated.
+func (h *MemoryHandler) SetFlagService(svc *memflags.Service) {
+	h.flagService = svc
+}
+
+// ListFlags godoc
+// @Summary List flagged memories
+// @Description List memories the bot flagged as outdated, newest first. Pending flags wait for the owner to confirm or dismiss them.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param status query string false "pending, confirmed or dismissed; all when omitted"
+// @Param limit query int false "Maximum number of flags"
+// @Success 200 {object} flags.ListFlagsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/flags [get].
+func (h *MemoryHandler) ListFlags(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if h.flagService == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "memory flags not available")
+	}
+	limit := 0
+	if raw := strings.TrimSpace(c.QueryParam("limit")); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+	}
+	items, err := h.flagService.List(c.Request().Context(), botID, c.QueryParam("status"), limit)
+	if err != nil {
+		return memoryFlagHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, memflags.ListFlagsResponse{Items: items})
+}
+
+// ConfirmFlag godoc
+// @Summary Confirm a flagged memory
+// @Description Delete the flagged memory and mark the flag confirmed.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param flag_id path string true "Flag ID"
+// @Success 200 {object} flags.Flag
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/flags/{flag_id}/confirm [post].
+func (h *MemoryHandler) ConfirmFlag(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if h.flagService == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "memory flags not available")
+	}
+	ctx := c.Request().Context()
+	flagID := strings.TrimSpace(c.Param("flag_id"))
+	flag, err := h.flagService.Get(ctx, botID, flagID)
+	if err != nil {
+		return memoryFlagHTTPError(err)
+	}
+	if flag.Status != memflags.StatusPending {
+		return memoryFlagHTTPError(memflags.ErrFlagResolved)
+	}
+	if err := requireMemoryOwnedByBot(botID, flag.MemoryID); err != nil {
+		return err
+	}
+	provider, err := h.checkService(ctx, botID)
+	if err != nil {
+		return err
+	}
+	// Delete first: a confirmed flag must never leave the memory behind.
+	if _, err := provider.Delete(ctx, flag.MemoryID); err != nil {
+		h.logger.Warn("delete flagged memory failed", slog.String("memory_id", flag.MemoryID), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	flag, err = h.flagService.Resolve(ctx, botID, flagID, memflags.StatusConfirmed)
+	if err != nil {
+		return memoryFlagHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, flag)
+}
+
+// DismissFlag godoc
+// @Summary Dismiss a flagged memory
+// @Description Keep the flagged memory and mark the flag dismissed.
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param flag_id path string true "Flag ID"
+// @Success 200 {object} flags.Flag
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/flags/{flag_id}/dismiss [post].
+func (h *MemoryHandler) DismissFlag(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if h.flagService == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "memory flags not available")
+	}
+	flag, err := h.flagService.Resolve(c.Request().Context(), botID, strings.TrimSpace(c.Param("flag_id")), memflags.StatusDismissed)
+	if err != nil {
+		return memoryFlagHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, flag)
+}
+
+func memoryFlagHTTPError(err error) error {
+	switch {
+	case errors.Is(err, memflags.ErrInvalidStatus):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, memflags.ErrFlagNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, memflags.ErrFlagResolved):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
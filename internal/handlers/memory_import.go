@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/memory/migrate"
+)
+
+const (
+	maxMemoryImportBytes = 32 << 20
+	maxMemoryImportItems = 10000
+)
+
+// MemoryImportResponse reports the outcome of importing an external memory
+// export.
+type MemoryImportResponse struct {
+	Format   string   `json:"format"`
+	Total    int      `json:"total"`
+	Imported int      `json:"imported"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// memoryExportRecord is one line of a memory export.
+type memoryExportRecord struct {
+	ID        string         `json:"id"`
+	Memory    string         `json:"memory"`
+	CreatedAt string         `json:"created_at,omitempty"`
+	UpdatedAt string         `json:"updated_at,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// ChatExport godoc
+// @Summary Export memories
+// @Description Downloads every memory of the bot as JSON Lines, one memory with its metadata per line. Import the file with format memoh to restore it, re-embedding each memory with the bot's current provider.
+// @Tags memory
+// @Produce application/x-ndjson
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {file} file
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/export [get].
+func (h *MemoryHandler) ChatExport(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	scopes, err := h.resolveEnabledScopes(botID)
+	if err != nil {
+		return err
+	}
+	provider, err := h.checkService(c.Request().Context(), botID)
+	if err != nil {
+		return err
+	}
+	// Collect everything before writing so a failing namespace surfaces as an
+	// error instead of a truncated backup.
+	var items []memprovider.MemoryItem
+	for _, scope := range scopes {
+		resp, err := provider.GetAll(c.Request().Context(), memprovider.GetAllRequest{
+			Filters: buildNamespaceFilters(scope.Namespace, scope.ScopeID, nil),
+			NoStats: true,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "export failed: "+err.Error())
+		}
+		items = append(items, resp.Results...)
+	}
+	items = deduplicateMemoryItems(botID, items)
+
+	filename := fmt.Sprintf("bot-%s-memories-%s.jsonl", botID, time.Now().UTC().Format("20060102T150405Z"))
+	c.Response().Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	c.Response().WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(c.Response())
+	for _, item := range items {
+		if err := encoder.Encode(memoryExportRecord{
+			ID:        item.ID,
+			Memory:    item.Memory,
+			CreatedAt: item.CreatedAt,
+			UpdatedAt: item.UpdatedAt,
+			Metadata:  item.Metadata,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChatImport godoc
+// @Summary Import memories from mem0, Letta or a Memoh export
+// @Description Imports a mem0 get_all export, a Letta archival memory / agent file export, or a Memoh memory export (JSON Lines) into the bot-shared namespace. Each memory is stored verbatim (no LLM extraction) and embedded by the selected provider. Letta core memory blocks map to the identity (human), persona and context layers; mem0 categories and Letta tags become topics.
+// @Tags memory
+// @Accept multipart/form-data
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param file formData file true "Memory export JSON or JSON Lines"
+// @Param format formData string true "mem0, letta or memoh"
+// @Success 200 {object} MemoryImportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory/import [post].
+func (h *MemoryHandler) ChatImport(c echo.Context) error {
+	botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	format := strings.ToLower(strings.TrimSpace(c.FormValue("format")))
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+	if file.Size > maxMemoryImportBytes {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "export file is too large")
+	}
+	src, err := file.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to open uploaded file")
+	}
+	defer func() { _ = src.Close() }()
+	data, err := io.ReadAll(io.LimitReader(src, maxMemoryImportBytes))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to read uploaded file")
+	}
+
+	items, err := migrate.ParseExternal(format, data)
+	if err != nil {
+		if errors.Is(err, migrate.ErrUnsupportedExternalFormat) || errors.Is(err, migrate.ErrInvalidExternalExport) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if len(items) > maxMemoryImportItems {
+		return echo.NewHTTPError(http.StatusBadRequest, "export has too many memories")
+	}
+
+	scopeID, resolvedBotID, err := h.resolveWriteScope(botID)
+	if err != nil {
+		return err
+	}
+	provider, err := h.checkService(c.Request().Context(), resolvedBotID)
+	if err != nil {
+		return err
+	}
+	filters := buildNamespaceFilters(sharedMemoryNamespace, scopeID, nil)
+	infer := false
+	resp := MemoryImportResponse{Format: format, Total: len(items)}
+	for _, item := range items {
+		_, err := provider.Add(c.Request().Context(), memprovider.AddRequest{
+			Message:  item.Text,
+			BotID:    resolvedBotID,
+			Metadata: item.Metadata,
+			Filters:  filters,
+			Infer:    &infer,
+		})
+		if err != nil {
+			resp.Failed++
+			if len(resp.Errors) < 20 {
+				resp.Errors = append(resp.Errors, err.Error())
+			}
+			if c.Request().Context().Err() != nil {
+				break
+			}
+			continue
+		}
+		resp.Imported++
+	}
+	if resp.Failed > 0 {
+		h.logger.Warn("memory import finished with failures",
+			slog.String("bot_id", resolvedBotID),
+			slog.String("format", format),
+			slog.Int("imported", resp.Imported),
+			slog.Int("failed", resp.Failed),
+		)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
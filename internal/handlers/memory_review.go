@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	memoryreview "github.com/memohai/memoh/internal/memory/review"
+)
+
+type MemoryReviewHandler struct {
+	service        *memoryreview.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewMemoryReviewHandler(log *slog.Logger, service *memoryreview.Service, botService *bots.Service, accountService *accounts.Service) *MemoryReviewHandler {
+	return &MemoryReviewHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "memory_review")),
+	}
+}
+
+func (h *MemoryReviewHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/memory-review")
+	group.GET("", h.Get)
+	group.PUT("", h.Update)
+}
+
+// Get godoc
+// @Summary Get a bot's weekly memory review settings
+// @Description Bots that never enabled the review get disabled defaults
+// @Tags memory
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} memoryreview.Settings
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory-review [get].
+func (h *MemoryReviewHandler) Get(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	resp, err := h.service.Get(c.Request().Context(), botID)
+	if err != nil {
+		return memoryReviewHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Update godoc
+// @Summary Update a bot's weekly memory review settings
+// @Description When enabled, the memories the bot added each week are sent to the channel target with keep and forget actions
+// @Tags memory
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param request body memoryreview.UpdateRequest true "Memory review settings"
+// @Success 200 {object} memoryreview.Settings
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/memory-review [put].
+func (h *MemoryReviewHandler) Update(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req memoryreview.UpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.Update(c.Request().Context(), botID, req)
+	if err != nil {
+		return memoryReviewHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *MemoryReviewHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func memoryReviewHTTPError(err error) error {
+	if errors.Is(err, memoryreview.ErrInvalidReview) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
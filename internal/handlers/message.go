@@ -80,6 +80,7 @@ func (h *MessageHandler) Register(e *echo.Echo) {
 	botGroup := e.Group("/bots/:bot_id")
 	botGroup.GET("/messages", h.ListMessages)
 	botGroup.GET("/messages/locate", h.LocateMessage)
+	botGroup.GET("/messages/:message_id/trace", h.GetMessageTrace)
 	botGroup.DELETE("/messages", h.DeleteMessages)
 	botGroup.GET("/media/:content_hash", h.ServeMedia)
 
@@ -310,6 +311,64 @@ func (h *MessageHandler) LocateMessage(c echo.Context) error {
 	})
 }
 
+// GetMessageTrace godoc
+// @Summary Get the cost and latency trace of a message
+// @Description Return the breakdown of the round containing a message: model selection, history tokens loaded, gateway latency, per-tool-call durations, token usage and memory writes
+// @Tags messages
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param message_id path string true "Message ID"
+// @Param session_id query string true "Session ID"
+// @Success 200 {object} messagepkg.TurnTrace
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/messages/{message_id}/trace [get].
+func (h *MessageHandler) GetMessageTrace(c echo.Context) error {
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	messageID := strings.TrimSpace(c.Param("message_id"))
+	if messageID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "message id is required")
+	}
+	if h.messageService == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "message service not configured")
+	}
+
+	sessionID := strings.TrimSpace(c.QueryParam("session_id"))
+	if sessionID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "session_id is required")
+	}
+	if _, _, _, err := h.authorizeMessageSession(c, channelIdentityID, botID, sessionID); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	turn, err := h.messageService.GetVisibleTurnByMessage(ctx, sessionID, messageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "message not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	fromID := turn.RequestMessageID
+	if fromID == "" {
+		fromID = messageID
+	}
+	messages, err := h.messageService.ListVisibleFromBySession(ctx, sessionID, fromID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, messagepkg.BuildTurnTrace(turn, messages))
+}
+
 func parseBoundedInt32(raw string, fallback int32, minValue int32, maxValue int32) int32 {
 	value := fallback
 	if s := strings.TrimSpace(raw); s != "" {
@@ -689,7 +748,7 @@ func (h *MessageHandler) authorizeBotMessageAccess(c echo.Context, channelIdenti
 		return bots.Bot{}, nil, echo.NewHTTPError(http.StatusInternalServerError, "bot services not configured")
 	}
 	ctx := c.Request().Context()
-	isAdmin, err := h.accountService.IsAdmin(ctx, channelIdentityID)
+	manageAll, err := managesAllBots(ctx, h.accountService, channelIdentityID)
 	if err != nil {
 		return bots.Bot{}, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -700,7 +759,7 @@ func (h *MessageHandler) authorizeBotMessageAccess(c echo.Context, channelIdenti
 		}
 		return bots.Bot{}, nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	perms, err := h.botService.ResolveUserPermissionsForBot(ctx, bot, channelIdentityID, isAdmin)
+	perms, err := h.botService.ResolveUserPermissionsForBot(ctx, bot, channelIdentityID, manageAll)
 	if err != nil {
 		if errors.Is(err, bots.ErrBotNotFound) {
 			return bots.Bot{}, nil, echo.NewHTTPError(http.StatusNotFound, "bot not found")
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/chat/tagging"
+)
+
+type MessageTaggingHandler struct {
+	service        *tagging.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewMessageTaggingHandler(log *slog.Logger, service *tagging.Service, botService *bots.Service, accountService *accounts.Service) *MessageTaggingHandler {
+	return &MessageTaggingHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "message_tagging")),
+	}
+}
+
+func (h *MessageTaggingHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/message-tagging")
+	group.GET("", h.Get)
+	group.PUT("", h.Update)
+	group.GET("/analytics", h.Analytics)
+}
+
+// Get godoc
+// @Summary Get a bot's message tagging settings
+// @Description Bots that never enabled tagging get disabled defaults with the default topics
+// @Tags messages
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} tagging.Settings
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/message-tagging [get].
+func (h *MessageTaggingHandler) Get(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	resp, err := h.service.Get(c.Request().Context(), botID)
+	if err != nil {
+		return messageTaggingHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Update godoc
+// @Summary Update a bot's message tagging settings
+// @Description When enabled, user messages sent from then on are tagged with a sentiment and topics by the tagging model, or the owner's title model
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param request body tagging.UpdateRequest true "Message tagging settings"
+// @Success 200 {object} tagging.Settings
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/message-tagging [put].
+func (h *MessageTaggingHandler) Update(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req tagging.UpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.Update(c.Request().Context(), botID, req)
+	if err != nil {
+		return messageTaggingHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Analytics godoc
+// @Summary Get sentiment and topic analytics of a bot's user messages
+// @Description Daily sentiment counts and the top topics of tagged user messages over the last days, today included
+// @Tags messages
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param days query int false "Number of UTC days, default 30, at most 365"
+// @Success 200 {object} tagging.Analytics
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/message-tagging/analytics [get].
+func (h *MessageTaggingHandler) Analytics(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	days := 0
+	if raw := strings.TrimSpace(c.QueryParam("days")); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil || days <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "days must be a positive integer")
+		}
+	}
+	resp, err := h.service.Analytics(c.Request().Context(), botID, days)
+	if err != nil {
+		return messageTaggingHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *MessageTaggingHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func messageTaggingHTTPError(err error) error {
+	if errors.Is(err, tagging.ErrInvalidSettings) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
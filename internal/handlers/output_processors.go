@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel/postprocess"
+)
+
+type OutputProcessorsHandler struct {
+	service        *postprocess.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+// OutputProcessorsRequest replaces a bot's output processors. The list order
+// is the order in which they run.
+type OutputProcessorsRequest struct {
+	Processors []postprocess.Processor `json:"processors"`
+}
+
+type OutputProcessorsResponse struct {
+	Processors []postprocess.Processor `json:"processors"`
+}
+
+func NewOutputProcessorsHandler(log *slog.Logger, service *postprocess.Service, botService *bots.Service, accountService *accounts.Service) *OutputProcessorsHandler {
+	return &OutputProcessorsHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "output_processors")),
+	}
+}
+
+func (h *OutputProcessorsHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/output-processors")
+	group.GET("", h.Get)
+	group.PUT("", h.Replace)
+}
+
+// Get godoc
+// @Summary Get a bot's output processors
+// @Description Processors rewrite replies before they are delivered to IM channels, in list order
+// @Tags output-processors
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} OutputProcessorsResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/output-processors [get].
+func (h *OutputProcessorsHandler) Get(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	processors, err := h.service.List(c.Request().Context(), botID)
+	if err != nil {
+		return outputProcessorsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, OutputProcessorsResponse{Processors: processors})
+}
+
+// Replace godoc
+// @Summary Replace a bot's output processors
+// @Description Supported types are regex_replace, banned_phrases, signature and strip_emoji. Platforms limits a processor to some channel types. Regex and banned-phrase processors hold back streamed previews so unprocessed text is never shown
+// @Tags output-processors
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param request body OutputProcessorsRequest true "Processors in pipeline order"
+// @Success 200 {object} OutputProcessorsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/output-processors [put].
+func (h *OutputProcessorsHandler) Replace(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req OutputProcessorsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	processors, err := h.service.Replace(c.Request().Context(), botID, req.Processors)
+	if err != nil {
+		return outputProcessorsHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, OutputProcessorsResponse{Processors: processors})
+}
+
+func (h *OutputProcessorsHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func outputProcessorsHTTPError(err error) error {
+	if errors.Is(err, postprocess.ErrInvalidProcessor) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/memohai/memoh/internal/apperror"
+	"github.com/memohai/memoh/internal/audit"
 	"github.com/memohai/memoh/internal/auth"
+	"github.com/memohai/memoh/internal/config"
 	"github.com/memohai/memoh/internal/models"
 	"github.com/memohai/memoh/internal/oauthctx"
 	"github.com/memohai/memoh/internal/providers"
@@ -21,6 +23,7 @@ import (
 type ProvidersHandler struct {
 	service       *providers.Service
 	modelsService *models.Service
+	audit         *audit.Service
 	logger        *slog.Logger
 }
 
@@ -32,6 +35,11 @@ func NewProvidersHandler(log *slog.Logger, service *providers.Service, modelsSer
 	}
 }
 
+// SetAuditLog records provider API key changes in the audit log.
+func (h *ProvidersHandler) SetAuditLog(service *audit.Service) {
+	h.audit = service
+}
+
 func (h *ProvidersHandler) Register(e *echo.Echo) {
 	group := e.Group("/providers")
 	group.POST("", h.Create)
@@ -67,8 +75,12 @@ func (h *ProvidersHandler) CreateFromTemplate(c echo.Context) error {
 	}
 	resp, err := h.service.CreateFromTemplate(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, config.ErrEndpointNotLocal) {
+			return echo.NewHTTPError(http.StatusBadRequest, "offline mode: "+err.Error())
+		}
 		return err
 	}
+	h.recordKeyChange(c, resp, nil, req.Config)
 	return c.JSON(http.StatusCreated, resp)
 }
 
@@ -96,8 +108,9 @@ func (h *ProvidersHandler) Create(c echo.Context) error {
 
 	resp, err := h.service.Create(c.Request().Context(), req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return providerWriteError(err)
 	}
+	h.recordKeyChange(c, resp, nil, req.Config)
 
 	return c.JSON(http.StatusCreated, resp)
 }
@@ -234,10 +247,17 @@ func (h *ProvidersHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	var before map[string]any
+	if h.audit != nil && hasProviderSecret(req.Config) {
+		if existing, err := h.service.Get(c.Request().Context(), id); err == nil {
+			before = existing.Config
+		}
+	}
 	resp, err := h.service.Update(c.Request().Context(), id, req)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return providerWriteError(err)
 	}
+	h.recordKeyChange(c, resp, before, req.Config)
 
 	return c.JSON(http.StatusOK, resp)
 }
@@ -551,3 +571,54 @@ func mergeDiscoveredConfig(existing, discovered models.ModelConfig) (models.Mode
 	}
 	return out, changed
 }
+
+// providerWriteError maps provider create/update failures to HTTP errors;
+// endpoint policy violations are the caller's to fix.
+func providerWriteError(err error) error {
+	if errors.Is(err, config.ErrEndpointNotLocal) {
+		return echo.NewHTTPError(http.StatusBadRequest, "offline mode: "+err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
+// providerSecretKeys are the provider config fields whose changes are audited.
+var providerSecretKeys = []string{"api_key", "oauth_client_secret"}
+
+func hasProviderSecret(cfg map[string]any) bool {
+	for _, key := range providerSecretKeys {
+		if _, ok := cfg[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// recordKeyChange audits secrets the request set. before holds the provider's
+// masked config; a request echoing the masked value back changes nothing.
+func (h *ProvidersHandler) recordKeyChange(c echo.Context, provider providers.GetResponse, before, incoming map[string]any) {
+	if h.audit == nil {
+		return
+	}
+	from := map[string]any{}
+	to := map[string]any{}
+	for _, key := range providerSecretKeys {
+		value, ok := incoming[key].(string)
+		if !ok {
+			continue
+		}
+		prev, _ := before[key].(string)
+		from[key] = strings.TrimSpace(prev)
+		to[key] = strings.TrimSpace(value)
+	}
+	diff := audit.Diff(from, to)
+	if len(diff) == 0 {
+		return
+	}
+	recordAudit(c, h.audit, audit.Entry{
+		Action:     audit.ActionProviderKeyChanged,
+		TargetType: audit.TargetProvider,
+		TargetID:   provider.ID,
+		Details:    map[string]any{"name": provider.Name, "client_type": provider.ClientType},
+		Diff:       diff,
+	})
+}
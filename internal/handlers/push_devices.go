@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/push"
+)
+
+// PushDeviceHandler registers companion app devices for a bot's push
+// notifications. Devices belong to the calling user.
+type PushDeviceHandler struct {
+	service        *push.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+type pushDeviceListResponse struct {
+	Items []push.Device `json:"items"`
+}
+
+func NewPushDeviceHandler(log *slog.Logger, service *push.Service, botService *bots.Service, accountService *accounts.Service) *PushDeviceHandler {
+	return &PushDeviceHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "push_devices")),
+	}
+}
+
+func (h *PushDeviceHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/push/devices")
+	group.GET("", h.List)
+	group.POST("", h.Create)
+	group.PATCH("/:device_id", h.Update)
+	group.DELETE("/:device_id", h.Delete)
+}
+
+// List godoc
+// @Summary List my push devices for a bot
+// @Tags push
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} pushDeviceListResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /bots/{bot_id}/push/devices [get].
+func (h *PushDeviceHandler) List(c echo.Context) error {
+	userID, botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	items, err := h.service.ListDevices(c.Request().Context(), botID, userID)
+	if err != nil {
+		return pushHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, pushDeviceListResponse{Items: items})
+}
+
+// Create godoc
+// @Summary Register a push device for a bot
+// @Description Register an APNs or FCM device token so the bot's messages sent to the push channel reach the companion app. Registering a known token updates it.
+// @Tags push
+// @Param bot_id path string true "Bot ID"
+// @Param payload body push.RegisterDeviceRequest true "Device"
+// @Success 201 {object} push.Device
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/push/devices [post].
+func (h *PushDeviceHandler) Create(c echo.Context) error {
+	userID, botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	var req push.RegisterDeviceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid push device payload")
+	}
+	device, err := h.service.RegisterDevice(c.Request().Context(), botID, userID, req)
+	if err != nil {
+		return pushHTTPError(err)
+	}
+	return c.JSON(http.StatusCreated, device)
+}
+
+// Update godoc
+// @Summary Mute or unmute a push device
+// @Tags push
+// @Param bot_id path string true "Bot ID"
+// @Param device_id path string true "Device ID"
+// @Param payload body push.UpdateDeviceRequest true "Mute setting"
+// @Success 200 {object} push.Device
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/push/devices/{device_id} [patch].
+func (h *PushDeviceHandler) Update(c echo.Context) error {
+	userID, botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	var req push.UpdateDeviceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid push device payload")
+	}
+	device, err := h.service.UpdateDevice(c.Request().Context(), botID, userID, strings.TrimSpace(c.Param("device_id")), req)
+	if err != nil {
+		return pushHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, device)
+}
+
+// Delete godoc
+// @Summary Unregister a push device
+// @Tags push
+// @Param bot_id path string true "Bot ID"
+// @Param device_id path string true "Device ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/push/devices/{device_id} [delete].
+func (h *PushDeviceHandler) Delete(c echo.Context) error {
+	userID, botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if err := h.service.DeleteDevice(c.Request().Context(), botID, userID, strings.TrimSpace(c.Param("device_id"))); err != nil {
+		return pushHTTPError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// requireBotAccess lets anyone who can chat with the bot receive its
+// notifications.
+func (h *PushDeviceHandler) requireBotAccess(c echo.Context) (string, string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, bots.PermissionChat); err != nil {
+		return "", "", err
+	}
+	return userID, botID, nil
+}
+
+func pushHTTPError(err error) error {
+	switch {
+	case errors.Is(err, push.ErrInvalidPlatform),
+		errors.Is(err, push.ErrInvalidToken),
+		errors.Is(err, push.ErrInvalidName),
+		errors.Is(err, push.ErrInvalidMute):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, push.ErrDeviceNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, push.ErrNotConfigured):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
@@ -35,10 +37,14 @@ func (h *ScheduleHandler) Register(e *echo.Echo) {
 	group.GET("", h.List)
 	group.GET("/logs", h.ListLogs)
 	group.DELETE("/logs", h.DeleteLogs)
+	group.GET("/feed-tokens", h.ListFeedTokens)
+	group.POST("/feed-tokens", h.CreateFeedToken)
+	group.DELETE("/feed-tokens/:token_id", h.RevokeFeedToken)
 	group.GET("/:id", h.Get)
 	group.GET("/:id/logs", h.ListLogsBySchedule)
 	group.PUT("/:id", h.Update)
 	group.DELETE("/:id", h.Delete)
+	e.GET("/bots/:bot_id/schedules.ics", h.CalendarFeed)
 }
 
 // Create godoc
@@ -314,6 +320,128 @@ func (h *ScheduleHandler) DeleteLogs(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// CreateFeedToken godoc
+// @Summary Create schedule calendar feed token
+// @Description Issue a token for subscribing to the bot's schedules from a calendar client. The token and feed URL are only returned here
+// @Tags schedule
+// @Param bot_id path string true "Bot ID"
+// @Param payload body schedule.CreateFeedTokenRequest true "Feed token payload"
+// @Success 201 {object} schedule.FeedToken
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/schedule/feed-tokens [post].
+func (h *ScheduleHandler) CreateFeedToken(c echo.Context) error {
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+	var req schedule.CreateFeedTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	resp, err := h.service.CreateFeedToken(c.Request().Context(), botID, userID, req)
+	if err != nil {
+		if errors.Is(err, schedule.ErrInvalidFeedTokenName) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListFeedTokens godoc
+// @Summary List schedule calendar feed tokens
+// @Tags schedule
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} schedule.ListFeedTokensResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/schedule/feed-tokens [get].
+func (h *ScheduleHandler) ListFeedTokens(c echo.Context) error {
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+	items, err := h.service.ListFeedTokens(c.Request().Context(), botID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, schedule.ListFeedTokensResponse{Items: items})
+}
+
+// RevokeFeedToken godoc
+// @Summary Revoke schedule calendar feed token
+// @Tags schedule
+// @Param bot_id path string true "Bot ID"
+// @Param token_id path string true "Feed token ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/schedule/feed-tokens/{token_id} [delete].
+func (h *ScheduleHandler) RevokeFeedToken(c echo.Context) error {
+	userID, err := h.requireUserID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return err
+	}
+	if err := h.service.RevokeFeedToken(c.Request().Context(), botID, strings.TrimSpace(c.Param("token_id"))); err != nil {
+		if errors.Is(err, schedule.ErrFeedTokenNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CalendarFeed godoc
+// @Summary Schedule calendar feed
+// @Description Upcoming runs of the bot's enabled schedules over the next 30 days as iCalendar. Authorized by a feed token instead of a session token
+// @Tags schedule
+// @Param bot_id path string true "Bot ID"
+// @Param token query string true "Feed token"
+// @Produce text/calendar
+// @Success 200 {string} string
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/schedules.ics [get].
+func (h *ScheduleHandler) CalendarFeed(c echo.Context) error {
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	ctx := c.Request().Context()
+	if err := h.service.AuthenticateFeedToken(ctx, botID, c.QueryParam("token")); err != nil {
+		if errors.Is(err, schedule.ErrInvalidFeedToken) {
+			return echo.NewHTTPError(http.StatusForbidden, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	body, err := h.service.CalendarFeed(ctx, botID, time.Now().UTC())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, "private, no-store")
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
 func (*ScheduleHandler) requireUserID(c echo.Context) (string, error) {
 	return RequireChannelIdentityID(c)
 }
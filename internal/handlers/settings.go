@@ -12,6 +12,7 @@ import (
 	"github.com/memohai/memoh/internal/accounts"
 	"github.com/memohai/memoh/internal/bots"
 	"github.com/memohai/memoh/internal/heartbeat"
+	"github.com/memohai/memoh/internal/persona"
 	"github.com/memohai/memoh/internal/settings"
 )
 
@@ -39,6 +40,7 @@ func (h *SettingsHandler) Register(e *echo.Echo) {
 	group.POST("", h.Upsert)
 	group.PUT("", h.Upsert)
 	group.DELETE("", h.Delete)
+	group.GET("/persona-presets", h.PersonaPresets)
 }
 
 // Get godoc
@@ -98,6 +100,11 @@ func (h *SettingsHandler) Upsert(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	if req.Persona != nil {
+		if err := persona.Validate(*req.Persona); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
 	resp, err := h.service.UpsertBot(c.Request().Context(), botID, req)
 	if err != nil {
 		if feedbackErr := acpFeedbackHTTPError(err); feedbackErr != nil {
@@ -121,6 +128,29 @@ func (h *SettingsHandler) Upsert(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// PersonaPresets godoc
+// @Summary List persona presets
+// @Description Built-in persona presets a bot or channel route can select, with their slider levels
+// @Tags settings
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {array} persona.Preset
+// @Failure 400 {object} ErrorResponse
+// @Router /bots/{bot_id}/settings/persona-presets [get].
+func (h *SettingsHandler) PersonaPresets(c echo.Context) error {
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, channelIdentityID, botID, bots.PermissionChat); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, persona.Presets())
+}
+
 // Delete godoc
 // @Summary Delete user settings
 // @Description Remove agent settings for current user
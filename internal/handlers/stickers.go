@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel/stickers"
+)
+
+type StickersHandler struct {
+	service        *stickers.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+// StickersRequest replaces a bot's sticker catalog.
+type StickersRequest struct {
+	Stickers []stickers.Sticker `json:"stickers"`
+}
+
+type StickersResponse struct {
+	Stickers []stickers.Sticker `json:"stickers"`
+}
+
+func NewStickersHandler(log *slog.Logger, service *stickers.Service, botService *bots.Service, accountService *accounts.Service) *StickersHandler {
+	return &StickersHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "stickers")),
+	}
+}
+
+func (h *StickersHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/stickers")
+	group.GET("", h.Get)
+	group.PUT("", h.Replace)
+}
+
+// Get godoc
+// @Summary Get a bot's sticker catalog
+// @Description The agent can only send stickers listed in its bot's catalog
+// @Tags stickers
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} StickersResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/stickers [get].
+func (h *StickersHandler) Get(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	catalog, err := h.service.List(c.Request().Context(), botID)
+	if err != nil {
+		return stickersHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, StickersResponse{Stickers: catalog})
+}
+
+// Replace godoc
+// @Summary Replace a bot's sticker catalog
+// @Description Each sticker has a unique name, a description telling the agent when to use it, native sticker ids keyed by platform (Telegram file_id, Discord sticker id) and an emoji sent on other platforms
+// @Tags stickers
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param request body StickersRequest true "Sticker catalog"
+// @Success 200 {object} StickersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/stickers [put].
+func (h *StickersHandler) Replace(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req StickersRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	catalog, err := h.service.Replace(c.Request().Context(), botID, req.Stickers)
+	if err != nil {
+		return stickersHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, StickersResponse{Stickers: catalog})
+}
+
+func (h *StickersHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func stickersHTTPError(err error) error {
+	if errors.Is(err, stickers.ErrInvalidSticker) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/usagereport"
+)
+
+// UsageReportHandler serves downloadable usage reports for stakeholders who
+// do not use the dashboard.
+type UsageReportHandler struct {
+	service        *usagereport.Service
+	memory         *MemoryHandler
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewUsageReportHandler(log *slog.Logger, service *usagereport.Service, botService *bots.Service, accountService *accounts.Service) *UsageReportHandler {
+	return &UsageReportHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "usage_report")),
+	}
+}
+
+// SetMemoryHandler enables the memory growth section, read through the same
+// providers as the memory analytics endpoint.
+func (h *UsageReportHandler) SetMemoryHandler(memory *MemoryHandler) {
+	h.memory = memory
+}
+
+func (h *UsageReportHandler) Register(e *echo.Echo) {
+	e.GET("/bots/:bot_id/reports", h.GetReport)
+}
+
+// GetReport godoc
+// @Summary Download a usage report
+// @Description Build a weekly or monthly usage report for a bot (messages per platform, active users, token usage per model, top tools and memory growth) as a CSV or PDF download. Without a date the last complete period is reported. Periods are UTC. Memory growth is omitted when the bot's memory provider is unavailable
+// @Tags reports
+// @Produce text/csv
+// @Produce application/pdf
+// @Param bot_id path string true "Bot ID"
+// @Param period query string false "weekly or monthly (default monthly)"
+// @Param date query string false "Any day in the period to report (YYYY-MM-DD)"
+// @Param format query string false "csv or pdf (default csv)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/reports [get].
+func (h *UsageReportHandler) GetReport(c echo.Context) error {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccess(c.Request().Context(), h.botService, h.accountService, userID, botID)
+	if err != nil {
+		return err
+	}
+
+	period := strings.ToLower(strings.TrimSpace(c.QueryParam("period")))
+	if period == "" {
+		period = usagereport.PeriodMonthly
+	}
+	format := strings.ToLower(strings.TrimSpace(c.QueryParam("format")))
+	if format == "" {
+		format = usagereport.FormatCSV
+	}
+	if format != usagereport.FormatCSV && format != usagereport.FormatPDF {
+		return echo.NewHTTPError(http.StatusBadRequest, usagereport.ErrInvalidFormat.Error())
+	}
+	var from, to time.Time
+	if raw := strings.TrimSpace(c.QueryParam("date")); raw != "" {
+		day, parseErr := time.Parse(time.DateOnly, raw)
+		if parseErr != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid date format, expected YYYY-MM-DD")
+		}
+		from, to, err = usagereport.PeriodRange(period, day)
+	} else {
+		from, to, err = usagereport.LastCompletePeriod(period, time.Now())
+	}
+	if errors.Is(err, usagereport.ErrInvalidPeriod) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.Build(ctx, botID, period, from, to)
+	if err != nil {
+		h.logger.Error("build usage report failed", slog.String("bot_id", botID), slog.Any("error", err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to build usage report")
+	}
+	report.BotName = bot.DisplayName
+	if report.BotName == "" {
+		report.BotName = bot.Name
+	}
+	if h.memory != nil {
+		added, total, memErr := h.memory.MemoryGrowth(ctx, botID, from, to)
+		if memErr != nil {
+			h.logger.Warn("usage report without memory growth", slog.String("bot_id", botID), slog.Any("error", memErr))
+		} else {
+			report.Memory = &usagereport.MemoryGrowth{Added: added, Total: total}
+		}
+	}
+
+	// Render before writing headers so a failure is still a JSON error.
+	var body bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if format == usagereport.FormatPDF {
+		contentType = "application/pdf"
+		err = usagereport.WritePDF(&body, report)
+	} else {
+		err = usagereport.WriteCSV(&body, report)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to render usage report")
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+report.FileName(format)+`"`)
+	return c.Blob(http.StatusOK, contentType, body.Bytes())
+}
@@ -19,6 +19,7 @@ import (
 	acpclient "github.com/memohai/memoh/internal/agent/runtime/acp/client"
 	acpprofile "github.com/memohai/memoh/internal/agent/runtime/acp/profile"
 	"github.com/memohai/memoh/internal/apperror"
+	"github.com/memohai/memoh/internal/audit"
 	"github.com/memohai/memoh/internal/auth"
 	"github.com/memohai/memoh/internal/bots"
 	"github.com/memohai/memoh/internal/channel"
@@ -26,6 +27,7 @@ import (
 	"github.com/memohai/memoh/internal/db"
 	"github.com/memohai/memoh/internal/httpx"
 	"github.com/memohai/memoh/internal/identity"
+	"github.com/memohai/memoh/internal/policy"
 	runtimeRpc "github.com/memohai/memoh/internal/rpc/runtime"
 	"github.com/memohai/memoh/internal/workspace"
 	"github.com/memohai/memoh/internal/workspace/bridge"
@@ -60,6 +62,8 @@ type UsersHandler struct {
 	registry       *channel.Registry
 	acpWorkspace   botCreateWorkspace
 	acpRuntimes    acpRuntimeCloser
+	audit          *audit.Service
+	idempotency    *idempotencyCache
 	logger         *slog.Logger
 }
 
@@ -76,6 +80,7 @@ func NewUsersHandler(log *slog.Logger, service *accounts.Service, botService *bo
 		channelRuntime: channelRuntime,
 		registry:       registry,
 		acpWorkspace:   acpWorkspace,
+		idempotency:    newIdempotencyCache(idempotencyTTL, idempotencyCapacity),
 		logger:         log.With(slog.String("handler", "users")),
 	}
 }
@@ -84,6 +89,12 @@ func (h *UsersHandler) SetACPRuntimeCloser(closer acpRuntimeCloser) {
 	h.acpRuntimes = closer
 }
 
+// SetAuditLog records user creation, bot transfers and channel config
+// changes in the audit log.
+func (h *UsersHandler) SetAuditLog(service *audit.Service) {
+	h.audit = service
+}
+
 func (h *UsersHandler) Register(e *echo.Echo) {
 	userGroup := e.Group("/users")
 	userGroup.GET("/me", h.GetMe)
@@ -94,6 +105,8 @@ func (h *UsersHandler) Register(e *echo.Echo) {
 	userGroup.PUT("/:id", h.UpdateUser)
 	userGroup.POST("", h.CreateUser)
 	userGroup.DELETE("/:id", h.RemoveMember)
+	userGroup.PUT("/:id/role", h.UpdateUserRole)
+	e.GET("/roles", h.ListRoles)
 
 	botGroup := e.Group("/bots")
 	botGroup.POST("", h.CreateBot)
@@ -111,6 +124,7 @@ func (h *UsersHandler) Register(e *echo.Echo) {
 	botGroup.DELETE("/:id/channel/:platform", h.DeleteBotChannelConfig)
 	botGroup.POST("/:id/channel/:platform/send", h.SendBotMessage)
 	botGroup.POST("/:id/channel/:platform/send_chat", h.SendBotMessageSession)
+	botGroup.POST("/:id/broadcast", h.BroadcastBotMessage)
 }
 
 // GetMe godoc
@@ -193,7 +207,7 @@ func (h *UsersHandler) UpdateMyPassword(c echo.Context) error {
 }
 
 // ListUsers godoc
-// @Summary List users (admin only)
+// @Summary List users (requires users.view)
 // @Description List users
 // @Tags users
 // @Success 200 {object} accounts.ListAccountsResponse
@@ -206,12 +220,8 @@ func (h *UsersHandler) ListUsers(c echo.Context) error {
 	if err != nil {
 		return err
 	}
-	isAdmin, err := h.service.IsAdmin(c.Request().Context(), channelIdentityID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	if !isAdmin {
-		return echo.NewHTTPError(http.StatusForbidden, "admin role required")
+	if err := requireUserPermission(c, h.service, channelIdentityID, policy.PermissionUsersView); err != nil {
+		return err
 	}
 	if strings.TrimSpace(c.QueryParam("user_type")) != "" || strings.TrimSpace(c.QueryParam("owner_id")) != "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "user_type and owner_id are not supported")
@@ -225,7 +235,7 @@ func (h *UsersHandler) ListUsers(c echo.Context) error {
 
 // GetUser godoc
 // @Summary Get user by ID
-// @Description Get user details (self or admin only)
+// @Description Get user details (self, or users.view)
 // @Tags users
 // @Param id path string true "User ID"
 // @Success 200 {object} accounts.Account
@@ -244,12 +254,8 @@ func (h *UsersHandler) GetUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "user id is required")
 	}
 	if targetID != channelIdentityID {
-		isAdmin, err := h.service.IsAdmin(c.Request().Context(), channelIdentityID)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
-		if !isAdmin {
-			return echo.NewHTTPError(http.StatusForbidden, "user access denied")
+		if err := requireUserPermission(c, h.service, channelIdentityID, policy.PermissionUsersView); err != nil {
+			return err
 		}
 	}
 	user, err := h.service.Get(c.Request().Context(), targetID)
@@ -263,7 +269,7 @@ func (h *UsersHandler) GetUser(c echo.Context) error {
 }
 
 // UpdateUser godoc
-// @Summary Update user (admin only)
+// @Summary Update user (requires users.manage)
 // @Description Update the user's role or membership status in the current workspace
 // @Tags users
 // @Param id path string true "User ID"
@@ -280,12 +286,8 @@ func (h *UsersHandler) UpdateUser(c echo.Context) error {
 	if err != nil {
 		return err
 	}
-	isAdmin, err := h.service.IsAdmin(c.Request().Context(), channelIdentityID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	if !isAdmin {
-		return echo.NewHTTPError(http.StatusForbidden, "admin role required")
+	if err := requireUserPermission(c, h.service, channelIdentityID, policy.PermissionUsersManage); err != nil {
+		return err
 	}
 	targetID := strings.TrimSpace(c.Param("id"))
 	if targetID == "" {
@@ -304,6 +306,9 @@ func (h *UsersHandler) UpdateUser(c echo.Context) error {
 	}
 	resp, err := h.service.UpdateAdmin(c.Request().Context(), targetID, req)
 	if err != nil {
+		if errors.Is(err, accounts.ErrInvalidRole) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		if errors.Is(err, db.ErrLastActiveAdmin) {
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		}
@@ -313,7 +318,7 @@ func (h *UsersHandler) UpdateUser(c echo.Context) error {
 }
 
 // CreateUser godoc
-// @Summary Create human user (admin only)
+// @Summary Create human user (requires users.manage)
 // @Description Create a new human user account
 // @Tags users
 // @Param payload body accounts.CreateAccountRequest true "User payload"
@@ -327,12 +332,8 @@ func (h *UsersHandler) CreateUser(c echo.Context) error {
 	if err != nil {
 		return err
 	}
-	isAdmin, err := h.service.IsAdmin(c.Request().Context(), channelIdentityID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	if !isAdmin {
-		return echo.NewHTTPError(http.StatusForbidden, "admin role required")
+	if err := requireUserPermission(c, h.service, channelIdentityID, policy.PermissionUsersManage); err != nil {
+		return err
 	}
 	var req accounts.CreateAccountRequest
 	if err := c.Bind(&req); err != nil {
@@ -341,13 +342,23 @@ func (h *UsersHandler) CreateUser(c echo.Context) error {
 	//nolint:staticcheck // Keep backward-compatible behavior: CreateHuman creates backing user when owner id is empty.
 	resp, err := h.service.CreateHuman(c.Request().Context(), "", req)
 	if err != nil {
+		if errors.Is(err, accounts.ErrInvalidRole) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	recordAudit(c, h.audit, audit.Entry{
+		ActorUserID: channelIdentityID,
+		Action:      audit.ActionUserCreated,
+		TargetType:  audit.TargetUser,
+		TargetID:    resp.ID,
+		Details:     map[string]any{"username": resp.Username, "role": resp.Role},
+	})
 	return c.JSON(http.StatusCreated, resp)
 }
 
 // RemoveMember godoc
-// @Summary Deactivate member (admin only)
+// @Summary Deactivate member (requires users.manage)
 // @Description Deactivate the member in the current workspace without changing global credentials
 // @Tags users
 // @Param id path string true "User ID"
@@ -363,12 +374,8 @@ func (h *UsersHandler) RemoveMember(c echo.Context) error {
 	if err != nil {
 		return err
 	}
-	isAdmin, err := h.service.IsAdmin(c.Request().Context(), channelIdentityID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	if !isAdmin {
-		return echo.NewHTTPError(http.StatusForbidden, "admin role required")
+	if err := requireUserPermission(c, h.service, channelIdentityID, policy.PermissionUsersManage); err != nil {
+		return err
 	}
 	targetID := strings.TrimSpace(c.Param("id"))
 	if targetID == "" {
@@ -395,9 +402,67 @@ func (h *UsersHandler) RemoveMember(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// UpdateUserRole godoc
+// @Summary Assign user role (requires users.manage)
+// @Description Assign the user's workspace role: admin, operator, bot_manager, member or viewer
+// @Tags users
+// @Param id path string true "User ID"
+// @Param payload body accounts.UpdateRoleRequest true "Role payload"
+// @Success 200 {object} accounts.Account
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/role [put].
+func (h *UsersHandler) UpdateUserRole(c echo.Context) error {
+	if _, err := RequirePermission(c, h.service, policy.PermissionUsersManage); err != nil {
+		return err
+	}
+	targetID := strings.TrimSpace(c.Param("id"))
+	if targetID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "user id is required")
+	}
+	var req accounts.UpdateRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if strings.TrimSpace(req.Role) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "role is required")
+	}
+	resp, err := h.service.UpdateAdmin(c.Request().Context(), targetID, accounts.UpdateAccountRequest{Role: &req.Role})
+	if err != nil {
+		if errors.Is(err, accounts.ErrInvalidRole) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, db.ErrLastActiveAdmin) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the workspace roles and the permissions each grants
+// @Tags users
+// @Success 200 {object} policy.ListRolesResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /roles [get].
+func (h *UsersHandler) ListRoles(c echo.Context) error {
+	if _, err := h.requireChannelIdentityID(c); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, policy.ListRolesResponse{Items: policy.RoleInfos()})
+}
+
 // CreateBot godoc
 // @Summary Create bot user
-// @Description Create a bot user owned by current user (or admin-specified owner)
+// @Description Create a bot user owned by current user (requires bots.create; owner_id override requires bots.manage_all)
 // @Tags bots
 // @Param payload body bots.CreateBotRequest true "Bot payload"
 // @Success 201 {object} bots.Bot
@@ -407,7 +472,7 @@ func (h *UsersHandler) RemoveMember(c echo.Context) error {
 // @Failure 500 {object} ErrorResponse
 // @Router /bots [post].
 func (h *UsersHandler) CreateBot(c echo.Context) error {
-	channelIdentityID, err := h.requireChannelIdentityID(c)
+	channelIdentityID, err := RequirePermission(c, h.service, policy.PermissionBotsCreate)
 	if err != nil {
 		return err
 	}
@@ -418,12 +483,8 @@ func (h *UsersHandler) CreateBot(c echo.Context) error {
 	ownerID := channelIdentityID
 	ownerFromToken := true
 	if raw := strings.TrimSpace(c.QueryParam("owner_id")); raw != "" {
-		isAdmin, err := h.service.IsAdmin(c.Request().Context(), channelIdentityID)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
-		if !isAdmin {
-			return echo.NewHTTPError(http.StatusForbidden, "admin role required for owner override")
+		if err := requireUserPermission(c, h.service, channelIdentityID, policy.PermissionBotsManageAll); err != nil {
+			return err
 		}
 		if err := identity.ValidateChannelIdentityID(raw); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -673,9 +734,9 @@ func (h *UsersHandler) CheckBotName(c echo.Context) error {
 
 // ListBots godoc
 // @Summary List bots
-// @Description List bots accessible to current user (admin can specify owner_id)
+// @Description List bots accessible to current user (bots.manage_all can specify owner_id)
 // @Tags bots
-// @Param owner_id query string false "Owner user ID (admin only)"
+// @Param owner_id query string false "Owner user ID (requires bots.manage_all)"
 // @Success 200 {object} bots.ListBotsResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -688,12 +749,8 @@ func (h *UsersHandler) ListBots(c echo.Context) error {
 	}
 	ownerID := strings.TrimSpace(c.QueryParam("owner_id"))
 	if ownerID != "" {
-		isAdmin, err := h.service.IsAdmin(c.Request().Context(), channelIdentityID)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-		}
-		if !isAdmin {
-			return echo.NewHTTPError(http.StatusForbidden, "admin role required for owner filter")
+		if err := requireUserPermission(c, h.service, channelIdentityID, policy.PermissionBotsManageAll); err != nil {
+			return err
 		}
 		items, err := h.botService.ListByOwner(c.Request().Context(), ownerID)
 		if err != nil {
@@ -778,11 +835,11 @@ func (h *UsersHandler) ListBotChecks(c echo.Context) error {
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	isAdmin, err := h.service.IsAdmin(c.Request().Context(), channelIdentityID)
+	manageAll, err := managesAllBots(c.Request().Context(), h.service, channelIdentityID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	perms, err := h.botService.ResolveUserPermissions(c.Request().Context(), bot.ID, channelIdentityID, isAdmin)
+	perms, err := h.botService.ResolveUserPermissions(c.Request().Context(), bot.ID, channelIdentityID, manageAll)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -1038,7 +1095,7 @@ func (h *UsersHandler) prepareACPWorkspaceConfig(ctx context.Context, bot bots.B
 }
 
 // TransferBotOwner godoc
-// @Summary Transfer bot owner (admin only)
+// @Summary Transfer bot owner (requires bots.manage_all)
 // @Description Transfer bot ownership to another human user
 // @Tags bots
 // @Param id path string true "Bot ID"
@@ -1054,12 +1111,8 @@ func (h *UsersHandler) TransferBotOwner(c echo.Context) error {
 	if err != nil {
 		return err
 	}
-	isAdmin, err := h.service.IsAdmin(c.Request().Context(), channelIdentityID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
-	}
-	if !isAdmin {
-		return echo.NewHTTPError(http.StatusForbidden, "admin role required")
+	if err := requireUserPermission(c, h.service, channelIdentityID, policy.PermissionBotsManageAll); err != nil {
+		return err
 	}
 	botID := strings.TrimSpace(c.Param("id"))
 	if botID == "" {
@@ -1069,6 +1122,12 @@ func (h *UsersHandler) TransferBotOwner(c echo.Context) error {
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	previousOwner := ""
+	if h.audit != nil {
+		if bot, err := h.botService.Get(c.Request().Context(), botID); err == nil {
+			previousOwner = bot.OwnerUserID
+		}
+	}
 	resp, err := h.botService.TransferOwner(c.Request().Context(), botID, req.OwnerUserID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -1079,6 +1138,13 @@ func (h *UsersHandler) TransferBotOwner(c echo.Context) error {
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	recordAudit(c, h.audit, audit.Entry{
+		ActorUserID: channelIdentityID,
+		Action:      audit.ActionBotTransferred,
+		TargetType:  audit.TargetBot,
+		TargetID:    resp.ID,
+		Diff:        audit.Diff(map[string]any{"owner_user_id": previousOwner}, map[string]any{"owner_user_id": resp.OwnerUserID}),
+	})
 	return c.JSON(http.StatusOK, scrubBotForResponse(resp))
 }
 
@@ -1199,6 +1265,7 @@ func (h *UsersHandler) UpsertBotChannelConfig(c echo.Context) error {
 	if h.channelRuntime == nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "channel lifecycle not configured")
 	}
+	before := h.channelConfigAuditFields(c.Request().Context(), botID, channelType)
 	resp, err := h.channelRuntime.UpsertBotChannelConfig(c.Request().Context(), botID, channelType, req)
 	if err != nil {
 		if mapped := mapChannelRuntimeError(err); mapped != nil {
@@ -1212,6 +1279,14 @@ func (h *UsersHandler) UpsertBotChannelConfig(c echo.Context) error {
 		}
 		return echo.NewHTTPError(status, err.Error())
 	}
+	recordAudit(c, h.audit, audit.Entry{
+		ActorUserID: channelIdentityID,
+		Action:      audit.ActionChannelConfigUpdated,
+		TargetType:  audit.TargetChannelConfig,
+		TargetID:    resp.ID,
+		Details:     map[string]any{"bot_id": botID, "channel_type": channelType.String()},
+		Diff:        audit.Diff(before, channelConfigFields(resp)),
+	})
 	return c.JSON(http.StatusOK, resp)
 }
 
@@ -1357,21 +1432,55 @@ func (h *UsersHandler) DeleteBotChannelConfig(c echo.Context) error {
 	if h.channelRuntime == nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "channel lifecycle not configured")
 	}
+	before := h.channelConfigAuditFields(c.Request().Context(), botID, channelType)
 	if err := h.channelRuntime.DeleteBotChannelConfig(c.Request().Context(), botID, channelType); err != nil {
 		if mapped := mapChannelRuntimeError(err); mapped != nil {
 			return mapped
 		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
+	recordAudit(c, h.audit, audit.Entry{
+		ActorUserID: channelIdentityID,
+		Action:      audit.ActionChannelConfigDeleted,
+		TargetType:  audit.TargetChannelConfig,
+		TargetID:    channelType.String() + ":" + botID,
+		Details:     map[string]any{"bot_id": botID, "channel_type": channelType.String()},
+		Diff:        audit.Diff(before, map[string]any{}),
+	})
 	return c.NoContent(http.StatusNoContent)
 }
 
+// channelConfigAuditFields returns the audited fields of the bot's current
+// channel config, or nil when auditing is off or no config exists yet.
+func (h *UsersHandler) channelConfigAuditFields(ctx context.Context, botID string, channelType channel.ChannelType) map[string]any {
+	if h.audit == nil || h.channelStore == nil {
+		return nil
+	}
+	cfg, err := h.channelStore.ResolveEffectiveConfig(ctx, botID, channelType)
+	if err != nil {
+		return nil
+	}
+	return channelConfigFields(cfg)
+}
+
+// channelConfigFields lists the user-editable fields of cfg. Credentials are
+// redacted by audit.Diff.
+func channelConfigFields(cfg channel.ChannelConfig) map[string]any {
+	return map[string]any{
+		"credentials":       cfg.Credentials,
+		"external_identity": cfg.ExternalIdentity,
+		"routing":           cfg.Routing,
+		"disabled":          cfg.Disabled,
+	}
+}
+
 // SendBotMessage godoc
 // @Summary Send message via bot channel
 // @Description Send a message using bot channel configuration
 // @Tags bots
 // @Param id path string true "Bot ID"
 // @Param platform path string true "Channel platform"
+// @Param Idempotency-Key header string false "Client key that makes retries of this request return the original result instead of sending again"
 // @Param payload body channel.SendRequest true "Send payload"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} ErrorResponse
@@ -1399,6 +1508,16 @@ func (h *UsersHandler) SendBotMessage(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	body, err := readIdempotentBody(c)
+	if err != nil {
+		return err
+	}
+	return serveIdempotent(c, h.idempotency, "send:"+channelIdentityID+":"+botID+":"+channelType.String(), body, func() error {
+		return h.sendBotMessage(c, botID, channelType)
+	})
+}
+
+func (h *UsersHandler) sendBotMessage(c echo.Context, botID string, channelType channel.ChannelType) error {
 	var req channel.SendRequest
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -1421,6 +1540,7 @@ func (h *UsersHandler) SendBotMessage(c echo.Context) error {
 // @Tags bots
 // @Param id path string true "Bot ID"
 // @Param platform path string true "Channel platform"
+// @Param Idempotency-Key header string false "Client key that makes retries of this request return the original result instead of sending again"
 // @Param payload body channel.SendRequest true "Send payload"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} ErrorResponse
@@ -1455,7 +1575,16 @@ func (h *UsersHandler) SendBotMessageSession(c echo.Context) error {
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	body, err := readIdempotentBody(c)
+	if err != nil {
+		return err
+	}
+	return serveIdempotent(c, h.idempotency, "send_chat:"+chatToken.RouteID, body, func() error {
+		return h.sendBotMessageSession(c, botID, channelType, route.ReplyTarget)
+	})
+}
 
+func (h *UsersHandler) sendBotMessageSession(c echo.Context, botID string, channelType channel.ChannelType, target string) error {
 	var req channel.SendRequest
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -1464,7 +1593,7 @@ func (h *UsersHandler) SendBotMessageSession(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "message is required")
 	}
 	if err := h.channelRuntime.Send(c.Request().Context(), botID, channelType, channel.SendRequest{
-		Target:  route.ReplyTarget,
+		Target:  target,
 		Message: req.Message,
 	}); err != nil {
 		if mapped := mapChannelRuntimeError(err); mapped != nil {
@@ -1475,6 +1604,69 @@ func (h *UsersHandler) SendBotMessageSession(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 }
 
+// BroadcastBotMessage godoc
+// @Summary Broadcast a message to a bot's conversations
+// @Description Send one message to every bound conversation of the bot across platforms, or to those matching the filter. Each platform is sent to at a limited rate. With dry_run set the matching conversations are listed and nothing is sent.
+// @Tags bots
+// @Param id path string true "Bot ID"
+// @Param Idempotency-Key header string false "Client key that makes retries of this request return the original result instead of sending again"
+// @Param payload body channel.BroadcastRequest true "Broadcast payload"
+// @Success 200 {object} channel.BroadcastResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} apperror.Problem
+// @Router /bots/{id}/broadcast [post].
+func (h *UsersHandler) BroadcastBotMessage(c echo.Context) error {
+	channelIdentityID, err := h.requireChannelIdentityID(c)
+	if err != nil {
+		return err
+	}
+	botID := strings.TrimSpace(c.Param("id"))
+	if botID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), channelIdentityID, botID); err != nil {
+		return err
+	}
+	if h.channelRuntime == nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "channel manager not configured")
+	}
+	body, err := readIdempotentBody(c)
+	if err != nil {
+		return err
+	}
+	return serveIdempotent(c, h.idempotency, "broadcast:"+channelIdentityID+":"+botID, body, func() error {
+		return h.broadcastBotMessage(c, botID)
+	})
+}
+
+func (h *UsersHandler) broadcastBotMessage(c echo.Context, botID string) error {
+	var req channel.BroadcastRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if !req.DryRun && req.Message.IsEmpty() {
+		return echo.NewHTTPError(http.StatusBadRequest, "message is required")
+	}
+	for i, raw := range req.Filter.ChannelTypes {
+		channelType, err := h.registry.ParseChannelType(raw.String())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		req.Filter.ChannelTypes[i] = channelType
+	}
+	result, err := h.channelRuntime.Broadcast(c.Request().Context(), botID, req)
+	if err != nil {
+		if mapped := mapChannelRuntimeError(err); mapped != nil {
+			return mapped
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
 func mapChannelRuntimeError(err error) error {
 	if errors.Is(err, runtimeRpc.ErrUnavailable) {
 		return apperror.Wrap(apperror.CodeChannelRuntimeUnavailable, err, nil)
@@ -1489,11 +1681,11 @@ func (h *UsersHandler) authorizeBotAccess(ctx context.Context, channelIdentityID
 // attachCurrentUserPermissions populates the requesting user's effective access
 // permissions for a single bot.
 func (h *UsersHandler) attachCurrentUserPermissions(ctx context.Context, channelIdentityID string, bot *bots.Bot) error {
-	isAdmin, err := h.service.IsAdmin(ctx, channelIdentityID)
+	manageAll, err := managesAllBots(ctx, h.service, channelIdentityID)
 	if err != nil {
 		return err
 	}
-	perms, err := h.botService.ResolveUserPermissions(ctx, bot.ID, channelIdentityID, isAdmin)
+	perms, err := h.botService.ResolveUserPermissions(ctx, bot.ID, channelIdentityID, manageAll)
 	if err != nil {
 		return err
 	}
@@ -1503,12 +1695,12 @@ func (h *UsersHandler) attachCurrentUserPermissions(ctx context.Context, channel
 
 // attachCurrentUserPermissionsList populates effective permissions for a list of bots.
 func (h *UsersHandler) attachCurrentUserPermissionsList(ctx context.Context, channelIdentityID string, items []bots.Bot) error {
-	isAdmin, err := h.service.IsAdmin(ctx, channelIdentityID)
+	manageAll, err := managesAllBots(ctx, h.service, channelIdentityID)
 	if err != nil {
 		return err
 	}
 	for i := range items {
-		perms, err := h.botService.ResolveUserPermissions(ctx, items[i].ID, channelIdentityID, isAdmin)
+		perms, err := h.botService.ResolveUserPermissions(ctx, items[i].ID, channelIdentityID, manageAll)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/voice"
+)
+
+// VoiceHandler serves experimental WebRTC voice calls with bots.
+type VoiceHandler struct {
+	service        *voice.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+type voiceOfferRequest struct {
+	Type string `json:"type"`
+	SDP  string `json:"sdp"`
+}
+
+type voiceOfferResponse struct {
+	Type      string `json:"type"`
+	SDP       string `json:"sdp"`
+	SessionID string `json:"session_id"`
+}
+
+type voiceSessionListResponse struct {
+	Items []voice.SessionInfo `json:"items"`
+}
+
+func NewVoiceHandler(log *slog.Logger, service *voice.Service, botService *bots.Service, accountService *accounts.Service) *VoiceHandler {
+	return &VoiceHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "voice")),
+	}
+}
+
+func (h *VoiceHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/voice")
+	group.POST("/offer", h.Offer)
+	group.GET("/sessions", h.ListSessions)
+	group.DELETE("/sessions/:session_id", h.CloseSession)
+}
+
+// Offer godoc
+// @Summary Start a voice call with a bot
+// @Description Answer a WebRTC offer with PCMU audio. The caller's speech is transcribed with the bot's transcription model and the reply is spoken with its speech model. Open a "voice-events" data channel to receive transcripts and reply text. Experimental.
+// @Tags voice
+// @Param bot_id path string true "Bot ID"
+// @Param payload body voiceOfferRequest true "WebRTC offer"
+// @Success 200 {object} voiceOfferResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /bots/{bot_id}/voice/offer [post].
+func (h *VoiceHandler) Offer(c echo.Context) error {
+	userID, botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	var req voiceOfferRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid voice offer payload")
+	}
+	if req.Type != "" && req.Type != "offer" {
+		return echo.NewHTTPError(http.StatusBadRequest, "session description must be an offer")
+	}
+	answer, err := h.service.Answer(c.Request().Context(), botID, userID, voice.OfferRequest{SDP: req.SDP})
+	if err != nil {
+		return voiceHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, voiceOfferResponse{
+		Type:      answer.Type,
+		SDP:       answer.SDP,
+		SessionID: answer.SessionID,
+	})
+}
+
+// ListSessions godoc
+// @Summary List active voice calls with a bot
+// @Tags voice
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} voiceSessionListResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /bots/{bot_id}/voice/sessions [get].
+func (h *VoiceHandler) ListSessions(c echo.Context) error {
+	_, botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, voiceSessionListResponse{Items: h.service.ListSessions(botID)})
+}
+
+// CloseSession godoc
+// @Summary Hang up a voice call
+// @Tags voice
+// @Param bot_id path string true "Bot ID"
+// @Param session_id path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /bots/{bot_id}/voice/sessions/{session_id} [delete].
+func (h *VoiceHandler) CloseSession(c echo.Context) error {
+	_, botID, err := h.requireBotAccess(c)
+	if err != nil {
+		return err
+	}
+	if !h.service.CloseSession(botID, strings.TrimSpace(c.Param("session_id"))) {
+		return echo.NewHTTPError(http.StatusNotFound, "voice session not found")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *VoiceHandler) requireBotAccess(c echo.Context) (string, string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	if _, err := h.authorizeBotAccess(c.Request().Context(), userID, botID); err != nil {
+		return "", "", err
+	}
+	return userID, botID, nil
+}
+
+func (h *VoiceHandler) authorizeBotAccess(ctx context.Context, userID, botID string) (bots.Bot, error) {
+	return AuthorizeBotAccess(ctx, h.botService, h.accountService, userID, botID)
+}
+
+func voiceHTTPError(err error) error {
+	switch {
+	case errors.Is(err, voice.ErrInvalidOffer), errors.Is(err, voice.ErrSpeechNotConfigured):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, voice.ErrTooManyCalls):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, voice.ErrVoiceUnavailable):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
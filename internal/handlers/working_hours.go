@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/accounts"
+	"github.com/memohai/memoh/internal/bots"
+	"github.com/memohai/memoh/internal/channel/workhours"
+)
+
+type WorkingHoursHandler struct {
+	service        *workhours.Service
+	botService     *bots.Service
+	accountService *accounts.Service
+	logger         *slog.Logger
+}
+
+func NewWorkingHoursHandler(log *slog.Logger, service *workhours.Service, botService *bots.Service, accountService *accounts.Service) *WorkingHoursHandler {
+	return &WorkingHoursHandler{
+		service:        service,
+		botService:     botService,
+		accountService: accountService,
+		logger:         log.With(slog.String("handler", "working_hours")),
+	}
+}
+
+func (h *WorkingHoursHandler) Register(e *echo.Echo) {
+	group := e.Group("/bots/:bot_id/working-hours")
+	group.GET("", h.Get)
+	group.PUT("", h.Replace)
+}
+
+// Get godoc
+// @Summary Get a bot's working hours
+// @Description Bots without working hours are always available
+// @Tags working-hours
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Success 200 {object} workhours.Hours
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/working-hours [get].
+func (h *WorkingHoursHandler) Get(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionChat)
+	if err != nil {
+		return err
+	}
+	hours, err := h.service.Get(c.Request().Context(), botID)
+	if err != nil {
+		return workingHoursHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, hours)
+}
+
+// Replace godoc
+// @Summary Replace a bot's working hours
+// @Description Private messages received outside the windows get the away message, or a localized default. With defer_replies they are also queued and answered at the next opening time
+// @Tags working-hours
+// @Accept json
+// @Produce json
+// @Param bot_id path string true "Bot ID"
+// @Param request body workhours.Hours true "Working hours"
+// @Success 200 {object} workhours.Hours
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /bots/{bot_id}/working-hours [put].
+func (h *WorkingHoursHandler) Replace(c echo.Context) error {
+	botID, err := h.authorizeBot(c, bots.PermissionManage)
+	if err != nil {
+		return err
+	}
+	var req workhours.Hours
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	hours, err := h.service.Replace(c.Request().Context(), botID, req)
+	if err != nil {
+		return workingHoursHTTPError(err)
+	}
+	return c.JSON(http.StatusOK, hours)
+}
+
+func (h *WorkingHoursHandler) authorizeBot(c echo.Context, permission string) (string, error) {
+	userID, err := RequireChannelIdentityID(c)
+	if err != nil {
+		return "", err
+	}
+	botID := strings.TrimSpace(c.Param("bot_id"))
+	if botID == "" {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "bot id is required")
+	}
+	bot, err := AuthorizeBotAccessWithPermission(c.Request().Context(), h.botService, h.accountService, userID, botID, permission)
+	if err != nil {
+		return "", err
+	}
+	return bot.ID, nil
+}
+
+func workingHoursHTTPError(err error) error {
+	if errors.Is(err, workhours.ErrInvalidHours) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
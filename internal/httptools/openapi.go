@@ -0,0 +1,324 @@
+package httptools
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidSpec reports an OpenAPI document that cannot be turned into
+// tools.
+var ErrInvalidSpec = errors.New("invalid openapi spec")
+
+const (
+	maxOperations = 100
+	maxToolName   = 64
+	// maxRefDepth bounds $ref resolution so recursive schemas terminate.
+	maxRefDepth = 8
+)
+
+var (
+	methods         = []string{"get", "put", "post", "patch", "delete"}
+	toolNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+)
+
+// Operation is one API operation exposed as a tool.
+type Operation struct {
+	Name         string         `json:"name"`
+	OperationID  string         `json:"operation_id,omitempty"`
+	Description  string         `json:"description,omitempty"`
+	Method       string         `json:"method"`
+	Path         string         `json:"path"`
+	Parameters   []Parameter    `json:"parameters,omitempty"`
+	Body         bool           `json:"body,omitempty"`
+	BodyRequired bool           `json:"body_required,omitempty"`
+	InputSchema  map[string]any `json:"input_schema"`
+}
+
+// Parameter is a path, query or header parameter of an operation.
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// parsedSpec is the part of an OpenAPI document the import keeps.
+type parsedSpec struct {
+	serverURL  string
+	operations []Operation
+}
+
+// parseSpec turns an OpenAPI 3 document (JSON or YAML) into tool operations.
+// prefix namespaces the tool names; include, when non-empty, limits the
+// import to the listed operation IDs.
+func parseSpec(raw, prefix string, include []string) (parsedSpec, error) {
+	var doc any
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return parsedSpec{}, fmt.Errorf("%w: %s", ErrInvalidSpec, err.Error())
+	}
+	root, ok := normalizeYAML(doc).(map[string]any)
+	if !ok {
+		return parsedSpec{}, fmt.Errorf("%w: document must be an object", ErrInvalidSpec)
+	}
+	if version, _ := root["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return parsedSpec{}, fmt.Errorf("%w: only OpenAPI 3 documents are supported", ErrInvalidSpec)
+	}
+	paths, _ := root["paths"].(map[string]any)
+	if len(paths) == 0 {
+		return parsedSpec{}, fmt.Errorf("%w: no paths", ErrInvalidSpec)
+	}
+	wanted := map[string]bool{}
+	for _, id := range include {
+		if id = strings.TrimSpace(id); id != "" {
+			wanted[id] = true
+		}
+	}
+
+	r := resolver{root: root}
+	pathKeys := make([]string, 0, len(paths))
+	for path := range paths {
+		pathKeys = append(pathKeys, path)
+	}
+	sort.Strings(pathKeys)
+
+	var ops []Operation
+	names := map[string]bool{}
+	for _, path := range pathKeys {
+		item, _ := r.deref(paths[path], 0).(map[string]any)
+		shared := r.parameters(item["parameters"])
+		for _, method := range methods {
+			rawOp, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			opID, _ := rawOp["operationId"].(string)
+			opID = strings.TrimSpace(opID)
+			if len(wanted) > 0 && !wanted[opID] {
+				continue
+			}
+			op := r.operation(method, path, opID, rawOp, shared)
+			op.Name = uniqueToolName(prefix, opID, method, path, names)
+			ops = append(ops, op)
+			if len(ops) > maxOperations {
+				return parsedSpec{}, fmt.Errorf("%w: more than %d operations; list the operations to import", ErrInvalidSpec, maxOperations)
+			}
+		}
+	}
+	if len(ops) == 0 {
+		return parsedSpec{}, fmt.Errorf("%w: no operations to import", ErrInvalidSpec)
+	}
+	spec := parsedSpec{operations: ops}
+	if servers, _ := root["servers"].([]any); len(servers) > 0 {
+		if server, ok := servers[0].(map[string]any); ok {
+			spec.serverURL, _ = server["url"].(string)
+		}
+	}
+	return spec, nil
+}
+
+type resolver struct {
+	root map[string]any
+}
+
+// deref follows a local "#/..." $ref. Remote refs are left unresolved.
+func (r resolver) deref(v any, depth int) any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	ref, _ := m["$ref"].(string)
+	if ref == "" || depth >= maxRefDepth || !strings.HasPrefix(ref, "#/") {
+		return v
+	}
+	var cur any = r.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		next, ok := cur.(map[string]any)
+		if !ok {
+			return map[string]any{}
+		}
+		cur = next[part]
+	}
+	return r.deref(cur, depth+1)
+}
+
+// schema returns a copy of a JSON schema with local refs inlined. Refs
+// nested deeper than maxRefDepth become an unconstrained object.
+func (r resolver) schema(v any, depth int) any {
+	if depth > maxRefDepth {
+		return map[string]any{"type": "object"}
+	}
+	switch value := v.(type) {
+	case map[string]any:
+		if ref, _ := value["$ref"].(string); ref != "" {
+			return r.schema(r.deref(map[string]any{"$ref": ref}, 0), depth+1)
+		}
+		out := make(map[string]any, len(value))
+		for k, item := range value {
+			out[k] = r.schema(item, depth)
+		}
+		return out
+	case []any:
+		out := make([]any, len(value))
+		for i, item := range value {
+			out[i] = r.schema(item, depth)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+type specParameter struct {
+	Parameter
+	description string
+	schema      any
+}
+
+func (r resolver) parameters(v any) []specParameter {
+	list, _ := v.([]any)
+	out := make([]specParameter, 0, len(list))
+	for _, raw := range list {
+		p, ok := r.deref(raw, 0).(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := p["name"].(string)
+		in, _ := p["in"].(string)
+		if strings.TrimSpace(name) == "" || (in != "path" && in != "query" && in != "header") {
+			continue
+		}
+		required, _ := p["required"].(bool)
+		description, _ := p["description"].(string)
+		schema := r.schema(p["schema"], 0)
+		if schema == nil {
+			schema = map[string]any{"type": "string"}
+		}
+		out = append(out, specParameter{
+			Parameter:   Parameter{Name: name, In: in, Required: required || in == "path"},
+			description: description,
+			schema:      schema,
+		})
+	}
+	return out
+}
+
+func (r resolver) operation(method, path, opID string, raw map[string]any, shared []specParameter) Operation {
+	op := Operation{OperationID: opID, Method: strings.ToUpper(method), Path: path}
+	summary, _ := raw["summary"].(string)
+	description, _ := raw["description"].(string)
+	op.Description = strings.TrimSpace(strings.TrimSpace(summary) + "\n\n" + strings.TrimSpace(description))
+	if op.Description == "" {
+		op.Description = op.Method + " " + path
+	}
+
+	params := map[string]specParameter{}
+	var order []string
+	for _, p := range append(shared, r.parameters(raw["parameters"])...) {
+		if _, seen := params[p.Name]; !seen {
+			order = append(order, p.Name)
+		}
+		params[p.Name] = p
+	}
+	properties := map[string]any{}
+	var required []string
+	for _, name := range order {
+		p := params[name]
+		prop, _ := p.schema.(map[string]any)
+		if prop == nil {
+			prop = map[string]any{}
+		}
+		if p.description != "" {
+			if _, ok := prop["description"]; !ok {
+				prop["description"] = p.description
+			}
+		}
+		properties[name] = prop
+		op.Parameters = append(op.Parameters, p.Parameter)
+		if p.Required {
+			required = append(required, name)
+		}
+	}
+	if body, ok := r.deref(raw["requestBody"], 0).(map[string]any); ok {
+		content, _ := body["content"].(map[string]any)
+		if media, ok := content["application/json"].(map[string]any); ok {
+			op.Body = true
+			op.BodyRequired, _ = body["required"].(bool)
+			bodySchema, _ := r.schema(media["schema"], 0).(map[string]any)
+			if bodySchema == nil {
+				bodySchema = map[string]any{"type": "object"}
+			}
+			properties["body"] = bodySchema
+			if op.BodyRequired {
+				required = append(required, "body")
+			}
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	op.InputSchema = schema
+	return op
+}
+
+// uniqueToolName builds "<prefix>_<operationId>", falling back to the method
+// and path, and keeps names unique within one import.
+func uniqueToolName(prefix, opID, method, path string, taken map[string]bool) string {
+	base := opID
+	if base == "" {
+		base = method + "_" + path
+	}
+	base = strings.Trim(toolNameInvalid.ReplaceAllString(base, "_"), "_")
+	name := prefix + "_" + base
+	if len(name) > maxToolName {
+		name = name[:maxToolName]
+	}
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		suffix := fmt.Sprintf("_%d", i)
+		if len(name)+len(suffix) > maxToolName {
+			candidate = name[:maxToolName-len(suffix)] + suffix
+		} else {
+			candidate = name + suffix
+		}
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+// normalizeYAML converts YAML maps with non-string keys (such as unquoted
+// response codes) into JSON-compatible maps.
+func normalizeYAML(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, item := range value {
+			value[k] = normalizeYAML(item)
+		}
+		return value
+	case map[any]any:
+		out := make(map[string]any, len(value))
+		for k, item := range value {
+			out[fmt.Sprint(k)] = normalizeYAML(item)
+		}
+		return out
+	case []any:
+		for i, item := range value {
+			value[i] = normalizeYAML(item)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// validBaseURL reports whether raw is an absolute http(s) URL.
+func validBaseURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
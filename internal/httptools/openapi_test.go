@@ -0,0 +1,122 @@
+package httptools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const petSpec = `
+openapi: 3.0.0
+servers:
+  - url: https://pets.example.com/v1
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        schema: {type: string}
+    get:
+      operationId: getPet
+      summary: Get a pet
+      parameters:
+        - name: verbose
+          in: query
+          schema: {type: boolean}
+      responses:
+        200: {description: ok}
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Pet'}
+      responses:
+        201: {description: created}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+        parent: {$ref: '#/components/schemas/Pet'}
+`
+
+func TestParseSpecBuildsOperations(t *testing.T) {
+	t.Parallel()
+
+	spec, err := parseSpec(petSpec, "pets", nil)
+	if err != nil {
+		t.Fatalf("parseSpec: %v", err)
+	}
+	if spec.serverURL != "https://pets.example.com/v1" {
+		t.Fatalf("server url = %q", spec.serverURL)
+	}
+	if len(spec.operations) != 2 {
+		t.Fatalf("operations = %+v", spec.operations)
+	}
+	create, get := spec.operations[0], spec.operations[1]
+	if create.Name != "pets_createPet" || create.Method != "POST" || !create.Body || !create.BodyRequired {
+		t.Fatalf("create = %+v", create)
+	}
+	body, _ := create.InputSchema["properties"].(map[string]any)["body"].(map[string]any)
+	if _, ok := body["properties"].(map[string]any)["name"]; !ok {
+		t.Fatalf("body schema not resolved: %+v", body)
+	}
+	if get.Name != "pets_getPet" || get.Description != "Get a pet" || len(get.Parameters) != 2 {
+		t.Fatalf("get = %+v", get)
+	}
+	if !get.Parameters[0].Required || get.Parameters[0].In != "path" {
+		t.Fatalf("path parameter = %+v", get.Parameters[0])
+	}
+	required, _ := get.InputSchema["required"].([]string)
+	if len(required) != 1 || required[0] != "petId" {
+		t.Fatalf("required = %v", required)
+	}
+}
+
+func TestParseSpecFiltersOperations(t *testing.T) {
+	t.Parallel()
+
+	spec, err := parseSpec(petSpec, "pets", []string{"getPet"})
+	if err != nil {
+		t.Fatalf("parseSpec: %v", err)
+	}
+	if len(spec.operations) != 1 || spec.operations[0].OperationID != "getPet" {
+		t.Fatalf("operations = %+v", spec.operations)
+	}
+	if _, err := parseSpec(petSpec, "pets", []string{"missing"}); !errors.Is(err, ErrInvalidSpec) {
+		t.Fatalf("err = %v, want ErrInvalidSpec", err)
+	}
+}
+
+func TestParseSpecRejectsInvalidDocuments(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{
+		"not: [valid",
+		`{"swagger": "2.0", "paths": {"/a": {"get": {}}}}`,
+		`{"openapi": "3.1.0", "paths": {}}`,
+	} {
+		if _, err := parseSpec(raw, "x", nil); !errors.Is(err, ErrInvalidSpec) {
+			t.Fatalf("parseSpec(%q) err = %v, want ErrInvalidSpec", raw, err)
+		}
+	}
+}
+
+func TestUniqueToolName(t *testing.T) {
+	t.Parallel()
+
+	taken := map[string]bool{}
+	if got := uniqueToolName("api", "", "get", "/users/{id}", taken); got != "api_get__users_id" {
+		t.Fatalf("fallback name = %q", got)
+	}
+	long := strings.Repeat("a", 80)
+	first := uniqueToolName("api", long, "get", "/", taken)
+	second := uniqueToolName("api", long, "get", "/", taken)
+	if len(first) != maxToolName || len(second) != maxToolName || first == second || !strings.HasSuffix(second, "_2") {
+		t.Fatalf("names = %q, %q", first, second)
+	}
+}
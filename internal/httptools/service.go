@@ -0,0 +1,316 @@
+// Package httptools turns OpenAPI specs into per-bot tools. Each imported
+// API becomes a tool set; its operations are served through the MCP tool
+// gateway and the agent's tool providers, and calls are sent to the API
+// with the set's stored credentials.
+package httptools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/netguard"
+)
+
+// ErrInvalidToolSet reports an import request with an invalid name, base URL
+// or auth setting.
+var ErrInvalidToolSet = errors.New("invalid http tool set")
+
+const (
+	AuthNone   = "none"
+	AuthBearer = "bearer"
+	AuthHeader = "header"
+	AuthQuery  = "query"
+	AuthBasic  = "basic"
+)
+
+const (
+	callTimeout = 30 * time.Second
+	// maxResponseBytes caps how much of a response body is returned to the
+	// model; longer bodies are cut and marked truncated.
+	maxResponseBytes = 64 << 10
+)
+
+var setNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+type httpToolQueries interface {
+	ListBotHTTPTools(ctx context.Context, botID pgtype.UUID) ([]sqlc.BotHttpTool, error)
+	UpsertBotHTTPTool(ctx context.Context, arg sqlc.UpsertBotHTTPToolParams) (sqlc.BotHttpTool, error)
+	DeleteBotHTTPTool(ctx context.Context, arg sqlc.DeleteBotHTTPToolParams) error
+}
+
+// Auth is how requests of a tool set are authenticated. Value holds the
+// token, header value, query value or password.
+type Auth struct {
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+// ToolSet is one imported API. Auth.Value is masked in service responses.
+type ToolSet struct {
+	ID         string      `json:"id"`
+	BotID      string      `json:"bot_id"`
+	Name       string      `json:"name"`
+	BaseURL    string      `json:"base_url"`
+	Operations []Operation `json:"operations"`
+	Auth       Auth        `json:"auth"`
+	Enabled    bool        `json:"enabled"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// ImportRequest imports an OpenAPI spec as a tool set. Importing under an
+// existing name replaces that set; a nil Auth keeps its credentials.
+type ImportRequest struct {
+	Name string `json:"name"`
+	// Spec is the OpenAPI 3 document, JSON or YAML.
+	Spec string `json:"spec"`
+	// BaseURL overrides the first server URL of the spec.
+	BaseURL string `json:"base_url,omitempty"`
+	// Operations limits the import to these operation IDs.
+	Operations []string `json:"operations,omitempty"`
+	Auth       *Auth    `json:"auth,omitempty"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+// Service stores tool sets and serves their operations as tools.
+type Service struct {
+	queries dbstore.Queries
+	client  *http.Client
+	logger  *slog.Logger
+}
+
+// NewService creates an HTTP tool service. Operations are called with a
+// client that refuses loopback, private and link-local addresses.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		client:  netguard.NewClient(callTimeout),
+		logger:  log.With(slog.String("service", "http_tools")),
+	}
+}
+
+func (s *Service) store() (httpToolQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("http tool service not configured")
+	}
+	store, ok := s.queries.(httpToolQueries)
+	if !ok {
+		return nil, errors.New("http tool queries not supported by store")
+	}
+	return store, nil
+}
+
+// List returns a bot's tool sets with masked credentials.
+func (s *Service) List(ctx context.Context, botID string) ([]ToolSet, error) {
+	sets, err := s.list(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sets {
+		sets[i].Auth.Value = maskSecret(sets[i].Auth.Value)
+	}
+	return sets, nil
+}
+
+func (s *Service) list(ctx context.Context, botID string) ([]ToolSet, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListBotHTTPTools(ctx, pgBotID)
+	if err != nil {
+		return nil, fmt.Errorf("list http tools: %w", err)
+	}
+	sets := make([]ToolSet, 0, len(rows))
+	for _, row := range rows {
+		set, err := toToolSet(row)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// Import parses req.Spec and stores its operations as a tool set.
+func (s *Service) Import(ctx context.Context, botID string, req ImportRequest) (ToolSet, error) {
+	store, err := s.store()
+	if err != nil {
+		return ToolSet{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return ToolSet{}, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if !setNamePattern.MatchString(name) {
+		return ToolSet{}, fmt.Errorf("%w: name must be 1-32 lowercase letters, digits or underscores, starting with a letter", ErrInvalidToolSet)
+	}
+	spec, err := parseSpec(req.Spec, name, req.Operations)
+	if err != nil {
+		return ToolSet{}, err
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(req.BaseURL), "/")
+	if baseURL == "" {
+		baseURL = strings.TrimRight(strings.TrimSpace(spec.serverURL), "/")
+	}
+	if !validBaseURL(baseURL) {
+		return ToolSet{}, fmt.Errorf("%w: base_url must be an absolute http(s) URL", ErrInvalidToolSet)
+	}
+
+	var stored *Auth
+	if existing, err := s.list(ctx, botID); err == nil {
+		for _, set := range existing {
+			if set.Name == name {
+				stored = &set.Auth
+			}
+		}
+	}
+	auth := Auth{Type: AuthNone}
+	switch {
+	case req.Auth == nil && stored != nil:
+		auth = *stored
+	case req.Auth != nil:
+		auth = *req.Auth
+		// A masked value sent back unchanged keeps the stored secret.
+		if stored != nil && auth.Value != "" && auth.Value == maskSecret(stored.Value) {
+			auth.Value = stored.Value
+		}
+	}
+	if auth, err = normalizeAuth(auth); err != nil {
+		return ToolSet{}, err
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	operations, err := json.Marshal(spec.operations)
+	if err != nil {
+		return ToolSet{}, err
+	}
+	authJSON, err := json.Marshal(auth)
+	if err != nil {
+		return ToolSet{}, err
+	}
+	row, err := store.UpsertBotHTTPTool(ctx, sqlc.UpsertBotHTTPToolParams{
+		BotID:      pgBotID,
+		Name:       name,
+		BaseUrl:    baseURL,
+		Operations: operations,
+		Auth:       authJSON,
+		Enabled:    enabled,
+	})
+	if err != nil {
+		return ToolSet{}, fmt.Errorf("save http tools: %w", err)
+	}
+	set, err := toToolSet(row)
+	if err != nil {
+		return ToolSet{}, err
+	}
+	set.Auth.Value = maskSecret(set.Auth.Value)
+	return set, nil
+}
+
+// Delete removes a tool set.
+func (s *Service) Delete(ctx context.Context, botID, id string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return err
+	}
+	return store.DeleteBotHTTPTool(ctx, sqlc.DeleteBotHTTPToolParams{BotID: pgBotID, ID: pgID})
+}
+
+func normalizeAuth(auth Auth) (Auth, error) {
+	auth.Type = strings.ToLower(strings.TrimSpace(auth.Type))
+	auth.Name = strings.TrimSpace(auth.Name)
+	auth.Username = strings.TrimSpace(auth.Username)
+	auth.Value = strings.TrimSpace(auth.Value)
+	switch auth.Type {
+	case "", AuthNone:
+		return Auth{Type: AuthNone}, nil
+	case AuthBearer:
+		auth.Name, auth.Username = "", ""
+	case AuthHeader, AuthQuery:
+		if auth.Name == "" {
+			return Auth{}, fmt.Errorf("%w: %s auth needs a name", ErrInvalidToolSet, auth.Type)
+		}
+		auth.Username = ""
+	case AuthBasic:
+		if auth.Username == "" {
+			return Auth{}, fmt.Errorf("%w: basic auth needs a username", ErrInvalidToolSet)
+		}
+		auth.Name = ""
+	default:
+		return Auth{}, fmt.Errorf("%w: unknown auth type %q", ErrInvalidToolSet, auth.Type)
+	}
+	if auth.Value == "" {
+		return Auth{}, fmt.Errorf("%w: %s auth needs a value", ErrInvalidToolSet, auth.Type)
+	}
+	return auth, nil
+}
+
+func toToolSet(row sqlc.BotHttpTool) (ToolSet, error) {
+	set := ToolSet{
+		ID:        row.ID.String(),
+		BotID:     row.BotID.String(),
+		Name:      row.Name,
+		BaseURL:   row.BaseUrl,
+		Enabled:   row.Enabled,
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+	if len(row.Operations) > 0 {
+		if err := json.Unmarshal(row.Operations, &set.Operations); err != nil {
+			return ToolSet{}, fmt.Errorf("decode http tool operations: %w", err)
+		}
+	}
+	if len(row.Auth) > 0 {
+		if err := json.Unmarshal(row.Auth, &set.Auth); err != nil {
+			return ToolSet{}, fmt.Errorf("decode http tool auth: %w", err)
+		}
+	}
+	if set.Auth.Type == "" {
+		set.Auth.Type = AuthNone
+	}
+	return set, nil
+}
+
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) <= 8 {
+		return "********"
+	}
+	return value[:4] + "****" + value[len(value)-4:]
+}
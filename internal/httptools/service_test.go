@@ -0,0 +1,224 @@
+package httptools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/mcp"
+)
+
+const testBotID = "11111111-1111-1111-1111-111111111111"
+
+type fakeHTTPToolQueries struct {
+	dbstore.Queries
+
+	rows []sqlc.BotHttpTool
+}
+
+func (f *fakeHTTPToolQueries) ListBotHTTPTools(context.Context, pgtype.UUID) ([]sqlc.BotHttpTool, error) {
+	return f.rows, nil
+}
+
+func (f *fakeHTTPToolQueries) UpsertBotHTTPTool(_ context.Context, arg sqlc.UpsertBotHTTPToolParams) (sqlc.BotHttpTool, error) {
+	id, _ := db.ParseUUID("22222222-2222-2222-2222-222222222222")
+	row := sqlc.BotHttpTool{
+		ID:         id,
+		BotID:      arg.BotID,
+		Name:       arg.Name,
+		BaseUrl:    arg.BaseUrl,
+		Operations: arg.Operations,
+		Auth:       arg.Auth,
+		Enabled:    arg.Enabled,
+	}
+	for i := range f.rows {
+		if f.rows[i].Name == arg.Name {
+			f.rows[i] = row
+			return row, nil
+		}
+	}
+	f.rows = append(f.rows, row)
+	return row, nil
+}
+
+func (*fakeHTTPToolQueries) DeleteBotHTTPTool(context.Context, sqlc.DeleteBotHTTPToolParams) error {
+	return nil
+}
+
+func TestImportMasksAndKeepsCredentials(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(nil, &fakeHTTPToolQueries{})
+	ctx := context.Background()
+	set, err := svc.Import(ctx, testBotID, ImportRequest{
+		Name: "pets",
+		Spec: petSpec,
+		Auth: &Auth{Type: "Bearer", Value: "secret-token-1234"},
+	})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if set.BaseURL != "https://pets.example.com/v1" || !set.Enabled || len(set.Operations) != 2 {
+		t.Fatalf("set = %+v", set)
+	}
+	if set.Auth.Type != AuthBearer || set.Auth.Value != "secr****1234" {
+		t.Fatalf("auth = %+v", set.Auth)
+	}
+
+	// Re-importing with the masked value keeps the stored secret.
+	if _, err := svc.Import(ctx, testBotID, ImportRequest{Name: "pets", Spec: petSpec, Auth: &set.Auth}); err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	sets, err := svc.list(ctx, testBotID)
+	if err != nil || len(sets) != 1 {
+		t.Fatalf("list = %+v, %v", sets, err)
+	}
+	if sets[0].Auth.Value != "secret-token-1234" {
+		t.Fatalf("stored secret = %q", sets[0].Auth.Value)
+	}
+}
+
+func TestImportRejectsInvalidToolSets(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(nil, &fakeHTTPToolQueries{})
+	for _, req := range []ImportRequest{
+		{Name: "Bad Name", Spec: petSpec},
+		{Name: "pets", Spec: petSpec, BaseURL: "ftp://pets.example.com"},
+		{Name: "pets", Spec: petSpec, Auth: &Auth{Type: AuthHeader, Value: "x"}},
+		{Name: "pets", Spec: petSpec, Auth: &Auth{Type: "oauth", Value: "x"}},
+	} {
+		if _, err := svc.Import(context.Background(), testBotID, req); !errors.Is(err, ErrInvalidToolSet) {
+			t.Fatalf("Import(%+v) err = %v, want ErrInvalidToolSet", req, err)
+		}
+	}
+}
+
+func TestCallToolSendsSignedRequest(t *testing.T) {
+	t.Parallel()
+
+	var got *http.Request
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		raw, _ := io.ReadAll(r.Body)
+		gotBody = string(raw)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte("exists"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "p 1"})
+	}))
+	defer server.Close()
+
+	svc := NewService(nil, &fakeHTTPToolQueries{})
+	svc.client = server.Client()
+	ctx := context.Background()
+	if _, err := svc.Import(ctx, testBotID, ImportRequest{
+		Name:    "pets",
+		Spec:    petSpec,
+		BaseURL: server.URL + "/v1",
+		Auth:    &Auth{Type: AuthHeader, Name: "X-Api-Key", Value: "key-123"},
+	}); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	session := mcp.ToolSessionContext{BotID: testBotID}
+
+	tools, err := svc.ListTools(ctx, session)
+	if err != nil || len(tools) != 2 {
+		t.Fatalf("ListTools = %+v, %v", tools, err)
+	}
+
+	result, err := svc.CallTool(ctx, session, "pets_getPet", map[string]any{"petId": "p 1", "verbose": true})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if got.URL.EscapedPath() != "/v1/pets/p%201" || got.URL.Query().Get("verbose") != "true" {
+		t.Fatalf("request url = %s", got.URL)
+	}
+	if got.Header.Get("X-Api-Key") != "key-123" {
+		t.Fatalf("auth header = %q", got.Header.Get("X-Api-Key"))
+	}
+	structured, _ := result["structuredContent"].(map[string]any)
+	if structured["status"] != http.StatusOK || structured["body"].(map[string]any)["id"] != "p 1" {
+		t.Fatalf("result = %+v", result)
+	}
+
+	result, err = svc.CallTool(ctx, session, "pets_createPet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if gotBody != `{"name":"Rex"}` || got.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("request body = %q", gotBody)
+	}
+	if result["isError"] != true {
+		t.Fatalf("result = %+v, want error result", result)
+	}
+
+	result, _ = svc.CallTool(ctx, session, "pets_getPet", map[string]any{})
+	if result["isError"] != true {
+		t.Fatalf("missing parameter result = %+v", result)
+	}
+	if _, err := svc.CallTool(ctx, session, "other_tool", nil); !errors.Is(err, mcp.ErrToolNotFound) {
+		t.Fatalf("unknown tool err = %v", err)
+	}
+}
+
+func TestCallToolTruncatesLargeResponses(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", maxResponseBytes+10)))
+	}))
+	defer server.Close()
+
+	svc := NewService(nil, &fakeHTTPToolQueries{})
+	svc.client = server.Client()
+	ctx := context.Background()
+	if _, err := svc.Import(ctx, testBotID, ImportRequest{Name: "pets", Spec: petSpec, BaseURL: server.URL}); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	result, err := svc.CallTool(ctx, mcp.ToolSessionContext{BotID: testBotID}, "pets_getPet", map[string]any{"petId": "1"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	structured, _ := result["structuredContent"].(map[string]any)
+	if structured["truncated"] != true || len(structured["body"].(string)) != maxResponseBytes {
+		t.Fatalf("structured = %v", structured["truncated"])
+	}
+}
+
+func TestCallToolRefusesLoopbackAPIs(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	svc := NewService(nil, &fakeHTTPToolQueries{})
+	ctx := context.Background()
+	if _, err := svc.Import(ctx, testBotID, ImportRequest{Name: "pets", Spec: petSpec, BaseURL: server.URL}); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	result, err := svc.CallTool(ctx, mcp.ToolSessionContext{BotID: testBotID}, "pets_getPet", map[string]any{"petId": "1"})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if result["isError"] != true || called {
+		t.Fatalf("result = %+v, called = %v, want the loopback call refused", result, called)
+	}
+}
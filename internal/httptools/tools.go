@@ -0,0 +1,183 @@
+package httptools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/memohai/memoh/internal/mcp"
+)
+
+// ListTools implements mcp.ToolSource with the operations of the session
+// bot's enabled tool sets.
+func (s *Service) ListTools(ctx context.Context, session mcp.ToolSessionContext) ([]mcp.ToolDescriptor, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if botID == "" {
+		return nil, nil
+	}
+	sets, err := s.list(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	var tools []mcp.ToolDescriptor
+	for _, set := range sets {
+		if !set.Enabled {
+			continue
+		}
+		for _, op := range set.Operations {
+			tools = append(tools, mcp.ToolDescriptor{
+				Name:        op.Name,
+				Description: op.Description,
+				InputSchema: op.InputSchema,
+			})
+		}
+	}
+	return tools, nil
+}
+
+// CallTool implements mcp.ToolSource by sending the operation's request.
+// Request and HTTP errors are returned as tool error results.
+func (s *Service) CallTool(ctx context.Context, session mcp.ToolSessionContext, toolName string, arguments map[string]any) (map[string]any, error) {
+	botID := strings.TrimSpace(session.BotID)
+	if botID == "" {
+		return nil, mcp.ErrToolNotFound
+	}
+	sets, err := s.list(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	for _, set := range sets {
+		if !set.Enabled {
+			continue
+		}
+		for _, op := range set.Operations {
+			if op.Name == toolName {
+				return s.call(ctx, set, op, arguments), nil
+			}
+		}
+	}
+	return nil, mcp.ErrToolNotFound
+}
+
+func (s *Service) call(ctx context.Context, set ToolSet, op Operation, arguments map[string]any) map[string]any {
+	req, err := buildRequest(ctx, set, op, arguments)
+	if err != nil {
+		return mcp.BuildToolErrorResult(err.Error())
+	}
+	resp, err := s.client.Do(req) //nolint:gosec // G704: the URL is the bot owner's configured API base URL
+	if err != nil {
+		s.logger.Debug("http tool request failed", slog.String("tool", op.Name), slog.Any("error", err))
+		return mcp.BuildToolErrorResult(fmt.Sprintf("request failed: %v", err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return mcp.BuildToolErrorResult(fmt.Sprintf("read response: %v", err))
+	}
+	truncated := len(raw) > maxResponseBytes
+	if truncated {
+		raw = raw[:maxResponseBytes]
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return mcp.BuildToolErrorResult(fmt.Sprintf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(raw))))
+	}
+	result := map[string]any{"status": resp.StatusCode}
+	var decoded any
+	if !truncated && json.Unmarshal(raw, &decoded) == nil {
+		result["body"] = decoded
+	} else {
+		result["body"] = string(raw)
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+	return mcp.BuildToolSuccessResult(result)
+}
+
+// buildRequest fills the operation's path, query and header parameters
+// from arguments, encodes the body and applies the set's credentials.
+func buildRequest(ctx context.Context, set ToolSet, op Operation, arguments map[string]any) (*http.Request, error) {
+	path := op.Path
+	query := url.Values{}
+	header := http.Header{}
+	for _, p := range op.Parameters {
+		value, ok := arguments[p.Name]
+		if !ok || value == nil {
+			if p.Required {
+				return nil, fmt.Errorf("missing required parameter %q", p.Name)
+			}
+			continue
+		}
+		text := argumentText(value)
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(text))
+		case "query":
+			if list, ok := value.([]any); ok {
+				for _, item := range list {
+					query.Add(p.Name, argumentText(item))
+				}
+				continue
+			}
+			query.Set(p.Name, text)
+		case "header":
+			header.Set(p.Name, text)
+		}
+	}
+	var body io.Reader
+	if op.Body {
+		value, ok := arguments["body"]
+		if ok && value != nil {
+			payload, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("encode body: %w", err)
+			}
+			body = bytes.NewReader(payload)
+			header.Set("Content-Type", "application/json")
+		} else if op.BodyRequired {
+			return nil, fmt.Errorf("missing required parameter %q", "body")
+		}
+	}
+	switch set.Auth.Type {
+	case AuthBearer:
+		header.Set("Authorization", "Bearer "+set.Auth.Value)
+	case AuthHeader:
+		header.Set(set.Auth.Name, set.Auth.Value)
+	case AuthQuery:
+		query.Set(set.Auth.Name, set.Auth.Value)
+	}
+
+	target := strings.TrimRight(set.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+	if encoded := query.Encode(); encoded != "" {
+		target += "?" + encoded
+	}
+	req, err := http.NewRequestWithContext(ctx, op.Method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if set.Auth.Type == AuthBasic {
+		req.SetBasicAuth(set.Auth.Username, set.Auth.Value)
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func argumentText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%f", v), "0"), ".")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,82 @@
+package knowledge
+
+import "strings"
+
+const (
+	// DefaultChunkSize is the target chunk length in runes for collections
+	// created without one. Chunks break on paragraph boundaries where
+	// possible so retrieved passages stay readable.
+	DefaultChunkSize = 1200
+	// MinChunkSize and MaxChunkSize bound per-collection chunk sizes; the
+	// database enforces the same range.
+	MinChunkSize = 200
+	MaxChunkSize = 8000
+	// maxChunks caps how many chunks one document may produce.
+	maxChunks = 512
+)
+
+// chunkText splits content into paragraph-aligned chunks of at most size
+// runes. Paragraphs longer than the budget are hard-split. With overlap > 0,
+// every chunk after the first starts with the last overlap runes of the
+// previous chunk so sentences cut at a boundary stay retrievable.
+func chunkText(content string, size, overlap int) []string {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if overlap < 0 || overlap*2 > size {
+		overlap = 0
+	}
+	// Leave room for the overlap and the newline joining it to the chunk.
+	budget := size
+	if overlap > 0 {
+		budget = size - overlap - 1
+	}
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+	flush := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			chunks = append(chunks, text)
+		}
+		current.Reset()
+		currentLen = 0
+	}
+	for _, paragraph := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		runes := []rune(paragraph)
+		if currentLen > 0 && currentLen+2+len(runes) > budget {
+			flush()
+		}
+		for len(runes) > budget {
+			if currentLen > 0 {
+				flush()
+			}
+			chunks = append(chunks, string(runes[:budget]))
+			runes = runes[budget:]
+		}
+		if currentLen > 0 {
+			current.WriteString("\n\n")
+			currentLen += 2
+		}
+		current.WriteString(string(runes))
+		currentLen += len(runes)
+	}
+	flush()
+	if len(chunks) > maxChunks {
+		chunks = chunks[:maxChunks]
+	}
+	if overlap == 0 || len(chunks) < 2 {
+		return chunks
+	}
+	out := make([]string, len(chunks))
+	out[0] = chunks[0]
+	for i := 1; i < len(chunks); i++ {
+		prev := []rune(chunks[i-1])
+		tail := prev[max(0, len(prev)-overlap):]
+		out[i] = strings.TrimSpace(string(tail)) + "\n" + chunks[i]
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package knowledge
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// ConnectorAdapter syncs documents from an external knowledge tool into a
+// collection. Implementations live under internal/knowledge/connectors and
+// are registered on the service at startup.
+//
+// A sync asks the connector for the items changed since the cursor it
+// returned last time, then fetches the content of each item whose version
+// differs from the stored document. Connectors only read; the service owns
+// documents, OAuth tokens, and sync bookkeeping.
+type ConnectorAdapter interface {
+	// Type identifies the connector, e.g. "notion".
+	Type() string
+	Meta() ConnectorMeta
+	// OAuth describes the authorization the connector needs. Client
+	// credentials come from the OAuth clients config entry named by
+	// ConnectorMeta.OAuthClient.
+	OAuth() ConnectorOAuth
+	// NormalizeConfig validates connector settings and drops unknown keys.
+	NormalizeConfig(raw map[string]any) (map[string]any, error)
+	// Account describes the account a new token belongs to, for display.
+	Account(ctx context.Context, client *http.Client, token *oauth2.Token) (string, error)
+	// Changes lists items changed since cursor. An empty cursor lists every
+	// item, which the service treats as a full sync.
+	Changes(ctx context.Context, req ConnectorRequest, cursor string) (ConnectorChanges, error)
+	// Fetch returns the content of one item as plain text or Markdown.
+	Fetch(ctx context.Context, req ConnectorRequest, item ConnectorItem) (ConnectorContent, error)
+}
+
+// ConnectorMeta describes a connector type to clients.
+type ConnectorMeta struct {
+	Type         string           `json:"type"`
+	DisplayName  string           `json:"display_name"`
+	OAuthClient  string           `json:"oauth_client"`
+	ConfigFields []ConnectorField `json:"config_fields"`
+	// Configured reports whether the OAuth client is configured, so the
+	// connector can be authorized.
+	Configured bool `json:"configured"`
+}
+
+// ConnectorField describes one connector setting.
+type ConnectorField struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Example     any    `json:"example,omitempty"`
+}
+
+// ConnectorOAuth holds the default OAuth endpoints and scopes of a
+// connector. AuthParams are added to the authorization URL.
+type ConnectorOAuth struct {
+	AuthURL    string
+	TokenURL   string
+	Scopes     []string
+	AuthParams map[string]string
+}
+
+// ConnectorRequest is passed to every connector call. Client sends requests
+// with the connector's OAuth token and refreshes it when needed.
+type ConnectorRequest struct {
+	Client *http.Client
+	Config map[string]any
+}
+
+// ConnectorItem is one page or file reported by Changes. Version is an
+// opaque revision marker such as an edit time or revision number; an item
+// whose version matches the stored document is not fetched again.
+type ConnectorItem struct {
+	ExternalID string
+	Title      string
+	URL        string
+	Version    string
+	// Deleted items are removed from the collection.
+	Deleted bool
+}
+
+// ConnectorChanges is the result of Changes. Cursor is stored once the
+// sync succeeds and passed to the next incremental sync.
+type ConnectorChanges struct {
+	Items  []ConnectorItem
+	Cursor string
+}
+
+// ConnectorContent is the fetched content of an item. An empty Title keeps
+// the title reported by Changes.
+type ConnectorContent struct {
+	Title   string
+	Content string
+}
@@ -0,0 +1,1023 @@
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/oauth2"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	"github.com/memohai/memoh/internal/oauthclients"
+)
+
+const (
+	connectorQueueSize      = 64
+	connectorSweepInterval  = time.Minute
+	connectorSyncHistory    = 20
+	connectorRequestTimeout = time.Minute
+)
+
+// RegisterConnector makes a connector type available to collections. It is
+// called at startup, before Run.
+func (s *Service) RegisterConnector(adapter ConnectorAdapter) {
+	s.adapters[adapter.Type()] = adapter
+}
+
+// SetOAuthClients sets the OAuth client config connectors authorize with.
+func (s *Service) SetOAuthClients(clients oauthclients.Resolver) {
+	s.oauthClients = clients
+}
+
+// ListConnectorTypes lists the registered connector types, sorted by type.
+func (s *Service) ListConnectorTypes() []ConnectorMeta {
+	items := make([]ConnectorMeta, 0, len(s.adapters))
+	for _, adapter := range s.adapters {
+		meta := adapter.Meta()
+		meta.Configured = s.hasOAuthClient(adapter)
+		items = append(items, meta)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Type < items[j].Type })
+	return items
+}
+
+// CreateConnector adds a connector to a collection. Nothing is synced until
+// the connector is authorized; authorizing starts the first sync.
+func (s *Service) CreateConnector(ctx context.Context, collectionID string, req CreateConnectorRequest) (Connector, error) {
+	if s.externalDisabled {
+		return Connector{}, ErrExternalSourcesDisabled
+	}
+	store, err := s.store()
+	if err != nil {
+		return Connector{}, err
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return Connector{}, err
+	}
+	adapter, ok := s.adapters[strings.TrimSpace(req.Type)]
+	if !ok {
+		return Connector{}, fmt.Errorf("%w: unknown type %q", ErrInvalidConnector, req.Type)
+	}
+	name := req.Name
+	if strings.TrimSpace(name) == "" {
+		name = adapter.Meta().DisplayName
+	}
+	settings, err := normalizeConnectorSettings(adapter, name, req.Config, req.SyncPattern)
+	if err != nil {
+		return Connector{}, err
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	row, err := store.CreateKnowledgeConnector(ctx, sqlc.CreateKnowledgeConnectorParams{
+		CollectionID: collection.ID,
+		Type:         adapter.Type(),
+		Name:         settings.name,
+		Config:       encodeConnectorConfig(settings.config),
+		SyncPattern:  settings.syncPattern,
+		Enabled:      enabled,
+		NextSyncAt:   nextCrawlRun(settings.syncPattern, enabled, time.Now()),
+	})
+	if err != nil {
+		return Connector{}, fmt.Errorf("create knowledge connector: %w", err)
+	}
+	return toConnector(row, sqlc.KnowledgeConnectorToken{}), nil
+}
+
+// ListConnectors lists the connectors of a collection.
+func (s *Service) ListConnectors(ctx context.Context, collectionID string) ([]Connector, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListKnowledgeConnectors(ctx, collection.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list knowledge connectors: %w", err)
+	}
+	items := make([]Connector, 0, len(rows))
+	for _, row := range rows {
+		token, err := s.connectorToken(ctx, store, row.ID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, toConnector(row, token))
+	}
+	return items, nil
+}
+
+// GetConnector returns one connector of a collection.
+func (s *Service) GetConnector(ctx context.Context, collectionID, connectorID string) (Connector, error) {
+	store, err := s.store()
+	if err != nil {
+		return Connector{}, err
+	}
+	row, err := s.getConnector(ctx, store, collectionID, connectorID)
+	if err != nil {
+		return Connector{}, err
+	}
+	token, err := s.connectorToken(ctx, store, row.ID)
+	if err != nil {
+		return Connector{}, err
+	}
+	return toConnector(row, token), nil
+}
+
+// UpdateConnector changes a connector. Changing its config clears the sync
+// cursor, so the next sync is a full sync that also removes documents the
+// connector no longer covers.
+func (s *Service) UpdateConnector(ctx context.Context, collectionID, connectorID string, req UpdateConnectorRequest) (Connector, error) {
+	store, err := s.store()
+	if err != nil {
+		return Connector{}, err
+	}
+	current, err := s.getConnector(ctx, store, collectionID, connectorID)
+	if err != nil {
+		return Connector{}, err
+	}
+	adapter, ok := s.adapters[current.Type]
+	if !ok {
+		return Connector{}, fmt.Errorf("%w: unknown type %q", ErrInvalidConnector, current.Type)
+	}
+	name := current.Name
+	config := decodeConnectorConfig(current.Config)
+	syncPattern := current.SyncPattern
+	enabled := current.Enabled
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Config != nil {
+		config = *req.Config
+	}
+	if req.SyncPattern != nil {
+		syncPattern = *req.SyncPattern
+	}
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	settings, err := normalizeConnectorSettings(adapter, name, config, syncPattern)
+	if err != nil {
+		return Connector{}, err
+	}
+	encoded := encodeConnectorConfig(settings.config)
+	cursor := current.Cursor
+	if !jsonEqual(encoded, current.Config) {
+		cursor = ""
+	}
+	row, err := store.UpdateKnowledgeConnector(ctx, sqlc.UpdateKnowledgeConnectorParams{
+		Name:        settings.name,
+		Config:      encoded,
+		SyncPattern: settings.syncPattern,
+		Enabled:     enabled,
+		Cursor:      cursor,
+		NextSyncAt:  nextCrawlRun(settings.syncPattern, enabled, time.Now()),
+		ID:          current.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Connector{}, ErrConnectorNotFound
+		}
+		return Connector{}, fmt.Errorf("update knowledge connector: %w", err)
+	}
+	token, err := s.connectorToken(ctx, store, row.ID)
+	if err != nil {
+		return Connector{}, err
+	}
+	return toConnector(row, token), nil
+}
+
+// DeleteConnector deletes a connector with its token and sync history.
+// Documents it synced stay in the collection.
+func (s *Service) DeleteConnector(ctx context.Context, collectionID, connectorID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	current, err := s.getConnector(ctx, store, collectionID, connectorID)
+	if err != nil {
+		return err
+	}
+	n, err := store.DeleteKnowledgeConnector(ctx, current.ID)
+	if err != nil {
+		return fmt.Errorf("delete knowledge connector: %w", err)
+	}
+	if n == 0 {
+		return ErrConnectorNotFound
+	}
+	return nil
+}
+
+// SyncConnector starts a sync now. A full sync lists every item and removes
+// documents whose item is gone; otherwise only changes since the last sync
+// are fetched. A sync still waiting to start is reused.
+func (s *Service) SyncConnector(ctx context.Context, collectionID, connectorID string, full bool) (ConnectorSync, error) {
+	if s.externalDisabled {
+		return ConnectorSync{}, ErrExternalSourcesDisabled
+	}
+	store, err := s.store()
+	if err != nil {
+		return ConnectorSync{}, err
+	}
+	current, err := s.getConnector(ctx, store, collectionID, connectorID)
+	if err != nil {
+		return ConnectorSync{}, err
+	}
+	token, err := s.connectorToken(ctx, store, current.ID)
+	if err != nil {
+		return ConnectorSync{}, err
+	}
+	if token.AccessToken == "" {
+		return ConnectorSync{}, ErrConnectorUnauthorized
+	}
+	return s.enqueueConnectorSync(ctx, store, current.ID, full)
+}
+
+// ListConnectorSyncs lists the most recent syncs of a connector.
+func (s *Service) ListConnectorSyncs(ctx context.Context, collectionID, connectorID string) ([]ConnectorSync, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.getConnector(ctx, store, collectionID, connectorID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListKnowledgeConnectorSyncs(ctx, sqlc.ListKnowledgeConnectorSyncsParams{
+		ConnectorID: current.ID,
+		RowLimit:    connectorSyncHistory,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list knowledge connector syncs: %w", err)
+	}
+	items := make([]ConnectorSync, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toConnectorSync(row))
+	}
+	return items, nil
+}
+
+// AuthorizeConnector starts the OAuth flow of a connector and returns the
+// URL to send the user to. state identifies the flow in the callback;
+// redirectURI is used unless the OAuth client config sets its own.
+func (s *Service) AuthorizeConnector(ctx context.Context, collectionID, connectorID, redirectURI, state string) (string, error) {
+	if s.externalDisabled {
+		return "", ErrExternalSourcesDisabled
+	}
+	store, err := s.store()
+	if err != nil {
+		return "", err
+	}
+	current, err := s.getConnector(ctx, store, collectionID, connectorID)
+	if err != nil {
+		return "", err
+	}
+	adapter, ok := s.adapters[current.Type]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown type %q", ErrInvalidConnector, current.Type)
+	}
+	cfg, err := s.oauthConfig(adapter, redirectURI)
+	if err != nil {
+		return "", err
+	}
+	if err := store.SetKnowledgeConnectorOAuthState(ctx, sqlc.SetKnowledgeConnectorOAuthStateParams{
+		ConnectorID: current.ID,
+		State:       state,
+	}); err != nil {
+		return "", fmt.Errorf("store oauth state: %w", err)
+	}
+	opts := make([]oauth2.AuthCodeOption, 0, len(adapter.OAuth().AuthParams))
+	for key, value := range adapter.OAuth().AuthParams {
+		opts = append(opts, oauth2.SetAuthURLParam(key, value))
+	}
+	return cfg.AuthCodeURL(state, opts...), nil
+}
+
+// CompleteConnectorOAuth finishes the OAuth flow started by
+// AuthorizeConnector: it exchanges code for a token, stores it, and starts a
+// full sync. redirectURI must match the one passed to AuthorizeConnector.
+func (s *Service) CompleteConnectorOAuth(ctx context.Context, state, code, redirectURI string) (Connector, error) {
+	if s.externalDisabled {
+		return Connector{}, ErrExternalSourcesDisabled
+	}
+	store, err := s.store()
+	if err != nil {
+		return Connector{}, err
+	}
+	if strings.TrimSpace(state) == "" {
+		return Connector{}, ErrInvalidOAuthState
+	}
+	pending, err := store.GetKnowledgeConnectorTokenByState(ctx, state)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Connector{}, ErrInvalidOAuthState
+		}
+		return Connector{}, fmt.Errorf("load oauth state: %w", err)
+	}
+	row, err := store.GetKnowledgeConnector(ctx, pending.ConnectorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Connector{}, ErrConnectorNotFound
+		}
+		return Connector{}, fmt.Errorf("get knowledge connector: %w", err)
+	}
+	adapter, ok := s.adapters[row.Type]
+	if !ok {
+		return Connector{}, fmt.Errorf("%w: unknown type %q", ErrInvalidConnector, row.Type)
+	}
+	cfg, err := s.oauthConfig(adapter, redirectURI)
+	if err != nil {
+		return Connector{}, err
+	}
+	httpCtx := context.WithValue(ctx, oauth2.HTTPClient, s.httpClient)
+	tok, err := cfg.Exchange(httpCtx, code)
+	if err != nil {
+		return Connector{}, fmt.Errorf("exchange oauth code: %w", err)
+	}
+	account, err := adapter.Account(ctx, cfg.Client(httpCtx, tok), tok)
+	if err != nil {
+		// The token is usable without an account label; keep going.
+		s.logger.Warn("resolve connector account failed", slog.String("connector_id", row.ID.String()), slog.Any("error", err))
+	}
+	token, err := store.UpsertKnowledgeConnectorToken(ctx, tokenParams(row.ID, account, tok))
+	if err != nil {
+		return Connector{}, fmt.Errorf("store oauth token: %w", err)
+	}
+	if _, err := s.enqueueConnectorSync(ctx, store, row.ID, true); err != nil {
+		s.logger.Warn("start connector sync failed", slog.String("connector_id", row.ID.String()), slog.Any("error", err))
+	}
+	return toConnector(row, token), nil
+}
+
+// DisconnectConnector deletes the stored OAuth token of a connector.
+// Scheduled syncs fail until it is authorized again.
+func (s *Service) DisconnectConnector(ctx context.Context, collectionID, connectorID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	current, err := s.getConnector(ctx, store, collectionID, connectorID)
+	if err != nil {
+		return err
+	}
+	if _, err := store.DeleteKnowledgeConnectorToken(ctx, current.ID); err != nil {
+		return fmt.Errorf("delete connector token: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) getConnector(ctx context.Context, store knowledgeQueries, collectionID, connectorID string) (sqlc.KnowledgeConnector, error) {
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return sqlc.KnowledgeConnector{}, err
+	}
+	pgConnectorID, err := db.ParseUUID(connectorID)
+	if err != nil {
+		return sqlc.KnowledgeConnector{}, ErrConnectorNotFound
+	}
+	row, err := store.GetKnowledgeConnector(ctx, pgConnectorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.KnowledgeConnector{}, ErrConnectorNotFound
+		}
+		return sqlc.KnowledgeConnector{}, fmt.Errorf("get knowledge connector: %w", err)
+	}
+	if row.CollectionID != collection.ID {
+		return sqlc.KnowledgeConnector{}, ErrConnectorNotFound
+	}
+	return row, nil
+}
+
+// connectorToken returns the stored token of a connector, or a zero token
+// when it was never authorized.
+func (*Service) connectorToken(ctx context.Context, store knowledgeQueries, connectorID pgtype.UUID) (sqlc.KnowledgeConnectorToken, error) {
+	token, err := store.GetKnowledgeConnectorToken(ctx, connectorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.KnowledgeConnectorToken{}, nil
+		}
+		return sqlc.KnowledgeConnectorToken{}, fmt.Errorf("get connector token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *Service) enqueueConnectorSync(ctx context.Context, store knowledgeQueries, connectorID pgtype.UUID, full bool) (ConnectorSync, error) {
+	row, err := store.EnqueueKnowledgeConnectorSync(ctx, sqlc.EnqueueKnowledgeConnectorSyncParams{
+		ConnectorID: connectorID,
+		FullSync:    full,
+	})
+	if err != nil {
+		return ConnectorSync{}, fmt.Errorf("enqueue knowledge connector sync: %w", err)
+	}
+	sync := toConnectorSync(row)
+	select {
+	case s.syncs <- sync.ID:
+	default:
+		// The sync stays pending in the database; the next sweep starts it.
+		s.logger.Warn("knowledge connector sync queue full, deferring sync", slog.String("sync_id", sync.ID))
+	}
+	return sync, nil
+}
+
+func (s *Service) oauthClient(adapter ConnectorAdapter) (oauthclients.Client, bool) {
+	if s.oauthClients == nil {
+		return oauthclients.Client{}, false
+	}
+	return s.oauthClients.Get(adapter.Meta().OAuthClient)
+}
+
+func (s *Service) hasOAuthClient(adapter ConnectorAdapter) bool {
+	client, ok := s.oauthClient(adapter)
+	return ok && strings.TrimSpace(client.ClientID) != "" && strings.TrimSpace(client.ClientSecret) != ""
+}
+
+// oauthConfig builds the OAuth config of a connector. The OAuth client
+// config supplies the credentials and may override the connector's
+// endpoints, scopes, and redirect URI.
+func (s *Service) oauthConfig(adapter ConnectorAdapter, redirectURI string) (*oauth2.Config, error) {
+	client, ok := s.oauthClient(adapter)
+	if !ok || strings.TrimSpace(client.ClientID) == "" || strings.TrimSpace(client.ClientSecret) == "" {
+		return nil, ErrConnectorUnavailable
+	}
+	spec := adapter.OAuth()
+	cfg := &oauth2.Config{
+		ClientID:     strings.TrimSpace(client.ClientID),
+		ClientSecret: strings.TrimSpace(client.ClientSecret),
+		Endpoint:     oauth2.Endpoint{AuthURL: spec.AuthURL, TokenURL: spec.TokenURL},
+		Scopes:       spec.Scopes,
+		RedirectURL:  redirectURI,
+	}
+	if v := strings.TrimSpace(client.AuthorizationEndpoint); v != "" {
+		cfg.Endpoint.AuthURL = v
+	}
+	if v := strings.TrimSpace(client.TokenEndpoint); v != "" {
+		cfg.Endpoint.TokenURL = v
+	}
+	if len(client.AllowedScopes) > 0 {
+		cfg.Scopes = client.AllowedScopes
+	}
+	if v := strings.TrimSpace(client.RedirectURI); v != "" {
+		cfg.RedirectURL = v
+	}
+	return cfg, nil
+}
+
+// connectorClient returns an HTTP client authorized with the connector's
+// token. Tokens refreshed while it is used are saved back.
+func (s *Service) connectorClient(ctx context.Context, store knowledgeQueries, adapter ConnectorAdapter, stored sqlc.KnowledgeConnectorToken) (*http.Client, error) {
+	cfg, err := s.oauthConfig(adapter, "")
+	if err != nil {
+		return nil, err
+	}
+	tok := &oauth2.Token{
+		AccessToken:  stored.AccessToken,
+		RefreshToken: stored.RefreshToken,
+		TokenType:    stored.TokenType,
+	}
+	if stored.ExpiresAt.Valid {
+		tok.Expiry = stored.ExpiresAt.Time
+	}
+	httpCtx := context.WithValue(ctx, oauth2.HTTPClient, s.httpClient)
+	source := &savingTokenSource{
+		ctx:     context.WithoutCancel(ctx),
+		store:   store,
+		logger:  s.logger,
+		token:   stored,
+		base:    cfg.TokenSource(httpCtx, tok),
+		current: tok.AccessToken,
+	}
+	return oauth2.NewClient(httpCtx, source), nil
+}
+
+// savingTokenSource saves tokens its base source refreshed, so a refresh
+// token rotated by the provider is not lost.
+type savingTokenSource struct {
+	ctx     context.Context
+	store   knowledgeQueries
+	logger  *slog.Logger
+	token   sqlc.KnowledgeConnectorToken
+	base    oauth2.TokenSource
+	current string
+}
+
+func (t *savingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := t.base.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok.AccessToken == t.current {
+		return tok, nil
+	}
+	t.current = tok.AccessToken
+	if _, err := t.store.UpsertKnowledgeConnectorToken(t.ctx, tokenParams(t.token.ConnectorID, t.token.Account, tok)); err != nil {
+		t.logger.Warn("save refreshed connector token failed", slog.String("connector_id", t.token.ConnectorID.String()), slog.Any("error", err))
+	}
+	return tok, nil
+}
+
+func tokenParams(connectorID pgtype.UUID, account string, tok *oauth2.Token) sqlc.UpsertKnowledgeConnectorTokenParams {
+	params := sqlc.UpsertKnowledgeConnectorTokenParams{
+		ConnectorID:  connectorID,
+		Account:      account,
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		TokenType:    tok.TokenType,
+	}
+	if scope, ok := tok.Extra("scope").(string); ok {
+		params.Scope = scope
+	}
+	if !tok.Expiry.IsZero() {
+		params.ExpiresAt = pgtype.Timestamptz{Time: tok.Expiry.UTC(), Valid: true}
+	}
+	return params
+}
+
+// runConnectorSyncs executes connector syncs until ctx is done. Like the
+// crawl loop it sweeps on start and then periodically, which also enqueues
+// syncs for connectors whose schedule is due.
+func (s *Service) runConnectorSyncs(ctx context.Context) {
+	s.sweepConnectorSyncs(ctx)
+	ticker := time.NewTicker(connectorSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.syncs:
+			s.processConnectorSync(ctx, id)
+		case <-ticker.C:
+			s.sweepConnectorSyncs(ctx)
+		}
+	}
+}
+
+func (s *Service) sweepConnectorSyncs(ctx context.Context) {
+	if s.externalDisabled {
+		return
+	}
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("knowledge connector sweep failed", slog.Any("error", err))
+		return
+	}
+	s.scheduleDueConnectorSyncs(ctx, store, time.Now())
+	rows, err := store.ListUnfinishedKnowledgeConnectorSyncs(ctx)
+	if err != nil {
+		s.logger.Warn("list unfinished knowledge connector syncs failed", slog.Any("error", err))
+		return
+	}
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return
+		}
+		s.processConnectorSync(ctx, row.ID.String())
+	}
+}
+
+// scheduleDueConnectorSyncs enqueues an incremental sync for every connector
+// whose schedule is due and moves its next sync forward.
+func (s *Service) scheduleDueConnectorSyncs(ctx context.Context, store knowledgeQueries, now time.Time) {
+	due, err := store.ListDueKnowledgeConnectors(ctx, pgtype.Timestamptz{Time: now.UTC(), Valid: true})
+	if err != nil {
+		s.logger.Warn("list due knowledge connectors failed", slog.Any("error", err))
+		return
+	}
+	for _, row := range due {
+		if err := store.SetKnowledgeConnectorNextSync(ctx, sqlc.SetKnowledgeConnectorNextSyncParams{
+			NextSyncAt: nextCrawlRun(row.SyncPattern, row.Enabled, now),
+			ID:         row.ID,
+		}); err != nil {
+			s.logger.Warn("schedule knowledge connector sync failed", slog.String("connector_id", row.ID.String()), slog.Any("error", err))
+			continue
+		}
+		if _, err := store.EnqueueKnowledgeConnectorSync(ctx, sqlc.EnqueueKnowledgeConnectorSyncParams{ConnectorID: row.ID}); err != nil {
+			s.logger.Warn("enqueue knowledge connector sync failed", slog.String("connector_id", row.ID.String()), slog.Any("error", err))
+		}
+	}
+}
+
+// processConnectorSync runs one sync. A sync that is already finished (it
+// was both queued and picked up by a sweep) is skipped.
+func (s *Service) processConnectorSync(ctx context.Context, syncID string) {
+	if s.externalDisabled {
+		return
+	}
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("knowledge connector sync failed", slog.String("sync_id", syncID), slog.Any("error", err))
+		return
+	}
+	pgSyncID, err := db.ParseUUID(syncID)
+	if err != nil {
+		return
+	}
+	sync, err := store.GetKnowledgeConnectorSync(ctx, pgSyncID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Warn("load knowledge connector sync failed", slog.String("sync_id", syncID), slog.Any("error", err))
+		}
+		return
+	}
+	if sync.Status != JobPending && sync.Status != JobRunning {
+		return
+	}
+	ran, err := s.syncConnector(ctx, store, sync)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down: leave the sync running so the next start retries it.
+			return
+		}
+		s.logger.Error("knowledge connector sync failed", slog.String("sync_id", syncID), slog.Any("error", err))
+		if failErr := store.FailKnowledgeConnectorSync(context.WithoutCancel(ctx), sqlc.FailKnowledgeConnectorSyncParams{
+			Error: err.Error(),
+			ID:    sync.ID,
+		}); failErr != nil {
+			s.logger.Warn("mark knowledge connector sync failed", slog.String("sync_id", syncID), slog.Any("error", failErr))
+		}
+		return
+	}
+	if !ran {
+		return
+	}
+	if err := store.CompleteKnowledgeConnectorSync(ctx, sync.ID); err != nil {
+		s.logger.Error("complete knowledge connector sync failed", slog.String("sync_id", syncID), slog.Any("error", err))
+	}
+}
+
+// connectorStats counts items for the sync record.
+type connectorStats struct {
+	seen, ingested, skipped, removed int
+}
+
+// syncConnector syncs the connector's changed items into its collection.
+// Items whose version is unchanged are not fetched, deleted items are
+// removed, and a full sync also removes documents whose item is no longer
+// listed. The cursor only advances when every item synced, so failed items
+// are retried next time. It reports false when another sync already
+// finished the job.
+func (s *Service) syncConnector(ctx context.Context, store knowledgeQueries, sync sqlc.KnowledgeConnectorSync) (bool, error) {
+	row, err := store.GetKnowledgeConnector(ctx, sync.ConnectorID)
+	if err != nil {
+		return false, fmt.Errorf("load connector: %w", err)
+	}
+	adapter, ok := s.adapters[row.Type]
+	if !ok {
+		return false, fmt.Errorf("%w: unknown type %q", ErrInvalidConnector, row.Type)
+	}
+	collection, err := store.GetKnowledgeCollection(ctx, row.CollectionID)
+	if err != nil {
+		return false, fmt.Errorf("load collection: %w", err)
+	}
+	token, err := s.connectorToken(ctx, store, row.ID)
+	if err != nil {
+		return false, err
+	}
+	if token.AccessToken == "" {
+		return false, ErrConnectorUnauthorized
+	}
+	client, err := s.connectorClient(ctx, store, adapter, token)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := store.MarkKnowledgeConnectorSyncRunning(ctx, sync.ID)
+	if err != nil {
+		return false, fmt.Errorf("mark sync running: %w", err)
+	}
+	if claimed == 0 {
+		return false, nil
+	}
+
+	full := sync.FullSync || row.Cursor == ""
+	cursor := row.Cursor
+	if full {
+		cursor = ""
+	}
+	req := ConnectorRequest{Client: client, Config: decodeConnectorConfig(row.Config)}
+	changes, err := adapter.Changes(ctx, req, cursor)
+	if err != nil {
+		return false, fmt.Errorf("list changes: %w", err)
+	}
+
+	var stats connectorStats
+	listed := map[string]bool{}
+	failed := 0
+	for _, item := range changes.Items {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		stats.seen++
+		if item.Deleted {
+			removed, err := s.removeConnectorItem(ctx, store, collection, row.ID, item.ExternalID)
+			if err != nil {
+				return false, fmt.Errorf("remove %s: %w", item.ExternalID, err)
+			}
+			if removed {
+				stats.removed++
+			}
+			s.recordConnectorProgress(ctx, store, sync.ID, stats)
+			continue
+		}
+		// Keep documents of items that failed this time; they may be back
+		// on the next sync.
+		listed[item.ExternalID] = true
+		changed, err := s.ingestConnectorItem(ctx, store, collection, adapter, req, row.ID, item)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			failed++
+			stats.skipped++
+			s.logger.Info("knowledge connector item skipped",
+				slog.String("sync_id", sync.ID.String()), slog.String("item", item.ExternalID), slog.Any("error", err))
+		case changed:
+			stats.ingested++
+		default:
+			stats.skipped++
+		}
+		s.recordConnectorProgress(ctx, store, sync.ID, stats)
+	}
+
+	if full {
+		documents, err := store.ListKnowledgeConnectorDocuments(ctx, row.ID)
+		if err != nil {
+			return false, fmt.Errorf("list connector documents: %w", err)
+		}
+		for _, doc := range documents {
+			if listed[doc.ExternalID] {
+				continue
+			}
+			removed, err := s.removeDocument(ctx, store, collection, doc.CollectionID, doc.ID)
+			if err != nil {
+				return false, fmt.Errorf("remove document %s: %w", doc.ID.String(), err)
+			}
+			if removed {
+				stats.removed++
+			}
+		}
+	}
+	s.recordConnectorProgress(ctx, store, sync.ID, stats)
+
+	if failed > 0 {
+		return false, fmt.Errorf("%d of %d items could not be synced", failed, stats.seen)
+	}
+	if err := store.SetKnowledgeConnectorCursor(ctx, sqlc.SetKnowledgeConnectorCursorParams{
+		Cursor: changes.Cursor,
+		ID:     row.ID,
+	}); err != nil {
+		return false, fmt.Errorf("save cursor: %w", err)
+	}
+	return true, nil
+}
+
+func (s *Service) recordConnectorProgress(ctx context.Context, store knowledgeQueries, syncID pgtype.UUID, stats connectorStats) {
+	if err := store.SetKnowledgeConnectorSyncProgress(context.WithoutCancel(ctx), sqlc.SetKnowledgeConnectorSyncProgressParams{
+		ItemsSeen:         int32(stats.seen),     //nolint:gosec // bounded by the items one sync lists.
+		DocumentsIngested: int32(stats.ingested), //nolint:gosec // bounded by the items one sync lists.
+		DocumentsSkipped:  int32(stats.skipped),  //nolint:gosec // bounded by the items one sync lists.
+		DocumentsRemoved:  int32(stats.removed),  //nolint:gosec // bounded by the connector's documents.
+		ID:                syncID,
+	}); err != nil {
+		s.logger.Warn("record knowledge connector progress failed", slog.String("sync_id", syncID.String()), slog.Any("error", err))
+	}
+}
+
+// ingestConnectorItem stores an item as the collection's document for it.
+// It reports false when the item's version or content is unchanged. An item
+// that is now empty has its document removed.
+func (s *Service) ingestConnectorItem(ctx context.Context, store knowledgeQueries, collection sqlc.KnowledgeCollection, adapter ConnectorAdapter, req ConnectorRequest, connectorID pgtype.UUID, item ConnectorItem) (bool, error) {
+	existing, err := store.GetKnowledgeDocumentByExternalID(ctx, sqlc.GetKnowledgeDocumentByExternalIDParams{
+		ConnectorID: connectorID,
+		ExternalID:  item.ExternalID,
+	})
+	found := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("find document: %w", err)
+	}
+	if found && item.Version != "" && existing.ExternalVersion == item.Version {
+		return false, nil
+	}
+
+	fetched, err := adapter.Fetch(ctx, req, item)
+	if err != nil {
+		return false, fmt.Errorf("fetch: %w", err)
+	}
+	content := strings.TrimSpace(fetched.Content)
+	if content == "" {
+		if found {
+			if _, err := s.removeDocument(ctx, store, collection, existing.CollectionID, existing.ID); err != nil {
+				return false, fmt.Errorf("remove empty document: %w", err)
+			}
+		}
+		return false, nil
+	}
+	title := strings.TrimSpace(fetched.Title)
+	if title == "" {
+		title = strings.TrimSpace(item.Title)
+	}
+	if title == "" {
+		title = item.ExternalID
+	}
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+
+	if found {
+		row, err := store.UpdateKnowledgeConnectorDocument(ctx, sqlc.UpdateKnowledgeConnectorDocumentParams{
+			Title:           title,
+			Content:         content,
+			ContentHash:     hash,
+			SourceUrl:       item.URL,
+			ExternalVersion: item.Version,
+			ID:              existing.ID,
+		})
+		if err != nil {
+			return false, fmt.Errorf("update document: %w", err)
+		}
+		if existing.ContentHash == hash {
+			return false, nil
+		}
+		if _, err := s.processDocument(ctx, store, collection, row, true); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	row, err := store.CreateKnowledgeDocument(ctx, sqlc.CreateKnowledgeDocumentParams{
+		CollectionID:    collection.ID,
+		Title:           title,
+		Content:         content,
+		ContentHash:     hash,
+		Metadata:        encodeMetadata(nil),
+		SourceUrl:       item.URL,
+		ConnectorID:     connectorID,
+		ExternalID:      item.ExternalID,
+		ExternalVersion: item.Version,
+	})
+	if err != nil {
+		return false, fmt.Errorf("create document: %w", err)
+	}
+	if _, err := s.processDocument(ctx, store, collection, row, false); err != nil {
+		if _, delErr := store.DeleteKnowledgeDocument(ctx, sqlc.DeleteKnowledgeDocumentParams{CollectionID: collection.ID, ID: row.ID}); delErr != nil {
+			s.logger.Warn("remove unindexed knowledge document failed",
+				slog.String("document_id", row.ID.String()), slog.Any("error", delErr))
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// removeConnectorItem removes the document of a deleted item, if any.
+func (s *Service) removeConnectorItem(ctx context.Context, store knowledgeQueries, collection sqlc.KnowledgeCollection, connectorID pgtype.UUID, externalID string) (bool, error) {
+	existing, err := store.GetKnowledgeDocumentByExternalID(ctx, sqlc.GetKnowledgeDocumentByExternalIDParams{
+		ConnectorID: connectorID,
+		ExternalID:  externalID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("find document: %w", err)
+	}
+	return s.removeDocument(ctx, store, collection, existing.CollectionID, existing.ID)
+}
+
+// removeDocument deletes a document and its vectors.
+func (s *Service) removeDocument(ctx context.Context, store knowledgeQueries, collection sqlc.KnowledgeCollection, collectionID, documentID pgtype.UUID) (bool, error) {
+	n, err := store.DeleteKnowledgeDocument(ctx, sqlc.DeleteKnowledgeDocumentParams{CollectionID: collectionID, ID: documentID})
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+	if s.index != nil {
+		if err := s.index.DeleteDocument(ctx, collection.TeamID, collectionID, documentID); err != nil {
+			s.logger.Warn("delete knowledge document vectors failed",
+				slog.String("document_id", documentID.String()), slog.Any("error", err))
+		}
+	}
+	return true, nil
+}
+
+// connectorSettings is the user-editable part of a connector.
+type connectorSettings struct {
+	name        string
+	config      map[string]any
+	syncPattern string
+}
+
+func normalizeConnectorSettings(adapter ConnectorAdapter, name string, config map[string]any, syncPattern string) (connectorSettings, error) {
+	out := connectorSettings{
+		name:        strings.TrimSpace(name),
+		syncPattern: strings.TrimSpace(syncPattern),
+	}
+	if out.name == "" {
+		return connectorSettings{}, ErrNameRequired
+	}
+	if len([]rune(out.name)) > maxNameLength {
+		return connectorSettings{}, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidConnector, maxNameLength)
+	}
+	if config == nil {
+		config = map[string]any{}
+	}
+	normalized, err := adapter.NormalizeConfig(maps.Clone(config))
+	if err != nil {
+		return connectorSettings{}, fmt.Errorf("%w: %s", ErrInvalidConnector, err.Error())
+	}
+	out.config = normalized
+	if out.syncPattern != "" {
+		if _, err := crawlScheduleParser.Parse(out.syncPattern); err != nil {
+			return connectorSettings{}, fmt.Errorf("%w: sync_pattern: %s", ErrInvalidConnector, err.Error())
+		}
+	}
+	return out, nil
+}
+
+func encodeConnectorConfig(config map[string]any) []byte {
+	if len(config) == 0 {
+		return []byte("{}")
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return []byte("{}")
+	}
+	return data
+}
+
+func decodeConnectorConfig(data []byte) map[string]any {
+	config := map[string]any{}
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &config)
+	}
+	return config
+}
+
+// jsonEqual compares two JSON documents by value, so key order and
+// whitespace from the database do not count as a change.
+func jsonEqual(a, b []byte) bool {
+	var av, bv any
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	ad, _ := json.Marshal(av)
+	bd, _ := json.Marshal(bv)
+	return string(ad) == string(bd)
+}
+
+func toConnector(row sqlc.KnowledgeConnector, token sqlc.KnowledgeConnectorToken) Connector {
+	connector := Connector{
+		ID:           row.ID.String(),
+		CollectionID: row.CollectionID.String(),
+		Type:         row.Type,
+		Name:         row.Name,
+		Config:       decodeConnectorConfig(row.Config),
+		SyncPattern:  row.SyncPattern,
+		Enabled:      row.Enabled,
+		Authorized:   token.AccessToken != "",
+		Account:      token.Account,
+		CreatedAt:    db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:    db.TimeFromPg(row.UpdatedAt),
+	}
+	if row.NextSyncAt.Valid {
+		next := db.TimeFromPg(row.NextSyncAt)
+		connector.NextSyncAt = &next
+	}
+	return connector
+}
+
+func toConnectorSync(row sqlc.KnowledgeConnectorSync) ConnectorSync {
+	sync := ConnectorSync{
+		ID:                row.ID.String(),
+		ConnectorID:       row.ConnectorID.String(),
+		Status:            row.Status,
+		Error:             row.Error,
+		FullSync:          row.FullSync,
+		ItemsSeen:         int(row.ItemsSeen),
+		DocumentsIngested: int(row.DocumentsIngested),
+		DocumentsSkipped:  int(row.DocumentsSkipped),
+		DocumentsRemoved:  int(row.DocumentsRemoved),
+		CreatedAt:         db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:         db.TimeFromPg(row.UpdatedAt),
+	}
+	if row.CompletedAt.Valid {
+		completed := db.TimeFromPg(row.CompletedAt)
+		sync.CompletedAt = &completed
+	}
+	return sync
+}
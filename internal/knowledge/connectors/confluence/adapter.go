@@ -0,0 +1,349 @@
+// Package confluence syncs Confluence Cloud pages into knowledge
+// collections.
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"golang.org/x/oauth2"
+
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+const (
+	Type           = "confluence"
+	oauthClientRef = "knowledge_confluence"
+	defaultAPIURL  = "https://api.atlassian.com"
+	pageSize       = 50
+	maxSpaces      = 50
+	maxErrorBytes  = 512
+	// cqlDateLayout is the date format CQL accepts. Dates are compared in the
+	// user's time zone, so incremental searches start a day before the
+	// cursor and rely on versions to skip pages already synced.
+	cqlDateLayout = "2006-01-02"
+)
+
+// Adapter implements knowledge.ConnectorAdapter for Confluence Cloud.
+type Adapter struct {
+	apiURL string
+
+	mu       sync.Mutex
+	cloudIDs map[string]string
+}
+
+func New() *Adapter {
+	return &Adapter{apiURL: defaultAPIURL, cloudIDs: map[string]string{}}
+}
+
+func (*Adapter) Type() string { return Type }
+
+func (*Adapter) Meta() knowledge.ConnectorMeta {
+	return knowledge.ConnectorMeta{
+		Type:        Type,
+		DisplayName: "Confluence",
+		OAuthClient: oauthClientRef,
+		ConfigFields: []knowledge.ConnectorField{
+			{
+				Key:         "site",
+				Type:        "string",
+				Title:       "Site",
+				Description: "Confluence Cloud site URL",
+				Required:    true,
+				Example:     "https://example.atlassian.net",
+			},
+			{
+				Key:         "spaces",
+				Type:        "array",
+				Title:       "Spaces",
+				Description: "Space keys to sync; empty syncs every space the account can read",
+				Example:     []string{"ENG", "HR"},
+			},
+		},
+	}
+}
+
+func (*Adapter) OAuth() knowledge.ConnectorOAuth {
+	return knowledge.ConnectorOAuth{
+		AuthURL:  "https://auth.atlassian.com/authorize",
+		TokenURL: "https://auth.atlassian.com/oauth/token",
+		Scopes: []string{
+			"read:confluence-content.all",
+			"read:confluence-space.summary",
+			"search:confluence",
+			"offline_access",
+		},
+		AuthParams: map[string]string{"audience": "api.atlassian.com", "prompt": "consent"},
+	}
+}
+
+func (*Adapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	site, _ := raw["site"].(string)
+	site = strings.TrimRight(strings.TrimSpace(site), "/")
+	if site == "" {
+		return nil, errors.New("site is required")
+	}
+	u, err := url.Parse(site)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("site %q is not a valid URL", site)
+	}
+	spaces := []string{}
+	switch v := raw["spaces"].(type) {
+	case nil:
+	case []any:
+		for _, item := range v {
+			key, ok := item.(string)
+			if !ok {
+				return nil, errors.New("spaces must be a list of space keys")
+			}
+			if key = strings.TrimSpace(key); key != "" {
+				spaces = append(spaces, key)
+			}
+		}
+	case []string:
+		for _, key := range v {
+			if key = strings.TrimSpace(key); key != "" {
+				spaces = append(spaces, key)
+			}
+		}
+	default:
+		return nil, errors.New("spaces must be a list of space keys")
+	}
+	if len(spaces) > maxSpaces {
+		return nil, fmt.Errorf("at most %d spaces are allowed", maxSpaces)
+	}
+	return map[string]any{"site": u.Scheme + "://" + u.Host, "spaces": spaces}, nil
+}
+
+type resource struct {
+	ID   string `json:"id"`
+	URL  string `json:"url"`
+	Name string `json:"name"`
+}
+
+// Account names the sites the token can access.
+func (a *Adapter) Account(ctx context.Context, client *http.Client, _ *oauth2.Token) (string, error) {
+	resources, err := a.resources(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(resources))
+	for _, r := range resources {
+		names = append(names, r.Name)
+	}
+	return strings.Join(names, ", "), nil
+}
+
+func (a *Adapter) resources(ctx context.Context, client *http.Client) ([]resource, error) {
+	var out []resource
+	if err := a.get(ctx, client, a.apiURL+"/oauth/token/accessible-resources", &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// cloudID resolves the cloud ID of the configured site, which API URLs are
+// built from.
+func (a *Adapter) cloudID(ctx context.Context, client *http.Client, site string) (string, error) {
+	a.mu.Lock()
+	id, ok := a.cloudIDs[site]
+	a.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+	resources, err := a.resources(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range resources {
+		if strings.EqualFold(strings.TrimRight(r.URL, "/"), site) {
+			a.mu.Lock()
+			a.cloudIDs[site] = r.ID
+			a.mu.Unlock()
+			return r.ID, nil
+		}
+	}
+	return "", fmt.Errorf("site %s is not accessible with this authorization", site)
+}
+
+func (a *Adapter) baseURL(ctx context.Context, req knowledge.ConnectorRequest) (string, error) {
+	site, _ := req.Config["site"].(string)
+	id, err := a.cloudID(ctx, req.Client, site)
+	if err != nil {
+		return "", err
+	}
+	return a.apiURL + "/ex/confluence/" + url.PathEscape(id) + "/wiki/rest/api", nil
+}
+
+type content struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Version struct {
+		Number int    `json:"number"`
+		When   string `json:"when"`
+	} `json:"version"`
+	Body struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Links struct {
+		WebUI string `json:"webui"`
+	} `json:"_links"`
+}
+
+type searchResponse struct {
+	Results []content `json:"results"`
+	Size    int       `json:"size"`
+	Links   struct {
+		Base string `json:"base"`
+		Next string `json:"next"`
+	} `json:"_links"`
+}
+
+// Changes searches pages with CQL, most recently modified first. The cursor
+// is the modification time of the newest page of the previous sync.
+// Deleted pages are not reported; full syncs remove them.
+func (a *Adapter) Changes(ctx context.Context, req knowledge.ConnectorRequest, cursor string) (knowledge.ConnectorChanges, error) {
+	var since time.Time
+	if cursor != "" {
+		parsed, err := time.Parse(time.RFC3339, cursor)
+		if err != nil {
+			return knowledge.ConnectorChanges{}, fmt.Errorf("invalid cursor %q: %w", cursor, err)
+		}
+		since = parsed
+	}
+	base, err := a.baseURL(ctx, req)
+	if err != nil {
+		return knowledge.ConnectorChanges{}, err
+	}
+	cql := buildCQL(spacesFromConfig(req.Config), since)
+	changes := knowledge.ConnectorChanges{Cursor: cursor}
+	var newest time.Time
+	for start := 0; ; start += pageSize {
+		query := url.Values{
+			"cql":    {cql},
+			"expand": {"version"},
+			"limit":  {strconv.Itoa(pageSize)},
+			"start":  {strconv.Itoa(start)},
+		}
+		var resp searchResponse
+		if err := a.get(ctx, req.Client, base+"/content/search?"+query.Encode(), &resp); err != nil {
+			return knowledge.ConnectorChanges{}, err
+		}
+		for _, c := range resp.Results {
+			when, err := time.Parse(time.RFC3339, c.Version.When)
+			if err != nil {
+				return knowledge.ConnectorChanges{}, fmt.Errorf("page %s: invalid version time %q", c.ID, c.Version.When)
+			}
+			if !since.IsZero() && when.Before(since) {
+				continue
+			}
+			if when.After(newest) {
+				newest = when
+			}
+			changes.Items = append(changes.Items, knowledge.ConnectorItem{
+				ExternalID: c.ID,
+				Title:      c.Title,
+				URL:        pageURL(resp.Links.Base, c.Links.WebUI),
+				Version:    strconv.Itoa(c.Version.Number),
+			})
+		}
+		if resp.Links.Next == "" || len(resp.Results) == 0 {
+			break
+		}
+	}
+	if !newest.IsZero() {
+		changes.Cursor = newest.UTC().Format(time.RFC3339)
+	}
+	return changes, nil
+}
+
+func buildCQL(spaces []string, since time.Time) string {
+	clauses := []string{"type = page"}
+	if len(spaces) > 0 {
+		quoted := make([]string, 0, len(spaces))
+		for _, key := range spaces {
+			quoted = append(quoted, strconv.Quote(key))
+		}
+		clauses = append(clauses, "space in ("+strings.Join(quoted, ", ")+")")
+	}
+	if !since.IsZero() {
+		clauses = append(clauses, fmt.Sprintf("lastmodified >= %q", since.AddDate(0, 0, -1).UTC().Format(cqlDateLayout)))
+	}
+	return strings.Join(clauses, " AND ") + " ORDER BY lastmodified DESC"
+}
+
+func spacesFromConfig(config map[string]any) []string {
+	var spaces []string
+	switch v := config["spaces"].(type) {
+	case []any:
+		for _, item := range v {
+			if key, ok := item.(string); ok && key != "" {
+				spaces = append(spaces, key)
+			}
+		}
+	case []string:
+		spaces = v
+	}
+	return spaces
+}
+
+func pageURL(base, webui string) string {
+	if base == "" || webui == "" {
+		return ""
+	}
+	return strings.TrimRight(base, "/") + webui
+}
+
+// Fetch converts the page's storage format to Markdown.
+func (a *Adapter) Fetch(ctx context.Context, req knowledge.ConnectorRequest, item knowledge.ConnectorItem) (knowledge.ConnectorContent, error) {
+	base, err := a.baseURL(ctx, req)
+	if err != nil {
+		return knowledge.ConnectorContent{}, err
+	}
+	var c content
+	if err := a.get(ctx, req.Client, base+"/content/"+url.PathEscape(item.ExternalID)+"?expand=body.storage,version", &c); err != nil {
+		return knowledge.ConnectorContent{}, err
+	}
+	markdown, err := htmltomarkdown.ConvertString(c.Body.Storage.Value)
+	if err != nil {
+		return knowledge.ConnectorContent{}, fmt.Errorf("convert page %s: %w", item.ExternalID, err)
+	}
+	return knowledge.ConnectorContent{Title: c.Title, Content: markdown}, nil
+}
+
+func (*Adapter) get(ctx context.Context, client *http.Client, rawURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+		path := rawURL
+		if u, err := url.Parse(rawURL); err == nil {
+			path = u.Path
+		}
+		return fmt.Errorf("confluence GET %s: http status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode confluence response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package confluence
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+func TestNormalizeConfigTrimsSiteAndSpaces(t *testing.T) {
+	config, err := New().NormalizeConfig(map[string]any{
+		"site":   " https://example.atlassian.net/wiki/ ",
+		"spaces": []any{"ENG", " ", "HR "},
+	})
+	if err != nil {
+		t.Fatalf("NormalizeConfig: %v", err)
+	}
+	if config["site"] != "https://example.atlassian.net" {
+		t.Fatalf("site = %v", config["site"])
+	}
+	if spaces := config["spaces"].([]string); len(spaces) != 2 || spaces[1] != "HR" {
+		t.Fatalf("spaces = %v", spaces)
+	}
+	if _, err := New().NormalizeConfig(map[string]any{}); err == nil {
+		t.Fatal("missing site should be rejected")
+	}
+}
+
+func TestChangesSearchesSpacesAndSkipsOlderPages(t *testing.T) {
+	var cql string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token/accessible-resources", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"cloud-1","url":"https://example.atlassian.net","name":"Example"}]`))
+	})
+	mux.HandleFunc("/ex/confluence/cloud-1/wiki/rest/api/content/search", func(w http.ResponseWriter, r *http.Request) {
+		cql = r.URL.Query().Get("cql")
+		_, _ = w.Write([]byte(`{"results":[
+			{"id":"10","title":"Runbook","version":{"number":4,"when":"2026-03-03T10:00:00.000Z"},"_links":{"webui":"/spaces/ENG/pages/10"}},
+			{"id":"9","title":"Old","version":{"number":1,"when":"2026-03-01T09:00:00.000Z"},"_links":{"webui":"/spaces/ENG/pages/9"}}
+		],"size":2,"_links":{"base":"https://example.atlassian.net/wiki"}}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	adapter := &Adapter{apiURL: server.URL, cloudIDs: map[string]string{}}
+	req := knowledge.ConnectorRequest{
+		Client: server.Client(),
+		Config: map[string]any{"site": "https://example.atlassian.net", "spaces": []any{"ENG"}},
+	}
+	changes, err := adapter.Changes(context.Background(), req, "2026-03-02T00:00:00Z")
+	if err != nil {
+		t.Fatalf("Changes: %v", err)
+	}
+	if !strings.Contains(cql, `space in ("ENG")`) || !strings.Contains(cql, `lastmodified >= "2026-03-01"`) {
+		t.Fatalf("cql = %q", cql)
+	}
+	if len(changes.Items) != 1 {
+		t.Fatalf("items = %+v, want only the page edited after the cursor", changes.Items)
+	}
+	item := changes.Items[0]
+	if item.Version != "4" || item.URL != "https://example.atlassian.net/wiki/spaces/ENG/pages/10" {
+		t.Fatalf("item = %+v", item)
+	}
+	if changes.Cursor != "2026-03-03T10:00:00Z" {
+		t.Fatalf("cursor = %q", changes.Cursor)
+	}
+}
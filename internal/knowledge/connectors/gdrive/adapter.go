@@ -0,0 +1,279 @@
+// Package gdrive syncs Google Docs and text files from Google Drive into
+// knowledge collections.
+package gdrive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+const (
+	Type           = "google_drive"
+	oauthClientRef = "knowledge_google_drive"
+	defaultBaseURL = "https://www.googleapis.com/drive/v3"
+	driveScope     = "https://www.googleapis.com/auth/drive.readonly"
+	folderMimeType = "application/vnd.google-apps.folder"
+	docMimeType    = "application/vnd.google-apps.document"
+	pageSize       = 100
+	maxFileBytes   = 5 << 20
+	maxErrorBytes  = 512
+	fileFields     = "id,name,mimeType,webViewLink,version,trashed,parents"
+)
+
+// supportedMimeTypes are the files synced: Google Docs are exported as
+// plain text, the others are downloaded as is.
+var supportedMimeTypes = []string{docMimeType, "text/plain", "text/markdown"}
+
+// Adapter implements knowledge.ConnectorAdapter for Google Drive.
+type Adapter struct {
+	baseURL string
+}
+
+func New() *Adapter {
+	return &Adapter{baseURL: defaultBaseURL}
+}
+
+func (*Adapter) Type() string { return Type }
+
+func (*Adapter) Meta() knowledge.ConnectorMeta {
+	return knowledge.ConnectorMeta{
+		Type:        Type,
+		DisplayName: "Google Drive",
+		OAuthClient: oauthClientRef,
+		ConfigFields: []knowledge.ConnectorField{
+			{
+				Key:         "folder_id",
+				Type:        "string",
+				Title:       "Folder ID",
+				Description: "Only sync files directly inside this folder; empty syncs every readable file",
+			},
+		},
+	}
+}
+
+func (*Adapter) OAuth() knowledge.ConnectorOAuth {
+	return knowledge.ConnectorOAuth{
+		AuthURL:    google.Endpoint.AuthURL,
+		TokenURL:   google.Endpoint.TokenURL,
+		Scopes:     []string{driveScope},
+		AuthParams: map[string]string{"access_type": "offline", "prompt": "consent"},
+	}
+}
+
+func (*Adapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	folderID, _ := raw["folder_id"].(string)
+	folderID = strings.TrimSpace(folderID)
+	if strings.ContainsAny(folderID, "'\\/ ") {
+		return nil, fmt.Errorf("folder_id %q is not a Drive folder ID", folderID)
+	}
+	if folderID == "" {
+		return map[string]any{}, nil
+	}
+	return map[string]any{"folder_id": folderID}, nil
+}
+
+// Account returns the email address of the Drive user.
+func (a *Adapter) Account(ctx context.Context, client *http.Client, _ *oauth2.Token) (string, error) {
+	var about struct {
+		User struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"user"`
+	}
+	if err := a.getJSON(ctx, client, "/about?fields=user(emailAddress)", &about); err != nil {
+		return "", err
+	}
+	return about.User.EmailAddress, nil
+}
+
+type file struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	MimeType    string   `json:"mimeType"`
+	WebViewLink string   `json:"webViewLink"`
+	Version     string   `json:"version"`
+	Trashed     bool     `json:"trashed"`
+	Parents     []string `json:"parents"`
+}
+
+// Changes lists every supported file on a full sync and the Drive changes
+// since the cursor otherwise. The cursor is a Drive changes page token;
+// a full sync takes it before listing, so edits made while listing are
+// picked up by the next sync.
+func (a *Adapter) Changes(ctx context.Context, req knowledge.ConnectorRequest, cursor string) (knowledge.ConnectorChanges, error) {
+	folderID, _ := req.Config["folder_id"].(string)
+	if cursor == "" {
+		return a.listFiles(ctx, req.Client, folderID)
+	}
+	changes := knowledge.ConnectorChanges{}
+	token := cursor
+	for {
+		query := url.Values{
+			"pageToken":                 {token},
+			"pageSize":                  {fmt.Sprint(pageSize)},
+			"includeItemsFromAllDrives": {"true"},
+			"supportsAllDrives":         {"true"},
+			"fields":                    {"nextPageToken,newStartPageToken,changes(fileId,removed,file(" + fileFields + "))"},
+		}
+		var resp struct {
+			NextPageToken     string `json:"nextPageToken"`
+			NewStartPageToken string `json:"newStartPageToken"`
+			Changes           []struct {
+				FileID  string `json:"fileId"`
+				Removed bool   `json:"removed"`
+				File    *file  `json:"file"`
+			} `json:"changes"`
+		}
+		if err := a.getJSON(ctx, req.Client, "/changes?"+query.Encode(), &resp); err != nil {
+			return knowledge.ConnectorChanges{}, err
+		}
+		for _, change := range resp.Changes {
+			if change.File != nil && change.File.MimeType == folderMimeType {
+				continue
+			}
+			if change.Removed || change.File == nil || !wanted(*change.File, folderID) {
+				// Files moved out of the folder or converted to another type
+				// are dropped like deleted ones.
+				changes.Items = append(changes.Items, knowledge.ConnectorItem{ExternalID: change.FileID, Deleted: true})
+				continue
+			}
+			changes.Items = append(changes.Items, toItem(*change.File))
+		}
+		if resp.NewStartPageToken != "" {
+			changes.Cursor = resp.NewStartPageToken
+			return changes, nil
+		}
+		if resp.NextPageToken == "" {
+			return knowledge.ConnectorChanges{}, errors.New("drive changes response has no page token")
+		}
+		token = resp.NextPageToken
+	}
+}
+
+func (a *Adapter) listFiles(ctx context.Context, client *http.Client, folderID string) (knowledge.ConnectorChanges, error) {
+	var start struct {
+		StartPageToken string `json:"startPageToken"`
+	}
+	if err := a.getJSON(ctx, client, "/changes/startPageToken?supportsAllDrives=true", &start); err != nil {
+		return knowledge.ConnectorChanges{}, err
+	}
+	types := make([]string, 0, len(supportedMimeTypes))
+	for _, mimeType := range supportedMimeTypes {
+		types = append(types, "mimeType = '"+mimeType+"'")
+	}
+	q := "trashed = false and (" + strings.Join(types, " or ") + ")"
+	if folderID != "" {
+		q += " and '" + folderID + "' in parents"
+	}
+	changes := knowledge.ConnectorChanges{Cursor: start.StartPageToken}
+	pageToken := ""
+	for {
+		query := url.Values{
+			"q":                         {q},
+			"pageSize":                  {fmt.Sprint(pageSize)},
+			"includeItemsFromAllDrives": {"true"},
+			"supportsAllDrives":         {"true"},
+			"fields":                    {"nextPageToken,files(" + fileFields + ")"},
+		}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		var resp struct {
+			NextPageToken string `json:"nextPageToken"`
+			Files         []file `json:"files"`
+		}
+		if err := a.getJSON(ctx, client, "/files?"+query.Encode(), &resp); err != nil {
+			return knowledge.ConnectorChanges{}, err
+		}
+		for _, f := range resp.Files {
+			changes.Items = append(changes.Items, toItem(f))
+		}
+		if resp.NextPageToken == "" {
+			return changes, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func wanted(f file, folderID string) bool {
+	if f.Trashed || !slices.Contains(supportedMimeTypes, f.MimeType) {
+		return false
+	}
+	return folderID == "" || slices.Contains(f.Parents, folderID)
+}
+
+func toItem(f file) knowledge.ConnectorItem {
+	return knowledge.ConnectorItem{
+		ExternalID: f.ID,
+		Title:      f.Name,
+		URL:        f.WebViewLink,
+		Version:    f.Version,
+	}
+}
+
+// Fetch exports Google Docs as plain text and downloads other files.
+func (a *Adapter) Fetch(ctx context.Context, req knowledge.ConnectorRequest, item knowledge.ConnectorItem) (knowledge.ConnectorContent, error) {
+	var f file
+	if err := a.getJSON(ctx, req.Client, "/files/"+url.PathEscape(item.ExternalID)+"?supportsAllDrives=true&fields="+url.QueryEscape(fileFields), &f); err != nil {
+		return knowledge.ConnectorContent{}, err
+	}
+	path := "/files/" + url.PathEscape(item.ExternalID) + "?alt=media&supportsAllDrives=true"
+	if f.MimeType == docMimeType {
+		path = "/files/" + url.PathEscape(item.ExternalID) + "/export?mimeType=text%2Fplain"
+	}
+	body, err := a.get(ctx, req.Client, path)
+	if err != nil {
+		return knowledge.ConnectorContent{}, err
+	}
+	defer func() { _ = body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(body, maxFileBytes+1))
+	if err != nil {
+		return knowledge.ConnectorContent{}, err
+	}
+	if len(data) > maxFileBytes {
+		return knowledge.ConnectorContent{}, fmt.Errorf("file %s is larger than %d bytes", item.ExternalID, maxFileBytes)
+	}
+	// Exports start with a byte order mark.
+	content := strings.TrimPrefix(string(data), "\ufeff")
+	return knowledge.ConnectorContent{Title: f.Name, Content: content}, nil
+}
+
+func (a *Adapter) getJSON(ctx context.Context, client *http.Client, path string, out any) error {
+	body, err := a.get(ctx, client, path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return fmt.Errorf("decode drive response: %w", err)
+	}
+	return nil
+}
+
+func (a *Adapter) get(ctx context.Context, client *http.Client, path string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("drive GET %s: http status %d: %s", strings.SplitN(path, "?", 2)[0], resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,365 @@
+// Package notion syncs Notion pages shared with the integration into
+// knowledge collections.
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+const (
+	Type           = "notion"
+	oauthClientRef = "knowledge_notion"
+	defaultBaseURL = "https://api.notion.com/v1"
+	notionVersion  = "2022-06-28"
+	pageSize       = 100
+	// maxBlockDepth and maxBlocks bound how much of a page is rendered, so a
+	// huge page cannot stall a sync.
+	maxBlockDepth = 5
+	maxBlocks     = 5000
+	maxErrorBytes = 512
+)
+
+// Adapter implements knowledge.ConnectorAdapter for Notion. Notion only
+// exposes the pages the user shared with the integration while
+// authorizing, so there is nothing to configure.
+type Adapter struct {
+	baseURL string
+}
+
+func New() *Adapter {
+	return &Adapter{baseURL: defaultBaseURL}
+}
+
+func (*Adapter) Type() string { return Type }
+
+func (*Adapter) Meta() knowledge.ConnectorMeta {
+	return knowledge.ConnectorMeta{
+		Type:         Type,
+		DisplayName:  "Notion",
+		OAuthClient:  oauthClientRef,
+		ConfigFields: []knowledge.ConnectorField{},
+	}
+}
+
+func (*Adapter) OAuth() knowledge.ConnectorOAuth {
+	return knowledge.ConnectorOAuth{
+		AuthURL:    defaultBaseURL + "/oauth/authorize",
+		TokenURL:   defaultBaseURL + "/oauth/token",
+		AuthParams: map[string]string{"owner": "user"},
+	}
+}
+
+func (*Adapter) NormalizeConfig(map[string]any) (map[string]any, error) {
+	return map[string]any{}, nil
+}
+
+// Account returns the workspace name Notion sends with the token.
+func (*Adapter) Account(_ context.Context, _ *http.Client, token *oauth2.Token) (string, error) {
+	name, _ := token.Extra("workspace_name").(string)
+	return name, nil
+}
+
+type page struct {
+	ID             string                  `json:"id"`
+	Object         string                  `json:"object"`
+	URL            string                  `json:"url"`
+	LastEditedTime string                  `json:"last_edited_time"`
+	Archived       bool                    `json:"archived"`
+	InTrash        bool                    `json:"in_trash"`
+	Properties     map[string]pageProperty `json:"properties"`
+}
+
+type pageProperty struct {
+	Type  string     `json:"type"`
+	Title []richText `json:"title"`
+}
+
+type richText struct {
+	PlainText string `json:"plain_text"`
+}
+
+type listResponse[T any] struct {
+	Results    []T    `json:"results"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// Changes searches pages by last edit, newest first. The cursor is the edit
+// time of the newest page of the previous sync; since Notion rounds edit
+// times to the minute, pages edited in that same minute are listed again
+// and skipped by their unchanged version.
+func (a *Adapter) Changes(ctx context.Context, req knowledge.ConnectorRequest, cursor string) (knowledge.ConnectorChanges, error) {
+	var since time.Time
+	if cursor != "" {
+		parsed, err := time.Parse(time.RFC3339, cursor)
+		if err != nil {
+			return knowledge.ConnectorChanges{}, fmt.Errorf("invalid cursor %q: %w", cursor, err)
+		}
+		since = parsed
+	}
+	changes := knowledge.ConnectorChanges{Cursor: cursor}
+	var newest time.Time
+	start := ""
+	for {
+		body := map[string]any{
+			"filter":    map[string]string{"property": "object", "value": "page"},
+			"sort":      map[string]string{"direction": "descending", "timestamp": "last_edited_time"},
+			"page_size": pageSize,
+		}
+		if start != "" {
+			body["start_cursor"] = start
+		}
+		var resp listResponse[page]
+		if err := a.do(ctx, req.Client, http.MethodPost, "/search", body, &resp); err != nil {
+			return knowledge.ConnectorChanges{}, err
+		}
+		for _, p := range resp.Results {
+			edited, err := time.Parse(time.RFC3339, p.LastEditedTime)
+			if err != nil {
+				return knowledge.ConnectorChanges{}, fmt.Errorf("page %s: invalid last_edited_time %q", p.ID, p.LastEditedTime)
+			}
+			if !since.IsZero() && edited.Before(since) {
+				return finish(changes, newest), nil
+			}
+			if edited.After(newest) {
+				newest = edited
+			}
+			changes.Items = append(changes.Items, knowledge.ConnectorItem{
+				ExternalID: p.ID,
+				Title:      pageTitle(p),
+				URL:        p.URL,
+				Version:    p.LastEditedTime,
+				Deleted:    p.Archived || p.InTrash,
+			})
+		}
+		if !resp.HasMore || resp.NextCursor == "" {
+			return finish(changes, newest), nil
+		}
+		start = resp.NextCursor
+	}
+}
+
+func finish(changes knowledge.ConnectorChanges, newest time.Time) knowledge.ConnectorChanges {
+	if !newest.IsZero() {
+		changes.Cursor = newest.UTC().Format(time.RFC3339)
+	}
+	return changes
+}
+
+func pageTitle(p page) string {
+	for _, prop := range p.Properties {
+		if prop.Type == "title" {
+			return plainText(prop.Title)
+		}
+	}
+	return ""
+}
+
+// Fetch renders the page's blocks as Markdown. Child pages and databases
+// are synced as pages of their own and are skipped here.
+func (a *Adapter) Fetch(ctx context.Context, req knowledge.ConnectorRequest, item knowledge.ConnectorItem) (knowledge.ConnectorContent, error) {
+	r := renderer{ctx: ctx, adapter: a, client: req.Client}
+	if err := r.children(item.ExternalID, "", 0); err != nil {
+		return knowledge.ConnectorContent{}, err
+	}
+	return knowledge.ConnectorContent{Content: strings.TrimSpace(r.out.String())}, nil
+}
+
+type block struct {
+	ID          string
+	Type        string
+	HasChildren bool
+	body        blockBody
+}
+
+type blockBody struct {
+	RichText []richText   `json:"rich_text"`
+	Checked  bool         `json:"checked"`
+	Language string       `json:"language"`
+	URL      string       `json:"url"`
+	Cells    [][]richText `json:"cells"`
+}
+
+func (b *block) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var head struct {
+		ID          string `json:"id"`
+		Type        string `json:"type"`
+		HasChildren bool   `json:"has_children"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return err
+	}
+	b.ID, b.Type, b.HasChildren = head.ID, head.Type, head.HasChildren
+	if body, ok := raw[head.Type]; ok {
+		// Unknown block types may have bodies of another shape; render them
+		// as empty rather than failing the page.
+		_ = json.Unmarshal(body, &b.body)
+	}
+	return nil
+}
+
+type renderer struct {
+	ctx     context.Context
+	adapter *Adapter
+	client  *http.Client
+	out     strings.Builder
+	blocks  int
+	// inList is set after a list item, so the next other block starts a
+	// new paragraph.
+	inList bool
+}
+
+func (r *renderer) children(id, indent string, depth int) error {
+	start := ""
+	for {
+		query := url.Values{"page_size": {fmt.Sprint(pageSize)}}
+		if start != "" {
+			query.Set("start_cursor", start)
+		}
+		var resp listResponse[block]
+		if err := r.adapter.do(r.ctx, r.client, http.MethodGet, "/blocks/"+url.PathEscape(id)+"/children?"+query.Encode(), nil, &resp); err != nil {
+			return err
+		}
+		for _, b := range resp.Results {
+			r.blocks++
+			if r.blocks > maxBlocks {
+				return nil
+			}
+			r.block(b, indent)
+			if b.HasChildren && depth+1 < maxBlockDepth && b.Type != "child_page" && b.Type != "child_database" {
+				childIndent := indent
+				if isListBlock(b.Type) {
+					childIndent += "  "
+				}
+				if err := r.children(b.ID, childIndent, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		if !resp.HasMore || resp.NextCursor == "" {
+			return nil
+		}
+		start = resp.NextCursor
+	}
+}
+
+func (r *renderer) block(b block, indent string) {
+	text := plainText(b.body.RichText)
+	var line string
+	switch b.Type {
+	case "heading_1":
+		line = "# " + text
+	case "heading_2":
+		line = "## " + text
+	case "heading_3":
+		line = "### " + text
+	case "bulleted_list_item", "toggle":
+		line = "- " + text
+	case "numbered_list_item":
+		line = "1. " + text
+	case "to_do":
+		if b.body.Checked {
+			line = "- [x] " + text
+		} else {
+			line = "- [ ] " + text
+		}
+	case "quote", "callout":
+		line = "> " + text
+	case "code":
+		line = "```" + b.body.Language + "\n" + text + "\n```"
+	case "divider":
+		line = "---"
+	case "table_row":
+		cells := make([]string, 0, len(b.body.Cells))
+		for _, cell := range b.body.Cells {
+			cells = append(cells, plainText(cell))
+		}
+		line = "| " + strings.Join(cells, " | ") + " |"
+	case "bookmark", "embed", "link_preview":
+		line = b.body.URL
+	case "child_page", "child_database":
+		return
+	default:
+		line = text
+	}
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	compact := isListBlock(b.Type) || b.Type == "table_row"
+	if r.inList && !compact {
+		r.out.WriteString("\n")
+	}
+	r.inList = compact
+	r.out.WriteString(indent)
+	r.out.WriteString(line)
+	if compact {
+		r.out.WriteString("\n")
+	} else {
+		r.out.WriteString("\n\n")
+	}
+}
+
+func isListBlock(blockType string) bool {
+	switch blockType {
+	case "bulleted_list_item", "numbered_list_item", "to_do", "toggle":
+		return true
+	}
+	return false
+}
+
+func plainText(parts []richText) string {
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(part.PlainText)
+	}
+	return b.String()
+}
+
+func (a *Adapter) do(ctx context.Context, client *http.Client, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Notion-Version", notionVersion)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+		return fmt.Errorf("notion %s %s: http status %d: %s", method, strings.SplitN(path, "?", 2)[0], resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode notion response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package notion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/memohai/memoh/internal/knowledge"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Notion-Version") != notionVersion {
+			t.Errorf("missing Notion-Version header")
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["start_cursor"] == nil {
+			_, _ = w.Write([]byte(`{"results":[
+				{"id":"p3","url":"https://notion.so/p3","last_edited_time":"2026-03-03T10:00:00.000Z","properties":{"Name":{"type":"title","title":[{"plain_text":"Roadmap"}]}}},
+				{"id":"p2","url":"https://notion.so/p2","last_edited_time":"2026-03-02T10:00:00.000Z","in_trash":true,"properties":{}}
+			],"has_more":true,"next_cursor":"c1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[
+			{"id":"p1","url":"https://notion.so/p1","last_edited_time":"2026-03-01T10:00:00.000Z","properties":{}}
+		],"has_more":false}`))
+	})
+	mux.HandleFunc("/blocks/p3/children", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[
+			{"id":"b1","type":"heading_1","heading_1":{"rich_text":[{"plain_text":"Plan"}]}},
+			{"id":"b2","type":"bulleted_list_item","has_children":true,"bulleted_list_item":{"rich_text":[{"plain_text":"Ship "},{"plain_text":"v2"}]}},
+			{"id":"b3","type":"to_do","to_do":{"rich_text":[{"plain_text":"Docs"}],"checked":true}},
+			{"id":"b4","type":"child_page","has_children":true,"child_page":{"title":"Sub"}},
+			{"id":"b5","type":"code","code":{"rich_text":[{"plain_text":"go test"}],"language":"shell"}}
+		],"has_more":false}`))
+	})
+	mux.HandleFunc("/blocks/b2/children", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[
+			{"id":"b6","type":"paragraph","paragraph":{"rich_text":[{"plain_text":"by March"}]}}
+		],"has_more":false}`))
+	})
+	mux.HandleFunc("/blocks/b4/children", func(http.ResponseWriter, *http.Request) {
+		t.Errorf("child pages must not be rendered into their parent")
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestChangesListsPagesAndAdvancesCursor(t *testing.T) {
+	server := newTestServer(t)
+	adapter := &Adapter{baseURL: server.URL}
+	req := knowledge.ConnectorRequest{Client: server.Client()}
+
+	changes, err := adapter.Changes(context.Background(), req, "")
+	if err != nil {
+		t.Fatalf("Changes: %v", err)
+	}
+	if len(changes.Items) != 3 {
+		t.Fatalf("items = %+v, want 3", changes.Items)
+	}
+	if changes.Items[0].Title != "Roadmap" || changes.Items[0].Version != "2026-03-03T10:00:00.000Z" {
+		t.Fatalf("first item = %+v", changes.Items[0])
+	}
+	if !changes.Items[1].Deleted {
+		t.Fatalf("trashed page should be reported deleted")
+	}
+	if changes.Cursor != "2026-03-03T10:00:00Z" {
+		t.Fatalf("cursor = %q", changes.Cursor)
+	}
+
+	changes, err = adapter.Changes(context.Background(), req, "2026-03-02T10:00:00Z")
+	if err != nil {
+		t.Fatalf("Changes incremental: %v", err)
+	}
+	if len(changes.Items) != 2 || changes.Items[1].ExternalID != "p2" {
+		t.Fatalf("incremental items = %+v, want p3 and p2", changes.Items)
+	}
+}
+
+func TestFetchRendersBlocksAsMarkdown(t *testing.T) {
+	server := newTestServer(t)
+	adapter := &Adapter{baseURL: server.URL}
+
+	content, err := adapter.Fetch(context.Background(), knowledge.ConnectorRequest{Client: server.Client()}, knowledge.ConnectorItem{ExternalID: "p3"})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	want := strings.Join([]string{
+		"# Plan",
+		"",
+		"- Ship v2",
+		"",
+		"  by March",
+		"",
+		"- [x] Docs",
+		"",
+		"```shell",
+		"go test",
+		"```",
+	}, "\n")
+	if content.Content != want {
+		t.Fatalf("content =\n%s\nwant\n%s", content.Content, want)
+	}
+}
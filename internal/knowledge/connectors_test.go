@@ -0,0 +1,465 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/oauth2"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	"github.com/memohai/memoh/internal/oauthclients"
+)
+
+const testConnectorID = "88888888-8888-8888-8888-888888888888"
+
+func (f *fakeKnowledgeQueries) CompleteKnowledgeConnectorSync(_ context.Context, id pgtype.UUID) error {
+	sync := f.syncs[id.String()]
+	sync.Status = JobCompleted
+	f.syncs[id.String()] = sync
+	return nil
+}
+
+func (*fakeKnowledgeQueries) CreateKnowledgeConnector(context.Context, sqlc.CreateKnowledgeConnectorParams) (sqlc.KnowledgeConnector, error) {
+	return sqlc.KnowledgeConnector{}, errors.New("not implemented")
+}
+
+func (f *fakeKnowledgeQueries) DeleteKnowledgeConnector(_ context.Context, id pgtype.UUID) (int64, error) {
+	delete(f.connectors, id.String())
+	return 1, nil
+}
+
+func (f *fakeKnowledgeQueries) DeleteKnowledgeConnectorToken(_ context.Context, connectorID pgtype.UUID) (int64, error) {
+	delete(f.tokens, connectorID.String())
+	return 1, nil
+}
+
+func (f *fakeKnowledgeQueries) EnqueueKnowledgeConnectorSync(_ context.Context, arg sqlc.EnqueueKnowledgeConnectorSyncParams) (sqlc.KnowledgeConnectorSync, error) {
+	for id, sync := range f.syncs {
+		if sync.ConnectorID == arg.ConnectorID && sync.Status == JobPending {
+			sync.FullSync = sync.FullSync || arg.FullSync
+			f.syncs[id] = sync
+			return sync, nil
+		}
+	}
+	f.syncSeq++
+	id := pgtype.UUID{Valid: true}
+	id.Bytes[0] = 0xe0
+	id.Bytes[15] = f.syncSeq
+	sync := sqlc.KnowledgeConnectorSync{ID: id, ConnectorID: arg.ConnectorID, Status: JobPending, FullSync: arg.FullSync}
+	f.syncs[id.String()] = sync
+	return sync, nil
+}
+
+func (f *fakeKnowledgeQueries) FailKnowledgeConnectorSync(_ context.Context, arg sqlc.FailKnowledgeConnectorSyncParams) error {
+	sync := f.syncs[arg.ID.String()]
+	sync.Status = JobFailed
+	sync.Error = arg.Error
+	f.syncs[arg.ID.String()] = sync
+	return nil
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeConnector(_ context.Context, id pgtype.UUID) (sqlc.KnowledgeConnector, error) {
+	row, ok := f.connectors[id.String()]
+	if !ok {
+		return sqlc.KnowledgeConnector{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeConnectorSync(_ context.Context, id pgtype.UUID) (sqlc.KnowledgeConnectorSync, error) {
+	sync, ok := f.syncs[id.String()]
+	if !ok {
+		return sqlc.KnowledgeConnectorSync{}, pgx.ErrNoRows
+	}
+	return sync, nil
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeConnectorToken(_ context.Context, connectorID pgtype.UUID) (sqlc.KnowledgeConnectorToken, error) {
+	token, ok := f.tokens[connectorID.String()]
+	if !ok {
+		return sqlc.KnowledgeConnectorToken{}, pgx.ErrNoRows
+	}
+	return token, nil
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeConnectorTokenByState(_ context.Context, state string) (sqlc.KnowledgeConnectorToken, error) {
+	for _, token := range f.tokens {
+		if token.State != "" && token.State == state {
+			return token, nil
+		}
+	}
+	return sqlc.KnowledgeConnectorToken{}, pgx.ErrNoRows
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeDocumentByExternalID(_ context.Context, arg sqlc.GetKnowledgeDocumentByExternalIDParams) (sqlc.KnowledgeDocument, error) {
+	for _, doc := range f.documents {
+		if doc.ConnectorID == arg.ConnectorID && doc.ExternalID == arg.ExternalID {
+			return doc, nil
+		}
+	}
+	return sqlc.KnowledgeDocument{}, pgx.ErrNoRows
+}
+
+func (*fakeKnowledgeQueries) ListDueKnowledgeConnectors(context.Context, pgtype.Timestamptz) ([]sqlc.KnowledgeConnector, error) {
+	return nil, nil
+}
+
+func (f *fakeKnowledgeQueries) ListKnowledgeConnectorDocuments(_ context.Context, connectorID pgtype.UUID) ([]sqlc.ListKnowledgeConnectorDocumentsRow, error) {
+	var rows []sqlc.ListKnowledgeConnectorDocumentsRow
+	for _, doc := range f.documents {
+		if doc.ConnectorID == connectorID {
+			rows = append(rows, sqlc.ListKnowledgeConnectorDocumentsRow{ID: doc.ID, CollectionID: doc.CollectionID, ExternalID: doc.ExternalID})
+		}
+	}
+	return rows, nil
+}
+
+func (*fakeKnowledgeQueries) ListKnowledgeConnectorSyncs(context.Context, sqlc.ListKnowledgeConnectorSyncsParams) ([]sqlc.KnowledgeConnectorSync, error) {
+	return nil, nil
+}
+
+func (*fakeKnowledgeQueries) ListKnowledgeConnectors(context.Context, pgtype.UUID) ([]sqlc.KnowledgeConnector, error) {
+	return nil, nil
+}
+
+func (*fakeKnowledgeQueries) ListUnfinishedKnowledgeConnectorSyncs(context.Context) ([]sqlc.KnowledgeConnectorSync, error) {
+	return nil, nil
+}
+
+func (f *fakeKnowledgeQueries) MarkKnowledgeConnectorSyncRunning(_ context.Context, id pgtype.UUID) (int64, error) {
+	sync, ok := f.syncs[id.String()]
+	if !ok || (sync.Status != JobPending && sync.Status != JobRunning) {
+		return 0, nil
+	}
+	sync.Status = JobRunning
+	f.syncs[id.String()] = sync
+	return 1, nil
+}
+
+func (f *fakeKnowledgeQueries) SetKnowledgeConnectorCursor(_ context.Context, arg sqlc.SetKnowledgeConnectorCursorParams) error {
+	row := f.connectors[arg.ID.String()]
+	row.Cursor = arg.Cursor
+	f.connectors[arg.ID.String()] = row
+	return nil
+}
+
+func (*fakeKnowledgeQueries) SetKnowledgeConnectorNextSync(context.Context, sqlc.SetKnowledgeConnectorNextSyncParams) error {
+	return nil
+}
+
+func (f *fakeKnowledgeQueries) SetKnowledgeConnectorOAuthState(_ context.Context, arg sqlc.SetKnowledgeConnectorOAuthStateParams) error {
+	token := f.tokens[arg.ConnectorID.String()]
+	token.ConnectorID = arg.ConnectorID
+	token.State = arg.State
+	f.tokens[arg.ConnectorID.String()] = token
+	return nil
+}
+
+func (f *fakeKnowledgeQueries) SetKnowledgeConnectorSyncProgress(_ context.Context, arg sqlc.SetKnowledgeConnectorSyncProgressParams) error {
+	sync := f.syncs[arg.ID.String()]
+	sync.ItemsSeen = arg.ItemsSeen
+	sync.DocumentsIngested = arg.DocumentsIngested
+	sync.DocumentsSkipped = arg.DocumentsSkipped
+	sync.DocumentsRemoved = arg.DocumentsRemoved
+	f.syncs[arg.ID.String()] = sync
+	return nil
+}
+
+func (*fakeKnowledgeQueries) UpdateKnowledgeConnector(context.Context, sqlc.UpdateKnowledgeConnectorParams) (sqlc.KnowledgeConnector, error) {
+	return sqlc.KnowledgeConnector{}, errors.New("not implemented")
+}
+
+func (f *fakeKnowledgeQueries) UpdateKnowledgeConnectorDocument(_ context.Context, arg sqlc.UpdateKnowledgeConnectorDocumentParams) (sqlc.KnowledgeDocument, error) {
+	row, ok := f.documents[arg.ID.String()]
+	if !ok {
+		return sqlc.KnowledgeDocument{}, pgx.ErrNoRows
+	}
+	row.Title = arg.Title
+	row.Content = arg.Content
+	row.ContentHash = arg.ContentHash
+	row.SourceUrl = arg.SourceUrl
+	row.ExternalVersion = arg.ExternalVersion
+	f.documents[arg.ID.String()] = row
+	return row, nil
+}
+
+func (f *fakeKnowledgeQueries) UpsertKnowledgeConnectorToken(_ context.Context, arg sqlc.UpsertKnowledgeConnectorTokenParams) (sqlc.KnowledgeConnectorToken, error) {
+	token := sqlc.KnowledgeConnectorToken{
+		ConnectorID:  arg.ConnectorID,
+		Account:      arg.Account,
+		AccessToken:  arg.AccessToken,
+		RefreshToken: arg.RefreshToken,
+		TokenType:    arg.TokenType,
+		ExpiresAt:    arg.ExpiresAt,
+		Scope:        arg.Scope,
+	}
+	f.tokens[arg.ConnectorID.String()] = token
+	return token, nil
+}
+
+// fakeAdapter lists the items in changes for every cursor and serves content
+// from pages; a missing page fails to fetch.
+type fakeAdapter struct {
+	changes map[string]ConnectorChanges
+	pages   map[string]string
+	fetched []string
+}
+
+func (*fakeAdapter) Type() string { return "fake" }
+
+func (*fakeAdapter) Meta() ConnectorMeta {
+	return ConnectorMeta{Type: "fake", DisplayName: "Fake", OAuthClient: "knowledge_fake"}
+}
+
+func (*fakeAdapter) OAuth() ConnectorOAuth {
+	return ConnectorOAuth{AuthURL: "https://fake.example/authorize", TokenURL: "https://fake.example/token"}
+}
+
+func (*fakeAdapter) NormalizeConfig(raw map[string]any) (map[string]any, error) {
+	return raw, nil
+}
+
+func (*fakeAdapter) Account(_ context.Context, _ *http.Client, token *oauth2.Token) (string, error) {
+	account, _ := token.Extra("account").(string)
+	return account, nil
+}
+
+func (a *fakeAdapter) Changes(_ context.Context, _ ConnectorRequest, cursor string) (ConnectorChanges, error) {
+	return a.changes[cursor], nil
+}
+
+func (a *fakeAdapter) Fetch(_ context.Context, _ ConnectorRequest, item ConnectorItem) (ConnectorContent, error) {
+	a.fetched = append(a.fetched, item.ExternalID)
+	content, ok := a.pages[item.ExternalID]
+	if !ok {
+		return ConnectorContent{}, errors.New("not found")
+	}
+	return ConnectorContent{Content: content}, nil
+}
+
+type fakeOAuthClients struct {
+	client oauthclients.Client
+}
+
+func (f fakeOAuthClients) Get(string) (oauthclients.Client, bool) { return f.client, true }
+
+func (fakeOAuthClients) HasUsableClient(string) bool { return true }
+
+func newConnectorTestService(queries *fakeKnowledgeQueries, adapter *fakeAdapter) *Service {
+	seedCollections(queries)
+	queries.connectors[testConnectorID] = sqlc.KnowledgeConnector{
+		ID:           db.ParseUUIDOrEmpty(testConnectorID),
+		CollectionID: db.ParseUUIDOrEmpty(textColID),
+		Type:         "fake",
+		Name:         "Fake",
+		Config:       []byte("{}"),
+		Enabled:      true,
+	}
+	svc := newTestService(queries, &fakeIndex{}, staticEmbed)
+	svc.RegisterConnector(adapter)
+	svc.SetOAuthClients(fakeOAuthClients{client: oauthclients.Client{ClientID: "id", ClientSecret: "secret"}})
+	return svc
+}
+
+func runConnectorSync(t *testing.T, svc *Service, queries *fakeKnowledgeQueries, full bool) sqlc.KnowledgeConnectorSync {
+	t.Helper()
+	sync, err := queries.EnqueueKnowledgeConnectorSync(context.Background(), sqlc.EnqueueKnowledgeConnectorSyncParams{
+		ConnectorID: db.ParseUUIDOrEmpty(testConnectorID),
+		FullSync:    full,
+	})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	svc.processConnectorSync(context.Background(), sync.ID.String())
+	return queries.syncs[sync.ID.String()]
+}
+
+func TestConnectorSyncIngestsUpdatesAndRemovesItems(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	adapter := &fakeAdapter{
+		changes: map[string]ConnectorChanges{
+			"": {Cursor: "c1", Items: []ConnectorItem{
+				{ExternalID: "a", Title: "Alpha", Version: "1"},
+				{ExternalID: "b", Title: "Beta", Version: "1"},
+				{ExternalID: "c", Title: "Gamma", Version: "1"},
+			}},
+			"c1": {Cursor: "c2", Items: []ConnectorItem{
+				{ExternalID: "a", Title: "Alpha", Version: "2"},
+				{ExternalID: "b", Title: "Beta", Version: "1"},
+				{ExternalID: "c", Deleted: true},
+			}},
+		},
+		pages: map[string]string{"a": "Alpha v1", "b": "Beta v1", "c": "Gamma v1"},
+	}
+	svc := newConnectorTestService(queries, adapter)
+	queries.tokens[testConnectorID] = sqlc.KnowledgeConnectorToken{ConnectorID: db.ParseUUIDOrEmpty(testConnectorID), AccessToken: "token"}
+
+	first := runConnectorSync(t, svc, queries, false)
+	if first.Status != JobCompleted || first.DocumentsIngested != 3 {
+		t.Fatalf("first sync = %+v, want 3 documents ingested", first)
+	}
+	if cursor := queries.connectors[testConnectorID].Cursor; cursor != "c1" {
+		t.Fatalf("cursor = %q, want c1", cursor)
+	}
+
+	adapter.pages["a"] = "Alpha v2"
+	adapter.fetched = nil
+	second := runConnectorSync(t, svc, queries, false)
+	if second.Status != JobCompleted {
+		t.Fatalf("second sync = %+v, want completed", second)
+	}
+	if second.DocumentsIngested != 1 || second.DocumentsSkipped != 1 || second.DocumentsRemoved != 1 {
+		t.Fatalf("second sync = %+v, want 1 ingested, 1 skipped, 1 removed", second)
+	}
+	if len(adapter.fetched) != 1 || adapter.fetched[0] != "a" {
+		t.Fatalf("fetched = %v, want only the changed item", adapter.fetched)
+	}
+	if len(queries.documents) != 2 {
+		t.Fatalf("documents = %d, want 2", len(queries.documents))
+	}
+	for _, doc := range queries.documents {
+		if doc.ExternalID == "a" && (doc.Content != "Alpha v2" || doc.ExternalVersion != "2") {
+			t.Fatalf("item a not updated: %+v", doc)
+		}
+	}
+}
+
+func TestConnectorFullSyncRemovesUnlistedDocuments(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	adapter := &fakeAdapter{
+		changes: map[string]ConnectorChanges{
+			"": {Cursor: "c1", Items: []ConnectorItem{{ExternalID: "a", Version: "1"}}},
+		},
+		pages: map[string]string{"a": "Alpha"},
+	}
+	svc := newConnectorTestService(queries, adapter)
+	connector := queries.connectors[testConnectorID]
+	connector.Cursor = "c0"
+	queries.connectors[testConnectorID] = connector
+	queries.tokens[testConnectorID] = sqlc.KnowledgeConnectorToken{ConnectorID: connector.ID, AccessToken: "token"}
+	queries.documents[testDocID] = sqlc.KnowledgeDocument{
+		ID:           db.ParseUUIDOrEmpty(testDocID),
+		CollectionID: db.ParseUUIDOrEmpty(textColID),
+		ConnectorID:  connector.ID,
+		ExternalID:   "gone",
+	}
+
+	sync := runConnectorSync(t, svc, queries, true)
+
+	if sync.Status != JobCompleted || sync.DocumentsRemoved != 1 {
+		t.Fatalf("sync = %+v, want completed with 1 removed", sync)
+	}
+	if _, ok := queries.documents[testDocID]; ok {
+		t.Fatal("document of unlisted item still stored")
+	}
+}
+
+func TestConnectorSyncKeepsCursorWhenItemFails(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	adapter := &fakeAdapter{
+		changes: map[string]ConnectorChanges{
+			"": {Cursor: "c1", Items: []ConnectorItem{
+				{ExternalID: "a", Version: "1"},
+				{ExternalID: "broken", Version: "1"},
+			}},
+		},
+		pages: map[string]string{"a": "Alpha"},
+	}
+	svc := newConnectorTestService(queries, adapter)
+	queries.tokens[testConnectorID] = sqlc.KnowledgeConnectorToken{ConnectorID: db.ParseUUIDOrEmpty(testConnectorID), AccessToken: "token"}
+
+	sync := runConnectorSync(t, svc, queries, false)
+
+	if sync.Status != JobFailed || sync.DocumentsIngested != 1 || sync.DocumentsSkipped != 1 {
+		t.Fatalf("sync = %+v, want failed with 1 ingested and 1 skipped", sync)
+	}
+	if cursor := queries.connectors[testConnectorID].Cursor; cursor != "" {
+		t.Fatalf("cursor = %q, want unchanged", cursor)
+	}
+}
+
+func TestConnectorSyncFailsWithoutToken(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	svc := newConnectorTestService(queries, &fakeAdapter{})
+
+	if _, err := svc.SyncConnector(context.Background(), textColID, testConnectorID, false); !errors.Is(err, ErrConnectorUnauthorized) {
+		t.Fatalf("SyncConnector error = %v, want ErrConnectorUnauthorized", err)
+	}
+}
+
+func TestConnectorOAuthStoresTokenAndStartsFullSync(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("code") != "the-code" {
+			http.Error(w, "bad code", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"at","refresh_token":"rt","token_type":"bearer","expires_in":3600,"account":"Acme"}`))
+	}))
+	defer tokenServer.Close()
+
+	queries := newFakeKnowledgeQueries()
+	svc := newConnectorTestService(queries, &fakeAdapter{})
+	svc.SetOAuthClients(fakeOAuthClients{client: oauthclients.Client{
+		ClientID:      "id",
+		ClientSecret:  "secret",
+		TokenEndpoint: tokenServer.URL,
+	}})
+	svc.httpClient = tokenServer.Client()
+
+	authURL, err := svc.AuthorizeConnector(context.Background(), textColID, testConnectorID, "https://memoh.example/callback", "state-1")
+	if err != nil {
+		t.Fatalf("AuthorizeConnector: %v", err)
+	}
+	if authURL == "" {
+		t.Fatal("empty authorization URL")
+	}
+	if _, err := svc.CompleteConnectorOAuth(context.Background(), "other-state", "the-code", "https://memoh.example/callback"); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Fatalf("unknown state error = %v, want ErrInvalidOAuthState", err)
+	}
+
+	connector, err := svc.CompleteConnectorOAuth(context.Background(), "state-1", "the-code", "https://memoh.example/callback")
+	if err != nil {
+		t.Fatalf("CompleteConnectorOAuth: %v", err)
+	}
+	if !connector.Authorized || connector.Account != "Acme" {
+		t.Fatalf("connector = %+v, want authorized for Acme", connector)
+	}
+	token := queries.tokens[testConnectorID]
+	if token.AccessToken != "at" || token.RefreshToken != "rt" || token.State != "" || !token.ExpiresAt.Valid {
+		t.Fatalf("stored token = %+v", token)
+	}
+	if len(queries.syncs) != 1 {
+		t.Fatalf("syncs = %d, want 1", len(queries.syncs))
+	}
+	for _, sync := range queries.syncs {
+		if !sync.FullSync {
+			t.Fatalf("sync = %+v, want a full sync", sync)
+		}
+	}
+}
+
+func TestOfflineRefusesConnectors(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	svc := newConnectorTestService(queries, &fakeAdapter{})
+	svc.DisableExternalSources()
+
+	if _, err := svc.CreateConnector(context.Background(), textColID, CreateConnectorRequest{Type: "fake", Name: "Fake"}); !errors.Is(err, ErrExternalSourcesDisabled) {
+		t.Fatalf("CreateConnector = %v, want ErrExternalSourcesDisabled", err)
+	}
+	if _, err := svc.SyncConnector(context.Background(), textColID, testConnectorID, true); !errors.Is(err, ErrExternalSourcesDisabled) {
+		t.Fatalf("SyncConnector = %v, want ErrExternalSourcesDisabled", err)
+	}
+	if _, err := svc.AuthorizeConnector(context.Background(), textColID, testConnectorID, "https://example.com/callback", "state"); !errors.Is(err, ErrExternalSourcesDisabled) {
+		t.Fatalf("AuthorizeConnector = %v, want ErrExternalSourcesDisabled", err)
+	}
+	if sync := runConnectorSync(t, svc, queries, true); sync.Status != JobPending {
+		t.Fatalf("sync status = %q, want it left pending", sync.Status)
+	}
+}
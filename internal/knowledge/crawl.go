@@ -0,0 +1,290 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	readability "github.com/go-shiori/go-readability"
+	"golang.org/x/net/html"
+
+	"github.com/memohai/memoh/internal/netguard"
+)
+
+const (
+	crawlUserAgent    = "MemohKnowledgeCrawler/1.0"
+	crawlFetchTimeout = 30 * time.Second
+	crawlMaxBodyBytes = 5 << 20
+)
+
+// crawlConfig is a validated crawl source.
+type crawlConfig struct {
+	seeds    []*url.URL
+	maxDepth int
+	maxPages int
+	include  []*regexp.Regexp
+	exclude  []*regexp.Regexp
+	delay    time.Duration
+}
+
+// crawledPage is the main content of one fetched page.
+type crawledPage struct {
+	URL     string
+	Title   string
+	Content string
+}
+
+// crawler walks pages breadth-first from the seed URLs. It stays on the
+// seeds' hosts, follows links at most maxDepth hops from a seed, fetches at
+// most maxPages pages, and waits delay between requests. Its client refuses
+// loopback, private and link-local addresses.
+type crawler struct {
+	client *http.Client
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func newCrawler() *crawler {
+	return &crawler{
+		client: netguard.NewClient(crawlFetchTimeout),
+		sleep:  sleepContext,
+	}
+}
+
+type crawlTarget struct {
+	url   *url.URL
+	depth int
+}
+
+// crawl calls visit for every page with extractable content and failed for
+// every page that could not be fetched or read. It returns the number of
+// pages requested. An error from visit stops the crawl.
+func (c *crawler) crawl(ctx context.Context, cfg crawlConfig, visit func(crawledPage) error, failed func(pageURL string, err error)) (int, error) {
+	hosts := make(map[string]bool, len(cfg.seeds))
+	queued := map[string]bool{}
+	queue := make([]crawlTarget, 0, len(cfg.seeds))
+	for _, seed := range cfg.seeds {
+		hosts[strings.ToLower(seed.Host)] = true
+		key := canonicalURL(seed)
+		if !queued[key] {
+			queued[key] = true
+			queue = append(queue, crawlTarget{url: seed})
+		}
+	}
+
+	fetched := 0
+	for len(queue) > 0 && fetched < cfg.maxPages {
+		target := queue[0]
+		queue = queue[1:]
+		if fetched > 0 {
+			if err := c.sleep(ctx, cfg.delay); err != nil {
+				return fetched, err
+			}
+		}
+		fetched++
+		pageURL := canonicalURL(target.url)
+		page, links, err := c.fetch(ctx, target.url)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fetched, ctx.Err()
+			}
+			failed(pageURL, err)
+			continue
+		}
+		if strings.TrimSpace(page.Content) != "" {
+			if err := visit(page); err != nil {
+				return fetched, err
+			}
+		}
+		if target.depth >= min(cfg.maxDepth, maxCrawlDepth) {
+			continue
+		}
+		for _, link := range links {
+			if !hosts[strings.ToLower(link.Host)] || !cfg.follows(link.String()) {
+				continue
+			}
+			key := canonicalURL(link)
+			if queued[key] {
+				continue
+			}
+			queued[key] = true
+			queue = append(queue, crawlTarget{url: link, depth: target.depth + 1})
+		}
+	}
+	return fetched, nil
+}
+
+// follows reports whether a discovered link passes the include and exclude
+// patterns. Seed URLs are always fetched.
+func (cfg crawlConfig) follows(link string) bool {
+	for _, re := range cfg.exclude {
+		if re.MatchString(link) {
+			return false
+		}
+	}
+	if len(cfg.include) == 0 {
+		return true
+	}
+	for _, re := range cfg.include {
+		if re.MatchString(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch downloads one page and returns its main content and, for HTML, the
+// links it contains. Pages other than HTML and plain text yield no content.
+func (c *crawler) fetch(ctx context.Context, target *url.URL) (crawledPage, []*url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return crawledPage{}, nil, err
+	}
+	req.Header.Set("User-Agent", crawlUserAgent)
+	resp, err := c.client.Do(req) //nolint:gosec // crawls URLs configured by the collection owner.
+	if err != nil {
+		return crawledPage{}, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return crawledPage{}, nil, fmt.Errorf("http status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, crawlMaxBodyBytes))
+	if err != nil {
+		return crawledPage{}, nil, err
+	}
+
+	// Redirects may land on another URL; record and resolve against that.
+	final := target
+	if resp.Request != nil && resp.Request.URL != nil {
+		final = resp.Request.URL
+	}
+	page := crawledPage{URL: canonicalURL(final)}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/html", "application/xhtml+xml", "":
+		page.Title, page.Content = extractMainContent(final, body)
+		return page, extractLinks(final, body), nil
+	case "text/plain", "text/markdown":
+		page.Content = strings.TrimSpace(string(body))
+		return page, nil, nil
+	default:
+		return page, nil, nil
+	}
+}
+
+// extractMainContent returns the page title and its main content as
+// Markdown, dropping navigation and other boilerplate. Pages readability
+// cannot make sense of are converted whole.
+func extractMainContent(pageURL *url.URL, body []byte) (string, string) {
+	article, err := readability.FromReader(bytes.NewReader(body), pageURL)
+	if err == nil && strings.TrimSpace(article.Content) != "" {
+		markdown, convErr := htmltomarkdown.ConvertString(article.Content)
+		if convErr != nil {
+			markdown = article.TextContent
+		}
+		return strings.TrimSpace(article.Title), strings.TrimSpace(markdown)
+	}
+	markdown, err := htmltomarkdown.ConvertString(string(body))
+	if err != nil {
+		return "", ""
+	}
+	return "", strings.TrimSpace(markdown)
+}
+
+// extractLinks returns the absolute http(s) links of an HTML page, without
+// fragments.
+func extractLinks(base *url.URL, body []byte) []*url.URL {
+	var links []*url.URL
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			if string(name) == "base" {
+				// <base href> changes how every relative link resolves.
+				if href := hrefAttr(tokenizer, hasAttr); href != "" {
+					if resolved, err := base.Parse(href); err == nil {
+						base = resolved
+					}
+				}
+				continue
+			}
+			if string(name) != "a" {
+				continue
+			}
+			href := hrefAttr(tokenizer, hasAttr)
+			if href == "" {
+				continue
+			}
+			link, err := base.Parse(href)
+			if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+				continue
+			}
+			link.Fragment = ""
+			link.RawFragment = ""
+			links = append(links, link)
+		}
+	}
+}
+
+func hrefAttr(tokenizer *html.Tokenizer, hasAttr bool) string {
+	for hasAttr {
+		var key, val []byte
+		key, val, hasAttr = tokenizer.TagAttr()
+		if string(key) == "href" {
+			return strings.TrimSpace(string(val))
+		}
+	}
+	return ""
+}
+
+// parseSeedURL accepts absolute http(s) URLs only.
+func parseSeedURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("seed URLs must be absolute http or https URLs")
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u, nil
+}
+
+// canonicalURL is the form stored as a document's source URL.
+func canonicalURL(u *url.URL) string {
+	c := *u
+	c.Fragment = ""
+	c.RawFragment = ""
+	c.Host = strings.ToLower(c.Host)
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	return c.String()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
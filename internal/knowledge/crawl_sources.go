@@ -0,0 +1,770 @@
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/robfig/cron/v3"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+const (
+	defaultCrawlMaxDepth = 1
+	defaultCrawlMaxPages = 50
+	defaultCrawlDelayMs  = 1000
+	maxCrawlDepth        = 5
+	maxCrawlPages        = 1000
+	maxCrawlDelayMs      = 60000
+	maxCrawlSeeds        = 20
+	maxCrawlPatterns     = 20
+	crawlQueueSize       = 64
+	crawlSweepInterval   = time.Minute
+	crawlRunHistory      = 20
+)
+
+var crawlScheduleParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// CreateCrawlSource adds a website crawl source to a collection. Enabled
+// sources with a refresh pattern are crawled on that schedule; use
+// RunCrawlSource for the first crawl.
+func (s *Service) CreateCrawlSource(ctx context.Context, collectionID string, req CreateCrawlSourceRequest) (CrawlSource, error) {
+	if s.externalDisabled {
+		return CrawlSource{}, ErrExternalSourcesDisabled
+	}
+	store, err := s.store()
+	if err != nil {
+		return CrawlSource{}, err
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return CrawlSource{}, err
+	}
+	params := sqlc.CreateKnowledgeCrawlSourceParams{
+		CollectionID: collection.ID,
+		Enabled:      req.Enabled == nil || *req.Enabled,
+	}
+	maxDepth := defaultCrawlMaxDepth
+	if req.MaxDepth != nil {
+		maxDepth = *req.MaxDepth
+	}
+	maxPages := req.MaxPages
+	if maxPages == 0 {
+		maxPages = defaultCrawlMaxPages
+	}
+	delayMs := defaultCrawlDelayMs
+	if req.DelayMs != nil {
+		delayMs = *req.DelayMs
+	}
+	settings, err := normalizeCrawlSettings(crawlSettings{
+		name:           req.Name,
+		seeds:          req.SeedURLs,
+		maxDepth:       maxDepth,
+		maxPages:       maxPages,
+		include:        req.IncludePatterns,
+		exclude:        req.ExcludePatterns,
+		delayMs:        delayMs,
+		refreshPattern: req.RefreshPattern,
+	})
+	if err != nil {
+		return CrawlSource{}, err
+	}
+	params.Name = settings.name
+	params.SeedUrls = encodeStrings(settings.seeds)
+	params.MaxDepth = int32(settings.maxDepth) //nolint:gosec // validated by normalizeCrawlSettings.
+	params.MaxPages = int32(settings.maxPages) //nolint:gosec // validated by normalizeCrawlSettings.
+	params.IncludePatterns = encodeStrings(settings.include)
+	params.ExcludePatterns = encodeStrings(settings.exclude)
+	params.DelayMs = int32(settings.delayMs) //nolint:gosec // validated by normalizeCrawlSettings.
+	params.RefreshPattern = settings.refreshPattern
+	params.NextRunAt = nextCrawlRun(settings.refreshPattern, params.Enabled, time.Now())
+	row, err := store.CreateKnowledgeCrawlSource(ctx, params)
+	if err != nil {
+		return CrawlSource{}, fmt.Errorf("create knowledge crawl source: %w", err)
+	}
+	return toCrawlSource(row), nil
+}
+
+// ListCrawlSources lists the crawl sources of a collection.
+func (s *Service) ListCrawlSources(ctx context.Context, collectionID string) ([]CrawlSource, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListKnowledgeCrawlSources(ctx, collection.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list knowledge crawl sources: %w", err)
+	}
+	items := make([]CrawlSource, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toCrawlSource(row))
+	}
+	return items, nil
+}
+
+// GetCrawlSource returns one crawl source of a collection.
+func (s *Service) GetCrawlSource(ctx context.Context, collectionID, sourceID string) (CrawlSource, error) {
+	store, err := s.store()
+	if err != nil {
+		return CrawlSource{}, err
+	}
+	row, err := s.getCrawlSource(ctx, store, collectionID, sourceID)
+	if err != nil {
+		return CrawlSource{}, err
+	}
+	return toCrawlSource(row), nil
+}
+
+// UpdateCrawlSource changes a crawl source. The next scheduled run is
+// recomputed from the refresh pattern; documents already crawled are left
+// alone until the next run.
+func (s *Service) UpdateCrawlSource(ctx context.Context, collectionID, sourceID string, req UpdateCrawlSourceRequest) (CrawlSource, error) {
+	store, err := s.store()
+	if err != nil {
+		return CrawlSource{}, err
+	}
+	current, err := s.getCrawlSource(ctx, store, collectionID, sourceID)
+	if err != nil {
+		return CrawlSource{}, err
+	}
+	settings := crawlSettings{
+		name:           current.Name,
+		seeds:          decodeStrings(current.SeedUrls),
+		maxDepth:       int(current.MaxDepth),
+		maxPages:       int(current.MaxPages),
+		include:        decodeStrings(current.IncludePatterns),
+		exclude:        decodeStrings(current.ExcludePatterns),
+		delayMs:        int(current.DelayMs),
+		refreshPattern: current.RefreshPattern,
+	}
+	enabled := current.Enabled
+	if req.Name != nil {
+		settings.name = *req.Name
+	}
+	if req.SeedURLs != nil {
+		settings.seeds = *req.SeedURLs
+	}
+	if req.MaxDepth != nil {
+		settings.maxDepth = *req.MaxDepth
+	}
+	if req.MaxPages != nil {
+		settings.maxPages = *req.MaxPages
+	}
+	if req.IncludePatterns != nil {
+		settings.include = *req.IncludePatterns
+	}
+	if req.ExcludePatterns != nil {
+		settings.exclude = *req.ExcludePatterns
+	}
+	if req.DelayMs != nil {
+		settings.delayMs = *req.DelayMs
+	}
+	if req.RefreshPattern != nil {
+		settings.refreshPattern = *req.RefreshPattern
+	}
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	if settings, err = normalizeCrawlSettings(settings); err != nil {
+		return CrawlSource{}, err
+	}
+	row, err := store.UpdateKnowledgeCrawlSource(ctx, sqlc.UpdateKnowledgeCrawlSourceParams{
+		Name:            settings.name,
+		SeedUrls:        encodeStrings(settings.seeds),
+		MaxDepth:        int32(settings.maxDepth), //nolint:gosec // validated by normalizeCrawlSettings.
+		MaxPages:        int32(settings.maxPages), //nolint:gosec // validated by normalizeCrawlSettings.
+		IncludePatterns: encodeStrings(settings.include),
+		ExcludePatterns: encodeStrings(settings.exclude),
+		DelayMs:         int32(settings.delayMs), //nolint:gosec // validated by normalizeCrawlSettings.
+		RefreshPattern:  settings.refreshPattern,
+		Enabled:         enabled,
+		NextRunAt:       nextCrawlRun(settings.refreshPattern, enabled, time.Now()),
+		ID:              current.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CrawlSource{}, ErrCrawlSourceNotFound
+		}
+		return CrawlSource{}, fmt.Errorf("update knowledge crawl source: %w", err)
+	}
+	return toCrawlSource(row), nil
+}
+
+// DeleteCrawlSource deletes a crawl source and its run history. Documents it
+// crawled stay in the collection.
+func (s *Service) DeleteCrawlSource(ctx context.Context, collectionID, sourceID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	current, err := s.getCrawlSource(ctx, store, collectionID, sourceID)
+	if err != nil {
+		return err
+	}
+	n, err := store.DeleteKnowledgeCrawlSource(ctx, current.ID)
+	if err != nil {
+		return fmt.Errorf("delete knowledge crawl source: %w", err)
+	}
+	if n == 0 {
+		return ErrCrawlSourceNotFound
+	}
+	return nil
+}
+
+// RunCrawlSource starts a crawl now. A run still waiting to start is reused.
+func (s *Service) RunCrawlSource(ctx context.Context, collectionID, sourceID string) (CrawlRun, error) {
+	if s.externalDisabled {
+		return CrawlRun{}, ErrExternalSourcesDisabled
+	}
+	store, err := s.store()
+	if err != nil {
+		return CrawlRun{}, err
+	}
+	source, err := s.getCrawlSource(ctx, store, collectionID, sourceID)
+	if err != nil {
+		return CrawlRun{}, err
+	}
+	row, err := store.EnqueueKnowledgeCrawlRun(ctx, source.ID)
+	if err != nil {
+		return CrawlRun{}, fmt.Errorf("enqueue knowledge crawl run: %w", err)
+	}
+	run := toCrawlRun(row)
+	select {
+	case s.crawls <- run.ID:
+	default:
+		// The run stays pending in the database; the next sweep starts it.
+		s.logger.Warn("knowledge crawl queue full, deferring run", slog.String("run_id", run.ID))
+	}
+	return run, nil
+}
+
+// ListCrawlRuns lists the most recent runs of a crawl source.
+func (s *Service) ListCrawlRuns(ctx context.Context, collectionID, sourceID string) ([]CrawlRun, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.getCrawlSource(ctx, store, collectionID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListKnowledgeCrawlRuns(ctx, sqlc.ListKnowledgeCrawlRunsParams{
+		SourceID: source.ID,
+		RowLimit: crawlRunHistory,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list knowledge crawl runs: %w", err)
+	}
+	items := make([]CrawlRun, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toCrawlRun(row))
+	}
+	return items, nil
+}
+
+func (s *Service) getCrawlSource(ctx context.Context, store knowledgeQueries, collectionID, sourceID string) (sqlc.KnowledgeCrawlSource, error) {
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return sqlc.KnowledgeCrawlSource{}, err
+	}
+	pgSourceID, err := db.ParseUUID(sourceID)
+	if err != nil {
+		return sqlc.KnowledgeCrawlSource{}, ErrCrawlSourceNotFound
+	}
+	row, err := store.GetKnowledgeCrawlSource(ctx, pgSourceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.KnowledgeCrawlSource{}, ErrCrawlSourceNotFound
+		}
+		return sqlc.KnowledgeCrawlSource{}, fmt.Errorf("get knowledge crawl source: %w", err)
+	}
+	if row.CollectionID != collection.ID {
+		return sqlc.KnowledgeCrawlSource{}, ErrCrawlSourceNotFound
+	}
+	return row, nil
+}
+
+// runCrawls executes crawl runs until ctx is done. Like the reprocess loop it
+// sweeps on start and then periodically, which also enqueues runs for
+// sources whose refresh is due. Crawls get their own loop because a polite
+// crawl of a large site takes far longer than re-processing.
+func (s *Service) runCrawls(ctx context.Context) {
+	s.sweepCrawls(ctx)
+	ticker := time.NewTicker(crawlSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.crawls:
+			s.processCrawlRun(ctx, id)
+		case <-ticker.C:
+			s.sweepCrawls(ctx)
+		}
+	}
+}
+
+func (s *Service) sweepCrawls(ctx context.Context) {
+	if s.externalDisabled {
+		return
+	}
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("knowledge crawl sweep failed", slog.Any("error", err))
+		return
+	}
+	s.scheduleDueCrawls(ctx, store, time.Now())
+	rows, err := store.ListUnfinishedKnowledgeCrawlRuns(ctx)
+	if err != nil {
+		s.logger.Warn("list unfinished knowledge crawl runs failed", slog.Any("error", err))
+		return
+	}
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return
+		}
+		s.processCrawlRun(ctx, row.ID.String())
+	}
+}
+
+// scheduleDueCrawls enqueues a run for every source whose refresh is due and
+// moves its next run forward. A source that is still crawling keeps a single
+// pending run, so slow crawls do not pile up.
+func (s *Service) scheduleDueCrawls(ctx context.Context, store knowledgeQueries, now time.Time) {
+	due, err := store.ListDueKnowledgeCrawlSources(ctx, pgtype.Timestamptz{Time: now.UTC(), Valid: true})
+	if err != nil {
+		s.logger.Warn("list due knowledge crawl sources failed", slog.Any("error", err))
+		return
+	}
+	for _, source := range due {
+		if err := store.SetKnowledgeCrawlSourceNextRun(ctx, sqlc.SetKnowledgeCrawlSourceNextRunParams{
+			NextRunAt: nextCrawlRun(source.RefreshPattern, source.Enabled, now),
+			ID:        source.ID,
+		}); err != nil {
+			s.logger.Warn("schedule knowledge crawl failed", slog.String("source_id", source.ID.String()), slog.Any("error", err))
+			continue
+		}
+		if _, err := store.EnqueueKnowledgeCrawlRun(ctx, source.ID); err != nil {
+			s.logger.Warn("enqueue knowledge crawl run failed", slog.String("source_id", source.ID.String()), slog.Any("error", err))
+		}
+	}
+}
+
+// processCrawlRun runs one crawl. A run that is already finished (it was
+// both queued and picked up by a sweep) is skipped.
+func (s *Service) processCrawlRun(ctx context.Context, runID string) {
+	if s.externalDisabled {
+		return
+	}
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("knowledge crawl failed", slog.String("run_id", runID), slog.Any("error", err))
+		return
+	}
+	pgRunID, err := db.ParseUUID(runID)
+	if err != nil {
+		return
+	}
+	run, err := store.GetKnowledgeCrawlRun(ctx, pgRunID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Warn("load knowledge crawl run failed", slog.String("run_id", runID), slog.Any("error", err))
+		}
+		return
+	}
+	if run.Status != JobPending && run.Status != JobRunning {
+		return
+	}
+	ran, err := s.crawlSource(ctx, store, run)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down: leave the run running so the next start retries it.
+			return
+		}
+		s.logger.Error("knowledge crawl failed", slog.String("run_id", runID), slog.Any("error", err))
+		if failErr := store.FailKnowledgeCrawlRun(context.WithoutCancel(ctx), sqlc.FailKnowledgeCrawlRunParams{
+			Error: err.Error(),
+			ID:    run.ID,
+		}); failErr != nil {
+			s.logger.Warn("mark knowledge crawl failed", slog.String("run_id", runID), slog.Any("error", failErr))
+		}
+		return
+	}
+	if !ran {
+		return
+	}
+	if err := store.CompleteKnowledgeCrawlRun(ctx, run.ID); err != nil {
+		s.logger.Error("complete knowledge crawl failed", slog.String("run_id", runID), slog.Any("error", err))
+	}
+}
+
+// crawlStats counts pages for the run record.
+type crawlStats struct {
+	fetched, ingested, skipped, removed int
+}
+
+// crawlSource crawls the run's source into its collection. Changed pages are
+// re-processed, unchanged pages are skipped, and once the crawl finishes the
+// documents of pages no longer reachable are removed. It reports false when
+// another run already finished the job.
+func (s *Service) crawlSource(ctx context.Context, store knowledgeQueries, run sqlc.KnowledgeCrawlRun) (bool, error) {
+	source, err := store.GetKnowledgeCrawlSource(ctx, run.SourceID)
+	if err != nil {
+		return false, fmt.Errorf("load crawl source: %w", err)
+	}
+	collection, err := store.GetKnowledgeCollection(ctx, source.CollectionID)
+	if err != nil {
+		return false, fmt.Errorf("load collection: %w", err)
+	}
+	cfg, err := crawlConfigFromRow(source)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := store.MarkKnowledgeCrawlRunRunning(ctx, run.ID)
+	if err != nil {
+		return false, fmt.Errorf("mark run running: %w", err)
+	}
+	if claimed == 0 {
+		return false, nil
+	}
+
+	var stats crawlStats
+	seen := map[string]bool{}
+	reachable := 0
+	visit := func(page crawledPage) error {
+		seen[page.URL] = true
+		reachable++
+		changed, err := s.ingestPage(ctx, store, collection, source.ID, page)
+		if err != nil {
+			return fmt.Errorf("ingest %s: %w", page.URL, err)
+		}
+		if changed {
+			stats.ingested++
+		} else {
+			stats.skipped++
+		}
+		stats.fetched = stats.ingested + stats.skipped
+		s.recordCrawlProgress(ctx, store, run.ID, stats)
+		return nil
+	}
+	failed := func(pageURL string, err error) {
+		// Keep documents of pages that failed this time; they may be back
+		// on the next run.
+		seen[pageURL] = true
+		stats.skipped++
+		s.logger.Info("knowledge crawl page skipped",
+			slog.String("run_id", run.ID.String()), slog.String("url", pageURL), slog.Any("error", err))
+	}
+	fetched, err := s.crawler.crawl(ctx, cfg, visit, failed)
+	stats.fetched = fetched
+	if err != nil {
+		s.recordCrawlProgress(ctx, store, run.ID, stats)
+		return false, err
+	}
+	if reachable == 0 {
+		// Removing every page because the site is down would empty the
+		// collection; fail the run instead.
+		s.recordCrawlProgress(ctx, store, run.ID, stats)
+		return false, errors.New("no page with content could be fetched")
+	}
+
+	documents, err := store.ListKnowledgeCrawlDocuments(ctx, source.ID)
+	if err != nil {
+		return false, fmt.Errorf("list crawled documents: %w", err)
+	}
+	for _, doc := range documents {
+		if seen[doc.SourceUrl] {
+			continue
+		}
+		n, err := store.DeleteKnowledgeDocument(ctx, sqlc.DeleteKnowledgeDocumentParams{CollectionID: doc.CollectionID, ID: doc.ID})
+		if err != nil {
+			return false, fmt.Errorf("remove document %s: %w", doc.ID.String(), err)
+		}
+		if n == 0 {
+			continue
+		}
+		stats.removed++
+		if s.index != nil {
+			if err := s.index.DeleteDocument(ctx, collection.TeamID, doc.CollectionID, doc.ID); err != nil {
+				s.logger.Warn("delete knowledge document vectors failed",
+					slog.String("document_id", doc.ID.String()), slog.Any("error", err))
+			}
+		}
+	}
+	s.recordCrawlProgress(ctx, store, run.ID, stats)
+	return true, nil
+}
+
+func (s *Service) recordCrawlProgress(ctx context.Context, store knowledgeQueries, runID pgtype.UUID, stats crawlStats) {
+	if err := store.SetKnowledgeCrawlRunProgress(context.WithoutCancel(ctx), sqlc.SetKnowledgeCrawlRunProgressParams{
+		PagesFetched:  int32(stats.fetched),  //nolint:gosec // bounded by maxCrawlPages.
+		PagesIngested: int32(stats.ingested), //nolint:gosec // bounded by maxCrawlPages.
+		PagesSkipped:  int32(stats.skipped),  //nolint:gosec // bounded by maxCrawlPages.
+		PagesRemoved:  int32(stats.removed),  //nolint:gosec // bounded by the source's documents.
+		ID:            runID,
+	}); err != nil {
+		s.logger.Warn("record knowledge crawl progress failed", slog.String("run_id", runID.String()), slog.Any("error", err))
+	}
+}
+
+// ingestPage stores a crawled page as the collection's document for its URL.
+// It reports false when the stored document already has the same content.
+func (s *Service) ingestPage(ctx context.Context, store knowledgeQueries, collection sqlc.KnowledgeCollection, sourceID pgtype.UUID, page crawledPage) (bool, error) {
+	sum := sha256.Sum256([]byte(page.Content))
+	hash := hex.EncodeToString(sum[:])
+	title := strings.TrimSpace(page.Title)
+	if title == "" {
+		title = page.URL
+	}
+	existing, err := store.GetKnowledgeDocumentBySourceURL(ctx, sqlc.GetKnowledgeDocumentBySourceURLParams{
+		CollectionID: collection.ID,
+		SourceUrl:    page.URL,
+	})
+	switch {
+	case err == nil:
+		if existing.ContentHash == hash && existing.CrawlSourceID == sourceID {
+			return false, nil
+		}
+		row, err := store.UpdateKnowledgeDocumentContent(ctx, sqlc.UpdateKnowledgeDocumentContentParams{
+			Title:         title,
+			Content:       page.Content,
+			ContentHash:   hash,
+			CrawlSourceID: sourceID,
+			ID:            existing.ID,
+		})
+		if err != nil {
+			return false, fmt.Errorf("update document: %w", err)
+		}
+		if existing.ContentHash == hash {
+			// Only the owning source changed; the indexed content is current.
+			return false, nil
+		}
+		if _, err := s.processDocument(ctx, store, collection, row, true); err != nil {
+			return false, err
+		}
+		return true, nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return false, fmt.Errorf("find document: %w", err)
+	}
+
+	row, err := store.CreateKnowledgeDocument(ctx, sqlc.CreateKnowledgeDocumentParams{
+		CollectionID:  collection.ID,
+		Title:         title,
+		Content:       page.Content,
+		ContentHash:   hash,
+		Metadata:      encodeMetadata(nil),
+		SourceUrl:     page.URL,
+		CrawlSourceID: sourceID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("create document: %w", err)
+	}
+	if _, err := s.processDocument(ctx, store, collection, row, false); err != nil {
+		if _, delErr := store.DeleteKnowledgeDocument(ctx, sqlc.DeleteKnowledgeDocumentParams{CollectionID: collection.ID, ID: row.ID}); delErr != nil {
+			s.logger.Warn("remove unindexed knowledge document failed",
+				slog.String("document_id", row.ID.String()), slog.Any("error", delErr))
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// crawlSettings is the user-editable part of a crawl source.
+type crawlSettings struct {
+	name           string
+	seeds          []string
+	maxDepth       int
+	maxPages       int
+	include        []string
+	exclude        []string
+	delayMs        int
+	refreshPattern string
+}
+
+func normalizeCrawlSettings(in crawlSettings) (crawlSettings, error) {
+	out := in
+	out.name = strings.TrimSpace(in.name)
+	if out.name == "" {
+		return crawlSettings{}, ErrNameRequired
+	}
+	if len([]rune(out.name)) > maxNameLength {
+		return crawlSettings{}, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidCrawlSource, maxNameLength)
+	}
+	out.seeds = make([]string, 0, len(in.seeds))
+	for _, raw := range in.seeds {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		u, err := parseSeedURL(raw)
+		if err != nil {
+			return crawlSettings{}, fmt.Errorf("%w: seed URL %q: %s", ErrInvalidCrawlSource, raw, err.Error())
+		}
+		out.seeds = append(out.seeds, canonicalURL(u))
+	}
+	if len(out.seeds) == 0 {
+		return crawlSettings{}, fmt.Errorf("%w: at least one seed URL is required", ErrInvalidCrawlSource)
+	}
+	if len(out.seeds) > maxCrawlSeeds {
+		return crawlSettings{}, fmt.Errorf("%w: at most %d seed URLs are allowed", ErrInvalidCrawlSource, maxCrawlSeeds)
+	}
+	if in.maxDepth < 0 || in.maxDepth > maxCrawlDepth {
+		return crawlSettings{}, fmt.Errorf("%w: max_depth must be between 0 and %d", ErrInvalidCrawlSource, maxCrawlDepth)
+	}
+	if in.maxPages < 1 || in.maxPages > maxCrawlPages {
+		return crawlSettings{}, fmt.Errorf("%w: max_pages must be between 1 and %d", ErrInvalidCrawlSource, maxCrawlPages)
+	}
+	if in.delayMs < 0 || in.delayMs > maxCrawlDelayMs {
+		return crawlSettings{}, fmt.Errorf("%w: delay_ms must be between 0 and %d", ErrInvalidCrawlSource, maxCrawlDelayMs)
+	}
+	var err error
+	if out.include, _, err = compilePatterns(in.include); err != nil {
+		return crawlSettings{}, err
+	}
+	if out.exclude, _, err = compilePatterns(in.exclude); err != nil {
+		return crawlSettings{}, err
+	}
+	out.refreshPattern = strings.TrimSpace(in.refreshPattern)
+	if out.refreshPattern != "" {
+		if _, err := crawlScheduleParser.Parse(out.refreshPattern); err != nil {
+			return crawlSettings{}, fmt.Errorf("%w: refresh_pattern: %s", ErrInvalidCrawlSource, err.Error())
+		}
+	}
+	return out, nil
+}
+
+// compilePatterns validates URL patterns, which are regular expressions
+// matched against the absolute URL of each discovered link.
+func compilePatterns(patterns []string) ([]string, []*regexp.Regexp, error) {
+	kept := make([]string, 0, len(patterns))
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: pattern %q: %s", ErrInvalidCrawlSource, pattern, err.Error())
+		}
+		kept = append(kept, pattern)
+		compiled = append(compiled, re)
+	}
+	if len(kept) > maxCrawlPatterns {
+		return nil, nil, fmt.Errorf("%w: at most %d patterns are allowed", ErrInvalidCrawlSource, maxCrawlPatterns)
+	}
+	return kept, compiled, nil
+}
+
+func crawlConfigFromRow(row sqlc.KnowledgeCrawlSource) (crawlConfig, error) {
+	cfg := crawlConfig{
+		maxDepth: int(row.MaxDepth),
+		maxPages: int(row.MaxPages),
+		delay:    time.Duration(row.DelayMs) * time.Millisecond,
+	}
+	for _, raw := range decodeStrings(row.SeedUrls) {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return crawlConfig{}, fmt.Errorf("seed URL %q: %w", raw, err)
+		}
+		cfg.seeds = append(cfg.seeds, u)
+	}
+	var err error
+	if _, cfg.include, err = compilePatterns(decodeStrings(row.IncludePatterns)); err != nil {
+		return crawlConfig{}, err
+	}
+	if _, cfg.exclude, err = compilePatterns(decodeStrings(row.ExcludePatterns)); err != nil {
+		return crawlConfig{}, err
+	}
+	return cfg, nil
+}
+
+// nextCrawlRun is when a source is next crawled on its own, or NULL for
+// disabled sources and sources without a refresh pattern.
+func nextCrawlRun(pattern string, enabled bool, now time.Time) pgtype.Timestamptz {
+	if !enabled || pattern == "" {
+		return pgtype.Timestamptz{}
+	}
+	schedule, err := crawlScheduleParser.Parse(pattern)
+	if err != nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: schedule.Next(now).UTC(), Valid: true}
+}
+
+func encodeStrings(values []string) []byte {
+	if values == nil {
+		values = []string{}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return []byte("[]")
+	}
+	return data
+}
+
+func decodeStrings(data []byte) []string {
+	values := []string{}
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &values)
+	}
+	return values
+}
+
+func toCrawlSource(row sqlc.KnowledgeCrawlSource) CrawlSource {
+	source := CrawlSource{
+		ID:              row.ID.String(),
+		CollectionID:    row.CollectionID.String(),
+		Name:            row.Name,
+		SeedURLs:        decodeStrings(row.SeedUrls),
+		MaxDepth:        int(row.MaxDepth),
+		MaxPages:        int(row.MaxPages),
+		IncludePatterns: decodeStrings(row.IncludePatterns),
+		ExcludePatterns: decodeStrings(row.ExcludePatterns),
+		DelayMs:         int(row.DelayMs),
+		RefreshPattern:  row.RefreshPattern,
+		Enabled:         row.Enabled,
+		CreatedAt:       db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:       db.TimeFromPg(row.UpdatedAt),
+	}
+	if row.NextRunAt.Valid {
+		next := db.TimeFromPg(row.NextRunAt)
+		source.NextRunAt = &next
+	}
+	return source
+}
+
+func toCrawlRun(row sqlc.KnowledgeCrawlRun) CrawlRun {
+	run := CrawlRun{
+		ID:            row.ID.String(),
+		SourceID:      row.SourceID.String(),
+		Status:        row.Status,
+		Error:         row.Error,
+		PagesFetched:  int(row.PagesFetched),
+		PagesIngested: int(row.PagesIngested),
+		PagesSkipped:  int(row.PagesSkipped),
+		PagesRemoved:  int(row.PagesRemoved),
+		CreatedAt:     db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:     db.TimeFromPg(row.UpdatedAt),
+	}
+	if row.CompletedAt.Valid {
+		completed := db.TimeFromPg(row.CompletedAt)
+		run.CompletedAt = &completed
+	}
+	return run
+}
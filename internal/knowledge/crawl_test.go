@@ -0,0 +1,441 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+const testSourceID = "77777777-7777-7777-7777-777777777777"
+
+func (f *fakeKnowledgeQueries) CompleteKnowledgeCrawlRun(_ context.Context, id pgtype.UUID) error {
+	run := f.runs[id.String()]
+	run.Status = JobCompleted
+	f.runs[id.String()] = run
+	return nil
+}
+
+func (*fakeKnowledgeQueries) CreateKnowledgeCrawlSource(context.Context, sqlc.CreateKnowledgeCrawlSourceParams) (sqlc.KnowledgeCrawlSource, error) {
+	return sqlc.KnowledgeCrawlSource{}, errors.New("not implemented")
+}
+
+func (f *fakeKnowledgeQueries) DeleteKnowledgeCrawlSource(_ context.Context, id pgtype.UUID) (int64, error) {
+	delete(f.sources, id.String())
+	return 1, nil
+}
+
+func (f *fakeKnowledgeQueries) EnqueueKnowledgeCrawlRun(_ context.Context, sourceID pgtype.UUID) (sqlc.KnowledgeCrawlRun, error) {
+	for _, run := range f.runs {
+		if run.SourceID == sourceID && run.Status == JobPending {
+			return run, nil
+		}
+	}
+	f.runSeq++
+	id := pgtype.UUID{Valid: true}
+	id.Bytes[0] = 0xc0
+	id.Bytes[15] = f.runSeq
+	run := sqlc.KnowledgeCrawlRun{ID: id, SourceID: sourceID, Status: JobPending}
+	f.runs[id.String()] = run
+	return run, nil
+}
+
+func (f *fakeKnowledgeQueries) FailKnowledgeCrawlRun(_ context.Context, arg sqlc.FailKnowledgeCrawlRunParams) error {
+	run := f.runs[arg.ID.String()]
+	run.Status = JobFailed
+	run.Error = arg.Error
+	f.runs[arg.ID.String()] = run
+	return nil
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeCrawlRun(_ context.Context, id pgtype.UUID) (sqlc.KnowledgeCrawlRun, error) {
+	run, ok := f.runs[id.String()]
+	if !ok {
+		return sqlc.KnowledgeCrawlRun{}, pgx.ErrNoRows
+	}
+	return run, nil
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeCrawlSource(_ context.Context, id pgtype.UUID) (sqlc.KnowledgeCrawlSource, error) {
+	source, ok := f.sources[id.String()]
+	if !ok {
+		return sqlc.KnowledgeCrawlSource{}, pgx.ErrNoRows
+	}
+	return source, nil
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeDocumentBySourceURL(_ context.Context, arg sqlc.GetKnowledgeDocumentBySourceURLParams) (sqlc.KnowledgeDocument, error) {
+	for _, doc := range f.documents {
+		if doc.CollectionID == arg.CollectionID && doc.SourceUrl == arg.SourceUrl {
+			return doc, nil
+		}
+	}
+	return sqlc.KnowledgeDocument{}, pgx.ErrNoRows
+}
+
+func (f *fakeKnowledgeQueries) ListDueKnowledgeCrawlSources(_ context.Context, now pgtype.Timestamptz) ([]sqlc.KnowledgeCrawlSource, error) {
+	var rows []sqlc.KnowledgeCrawlSource
+	for _, source := range f.sources {
+		if source.Enabled && source.NextRunAt.Valid && !source.NextRunAt.Time.After(now.Time) {
+			rows = append(rows, source)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeKnowledgeQueries) ListKnowledgeCrawlDocuments(_ context.Context, crawlSourceID pgtype.UUID) ([]sqlc.ListKnowledgeCrawlDocumentsRow, error) {
+	var rows []sqlc.ListKnowledgeCrawlDocumentsRow
+	for _, doc := range f.documents {
+		if doc.CrawlSourceID == crawlSourceID {
+			rows = append(rows, sqlc.ListKnowledgeCrawlDocumentsRow{ID: doc.ID, CollectionID: doc.CollectionID, SourceUrl: doc.SourceUrl})
+		}
+	}
+	return rows, nil
+}
+
+func (*fakeKnowledgeQueries) ListKnowledgeCrawlRuns(context.Context, sqlc.ListKnowledgeCrawlRunsParams) ([]sqlc.KnowledgeCrawlRun, error) {
+	return nil, nil
+}
+
+func (*fakeKnowledgeQueries) ListKnowledgeCrawlSources(context.Context, pgtype.UUID) ([]sqlc.KnowledgeCrawlSource, error) {
+	return nil, nil
+}
+
+func (*fakeKnowledgeQueries) ListUnfinishedKnowledgeCrawlRuns(context.Context) ([]sqlc.KnowledgeCrawlRun, error) {
+	return nil, nil
+}
+
+func (f *fakeKnowledgeQueries) MarkKnowledgeCrawlRunRunning(_ context.Context, id pgtype.UUID) (int64, error) {
+	run, ok := f.runs[id.String()]
+	if !ok || (run.Status != JobPending && run.Status != JobRunning) {
+		return 0, nil
+	}
+	run.Status = JobRunning
+	f.runs[id.String()] = run
+	return 1, nil
+}
+
+func (f *fakeKnowledgeQueries) SetKnowledgeCrawlRunProgress(_ context.Context, arg sqlc.SetKnowledgeCrawlRunProgressParams) error {
+	run := f.runs[arg.ID.String()]
+	run.PagesFetched = arg.PagesFetched
+	run.PagesIngested = arg.PagesIngested
+	run.PagesSkipped = arg.PagesSkipped
+	run.PagesRemoved = arg.PagesRemoved
+	f.runs[arg.ID.String()] = run
+	return nil
+}
+
+func (f *fakeKnowledgeQueries) SetKnowledgeCrawlSourceNextRun(_ context.Context, arg sqlc.SetKnowledgeCrawlSourceNextRunParams) error {
+	source := f.sources[arg.ID.String()]
+	source.NextRunAt = arg.NextRunAt
+	f.sources[arg.ID.String()] = source
+	return nil
+}
+
+func (*fakeKnowledgeQueries) UpdateKnowledgeCrawlSource(context.Context, sqlc.UpdateKnowledgeCrawlSourceParams) (sqlc.KnowledgeCrawlSource, error) {
+	return sqlc.KnowledgeCrawlSource{}, errors.New("not implemented")
+}
+
+func (f *fakeKnowledgeQueries) UpdateKnowledgeDocumentContent(_ context.Context, arg sqlc.UpdateKnowledgeDocumentContentParams) (sqlc.KnowledgeDocument, error) {
+	row, ok := f.documents[arg.ID.String()]
+	if !ok {
+		return sqlc.KnowledgeDocument{}, pgx.ErrNoRows
+	}
+	row.Title = arg.Title
+	row.Content = arg.Content
+	row.ContentHash = arg.ContentHash
+	row.CrawlSourceID = arg.CrawlSourceID
+	f.documents[arg.ID.String()] = row
+	return row, nil
+}
+
+// testSite serves HTML pages from a map of path to body; a missing path is a
+// 404.
+type testSite struct {
+	mu    sync.Mutex
+	pages map[string]string
+	hits  []string
+}
+
+func (s *testSite) set(path, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[path] = body
+}
+
+func (s *testSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hits = append(s.hits, r.URL.Path)
+	body, ok := s.pages[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = fmt.Fprint(w, body)
+}
+
+func page(title, text string, links ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<html><head><title>%s</title></head><body><article><h1>%s</h1><p>%s</p>", title, title, text)
+	for _, link := range links {
+		fmt.Fprintf(&b, `<a href="%s">%s</a> `, link, link)
+	}
+	b.WriteString("</article></body></html>")
+	return b.String()
+}
+
+func noSleep(context.Context, time.Duration) error { return nil }
+
+func TestCrawlerRespectsDepthPatternsAndHost(t *testing.T) {
+	site := &testSite{pages: map[string]string{
+		"/":             page("Home", "Welcome to the docs.", "/guide", "/guide#install", "/private/keys", "https://other.invalid/", "mailto:docs@example.com"),
+		"/guide":        page("Guide", "Install the agent first.", "/guide/deep"),
+		"/guide/deep":   page("Deep", "Too far from the seed."),
+		"/private/keys": page("Keys", "Should never be fetched."),
+	}}
+	server := httptest.NewServer(site)
+	defer server.Close()
+
+	seed, err := parseSeedURL(server.URL + "/")
+	if err != nil {
+		t.Fatalf("parseSeedURL: %v", err)
+	}
+	_, exclude, err := compilePatterns([]string{"/private/"})
+	if err != nil {
+		t.Fatalf("compilePatterns: %v", err)
+	}
+	var slept int
+	c := &crawler{client: server.Client(), sleep: func(context.Context, time.Duration) error {
+		slept++
+		return nil
+	}}
+	var visited []string
+	fetched, err := c.crawl(context.Background(), crawlConfig{
+		seeds:    []*url.URL{seed},
+		maxDepth: 1,
+		maxPages: 10,
+		exclude:  exclude,
+		delay:    time.Second,
+	}, func(p crawledPage) error {
+		visited = append(visited, strings.TrimPrefix(p.URL, server.URL))
+		return nil
+	}, func(string, error) {})
+	if err != nil {
+		t.Fatalf("crawl: %v", err)
+	}
+	sort.Strings(visited)
+	if strings.Join(visited, ",") != "/,/guide" {
+		t.Fatalf("visited = %v, want / and /guide", visited)
+	}
+	if fetched != 2 || slept != 1 {
+		t.Fatalf("fetched = %d, slept = %d; want 2 fetches with one delay between them", fetched, slept)
+	}
+}
+
+func TestCrawlerStopsAtMaxPages(t *testing.T) {
+	site := &testSite{pages: map[string]string{
+		"/":  page("Home", "Index of pages.", "/a", "/b", "/c"),
+		"/a": page("A", "Page A."),
+		"/b": page("B", "Page B."),
+		"/c": page("C", "Page C."),
+	}}
+	server := httptest.NewServer(site)
+	defer server.Close()
+
+	seed, _ := parseSeedURL(server.URL)
+	c := &crawler{client: server.Client(), sleep: noSleep}
+	fetched, err := c.crawl(context.Background(), crawlConfig{seeds: []*url.URL{seed}, maxDepth: 2, maxPages: 2},
+		func(crawledPage) error { return nil }, func(string, error) {})
+	if err != nil {
+		t.Fatalf("crawl: %v", err)
+	}
+	if fetched != 2 || len(site.hits) != 2 {
+		t.Fatalf("fetched = %d, hits = %v; want 2", fetched, site.hits)
+	}
+}
+
+func TestNormalizeCrawlSettingsRejectsInvalidInput(t *testing.T) {
+	valid := crawlSettings{name: "docs", seeds: []string{"https://docs.example.com"}, maxDepth: 1, maxPages: 10, delayMs: 500}
+	if got, err := normalizeCrawlSettings(valid); err != nil || got.seeds[0] != "https://docs.example.com/" {
+		t.Fatalf("normalizeCrawlSettings(valid) = %+v, %v", got, err)
+	}
+	cases := map[string]func(*crawlSettings){
+		"relative seed":  func(s *crawlSettings) { s.seeds = []string{"/docs"} },
+		"ftp seed":       func(s *crawlSettings) { s.seeds = []string{"ftp://docs.example.com"} },
+		"no seeds":       func(s *crawlSettings) { s.seeds = nil },
+		"bad pattern":    func(s *crawlSettings) { s.include = []string{"("} },
+		"deep":           func(s *crawlSettings) { s.maxDepth = maxCrawlDepth + 1 },
+		"no pages":       func(s *crawlSettings) { s.maxPages = 0 },
+		"negative delay": func(s *crawlSettings) { s.delayMs = -1 },
+		"bad schedule":   func(s *crawlSettings) { s.refreshPattern = "every day" },
+	}
+	for name, mutate := range cases {
+		settings := valid
+		mutate(&settings)
+		if _, err := normalizeCrawlSettings(settings); !errors.Is(err, ErrInvalidCrawlSource) {
+			t.Errorf("%s: err = %v, want ErrInvalidCrawlSource", name, err)
+		}
+	}
+}
+
+func TestScheduleDueCrawlsEnqueuesAndAdvances(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	queries.sources[testSourceID] = sqlc.KnowledgeCrawlSource{
+		ID:             db.ParseUUIDOrEmpty(testSourceID),
+		RefreshPattern: "@daily",
+		Enabled:        true,
+		NextRunAt:      pgtype.Timestamptz{Time: now.Add(-time.Minute), Valid: true},
+	}
+	svc := newTestService(queries, &fakeIndex{}, staticEmbed)
+
+	svc.scheduleDueCrawls(context.Background(), queries, now)
+
+	if len(queries.runs) != 1 {
+		t.Fatalf("runs = %d, want 1", len(queries.runs))
+	}
+	next := queries.sources[testSourceID].NextRunAt
+	if !next.Valid || !next.Time.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("next run = %v, want next midnight", next.Time)
+	}
+}
+
+func TestCrawlRunIngestsUpdatesAndRemovesPages(t *testing.T) {
+	site := &testSite{pages: map[string]string{
+		"/":        page("Home", "Product documentation home.", "/install", "/faq"),
+		"/install": page("Install", "Run the installer."),
+		"/faq":     page("FAQ", "Frequently asked questions."),
+	}}
+	server := httptest.NewServer(site)
+	defer server.Close()
+
+	queries := newFakeKnowledgeQueries()
+	seedCollections(queries)
+	queries.sources[testSourceID] = sqlc.KnowledgeCrawlSource{
+		ID:           db.ParseUUIDOrEmpty(testSourceID),
+		CollectionID: db.ParseUUIDOrEmpty(textColID),
+		SeedUrls:     encodeStrings([]string{server.URL + "/"}),
+		MaxDepth:     1,
+		MaxPages:     10,
+		Enabled:      true,
+	}
+	svc := newTestService(queries, &fakeIndex{}, staticEmbed)
+	svc.crawler = &crawler{client: server.Client(), sleep: noSleep}
+
+	runOnce := func() sqlc.KnowledgeCrawlRun {
+		t.Helper()
+		run, err := queries.EnqueueKnowledgeCrawlRun(context.Background(), db.ParseUUIDOrEmpty(testSourceID))
+		if err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		svc.processCrawlRun(context.Background(), run.ID.String())
+		return queries.runs[run.ID.String()]
+	}
+
+	first := runOnce()
+	if first.Status != JobCompleted || first.PagesIngested != 3 {
+		t.Fatalf("first run = %+v, want 3 pages ingested", first)
+	}
+	if len(queries.documents) != 3 {
+		t.Fatalf("documents = %d, want 3", len(queries.documents))
+	}
+
+	// Excluding the FAQ drops it from the crawl; the home page is unchanged.
+	source := queries.sources[testSourceID]
+	source.ExcludePatterns = encodeStrings([]string{"/faq$"})
+	queries.sources[testSourceID] = source
+	site.set("/install", page("Install", "Run the new installer."))
+	second := runOnce()
+	if second.Status != JobCompleted {
+		t.Fatalf("second run = %+v, want completed", second)
+	}
+	if second.PagesIngested != 1 || second.PagesSkipped != 1 || second.PagesRemoved != 1 {
+		t.Fatalf("second run = %+v, want 1 ingested, 1 skipped, 1 removed", second)
+	}
+	for _, doc := range queries.documents {
+		if strings.HasSuffix(doc.SourceUrl, "/faq") {
+			t.Fatalf("document for removed page still stored: %+v", doc)
+		}
+		if strings.HasSuffix(doc.SourceUrl, "/install") && !strings.Contains(doc.Content, "new installer") {
+			t.Fatalf("install page not updated: %q", doc.Content)
+		}
+	}
+}
+
+func TestCrawlRunFailsWithoutRemovingWhenSiteIsDown(t *testing.T) {
+	site := &testSite{pages: map[string]string{}}
+	server := httptest.NewServer(site)
+	defer server.Close()
+
+	queries := newFakeKnowledgeQueries()
+	seedCollections(queries)
+	sourceID := db.ParseUUIDOrEmpty(testSourceID)
+	queries.sources[testSourceID] = sqlc.KnowledgeCrawlSource{
+		ID:           sourceID,
+		CollectionID: db.ParseUUIDOrEmpty(textColID),
+		SeedUrls:     encodeStrings([]string{server.URL + "/"}),
+		MaxPages:     10,
+		Enabled:      true,
+	}
+	queries.documents[testDocID] = sqlc.KnowledgeDocument{
+		ID:            db.ParseUUIDOrEmpty(testDocID),
+		CollectionID:  db.ParseUUIDOrEmpty(textColID),
+		SourceUrl:     server.URL + "/old",
+		CrawlSourceID: sourceID,
+	}
+	svc := newTestService(queries, &fakeIndex{}, staticEmbed)
+	svc.crawler = &crawler{client: server.Client(), sleep: noSleep}
+
+	run, _ := queries.EnqueueKnowledgeCrawlRun(context.Background(), sourceID)
+	svc.processCrawlRun(context.Background(), run.ID.String())
+
+	if got := queries.runs[run.ID.String()]; got.Status != JobFailed {
+		t.Fatalf("run = %+v, want failed", got)
+	}
+	if _, ok := queries.documents[testDocID]; !ok {
+		t.Fatal("existing crawled document removed after a failed crawl")
+	}
+}
+
+func TestOfflineRefusesCrawls(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	seedCollections(queries)
+	queries.sources[testSourceID] = sqlc.KnowledgeCrawlSource{
+		ID:           db.ParseUUIDOrEmpty(testSourceID),
+		CollectionID: db.ParseUUIDOrEmpty(textColID),
+		SeedUrls:     encodeStrings([]string{"https://docs.example.com/"}),
+		MaxDepth:     1,
+		MaxPages:     10,
+		Enabled:      true,
+	}
+	svc := newTestService(queries, &fakeIndex{}, staticEmbed)
+	svc.DisableExternalSources()
+
+	if _, err := svc.CreateCrawlSource(context.Background(), textColID, CreateCrawlSourceRequest{
+		Name:     "Docs",
+		SeedURLs: []string{"https://docs.example.com/"},
+	}); !errors.Is(err, ErrExternalSourcesDisabled) {
+		t.Fatalf("CreateCrawlSource = %v, want ErrExternalSourcesDisabled", err)
+	}
+	if _, err := svc.RunCrawlSource(context.Background(), textColID, testSourceID); !errors.Is(err, ErrExternalSourcesDisabled) {
+		t.Fatalf("RunCrawlSource = %v, want ErrExternalSourcesDisabled", err)
+	}
+	if len(queries.runs) != 0 {
+		t.Fatalf("runs = %d, want none", len(queries.runs))
+	}
+}
@@ -0,0 +1,193 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	sdk "github.com/memohai/twilight-ai/sdk"
+	"github.com/pgvector/pgvector-go"
+
+	pgvectordb "github.com/memohai/memoh/internal/db/pgvector"
+	pgvectorsqlc "github.com/memohai/memoh/internal/db/pgvector/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/models"
+)
+
+// chunkHit is a chunk returned from the vector index.
+type chunkHit struct {
+	DocumentID string
+	Text       string
+	Score      float64
+}
+
+// vectorIndex stores chunk embeddings, one namespace per collection.
+type vectorIndex interface {
+	Upsert(ctx context.Context, teamID, collectionID, documentID, modelID pgtype.UUID, chunks []string, vectors [][]float32) error
+	Search(ctx context.Context, teamID, collectionID, modelID pgtype.UUID, vector []float32, limit int) ([]chunkHit, error)
+	DeleteDocument(ctx context.Context, teamID, collectionID, documentID pgtype.UUID) error
+	DeleteCollection(ctx context.Context, teamID, collectionID pgtype.UUID) error
+}
+
+// embedFunc embeds text with the collection's embedding model.
+type embedFunc func(ctx context.Context, queries dbstore.Queries, modelID pgtype.UUID, text string) ([]float32, error)
+
+type pgvectorIndex struct {
+	store *pgvectordb.Store
+}
+
+func newPGVectorIndex(store *pgvectordb.Store) vectorIndex {
+	if store == nil {
+		return nil
+	}
+	return &pgvectorIndex{store: store}
+}
+
+// withTeamTx binds the pgvector RLS context transaction-locally, mirroring the
+// semantic memory index.
+func (r *pgvectorIndex) withTeamTx(ctx context.Context, teamID pgtype.UUID, fn func(*pgvectorsqlc.Queries) error) error {
+	tx, err := r.store.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("knowledge index: begin team transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, "SELECT set_config('memoh.team_id', $1, true)", teamID.String()); err != nil {
+		return fmt.Errorf("knowledge index: bind team: %w", err)
+	}
+	if err := fn(r.store.Queries().WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pgvectorIndex) Upsert(ctx context.Context, teamID, collectionID, documentID, modelID pgtype.UUID, chunks []string, vectors [][]float32) error {
+	return r.withTeamTx(ctx, teamID, func(q *pgvectorsqlc.Queries) error {
+		for i, chunk := range chunks {
+			err := q.InsertKnowledgeChunkEmbedding(ctx, pgvectorsqlc.InsertKnowledgeChunkEmbeddingParams{
+				TeamID:       teamID,
+				CollectionID: collectionID,
+				DocumentID:   documentID,
+				ChunkIndex:   int32(i), //nolint:gosec // bounded by maxChunks.
+				ModelID:      modelID,
+				Dimensions:   int32(len(vectors[i])), //nolint:gosec // embedding widths are small.
+				Content:      chunk,
+				Embedding:    pgvector.NewVector(vectors[i]),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *pgvectorIndex) Search(ctx context.Context, teamID, collectionID, modelID pgtype.UUID, vector []float32, limit int) ([]chunkHit, error) {
+	var hits []chunkHit
+	err := r.withTeamTx(ctx, teamID, func(q *pgvectorsqlc.Queries) error {
+		rows, err := q.SearchKnowledgeChunkEmbeddings(ctx, pgvectorsqlc.SearchKnowledgeChunkEmbeddingsParams{
+			Embedding:    pgvector.NewVector(vector),
+			TeamID:       teamID,
+			CollectionID: collectionID,
+			ModelID:      modelID,
+			RowLimit:     int32(limit), //nolint:gosec // callers cap limit.
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			hits = append(hits, chunkHit{DocumentID: row.DocumentID.String(), Text: row.Content, Score: row.Score})
+		}
+		return nil
+	})
+	return hits, err
+}
+
+func (r *pgvectorIndex) DeleteDocument(ctx context.Context, teamID, collectionID, documentID pgtype.UUID) error {
+	return r.withTeamTx(ctx, teamID, func(q *pgvectorsqlc.Queries) error {
+		return q.DeleteKnowledgeDocumentEmbeddings(ctx, pgvectorsqlc.DeleteKnowledgeDocumentEmbeddingsParams{
+			TeamID:       teamID,
+			CollectionID: collectionID,
+			DocumentID:   documentID,
+		})
+	})
+}
+
+func (r *pgvectorIndex) DeleteCollection(ctx context.Context, teamID, collectionID pgtype.UUID) error {
+	return r.withTeamTx(ctx, teamID, func(q *pgvectorsqlc.Queries) error {
+		return q.DeleteKnowledgeCollectionEmbeddings(ctx, pgvectorsqlc.DeleteKnowledgeCollectionEmbeddingsParams{
+			TeamID:       teamID,
+			CollectionID: collectionID,
+		})
+	})
+}
+
+// embeddingModel is a resolved, enabled embedding model.
+type embeddingModel struct {
+	clientType string
+	baseURL    string
+	apiKey     string
+	modelID    string
+	dimensions int
+}
+
+func resolveEmbeddingModel(ctx context.Context, queries dbstore.Queries, id pgtype.UUID) (embeddingModel, error) {
+	if queries == nil {
+		return embeddingModel{}, errors.New("queries are required")
+	}
+	row, err := queries.GetModelByID(ctx, id)
+	if err != nil {
+		return embeddingModel{}, fmt.Errorf("%w: %s", ErrInvalidEmbeddingModel, id.String())
+	}
+	if row.Type != "embedding" || !row.Enable || !row.ProviderID.Valid {
+		return embeddingModel{}, fmt.Errorf("%w: %s is not an enabled embedding model", ErrInvalidEmbeddingModel, id.String())
+	}
+	provider, err := queries.GetProviderByID(ctx, row.ProviderID)
+	if err != nil {
+		return embeddingModel{}, fmt.Errorf("get embedding provider: %w", err)
+	}
+	var modelCfg struct {
+		Dimensions *int `json:"dimensions"`
+	}
+	if len(row.Config) > 0 {
+		_ = json.Unmarshal(row.Config, &modelCfg)
+	}
+	var providerCfg map[string]any
+	if len(provider.Config) > 0 {
+		_ = json.Unmarshal(provider.Config, &providerCfg)
+	}
+	baseURL, _ := providerCfg["base_url"].(string)
+	apiKey, _ := providerCfg["api_key"].(string)
+	spec := embeddingModel{
+		clientType: strings.TrimSpace(provider.ClientType),
+		baseURL:    strings.TrimSpace(baseURL),
+		apiKey:     strings.TrimSpace(apiKey),
+		modelID:    strings.TrimSpace(row.ModelID),
+	}
+	if modelCfg.Dimensions != nil {
+		spec.dimensions = *modelCfg.Dimensions
+	}
+	return spec, nil
+}
+
+func embedWithModel(ctx context.Context, queries dbstore.Queries, modelID pgtype.UUID, text string) ([]float32, error) {
+	spec, err := resolveEmbeddingModel(ctx, queries, modelID)
+	if err != nil {
+		return nil, err
+	}
+	model := models.NewSDKEmbeddingModel(spec.clientType, spec.baseURL, spec.apiKey, spec.modelID, models.DefaultProviderRequestTimeout, nil)
+	vec, err := sdk.NewClient().Embed(ctx, text, sdk.WithEmbeddingModel(model))
+	if err != nil {
+		return nil, fmt.Errorf("embed: %w", err)
+	}
+	out := make([]float32, len(vec))
+	for i, v := range vec {
+		out[i] = float32(v)
+	}
+	if spec.dimensions > 0 && len(out) != spec.dimensions {
+		return nil, fmt.Errorf("embedding dimensions = %d, want %d", len(out), spec.dimensions)
+	}
+	return out, nil
+}
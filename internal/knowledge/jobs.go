@@ -0,0 +1,232 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+const (
+	reprocessQueueSize     = 64
+	reprocessSweepInterval = time.Minute
+	reprocessJobHistory    = 20
+)
+
+// Reprocess starts a job that re-processes every document of a collection
+// with its current settings. A job still waiting to start is reused.
+func (s *Service) Reprocess(ctx context.Context, collectionID string) (ReprocessJob, error) {
+	store, err := s.store()
+	if err != nil {
+		return ReprocessJob{}, err
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return ReprocessJob{}, err
+	}
+	return s.enqueueReprocess(ctx, store, collection.ID)
+}
+
+// ListReprocessJobs lists the most recent re-processing jobs of a collection.
+func (s *Service) ListReprocessJobs(ctx context.Context, collectionID string) ([]ReprocessJob, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListKnowledgeReprocessJobs(ctx, sqlc.ListKnowledgeReprocessJobsParams{
+		CollectionID: collection.ID,
+		RowLimit:     reprocessJobHistory,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list knowledge reprocess jobs: %w", err)
+	}
+	items := make([]ReprocessJob, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toReprocessJob(row))
+	}
+	return items, nil
+}
+
+func (s *Service) enqueueReprocess(ctx context.Context, store knowledgeQueries, collectionID pgtype.UUID) (ReprocessJob, error) {
+	row, err := store.EnqueueKnowledgeReprocessJob(ctx, collectionID)
+	if err != nil {
+		return ReprocessJob{}, fmt.Errorf("enqueue knowledge reprocess job: %w", err)
+	}
+	job := toReprocessJob(row)
+	select {
+	case s.queue <- job.ID:
+	default:
+		// The job stays pending in the database; the next sweep runs it.
+		s.logger.Warn("knowledge reprocess queue full, deferring job", slog.String("job_id", job.ID))
+	}
+	return job, nil
+}
+
+// Run processes reprocess jobs, crawl runs, and connector syncs until done is
+// closed. On start and then periodically it sweeps for unfinished work, so
+// jobs interrupted by a restart or dropped from a full queue still run.
+func (s *Service) Run(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		cancel()
+	}()
+	go s.runCrawls(ctx)
+	go s.runConnectorSyncs(ctx)
+
+	s.sweep(ctx)
+	ticker := time.NewTicker(reprocessSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.processJob(ctx, id)
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *Service) sweep(ctx context.Context) {
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("knowledge reprocess sweep failed", slog.Any("error", err))
+		return
+	}
+	rows, err := store.ListUnfinishedKnowledgeReprocessJobs(ctx)
+	if err != nil {
+		s.logger.Warn("list unfinished knowledge reprocess jobs failed", slog.Any("error", err))
+		return
+	}
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return
+		}
+		s.processJob(ctx, row.ID.String())
+	}
+}
+
+// processJob runs one job. A job that is already finished (it was both
+// queued and picked up by a sweep) is skipped.
+func (s *Service) processJob(ctx context.Context, jobID string) {
+	store, err := s.store()
+	if err != nil {
+		s.logger.Error("knowledge reprocess failed", slog.String("job_id", jobID), slog.Any("error", err))
+		return
+	}
+	pgJobID, err := db.ParseUUID(jobID)
+	if err != nil {
+		return
+	}
+	job, err := store.GetKnowledgeReprocessJob(ctx, pgJobID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Warn("load knowledge reprocess job failed", slog.String("job_id", jobID), slog.Any("error", err))
+		}
+		return
+	}
+	if job.Status != JobPending && job.Status != JobRunning {
+		return
+	}
+	ran, err := s.reprocessCollection(ctx, store, job)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down: leave the job running so the next start retries it.
+			return
+		}
+		s.logger.Error("knowledge reprocess failed", slog.String("job_id", jobID), slog.Any("error", err))
+		if failErr := store.FailKnowledgeReprocessJob(context.WithoutCancel(ctx), sqlc.FailKnowledgeReprocessJobParams{
+			ID:    job.ID,
+			Error: err.Error(),
+		}); failErr != nil {
+			s.logger.Warn("mark knowledge reprocess failed", slog.String("job_id", jobID), slog.Any("error", failErr))
+		}
+		return
+	}
+	if !ran {
+		return
+	}
+	if err := store.CompleteKnowledgeReprocessJob(ctx, job.ID); err != nil {
+		s.logger.Error("complete knowledge reprocess failed", slog.String("job_id", jobID), slog.Any("error", err))
+	}
+}
+
+// reprocessCollection reports false when another run already finished the
+// job.
+func (s *Service) reprocessCollection(ctx context.Context, store knowledgeQueries, job sqlc.KnowledgeReprocessJob) (bool, error) {
+	// Load settings when the job starts, not when it was queued, so the job
+	// applies the latest change.
+	collection, err := store.GetKnowledgeCollection(ctx, job.CollectionID)
+	if err != nil {
+		return false, fmt.Errorf("load collection: %w", err)
+	}
+	documents, err := store.ListKnowledgeDocuments(ctx, collection.ID)
+	if err != nil {
+		return false, fmt.Errorf("list documents: %w", err)
+	}
+	claimed, err := store.MarkKnowledgeReprocessJobRunning(ctx, sqlc.MarkKnowledgeReprocessJobRunningParams{
+		DocumentsTotal: int32(len(documents)), //nolint:gosec // document counts fit in int32.
+		ID:             job.ID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("mark job running: %w", err)
+	}
+	if claimed == 0 {
+		return false, nil
+	}
+	for i, doc := range documents {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		row, err := store.GetKnowledgeDocument(ctx, sqlc.GetKnowledgeDocumentParams{CollectionID: collection.ID, ID: doc.ID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Deleted while the job ran.
+				continue
+			}
+			return false, fmt.Errorf("load document %s: %w", doc.ID.String(), err)
+		}
+		if _, err := s.processDocument(ctx, store, collection, row, true); err != nil {
+			return false, fmt.Errorf("process document %s: %w", doc.ID.String(), err)
+		}
+		if err := store.SetKnowledgeReprocessJobProgress(ctx, sqlc.SetKnowledgeReprocessJobProgressParams{
+			DocumentsDone: int32(i + 1), //nolint:gosec // bounded by DocumentsTotal.
+			ID:            job.ID,
+		}); err != nil {
+			s.logger.Warn("record knowledge reprocess progress failed", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+		}
+	}
+	return true, nil
+}
+
+func toReprocessJob(row sqlc.KnowledgeReprocessJob) ReprocessJob {
+	job := ReprocessJob{
+		ID:             row.ID.String(),
+		CollectionID:   row.CollectionID.String(),
+		Status:         row.Status,
+		Error:          row.Error,
+		DocumentsTotal: int(row.DocumentsTotal),
+		DocumentsDone:  int(row.DocumentsDone),
+		CreatedAt:      db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:      db.TimeFromPg(row.UpdatedAt),
+	}
+	if row.CompletedAt.Valid {
+		completed := db.TimeFromPg(row.CompletedAt)
+		job.CompletedAt = &completed
+	}
+	return job
+}
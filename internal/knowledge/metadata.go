@@ -0,0 +1,135 @@
+package knowledge
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	maxMetadataKeys     = 32
+	maxMetadataValueLen = 512
+	// metadataTitleKey holds a title derived from the document's first
+	// heading. It never overrides a title given when the document was added.
+	metadataTitleKey = "title"
+)
+
+// extractMetadata applies the collection's extraction settings to content.
+// It returns the text to index and the extracted metadata, which may be nil.
+func extractMetadata(content string, settings MetadataExtraction) (string, map[string]string) {
+	var metadata map[string]string
+	text := content
+	if settings.FrontMatter {
+		var front map[string]string
+		text, front = splitFrontMatter(text)
+		metadata = front
+	}
+	if settings.TitleFromHeading {
+		if title := firstHeading(text); title != "" {
+			if metadata == nil {
+				metadata = map[string]string{}
+			}
+			if _, ok := metadata[metadataTitleKey]; !ok {
+				metadata[metadataTitleKey] = title
+			}
+		}
+	}
+	return text, metadata
+}
+
+// splitFrontMatter parses a leading block delimited by "---" lines. Lines
+// that are not "key: value" pairs are ignored; content without a closed
+// block is returned unchanged.
+func splitFrontMatter(content string) (string, map[string]string) {
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return content, nil
+	}
+	rest := normalized[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return content, nil
+	}
+	block := rest[:end]
+	body := strings.TrimPrefix(rest[end+len("\n---"):], "\n")
+	metadata := map[string]string{}
+	for _, line := range strings.Split(block, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if key == "" || value == "" || len(metadata) >= maxMetadataKeys {
+			continue
+		}
+		if len(value) > maxMetadataValueLen {
+			value = value[:maxMetadataValueLen]
+		}
+		metadata[key] = value
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	return body, metadata
+}
+
+func firstHeading(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		title := strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if title != "" {
+			return title
+		}
+	}
+	return ""
+}
+
+// documentTitle is the title shown for a document: the title it was added
+// with, else one extracted from its content.
+func documentTitle(title string, metadata map[string]string) string {
+	if title = strings.TrimSpace(title); title != "" {
+		return title
+	}
+	return metadata[metadataTitleKey]
+}
+
+func encodeMetadata(metadata map[string]string) []byte {
+	if len(metadata) == 0 {
+		return []byte("{}")
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return []byte("{}")
+	}
+	return data
+}
+
+func decodeMetadata(data []byte) map[string]string {
+	if len(data) == 0 {
+		return nil
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(data, &metadata); err != nil || len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+func encodeExtraction(settings MetadataExtraction) []byte {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return []byte("{}")
+	}
+	return data
+}
+
+func decodeExtraction(data []byte) MetadataExtraction {
+	var settings MetadataExtraction
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &settings)
+	}
+	return settings
+}
@@ -0,0 +1,833 @@
+// Package knowledge manages knowledge collections: named document sets that
+// bots can be attached to. Collections are retrieved at chat time next to
+// personal memory but are stored and managed independently of it.
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	pgvectordb "github.com/memohai/memoh/internal/db/pgvector"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/oauthclients"
+)
+
+const (
+	// DefaultRetrieveLimit is how many passages Retrieve returns when the
+	// caller does not ask for a specific number.
+	DefaultRetrieveLimit = 5
+	maxRetrieveLimit     = 20
+	maxNameLength        = 128
+)
+
+var (
+	ErrCollectionNotFound    = errors.New("knowledge collection not found")
+	ErrDocumentNotFound      = errors.New("knowledge document not found")
+	ErrCollectionNameTaken   = errors.New("knowledge collection name already exists")
+	ErrNameRequired          = errors.New("collection name is required")
+	ErrContentRequired       = errors.New("document content is required")
+	ErrInvalidEmbeddingModel = errors.New("invalid embedding model")
+	ErrInvalidChunking       = errors.New("invalid chunk settings")
+	ErrJobNotFound           = errors.New("knowledge reprocess job not found")
+	ErrCrawlSourceNotFound   = errors.New("knowledge crawl source not found")
+	ErrInvalidCrawlSource    = errors.New("invalid crawl source")
+	ErrConnectorNotFound     = errors.New("knowledge connector not found")
+	ErrInvalidConnector      = errors.New("invalid connector")
+	ErrConnectorUnavailable  = errors.New("connector OAuth client is not configured")
+	ErrConnectorUnauthorized = errors.New("connector is not authorized")
+	ErrInvalidOAuthState     = errors.New("invalid or expired OAuth state")
+	// ErrExternalSourcesDisabled is returned for crawls and connector syncs
+	// while offline mode keeps the deployment off the public internet.
+	ErrExternalSourcesDisabled = errors.New("external knowledge sources are disabled in offline mode")
+)
+
+type knowledgeQueries interface {
+	AttachBotKnowledgeCollection(ctx context.Context, arg sqlc.AttachBotKnowledgeCollectionParams) error
+	CompleteKnowledgeConnectorSync(ctx context.Context, id pgtype.UUID) error
+	CompleteKnowledgeCrawlRun(ctx context.Context, id pgtype.UUID) error
+	CompleteKnowledgeReprocessJob(ctx context.Context, id pgtype.UUID) error
+	CreateKnowledgeCollection(ctx context.Context, arg sqlc.CreateKnowledgeCollectionParams) (sqlc.KnowledgeCollection, error)
+	CreateKnowledgeConnector(ctx context.Context, arg sqlc.CreateKnowledgeConnectorParams) (sqlc.KnowledgeConnector, error)
+	CreateKnowledgeCrawlSource(ctx context.Context, arg sqlc.CreateKnowledgeCrawlSourceParams) (sqlc.KnowledgeCrawlSource, error)
+	CreateKnowledgeDocument(ctx context.Context, arg sqlc.CreateKnowledgeDocumentParams) (sqlc.KnowledgeDocument, error)
+	DeleteKnowledgeCollection(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteKnowledgeConnector(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteKnowledgeConnectorToken(ctx context.Context, connectorID pgtype.UUID) (int64, error)
+	DeleteKnowledgeCrawlSource(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteKnowledgeDocument(ctx context.Context, arg sqlc.DeleteKnowledgeDocumentParams) (int64, error)
+	DetachBotKnowledgeCollection(ctx context.Context, arg sqlc.DetachBotKnowledgeCollectionParams) (int64, error)
+	EnqueueKnowledgeConnectorSync(ctx context.Context, arg sqlc.EnqueueKnowledgeConnectorSyncParams) (sqlc.KnowledgeConnectorSync, error)
+	EnqueueKnowledgeCrawlRun(ctx context.Context, sourceID pgtype.UUID) (sqlc.KnowledgeCrawlRun, error)
+	EnqueueKnowledgeReprocessJob(ctx context.Context, collectionID pgtype.UUID) (sqlc.KnowledgeReprocessJob, error)
+	FailKnowledgeConnectorSync(ctx context.Context, arg sqlc.FailKnowledgeConnectorSyncParams) error
+	FailKnowledgeCrawlRun(ctx context.Context, arg sqlc.FailKnowledgeCrawlRunParams) error
+	FailKnowledgeReprocessJob(ctx context.Context, arg sqlc.FailKnowledgeReprocessJobParams) error
+	GetKnowledgeCollection(ctx context.Context, id pgtype.UUID) (sqlc.KnowledgeCollection, error)
+	GetKnowledgeConnector(ctx context.Context, id pgtype.UUID) (sqlc.KnowledgeConnector, error)
+	GetKnowledgeConnectorSync(ctx context.Context, id pgtype.UUID) (sqlc.KnowledgeConnectorSync, error)
+	GetKnowledgeConnectorToken(ctx context.Context, connectorID pgtype.UUID) (sqlc.KnowledgeConnectorToken, error)
+	GetKnowledgeConnectorTokenByState(ctx context.Context, state string) (sqlc.KnowledgeConnectorToken, error)
+	GetKnowledgeCrawlRun(ctx context.Context, id pgtype.UUID) (sqlc.KnowledgeCrawlRun, error)
+	GetKnowledgeCrawlSource(ctx context.Context, id pgtype.UUID) (sqlc.KnowledgeCrawlSource, error)
+	GetKnowledgeDocument(ctx context.Context, arg sqlc.GetKnowledgeDocumentParams) (sqlc.KnowledgeDocument, error)
+	GetKnowledgeDocumentByExternalID(ctx context.Context, arg sqlc.GetKnowledgeDocumentByExternalIDParams) (sqlc.KnowledgeDocument, error)
+	GetKnowledgeDocumentBySourceURL(ctx context.Context, arg sqlc.GetKnowledgeDocumentBySourceURLParams) (sqlc.KnowledgeDocument, error)
+	GetKnowledgeReprocessJob(ctx context.Context, id pgtype.UUID) (sqlc.KnowledgeReprocessJob, error)
+	ListBotKnowledgeCollections(ctx context.Context, botID pgtype.UUID) ([]sqlc.KnowledgeCollection, error)
+	ListDueKnowledgeConnectors(ctx context.Context, now pgtype.Timestamptz) ([]sqlc.KnowledgeConnector, error)
+	ListDueKnowledgeCrawlSources(ctx context.Context, now pgtype.Timestamptz) ([]sqlc.KnowledgeCrawlSource, error)
+	ListKnowledgeCollections(ctx context.Context) ([]sqlc.KnowledgeCollection, error)
+	ListKnowledgeConnectorDocuments(ctx context.Context, connectorID pgtype.UUID) ([]sqlc.ListKnowledgeConnectorDocumentsRow, error)
+	ListKnowledgeConnectorSyncs(ctx context.Context, arg sqlc.ListKnowledgeConnectorSyncsParams) ([]sqlc.KnowledgeConnectorSync, error)
+	ListKnowledgeConnectors(ctx context.Context, collectionID pgtype.UUID) ([]sqlc.KnowledgeConnector, error)
+	ListKnowledgeCrawlDocuments(ctx context.Context, crawlSourceID pgtype.UUID) ([]sqlc.ListKnowledgeCrawlDocumentsRow, error)
+	ListKnowledgeCrawlRuns(ctx context.Context, arg sqlc.ListKnowledgeCrawlRunsParams) ([]sqlc.KnowledgeCrawlRun, error)
+	ListKnowledgeCrawlSources(ctx context.Context, collectionID pgtype.UUID) ([]sqlc.KnowledgeCrawlSource, error)
+	ListKnowledgeDocuments(ctx context.Context, collectionID pgtype.UUID) ([]sqlc.ListKnowledgeDocumentsRow, error)
+	ListKnowledgeReprocessJobs(ctx context.Context, arg sqlc.ListKnowledgeReprocessJobsParams) ([]sqlc.KnowledgeReprocessJob, error)
+	ListUnfinishedKnowledgeConnectorSyncs(ctx context.Context) ([]sqlc.KnowledgeConnectorSync, error)
+	ListUnfinishedKnowledgeCrawlRuns(ctx context.Context) ([]sqlc.KnowledgeCrawlRun, error)
+	ListUnfinishedKnowledgeReprocessJobs(ctx context.Context) ([]sqlc.KnowledgeReprocessJob, error)
+	MarkKnowledgeConnectorSyncRunning(ctx context.Context, id pgtype.UUID) (int64, error)
+	MarkKnowledgeCrawlRunRunning(ctx context.Context, id pgtype.UUID) (int64, error)
+	MarkKnowledgeReprocessJobRunning(ctx context.Context, arg sqlc.MarkKnowledgeReprocessJobRunningParams) (int64, error)
+	SearchKnowledgeDocumentsText(ctx context.Context, arg sqlc.SearchKnowledgeDocumentsTextParams) ([]sqlc.SearchKnowledgeDocumentsTextRow, error)
+	SetKnowledgeConnectorCursor(ctx context.Context, arg sqlc.SetKnowledgeConnectorCursorParams) error
+	SetKnowledgeConnectorNextSync(ctx context.Context, arg sqlc.SetKnowledgeConnectorNextSyncParams) error
+	SetKnowledgeConnectorOAuthState(ctx context.Context, arg sqlc.SetKnowledgeConnectorOAuthStateParams) error
+	SetKnowledgeConnectorSyncProgress(ctx context.Context, arg sqlc.SetKnowledgeConnectorSyncProgressParams) error
+	SetKnowledgeCrawlRunProgress(ctx context.Context, arg sqlc.SetKnowledgeCrawlRunProgressParams) error
+	SetKnowledgeCrawlSourceNextRun(ctx context.Context, arg sqlc.SetKnowledgeCrawlSourceNextRunParams) error
+	SetKnowledgeDocumentProcessing(ctx context.Context, arg sqlc.SetKnowledgeDocumentProcessingParams) error
+	SetKnowledgeReprocessJobProgress(ctx context.Context, arg sqlc.SetKnowledgeReprocessJobProgressParams) error
+	UpdateKnowledgeCollection(ctx context.Context, arg sqlc.UpdateKnowledgeCollectionParams) (sqlc.KnowledgeCollection, error)
+	UpdateKnowledgeConnector(ctx context.Context, arg sqlc.UpdateKnowledgeConnectorParams) (sqlc.KnowledgeConnector, error)
+	UpdateKnowledgeConnectorDocument(ctx context.Context, arg sqlc.UpdateKnowledgeConnectorDocumentParams) (sqlc.KnowledgeDocument, error)
+	UpdateKnowledgeCrawlSource(ctx context.Context, arg sqlc.UpdateKnowledgeCrawlSourceParams) (sqlc.KnowledgeCrawlSource, error)
+	UpdateKnowledgeDocumentContent(ctx context.Context, arg sqlc.UpdateKnowledgeDocumentContentParams) (sqlc.KnowledgeDocument, error)
+	UpsertKnowledgeConnectorToken(ctx context.Context, arg sqlc.UpsertKnowledgeConnectorTokenParams) (sqlc.KnowledgeConnectorToken, error)
+}
+
+// Service manages knowledge collections and retrieves passages from them.
+type Service struct {
+	queries dbstore.Queries
+	index   vectorIndex
+	embed   embedFunc
+	logger  *slog.Logger
+	queue   chan string
+	crawler *crawler
+	crawls  chan string
+
+	adapters     map[string]ConnectorAdapter
+	oauthClients oauthclients.Resolver
+	httpClient   *http.Client
+	syncs        chan string
+	// externalDisabled refuses work that fetches from outside the
+	// deployment. It is set once at startup.
+	externalDisabled bool
+}
+
+// NewService creates a knowledge service. vectors may be nil, in which case
+// documents are stored without embeddings and retrieval uses full-text search.
+func NewService(log *slog.Logger, queries dbstore.Queries, vectors *pgvectordb.Store) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		index:   newPGVectorIndex(vectors),
+		embed:   embedWithModel,
+		logger:  log.With(slog.String("service", "knowledge")),
+		queue:   make(chan string, reprocessQueueSize),
+		crawler: newCrawler(),
+		crawls:  make(chan string, crawlQueueSize),
+
+		adapters:   map[string]ConnectorAdapter{},
+		httpClient: &http.Client{Timeout: connectorRequestTimeout},
+		syncs:      make(chan string, connectorQueueSize),
+	}
+}
+
+// DisableExternalSources refuses website crawls and connector setup and
+// syncs, leaving existing documents searchable. Offline deployments call it before the workers start.
+func (s *Service) DisableExternalSources() {
+	s.externalDisabled = true
+}
+
+func (s *Service) store() (knowledgeQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("knowledge service not configured")
+	}
+	store, ok := s.queries.(knowledgeQueries)
+	if !ok {
+		return nil, errors.New("knowledge queries not supported by store")
+	}
+	return store, nil
+}
+
+// CreateCollection creates a collection. The embedding model is optional;
+// without one the collection is searched by full text only.
+func (s *Service) CreateCollection(ctx context.Context, req CreateCollectionRequest) (Collection, error) {
+	store, err := s.store()
+	if err != nil {
+		return Collection{}, err
+	}
+	name, err := normalizeName(req.Name)
+	if err != nil {
+		return Collection{}, err
+	}
+	modelID, err := s.parseEmbeddingModel(ctx, req.EmbeddingModelID)
+	if err != nil {
+		return Collection{}, err
+	}
+	chunkSize := req.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if err := validateChunking(chunkSize, req.ChunkOverlap); err != nil {
+		return Collection{}, err
+	}
+	row, err := store.CreateKnowledgeCollection(ctx, sqlc.CreateKnowledgeCollectionParams{
+		Name:               name,
+		Description:        strings.TrimSpace(req.Description),
+		EmbeddingModelID:   modelID,
+		ChunkSize:          int32(chunkSize),        //nolint:gosec // validated by validateChunking.
+		ChunkOverlap:       int32(req.ChunkOverlap), //nolint:gosec // validated by validateChunking.
+		MetadataExtraction: encodeExtraction(req.MetadataExtraction),
+	})
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return Collection{}, ErrCollectionNameTaken
+		}
+		return Collection{}, fmt.Errorf("create knowledge collection: %w", err)
+	}
+	return toCollection(row), nil
+}
+
+// ListCollections lists every collection in the current team.
+func (s *Service) ListCollections(ctx context.Context) ([]Collection, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListKnowledgeCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list knowledge collections: %w", err)
+	}
+	return toCollections(rows), nil
+}
+
+// GetCollection returns one collection.
+func (s *Service) GetCollection(ctx context.Context, id string) (Collection, error) {
+	store, err := s.store()
+	if err != nil {
+		return Collection{}, err
+	}
+	row, err := s.getCollection(ctx, store, id)
+	if err != nil {
+		return Collection{}, err
+	}
+	return toCollection(row), nil
+}
+
+// UpdateCollection changes a collection. When the embedding model, chunk
+// settings, or metadata extraction change, every document is re-processed by
+// a background job, which is returned with the collection.
+func (s *Service) UpdateCollection(ctx context.Context, id string, req UpdateCollectionRequest) (UpdateCollectionResponse, error) {
+	store, err := s.store()
+	if err != nil {
+		return UpdateCollectionResponse{}, err
+	}
+	current, err := s.getCollection(ctx, store, id)
+	if err != nil {
+		return UpdateCollectionResponse{}, err
+	}
+	params := sqlc.UpdateKnowledgeCollectionParams{
+		Name:               current.Name,
+		Description:        current.Description,
+		EmbeddingModelID:   current.EmbeddingModelID,
+		ChunkSize:          current.ChunkSize,
+		ChunkOverlap:       current.ChunkOverlap,
+		MetadataExtraction: current.MetadataExtraction,
+		ID:                 current.ID,
+	}
+	if req.Name != nil {
+		if params.Name, err = normalizeName(*req.Name); err != nil {
+			return UpdateCollectionResponse{}, err
+		}
+	}
+	if req.Description != nil {
+		params.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.EmbeddingModelID != nil {
+		if params.EmbeddingModelID, err = s.parseEmbeddingModel(ctx, *req.EmbeddingModelID); err != nil {
+			return UpdateCollectionResponse{}, err
+		}
+	}
+	chunkSize, chunkOverlap := int(current.ChunkSize), int(current.ChunkOverlap)
+	if req.ChunkSize != nil {
+		chunkSize = *req.ChunkSize
+	}
+	if req.ChunkOverlap != nil {
+		chunkOverlap = *req.ChunkOverlap
+	}
+	if err := validateChunking(chunkSize, chunkOverlap); err != nil {
+		return UpdateCollectionResponse{}, err
+	}
+	params.ChunkSize = int32(chunkSize)       //nolint:gosec // validated by validateChunking.
+	params.ChunkOverlap = int32(chunkOverlap) //nolint:gosec // validated by validateChunking.
+	extraction := decodeExtraction(current.MetadataExtraction)
+	if req.MetadataExtraction != nil {
+		params.MetadataExtraction = encodeExtraction(*req.MetadataExtraction)
+	}
+	reprocess := params.EmbeddingModelID != current.EmbeddingModelID ||
+		params.ChunkSize != current.ChunkSize ||
+		params.ChunkOverlap != current.ChunkOverlap ||
+		(req.MetadataExtraction != nil && *req.MetadataExtraction != extraction)
+
+	row, err := store.UpdateKnowledgeCollection(ctx, params)
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return UpdateCollectionResponse{}, ErrCollectionNameTaken
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return UpdateCollectionResponse{}, ErrCollectionNotFound
+		}
+		return UpdateCollectionResponse{}, fmt.Errorf("update knowledge collection: %w", err)
+	}
+	resp := UpdateCollectionResponse{Collection: toCollection(row)}
+	if reprocess {
+		job, err := s.enqueueReprocess(ctx, store, row.ID)
+		if err != nil {
+			return UpdateCollectionResponse{}, err
+		}
+		resp.ReprocessJob = &job
+	}
+	return resp, nil
+}
+
+// DeleteCollection deletes a collection, its documents, bot attachments, and
+// its vectors.
+func (s *Service) DeleteCollection(ctx context.Context, id string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	current, err := s.getCollection(ctx, store, id)
+	if err != nil {
+		return err
+	}
+	n, err := store.DeleteKnowledgeCollection(ctx, current.ID)
+	if err != nil {
+		return fmt.Errorf("delete knowledge collection: %w", err)
+	}
+	if n == 0 {
+		return ErrCollectionNotFound
+	}
+	if s.index != nil {
+		if err := s.index.DeleteCollection(ctx, current.TeamID, current.ID); err != nil {
+			s.logger.Warn("delete knowledge collection vectors failed",
+				slog.String("collection_id", current.ID.String()), slog.Any("error", err))
+		}
+	}
+	return nil
+}
+
+// AddDocument stores a document and processes it with the collection's
+// settings: metadata is extracted, the text is chunked, and, when the
+// collection has an embedding model and a vector store is configured, the
+// chunks are indexed. A document whose indexing fails is removed again so the
+// collection never holds half-indexed content.
+func (s *Service) AddDocument(ctx context.Context, collectionID string, req AddDocumentRequest) (Document, error) {
+	store, err := s.store()
+	if err != nil {
+		return Document{}, err
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return Document{}, ErrContentRequired
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return Document{}, err
+	}
+	sum := sha256.Sum256([]byte(content))
+	row, err := store.CreateKnowledgeDocument(ctx, sqlc.CreateKnowledgeDocumentParams{
+		CollectionID: collection.ID,
+		Title:        strings.TrimSpace(req.Title),
+		Content:      content,
+		ContentHash:  hex.EncodeToString(sum[:]),
+		Metadata:     encodeMetadata(nil),
+		SourceUrl:    strings.TrimSpace(req.SourceURL),
+	})
+	if err != nil {
+		return Document{}, fmt.Errorf("create knowledge document: %w", err)
+	}
+	row, err = s.processDocument(ctx, store, collection, row, false)
+	if err != nil {
+		if _, delErr := store.DeleteKnowledgeDocument(ctx, sqlc.DeleteKnowledgeDocumentParams{CollectionID: collection.ID, ID: row.ID}); delErr != nil {
+			s.logger.Warn("remove unindexed knowledge document failed",
+				slog.String("document_id", row.ID.String()), slog.Any("error", delErr))
+		}
+		return Document{}, fmt.Errorf("index knowledge document: %w", err)
+	}
+	return toDocument(row), nil
+}
+
+// processDocument extracts metadata, chunks, and indexes one document with
+// the collection's current settings. With replace set, existing vectors of
+// the document are dropped first, whatever model produced them.
+func (s *Service) processDocument(ctx context.Context, store knowledgeQueries, collection sqlc.KnowledgeCollection, row sqlc.KnowledgeDocument, replace bool) (sqlc.KnowledgeDocument, error) {
+	text, metadata := extractMetadata(row.Content, decodeExtraction(collection.MetadataExtraction))
+	chunks := chunkText(text, int(collection.ChunkSize), int(collection.ChunkOverlap))
+	if s.index != nil && replace {
+		if err := s.index.DeleteDocument(ctx, collection.TeamID, collection.ID, row.ID); err != nil {
+			return row, fmt.Errorf("delete document vectors: %w", err)
+		}
+	}
+	if s.index != nil && collection.EmbeddingModelID.Valid {
+		if err := s.indexChunks(ctx, collection, row.ID, chunks); err != nil {
+			return row, err
+		}
+	}
+	row.ChunkCount = int32(len(chunks)) //nolint:gosec // bounded by maxChunks.
+	row.Metadata = encodeMetadata(metadata)
+	if err := store.SetKnowledgeDocumentProcessing(ctx, sqlc.SetKnowledgeDocumentProcessingParams{
+		ChunkCount: row.ChunkCount,
+		Metadata:   row.Metadata,
+		ID:         row.ID,
+	}); err != nil {
+		return row, fmt.Errorf("record document processing: %w", err)
+	}
+	return row, nil
+}
+
+func (s *Service) indexChunks(ctx context.Context, collection sqlc.KnowledgeCollection, documentID pgtype.UUID, chunks []string) error {
+	vectors := make([][]float32, 0, len(chunks))
+	for _, chunk := range chunks {
+		vec, err := s.embed(ctx, s.queries, collection.EmbeddingModelID, chunk)
+		if err != nil {
+			return err
+		}
+		vectors = append(vectors, vec)
+	}
+	return s.index.Upsert(ctx, collection.TeamID, collection.ID, documentID, collection.EmbeddingModelID, chunks, vectors)
+}
+
+// ListDocuments lists the documents of a collection without their content.
+func (s *Service) ListDocuments(ctx context.Context, collectionID string) ([]Document, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListKnowledgeDocuments(ctx, collection.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list knowledge documents: %w", err)
+	}
+	items := make([]Document, 0, len(rows))
+	for _, row := range rows {
+		metadata := decodeMetadata(row.Metadata)
+		items = append(items, Document{
+			ID:            row.ID.String(),
+			CollectionID:  row.CollectionID.String(),
+			Title:         documentTitle(row.Title, metadata),
+			ContentHash:   row.ContentHash,
+			ContentLength: row.ContentLength,
+			ChunkCount:    int(row.ChunkCount),
+			Metadata:      metadata,
+			SourceURL:     row.SourceUrl,
+			CreatedAt:     db.TimeFromPg(row.CreatedAt),
+			UpdatedAt:     db.TimeFromPg(row.UpdatedAt),
+		})
+	}
+	return items, nil
+}
+
+// GetDocument returns one document with its content.
+func (s *Service) GetDocument(ctx context.Context, collectionID, documentID string) (Document, error) {
+	store, err := s.store()
+	if err != nil {
+		return Document{}, err
+	}
+	pgCollectionID, pgDocumentID, err := parseDocumentIDs(collectionID, documentID)
+	if err != nil {
+		return Document{}, err
+	}
+	row, err := store.GetKnowledgeDocument(ctx, sqlc.GetKnowledgeDocumentParams{CollectionID: pgCollectionID, ID: pgDocumentID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Document{}, ErrDocumentNotFound
+		}
+		return Document{}, fmt.Errorf("get knowledge document: %w", err)
+	}
+	return toDocument(row), nil
+}
+
+// DeleteDocument deletes a document and its vectors.
+func (s *Service) DeleteDocument(ctx context.Context, collectionID, documentID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return err
+	}
+	pgDocumentID, err := db.ParseUUID(documentID)
+	if err != nil {
+		return ErrDocumentNotFound
+	}
+	n, err := store.DeleteKnowledgeDocument(ctx, sqlc.DeleteKnowledgeDocumentParams{CollectionID: collection.ID, ID: pgDocumentID})
+	if err != nil {
+		return fmt.Errorf("delete knowledge document: %w", err)
+	}
+	if n == 0 {
+		return ErrDocumentNotFound
+	}
+	if s.index != nil {
+		if err := s.index.DeleteDocument(ctx, collection.TeamID, collection.ID, pgDocumentID); err != nil {
+			s.logger.Warn("delete knowledge document vectors failed",
+				slog.String("document_id", documentID), slog.Any("error", err))
+		}
+	}
+	return nil
+}
+
+// AttachToBot makes a collection available to a bot at chat time. Attaching
+// twice is a no-op.
+func (s *Service) AttachToBot(ctx context.Context, botID, collectionID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return fmt.Errorf("invalid bot id: %w", err)
+	}
+	collection, err := s.getCollection(ctx, store, collectionID)
+	if err != nil {
+		return err
+	}
+	if err := store.AttachBotKnowledgeCollection(ctx, sqlc.AttachBotKnowledgeCollectionParams{BotID: pgBotID, CollectionID: collection.ID}); err != nil {
+		return fmt.Errorf("attach knowledge collection: %w", err)
+	}
+	return nil
+}
+
+// DetachFromBot removes a collection from a bot.
+func (s *Service) DetachFromBot(ctx context.Context, botID, collectionID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return fmt.Errorf("invalid bot id: %w", err)
+	}
+	pgCollectionID, err := db.ParseUUID(collectionID)
+	if err != nil {
+		return ErrCollectionNotFound
+	}
+	n, err := store.DetachBotKnowledgeCollection(ctx, sqlc.DetachBotKnowledgeCollectionParams{BotID: pgBotID, CollectionID: pgCollectionID})
+	if err != nil {
+		return fmt.Errorf("detach knowledge collection: %w", err)
+	}
+	if n == 0 {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
+
+// ListBotCollections lists the collections attached to a bot.
+func (s *Service) ListBotCollections(ctx context.Context, botID string) ([]Collection, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot id: %w", err)
+	}
+	rows, err := store.ListBotKnowledgeCollections(ctx, pgBotID)
+	if err != nil {
+		return nil, fmt.Errorf("list bot knowledge collections: %w", err)
+	}
+	return toCollections(rows), nil
+}
+
+// Retrieve returns the passages most relevant to query across every
+// collection attached to the bot. Collections with an embedding model are
+// searched by vector similarity; others, or any collection whose vector
+// search fails, fall back to full-text search. Failures in one collection do
+// not hide results from the others.
+func (s *Service) Retrieve(ctx context.Context, botID, query string, limit int) ([]Passage, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = DefaultRetrieveLimit
+	}
+	limit = min(limit, maxRetrieveLimit)
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot id: %w", err)
+	}
+	collections, err := store.ListBotKnowledgeCollections(ctx, pgBotID)
+	if err != nil {
+		return nil, fmt.Errorf("list bot knowledge collections: %w", err)
+	}
+	var passages []Passage
+	for _, collection := range collections {
+		found, err := s.searchVectors(ctx, store, collection, query, limit)
+		if err != nil {
+			s.logger.Warn("knowledge vector search failed, using full-text search",
+				slog.String("collection_id", collection.ID.String()), slog.Any("error", err))
+		}
+		if found == nil {
+			found, err = s.searchText(ctx, store, collection, query, limit)
+			if err != nil {
+				s.logger.Warn("knowledge text search failed",
+					slog.String("collection_id", collection.ID.String()), slog.Any("error", err))
+				continue
+			}
+		}
+		passages = append(passages, found...)
+	}
+	sort.SliceStable(passages, func(i, j int) bool { return passages[i].Score > passages[j].Score })
+	if len(passages) > limit {
+		passages = passages[:limit]
+	}
+	return passages, nil
+}
+
+// searchVectors returns nil passages when the collection is not vector
+// indexed, so the caller falls back to full-text search.
+func (s *Service) searchVectors(ctx context.Context, store knowledgeQueries, collection sqlc.KnowledgeCollection, query string, limit int) ([]Passage, error) {
+	if s.index == nil || !collection.EmbeddingModelID.Valid {
+		return nil, nil
+	}
+	vec, err := s.embed(ctx, s.queries, collection.EmbeddingModelID, query)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := s.index.Search(ctx, collection.TeamID, collection.ID, collection.EmbeddingModelID, vec, limit)
+	if err != nil {
+		return nil, err
+	}
+	titles := map[string]string{}
+	passages := make([]Passage, 0, len(hits))
+	for _, hit := range hits {
+		title, ok := titles[hit.DocumentID]
+		if !ok {
+			title = s.documentTitle(ctx, store, collection.ID, hit.DocumentID)
+			titles[hit.DocumentID] = title
+		}
+		passages = append(passages, Passage{
+			CollectionID:   collection.ID.String(),
+			CollectionName: collection.Name,
+			DocumentID:     hit.DocumentID,
+			DocumentTitle:  title,
+			Text:           hit.Text,
+			Score:          hit.Score,
+		})
+	}
+	return passages, nil
+}
+
+func (s *Service) searchText(ctx context.Context, store knowledgeQueries, collection sqlc.KnowledgeCollection, query string, limit int) ([]Passage, error) {
+	rows, err := store.SearchKnowledgeDocumentsText(ctx, sqlc.SearchKnowledgeDocumentsTextParams{
+		Query:        query,
+		CollectionID: collection.ID,
+		RowLimit:     int32(limit), //nolint:gosec // capped by maxRetrieveLimit.
+	})
+	if err != nil {
+		return nil, err
+	}
+	passages := make([]Passage, 0, len(rows))
+	for _, row := range rows {
+		metadata := decodeMetadata(row.Metadata)
+		text, _ := extractMetadata(row.Content, decodeExtraction(collection.MetadataExtraction))
+		if chunks := chunkText(text, int(collection.ChunkSize), int(collection.ChunkOverlap)); len(chunks) > 0 {
+			text = chunks[0]
+		}
+		passages = append(passages, Passage{
+			CollectionID:   collection.ID.String(),
+			CollectionName: collection.Name,
+			DocumentID:     row.ID.String(),
+			DocumentTitle:  documentTitle(row.Title, metadata),
+			Text:           text,
+			Score:          row.Score,
+		})
+	}
+	return passages, nil
+}
+
+func (*Service) documentTitle(ctx context.Context, store knowledgeQueries, collectionID pgtype.UUID, documentID string) string {
+	pgDocumentID, err := db.ParseUUID(documentID)
+	if err != nil {
+		return ""
+	}
+	row, err := store.GetKnowledgeDocument(ctx, sqlc.GetKnowledgeDocumentParams{CollectionID: collectionID, ID: pgDocumentID})
+	if err != nil {
+		return ""
+	}
+	return documentTitle(row.Title, decodeMetadata(row.Metadata))
+}
+
+func (*Service) getCollection(ctx context.Context, store knowledgeQueries, id string) (sqlc.KnowledgeCollection, error) {
+	pgID, err := db.ParseUUID(id)
+	if err != nil {
+		return sqlc.KnowledgeCollection{}, ErrCollectionNotFound
+	}
+	row, err := store.GetKnowledgeCollection(ctx, pgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sqlc.KnowledgeCollection{}, ErrCollectionNotFound
+		}
+		return sqlc.KnowledgeCollection{}, fmt.Errorf("get knowledge collection: %w", err)
+	}
+	return row, nil
+}
+
+// FormatContext renders passages as a context block for the model. It
+// returns "" when there is nothing to add.
+func FormatContext(passages []Passage) string {
+	if len(passages) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Relevant passages from attached knowledge collections:\n")
+	for _, p := range passages {
+		b.WriteString("\n[")
+		b.WriteString(p.CollectionName)
+		if p.DocumentTitle != "" {
+			b.WriteString(" / ")
+			b.WriteString(p.DocumentTitle)
+		}
+		b.WriteString("]\n")
+		b.WriteString(p.Text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrNameRequired
+	}
+	if len([]rune(name)) > maxNameLength {
+		return "", fmt.Errorf("collection name must be at most %d characters", maxNameLength)
+	}
+	return name, nil
+}
+
+// parseEmbeddingModel validates an embedding model reference. An empty
+// reference means no model.
+func (s *Service) parseEmbeddingModel(ctx context.Context, ref string) (pgtype.UUID, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return pgtype.UUID{}, nil
+	}
+	modelID, err := db.ParseUUID(ref)
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("%w: %s", ErrInvalidEmbeddingModel, ref)
+	}
+	if _, err := resolveEmbeddingModel(ctx, s.queries, modelID); err != nil {
+		return pgtype.UUID{}, err
+	}
+	return modelID, nil
+}
+
+func validateChunking(size, overlap int) error {
+	if size < MinChunkSize || size > MaxChunkSize {
+		return fmt.Errorf("%w: chunk_size must be between %d and %d", ErrInvalidChunking, MinChunkSize, MaxChunkSize)
+	}
+	if overlap < 0 || overlap*2 > size {
+		return fmt.Errorf("%w: chunk_overlap must be between 0 and half of chunk_size", ErrInvalidChunking)
+	}
+	return nil
+}
+
+func parseDocumentIDs(collectionID, documentID string) (pgtype.UUID, pgtype.UUID, error) {
+	pgCollectionID, err := db.ParseUUID(collectionID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, ErrCollectionNotFound
+	}
+	pgDocumentID, err := db.ParseUUID(documentID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, ErrDocumentNotFound
+	}
+	return pgCollectionID, pgDocumentID, nil
+}
+
+func toCollection(row sqlc.KnowledgeCollection) Collection {
+	c := Collection{
+		ID:                 row.ID.String(),
+		Name:               row.Name,
+		Description:        row.Description,
+		ChunkSize:          int(row.ChunkSize),
+		ChunkOverlap:       int(row.ChunkOverlap),
+		MetadataExtraction: decodeExtraction(row.MetadataExtraction),
+		CreatedAt:          db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:          db.TimeFromPg(row.UpdatedAt),
+	}
+	if row.EmbeddingModelID.Valid {
+		c.EmbeddingModelID = row.EmbeddingModelID.String()
+	}
+	return c
+}
+
+func toCollections(rows []sqlc.KnowledgeCollection) []Collection {
+	items := make([]Collection, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toCollection(row))
+	}
+	return items
+}
+
+func toDocument(row sqlc.KnowledgeDocument) Document {
+	metadata := decodeMetadata(row.Metadata)
+	d := Document{
+		ID:            row.ID.String(),
+		CollectionID:  row.CollectionID.String(),
+		Title:         documentTitle(row.Title, metadata),
+		Content:       row.Content,
+		ContentHash:   row.ContentHash,
+		ContentLength: int64(utf8.RuneCountInString(row.Content)),
+		ChunkCount:    int(row.ChunkCount),
+		Metadata:      metadata,
+		SourceURL:     row.SourceUrl,
+		CreatedAt:     db.TimeFromPg(row.CreatedAt),
+		UpdatedAt:     db.TimeFromPg(row.UpdatedAt),
+	}
+	if row.CrawlSourceID.Valid {
+		d.CrawlSourceID = row.CrawlSourceID.String()
+	}
+	if row.ConnectorID.Valid {
+		d.ConnectorID = row.ConnectorID.String()
+	}
+	return d
+}
@@ -0,0 +1,495 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	testBotID   = "11111111-1111-1111-1111-111111111111"
+	testTeamID  = "22222222-2222-2222-2222-222222222222"
+	vectorColID = "33333333-3333-3333-3333-333333333333"
+	textColID   = "44444444-4444-4444-4444-444444444444"
+	testModelID = "55555555-5555-5555-5555-555555555555"
+	testDocID   = "66666666-6666-6666-6666-666666666666"
+)
+
+type fakeKnowledgeQueries struct {
+	dbstore.Queries
+
+	collections map[string]sqlc.KnowledgeCollection
+	documents   map[string]sqlc.KnowledgeDocument
+	attached    []string
+	textRows    []sqlc.SearchKnowledgeDocumentsTextRow
+	deleted     int
+	jobs        map[string]sqlc.KnowledgeReprocessJob
+	jobSeq      byte
+	docSeq      byte
+	sources     map[string]sqlc.KnowledgeCrawlSource
+	runs        map[string]sqlc.KnowledgeCrawlRun
+	runSeq      byte
+	connectors  map[string]sqlc.KnowledgeConnector
+	tokens      map[string]sqlc.KnowledgeConnectorToken
+	syncs       map[string]sqlc.KnowledgeConnectorSync
+	syncSeq     byte
+}
+
+func newFakeKnowledgeQueries() *fakeKnowledgeQueries {
+	return &fakeKnowledgeQueries{
+		collections: map[string]sqlc.KnowledgeCollection{},
+		documents:   map[string]sqlc.KnowledgeDocument{},
+		jobs:        map[string]sqlc.KnowledgeReprocessJob{},
+		sources:     map[string]sqlc.KnowledgeCrawlSource{},
+		runs:        map[string]sqlc.KnowledgeCrawlRun{},
+		connectors:  map[string]sqlc.KnowledgeConnector{},
+		tokens:      map[string]sqlc.KnowledgeConnectorToken{},
+		syncs:       map[string]sqlc.KnowledgeConnectorSync{},
+	}
+}
+
+func (f *fakeKnowledgeQueries) AttachBotKnowledgeCollection(context.Context, sqlc.AttachBotKnowledgeCollectionParams) error {
+	return nil
+}
+
+func (f *fakeKnowledgeQueries) CreateKnowledgeCollection(context.Context, sqlc.CreateKnowledgeCollectionParams) (sqlc.KnowledgeCollection, error) {
+	return sqlc.KnowledgeCollection{}, errors.New("not implemented")
+}
+
+func (f *fakeKnowledgeQueries) CreateKnowledgeDocument(_ context.Context, arg sqlc.CreateKnowledgeDocumentParams) (sqlc.KnowledgeDocument, error) {
+	id := db.ParseUUIDOrEmpty(testDocID)
+	if _, taken := f.documents[testDocID]; taken {
+		f.docSeq++
+		id = pgtype.UUID{Valid: true}
+		id.Bytes[0] = 0xd0
+		id.Bytes[15] = f.docSeq
+	}
+	row := sqlc.KnowledgeDocument{
+		ID:              id,
+		CollectionID:    arg.CollectionID,
+		Title:           arg.Title,
+		Content:         arg.Content,
+		ContentHash:     arg.ContentHash,
+		Metadata:        arg.Metadata,
+		SourceUrl:       arg.SourceUrl,
+		CrawlSourceID:   arg.CrawlSourceID,
+		ConnectorID:     arg.ConnectorID,
+		ExternalID:      arg.ExternalID,
+		ExternalVersion: arg.ExternalVersion,
+	}
+	f.documents[id.String()] = row
+	return row, nil
+}
+
+func (*fakeKnowledgeQueries) DeleteKnowledgeCollection(context.Context, pgtype.UUID) (int64, error) {
+	return 1, nil
+}
+
+func (f *fakeKnowledgeQueries) DeleteKnowledgeDocument(_ context.Context, arg sqlc.DeleteKnowledgeDocumentParams) (int64, error) {
+	f.deleted++
+	delete(f.documents, arg.ID.String())
+	return 1, nil
+}
+
+func (*fakeKnowledgeQueries) DetachBotKnowledgeCollection(context.Context, sqlc.DetachBotKnowledgeCollectionParams) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeCollection(_ context.Context, id pgtype.UUID) (sqlc.KnowledgeCollection, error) {
+	row, ok := f.collections[id.String()]
+	if !ok {
+		return sqlc.KnowledgeCollection{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeDocument(_ context.Context, arg sqlc.GetKnowledgeDocumentParams) (sqlc.KnowledgeDocument, error) {
+	row, ok := f.documents[arg.ID.String()]
+	if !ok {
+		return sqlc.KnowledgeDocument{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeKnowledgeQueries) ListBotKnowledgeCollections(context.Context, pgtype.UUID) ([]sqlc.KnowledgeCollection, error) {
+	rows := make([]sqlc.KnowledgeCollection, 0, len(f.attached))
+	for _, id := range f.attached {
+		rows = append(rows, f.collections[id])
+	}
+	return rows, nil
+}
+
+func (*fakeKnowledgeQueries) ListKnowledgeCollections(context.Context) ([]sqlc.KnowledgeCollection, error) {
+	return nil, nil
+}
+
+func (f *fakeKnowledgeQueries) ListKnowledgeDocuments(_ context.Context, collectionID pgtype.UUID) ([]sqlc.ListKnowledgeDocumentsRow, error) {
+	var rows []sqlc.ListKnowledgeDocumentsRow
+	for _, doc := range f.documents {
+		if doc.CollectionID == collectionID {
+			rows = append(rows, sqlc.ListKnowledgeDocumentsRow{ID: doc.ID, CollectionID: doc.CollectionID})
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeKnowledgeQueries) SearchKnowledgeDocumentsText(_ context.Context, arg sqlc.SearchKnowledgeDocumentsTextParams) ([]sqlc.SearchKnowledgeDocumentsTextRow, error) {
+	var rows []sqlc.SearchKnowledgeDocumentsTextRow
+	for _, row := range f.textRows {
+		if row.CollectionID == arg.CollectionID {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeKnowledgeQueries) SetKnowledgeDocumentProcessing(_ context.Context, arg sqlc.SetKnowledgeDocumentProcessingParams) error {
+	row := f.documents[arg.ID.String()]
+	row.ChunkCount = arg.ChunkCount
+	row.Metadata = arg.Metadata
+	f.documents[arg.ID.String()] = row
+	return nil
+}
+
+func (f *fakeKnowledgeQueries) UpdateKnowledgeCollection(_ context.Context, arg sqlc.UpdateKnowledgeCollectionParams) (sqlc.KnowledgeCollection, error) {
+	row, ok := f.collections[arg.ID.String()]
+	if !ok {
+		return sqlc.KnowledgeCollection{}, pgx.ErrNoRows
+	}
+	row.Name = arg.Name
+	row.Description = arg.Description
+	row.EmbeddingModelID = arg.EmbeddingModelID
+	row.ChunkSize = arg.ChunkSize
+	row.ChunkOverlap = arg.ChunkOverlap
+	row.MetadataExtraction = arg.MetadataExtraction
+	f.collections[arg.ID.String()] = row
+	return row, nil
+}
+
+func (f *fakeKnowledgeQueries) CompleteKnowledgeReprocessJob(_ context.Context, id pgtype.UUID) error {
+	return f.setJobStatus(id, JobCompleted, "")
+}
+
+func (f *fakeKnowledgeQueries) EnqueueKnowledgeReprocessJob(_ context.Context, collectionID pgtype.UUID) (sqlc.KnowledgeReprocessJob, error) {
+	for _, job := range f.jobs {
+		if job.CollectionID == collectionID && job.Status == JobPending {
+			return job, nil
+		}
+	}
+	f.jobSeq++
+	var id pgtype.UUID
+	id.Bytes[15] = f.jobSeq
+	id.Valid = true
+	job := sqlc.KnowledgeReprocessJob{ID: id, CollectionID: collectionID, Status: JobPending}
+	f.jobs[id.String()] = job
+	return job, nil
+}
+
+func (f *fakeKnowledgeQueries) FailKnowledgeReprocessJob(_ context.Context, arg sqlc.FailKnowledgeReprocessJobParams) error {
+	return f.setJobStatus(arg.ID, JobFailed, arg.Error)
+}
+
+func (f *fakeKnowledgeQueries) GetKnowledgeReprocessJob(_ context.Context, id pgtype.UUID) (sqlc.KnowledgeReprocessJob, error) {
+	job, ok := f.jobs[id.String()]
+	if !ok {
+		return sqlc.KnowledgeReprocessJob{}, pgx.ErrNoRows
+	}
+	return job, nil
+}
+
+func (*fakeKnowledgeQueries) ListKnowledgeReprocessJobs(context.Context, sqlc.ListKnowledgeReprocessJobsParams) ([]sqlc.KnowledgeReprocessJob, error) {
+	return nil, nil
+}
+
+func (*fakeKnowledgeQueries) ListUnfinishedKnowledgeReprocessJobs(context.Context) ([]sqlc.KnowledgeReprocessJob, error) {
+	return nil, nil
+}
+
+func (f *fakeKnowledgeQueries) MarkKnowledgeReprocessJobRunning(_ context.Context, arg sqlc.MarkKnowledgeReprocessJobRunningParams) (int64, error) {
+	job, ok := f.jobs[arg.ID.String()]
+	if !ok || (job.Status != JobPending && job.Status != JobRunning) {
+		return 0, nil
+	}
+	job.Status = JobRunning
+	job.DocumentsTotal = arg.DocumentsTotal
+	f.jobs[arg.ID.String()] = job
+	return 1, nil
+}
+
+func (f *fakeKnowledgeQueries) SetKnowledgeReprocessJobProgress(_ context.Context, arg sqlc.SetKnowledgeReprocessJobProgressParams) error {
+	job := f.jobs[arg.ID.String()]
+	job.DocumentsDone = arg.DocumentsDone
+	f.jobs[arg.ID.String()] = job
+	return nil
+}
+
+func (f *fakeKnowledgeQueries) setJobStatus(id pgtype.UUID, status, errText string) error {
+	job := f.jobs[id.String()]
+	job.Status = status
+	job.Error = errText
+	f.jobs[id.String()] = job
+	return nil
+}
+
+type fakeIndex struct {
+	upserted int
+	deleted  int
+	hits     []chunkHit
+}
+
+func (f *fakeIndex) Upsert(_ context.Context, _, _, _, _ pgtype.UUID, chunks []string, _ [][]float32) error {
+	f.upserted += len(chunks)
+	return nil
+}
+
+func (f *fakeIndex) Search(context.Context, pgtype.UUID, pgtype.UUID, pgtype.UUID, []float32, int) ([]chunkHit, error) {
+	return f.hits, nil
+}
+
+func (f *fakeIndex) DeleteDocument(context.Context, pgtype.UUID, pgtype.UUID, pgtype.UUID) error {
+	f.deleted++
+	return nil
+}
+
+func (*fakeIndex) DeleteCollection(context.Context, pgtype.UUID, pgtype.UUID) error {
+	return nil
+}
+
+func newTestService(queries *fakeKnowledgeQueries, index *fakeIndex, embed embedFunc) *Service {
+	svc := NewService(slog.Default(), queries, nil)
+	svc.index = index
+	svc.embed = embed
+	return svc
+}
+
+func staticEmbed(context.Context, dbstore.Queries, pgtype.UUID, string) ([]float32, error) {
+	return []float32{1, 0}, nil
+}
+
+func seedCollections(queries *fakeKnowledgeQueries) {
+	queries.collections[vectorColID] = sqlc.KnowledgeCollection{
+		ID:               db.ParseUUIDOrEmpty(vectorColID),
+		TeamID:           db.ParseUUIDOrEmpty(testTeamID),
+		Name:             "handbook",
+		EmbeddingModelID: db.ParseUUIDOrEmpty(testModelID),
+		ChunkSize:        DefaultChunkSize,
+	}
+	queries.collections[textColID] = sqlc.KnowledgeCollection{
+		ID:        db.ParseUUIDOrEmpty(textColID),
+		TeamID:    db.ParseUUIDOrEmpty(testTeamID),
+		Name:      "faq",
+		ChunkSize: DefaultChunkSize,
+	}
+}
+
+func TestRetrieveMergesVectorAndTextCollections(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	seedCollections(queries)
+	queries.attached = []string{vectorColID, textColID}
+	queries.documents[testDocID] = sqlc.KnowledgeDocument{ID: db.ParseUUIDOrEmpty(testDocID), Title: "Onboarding"}
+	queries.textRows = []sqlc.SearchKnowledgeDocumentsTextRow{{
+		ID:           db.ParseUUIDOrEmpty(testDocID),
+		CollectionID: db.ParseUUIDOrEmpty(textColID),
+		Title:        "Refunds",
+		Content:      "Refunds take five days.",
+		Score:        0.4,
+	}}
+	index := &fakeIndex{hits: []chunkHit{{DocumentID: testDocID, Text: "Laptops ship on day one.", Score: 0.9}}}
+	svc := newTestService(queries, index, staticEmbed)
+
+	passages, err := svc.Retrieve(context.Background(), testBotID, "first day", 5)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(passages) != 2 {
+		t.Fatalf("passages = %d, want 2", len(passages))
+	}
+	if passages[0].CollectionName != "handbook" || passages[0].DocumentTitle != "Onboarding" {
+		t.Fatalf("first passage = %+v, want handbook vector hit", passages[0])
+	}
+	if passages[1].CollectionName != "faq" || passages[1].Text != "Refunds take five days." {
+		t.Fatalf("second passage = %+v, want faq text hit", passages[1])
+	}
+	if got := FormatContext(passages); !strings.Contains(got, "[handbook / Onboarding]") {
+		t.Fatalf("FormatContext missing collection header:\n%s", got)
+	}
+}
+
+func TestRetrieveFallsBackToTextWhenEmbeddingFails(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	seedCollections(queries)
+	queries.attached = []string{vectorColID}
+	queries.textRows = []sqlc.SearchKnowledgeDocumentsTextRow{{
+		ID:           db.ParseUUIDOrEmpty(testDocID),
+		CollectionID: db.ParseUUIDOrEmpty(vectorColID),
+		Title:        "Onboarding",
+		Content:      "Laptops ship on day one.",
+		Score:        0.2,
+	}}
+	failing := func(context.Context, dbstore.Queries, pgtype.UUID, string) ([]float32, error) {
+		return nil, errors.New("provider down")
+	}
+	svc := newTestService(queries, &fakeIndex{}, failing)
+
+	passages, err := svc.Retrieve(context.Background(), testBotID, "laptop", 0)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(passages) != 1 || passages[0].DocumentTitle != "Onboarding" {
+		t.Fatalf("passages = %+v, want text fallback hit", passages)
+	}
+}
+
+func TestAddDocumentIndexesChunks(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	seedCollections(queries)
+	index := &fakeIndex{}
+	svc := newTestService(queries, index, staticEmbed)
+
+	doc, err := svc.AddDocument(context.Background(), vectorColID, AddDocumentRequest{Title: "Guide", Content: "one\n\ntwo"})
+	if err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	if doc.ChunkCount != 1 || index.upserted != 1 {
+		t.Fatalf("chunk count = %d, upserted = %d; want 1, 1", doc.ChunkCount, index.upserted)
+	}
+	if _, err := svc.AddDocument(context.Background(), vectorColID, AddDocumentRequest{Content: "  "}); !errors.Is(err, ErrContentRequired) {
+		t.Fatalf("empty content error = %v, want ErrContentRequired", err)
+	}
+}
+
+func TestAddDocumentRemovesDocumentWhenIndexingFails(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	seedCollections(queries)
+	failing := func(context.Context, dbstore.Queries, pgtype.UUID, string) ([]float32, error) {
+		return nil, errors.New("provider down")
+	}
+	svc := newTestService(queries, &fakeIndex{}, failing)
+
+	if _, err := svc.AddDocument(context.Background(), vectorColID, AddDocumentRequest{Content: "text"}); err == nil {
+		t.Fatal("expected indexing error")
+	}
+	if queries.deleted != 1 || len(queries.documents) != 0 {
+		t.Fatalf("deleted = %d, remaining = %d; want the document removed", queries.deleted, len(queries.documents))
+	}
+}
+
+func TestAddDocumentUnknownCollection(t *testing.T) {
+	svc := newTestService(newFakeKnowledgeQueries(), &fakeIndex{}, staticEmbed)
+	_, err := svc.AddDocument(context.Background(), vectorColID, AddDocumentRequest{Content: "text"})
+	if !errors.Is(err, ErrCollectionNotFound) {
+		t.Fatalf("error = %v, want ErrCollectionNotFound", err)
+	}
+}
+
+func TestUpdateCollectionReprocessesOnSettingsChange(t *testing.T) {
+	queries := newFakeKnowledgeQueries()
+	seedCollections(queries)
+	queries.documents[testDocID] = sqlc.KnowledgeDocument{
+		ID:           db.ParseUUIDOrEmpty(testDocID),
+		CollectionID: db.ParseUUIDOrEmpty(vectorColID),
+		Content:      "---\nauthor: Ada\n---\n# Setup\n\nInstall the agent.",
+	}
+	index := &fakeIndex{}
+	svc := newTestService(queries, index, staticEmbed)
+	ctx := context.Background()
+
+	description := "Team handbook"
+	resp, err := svc.UpdateCollection(ctx, vectorColID, UpdateCollectionRequest{Description: &description})
+	if err != nil {
+		t.Fatalf("UpdateCollection: %v", err)
+	}
+	if resp.ReprocessJob != nil {
+		t.Fatal("description change should not start a reprocess job")
+	}
+
+	size := 400
+	extraction := MetadataExtraction{FrontMatter: true, TitleFromHeading: true}
+	resp, err = svc.UpdateCollection(ctx, vectorColID, UpdateCollectionRequest{ChunkSize: &size, MetadataExtraction: &extraction})
+	if err != nil {
+		t.Fatalf("UpdateCollection: %v", err)
+	}
+	if resp.ReprocessJob == nil || resp.ChunkSize != size || !resp.MetadataExtraction.FrontMatter {
+		t.Fatalf("response = %+v, want new settings and a reprocess job", resp)
+	}
+	again, err := svc.UpdateCollection(ctx, vectorColID, UpdateCollectionRequest{MetadataExtraction: &MetadataExtraction{FrontMatter: true}})
+	if err != nil {
+		t.Fatalf("UpdateCollection: %v", err)
+	}
+	if again.ReprocessJob == nil || again.ReprocessJob.ID != resp.ReprocessJob.ID {
+		t.Fatal("pending reprocess job should be reused")
+	}
+
+	svc.processJob(ctx, resp.ReprocessJob.ID)
+	job := queries.jobs[resp.ReprocessJob.ID]
+	if job.Status != JobCompleted || job.DocumentsTotal != 1 || job.DocumentsDone != 1 {
+		t.Fatalf("job = %+v, want completed with one document", job)
+	}
+	if index.deleted != 1 || index.upserted != 1 {
+		t.Fatalf("index deleted = %d, upserted = %d; want old vectors replaced", index.deleted, index.upserted)
+	}
+	doc := toDocument(queries.documents[testDocID])
+	if doc.Metadata["author"] != "Ada" || doc.Title != "" {
+		t.Fatalf("document = %+v, want front matter only after the second change", doc)
+	}
+
+	overlap := 300
+	if _, err := svc.UpdateCollection(ctx, vectorColID, UpdateCollectionRequest{ChunkOverlap: &overlap}); !errors.Is(err, ErrInvalidChunking) {
+		t.Fatalf("overlap error = %v, want ErrInvalidChunking", err)
+	}
+}
+
+func TestExtractMetadata(t *testing.T) {
+	content := "---\ntitle: Handbook\nOwner: \"People Ops\"\nnot a pair\n---\n# Welcome\n\nBody"
+	text, metadata := extractMetadata(content, MetadataExtraction{FrontMatter: true, TitleFromHeading: true})
+	if text != "# Welcome\n\nBody" {
+		t.Fatalf("text = %q, want front matter removed", text)
+	}
+	if metadata["title"] != "Handbook" || metadata["owner"] != "People Ops" || len(metadata) != 2 {
+		t.Fatalf("metadata = %v", metadata)
+	}
+
+	text, metadata = extractMetadata(content, MetadataExtraction{TitleFromHeading: true})
+	if text != content || metadata["title"] != "Welcome" {
+		t.Fatalf("heading only: text changed = %v, metadata = %v", text != content, metadata)
+	}
+	if _, metadata := extractMetadata(content, MetadataExtraction{}); metadata != nil {
+		t.Fatalf("disabled extraction metadata = %v, want nil", metadata)
+	}
+}
+
+func TestChunkText(t *testing.T) {
+	if got := chunkText("  \n\n ", DefaultChunkSize, 0); len(got) != 0 {
+		t.Fatalf("blank content chunks = %d, want 0", len(got))
+	}
+	short := chunkText("alpha\n\nbeta", DefaultChunkSize, 0)
+	if len(short) != 1 || short[0] != "alpha\n\nbeta" {
+		t.Fatalf("short content chunks = %q", short)
+	}
+	for _, overlap := range []int{0, 50} {
+		long := chunkText(strings.Repeat("word ", 200), 300, overlap)
+		if len(long) < 2 {
+			t.Fatalf("overlap %d: long paragraph chunks = %d, want hard split", overlap, len(long))
+		}
+		for i, chunk := range long {
+			if n := len([]rune(chunk)); n > 300 {
+				t.Fatalf("overlap %d: chunk length = %d, want <= 300", overlap, n)
+			}
+			if overlap > 0 && i > 0 {
+				tail := []rune(long[i-1])
+				if !strings.HasPrefix(chunk, strings.TrimSpace(string(tail[len(tail)-overlap:]))) {
+					t.Fatalf("overlap %d: chunk %d does not start with the previous tail", overlap, i)
+				}
+			}
+		}
+	}
+}
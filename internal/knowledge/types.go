@@ -0,0 +1,234 @@
+package knowledge
+
+import "time"
+
+// Collection is a named document set managed apart from chat memory.
+type Collection struct {
+	ID                 string             `json:"id"`
+	Name               string             `json:"name"`
+	Description        string             `json:"description"`
+	EmbeddingModelID   string             `json:"embedding_model_id,omitempty"`
+	ChunkSize          int                `json:"chunk_size"`
+	ChunkOverlap       int                `json:"chunk_overlap"`
+	MetadataExtraction MetadataExtraction `json:"metadata_extraction"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// MetadataExtraction selects which metadata is extracted from documents
+// while they are processed.
+type MetadataExtraction struct {
+	// FrontMatter parses a leading "---" block of "key: value" lines into
+	// document metadata and drops it from the indexed text.
+	FrontMatter bool `json:"front_matter"`
+	// TitleFromHeading uses the first Markdown heading as the title of
+	// documents added without one.
+	TitleFromHeading bool `json:"title_from_heading"`
+}
+
+// Document is one text document stored in a collection. Content is omitted
+// from list responses.
+type Document struct {
+	ID            string            `json:"id"`
+	CollectionID  string            `json:"collection_id"`
+	Title         string            `json:"title"`
+	Content       string            `json:"content,omitempty"`
+	ContentHash   string            `json:"content_hash"`
+	ContentLength int64             `json:"content_length"`
+	ChunkCount    int               `json:"chunk_count"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	SourceURL     string            `json:"source_url,omitempty"`
+	CrawlSourceID string            `json:"crawl_source_id,omitempty"`
+	ConnectorID   string            `json:"connector_id,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// Job states, shared by reprocess jobs, crawl runs, and connector syncs.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// ReprocessJob re-chunks, re-embeds, and re-extracts metadata for every
+// document of a collection after its processing settings change.
+type ReprocessJob struct {
+	ID             string     `json:"id"`
+	CollectionID   string     `json:"collection_id"`
+	Status         string     `json:"status"`
+	Error          string     `json:"error,omitempty"`
+	DocumentsTotal int        `json:"documents_total"`
+	DocumentsDone  int        `json:"documents_done"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// CrawlSource crawls a website into a collection. Each fetched page is
+// stored as a document keyed by its URL, so later runs update changed pages,
+// skip unchanged ones, and remove pages that disappeared. RefreshPattern is a
+// cron expression that re-runs the crawl; empty means manual runs only.
+type CrawlSource struct {
+	ID              string     `json:"id"`
+	CollectionID    string     `json:"collection_id"`
+	Name            string     `json:"name"`
+	SeedURLs        []string   `json:"seed_urls"`
+	MaxDepth        int        `json:"max_depth"`
+	MaxPages        int        `json:"max_pages"`
+	IncludePatterns []string   `json:"include_patterns"`
+	ExcludePatterns []string   `json:"exclude_patterns"`
+	DelayMs         int        `json:"delay_ms"`
+	RefreshPattern  string     `json:"refresh_pattern,omitempty"`
+	Enabled         bool       `json:"enabled"`
+	NextRunAt       *time.Time `json:"next_run_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// CrawlRun is one execution of a crawl source. Skipped pages were unchanged
+// since the last run or could not be fetched.
+type CrawlRun struct {
+	ID            string     `json:"id"`
+	SourceID      string     `json:"source_id"`
+	Status        string     `json:"status"`
+	Error         string     `json:"error,omitempty"`
+	PagesFetched  int        `json:"pages_fetched"`
+	PagesIngested int        `json:"pages_ingested"`
+	PagesSkipped  int        `json:"pages_skipped"`
+	PagesRemoved  int        `json:"pages_removed"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
+
+// Connector syncs pages or files from an external tool such as Notion into
+// a collection. Authorized reports whether an OAuth token is stored;
+// Account names the account it belongs to.
+type Connector struct {
+	ID           string         `json:"id"`
+	CollectionID string         `json:"collection_id"`
+	Type         string         `json:"type"`
+	Name         string         `json:"name"`
+	Config       map[string]any `json:"config"`
+	SyncPattern  string         `json:"sync_pattern,omitempty"`
+	Enabled      bool           `json:"enabled"`
+	Authorized   bool           `json:"authorized"`
+	Account      string         `json:"account,omitempty"`
+	NextSyncAt   *time.Time     `json:"next_sync_at,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// ConnectorSync is one sync of a connector. Skipped items were unchanged
+// since the last sync or could not be fetched.
+type ConnectorSync struct {
+	ID                string     `json:"id"`
+	ConnectorID       string     `json:"connector_id"`
+	Status            string     `json:"status"`
+	Error             string     `json:"error,omitempty"`
+	FullSync          bool       `json:"full_sync"`
+	ItemsSeen         int        `json:"items_seen"`
+	DocumentsIngested int        `json:"documents_ingested"`
+	DocumentsSkipped  int        `json:"documents_skipped"`
+	DocumentsRemoved  int        `json:"documents_removed"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+}
+
+// Passage is a retrieved piece of a document.
+type Passage struct {
+	CollectionID   string  `json:"collection_id"`
+	CollectionName string  `json:"collection_name"`
+	DocumentID     string  `json:"document_id"`
+	DocumentTitle  string  `json:"document_title,omitempty"`
+	Text           string  `json:"text"`
+	Score          float64 `json:"score"`
+}
+
+// CreateCollectionRequest creates a collection. Zero chunk settings use the
+// defaults.
+type CreateCollectionRequest struct {
+	Name               string             `json:"name"`
+	Description        string             `json:"description,omitempty"`
+	EmbeddingModelID   string             `json:"embedding_model_id,omitempty"`
+	ChunkSize          int                `json:"chunk_size,omitempty"`
+	ChunkOverlap       int                `json:"chunk_overlap,omitempty"`
+	MetadataExtraction MetadataExtraction `json:"metadata_extraction"`
+}
+
+// UpdateCollectionRequest changes a collection. Changing the embedding model,
+// chunk settings, or metadata extraction re-processes every document in the
+// background. An empty EmbeddingModelID removes the model.
+type UpdateCollectionRequest struct {
+	Name               *string             `json:"name,omitempty"`
+	Description        *string             `json:"description,omitempty"`
+	EmbeddingModelID   *string             `json:"embedding_model_id,omitempty"`
+	ChunkSize          *int                `json:"chunk_size,omitempty"`
+	ChunkOverlap       *int                `json:"chunk_overlap,omitempty"`
+	MetadataExtraction *MetadataExtraction `json:"metadata_extraction,omitempty"`
+}
+
+// UpdateCollectionResponse is the updated collection plus the re-processing
+// job it started, if any.
+type UpdateCollectionResponse struct {
+	Collection
+	ReprocessJob *ReprocessJob `json:"reprocess_job,omitempty"`
+}
+
+// AddDocumentRequest adds a document. SourceURL optionally records where the
+// content came from.
+type AddDocumentRequest struct {
+	Title     string `json:"title,omitempty"`
+	Content   string `json:"content"`
+	SourceURL string `json:"source_url,omitempty"`
+}
+
+// CreateCrawlSourceRequest creates a crawl source. Omitted limits use the
+// defaults; Enabled defaults to true.
+type CreateCrawlSourceRequest struct {
+	Name            string   `json:"name"`
+	SeedURLs        []string `json:"seed_urls"`
+	MaxDepth        *int     `json:"max_depth,omitempty"`
+	MaxPages        int      `json:"max_pages,omitempty"`
+	IncludePatterns []string `json:"include_patterns,omitempty"`
+	ExcludePatterns []string `json:"exclude_patterns,omitempty"`
+	DelayMs         *int     `json:"delay_ms,omitempty"`
+	RefreshPattern  string   `json:"refresh_pattern,omitempty"`
+	Enabled         *bool    `json:"enabled,omitempty"`
+}
+
+// UpdateCrawlSourceRequest changes a crawl source. An empty RefreshPattern
+// stops periodic refreshes.
+type UpdateCrawlSourceRequest struct {
+	Name            *string   `json:"name,omitempty"`
+	SeedURLs        *[]string `json:"seed_urls,omitempty"`
+	MaxDepth        *int      `json:"max_depth,omitempty"`
+	MaxPages        *int      `json:"max_pages,omitempty"`
+	IncludePatterns *[]string `json:"include_patterns,omitempty"`
+	ExcludePatterns *[]string `json:"exclude_patterns,omitempty"`
+	DelayMs         *int      `json:"delay_ms,omitempty"`
+	RefreshPattern  *string   `json:"refresh_pattern,omitempty"`
+	Enabled         *bool     `json:"enabled,omitempty"`
+}
+
+// CreateConnectorRequest creates a connector. Name defaults to the display
+// name of the connector type; Enabled defaults to true.
+type CreateConnectorRequest struct {
+	Type        string         `json:"type"`
+	Name        string         `json:"name,omitempty"`
+	Config      map[string]any `json:"config,omitempty"`
+	SyncPattern string         `json:"sync_pattern,omitempty"`
+	Enabled     *bool          `json:"enabled,omitempty"`
+}
+
+// UpdateConnectorRequest changes a connector. Changing Config makes the
+// next sync a full sync. An empty SyncPattern stops scheduled syncs.
+type UpdateConnectorRequest struct {
+	Name        *string         `json:"name,omitempty"`
+	Config      *map[string]any `json:"config,omitempty"`
+	SyncPattern *string         `json:"sync_pattern,omitempty"`
+	Enabled     *bool           `json:"enabled,omitempty"`
+}
@@ -0,0 +1,89 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxDocumentFileBytes bounds an uploaded document file.
+const MaxDocumentFileBytes = 32 << 20
+
+var (
+	ErrUnsupportedDocumentType = errors.New("unsupported document file type")
+	ErrDocumentTooLarge        = errors.New("document file is too large")
+)
+
+// DocumentFile is an uploaded file to add as a document. HTML is reduced to
+// its main content as Markdown; plain text, Markdown, CSV and JSON are
+// stored as they are.
+type DocumentFile struct {
+	Name        string
+	ContentType string
+	Title       string
+	SourceURL   string
+	Reader      io.Reader
+}
+
+// AddDocumentFile reads an uploaded file and adds it like AddDocument. The
+// title defaults to the HTML title or the file name.
+func (s *Service) AddDocumentFile(ctx context.Context, collectionID string, file DocumentFile) (Document, error) {
+	body, err := io.ReadAll(io.LimitReader(file.Reader, MaxDocumentFileBytes+1))
+	if err != nil {
+		return Document{}, fmt.Errorf("read document file: %w", err)
+	}
+	if len(body) > MaxDocumentFileBytes {
+		return Document{}, ErrDocumentTooLarge
+	}
+	title, content, err := documentFileContent(file.Name, file.ContentType, body)
+	if err != nil {
+		return Document{}, err
+	}
+	if t := strings.TrimSpace(file.Title); t != "" {
+		title = t
+	}
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(file.Name), filepath.Ext(file.Name))
+	}
+	return s.AddDocument(ctx, collectionID, AddDocumentRequest{
+		Title:     title,
+		Content:   content,
+		SourceURL: file.SourceURL,
+	})
+}
+
+// documentFileContent returns the title and text of an uploaded file,
+// going by its content type and then its extension.
+func documentFileContent(name, contentType string, body []byte) (string, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		mediaType = mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+	if mediaType == "" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".md", ".markdown":
+			mediaType = "text/markdown"
+		case ".txt", ".text":
+			mediaType = "text/plain"
+		}
+	}
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		title, content := extractMainContent(&url.URL{}, body)
+		return title, content, nil
+	case "text/plain", "text/markdown", "text/x-markdown", "text/csv", "application/json":
+		if !utf8.Valid(body) {
+			return "", "", fmt.Errorf("%w: file is not UTF-8 text", ErrUnsupportedDocumentType)
+		}
+		return "", strings.TrimSpace(string(body)), nil
+	default:
+		return "", "", fmt.Errorf("%w: %q", ErrUnsupportedDocumentType, mediaType)
+	}
+}
@@ -0,0 +1,25 @@
+package knowledge
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDocumentFileContent(t *testing.T) {
+	t.Parallel()
+
+	title, content, err := documentFileContent("page.html", "text/html; charset=utf-8",
+		[]byte("<html><head><title>Refunds</title></head><body><article><h1>Refunds</h1><p>Refunds take five days.</p></article></body></html>"))
+	if err != nil || content == "" {
+		t.Fatalf("html = %q, %q, %v; want converted content", title, content, err)
+	}
+	if _, content, err := documentFileContent("notes.md", "application/octet-stream", []byte("  # Notes\n")); err != nil || content != "# Notes" {
+		t.Fatalf("markdown by extension = %q, %v", content, err)
+	}
+	if _, _, err := documentFileContent("scan.pdf", "application/pdf", []byte("%PDF-1.7")); !errors.Is(err, ErrUnsupportedDocumentType) {
+		t.Fatalf("pdf error = %v, want ErrUnsupportedDocumentType", err)
+	}
+	if _, _, err := documentFileContent("data.txt", "text/plain", []byte{0xff, 0xfe}); !errors.Is(err, ErrUnsupportedDocumentType) {
+		t.Fatalf("binary text error = %v, want ErrUnsupportedDocumentType", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+
+	"github.com/memohai/memoh/internal/redact"
 )
 
 type ctxKey struct{}
@@ -15,6 +17,7 @@ var (
 )
 
 // Init initializes the global logger with the given level and format (e.g. "debug", "json").
+// Output passes through redact.NewHandler so credentials never reach the logs.
 func Init(level, format string) {
 	var handler slog.Handler
 	opts := &slog.HandlerOptions{
@@ -26,6 +29,7 @@ func Init(level, format string) {
 	} else {
 		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
+	handler = redact.NewHandler(handler)
 
 	L = slog.New(handler)
 	slog.SetDefault(L)
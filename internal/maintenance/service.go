@@ -0,0 +1,216 @@
+// Package maintenance holds the deployment-wide maintenance switch used for
+// planned upgrades. While it is on, bots answer every conversation with the
+// maintenance notice instead of running the agent and scheduled runs are
+// skipped. Channel adapters stay connected, so nothing has to reconnect when
+// maintenance ends.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+// MaxMessageLength bounds the notice, in characters.
+const MaxMessageLength = 2000
+
+// cacheTTL bounds how long a process serves the switch without reloading it.
+// Writes invalidate the cache of the process that made them; other processes
+// (the split-mode channel service) pick changes up within this window.
+const cacheTTL = 30 * time.Second
+
+var ErrMessageTooLong = errors.New("maintenance message too long")
+
+// Checker reports whether maintenance is on and the operator's notice. An
+// empty notice means the localized default.
+type Checker interface {
+	Active(ctx context.Context) (string, bool)
+}
+
+// State is the maintenance switch as shown to operators.
+type State struct {
+	Enabled bool `json:"enabled"`
+	// Message is the operator's notice; empty means the localized default.
+	Message string `json:"message"`
+	// StartedAt is when maintenance was turned on, nil when it is off.
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type maintenanceQueries interface {
+	GetMaintenanceMode(ctx context.Context) (sqlc.MaintenanceMode, error)
+	EnableMaintenanceMode(ctx context.Context, arg sqlc.EnableMaintenanceModeParams) (sqlc.MaintenanceMode, error)
+	DisableMaintenanceMode(ctx context.Context, updatedBy pgtype.UUID) (sqlc.MaintenanceMode, error)
+}
+
+// Service reads the switch through a short-lived cache and writes it.
+type Service struct {
+	queries dbstore.Queries
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu       sync.Mutex
+	cached   *State
+	loadedAt time.Time
+}
+
+// NewService creates a maintenance service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "maintenance")),
+		now:     time.Now,
+	}
+}
+
+func (s *Service) store() (maintenanceQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("maintenance service not configured")
+	}
+	store, ok := s.queries.(maintenanceQueries)
+	if !ok {
+		return nil, errors.New("maintenance queries not supported by store")
+	}
+	return store, nil
+}
+
+// Active reports whether maintenance is on and returns the operator's
+// notice. When the switch cannot be loaded, bots keep answering normally.
+func (s *Service) Active(ctx context.Context) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	state := s.load(ctx)
+	if !state.Enabled {
+		return "", false
+	}
+	return state.Message, true
+}
+
+// Status returns the current switch, read from the database.
+func (s *Service) Status(ctx context.Context) (State, error) {
+	state, err := s.fetch(ctx)
+	if err != nil {
+		return State{}, err
+	}
+	s.remember(state)
+	return state, nil
+}
+
+// Enable turns maintenance on with message; an empty message sends the
+// localized default notice. Enabling again only replaces the notice.
+// updatedBy is the acting user and may be empty for the CLI.
+func (s *Service) Enable(ctx context.Context, message, updatedBy string) (State, error) {
+	message = strings.TrimSpace(message)
+	if len([]rune(message)) > MaxMessageLength {
+		return State{}, ErrMessageTooLong
+	}
+	store, err := s.store()
+	if err != nil {
+		return State{}, err
+	}
+	row, err := store.EnableMaintenanceMode(ctx, sqlc.EnableMaintenanceModeParams{
+		Message:   message,
+		UpdatedBy: db.ParseUUIDOrEmpty(strings.TrimSpace(updatedBy)),
+	})
+	if err != nil {
+		return State{}, err
+	}
+	state := toState(row)
+	s.remember(state)
+	s.logger.Info("maintenance mode enabled", slog.String("updated_by", state.UpdatedBy))
+	return state, nil
+}
+
+// Disable turns maintenance off. The last notice is kept for the next time.
+func (s *Service) Disable(ctx context.Context, updatedBy string) (State, error) {
+	store, err := s.store()
+	if err != nil {
+		return State{}, err
+	}
+	row, err := store.DisableMaintenanceMode(ctx, db.ParseUUIDOrEmpty(strings.TrimSpace(updatedBy)))
+	if err != nil {
+		return State{}, err
+	}
+	state := toState(row)
+	s.remember(state)
+	s.logger.Info("maintenance mode disabled", slog.String("updated_by", state.UpdatedBy))
+	return state, nil
+}
+
+func (s *Service) remember(state State) {
+	s.mu.Lock()
+	s.cached, s.loadedAt = &state, s.now()
+	s.mu.Unlock()
+}
+
+// load returns the cached switch, reloading it after cacheTTL. A failed
+// reload keeps serving what was loaded before (or off) until the next
+// window, so a database outage does not cost a query per message.
+func (s *Service) load(ctx context.Context) State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if s.cached != nil && now.Sub(s.loadedAt) < cacheTTL {
+		return *s.cached
+	}
+	state, err := s.fetch(ctx)
+	if err != nil {
+		s.logger.Warn("load maintenance mode failed", slog.Any("error", err))
+		if s.cached == nil {
+			s.cached = &State{}
+		}
+		s.loadedAt = now
+		return *s.cached
+	}
+	s.cached, s.loadedAt = &state, now
+	return state
+}
+
+func (s *Service) fetch(ctx context.Context) (State, error) {
+	store, err := s.store()
+	if err != nil {
+		return State{}, err
+	}
+	row, err := store.GetMaintenanceMode(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	return toState(row), nil
+}
+
+func toState(row sqlc.MaintenanceMode) State {
+	state := State{
+		Enabled: row.Enabled,
+		Message: strings.TrimSpace(row.Message),
+	}
+	if row.StartedAt.Valid {
+		t := row.StartedAt.Time
+		state.StartedAt = &t
+	}
+	if row.UpdatedBy.Valid {
+		state.UpdatedBy = row.UpdatedBy.String()
+	}
+	if row.UpdatedAt.Valid {
+		t := row.UpdatedAt.Time
+		state.UpdatedAt = &t
+	}
+	return state
+}
@@ -0,0 +1,163 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const testUserID = "0b4c8f2e-5d61-4f0a-8c77-3e2a9d1b6c40"
+
+type fakeMaintenanceQueries struct {
+	dbstore.Queries
+
+	row   *sqlc.MaintenanceMode
+	gets  int
+	err   error
+	clock time.Time
+}
+
+func (f *fakeMaintenanceQueries) GetMaintenanceMode(context.Context) (sqlc.MaintenanceMode, error) {
+	f.gets++
+	if f.err != nil {
+		return sqlc.MaintenanceMode{}, f.err
+	}
+	if f.row == nil {
+		return sqlc.MaintenanceMode{}, pgx.ErrNoRows
+	}
+	return *f.row, nil
+}
+
+func (f *fakeMaintenanceQueries) EnableMaintenanceMode(_ context.Context, arg sqlc.EnableMaintenanceModeParams) (sqlc.MaintenanceMode, error) {
+	started := pgtype.Timestamptz{Time: f.clock, Valid: true}
+	if f.row != nil && f.row.Enabled {
+		started = f.row.StartedAt
+	}
+	f.row = &sqlc.MaintenanceMode{
+		Enabled:   true,
+		Message:   arg.Message,
+		UpdatedBy: arg.UpdatedBy,
+		StartedAt: started,
+		UpdatedAt: pgtype.Timestamptz{Time: f.clock, Valid: true},
+	}
+	return *f.row, nil
+}
+
+func (f *fakeMaintenanceQueries) DisableMaintenanceMode(_ context.Context, updatedBy pgtype.UUID) (sqlc.MaintenanceMode, error) {
+	row := sqlc.MaintenanceMode{}
+	if f.row != nil {
+		row = *f.row
+	}
+	row.Enabled = false
+	row.UpdatedBy = updatedBy
+	row.StartedAt = pgtype.Timestamptz{}
+	row.UpdatedAt = pgtype.Timestamptz{Time: f.clock, Valid: true}
+	f.row = &row
+	return row, nil
+}
+
+func newTestService(queries *fakeMaintenanceQueries) (*Service, *time.Time) {
+	now := time.Unix(1710000000, 0)
+	queries.clock = now
+	svc := NewService(nil, queries)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func TestEnableAndDisable(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queries := &fakeMaintenanceQueries{}
+	svc, now := newTestService(queries)
+
+	if _, active := svc.Active(ctx); active {
+		t.Fatal("maintenance active without a row")
+	}
+	state, err := svc.Enable(ctx, "  Upgrading to 2.0, back at 14:00.  ", testUserID)
+	if err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	if !state.Enabled || state.StartedAt == nil || state.UpdatedBy != testUserID {
+		t.Fatalf("Enable state = %+v", state)
+	}
+	message, active := svc.Active(ctx)
+	if !active || message != "Upgrading to 2.0, back at 14:00." {
+		t.Fatalf("Active = %q, %v", message, active)
+	}
+
+	startedAt := *state.StartedAt
+	*now = now.Add(time.Hour)
+	queries.clock = *now
+	state, err = svc.Enable(ctx, "", "")
+	if err != nil {
+		t.Fatalf("Enable again: %v", err)
+	}
+	if !state.StartedAt.Equal(startedAt) {
+		t.Fatalf("re-enabling moved started_at to %v", state.StartedAt)
+	}
+	if message, _ := svc.Active(ctx); message != "" {
+		t.Fatalf("Active message = %q, want empty for the default notice", message)
+	}
+
+	state, err = svc.Disable(ctx, testUserID)
+	if err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if state.Enabled || state.StartedAt != nil {
+		t.Fatalf("Disable state = %+v", state)
+	}
+	if _, active := svc.Active(ctx); active {
+		t.Fatal("maintenance still active after Disable")
+	}
+}
+
+func TestEnableRejectsLongMessage(t *testing.T) {
+	t.Parallel()
+
+	svc, _ := newTestService(&fakeMaintenanceQueries{})
+	if _, err := svc.Enable(context.Background(), strings.Repeat("x", MaxMessageLength+1), ""); !errors.Is(err, ErrMessageTooLong) {
+		t.Fatalf("Enable error = %v, want ErrMessageTooLong", err)
+	}
+}
+
+func TestActiveCachesAndSurvivesOutage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queries := &fakeMaintenanceQueries{row: &sqlc.MaintenanceMode{Enabled: true, Message: "Back soon."}}
+	svc, now := newTestService(queries)
+
+	for range 3 {
+		if _, active := svc.Active(ctx); !active {
+			t.Fatal("maintenance reported off")
+		}
+	}
+	if queries.gets != 1 {
+		t.Fatalf("loaded %d times, want the cache to serve repeat reads", queries.gets)
+	}
+
+	// Another process turned maintenance off; this one sees it after the TTL.
+	queries.row = &sqlc.MaintenanceMode{}
+	if _, active := svc.Active(ctx); !active {
+		t.Fatal("cache dropped before its TTL")
+	}
+	*now = now.Add(cacheTTL)
+	if _, active := svc.Active(ctx); active {
+		t.Fatal("change not picked up after the TTL")
+	}
+
+	queries.err = errors.New("database down")
+	*now = now.Add(cacheTTL)
+	if _, active := svc.Active(ctx); active {
+		t.Fatal("failed reload changed the switch")
+	}
+}
@@ -23,6 +23,7 @@ type ToolSessionContext struct {
 	ToolCallID          string
 	SessionType         string
 	RouteID             string
+	UserID              string
 	ChannelIdentityID   string
 	SessionToken        string `json:"-"`
 	CurrentPlatform     string
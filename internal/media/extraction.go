@@ -0,0 +1,80 @@
+package media
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// maxCachedExtractions bounds the extraction cache; the least recently used
+// result is evicted first.
+const maxCachedExtractions = 1024
+
+// extractionCache keeps text extracted from assets (transcripts, OCR) keyed
+// by content hash, so an attachment sent again is not re-extracted. It lives
+// in memory only, in keeping with the no-sidecar-files design.
+type extractionCache struct {
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type extractionEntry struct {
+	key  string
+	text string
+}
+
+// CachedExtraction returns text previously extracted from the asset by
+// extractor. extractor names the extraction and anything that changes its
+// output, e.g. "transcription:<model id>".
+func (s *Service) CachedExtraction(botID, contentHash, extractor string) (string, bool) {
+	key, ok := extractionKey(botID, contentHash, extractor)
+	if s == nil || !ok {
+		return "", false
+	}
+	c := &s.extractions
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*extractionEntry).text, true
+}
+
+// StoreExtraction caches text extracted from the asset by extractor.
+func (s *Service) StoreExtraction(botID, contentHash, extractor, text string) {
+	key, ok := extractionKey(botID, contentHash, extractor)
+	if s == nil || !ok {
+		return
+	}
+	c := &s.extractions
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.order = list.New()
+		c.entries = make(map[string]*list.Element)
+	}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*extractionEntry).text = text
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&extractionEntry{key: key, text: text})
+	for c.order.Len() > maxCachedExtractions {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*extractionEntry).key)
+	}
+}
+
+func extractionKey(botID, contentHash, extractor string) (string, bool) {
+	botID = strings.TrimSpace(botID)
+	contentHash = strings.TrimSpace(contentHash)
+	extractor = strings.TrimSpace(extractor)
+	if botID == "" || contentHash == "" || extractor == "" {
+		return "", false
+	}
+	return botID + "/" + contentHash + "/" + extractor, true
+}
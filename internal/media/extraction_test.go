@@ -0,0 +1,46 @@
+package media
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExtractionCache(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(nil, nil)
+	if _, ok := svc.CachedExtraction("bot-1", "abc", "transcription:stt-1"); ok {
+		t.Fatal("empty cache returned a hit")
+	}
+	svc.StoreExtraction("bot-1", "abc", "transcription:stt-1", "hello")
+	if text, ok := svc.CachedExtraction("bot-1", "abc", "transcription:stt-1"); !ok || text != "hello" {
+		t.Fatalf("CachedExtraction = %q, %v", text, ok)
+	}
+	if _, ok := svc.CachedExtraction("bot-1", "abc", "transcription:stt-2"); ok {
+		t.Fatal("hit for another extractor")
+	}
+	if _, ok := svc.CachedExtraction("bot-2", "abc", "transcription:stt-1"); ok {
+		t.Fatal("hit for another bot")
+	}
+}
+
+func TestExtractionCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(nil, nil)
+	svc.StoreExtraction("bot-1", "first", "ocr", "first")
+	for i := range maxCachedExtractions - 1 {
+		svc.StoreExtraction("bot-1", fmt.Sprintf("hash-%d", i), "ocr", "text")
+	}
+	// Touch the oldest entry so the next insert evicts hash-0 instead.
+	if _, ok := svc.CachedExtraction("bot-1", "first", "ocr"); !ok {
+		t.Fatal("entry evicted before the cache was full")
+	}
+	svc.StoreExtraction("bot-1", "overflow", "ocr", "text")
+	if _, ok := svc.CachedExtraction("bot-1", "first", "ocr"); !ok {
+		t.Fatal("recently used entry evicted")
+	}
+	if _, ok := svc.CachedExtraction("bot-1", "hash-0", "ocr"); ok {
+		t.Fatal("least recently used entry kept")
+	}
+}
@@ -18,8 +18,9 @@ import (
 // Service provides content-addressed media asset persistence.
 // All metadata is derived from the filesystem — no database, no sidecar files.
 type Service struct {
-	provider storage.Provider
-	logger   *slog.Logger
+	provider    storage.Provider
+	logger      *slog.Logger
+	extractions extractionCache
 }
 
 // NewService creates a media service with the given storage provider.
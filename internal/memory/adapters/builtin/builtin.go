@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/memohai/memoh/internal/mcp"
 	adapters "github.com/memohai/memoh/internal/memory/adapters"
@@ -26,6 +27,10 @@ type BuiltinProvider struct {
 	llm     adapters.LLM
 	logger  *slog.Logger
 	packer  contextPackerConfig
+	// userIsolation scopes chat-formed memories to the participant they
+	// came from, so users of a multi-user bot only recall their own and
+	// bot-wide memories.
+	userIsolation bool
 }
 
 // Runtime is the runtime memory backend required by the builtin provider.
@@ -46,6 +51,10 @@ type Runtime interface {
 	Rebuild(ctx context.Context, botID string) (adapters.RebuildResult, error)
 }
 
+type queryExpanderRuntime interface {
+	SetQueryExpander(expander adapters.QueryExpander)
+}
+
 type llmCompactRuntime interface {
 	CompactWithLLM(ctx context.Context, filters map[string]any, ratio float64, decayDays int, llm adapters.LLM) (adapters.CompactResult, error)
 }
@@ -63,8 +72,17 @@ func NewBuiltinProvider(log *slog.Logger, service Runtime) *BuiltinProvider {
 }
 
 // SetLLM injects the LLM client used for Extract/Decide in memory formation.
+// When the client can expand queries, the runtime uses it for search query
+// expansion too.
 func (p *BuiltinProvider) SetLLM(llm adapters.LLM) {
 	p.llm = llm
+	expander, ok := llm.(adapters.QueryExpander)
+	if !ok {
+		return
+	}
+	if runtime, ok := p.service.(queryExpanderRuntime); ok {
+		runtime.SetQueryExpander(expander)
+	}
 }
 
 // Close releases runtime-owned resources such as the semantic retry worker.
@@ -99,13 +117,25 @@ func (p *BuiltinProvider) SetPackerConfig(cfg contextPackerConfig) {
 	}
 }
 
-// ApplyProviderConfig reads context packing knobs from a provider config map
-// and applies any non-zero values to the provider's packer configuration.
+// ApplyProviderConfig reads context packing knobs and user isolation from a
+// provider config map and applies any non-zero values to the provider.
 func (p *BuiltinProvider) ApplyProviderConfig(providerConfig map[string]any) {
 	p.SetPackerConfig(contextPackerConfig{
 		TargetItems:   intFromConfig(providerConfig, "context_target_items"),
 		MaxTotalChars: intFromConfig(providerConfig, "context_max_total_chars"),
 	})
+	if isolate, ok := providerConfig["user_isolation"].(bool); ok {
+		p.userIsolation = isolate
+	}
+}
+
+// scopeUserID returns the user chat memories are scoped to, or "" when user
+// isolation is off.
+func (p *BuiltinProvider) scopeUserID(userID, channelIdentityID string) string {
+	if !p.userIsolation {
+		return ""
+	}
+	return adapters.MemoryScopeUserID(userID, channelIdentityID)
 }
 
 func intFromConfig(m map[string]any, key string) int {
@@ -194,15 +224,17 @@ func (p *BuiltinProvider) OnBeforeChat(ctx context.Context, req adapters.BeforeC
 
 	fetchLimit := overfetchLimit(p.packer)
 	resp, err := p.service.Search(ctx, adapters.SearchRequest{
-		Query: req.Query,
-		BotID: req.BotID,
-		Limit: fetchLimit,
+		Query:  req.Query,
+		BotID:  req.BotID,
+		UserID: p.scopeUserID(req.UserID, req.ChannelIdentityID),
+		Limit:  fetchLimit,
 		Filters: map[string]any{
 			"namespace": sharedMemoryNamespace,
 			"scopeId":   req.BotID,
 			"bot_id":    req.BotID,
 		},
 		NoStats: true,
+		Fusion:  req.Fusion,
 	})
 	if err != nil {
 		p.logger.Warn("memory search for context failed", slog.Any("error", err))
@@ -260,7 +292,7 @@ func (p *BuiltinProvider) OnAfterChat(ctx context.Context, req adapters.AfterCha
 	}
 
 	if p.llm != nil {
-		result := runFormation(ctx, p.logger, p.llm, p.service, req)
+		result := runFormation(ctx, p.logger, p.llm, p.service, req, p.scopeUserID(req.UserID, req.ChannelIdentityID))
 		p.logger.Debug("memory formation completed",
 			slog.String("bot_id", botID),
 			slog.Int("extracted", result.ExtractedFacts),
@@ -282,6 +314,7 @@ func (p *BuiltinProvider) OnAfterChat(ctx context.Context, req adapters.AfterCha
 	if _, err := p.service.Add(ctx, adapters.AddRequest{
 		Messages: req.Messages,
 		BotID:    botID,
+		UserID:   p.scopeUserID(req.UserID, req.ChannelIdentityID),
 		Metadata: metadata,
 		Filters:  filters,
 	}); err != nil {
@@ -348,9 +381,10 @@ func (p *BuiltinProvider) CallTool(ctx context.Context, session mcp.ToolSessionC
 	}
 
 	resp, err := p.service.Search(ctx, adapters.SearchRequest{
-		Query: query,
-		BotID: botID,
-		Limit: limit,
+		Query:  query,
+		BotID:  botID,
+		UserID: p.scopeUserID(session.UserID, session.ChannelIdentityID),
+		Limit:  limit,
 		Filters: map[string]any{
 			"namespace": sharedMemoryNamespace,
 			"scopeId":   botID,
@@ -473,6 +507,24 @@ func (p *BuiltinProvider) Rebuild(ctx context.Context, botID string) (adapters.R
 	return p.service.Rebuild(ctx, botID)
 }
 
+// reembeddingRuntime is implemented by runtimes with a semantic index that
+// can be rebuilt in the background. Only the graph runtime does.
+type reembeddingRuntime interface {
+	StartReembed(ctx context.Context, botID string) (adapters.ReembedStatus, error)
+}
+
+// StartReembed implements adapters.ReembedProvider.
+func (p *BuiltinProvider) StartReembed(ctx context.Context, botID string) (adapters.ReembedStatus, error) {
+	if p.service == nil {
+		return adapters.ReembedStatus{}, errors.New("memory runtime not configured")
+	}
+	rt, ok := p.service.(reembeddingRuntime)
+	if !ok {
+		return adapters.ReembedStatus{}, adapters.ErrReembedUnavailable
+	}
+	return rt.StartReembed(ctx, botID)
+}
+
 // markdownIngestor is the optional Runtime capability for ingesting agent-
 // authored Markdown files back into the DB as nodes. Only the graph runtime
 // implements it (the file runtime treats files as the source of truth).
@@ -497,3 +549,21 @@ func (p *BuiltinProvider) IngestFromMarkdown(ctx context.Context, botID string)
 	}
 	return adapters.IngestResult{Ingested: res.Ingested, Skipped: res.Skipped}, nil
 }
+
+// expiringRuntime is implemented by runtimes that store memory TTLs. Only
+// the graph runtime does.
+type expiringRuntime interface {
+	ExpireMemories(ctx context.Context, botID string, now time.Time) (int, error)
+}
+
+// ExpireMemories implements adapters.MemoryExpiryProvider.
+func (p *BuiltinProvider) ExpireMemories(ctx context.Context, botID string, now time.Time) (int, error) {
+	if p.service == nil {
+		return 0, errors.New("memory runtime not configured")
+	}
+	rt, ok := p.service.(expiringRuntime)
+	if !ok {
+		return 0, nil
+	}
+	return rt.ExpireMemories(ctx, botID, now)
+}
@@ -0,0 +1,145 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	adapters "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+// errEmbeddingDimensionMismatch reports that the embedding provider returned
+// a vector whose size differs from the model's configured dimensions.
+var errEmbeddingDimensionMismatch = errors.New("pgvector semantic index: embedding dimensions changed")
+
+// dimensionDrift remembers, per bot, that the embedding provider silently
+// changed its vector size. While a bot is drifted the index stops calling the
+// provider for it: writes are quarantined (the wiki store still has them, so
+// a re-embedding job can index them later) and searches fall back to lexical
+// seeds. Each bot leaves the quarantine through its own re-embedding job.
+type dimensionDrift struct {
+	mu   sync.Mutex
+	bots map[string]*botDimensionDrift
+}
+
+type botDimensionDrift struct {
+	observed    int
+	detectedAt  time.Time
+	quarantined int
+}
+
+// detect records observed as the provider's vector size for botID and
+// reports whether this is a new drift.
+func (d *dimensionDrift) detect(botID string, observed int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.bots == nil {
+		d.bots = map[string]*botDimensionDrift{}
+	}
+	state := d.bots[botID]
+	if state == nil {
+		state = &botDimensionDrift{}
+		d.bots[botID] = state
+	}
+	if state.observed == observed {
+		return false
+	}
+	state.observed = observed
+	state.detectedAt = time.Now().UTC()
+	return true
+}
+
+func (d *dimensionDrift) active(botID string) (int, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state := d.bots[botID]
+	if state == nil || state.observed == 0 {
+		return 0, false
+	}
+	return state.observed, true
+}
+
+func (d *dimensionDrift) quarantine(botID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if state := d.bots[botID]; state != nil {
+		state.quarantined++
+	}
+}
+
+// clear lifts the quarantine of botID only.
+func (d *dimensionDrift) clear(botID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.bots, botID)
+}
+
+func (d *dimensionDrift) status(botID string, configured int) *adapters.EmbeddingDimensionStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state := d.bots[botID]
+	if state == nil || state.observed == 0 {
+		return nil
+	}
+	return &adapters.EmbeddingDimensionStatus{
+		Configured:        configured,
+		Observed:          state.observed,
+		QuarantinedWrites: state.quarantined,
+		DetectedAt:        state.detectedAt,
+		Action:            fmt.Sprintf("set the embedding model's dimensions to %d, then start a re-embedding job (POST /bots/{bot_id}/memory/reembed)", state.observed),
+	}
+}
+
+// checkDimensions flags a drift for botID when got differs from the
+// configured size. The first detection is logged at error level so it
+// reaches the admin.
+func (r *pgvectorIndex) checkDimensions(botID string, got int) error {
+	want := int(r.dimensions.Load())
+	if want <= 0 || got == want {
+		return nil
+	}
+	if r.drift.detect(botID, got) && r.logger != nil {
+		r.logger.Error("embedding provider changed vector dimensions; semantic memory writes are quarantined",
+			slog.String("bot_id", botID),
+			slog.String("embedding_model_id", r.modelRef),
+			slog.Int("configured", want),
+			slog.Int("observed", got),
+			slog.String("action", "update the model's dimensions and start a memory re-embedding job"))
+	}
+	return fmt.Errorf("%w: got %d, want %d", errEmbeddingDimensionMismatch, got, want)
+}
+
+// quarantined returns the drift error while botID is quarantined, so
+// callers skip the embedding call entirely.
+func (r *pgvectorIndex) quarantined(botID string) error {
+	observed, ok := r.drift.active(botID)
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("%w: provider returns %d, model is configured for %d", errEmbeddingDimensionMismatch, observed, r.dimensions.Load())
+}
+
+func (r *pgvectorIndex) dimensionStatus(botID string) *adapters.EmbeddingDimensionStatus {
+	return r.drift.status(botID, int(r.dimensions.Load()))
+}
+
+// reloadModel re-reads the embedding model so a corrected dimensions setting
+// takes effect. It refuses while the configured size still differs from what
+// the provider was seen returning for botID, and lifts that bot's quarantine
+// otherwise; other bots stay quarantined until they are re-embedded too.
+func (r *pgvectorIndex) reloadModel(ctx context.Context, botID string) error {
+	spec, err := resolveEmbeddingModel(ctx, r.lookup, r.modelRef)
+	if err != nil {
+		return err
+	}
+	if observed, ok := r.drift.active(botID); ok && spec.dimensions != observed {
+		return fmt.Errorf("%w: the provider returns %d dimensions but model %s is configured for %d; update the model's dimensions first",
+			adapters.ErrEmbeddingDimensionsUnresolved, observed, r.modelRef, spec.dimensions)
+	}
+	r.dimensions.Store(int64(spec.dimensions))
+	r.drift.clear(botID)
+	return nil
+}
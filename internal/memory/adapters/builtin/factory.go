@@ -17,9 +17,10 @@ import (
 // the source of truth, with Markdown as a derived view. Returns an error if
 // the wiki store is not configured.
 //
-// queries is used to resolve the optional embedding_model_id from the main
-// relational store. The pgvector semantic seed index itself uses the dedicated
-// [pgvector] database, so Local stores intentionally run graph-only.
+// queries is used to resolve the optional embedding_model_id and
+// rerank_model_id from the main relational store. The pgvector semantic seed
+// index itself uses the dedicated [pgvector] database, so Local stores
+// intentionally run graph-only.
 func NewBuiltinRuntimeFromConfig(logger *slog.Logger, providerConfig map[string]any, store *storefs.Service, queries dbstore.Queries, vectorStore *pgvectordb.Store, wikiStore wikistore.Store) (Runtime, error) {
 	return NewBuiltinRuntimeFromConfigContext(context.Background(), logger, providerConfig, store, queries, vectorStore, wikiStore, nil)
 }
@@ -32,10 +33,17 @@ func NewBuiltinRuntimeFromConfigContext(ctx context.Context, logger *slog.Logger
 		return nil, errors.New("graph runtime: wiki store not configured")
 	}
 	runtime := NewGraphRuntime(logger, wikiStore, store)
+	runtime.SetSearchFusion(searchFusionFromConfig(providerConfig))
+	runtime.SetQueryExpansion(queryExpansionFromConfig(providerConfig))
 	semantic, err := newPGVectorIndex(ctx, logger, providerConfig, queries, vectorStore, resolver)
 	if err != nil {
 		return nil, err
 	}
 	runtime.SetSemanticIndex(ctx, semantic)
+	reranker, err := newRerankerFromConfig(ctx, providerConfig, queries)
+	if err != nil {
+		return nil, err
+	}
+	runtime.SetReranker(reranker)
 	return runtime, nil
 }
@@ -54,7 +54,7 @@ func (r *fileRuntime) Add(ctx context.Context, req adapters.AddRequest) (adapter
 		Hash:      runtimeHash(text),
 		CreatedAt: now.Format(time.RFC3339),
 		UpdatedAt: now.Format(time.RFC3339),
-		Metadata:  req.Metadata,
+		Metadata:  adapters.WithUserScope(req.Metadata, runtimeUserID(req.UserID, req.Filters)),
 		BotID:     botID,
 	}
 	itemsToPersist := []storefs.MemoryItem{storeItemFromMemoryItem(item)}
@@ -73,9 +73,13 @@ func (r *fileRuntime) Search(ctx context.Context, req adapters.SearchRequest) (a
 	if err != nil {
 		return adapters.SearchResponse{}, err
 	}
+	userID := runtimeUserID(req.UserID, req.Filters)
 	query := strings.ToLower(strings.TrimSpace(req.Query))
 	results := make([]adapters.MemoryItem, 0, len(items))
 	for _, item := range items {
+		if !adapters.VisibleToUser(item.Metadata, userID) {
+			continue
+		}
 		score := fileRuntimeScore(query, item.Memory)
 		if query != "" && score <= 0 {
 			continue
@@ -105,9 +109,15 @@ func (r *fileRuntime) GetAll(ctx context.Context, req adapters.GetAllRequest) (a
 	if err != nil {
 		return adapters.SearchResponse{}, err
 	}
-	for i := range items {
-		items[i].BotID = botID
+	userID := runtimeUserID(req.UserID, req.Filters)
+	visible := items[:0]
+	for _, item := range items {
+		if adapters.VisibleToUser(item.Metadata, userID) {
+			item.BotID = botID
+			visible = append(visible, item)
+		}
 	}
+	items = visible
 	sort.Slice(items, func(i, j int) bool { return items[i].UpdatedAt > items[j].UpdatedAt })
 	if req.Limit > 0 && len(items) > req.Limit {
 		items = items[:req.Limit]
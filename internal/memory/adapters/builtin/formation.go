@@ -31,7 +31,8 @@ type formationResult struct {
 }
 
 // runFormation executes the Extract -> candidate retrieval -> Decide -> apply pipeline.
-func runFormation(ctx context.Context, logger *slog.Logger, llm adapters.LLM, runtime Runtime, req adapters.AfterChatRequest) formationResult {
+// A non-empty userID scopes candidates and added memories to that user.
+func runFormation(ctx context.Context, logger *slog.Logger, llm adapters.LLM, runtime Runtime, req adapters.AfterChatRequest, userID string) formationResult {
 	ctx, cancel := context.WithTimeout(ctx, formationTimeout)
 	defer cancel()
 
@@ -53,7 +54,7 @@ func runFormation(ctx context.Context, logger *slog.Logger, llm adapters.LLM, ru
 	}
 	result.ExtractedFacts = len(facts)
 
-	candidates := gatherCandidates(ctx, logger, runtime, botID, facts)
+	candidates := gatherCandidates(ctx, logger, runtime, botID, userID, facts)
 
 	decided, err := llm.Decide(ctx, adapters.DecideRequest{
 		BotID:      botID,
@@ -70,6 +71,9 @@ func runFormation(ctx context.Context, logger *slog.Logger, llm adapters.LLM, ru
 		"scopeId":   botID,
 		"bot_id":    botID,
 	}
+	if userID != "" {
+		filters[adapters.MetadataUserID] = userID
+	}
 	metadata := adapters.BuildProfileMetadata(req.UserID, req.ChannelIdentityID, req.DisplayName)
 
 	applyActions(ctx, logger, runtime, botID, decided.Actions, filters, metadata, &result)
@@ -77,7 +81,7 @@ func runFormation(ctx context.Context, logger *slog.Logger, llm adapters.LLM, ru
 }
 
 // gatherCandidates collects existing memories relevant to the extracted facts.
-func gatherCandidates(ctx context.Context, logger *slog.Logger, runtime Runtime, botID string, facts []string) []adapters.CandidateMemory {
+func gatherCandidates(ctx context.Context, logger *slog.Logger, runtime Runtime, botID, userID string, facts []string) []adapters.CandidateMemory {
 	seen := make(map[string]struct{})
 	candidates := make([]adapters.CandidateMemory, 0, candidateSearchLimit)
 
@@ -94,6 +98,7 @@ func gatherCandidates(ctx context.Context, logger *slog.Logger, runtime Runtime,
 		resp, err := runtime.Search(ctx, adapters.SearchRequest{
 			Query:   fact,
 			BotID:   botID,
+			UserID:  userID,
 			Limit:   candidateSearchLimit / max(len(facts), 1),
 			Filters: filters,
 			NoStats: true,
@@ -126,6 +131,7 @@ func gatherCandidates(ctx context.Context, logger *slog.Logger, runtime Runtime,
 	if len(candidates) < maxCandidatesPerDecide {
 		resp, err := runtime.GetAll(ctx, adapters.GetAllRequest{
 			BotID:   botID,
+			UserID:  userID,
 			Limit:   candidateGetAllLimit,
 			Filters: filters,
 			NoStats: true,
@@ -61,7 +61,7 @@ func TestFormationExtractAndAdd(t *testing.T) {
 			{Role: "user", Content: "I like oolong tea and I live in Berlin"},
 			{Role: "assistant", Content: "Noted!"},
 		},
-	})
+	}, "")
 
 	if result.ExtractedFacts != 2 {
 		t.Fatalf("expected 2 extracted facts, got %d", result.ExtractedFacts)
@@ -107,7 +107,7 @@ func TestFormationUpdate(t *testing.T) {
 		Messages: []adapters.Message{
 			{Role: "user", Content: "Actually, I moved to Berlin"},
 		},
-	})
+	}, "")
 
 	if result.Updated != 1 {
 		t.Fatalf("expected 1 update, got %d", result.Updated)
@@ -152,7 +152,7 @@ func TestFormationDelete(t *testing.T) {
 		Messages: []adapters.Message{
 			{Role: "user", Content: "I stopped drinking coffee"},
 		},
-	})
+	}, "")
 
 	if result.Deleted != 1 {
 		t.Fatalf("expected 1 delete, got %d", result.Deleted)
@@ -179,7 +179,7 @@ func TestFormationNOOP(t *testing.T) {
 		Messages: []adapters.Message{
 			{Role: "user", Content: "I like tea"},
 		},
-	})
+	}, "")
 
 	if result.Skipped != 1 {
 		t.Fatalf("expected 1 skipped, got %d", result.Skipped)
@@ -207,7 +207,7 @@ func TestFormationNoFacts(t *testing.T) {
 			{Role: "user", Content: "Hello"},
 			{Role: "assistant", Content: "Hi there!"},
 		},
-	})
+	}, "")
 
 	if result.ExtractedFacts != 0 {
 		t.Fatalf("expected 0 extracted facts, got %d", result.ExtractedFacts)
@@ -243,7 +243,7 @@ func TestFormationMixedActions(t *testing.T) {
 		Messages: []adapters.Message{
 			{Role: "user", Content: "I moved to Berlin and I like dark mode"},
 		},
-	})
+	}, "")
 
 	if result.Added != 1 {
 		t.Fatalf("expected 1 add, got %d", result.Added)
@@ -280,7 +280,7 @@ func TestFormationInvalidActionsSkipped(t *testing.T) {
 		Messages: []adapters.Message{
 			{Role: "user", Content: "I like cats"},
 		},
-	})
+	}, "")
 
 	if result.Added != 1 {
 		t.Fatalf("expected 1 valid add, got %d", result.Added)
@@ -315,7 +315,7 @@ func TestFormationDuplicateActionsSameID(t *testing.T) {
 		Messages: []adapters.Message{
 			{Role: "user", Content: "I changed my mind"},
 		},
-	})
+	}, "")
 
 	if result.Updated != 1 {
 		t.Fatalf("expected 1 update (second should be deduped), got %d", result.Updated)
@@ -0,0 +1,156 @@
+package builtin
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	adapters "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+// rrfK is the rank constant of reciprocal rank fusion. 60 is the value from
+// the original RRF paper and damps the advantage of the very first ranks.
+const rrfK = 60
+
+// searchFusion is the resolved hybrid search setting of a graph runtime.
+type searchFusion struct {
+	mode         string
+	denseWeight  float64
+	sparseWeight float64
+}
+
+// defaultSearchFusion keeps the better of the semantic and lexical score,
+// which is how graph seeds were merged before fusion became configurable.
+func defaultSearchFusion() searchFusion {
+	return searchFusion{mode: adapters.FusionMax, denseWeight: 1, sparseWeight: 1}
+}
+
+// searchFusionFromConfig reads search_fusion, search_dense_weight and
+// search_sparse_weight from a provider config. Invalid values keep the
+// defaults.
+func searchFusionFromConfig(providerConfig map[string]any) searchFusion {
+	override := adapters.SearchFusion{Mode: adapters.StringFromConfig(providerConfig, "search_fusion")}
+	if w, ok := floatFromConfig(providerConfig, "search_dense_weight"); ok {
+		override.DenseWeight = &w
+	}
+	if w, ok := floatFromConfig(providerConfig, "search_sparse_weight"); ok {
+		override.SparseWeight = &w
+	}
+	return defaultSearchFusion().with(override)
+}
+
+// with applies a validated override; an invalid override is ignored.
+func (f searchFusion) with(override adapters.SearchFusion) searchFusion {
+	if override.Validate() != nil {
+		return f
+	}
+	if mode := strings.ToLower(strings.TrimSpace(override.Mode)); mode != "" {
+		f.mode = mode
+	}
+	if override.DenseWeight != nil {
+		f.denseWeight = *override.DenseWeight
+	}
+	if override.SparseWeight != nil {
+		f.sparseWeight = *override.SparseWeight
+	}
+	if f.denseWeight == 0 && f.sparseWeight == 0 {
+		return defaultSearchFusion()
+	}
+	return f
+}
+
+// fuse combines semantic (dense) and lexical (sparse) scores keyed by node
+// ID into one score per node.
+func (f searchFusion) fuse(dense, sparse map[string]float64) map[string]float64 {
+	fused := make(map[string]float64, len(dense)+len(sparse))
+	switch f.mode {
+	case adapters.FusionRRF:
+		for id, rank := range ranks(dense) {
+			fused[id] += f.denseWeight / float64(rrfK+rank)
+		}
+		for id, rank := range ranks(sparse) {
+			fused[id] += f.sparseWeight / float64(rrfK+rank)
+		}
+	case adapters.FusionRelative:
+		for id, score := range normalizeScores(dense) {
+			fused[id] += f.denseWeight * score
+		}
+		for id, score := range normalizeScores(sparse) {
+			fused[id] += f.sparseWeight * score
+		}
+	default:
+		for id, score := range dense {
+			fused[id] = max64(fused[id], f.denseWeight*score)
+		}
+		for id, score := range sparse {
+			fused[id] = max64(fused[id], f.sparseWeight*score)
+		}
+	}
+	return fused
+}
+
+// ranks returns the 1-based rank of each ID by descending score; ties are
+// broken by ID so fusion is deterministic.
+func ranks(scores map[string]float64) map[string]int {
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] == scores[ids[j]] {
+			return ids[i] < ids[j]
+		}
+		return scores[ids[i]] > scores[ids[j]]
+	})
+	out := make(map[string]int, len(ids))
+	for i, id := range ids {
+		out[id] = i + 1
+	}
+	return out
+}
+
+// normalizeScores min-max scales scores into [0, 1]. A list whose scores
+// are all equal maps to 1.
+func normalizeScores(scores map[string]float64) map[string]float64 {
+	if len(scores) == 0 {
+		return nil
+	}
+	lo, hi := 0.0, 0.0
+	first := true
+	for _, s := range scores {
+		if first {
+			lo, hi = s, s
+			first = false
+			continue
+		}
+		lo = min(lo, s)
+		hi = max(hi, s)
+	}
+	out := make(map[string]float64, len(scores))
+	for id, s := range scores {
+		if hi == lo {
+			out[id] = 1
+			continue
+		}
+		out[id] = (s - lo) / (hi - lo)
+	}
+	return out
+}
+
+func floatFromConfig(m map[string]any, key string) (float64, bool) {
+	if m == nil {
+		return 0, false
+	}
+	switch n := m[key].(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,55 @@
+package builtin
+
+import (
+	"math"
+	"testing"
+
+	adapters "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+func TestSearchFusionFromConfig(t *testing.T) {
+	t.Parallel()
+
+	got := searchFusionFromConfig(map[string]any{
+		"search_fusion":        "RRF",
+		"search_dense_weight":  float64(2),
+		"search_sparse_weight": "0.5",
+	})
+	if got.mode != adapters.FusionRRF || got.denseWeight != 2 || got.sparseWeight != 0.5 {
+		t.Fatalf("fusion = %+v", got)
+	}
+	if got := searchFusionFromConfig(map[string]any{"search_fusion": "bogus"}); got != defaultSearchFusion() {
+		t.Fatalf("invalid config fusion = %+v, want default", got)
+	}
+
+	zero := 0.0
+	override := got.with(adapters.SearchFusion{Mode: adapters.FusionRelative, DenseWeight: &zero})
+	if override.mode != adapters.FusionRelative || override.denseWeight != 0 || override.sparseWeight != 0.5 {
+		t.Fatalf("override = %+v", override)
+	}
+}
+
+func TestSearchFusionFuse(t *testing.T) {
+	t.Parallel()
+
+	dense := map[string]float64{"a": 0.9, "b": 0.5}
+	sparse := map[string]float64{"b": 3, "c": 1}
+
+	maxFused := defaultSearchFusion().fuse(dense, sparse)
+	if maxFused["a"] != 0.9 || maxFused["b"] != 3 || maxFused["c"] != 1 {
+		t.Fatalf("max fusion = %+v", maxFused)
+	}
+
+	rrf := searchFusion{mode: adapters.FusionRRF, denseWeight: 1, sparseWeight: 1}.fuse(dense, sparse)
+	if !(rrf["b"] > rrf["a"] && rrf["a"] > rrf["c"]) {
+		t.Fatalf("rrf should rank the node found by both lists first: %+v", rrf)
+	}
+	if want := 1.0/61 + 1.0/62; math.Abs(rrf["b"]-want) > 1e-12 {
+		t.Fatalf("rrf[b] = %v, want %v", rrf["b"], want)
+	}
+
+	relative := searchFusion{mode: adapters.FusionRelative, denseWeight: 3, sparseWeight: 1}.fuse(dense, sparse)
+	if relative["a"] != 3 || relative["b"] != 1 || relative["c"] != 0 {
+		t.Fatalf("relative fusion = %+v", relative)
+	}
+}
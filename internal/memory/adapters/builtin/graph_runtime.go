@@ -26,13 +26,18 @@ const ModeGraph = "graph"
 // store (wikistore.Store) is authoritative; the filesystem store (memoryStore)
 // holds the derived Markdown view the agent reads.
 type graphRuntime struct {
-	store    wikistore.Store
-	fs       memoryStore
-	cache    *graphCache
-	syncer   *graphSync
-	semantic *pgvectorIndex
-	retry    *semanticRetryQueue
-	logger   *slog.Logger
+	store     wikistore.Store
+	fs        memoryStore
+	cache     *graphCache
+	syncer    *graphSync
+	semantic  *pgvectorIndex
+	retry     *semanticRetryQueue
+	reembeds  *reembedJobs
+	fusion    searchFusion
+	reranker  documentReranker
+	expansion queryExpansion
+	expander  adapters.QueryExpander
+	logger    *slog.Logger
 }
 
 // NewGraphRuntime constructs a graphRuntime. wikiStore is required; fs is the
@@ -42,15 +47,41 @@ func NewGraphRuntime(logger *slog.Logger, wikiStore wikistore.Store, fs memorySt
 		logger = slog.Default()
 	}
 	return &graphRuntime{
-		store:  wikiStore,
-		fs:     fs,
-		cache:  newGraphCache(),
-		syncer: newGraphSync(fs, logger),
-		retry:  newSemanticRetryQueue(logger),
-		logger: logger.With("runtime", "graph"),
+		store:    wikiStore,
+		fs:       fs,
+		cache:    newGraphCache(),
+		syncer:   newGraphSync(fs, logger),
+		retry:    newSemanticRetryQueue(logger),
+		reembeds: newReembedJobs(logger),
+		fusion:   defaultSearchFusion(),
+		logger:   logger.With("runtime", "graph"),
 	}
 }
 
+// SetSearchFusion sets how semantic and lexical seed scores are combined
+// when a search does not override it.
+func (r *graphRuntime) SetSearchFusion(fusion searchFusion) {
+	r.fusion = fusion
+}
+
+// SetReranker wires an optional rerank model used by searches that ask for
+// reranking. A nil reranker leaves search results in graph order.
+func (r *graphRuntime) SetReranker(reranker documentReranker) {
+	r.reranker = reranker
+}
+
+// SetQueryExpansion sets whether and how search queries are expanded before
+// seeding.
+func (r *graphRuntime) SetQueryExpansion(expansion queryExpansion) {
+	r.expansion = expansion
+}
+
+// SetQueryExpander wires the model that expands search queries. Without one,
+// searches run the original query only.
+func (r *graphRuntime) SetQueryExpander(expander adapters.QueryExpander) {
+	r.expander = expander
+}
+
 // SetSemanticIndex wires an optional Postgres pgvector seed index. It never
 // owns the memory source of truth; failures only degrade to graph lexical recall.
 func (r *graphRuntime) SetSemanticIndex(ctx context.Context, index *pgvectorIndex) {
@@ -72,6 +103,9 @@ func (r *graphRuntime) Close() error {
 	if r.retry != nil {
 		r.retry.stop()
 	}
+	if r.reembeds != nil {
+		r.reembeds.stop()
+	}
 	return nil
 }
 
@@ -111,6 +145,13 @@ func (r *graphRuntime) semanticUpsertBestEffort(botID string, n migrate.NodeSpec
 	ctx, cancel := context.WithTimeout(context.Background(), semanticEmbedTimeout)
 	defer cancel()
 	if err := r.semantic.Upsert(ctx, botID, n.ID, n.Body, n.Hash); err != nil {
+		if errors.Is(err, errEmbeddingDimensionMismatch) {
+			// Retrying cannot succeed until the model is reconfigured; the
+			// re-embedding job indexes the node from the wiki store instead.
+			r.logger.Debug("graph: pgvector upsert quarantined", "bot_id", botID, "node_id", n.ID, "err", err)
+			r.retry.discard(botID, []string{n.ID})
+			return
+		}
 		r.logger.Debug("graph: pgvector upsert failed; queued for retry", "bot_id", botID, "node_id", n.ID, "err", err)
 		r.retry.enqueue(semanticRetryEntry{botID: botID, nodeID: n.ID, body: n.Body, hash: n.Hash})
 		return
@@ -140,8 +181,11 @@ func (r *graphRuntime) Add(ctx context.Context, req adapters.AddRequest) (adapte
 		Hash:      runtimeHash(text),
 		CreatedAt: now.Format(time.RFC3339),
 		UpdatedAt: now.Format(time.RFC3339),
-		Metadata:  req.Metadata,
+		Metadata:  adapters.WithUserScope(req.Metadata, runtimeUserID(req.UserID, req.Filters)),
 	}, botID)
+	if req.TTL > 0 {
+		spec.ExpiresAt = now.Add(req.TTL)
+	}
 
 	saved, err := r.store.UpsertNode(ctx, spec)
 	if err != nil {
@@ -160,64 +204,107 @@ func (r *graphRuntime) Search(ctx context.Context, req adapters.SearchRequest) (
 	if err != nil {
 		return adapters.SearchResponse{}, err
 	}
+	userID := runtimeUserID(req.UserID, req.Filters)
 	limit := req.Limit
 	if limit <= 0 {
 		limit = 10
 	}
 
 	// Primary path: graph seed-then-expand over the cached PG graph.
-	resp, graphErr := r.searchGraph(ctx, botID, req.Query, limit)
+	fusion := r.fusion
+	if req.Fusion != nil {
+		fusion = fusion.with(*req.Fusion)
+	}
+	rerank := req.Rerank && r.reranker != nil && strings.TrimSpace(req.Query) != ""
+	candidates := limit
+	if rerank && candidates < rerankCandidates {
+		candidates = rerankCandidates
+	}
+	resp, graphErr := r.searchGraph(ctx, botID, userID, req.Query, candidates, fusion)
 	if graphErr == nil {
+		if rerank {
+			reranked, err := rerankItems(ctx, r.reranker, req.Query, resp.Results, limit)
+			if err == nil {
+				resp.Results = reranked
+				return resp, nil
+			}
+			r.logger.Warn("memory rerank failed, keeping graph order", "bot_id", botID, "err", err)
+		}
+		if len(resp.Results) > limit {
+			resp.Results = resp.Results[:limit]
+		}
 		return resp, nil
 	}
 
 	// Reliability fallback: degrade to file-lexical over the derived Markdown.
 	r.logger.Warn("graph search failed, falling back to file lexical", "bot_id", botID, "err", graphErr)
-	fallback, err := r.searchFileFallback(ctx, botID, req.Query, limit)
+	fallback, err := r.searchFileFallback(ctx, botID, userID, req.Query, limit)
 	if fallback.FallbackReason == "" {
 		fallback.FallbackReason = "graph_error"
 	}
 	return fallback, err
 }
 
-// searchGraph runs seed-then-expand: lexical-score nodes -> top-K seeds ->
-// BFS expand along edges -> merge -> populate Relations.
-func (r *graphRuntime) searchGraph(ctx context.Context, botID, query string, limit int) (adapters.SearchResponse, error) {
+// searchGraph runs seed-then-expand: semantic and lexical scores fused into
+// top-K seeds -> BFS expand along edges -> merge -> populate Relations.
+// With a userID, other users' memories are neither returned nor expanded
+// through.
+func (r *graphRuntime) searchGraph(ctx context.Context, botID, userID, query string, limit int, fusion searchFusion) (adapters.SearchResponse, error) {
 	graph, err := r.cache.getOrBuild(ctx, botID, r.store)
 	if err != nil {
 		return adapters.SearchResponse{}, err
 	}
 	nodes := graph.nodeSlice()
+	visible := func(n migrate.NodeSpec) bool { return adapters.VisibleToUser(n.Metadata, userID) }
 
 	overfetch := limit * 3
 	if overfetch < 10 {
 		overfetch = 10
 	}
 
-	// 1. Seed: pgvector semantic seeds when configured, plus lexical seeds.
+	// 1. Seed: pgvector semantic (dense) seeds when configured, plus lexical
+	// (sparse) seeds, fused per the runtime's hybrid search setting.
 	type seed struct {
 		id    string
 		score float64
 	}
-	seedScores := map[string]float64{}
+	// Expanded queries (translations or synonyms) seed alongside the
+	// original, so memories written in another language are still found.
+	expanded := r.expandQuery(ctx, botID, query, nodes)
+	denseScores := map[string]float64{}
 	if r.semantic != nil {
-		if semanticSeeds, semanticErr := r.semantic.SearchSeeds(ctx, botID, query, overfetch); semanticErr != nil {
-			r.logger.Debug("graph: pgvector seed search failed, using lexical seeds", "bot_id", botID, "err", semanticErr)
-		} else {
+		for i, q := range append([]string{query}, expanded...) {
+			semanticSeeds, semanticErr := r.semantic.SearchSeeds(ctx, botID, q, overfetch)
+			if semanticErr != nil {
+				r.logger.Debug("graph: pgvector seed search failed, using lexical seeds", "bot_id", botID, "err", semanticErr)
+				break
+			}
+			weight := 1.0
+			if i > 0 {
+				weight = expandedQueryWeight
+			}
 			for id, score := range semanticSeeds {
-				if _, ok := graph.nodes[id]; ok {
-					seedScores[id] = max64(seedScores[id], score)
+				if n, ok := graph.nodes[id]; ok && visible(n) {
+					denseScores[id] = max64(denseScores[id], score*weight)
 				}
 			}
 		}
 	}
+	sparseScores := map[string]float64{}
 	for _, n := range nodes {
+		if !visible(n) {
+			continue
+		}
 		s := graphLexicalScore(query, n.Body)
+		for _, q := range expanded {
+			s = max64(s, graphLexicalScore(q, n.Body)*expandedQueryWeight)
+		}
 		if s <= 0 && strings.TrimSpace(query) != "" {
 			continue
 		}
-		seedScores[n.ID] = max64(seedScores[n.ID], s)
+		sparseScores[n.ID] = s
 	}
+	seedScores := fusion.fuse(denseScores, sparseScores)
 	seeds := make([]seed, 0, len(seedScores))
 	for id, score := range seedScores {
 		seeds = append(seeds, seed{id: id, score: score})
@@ -245,12 +332,15 @@ func (r *graphRuntime) searchGraph(ctx context.Context, botID, query string, lim
 		scores[s.id] = hit{score: max64(scores[s.id].score, s.score)}
 		// depth 1
 		for _, nb := range graph.neighbors(s.id) {
+			if !visible(nb.node) {
+				continue
+			}
 			ns := float64(s.score) * float64(nb.weight) * decay
 			scores[nb.node.ID] = hit{score: max64(scores[nb.node.ID].score, ns)}
 			addEdge(s.id, nb.node.ID, string(nb.rel))
 			// depth 2
 			for _, nb2 := range graph.neighbors(nb.node.ID) {
-				if nb2.node.ID == s.id {
+				if nb2.node.ID == s.id || !visible(nb2.node) {
 					continue
 				}
 				ns2 := ns * decay
@@ -294,7 +384,7 @@ func (r *graphRuntime) searchGraph(ctx context.Context, botID, query string, lim
 // searchFileFallback is the reliability fallback: read the derived Markdown via
 // the bridge and score lexically, exactly like fileRuntime. Used when the PG
 // graph is unavailable.
-func (r *graphRuntime) searchFileFallback(ctx context.Context, botID, query string, limit int) (adapters.SearchResponse, error) {
+func (r *graphRuntime) searchFileFallback(ctx context.Context, botID, userID, query string, limit int) (adapters.SearchResponse, error) {
 	if r.fs == nil {
 		return adapters.SearchResponse{}, nil
 	}
@@ -305,6 +395,9 @@ func (r *graphRuntime) searchFileFallback(ctx context.Context, botID, query stri
 	q := strings.ToLower(strings.TrimSpace(query))
 	results := make([]adapters.MemoryItem, 0, len(items))
 	for _, it := range items {
+		if !adapters.VisibleToUser(it.Metadata, userID) {
+			continue
+		}
 		score := graphLexicalScore(q, it.Memory)
 		if q != "" && score <= 0 {
 			continue
@@ -334,11 +427,12 @@ func (r *graphRuntime) GetAll(ctx context.Context, req adapters.GetAllRequest) (
 	if err != nil {
 		return adapters.SearchResponse{}, err
 	}
+	userID := runtimeUserID(req.UserID, req.Filters)
 	nodes, err := r.store.ListNodes(ctx, botID)
 	if err != nil {
 		// Fallback to derived files if the store is unavailable.
 		r.logger.Warn("graph GetAll failed, falling back to files", "bot_id", botID, "err", err)
-		fallback, fallbackErr := r.searchFileFallback(ctx, botID, "", req.Limit)
+		fallback, fallbackErr := r.searchFileFallback(ctx, botID, userID, "", req.Limit)
 		if fallback.FallbackReason == "" {
 			fallback.FallbackReason = "graph_error"
 		}
@@ -346,7 +440,9 @@ func (r *graphRuntime) GetAll(ctx context.Context, req adapters.GetAllRequest) (
 	}
 	out := make([]adapters.MemoryItem, 0, len(nodes))
 	for _, n := range nodes {
-		out = append(out, nodeSpecToMemoryItem(n))
+		if adapters.VisibleToUser(n.Metadata, userID) {
+			out = append(out, nodeSpecToMemoryItem(n))
+		}
 	}
 	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt > out[j].CreatedAt })
 	if req.Limit > 0 && len(out) > req.Limit {
@@ -446,6 +542,25 @@ func (r *graphRuntime) DeleteBatch(ctx context.Context, memoryIDs []string) (ada
 	return adapters.DeleteResponse{Message: "Memories deleted successfully!"}, nil
 }
 
+// ExpireMemories deletes the bot's nodes whose TTL has passed and drops them
+// from the semantic index and the derived Markdown view.
+func (r *graphRuntime) ExpireMemories(ctx context.Context, botID string, now time.Time) (int, error) {
+	if r.store == nil {
+		return 0, errors.New("graph runtime: wiki store not configured")
+	}
+	ids, err := r.store.DeleteExpiredNodes(ctx, botID, now)
+	if err != nil {
+		return 0, fmt.Errorf("graph runtime: delete expired nodes: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	r.retry.discard(botID, ids)
+	r.discardSemanticNodes(ctx, botID, ids)
+	r.syncAndInvalidate(ctx, botID)
+	return len(ids), nil
+}
+
 func (r *graphRuntime) resolveNodeByMemoryID(ctx context.Context, botID, memoryID string) (migrate.NodeSpec, string, error) {
 	memoryID = strings.TrimSpace(memoryID)
 	node, err := r.store.GetNode(ctx, botID, memoryID)
@@ -561,7 +676,9 @@ func (r *graphRuntime) Status(ctx context.Context, botID string) (adapters.Memor
 			}
 		}
 		resp.RetryQueueDepth = r.retry.depth(botID)
-		resp.Degraded = resp.RetryQueueDepth > 0 || resp.Pgvector == nil || !resp.Pgvector.OK
+		resp.EmbeddingDimensions = r.semantic.dimensionStatus(botID)
+		resp.Reembed = r.reembeds.status(botID)
+		resp.Degraded = resp.RetryQueueDepth > 0 || resp.EmbeddingDimensions != nil || resp.Pgvector == nil || !resp.Pgvector.OK
 	}
 	if r.fs != nil {
 		if fc, err := r.fs.CountMemoryFiles(ctx, botID); err == nil {
@@ -606,6 +723,31 @@ func (r *graphRuntime) Rebuild(ctx context.Context, botID string) (adapters.Rebu
 	return result, nil
 }
 
+// StartReembed reloads the embedding model and re-indexes every node of the
+// bot in the background, rate-limited. It is the way out of a dimension
+// drift: once the model's dimensions match the provider again, the bot's
+// quarantine lifts and the job re-embeds what was written meanwhile.
+func (r *graphRuntime) StartReembed(ctx context.Context, botID string) (adapters.ReembedStatus, error) {
+	if r.store == nil {
+		return adapters.ReembedStatus{}, errors.New("graph runtime: wiki store not configured")
+	}
+	if r.semantic == nil {
+		return adapters.ReembedStatus{}, adapters.ErrReembedUnavailable
+	}
+	if status := r.reembeds.status(botID); status != nil && status.Running {
+		return adapters.ReembedStatus{}, adapters.ErrReembedRunning
+	}
+	if err := r.semantic.reloadModel(ctx, botID); err != nil {
+		return adapters.ReembedStatus{}, err
+	}
+	nodes, err := r.store.ListNodes(ctx, botID)
+	if err != nil {
+		return adapters.ReembedStatus{}, fmt.Errorf("graph runtime: re-embed list: %w", err)
+	}
+	r.retry.discardBot(botID)
+	return r.reembeds.start(botID, nodes, r.semantic)
+}
+
 // ---- node/memory item conversion ----
 
 // memoryItemToNodeSpec derives a NodeSpec from a MemoryItem, classifying the
@@ -636,6 +778,7 @@ func memoryItemToNodeSpec(item adapters.MemoryItem, botID string) migrate.NodeSp
 		ProfileRef: profileRef,
 		Topic:      metadataStringVal(item.Metadata, "topic"),
 		CapturedAt: parseGraphTime(item.CreatedAt),
+		ExpiresAt:  metadataTimeVal(item.Metadata, "expires_at"),
 	}
 }
 
@@ -677,6 +820,20 @@ func metadataFloatVal(m map[string]any, key string, def float32) float32 {
 	return def
 }
 
+// metadataTimeVal reads an RFC 3339 timestamp, returning the zero time when
+// the key is missing or malformed.
+func metadataTimeVal(m map[string]any, key string) time.Time {
+	raw := metadataStringVal(m, key)
+	if raw == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
 func parseGraphTime(s string) time.Time {
 	s = strings.TrimSpace(s)
 	if s == "" {
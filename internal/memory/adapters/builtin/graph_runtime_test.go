@@ -66,6 +66,17 @@ func (s *fakeWikiStore) DeleteAllNodes(_ context.Context, _ string) error {
 	return nil
 }
 
+func (s *fakeWikiStore) DeleteExpiredNodes(_ context.Context, _ string, now time.Time) ([]string, error) {
+	var ids []string
+	for id, n := range s.nodes {
+		if !n.ExpiresAt.IsZero() && !n.ExpiresAt.After(now) {
+			ids = append(ids, id)
+			delete(s.nodes, id)
+		}
+	}
+	return ids, nil
+}
+
 func (s *fakeWikiStore) CountNodes(_ context.Context, _ string) (int, error) {
 	return len(s.nodes), nil
 }
@@ -200,6 +211,41 @@ func TestGraphRuntimeAddSearchDelete(t *testing.T) {
 	}
 }
 
+func TestGraphRuntimeExpireMemories(t *testing.T) {
+	t.Parallel()
+	store := newFakeWikiStore()
+	rt := NewGraphRuntime(nil, store, newFakeStore())
+
+	botID := "graph-bot-ttl"
+	ctx := context.Background()
+	added, err := rt.Add(ctx, adapters.AddRequest{BotID: botID, Message: "Travelling in Lisbon this week", TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("Add with TTL: %v", err)
+	}
+	if _, err := rt.Add(ctx, adapters.AddRequest{BotID: botID, Message: "Allergic to peanuts"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	expiresAt, _ := added.Results[0].Metadata["expires_at"].(string)
+	if expiresAt == "" {
+		t.Fatalf("expected expires_at in metadata, got %+v", added.Results[0].Metadata)
+	}
+
+	if n, err := rt.ExpireMemories(ctx, botID, time.Now()); err != nil || n != 0 {
+		t.Fatalf("early expiry = %d, %v", n, err)
+	}
+	n, err := rt.ExpireMemories(ctx, botID, time.Now().Add(2*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("expiry = %d, %v", n, err)
+	}
+	all, err := rt.GetAll(ctx, adapters.GetAllRequest{BotID: botID})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(all.Results) != 1 || all.Results[0].Memory != "Allergic to peanuts" {
+		t.Fatalf("GetAll after expiry = %+v", all.Results)
+	}
+}
+
 func TestGraphRuntimeSearchExpandsRefs(t *testing.T) {
 	t.Parallel()
 	store := newFakeWikiStore()
@@ -580,8 +626,11 @@ func (errWikiStore) ListNodes(context.Context, string) ([]migrate.NodeSpec, erro
 func (errWikiStore) ListNodesByLayer(context.Context, string, migrate.MemoryLayer) ([]migrate.NodeSpec, error) {
 	return nil, errForced
 }
-func (errWikiStore) DeleteNode(context.Context, string, string) error      { return nil }
-func (errWikiStore) DeleteAllNodes(context.Context, string) error          { return nil }
+func (errWikiStore) DeleteNode(context.Context, string, string) error { return nil }
+func (errWikiStore) DeleteAllNodes(context.Context, string) error     { return nil }
+func (errWikiStore) DeleteExpiredNodes(context.Context, string, time.Time) ([]string, error) {
+	return nil, errForced
+}
 func (errWikiStore) CountNodes(context.Context, string) (int, error)       { return 0, errForced }
 func (errWikiStore) UpsertEdges(context.Context, []migrate.EdgeSpec) error { return nil }
 func (errWikiStore) ListEdges(context.Context, string) ([]migrate.EdgeSpec, error) {
@@ -626,3 +675,49 @@ func TestGraphRuntimeSearchCJKSentence(t *testing.T) {
 		t.Fatalf("CJK search result = %q, want the 中文交流 memory", resp.Results[0].Memory)
 	}
 }
+
+func TestGraphRuntimeSearchScopesToUser(t *testing.T) {
+	t.Parallel()
+	store := newFakeWikiStore()
+	rt := NewGraphRuntime(nil, store, newFakeStore())
+
+	botID := "graph-bot-users"
+	ctx := context.Background()
+	for _, req := range []adapters.AddRequest{
+		{BotID: botID, Message: "alice prefers green tea", UserID: "alice"},
+		{BotID: botID, Message: "bob prefers black tea", Filters: map[string]any{"user_id": "bob"}},
+		{BotID: botID, Message: "the team prefers tea breaks at four"},
+	} {
+		if _, err := rt.Add(ctx, req); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	resp, err := rt.Search(ctx, adapters.SearchRequest{BotID: botID, UserID: "alice", Query: "prefers tea", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("alice sees %d memories, want 2: %+v", len(resp.Results), resp.Results)
+	}
+	for _, item := range resp.Results {
+		if strings.Contains(item.Memory, "bob") {
+			t.Fatalf("alice sees bob's memory %q", item.Memory)
+		}
+	}
+
+	all, err := rt.GetAll(ctx, adapters.GetAllRequest{BotID: botID, UserID: "bob"})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(all.Results) != 2 {
+		t.Fatalf("bob lists %d memories, want 2", len(all.Results))
+	}
+	unscoped, err := rt.Search(ctx, adapters.SearchRequest{BotID: botID, Query: "prefers tea", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(unscoped.Results) != 3 {
+		t.Fatalf("unscoped search returned %d memories, want 3", len(unscoped.Results))
+	}
+}
@@ -137,6 +137,9 @@ func buildNodeMetadata(n migrate.NodeSpec) map[string]any {
 	if n.Topic != "" {
 		meta["topic"] = n.Topic
 	}
+	if !n.ExpiresAt.IsZero() {
+		meta["expires_at"] = formatNodeTime(n.ExpiresAt)
+	}
 	return meta
 }
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync/atomic"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	sdk "github.com/memohai/twilight-ai/sdk"
@@ -35,6 +36,10 @@ type pgvectorIndex struct {
 	modelRef    string
 	resolveTeam adapters.TeamIDResolver
 	logger      *slog.Logger
+	// dimensions is the configured vector size. It starts from model and is
+	// replaced when a re-embedding job reloads the model.
+	dimensions atomic.Int64
+	drift      dimensionDrift
 }
 
 type embeddingModelSpec struct {
@@ -54,7 +59,7 @@ func newPGVectorIndex(ctx context.Context, logger *slog.Logger, providerConfig m
 	if logger == nil {
 		logger = slog.Default()
 	}
-	if vectorStore == nil || vectorStore.Queries() == nil {
+	if vectorStore == nil {
 		logger.Debug("graph: pgvector semantic index unavailable", slog.String("embedding_model_id", modelRef))
 		return nil, nil
 	}
@@ -78,6 +83,7 @@ func newPGVectorIndex(ctx context.Context, logger *slog.Logger, providerConfig m
 		resolveTeam: resolver,
 		logger:      logger,
 	}
+	index.dimensions.Store(int64(spec.dimensions))
 	return index, nil
 }
 
@@ -159,7 +165,11 @@ func (r *pgvectorIndex) withTeamTx(ctx context.Context, fn func(*pgvectorsqlc.Qu
 	return nil
 }
 
-func (r *pgvectorIndex) embedText(ctx context.Context, text string) ([]float32, error) {
+func (r *pgvectorIndex) embedText(ctx context.Context, botID, text string) ([]float32, error) {
+	botID = strings.TrimSpace(botID)
+	if err := r.quarantined(botID); err != nil {
+		return nil, err
+	}
 	if err := r.ensureEmbeddingEnabled(ctx); err != nil {
 		return nil, err
 	}
@@ -169,8 +179,8 @@ func (r *pgvectorIndex) embedText(ctx context.Context, text string) ([]float32,
 		return nil, fmt.Errorf("pgvector semantic embed: %w", err)
 	}
 	out := float64sToFloat32s(vec)
-	if r.model.dimensions > 0 && len(out) != r.model.dimensions {
-		return nil, fmt.Errorf("pgvector semantic index: embedding dimensions = %d, want %d", len(out), r.model.dimensions)
+	if err := r.checkDimensions(botID, len(out)); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
@@ -183,8 +193,11 @@ func (r *pgvectorIndex) Upsert(ctx context.Context, botID, nodeID, body, hash st
 	if err != nil {
 		return err
 	}
-	vec, err := r.embedText(ctx, body)
+	vec, err := r.embedText(ctx, botID, body)
 	if err != nil {
+		if errors.Is(err, errEmbeddingDimensionMismatch) {
+			r.drift.quarantine(strings.TrimSpace(botID))
+		}
 		return err
 	}
 	dimensions, err := checkedPgvectorInt32("dimensions", len(vec))
@@ -216,7 +229,11 @@ func (r *pgvectorIndex) SearchSeeds(ctx context.Context, botID, query string, li
 	if err != nil {
 		return nil, err
 	}
-	vec, err := r.embedText(ctx, query)
+	vec, err := r.embedText(ctx, botID, query)
+	if err != nil {
+		return nil, err
+	}
+	dimensions, err := checkedPgvectorInt32("dimensions", len(vec))
 	if err != nil {
 		return nil, err
 	}
@@ -227,11 +244,12 @@ func (r *pgvectorIndex) SearchSeeds(ctx context.Context, botID, query string, li
 	seeds := map[string]float64{}
 	err = r.withTeamTx(ctx, func(teamQueries *pgvectorsqlc.Queries, teamUUID pgtype.UUID) error {
 		rows, queryErr := teamQueries.SearchMemoryNodeEmbeddings(ctx, pgvectorsqlc.SearchMemoryNodeEmbeddingsParams{
-			Embedding: pgvector.NewVector(vec),
-			TeamID:    teamUUID,
-			BotID:     botUUID,
-			ModelID:   r.model.uuid,
-			RowLimit:  rowLimit,
+			Embedding:  pgvector.NewVector(vec),
+			TeamID:     teamUUID,
+			BotID:      botUUID,
+			ModelID:    r.model.uuid,
+			Dimensions: dimensions,
+			RowLimit:   rowLimit,
 		})
 		if queryErr != nil {
 			return queryErr
@@ -0,0 +1,202 @@
+package builtin
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	adapters "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/memory/migrate"
+)
+
+const (
+	// maxExpandedQueries bounds the extra queries one search runs, each of
+	// which costs an embedding call when a semantic index is configured.
+	maxExpandedQueries = 3
+	// expandedQueryWeight ranks matches of an expanded query slightly below
+	// equally good matches of the query the user actually wrote.
+	expandedQueryWeight   = 0.9
+	queryExpansionTimeout = 5 * time.Second
+	// languageSampleNodes bounds how many memories are inspected to find a
+	// bot's dominant languages.
+	languageSampleNodes = 500
+	// minLanguageShare is the share of memories a language needs to count
+	// as dominant.
+	minLanguageShare = 0.2
+	maxDominantLangs = 3
+)
+
+// languageNames maps the language codes detectLanguage returns to the names
+// given to the expansion model.
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"el": "Greek",
+	"en": "English",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"ru": "Russian",
+	"th": "Thai",
+	"zh": "Chinese",
+}
+
+// queryExpansion is the resolved query_expansion setting of a graph runtime.
+type queryExpansion struct {
+	mode      string
+	languages []string
+}
+
+// queryExpansionFromConfig reads query_expansion and
+// query_expansion_languages from a provider config. Unknown modes turn
+// expansion off.
+func queryExpansionFromConfig(providerConfig map[string]any) queryExpansion {
+	out := queryExpansion{mode: adapters.QueryExpansionOff}
+	switch mode := strings.ToLower(strings.TrimSpace(adapters.StringFromConfig(providerConfig, "query_expansion"))); mode {
+	case adapters.QueryExpansionTranslate, adapters.QueryExpansionSynonyms:
+		out.mode = mode
+	}
+	var raw []string
+	switch v := providerConfig["query_expansion_languages"].(type) {
+	case string:
+		raw = strings.Split(v, ",")
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	case []string:
+		raw = v
+	}
+	for _, lang := range raw {
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+			out.languages = append(out.languages, lang)
+		}
+	}
+	return out
+}
+
+// expandQuery returns the alternate queries to search besides query, or nil
+// when expansion is off, unnecessary, or fails. Failures never fail the
+// search; it just runs with the original query only.
+func (r *graphRuntime) expandQuery(ctx context.Context, botID, query string, nodes []migrate.NodeSpec) []string {
+	if r.expander == nil || strings.TrimSpace(query) == "" {
+		return nil
+	}
+	switch r.expansion.mode {
+	case adapters.QueryExpansionTranslate, adapters.QueryExpansionSynonyms:
+	default:
+		return nil
+	}
+	req := adapters.ExpandQueryRequest{BotID: botID, Query: query, Max: maxExpandedQueries}
+	if r.expansion.mode == adapters.QueryExpansionTranslate {
+		languages := r.expansion.languages
+		if len(languages) == 0 {
+			languages = dominantLanguages(nodes)
+		}
+		own := detectLanguage(query)
+		for _, lang := range languages {
+			if lang == own {
+				continue
+			}
+			if name, ok := languageNames[lang]; ok {
+				lang = name
+			}
+			req.Languages = append(req.Languages, lang)
+		}
+		if len(req.Languages) == 0 {
+			return nil
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, queryExpansionTimeout)
+	defer cancel()
+	resp, err := r.expander.ExpandQuery(ctx, req)
+	if err != nil {
+		r.logger.Debug("graph: query expansion failed, searching the original query only", "bot_id", botID, "err", err)
+		return nil
+	}
+	queries := resp.Queries
+	if len(queries) > maxExpandedQueries {
+		queries = queries[:maxExpandedQueries]
+	}
+	return queries
+}
+
+// dominantLanguages returns the languages at least minLanguageShare of the
+// sampled memories are written in, most common first.
+func dominantLanguages(nodes []migrate.NodeSpec) []string {
+	counts := map[string]int{}
+	total := 0
+	for _, n := range nodes {
+		if total == languageSampleNodes {
+			break
+		}
+		if lang := detectLanguage(n.Body); lang != "" {
+			counts[lang]++
+			total++
+		}
+	}
+	langs := make([]string, 0, len(counts))
+	for lang, n := range counts {
+		if float64(n) >= minLanguageShare*float64(total) {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if counts[langs[i]] != counts[langs[j]] {
+			return counts[langs[i]] > counts[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+	if len(langs) > maxDominantLangs {
+		langs = langs[:maxDominantLangs]
+	}
+	return langs
+}
+
+// detectLanguage guesses the language of text from its script. Latin script
+// is reported as English; bots with memories in other Latin-script languages
+// should set query_expansion_languages. Returns "" for text without letters.
+func detectLanguage(text string) string {
+	counts := map[string]int{}
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"] += 3
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"] += 3
+		case unicode.Is(unicode.Han, r):
+			// One ideograph carries about as much as a short word.
+			counts["zh"] += 3
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Latin, r):
+			counts["en"]++
+		}
+	}
+	// Japanese text mixes kana with kanji.
+	if counts["ja"] > 0 {
+		counts["ja"] += counts["zh"]
+		delete(counts, "zh")
+	}
+	best, bestCount := "", 0
+	for lang, n := range counts {
+		if n > bestCount || (n == bestCount && lang < best) {
+			best, bestCount = lang, n
+		}
+	}
+	return best
+}
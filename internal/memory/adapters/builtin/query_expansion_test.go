@@ -0,0 +1,88 @@
+package builtin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	adapters "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+type fakeQueryExpander struct {
+	queries []string
+	reqs    []adapters.ExpandQueryRequest
+}
+
+func (f *fakeQueryExpander) ExpandQuery(_ context.Context, req adapters.ExpandQueryRequest) (adapters.ExpandQueryResponse, error) {
+	f.reqs = append(f.reqs, req)
+	return adapters.ExpandQueryResponse{Queries: f.queries}, nil
+}
+
+func TestGraphRuntimeSearchTranslatesQueryIntoMemoryLanguage(t *testing.T) {
+	t.Parallel()
+	rt := NewGraphRuntime(nil, newFakeWikiStore(), newFakeStore())
+	ctx := context.Background()
+	botID := "graph-bot-expand"
+	for _, msg := range []string{"我喜欢喝乌龙茶", "我住在柏林"} {
+		if _, err := rt.Add(ctx, adapters.AddRequest{BotID: botID, Message: msg}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	resp, err := rt.Search(ctx, adapters.SearchRequest{BotID: botID, Query: "oolong tea", Limit: 5})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(resp.Results) != 0 {
+		t.Fatalf("unexpanded search found %d results, want 0", len(resp.Results))
+	}
+
+	expander := &fakeQueryExpander{queries: []string{"乌龙茶"}}
+	rt.SetQueryExpansion(queryExpansionFromConfig(map[string]any{"query_expansion": "translate"}))
+	rt.SetQueryExpander(expander)
+	resp, err = rt.Search(ctx, adapters.SearchRequest{BotID: botID, Query: "oolong tea", Limit: 5})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(resp.Results) == 0 || !strings.Contains(resp.Results[0].Memory, "乌龙茶") {
+		t.Fatalf("expanded search results = %+v, want the oolong memory first", resp.Results)
+	}
+	if len(expander.reqs) != 1 || strings.Join(expander.reqs[0].Languages, ",") != "Chinese" {
+		t.Fatalf("expand requests = %+v, want one translation into Chinese", expander.reqs)
+	}
+}
+
+func TestGraphRuntimeSkipsTranslationIntoQueryLanguage(t *testing.T) {
+	t.Parallel()
+	rt := NewGraphRuntime(nil, newFakeWikiStore(), newFakeStore())
+	ctx := context.Background()
+	if _, err := rt.Add(ctx, adapters.AddRequest{BotID: "graph-bot-same", Message: "I prefer oolong tea"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	expander := &fakeQueryExpander{}
+	rt.SetQueryExpansion(queryExpansionFromConfig(map[string]any{"query_expansion": "translate"}))
+	rt.SetQueryExpander(expander)
+	if _, err := rt.Search(ctx, adapters.SearchRequest{BotID: "graph-bot-same", Query: "tea", Limit: 5}); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(expander.reqs) != 0 {
+		t.Fatalf("expander called %d times for a query already in the memory language", len(expander.reqs))
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	t.Parallel()
+	for text, want := range map[string]string{
+		"I prefer oolong tea": "en",
+		"我喜欢喝乌龙茶":             "zh",
+		"私はお茶が好きです":           "ja",
+		"나는 차를 좋아해요":          "ko",
+		"Я люблю чай":         "ru",
+		"我喜欢 oolong":          "zh",
+		"12345":               "",
+	} {
+		if got := detectLanguage(text); got != want {
+			t.Errorf("detectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
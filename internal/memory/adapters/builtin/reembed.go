@@ -0,0 +1,136 @@
+//nolint:sloglint // re-embedding job logs use inline key/value pairs like the retry queue
+package builtin
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	adapters "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/memory/migrate"
+)
+
+// reembedInterval spaces the embedding calls of a re-embedding job so a
+// large bot does not trip the provider's rate limits or starve chat traffic.
+const reembedInterval = 500 * time.Millisecond
+
+// reembedJobs runs at most one background re-embedding job per bot and keeps
+// the last job's progress for Status.
+type reembedJobs struct {
+	interval time.Duration
+
+	mu     sync.Mutex
+	jobs   map[string]*adapters.ReembedStatus
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *slog.Logger
+}
+
+func newReembedJobs(logger *slog.Logger) *reembedJobs {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &reembedJobs{
+		interval: reembedInterval,
+		jobs:     map[string]*adapters.ReembedStatus{},
+		ctx:      ctx,
+		cancel:   cancel,
+		logger:   logger,
+	}
+}
+
+// start re-embeds nodes into index in the background, one per interval.
+// Nodes are indexed from their stored body, so writes quarantined while the
+// provider's dimensions were wrong are picked up too.
+func (j *reembedJobs) start(botID string, nodes []migrate.NodeSpec, index semanticUpserter) (adapters.ReembedStatus, error) {
+	j.mu.Lock()
+	if current, ok := j.jobs[botID]; ok && current.Running {
+		j.mu.Unlock()
+		return adapters.ReembedStatus{}, adapters.ErrReembedRunning
+	}
+	if j.ctx.Err() != nil {
+		j.mu.Unlock()
+		return adapters.ReembedStatus{}, adapters.ErrReembedUnavailable
+	}
+	status := &adapters.ReembedStatus{Running: true, Total: len(nodes), StartedAt: time.Now().UTC()}
+	j.jobs[botID] = status
+	snapshot := *status
+	j.wg.Add(1)
+	j.mu.Unlock()
+
+	go func() {
+		defer j.wg.Done()
+		j.run(botID, nodes, index, status)
+	}()
+	return snapshot, nil
+}
+
+func (j *reembedJobs) run(botID string, nodes []migrate.NodeSpec, index semanticUpserter, status *adapters.ReembedStatus) {
+	limiter := rate.NewLimiter(rate.Every(j.interval), 1)
+	var runErr error
+	for _, node := range nodes {
+		if strings.TrimSpace(node.Body) == "" {
+			j.progress(status, nil)
+			continue
+		}
+		if runErr = limiter.Wait(j.ctx); runErr != nil {
+			break
+		}
+		ctx, cancel := context.WithTimeout(j.ctx, semanticEmbedTimeout)
+		err := index.Upsert(ctx, botID, node.ID, node.Body, node.Hash)
+		cancel()
+		if err != nil {
+			j.logger.Warn("memory re-embed failed", "bot_id", botID, "node_id", node.ID, "err", err)
+		}
+		j.progress(status, err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	finished := time.Now().UTC()
+	status.Running = false
+	status.FinishedAt = &finished
+	if runErr != nil {
+		status.Error = runErr.Error()
+	}
+	j.logger.Info("memory re-embed finished", "bot_id", botID, "done", status.Done, "failed", status.Failed, "total", status.Total)
+}
+
+func (j *reembedJobs) progress(status *adapters.ReembedStatus, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		status.Failed++
+		return
+	}
+	status.Done++
+}
+
+// status returns the bot's running or most recent job, or nil.
+func (j *reembedJobs) status(botID string) *adapters.ReembedStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	current, ok := j.jobs[botID]
+	if !ok {
+		return nil
+	}
+	snapshot := *current
+	return &snapshot
+}
+
+// stop cancels running jobs and waits for them to exit.
+func (j *reembedJobs) stop() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.cancel()
+	j.mu.Unlock()
+	j.wg.Wait()
+}
@@ -0,0 +1,143 @@
+package builtin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	adapters "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/memory/migrate"
+)
+
+func TestPGVectorDimensionDriftQuarantinesPerBot(t *testing.T) {
+	t.Parallel()
+	index := &pgvectorIndex{modelRef: "embed"}
+	index.dimensions.Store(1536)
+
+	if err := index.checkDimensions("bot-a", 1536); err != nil {
+		t.Fatalf("checkDimensions(configured) error = %v", err)
+	}
+	for _, botID := range []string{"bot-a", "bot-b"} {
+		if err := index.checkDimensions(botID, 3072); !errors.Is(err, errEmbeddingDimensionMismatch) {
+			t.Fatalf("checkDimensions(%s, 3072) error = %v, want dimension mismatch", botID, err)
+		}
+		if err := index.quarantined(botID); !errors.Is(err, errEmbeddingDimensionMismatch) {
+			t.Fatalf("quarantined(%s) = %v, want dimension mismatch", botID, err)
+		}
+	}
+	if err := index.quarantined("bot-c"); err != nil {
+		t.Fatalf("quarantined(bot-c) = %v, want no drift for a bot that never saw it", err)
+	}
+	index.drift.quarantine("bot-a")
+	index.drift.quarantine("bot-a")
+
+	status := index.dimensionStatus("bot-a")
+	if status == nil {
+		t.Fatal("dimensionStatus() = nil, want drift")
+	}
+	if status.Configured != 1536 || status.Observed != 3072 || status.QuarantinedWrites != 2 || status.Action == "" {
+		t.Fatalf("dimensionStatus() = %+v", status)
+	}
+	if other := index.dimensionStatus("bot-b"); other == nil || other.QuarantinedWrites != 0 {
+		t.Fatalf("dimensionStatus(bot-b) = %+v, want drift without quarantined writes", other)
+	}
+
+	index.drift.clear("bot-a")
+	if err := index.quarantined("bot-a"); err != nil {
+		t.Fatalf("quarantined(bot-a) after clear = %v", err)
+	}
+	if status := index.dimensionStatus("bot-a"); status != nil {
+		t.Fatalf("dimensionStatus(bot-a) after clear = %+v, want nil", status)
+	}
+	if err := index.quarantined("bot-b"); !errors.Is(err, errEmbeddingDimensionMismatch) {
+		t.Fatalf("quarantined(bot-b) after clearing bot-a = %v, want still quarantined", err)
+	}
+	if status := index.dimensionStatus("bot-b"); status == nil || status.Observed != 3072 {
+		t.Fatalf("dimensionStatus(bot-b) after clearing bot-a = %+v, want drift", status)
+	}
+}
+
+func TestReembedJobsIndexesNodesAndReportsProgress(t *testing.T) {
+	t.Parallel()
+	jobs := newReembedJobs(nil)
+	jobs.interval = time.Millisecond
+	t.Cleanup(jobs.stop)
+
+	nodes := []migrate.NodeSpec{
+		{ID: "n1", Body: "alpha", Hash: "h1"},
+		{ID: "n2", Body: "  ", Hash: "h2"},
+		{ID: "n3", Body: "gamma", Hash: "h3"},
+	}
+	idx := &fakeUpserter{}
+	started, err := jobs.start("bot-a", nodes, idx)
+	if err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	if !started.Running || started.Total != 3 {
+		t.Fatalf("start() = %+v, want running job over 3 nodes", started)
+	}
+
+	status := waitReembed(t, jobs, "bot-a")
+	if status.Done != 3 || status.Failed != 0 || status.FinishedAt == nil {
+		t.Fatalf("status = %+v, want 3 done", status)
+	}
+	if got := idx.count(); got != 2 {
+		t.Fatalf("upserted = %d, want 2 (blank body skipped)", got)
+	}
+}
+
+func TestReembedJobsRejectsConcurrentRunAndCountsFailures(t *testing.T) {
+	t.Parallel()
+	jobs := newReembedJobs(nil)
+	jobs.interval = 20 * time.Millisecond
+	t.Cleanup(jobs.stop)
+
+	idx := &fakeUpserter{failing: true}
+	nodes := []migrate.NodeSpec{{ID: "n1", Body: "alpha"}, {ID: "n2", Body: "beta"}}
+	if _, err := jobs.start("bot-a", nodes, idx); err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	if _, err := jobs.start("bot-a", nodes, idx); !errors.Is(err, adapters.ErrReembedRunning) {
+		t.Fatalf("second start() error = %v, want ErrReembedRunning", err)
+	}
+
+	status := waitReembed(t, jobs, "bot-a")
+	if status.Failed != 2 || status.Done != 0 {
+		t.Fatalf("status = %+v, want 2 failed", status)
+	}
+	if _, err := jobs.start("bot-a", nodes, &fakeUpserter{}); err != nil {
+		t.Fatalf("restart after finish error = %v", err)
+	}
+}
+
+func TestReembedJobsStopCancelsRunningJob(t *testing.T) {
+	t.Parallel()
+	jobs := newReembedJobs(nil)
+	jobs.interval = time.Hour
+	nodes := []migrate.NodeSpec{{ID: "n1", Body: "alpha"}, {ID: "n2", Body: "beta"}}
+	if _, err := jobs.start("bot-a", nodes, &fakeUpserter{}); err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	jobs.stop()
+
+	status := jobs.status("bot-a")
+	if status == nil || status.Running || status.Error == "" {
+		t.Fatalf("status after stop = %+v, want stopped job with error", status)
+	}
+	if _, err := jobs.start("bot-b", nodes, &fakeUpserter{}); !errors.Is(err, adapters.ErrReembedUnavailable) {
+		t.Fatalf("start() after stop error = %v, want ErrReembedUnavailable", err)
+	}
+}
+
+func waitReembed(t *testing.T, jobs *reembedJobs, botID string) adapters.ReembedStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if status := jobs.status(botID); status != nil && !status.Running {
+			return *status
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("re-embed job for %s did not finish", botID)
+	return adapters.ReembedStatus{}
+}
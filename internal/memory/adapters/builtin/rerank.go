@@ -0,0 +1,98 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/memohai/memoh/internal/db"
+	dbsqlc "github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	adapters "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/models"
+)
+
+// rerankCandidates is how many graph results a reranked search hands to the
+// rerank model before cutting back to the requested limit.
+const rerankCandidates = 30
+
+// documentReranker scores documents against a query; *models.Reranker is the
+// production implementation.
+type documentReranker interface {
+	Rerank(ctx context.Context, query string, documents []string) ([]models.RerankResult, error)
+}
+
+// newRerankerFromConfig resolves the optional rerank_model_id of a provider
+// config. It returns nil when no rerank model is configured.
+func newRerankerFromConfig(ctx context.Context, providerConfig map[string]any, queries dbstore.Queries) (documentReranker, error) {
+	modelRef := strings.TrimSpace(adapters.StringFromConfig(providerConfig, "rerank_model_id"))
+	if modelRef == "" {
+		return nil, nil
+	}
+	if queries == nil {
+		return nil, errors.New("memory rerank: queries are required")
+	}
+	var row dbsqlc.Model
+	if parsed, err := db.ParseUUID(modelRef); err == nil {
+		if dbModel, err := queries.GetModelByID(ctx, parsed); err == nil {
+			row = dbModel
+		}
+	}
+	if !row.ID.Valid {
+		rows, err := queries.ListModelsByModelID(ctx, modelRef)
+		if err != nil || len(rows) == 0 {
+			return nil, fmt.Errorf("memory rerank: rerank model not found: %s", modelRef)
+		}
+		row = rows[0]
+	}
+	if row.Type != string(models.ModelTypeRerank) {
+		return nil, fmt.Errorf("memory rerank: model %s is not a rerank model", modelRef)
+	}
+	if !row.Enable {
+		return nil, fmt.Errorf("memory rerank: rerank model %s is disabled", modelRef)
+	}
+	if !row.ProviderID.Valid {
+		return nil, fmt.Errorf("memory rerank: model %s has no provider", modelRef)
+	}
+	provider, err := queries.GetProviderByID(ctx, row.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("memory rerank: get rerank provider: %w", err)
+	}
+	var providerCfg map[string]any
+	if len(provider.Config) > 0 {
+		_ = json.Unmarshal(provider.Config, &providerCfg)
+	}
+	baseURL, _ := providerCfg["base_url"].(string)
+	apiKey, _ := providerCfg["api_key"].(string)
+	return models.NewReranker(baseURL, apiKey, row.ModelID, semanticEmbedTimeout, nil), nil
+}
+
+// rerankItems reorders items by the rerank model's relevance to query and
+// keeps the first limit. Scores become the rerank relevance scores.
+func rerankItems(ctx context.Context, reranker documentReranker, query string, items []adapters.MemoryItem, limit int) ([]adapters.MemoryItem, error) {
+	documents := make([]string, len(items))
+	for i, item := range items {
+		documents[i] = item.Memory
+	}
+	ranked, err := reranker.Rerank(ctx, query, documents)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]adapters.MemoryItem, 0, min(limit, len(ranked)))
+	seen := make(map[int]bool, len(ranked))
+	for _, result := range ranked {
+		if len(out) == limit {
+			break
+		}
+		if seen[result.Index] {
+			continue
+		}
+		seen[result.Index] = true
+		item := items[result.Index]
+		item.Score = result.RelevanceScore
+		out = append(out, item)
+	}
+	return out, nil
+}
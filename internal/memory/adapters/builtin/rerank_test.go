@@ -0,0 +1,67 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	adapters "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/models"
+)
+
+// keywordReranker ranks documents containing keyword first.
+type keywordReranker struct {
+	keyword string
+	err     error
+}
+
+func (k keywordReranker) Rerank(_ context.Context, _ string, documents []string) ([]models.RerankResult, error) {
+	if k.err != nil {
+		return nil, k.err
+	}
+	results := make([]models.RerankResult, 0, len(documents))
+	for i, doc := range documents {
+		if strings.Contains(doc, k.keyword) {
+			results = append([]models.RerankResult{{Index: i, RelevanceScore: 0.9}}, results...)
+			continue
+		}
+		results = append(results, models.RerankResult{Index: i, RelevanceScore: 0.1})
+	}
+	return results, nil
+}
+
+func TestGraphRuntimeSearchRerank(t *testing.T) {
+	t.Parallel()
+	rt := NewGraphRuntime(nil, newFakeWikiStore(), newFakeStore())
+	ctx := context.Background()
+	botID := "rerank-bot"
+	for _, msg := range []string{"tea with milk and tea leaves", "green tea from Kyoto", "black tea at breakfast"} {
+		if _, err := rt.Add(ctx, adapters.AddRequest{BotID: botID, Message: msg}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	rt.SetReranker(keywordReranker{keyword: "Kyoto"})
+	resp, err := rt.Search(ctx, adapters.SearchRequest{BotID: botID, Query: "tea", Limit: 2, Rerank: true})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(resp.Results) != 2 || !strings.Contains(resp.Results[0].Memory, "Kyoto") || resp.Results[0].Score != 0.9 {
+		t.Fatalf("reranked results = %+v", resp.Results)
+	}
+
+	plain, err := rt.Search(ctx, adapters.SearchRequest{BotID: botID, Query: "tea", Limit: 2})
+	if err != nil {
+		t.Fatalf("Search without rerank: %v", err)
+	}
+	if len(plain.Results) != 2 || plain.Results[0].Score == 0.9 {
+		t.Fatalf("search without rerank = %+v", plain.Results)
+	}
+
+	rt.SetReranker(keywordReranker{err: errors.New("rerank endpoint down")})
+	fallback, err := rt.Search(ctx, adapters.SearchRequest{BotID: botID, Query: "tea", Limit: 2, Rerank: true})
+	if err != nil || len(fallback.Results) != 2 {
+		t.Fatalf("failed rerank should keep graph order: %+v, %v", fallback.Results, err)
+	}
+}
@@ -99,6 +99,15 @@ func runtimeBotID(botID string, filters map[string]any) (string, error) {
 	return botID, nil
 }
 
+// runtimeUserID returns the user a request is scoped to, from the request
+// field or the "user_id" filter.
+func runtimeUserID(userID string, filters map[string]any) string {
+	if userID = strings.TrimSpace(userID); userID != "" {
+		return userID
+	}
+	return strings.TrimSpace(runtimeFilterString(filters, adapters.MetadataUserID))
+}
+
 func runtimeBotIDFromMemoryID(memoryID string) string {
 	parts := strings.SplitN(strings.TrimSpace(memoryID), ":", 2)
 	if len(parts) != 2 {
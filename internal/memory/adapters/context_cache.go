@@ -27,6 +27,7 @@ type MemoryContextCacheConfig struct {
 type MemoryContextCacheKey struct {
 	BotID         string
 	ChatID        string
+	UserID        string
 	ProviderID    string
 	QueryHash     string
 	MemoryVersion string
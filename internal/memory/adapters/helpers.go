@@ -69,6 +69,43 @@ func MergeMetadata(base map[string]any, extra map[string]any) map[string]any {
 	return out
 }
 
+// MetadataUserID is the metadata key of the user a memory is scoped to.
+// Memories without it are shared by every user of the bot.
+const MetadataUserID = "user_id"
+
+// MemoryScopeUserID returns the key memories of a chat participant are
+// scoped to: the linked account user, or the channel identity of senders
+// without an account.
+func MemoryScopeUserID(userID, channelIdentityID string) string {
+	if userID = strings.TrimSpace(userID); userID != "" {
+		return userID
+	}
+	return strings.TrimSpace(channelIdentityID)
+}
+
+// WithUserScope returns a copy of metadata scoped to userID. An empty userID
+// returns metadata unchanged.
+func WithUserScope(metadata map[string]any, userID string) map[string]any {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return metadata
+	}
+	return MergeMetadata(metadata, map[string]any{MetadataUserID: userID})
+}
+
+// VisibleToUser reports whether a memory with the given metadata may be
+// returned to userID: shared memories are visible to everyone, scoped ones
+// only to their user. An empty userID sees every memory.
+func VisibleToUser(metadata map[string]any, userID string) bool {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return true
+	}
+	owner, _ := metadata[MetadataUserID].(string)
+	owner = strings.TrimSpace(owner)
+	return owner == "" || owner == userID
+}
+
 func BuildProfileMetadata(userID, channelIdentityID, displayName string) map[string]any {
 	userID = strings.TrimSpace(userID)
 	channelIdentityID = strings.TrimSpace(channelIdentityID)
@@ -63,3 +63,21 @@ func TestDeduplicateItems(t *testing.T) {
 		t.Fatalf("expected 2 items, got %d", len(result))
 	}
 }
+
+func TestVisibleToUser(t *testing.T) {
+	t.Parallel()
+
+	scoped := WithUserScope(map[string]any{"topic": "tea"}, "alice")
+	if scoped[MetadataUserID] != "alice" || scoped["topic"] != "tea" {
+		t.Fatalf("scoped metadata = %v", scoped)
+	}
+	if !VisibleToUser(scoped, "alice") || VisibleToUser(scoped, "bob") || !VisibleToUser(scoped, "") {
+		t.Fatal("scoped memory visibility is wrong")
+	}
+	if !VisibleToUser(nil, "bob") {
+		t.Fatal("shared memory should be visible to every user")
+	}
+	if got := MemoryScopeUserID(" ", "ci-1"); got != "ci-1" {
+		t.Fatalf("scope user = %q, want channel identity fallback", got)
+	}
+}
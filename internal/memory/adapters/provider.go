@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"context"
+	"time"
 
 	"github.com/memohai/memoh/internal/mcp"
 )
@@ -51,6 +52,13 @@ type MemoryVersionProvider interface {
 	MemoryVersion(ctx context.Context, botID string) string
 }
 
+// MemoryExpiryProvider is implemented by providers that store memory TTLs.
+// ExpireMemories deletes the bot's memories that expired at or before now and
+// returns how many were removed.
+type MemoryExpiryProvider interface {
+	ExpireMemories(ctx context.Context, botID string, now time.Time) (int, error)
+}
+
 // SourceSyncProvider is implemented by providers that can report runtime status
 // and rebuild derived storage from a canonical source of truth.
 type SourceSyncProvider interface {
@@ -58,6 +66,13 @@ type SourceSyncProvider interface {
 	Rebuild(ctx context.Context, botID string) (RebuildResult, error)
 }
 
+// ReembedProvider is implemented by providers whose semantic index can be
+// rebuilt in the background, e.g. after the embedding provider changed its
+// vector dimensions. StartReembed returns once the job is queued.
+type ReembedProvider interface {
+	StartReembed(ctx context.Context, botID string) (ReembedStatus, error)
+}
+
 // MarkdownIngestProvider is implemented by providers whose canonical source of
 // truth is the DB but which also accept agent-authored Markdown files as input.
 // IngestFromMarkdown reads /data/memory/*.md and upserts them as DB nodes,
@@ -0,0 +1,42 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/memohai/memoh/internal/settings"
+)
+
+// ErrRegistryNotConfigured is returned by ResolveBotProvider without a registry.
+var ErrRegistryNotConfigured = errors.New("memory registry not configured")
+
+// ResolveBotProvider returns the memory provider for a bot, mirroring the
+// memory handler: an explicitly selected provider must be available; only
+// bots without a selected provider fall back to the builtin default. Errors
+// loading the bot's settings or either provider are returned, never
+// swallowed. botSettings may be nil, which selects the default.
+func ResolveBotProvider(ctx context.Context, registry *Registry, botSettings *settings.Service, botID string) (Provider, error) {
+	if registry == nil {
+		return nil, ErrRegistryNotConfigured
+	}
+	if botSettings != nil {
+		cfg, err := botSettings.GetBot(ctx, botID)
+		if err != nil {
+			return nil, fmt.Errorf("load memory provider setting: %w", err)
+		}
+		if providerID := strings.TrimSpace(cfg.MemoryProviderID); providerID != "" {
+			p, err := registry.Get(ctx, providerID)
+			if err != nil {
+				return nil, fmt.Errorf("configured memory provider is unavailable: %w", err)
+			}
+			return p, nil
+		}
+	}
+	p, err := registry.Get(ctx, DefaultBuiltinProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("default memory provider is unavailable: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,56 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+type resolveSettingsQueries struct {
+	dbstore.Queries
+	providerID pgtype.UUID
+	err        error
+}
+
+func (q *resolveSettingsQueries) GetSettingsByBotID(_ context.Context, botID pgtype.UUID) (sqlc.GetSettingsByBotIDRow, error) {
+	if q.err != nil {
+		return sqlc.GetSettingsByBotIDRow{}, q.err
+	}
+	return sqlc.GetSettingsByBotIDRow{BotID: botID, MemoryProviderID: q.providerID}, nil
+}
+
+func TestResolveBotProvider(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const botID = "00000000-0000-0000-0000-000000000001"
+	selected := &bootstrapProvider{providerType: "selected"}
+	fallback := &bootstrapProvider{providerType: "builtin"}
+	registry := NewRegistry(slog.Default())
+	registry.Register("00000000-0000-0000-0000-0000000000aa", selected)
+	registry.Register(DefaultBuiltinProviderID, fallback)
+	selectedID := pgtype.UUID{Bytes: [16]byte{15: 0xaa}, Valid: true}
+	newSettings := func(q *resolveSettingsQueries) *settings.Service {
+		return settings.NewService(slog.Default(), q, nil, nil)
+	}
+
+	if _, err := ResolveBotProvider(ctx, nil, nil, botID); !errors.Is(err, ErrRegistryNotConfigured) {
+		t.Fatalf("nil registry error = %v, want ErrRegistryNotConfigured", err)
+	}
+	if p, err := ResolveBotProvider(ctx, registry, newSettings(&resolveSettingsQueries{providerID: selectedID}), botID); err != nil || p != selected {
+		t.Fatalf("selected provider = %v, %v", p, err)
+	}
+	if p, err := ResolveBotProvider(ctx, registry, newSettings(&resolveSettingsQueries{}), botID); err != nil || p != fallback {
+		t.Fatalf("default provider = %v, %v", p, err)
+	}
+	settingsErr := errors.New("settings unavailable")
+	if _, err := ResolveBotProvider(ctx, registry, newSettings(&resolveSettingsQueries{err: settingsErr}), botID); !errors.Is(err, settingsErr) {
+		t.Fatalf("settings failure error = %v, want it returned", err)
+	}
+}
@@ -50,7 +50,54 @@ func (*Service) ListMeta(_ context.Context) []ProviderMeta {
 					"embedding_model_id": {
 						Type:        "string",
 						Title:       "Embedding Model",
-						Description: "Optional embedding model used to maintain the dedicated pgvector semantic seed index for graph recall. local stores remain graph-only.",
+						Description: "Optional embedding model used to maintain the dedicated pgvector semantic seed index for graph recall. A model ID is stored as the model's stable row ID, so renaming the model keeps the index. local stores remain graph-only.",
+						Required:    false,
+					},
+					"search_fusion": {
+						Type:        "select",
+						Title:       "Search Fusion",
+						Description: "How semantic (embedding) and lexical matches are combined when an embedding model is set: max keeps the better score, rrf uses weighted reciprocal rank fusion, relative sums min-max normalized scores. Defaults to max.",
+						Required:    false,
+						Example:     "rrf",
+					},
+					"search_dense_weight": {
+						Type:        "number",
+						Title:       "Semantic Weight",
+						Description: "Weight of semantic matches in hybrid search. Defaults to 1.",
+						Required:    false,
+						Example:     1,
+					},
+					"search_sparse_weight": {
+						Type:        "number",
+						Title:       "Lexical Weight",
+						Description: "Weight of lexical matches in hybrid search. Defaults to 1.",
+						Required:    false,
+						Example:     1,
+					},
+					"rerank_model_id": {
+						Type:        "string",
+						Title:       "Rerank Model",
+						Description: "Optional rerank model (an OpenAI-compatible /rerank endpoint). Searches that request rerank pass the top candidates through it and reorder results by relevance.",
+						Required:    false,
+					},
+					"query_expansion": {
+						Type:        "select",
+						Title:       "Query Expansion",
+						Description: "Expand recall queries with the memory model before searching: translate rewrites the query into the languages most of the bot's memories are written in, synonyms adds rephrasings in the query's language. Adds one model call per search. Defaults to off.",
+						Required:    false,
+						Example:     "translate",
+					},
+					"query_expansion_languages": {
+						Type:        "string",
+						Title:       "Expansion Languages",
+						Description: "Comma-separated languages to translate queries into, e.g. en,zh. Defaults to the languages detected in the bot's memories.",
+						Required:    false,
+						Example:     "en,zh",
+					},
+					"user_isolation": {
+						Type:        "boolean",
+						Title:       "Per-User Memory",
+						Description: "Scope memories formed in chats to the user they came from. Each user of a multi-user bot then recalls only their own memories plus those shared by the whole bot. Off by default.",
 						Required:    false,
 					},
 					"context_target_items": {
@@ -130,7 +177,7 @@ func (s *Service) Create(ctx context.Context, req ProviderCreateRequest) (Provid
 	if !isValidProviderType(req.Provider) {
 		return ProviderGetResponse{}, fmt.Errorf("invalid provider type: %s", req.Provider)
 	}
-	configJSON, err := json.Marshal(req.Config)
+	configJSON, err := json.Marshal(s.pinEmbeddingModel(ctx, string(req.Provider), req.Config))
 	if err != nil {
 		return ProviderGetResponse{}, fmt.Errorf("marshal config: %w", err)
 	}
@@ -224,7 +271,7 @@ func (s *Service) Update(ctx context.Context, id string, req ProviderUpdateReque
 	}
 	config := current.Config
 	if req.Config != nil {
-		configJSON, marshalErr := json.Marshal(req.Config)
+		configJSON, marshalErr := json.Marshal(s.pinEmbeddingModel(ctx, current.Provider, req.Config))
 		if marshalErr != nil {
 			return ProviderGetResponse{}, fmt.Errorf("marshal config: %w", marshalErr)
 		}
@@ -243,6 +290,33 @@ func (s *Service) Update(ctx context.Context, id string, req ProviderUpdateReque
 	return resp, nil
 }
 
+// pinEmbeddingModel stores a builtin provider's embedding model by its row
+// ID rather than its model ID. Semantic index rows are keyed by the row ID,
+// so a pinned reference keeps resolving them after the model is renamed. A
+// model ID that is unknown or shared by several models is kept as given.
+func (s *Service) pinEmbeddingModel(ctx context.Context, providerType string, cfg map[string]any) map[string]any {
+	if providerType != string(ProviderBuiltin) || s.queries == nil {
+		return cfg
+	}
+	ref := StringFromConfig(cfg, "embedding_model_id")
+	if ref == "" {
+		return cfg
+	}
+	if _, err := db.ParseUUID(ref); err == nil {
+		return cfg
+	}
+	rows, err := s.queries.ListModelsByModelID(ctx, ref)
+	if err != nil || len(rows) != 1 || !rows[0].ID.Valid {
+		return cfg
+	}
+	pinned := make(map[string]any, len(cfg))
+	for k, v := range cfg {
+		pinned[k] = v
+	}
+	pinned["embedding_model_id"] = rows[0].ID.String()
+	return pinned
+}
+
 func (s *Service) Delete(ctx context.Context, id string) error {
 	pgID, err := db.ParseUUID(id)
 	if err != nil {
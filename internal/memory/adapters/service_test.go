@@ -321,3 +321,42 @@ func TestRegistryCloseClosesAllProvidersOnce(t *testing.T) {
 		t.Fatalf("second provider Close() calls = %d, want 1", got)
 	}
 }
+
+type embeddingModelQueries struct {
+	dbstore.Queries
+	models map[string][]sqlc.Model
+}
+
+func (q *embeddingModelQueries) ListModelsByModelID(_ context.Context, modelID string) ([]sqlc.Model, error) {
+	return q.models[modelID], nil
+}
+
+func TestPinEmbeddingModelStoresRowID(t *testing.T) {
+	t.Parallel()
+	rowID := pgtype.UUID{Bytes: [16]byte{1, 2, 3}, Valid: true}
+	otherID := pgtype.UUID{Bytes: [16]byte{4, 5, 6}, Valid: true}
+	svc := NewService(slog.Default(), &embeddingModelQueries{models: map[string][]sqlc.Model{
+		"text-embedding-3-small": {{ID: rowID}},
+		"shared-embedding":       {{ID: rowID}, {ID: otherID}},
+	}}, config.Config{})
+
+	cfg := map[string]any{"embedding_model_id": "text-embedding-3-small", "memory_mode": "graph"}
+	pinned := svc.pinEmbeddingModel(context.Background(), string(ProviderBuiltin), cfg)
+	if got := pinned["embedding_model_id"]; got != rowID.String() {
+		t.Fatalf("embedding_model_id = %v, want %s", got, rowID.String())
+	}
+	if pinned["memory_mode"] != "graph" || cfg["embedding_model_id"] != "text-embedding-3-small" {
+		t.Fatalf("pinning must copy the config: pinned=%v original=%v", pinned, cfg)
+	}
+
+	for _, ref := range []string{"shared-embedding", "unknown-model", otherID.String()} {
+		cfg := map[string]any{"embedding_model_id": ref}
+		if got := svc.pinEmbeddingModel(context.Background(), string(ProviderBuiltin), cfg)["embedding_model_id"]; got != ref {
+			t.Fatalf("ref %q rewritten to %v", ref, got)
+		}
+	}
+	mem0 := map[string]any{"embedding_model_id": "text-embedding-3-small"}
+	if got := svc.pinEmbeddingModel(context.Background(), string(ProviderMem0), mem0)["embedding_model_id"]; got != "text-embedding-3-small" {
+		t.Fatalf("non-builtin config rewritten to %v", got)
+	}
+}
@@ -2,14 +2,35 @@ package adapters
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 )
 
+// ErrInvalidSearchFusion is returned for hybrid search settings that cannot
+// be applied.
+var ErrInvalidSearchFusion = errors.New("invalid search fusion")
+
+// Re-embedding errors. ErrEmbeddingDimensionsUnresolved means the embedding
+// provider still returns vectors of a size the model is not configured for.
+var (
+	ErrReembedUnavailable            = errors.New("re-embedding is not available for this memory provider")
+	ErrReembedRunning                = errors.New("re-embedding is already running for this bot")
+	ErrEmbeddingDimensionsUnresolved = errors.New("embedding model dimensions do not match the provider")
+)
+
 // BeforeChatRequest is passed to OnBeforeChat before sending to the agent gateway.
 type BeforeChatRequest struct {
-	Query  string
-	BotID  string
-	ChatID string
+	Query             string
+	BotID             string
+	ChatID            string
+	UserID            string
+	ChannelIdentityID string
+	// Fusion overrides the provider's configured hybrid fusion for the
+	// recall search. Providers without hybrid recall ignore it.
+	Fusion *SearchFusion
 }
 
 // BeforeChatResult contains memory context to inject into the conversation.
@@ -36,33 +57,122 @@ type LLM interface {
 	Compact(ctx context.Context, req CompactRequest) (CompactResponse, error)
 }
 
+// QueryExpander is an optional LLM capability that rewrites a memory search
+// query into alternate queries, so memories stored in another language or
+// wording than the query are still recalled.
+type QueryExpander interface {
+	ExpandQuery(ctx context.Context, req ExpandQueryRequest) (ExpandQueryResponse, error)
+}
+
+// ExpandQueryRequest asks for translations of Query into Languages, or for
+// synonym rephrasings in the query's own language when Languages is empty.
+type ExpandQueryRequest struct {
+	BotID     string   `json:"bot_id"`
+	Query     string   `json:"query"`
+	Languages []string `json:"languages,omitempty"`
+	Max       int      `json:"max,omitempty"`
+}
+
+// ExpandQueryResponse holds the alternate queries, excluding the original.
+type ExpandQueryResponse struct {
+	Queries []string `json:"queries"`
+}
+
+// Query expansion modes of the builtin provider's query_expansion setting.
+const (
+	QueryExpansionOff       = "off"
+	QueryExpansionTranslate = "translate"
+	QueryExpansionSynonyms  = "synonyms"
+)
+
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
 }
 
 type AddRequest struct {
-	Message          string         `json:"message,omitempty"`
-	Messages         []Message      `json:"messages,omitempty"`
-	BotID            string         `json:"bot_id,omitempty"`
-	AgentID          string         `json:"agent_id,omitempty"`
-	RunID            string         `json:"run_id,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	Messages []Message `json:"messages,omitempty"`
+	BotID    string    `json:"bot_id,omitempty"`
+	AgentID  string    `json:"agent_id,omitempty"`
+	RunID    string    `json:"run_id,omitempty"`
+	// UserID scopes the memory to one user of a multi-user bot. Empty keeps
+	// it shared by every user of the bot.
+	UserID           string         `json:"user_id,omitempty"`
 	Metadata         map[string]any `json:"metadata,omitempty"`
 	Filters          map[string]any `json:"filters,omitempty"`
 	Infer            *bool          `json:"infer,omitempty"`
 	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
+	// TTL makes the memory expire after the given duration. Zero keeps it
+	// until it is deleted. Providers without expiry support ignore it.
+	TTL time.Duration `json:"-"`
 }
 
 type SearchRequest struct {
-	Query            string         `json:"query"`
-	BotID            string         `json:"bot_id,omitempty"`
-	AgentID          string         `json:"agent_id,omitempty"`
-	RunID            string         `json:"run_id,omitempty"`
+	Query   string `json:"query"`
+	BotID   string `json:"bot_id,omitempty"`
+	AgentID string `json:"agent_id,omitempty"`
+	RunID   string `json:"run_id,omitempty"`
+	// UserID limits results to the user's own memories and the memories
+	// shared by the whole bot.
+	UserID           string         `json:"user_id,omitempty"`
 	Limit            int            `json:"limit,omitempty"`
 	Filters          map[string]any `json:"filters,omitempty"`
 	Sources          []string       `json:"sources,omitempty"`
 	EmbeddingEnabled *bool          `json:"embedding_enabled,omitempty"`
 	NoStats          bool           `json:"no_stats,omitempty"`
+	// Fusion overrides the provider's configured hybrid fusion for this
+	// search. Providers without hybrid recall ignore it.
+	Fusion *SearchFusion `json:"fusion,omitempty"`
+	// Rerank passes the top candidates through the provider's rerank model
+	// and reorders results by its relevance scores. Providers without a
+	// rerank model ignore it.
+	Rerank bool `json:"rerank,omitempty"`
+}
+
+// Hybrid fusion modes for combining semantic (dense) and lexical (sparse)
+// matches.
+const (
+	// FusionMax keeps the better of the two weighted scores per memory.
+	FusionMax = "max"
+	// FusionRRF is weighted reciprocal rank fusion.
+	FusionRRF = "rrf"
+	// FusionRelative min-max normalizes each score list and sums them
+	// weighted (relative score fusion).
+	FusionRelative = "relative"
+)
+
+// SearchFusion configures hybrid search. Unset fields keep the provider's
+// configured values.
+type SearchFusion struct {
+	Mode         string   `json:"mode,omitempty"`
+	DenseWeight  *float64 `json:"dense_weight,omitempty"`
+	SparseWeight *float64 `json:"sparse_weight,omitempty"`
+}
+
+// Validate rejects unknown modes and negative weights.
+func (f SearchFusion) Validate() error {
+	switch strings.ToLower(strings.TrimSpace(f.Mode)) {
+	case "", FusionMax, FusionRRF, FusionRelative:
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidSearchFusion, f.Mode)
+	}
+	for _, w := range []*float64{f.DenseWeight, f.SparseWeight} {
+		if w != nil && (*w < 0 || math.IsNaN(*w) || math.IsInf(*w, 0)) {
+			return fmt.Errorf("%w: weights must be finite and not negative", ErrInvalidSearchFusion)
+		}
+	}
+	if f.DenseWeight != nil && f.SparseWeight != nil && *f.DenseWeight == 0 && *f.SparseWeight == 0 {
+		return fmt.Errorf("%w: at least one weight must be positive", ErrInvalidSearchFusion)
+	}
+	return nil
+}
+
+// ClassicSearchFusion keeps the better of the unweighted semantic and
+// lexical score per memory, the recall used before hybrid fusion settings.
+func ClassicSearchFusion() *SearchFusion {
+	weight := 1.0
+	return &SearchFusion{Mode: FusionMax, DenseWeight: &weight, SparseWeight: &weight}
 }
 
 type UpdateRequest struct {
@@ -72,9 +182,11 @@ type UpdateRequest struct {
 }
 
 type GetAllRequest struct {
-	BotID   string         `json:"bot_id,omitempty"`
-	AgentID string         `json:"agent_id,omitempty"`
-	RunID   string         `json:"run_id,omitempty"`
+	BotID   string `json:"bot_id,omitempty"`
+	AgentID string `json:"agent_id,omitempty"`
+	RunID   string `json:"run_id,omitempty"`
+	// UserID limits results like SearchRequest.UserID.
+	UserID  string         `json:"user_id,omitempty"`
 	Limit   int            `json:"limit,omitempty"`
 	Filters map[string]any `json:"filters,omitempty"`
 	NoStats bool           `json:"no_stats,omitempty"`
@@ -208,9 +320,35 @@ type MemoryStatusResponse struct {
 	Encoder           *HealthStatus           `json:"encoder,omitempty"`
 	Pgvector          *HealthStatus           `json:"pgvector,omitempty"`
 	// Degraded reports that the semantic seed index is behind the wiki store
-	// (failed upserts are queued for retry); graph recall still works.
-	Degraded        bool `json:"degraded"`
-	RetryQueueDepth int  `json:"retry_queue_depth"`
+	// (failed upserts are queued for retry or quarantined); graph recall
+	// still works.
+	Degraded            bool                      `json:"degraded"`
+	RetryQueueDepth     int                       `json:"retry_queue_depth"`
+	EmbeddingDimensions *EmbeddingDimensionStatus `json:"embedding_dimensions,omitempty"`
+	Reembed             *ReembedStatus            `json:"reembed,omitempty"`
+}
+
+// EmbeddingDimensionStatus reports that the embedding provider started
+// returning vectors of a different size than the model is configured for.
+// Semantic writes are quarantined until the model's dimensions are updated
+// and a re-embedding job is started; Action says what to do next.
+type EmbeddingDimensionStatus struct {
+	Configured        int       `json:"configured"`
+	Observed          int       `json:"observed"`
+	QuarantinedWrites int       `json:"quarantined_writes"`
+	DetectedAt        time.Time `json:"detected_at"`
+	Action            string    `json:"action"`
+}
+
+// ReembedStatus is the progress of a bot's background re-embedding job.
+type ReembedStatus struct {
+	Running    bool       `json:"running"`
+	Total      int        `json:"total"`
+	Done       int        `json:"done"`
+	Failed     int        `json:"failed"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
 }
 
 // Memory provider admin types.
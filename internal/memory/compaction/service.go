@@ -0,0 +1,270 @@
+// Package compaction runs semantic memory compaction for a bot and keeps a
+// history of runs. Runs are started from the memory API or by a bot's
+// compaction schedule.
+package compaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+var (
+	// ErrInvalidRequest reports a ratio outside (0, 1] or a negative decay.
+	ErrInvalidRequest = errors.New("invalid memory compact request")
+	// ErrNotSupported reports a bot whose memory provider cannot compact.
+	ErrNotSupported = errors.New("selected memory provider does not support semantic compact")
+)
+
+const (
+	SourceManual   = "manual"
+	SourceSchedule = "schedule"
+
+	StatusRunning = "running"
+	StatusOK      = "ok"
+	StatusError   = "error"
+
+	defaultRunLimit = 50
+	maxRunLimit     = 200
+
+	// sharedNamespace is the bot-shared memory scope compaction applies to.
+	sharedNamespace = "bot"
+)
+
+type runQueries interface {
+	CreateMemoryCompactRun(ctx context.Context, arg sqlc.CreateMemoryCompactRunParams) (sqlc.MemoryCompactRun, error)
+	CompleteMemoryCompactRun(ctx context.Context, arg sqlc.CompleteMemoryCompactRunParams) (sqlc.MemoryCompactRun, error)
+	ListMemoryCompactRuns(ctx context.Context, arg sqlc.ListMemoryCompactRunsParams) ([]sqlc.MemoryCompactRun, error)
+}
+
+// Request configures one compaction run.
+type Request struct {
+	// Ratio is the share of memories to keep, in (0, 1].
+	Ratio float64
+	// DecayDays treats memories older than this many days as low priority;
+	// zero disables decay.
+	DecayDays int
+	// Source is SourceManual or SourceSchedule.
+	Source string
+}
+
+// Run is one recorded compaction.
+type Run struct {
+	ID           string     `json:"id"`
+	BotID        string     `json:"bot_id"`
+	Source       string     `json:"source"`
+	Status       string     `json:"status"`
+	Ratio        float64    `json:"ratio"`
+	DecayDays    int        `json:"decay_days"`
+	BeforeCount  int        `json:"before_count"`
+	AfterCount   int        `json:"after_count"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+// Service compacts bot memories and records each run.
+type Service struct {
+	queries         dbstore.Queries
+	settingsService *settings.Service
+	memoryRegistry  *memprovider.Registry
+	resolve         func(ctx context.Context, botID string) (memprovider.Provider, error)
+	logger          *slog.Logger
+}
+
+// NewService creates a memory compaction service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	s := &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "memory_compaction")),
+	}
+	s.resolve = func(ctx context.Context, botID string) (memprovider.Provider, error) {
+		return memprovider.ResolveBotProvider(ctx, s.memoryRegistry, s.settingsService, botID)
+	}
+	return s
+}
+
+// SetMemoryRegistry sets the registry used to reach each bot's provider.
+func (s *Service) SetMemoryRegistry(registry *memprovider.Registry) {
+	s.memoryRegistry = registry
+}
+
+// SetSettingsService sets the settings service used to find a bot's
+// selected memory provider.
+func (s *Service) SetSettingsService(service *settings.Service) {
+	s.settingsService = service
+}
+
+func (s *Service) store() (runQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("memory compaction service not configured")
+	}
+	store, ok := s.queries.(runQueries)
+	if !ok {
+		return nil, errors.New("memory compaction queries not supported by store")
+	}
+	return store, nil
+}
+
+// Compact compacts the bot-shared memories of botID and records the run.
+// Requests the provider cannot serve are rejected before a run is recorded.
+func (s *Service) Compact(ctx context.Context, botID string, req Request) (memprovider.CompactResult, error) {
+	if req.Ratio <= 0 || req.Ratio > 1 {
+		return memprovider.CompactResult{}, fmt.Errorf("%w: ratio must be in range (0, 1]", ErrInvalidRequest)
+	}
+	if req.DecayDays < 0 {
+		return memprovider.CompactResult{}, fmt.Errorf("%w: decay_days must not be negative", ErrInvalidRequest)
+	}
+	source := req.Source
+	if source != SourceSchedule {
+		source = SourceManual
+	}
+	store, err := s.store()
+	if err != nil {
+		return memprovider.CompactResult{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return memprovider.CompactResult{}, err
+	}
+	provider, err := s.resolve(ctx, botID)
+	if err != nil {
+		return memprovider.CompactResult{}, err
+	}
+	if reason := unsupportedReason(provider); reason != "" {
+		return memprovider.CompactResult{}, fmt.Errorf("%w: %s", ErrNotSupported, reason)
+	}
+
+	run, err := store.CreateMemoryCompactRun(ctx, sqlc.CreateMemoryCompactRunParams{
+		BotID:     pgBotID,
+		Source:    source,
+		Ratio:     req.Ratio,
+		DecayDays: int32(req.DecayDays), //nolint:gosec // decay days is a small day count
+	})
+	if err != nil {
+		return memprovider.CompactResult{}, fmt.Errorf("record memory compact run: %w", err)
+	}
+
+	filters := map[string]any{"namespace": sharedNamespace, "scopeId": strings.TrimSpace(botID)}
+	result, compactErr := provider.Compact(ctx, filters, req.Ratio, req.DecayDays)
+	complete := sqlc.CompleteMemoryCompactRunParams{ID: run.ID, Status: StatusOK}
+	if compactErr != nil {
+		complete.Status = StatusError
+		complete.ErrorMessage = compactErr.Error()
+	} else {
+		complete.BeforeCount = int32(result.BeforeCount) //nolint:gosec // memory counts fit in int32
+		complete.AfterCount = int32(result.AfterCount)   //nolint:gosec // memory counts fit in int32
+	}
+	// The run outcome is recorded even when the caller's context has ended.
+	if _, err := store.CompleteMemoryCompactRun(context.WithoutCancel(ctx), complete); err != nil {
+		s.logger.Warn("complete memory compact run failed", slog.String("bot_id", botID), slog.Any("error", err))
+	}
+	if compactErr != nil {
+		return memprovider.CompactResult{}, compactErr
+	}
+	s.logger.Info("memory compacted",
+		slog.String("bot_id", botID),
+		slog.String("source", source),
+		slog.Int("before", result.BeforeCount),
+		slog.Int("after", result.AfterCount),
+	)
+	return result, nil
+}
+
+// CompactMemory implements schedule.MemoryCompactor.
+func (s *Service) CompactMemory(ctx context.Context, botID string, ratio float64, decayDays int) error {
+	_, err := s.Compact(ctx, botID, Request{Ratio: ratio, DecayDays: decayDays, Source: SourceSchedule})
+	return err
+}
+
+// ListRuns returns the bot's most recent compaction runs, newest first.
+func (s *Service) ListRuns(ctx context.Context, botID string, limit int) ([]Run, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultRunLimit
+	}
+	if limit > maxRunLimit {
+		limit = maxRunLimit
+	}
+	rows, err := store.ListMemoryCompactRuns(ctx, sqlc.ListMemoryCompactRunsParams{
+		BotID:    pgBotID,
+		RowLimit: int32(limit), //nolint:gosec // bounded by maxRunLimit
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list memory compact runs: %w", err)
+	}
+	runs := make([]Run, 0, len(rows))
+	for _, row := range rows {
+		runs = append(runs, toRun(row))
+	}
+	return runs, nil
+}
+
+// SemanticCapability reports whether the bot's provider can compact.
+func (s *Service) SemanticCapability(ctx context.Context, botID string) memprovider.MemoryCompactCapability {
+	provider, err := s.resolve(ctx, botID)
+	if err != nil {
+		return memprovider.MemoryCompactCapability{Reason: err.Error()}
+	}
+	if reason := unsupportedReason(provider); reason != "" {
+		return memprovider.MemoryCompactCapability{Reason: reason}
+	}
+	return provider.(memprovider.SemanticCompactProvider).SemanticCompactCapability()
+}
+
+func unsupportedReason(provider memprovider.Provider) string {
+	if provider == nil {
+		return "memory service not available"
+	}
+	semantic, ok := provider.(memprovider.SemanticCompactProvider)
+	if !ok {
+		return "provider has no compact capability"
+	}
+	capability := semantic.SemanticCompactCapability()
+	if capability.Semantic {
+		return ""
+	}
+	if reason := strings.TrimSpace(capability.Reason); reason != "" {
+		return reason
+	}
+	return "provider has no compact capability"
+}
+
+func toRun(row sqlc.MemoryCompactRun) Run {
+	run := Run{
+		ID:           row.ID.String(),
+		BotID:        row.BotID.String(),
+		Source:       row.Source,
+		Status:       row.Status,
+		Ratio:        row.Ratio,
+		DecayDays:    int(row.DecayDays),
+		BeforeCount:  int(row.BeforeCount),
+		AfterCount:   int(row.AfterCount),
+		ErrorMessage: row.ErrorMessage,
+		StartedAt:    row.StartedAt.Time,
+	}
+	if row.CompletedAt.Valid {
+		completed := row.CompletedAt.Time
+		run.CompletedAt = &completed
+	}
+	return run
+}
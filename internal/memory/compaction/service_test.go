@@ -0,0 +1,136 @@
+package compaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+type fakeRunQueries struct {
+	dbstore.Queries
+
+	created   []sqlc.CreateMemoryCompactRunParams
+	completed []sqlc.CompleteMemoryCompactRunParams
+}
+
+func (f *fakeRunQueries) CreateMemoryCompactRun(_ context.Context, arg sqlc.CreateMemoryCompactRunParams) (sqlc.MemoryCompactRun, error) {
+	f.created = append(f.created, arg)
+	return sqlc.MemoryCompactRun{ID: botUUID(9), BotID: arg.BotID, Source: arg.Source, Status: StatusRunning}, nil
+}
+
+func (f *fakeRunQueries) CompleteMemoryCompactRun(_ context.Context, arg sqlc.CompleteMemoryCompactRunParams) (sqlc.MemoryCompactRun, error) {
+	f.completed = append(f.completed, arg)
+	return sqlc.MemoryCompactRun{ID: arg.ID, Status: arg.Status}, nil
+}
+
+func (*fakeRunQueries) ListMemoryCompactRuns(context.Context, sqlc.ListMemoryCompactRunsParams) ([]sqlc.MemoryCompactRun, error) {
+	return nil, nil
+}
+
+type fakeCompactProvider struct {
+	memprovider.Provider
+
+	semantic bool
+	result   memprovider.CompactResult
+	err      error
+	filters  map[string]any
+}
+
+func (f *fakeCompactProvider) SemanticCompactCapability() memprovider.MemoryCompactCapability {
+	return memprovider.MemoryCompactCapability{Semantic: f.semantic, Reason: "no llm"}
+}
+
+func (f *fakeCompactProvider) Compact(_ context.Context, filters map[string]any, _ float64, _ int) (memprovider.CompactResult, error) {
+	f.filters = filters
+	return f.result, f.err
+}
+
+func botUUID(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func newTestService(provider memprovider.Provider) (*Service, *fakeRunQueries) {
+	queries := &fakeRunQueries{}
+	svc := NewService(nil, queries)
+	svc.resolve = func(context.Context, string) (memprovider.Provider, error) {
+		return provider, nil
+	}
+	return svc, queries
+}
+
+func TestCompactRecordsRun(t *testing.T) {
+	t.Parallel()
+
+	provider := &fakeCompactProvider{semantic: true, result: memprovider.CompactResult{BeforeCount: 10, AfterCount: 4}}
+	svc, queries := newTestService(provider)
+	botID := botUUID(1).String()
+
+	result, err := svc.Compact(context.Background(), botID, Request{Ratio: 0.5, DecayDays: 30, Source: SourceSchedule})
+	if err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if result.AfterCount != 4 {
+		t.Fatalf("result = %+v", result)
+	}
+	if provider.filters["namespace"] != "bot" || provider.filters["scopeId"] != botID {
+		t.Fatalf("filters = %v", provider.filters)
+	}
+	if len(queries.created) != 1 || queries.created[0].Source != SourceSchedule || queries.created[0].DecayDays != 30 {
+		t.Fatalf("created = %+v", queries.created)
+	}
+	if len(queries.completed) != 1 || queries.completed[0].Status != StatusOK || queries.completed[0].BeforeCount != 10 || queries.completed[0].AfterCount != 4 {
+		t.Fatalf("completed = %+v", queries.completed)
+	}
+}
+
+func TestCompactRecordsFailure(t *testing.T) {
+	t.Parallel()
+
+	provider := &fakeCompactProvider{semantic: true, err: errors.New("llm unavailable")}
+	svc, queries := newTestService(provider)
+
+	if _, err := svc.Compact(context.Background(), botUUID(1).String(), Request{Ratio: 0.5}); err == nil {
+		t.Fatal("Compact succeeded, want error")
+	}
+	if len(queries.created) != 1 || queries.created[0].Source != SourceManual {
+		t.Fatalf("created = %+v", queries.created)
+	}
+	if len(queries.completed) != 1 || queries.completed[0].Status != StatusError || queries.completed[0].ErrorMessage != "llm unavailable" {
+		t.Fatalf("completed = %+v", queries.completed)
+	}
+}
+
+func TestCompactRejectsWithoutRecording(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		provider memprovider.Provider
+		req      Request
+		want     error
+	}{
+		{name: "ratio too high", provider: &fakeCompactProvider{semantic: true}, req: Request{Ratio: 1.5}, want: ErrInvalidRequest},
+		{name: "negative decay", provider: &fakeCompactProvider{semantic: true}, req: Request{Ratio: 0.5, DecayDays: -1}, want: ErrInvalidRequest},
+		{name: "not semantic", provider: &fakeCompactProvider{}, req: Request{Ratio: 0.5}, want: ErrNotSupported},
+		{name: "no capability", provider: struct{ memprovider.Provider }{}, req: Request{Ratio: 0.5}, want: ErrNotSupported},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, queries := newTestService(tc.provider)
+			if _, err := svc.Compact(context.Background(), botUUID(1).String(), tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("Compact error = %v, want %v", err, tc.want)
+			}
+			if len(queries.created) != 0 {
+				t.Fatalf("created = %+v, want none", queries.created)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Package expiry deletes memories whose TTL has passed. It finds the bots
+// with expired memory nodes and lets each bot's memory provider remove them,
+// so the provider's semantic index and derived files stay consistent.
+package expiry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+const (
+	sweepInterval = time.Minute
+	sweepBotLimit = 100
+)
+
+type expiryQueries interface {
+	ListBotsWithExpiredMemoryNodes(ctx context.Context, arg sqlc.ListBotsWithExpiredMemoryNodesParams) ([]pgtype.UUID, error)
+}
+
+// Service periodically removes expired memories.
+type Service struct {
+	queries         dbstore.Queries
+	settingsService *settings.Service
+	memoryRegistry  *memprovider.Registry
+	resolve         func(ctx context.Context, botID string) (memprovider.Provider, error)
+	logger          *slog.Logger
+}
+
+// NewService creates a memory expiry service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	s := &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "memory_expiry")),
+	}
+	s.resolve = func(ctx context.Context, botID string) (memprovider.Provider, error) {
+		return memprovider.ResolveBotProvider(ctx, s.memoryRegistry, s.settingsService, botID)
+	}
+	return s
+}
+
+// SetMemoryRegistry sets the registry used to reach each bot's provider.
+func (s *Service) SetMemoryRegistry(registry *memprovider.Registry) {
+	s.memoryRegistry = registry
+}
+
+// SetSettingsService sets the settings service used to find a bot's
+// selected memory provider.
+func (s *Service) SetSettingsService(service *settings.Service) {
+	s.settingsService = service
+}
+
+func (s *Service) store() (expiryQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("memory expiry service not configured")
+	}
+	store, ok := s.queries.(expiryQueries)
+	if !ok {
+		return nil, errors.New("memory expiry queries not supported by store")
+	}
+	return store, nil
+}
+
+// Run sweeps expired memories every minute until done is closed.
+func (s *Service) Run(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.Sweep(ctx, time.Now()); err != nil {
+			s.logger.Warn("memory expiry sweep failed", slog.Any("error", err))
+		} else if n > 0 {
+			s.logger.Info("expired memories removed", slog.Int("count", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep removes memories that expired at or before now and returns how many
+// were removed. A bot whose provider fails is logged and skipped.
+func (s *Service) Sweep(ctx context.Context, now time.Time) (int, error) {
+	store, err := s.store()
+	if err != nil {
+		return 0, err
+	}
+	botIDs, err := store.ListBotsWithExpiredMemoryNodes(ctx, sqlc.ListBotsWithExpiredMemoryNodesParams{
+		Now:      pgtype.Timestamptz{Time: now.UTC(), Valid: true},
+		RowLimit: sweepBotLimit,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list bots with expired memories: %w", err)
+	}
+	removed := 0
+	for _, pgBotID := range botIDs {
+		botID := pgBotID.String()
+		n, err := s.expireBot(ctx, botID, now)
+		if err != nil {
+			s.logger.Warn("expire bot memories failed", slog.String("bot_id", botID), slog.Any("error", err))
+			continue
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+func (s *Service) expireBot(ctx context.Context, botID string, now time.Time) (int, error) {
+	provider, err := s.resolve(ctx, botID)
+	if err != nil {
+		return 0, err
+	}
+	expirer, ok := provider.(memprovider.MemoryExpiryProvider)
+	if !ok {
+		return 0, nil
+	}
+	return expirer.ExpireMemories(ctx, botID, now)
+}
@@ -0,0 +1,71 @@
+package expiry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+type fakeExpiryQueries struct {
+	dbstore.Queries
+
+	bots []pgtype.UUID
+}
+
+func (f *fakeExpiryQueries) ListBotsWithExpiredMemoryNodes(context.Context, sqlc.ListBotsWithExpiredMemoryNodesParams) ([]pgtype.UUID, error) {
+	return f.bots, nil
+}
+
+type fakeExpiringProvider struct {
+	memprovider.Provider
+
+	removed int
+	err     error
+	bots    []string
+}
+
+func (f *fakeExpiringProvider) ExpireMemories(_ context.Context, botID string, _ time.Time) (int, error) {
+	f.bots = append(f.bots, botID)
+	return f.removed, f.err
+}
+
+func botUUID(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func TestSweepExpiresEachBot(t *testing.T) {
+	t.Parallel()
+
+	queries := &fakeExpiryQueries{bots: []pgtype.UUID{botUUID(1), botUUID(2), botUUID(3)}}
+	svc := NewService(nil, queries)
+	healthy := &fakeExpiringProvider{removed: 2}
+	failing := &fakeExpiringProvider{err: errors.New("index unavailable")}
+	svc.resolve = func(_ context.Context, botID string) (memprovider.Provider, error) {
+		switch botID {
+		case botUUID(2).String():
+			return failing, nil
+		case botUUID(3).String():
+			// Providers without TTL support are skipped.
+			return struct{ memprovider.Provider }{}, nil
+		}
+		return healthy, nil
+	}
+
+	n, err := svc.Sweep(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("removed = %d, want 2", n)
+	}
+	if len(healthy.bots) != 1 || len(failing.bots) != 1 {
+		t.Fatalf("expired bots = %v / %v", healthy.bots, failing.bots)
+	}
+}
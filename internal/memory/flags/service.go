@@ -0,0 +1,247 @@
+// Package flags queues memories the agent believes are outdated, such as an
+// old address the user corrected in chat. The agent cannot delete memories
+// on its own; a flag waits until the bot owner confirms or dismisses it.
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	messagepkg "github.com/memohai/memoh/internal/chat/message"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+const (
+	maxReasonRunes = 500
+	maxMemoryRunes = 2000
+	defaultLimit   = 50
+	maxLimit       = 200
+)
+
+var (
+	ErrFlagNotFound  = errors.New("memory flag not found")
+	ErrFlagResolved  = errors.New("memory flag is already resolved")
+	ErrInvalidMemory = errors.New("invalid memory id")
+	ErrInvalidReason = errors.New("invalid flag reason")
+	ErrInvalidStatus = errors.New("invalid flag status")
+)
+
+type flagQueries interface {
+	CreateMemoryFlag(ctx context.Context, arg sqlc.CreateMemoryFlagParams) (sqlc.MemoryFlag, error)
+	GetMemoryFlag(ctx context.Context, arg sqlc.GetMemoryFlagParams) (sqlc.MemoryFlag, error)
+	ListMemoryFlags(ctx context.Context, arg sqlc.ListMemoryFlagsParams) ([]sqlc.MemoryFlag, error)
+	ResolveMemoryFlag(ctx context.Context, arg sqlc.ResolveMemoryFlagParams) (sqlc.MemoryFlag, error)
+}
+
+// Service stores memory flags.
+type Service struct {
+	queries dbstore.Queries
+	cipher  messagepkg.ContentCipher
+	logger  *slog.Logger
+}
+
+// NewService creates a memory flag service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "memory_flags")),
+	}
+}
+
+// SetContentCipher seals the flagged memory text with the bot's data key. The
+// text is a copy of the memory, so it gets the same protection as history.
+func (s *Service) SetContentCipher(cipher messagepkg.ContentCipher) {
+	s.cipher = cipher
+}
+
+func (s *Service) store() (flagQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("memory flag service not configured")
+	}
+	store, ok := s.queries.(flagQueries)
+	if !ok {
+		return nil, errors.New("memory flag queries not supported by store")
+	}
+	return store, nil
+}
+
+// Create queues a memory of the bot for review. Memory IDs carry the owning
+// bot as a "<botID>:" prefix, and a bot may only flag its own memories.
+func (s *Service) Create(ctx context.Context, botID string, req CreateRequest) (Flag, error) {
+	store, err := s.store()
+	if err != nil {
+		return Flag{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Flag{}, fmt.Errorf("invalid bot id: %w", err)
+	}
+	memoryID := strings.TrimSpace(req.MemoryID)
+	if !OwnedByBot(botID, memoryID) {
+		return Flag{}, fmt.Errorf("%w: %q does not belong to this bot", ErrInvalidMemory, memoryID)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxReasonRunes {
+		return Flag{}, fmt.Errorf("%w: must be 1 to %d characters", ErrInvalidReason, maxReasonRunes)
+	}
+	memory := textutil.TruncateRunesWithSuffix(strings.TrimSpace(req.Memory), maxMemoryRunes, "…")
+	if s.cipher != nil && memory != "" {
+		if memory, err = s.cipher.SealText(ctx, pgBotID, memory); err != nil {
+			return Flag{}, fmt.Errorf("seal flagged memory: %w", err)
+		}
+	}
+	row, err := store.CreateMemoryFlag(ctx, sqlc.CreateMemoryFlagParams{
+		BotID:     pgBotID,
+		MemoryID:  memoryID,
+		Memory:    memory,
+		Reason:    reason,
+		SessionID: strings.TrimSpace(req.SessionID),
+	})
+	if err != nil {
+		return Flag{}, err
+	}
+	return s.toFlag(ctx, row)
+}
+
+// List returns the bot's flags, newest first. An empty status lists flags in
+// every status.
+func (s *Service) List(ctx context.Context, botID, status string, limit int) ([]Flag, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot id: %w", err)
+	}
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && status != StatusPending && status != StatusConfirmed && status != StatusDismissed {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	limit = min(limit, maxLimit)
+	rows, err := store.ListMemoryFlags(ctx, sqlc.ListMemoryFlagsParams{
+		BotID:      pgBotID,
+		Status:     status,
+		LimitCount: int32(limit), //nolint:gosec // G115: bounded by maxLimit
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Flag, 0, len(rows))
+	for _, row := range rows {
+		item, err := s.toFlag(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Get returns one of the bot's flags.
+func (s *Service) Get(ctx context.Context, botID, flagID string) (Flag, error) {
+	store, err := s.store()
+	if err != nil {
+		return Flag{}, err
+	}
+	pgBotID, pgID, err := parseFlagID(botID, flagID)
+	if err != nil {
+		return Flag{}, err
+	}
+	row, err := store.GetMemoryFlag(ctx, sqlc.GetMemoryFlagParams{ID: pgID, BotID: pgBotID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Flag{}, ErrFlagNotFound
+	}
+	if err != nil {
+		return Flag{}, err
+	}
+	return s.toFlag(ctx, row)
+}
+
+// Resolve marks a pending flag confirmed or dismissed. Deleting the memory of
+// a confirmed flag is up to the caller, which holds the memory provider.
+func (s *Service) Resolve(ctx context.Context, botID, flagID, status string) (Flag, error) {
+	store, err := s.store()
+	if err != nil {
+		return Flag{}, err
+	}
+	if status != StatusConfirmed && status != StatusDismissed {
+		return Flag{}, fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+	pgBotID, pgID, err := parseFlagID(botID, flagID)
+	if err != nil {
+		return Flag{}, err
+	}
+	row, err := store.ResolveMemoryFlag(ctx, sqlc.ResolveMemoryFlagParams{Status: status, ID: pgID, BotID: pgBotID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, getErr := s.Get(ctx, botID, flagID); getErr == nil {
+			return Flag{}, ErrFlagResolved
+		}
+		return Flag{}, ErrFlagNotFound
+	}
+	if err != nil {
+		return Flag{}, err
+	}
+	return s.toFlag(ctx, row)
+}
+
+// OwnedByBot reports whether memoryID belongs to botID. Providers parse the
+// owner as everything before the first ":".
+func OwnedByBot(botID, memoryID string) bool {
+	owner, rest, ok := strings.Cut(strings.TrimSpace(memoryID), ":")
+	return ok && strings.TrimSpace(rest) != "" && strings.TrimSpace(owner) == strings.TrimSpace(botID)
+}
+
+func parseFlagID(botID, flagID string) (pgtype.UUID, pgtype.UUID, error) {
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, fmt.Errorf("invalid bot id: %w", err)
+	}
+	pgID, err := db.ParseUUID(flagID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, ErrFlagNotFound
+	}
+	return pgBotID, pgID, nil
+}
+
+func (s *Service) toFlag(ctx context.Context, row sqlc.MemoryFlag) (Flag, error) {
+	memory := row.Memory
+	if s.cipher != nil {
+		opened, err := s.cipher.OpenText(ctx, row.BotID, memory)
+		if err != nil {
+			return Flag{}, fmt.Errorf("open flagged memory: %w", err)
+		}
+		memory = opened
+	}
+	flag := Flag{
+		ID:        row.ID.String(),
+		BotID:     row.BotID.String(),
+		MemoryID:  row.MemoryID,
+		Memory:    memory,
+		Reason:    row.Reason,
+		SessionID: row.SessionID,
+		Status:    row.Status,
+		CreatedAt: row.CreatedAt.Time,
+	}
+	if row.ResolvedAt.Valid {
+		t := row.ResolvedAt.Time
+		flag.ResolvedAt = &t
+	}
+	return flag, nil
+}
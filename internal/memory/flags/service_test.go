@@ -0,0 +1,163 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	testBotID  = "33333333-3333-3333-3333-333333333333"
+	testFlagID = "44444444-4444-4444-4444-444444444444"
+)
+
+type fakeFlagQueries struct {
+	dbstore.Queries
+
+	created []sqlc.CreateMemoryFlagParams
+	flags   map[string]sqlc.MemoryFlag
+}
+
+func (f *fakeFlagQueries) CreateMemoryFlag(_ context.Context, arg sqlc.CreateMemoryFlagParams) (sqlc.MemoryFlag, error) {
+	f.created = append(f.created, arg)
+	return sqlc.MemoryFlag{BotID: arg.BotID, MemoryID: arg.MemoryID, Memory: arg.Memory, Reason: arg.Reason, Status: StatusPending}, nil
+}
+
+func (f *fakeFlagQueries) GetMemoryFlag(_ context.Context, arg sqlc.GetMemoryFlagParams) (sqlc.MemoryFlag, error) {
+	row, ok := f.flags[arg.ID.String()]
+	if !ok || row.BotID != arg.BotID {
+		return sqlc.MemoryFlag{}, pgx.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeFlagQueries) ListMemoryFlags(_ context.Context, arg sqlc.ListMemoryFlagsParams) ([]sqlc.MemoryFlag, error) {
+	var out []sqlc.MemoryFlag
+	for _, row := range f.flags {
+		if arg.Status == "" || row.Status == arg.Status {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeFlagQueries) ResolveMemoryFlag(_ context.Context, arg sqlc.ResolveMemoryFlagParams) (sqlc.MemoryFlag, error) {
+	row, ok := f.flags[arg.ID.String()]
+	if !ok || row.BotID != arg.BotID || row.Status != StatusPending {
+		return sqlc.MemoryFlag{}, pgx.ErrNoRows
+	}
+	row.Status = arg.Status
+	f.flags[arg.ID.String()] = row
+	return row, nil
+}
+
+// fakeCipher marks sealed text with a prefix.
+type fakeCipher struct{}
+
+const sealedPrefix = "sealed:"
+
+func (fakeCipher) SealJSON(_ context.Context, _ pgtype.UUID, doc []byte) ([]byte, error) {
+	return append([]byte(sealedPrefix), doc...), nil
+}
+
+func (fakeCipher) OpenJSON(_ context.Context, _ pgtype.UUID, stored []byte) ([]byte, error) {
+	return []byte(strings.TrimPrefix(string(stored), sealedPrefix)), nil
+}
+
+func (fakeCipher) SealText(_ context.Context, _ pgtype.UUID, text string) (string, error) {
+	return sealedPrefix + text, nil
+}
+
+func (fakeCipher) OpenText(_ context.Context, _ pgtype.UUID, stored string) (string, error) {
+	return strings.TrimPrefix(stored, sealedPrefix), nil
+}
+
+func newTestService(t *testing.T) (*Service, *fakeFlagQueries) {
+	t.Helper()
+	botID, err := db.ParseUUID(testBotID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flagID, err := db.ParseUUID(testFlagID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := &fakeFlagQueries{flags: map[string]sqlc.MemoryFlag{
+		testFlagID: {ID: flagID, BotID: botID, MemoryID: testBotID + ":mem_1", Status: StatusPending},
+	}}
+	return NewService(nil, queries), queries
+}
+
+func TestCreateRequiresOwnMemoryAndReason(t *testing.T) {
+	svc, queries := newTestService(t)
+	ctx := context.Background()
+
+	flag, err := svc.Create(ctx, testBotID, CreateRequest{MemoryID: testBotID + ":mem_1", Memory: "Lives at 1 Old Street", Reason: " moved to 2 New Road "})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if flag.Status != StatusPending || flag.Reason != "moved to 2 New Road" {
+		t.Fatalf("flag = %+v", flag)
+	}
+
+	for name, req := range map[string]CreateRequest{
+		"other bot":  {MemoryID: "55555555-5555-5555-5555-555555555555:mem_1", Reason: "outdated"},
+		"no prefix":  {MemoryID: "mem_1", Reason: "outdated"},
+		"no reason":  {MemoryID: testBotID + ":mem_1"},
+		"empty tail": {MemoryID: testBotID + ":", Reason: "outdated"},
+	} {
+		if _, err := svc.Create(ctx, testBotID, req); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if len(queries.created) != 1 {
+		t.Fatalf("created %d flags, want 1", len(queries.created))
+	}
+}
+
+func TestCreateSealsMemoryText(t *testing.T) {
+	svc, queries := newTestService(t)
+	svc.SetContentCipher(fakeCipher{})
+	ctx := context.Background()
+
+	flag, err := svc.Create(ctx, testBotID, CreateRequest{MemoryID: testBotID + ":mem_1", Memory: "Lives at 1 Old Street", Reason: "moved"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if got := queries.created[0].Memory; got != sealedPrefix+"Lives at 1 Old Street" {
+		t.Fatalf("stored memory = %q, want it sealed", got)
+	}
+	if flag.Memory != "Lives at 1 Old Street" {
+		t.Fatalf("flag memory = %q, want the opened text", flag.Memory)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.Resolve(ctx, testBotID, testFlagID, StatusPending); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("Resolve to pending error = %v", err)
+	}
+	flag, err := svc.Resolve(ctx, testBotID, testFlagID, StatusDismissed)
+	if err != nil || flag.Status != StatusDismissed {
+		t.Fatalf("Resolve = %+v, %v", flag, err)
+	}
+	if _, err := svc.Resolve(ctx, testBotID, testFlagID, StatusConfirmed); !errors.Is(err, ErrFlagResolved) {
+		t.Fatalf("second Resolve error = %v", err)
+	}
+	if _, err := svc.Resolve(ctx, "55555555-5555-5555-5555-555555555555", testFlagID, StatusConfirmed); !errors.Is(err, ErrFlagNotFound) {
+		t.Fatalf("Resolve for other bot error = %v", err)
+	}
+	if _, err := svc.List(ctx, testBotID, "stale", 0); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("List with bad status error = %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package flags
+
+import "time"
+
+// Flag statuses. A flag stays pending until the bot owner confirms it, which
+// deletes the memory, or dismisses it, which keeps the memory.
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusDismissed = "dismissed"
+)
+
+// Flag is a memory the agent marked as outdated or wrong, queued for the
+// bot owner to review. Memory is the memory text when it was flagged.
+type Flag struct {
+	ID         string     `json:"id"`
+	BotID      string     `json:"bot_id"`
+	MemoryID   string     `json:"memory_id"`
+	Memory     string     `json:"memory"`
+	Reason     string     `json:"reason"`
+	SessionID  string     `json:"session_id,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// CreateRequest flags a memory. Flagging a memory that already has a pending
+// flag replaces its reason.
+type CreateRequest struct {
+	MemoryID  string
+	Memory    string
+	Reason    string
+	SessionID string
+}
+
+// ListFlagsResponse is the response body of the flag list endpoint.
+type ListFlagsResponse struct {
+	Items []Flag `json:"items"`
+}
@@ -17,6 +17,7 @@ const (
 	defaultTimeout   = models.DefaultProviderRequestTimeout
 	maxExtractFacts  = 10
 	maxDecideActions = 20
+	maxExpandQueries = 3
 )
 
 // Config holds model resolution details for the memory LLM.
@@ -169,7 +170,59 @@ func (c *Client) Compact(ctx context.Context, req adapters.CompactRequest) (adap
 	return adapters.CompactResponse{Facts: facts}, nil
 }
 
-// buildUpdateUserMessage formats the Decide user message following Mem0's
+// ExpandQuery rewrites a memory search query into alternate queries.
+func (c *Client) ExpandQuery(ctx context.Context, req adapters.ExpandQueryRequest) (adapters.ExpandQueryResponse, error) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return adapters.ExpandQueryResponse{}, nil
+	}
+	limit := req.Max
+	if limit <= 0 {
+		limit = maxExpandQueries
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	payload, err := json.Marshal(map[string]any{
+		"query":       query,
+		"languages":   req.Languages,
+		"max_queries": limit,
+	})
+	if err != nil {
+		return adapters.ExpandQueryResponse{}, fmt.Errorf("expand query: marshal input: %w", err)
+	}
+	result, err := sdk.GenerateTextResult(ctx,
+		sdk.WithModel(c.model()),
+		sdk.WithSystem(expandQuerySystemPrompt),
+		sdk.WithMessages([]sdk.Message{sdk.UserMessage(string(payload))}),
+	)
+	if err != nil {
+		return adapters.ExpandQueryResponse{}, fmt.Errorf("expand query: %w", err)
+	}
+	return adapters.ExpandQueryResponse{Queries: expandedQueries(query, parseJSONStringArray(result.Text), limit)}, nil
+}
+
+// expandedQueries drops blanks, duplicates and the original query and keeps
+// at most limit queries.
+func expandedQueries(query string, queries []string, limit int) []string {
+	seen := map[string]bool{strings.ToLower(query): true}
+	out := make([]string, 0, min(limit, len(queries)))
+	for _, q := range queries {
+		q = strings.TrimSpace(q)
+		key := strings.ToLower(q)
+		if q == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, q)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+// buildUpdateUserMessage formats// buildUpdateUserMessage formats the Decide user message following Mem0's
 // update prompt convention: current memory + retrieved facts in triple backticks.
 func buildUpdateUserMessage(candidates []adapters.CandidateMemory, facts []string) string {
 	var sb strings.Builder
@@ -344,3 +397,14 @@ Output rules:
 - Return a JSON array only.
 - Each array item must be a concise fact string.
 - Do not wrap the JSON in Markdown or add explanatory text.`
+
+const expandQuerySystemPrompt = `You expand search queries for a long-term memory store. The user message is JSON with "query", optional "languages", and "max_queries".
+
+- When "languages" is non-empty, translate the query into each listed language, one query per language.
+- When "languages" is empty, write rephrasings of the query in its own language using synonyms and closely related terms.
+- Keep names, numbers, and identifiers unchanged. Keep each query as short as the original.
+- Return at most max_queries queries and never repeat the original query.
+
+Output rules:
+- Return a JSON array of strings only.
+- Do not wrap the JSON in Markdown or add explanatory text.`
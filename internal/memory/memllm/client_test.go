@@ -191,3 +191,11 @@ func TestExtractJSONBlock_PlainFence(t *testing.T) {
 }
 
 var _ adapters.LLM = (*Client)(nil)
+
+func TestExpandedQueries_DropsOriginalAndDuplicates(t *testing.T) {
+	t.Parallel()
+	got := expandedQueries("Oolong tea", []string{"oolong tea", "乌龙茶", " ", "乌龙茶", "ウーロン茶", "чай улун"}, 2)
+	if strings.Join(got, "|") != "乌龙茶|ウーロン茶" {
+		t.Fatalf("expandedQueries = %v", got)
+	}
+}
@@ -0,0 +1,306 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Memory export formats accepted by ParseExternal. ExternalFormatMemoh is
+// the JSON Lines file written by the memory export endpoint.
+const (
+	ExternalFormatMem0  = "mem0"
+	ExternalFormatLetta = "letta"
+	ExternalFormatMemoh = "memoh"
+)
+
+var (
+	ErrUnsupportedExternalFormat = errors.New("unsupported memory export format")
+	ErrInvalidExternalExport     = errors.New("invalid memory export")
+)
+
+// ExternalMemory is one memory read from another memory framework's export,
+// with metadata already mapped to the keys Memoh nodes understand (layer,
+// topic) plus the source_* keys that record where it came from.
+type ExternalMemory struct {
+	SourceID  string
+	Text      string
+	CreatedAt time.Time
+	Metadata  map[string]any
+}
+
+// ParseExternal reads a mem0, Letta or Memoh memory export. Entries with
+// empty text and exact duplicates are dropped.
+func ParseExternal(format string, data []byte) ([]ExternalMemory, error) {
+	var (
+		out []ExternalMemory
+		err error
+	)
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case ExternalFormatMem0:
+		out, err = parseMem0(data)
+	case ExternalFormatLetta:
+		out, err = parseLetta(data)
+	case ExternalFormatMemoh:
+		out, err = parseMemoh(data)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedExternalFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dedupeExternal(out), nil
+}
+
+type mem0Memory struct {
+	ID         string         `json:"id"`
+	Memory     string         `json:"memory"`
+	Text       string         `json:"text"`
+	Metadata   map[string]any `json:"metadata"`
+	Categories []string       `json:"categories"`
+	UserID     string         `json:"user_id"`
+	AgentID    string         `json:"agent_id"`
+	CreatedAt  string         `json:"created_at"`
+}
+
+// parseMem0 accepts the output of mem0's get_all, either the bare list or
+// the {"results": [...]} / {"memories": [...]} envelopes.
+func parseMem0(data []byte) ([]ExternalMemory, error) {
+	var items []mem0Memory
+	if err := unmarshalListOrEnvelope(data, &items, "results", "memories"); err != nil {
+		return nil, err
+	}
+	out := make([]ExternalMemory, 0, len(items))
+	for _, item := range items {
+		text := item.Memory
+		if strings.TrimSpace(text) == "" {
+			text = item.Text
+		}
+		metadata := map[string]any{"source": ExternalFormatMem0}
+		for k, v := range item.Metadata {
+			metadata[k] = v
+		}
+		setSourceMetadata(metadata, "source_id", item.ID)
+		setSourceMetadata(metadata, "source_user_id", item.UserID)
+		setSourceMetadata(metadata, "source_agent_id", item.AgentID)
+		if len(item.Categories) > 0 {
+			metadata["source_categories"] = item.Categories
+			if metadataString(metadata, "topic") == "" {
+				metadata["topic"] = item.Categories[0]
+			}
+		}
+		out = append(out, ExternalMemory{
+			SourceID:  item.ID,
+			Text:      text,
+			CreatedAt: parseExternalTime(item.CreatedAt),
+			Metadata:  metadata,
+		})
+	}
+	return out, nil
+}
+
+type lettaPassage struct {
+	ID        string         `json:"id"`
+	Text      string         `json:"text"`
+	Tags      []string       `json:"tags"`
+	Metadata  map[string]any `json:"metadata"`
+	CreatedAt string         `json:"created_at"`
+}
+
+type lettaBlock struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+type lettaAgent struct {
+	Name       string         `json:"name"`
+	CoreMemory []lettaBlock   `json:"core_memory"`
+	Passages   []lettaPassage `json:"passages"`
+}
+
+type lettaExport struct {
+	Agents     []lettaAgent   `json:"agents"`
+	Blocks     []lettaBlock   `json:"blocks"`
+	CoreMemory []lettaBlock   `json:"core_memory"`
+	Passages   []lettaPassage `json:"passages"`
+}
+
+// parseLetta accepts a list of archival memory passages as returned by the
+// Letta API, or an agent file whose core memory blocks and passages are
+// imported together.
+func parseLetta(data []byte) ([]ExternalMemory, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var passages []lettaPassage
+		if err := json.Unmarshal(data, &passages); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidExternalExport, err.Error())
+		}
+		return lettaPassages(passages), nil
+	}
+	var export lettaExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidExternalExport, err.Error())
+	}
+	blocks := make([]lettaBlock, 0, len(export.Blocks)+len(export.CoreMemory))
+	blocks = append(blocks, export.Blocks...)
+	blocks = append(blocks, export.CoreMemory...)
+	passages := export.Passages
+	for _, agent := range export.Agents {
+		blocks = append(blocks, agent.CoreMemory...)
+		passages = append(passages, agent.Passages...)
+	}
+	if len(blocks) == 0 && len(passages) == 0 {
+		return nil, fmt.Errorf("%w: no passages or memory blocks found", ErrInvalidExternalExport)
+	}
+	out := lettaBlocks(blocks)
+	return append(out, lettaPassages(passages)...), nil
+}
+
+func lettaPassages(passages []lettaPassage) []ExternalMemory {
+	out := make([]ExternalMemory, 0, len(passages))
+	for _, p := range passages {
+		metadata := map[string]any{"source": ExternalFormatLetta}
+		for k, v := range p.Metadata {
+			metadata[k] = v
+		}
+		setSourceMetadata(metadata, "source_id", p.ID)
+		if len(p.Tags) > 0 {
+			metadata["source_tags"] = p.Tags
+			if metadataString(metadata, "topic") == "" {
+				metadata["topic"] = p.Tags[0]
+			}
+		}
+		out = append(out, ExternalMemory{
+			SourceID:  p.ID,
+			Text:      p.Text,
+			CreatedAt: parseExternalTime(p.CreatedAt),
+			Metadata:  metadata,
+		})
+	}
+	return out
+}
+
+// lettaBlocks maps core memory blocks to layered memories: the "human" block
+// describes the user and "persona" the agent itself.
+func lettaBlocks(blocks []lettaBlock) []ExternalMemory {
+	out := make([]ExternalMemory, 0, len(blocks))
+	for _, b := range blocks {
+		label := strings.ToLower(strings.TrimSpace(b.Label))
+		metadata := map[string]any{"source": ExternalFormatLetta, "topic": label}
+		setSourceMetadata(metadata, "source_id", b.ID)
+		switch label {
+		case "human":
+			metadata["layer"] = string(LayerIdentity)
+		case "persona":
+			metadata["layer"] = string(LayerPersona)
+		default:
+			metadata["layer"] = string(LayerContext)
+		}
+		out = append(out, ExternalMemory{SourceID: b.ID, Text: b.Value, Metadata: metadata})
+	}
+	return out
+}
+
+type memohMemory struct {
+	ID        string         `json:"id"`
+	Memory    string         `json:"memory"`
+	CreatedAt string         `json:"created_at"`
+	Metadata  map[string]any `json:"metadata"`
+}
+
+// parseMemoh reads a Memoh memory export: one memory object per line. The
+// exported metadata is kept as is so a restored memory keeps its layer,
+// topic and original source.
+func parseMemoh(data []byte) ([]ExternalMemory, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var out []ExternalMemory
+	for line := 1; ; line++ {
+		var item memohMemory
+		if err := decoder.Decode(&item); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("%w: record %d: %s", ErrInvalidExternalExport, line, err.Error())
+		}
+		metadata := map[string]any{}
+		for k, v := range item.Metadata {
+			metadata[k] = v
+		}
+		setSourceMetadata(metadata, "source_id", item.ID)
+		out = append(out, ExternalMemory{
+			SourceID:  item.ID,
+			Text:      item.Memory,
+			CreatedAt: parseExternalTime(item.CreatedAt),
+			Metadata:  metadata,
+		})
+	}
+	return out, nil
+}
+
+func unmarshalListOrEnvelope(data []byte, out any, keys ...string) error {
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidExternalExport, err.Error())
+		}
+		return nil
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidExternalExport, err.Error())
+	}
+	for _, key := range keys {
+		raw, ok := envelope[key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(raw, out); err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrInvalidExternalExport, key, err.Error())
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: expected a list or one of %s", ErrInvalidExternalExport, strings.Join(keys, ", "))
+}
+
+func dedupeExternal(items []ExternalMemory) []ExternalMemory {
+	out := items[:0]
+	seen := map[string]struct{}{}
+	for _, item := range items {
+		item.Text = strings.TrimSpace(item.Text)
+		if item.Text == "" {
+			continue
+		}
+		if _, dup := seen[item.Text]; dup {
+			continue
+		}
+		seen[item.Text] = struct{}{}
+		if !item.CreatedAt.IsZero() {
+			item.Metadata["source_created_at"] = item.CreatedAt.UTC().Format(time.RFC3339)
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func setSourceMetadata(metadata map[string]any, key, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		metadata[key] = value
+	}
+}
+
+func parseExternalTime(raw string) time.Time {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
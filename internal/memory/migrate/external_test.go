@@ -0,0 +1,118 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseExternalMem0(t *testing.T) {
+	data := []byte(`{"results": [
+		{"id": "m-1", "memory": "Prefers oolong tea", "user_id": "alice", "categories": ["food"], "created_at": "2024-07-20T23:08:18.620396-07:00", "metadata": {"confidence": 0.9}},
+		{"id": "m-2", "memory": "  Prefers oolong tea  ", "user_id": "alice"},
+		{"id": "m-3", "memory": "", "user_id": "alice"},
+		{"id": "m-4", "memory": "Lives in Berlin", "user_id": "alice", "metadata": {"topic": "location"}, "categories": ["personal"]}
+	]}`)
+	items, err := ParseExternal("mem0", data)
+	if err != nil {
+		t.Fatalf("ParseExternal returned error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 memories after dedupe, got %d", len(items))
+	}
+	first := items[0]
+	if first.Text != "Prefers oolong tea" || first.Metadata["source_id"] != "m-1" || first.Metadata["source_user_id"] != "alice" {
+		t.Fatalf("unexpected first memory: %+v", first)
+	}
+	if first.Metadata["topic"] != "food" || first.Metadata["confidence"] != 0.9 {
+		t.Fatalf("expected category topic and kept metadata, got %+v", first.Metadata)
+	}
+	if first.Metadata["source_created_at"] != "2024-07-21T06:08:18Z" {
+		t.Fatalf("unexpected source_created_at: %v", first.Metadata["source_created_at"])
+	}
+	if items[1].Metadata["topic"] != "location" {
+		t.Fatalf("explicit topic should win over categories, got %v", items[1].Metadata["topic"])
+	}
+}
+
+func TestParseExternalMem0BareList(t *testing.T) {
+	items, err := ParseExternal("mem0", []byte(`[{"id": "m-1", "memory": "Has a cat"}]`))
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one memory, got %d (%v)", len(items), err)
+	}
+}
+
+func TestParseExternalLettaAgentFile(t *testing.T) {
+	data := []byte(`{"agents": [{
+		"name": "helper",
+		"core_memory": [
+			{"id": "block-1", "label": "human", "value": "Name: Alice. Works as a nurse."},
+			{"id": "block-2", "label": "persona", "value": "I am a cheerful assistant."},
+			{"id": "block-3", "label": "projects", "value": "Garden redesign"}
+		],
+		"passages": [{"id": "passage-1", "text": "Alice visited Lisbon in May.", "tags": ["travel"], "created_at": "2024-05-02T10:00:00Z"}]
+	}]}`)
+	items, err := ParseExternal("letta", data)
+	if err != nil {
+		t.Fatalf("ParseExternal returned error: %v", err)
+	}
+	if len(items) != 4 {
+		t.Fatalf("expected 4 memories, got %d", len(items))
+	}
+	wantLayer := []string{string(LayerIdentity), string(LayerPersona), string(LayerContext)}
+	for i, want := range wantLayer {
+		if got := items[i].Metadata["layer"]; got != want {
+			t.Fatalf("block %d layer = %v, want %s", i, got, want)
+		}
+	}
+	passage := items[3]
+	if passage.Metadata["topic"] != "travel" || passage.Metadata["source"] != ExternalFormatLetta {
+		t.Fatalf("unexpected passage metadata: %+v", passage.Metadata)
+	}
+	if _, ok := passage.Metadata["layer"]; ok {
+		t.Fatalf("passages should fall back to the default layer, got %v", passage.Metadata["layer"])
+	}
+}
+
+func TestParseExternalLettaPassageList(t *testing.T) {
+	items, err := ParseExternal("letta", []byte(`[{"id": "passage-1", "text": "Likes jazz"}]`))
+	if err != nil || len(items) != 1 || items[0].Text != "Likes jazz" {
+		t.Fatalf("unexpected result: %+v (%v)", items, err)
+	}
+}
+
+func TestParseExternalMemohJSONL(t *testing.T) {
+	data := []byte(`{"id": "mem-1", "memory": "Prefers window seats", "created_at": "2026-03-01T08:00:00Z", "metadata": {"layer": "identity", "topic": "travel", "source": "mem0"}}
+{"id": "mem-2", "memory": "Allergic to peanuts"}
+
+{"id": "mem-3", "memory": "Prefers window seats"}
+`)
+	items, err := ParseExternal("memoh", data)
+	if err != nil {
+		t.Fatalf("ParseExternal returned error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 memories after dedupe, got %d", len(items))
+	}
+	first := items[0]
+	if first.Metadata["layer"] != "identity" || first.Metadata["source"] != "mem0" || first.Metadata["source_id"] != "mem-1" {
+		t.Fatalf("expected exported metadata kept, got %+v", first.Metadata)
+	}
+	if first.Metadata["source_created_at"] != "2026-03-01T08:00:00Z" {
+		t.Fatalf("unexpected source_created_at: %v", first.Metadata["source_created_at"])
+	}
+	if _, err := ParseExternal("memoh", []byte("{\"id\": \"mem-1\"}\nnot json\n")); !errors.Is(err, ErrInvalidExternalExport) {
+		t.Fatalf("expected ErrInvalidExternalExport for a broken line, got %v", err)
+	}
+}
+
+func TestParseExternalErrors(t *testing.T) {
+	if _, err := ParseExternal("zep", []byte(`[]`)); !errors.Is(err, ErrUnsupportedExternalFormat) {
+		t.Fatalf("expected ErrUnsupportedExternalFormat, got %v", err)
+	}
+	if _, err := ParseExternal("mem0", []byte(`{"data": []}`)); !errors.Is(err, ErrInvalidExternalExport) {
+		t.Fatalf("expected ErrInvalidExternalExport for unknown envelope, got %v", err)
+	}
+	if _, err := ParseExternal("letta", []byte(`{"agents": []}`)); !errors.Is(err, ErrInvalidExternalExport) {
+		t.Fatalf("expected ErrInvalidExternalExport for empty agent file, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/command"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+const (
+	// maxDigestMemories bounds one digest; the rest are counted but only
+	// reviewable in the dashboard.
+	maxDigestMemories  = 20
+	digestMemoryRunes  = 200
+	callbackActionType = "callback"
+)
+
+// newMemory is a memory added since the last review.
+type newMemory struct {
+	localID   string
+	text      string
+	createdAt time.Time
+}
+
+// deliver sends the digest of memories added since the last review. It
+// reports false without error when there was nothing to review.
+func (s *Service) deliver(ctx context.Context, row sqlc.BotMemoryReview, now time.Time) (bool, error) {
+	if s.channels == nil {
+		return false, errors.New("channel delivery not configured")
+	}
+	botID := row.BotID.String()
+	provider, err := s.resolve(ctx, botID)
+	if err != nil {
+		return false, fmt.Errorf("resolve memory provider: %w", err)
+	}
+	since := now.Add(-reviewInterval)
+	if row.LastReviewedAt.Valid {
+		since = row.LastReviewedAt.Time
+	}
+	all, err := provider.GetAll(ctx, memprovider.GetAllRequest{BotID: botID, NoStats: true})
+	if err != nil {
+		return false, fmt.Errorf("list memories: %w", err)
+	}
+	memories := memoriesSince(botID, all.Results, since, now)
+	if len(memories) == 0 {
+		return false, nil
+	}
+	channelType := channel.ChannelType(row.ChannelType)
+	buttons := false
+	if s.channelTypes != nil {
+		if caps, ok := s.channelTypes.GetCapabilities(channelType); ok {
+			buttons = caps.Buttons
+		}
+	}
+	if err := s.channels.Send(ctx, botID, channelType, channel.SendRequest{
+		Target:  row.Target,
+		Message: composeDigest(memories, buttons),
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// memoriesSince returns the bot's memories created in (since, now], oldest
+// first. Memories without a parseable creation time are skipped.
+func memoriesSince(botID string, items []memprovider.MemoryItem, since, now time.Time) []newMemory {
+	prefix := botID + ":"
+	out := make([]newMemory, 0, len(items))
+	for _, item := range items {
+		localID, ok := strings.CutPrefix(strings.TrimSpace(item.ID), prefix)
+		if !ok || localID == "" {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(item.CreatedAt))
+		if err != nil || !createdAt.After(since) || createdAt.After(now) {
+			continue
+		}
+		out = append(out, newMemory{localID: localID, text: strings.TrimSpace(item.Memory), createdAt: createdAt})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].createdAt.Before(out[j].createdAt) })
+	return out
+}
+
+// composeDigest lists the memories with their IDs. Channels with callback
+// buttons get a keep/forget row per memory; the others get the equivalent
+// slash commands to type.
+func composeDigest(memories []newMemory, buttons bool) channel.Message {
+	header := fmt.Sprintf("**Memory review**: %d new memor", len(memories))
+	if len(memories) == 1 {
+		header += "y"
+	} else {
+		header += "ies"
+	}
+	lines := []string{header + " this week.", ""}
+	shown := memories
+	if len(shown) > maxDigestMemories {
+		shown = shown[:maxDigestMemories]
+	}
+	msg := channel.Message{Format: channel.MessageFormatMarkdown}
+	for i, mem := range shown {
+		lines = append(lines, fmt.Sprintf("%d. %s (`%s`)", i+1, textutil.TruncateRunesWithSuffix(strings.Join(strings.Fields(mem.text), " "), digestMemoryRunes, "…"), mem.localID))
+		if buttons {
+			msg.Actions = append(msg.Actions,
+				channel.Action{Type: callbackActionType, Label: fmt.Sprintf("Keep %d", i+1), Value: command.EncodeMemoryReviewCallback(command.MemoryReviewKeep, mem.localID), Row: i},
+				channel.Action{Type: callbackActionType, Label: fmt.Sprintf("Forget %d", i+1), Value: command.EncodeMemoryReviewCallback(command.MemoryReviewForget, mem.localID), Row: i},
+			)
+		}
+	}
+	if rest := len(memories) - len(shown); rest > 0 {
+		lines = append(lines, "", fmt.Sprintf("…and %d more. Review them in the dashboard.", rest))
+	}
+	if !buttons {
+		lines = append(lines, "", "Reply `/memory forget <id>` to delete a memory or `/memory keep <id>` to keep it.")
+	}
+	msg.Text = strings.Join(lines, "\n")
+	return msg
+}
@@ -0,0 +1,281 @@
+// Package review sends bot owners an opt-in weekly digest of the memories a
+// bot added, with keep and forget actions for each one, so long-term memory
+// stays curated.
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+const (
+	sweepInterval = 10 * time.Minute
+	// reviewInterval is the time between two digests of the same bot.
+	reviewInterval = 7 * 24 * time.Hour
+)
+
+// ErrInvalidReview is returned for memory review settings that cannot be
+// saved.
+var ErrInvalidReview = errors.New("invalid memory review settings")
+
+type reviewQueries interface {
+	GetBotMemoryReview(ctx context.Context, botID pgtype.UUID) (sqlc.BotMemoryReview, error)
+	ListDueBotMemoryReviews(ctx context.Context, now pgtype.Timestamptz) ([]sqlc.BotMemoryReview, error)
+	MarkBotMemoryReviewSent(ctx context.Context, arg sqlc.MarkBotMemoryReviewSentParams) error
+	SetBotMemoryReviewNextAt(ctx context.Context, arg sqlc.SetBotMemoryReviewNextAtParams) error
+	UpsertBotMemoryReview(ctx context.Context, arg sqlc.UpsertBotMemoryReviewParams) (sqlc.BotMemoryReview, error)
+}
+
+// Settings is a bot's memory review configuration.
+type Settings struct {
+	BotID          string     `json:"bot_id"`
+	Enabled        bool       `json:"enabled"`
+	ChannelType    string     `json:"channel_type"`
+	Target         string     `json:"target"`
+	NextReviewAt   *time.Time `json:"next_review_at,omitempty"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty"`
+}
+
+// UpdateRequest changes the fields that are set.
+type UpdateRequest struct {
+	Enabled     *bool   `json:"enabled,omitempty"`
+	ChannelType *string `json:"channel_type,omitempty"`
+	Target      *string `json:"target,omitempty"`
+}
+
+// Service stores memory review settings and sends the weekly digests.
+type Service struct {
+	queries         dbstore.Queries
+	settingsService *settings.Service
+	memoryRegistry  *memprovider.Registry
+	channels        channel.Runtime
+	channelTypes    *channel.Registry
+	resolve         func(ctx context.Context, botID string) (memprovider.Provider, error)
+	logger          *slog.Logger
+	now             func() time.Time
+}
+
+// NewService creates a memory review service.
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	s := &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "memory_review")),
+		now:     time.Now,
+	}
+	s.resolve = func(ctx context.Context, botID string) (memprovider.Provider, error) {
+		return memprovider.ResolveBotProvider(ctx, s.memoryRegistry, s.settingsService, botID)
+	}
+	return s
+}
+
+// SetMemoryRegistry sets the registry used to reach each bot's provider.
+func (s *Service) SetMemoryRegistry(registry *memprovider.Registry) {
+	s.memoryRegistry = registry
+}
+
+// SetSettingsService sets the settings service used to find a bot's
+// selected memory provider.
+func (s *Service) SetSettingsService(service *settings.Service) {
+	s.settingsService = service
+}
+
+// SetChannelRuntime enables digest delivery. registry validates the digest
+// channel and tells whether it can show keep/forget buttons.
+func (s *Service) SetChannelRuntime(runtime channel.Runtime, registry *channel.Registry) {
+	s.channels = runtime
+	s.channelTypes = registry
+}
+
+func (s *Service) store() (reviewQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("memory review service not configured")
+	}
+	store, ok := s.queries.(reviewQueries)
+	if !ok {
+		return nil, errors.New("memory review queries not supported by store")
+	}
+	return store, nil
+}
+
+// Get returns the memory review settings of a bot. Bots that never set them
+// get disabled defaults.
+func (s *Service) Get(ctx context.Context, botID string) (Settings, error) {
+	store, err := s.store()
+	if err != nil {
+		return Settings{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Settings{}, err
+	}
+	row, err := store.GetBotMemoryReview(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Settings{BotID: botID}, nil
+		}
+		return Settings{}, fmt.Errorf("get memory review: %w", err)
+	}
+	return toSettings(row), nil
+}
+
+// Update changes the memory review settings of a bot. Enabling the review
+// schedules the first digest one week out.
+func (s *Service) Update(ctx context.Context, botID string, req UpdateRequest) (Settings, error) {
+	store, err := s.store()
+	if err != nil {
+		return Settings{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return Settings{}, err
+	}
+	current, err := s.Get(ctx, botID)
+	if err != nil {
+		return Settings{}, err
+	}
+	next := current
+	if req.Enabled != nil {
+		next.Enabled = *req.Enabled
+	}
+	if req.ChannelType != nil {
+		next.ChannelType = strings.TrimSpace(*req.ChannelType)
+	}
+	if req.Target != nil {
+		next.Target = strings.TrimSpace(*req.Target)
+	}
+	if next.ChannelType != "" && s.channelTypes != nil {
+		channelType, err := s.channelTypes.ParseChannelType(next.ChannelType)
+		if err != nil {
+			return Settings{}, fmt.Errorf("%w: %s", ErrInvalidReview, err.Error())
+		}
+		next.ChannelType = channelType.String()
+	}
+	if next.Enabled && (next.ChannelType == "" || next.Target == "") {
+		return Settings{}, fmt.Errorf("%w: channel_type and target are required to enable the review", ErrInvalidReview)
+	}
+
+	nextReviewAt := pgtype.Timestamptz{}
+	switch {
+	case !next.Enabled:
+	case current.Enabled && current.NextReviewAt != nil:
+		nextReviewAt = pgtype.Timestamptz{Time: current.NextReviewAt.UTC(), Valid: true}
+	default:
+		nextReviewAt = pgtype.Timestamptz{Time: s.now().Add(reviewInterval).UTC(), Valid: true}
+	}
+	row, err := store.UpsertBotMemoryReview(ctx, sqlc.UpsertBotMemoryReviewParams{
+		BotID:        pgBotID,
+		Enabled:      next.Enabled,
+		ChannelType:  next.ChannelType,
+		Target:       next.Target,
+		NextReviewAt: nextReviewAt,
+	})
+	if err != nil {
+		return Settings{}, fmt.Errorf("save memory review: %w", err)
+	}
+	return toSettings(row), nil
+}
+
+// Run sends due digests every sweep interval until done is closed.
+func (s *Service) Run(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.Sweep(ctx, s.now()); err != nil {
+			s.logger.Warn("memory review sweep failed", slog.Any("error", err))
+		} else if n > 0 {
+			s.logger.Info("memory review digests sent", slog.Int("count", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep sends the digests due at or before now and returns how many were
+// sent. Each bot's next review is scheduled before delivery, so a failing
+// channel is retried a week later; the memories it missed stay in the next
+// digest because they are newer than the last successful review.
+func (s *Service) Sweep(ctx context.Context, now time.Time) (int, error) {
+	store, err := s.store()
+	if err != nil {
+		return 0, err
+	}
+	due, err := store.ListDueBotMemoryReviews(ctx, pgtype.Timestamptz{Time: now.UTC(), Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("list due memory reviews: %w", err)
+	}
+	sent := 0
+	for _, row := range due {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		botID := row.BotID.String()
+		if err := store.SetBotMemoryReviewNextAt(ctx, sqlc.SetBotMemoryReviewNextAtParams{
+			BotID:        row.BotID,
+			NextReviewAt: pgtype.Timestamptz{Time: now.Add(reviewInterval).UTC(), Valid: true},
+		}); err != nil {
+			s.logger.Warn("schedule memory review failed", slog.String("bot_id", botID), slog.Any("error", err))
+			continue
+		}
+		ok, err := s.deliver(ctx, row, now)
+		if err != nil {
+			s.logger.Warn("send memory review failed", slog.String("bot_id", botID), slog.Any("error", err))
+			continue
+		}
+		if err := store.MarkBotMemoryReviewSent(context.WithoutCancel(ctx), sqlc.MarkBotMemoryReviewSentParams{
+			BotID:      row.BotID,
+			ReviewedAt: pgtype.Timestamptz{Time: now.UTC(), Valid: true},
+		}); err != nil {
+			s.logger.Warn("mark memory review sent failed", slog.String("bot_id", botID), slog.Any("error", err))
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func timePtr(ts pgtype.Timestamptz) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+func toSettings(row sqlc.BotMemoryReview) Settings {
+	return Settings{
+		BotID:          row.BotID.String(),
+		Enabled:        row.Enabled,
+		ChannelType:    row.ChannelType,
+		Target:         row.Target,
+		NextReviewAt:   timePtr(row.NextReviewAt),
+		LastReviewedAt: timePtr(row.LastReviewedAt),
+	}
+}
@@ -0,0 +1,151 @@
+package review
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/channel"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	memprovider "github.com/memohai/memoh/internal/memory/adapters"
+)
+
+type fakeReviewQueries struct {
+	dbstore.Queries
+
+	due    []sqlc.BotMemoryReview
+	nextAt []pgtype.Timestamptz
+	sent   []pgtype.UUID
+}
+
+func (*fakeReviewQueries) GetBotMemoryReview(context.Context, pgtype.UUID) (sqlc.BotMemoryReview, error) {
+	return sqlc.BotMemoryReview{}, nil
+}
+
+func (f *fakeReviewQueries) ListDueBotMemoryReviews(context.Context, pgtype.Timestamptz) ([]sqlc.BotMemoryReview, error) {
+	return f.due, nil
+}
+
+func (f *fakeReviewQueries) MarkBotMemoryReviewSent(_ context.Context, arg sqlc.MarkBotMemoryReviewSentParams) error {
+	f.sent = append(f.sent, arg.BotID)
+	return nil
+}
+
+func (f *fakeReviewQueries) SetBotMemoryReviewNextAt(_ context.Context, arg sqlc.SetBotMemoryReviewNextAtParams) error {
+	f.nextAt = append(f.nextAt, arg.NextReviewAt)
+	return nil
+}
+
+func (*fakeReviewQueries) UpsertBotMemoryReview(_ context.Context, arg sqlc.UpsertBotMemoryReviewParams) (sqlc.BotMemoryReview, error) {
+	return sqlc.BotMemoryReview{BotID: arg.BotID, Enabled: arg.Enabled, ChannelType: arg.ChannelType, Target: arg.Target, NextReviewAt: arg.NextReviewAt}, nil
+}
+
+type fakeMemoryProvider struct {
+	memprovider.Provider
+
+	items []memprovider.MemoryItem
+}
+
+func (f *fakeMemoryProvider) GetAll(context.Context, memprovider.GetAllRequest) (memprovider.SearchResponse, error) {
+	return memprovider.SearchResponse{Results: f.items}, nil
+}
+
+type fakeRuntime struct {
+	channel.Runtime
+
+	sent []channel.SendRequest
+}
+
+func (f *fakeRuntime) Send(_ context.Context, _ string, _ channel.ChannelType, req channel.SendRequest) error {
+	f.sent = append(f.sent, req)
+	return nil
+}
+
+func botUUID(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func TestSweepSendsMemoriesAddedSinceLastReview(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	botID := botUUID(1)
+	prefix := botID.String() + ":"
+	queries := &fakeReviewQueries{due: []sqlc.BotMemoryReview{{
+		BotID:          botID,
+		Enabled:        true,
+		ChannelType:    "slack",
+		Target:         "D123",
+		LastReviewedAt: pgtype.Timestamptz{Time: now.Add(-reviewInterval), Valid: true},
+	}}}
+	provider := &fakeMemoryProvider{items: []memprovider.MemoryItem{
+		{ID: prefix + "mem_old", Memory: "Reviewed last week", CreatedAt: now.Add(-8 * 24 * time.Hour).Format(time.RFC3339)},
+		{ID: prefix + "mem_b", Memory: "Prefers tea", CreatedAt: now.Add(-time.Hour).Format(time.RFC3339)},
+		{ID: prefix + "mem_a", Memory: "Lives in Lisbon", CreatedAt: now.Add(-48 * time.Hour).Format(time.RFC3339)},
+		{ID: "other-bot:mem_x", Memory: "Not ours", CreatedAt: now.Add(-time.Hour).Format(time.RFC3339)},
+	}}
+	runtime := &fakeRuntime{}
+	svc := NewService(nil, queries)
+	svc.SetChannelRuntime(runtime, nil)
+	svc.resolve = func(context.Context, string) (memprovider.Provider, error) { return provider, nil }
+
+	n, err := svc.Sweep(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if n != 1 || len(runtime.sent) != 1 {
+		t.Fatalf("sent = %d / %d, want 1", n, len(runtime.sent))
+	}
+	text := runtime.sent[0].Message.Text
+	for _, want := range []string{"2 new memories", "1. Lives in Lisbon (`mem_a`)", "2. Prefers tea (`mem_b`)", "/memory forget <id>"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("digest missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Reviewed last week") || strings.Contains(text, "Not ours") {
+		t.Fatalf("digest includes old or foreign memories:\n%s", text)
+	}
+	if len(queries.nextAt) != 1 || !queries.nextAt[0].Time.Equal(now.Add(reviewInterval)) {
+		t.Fatalf("next review = %+v, want %s", queries.nextAt, now.Add(reviewInterval))
+	}
+	if len(queries.sent) != 1 {
+		t.Fatalf("marked sent = %d, want 1", len(queries.sent))
+	}
+}
+
+func TestComposeDigestAddsKeepForgetButtons(t *testing.T) {
+	t.Parallel()
+
+	msg := composeDigest([]newMemory{{localID: "mem_a", text: "Lives in Lisbon"}}, true)
+	if len(msg.Actions) != 2 {
+		t.Fatalf("actions = %+v, want keep and forget", msg.Actions)
+	}
+	if msg.Actions[0].Value != "m~mr~k~mem_a" || msg.Actions[1].Value != "m~mr~f~mem_a" {
+		t.Fatalf("action values = %q, %q", msg.Actions[0].Value, msg.Actions[1].Value)
+	}
+	if strings.Contains(msg.Text, "/memory forget") {
+		t.Fatalf("button digest repeats the text fallback:\n%s", msg.Text)
+	}
+}
+
+func TestUpdateRequiresDestinationToEnable(t *testing.T) {
+	t.Parallel()
+
+	svc := NewService(nil, &fakeReviewQueries{})
+	enabled := true
+	if _, err := svc.Update(context.Background(), botUUID(1).String(), UpdateRequest{Enabled: &enabled}); err == nil {
+		t.Fatal("Update enabled without channel = nil error")
+	}
+	channelType, target := "telegram", "12345"
+	got, err := svc.Update(context.Background(), botUUID(1).String(), UpdateRequest{Enabled: &enabled, ChannelType: &channelType, Target: &target})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got.NextReviewAt == nil {
+		t.Fatal("enabling did not schedule the first review")
+	}
+}
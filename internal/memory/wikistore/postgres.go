@@ -14,7 +14,15 @@ import (
 
 // PostgresStore implements Store over the PostgreSQL memory_wiki tables.
 type PostgresStore struct {
-	q *dbsqlc.Queries
+	q      *dbsqlc.Queries
+	cipher TextCipher
+}
+
+// TextCipher seals node bodies at rest. *encryption.Keyring implements it;
+// bodies that were never sealed must open unchanged.
+type TextCipher interface {
+	SealText(ctx context.Context, botID pgtype.UUID, text string) (string, error)
+	OpenText(ctx context.Context, botID pgtype.UUID, stored string) (string, error)
 }
 
 // NewPostgres returns a Store backed by the PostgreSQL sqlc Queries.
@@ -22,15 +30,29 @@ func NewPostgres(q *dbsqlc.Queries) *PostgresStore {
 	return &PostgresStore{q: q}
 }
 
+// SetTextCipher enables at-rest encryption of node bodies. Hashes are
+// computed over plaintext before sealing, so deduplication is unaffected.
+func (s *PostgresStore) SetTextCipher(cipher TextCipher) {
+	s.cipher = cipher
+}
+
 func (s *PostgresStore) UpsertNode(ctx context.Context, node migrate.NodeSpec) (migrate.NodeSpec, error) {
 	if s.q == nil {
 		return migrate.NodeSpec{}, errors.New("wikistore(postgres): queries not configured")
 	}
 	r := nodeToRecord(node)
+	body := r.Body
+	if s.cipher != nil {
+		sealed, err := s.cipher.SealText(ctx, pgUUID(r.BotID), body)
+		if err != nil {
+			return migrate.NodeSpec{}, fmt.Errorf("wikistore(postgres): seal node body: %w", err)
+		}
+		body = sealed
+	}
 	row, err := s.q.UpsertMemoryNode(ctx, dbsqlc.UpsertMemoryNodeParams{
 		ID:               r.ID,
 		BotID:            pgUUID(r.BotID),
-		Body:             r.Body,
+		Body:             body,
 		Hash:             r.Hash,
 		Layer:            r.Layer,
 		FactType:         r.FactType,
@@ -46,7 +68,7 @@ func (s *PostgresStore) UpsertNode(ctx context.Context, node migrate.NodeSpec) (
 	if err != nil {
 		return migrate.NodeSpec{}, fmt.Errorf("wikistore(postgres): upsert node: %w", err)
 	}
-	return recordToNode(pgMemoryNodeToRecord(row)), nil
+	return s.nodeFromRow(ctx, row)
 }
 
 func (s *PostgresStore) GetNode(ctx context.Context, botID, nodeID string) (migrate.NodeSpec, error) {
@@ -60,7 +82,7 @@ func (s *PostgresStore) GetNode(ctx context.Context, botID, nodeID string) (migr
 		}
 		return migrate.NodeSpec{}, fmt.Errorf("wikistore(postgres): get node: %w", err)
 	}
-	return recordToNode(pgMemoryNodeToRecord(row)), nil
+	return s.nodeFromRow(ctx, row)
 }
 
 func (s *PostgresStore) ListNodes(ctx context.Context, botID string) ([]migrate.NodeSpec, error) {
@@ -73,7 +95,11 @@ func (s *PostgresStore) ListNodes(ctx context.Context, botID string) ([]migrate.
 	}
 	out := make([]migrate.NodeSpec, 0, len(rows))
 	for _, r := range rows {
-		out = append(out, recordToNode(pgMemoryNodeToRecord(r)))
+		node, err := s.nodeFromRow(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, node)
 	}
 	return out, nil
 }
@@ -91,7 +117,11 @@ func (s *PostgresStore) ListNodesByLayer(ctx context.Context, botID string, laye
 	}
 	out := make([]migrate.NodeSpec, 0, len(rows))
 	for _, r := range rows {
-		out = append(out, recordToNode(pgMemoryNodeToRecord(r)))
+		node, err := s.nodeFromRow(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, node)
 	}
 	return out, nil
 }
@@ -109,6 +139,25 @@ func (s *PostgresStore) DeleteNode(ctx context.Context, botID, nodeID string) er
 	return nil
 }
 
+func (s *PostgresStore) DeleteExpiredNodes(ctx context.Context, botID string, now time.Time) ([]string, error) {
+	if s.q == nil {
+		return nil, errors.New("wikistore(postgres): queries not configured")
+	}
+	ids, err := s.q.DeleteExpiredMemoryNodes(ctx, dbsqlc.DeleteExpiredMemoryNodesParams{
+		BotID: pgUUID(botID),
+		Now:   pgTimestamptz(now),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wikistore(postgres): delete expired nodes: %w", err)
+	}
+	for _, id := range ids {
+		if err := s.q.DeleteMemoryEdgesForNode(ctx, dbsqlc.DeleteMemoryEdgesForNodeParams{BotID: pgUUID(botID), SrcNode: id}); err != nil {
+			return ids, fmt.Errorf("wikistore(postgres): delete expired node edges: %w", err)
+		}
+	}
+	return ids, nil
+}
+
 func (s *PostgresStore) DeleteAllNodes(ctx context.Context, botID string) error {
 	if s.q == nil {
 		return errors.New("wikistore(postgres): queries not configured")
@@ -224,6 +273,17 @@ func (s *PostgresStore) RebuildDerivedEdges(ctx context.Context, botID string) (
 
 // ---- Postgres row -> record/spec helpers ----
 
+func (s *PostgresStore) nodeFromRow(ctx context.Context, r dbsqlc.MemoryNode) (migrate.NodeSpec, error) {
+	if s.cipher != nil {
+		body, err := s.cipher.OpenText(ctx, r.BotID, r.Body)
+		if err != nil {
+			return migrate.NodeSpec{}, fmt.Errorf("wikistore(postgres): open node %s body: %w", r.ID, err)
+		}
+		r.Body = body
+	}
+	return recordToNode(pgMemoryNodeToRecord(r)), nil
+}
+
 func pgMemoryNodeToRecord(r dbsqlc.MemoryNode) record {
 	rec := record{
 		ID:               r.ID,
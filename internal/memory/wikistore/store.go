@@ -30,6 +30,9 @@ type Store interface {
 	// DeleteAllNodes removes every node for a bot (edges removed by the caller
 	// or DB cascade).
 	DeleteAllNodes(ctx context.Context, botID string) error
+	// DeleteExpiredNodes removes the bot's nodes whose expires_at is at or
+	// before now, with their edges, and returns the removed node ids.
+	DeleteExpiredNodes(ctx context.Context, botID string, now time.Time) ([]string, error)
 	// CountNodes returns the node count for a bot.
 	CountNodes(ctx context.Context, botID string) (int, error)
 
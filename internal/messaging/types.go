@@ -65,12 +65,13 @@ type MessagePart struct {
 type AttachmentType string
 
 const (
-	AttachmentImage AttachmentType = "image"
-	AttachmentAudio AttachmentType = "audio"
-	AttachmentVideo AttachmentType = "video"
-	AttachmentVoice AttachmentType = "voice"
-	AttachmentFile  AttachmentType = "file"
-	AttachmentGIF   AttachmentType = "gif"
+	AttachmentImage   AttachmentType = "image"
+	AttachmentAudio   AttachmentType = "audio"
+	AttachmentVideo   AttachmentType = "video"
+	AttachmentVoice   AttachmentType = "voice"
+	AttachmentFile    AttachmentType = "file"
+	AttachmentGIF     AttachmentType = "gif"
+	AttachmentSticker AttachmentType = "sticker"
 )
 
 type Attachment struct {
@@ -196,6 +197,24 @@ type ContactReader interface {
 	ListContacts(ctx context.Context, botID string) ([]Contact, error)
 }
 
+// Sticker is a catalog sticker the agent may send. Platforms maps a platform
+// name to the sticker's native id there; Emoji is the fallback elsewhere.
+type Sticker struct {
+	Name        string
+	Description string
+	Emoji       string
+	Platforms   map[string]string
+}
+
+// NativeKey returns the sticker's id on platform, or "" when it has none.
+func (s Sticker) NativeKey(platform string) string {
+	return s.Platforms[strings.ToLower(strings.TrimSpace(platform))]
+}
+
+type StickerReader interface {
+	ListStickers(ctx context.Context, botID string) ([]Sticker, error)
+}
+
 func BundleFromAttachment(att Attachment) attachmentpkg.Bundle {
 	return attachmentpkg.Bundle{
 		Type:           string(att.Type),
@@ -514,7 +514,7 @@ func IsValidClientType(clientType ClientType) bool {
 
 func IsValidModelType(modelType ModelType) bool {
 	switch modelType {
-	case ModelTypeChat, ModelTypeEmbedding, ModelTypeSpeech, ModelTypeTranscription, ModelTypeVideo:
+	case ModelTypeChat, ModelTypeEmbedding, ModelTypeSpeech, ModelTypeTranscription, ModelTypeVideo, ModelTypeRerank:
 		return true
 	default:
 		return false
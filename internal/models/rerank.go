@@ -0,0 +1,100 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reranker scores documents against a query through an OpenAI-compatible
+// /rerank endpoint, the request shape shared by Jina, Cohere, vLLM and most
+// self-hosted rerank servers.
+type Reranker struct {
+	baseURL    string
+	apiKey     string
+	modelID    string
+	httpClient *http.Client
+}
+
+// RerankResult is the relevance score of the document at Index in the
+// request.
+type RerankResult struct {
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
+
+// NewReranker creates a Reranker for the given provider configuration.
+func NewReranker(baseURL, apiKey, modelID string, timeout time.Duration, httpClient *http.Client) *Reranker {
+	if timeout <= 0 {
+		timeout = DefaultProviderRequestTimeout
+	}
+	if httpClient == nil {
+		httpClient = NewProviderHTTPClient(timeout)
+	}
+	return &Reranker{
+		baseURL:    strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		apiKey:     strings.TrimSpace(apiKey),
+		modelID:    strings.TrimSpace(modelID),
+		httpClient: httpClient,
+	}
+}
+
+// Rerank returns one result per document, ordered by descending relevance.
+func (r *Reranker) Rerank(ctx context.Context, query string, documents []string) ([]RerankResult, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	if r.baseURL == "" {
+		return nil, errors.New("rerank: base URL is required")
+	}
+	body, err := json.Marshal(map[string]any{
+		"model":     r.modelID,
+		"query":     query,
+		"documents": documents,
+		"top_n":     len(documents),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rerank: encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+"/rerank", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("rerank: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rerank: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("rerank: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	var out struct {
+		Results []RerankResult `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("rerank: decode response: %w", err)
+	}
+	results := make([]RerankResult, 0, len(out.Results))
+	for _, result := range out.Results {
+		if result.Index < 0 || result.Index >= len(documents) {
+			return nil, fmt.Errorf("rerank: result index %d out of range", result.Index)
+		}
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].RelevanceScore > results[j].RelevanceScore
+	})
+	return results, nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRerankerOrdersResultsByRelevance(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/rerank" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			Model     string   `json:"model"`
+			Query     string   `json:"query"`
+			Documents []string `json:"documents"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "bge-reranker" || len(req.Documents) != 3 {
+			http.Error(w, "bad body", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[{"index":0,"relevance_score":0.1},{"index":2,"relevance_score":0.9},{"index":1,"relevance_score":0.5}]}`))
+	}))
+	defer srv.Close()
+
+	reranker := NewReranker(srv.URL+"/v1/", "sk-test", "bge-reranker", 0, nil)
+	results, err := reranker.Rerank(context.Background(), "coffee", []string{"tea", "espresso", "coffee beans"})
+	if err != nil {
+		t.Fatalf("Rerank: %v", err)
+	}
+	if len(results) != 3 || results[0].Index != 2 || results[1].Index != 1 || results[2].Index != 0 {
+		t.Fatalf("results = %+v", results)
+	}
+}
+
+func TestRerankerRejectsOutOfRangeIndex(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[{"index":5,"relevance_score":0.9}]}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewReranker(srv.URL, "", "m", 0, nil).Rerank(context.Background(), "q", []string{"a"}); err == nil {
+		t.Fatal("expected out-of-range index error")
+	}
+}
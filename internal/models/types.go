@@ -15,6 +15,7 @@ const (
 	ModelTypeSpeech        ModelType = "speech"
 	ModelTypeTranscription ModelType = "transcription"
 	ModelTypeVideo         ModelType = "video"
+	ModelTypeRerank        ModelType = "rerank"
 )
 
 type ClientType string
@@ -0,0 +1,62 @@
+// Package netguard keeps server-side fetches of user-supplied URLs on the
+// public internet. Its dial control runs after DNS resolution, for every
+// connection including redirects, and refuses loopback, private, link-local
+// and multicast addresses, so a URL cannot reach cloud metadata endpoints or
+// internal services.
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const defaultDialTimeout = 10 * time.Second
+
+// ErrRestrictedAddress is returned when a connection targets a non-public
+// address.
+var ErrRestrictedAddress = errors.New("destination address is not public")
+
+// IsRestricted reports whether ip must not be dialed for a user-supplied URL.
+func IsRestricted(ip net.IP) bool {
+	return ip == nil ||
+		ip.IsUnspecified() ||
+		ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast()
+}
+
+// Control is a net.Dialer control function refusing restricted addresses.
+// The dialer calls it with the resolved IP, so DNS rebinding cannot slip an
+// internal address past it.
+func Control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if IsRestricted(net.ParseIP(host)) {
+		return ErrRestrictedAddress
+	}
+	return nil
+}
+
+// NewTransport returns an HTTP transport whose connections go through
+// Control. It never uses a proxy: through a proxy only the proxy's address
+// would be checked, not the target's.
+func NewTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: defaultDialTimeout, Control: Control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// NewClient returns an HTTP client using NewTransport with the given overall
+// request timeout.
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: NewTransport()}
+}
@@ -0,0 +1,51 @@
+package netguard
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestControlRefusesNonPublicAddresses(t *testing.T) {
+	t.Parallel()
+
+	for _, address := range []string{
+		"127.0.0.1:80",
+		"10.1.2.3:443",
+		"192.168.1.1:80",
+		"169.254.169.254:80",
+		"0.0.0.0:80",
+		"[::1]:80",
+		"[fe80::1]:80",
+		"[fd00::1]:80",
+	} {
+		if err := Control("tcp", address, nil); !errors.Is(err, ErrRestrictedAddress) {
+			t.Errorf("Control(%s) = %v, want ErrRestrictedAddress", address, err)
+		}
+	}
+	for _, address := range []string{"93.184.216.34:443", "[2606:4700::1111]:443"} {
+		if err := Control("tcp", address, nil); err != nil {
+			t.Errorf("Control(%s) = %v, want nil", address, err)
+		}
+	}
+}
+
+func TestClientRefusesLoopbackServer(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://93.184.216.34:3128")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := NewClient(5 * time.Second).Get(server.URL)
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("request to a loopback server succeeded")
+	}
+	if !errors.Is(err, ErrRestrictedAddress) {
+		t.Fatalf("err = %v, want ErrRestrictedAddress", err)
+	}
+}
@@ -0,0 +1,264 @@
+// Package persona turns a bot's persona preset and personality sliders into a
+// system prompt fragment, so owners can shape how a bot talks without writing
+// prompts themselves.
+package persona
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RouteMetadataKey is the channel route metadata key holding a per-route
+// persona override.
+const RouteMetadataKey = "persona"
+
+// MaxLevel is the highest slider level; zero leaves a slider to the preset.
+const MaxLevel = 5
+
+// Tones a persona may use.
+const (
+	ToneNeutral      = "neutral"
+	ToneWarm         = "warm"
+	TonePlayful      = "playful"
+	ToneDirect       = "direct"
+	ToneProfessional = "professional"
+)
+
+var toneGuidance = map[string]string{
+	ToneNeutral:      "neutral and even-handed",
+	ToneWarm:         "warm, friendly and encouraging",
+	TonePlayful:      "playful and light-hearted, with gentle humor",
+	ToneDirect:       "direct and to the point, without hedging",
+	ToneProfessional: "professional and composed",
+}
+
+var verbosityGuidance = [MaxLevel + 1]string{
+	"",
+	"Keep replies as short as possible, a sentence or two.",
+	"Prefer brief replies and skip background unless asked.",
+	"Match reply length to the question.",
+	"Give thorough replies with relevant context.",
+	"Give detailed, comprehensive replies with examples.",
+}
+
+var emojiGuidance = [MaxLevel + 1]string{
+	"",
+	"Never use emoji.",
+	"Use emoji rarely, only when they add meaning.",
+	"Use an occasional emoji where it fits.",
+	"Use emoji freely to add warmth.",
+	"Use emoji generously throughout replies.",
+}
+
+var formalityGuidance = [MaxLevel + 1]string{
+	"",
+	"Write casually, like chatting with a friend.",
+	"Keep a relaxed, conversational register.",
+	"Use a balanced, everyday register.",
+	"Write in a polished, courteous register.",
+	"Write formally, as in business correspondence.",
+}
+
+// Persona is a preset plus optional slider overrides. Zero values inherit
+// from the preset.
+type Persona struct {
+	Preset    string `json:"preset,omitempty"`
+	Tone      string `json:"tone,omitempty"`
+	Verbosity int    `json:"verbosity,omitempty"`
+	Emoji     int    `json:"emoji,omitempty"`
+	Formality int    `json:"formality,omitempty"`
+}
+
+// Preset is a named persona from the built-in library.
+type Preset struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Tone        string `json:"tone"`
+	Verbosity   int    `json:"verbosity"`
+	Emoji       int    `json:"emoji"`
+	Formality   int    `json:"formality"`
+}
+
+var presets = []Preset{
+	{ID: "friendly", Name: "Friendly", Description: "Warm and approachable, for community and personal bots", Tone: ToneWarm, Verbosity: 3, Emoji: 3, Formality: 2},
+	{ID: "professional", Name: "Professional", Description: "Polished and precise, for customer-facing and workplace bots", Tone: ToneProfessional, Verbosity: 3, Emoji: 1, Formality: 4},
+	{ID: "concise", Name: "Concise", Description: "Short, direct answers with no filler", Tone: ToneDirect, Verbosity: 1, Emoji: 1, Formality: 3},
+	{ID: "playful", Name: "Playful", Description: "Fun and expressive, for casual group chats", Tone: TonePlayful, Verbosity: 3, Emoji: 4, Formality: 1},
+	{ID: "mentor", Name: "Mentor", Description: "Patient and explanatory, for teaching and onboarding", Tone: ToneWarm, Verbosity: 4, Emoji: 2, Formality: 3},
+}
+
+// Presets returns the built-in persona presets.
+func Presets() []Preset {
+	out := make([]Preset, len(presets))
+	copy(out, presets)
+	return out
+}
+
+// LookupPreset returns the preset with the given id.
+func LookupPreset(id string) (Preset, bool) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	for _, p := range presets {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// Validate reports the first unknown preset, tone or out-of-range slider.
+func Validate(p Persona) error {
+	if preset := strings.TrimSpace(p.Preset); preset != "" {
+		if _, ok := LookupPreset(preset); !ok {
+			return fmt.Errorf("unknown persona preset %q", preset)
+		}
+	}
+	if tone := strings.TrimSpace(p.Tone); tone != "" {
+		if _, ok := toneGuidance[strings.ToLower(tone)]; !ok {
+			return fmt.Errorf("unknown persona tone %q", tone)
+		}
+	}
+	for name, level := range map[string]int{"verbosity": p.Verbosity, "emoji": p.Emoji, "formality": p.Formality} {
+		if level < 0 || level > MaxLevel {
+			return fmt.Errorf("persona %s must be between 0 and %d", name, MaxLevel)
+		}
+	}
+	return nil
+}
+
+// Normalize lowercases names and drops unknown presets, unknown tones and
+// out-of-range sliders.
+func Normalize(p Persona) Persona {
+	out := Persona{}
+	if preset, ok := LookupPreset(p.Preset); ok {
+		out.Preset = preset.ID
+	}
+	if tone := strings.ToLower(strings.TrimSpace(p.Tone)); toneGuidance[tone] != "" {
+		out.Tone = tone
+	}
+	out.Verbosity = normalizeLevel(p.Verbosity)
+	out.Emoji = normalizeLevel(p.Emoji)
+	out.Formality = normalizeLevel(p.Formality)
+	return out
+}
+
+func normalizeLevel(level int) int {
+	if level < 0 || level > MaxLevel {
+		return 0
+	}
+	return level
+}
+
+// IsZero reports whether p sets nothing.
+func (p Persona) IsZero() bool {
+	return p == Persona{}
+}
+
+// Override layers the fields set in override on top of base.
+func Override(base, override Persona) Persona {
+	if override.Preset != "" {
+		base.Preset = override.Preset
+	}
+	if override.Tone != "" {
+		base.Tone = override.Tone
+	}
+	if override.Verbosity != 0 {
+		base.Verbosity = override.Verbosity
+	}
+	if override.Emoji != 0 {
+		base.Emoji = override.Emoji
+	}
+	if override.Formality != 0 {
+		base.Formality = override.Formality
+	}
+	return base
+}
+
+// Effective fills the sliders p leaves unset from its preset.
+func Effective(p Persona) Persona {
+	p = Normalize(p)
+	preset, ok := LookupPreset(p.Preset)
+	if !ok {
+		return p
+	}
+	return Override(Persona{
+		Preset:    preset.ID,
+		Tone:      preset.Tone,
+		Verbosity: preset.Verbosity,
+		Emoji:     preset.Emoji,
+		Formality: preset.Formality,
+	}, p)
+}
+
+// FromMetadata reads a persona override stored under RouteMetadataKey.
+func FromMetadata(metadata map[string]any) Persona {
+	raw, ok := metadata[RouteMetadataKey].(map[string]any)
+	if !ok {
+		return Persona{}
+	}
+	p := Persona{}
+	p.Preset, _ = raw["preset"].(string)
+	p.Tone, _ = raw["tone"].(string)
+	p.Verbosity = metadataInt(raw["verbosity"])
+	p.Emoji = metadataInt(raw["emoji"])
+	p.Formality = metadataInt(raw["formality"])
+	return Normalize(p)
+}
+
+// ToMetadata encodes p for storage under RouteMetadataKey.
+func ToMetadata(p Persona) map[string]any {
+	p = Normalize(p)
+	out := map[string]any{}
+	if p.Preset != "" {
+		out["preset"] = p.Preset
+	}
+	if p.Tone != "" {
+		out["tone"] = p.Tone
+	}
+	if p.Verbosity != 0 {
+		out["verbosity"] = p.Verbosity
+	}
+	if p.Emoji != 0 {
+		out["emoji"] = p.Emoji
+	}
+	if p.Formality != 0 {
+		out["formality"] = p.Formality
+	}
+	return out
+}
+
+func metadataInt(value any) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// Fragment renders the effective persona as a system prompt section, or ""
+// when the persona sets nothing.
+func Fragment(p Persona) string {
+	p = Effective(p)
+	var lines []string
+	if guidance := toneGuidance[p.Tone]; guidance != "" {
+		lines = append(lines, "- Tone: "+guidance+".")
+	}
+	for _, guidance := range []string{
+		verbosityGuidance[p.Verbosity],
+		emojiGuidance[p.Emoji],
+		formalityGuidance[p.Formality],
+	} {
+		if guidance != "" {
+			lines = append(lines, "- "+guidance)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "## Persona\n\nThe bot owner chose this communication style. Follow it unless the user explicitly asks for something different; it never overrides safety or factual accuracy.\n\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,64 @@
+package persona
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFragmentEmptyPersona(t *testing.T) {
+	if got := Fragment(Persona{}); got != "" {
+		t.Fatalf("Fragment(zero) = %q, want empty", got)
+	}
+}
+
+func TestFragmentUsesPresetAndSliderOverrides(t *testing.T) {
+	got := Fragment(Persona{Preset: "professional", Emoji: 4})
+	for _, want := range []string{
+		"## Persona",
+		"Tone: professional and composed.",
+		"Match reply length to the question.",
+		"Use emoji freely to add warmth.",
+		"Write in a polished, courteous register.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("Fragment missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Use emoji rarely") {
+		t.Fatalf("slider override did not replace preset emoji level:\n%s", got)
+	}
+}
+
+func TestOverrideLayersRouteFields(t *testing.T) {
+	got := Override(Persona{Preset: "friendly", Verbosity: 4}, Persona{Preset: "concise", Formality: 5})
+	want := Persona{Preset: "concise", Verbosity: 4, Formality: 5}
+	if got != want {
+		t.Fatalf("Override = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateRejectsUnknownValues(t *testing.T) {
+	for _, p := range []Persona{
+		{Preset: "pirate"},
+		{Tone: "sarcastic"},
+		{Verbosity: MaxLevel + 1},
+		{Emoji: -1},
+	} {
+		if err := Validate(p); err == nil {
+			t.Fatalf("Validate(%+v) = nil, want error", p)
+		}
+	}
+	if err := Validate(Persona{Preset: "Mentor", Tone: "Warm", Formality: 2}); err != nil {
+		t.Fatalf("Validate(valid) = %v", err)
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	in := Persona{Preset: "playful", Tone: ToneDirect, Emoji: 2}
+	metadata := map[string]any{RouteMetadataKey: ToMetadata(in)}
+	// Route metadata comes back from JSONB with float64 numbers.
+	metadata[RouteMetadataKey].(map[string]any)["emoji"] = float64(2)
+	if got := FromMetadata(metadata); got != in {
+		t.Fatalf("FromMetadata = %+v, want %+v", got, in)
+	}
+}
@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/memohai/memoh/internal/accounts"
+)
+
+// Permission is a workspace-wide capability granted by an account role.
+// Access to a single bot is still governed by ownership and bot grants.
+type Permission string
+
+const (
+	// PermissionUsersView lists workspace members and reads their profiles.
+	PermissionUsersView Permission = "users.view"
+	// PermissionUsersManage creates, updates and deactivates members, assigns
+	// roles and revokes sessions.
+	PermissionUsersManage Permission = "users.manage"
+	// PermissionBotsCreate creates bots owned by the caller.
+	PermissionBotsCreate Permission = "bots.create"
+	// PermissionBotsManageAll gives owner-level access to every bot, including
+	// creating bots for and transferring bots to other members.
+	PermissionBotsManageAll Permission = "bots.manage_all"
+	// PermissionOperationsManage manages feature flags, maintenance mode and
+	// inbound failures.
+	PermissionOperationsManage Permission = "operations.manage"
+	// PermissionDataManage exports and erases other people's data.
+	PermissionDataManage Permission = "data.manage"
+	// PermissionAuditView reads the audit log of administrative actions.
+	PermissionAuditView Permission = "audit.view"
+)
+
+// ErrPermissionDenied is returned by Require when the role lacks a permission.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// AllPermissions returns every permission, in display order.
+func AllPermissions() []Permission {
+	return []Permission{
+		PermissionUsersView,
+		PermissionUsersManage,
+		PermissionBotsCreate,
+		PermissionBotsManageAll,
+		PermissionOperationsManage,
+		PermissionDataManage,
+		PermissionAuditView,
+	}
+}
+
+var rolePermissions = map[string][]Permission{
+	accounts.RoleAdmin: AllPermissions(),
+	accounts.RoleOperator: {
+		PermissionUsersView,
+		PermissionBotsCreate,
+		PermissionOperationsManage,
+	},
+	accounts.RoleBotManager: {
+		PermissionUsersView,
+		PermissionBotsCreate,
+		PermissionBotsManageAll,
+	},
+	accounts.RoleMember: {
+		PermissionBotsCreate,
+	},
+	// Viewers only use bots that were shared with them.
+	accounts.RoleViewer: nil,
+}
+
+// Permissions returns the permissions granted by role. Unknown roles get none.
+func Permissions(role string) []Permission {
+	granted := rolePermissions[strings.ToLower(strings.TrimSpace(role))]
+	return append([]Permission(nil), granted...)
+}
+
+// RoleAllows reports whether role grants perm.
+func RoleAllows(role string, perm Permission) bool {
+	for _, granted := range rolePermissions[strings.ToLower(strings.TrimSpace(role))] {
+		if granted == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleInfo describes a role and the permissions it grants.
+type RoleInfo struct {
+	Role        string       `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// ListRolesResponse wraps the role table.
+type ListRolesResponse struct {
+	Items []RoleInfo `json:"items"`
+}
+
+// RoleInfos returns every assignable role with its permissions.
+func RoleInfos() []RoleInfo {
+	roles := accounts.Roles()
+	items := make([]RoleInfo, 0, len(roles))
+	for _, role := range roles {
+		perms := Permissions(role)
+		if perms == nil {
+			perms = []Permission{}
+		}
+		items = append(items, RoleInfo{Role: role, Permissions: perms})
+	}
+	return items
+}
+
+// RoleSource resolves a user's workspace role. Implemented by accounts.Service.
+type RoleSource interface {
+	Role(ctx context.Context, userID string) (string, error)
+}
+
+// Allowed reports whether the user's role grants perm. Inactive members and
+// users without a membership are never allowed.
+func Allowed(ctx context.Context, roles RoleSource, userID string, perm Permission) (bool, error) {
+	if roles == nil {
+		return false, errors.New("role source not configured")
+	}
+	role, err := roles.Role(ctx, strings.TrimSpace(userID))
+	if err != nil {
+		return false, err
+	}
+	return RoleAllows(role, perm), nil
+}
+
+// Require is like Allowed but returns ErrPermissionDenied when not allowed.
+func Require(ctx context.Context, roles RoleSource, userID string, perm Permission) error {
+	ok, err := Allowed(ctx, roles, userID, perm)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPermissionDenied
+	}
+	return nil
+}
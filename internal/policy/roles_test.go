@@ -0,0 +1,75 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/memohai/memoh/internal/accounts"
+)
+
+type staticRoles map[string]string
+
+func (r staticRoles) Role(_ context.Context, userID string) (string, error) {
+	return r[userID], nil
+}
+
+func TestRoleAllows(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		role    string
+		perm    Permission
+		allowed bool
+	}{
+		{accounts.RoleAdmin, PermissionDataManage, true},
+		{accounts.RoleAdmin, PermissionUsersManage, true},
+		{accounts.RoleAdmin, PermissionAuditView, true},
+		{accounts.RoleOperator, PermissionAuditView, false},
+		{accounts.RoleOperator, PermissionOperationsManage, true},
+		{accounts.RoleOperator, PermissionUsersView, true},
+		{accounts.RoleOperator, PermissionUsersManage, false},
+		{accounts.RoleOperator, PermissionBotsManageAll, false},
+		{accounts.RoleBotManager, PermissionBotsManageAll, true},
+		{accounts.RoleBotManager, PermissionOperationsManage, false},
+		{accounts.RoleMember, PermissionBotsCreate, true},
+		{accounts.RoleMember, PermissionUsersView, false},
+		{accounts.RoleViewer, PermissionBotsCreate, false},
+		{"", PermissionBotsCreate, false},
+		{"owner", PermissionBotsCreate, false},
+	}
+	for _, tc := range cases {
+		if got := RoleAllows(tc.role, tc.perm); got != tc.allowed {
+			t.Errorf("RoleAllows(%q, %q) = %v, want %v", tc.role, tc.perm, got, tc.allowed)
+		}
+	}
+}
+
+func TestEveryRoleHasAnEntry(t *testing.T) {
+	t.Parallel()
+
+	for _, role := range accounts.Roles() {
+		if _, ok := rolePermissions[role]; !ok {
+			t.Errorf("role %q has no permission entry", role)
+		}
+	}
+	if got := Permissions(accounts.RoleAdmin); len(got) != len(AllPermissions()) {
+		t.Fatalf("admin permissions = %v, want all", got)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	t.Parallel()
+
+	roles := staticRoles{"op": accounts.RoleOperator}
+	ctx := context.Background()
+	if err := Require(ctx, roles, "op", PermissionOperationsManage); err != nil {
+		t.Fatalf("Require operator: %v", err)
+	}
+	if err := Require(ctx, roles, "op", PermissionDataManage); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("Require error = %v, want ErrPermissionDenied", err)
+	}
+	if err := Require(ctx, roles, "stranger", PermissionBotsCreate); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("Require without membership = %v, want ErrPermissionDenied", err)
+	}
+}
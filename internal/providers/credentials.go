@@ -12,6 +12,7 @@ import (
 	memohcopilot "github.com/memohai/memoh/internal/copilot"
 	"github.com/memohai/memoh/internal/db/postgres/sqlc"
 	"github.com/memohai/memoh/internal/models"
+	"github.com/memohai/memoh/internal/redact"
 )
 
 const openAIAuthClaimPath = "https://api.openai.com/auth"
@@ -31,7 +32,18 @@ type OpenAICodexOAuthCredentials struct {
 	LastRefresh  time.Time
 }
 
+// ResolveModelCredentials returns the credentials for calling a provider's
+// models. The API key is registered for redaction so upstream errors that
+// echo it are masked in logs and stream errors.
 func (s *Service) ResolveModelCredentials(ctx context.Context, provider sqlc.Provider) (ModelCredentials, error) {
+	creds, err := s.resolveModelCredentials(ctx, provider)
+	if err == nil {
+		redact.SetSecrets("provider:"+provider.ID.String(), creds.APIKey)
+	}
+	return creds, err
+}
+
+func (s *Service) resolveModelCredentials(ctx context.Context, provider sqlc.Provider) (ModelCredentials, error) {
 	switch models.ClientType(provider.ClientType) {
 	case models.ClientTypeGitHubCopilot:
 		githubToken, err := s.GetValidAccessToken(ctx, provider.ID.String())
@@ -29,6 +29,9 @@ type Service struct {
 	httpClient   *http.Client
 	callbackURL  string
 	templatesDir string
+	// checkEndpoint, when set, rejects provider base URLs that violate the
+	// deployment's endpoint policy (offline mode).
+	checkEndpoint func(string) error
 }
 
 // NewService creates a new provider service.
@@ -49,6 +52,48 @@ func NewService(log *slog.Logger, queries dbstore.Queries, callbackURL string, t
 	}
 }
 
+// RestrictEndpoints installs an endpoint policy applied to every provider
+// create and update. Offline deployments use it to keep traffic local.
+func (s *Service) RestrictEndpoints(check func(string) error) {
+	s.checkEndpoint = check
+}
+
+// ValidateEndpoints checks every enabled provider against check and returns
+// the first violation. Providers without a base URL use their vendor's
+// public endpoint and therefore fail any restrictive policy.
+func (s *Service) ValidateEndpoints(ctx context.Context, check func(string) error) error {
+	lists := []func(context.Context) ([]sqlc.Provider, error){
+		s.queries.ListProviders,
+		s.queries.ListSpeechProviders,
+		s.queries.ListTranscriptionProviders,
+		s.queries.ListVideoProviders,
+	}
+	for _, list := range lists {
+		rows, err := list(ctx)
+		if err != nil {
+			return fmt.Errorf("list providers: %w", err)
+		}
+		for _, provider := range rows {
+			if !provider.Enable {
+				continue
+			}
+			if err := check(configString(providerConfig(provider.Config), "base_url")); err != nil {
+				return fmt.Errorf("provider %q: %w", provider.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// enforceEndpoint applies the installed endpoint policy to an enabled
+// provider's config. Disabled providers never send traffic and are exempt.
+func (s *Service) enforceEndpoint(enabled bool, cfg map[string]any) error {
+	if s.checkEndpoint == nil || !enabled {
+		return nil
+	}
+	return s.checkEndpoint(configString(cfg, "base_url"))
+}
+
 // Create creates a new provider.
 func (s *Service) Create(ctx context.Context, req CreateRequest) (GetResponse, error) {
 	metadataJSON, err := json.Marshal(req.Metadata)
@@ -60,7 +105,11 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (GetResponse, e
 	if clientType == "" {
 		clientType = string(models.ClientTypeOpenAICompletions)
 	}
-	configJSON, err := json.Marshal(normalizeProviderConfig(clientType, req.Config))
+	config := normalizeProviderConfig(clientType, req.Config)
+	if err := s.enforceEndpoint(true, config); err != nil {
+		return GetResponse{}, err
+	}
+	configJSON, err := json.Marshal(config)
 	if err != nil {
 		return GetResponse{}, fmt.Errorf("marshal config: %w", err)
 	}
@@ -112,8 +161,11 @@ func (s *Service) CreateFromTemplate(ctx context.Context, req CreateFromTemplate
 	if name == "" {
 		name = template.Name
 	}
-	config := providertemplates.MergeConfig(providertemplates.DecodeConfig(template.DefaultConfig), req.Config)
-	configJSON, err := providertemplates.Marshal(normalizeProviderConfig(template.Driver, config))
+	config := normalizeProviderConfig(template.Driver, providertemplates.MergeConfig(providertemplates.DecodeConfig(template.DefaultConfig), req.Config))
+	if err := s.enforceEndpoint(true, config); err != nil {
+		return GetResponse{}, err
+	}
+	configJSON, err := providertemplates.Marshal(config)
 	if err != nil {
 		return GetResponse{}, apperror.Wrap(apperror.CodeProviderTemplateOperationFailed, err, nil)
 	}
@@ -218,6 +270,9 @@ func (s *Service) Update(ctx context.Context, id string, req UpdateRequest) (Get
 	} else {
 		existingConfig = normalizeProviderConfig(clientType, existingConfig)
 	}
+	if err := s.enforceEndpoint(enable, existingConfig); err != nil {
+		return GetResponse{}, err
+	}
 	configJSON, err := json.Marshal(existingConfig)
 	if err != nil {
 		return GetResponse{}, fmt.Errorf("marshal config: %w", err)
@@ -0,0 +1,153 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+
+	sendTimeout   = 15 * time.Second
+	maxErrorBytes = 4 << 10
+	// apnsTokenLifetime renews the provider token well inside the hour Apple
+	// accepts it for, and no more often than Apple allows.
+	apnsTokenLifetime = 45 * time.Minute
+)
+
+// apnsClient sends alerts through APNs with token-based authentication.
+// Go's HTTP client speaks HTTP/2 to it as APNs requires.
+type apnsClient struct {
+	baseURL string
+	keyID   string
+	teamID  string
+	topic   string
+	key     *ecdsa.PrivateKey
+	http    *http.Client
+	now     func() time.Time
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+func newAPNsClient(cfg config.PushConfig) (*apnsClient, error) {
+	raw, err := os.ReadFile(cfg.APNsKeyPath) //nolint:gosec // key path comes from server config.
+	if err != nil {
+		return nil, fmt.Errorf("read apns key: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse apns key: %w", err)
+	}
+	baseURL := apnsProductionURL
+	if cfg.APNsSandbox {
+		baseURL = apnsSandboxURL
+	}
+	return &apnsClient{
+		baseURL: baseURL,
+		keyID:   strings.TrimSpace(cfg.APNsKeyID),
+		teamID:  strings.TrimSpace(cfg.APNsTeamID),
+		topic:   strings.TrimSpace(cfg.APNsTopic),
+		key:     key,
+		http:    &http.Client{Timeout: sendTimeout},
+		now:     time.Now,
+	}, nil
+}
+
+func (c *apnsClient) providerToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.token != "" && now.Sub(c.issuedAt) < apnsTokenLifetime {
+		return c.token, nil
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": c.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = c.keyID
+	signed, err := token.SignedString(c.key)
+	if err != nil {
+		return "", fmt.Errorf("sign apns token: %w", err)
+	}
+	c.token = signed
+	c.issuedAt = now
+	return signed, nil
+}
+
+type apnsAlert struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body"`
+}
+
+type apnsAps struct {
+	Alert apnsAlert `json:"alert"`
+	Sound string    `json:"sound"`
+}
+
+func (c *apnsClient) send(ctx context.Context, deviceToken string, n Notification) error {
+	providerToken, err := c.providerToken()
+	if err != nil {
+		return err
+	}
+	payload := map[string]any{}
+	for key, value := range n.Data {
+		payload[key] = value
+	}
+	payload["aps"] = apnsAps{Alert: apnsAlert{Title: n.Title, Body: n.Body}, Sound: "default"}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/3/device/"+url.PathEscape(deviceToken), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apns-topic", c.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	var apiErr struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBytes)).Decode(&apiErr)
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		apiErr.Reason == "BadDeviceToken",
+		apiErr.Reason == "Unregistered",
+		apiErr.Reason == "DeviceTokenNotForTopic":
+		return fmt.Errorf("%w: apns %s", errTokenUnregistered, apiErr.Reason)
+	}
+	if resp.StatusCode == http.StatusForbidden && apiErr.Reason == "ExpiredProviderToken" {
+		c.mu.Lock()
+		c.token = ""
+		c.mu.Unlock()
+	}
+	return fmt.Errorf("apns: status %d: %s", resp.StatusCode, apiErr.Reason)
+}
@@ -0,0 +1,132 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	fcmBaseURL       = "https://fcm.googleapis.com"
+	fcmScope         = "https://www.googleapis.com/auth/firebase.messaging"
+	defaultTokenURL  = "https://oauth2.googleapis.com/token"
+	fcmUnregistered  = "UNREGISTERED"
+	fcmErrorTypeName = "type.googleapis.com/google.firebase.fcm.v1.FcmError"
+)
+
+// serviceAccount is the part of a Firebase service account key the client
+// needs.
+type serviceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// fcmClient sends notifications through the FCM HTTP v1 API, authorized as
+// the service account.
+type fcmClient struct {
+	baseURL   string
+	projectID string
+	http      *http.Client
+}
+
+func newFCMClient(credentialsPath string) (*fcmClient, error) {
+	raw, err := os.ReadFile(credentialsPath) //nolint:gosec // credentials path comes from server config.
+	if err != nil {
+		return nil, fmt.Errorf("read fcm credentials: %w", err)
+	}
+	var account serviceAccount
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return nil, fmt.Errorf("parse fcm credentials: %w", err)
+	}
+	if account.Type != "service_account" || account.ProjectID == "" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("fcm credentials must be a service account key with project_id, client_email and private_key")
+	}
+	tokenURL := account.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+	jwtConfig := &jwt.Config{
+		Email:        account.ClientEmail,
+		PrivateKey:   []byte(account.PrivateKey),
+		PrivateKeyID: account.PrivateKeyID,
+		Scopes:       []string{fcmScope},
+		TokenURL:     tokenURL,
+	}
+	client := oauth2.NewClient(context.Background(), jwtConfig.TokenSource(context.Background()))
+	client.Timeout = sendTimeout
+	return &fcmClient{baseURL: fcmBaseURL, projectID: account.ProjectID, http: client}, nil
+}
+
+type fcmNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			Type      string `json:"@type"`
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func (c *fcmClient) send(ctx context.Context, deviceToken string, n Notification) error {
+	body, err := json.Marshal(map[string]fcmMessage{"message": {
+		Token:        deviceToken,
+		Notification: fcmNotification{Title: n.Title, Body: n.Body},
+		Data:         n.Data,
+	}})
+	if err != nil {
+		return err
+	}
+	endpoint := c.baseURL + "/v1/projects/" + url.PathEscape(c.projectID) + "/messages:send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	var apiErr fcmErrorResponse
+	_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBytes)).Decode(&apiErr)
+	for _, detail := range apiErr.Error.Details {
+		if detail.Type == fcmErrorTypeName && detail.ErrorCode == fcmUnregistered {
+			return fmt.Errorf("%w: fcm %s", errTokenUnregistered, detail.ErrorCode)
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: fcm %s", errTokenUnregistered, apiErr.Error.Status)
+	}
+	return fmt.Errorf("fcm: status %d: %s", resp.StatusCode, strings.TrimSpace(apiErr.Error.Message))
+}
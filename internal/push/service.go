@@ -0,0 +1,325 @@
+// Package push delivers bot messages to the companion mobile app through
+// Apple Push Notification service and Firebase Cloud Messaging. Devices are
+// registered per bot and user, and each can be muted on its own.
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/config"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+	"github.com/memohai/memoh/internal/textutil"
+)
+
+const (
+	maxTokenLength = 4096
+	maxDeviceName  = 100
+	// maxBodyRunes keeps notifications under the 4 KB payload limit of both
+	// services; the app opens the full message from the chat.
+	maxBodyRunes = 1000
+)
+
+var (
+	ErrNotConfigured     = errors.New("push notifications are not configured")
+	ErrInvalidPlatform   = errors.New("invalid push platform")
+	ErrInvalidToken      = errors.New("invalid push token")
+	ErrInvalidName       = errors.New("invalid device name")
+	ErrInvalidMute       = errors.New("invalid mute setting")
+	ErrDeviceNotFound    = errors.New("push device not found")
+	ErrNoDevices         = errors.New("no unmuted push devices registered")
+	errTokenUnregistered = errors.New("push token is no longer registered")
+)
+
+type pushQueries interface {
+	DeletePushDevice(ctx context.Context, arg sqlc.DeletePushDeviceParams) (int64, error)
+	DeletePushDevicesByToken(ctx context.Context, arg sqlc.DeletePushDevicesByTokenParams) error
+	ListActivePushDevices(ctx context.Context, arg sqlc.ListActivePushDevicesParams) ([]sqlc.PushDevice, error)
+	ListPushDevices(ctx context.Context, arg sqlc.ListPushDevicesParams) ([]sqlc.PushDevice, error)
+	TouchPushDevice(ctx context.Context, id pgtype.UUID) error
+	UpdatePushDeviceMute(ctx context.Context, arg sqlc.UpdatePushDeviceMuteParams) (sqlc.PushDevice, error)
+	UpsertPushDevice(ctx context.Context, arg sqlc.UpsertPushDeviceParams) (sqlc.PushDevice, error)
+}
+
+// sender delivers a notification to one device token. It returns
+// errTokenUnregistered when the token will never work again.
+type sender interface {
+	send(ctx context.Context, token string, n Notification) error
+}
+
+// Service manages push devices and sends notifications to them.
+type Service struct {
+	queries dbstore.Queries
+	senders map[string]sender
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewService creates the push service with the platforms configured in cfg.
+// Without credentials for a platform its devices cannot be registered.
+func NewService(log *slog.Logger, queries dbstore.Queries, cfg config.PushConfig) (*Service, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+	s := &Service{
+		queries: queries,
+		senders: map[string]sender{},
+		logger:  log.With(slog.String("service", "push")),
+		now:     time.Now,
+	}
+	if cfg.APNsEnabled() {
+		client, err := newAPNsClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		s.senders[PlatformAPNs] = client
+	}
+	if cfg.FCMEnabled() {
+		client, err := newFCMClient(cfg.FCMCredentialsPath)
+		if err != nil {
+			return nil, err
+		}
+		s.senders[PlatformFCM] = client
+	}
+	return s, nil
+}
+
+// Enabled reports whether any push platform is configured.
+func (s *Service) Enabled() bool {
+	return s != nil && len(s.senders) > 0
+}
+
+func (s *Service) store() (pushQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("push service not configured")
+	}
+	store, ok := s.queries.(pushQueries)
+	if !ok {
+		return nil, errors.New("push queries not supported by store")
+	}
+	return store, nil
+}
+
+// RegisterDevice registers a device token to receive the bot's messages for
+// the user.
+func (s *Service) RegisterDevice(ctx context.Context, botID, userID string, req RegisterDeviceRequest) (Device, error) {
+	store, err := s.store()
+	if err != nil {
+		return Device{}, err
+	}
+	platform := strings.ToLower(strings.TrimSpace(req.Platform))
+	if platform != PlatformAPNs && platform != PlatformFCM {
+		return Device{}, fmt.Errorf("%w: must be %q or %q", ErrInvalidPlatform, PlatformAPNs, PlatformFCM)
+	}
+	if _, ok := s.senders[platform]; !ok {
+		return Device{}, fmt.Errorf("%w: %s", ErrNotConfigured, platform)
+	}
+	token := strings.TrimSpace(req.Token)
+	if token == "" || len(token) > maxTokenLength {
+		return Device{}, ErrInvalidToken
+	}
+	name := strings.TrimSpace(req.Name)
+	if utf8.RuneCountInString(name) > maxDeviceName {
+		return Device{}, fmt.Errorf("%w: must be at most %d characters", ErrInvalidName, maxDeviceName)
+	}
+	pgBotID, pgUserID, err := parseOwner(botID, userID)
+	if err != nil {
+		return Device{}, err
+	}
+	row, err := store.UpsertPushDevice(ctx, sqlc.UpsertPushDeviceParams{
+		BotID:    pgBotID,
+		UserID:   pgUserID,
+		Platform: platform,
+		Token:    token,
+		Name:     name,
+	})
+	if err != nil {
+		return Device{}, err
+	}
+	return toDevice(row), nil
+}
+
+// ListDevices returns the user's devices registered for the bot.
+func (s *Service) ListDevices(ctx context.Context, botID, userID string) ([]Device, error) {
+	store, err := s.store()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, pgUserID, err := parseOwner(botID, userID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListPushDevices(ctx, sqlc.ListPushDevicesParams{BotID: pgBotID, UserID: pgUserID})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Device, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toDevice(row))
+	}
+	return items, nil
+}
+
+// UpdateDevice mutes or unmutes one of the user's devices.
+func (s *Service) UpdateDevice(ctx context.Context, botID, userID, deviceID string, req UpdateDeviceRequest) (Device, error) {
+	store, err := s.store()
+	if err != nil {
+		return Device{}, err
+	}
+	pgBotID, pgUserID, err := parseOwner(botID, userID)
+	if err != nil {
+		return Device{}, err
+	}
+	pgID, err := db.ParseUUID(deviceID)
+	if err != nil {
+		return Device{}, ErrDeviceNotFound
+	}
+	var mutedUntil pgtype.Timestamptz
+	if req.MutedUntil != nil {
+		if !req.MutedUntil.After(s.now()) {
+			return Device{}, fmt.Errorf("%w: muted_until must be in the future", ErrInvalidMute)
+		}
+		mutedUntil = pgtype.Timestamptz{Time: req.MutedUntil.UTC(), Valid: true}
+	}
+	row, err := store.UpdatePushDeviceMute(ctx, sqlc.UpdatePushDeviceMuteParams{
+		Muted:      req.Muted,
+		MutedUntil: mutedUntil,
+		ID:         pgID,
+		BotID:      pgBotID,
+		UserID:     pgUserID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Device{}, ErrDeviceNotFound
+	}
+	if err != nil {
+		return Device{}, err
+	}
+	return toDevice(row), nil
+}
+
+// DeleteDevice unregisters one of the user's devices.
+func (s *Service) DeleteDevice(ctx context.Context, botID, userID, deviceID string) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	pgBotID, pgUserID, err := parseOwner(botID, userID)
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(deviceID)
+	if err != nil {
+		return ErrDeviceNotFound
+	}
+	affected, err := store.DeletePushDevice(ctx, sqlc.DeletePushDeviceParams{ID: pgID, BotID: pgBotID, UserID: pgUserID})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// Notify sends a notification to the user's unmuted devices for the bot.
+// Tokens the platform reports as unregistered are removed. It fails only
+// when no device received the notification.
+func (s *Service) Notify(ctx context.Context, botID, userID string, n Notification) error {
+	store, err := s.store()
+	if err != nil {
+		return err
+	}
+	if !s.Enabled() {
+		return ErrNotConfigured
+	}
+	pgBotID, pgUserID, err := parseOwner(botID, userID)
+	if err != nil {
+		return err
+	}
+	devices, err := store.ListActivePushDevices(ctx, sqlc.ListActivePushDevicesParams{BotID: pgBotID, UserID: pgUserID})
+	if err != nil {
+		return err
+	}
+	n.Body = textutil.TruncateRunesWithSuffix(strings.TrimSpace(n.Body), maxBodyRunes, "…")
+	delivered := 0
+	var errs []error
+	for _, device := range devices {
+		client, ok := s.senders[device.Platform]
+		if !ok {
+			continue
+		}
+		err := client.send(ctx, device.Token, n)
+		switch {
+		case err == nil:
+			delivered++
+			if err := store.TouchPushDevice(ctx, device.ID); err != nil {
+				s.logger.Warn("record push delivery failed", slog.String("device_id", device.ID.String()), slog.Any("error", err))
+			}
+		case errors.Is(err, errTokenUnregistered):
+			s.logger.Info("removing unregistered push device",
+				slog.String("bot_id", botID),
+				slog.String("device_id", device.ID.String()),
+				slog.String("platform", device.Platform))
+			if err := store.DeletePushDevicesByToken(ctx, sqlc.DeletePushDevicesByTokenParams{Platform: device.Platform, Token: device.Token}); err != nil {
+				s.logger.Warn("remove push device failed", slog.String("device_id", device.ID.String()), slog.Any("error", err))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s device %s: %w", device.Platform, device.ID.String(), err))
+		}
+	}
+	if delivered > 0 {
+		for _, err := range errs {
+			s.logger.Warn("push delivery failed", slog.String("bot_id", botID), slog.Any("error", err))
+		}
+		return nil
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return ErrNoDevices
+}
+
+func parseOwner(botID, userID string) (pgtype.UUID, pgtype.UUID, error) {
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, fmt.Errorf("invalid bot id: %w", err)
+	}
+	pgUserID, err := db.ParseUUID(userID)
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, fmt.Errorf("invalid user id: %w", err)
+	}
+	return pgBotID, pgUserID, nil
+}
+
+func toDevice(row sqlc.PushDevice) Device {
+	return Device{
+		ID:         row.ID.String(),
+		BotID:      row.BotID.String(),
+		UserID:     row.UserID.String(),
+		Platform:   row.Platform,
+		Name:       row.Name,
+		Muted:      row.Muted,
+		MutedUntil: timePtr(row.MutedUntil),
+		LastUsedAt: timePtr(row.LastUsedAt),
+		CreatedAt:  row.CreatedAt.Time,
+		UpdatedAt:  row.UpdatedAt.Time,
+	}
+}
+
+func timePtr(value pgtype.Timestamptz) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time
+	return &t
+}
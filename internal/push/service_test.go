@@ -0,0 +1,280 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/config"
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	testBotID  = "33333333-3333-3333-3333-333333333333"
+	testUserID = "55555555-5555-5555-5555-555555555555"
+)
+
+type fakePushQueries struct {
+	dbstore.Queries
+
+	devices []sqlc.PushDevice
+	touched []pgtype.UUID
+	removed []string
+}
+
+func (f *fakePushQueries) ListActivePushDevices(_ context.Context, _ sqlc.ListActivePushDevicesParams) ([]sqlc.PushDevice, error) {
+	var out []sqlc.PushDevice
+	for _, device := range f.devices {
+		if !device.Muted {
+			out = append(out, device)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePushQueries) TouchPushDevice(_ context.Context, id pgtype.UUID) error {
+	f.touched = append(f.touched, id)
+	return nil
+}
+
+func (f *fakePushQueries) DeletePushDevicesByToken(_ context.Context, arg sqlc.DeletePushDevicesByTokenParams) error {
+	f.removed = append(f.removed, arg.Platform+":"+arg.Token)
+	return nil
+}
+
+func (*fakePushQueries) DeletePushDevice(context.Context, sqlc.DeletePushDeviceParams) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakePushQueries) ListPushDevices(context.Context, sqlc.ListPushDevicesParams) ([]sqlc.PushDevice, error) {
+	return f.devices, nil
+}
+
+func (*fakePushQueries) UpdatePushDeviceMute(context.Context, sqlc.UpdatePushDeviceMuteParams) (sqlc.PushDevice, error) {
+	return sqlc.PushDevice{}, nil
+}
+
+func (*fakePushQueries) UpsertPushDevice(_ context.Context, arg sqlc.UpsertPushDeviceParams) (sqlc.PushDevice, error) {
+	return sqlc.PushDevice{BotID: arg.BotID, UserID: arg.UserID, Platform: arg.Platform, Token: arg.Token, Name: arg.Name}, nil
+}
+
+type fakeSender struct {
+	errs map[string]error
+	sent []string
+}
+
+func (f *fakeSender) send(_ context.Context, token string, n Notification) error {
+	if err := f.errs[token]; err != nil {
+		return err
+	}
+	f.sent = append(f.sent, token+":"+n.Body)
+	return nil
+}
+
+func testDevice(id, platform, token string, muted bool) sqlc.PushDevice {
+	return sqlc.PushDevice{
+		ID:       db.ParseUUIDOrEmpty(id),
+		BotID:    db.ParseUUIDOrEmpty(testBotID),
+		UserID:   db.ParseUUIDOrEmpty(testUserID),
+		Platform: platform,
+		Token:    token,
+		Muted:    muted,
+	}
+}
+
+func TestNotifySkipsMutedAndRemovesUnregistered(t *testing.T) {
+	queries := &fakePushQueries{devices: []sqlc.PushDevice{
+		testDevice("10000000-0000-0000-0000-000000000001", PlatformAPNs, "phone", false),
+		testDevice("10000000-0000-0000-0000-000000000002", PlatformAPNs, "tablet", true),
+		testDevice("10000000-0000-0000-0000-000000000003", PlatformFCM, "stale", false),
+		testDevice("10000000-0000-0000-0000-000000000004", PlatformFCM, "flaky", false),
+	}}
+	apns := &fakeSender{}
+	fcm := &fakeSender{errs: map[string]error{
+		"stale": errTokenUnregistered,
+		"flaky": errors.New("fcm: status 503"),
+	}}
+	s := &Service{queries: queries, senders: map[string]sender{PlatformAPNs: apns, PlatformFCM: fcm}, logger: slog.Default()}
+
+	if err := s.Notify(context.Background(), testBotID, testUserID, Notification{Body: " hello "}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(apns.sent) != 1 || apns.sent[0] != "phone:hello" {
+		t.Fatalf("apns sent = %v", apns.sent)
+	}
+	if len(queries.touched) != 1 || queries.touched[0] != queries.devices[0].ID {
+		t.Fatalf("touched = %v", queries.touched)
+	}
+	if len(queries.removed) != 1 || queries.removed[0] != "fcm:stale" {
+		t.Fatalf("removed = %v", queries.removed)
+	}
+
+	queries.devices = queries.devices[3:]
+	if err := s.Notify(context.Background(), testBotID, testUserID, Notification{Body: "hello"}); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("Notify() with only failing devices error = %v", err)
+	}
+	queries.devices = nil
+	if err := s.Notify(context.Background(), testBotID, testUserID, Notification{Body: "hello"}); !errors.Is(err, ErrNoDevices) {
+		t.Fatalf("Notify() without devices error = %v, want ErrNoDevices", err)
+	}
+}
+
+func TestRegisterDeviceRequiresConfiguredPlatform(t *testing.T) {
+	s := &Service{queries: &fakePushQueries{}, senders: map[string]sender{PlatformFCM: &fakeSender{}}, logger: slog.Default()}
+	ctx := context.Background()
+	if _, err := s.RegisterDevice(ctx, testBotID, testUserID, RegisterDeviceRequest{Platform: "webpush", Token: "t"}); !errors.Is(err, ErrInvalidPlatform) {
+		t.Fatalf("unknown platform error = %v", err)
+	}
+	if _, err := s.RegisterDevice(ctx, testBotID, testUserID, RegisterDeviceRequest{Platform: "APNS", Token: "t"}); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("unconfigured platform error = %v", err)
+	}
+	if _, err := s.RegisterDevice(ctx, testBotID, testUserID, RegisterDeviceRequest{Platform: "fcm", Token: "  "}); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("empty token error = %v", err)
+	}
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAPNsClientSend(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "AuthKey.p8")
+	writePEM(t, keyPath, "PRIVATE KEY", der)
+
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/3/device/gone" {
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"reason":"Unregistered"}`))
+			return
+		}
+		if r.URL.Path != "/3/device/abc123" || r.Header.Get("apns-topic") != "ai.memoh.app" || r.Header.Get("apns-push-type") != "alert" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.Header)
+		}
+		raw := strings.TrimPrefix(r.Header.Get("Authorization"), "bearer ")
+		token, err := jwt.Parse(raw, func(*jwt.Token) (any, error) { return &key.PublicKey, nil }, jwt.WithValidMethods([]string{"ES256"}))
+		if err != nil || token.Header["kid"] != "KEY123" {
+			t.Errorf("provider token = %v, %v", token, err)
+		} else if iss, _ := token.Claims.GetIssuer(); iss != "TEAM123" {
+			t.Errorf("provider token issuer = %q", iss)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	client, err := newAPNsClient(config.PushConfig{
+		APNsKeyID:   "KEY123",
+		APNsTeamID:  "TEAM123",
+		APNsKeyPath: keyPath,
+		APNsTopic:   "ai.memoh.app",
+	})
+	if err != nil {
+		t.Fatalf("newAPNsClient() error = %v", err)
+	}
+	client.baseURL = server.URL
+	n := Notification{Title: "Bot", Body: "Reminder", Data: map[string]string{"bot_id": testBotID}}
+	if err := client.send(context.Background(), "abc123", n); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	aps, _ := payload["aps"].(map[string]any)
+	alert, _ := aps["alert"].(map[string]any)
+	if alert["title"] != "Bot" || alert["body"] != "Reminder" || payload["bot_id"] != testBotID {
+		t.Fatalf("payload = %v", payload)
+	}
+	if err := client.send(context.Background(), "gone", n); !errors.Is(err, errTokenUnregistered) {
+		t.Fatalf("send() to unregistered token error = %v", err)
+	}
+}
+
+func TestFCMClientSend(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var message struct {
+		Message fcmMessage `json:"message"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"access","token_type":"Bearer","expires_in":3600}`))
+		case "/v1/projects/memoh-test/messages:send":
+			if r.Header.Get("Authorization") != "Bearer access" {
+				t.Errorf("authorization = %q", r.Header.Get("Authorization"))
+			}
+			_ = json.NewDecoder(r.Body).Decode(&message)
+			if message.Message.Token == "gone" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"name":"projects/memoh-test/messages/1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	credentials, err := json.Marshal(serviceAccount{
+		Type:        "service_account",
+		ProjectID:   "memoh-test",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail: "push@memoh-test.iam.gserviceaccount.com",
+		TokenURI:    server.URL + "/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialsPath := filepath.Join(t.TempDir(), "fcm.json")
+	if err := os.WriteFile(credentialsPath, credentials, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := newFCMClient(credentialsPath)
+	if err != nil {
+		t.Fatalf("newFCMClient() error = %v", err)
+	}
+	client.baseURL = server.URL
+	if err := client.send(context.Background(), "device", Notification{Title: "Bot", Body: "Reminder"}); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if message.Message.Token != "device" || message.Message.Notification.Body != "Reminder" {
+		t.Fatalf("message = %+v", message.Message)
+	}
+	if err := client.send(context.Background(), "gone", Notification{Body: "Reminder"}); !errors.Is(err, errTokenUnregistered) {
+		t.Fatalf("send() to unregistered token error = %v", err)
+	}
+}
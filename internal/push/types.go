@@ -0,0 +1,47 @@
+package push
+
+import "time"
+
+// Platforms a device can register for.
+const (
+	PlatformAPNs = "apns"
+	PlatformFCM  = "fcm"
+)
+
+// Device is a companion app install that receives a bot's messages for one
+// user. The push token itself is never returned.
+type Device struct {
+	ID         string     `json:"id"`
+	BotID      string     `json:"bot_id"`
+	UserID     string     `json:"user_id"`
+	Platform   string     `json:"platform"`
+	Name       string     `json:"name"`
+	Muted      bool       `json:"muted"`
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// RegisterDeviceRequest registers a device token. Registering a token again
+// updates its name and owner.
+type RegisterDeviceRequest struct {
+	Platform string `json:"platform"`
+	Token    string `json:"token"`
+	Name     string `json:"name,omitempty"`
+}
+
+// UpdateDeviceRequest sets a device's mute state. Muted silences the device
+// until it is unmuted; MutedUntil silences it until that time.
+type UpdateDeviceRequest struct {
+	Muted      bool       `json:"muted"`
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+}
+
+// Notification is a message shown on the device. Data is delivered to the
+// app alongside it.
+type Notification struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
@@ -0,0 +1,50 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// credentialPatterns match credentials that were never registered, such as
+// provider API keys echoed in upstream error bodies. The first capture group
+// is the secret to mask; surrounding context (header or parameter names) is
+// kept so the text stays readable.
+var credentialPatterns = []*regexp.Regexp{
+	// Authorization headers and bare bearer tokens.
+	regexp.MustCompile(`(?i)\bauthorization"?\s*[:=]\s*"?(?:bearer\s+|basic\s+|token\s+)?([^\s"',;]{8,})`),
+	regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{8,})`),
+	// key=value and "key": "value" pairs with credential names.
+	regexp.MustCompile(`(?i)\b(?:api[_-]?key|x-api-key|access[_-]?token|refresh[_-]?token|id[_-]?token|client[_-]?secret|app[_-]?secret|secret|password|passwd|token)"?\s*[:=]\s*"?([^\s"'&,;]{8,})`),
+	// Well-known key formats.
+	regexp.MustCompile(`\b(sk-[A-Za-z0-9_-]{16,})`),
+	regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{30,})`),
+	regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,})`),
+	regexp.MustCompile(`\b(github_pat_[A-Za-z0-9_]{20,})`),
+	regexp.MustCompile(`\b(xox[abprs]-[A-Za-z0-9-]{10,})`),
+	regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20,})`),
+}
+
+// maskPatterns masks the credential group of every pattern match in text.
+func maskPatterns(text string) string {
+	for _, re := range credentialPatterns {
+		matches := re.FindAllStringSubmatchIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			start, end := m[2], m[3]
+			if start < 0 || start < last {
+				continue
+			}
+			b.WriteString(text[last:start])
+			b.WriteString(strings.Repeat("*", utf8.RuneCountInString(text[start:end])))
+			last = end
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+	return text
+}
@@ -26,9 +26,9 @@ var registry = struct {
 // or "telegram:<configID>". For multiple instances of the same adapter,
 // include a stable instance identifier in the key.
 //
-// Callers decide where redaction is appropriate. Logs and normal outbound
-// messages should retain their original text unless their own contract says
-// otherwise.
+// Registered secrets are masked in log output (see NewHandler), stream error
+// events and wherever else callers apply Text. Normal outbound messages
+// retain their original text unless their own contract says otherwise.
 func SetSecrets(key string, secrets ...string) {
 	var variants []string
 	for _, secret := range secrets {
@@ -52,7 +52,8 @@ func SetSecrets(key string, secrets ...string) {
 	registry.cache = rebuildSecretCache(registry.groups)
 }
 
-// Text masks registered secrets in text selected by the caller.
+// Text masks registered secrets and text shaped like credentials
+// (Authorization headers, api_key=... parameters, well-known key formats).
 func Text(text string) string {
 	if strings.TrimSpace(text) == "" {
 		return text
@@ -66,7 +67,7 @@ func Text(text string) string {
 	for _, secret := range cache {
 		result = strings.ReplaceAll(result, secret, strings.Repeat("*", utf8.RuneCountInString(secret)))
 	}
-	return result
+	return maskPatterns(result)
 }
 
 func variantsFor(secret string) []string {
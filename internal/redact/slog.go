@@ -0,0 +1,75 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Handler is a slog.Handler that masks secrets in record messages and
+// attribute values before passing records on.
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler wraps next so every record it receives is redacted with Text.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	out := slog.NewRecord(record.Time, record.Level, Text(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		out.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		redacted = append(redacted, redactAttr(attr))
+	}
+	return &Handler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr masks string values, recursing into groups. Errors and other
+// values are formatted and replaced by their masked text only when masking
+// changed something, so structured values stay structured.
+func redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Text(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]slog.Attr, 0, len(group))
+		for _, item := range group {
+			redacted = append(redacted, redactAttr(item))
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		var text string
+		switch v := value.Any().(type) {
+		case nil:
+			return slog.Attr{Key: attr.Key, Value: value}
+		case error:
+			text = v.Error()
+		default:
+			text = fmt.Sprintf("%+v", v)
+		}
+		if masked := Text(text); masked != text {
+			return slog.String(attr.Key, masked)
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
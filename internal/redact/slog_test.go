@@ -0,0 +1,35 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestHandler_MasksMessagesAndAttrs(t *testing.T) {
+	resetForTest()
+	t.Cleanup(resetForTest)
+
+	const secret = "registered-secret-value"
+	SetSecrets("test", secret)
+
+	var buf bytes.Buffer
+	log := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil))).With(slog.String("token", "token="+secret))
+	log.Warn("call failed with "+secret,
+		slog.Any("error", errors.New("401: Authorization: Bearer abcdefgh12345678")),
+		slog.Group("request", slog.String("url", "https://api.example.com/?api_key=abc123def456")),
+		slog.Int("status", 401),
+	)
+
+	out := buf.String()
+	for _, leaked := range []string{secret, "abcdefgh12345678", "abc123def456"} {
+		if strings.Contains(out, leaked) {
+			t.Fatalf("log output leaks %q: %s", leaked, out)
+		}
+	}
+	if !strings.Contains(out, `"status":401`) {
+		t.Fatalf("non-string attrs should be kept: %s", out)
+	}
+}
@@ -23,6 +23,7 @@ const (
 	MethodSetWebhook   = "channel.webhook.set"
 	MethodSend         = "channel.message.send"
 	MethodReact        = "channel.message.react"
+	MethodBroadcast    = "channel.message.broadcast"
 	MethodStatuses     = "channel.connection.statuses"
 	MethodRefreshEmail = "channel.email.refresh"
 	MethodSendEmail    = "channel.email.send"
@@ -47,6 +48,7 @@ type channelInput struct {
 	Webhook     channel.SetWebhookEndpointRequest
 	Send        channel.SendRequest
 	React       channel.ReactRequest
+	Broadcast   channel.BroadcastRequest
 }
 
 func (c *Client) UpsertBotChannelConfig(ctx context.Context, botID string, typ channel.ChannelType, req channel.UpsertConfigRequest) (channel.ChannelConfig, error) {
@@ -76,6 +78,11 @@ func (c *Client) React(ctx context.Context, botID string, typ channel.ChannelTyp
 	return c.call(ctx, MethodReact, channelInput{BotID: botID, ChannelType: typ, React: req}, nil)
 }
 
+func (c *Client) Broadcast(ctx context.Context, botID string, req channel.BroadcastRequest) (channel.BroadcastResult, error) {
+	var out channel.BroadcastResult
+	return out, c.call(ctx, MethodBroadcast, channelInput{BotID: botID, Broadcast: req}, &out)
+}
+
 func (c *Client) ConnectionStatusesByBot(botID string) []channel.ConnectionStatus {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -240,6 +247,16 @@ func Handlers(channelRuntime channel.Runtime, emailRuntime email.Runtime, tunnel
 			}
 			return nil, runtimeRpc.Public(channelRuntime.React(ctx, in.BotID, in.ChannelType, in.React))
 		},
+		MethodBroadcast: func(ctx context.Context, raw json.RawMessage) (any, error) {
+			var in channelInput
+			if err := decode(raw, &in); err != nil {
+				return nil, err
+			}
+			// Per-conversation failures are reported in the result; an
+			// error here means the broadcast could not start.
+			out, err := channelRuntime.Broadcast(ctx, in.BotID, in.Broadcast)
+			return out, runtimeRpc.Public(err)
+		},
 		MethodStatuses: func(_ context.Context, raw json.RawMessage) (any, error) {
 			var botID string
 			if err := decode(raw, &botID); err != nil {
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
@@ -29,6 +30,8 @@ func Dial(target, secret string) (*grpc.ClientConn, error) {
 	return grpc.NewClient(
 		target,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		// Carries the caller's trace context (traceparent) to the peer.
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		grpc.WithUnaryInterceptor(UnaryClientAuth(secret)),
 		grpc.WithStreamInterceptor(StreamClientAuth(secret)),
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{
@@ -57,6 +60,7 @@ func NewServer(secret string, opts ...grpc.ServerOption) *grpc.Server {
 		}),
 		grpc.MaxRecvMsgSize(MaxMessageBytes),
 		grpc.MaxSendMsgSize(MaxMessageBytes),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 	}
 	return grpc.NewServer(append(base, opts...)...)
 }
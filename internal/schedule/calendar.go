@@ -0,0 +1,145 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/memohai/memoh/internal/db"
+)
+
+const (
+	// feedWindow is how far ahead the calendar feed lists runs. Cron
+	// patterns cannot be expressed as RRULEs in general, so runs are
+	// expanded one by one.
+	feedWindow = 30 * 24 * time.Hour
+	// maxFeedRunsPerSchedule keeps a minutely schedule from flooding the
+	// calendar.
+	maxFeedRunsPerSchedule = 100
+	// feedRunDuration gives runs a visible block in calendar clients.
+	feedRunDuration = 15 * time.Minute
+	// feedRefreshInterval is the refresh hint sent to calendar clients.
+	feedRefreshInterval = time.Hour
+
+	icsTimeLayout = "20060102T150405Z"
+	icsLineLimit  = 75
+)
+
+type calendarRun struct {
+	schedule Schedule
+	at       time.Time
+}
+
+// CalendarFeed renders the bot's upcoming schedule runs as an iCalendar
+// feed. Runs are listed for the next 30 days, evaluated in each schedule's
+// timezone (the bot's by default), and stop at a schedule's max_calls.
+func (s *Service) CalendarFeed(ctx context.Context, botID string, now time.Time) ([]byte, error) {
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	bot, err := s.queries.GetBotByID(ctx, pgBotID)
+	if err != nil {
+		return nil, fmt.Errorf("get bot: %w", err)
+	}
+	name := bot.Name
+	if bot.DisplayName.Valid && strings.TrimSpace(bot.DisplayName.String) != "" {
+		name = bot.DisplayName.String
+	}
+	items, err := s.List(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	loc := s.resolveBotLocation(ctx, pgBotID)
+	var runs []calendarRun
+	for _, item := range items {
+		if !item.Enabled {
+			continue
+		}
+		parsed, err := s.parser.Parse(item.Pattern)
+		if err != nil {
+			continue
+		}
+		limit := maxFeedRunsPerSchedule
+		if item.MaxCalls != nil {
+			limit = min(limit, *item.MaxCalls-item.CurrentCalls)
+		}
+		for _, at := range upcomingRuns(newLocationSchedule(parsed, scheduleLocation(item.Timezone, loc)), now, now.Add(feedWindow), limit) {
+			runs = append(runs, calendarRun{schedule: item, at: at})
+		}
+	}
+	return renderCalendar(name, runs, now), nil
+}
+
+// upcomingRuns lists the times sched fires after from and up to until.
+func upcomingRuns(sched cron.Schedule, from, until time.Time, limit int) []time.Time {
+	var out []time.Time
+	for t := sched.Next(from); len(out) < limit && !t.IsZero() && !t.After(until); t = sched.Next(t) {
+		out = append(out, t)
+	}
+	return out
+}
+
+func renderCalendar(name string, runs []calendarRun, now time.Time) []byte {
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].at.Before(runs[j].at) })
+	stamp := now.UTC().Format(icsTimeLayout)
+	refresh := fmt.Sprintf("PT%dM", int(feedRefreshInterval/time.Minute))
+
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//Memoh//Schedules//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:"+escapeICSText(name+" schedules"))
+	writeICSLine(&b, "REFRESH-INTERVAL;VALUE=DURATION:"+refresh)
+	writeICSLine(&b, "X-PUBLISHED-TTL:"+refresh)
+	for _, run := range runs {
+		description := run.schedule.Description
+		if description != "" {
+			description += "\n\n"
+		}
+		description += "Schedule: " + run.schedule.Pattern
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, fmt.Sprintf("UID:%s-%d@memoh", run.schedule.ID, run.at.Unix()))
+		writeICSLine(&b, "DTSTAMP:"+stamp)
+		writeICSLine(&b, "DTSTART:"+run.at.UTC().Format(icsTimeLayout))
+		writeICSLine(&b, "DTEND:"+run.at.Add(feedRunDuration).UTC().Format(icsTimeLayout))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(run.schedule.Name))
+		writeICSLine(&b, "DESCRIPTION:"+escapeICSText(description))
+		writeICSLine(&b, "TRANSP:TRANSPARENT")
+		writeICSLine(&b, "END:VEVENT")
+	}
+	writeICSLine(&b, "END:VCALENDAR")
+	return []byte(b.String())
+}
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func escapeICSText(value string) string {
+	return icsTextEscaper.Replace(value)
+}
+
+// writeICSLine writes a content line folded at 75 octets as RFC 5545
+// requires, without splitting UTF-8 sequences.
+func writeICSLine(b *strings.Builder, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
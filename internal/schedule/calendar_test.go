@@ -0,0 +1,87 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func TestUpcomingRunsRespectsWindowAndLimit(t *testing.T) {
+	parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	sched, err := parser.Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	runs := upcomingRuns(sched, now, now.Add(72*time.Hour), 10)
+	if len(runs) != 3 {
+		t.Fatalf("runs = %v, want 3 in window", runs)
+	}
+	if !runs[0].Equal(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("first run = %v", runs[0])
+	}
+	if got := upcomingRuns(sched, now, now.Add(72*time.Hour), 2); len(got) != 2 {
+		t.Fatalf("limited runs = %d, want 2", len(got))
+	}
+	if got := upcomingRuns(sched, now, now.Add(72*time.Hour), 0); len(got) != 0 {
+		t.Fatalf("exhausted schedule listed %d runs", len(got))
+	}
+}
+
+func TestRenderCalendar(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.FixedZone("UTC+8", 8*3600))
+	out := string(renderCalendar("Helper", []calendarRun{{
+		schedule: Schedule{ID: "s1", Name: "Standup, daily; notes", Description: "line one\nline two", Pattern: "0 9 * * *"},
+		at:       at,
+	}}, now))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Helper schedules\r\n",
+		"UID:s1-" + "1772413200" + "@memoh\r\n",
+		"DTSTART:20260302T010000Z\r\n",
+		"DTEND:20260302T011500Z\r\n",
+		`SUMMARY:Standup\, daily\; notes` + "\r\n",
+		`DESCRIPTION:line one\nline two\n\nSchedule: 0 9 * * *` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("calendar missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteICSLineFoldsLongLines(t *testing.T) {
+	var b strings.Builder
+	writeICSLine(&b, "SUMMARY:"+strings.Repeat("日", 60))
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("line not folded: %q", b.String())
+	}
+	var joined strings.Builder
+	for i, line := range lines {
+		if len(line) > icsLineLimit {
+			t.Fatalf("line %d is %d octets", i, len(line))
+		}
+		if i > 0 {
+			if !strings.HasPrefix(line, " ") {
+				t.Fatalf("continuation line %d missing leading space", i)
+			}
+			line = line[1:]
+		}
+		joined.WriteString(line)
+	}
+	if joined.String() != "SUMMARY:"+strings.Repeat("日", 60) {
+		t.Fatalf("unfolded line changed: %q", joined.String())
+	}
+}
+
+func TestHashFeedTokenIsStable(t *testing.T) {
+	if hashFeedToken("abc") != hashFeedToken("abc") || hashFeedToken("abc") == hashFeedToken("abd") {
+		t.Fatal("feed token hash is not a stable digest")
+	}
+}
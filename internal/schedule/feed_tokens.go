@@ -0,0 +1,199 @@
+package schedule
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	neturl "net/url"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+var (
+	ErrFeedTokenNotFound    = errors.New("feed token not found")
+	ErrInvalidFeedToken     = errors.New("invalid feed token")
+	ErrInvalidFeedTokenName = errors.New("invalid feed token name")
+)
+
+const (
+	// feedTokenBytes is the entropy of a feed token. Tokens end up in calendar
+	// client URLs, so they are the only thing guarding the feed.
+	feedTokenBytes   = 32
+	maxFeedTokenName = 100
+)
+
+type feedTokenQueries interface {
+	CreateScheduleFeedToken(ctx context.Context, arg sqlc.CreateScheduleFeedTokenParams) (sqlc.ScheduleFeedToken, error)
+	DeleteScheduleFeedToken(ctx context.Context, arg sqlc.DeleteScheduleFeedTokenParams) (int64, error)
+	GetScheduleFeedTokenByHash(ctx context.Context, tokenHash string) (sqlc.ScheduleFeedToken, error)
+	ListScheduleFeedTokens(ctx context.Context, botID pgtype.UUID) ([]sqlc.ScheduleFeedToken, error)
+	TouchScheduleFeedToken(ctx context.Context, id pgtype.UUID) error
+}
+
+func (s *Service) feedTokenStore() (feedTokenQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("schedule queries not configured")
+	}
+	store, ok := s.queries.(feedTokenQueries)
+	if !ok {
+		return nil, errors.New("schedule feed token queries not supported by store")
+	}
+	return store, nil
+}
+
+// FeedPath is the calendar feed path of a bot. Requests to it carry a feed
+// token instead of a session token.
+func FeedPath(botID string) string {
+	return "/bots/" + neturl.PathEscape(botID) + "/schedules.ics"
+}
+
+// IsFeedPath reports whether path is a bot calendar feed path.
+func IsFeedPath(path string) bool {
+	rest, ok := strings.CutPrefix(path, "/bots/")
+	if !ok {
+		return false
+	}
+	botID, ok := strings.CutSuffix(rest, "/schedules.ics")
+	return ok && botID != "" && !strings.Contains(botID, "/")
+}
+
+// CreateFeedToken issues a token for the bot's calendar feed. Only a hash is
+// stored, so the token and feed URL are returned this once.
+func (s *Service) CreateFeedToken(ctx context.Context, botID, userID string, req CreateFeedTokenRequest) (FeedToken, error) {
+	store, err := s.feedTokenStore()
+	if err != nil {
+		return FeedToken{}, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if len([]rune(name)) > maxFeedTokenName {
+		return FeedToken{}, fmt.Errorf("%w: must be at most %d characters", ErrInvalidFeedTokenName, maxFeedTokenName)
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return FeedToken{}, err
+	}
+	buf := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return FeedToken{}, fmt.Errorf("generate feed token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	row, err := store.CreateScheduleFeedToken(ctx, sqlc.CreateScheduleFeedTokenParams{
+		BotID:     pgBotID,
+		Name:      name,
+		TokenHash: hashFeedToken(token),
+		CreatedBy: db.ParseUUIDOrEmpty(userID),
+	})
+	if err != nil {
+		return FeedToken{}, err
+	}
+	item := toFeedToken(row)
+	item.Token = token
+	item.URL = FeedPath(item.BotID) + "?token=" + neturl.QueryEscape(token)
+	return item, nil
+}
+
+func (s *Service) ListFeedTokens(ctx context.Context, botID string) ([]FeedToken, error) {
+	store, err := s.feedTokenStore()
+	if err != nil {
+		return nil, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := store.ListScheduleFeedTokens(ctx, pgBotID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]FeedToken, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, toFeedToken(row))
+	}
+	return items, nil
+}
+
+// RevokeFeedToken deletes a token; calendars subscribed with it stop
+// refreshing.
+func (s *Service) RevokeFeedToken(ctx context.Context, botID, tokenID string) error {
+	store, err := s.feedTokenStore()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	pgID, err := db.ParseUUID(tokenID)
+	if err != nil {
+		return ErrFeedTokenNotFound
+	}
+	n, err := store.DeleteScheduleFeedToken(ctx, sqlc.DeleteScheduleFeedTokenParams{ID: pgID, BotID: pgBotID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrFeedTokenNotFound
+	}
+	return nil
+}
+
+// AuthenticateFeedToken checks that token was issued for the bot's feed.
+func (s *Service) AuthenticateFeedToken(ctx context.Context, botID, token string) error {
+	store, err := s.feedTokenStore()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return ErrInvalidFeedToken
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrInvalidFeedToken
+	}
+	row, err := store.GetScheduleFeedTokenByHash(ctx, hashFeedToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidFeedToken
+		}
+		return err
+	}
+	if row.BotID != pgBotID {
+		return ErrInvalidFeedToken
+	}
+	if err := store.TouchScheduleFeedToken(ctx, row.ID); err != nil {
+		s.logger.Warn("record feed token use failed", slog.String("token_id", row.ID.String()), slog.Any("error", err))
+	}
+	return nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toFeedToken(row sqlc.ScheduleFeedToken) FeedToken {
+	item := FeedToken{
+		ID:    row.ID.String(),
+		BotID: row.BotID.String(),
+		Name:  row.Name,
+	}
+	if row.CreatedAt.Valid {
+		item.CreatedAt = row.CreatedAt.Time
+	}
+	if row.LastUsedAt.Valid {
+		t := row.LastUsedAt.Time
+		item.LastUsedAt = &t
+	}
+	return item
+}
@@ -0,0 +1,83 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	DefaultMaxPerBot   = 20
+	DefaultMinInterval = 5 * time.Minute
+
+	// intervalSampleRuns is how many upcoming runs ShortestInterval looks at.
+	// That covers every gap of a pattern repeating within a day or a week,
+	// which is where overly frequent patterns come from.
+	intervalSampleRuns = 64
+)
+
+// patternParser parses cron patterns, with an optional leading seconds field.
+var patternParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ErrLimitExceeded is returned when a schedule would exceed a bot's limits.
+var ErrLimitExceeded = errors.New("schedule limit exceeded")
+
+// Limits caps the schedules an agent can set up for its own bot. A zero
+// field disables that check.
+type Limits struct {
+	MaxPerBot   int
+	MinInterval time.Duration
+}
+
+// DefaultLimits returns the limits used when none are configured.
+func DefaultLimits() Limits {
+	return Limits{MaxPerBot: DefaultMaxPerBot, MinInterval: DefaultMinInterval}
+}
+
+// CheckCount reports whether a bot that already has existing schedules may
+// create another one.
+func (l Limits) CheckCount(existing int) error {
+	if l.MaxPerBot > 0 && existing >= l.MaxPerBot {
+		return fmt.Errorf("%w: a bot can have at most %d schedules; delete one first", ErrLimitExceeded, l.MaxPerBot)
+	}
+	return nil
+}
+
+// CheckPattern reports whether pattern runs no more often than MinInterval.
+func (l Limits) CheckPattern(pattern string) error {
+	if l.MinInterval <= 0 {
+		return nil
+	}
+	shortest, err := ShortestInterval(pattern, time.Now())
+	if err != nil {
+		return err
+	}
+	if shortest > 0 && shortest < l.MinInterval {
+		return fmt.Errorf("%w: pattern %q runs every %s, more often than the minimum interval of %s", ErrLimitExceeded, pattern, shortest, l.MinInterval)
+	}
+	return nil
+}
+
+// ShortestInterval returns the shortest gap between upcoming runs of
+// pattern after from, or zero when it runs at most once.
+func ShortestInterval(pattern string, from time.Time) (time.Duration, error) {
+	parsed, err := patternParser.Parse(pattern)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cron pattern: %w", err)
+	}
+	var shortest time.Duration
+	prev := parsed.Next(from)
+	for i := 0; i < intervalSampleRuns && !prev.IsZero(); i++ {
+		next := parsed.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if gap := next.Sub(prev); shortest == 0 || gap < shortest {
+			shortest = gap
+		}
+		prev = next
+	}
+	return shortest, nil
+}
@@ -0,0 +1,49 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShortestInterval(t *testing.T) {
+	from := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"*/10 * * * *":   10 * time.Minute,
+		"0 9 * * 1":      7 * 24 * time.Hour,
+		"0,1 9 * * *":    time.Minute,
+		"*/30 * * * * *": 30 * time.Second,
+		"@hourly":        time.Hour,
+	}
+	for pattern, want := range cases {
+		got, err := ShortestInterval(pattern, from)
+		if err != nil {
+			t.Fatalf("ShortestInterval(%q): %v", pattern, err)
+		}
+		if got != want {
+			t.Errorf("ShortestInterval(%q) = %s, want %s", pattern, got, want)
+		}
+	}
+	if _, err := ShortestInterval("not a pattern", from); err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	limits := Limits{MaxPerBot: 2, MinInterval: 5 * time.Minute}
+	if err := limits.CheckCount(1); err != nil {
+		t.Fatalf("CheckCount(1) = %v", err)
+	}
+	if err := limits.CheckCount(2); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("CheckCount(2) = %v", err)
+	}
+	if err := limits.CheckPattern("0 9 * * 1"); err != nil {
+		t.Fatalf("weekly pattern rejected: %v", err)
+	}
+	if err := limits.CheckPattern("* * * * *"); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("minutely pattern = %v", err)
+	}
+	if err := (Limits{}).CheckPattern("* * * * * *"); err != nil {
+		t.Fatalf("zero limits rejected pattern: %v", err)
+	}
+}
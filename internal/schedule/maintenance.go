@@ -0,0 +1,35 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrMaintenanceActive is returned when a schedule run is skipped because
+// the deployment is in maintenance mode.
+var ErrMaintenanceActive = errors.New("maintenance mode is active")
+
+// MaintenanceChecker reports whether the deployment is in maintenance mode.
+type MaintenanceChecker interface {
+	Active(ctx context.Context) (string, bool)
+}
+
+// SetMaintenance pauses schedule runs while maintenance mode is on. Runs
+// due during maintenance are skipped, not caught up afterwards, and do not
+// count towards max_calls.
+func (s *Service) SetMaintenance(checker MaintenanceChecker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maintenance = checker
+}
+
+func (s *Service) maintenanceActive(ctx context.Context) bool {
+	s.mu.Lock()
+	checker := s.maintenance
+	s.mu.Unlock()
+	if checker == nil {
+		return false
+	}
+	_, active := checker.Active(ctx)
+	return active
+}
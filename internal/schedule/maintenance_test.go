@@ -0,0 +1,35 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+type fakeMaintenance struct{ active bool }
+
+func (f fakeMaintenance) Active(context.Context) (string, bool) { return "", f.active }
+
+type countingTriggerer struct{ calls int }
+
+func (c *countingTriggerer) TriggerSchedule(context.Context, string, TriggerPayload, string) (TriggerResult, error) {
+	c.calls++
+	return TriggerResult{}, nil
+}
+
+func TestRunScheduleSkippedDuringMaintenance(t *testing.T) {
+	t.Parallel()
+
+	triggerer := &countingTriggerer{}
+	svc := &Service{triggerer: triggerer, logger: slog.Default()}
+	svc.SetMaintenance(fakeMaintenance{active: true})
+
+	err := svc.runSchedule(context.Background(), Schedule{ID: "11111111-1111-1111-1111-111111111111", BotID: "22222222-2222-2222-2222-222222222222"})
+	if !errors.Is(err, ErrMaintenanceActive) {
+		t.Fatalf("runSchedule error = %v, want ErrMaintenanceActive", err)
+	}
+	if triggerer.calls != 0 {
+		t.Fatalf("triggered %d runs during maintenance", triggerer.calls)
+	}
+}
@@ -0,0 +1,240 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/robfig/cron/v3"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+)
+
+var (
+	ErrMemoryCompactionNotFound = errors.New("memory compaction schedule not found")
+	ErrInvalidMemoryCompaction  = errors.New("invalid memory compaction schedule")
+)
+
+// memoryCompactRunTimeout bounds a scheduled compaction. Compaction asks the
+// LLM to consolidate every memory of the bot, so it gets longer than a
+// scheduled chat run.
+const memoryCompactRunTimeout = 30 * time.Minute
+
+// maxMemoryCompactDecayDays caps decay_days at ten years.
+const maxMemoryCompactDecayDays = 3650
+
+// MemoryCompactor compacts a bot's shared memories.
+type MemoryCompactor interface {
+	CompactMemory(ctx context.Context, botID string, ratio float64, decayDays int) error
+}
+
+type memoryCompactQueries interface {
+	DeleteBotMemoryCompactSchedule(ctx context.Context, botID pgtype.UUID) error
+	GetBotMemoryCompactSchedule(ctx context.Context, botID pgtype.UUID) (sqlc.BotMemoryCompactSchedule, error)
+	ListEnabledBotMemoryCompactSchedules(ctx context.Context) ([]sqlc.BotMemoryCompactSchedule, error)
+	UpsertBotMemoryCompactSchedule(ctx context.Context, arg sqlc.UpsertBotMemoryCompactScheduleParams) (sqlc.BotMemoryCompactSchedule, error)
+}
+
+// MemoryCompaction is a bot's automatic memory compaction schedule.
+type MemoryCompaction struct {
+	BotID     string    `json:"bot_id"`
+	Pattern   string    `json:"pattern"`
+	Timezone  string    `json:"timezone,omitempty"`
+	Ratio     float64   `json:"ratio"`
+	DecayDays int       `json:"decay_days"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MemoryCompactionRequest configures a bot's memory compaction schedule.
+type MemoryCompactionRequest struct {
+	Pattern   string  `json:"pattern"`
+	Timezone  string  `json:"timezone,omitempty"`
+	Ratio     float64 `json:"ratio"`
+	DecayDays int     `json:"decay_days"`
+	Enabled   *bool   `json:"enabled,omitempty"`
+}
+
+// SetMemoryCompactor sets the compactor scheduled memory compaction runs.
+func (s *Service) SetMemoryCompactor(compactor MemoryCompactor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memoryCompactor = compactor
+}
+
+func (s *Service) memoryCompactStore() (memoryCompactQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("schedule queries not configured")
+	}
+	store, ok := s.queries.(memoryCompactQueries)
+	if !ok {
+		return nil, errors.New("memory compaction schedule queries not supported by store")
+	}
+	return store, nil
+}
+
+// GetMemoryCompaction returns the bot's memory compaction schedule.
+func (s *Service) GetMemoryCompaction(ctx context.Context, botID string) (MemoryCompaction, error) {
+	store, err := s.memoryCompactStore()
+	if err != nil {
+		return MemoryCompaction{}, err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return MemoryCompaction{}, err
+	}
+	row, err := store.GetBotMemoryCompactSchedule(ctx, pgBotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return MemoryCompaction{}, ErrMemoryCompactionNotFound
+		}
+		return MemoryCompaction{}, err
+	}
+	return toMemoryCompaction(row), nil
+}
+
+// SetMemoryCompaction creates or replaces the bot's memory compaction
+// schedule and reschedules its job.
+func (s *Service) SetMemoryCompaction(ctx context.Context, botID string, req MemoryCompactionRequest) (MemoryCompaction, error) {
+	store, err := s.memoryCompactStore()
+	if err != nil {
+		return MemoryCompaction{}, err
+	}
+	pattern := strings.TrimSpace(req.Pattern)
+	if pattern == "" {
+		return MemoryCompaction{}, fmt.Errorf("%w: pattern is required", ErrInvalidMemoryCompaction)
+	}
+	if _, err := s.parser.Parse(pattern); err != nil {
+		return MemoryCompaction{}, fmt.Errorf("%w: invalid cron pattern: %s", ErrInvalidMemoryCompaction, err.Error())
+	}
+	tz, err := normalizeTimezone(req.Timezone)
+	if err != nil {
+		return MemoryCompaction{}, fmt.Errorf("%w: %s", ErrInvalidMemoryCompaction, err.Error())
+	}
+	if req.Ratio <= 0 || req.Ratio > 1 {
+		return MemoryCompaction{}, fmt.Errorf("%w: ratio must be in range (0, 1]", ErrInvalidMemoryCompaction)
+	}
+	if req.DecayDays < 0 || req.DecayDays > maxMemoryCompactDecayDays {
+		return MemoryCompaction{}, fmt.Errorf("%w: decay_days must be between 0 and %d", ErrInvalidMemoryCompaction, maxMemoryCompactDecayDays)
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return MemoryCompaction{}, err
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	row, err := store.UpsertBotMemoryCompactSchedule(ctx, sqlc.UpsertBotMemoryCompactScheduleParams{
+		BotID:     pgBotID,
+		Pattern:   pattern,
+		Timezone:  tz,
+		Ratio:     req.Ratio,
+		DecayDays: int32(req.DecayDays), //nolint:gosec // bounds checked above
+		Enabled:   enabled,
+	})
+	if err != nil {
+		return MemoryCompaction{}, err
+	}
+	s.removeJob(memoryCompactJobID(row.BotID))
+	if row.Enabled {
+		if err := s.scheduleMemoryCompaction(ctx, row); err != nil {
+			return MemoryCompaction{}, err
+		}
+	}
+	return toMemoryCompaction(row), nil
+}
+
+// DeleteMemoryCompaction removes the bot's memory compaction schedule.
+func (s *Service) DeleteMemoryCompaction(ctx context.Context, botID string) error {
+	store, err := s.memoryCompactStore()
+	if err != nil {
+		return err
+	}
+	pgBotID, err := db.ParseUUID(botID)
+	if err != nil {
+		return err
+	}
+	if err := store.DeleteBotMemoryCompactSchedule(ctx, pgBotID); err != nil {
+		return err
+	}
+	s.removeJob(memoryCompactJobID(pgBotID))
+	return nil
+}
+
+// bootstrapMemoryCompactions schedules every enabled memory compaction.
+func (s *Service) bootstrapMemoryCompactions(ctx context.Context) error {
+	store, err := s.memoryCompactStore()
+	if err != nil {
+		return err
+	}
+	items, err := store.ListEnabledBotMemoryCompactSchedules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := s.scheduleMemoryCompaction(ctx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func memoryCompactJobID(botID pgtype.UUID) string {
+	return "memory_compact:" + botID.String()
+}
+
+func (s *Service) scheduleMemoryCompaction(ctx context.Context, row sqlc.BotMemoryCompactSchedule) error {
+	botID := row.BotID.String()
+	ratio := row.Ratio
+	decayDays := int(row.DecayDays)
+	job := func() {
+		s.mu.Lock()
+		compactor := s.memoryCompactor
+		s.mu.Unlock()
+		if compactor == nil {
+			s.logger.Warn("memory compaction skipped: compactor not configured", slog.String("bot_id", botID))
+			return
+		}
+		runCtx, runCancel := context.WithTimeout(context.WithoutCancel(ctx), memoryCompactRunTimeout)
+		defer runCancel()
+		if err := compactor.CompactMemory(runCtx, botID, ratio, decayDays); err != nil {
+			s.logger.Error("scheduled memory compaction failed", slog.String("bot_id", botID), slog.Any("error", err))
+		}
+	}
+	sched, err := s.parser.Parse(row.Pattern)
+	if err != nil {
+		return err
+	}
+	loc := scheduleLocation(row.Timezone, s.resolveBotLocation(ctx, row.BotID))
+	entryID := s.cron.Schedule(newLocationSchedule(sched, loc), cron.FuncJob(job))
+	s.mu.Lock()
+	s.jobs[memoryCompactJobID(row.BotID)] = entryID
+	s.mu.Unlock()
+	return nil
+}
+
+func toMemoryCompaction(row sqlc.BotMemoryCompactSchedule) MemoryCompaction {
+	item := MemoryCompaction{
+		BotID:     row.BotID.String(),
+		Pattern:   row.Pattern,
+		Timezone:  row.Timezone,
+		Ratio:     row.Ratio,
+		DecayDays: int(row.DecayDays),
+		Enabled:   row.Enabled,
+	}
+	if row.CreatedAt.Valid {
+		item.CreatedAt = row.CreatedAt.Time
+	}
+	if row.UpdatedAt.Valid {
+		item.UpdatedAt = row.UpdatedAt.Time
+	}
+	return item
+}
@@ -0,0 +1,145 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/robfig/cron/v3"
+
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+type fakeMemoryCompactQueries struct {
+	dbstore.Queries
+
+	upserted []sqlc.UpsertBotMemoryCompactScheduleParams
+}
+
+func (*fakeMemoryCompactQueries) GetBotByID(context.Context, pgtype.UUID) (sqlc.GetBotByIDRow, error) {
+	return sqlc.GetBotByIDRow{}, pgx.ErrNoRows
+}
+
+func (*fakeMemoryCompactQueries) DeleteBotMemoryCompactSchedule(context.Context, pgtype.UUID) error {
+	return nil
+}
+
+func (*fakeMemoryCompactQueries) GetBotMemoryCompactSchedule(context.Context, pgtype.UUID) (sqlc.BotMemoryCompactSchedule, error) {
+	return sqlc.BotMemoryCompactSchedule{}, pgx.ErrNoRows
+}
+
+func (*fakeMemoryCompactQueries) ListEnabledBotMemoryCompactSchedules(context.Context) ([]sqlc.BotMemoryCompactSchedule, error) {
+	return nil, nil
+}
+
+func (f *fakeMemoryCompactQueries) UpsertBotMemoryCompactSchedule(_ context.Context, arg sqlc.UpsertBotMemoryCompactScheduleParams) (sqlc.BotMemoryCompactSchedule, error) {
+	f.upserted = append(f.upserted, arg)
+	return sqlc.BotMemoryCompactSchedule{
+		BotID:     arg.BotID,
+		Pattern:   arg.Pattern,
+		Timezone:  arg.Timezone,
+		Ratio:     arg.Ratio,
+		DecayDays: arg.DecayDays,
+		Enabled:   arg.Enabled,
+	}, nil
+}
+
+type fakeCompactor struct {
+	bots      []string
+	ratio     float64
+	decayDays int
+}
+
+func (f *fakeCompactor) CompactMemory(_ context.Context, botID string, ratio float64, decayDays int) error {
+	f.bots = append(f.bots, botID)
+	f.ratio, f.decayDays = ratio, decayDays
+	return nil
+}
+
+func newMemoryCompactTestService(queries dbstore.Queries) *Service {
+	return &Service{
+		queries: queries,
+		cron:    cron.New(cron.WithParser(patternParser)),
+		parser:  patternParser,
+		logger:  slog.Default(),
+		jobs:    map[string]cron.EntryID{},
+	}
+}
+
+func TestSetMemoryCompactionSchedulesJob(t *testing.T) {
+	t.Parallel()
+
+	queries := &fakeMemoryCompactQueries{}
+	svc := newMemoryCompactTestService(queries)
+	compactor := &fakeCompactor{}
+	svc.SetMemoryCompactor(compactor)
+	botID := "11111111-1111-1111-1111-111111111111"
+
+	item, err := svc.SetMemoryCompaction(context.Background(), botID, MemoryCompactionRequest{
+		Pattern:   "0 3 * * 0",
+		Timezone:  "Europe/Berlin",
+		Ratio:     0.6,
+		DecayDays: 90,
+	})
+	if err != nil {
+		t.Fatalf("SetMemoryCompaction: %v", err)
+	}
+	if !item.Enabled || item.Timezone != "Europe/Berlin" {
+		t.Fatalf("item = %+v", item)
+	}
+	entryID, ok := svc.jobs["memory_compact:"+botID]
+	if !ok {
+		t.Fatalf("jobs = %v, want memory compaction job", svc.jobs)
+	}
+	svc.cron.Entry(entryID).Job.Run()
+	if len(compactor.bots) != 1 || compactor.bots[0] != botID || compactor.ratio != 0.6 || compactor.decayDays != 90 {
+		t.Fatalf("compactor = %+v", compactor)
+	}
+
+	disabled := false
+	if _, err := svc.SetMemoryCompaction(context.Background(), botID, MemoryCompactionRequest{
+		Pattern: "0 3 * * 0",
+		Ratio:   0.6,
+		Enabled: &disabled,
+	}); err != nil {
+		t.Fatalf("SetMemoryCompaction(disabled): %v", err)
+	}
+	if _, ok := svc.jobs["memory_compact:"+botID]; ok {
+		t.Fatal("disabled schedule still has a job")
+	}
+}
+
+func TestSetMemoryCompactionValidates(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		req  MemoryCompactionRequest
+	}{
+		{name: "missing pattern", req: MemoryCompactionRequest{Ratio: 0.5}},
+		{name: "bad pattern", req: MemoryCompactionRequest{Pattern: "every week", Ratio: 0.5}},
+		{name: "bad timezone", req: MemoryCompactionRequest{Pattern: "@weekly", Timezone: "Mars/Olympus", Ratio: 0.5}},
+		{name: "zero ratio", req: MemoryCompactionRequest{Pattern: "@weekly"}},
+		{name: "ratio above one", req: MemoryCompactionRequest{Pattern: "@weekly", Ratio: 1.2}},
+		{name: "negative decay", req: MemoryCompactionRequest{Pattern: "@weekly", Ratio: 0.5, DecayDays: -1}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			queries := &fakeMemoryCompactQueries{}
+			svc := newMemoryCompactTestService(queries)
+			_, err := svc.SetMemoryCompaction(context.Background(), "11111111-1111-1111-1111-111111111111", tc.req)
+			if !errors.Is(err, ErrInvalidMemoryCompaction) {
+				t.Fatalf("SetMemoryCompaction error = %v, want ErrInvalidMemoryCompaction", err)
+			}
+			if len(queries.upserted) != 0 {
+				t.Fatalf("upserted = %+v, want none", queries.upserted)
+			}
+		})
+	}
+}
@@ -38,10 +38,12 @@ type Service struct {
 	defaultLocation *time.Location
 	mu              sync.Mutex
 	jobs            map[string]cron.EntryID
+	memoryCompactor MemoryCompactor
+	maintenance     MaintenanceChecker
 }
 
 func NewService(log *slog.Logger, queries dbstore.Queries, triggerer Triggerer, sessionCreator SessionCreator, runtimeConfig *boot.RuntimeConfig) *Service {
-	parser := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	parser := patternParser
 	location := time.UTC
 	if runtimeConfig != nil && runtimeConfig.TimezoneLocation != nil {
 		location = runtimeConfig.TimezoneLocation
@@ -75,7 +77,7 @@ func (s *Service) Bootstrap(ctx context.Context) error {
 			return err
 		}
 	}
-	return nil
+	return s.bootstrapMemoryCompactions(ctx)
 }
 
 func (s *Service) Create(ctx context.Context, botID string, req CreateRequest) (Schedule, error) {
@@ -88,6 +90,10 @@ func (s *Service) Create(ctx context.Context, botID string, req CreateRequest) (
 	if _, err := s.parser.Parse(req.Pattern); err != nil {
 		return Schedule{}, fmt.Errorf("invalid cron pattern: %w", err)
 	}
+	tz, err := normalizeTimezone(req.Timezone)
+	if err != nil {
+		return Schedule{}, err
+	}
 	pgBotID, err := db.ParseUUID(botID)
 	if err != nil {
 		return Schedule{}, err
@@ -111,6 +117,7 @@ func (s *Service) Create(ctx context.Context, botID string, req CreateRequest) (
 		Enabled:     enabled,
 		Command:     req.Command,
 		BotID:       pgBotID,
+		Timezone:    tz,
 	})
 	if err != nil {
 		return Schedule{}, err
@@ -197,6 +204,12 @@ func (s *Service) Update(ctx context.Context, id string, req UpdateRequest) (Sch
 	if req.Enabled != nil {
 		enabled = *req.Enabled
 	}
+	tz := existing.Timezone
+	if req.Timezone != nil {
+		if tz, err = normalizeTimezone(*req.Timezone); err != nil {
+			return Schedule{}, err
+		}
+	}
 	updated, err := s.queries.UpdateSchedule(ctx, sqlc.UpdateScheduleParams{
 		ID:          pgID,
 		Name:        name,
@@ -205,6 +218,7 @@ func (s *Service) Update(ctx context.Context, id string, req UpdateRequest) (Sch
 		MaxCalls:    maxCalls,
 		Enabled:     enabled,
 		Command:     command,
+		Timezone:    tz,
 	})
 	if err != nil {
 		return Schedule{}, err
@@ -251,6 +265,9 @@ func (s *Service) runSchedule(ctx context.Context, sched Schedule) error {
 	if s.triggerer == nil {
 		return errors.New("schedule triggerer not configured")
 	}
+	if s.maintenanceActive(ctx) {
+		return ErrMaintenanceActive
+	}
 	updated, err := s.queries.IncrementScheduleCalls(ctx, toUUID(sched.ID))
 	if err != nil {
 		return err
@@ -493,14 +510,19 @@ func (s *Service) scheduleJob(ctx context.Context, schedule sqlc.Schedule) error
 	job := func() {
 		runCtx, runCancel := context.WithTimeout(context.WithoutCancel(ctx), scheduleRunTimeout)
 		defer runCancel()
-		if err := s.runSchedule(runCtx, toSchedule(schedule)); err != nil {
+		err := s.runSchedule(runCtx, toSchedule(schedule))
+		if errors.Is(err, ErrMaintenanceActive) {
+			s.logger.Info("scheduled job skipped during maintenance", slog.String("schedule_id", schedule.ID.String()))
+			return
+		}
+		if err != nil {
 			s.logger.Error("scheduled job failed", slog.String("schedule_id", schedule.ID.String()), slog.Any("error", err))
 		}
 	}
 
-	// Resolve bot timezone so cron expressions are interpreted in the bot's
-	// configured timezone rather than the system default.
-	loc := s.resolveBotLocation(ctx, schedule.BotID)
+	// Interpret the cron expression in the schedule's own timezone, or the
+	// bot's configured timezone rather than the system default.
+	loc := scheduleLocation(schedule.Timezone, s.resolveBotLocation(ctx, schedule.BotID))
 	sched, err := cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor).Parse(schedule.Pattern)
 	if err != nil {
 		return err
@@ -544,6 +566,7 @@ func toSchedule(row sqlc.Schedule) Schedule {
 		Enabled:      row.Enabled,
 		Command:      row.Command,
 		BotID:        row.BotID.String(),
+		Timezone:     row.Timezone,
 	}
 	if row.MaxCalls.Valid {
 		maxCalls := int(row.MaxCalls.Int32)
@@ -596,6 +619,36 @@ func (s *Service) resolveBotLocation(ctx context.Context, botID pgtype.UUID) *ti
 	return loc
 }
 
+// scheduleLocation returns the location of a schedule's own timezone, or
+// fallback when it has none.
+func scheduleLocation(tz string, fallback *time.Location) *time.Location {
+	if strings.TrimSpace(tz) == "" {
+		return fallback
+	}
+	loc, err := time.LoadLocation(strings.TrimSpace(tz))
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
+
+// normalizeTimezone validates a schedule timezone. Empty stays empty so the
+// schedule follows the bot's timezone.
+func normalizeTimezone(raw string) (string, error) {
+	tz := strings.TrimSpace(raw)
+	if tz == "" {
+		return "", nil
+	}
+	if strings.EqualFold(tz, "local") {
+		return "", errors.New("invalid timezone: use an IANA name such as Europe/Berlin")
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return "", fmt.Errorf("invalid timezone: %w", err)
+	}
+	return loc.String(), nil
+}
+
 // locationSchedule wraps a cron.Schedule to evaluate Next() in a specific
 // timezone, regardless of the global cron location.
 type locationSchedule struct {
@@ -73,3 +73,34 @@ func TestGenerateTriggerToken_EmptyUserID(t *testing.T) {
 		t.Fatal("expected error for empty user ID")
 	}
 }
+
+func TestNormalizeTimezone(t *testing.T) {
+	t.Parallel()
+
+	if tz, err := normalizeTimezone(" Asia/Tokyo "); err != nil || tz != "Asia/Tokyo" {
+		t.Fatalf("normalizeTimezone(Asia/Tokyo) = %q, %v", tz, err)
+	}
+	if tz, err := normalizeTimezone(""); err != nil || tz != "" {
+		t.Fatalf("normalizeTimezone(empty) = %q, %v", tz, err)
+	}
+	for _, raw := range []string{"Local", "Mars/Olympus"} {
+		if _, err := normalizeTimezone(raw); err == nil {
+			t.Fatalf("normalizeTimezone(%q) should fail", raw)
+		}
+	}
+}
+
+func TestScheduleLocationFallsBackToBot(t *testing.T) {
+	t.Parallel()
+
+	bot := time.FixedZone("bot", 3600)
+	if loc := scheduleLocation("", bot); loc != bot {
+		t.Fatalf("empty timezone location = %v, want bot", loc)
+	}
+	if loc := scheduleLocation("Bad/Zone", bot); loc != bot {
+		t.Fatalf("invalid timezone location = %v, want bot", loc)
+	}
+	if loc := scheduleLocation("America/Chicago", bot); loc.String() != "America/Chicago" {
+		t.Fatalf("location = %v, want America/Chicago", loc)
+	}
+}
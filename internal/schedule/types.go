@@ -17,6 +17,9 @@ type Schedule struct {
 	Enabled      bool      `json:"enabled"`
 	Command      string    `json:"command"`
 	BotID        string    `json:"bot_id"`
+	// Timezone is the IANA timezone the pattern is written in. Empty means
+	// the bot's timezone.
+	Timezone string `json:"timezone,omitempty"`
 }
 
 type NullableInt struct {
@@ -56,6 +59,7 @@ type CreateRequest struct {
 	MaxCalls    NullableInt `json:"max_calls,omitempty"`
 	Command     string      `json:"command"`
 	Enabled     *bool       `json:"enabled,omitempty"`
+	Timezone    string      `json:"timezone,omitempty"`
 }
 
 type UpdateRequest struct {
@@ -65,6 +69,7 @@ type UpdateRequest struct {
 	MaxCalls    NullableInt `json:"max_calls,omitempty"`
 	Command     *string     `json:"command,omitempty"`
 	Enabled     *bool       `json:"enabled,omitempty"`
+	Timezone    *string     `json:"timezone,omitempty"`
 }
 
 type ListResponse struct {
@@ -88,3 +93,23 @@ type ListLogsResponse struct {
 	Items      []Log `json:"items"`
 	TotalCount int64 `json:"total_count"`
 }
+
+// FeedToken grants read access to a bot's schedule calendar feed. Token and
+// URL are only set when the token is created.
+type FeedToken struct {
+	ID         string     `json:"id"`
+	BotID      string     `json:"bot_id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	URL        string     `json:"url,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+type CreateFeedTokenRequest struct {
+	Name string `json:"name"`
+}
+
+type ListFeedTokensResponse struct {
+	Items []FeedToken `json:"items"`
+}
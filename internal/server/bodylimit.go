@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetBodyLimits caps request bodies on authenticated routes. defaultLimit
+// applies to every route; groups maps a route prefix, as registered (e.g.
+// "/bots/:bot_id/web/media"), to its own limit, the longest matching prefix
+// winning. A limit of zero leaves bodies unlimited. Public webhooks keep
+// their fixed 1M cap. Call it once, before Start.
+func (s *Server) SetBodyLimits(defaultLimit int64, groups map[string]int64) {
+	if s == nil || (defaultLimit <= 0 && len(groups) == 0) {
+		return
+	}
+	s.echo.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Body == nil || shouldSkipJWT(req.URL.Path) {
+				return next(c)
+			}
+			limit := bodyLimitFor(c.Path(), defaultLimit, groups)
+			if limit <= 0 {
+				return next(c)
+			}
+			if req.ContentLength > limit {
+				return echo.ErrStatusRequestEntityTooLarge
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			return next(c)
+		}
+	})
+}
+
+// bodyLimitFor returns the limit of the longest group prefix matching the
+// route, or defaultLimit when none does.
+func bodyLimitFor(route string, defaultLimit int64, groups map[string]int64) int64 {
+	limit, matched := defaultLimit, -1
+	for prefix, groupLimit := range groups {
+		if len(prefix) > matched && routeHasPrefix(route, prefix) {
+			limit, matched = groupLimit, len(prefix)
+		}
+	}
+	return limit
+}
+
+// routeHasPrefix matches whole path segments, so "/bots/backup" does not
+// cover "/bots/backups".
+func routeHasPrefix(route, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !strings.HasPrefix(route, prefix) {
+		return false
+	}
+	return len(route) == len(prefix) || route[len(prefix)] == '/'
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/auth"
+)
+
+type uploadTestHandler struct{}
+
+func (uploadTestHandler) Register(e *echo.Echo) {
+	read := func(c echo.Context) error {
+		if _, err := io.ReadAll(c.Request().Body); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusOK)
+	}
+	e.POST("/bots/:bot_id/settings", read)
+	e.POST("/bots/:bot_id/web/media", read)
+	e.POST("/bots/backup/import", read)
+}
+
+func TestServerBodyLimitsPerRouteGroup(t *testing.T) {
+	t.Parallel()
+
+	const secret = "test-secret"
+	server := NewServer(slog.New(slog.DiscardHandler), ":0", secret, uploadTestHandler{})
+	server.SetBodyLimits(8, map[string]int64{
+		"/bots/:bot_id/web/media": 32,
+		"/bots/backup":            0,
+	})
+	token, _, err := auth.GenerateToken("user-a", secret, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	post := func(path string, size int, chunked bool) int {
+		t.Helper()
+		var body io.Reader = strings.NewReader(strings.Repeat("x", size))
+		if chunked {
+			// Hide the length so only the reader enforces the limit.
+			body = io.MultiReader(body)
+		}
+		req := httptest.NewRequest(http.MethodPost, path, body)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, tc := range []struct {
+		path    string
+		size    int
+		chunked bool
+		want    int
+	}{
+		{"/bots/b1/settings", 8, false, http.StatusOK},
+		{"/bots/b1/settings", 9, false, http.StatusRequestEntityTooLarge},
+		{"/bots/b1/settings", 9, true, http.StatusRequestEntityTooLarge},
+		{"/bots/b1/web/media", 32, false, http.StatusOK},
+		{"/bots/b1/web/media", 33, true, http.StatusRequestEntityTooLarge},
+		{"/bots/backup/import", 1024, false, http.StatusOK},
+	} {
+		if got := post(tc.path, tc.size, tc.chunked); got != tc.want {
+			t.Errorf("POST %s with %d bytes (chunked %v) = %d, want %d", tc.path, tc.size, tc.chunked, got, tc.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 
@@ -17,6 +18,11 @@ func newHTTPErrorHandler(log *slog.Logger, fallback echo.HTTPErrorHandler) echo.
 			return
 		}
 
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			err = echo.ErrStatusRequestEntityTooLarge
+		}
+
 		problem, ok := apperror.ProblemFrom(err, httpx.RequestID(c))
 		if !ok {
 			fallback(err, c)
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+
+	"github.com/memohai/memoh/internal/auth"
+	"github.com/memohai/memoh/internal/sharedstate"
+)
+
+// rateLimitIdleExpiry is how long an idle user's bucket is kept.
+const rateLimitIdleExpiry = 5 * time.Minute
+
+// SetRateLimit limits each authenticated user to perMinute requests per
+// minute with bursts of up to burst requests; burst zero uses perMinute.
+// Unauthenticated routes (webhooks, public media, login) are not limited
+// here. perMinute zero or less leaves the API unlimited. The buckets live in
+// shared when it is set, so replicas enforce one limit, and in process
+// memory otherwise. Call it once, before Start.
+func (s *Server) SetRateLimit(perMinute, burst int, shared sharedstate.Store) {
+	if s == nil || perMinute <= 0 {
+		return
+	}
+	if burst <= 0 {
+		burst = perMinute
+	}
+	var store middleware.RateLimiterStore = middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(float64(perMinute) / 60),
+		Burst:     burst,
+		ExpiresIn: rateLimitIdleExpiry,
+	})
+	if shared != nil {
+		store = sharedRateLimiterStore{shared: shared, perMinute: perMinute, burst: burst}
+	}
+	s.echo.Use(middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Skipper: func(c echo.Context) bool {
+			return shouldSkipJWT(c.Request().URL.Path)
+		},
+		IdentifierExtractor: rateLimitIdentifier,
+		Store:               store,
+		DenyHandler: func(c echo.Context, _ string, _ error) error {
+			c.Response().Header().Set("Retry-After", "60")
+			return echo.NewHTTPError(http.StatusTooManyRequests, "too many requests, slow down")
+		},
+	}))
+}
+
+// rateLimitIdentifier keys the bucket by the authenticated user, falling
+// back to the client address for tokens without one.
+func rateLimitIdentifier(c echo.Context) (string, error) {
+	if userID, err := auth.UserIDFromContext(c); err == nil {
+		return "user:" + userID, nil
+	}
+	return "ip:" + c.RealIP(), nil
+}
+
+// sharedRateLimiterStore adapts a shared store to the echo rate limiter.
+type sharedRateLimiterStore struct {
+	shared    sharedstate.Store
+	perMinute int
+	burst     int
+}
+
+func (s sharedRateLimiterStore) Allow(identifier string) (bool, error) {
+	return s.shared.Allow(context.Background(), "api:"+identifier, s.perMinute, s.burst)
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/memohai/memoh/internal/auth"
+)
+
+type okTestHandler struct{}
+
+func (okTestHandler) Register(e *echo.Echo) {
+	e.GET("/bots", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+}
+
+func TestServerRateLimitsPerUser(t *testing.T) {
+	t.Parallel()
+
+	const secret = "test-secret"
+	server := NewServer(slog.New(slog.DiscardHandler), ":0", secret, okTestHandler{})
+	server.SetRateLimit(2, 0, nil)
+
+	get := func(path, userID string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if userID != "" {
+			token, _, err := auth.GenerateToken(userID, secret, time.Hour)
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for i := range 2 {
+		if code := get("/bots", "user-a"); code != http.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i, code)
+		}
+	}
+	if code := get("/bots", "user-a"); code != http.StatusTooManyRequests {
+		t.Fatalf("status after burst = %d, want 429", code)
+	}
+	if code := get("/bots", "user-b"); code != http.StatusOK {
+		t.Fatalf("other user status = %d, want 200", code)
+	}
+	for range 3 {
+		if code := get("/health", ""); code != http.StatusOK {
+			t.Fatalf("public route status = %d, want 200", code)
+		}
+	}
+}
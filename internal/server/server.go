@@ -11,7 +11,9 @@ import (
 
 	"github.com/memohai/memoh/internal/auth"
 	"github.com/memohai/memoh/internal/channel/publicmedia"
+	"github.com/memohai/memoh/internal/dataexport"
 	"github.com/memohai/memoh/internal/httpx"
+	"github.com/memohai/memoh/internal/schedule"
 )
 
 type Server struct {
@@ -57,8 +59,8 @@ func newServer(log *slog.Logger, addr string, jwtSecret string,
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:  []string{"*"},
 		AllowMethods:  []string{echo.GET, echo.HEAD, echo.POST, echo.PUT, echo.PATCH, echo.DELETE, echo.OPTIONS},
-		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, echo.HeaderXRequestID},
-		ExposeHeaders: []string{echo.HeaderXRequestID},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, echo.HeaderXRequestID, "Idempotency-Key"},
+		ExposeHeaders: []string{echo.HeaderXRequestID, "Idempotent-Replayed"},
 	}))
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		HandleError: true,
@@ -106,6 +108,11 @@ func shouldSkipJWT(path string) bool {
 	if path == "/" || path == "/ping" || path == "/health" || path == "/api/swagger.json" || path == "/auth/login" || path == "/runtimes/connect" {
 		return true
 	}
+	// Refresh and logout authenticate with a refresh token because the
+	// access token may already have expired.
+	if path == "/auth/refresh" || path == "/auth/logout" {
+		return true
+	}
 	if strings.HasPrefix(path, "/assets/") {
 		return true
 	}
@@ -118,15 +125,27 @@ func shouldSkipJWT(path string) bool {
 	if isPublicChannelMediaPath(path) {
 		return true
 	}
+	if dataexport.IsDownloadPath(path) {
+		return true
+	}
 	if strings.HasPrefix(path, "/email/mailgun/webhook/") {
 		return true
 	}
+	if path == "/github/webhook" {
+		return true
+	}
+	if schedule.IsFeedPath(path) {
+		return true
+	}
 	if strings.HasPrefix(path, "/email/oauth/callback") || strings.HasPrefix(path, "/api/email/oauth/callback") {
 		return true
 	}
 	if strings.HasPrefix(path, "/oauth/mcp/callback") || strings.HasPrefix(path, "/api/oauth/mcp/callback") {
 		return true
 	}
+	if strings.HasPrefix(path, "/knowledge/connectors/oauth/callback") || strings.HasPrefix(path, "/api/knowledge/connectors/oauth/callback") {
+		return true
+	}
 	if strings.HasPrefix(path, "/providers/oauth/callback") {
 		return true
 	}
@@ -158,7 +177,7 @@ func safeRequestLogURI(u *neturl.URL, fallback string) string {
 		return fallback
 	}
 	escapedPath := u.EscapedPath()
-	if isPublicChannelMediaPath(escapedPath) {
+	if isPublicChannelMediaPath(escapedPath) || dataexport.IsDownloadPath(escapedPath) || schedule.IsFeedPath(escapedPath) {
 		return escapedPath
 	}
 	if fallback != "" {
@@ -32,6 +32,8 @@ func TestShouldSkipJWT_ChannelWebhookPaths(t *testing.T) {
 		{path: "/channels/feishu/webhook", want: false},
 		{path: "/api/channels/feishu/webhook", want: false},
 		{path: "/webhook-tunnel/status", want: false},
+		{path: "/data-exports/job-1/download", want: true},
+		{path: "/data-exports/job-1", want: false},
 	}
 
 	for _, tc := range cases {
@@ -87,6 +89,53 @@ func TestShouldSkipJWT_MCPOAuthCallbackPaths(t *testing.T) {
 	}
 }
 
+func TestShouldSkipJWT_KnowledgeConnectorOAuthCallbackPaths(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"/knowledge/connectors/oauth/callback", "/api/knowledge/connectors/oauth/callback"} {
+		if !shouldSkipJWT(path) {
+			t.Fatalf("path=%q should skip jwt", path)
+		}
+	}
+	if shouldSkipJWT("/knowledge/collections") {
+		t.Fatal("knowledge collection routes must require jwt")
+	}
+}
+
+func TestShouldSkipJWT_ScheduleFeedPath(t *testing.T) {
+	t.Parallel()
+
+	if !shouldSkipJWT("/bots/bot-1/schedules.ics") {
+		t.Fatal("schedule calendar feed must skip jwt")
+	}
+	for _, path := range []string{"/bots/bot-1/schedule", "/bots/bot-1/schedule/feed-tokens", "/bots/schedules.ics", "/bots/a/b/schedules.ics"} {
+		if shouldSkipJWT(path) {
+			t.Fatalf("path=%q must require jwt", path)
+		}
+	}
+
+	u, err := neturl.Parse("/bots/bot-1/schedules.ics?token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := safeRequestLogURI(u, u.RequestURI()); got != "/bots/bot-1/schedules.ics" {
+		t.Fatalf("safeRequestLogURI = %q, feed token must not be logged", got)
+	}
+}
+
+func TestShouldSkipJWT_GitHubWebhookPath(t *testing.T) {
+	t.Parallel()
+
+	if !shouldSkipJWT("/github/webhook") {
+		t.Fatal("github webhook must skip jwt")
+	}
+	for _, path := range []string{"/github/repos", "/github/webhook/extra"} {
+		if shouldSkipJWT(path) {
+			t.Fatalf("path=%q must require jwt", path)
+		}
+	}
+}
+
 type errorTestHandler struct {
 	err error
 }
@@ -186,3 +235,17 @@ func TestShouldSkipJWTOnlyForRuntimeConnectEndpoint(t *testing.T) {
 		}
 	}
 }
+
+func TestShouldSkipJWTForRefreshTokenEndpoints(t *testing.T) {
+	t.Parallel()
+	for _, path := range []string{"/auth/refresh", "/auth/logout"} {
+		if !shouldSkipJWT(path) {
+			t.Fatalf("path=%q must authenticate with its refresh token", path)
+		}
+	}
+	for _, path := range []string{"/auth/refresh/extra", "/users/user-1/refresh-tokens"} {
+		if shouldSkipJWT(path) {
+			t.Fatalf("path=%q unexpectedly skips JWT", path)
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/memohai/memoh/internal/db/postgres/sqlc"
 	dbstore "github.com/memohai/memoh/internal/db/store"
 	netctl "github.com/memohai/memoh/internal/network"
+	"github.com/memohai/memoh/internal/persona"
 	tzutil "github.com/memohai/memoh/internal/timezone"
 )
 
@@ -125,6 +126,12 @@ func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest
 		current.ToolApprovalConfig = parseToolApprovalConfig(settingsRow.ToolApprovalConfig)
 		current.DisplayEnabled = settingsRow.DisplayEnabled
 		current.CommandUILanguage = settingsRow.CommandUiLanguage
+		current.InboundRateLimit = existingSettings.InboundRateLimit
+		current.InboundRateBurst = existingSettings.InboundRateBurst
+		current.WakeWordsEnabled = existingSettings.WakeWordsEnabled
+		current.WakeWords = existingSettings.WakeWords
+		current.WakeWordCooldownSeconds = existingSettings.WakeWordCooldownSeconds
+		current.Persona = existingSettings.Persona
 	}
 	current.OverlayEnabled = overlayBindingRow.OverlayEnabled
 	current.OverlayProvider = strings.TrimSpace(overlayBindingRow.OverlayProvider)
@@ -159,6 +166,24 @@ func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest
 	if req.CompactionRatio != nil && *req.CompactionRatio >= 1 && *req.CompactionRatio <= 100 {
 		current.CompactionRatio = *req.CompactionRatio
 	}
+	if req.InboundRateLimit != nil && *req.InboundRateLimit >= 0 && *req.InboundRateLimit <= MaxInboundRateLimit {
+		current.InboundRateLimit = *req.InboundRateLimit
+	}
+	if req.InboundRateBurst != nil && *req.InboundRateBurst >= 0 && *req.InboundRateBurst <= MaxInboundRateLimit {
+		current.InboundRateBurst = *req.InboundRateBurst
+	}
+	if req.WakeWordsEnabled != nil {
+		current.WakeWordsEnabled = *req.WakeWordsEnabled
+	}
+	if req.WakeWords != nil {
+		current.WakeWords = NormalizeWakeWords(req.WakeWords)
+	}
+	if req.WakeWordCooldownSeconds != nil && *req.WakeWordCooldownSeconds >= 0 && *req.WakeWordCooldownSeconds <= MaxWakeWordCooldown {
+		current.WakeWordCooldownSeconds = *req.WakeWordCooldownSeconds
+	}
+	if req.Persona != nil {
+		current.Persona = persona.Normalize(*req.Persona)
+	}
 	if req.PersistFullToolResults != nil {
 		current.PersistFullToolResults = *req.PersistFullToolResults
 	}
@@ -316,6 +341,10 @@ func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest
 	if err != nil {
 		return Settings{}, err
 	}
+	personaJSON, err := json.Marshal(persona.Normalize(current.Persona))
+	if err != nil {
+		return Settings{}, err
+	}
 
 	normalizedNetwork, err := s.normalizeOverlayConfig(current)
 	if err != nil {
@@ -345,40 +374,46 @@ func (s *Service) UpsertBot(ctx context.Context, botID string, req UpsertRequest
 		return Settings{}, rollbackNetworkChange(fmt.Errorf("marshal network config: %w", err))
 	}
 	updated, err := s.queries.UpsertBotSettings(ctx, sqlc.UpsertBotSettingsParams{
-		ID:                     pgID,
-		Timezone:               timezoneValue,
-		Language:               current.Language,
-		CommandUiLanguage:      current.CommandUILanguage,
-		ReasoningEnabled:       current.ReasoningEnabled,
-		ReasoningEffort:        current.ReasoningEffort,
-		HeartbeatEnabled:       current.HeartbeatEnabled,
-		HeartbeatInterval:      int32(current.HeartbeatInterval), //nolint:gosec // bounded by positive-only setter above
-		HeartbeatPrompt:        "",
-		CompactionEnabled:      current.CompactionEnabled,
-		CompactionThreshold:    int32(current.CompactionThreshold), //nolint:gosec // bounded by non-negative setter above
-		CompactionRatio:        int32(current.CompactionRatio),     //nolint:gosec // bounded 1-100 above
-		ChatModelID:            chatModelUUID,
-		ChatRuntime:            current.ChatRuntime,
-		ChatAcpAgentID:         nullableText(current.ChatACPAgentID),
-		ChatAcpProjectPath:     current.ChatACPProjectPath,
-		ChatAcpProjectMode:     current.ChatACPProjectMode,
-		HeartbeatModelID:       heartbeatModelUUID,
-		CompactionModelID:      compactionModelUUID,
-		ImageModelID:           imageModelUUID,
-		SearchProviderID:       searchProviderUUID,
-		FetchProviderIDSet:     fetchProviderIDSet,
-		FetchProviderID:        fetchProviderUUID,
-		MemoryProviderID:       memoryProviderUUID,
-		TtsModelID:             ttsModelUUID,
-		TranscriptionModelID:   transcriptionModelUUID,
-		VideoModelID:           videoModelUUID,
-		PersistFullToolResults: current.PersistFullToolResults,
-		ShowToolCallsInIm:      current.ShowToolCallsInIM,
-		ToolApprovalConfig:     toolApprovalConfig,
-		DisplayEnabled:         current.DisplayEnabled,
-		OverlayProvider:        normalizedNetwork.OverlayProvider,
-		OverlayEnabled:         normalizedNetwork.OverlayEnabled,
-		OverlayConfig:          overlayConfigJSON,
+		ID:                      pgID,
+		Timezone:                timezoneValue,
+		Language:                current.Language,
+		CommandUiLanguage:       current.CommandUILanguage,
+		ReasoningEnabled:        current.ReasoningEnabled,
+		ReasoningEffort:         current.ReasoningEffort,
+		HeartbeatEnabled:        current.HeartbeatEnabled,
+		HeartbeatInterval:       int32(current.HeartbeatInterval), //nolint:gosec // bounded by positive-only setter above
+		HeartbeatPrompt:         "",
+		CompactionEnabled:       current.CompactionEnabled,
+		CompactionThreshold:     int32(current.CompactionThreshold), //nolint:gosec // bounded by non-negative setter above
+		CompactionRatio:         int32(current.CompactionRatio),     //nolint:gosec // bounded 1-100 above
+		ChatModelID:             chatModelUUID,
+		ChatRuntime:             current.ChatRuntime,
+		ChatAcpAgentID:          nullableText(current.ChatACPAgentID),
+		ChatAcpProjectPath:      current.ChatACPProjectPath,
+		ChatAcpProjectMode:      current.ChatACPProjectMode,
+		HeartbeatModelID:        heartbeatModelUUID,
+		CompactionModelID:       compactionModelUUID,
+		ImageModelID:            imageModelUUID,
+		SearchProviderID:        searchProviderUUID,
+		FetchProviderIDSet:      fetchProviderIDSet,
+		FetchProviderID:         fetchProviderUUID,
+		MemoryProviderID:        memoryProviderUUID,
+		TtsModelID:              ttsModelUUID,
+		TranscriptionModelID:    transcriptionModelUUID,
+		VideoModelID:            videoModelUUID,
+		PersistFullToolResults:  current.PersistFullToolResults,
+		ShowToolCallsInIm:       current.ShowToolCallsInIM,
+		ToolApprovalConfig:      toolApprovalConfig,
+		DisplayEnabled:          current.DisplayEnabled,
+		OverlayProvider:         normalizedNetwork.OverlayProvider,
+		OverlayEnabled:          normalizedNetwork.OverlayEnabled,
+		OverlayConfig:           overlayConfigJSON,
+		InboundRateLimit:        int32(current.InboundRateLimit), //nolint:gosec // bounded by MaxInboundRateLimit above
+		InboundRateBurst:        int32(current.InboundRateBurst), //nolint:gosec // bounded by MaxInboundRateLimit above
+		WakeWordsEnabled:        current.WakeWordsEnabled,
+		WakeWords:               NormalizeWakeWords(current.WakeWords),
+		WakeWordCooldownSeconds: int32(current.WakeWordCooldownSeconds), //nolint:gosec // bounded by MaxWakeWordCooldown above
+		Persona:                 personaJSON,
 	})
 	if err != nil {
 		return Settings{}, rollbackNetworkChange(err)
@@ -493,6 +528,12 @@ func normalizeBotSettingsReadRow(row sqlc.GetSettingsByBotIDRow) Settings {
 		row.OverlayProvider,
 		row.OverlayEnabled,
 		row.OverlayConfig,
+		row.InboundRateLimit,
+		row.InboundRateBurst,
+		row.WakeWordsEnabled,
+		row.WakeWords,
+		row.WakeWordCooldownSeconds,
+		row.Persona,
 	)
 }
 
@@ -529,6 +570,12 @@ func normalizeBotSettingsWriteRow(row sqlc.UpsertBotSettingsRow) Settings {
 		row.OverlayProvider,
 		row.OverlayEnabled,
 		row.OverlayConfig,
+		row.InboundRateLimit,
+		row.InboundRateBurst,
+		row.WakeWordsEnabled,
+		row.WakeWords,
+		row.WakeWordCooldownSeconds,
+		row.Persona,
 	)
 }
 
@@ -564,6 +611,12 @@ func normalizeBotSettingsFields(
 	overlayProvider string,
 	overlayEnabled bool,
 	overlayConfig []byte,
+	inboundRateLimit int32,
+	inboundRateBurst int32,
+	wakeWordsEnabled bool,
+	wakeWords []string,
+	wakeWordCooldownSeconds int32,
+	personaConfig []byte,
 ) Settings {
 	settings := normalizeBotSetting(language, commandUILanguage, "", reasoningEnabled, reasoningEffort, heartbeatEnabled, heartbeatInterval, compactionEnabled, compactionThreshold, compactionRatio)
 	if timezone.Valid {
@@ -621,6 +674,12 @@ func normalizeBotSettingsFields(
 	settings.OverlayProvider = strings.TrimSpace(overlayProvider)
 	settings.OverlayEnabled = overlayEnabled
 	settings.OverlayConfig = normalizeJSONObject(overlayConfig)
+	settings.InboundRateLimit = int(inboundRateLimit)
+	settings.InboundRateBurst = int(inboundRateBurst)
+	settings.WakeWordsEnabled = wakeWordsEnabled
+	settings.WakeWords = NormalizeWakeWords(wakeWords)
+	settings.WakeWordCooldownSeconds = int(wakeWordCooldownSeconds)
+	settings.Persona = parsePersona(personaConfig)
 	return settings
 }
 
@@ -635,6 +694,14 @@ func parseToolApprovalConfig(raw []byte) ToolApprovalConfig {
 	return NormalizeToolApprovalConfig(cfg)
 }
 
+func parsePersona(raw []byte) persona.Persona {
+	var p persona.Persona
+	if len(raw) == 0 || json.Unmarshal(raw, &p) != nil {
+		return persona.Persona{}
+	}
+	return persona.Normalize(p)
+}
+
 func normalizeJSONObject(raw []byte) map[string]any {
 	if len(raw) == 0 {
 		return map[string]any{}
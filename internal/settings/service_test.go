@@ -2,12 +2,14 @@ package settings
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/jackc/pgx/v5/pgtype"
 
 	acpfeedback "github.com/memohai/memoh/internal/agent/decision/feedback"
 	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	"github.com/memohai/memoh/internal/persona"
 )
 
 func TestNormalizeBotSettingsReadRow_ShowToolCallsInIMDefault(t *testing.T) {
@@ -198,3 +200,41 @@ func TestReasoningEffortAllowsFullModelLadder(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeBotSettingsReadRow_WakeWords(t *testing.T) {
+	t.Parallel()
+
+	row := sqlc.GetSettingsByBotIDRow{
+		Language:                "en",
+		ReasoningEffort:         "medium",
+		HeartbeatInterval:       60,
+		CompactionRatio:         80,
+		WakeWordsEnabled:        true,
+		WakeWords:               []string{" 小梦 ", "Memo", "memo", "", strings.Repeat("x", MaxWakeWordLength+1)},
+		WakeWordCooldownSeconds: 30,
+	}
+	got := normalizeBotSettingsReadRow(row)
+	if !got.WakeWordsEnabled || got.WakeWordCooldownSeconds != 30 {
+		t.Fatalf("wake word settings = %+v", got)
+	}
+	if len(got.WakeWords) != 2 || got.WakeWords[0] != "小梦" || got.WakeWords[1] != "Memo" {
+		t.Fatalf("wake words = %q, want trimmed and deduplicated", got.WakeWords)
+	}
+}
+
+func TestNormalizeBotSettingsReadRow_Persona(t *testing.T) {
+	t.Parallel()
+
+	row := sqlc.GetSettingsByBotIDRow{
+		Language:          "en",
+		ReasoningEffort:   "medium",
+		HeartbeatInterval: 60,
+		CompactionRatio:   80,
+		Persona:           []byte(`{"preset":"Friendly","tone":"sarcastic","emoji":9,"formality":4}`),
+	}
+	got := normalizeBotSettingsReadRow(row)
+	want := persona.Persona{Preset: "friendly", Formality: 4}
+	if got.Persona != want {
+		t.Fatalf("persona = %+v, want %+v", got.Persona, want)
+	}
+}
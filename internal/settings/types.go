@@ -3,6 +3,8 @@ package settings
 import (
 	"encoding/json"
 	"strings"
+
+	"github.com/memohai/memoh/internal/persona"
 )
 
 const (
@@ -14,6 +16,14 @@ const (
 	ChatRuntimeACPAgent      = "acp_agent"
 	DefaultACPProjectPath    = "/data"
 	DefaultACPProjectMode    = "project"
+	// MaxInboundRateLimit caps inbound_rate_limit and inbound_rate_burst.
+	MaxInboundRateLimit = 10000
+	// MaxWakeWords caps how many wake words a bot keeps; MaxWakeWordLength
+	// caps each one, in characters.
+	MaxWakeWords      = 20
+	MaxWakeWordLength = 32
+	// MaxWakeWordCooldown caps wake_word_cooldown_seconds.
+	MaxWakeWordCooldown = 3600
 )
 
 type Settings struct {
@@ -50,6 +60,22 @@ type Settings struct {
 	OverlayEnabled         bool               `json:"overlay_enabled"`
 	OverlayProvider        string             `json:"overlay_provider,omitempty"`
 	OverlayConfig          map[string]any     `json:"overlay_config,omitempty"`
+	// InboundRateLimit is how many messages per minute one sender may send
+	// the bot from a channel; zero disables the limit.
+	InboundRateLimit int `json:"inbound_rate_limit"`
+	// InboundRateBurst is how many messages a sender may send at once before
+	// the per-minute rate applies; zero uses InboundRateLimit.
+	InboundRateBurst int `json:"inbound_rate_burst"`
+	// WakeWordsEnabled lets a group message that contains one of WakeWords
+	// trigger the bot like an @mention.
+	WakeWordsEnabled bool     `json:"wake_words_enabled"`
+	WakeWords        []string `json:"wake_words"`
+	// WakeWordCooldownSeconds is how long a conversation waits after a wake
+	// word triggered the bot before another one does; zero means no wait.
+	WakeWordCooldownSeconds int `json:"wake_word_cooldown_seconds"`
+	// Persona is the bot's persona preset and personality sliders; channel
+	// routes may override it.
+	Persona persona.Persona `json:"persona"`
 }
 
 type UpsertRequest struct {
@@ -86,6 +112,16 @@ type UpsertRequest struct {
 	OverlayEnabled         *bool               `json:"overlay_enabled,omitempty"`
 	OverlayProvider        *string             `json:"overlay_provider,omitempty"`
 	OverlayConfig          map[string]any      `json:"overlay_config,omitempty"`
+	InboundRateLimit       *int                `json:"inbound_rate_limit,omitempty"`
+	InboundRateBurst       *int                `json:"inbound_rate_burst,omitempty"`
+	WakeWordsEnabled       *bool               `json:"wake_words_enabled,omitempty"`
+	// WakeWords replaces the bot's wake words when set; send an empty list
+	// to clear them.
+	WakeWords               []string `json:"wake_words,omitempty"`
+	WakeWordCooldownSeconds *int     `json:"wake_word_cooldown_seconds,omitempty"`
+	// Persona replaces the bot's persona when set; send an empty object to
+	// clear it.
+	Persona *persona.Persona `json:"persona,omitempty"`
 }
 
 type ToolApprovalConfig struct {
@@ -303,6 +339,29 @@ func mergeStringLists(left, right []string) []string {
 	return out
 }
 
+// NormalizeWakeWords trims wake words and drops blanks, overlong words and
+// case-insensitive duplicates, keeping at most MaxWakeWords.
+func NormalizeWakeWords(words []string) []string {
+	out := make([]string, 0, len(words))
+	seen := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" || len([]rune(word)) > MaxWakeWordLength {
+			continue
+		}
+		key := strings.ToLower(word)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, word)
+		if len(out) == MaxWakeWords {
+			break
+		}
+	}
+	return out
+}
+
 func normalizeFilePolicy(policy, defaults ToolApprovalFilePolicy) ToolApprovalFilePolicy {
 	defaults.Mode = normalizeToolApprovalMode(policy.Mode)
 	defaults.RequireApproval = policy.RequireApproval
@@ -0,0 +1,100 @@
+package sharedstate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// memoryIdleExpiry is how long an untouched bucket is kept. A bucket left
+// alone this long has refilled for any limit of at least one token per
+// minute, so dropping it loses nothing.
+const memoryIdleExpiry = 10 * time.Minute
+
+// MemoryStore is the single-process Store.
+type MemoryStore struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	claims  map[string]time.Time
+	buckets map[string]*memoryBucket
+	swept   time.Time
+}
+
+type memoryBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewMemoryStore creates an in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		claims:  map[string]time.Time{},
+		buckets: map[string]*memoryBucket{},
+	}
+}
+
+func (s *MemoryStore) Claim(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 || key == "" {
+		return true, nil
+	}
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked(now)
+	if until, ok := s.claims[key]; ok && now.Before(until) {
+		return false, nil
+	}
+	s.claims[key] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.claims, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Allow(_ context.Context, key string, perMinute, burst int) (bool, error) {
+	if perMinute <= 0 {
+		return true, nil
+	}
+	burst = normalizeBurst(perMinute, burst)
+	key = bucketKey(key, perMinute, burst)
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked(now)
+	b := s.buckets[key]
+	if b == nil {
+		b = &memoryBucket{limiter: rate.NewLimiter(rate.Limit(float64(perMinute)/60), burst)}
+		s.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter.AllowN(now, 1), nil
+}
+
+func (*MemoryStore) Close() error { return nil }
+
+// sweepLocked drops expired claims and idle buckets, at most once a
+// minute.
+func (s *MemoryStore) sweepLocked(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+	s.swept = now
+	for k, until := range s.claims {
+		if !now.Before(until) {
+			delete(s.claims, k)
+		}
+	}
+	for k, b := range s.buckets {
+		if now.Sub(b.lastSeen) >= memoryIdleExpiry {
+			delete(s.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package sharedstate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+func TestMemoryStoreClaimExpiresAndReleases(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Unix(1_700_000_000, 0)
+	store.now = func() time.Time { return now }
+
+	if ok, _ := store.Claim(ctx, "k", time.Minute); !ok {
+		t.Fatal("first claim refused")
+	}
+	if ok, _ := store.Claim(ctx, "k", time.Minute); ok {
+		t.Fatal("second claim within ttl accepted")
+	}
+	now = now.Add(time.Minute)
+	if ok, _ := store.Claim(ctx, "k", time.Minute); !ok {
+		t.Fatal("claim after ttl refused")
+	}
+	_ = store.Release(ctx, "k")
+	if ok, _ := store.Claim(ctx, "k", time.Minute); !ok {
+		t.Fatal("claim after release refused")
+	}
+}
+
+func TestMemoryStoreAllowRefills(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Unix(1_700_000_000, 0)
+	store.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := store.Allow(ctx, "user:1", 60, 2); !ok {
+			t.Fatalf("request %d within burst refused", i+1)
+		}
+	}
+	if ok, _ := store.Allow(ctx, "user:1", 60, 2); ok {
+		t.Fatal("request over burst allowed")
+	}
+	if ok, _ := store.Allow(ctx, "user:2", 60, 2); !ok {
+		t.Fatal("other key shares the bucket")
+	}
+	now = now.Add(time.Second)
+	if ok, _ := store.Allow(ctx, "user:1", 60, 2); !ok {
+		t.Fatal("request after refill refused")
+	}
+}
+
+func TestNewWithoutRedisReturnsNil(t *testing.T) {
+	t.Parallel()
+
+	store, err := New(nil, config.RedisConfig{})
+	if err != nil || store != nil {
+		t.Fatalf("New() = %v, %v; want nil store", store, err)
+	}
+}
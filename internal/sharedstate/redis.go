@@ -0,0 +1,97 @@
+package sharedstate
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisOpTimeout bounds one Redis call. Shared state sits on the message
+// and request paths, so a slow server falls back to memory quickly.
+const redisOpTimeout = 500 * time.Millisecond
+
+// allowRedisBucketScript is a token bucket evaluated on the Redis clock so
+// replicas with skewed clocks share one refill rate. ARGV: tokens per
+// millisecond, burst, key TTL in milliseconds.
+var allowRedisBucketScript = redis.NewScript(`
+local now = redis.call('TIME')
+local now_ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if not tokens or not ts then
+  tokens = burst
+  ts = now_ms
+end
+tokens = math.min(burst, tokens + math.max(0, now_ms - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now_ms))
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return allowed
+`)
+
+// RedisStore is the Store shared by every replica through Redis or Valkey.
+type RedisStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisStore connects lazily to the Redis server at url.
+func NewRedisStore(url, keyPrefix string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(strings.TrimSpace(url))
+	if err != nil {
+		return nil, err
+	}
+	opts.ContextTimeoutEnabled = true
+	return &RedisStore{client: redis.NewClient(opts), keyPrefix: keyPrefix}, nil
+}
+
+func (s *RedisStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 || key == "" {
+		return true, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+	defer cancel()
+	return s.client.SetNX(ctx, s.keyPrefix+"claim:"+key, 1, ttl).Result()
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string) error {
+	if key == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), redisOpTimeout)
+	defer cancel()
+	return s.client.Del(ctx, s.keyPrefix+"claim:"+key).Err()
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string, perMinute, burst int) (bool, error) {
+	if perMinute <= 0 {
+		return true, nil
+	}
+	burst = normalizeBurst(perMinute, burst)
+	perMilli := float64(perMinute) / float64(time.Minute/time.Millisecond)
+	// Keep the bucket until it would have refilled completely.
+	ttl := time.Duration(float64(burst)/perMilli)*time.Millisecond + time.Second
+	ctx, cancel := context.WithTimeout(ctx, redisOpTimeout)
+	defer cancel()
+	allowed, err := allowRedisBucketScript.Run(ctx, s.client,
+		[]string{s.keyPrefix + "bucket:" + bucketKey(key, perMinute, burst)},
+		perMilli, burst, ttl.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return allowed == 1, nil
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,113 @@
+// Package sharedstate holds short-lived state that replicas of the server and
+// channel processes must agree on: duplicate-suppression windows and rate
+// limit buckets. Without Redis configured each process keeps that state in
+// its own memory, which is exact for a single replica.
+package sharedstate
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+// Store is short-lived shared state. Keys are namespaced by the caller.
+type Store interface {
+	// Claim sets key for ttl unless it is already set. It reports false when
+	// the key was still set, meaning the caller is a duplicate.
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release clears a claimed key so a retry is not treated as a duplicate.
+	Release(ctx context.Context, key string) error
+	// Allow takes one token from key's bucket, which refills at perMinute
+	// tokens per minute up to burst. Buckets of different limits are
+	// independent, so changing a limit starts a full bucket.
+	Allow(ctx context.Context, key string, perMinute, burst int) (bool, error)
+	Close() error
+}
+
+// New returns the Redis store when cfg names a server, or nil so callers
+// keep their own in-memory state. The Redis store falls back to memory while
+// Redis cannot be reached, so an outage loosens limits to per-process ones
+// instead of failing requests.
+func New(log *slog.Logger, cfg config.RedisConfig) (Store, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	redisStore, err := NewRedisStore(cfg.URL, cfg.KeyPrefixOrDefault())
+	if err != nil {
+		return nil, err
+	}
+	if log == nil {
+		log = slog.Default()
+	}
+	return &fallbackStore{
+		primary:  redisStore,
+		fallback: NewMemoryStore(),
+		logger:   log.With(slog.String("component", "sharedstate")),
+	}, nil
+}
+
+// fallbackWarnInterval bounds how often a Redis outage is logged.
+const fallbackWarnInterval = time.Minute
+
+type fallbackStore struct {
+	primary  Store
+	fallback Store
+	logger   *slog.Logger
+	warnedAt atomic.Int64
+}
+
+func (s *fallbackStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := s.primary.Claim(ctx, key, ttl)
+	if err != nil {
+		s.warn(err)
+		return s.fallback.Claim(ctx, key, ttl)
+	}
+	return ok, nil
+}
+
+func (s *fallbackStore) Release(ctx context.Context, key string) error {
+	_ = s.fallback.Release(ctx, key)
+	if err := s.primary.Release(ctx, key); err != nil {
+		s.warn(err)
+	}
+	return nil
+}
+
+func (s *fallbackStore) Allow(ctx context.Context, key string, perMinute, burst int) (bool, error) {
+	ok, err := s.primary.Allow(ctx, key, perMinute, burst)
+	if err != nil {
+		s.warn(err)
+		return s.fallback.Allow(ctx, key, perMinute, burst)
+	}
+	return ok, nil
+}
+
+func (s *fallbackStore) Close() error {
+	_ = s.fallback.Close()
+	return s.primary.Close()
+}
+
+func (s *fallbackStore) warn(err error) {
+	now := time.Now().UnixNano()
+	last := s.warnedAt.Load()
+	if now-last < int64(fallbackWarnInterval) || !s.warnedAt.CompareAndSwap(last, now) {
+		return
+	}
+	s.logger.Warn("redis unavailable, using in-memory state", slog.Any("error", err))
+}
+
+// bucketKey separates buckets of different limits.
+func bucketKey(key string, perMinute, burst int) string {
+	return key + ":" + strconv.Itoa(perMinute) + ":" + strconv.Itoa(burst)
+}
+
+func normalizeBurst(perMinute, burst int) int {
+	if burst <= 0 {
+		return perMinute
+	}
+	return burst
+}
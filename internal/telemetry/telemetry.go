@@ -0,0 +1,104 @@
+// Package telemetry sets up OpenTelemetry tracing. Components take their
+// tracer from Tracer and wrap outbound HTTP transports with Transport, so
+// with tracing off every span is a no-op and nothing is exported.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/memohai/memoh/internal/config"
+	"github.com/memohai/memoh/internal/version"
+)
+
+const instrumentationPrefix = "github.com/memohai/memoh/"
+
+// Setup installs the W3C trace context propagator and, when tracing is
+// enabled, a tracer provider exporting to the configured OTLP endpoint. The
+// returned function flushes and stops the exporter.
+func Setup(ctx context.Context, log *slog.Logger, cfg config.TelemetryConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	exporter, err := otlptracehttp.New(ctx, exporterOptions(cfg)...)
+	if err != nil {
+		return nil, err
+	}
+	version.EnsureBuildInfo()
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceNameOrDefault()),
+		semconv.ServiceVersion(version.Version),
+	)
+	sampler := sdktrace.ParentBased(sdktrace.AlwaysSample())
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	)
+	otel.SetTracerProvider(provider)
+	if log != nil {
+		log.Info("opentelemetry tracing enabled",
+			slog.String("endpoint", strings.TrimSpace(cfg.Endpoint)),
+			slog.String("service_name", cfg.ServiceNameOrDefault()),
+		)
+	}
+	return provider.Shutdown, nil
+}
+
+func exporterOptions(cfg config.TelemetryConfig) []otlptracehttp.Option {
+	endpoint := strings.TrimSpace(cfg.Endpoint)
+	var opts []otlptracehttp.Option
+	if strings.Contains(endpoint, "://") {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	} else {
+		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return opts
+}
+
+// Tracer returns the tracer for a component, named after its package path
+// below the module root (e.g. "channel/inbound").
+func Tracer(component string) trace.Tracer {
+	return otel.Tracer(instrumentationPrefix + strings.Trim(component, "/"))
+}
+
+// Transport wraps base so outbound requests get a client span and carry the
+// traceparent header. A nil base wraps http.DefaultTransport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
+
+// End records err on span, unless it is a cancellation, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,70 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/memohai/memoh/internal/config"
+)
+
+// These tests swap the global tracer provider and propagator, so they do not
+// run in parallel.
+
+func TestSetupDisabledIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), nil, config.TelemetryConfig{})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+}
+
+func TestSetupRejectsMissingEndpoint(t *testing.T) {
+	if _, err := Setup(context.Background(), nil, config.TelemetryConfig{Enabled: true}); err == nil {
+		t.Fatal("Setup() error = nil, want missing endpoint error")
+	}
+}
+
+func TestTransportPropagatesTraceParent(t *testing.T) {
+	if _, err := Setup(context.Background(), nil, config.TelemetryConfig{}); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	provider := sdktrace.NewTracerProvider()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	got := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got <- r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ctx, span := Tracer("test").Start(context.Background(), "parent")
+	defer span.End()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	header := <-got
+	traceID := span.SpanContext().TraceID().String()
+	if header == "" || len(header) < 35 || header[3:35] != traceID {
+		t.Fatalf("traceparent = %q, want trace id %s", header, traceID)
+	}
+}
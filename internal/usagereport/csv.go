@@ -0,0 +1,62 @@
+package usagereport
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteCSV writes the report in long form, one value per row, so it loads
+// into a spreadsheet without knowing the platforms, models or tools ahead.
+func WriteCSV(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	row := func(section, key, metric, value string) {
+		_ = cw.Write([]string{section, key, metric, value})
+	}
+	count := func(n int64) string { return strconv.FormatInt(n, 10) }
+
+	row("section", "key", "metric", "value")
+	row("report", "", "bot_id", r.BotID)
+	row("report", "", "bot_name", r.BotName)
+	row("report", "", "period", r.Period)
+	row("report", "", "from", r.From.UTC().Format(time.RFC3339))
+	row("report", "", "to", r.To.UTC().Format(time.RFC3339))
+	row("report", "", "generated_at", r.GeneratedAt.UTC().Format(time.RFC3339))
+
+	for _, p := range r.Platforms {
+		row("platform", p.Platform, "user_messages", count(p.UserMessages))
+		row("platform", p.Platform, "bot_messages", count(p.BotMessages))
+		row("platform", p.Platform, "active_users", count(p.ActiveUsers))
+	}
+	row("users", "", "active_users", count(r.ActiveUsers))
+
+	row("tokens", "", "input_tokens", count(r.Tokens.InputTokens))
+	row("tokens", "", "output_tokens", count(r.Tokens.OutputTokens))
+	row("tokens", "", "cache_read_tokens", count(r.Tokens.CacheReadTokens))
+	row("tokens", "", "reasoning_tokens", count(r.Tokens.ReasoningTokens))
+	for _, m := range r.Models {
+		key := modelLabel(m)
+		row("model", key, "input_tokens", count(m.InputTokens))
+		row("model", key, "output_tokens", count(m.OutputTokens))
+	}
+
+	for _, t := range r.Tools {
+		row("tool", t.Tool, "calls", count(t.Calls))
+	}
+
+	if r.Memory != nil {
+		row("memory", "", "added", strconv.Itoa(r.Memory.Added))
+		row("memory", "", "total", strconv.Itoa(r.Memory.Total))
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func modelLabel(m ModelUsage) string {
+	if m.Provider == "" {
+		return m.Model
+	}
+	return m.Provider + "/" + m.Model
+}
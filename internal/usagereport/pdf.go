@@ -0,0 +1,201 @@
+package usagereport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A4 in points, with the margin kept on every side.
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+)
+
+// WritePDF renders the report as a single-column PDF using the standard
+// Helvetica fonts, so no font files are embedded. Text outside Latin-1 is
+// replaced with '?'.
+func WritePDF(w io.Writer, r Report) error {
+	doc := &pdfDocument{}
+	doc.newPage()
+
+	title := "Usage report"
+	if name := strings.TrimSpace(r.BotName); name != "" {
+		title += ": " + name
+	}
+	doc.text(true, 18, []string{title}, nil)
+	period := "Period"
+	switch r.Period {
+	case PeriodWeekly:
+		period = "Week"
+	case PeriodMonthly:
+		period = "Month"
+	}
+	doc.text(false, 10, []string{fmt.Sprintf("%s of %s to %s (UTC)",
+		period,
+		r.From.UTC().Format(time.DateOnly),
+		r.To.UTC().AddDate(0, 0, -1).Format(time.DateOnly))}, nil)
+	doc.text(false, 10, []string{"Generated " + r.GeneratedAt.UTC().Format("2006-01-02 15:04") + " UTC"}, nil)
+
+	doc.section("Messages per platform")
+	cols := []float64{0, 200, 300, 400}
+	doc.text(true, 10, []string{"Platform", "User", "Bot", "Active users"}, cols)
+	if len(r.Platforms) == 0 {
+		doc.text(false, 10, []string{"No messages in this period."}, nil)
+	}
+	for _, p := range r.Platforms {
+		doc.text(false, 10, []string{p.Platform, groupDigits(p.UserMessages), groupDigits(p.BotMessages), groupDigits(p.ActiveUsers)}, cols)
+	}
+	doc.text(false, 10, []string{"Active users across platforms: " + groupDigits(r.ActiveUsers)}, nil)
+
+	doc.section("Tokens")
+	doc.text(false, 10, []string{"Input", groupDigits(r.Tokens.InputTokens)}, []float64{0, 200})
+	doc.text(false, 10, []string{"Output", groupDigits(r.Tokens.OutputTokens)}, []float64{0, 200})
+	doc.text(false, 10, []string{"Cache read", groupDigits(r.Tokens.CacheReadTokens)}, []float64{0, 200})
+	doc.text(false, 10, []string{"Reasoning", groupDigits(r.Tokens.ReasoningTokens)}, []float64{0, 200})
+	if len(r.Models) > 0 {
+		cols = []float64{0, 300, 400}
+		doc.space(6)
+		doc.text(true, 10, []string{"Model", "Input", "Output"}, cols)
+		for _, m := range r.Models {
+			doc.text(false, 10, []string{modelLabel(m), groupDigits(m.InputTokens), groupDigits(m.OutputTokens)}, cols)
+		}
+	}
+
+	doc.section("Top tools")
+	if len(r.Tools) == 0 {
+		doc.text(false, 10, []string{"No tool calls in this period."}, nil)
+	}
+	for _, t := range r.Tools {
+		doc.text(false, 10, []string{t.Tool, groupDigits(t.Calls)}, []float64{0, 300})
+	}
+
+	doc.section("Memory")
+	if r.Memory == nil {
+		doc.text(false, 10, []string{"Memory is not available for this bot."}, nil)
+	} else {
+		doc.text(false, 10, []string{"Added in period", strconv.Itoa(r.Memory.Added)}, []float64{0, 200})
+		doc.text(false, 10, []string{"Total at period end", strconv.Itoa(r.Memory.Total)}, []float64{0, 200})
+	}
+
+	_, err := w.Write(doc.bytes())
+	return err
+}
+
+// pdfDocument lays text out top to bottom, starting a new page when the
+// current one is full.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+func (d *pdfDocument) space(points float64) {
+	d.y -= points
+}
+
+func (d *pdfDocument) section(title string) {
+	d.space(10)
+	d.text(true, 13, []string{title}, nil)
+	d.space(2)
+}
+
+// text writes one line; cols are x offsets from the left margin for each
+// value, nil meaning a single value at the margin.
+func (d *pdfDocument) text(bold bool, size float64, values []string, cols []float64) {
+	lineHeight := size * 1.4
+	if d.y-lineHeight < pdfMargin {
+		d.newPage()
+	}
+	d.y -= lineHeight
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	page := d.pages[len(d.pages)-1]
+	for i, value := range values {
+		x := float64(pdfMargin)
+		if i < len(cols) {
+			x += cols[i]
+		}
+		fmt.Fprintf(page, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+			font, pdfNumber(size), pdfNumber(x), pdfNumber(d.y), pdfString(value))
+	}
+}
+
+func (d *pdfDocument) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	// Objects 1-4 are fixed; each page adds a page and a content object.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// pdfString escapes s for a literal string in WinAnsi encoding.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func pdfNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// groupDigits formats n with thousands separators, e.g. 1,234,567.
+func groupDigits(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return sign + s
+}
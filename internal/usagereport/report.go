@@ -0,0 +1,243 @@
+// Package usagereport builds downloadable per-bot usage reports for people
+// who follow a bot without using the dashboard: messages per platform,
+// active users, token usage, the most used tools and memory growth over a
+// week or a month, rendered as CSV or PDF.
+package usagereport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/memohai/memoh/internal/db"
+	"github.com/memohai/memoh/internal/db/postgres/sqlc"
+	dbstore "github.com/memohai/memoh/internal/db/store"
+)
+
+const (
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+
+	FormatCSV = "csv"
+	FormatPDF = "pdf"
+
+	topToolsLimit = 10
+)
+
+var (
+	ErrInvalidPeriod = errors.New("period must be weekly or monthly")
+	ErrInvalidFormat = errors.New("format must be csv or pdf")
+)
+
+type reportQueries interface {
+	GetUsageReportMessagesByPlatform(ctx context.Context, arg sqlc.GetUsageReportMessagesByPlatformParams) ([]sqlc.GetUsageReportMessagesByPlatformRow, error)
+	CountUsageReportActiveUsers(ctx context.Context, arg sqlc.CountUsageReportActiveUsersParams) (int64, error)
+	GetUsageReportToolCalls(ctx context.Context, arg sqlc.GetUsageReportToolCallsParams) ([]sqlc.GetUsageReportToolCallsRow, error)
+	GetTokenUsageByDayAndType(ctx context.Context, arg sqlc.GetTokenUsageByDayAndTypeParams) ([]sqlc.GetTokenUsageByDayAndTypeRow, error)
+	GetTokenUsageByModel(ctx context.Context, arg sqlc.GetTokenUsageByModelParams) ([]sqlc.GetTokenUsageByModelRow, error)
+}
+
+// Report is one bot's usage over [From, To).
+type Report struct {
+	BotID       string
+	BotName     string
+	Period      string
+	From        time.Time
+	To          time.Time
+	GeneratedAt time.Time
+	Platforms   []PlatformUsage
+	ActiveUsers int64
+	Tokens      TokenUsage
+	Models      []ModelUsage
+	Tools       []ToolUsage
+	// Memory is nil when the bot's memory provider could not be read.
+	Memory *MemoryGrowth
+}
+
+// PlatformUsage counts messages on one channel type. Bot messages count
+// every assistant step, including tool-calling ones.
+type PlatformUsage struct {
+	Platform     string
+	UserMessages int64
+	BotMessages  int64
+	ActiveUsers  int64
+}
+
+type TokenUsage struct {
+	InputTokens     int64
+	OutputTokens    int64
+	CacheReadTokens int64
+	ReasoningTokens int64
+}
+
+type ModelUsage struct {
+	Model        string
+	Provider     string
+	InputTokens  int64
+	OutputTokens int64
+}
+
+type ToolUsage struct {
+	Tool  string
+	Calls int64
+}
+
+// MemoryGrowth is the number of memories captured in the period and the
+// total at its end.
+type MemoryGrowth struct {
+	Added int
+	Total int
+}
+
+// PeriodRange returns the UTC bounds of the weekly (Monday to Monday) or
+// monthly period containing anchor.
+func PeriodRange(period string, anchor time.Time) (time.Time, time.Time, error) {
+	day := time.Date(anchor.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case PeriodMonthly:
+		from := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, 0), nil
+	case PeriodWeekly:
+		from := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return from, from.AddDate(0, 0, 7), nil
+	default:
+		return time.Time{}, time.Time{}, ErrInvalidPeriod
+	}
+}
+
+// LastCompletePeriod returns the bounds of the latest period that ended
+// before now.
+func LastCompletePeriod(period string, now time.Time) (time.Time, time.Time, error) {
+	from, _, err := PeriodRange(period, now.UTC())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return PeriodRange(period, from.AddDate(0, 0, -1))
+}
+
+// Service builds reports from the message history.
+type Service struct {
+	queries dbstore.Queries
+	logger  *slog.Logger
+}
+
+func NewService(log *slog.Logger, queries dbstore.Queries) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &Service{
+		queries: queries,
+		logger:  log.With(slog.String("service", "usage_report")),
+	}
+}
+
+func (s *Service) store() (reportQueries, error) {
+	if s == nil || s.queries == nil {
+		return nil, errors.New("usage report service not configured")
+	}
+	store, ok := s.queries.(reportQueries)
+	if !ok {
+		return nil, errors.New("usage report queries not supported by store")
+	}
+	return store, nil
+}
+
+// Build collects the bot's usage over [from, to). Memory growth is left to
+// the caller, which owns the memory providers.
+func (s *Service) Build(ctx context.Context, botID, period string, from, to time.Time) (Report, error) {
+	store, err := s.store()
+	if err != nil {
+		return Report{}, err
+	}
+	pgBotID, err := db.ParseUUID(strings.TrimSpace(botID))
+	if err != nil {
+		return Report{}, fmt.Errorf("invalid bot id: %w", err)
+	}
+	fromTS := pgtype.Timestamptz{Time: from, Valid: true}
+	toTS := pgtype.Timestamptz{Time: to, Valid: true}
+	report := Report{
+		BotID:       botID,
+		Period:      period,
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	platforms, err := store.GetUsageReportMessagesByPlatform(ctx, sqlc.GetUsageReportMessagesByPlatformParams{BotID: pgBotID, FromTime: fromTS, ToTime: toTS})
+	if err != nil {
+		return Report{}, fmt.Errorf("count messages by platform: %w", err)
+	}
+	for _, row := range platforms {
+		report.Platforms = append(report.Platforms, PlatformUsage{
+			Platform:     row.Platform,
+			UserMessages: row.UserMessages,
+			BotMessages:  row.BotMessages,
+			ActiveUsers:  row.ActiveUsers,
+		})
+	}
+	report.ActiveUsers, err = store.CountUsageReportActiveUsers(ctx, sqlc.CountUsageReportActiveUsersParams{BotID: pgBotID, FromTime: fromTS, ToTime: toTS})
+	if err != nil {
+		return Report{}, fmt.Errorf("count active users: %w", err)
+	}
+
+	days, err := store.GetTokenUsageByDayAndType(ctx, sqlc.GetTokenUsageByDayAndTypeParams{BotID: pgBotID, FromTime: fromTS, ToTime: toTS})
+	if err != nil {
+		return Report{}, fmt.Errorf("sum token usage: %w", err)
+	}
+	for _, row := range days {
+		report.Tokens.InputTokens += row.InputTokens
+		report.Tokens.OutputTokens += row.OutputTokens
+		report.Tokens.CacheReadTokens += row.CacheReadTokens
+		report.Tokens.ReasoningTokens += row.ReasoningTokens
+	}
+	models, err := store.GetTokenUsageByModel(ctx, sqlc.GetTokenUsageByModelParams{BotID: pgBotID, FromTime: fromTS, ToTime: toTS})
+	if err != nil {
+		return Report{}, fmt.Errorf("sum token usage by model: %w", err)
+	}
+	for _, row := range models {
+		report.Models = append(report.Models, ModelUsage{
+			Model:        row.ModelName,
+			Provider:     row.ProviderName,
+			InputTokens:  row.InputTokens,
+			OutputTokens: row.OutputTokens,
+		})
+	}
+
+	tools, err := store.GetUsageReportToolCalls(ctx, sqlc.GetUsageReportToolCallsParams{BotID: pgBotID, FromTime: fromTS, ToTime: toTS, RowLimit: topToolsLimit})
+	if err != nil {
+		return Report{}, fmt.Errorf("count tool calls: %w", err)
+	}
+	for _, row := range tools {
+		report.Tools = append(report.Tools, ToolUsage{Tool: row.ToolName, Calls: row.Calls})
+	}
+	return report, nil
+}
+
+// FileName is the suggested download name, e.g.
+// "support-bot-monthly-2026-09.csv".
+func (r Report) FileName(format string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, strings.TrimSpace(r.BotName))
+	name = strings.Trim(name, "-")
+	if name == "" {
+		name = "bot"
+	}
+	stamp := r.From.Format(time.DateOnly)
+	if r.Period == PeriodMonthly {
+		stamp = r.From.Format("2006-01")
+	}
+	return fmt.Sprintf("%s-%s-%s.%s", name, r.Period, stamp, format)
+}
@@ -0,0 +1,162 @@
+package usagereport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLastCompletePeriod(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC) // a Friday
+	cases := []struct {
+		period   string
+		from, to string
+	}{
+		{PeriodMonthly, "2026-09-01", "2026-10-01"},
+		{PeriodWeekly, "2026-10-05", "2026-10-12"},
+	}
+	for _, tc := range cases {
+		from, to, err := LastCompletePeriod(tc.period, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.period, err)
+		}
+		if got := from.Format(time.DateOnly); got != tc.from {
+			t.Fatalf("%s from = %s, want %s", tc.period, got, tc.from)
+		}
+		if got := to.Format(time.DateOnly); got != tc.to {
+			t.Fatalf("%s to = %s, want %s", tc.period, got, tc.to)
+		}
+	}
+	if _, _, err := LastCompletePeriod("daily", now); err != ErrInvalidPeriod {
+		t.Fatalf("daily: err = %v, want ErrInvalidPeriod", err)
+	}
+}
+
+func TestPeriodRangeWeekStartsOnMonday(t *testing.T) {
+	t.Parallel()
+
+	sunday := time.Date(2026, 10, 11, 23, 0, 0, 0, time.UTC)
+	from, to, err := PeriodRange(PeriodWeekly, sunday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from.Format(time.DateOnly) != "2026-10-05" || to.Format(time.DateOnly) != "2026-10-12" {
+		t.Fatalf("range = %s..%s, want 2026-10-05..2026-10-12", from, to)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	t.Parallel()
+
+	r := Report{BotName: "Support Bot!", Period: PeriodMonthly, From: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)}
+	if got := r.FileName(FormatCSV); got != "support-bot-monthly-2026-09.csv" {
+		t.Fatalf("FileName = %q", got)
+	}
+	r = Report{BotName: "客服", Period: PeriodWeekly, From: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)}
+	if got := r.FileName(FormatPDF); got != "bot-weekly-2026-10-05.pdf" {
+		t.Fatalf("FileName = %q", got)
+	}
+}
+
+func sampleReport() Report {
+	return Report{
+		BotID:       "bot-1",
+		BotName:     "Support (EU)",
+		Period:      PeriodMonthly,
+		From:        time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		GeneratedAt: time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC),
+		Platforms: []PlatformUsage{
+			{Platform: "telegram", UserMessages: 1200, BotMessages: 1500, ActiveUsers: 40},
+			{Platform: "local", UserMessages: 30, BotMessages: 31, ActiveUsers: 2},
+		},
+		ActiveUsers: 41,
+		Tokens:      TokenUsage{InputTokens: 1234567, OutputTokens: 89000},
+		Models:      []ModelUsage{{Model: "gpt-4o", Provider: "openai", InputTokens: 1234567, OutputTokens: 89000}},
+		Tools:       []ToolUsage{{Tool: "web_search", Calls: 77}},
+		Memory:      &MemoryGrowth{Added: 12, Total: 140},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, sampleReport()); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("report is not valid CSV: %v", err)
+	}
+	values := map[string]string{}
+	for _, row := range rows[1:] {
+		values[strings.Join(row[:3], "|")] = row[3]
+	}
+	for key, want := range map[string]string{
+		"platform|telegram|user_messages":   "1200",
+		"users||active_users":               "41",
+		"tokens||input_tokens":              "1234567",
+		"model|openai/gpt-4o|output_tokens": "89000",
+		"tool|web_search|calls":             "77",
+		"memory||added":                     "12",
+	} {
+		if values[key] != want {
+			t.Fatalf("%s = %q, want %q", key, values[key], want)
+		}
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	t.Parallel()
+
+	r := sampleReport()
+	r.Memory = nil
+	for i := 0; i < 80; i++ {
+		r.Tools = append(r.Tools, ToolUsage{Tool: "tool_" + strconv.Itoa(i), Calls: 1})
+	}
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	if !bytes.Contains(out, []byte(`(Usage report: Support \(EU\)) Tj`)) {
+		t.Fatal("title not escaped")
+	}
+	if !bytes.Contains(out, []byte("(1,234,567) Tj")) {
+		t.Fatal("token count not formatted")
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Fatal("long report should span two pages")
+	}
+
+	// Every xref entry must point at its object.
+	start, err := strconv.Atoi(string(regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[start:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj"; !bytes.HasPrefix(out[offset:], []byte(want)) {
+			t.Fatalf("xref entry %d does not point at %q", i+1, want)
+		}
+	}
+}
+
+func TestPDFStringEncodesLatin1(t *testing.T) {
+	t.Parallel()
+
+	if got := pdfString("café 客"); got != "caf\xe9 ?" {
+		t.Fatalf("pdfString = %q", got)
+	}
+}
@@ -0,0 +1,295 @@
+package voice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+)
+
+const (
+	// connectTimeout hangs up calls whose media never connects.
+	connectTimeout = 30 * time.Second
+	// maxSpeakingQueue bounds the sentences waiting to be synthesized.
+	maxSpeakingQueue = 16
+)
+
+// call is one caller's WebRTC session with a bot.
+type call struct {
+	service *Service
+	id      string
+	botID   string
+	userID  string
+
+	pc    *webrtc.PeerConnection
+	track *webrtc.TrackLocalStaticSample
+
+	ttsModelID      string
+	sttModelID      string
+	synthesisConfig map[string]any
+
+	ctx        context.Context
+	cancel     context.CancelFunc
+	createdAt  time.Time
+	utterances chan []int16
+
+	mu          sync.Mutex
+	state       string
+	events      *webrtc.DataChannel
+	replyCancel context.CancelFunc
+	closeOnce   sync.Once
+}
+
+// callEvent is sent to the client on the events data channel.
+type callEvent struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+const (
+	eventTranscript  = "transcript"
+	eventReply       = "reply"
+	eventInterrupted = "interrupted"
+	eventError       = "error"
+)
+
+func newCall(s *Service, id, botID, userID string, pc *webrtc.PeerConnection, track *webrtc.TrackLocalStaticSample) *call {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &call{
+		service:    s,
+		id:         id,
+		botID:      botID,
+		userID:     userID,
+		pc:         pc,
+		track:      track,
+		ctx:        ctx,
+		cancel:     cancel,
+		createdAt:  time.Now(),
+		utterances: make(chan []int16, 1),
+		state:      webrtc.PeerConnectionStateNew.String(),
+	}
+	pc.OnTrack(func(remote *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+		if remote.Kind() != webrtc.RTPCodecTypeAudio {
+			return
+		}
+		go c.listen(remote)
+	})
+	pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+		if dc.Label() != EventsChannelLabel {
+			return
+		}
+		c.mu.Lock()
+		c.events = dc
+		c.mu.Unlock()
+	})
+	pc.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		c.mu.Lock()
+		c.state = state.String()
+		c.mu.Unlock()
+		switch state {
+		case webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateDisconnected, webrtc.PeerConnectionStateClosed:
+			c.close()
+		default:
+		}
+	})
+	return c
+}
+
+func (c *call) info() SessionInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return SessionInfo{ID: c.id, BotID: c.botID, UserID: c.userID, State: c.state, CreatedAt: c.createdAt}
+}
+
+func (c *call) close() {
+	c.closeOnce.Do(func() {
+		c.cancel()
+		c.service.removeCall(c)
+		c.service.logger.Info("voice call ended", slog.String("bot_id", c.botID), slog.String("session_id", c.id))
+	})
+	// Closing the peer connection reports the closed state back through
+	// close, so it runs after the once.
+	_ = c.pc.Close()
+}
+
+// run answers utterances one at a time until the call ends.
+func (c *call) run() {
+	timer := time.AfterFunc(connectTimeout, func() {
+		c.mu.Lock()
+		connected := c.state == webrtc.PeerConnectionStateConnected.String()
+		c.mu.Unlock()
+		if !connected {
+			c.close()
+		}
+	})
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case utterance := <-c.utterances:
+			c.respond(utterance)
+		}
+	}
+}
+
+// listen reads caller audio and segments it into utterances. Speech that
+// starts while the bot is replying interrupts the reply.
+func (c *call) listen(remote *webrtc.TrackRemote) {
+	vad := newEndpointer()
+	for {
+		packet, _, err := remote.ReadRTP()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && c.ctx.Err() == nil {
+				c.service.logger.Debug("voice call audio read failed", slog.String("session_id", c.id), slog.Any("error", err))
+			}
+			return
+		}
+		event, utterance := vad.push(decodePCMU(packet.Payload))
+		switch event {
+		case vadSpeechStart:
+			if c.interrupt() {
+				c.send(callEvent{Type: eventInterrupted})
+			}
+		case vadUtteranceEnd:
+			// Only the latest utterance matters if the caller keeps talking
+			// while the previous one is being transcribed.
+			select {
+			case <-c.utterances:
+			default:
+			}
+			c.utterances <- utterance
+		default:
+		}
+	}
+}
+
+// interrupt cancels the reply in progress, if any.
+func (c *call) interrupt() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.replyCancel == nil {
+		return false
+	}
+	c.replyCancel()
+	c.replyCancel = nil
+	return true
+}
+
+func (c *call) respond(utterance []int16) {
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.mu.Lock()
+	c.replyCancel = cancel
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.replyCancel = nil
+		c.mu.Unlock()
+		cancel()
+	}()
+
+	result, err := c.service.speech.Transcribe(ctx, c.sttModelID, encodeWAV(utterance, sampleRate), "utterance.wav", "audio/wav", nil)
+	if err != nil {
+		c.fail(ctx, "transcribe utterance", err)
+		return
+	}
+	text := strings.TrimSpace(result.Text)
+	if text == "" {
+		return
+	}
+	c.send(callEvent{Type: eventTranscript, Text: text})
+
+	sentences := make(chan string, maxSpeakingQueue)
+	spoken := make(chan struct{})
+	go func() {
+		defer close(spoken)
+		for sentence := range sentences {
+			if ctx.Err() != nil {
+				continue
+			}
+			c.send(callEvent{Type: eventReply, Text: sentence})
+			c.speak(ctx, sentence)
+		}
+	}()
+	var buffer sentenceBuffer
+	err = c.service.replier.StreamReply(ctx, c.botID, c.userID, text, func(delta string) {
+		for _, sentence := range buffer.write(delta) {
+			select {
+			case sentences <- sentence:
+			case <-ctx.Done():
+			}
+		}
+	})
+	if rest := buffer.flush(); rest != "" && err == nil {
+		sentences <- rest
+	}
+	close(sentences)
+	<-spoken
+	if err != nil {
+		c.fail(ctx, "reply to utterance", err)
+	}
+}
+
+// speak synthesizes a sentence and plays it in real time so an interruption
+// cuts it off promptly.
+func (c *call) speak(ctx context.Context, sentence string) {
+	audioBytes, _, err := c.service.speech.Synthesize(ctx, c.ttsModelID, sentence, c.synthesisConfig)
+	if err != nil {
+		c.fail(ctx, "synthesize reply", err)
+		return
+	}
+	samples, rate, err := decodeWAV(audioBytes)
+	if err != nil {
+		c.service.logger.Warn("voice reply audio is not playable",
+			slog.String("bot_id", c.botID),
+			slog.String("tts_model_id", c.ttsModelID),
+			slog.Any("error", err))
+		return
+	}
+	samples = resample(samples, rate, sampleRate)
+	ticker := time.NewTicker(frameDuration * time.Millisecond)
+	defer ticker.Stop()
+	for start := 0; start < len(samples); start += samplesPerFrame {
+		frame := samples[start:min(start+samplesPerFrame, len(samples))]
+		if err := c.track.WriteSample(media.Sample{Data: encodePCMU(frame), Duration: frameDuration * time.Millisecond}); err != nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *call) fail(ctx context.Context, action string, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	c.service.logger.Warn("voice call "+action+" failed",
+		slog.String("bot_id", c.botID),
+		slog.String("session_id", c.id),
+		slog.Any("error", err))
+	c.send(callEvent{Type: eventError, Text: action + " failed"})
+}
+
+func (c *call) send(event callEvent) {
+	c.mu.Lock()
+	dc := c.events
+	c.mu.Unlock()
+	if dc == nil || dc.ReadyState() != webrtc.DataChannelStateOpen {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_ = dc.SendText(string(payload))
+}
@@ -0,0 +1,172 @@
+package voice
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Calls use G.711 μ-law (PCMU). Every WebRTC stack supports it and it needs
+// no native codec library, at the cost of narrowband (8 kHz) audio.
+const (
+	sampleRate      = 8000
+	frameDuration   = 20 // milliseconds
+	samplesPerFrame = sampleRate * frameDuration / 1000
+)
+
+var errUnsupportedWAV = errors.New("unsupported wav audio")
+
+func mulawDecode(b byte) int16 {
+	b = ^b
+	exponent := (b >> 4) & 0x07
+	sample := ((int32(b&0x0F) << 3) + 0x84) << exponent
+	sample -= 0x84
+	if b&0x80 != 0 {
+		return int16(-sample)
+	}
+	return int16(sample)
+}
+
+func mulawEncode(s int16) byte {
+	const (
+		bias = 0x84
+		clip = 32635
+	)
+	sample := int32(s)
+	var sign byte
+	if sample < 0 {
+		sample = -sample
+		sign = 0x80
+	}
+	sample = min(sample, clip) + bias
+	exponent := byte(7)
+	for mask := int32(0x4000); sample&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := byte(sample>>(exponent+3)) & 0x0F
+	return ^(sign | exponent<<4 | mantissa)
+}
+
+func decodePCMU(payload []byte) []int16 {
+	out := make([]int16, len(payload))
+	for i, b := range payload {
+		out[i] = mulawDecode(b)
+	}
+	return out
+}
+
+func encodePCMU(samples []int16) []byte {
+	out := make([]byte, len(samples))
+	for i, s := range samples {
+		out[i] = mulawEncode(s)
+	}
+	return out
+}
+
+// encodeWAV wraps mono 16-bit samples in a WAV container for transcription.
+func encodeWAV(samples []int16, rate int) []byte {
+	dataSize := len(samples) * 2
+	out := make([]byte, 44+dataSize)
+	copy(out[0:], "RIFF")
+	binary.LittleEndian.PutUint32(out[4:], uint32(36+dataSize))
+	copy(out[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(out[16:], 16)
+	binary.LittleEndian.PutUint16(out[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(out[22:], 1) // mono
+	binary.LittleEndian.PutUint32(out[24:], uint32(rate))
+	binary.LittleEndian.PutUint32(out[28:], uint32(rate*2))
+	binary.LittleEndian.PutUint16(out[32:], 2)
+	binary.LittleEndian.PutUint16(out[34:], 16)
+	copy(out[36:], "data")
+	binary.LittleEndian.PutUint32(out[40:], uint32(dataSize))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(out[44+i*2:], uint16(s))
+	}
+	return out
+}
+
+// decodeWAV reads 16-bit PCM WAV audio, mixed down to mono. Streaming
+// encoders leave the data size unset, so the data chunk is read to the end
+// when its size overruns the buffer.
+func decodeWAV(data []byte) ([]int16, int, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, fmt.Errorf("%w: not a RIFF/WAVE file", errUnsupportedWAV)
+	}
+	var (
+		channels, bits int
+		rate           int
+		haveFormat     bool
+	)
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := data[pos+8:]
+		if size <= len(body) {
+			body = body[:size]
+		}
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return nil, 0, fmt.Errorf("%w: short fmt chunk", errUnsupportedWAV)
+			}
+			format := binary.LittleEndian.Uint16(body[0:2])
+			if format != 1 && format != 0xFFFE {
+				return nil, 0, fmt.Errorf("%w: format %d is not PCM", errUnsupportedWAV, format)
+			}
+			channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			rate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bits = int(binary.LittleEndian.Uint16(body[14:16]))
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, 0, fmt.Errorf("%w: data before fmt chunk", errUnsupportedWAV)
+			}
+			if bits != 16 || channels < 1 || rate <= 0 {
+				return nil, 0, fmt.Errorf("%w: %d-bit %d-channel %d Hz", errUnsupportedWAV, bits, channels, rate)
+			}
+			frames := len(body) / (2 * channels)
+			out := make([]int16, frames)
+			for i := range frames {
+				var sum int32
+				for ch := range channels {
+					sum += int32(int16(binary.LittleEndian.Uint16(body[(i*channels+ch)*2:])))
+				}
+				out[i] = int16(sum / int32(channels))
+			}
+			return out, rate, nil
+		}
+		pos += 8 + size + size%2
+	}
+	return nil, 0, fmt.Errorf("%w: no data chunk", errUnsupportedWAV)
+}
+
+// resample converts mono audio between sample rates. Downsampling averages
+// the source samples each output sample covers, which is enough filtering
+// for speech.
+func resample(samples []int16, from, to int) []int16 {
+	if from == to || len(samples) == 0 {
+		return samples
+	}
+	n := int(int64(len(samples)) * int64(to) / int64(from))
+	out := make([]int16, n)
+	for i := range n {
+		start := int(int64(i) * int64(from) / int64(to))
+		if from > to {
+			end := min(int(int64(i+1)*int64(from)/int64(to)), len(samples))
+			var sum int32
+			for _, s := range samples[start:end] {
+				sum += int32(s)
+			}
+			if end > start {
+				out[i] = int16(sum / int32(end-start))
+			}
+			continue
+		}
+		// Upsampling interpolates linearly between neighbours.
+		pos := float64(i) * float64(from) / float64(to)
+		frac := pos - float64(start)
+		next := min(start+1, len(samples)-1)
+		out[i] = int16(float64(samples[start])*(1-frac) + float64(samples[next])*frac)
+	}
+	return out
+}
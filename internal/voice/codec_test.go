@@ -0,0 +1,166 @@
+package voice
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMulawRoundTrip(t *testing.T) {
+	for _, s := range []int16{0, 1, -1, 100, -100, 1000, -1000, 12345, -12345, 32767, -32768} {
+		got := mulawDecode(mulawEncode(s))
+		// μ-law keeps roughly 4 bits of mantissa per segment.
+		tolerance := math.Max(8, math.Abs(float64(s))/16)
+		if math.Abs(float64(got)-float64(s)) > tolerance {
+			t.Errorf("round trip of %d = %d, want within %.0f", s, got, tolerance)
+		}
+	}
+	// Every code word decodes to a value that encodes back to itself, apart
+	// from the duplicate zero.
+	for b := range 256 {
+		if b == 0x7F {
+			continue
+		}
+		if got := mulawEncode(mulawDecode(byte(b))); got != byte(b) {
+			t.Errorf("code %#x re-encoded as %#x", b, got)
+		}
+	}
+}
+
+func TestWAVRoundTrip(t *testing.T) {
+	samples := []int16{0, 1000, -1000, 32767, -32768}
+	got, rate, err := decodeWAV(encodeWAV(samples, 16000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != 16000 || !reflect.DeepEqual(got, samples) {
+		t.Fatalf("decodeWAV = %v @ %d, want %v @ 16000", got, rate, samples)
+	}
+}
+
+func TestDecodeWAVStreamingSizeAndStereo(t *testing.T) {
+	data := encodeWAV([]int16{100, 300, -200, -400}, 24000)
+	// Two channels, so the four samples are two frames.
+	data[22] = 2
+	// Streaming encoders write 0xFFFFFFFF as the data size.
+	copy(data[40:44], []byte{0xFF, 0xFF, 0xFF, 0xFF})
+	got, rate, err := decodeWAV(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != 24000 || !reflect.DeepEqual(got, []int16{200, -300}) {
+		t.Fatalf("decodeWAV = %v @ %d", got, rate)
+	}
+}
+
+func TestDecodeWAVRejectsOtherAudio(t *testing.T) {
+	if _, _, err := decodeWAV([]byte("ID3\x04mp3 frames")); !errors.Is(err, errUnsupportedWAV) {
+		t.Fatalf("mp3 err = %v", err)
+	}
+	data := encodeWAV([]int16{1, 2}, 8000)
+	data[20] = 3 // IEEE float
+	if _, _, err := decodeWAV(data); !errors.Is(err, errUnsupportedWAV) {
+		t.Fatalf("float err = %v", err)
+	}
+}
+
+func TestResample(t *testing.T) {
+	in := make([]int16, 2400)
+	for i := range in {
+		in[i] = int16(i % 3 * 100)
+	}
+	down := resample(in, 24000, 8000)
+	if len(down) != 800 {
+		t.Fatalf("len = %d, want 800", len(down))
+	}
+	for i, s := range down {
+		if s != 100 {
+			t.Fatalf("down[%d] = %d, want averaged 100", i, s)
+		}
+	}
+	up := resample([]int16{0, 100}, 8000, 16000)
+	if !reflect.DeepEqual(up, []int16{0, 50, 100, 100}) {
+		t.Fatalf("up = %v", up)
+	}
+}
+
+func tone(n int, amplitude float64) []int16 {
+	out := make([]int16, n)
+	for i := range out {
+		out[i] = int16(amplitude * math.Sin(2*math.Pi*440*float64(i)/sampleRate))
+	}
+	return out
+}
+
+func TestEndpointerSegmentsUtterance(t *testing.T) {
+	vad := newEndpointer()
+	feed := func(frames int, amplitude float64) (starts int, utterances [][]int16) {
+		for range frames {
+			switch event, utterance := vad.push(tone(samplesPerFrame, amplitude)); event {
+			case vadSpeechStart:
+				starts++
+			case vadUtteranceEnd:
+				utterances = append(utterances, utterance)
+			default:
+			}
+		}
+		return starts, utterances
+	}
+
+	if starts, utts := feed(50, 50); starts != 0 || len(utts) != 0 {
+		t.Fatalf("background noise: starts=%d utterances=%d", starts, len(utts))
+	}
+	// A short click is not speech.
+	if starts, _ := feed(3, 8000); starts != 0 {
+		t.Fatal("click counted as speech")
+	}
+	feed(20, 50)
+	starts, utts := feed(50, 8000)
+	if starts != 1 || len(utts) != 0 {
+		t.Fatalf("speech: starts=%d utterances=%d", starts, len(utts))
+	}
+	starts, utts = feed(40, 50)
+	if starts != 0 || len(utts) != 1 {
+		t.Fatalf("pause: starts=%d utterances=%d", starts, len(utts))
+	}
+	// The utterance keeps the preroll before speech was detected.
+	if minLen := (50 + 35) * samplesPerFrame; len(utts[0]) < minLen {
+		t.Fatalf("utterance has %d samples, want at least %d", len(utts[0]), minLen)
+	}
+}
+
+func TestEndpointerCutsOffLongUtterances(t *testing.T) {
+	vad := newEndpointer()
+	ends := 0
+	for range maxUtteranceSamples/samplesPerFrame + 20 {
+		if event, _ := vad.push(tone(samplesPerFrame, 8000)); event == vadUtteranceEnd {
+			ends++
+		}
+	}
+	if ends != 1 {
+		t.Fatalf("ends = %d, want 1", ends)
+	}
+}
+
+func TestSentenceBuffer(t *testing.T) {
+	var b sentenceBuffer
+	var got []string
+	for _, delta := range []string{"Sure, I can help with that", ". The meeting is at **3pm**", " tomorrow! Dr", ". Smith will", " join.\n- Bring notes", "\n你好。再见"} {
+		got = append(got, b.write(delta)...)
+	}
+	if rest := b.flush(); rest != "" {
+		got = append(got, rest)
+	}
+	want := []string{
+		"Sure, I can help with that.",
+		"The meeting is at 3pm tomorrow!",
+		"Dr. Smith will join.",
+		"Bring notes",
+		"你好。",
+		"再见",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sentences = %q\nwant %q", got, want)
+	}
+}
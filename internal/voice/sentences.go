@@ -0,0 +1,69 @@
+package voice
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minSentenceRunes keeps abbreviations and list numbers ("1.") from being
+// spoken as sentences of their own.
+const minSentenceRunes = 12
+
+// sentenceBuffer groups streamed reply text into sentences so speech can
+// start before the whole reply has been generated.
+type sentenceBuffer struct {
+	pending []rune
+}
+
+// write adds a text delta and returns the sentences it completed.
+func (b *sentenceBuffer) write(delta string) []string {
+	var out []string
+	for _, r := range delta {
+		b.pending = append(b.pending, r)
+		if !endsSentence(b.pending) {
+			continue
+		}
+		if text := speakable(string(b.pending)); text != "" {
+			out = append(out, text)
+		}
+		b.pending = b.pending[:0]
+	}
+	return out
+}
+
+// flush returns whatever text remains at the end of the reply.
+func (b *sentenceBuffer) flush() string {
+	text := speakable(string(b.pending))
+	b.pending = b.pending[:0]
+	return text
+}
+
+func endsSentence(text []rune) bool {
+	n := len(text)
+	last := text[n-1]
+	switch last {
+	case '\n':
+		return true
+	case '。', '！', '？', '；':
+		return true
+	}
+	// Latin punctuation only ends a sentence once whitespace follows it.
+	if n < 2 || !unicode.IsSpace(last) || n < minSentenceRunes {
+		return false
+	}
+	switch text[n-2] {
+	case '.', '!', '?', ';', ':':
+		return true
+	}
+	return false
+}
+
+var markdownMarkers = strings.NewReplacer("**", "", "__", "", "`", "", "~~", "", "#", "", "> ", "")
+
+// speakable strips markdown a speech model would read aloud.
+func speakable(text string) string {
+	text = markdownMarkers.Replace(text)
+	text = strings.TrimSpace(text)
+	text = strings.TrimLeft(text, "-*• ")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,291 @@
+// Package voice implements experimental voice calls with bots. A caller's
+// browser connects over WebRTC; each utterance is transcribed, sent to the
+// bot as a chat turn, and the reply is synthesized and streamed back
+// sentence by sentence. Talking over the bot interrupts it.
+package voice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	sdk "github.com/memohai/twilight-ai/sdk"
+	"github.com/pion/webrtc/v4"
+
+	"github.com/memohai/memoh/internal/audio"
+	"github.com/memohai/memoh/internal/settings"
+)
+
+const (
+	// CurrentChannel tells the agent a turn came from a voice call.
+	CurrentChannel = "voice"
+	// EventsChannelLabel is the data channel the client opens to receive
+	// transcripts and reply text.
+	EventsChannelLabel = "voice-events"
+
+	natIPsEnv      = "MEMOH_VOICE_WEBRTC_NAT_IPS"
+	maxCallsPerBot = 4
+	pcmuPayload    = 0
+)
+
+var (
+	ErrVoiceUnavailable    = errors.New("voice calls are not available")
+	ErrSpeechNotConfigured = errors.New("bot has no speech and transcription models configured")
+	ErrTooManyCalls        = errors.New("too many active voice calls for this bot")
+	ErrInvalidOffer        = errors.New("invalid voice call offer")
+)
+
+// Replier runs a chat turn for an utterance and streams the reply text.
+type Replier interface {
+	StreamReply(ctx context.Context, botID, userID, query string, onText func(delta string)) error
+}
+
+type speechService interface {
+	Transcribe(ctx context.Context, modelID string, audioBytes []byte, filename string, contentType string, overrideCfg map[string]any) (*sdk.TranscriptionResult, error)
+	Synthesize(ctx context.Context, modelID string, text string, overrideCfg map[string]any) ([]byte, string, error)
+	GetSpeechModelCapabilities(ctx context.Context, modelID string) (*audio.ModelCapabilities, error)
+}
+
+type botSettings interface {
+	GetBot(ctx context.Context, botID string) (settings.Settings, error)
+}
+
+type OfferRequest struct {
+	SDP string
+}
+
+type OfferResponse struct {
+	Type      string
+	SDP       string
+	SessionID string
+}
+
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	BotID     string    `json:"bot_id"`
+	UserID    string    `json:"user_id"`
+	State     string    `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Service struct {
+	logger   *slog.Logger
+	speech   speechService
+	settings botSettings
+	replier  Replier
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+func NewService(log *slog.Logger, audioService *audio.Service, settingsService *settings.Service) *Service {
+	return &Service{
+		logger:   log.With(slog.String("service", "voice")),
+		speech:   audioService,
+		settings: settingsService,
+		calls:    make(map[string]*call),
+	}
+}
+
+func (s *Service) SetReplier(replier Replier) {
+	s.replier = replier
+}
+
+// Answer accepts a caller's WebRTC offer and starts the call. Only PCMU
+// audio is negotiated.
+func (s *Service) Answer(ctx context.Context, botID, userID string, req OfferRequest) (OfferResponse, error) {
+	if s.replier == nil {
+		return OfferResponse{}, ErrVoiceUnavailable
+	}
+	if strings.TrimSpace(req.SDP) == "" {
+		return OfferResponse{}, fmt.Errorf("%w: sdp is required", ErrInvalidOffer)
+	}
+	cfg, err := s.settings.GetBot(ctx, botID)
+	if err != nil {
+		return OfferResponse{}, err
+	}
+	ttsModelID := strings.TrimSpace(cfg.TtsModelID)
+	sttModelID := strings.TrimSpace(cfg.TranscriptionModelID)
+	if ttsModelID == "" || sttModelID == "" {
+		return OfferResponse{}, ErrSpeechNotConfigured
+	}
+	if len(s.ListSessions(botID)) >= maxCallsPerBot {
+		return OfferResponse{}, ErrTooManyCalls
+	}
+
+	pc, err := s.newPeerConnection()
+	if err != nil {
+		return OfferResponse{}, err
+	}
+	track, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{
+		MimeType:  webrtc.MimeTypePCMU,
+		ClockRate: sampleRate,
+		Channels:  1,
+	}, "audio", "voice-"+botID)
+	if err != nil {
+		_ = pc.Close()
+		return OfferResponse{}, err
+	}
+	sender, err := pc.AddTrack(track)
+	if err != nil {
+		_ = pc.Close()
+		return OfferResponse{}, err
+	}
+	go drainRTCP(sender)
+
+	c := newCall(s, uuid.NewString(), botID, userID, pc, track)
+	c.ttsModelID = ttsModelID
+	c.sttModelID = sttModelID
+	c.synthesisConfig = s.wavSynthesisConfig(ctx, ttsModelID)
+	s.addCall(c)
+
+	if err := pc.SetRemoteDescription(webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: req.SDP}); err != nil {
+		c.close()
+		return OfferResponse{}, fmt.Errorf("%w: %s", ErrInvalidOffer, err.Error())
+	}
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		c.close()
+		return OfferResponse{}, fmt.Errorf("%w: %s", ErrInvalidOffer, err.Error())
+	}
+	gatherDone := webrtc.GatheringCompletePromise(pc)
+	if err := pc.SetLocalDescription(answer); err != nil {
+		c.close()
+		return OfferResponse{}, err
+	}
+	select {
+	case <-ctx.Done():
+		c.close()
+		return OfferResponse{}, ctx.Err()
+	case <-gatherDone:
+	}
+	local := pc.LocalDescription()
+	if local == nil {
+		c.close()
+		return OfferResponse{}, errors.New("local session description unavailable")
+	}
+	go c.run()
+	s.logger.Info("voice call started", slog.String("bot_id", botID), slog.String("session_id", c.id))
+	return OfferResponse{Type: "answer", SDP: local.SDP, SessionID: c.id}, nil
+}
+
+func (s *Service) ListSessions(botID string) []SessionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []SessionInfo
+	for _, c := range s.calls {
+		if c.botID == botID {
+			out = append(out, c.info())
+		}
+	}
+	slices.SortFunc(out, func(a, b SessionInfo) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return out
+}
+
+// CloseSession hangs up a call.
+func (s *Service) CloseSession(botID, sessionID string) bool {
+	s.mu.Lock()
+	c, ok := s.calls[sessionID]
+	s.mu.Unlock()
+	if !ok || c.botID != botID {
+		return false
+	}
+	c.close()
+	return true
+}
+
+// Close hangs up every call.
+func (s *Service) Close() {
+	s.mu.Lock()
+	calls := make([]*call, 0, len(s.calls))
+	for _, c := range s.calls {
+		calls = append(calls, c)
+	}
+	s.mu.Unlock()
+	for _, c := range calls {
+		c.close()
+	}
+}
+
+func (s *Service) addCall(c *call) {
+	s.mu.Lock()
+	s.calls[c.id] = c
+	s.mu.Unlock()
+}
+
+func (s *Service) removeCall(c *call) {
+	s.mu.Lock()
+	if s.calls[c.id] == c {
+		delete(s.calls, c.id)
+	}
+	s.mu.Unlock()
+}
+
+func (s *Service) newPeerConnection() (*webrtc.PeerConnection, error) {
+	mediaEngine := &webrtc.MediaEngine{}
+	if err := mediaEngine.RegisterCodec(webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypePCMU, ClockRate: sampleRate, Channels: 1},
+		PayloadType:        pcmuPayload,
+	}, webrtc.RTPCodecTypeAudio); err != nil {
+		return nil, err
+	}
+	settingEngine := webrtc.SettingEngine{}
+	var natIPs []string
+	for _, part := range strings.Split(os.Getenv(natIPsEnv), ",") {
+		ip := strings.TrimSpace(part)
+		if ip == "" {
+			continue
+		}
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("%s contains invalid IP %q", natIPsEnv, ip)
+		}
+		natIPs = append(natIPs, ip)
+	}
+	if len(natIPs) > 0 {
+		if err := settingEngine.SetICEAddressRewriteRules(webrtc.ICEAddressRewriteRule{
+			External:        natIPs,
+			AsCandidateType: webrtc.ICECandidateTypeHost,
+			Mode:            webrtc.ICEAddressRewriteReplace,
+		}); err != nil {
+			return nil, fmt.Errorf("configure voice WebRTC NAT rewrite: %w", err)
+		}
+	}
+	api := webrtc.NewAPI(webrtc.WithMediaEngine(mediaEngine), webrtc.WithSettingEngine(settingEngine))
+	return api.NewPeerConnection(webrtc.Configuration{})
+}
+
+// wavSynthesisConfig asks the speech model for WAV output, which the call
+// can play without a native decoder. Models without a WAV option are used
+// as configured; their replies reach the caller as text only.
+func (s *Service) wavSynthesisConfig(ctx context.Context, modelID string) map[string]any {
+	caps, err := s.speech.GetSpeechModelCapabilities(ctx, modelID)
+	if err != nil || caps == nil {
+		return nil
+	}
+	for _, field := range caps.ConfigSchema.Fields {
+		if field.Key != "format" && field.Key != "response_format" {
+			continue
+		}
+		if slices.Contains(field.Enum, "wav") {
+			return map[string]any{field.Key: "wav"}
+		}
+	}
+	return nil
+}
+
+func drainRTCP(sender *webrtc.RTPSender) {
+	buf := make([]byte, 1500)
+	for {
+		if _, _, err := sender.Read(buf); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package voice
+
+import "math"
+
+// Endpointing thresholds, in samples at sampleRate.
+const (
+	// speechStartSamples of continuous voice mark the caller as talking. It
+	// is also the barge-in trigger, so it is long enough to ignore clicks
+	// and breaths.
+	speechStartSamples = sampleRate * 200 / 1000
+	// utteranceEndSamples of silence end an utterance.
+	utteranceEndSamples = sampleRate * 700 / 1000
+	// maxUtteranceSamples cuts off callers who never pause.
+	maxUtteranceSamples = sampleRate * 30
+	// prerollSamples of audio before speech was detected are kept so the
+	// first syllable is not clipped.
+	prerollSamples = sampleRate * 300 / 1000
+
+	// minSpeechLevel is the lowest RMS counted as voice; the threshold
+	// rises with the background noise floor.
+	minSpeechLevel   = 500
+	noiseFloorFactor = 3
+	noiseFloorDecay  = 0.95
+)
+
+type vadEvent int
+
+const (
+	vadNone vadEvent = iota
+	// vadSpeechStart fires once the caller has talked long enough to count
+	// as speech.
+	vadSpeechStart
+	// vadUtteranceEnd fires after the caller goes quiet; the utterance
+	// audio is returned with it.
+	vadUtteranceEnd
+)
+
+// endpointer splits caller audio into utterances by frame energy. Browsers
+// apply echo cancellation and noise suppression before audio reaches it, so
+// a simple energy gate holds up for conversational turn taking.
+type endpointer struct {
+	noiseFloor float64
+	speaking   bool
+	voiced     int // consecutive voiced samples
+	silent     int // consecutive silent samples while speaking
+	preroll    []int16
+	utterance  []int16
+}
+
+func newEndpointer() *endpointer {
+	return &endpointer{noiseFloor: minSpeechLevel / noiseFloorFactor}
+}
+
+// push feeds one frame of caller audio.
+func (e *endpointer) push(frame []int16) (vadEvent, []int16) {
+	if len(frame) == 0 {
+		return vadNone, nil
+	}
+	level := rms(frame)
+	threshold := max(float64(minSpeechLevel), e.noiseFloor*noiseFloorFactor)
+	voice := level >= threshold
+	if !voice {
+		e.noiseFloor = e.noiseFloor*noiseFloorDecay + level*(1-noiseFloorDecay)
+	}
+
+	if !e.speaking {
+		if !voice {
+			e.voiced = 0
+			e.utterance = e.utterance[:0]
+			e.preroll = appendTail(e.preroll, frame, prerollSamples)
+			return vadNone, nil
+		}
+		e.voiced += len(frame)
+		e.utterance = append(e.utterance, frame...)
+		if e.voiced < speechStartSamples {
+			return vadNone, nil
+		}
+		e.speaking = true
+		e.silent = 0
+		e.utterance = append(append([]int16(nil), e.preroll...), e.utterance...)
+		e.preroll = e.preroll[:0]
+		return vadSpeechStart, nil
+	}
+
+	e.utterance = append(e.utterance, frame...)
+	if voice {
+		e.silent = 0
+	} else {
+		e.silent += len(frame)
+	}
+	if e.silent < utteranceEndSamples && len(e.utterance) < maxUtteranceSamples {
+		return vadNone, nil
+	}
+	out := e.utterance
+	e.speaking = false
+	e.voiced = 0
+	e.silent = 0
+	e.utterance = nil
+	return vadUtteranceEnd, out
+}
+
+func rms(frame []int16) float64 {
+	var sum float64
+	for _, s := range frame {
+		v := float64(s)
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(frame)))
+}
+
+// appendTail appends frame to buf and keeps only the last limit samples.
+func appendTail(buf, frame []int16, limit int) []int16 {
+	buf = append(buf, frame...)
+	if over := len(buf) - limit; over > 0 {
+		buf = append(buf[:0], buf[over:]...)
+	}
+	return buf
+}